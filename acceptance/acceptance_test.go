@@ -5,14 +5,10 @@ package acceptance
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"sort"
-	"strings"
 	"testing"
 	"time"
 
@@ -20,7 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/encoding/protojson"
 
-	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/enostest"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -66,7 +62,7 @@ func ensureEnosCLI(t *testing.T) {
 }
 
 func hasEnosCLI() bool {
-	if path, ok := os.LookupEnv("ENOS_BINARY_PATH"); ok {
+	if path, ok := os.LookupEnv(enostest.BinaryPathEnvVar); ok {
 		if path != "" {
 			return true
 		}
@@ -146,10 +142,7 @@ type acceptanceRunnerOpt func(*acceptanceRunner)
 func newAcceptanceRunner(t *testing.T, opts ...acceptanceRunnerOpt) *acceptanceRunner {
 	t.Helper()
 
-	r := &acceptanceRunner{
-		env: os.Environ(),
-	}
-	r.enosBinPath, _ = os.LookupEnv("ENOS_BINARY_PATH")
+	r := &acceptanceRunner{}
 	r.tfBinPath, _ = exec.LookPath("terraform")
 
 	for _, opt := range opts {
@@ -158,6 +151,14 @@ func newAcceptanceRunner(t *testing.T, opts ...acceptanceRunnerOpt) *acceptanceR
 
 	r.validate(t)
 
+	var err error
+	r.Runner, err = enostest.NewRunner(
+		enostest.WithBinPath(os.Getenv(enostest.BinaryPathEnvVar)),
+		// Don't cache decoded flight plans in the .enos directories of the test scenarios.
+		enostest.WithArgs("--decode-cache=false"),
+	)
+	require.NoError(t, err)
+
 	return r
 }
 
@@ -187,39 +188,14 @@ func skipUnlessExtEnabled() acceptanceRunnerOpt {
 
 // acceptanceRunner is the Enos CLI acceptance test runner.
 type acceptanceRunner struct {
-	enosBinPath              string
+	*enostest.Runner
 	tfBinPath                string
-	env                      []string
 	skipUnlessTerraformCLI   bool
 	skipUnlessAWSCredentials bool
 	skipUnlessEnosPrivateKey bool
 	skipUnlessExtEnabled     bool
 }
 
-// run runs an Enos sub-command.
-func (r *acceptanceRunner) run(ctx context.Context, subCommand string) ([]byte, []byte, error) {
-	path, err := filepath.Abs(r.enosBinPath)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	cmdParts := strings.Split(subCommand, " ")
-	// Don't specify a port so we can execute tests in parallel
-	cmdParts = append(cmdParts, "--grpc-listen", "http://localhost")
-
-	cmd := exec.CommandContext(ctx, path, cmdParts...)
-	cmd.Env = os.Environ()
-
-	stdout, err := cmd.Output()
-	var stderr []byte
-	var exitErr *exec.ExitError
-	if err != nil && errors.As(err, &exitErr) {
-		stderr = exitErr.Stderr
-	}
-
-	return stdout, stderr, err
-}
-
 func (r *acceptanceRunner) validate(t *testing.T) {
 	t.Helper()
 	ensureAcc(t)
@@ -238,18 +214,6 @@ func (r *acceptanceRunner) validate(t *testing.T) {
 	}
 }
 
-func sortResponses(r []*pb.Operation_Response) {
-	sort.Slice(r, func(i, j int) bool {
-		is := flightplan.NewScenario()
-		is.FromRef(r[i].GetOp().GetScenario())
-
-		js := flightplan.NewScenario()
-		js.FromRef(r[j].GetOp().GetScenario())
-
-		return is.String() < js.String()
-	})
-}
-
 func requireEqualOperationResponses(t *testing.T, expected *pb.OperationResponses, out []byte) {
 	t.Helper()
 
@@ -258,8 +222,8 @@ func requireEqualOperationResponses(t *testing.T, expected *pb.OperationResponse
 	require.Len(t, expected.GetResponses(), len(got.GetResponses()))
 	expectedResponses := expected.GetResponses()
 	gotResponses := got.GetResponses()
-	sortResponses(expectedResponses)
-	sortResponses(gotResponses)
+	enostest.SortOperationResponses(expectedResponses)
+	enostest.SortOperationResponses(gotResponses)
 
 	require.Lenf(t, gotResponses, len(expectedResponses),
 		fmt.Sprintf("expected %d responses, got %d", len(expectedResponses), len(gotResponses)),
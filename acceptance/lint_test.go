@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package acceptance
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+func TestAcc_Cmd_Lint(t *testing.T) {
+	t.Parallel()
+
+	for filter, test := range map[string]struct {
+		codes []string
+		fail  bool
+	}{
+		"": {
+			codes: []string{"scenario_description", "step_name"},
+			fail:  true,
+		},
+		"described": {
+			codes: []string{},
+		},
+	} {
+		t.Run(filter, func(t *testing.T) {
+			t.Parallel()
+			enos := newAcceptanceRunner(t)
+			path, err := filepath.Abs(filepath.Join("./scenarios", "lint"))
+			require.NoError(t, err)
+			cmd := fmt.Sprintf("lint --chdir %s --format json %s", path, filter)
+			out, _, err := enos.Run(context.Background(), cmd)
+			if test.fail {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			got := &pb.LintScenariosResponse{}
+			require.NoError(t, protojson.Unmarshal(out, got))
+			require.Len(t, got.GetLints(), len(test.codes))
+			for i := range test.codes {
+				require.Equal(t, test.codes[i], got.GetLints()[i].GetCode())
+			}
+		})
+	}
+}
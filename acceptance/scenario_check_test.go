@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hashicorp/enos/enostest"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -41,17 +42,12 @@ func TestAcc_Cmd_Scenario_Check(t *testing.T) {
 			t.Parallel()
 
 			enos := newAcceptanceRunner(t, skipUnlessTerraformCLI())
-			tmpDir := t.TempDir()
-			outDir := filepath.Join(tmpDir, test.dir)
-			err := os.MkdirAll(outDir, 0o755)
-			require.NoError(t, err)
-			outDir, err = filepath.EvalSymlinks(outDir)
-			require.NoError(t, err)
+			outDir := enostest.NewOutDir(t, test.dir)
 			path, err := filepath.Abs(filepath.Join("./scenarios", test.dir))
 			require.NoError(t, err)
 
 			cmd := fmt.Sprintf("scenario check --chdir %s --out %s --format json", path, outDir)
-			out, stderr, err := enos.run(context.Background(), cmd)
+			out, stderr, err := enos.Run(context.Background(), cmd)
 			require.NoError(t, err, "enos "+cmd+": "+string(out)+string(stderr))
 
 			expected := &pb.OperationResponses{
@@ -76,6 +72,7 @@ func TestAcc_Cmd_Scenario_Check(t *testing.T) {
 						Name:   test.name,
 						Filter: filter,
 						Uid:    uid,
+						Slug:   enostest.ScenarioSlug(test.name, elements),
 						Variants: &pb.Matrix_Vector{
 							Elements: elements,
 						},
@@ -91,8 +88,8 @@ func TestAcc_Cmd_Scenario_Check(t *testing.T) {
 						Check: &pb.Operation_Response_Check{
 							Generate: &pb.Operation_Response_Generate{
 								TerraformModule: &pb.Terraform_Module{
-									ModulePath:  filepath.Join(outDir, uid, "scenario.tf"),
-									RcPath:      filepath.Join(outDir, uid, "terraform.rc"),
+									ModulePath:  filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "scenario.tf"),
+									RcPath:      filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "terraform.rc"),
 									ScenarioRef: scenarioRef,
 								},
 							},
@@ -137,7 +134,7 @@ func TestAcc_Cmd_Scenario_Check_WithWarnings(t *testing.T) {
 			if failOnWarnings {
 				cmd = cmd + " --fail-on-warnings"
 			}
-			out, stderr, err := enos.run(context.Background(), cmd)
+			out, stderr, err := enos.Run(context.Background(), cmd)
 			if failOnWarnings {
 				require.Error(t, err, "enos "+cmd+": "+string(out)+string(stderr))
 
@@ -161,6 +158,7 @@ func TestAcc_Cmd_Scenario_Check_WithWarnings(t *testing.T) {
 					Id: &pb.Scenario_ID{
 						Name:   "warning",
 						Uid:    uid,
+						Slug:   enostest.ScenarioSlug("warning", elements),
 						Filter: "warning mod:" + variant,
 						Variants: &pb.Matrix_Vector{
 							Elements: elements,
@@ -194,8 +192,8 @@ func TestAcc_Cmd_Scenario_Check_WithWarnings(t *testing.T) {
 						Check: &pb.Operation_Response_Check{
 							Generate: &pb.Operation_Response_Generate{
 								TerraformModule: &pb.Terraform_Module{
-									ModulePath:  filepath.Join(outDir, uid, "scenario.tf"),
-									RcPath:      filepath.Join(outDir, uid, "terraform.rc"),
+									ModulePath:  filepath.Join(outDir, enostest.ScenarioSlug("warning", elements), "scenario.tf"),
+									RcPath:      filepath.Join(outDir, enostest.ScenarioSlug("warning", elements), "terraform.rc"),
 									ScenarioRef: scenarioRef,
 								},
 							},
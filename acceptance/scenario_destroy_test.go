@@ -7,12 +7,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hashicorp/enos/enostest"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -47,14 +47,7 @@ func TestAcc_Cmd_Scenario_Destroy(t *testing.T) {
 		t.Run(fmt.Sprintf("%s %s %s %t", test.dir, test.name, test.variants, test.launch), func(t *testing.T) {
 			t.Parallel()
 			enos := newAcceptanceRunner(t, skipUnlessTerraformCLI())
-			tmpDir, err := os.MkdirTemp("/tmp", "enos.destroy")
-			require.NoError(t, err)
-			t.Cleanup(func() { os.RemoveAll(tmpDir) })
-			outDir := filepath.Join(tmpDir, test.dir)
-			err = os.MkdirAll(outDir, 0o755)
-			require.NoError(t, err)
-			outDir, err = filepath.EvalSymlinks(outDir)
-			require.NoError(t, err)
+			outDir := enostest.NewOutDir(t, test.dir)
 			path, err := filepath.Abs(filepath.Join("./scenarios", test.dir))
 			require.NoError(t, err)
 
@@ -71,12 +64,12 @@ func TestAcc_Cmd_Scenario_Destroy(t *testing.T) {
 			// Test destroying a scenario with it launched or not
 			if test.launch {
 				cmd := fmt.Sprintf("scenario launch --chdir %s --out %s %s", path, outDir, filter)
-				out, stderr, err := enos.run(context.Background(), cmd)
+				out, stderr, err := enos.Run(context.Background(), cmd)
 				require.NoError(t, err, "enos "+cmd+": "+string(out)+string(stderr))
 			}
 
 			cmd := fmt.Sprintf("scenario destroy --chdir %s --out %s --format json %s", path, outDir, filter)
-			out, stderr, err := enos.run(context.Background(), cmd)
+			out, stderr, err := enos.Run(context.Background(), cmd)
 			require.NoError(t, err, "enos "+cmd+": "+string(out)+string(stderr))
 
 			scenarioRef := &pb.Ref_Scenario{
@@ -84,6 +77,7 @@ func TestAcc_Cmd_Scenario_Destroy(t *testing.T) {
 					Name:   test.name,
 					Filter: filter,
 					Uid:    test.uid,
+					Slug:   enostest.ScenarioSlug(test.name, elements),
 					Variants: &pb.Matrix_Vector{
 						Elements: elements,
 					},
@@ -101,8 +95,8 @@ func TestAcc_Cmd_Scenario_Destroy(t *testing.T) {
 							Destroy: &pb.Operation_Response_Destroy{
 								Generate: &pb.Operation_Response_Generate{
 									TerraformModule: &pb.Terraform_Module{
-										ModulePath:  filepath.Join(outDir, test.uid, "scenario.tf"),
-										RcPath:      filepath.Join(outDir, test.uid, "terraform.rc"),
+										ModulePath:  filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "scenario.tf"),
+										RcPath:      filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "terraform.rc"),
 										ScenarioRef: scenarioRef,
 									},
 								},
@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hashicorp/enos/enostest"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -60,14 +61,7 @@ func TestAcc_Cmd_Scenario_E2E_AWS(t *testing.T) {
 				skipUnlessExtEnabled(),
 			)
 
-			tmpDir, err := os.MkdirTemp("/tmp", "enos.aws.e2e")
-			require.NoError(t, err)
-			t.Cleanup(func() { os.RemoveAll(tmpDir) })
-			outDir := filepath.Join(tmpDir, test.dir)
-			err = os.MkdirAll(outDir, 0o755)
-			require.NoError(t, err)
-			outDir, err = filepath.EvalSymlinks(outDir)
-			require.NoError(t, err)
+			outDir := enostest.NewOutDir(t, test.dir)
 			path, err := filepath.Abs(filepath.Join("./scenarios", test.dir))
 			require.NoError(t, err)
 
@@ -81,24 +75,30 @@ func TestAcc_Cmd_Scenario_E2E_AWS(t *testing.T) {
 				// Since we failed lets output something useful that might help
 				// us debug the problem
 				for _, variant := range test.variants {
-					bytes, err := os.ReadFile(filepath.Join(outDir, variant.uid, "scenario.tf"))
+					elements := []*pb.Matrix_Element{}
+					for _, v := range variant.variants {
+						elements = append(elements, &pb.Matrix_Element{Key: v[0], Value: v[1]})
+					}
+					slug := enostest.ScenarioSlug(test.name, elements)
+
+					bytes, err := os.ReadFile(filepath.Join(outDir, slug, "scenario.tf"))
 					if err != nil {
 						t.Logf("unable to read failed scenario's generated module: %s", err.Error())
 					} else {
-						t.Logf("%s/scenario.tf\n (%s) %s", variant.uid, test.name, string(bytes))
+						t.Logf("%s/scenario.tf\n (%s) %s", slug, test.name, string(bytes))
 					}
 
-					bytes, err = os.ReadFile(filepath.Join(outDir, variant.uid, "terraform.rc"))
+					bytes, err = os.ReadFile(filepath.Join(outDir, slug, "terraform.rc"))
 					if err != nil {
 						t.Logf("unable to read failed scenario's generated cli config: %s", err.Error())
 					} else {
-						t.Logf("%s/terraform.rc (%s)\n %s", variant.uid, test.name, string(bytes))
+						t.Logf("%s/terraform.rc (%s)\n %s", slug, test.name, string(bytes))
 					}
 				}
 
 				// Lets try one more time to destroy resources that might have been
 				// created
-				out, _, err := enos.run(context.Background(), fmt.Sprintf("scenario destroy --chdir %s --out %s", path, outDir))
+				out, _, err := enos.Run(context.Background(), fmt.Sprintf("scenario destroy --chdir %s --out %s", path, outDir))
 				require.NoErrorf(t, err, string(out))
 			})
 
@@ -118,6 +118,7 @@ func TestAcc_Cmd_Scenario_E2E_AWS(t *testing.T) {
 					Id: &pb.Scenario_ID{
 						Name:   test.name,
 						Uid:    variant.uid,
+						Slug:   enostest.ScenarioSlug(test.name, elements),
 						Filter: variant.filter,
 						Variants: &pb.Matrix_Vector{
 							Elements: elements,
@@ -134,8 +135,8 @@ func TestAcc_Cmd_Scenario_E2E_AWS(t *testing.T) {
 						Run: &pb.Operation_Response_Run{
 							Generate: &pb.Operation_Response_Generate{
 								TerraformModule: &pb.Terraform_Module{
-									ModulePath:  filepath.Join(outDir, variant.uid, "scenario.tf"),
-									RcPath:      filepath.Join(outDir, variant.uid, "terraform.rc"),
+									ModulePath:  filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "scenario.tf"),
+									RcPath:      filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "terraform.rc"),
 									ScenarioRef: scenarioRef,
 								},
 							},
@@ -154,7 +155,7 @@ func TestAcc_Cmd_Scenario_E2E_AWS(t *testing.T) {
 			}
 
 			cmd := fmt.Sprintf("scenario run --chdir %s --out %s --format json", path, outDir)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			if err != nil {
 				failed = true
 			}
@@ -7,12 +7,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hashicorp/enos/enostest"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -76,14 +76,7 @@ func TestAcc_Cmd_Scenario_Generate(t *testing.T) {
 
 			enos := newAcceptanceRunner(t)
 
-			tmpDir, err := os.MkdirTemp("", "enos.generate.out")
-			require.NoError(t, err)
-			t.Cleanup(func() { os.RemoveAll(tmpDir) })
-			outDir := filepath.Join(tmpDir, test.dir)
-			err = os.MkdirAll(outDir, 0o755)
-			require.NoError(t, err)
-			outDir, err = filepath.EvalSymlinks(outDir)
-			require.NoError(t, err)
+			outDir := enostest.NewOutDir(t, test.dir)
 			path, err := filepath.Abs(filepath.Join("./scenarios", test.dir))
 			require.NoError(t, err)
 
@@ -109,12 +102,13 @@ func TestAcc_Cmd_Scenario_Generate(t *testing.T) {
 					Name:     test.name,
 					Filter:   filter,
 					Uid:      test.uid,
+					Slug:     enostest.ScenarioSlug(test.name, variants.GetElements()),
 					Variants: variants,
 				},
 			}
 
 			cmd := fmt.Sprintf("scenario generate --chdir %s --out %s %s --format json", path, outDir, filter)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			require.NoErrorf(t, err, string(out))
 
 			expected := &pb.OperationResponses{
@@ -127,8 +121,8 @@ func TestAcc_Cmd_Scenario_Generate(t *testing.T) {
 						Value: &pb.Operation_Response_Generate_{
 							Generate: &pb.Operation_Response_Generate{
 								TerraformModule: &pb.Terraform_Module{
-									ModulePath:  filepath.Join(outDir, test.uid, "scenario.tf"),
-									RcPath:      filepath.Join(outDir, test.uid, "terraform.rc"),
+									ModulePath:  filepath.Join(outDir, enostest.ScenarioSlug(test.name, variants.GetElements()), "scenario.tf"),
+									RcPath:      filepath.Join(outDir, enostest.ScenarioSlug(test.name, variants.GetElements()), "terraform.rc"),
 									ScenarioRef: scenarioRef,
 								},
 							},
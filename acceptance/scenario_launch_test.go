@@ -7,12 +7,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hashicorp/enos/enostest"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -46,14 +46,7 @@ func TestAcc_Cmd_Scenario_Launch(t *testing.T) {
 
 			enos := newAcceptanceRunner(t, skipUnlessTerraformCLI())
 
-			tmpDir, err := os.MkdirTemp("/tmp", "enos.launch")
-			require.NoError(t, err)
-			t.Cleanup(func() { os.RemoveAll(tmpDir) })
-			outDir := filepath.Join(tmpDir, test.dir)
-			err = os.MkdirAll(outDir, 0o755)
-			require.NoError(t, err)
-			outDir, err = filepath.EvalSymlinks(outDir)
-			require.NoError(t, err)
+			outDir := enostest.NewOutDir(t, test.dir)
 			path, err := filepath.Abs(filepath.Join("./scenarios", test.dir))
 			require.NoError(t, err)
 
@@ -72,6 +65,7 @@ func TestAcc_Cmd_Scenario_Launch(t *testing.T) {
 					Name:   test.name,
 					Filter: filter,
 					Uid:    test.uid,
+					Slug:   enostest.ScenarioSlug(test.name, elements),
 					Variants: &pb.Matrix_Vector{
 						Elements: elements,
 					},
@@ -79,7 +73,7 @@ func TestAcc_Cmd_Scenario_Launch(t *testing.T) {
 			}
 
 			cmd := fmt.Sprintf("scenario launch --chdir %s --out %s --format json %s", path, outDir, filter)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			require.NoError(t, err, string(out))
 
 			expected := &pb.OperationResponses{
@@ -93,8 +87,8 @@ func TestAcc_Cmd_Scenario_Launch(t *testing.T) {
 							Launch: &pb.Operation_Response_Launch{
 								Generate: &pb.Operation_Response_Generate{
 									TerraformModule: &pb.Terraform_Module{
-										ModulePath:  filepath.Join(outDir, test.uid, "scenario.tf"),
-										RcPath:      filepath.Join(outDir, test.uid, "terraform.rc"),
+										ModulePath:  filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "scenario.tf"),
+										RcPath:      filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "terraform.rc"),
 										ScenarioRef: scenarioRef,
 									},
 								},
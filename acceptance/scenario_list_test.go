@@ -4,14 +4,21 @@
 package acceptance
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
 
+	"github.com/hashicorp/enos/enostest"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -34,6 +41,7 @@ func TestAcc_Cmd_Scenario_List(t *testing.T) {
 						Name:   "test",
 						Filter: "test",
 						Uid:    "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+						Slug:   "test_9f86d081",
 					},
 				}},
 			},
@@ -46,6 +54,7 @@ func TestAcc_Cmd_Scenario_List(t *testing.T) {
 						Id: &pb.Scenario_ID{
 							Name:   "consul",
 							Uid:    "b713f0bd8f48dfad2263cabc455ade78f7e4e99a548101f31f935686dff67124",
+							Slug:   "consul_b713f0bd",
 							Filter: "consul",
 						},
 					},
@@ -54,6 +63,7 @@ func TestAcc_Cmd_Scenario_List(t *testing.T) {
 							Name:   "vault",
 							Filter: "vault",
 							Uid:    "e6f0a1fbb43c89196dcfcbef85908f19ab4c5f7cc4f4c452284697757683d7ef",
+							Slug:   "vault_e6f0a1fb",
 						},
 					},
 				},
@@ -68,6 +78,7 @@ func TestAcc_Cmd_Scenario_List(t *testing.T) {
 							Name:   "test",
 							Filter: "test backend:consul",
 							Uid:    "5ee261842ccc5bece062285d63a36dafc61bb5b95793f55820a885969ab8b19b",
+							Slug:   "test_consul_5ee26184",
 							Variants: &pb.Matrix_Vector{
 								Elements: []*pb.Matrix_Element{
 									{
@@ -83,6 +94,7 @@ func TestAcc_Cmd_Scenario_List(t *testing.T) {
 							Name:   "test",
 							Filter: "test backend:raft",
 							Uid:    "c3576214aca53aad678161d049f5c123026bff0fb5ec1761438c32114fe445a0",
+							Slug:   "test_raft_c3576214",
 							Variants: &pb.Matrix_Vector{
 								Elements: []*pb.Matrix_Element{
 									{
@@ -109,7 +121,7 @@ func TestAcc_Cmd_Scenario_List(t *testing.T) {
 			require.NoError(t, err)
 			cmd := fmt.Sprintf("scenario list --chdir %s --format json", path)
 			fmt.Println(path)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			if test.fail {
 				require.Error(t, err)
 
@@ -126,3 +138,88 @@ func TestAcc_Cmd_Scenario_List(t *testing.T) {
 		})
 	}
 }
+
+func TestAcc_Cmd_Scenario_List_Stream(t *testing.T) {
+	t.Parallel()
+
+	enos := newAcceptanceRunner(t)
+
+	path, err := filepath.Abs(filepath.Join("./", "scenarios/scenario_list_pass_3"))
+	require.NoError(t, err)
+	cmd := fmt.Sprintf("scenario list --chdir %s --format json --stream", path)
+	out, _, err := enos.Run(context.Background(), cmd)
+	require.NoError(t, err)
+
+	msgs, err := enostest.DecodeStream(out, func() *pb.EnosServiceListScenariosResponse {
+		return &pb.EnosServiceListScenariosResponse{}
+	})
+	require.NoError(t, err)
+
+	filters := []string{}
+	for _, msg := range msgs {
+		require.NotNil(t, msg.GetScenario())
+		filters = append(filters, msg.GetScenario().GetId().GetFilter())
+	}
+	slices.Sort(filters)
+
+	require.Equal(t, []string{"test backend:consul", "test backend:raft"}, filters)
+}
+
+func TestAcc_Cmd_Scenario_List_YAML(t *testing.T) {
+	t.Parallel()
+
+	enos := newAcceptanceRunner(t)
+
+	path, err := filepath.Abs(filepath.Join("./", "scenarios/scenario_list_pass_3"))
+	require.NoError(t, err)
+
+	cmd := fmt.Sprintf("scenario list --chdir %s --format json", path)
+	out, _, err := enos.Run(context.Background(), cmd)
+	require.NoError(t, err)
+	expected := &pb.ListScenariosResponse{}
+	require.NoError(t, protojson.Unmarshal(out, expected))
+
+	cmd = fmt.Sprintf("scenario list --chdir %s --format yaml", path)
+	out, _, err = enos.Run(context.Background(), cmd)
+	require.NoError(t, err)
+	got := &pb.ListScenariosResponse{}
+	require.NoError(t, protojson.Unmarshal(testYAMLToJSON(t, out), got))
+	require.Equal(t, expected.String(), got.String())
+
+	cmd = fmt.Sprintf("scenario list --chdir %s --format yaml --stream", path)
+	out, _, err = enos.Run(context.Background(), cmd)
+	require.NoError(t, err)
+
+	filters := []string{}
+	dec := yaml.NewDecoder(bytes.NewReader(out))
+	for {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		docJSON, err := json.Marshal(doc)
+		require.NoError(t, err)
+		msg := &pb.EnosServiceListScenariosResponse{}
+		require.NoError(t, protojson.Unmarshal(docJSON, msg))
+		require.NotNil(t, msg.GetScenario())
+		filters = append(filters, msg.GetScenario().GetId().GetFilter())
+	}
+	slices.Sort(filters)
+
+	require.Equal(t, []string{"test backend:consul", "test backend:raft"}, filters)
+}
+
+// testYAMLToJSON converts the YAML document to JSON so that it can be unmarshaled with protojson.
+func testYAMLToJSON(t *testing.T, in []byte) []byte {
+	t.Helper()
+
+	var doc any
+	require.NoError(t, yaml.Unmarshal(in, &doc))
+	out, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	return out
+}
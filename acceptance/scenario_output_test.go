@@ -7,12 +7,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hashicorp/enos/enostest"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -39,15 +39,7 @@ func TestAcc_Cmd_Scenario_Output(t *testing.T) {
 
 			enos := newAcceptanceRunner(t, skipUnlessTerraformCLI())
 
-			tmpDir, err := os.MkdirTemp("/tmp", "enos.exec")
-			require.NoError(t, err)
-			t.Cleanup(func() { os.RemoveAll(tmpDir) })
-
-			outDir := filepath.Join(tmpDir, test.dir)
-			err = os.MkdirAll(outDir, 0o755)
-			require.NoError(t, err)
-			outDir, err = filepath.EvalSymlinks(outDir)
-			require.NoError(t, err)
+			outDir := enostest.NewOutDir(t, test.dir)
 			path, err := filepath.Abs(filepath.Join("./scenarios", test.dir))
 			require.NoError(t, err)
 
@@ -66,6 +58,7 @@ func TestAcc_Cmd_Scenario_Output(t *testing.T) {
 					Name:   test.name,
 					Filter: filter,
 					Uid:    test.uid,
+					Slug:   enostest.ScenarioSlug(test.name, elements),
 					Variants: &pb.Matrix_Vector{
 						Elements: elements,
 					},
@@ -74,16 +67,16 @@ func TestAcc_Cmd_Scenario_Output(t *testing.T) {
 
 			t.Cleanup(func() {
 				cmd := fmt.Sprintf("scenario destroy --chdir %s --out %s %s", path, outDir, filter)
-				out, _, err := enos.run(context.Background(), cmd)
+				out, _, err := enos.Run(context.Background(), cmd)
 				require.NoError(t, err, string(out))
 			})
 
 			cmd := fmt.Sprintf("scenario launch --chdir %s --out %s %s", path, outDir, filter)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			require.NoError(t, err, string(out))
 
 			cmd = fmt.Sprintf(`scenario output --name step_reference_unknown --chdir %s --out %s --format json %s`, path, outDir, filter)
-			out, _, err = enos.run(context.Background(), cmd)
+			out, _, err = enos.Run(context.Background(), cmd)
 			require.NoError(t, err, string(out))
 
 			expected := &pb.OperationResponses{
@@ -96,8 +89,8 @@ func TestAcc_Cmd_Scenario_Output(t *testing.T) {
 						Value: &pb.Operation_Response_Output_{
 							Output: &pb.Operation_Response_Output{
 								TerraformModule: &pb.Terraform_Module{
-									ModulePath:  filepath.Join(outDir, test.uid, "scenario.tf"),
-									RcPath:      filepath.Join(outDir, test.uid, "terraform.rc"),
+									ModulePath:  filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "scenario.tf"),
+									RcPath:      filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "terraform.rc"),
 									ScenarioRef: scenarioRef,
 								},
 								Output: &pb.Terraform_Command_Output_Response{
@@ -7,12 +7,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/hashicorp/enos/enostest"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -46,15 +46,7 @@ func TestAcc_Cmd_Scenario_Run(t *testing.T) {
 
 			enos := newAcceptanceRunner(t, skipUnlessTerraformCLI())
 
-			tmpDir, err := os.MkdirTemp("/tmp", "enos.run")
-			require.NoError(t, err)
-			t.Cleanup(func() { os.RemoveAll(tmpDir) })
-
-			outDir := filepath.Join(tmpDir, test.dir)
-			err = os.MkdirAll(outDir, 0o755)
-			require.NoError(t, err)
-			outDir, err = filepath.EvalSymlinks(outDir)
-			require.NoError(t, err)
+			outDir := enostest.NewOutDir(t, test.dir)
 			path, err := filepath.Abs(filepath.Join("./scenarios", test.dir))
 			require.NoError(t, err)
 
@@ -73,6 +65,7 @@ func TestAcc_Cmd_Scenario_Run(t *testing.T) {
 					Name:   test.name,
 					Filter: filter,
 					Uid:    test.uid,
+					Slug:   enostest.ScenarioSlug(test.name, elements),
 					Variants: &pb.Matrix_Vector{
 						Elements: elements,
 					},
@@ -80,7 +73,7 @@ func TestAcc_Cmd_Scenario_Run(t *testing.T) {
 			}
 
 			cmd := fmt.Sprintf("scenario run --chdir %s --out %s --format json %s", path, outDir, filter)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			require.NoError(t, err, string(out))
 
 			expected := &pb.OperationResponses{
@@ -94,8 +87,8 @@ func TestAcc_Cmd_Scenario_Run(t *testing.T) {
 							Run: &pb.Operation_Response_Run{
 								Generate: &pb.Operation_Response_Generate{
 									TerraformModule: &pb.Terraform_Module{
-										ModulePath:  filepath.Join(outDir, test.uid, "scenario.tf"),
-										RcPath:      filepath.Join(outDir, test.uid, "terraform.rc"),
+										ModulePath:  filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "scenario.tf"),
+										RcPath:      filepath.Join(outDir, enostest.ScenarioSlug(test.name, elements), "terraform.rc"),
 										ScenarioRef: scenarioRef,
 									},
 								},
@@ -137,20 +130,12 @@ func TestAcc_Cmd_Scenario_Run_Timeout(t *testing.T) {
 
 			enos := newAcceptanceRunner(t, skipUnlessTerraformCLI())
 
-			tmpDir, err := os.MkdirTemp("/tmp", "enos.run")
-			require.NoError(t, err)
-			t.Cleanup(func() { os.RemoveAll(tmpDir) })
-
-			outDir := filepath.Join(tmpDir, test.dir)
-			err = os.MkdirAll(outDir, 0o755)
-			require.NoError(t, err)
-			outDir, err = filepath.EvalSymlinks(outDir)
-			require.NoError(t, err)
+			outDir := enostest.NewOutDir(t, test.dir)
 			path, err := filepath.Abs(filepath.Join("./scenarios", test.dir))
 			require.NoError(t, err)
 
 			cmd := fmt.Sprintf("scenario run --chdir %s --out %s --format json --timeout 1s %s", path, outDir, test.name)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			require.Error(t, err, string(out))
 		})
 	}
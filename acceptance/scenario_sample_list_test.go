@@ -51,7 +51,7 @@ func TestAcc_Cmd_Scenario_Sample_List(t *testing.T) {
 			require.NoError(t, err)
 			cmd := fmt.Sprintf("scenario sample list --chdir %s --format json", path)
 			fmt.Println(path)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			require.NoError(t, err)
 			got := &pb.ListSamplesResponse{}
 			require.NoError(t, protojson.Unmarshal(out, got))
@@ -81,6 +81,7 @@ func TestAcc_Cmd_Scenario_Sample_Observe(t *testing.T) {
 								Id: &pb.Scenario_ID{
 									Name:   "smoke",
 									Uid:    "ebc8083d61b560ea1678efb642cfdf54034a7374e18d27e757e71bb5dc28c62e",
+									Slug:   "smoke_arm64_rhel_ebc8083d",
 									Filter: "smoke arch:arm64 distro:rhel",
 									Variants: &pb.Matrix_Vector{
 										Elements: []*pb.Matrix_Element{
@@ -113,6 +114,7 @@ func TestAcc_Cmd_Scenario_Sample_Observe(t *testing.T) {
 								Id: &pb.Scenario_ID{
 									Name:   "smoke",
 									Uid:    "bdc1d6d8a1d8332d0ee5ea09e1cb6ad06d47953f8e225de8dc1f0ec3be5eb6a0",
+									Slug:   "smoke_s390x_amz_bdc1d6d8",
 									Filter: "smoke arch:s390x distro:amz",
 									Variants: &pb.Matrix_Vector{
 										Elements: []*pb.Matrix_Element{
@@ -145,6 +147,7 @@ func TestAcc_Cmd_Scenario_Sample_Observe(t *testing.T) {
 								Id: &pb.Scenario_ID{
 									Name:   "upgrade",
 									Uid:    "3f481b933e5406b3ccafc1a6bbe9fbf18f7f407bbb8546b89dab69345a39dc1b",
+									Slug:   "upgrade_amd64_amz_3f481b93",
 									Filter: "upgrade arch:amd64 distro:amz",
 									Variants: &pb.Matrix_Vector{
 										Elements: []*pb.Matrix_Element{
@@ -180,7 +183,7 @@ func TestAcc_Cmd_Scenario_Sample_Observe(t *testing.T) {
 				test.filter.GetSeed(),
 			)
 			fmt.Println(path)
-			stdout, stderr, err := enos.run(context.Background(), cmd)
+			stdout, stderr, err := enos.Run(context.Background(), cmd)
 			if test.fail {
 				require.Error(t, err)
 
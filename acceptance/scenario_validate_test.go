@@ -43,7 +43,7 @@ func TestAcc_Cmd_Scenario_Validate(t *testing.T) {
 			require.NoError(t, err)
 			cmd := fmt.Sprintf("scenario validate --chdir %s --format json", path)
 			fmt.Println(path)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			if test.fail {
 				require.Error(t, err)
 
@@ -81,7 +81,7 @@ func TestAcc_Cmd_Scenario_Validate_filtered(t *testing.T) {
 			require.NoError(t, err)
 			cmd := fmt.Sprintf("scenario validate %s --chdir %s --format json", filter, path)
 			fmt.Println(path)
-			out, _, err := enos.run(context.Background(), cmd)
+			out, _, err := enos.Run(context.Background(), cmd)
 			if test.fail {
 				require.Error(t, err)
 
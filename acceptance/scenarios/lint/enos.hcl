@@ -0,0 +1,20 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+module "consul" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "described" {
+  description = "A scenario with a description"
+
+  step "backend" {
+    module = module.consul
+  }
+}
+
+scenario "undescribed" {
+  step "Backend" {
+    module = module.consul
+  }
+}
@@ -0,0 +1,10 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+rule "scenario_description" {
+  severity = "error"
+}
+
+rule "step_name" {
+  pattern = "^[a-z]+$"
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package enosclient is a Go client for the enos gRPC API, i.e. the enos server that is started
+// with 'enos server'. A Client embeds the EnosServiceClient so that every RPC is available, and adds
+// helpers to wait for the server to be ready, to wait for and stream operations, and to build
+// scenario filters, e.g.
+//
+//	c, err := enosclient.Dial(ctx, "http://localhost:3205")
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//
+//	res, err := c.LaunchScenarios(ctx, &pb.LaunchScenariosRequest{
+//		Workspace: ws,
+//		Filter:    enosclient.NewFilter("upgrade").Include("distro", "rhel").Proto(),
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	ops, err := c.WaitForOperations(ctx, res)
+package enosclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/hashicorp/enos/internal/client"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// DefaultPollInterval is how often the status of operations and of the server are polled.
+var DefaultPollInterval = 2 * time.Second
+
+// Client is a client of an enos server.
+type Client struct {
+	pb.EnosServiceClient
+
+	conn         *client.Connection
+	connOpts     []client.Opt
+	pollInterval time.Duration
+}
+
+// Opt is a functional option for Dial.
+type Opt func(*Client)
+
+// WithDialOptions adds gRPC dial options to the connection, e.g. transport credentials. By default
+// the connection is insecure.
+func WithDialOptions(opts ...grpc.DialOption) Opt {
+	return func(c *Client) {
+		c.connOpts = append(c.connOpts, client.WithGRPCDialOpts(opts...))
+	}
+}
+
+// WithLogger sets the logger of the client.
+func WithLogger(log hclog.Logger) Opt {
+	return func(c *Client) {
+		c.connOpts = append(c.connOpts, client.WithLogger(log))
+	}
+}
+
+// WithPollInterval sets how often the status of operations and of the server are polled.
+func WithPollInterval(interval time.Duration) Opt {
+	return func(c *Client) {
+		c.pollInterval = interval
+	}
+}
+
+// Dial takes a context, the URL of the server and options and returns a new client. The URL is the
+// same as the --grpc-listen address of the server, e.g. http://localhost:3205 or unix://enos.sock.
+// The connection is established lazily, use WaitForReady to wait until the server is serving.
+func Dial(ctx context.Context, addr string, opts ...Opt) (*Client, error) {
+	c := &Client{
+		pollInterval: DefaultPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.pollInterval <= 0 {
+		return nil, errors.New("poll interval must be greater than zero")
+	}
+
+	listenURL, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing server address: %w", err)
+	}
+
+	c.conn, err = client.Connect(ctx, append([]client.Opt{client.WithGRPCListenURL(listenURL)}, c.connOpts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to server %s: %w", addr, err)
+	}
+	c.EnosServiceClient = c.conn.Client
+
+	return c, nil
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.Close()
+}
+
+// WaitForReady polls the grpc.health.v1 service of the server until it reports that it's serving
+// or the context is done. The server only serves once all of its readiness checks have passed.
+func (c *Client) WaitForReady(ctx context.Context) error {
+	if c.conn == nil || c.conn.Conn == nil {
+		return errors.New("client is not connected")
+	}
+
+	health := healthpb.NewHealthClient(c.conn.Conn)
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		res, err := health.Check(ctx, &healthpb.HealthCheckRequest{
+			Service: pb.EnosService_ServiceDesc.ServiceName,
+		})
+		if err == nil && res.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("waiting for server to be ready: %w: %w", ctx.Err(), err)
+			}
+
+			return fmt.Errorf("waiting for server to be ready: %w: server is %s", ctx.Err(), res.GetStatus())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package enosclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// testServer is a fake enos server whose operations complete after they've been polled a number
+// of times.
+type testServer struct {
+	pb.UnimplementedEnosServiceServer
+
+	mu    sync.Mutex
+	polls map[string]int
+	// pending is how many times an operation is polled before it completes.
+	pending int
+}
+
+func (s *testServer) Operation(ctx context.Context, req *pb.OperationRequest) (*pb.OperationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := req.GetOp().GetId()
+	if id == "missing" {
+		return &pb.OperationResponse{Diagnostics: []*pb.Diagnostic{{
+			Severity: pb.Diagnostic_SEVERITY_ERROR,
+			Summary:  "operation not found",
+		}}}, nil
+	}
+
+	s.polls[id]++
+	status := pb.Operation_STATUS_RUNNING
+	if s.polls[id] > s.pending {
+		status = pb.Operation_STATUS_COMPLETED
+	}
+
+	return &pb.OperationResponse{Response: &pb.Operation_Response{Op: req.GetOp(), Status: status}}, nil
+}
+
+func (s *testServer) OperationEventStream(
+	req *pb.OperationEventStreamRequest,
+	stream pb.EnosService_OperationEventStreamServer,
+) error {
+	for _, status := range []pb.Operation_Status{pb.Operation_STATUS_RUNNING, pb.Operation_STATUS_COMPLETED} {
+		if err := stream.Send(&pb.OperationEventStreamResponse{Event: &pb.Operation_Event{
+			Op:     req.GetOp(),
+			Status: status,
+		}}); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.polls[req.GetOp().GetId()] = s.pending
+	s.mu.Unlock()
+
+	return nil
+}
+
+func testStartServer(t *testing.T, svr *testServer) (string, *health.Server) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthSvr := health.NewServer()
+	healthSvr.SetServingStatus(pb.EnosService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	grpcSvr := grpc.NewServer()
+	svr.polls = map[string]int{}
+	pb.RegisterEnosServiceServer(grpcSvr, svr)
+	healthpb.RegisterHealthServer(grpcSvr, healthSvr)
+	go func() { _ = grpcSvr.Serve(listener) }()
+	t.Cleanup(grpcSvr.Stop)
+
+	return "http://" + listener.Addr().String(), healthSvr
+}
+
+func testDial(t *testing.T, addr string) *Client {
+	t.Helper()
+
+	c, err := Dial(context.Background(), addr, WithPollInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c
+}
+
+func Test_Client_WaitForReady(t *testing.T) {
+	t.Parallel()
+
+	addr, healthSvr := testStartServer(t, &testServer{})
+	c := testDial(t, addr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, c.WaitForReady(ctx), context.DeadlineExceeded)
+
+	healthSvr.SetServingStatus(pb.EnosService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, c.WaitForReady(ctx))
+}
+
+func Test_Client_WaitForOperations(t *testing.T) {
+	t.Parallel()
+
+	addr, _ := testStartServer(t, &testServer{pending: 3})
+	c := testDial(t, addr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	refs := []*pb.Ref_Operation{{Id: "a"}, {Id: "b"}}
+	responded := atomic.Int32{}
+	res, err := c.WaitForOperations(ctx, &pb.LaunchScenariosResponse{Operations: refs},
+		WithOnResponse(func(*pb.Operation_Response) { responded.Add(1) }),
+	)
+	require.NoError(t, err)
+	require.Len(t, res.GetResponses(), 2)
+	require.Equal(t, "a", res.GetResponses()[0].GetOp().GetId())
+	require.Equal(t, "b", res.GetResponses()[1].GetOp().GetId())
+	require.Equal(t, int32(2), responded.Load())
+	require.NotNil(t, res.GetTiming())
+	require.NoError(t, OperationsError(false, res))
+
+	events := atomic.Int32{}
+	res, err = c.WaitForOperations(ctx, &pb.LaunchScenariosResponse{Operations: []*pb.Ref_Operation{{Id: "c"}}},
+		WithOnEvent(func(*pb.Operation_Event) { events.Add(1) }),
+	)
+	require.NoError(t, err)
+	require.Len(t, res.GetResponses(), 1)
+	require.Equal(t, pb.Operation_STATUS_COMPLETED, res.GetResponses()[0].GetStatus())
+	require.Equal(t, int32(2), events.Load())
+
+	_, err = c.WaitForOperations(ctx, &pb.LaunchScenariosResponse{Operations: []*pb.Ref_Operation{{Id: "missing"}}})
+	require.ErrorContains(t, err, "operation not found")
+
+	res, err = c.WaitForOperations(ctx, &pb.LaunchScenariosResponse{
+		Decode:     &pb.DecodeResponse{Diagnostics: []*pb.Diagnostic{{Severity: pb.Diagnostic_SEVERITY_ERROR}}},
+		Operations: refs,
+	})
+	require.NoError(t, err)
+	require.Empty(t, res.GetResponses())
+	require.Error(t, OperationsError(false, res))
+}
@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package enosclient
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Filter is a builder of scenario filters, e.g.
+//
+//	enosclient.NewFilter("upgrade").Include("distro", "rhel").Exclude("arch", "arm64")
+//
+// is the filter of 'enos scenario launch upgrade distro:rhel !arch:arm64'.
+type Filter struct {
+	filter *pb.Scenario_Filter
+}
+
+// NewFilter returns a new filter of the scenarios with the name. An empty name matches every
+// scenario.
+func NewFilter(name string) *Filter {
+	return &Filter{filter: &pb.Scenario_Filter{Name: name}}
+}
+
+// AllScenarios returns a new filter of every scenario and variant.
+func AllScenarios() *Filter {
+	return &Filter{filter: &pb.Scenario_Filter{SelectAll: &pb.Scenario_Filter_SelectAll{}}}
+}
+
+// ParseFilter parses a filter in the syntax of the CLI, e.g. "upgrade distro:rhel !arch:arm64".
+func ParseFilter(filter string) (*Filter, error) {
+	sf, err := flightplan.ParseScenarioFilter(strings.Fields(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Filter{filter: sf.Proto()}, nil
+}
+
+// Include only selects the variants whose matrix has the value of the key.
+func (f *Filter) Include(key string, value string) *Filter {
+	f.filter.SelectAll = nil
+	if f.filter.GetInclude() == nil {
+		f.filter.Include = &pb.Matrix_Vector{}
+	}
+	f.filter.Include.Elements = append(f.filter.GetInclude().GetElements(), &pb.Matrix_Element{
+		Key:   key,
+		Value: value,
+	})
+
+	return f
+}
+
+// Exclude never selects the variants whose matrix has the value of the key.
+func (f *Filter) Exclude(key string, value string) *Filter {
+	f.filter.SelectAll = nil
+	f.filter.Exclude = append(f.filter.GetExclude(), &pb.Matrix_Exclude{
+		Mode: pb.Matrix_Exclude_MODE_CONTAINS,
+		Vector: &pb.Matrix_Vector{Elements: []*pb.Matrix_Element{{
+			Key:   key,
+			Value: value,
+		}}},
+	})
+
+	return f
+}
+
+// Union also selects the scenarios and variants of the other filter.
+func (f *Filter) Union(other *Filter) *Filter {
+	f.filter.Union = append(f.filter.GetUnion(), other.Proto())
+
+	return f
+}
+
+// Intersect only selects the scenarios and variants that the other filter also selects.
+func (f *Filter) Intersect(other *Filter) *Filter {
+	f.filter.Intersect = append(f.filter.GetIntersect(), other.Proto())
+
+	return f
+}
+
+// Difference never selects the scenarios and variants of the other filter.
+func (f *Filter) Difference(other *Filter) *Filter {
+	f.filter.Difference = append(f.filter.GetDifference(), other.Proto())
+
+	return f
+}
+
+// Shard only selects the scenarios and variants of one of count stable partitions, where index is
+// one based.
+func (f *Filter) Shard(index int32, count int32) *Filter {
+	f.filter.Shard = &pb.Scenario_Filter_Shard{Index: index, Count: count}
+
+	return f
+}
+
+// Proto returns a copy of the filter as a proto filter, e.g. for the filter of a request.
+func (f *Filter) Proto() *pb.Scenario_Filter {
+	filter, _ := proto.Clone(f.filter).(*pb.Scenario_Filter)
+
+	return filter
+}
+
+// String returns the filter in the syntax of the CLI.
+func (f *Filter) String() string {
+	sf, err := flightplan.NewScenarioFilter(flightplan.WithScenarioFilterDecode(f.filter))
+	if err != nil {
+		return ""
+	}
+
+	return sf.String()
+}
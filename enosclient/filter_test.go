@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package enosclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func Test_Filter(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		filter   *Filter
+		expected string
+	}{
+		"all": {
+			filter: AllScenarios(),
+		},
+		"name": {
+			filter:   NewFilter("upgrade"),
+			expected: "upgrade",
+		},
+		"include and exclude": {
+			filter:   NewFilter("upgrade").Include("distro", "rhel").Exclude("arch", "arm64"),
+			expected: "upgrade distro:rhel !arch:arm64",
+		},
+		"union": {
+			filter:   NewFilter("upgrade").Union(NewFilter("smoke").Include("distro", "rhel")),
+			expected: "(upgrade) | (smoke distro:rhel)",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.expected, test.filter.String())
+		})
+	}
+}
+
+func Test_ParseFilter(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := ParseFilter("upgrade distro:rhel !arch:arm64")
+	require.NoError(t, err)
+	built := NewFilter("upgrade").Include("distro", "rhel").Exclude("arch", "arm64")
+	require.True(t, proto.Equal(built.Proto(), parsed.Proto()), parsed.Proto().String())
+
+	all, err := ParseFilter("")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(AllScenarios().Proto(), all.Proto()))
+
+	_, err = ParseFilter("upgrade smoke")
+	require.Error(t, err)
+}
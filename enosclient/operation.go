@@ -0,0 +1,200 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package enosclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/enos/internal/client"
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// OperationsResponse is the response of an RPC that starts operations, e.g. a
+// *pb.LaunchScenariosResponse or a *pb.RunScenariosResponse.
+type OperationsResponse interface {
+	GetDecode() *pb.DecodeResponse
+	GetDiagnostics() []*pb.Diagnostic
+	GetOperations() []*pb.Ref_Operation
+}
+
+// WaitOpt is a functional option for WaitForOperations.
+type WaitOpt func(*waitOpts)
+
+type waitOpts struct {
+	onEvent    []func(*pb.Operation_Event)
+	onResponse []func(*pb.Operation_Response)
+}
+
+// WithOnEvent sets a function that is called with the events of the operations as they're
+// published. The events of the operations are streamed rather than polled when it's set. It is
+// called concurrently for the events of different operations.
+func WithOnEvent(f func(*pb.Operation_Event)) WaitOpt {
+	return func(o *waitOpts) {
+		o.onEvent = append(o.onEvent, f)
+	}
+}
+
+// WithOnResponse sets a function that is called with the response of each operation as soon as it
+// has finished. It is called concurrently for operations that finish at the same time.
+func WithOnResponse(f func(*pb.Operation_Response)) WaitOpt {
+	return func(o *waitOpts) {
+		o.onResponse = append(o.onResponse, f)
+	}
+}
+
+// IsFinished returns whether an operation with the status has finished.
+func IsFinished(s pb.Operation_Status) bool {
+	switch s {
+	case pb.Operation_STATUS_FAILED, pb.Operation_STATUS_COMPLETED, pb.Operation_STATUS_COMPLETED_WARNING, pb.Operation_STATUS_CANCELLED:
+		return true
+	case pb.Operation_STATUS_UNSPECIFIED, pb.Operation_STATUS_UNKNOWN, pb.Operation_STATUS_QUEUED, pb.Operation_STATUS_WAITING, pb.Operation_STATUS_RUNNING, pb.Operation_STATUS_RUNNING_WARNING:
+		return false
+	default:
+		return false
+	}
+}
+
+// OperationsError returns an error if the operations, or the decoding of their scenarios, have
+// failed. Warnings are failures when failOnWarnings is set.
+func OperationsError(failOnWarnings bool, res *pb.OperationResponses) error {
+	return status.OperationResponses(failOnWarnings, res)
+}
+
+// WaitForOperation polls the operation until it has finished or the context is done and returns
+// its response.
+func (c *Client) WaitForOperation(ctx context.Context, ref *pb.Ref_Operation) (*pb.Operation_Response, error) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		res, err := c.Operation(ctx, &pb.OperationRequest{Op: ref})
+		if err != nil {
+			return nil, fmt.Errorf("getting operation %s: %w", ref.GetId(), err)
+		}
+
+		if res.GetResponse() == nil {
+			if err := diagnostics.ToError(res.GetDiagnostics()...); err != nil {
+				return nil, fmt.Errorf("getting operation %s: %w", ref.GetId(), err)
+			}
+		} else if IsFinished(res.GetResponse().GetStatus()) {
+			return res.GetResponse(), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return res.GetResponse(), fmt.Errorf("waiting for operation %s: %w", ref.GetId(), context.Cause(ctx))
+		case <-ticker.C:
+		}
+	}
+}
+
+// StreamOperation streams the events of the operation to onEvent until it has finished or the
+// context is done and returns its response.
+func (c *Client) StreamOperation(
+	ctx context.Context,
+	ref *pb.Ref_Operation,
+	onEvent func(*pb.Operation_Event),
+) (*pb.Operation_Response, error) {
+	stream, err := c.OperationEventStream(ctx, &pb.OperationEventStreamRequest{Op: ref})
+	if err != nil {
+		return nil, fmt.Errorf("starting event stream of operation %s: %w", ref.GetId(), err)
+	}
+
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("streaming events of operation %s: %w", ref.GetId(), err)
+			}
+
+			break
+		}
+
+		if event := res.GetEvent(); event != nil && onEvent != nil {
+			onEvent(event)
+		}
+	}
+
+	// The stream is closed when the operation has finished, but we poll it in case it was closed
+	// before the final status was published.
+	return c.WaitForOperation(ctx, ref)
+}
+
+// WaitForOperations waits for all of the operations of the response to finish and returns their
+// responses in the order of the operations. If the scenarios of the operations failed to decode the
+// responses are returned as they are. Use OperationsError to determine whether any of the
+// operations have failed.
+func (c *Client) WaitForOperations(
+	ctx context.Context,
+	opRes OperationsResponse,
+	opts ...WaitOpt,
+) (*pb.OperationResponses, error) {
+	wOpts := &waitOpts{}
+	for _, opt := range opts {
+		opt(wOpts)
+	}
+
+	res := &pb.OperationResponses{
+		Decode:      opRes.GetDecode(),
+		Diagnostics: opRes.GetDiagnostics(),
+		Responses:   []*pb.Operation_Response{},
+	}
+
+	if status.HasFailed(false, res, res.GetDecode()) {
+		return res, nil
+	}
+
+	refs := opRes.GetOperations()
+	responses := make([]*pb.Operation_Response, len(refs))
+	errs := make([]error, len(refs))
+	wg := sync.WaitGroup{}
+	for i, ref := range refs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var opResponse *pb.Operation_Response
+			var err error
+			if len(wOpts.onEvent) > 0 {
+				opResponse, err = c.StreamOperation(ctx, ref, func(event *pb.Operation_Event) {
+					for _, f := range wOpts.onEvent {
+						f(event)
+					}
+				})
+			} else {
+				opResponse, err = c.WaitForOperation(ctx, ref)
+			}
+			if err != nil {
+				errs[i] = err
+
+				return
+			}
+
+			responses[i] = opResponse
+			for _, f := range wOpts.onResponse {
+				f(opResponse)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range responses {
+		if r != nil {
+			res.Responses = append(res.GetResponses(), r)
+		}
+	}
+
+	if len(res.GetResponses()) > 1 {
+		res.Timing = client.NewOperationTiming(res.GetResponses())
+	}
+
+	return res, errors.Join(errs...)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package enostest
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Decode decodes the JSON output of a sub-command that was run with --format json into the
+// response message, e.g. a *pb.ListScenariosResponse for 'scenario list'. Unknown fields are
+// discarded so that tests keep working with newer enos binaries.
+func Decode(out []byte, msg proto.Message) error {
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(out, msg); err != nil {
+		return fmt.Errorf("decoding response: %w: %s", err, string(out))
+	}
+
+	return nil
+}
+
+// DecodeStream decodes the JSON output of a sub-command that was run with --format json --stream,
+// i.e. a response message per line. NewMsg returns a new message for each line.
+func DecodeStream[T proto.Message](out []byte, newMsg func() T) ([]T, error) {
+	msgs := []T{}
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return msgs, nil
+	}
+
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		msg := newMsg()
+		if err := Decode(line, msg); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, nil
+}
+
+// DecodeOperationResponses decodes the JSON output of a sub-command that executes operations, e.g.
+// 'scenario launch', and sorts the responses by their scenario.
+func DecodeOperationResponses(out []byte) (*pb.OperationResponses, error) {
+	res := &pb.OperationResponses{}
+	if err := Decode(out, res); err != nil {
+		return nil, err
+	}
+	SortOperationResponses(res.GetResponses())
+
+	return res, nil
+}
+
+// SortOperationResponses sorts the operation responses by their scenario, as operations of
+// scenario variants are executed in parallel and their responses are in no specific order.
+func SortOperationResponses(r []*pb.Operation_Response) {
+	sort.Slice(r, func(i, j int) bool {
+		is := flightplan.NewScenario()
+		is.FromRef(r[i].GetOp().GetScenario())
+
+		js := flightplan.NewScenario()
+		js.FromRef(r[j].GetOp().GetScenario())
+
+		return is.String() < js.String()
+	})
+}
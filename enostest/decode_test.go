@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package enostest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+func Test_Decode(t *testing.T) {
+	t.Parallel()
+
+	res := &pb.ListScenariosResponse{}
+	require.NoError(t, Decode([]byte(`{"scenarios":[{"id":{"name":"test"}}],"unknown":true}`), res))
+	require.Equal(t, "test", res.GetScenarios()[0].GetId().GetName())
+
+	require.ErrorContains(t, Decode([]byte(`not json`), res), "not json")
+}
+
+func Test_DecodeStream(t *testing.T) {
+	t.Parallel()
+
+	msgs, err := DecodeStream([]byte(`{"scenario":{"id":{"name":"a"}}}
+{"scenario":{"id":{"name":"b"}}}
+`), func() *pb.EnosServiceListScenariosResponse {
+		return &pb.EnosServiceListScenariosResponse{}
+	})
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	require.Equal(t, "b", msgs[1].GetScenario().GetId().GetName())
+
+	msgs, err = DecodeStream(nil, func() *pb.EnosServiceListScenariosResponse {
+		return &pb.EnosServiceListScenariosResponse{}
+	})
+	require.NoError(t, err)
+	require.Empty(t, msgs)
+}
+
+func Test_DecodeOperationResponses(t *testing.T) {
+	t.Parallel()
+
+	res, err := DecodeOperationResponses([]byte(`{"responses":[
+{"op":{"scenario":{"id":{"name":"upgrade"}}}},
+{"op":{"scenario":{"id":{"name":"smoke"}}}}
+]}`))
+	require.NoError(t, err)
+	require.Len(t, res.GetResponses(), 2)
+	require.Equal(t, "smoke", res.GetResponses()[0].GetOp().GetScenario().GetId().GetName())
+	require.Equal(t, "upgrade", res.GetResponses()[1].GetOp().GetScenario().GetId().GetName())
+}
@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package enostest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// NewOutDir creates an out directory for the sub-commands of a test, i.e. the value of --out, in a
+// temporary directory that is removed when the test and its subtests complete. Symlinks in the
+// path are resolved as the paths in responses are.
+func NewOutDir(t testing.TB, name string) string {
+	t.Helper()
+
+	outDir := filepath.Join(t.TempDir(), name)
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		t.Fatalf("creating out dir: %s", err)
+	}
+
+	outDir, err := filepath.EvalSymlinks(outDir)
+	if err != nil {
+		t.Fatalf("resolving out dir: %s", err)
+	}
+
+	return outDir
+}
+
+// ScenarioSlug returns the slug of the scenario variant with the name and matrix elements, i.e. the
+// name of its directory in the out directory.
+func ScenarioSlug(name string, elements []*pb.Matrix_Element) string {
+	scenario := flightplan.NewScenario()
+	scenario.Name = name
+	scenario.Variants = flightplan.NewVectorFromProto(&pb.Matrix_Vector{Elements: elements})
+
+	return scenario.Slug()
+}
+
+// ScenarioDir returns the directory of the scenario variant in the out directory, i.e. where its
+// Terraform module is generated.
+func ScenarioDir(outDir string, name string, elements []*pb.Matrix_Element) string {
+	return filepath.Join(outDir, ScenarioSlug(name, elements))
+}
@@ -0,0 +1,20 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package enostest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewOutDir(t *testing.T) {
+	t.Parallel()
+
+	outDir := NewOutDir(t, "test")
+	require.DirExists(t, outDir)
+	require.Equal(t, "test", filepath.Base(outDir))
+	require.Equal(t, filepath.Join(outDir, ScenarioSlug("test", nil)), ScenarioDir(outDir, "test", nil))
+}
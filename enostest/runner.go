@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package enostest is a harness for Go tests that drive Enos scenarios with the Enos CLI. It finds
+// the enos binary, runs its sub-commands, manages their out directories and decodes their
+// responses, e.g.
+//
+//	enos, err := enostest.NewRunner()
+//	require.NoError(t, err)
+//	outDir := enostest.NewOutDir(t, "upgrade")
+//	out, stderr, err := enos.Run(ctx, "scenario launch --chdir ./enos --out "+outDir+" --format json upgrade")
+//	require.NoError(t, err, string(stderr))
+//	res, err := enostest.DecodeOperationResponses(out)
+package enostest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// BinaryPathEnvVar is the environment variable of the path to the enos binary.
+const BinaryPathEnvVar = "ENOS_BINARY_PATH"
+
+// ErrBinaryNotFound is returned when the enos binary could not be found.
+var ErrBinaryNotFound = errors.New("enos binary not found")
+
+// Runner runs Enos CLI sub-commands.
+type Runner struct {
+	// BinPath is the absolute path to the enos binary.
+	BinPath string
+	// Env is the environment of the sub-commands.
+	Env []string
+	// Args are added to the arguments of every sub-command, e.g. --decode-cache=false.
+	Args []string
+}
+
+// RunnerOpt is a functional option for a new Runner.
+type RunnerOpt func(*Runner)
+
+// NewRunner takes optional functional options and returns a new Runner. Unless the path is set
+// with WithBinPath the enos binary is the one of the ENOS_BINARY_PATH environment variable, or the
+// enos binary in the PATH.
+func NewRunner(opts ...RunnerOpt) (*Runner, error) {
+	r := &Runner{
+		Env: os.Environ(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.BinPath == "" {
+		r.BinPath, _ = os.LookupEnv(BinaryPathEnvVar)
+	}
+
+	if r.BinPath == "" {
+		path, err := exec.LookPath("enos")
+		if err != nil {
+			return nil, fmt.Errorf("%w: set %s or add it to the PATH", ErrBinaryNotFound, BinaryPathEnvVar)
+		}
+		r.BinPath = path
+	}
+
+	path, err := filepath.Abs(r.BinPath)
+	if err != nil {
+		return nil, err
+	}
+	r.BinPath = path
+
+	info, err := os.Stat(r.BinPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBinaryNotFound, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is a directory", ErrBinaryNotFound, r.BinPath)
+	}
+
+	return r, nil
+}
+
+// WithBinPath sets the path to the enos binary.
+func WithBinPath(path string) RunnerOpt {
+	return func(r *Runner) {
+		r.BinPath = path
+	}
+}
+
+// WithEnv sets the environment of the sub-commands. By default they inherit the environment of the
+// test.
+func WithEnv(env []string) RunnerOpt {
+	return func(r *Runner) {
+		r.Env = env
+	}
+}
+
+// WithAdditionalEnv adds variables to the environment of the sub-commands, e.g. "FOO=bar".
+func WithAdditionalEnv(env ...string) RunnerOpt {
+	return func(r *Runner) {
+		r.Env = append(r.Env, env...)
+	}
+}
+
+// WithArgs adds arguments to every sub-command, e.g. "--decode-cache=false" so that decoded flight
+// plans aren't cached in the .enos directory of flight plans that don't set an out directory.
+func WithArgs(args ...string) RunnerOpt {
+	return func(r *Runner) {
+		r.Args = append(r.Args, args...)
+	}
+}
+
+// Run runs an Enos sub-command, e.g. "scenario list --chdir ./enos --format json", where the
+// arguments are separated by spaces. Use RunArgs for arguments that contain spaces. It returns the
+// stdout and stderr of the sub-command.
+func (r *Runner) Run(ctx context.Context, subCommand string) ([]byte, []byte, error) {
+	return r.RunArgs(ctx, strings.Split(subCommand, " ")...)
+}
+
+// RunArgs runs an Enos sub-command with the arguments. It returns the stdout and stderr of the
+// sub-command. The gRPC server of the sub-command listens on a random port so that sub-commands can
+// run in parallel.
+func (r *Runner) RunArgs(ctx context.Context, args ...string) ([]byte, []byte, error) {
+	args = append(args, r.Args...)
+	// Don't specify a port so we can execute commands in parallel
+	args = append(args, "--grpc-listen", "http://localhost")
+
+	cmd := exec.CommandContext(ctx, r.BinPath, args...)
+	cmd.Env = r.Env
+
+	stdout, err := cmd.Output()
+	var stderr []byte
+	var exitErr *exec.ExitError
+	if err != nil && errors.As(err, &exitErr) {
+		stderr = exitErr.Stderr
+	}
+
+	return stdout, stderr, err
+}
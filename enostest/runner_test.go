@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package enostest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testFakeEnos writes a fake enos binary that prints its arguments and the FAKE_ENOS environment
+// variable.
+func testFakeEnos(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "enos")
+	require.NoError(t, os.WriteFile(path, []byte(`#!/bin/sh
+echo "$@"
+echo "$FAKE_ENOS" 1>&2
+[ "$1" = "fail" ] && exit 1
+exit 0
+`), 0o755))
+
+	return path
+}
+
+func Test_NewRunner(t *testing.T) {
+	t.Parallel()
+
+	path := testFakeEnos(t)
+
+	r, err := NewRunner(WithBinPath(path))
+	require.NoError(t, err)
+	require.Equal(t, path, r.BinPath)
+
+	_, err = NewRunner(WithBinPath(filepath.Join(t.TempDir(), "missing")))
+	require.ErrorIs(t, err, ErrBinaryNotFound)
+
+	_, err = NewRunner(WithBinPath(t.TempDir()))
+	require.ErrorIs(t, err, ErrBinaryNotFound)
+}
+
+func Test_Runner_Run(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRunner(WithBinPath(testFakeEnos(t)), WithAdditionalEnv("FAKE_ENOS=fake"))
+	require.NoError(t, err)
+
+	stdout, _, err := r.Run(context.Background(), "scenario list --format json")
+	require.NoError(t, err)
+	require.Equal(t, "scenario list --format json --grpc-listen http://localhost", strings.TrimSpace(string(stdout)))
+
+	stdout, _, err = r.RunArgs(context.Background(), "scenario", "list", "test arch:amd64")
+	require.NoError(t, err)
+	require.Equal(t, "scenario list test arch:amd64 --grpc-listen http://localhost", strings.TrimSpace(string(stdout)))
+
+	r.Args = []string{"--decode-cache=false"}
+	stdout, _, err = r.Run(context.Background(), "version")
+	require.NoError(t, err)
+	require.Equal(t, "version --decode-cache=false --grpc-listen http://localhost", strings.TrimSpace(string(stdout)))
+
+	_, stderr, err := r.Run(context.Background(), "fail")
+	require.Error(t, err)
+	require.Equal(t, "fake", strings.TrimSpace(string(stderr)))
+}
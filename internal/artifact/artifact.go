@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package artifact publishes the artifacts, reports, and logs of runs to object storage like S3,
+// GCS, and Azure Blob Storage for the retention of the evidence of runs.
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Store uploads objects to object storage.
+type Store interface {
+	// Upload uploads the contents to the object with the key and returns the URI of the object.
+	Upload(ctx context.Context, key string, contentType string, body io.Reader, size int64) (string, error)
+}
+
+// File is a local file to publish.
+type File struct {
+	// Path is the local path of the file.
+	Path string
+	// Name is the name of the object relative to the prefix of the run.
+	Name string
+}
+
+// Publisher publishes files to a store under the prefix of a run, i.e. <prefix>/<run id>/<name>.
+type Publisher struct {
+	store  Store
+	prefix string
+	runID  string
+}
+
+// PublisherOpt is a functional option.
+type PublisherOpt func(*Publisher)
+
+// NewPublisher takes a store and options and returns a new Publisher. Unless set, the ID of the
+// run is generated from the current time.
+func NewPublisher(store Store, opts ...PublisherOpt) *Publisher {
+	p := &Publisher{
+		store: store,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.runID == "" {
+		p.runID = NewRunID(time.Now())
+	}
+
+	return p
+}
+
+// WithPublisherPrefix sets the prefix of the keys of the objects of every run.
+func WithPublisherPrefix(prefix string) PublisherOpt {
+	return func(p *Publisher) {
+		p.prefix = strings.Trim(prefix, "/")
+	}
+}
+
+// WithPublisherRunID sets the ID of the run.
+func WithPublisherRunID(id string) PublisherOpt {
+	return func(p *Publisher) {
+		p.runID = strings.Trim(id, "/")
+	}
+}
+
+// RunID returns the ID of the run.
+func (p *Publisher) RunID() string {
+	return p.runID
+}
+
+// Key returns the key of the object of the name.
+func (p *Publisher) Key(name string) string {
+	return path.Join(p.prefix, p.runID, strings.TrimLeft(name, "/"))
+}
+
+// Publish uploads the files and returns the artifacts of the files that have been uploaded. Every
+// file is uploaded even if others fail.
+func (p *Publisher) Publish(ctx context.Context, files ...*File) ([]*pb.Artifact, error) {
+	artifacts := []*pb.Artifact{}
+	var err error
+
+	for _, file := range files {
+		uri, uploadErr := p.publish(ctx, file)
+		if uploadErr != nil {
+			err = errors.Join(err, fmt.Errorf("uploading %s: %w", file.Path, uploadErr))
+
+			continue
+		}
+
+		artifacts = append(artifacts, &pb.Artifact{Path: file.Path, Uri: uri})
+	}
+
+	return artifacts, err
+}
+
+// PublishContents uploads the contents to the object of the name and returns its artifact.
+func (p *Publisher) PublishContents(ctx context.Context, name string, contents []byte) (*pb.Artifact, error) {
+	uri, err := p.store.Upload(ctx, p.Key(name), contentType(name), bytes.NewReader(contents), int64(len(contents)))
+	if err != nil {
+		return nil, fmt.Errorf("uploading %s: %w", name, err)
+	}
+
+	return &pb.Artifact{Path: name, Uri: uri}, nil
+}
+
+func (p *Publisher) publish(ctx context.Context, file *File) (string, error) {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	return p.store.Upload(ctx, p.Key(file.Name), contentType(file.Name), f, info.Size())
+}
+
+// NewRunID returns a new ID of a run that started at the time, e.g. 20240102T150405Z-1a2b3c4d.
+// IDs sort by the time of their runs.
+func NewRunID(now time.Time) string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+
+	return now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
+
+// contentType returns the content type of the object of the name.
+func contentType(name string) string {
+	switch ext := path.Ext(name); ext {
+	case ".log", ".hcl", ".tf", ".tfvars":
+		return "text/plain; charset=utf-8"
+	default:
+		if typ := mime.TypeByExtension(ext); typ != "" {
+			return typ
+		}
+
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package artifact
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// memStore is a store that keeps uploaded objects in memory.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+	fail    map[string]bool
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		objects: map[string]string{},
+		types:   map[string]string{},
+		fail:    map[string]bool{},
+	}
+}
+
+func (m *memStore) Upload(ctx context.Context, key string, contentType string, body io.Reader, size int64) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.fail[key] {
+		return "", errors.New("access denied")
+	}
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	if int64(len(b)) != size {
+		return "", errors.New("size does not match the body")
+	}
+
+	m.objects[key] = string(b)
+	m.types[key] = contentType
+
+	return "mem://" + key, nil
+}
+
+// Test_Publisher_Publish tests publishing files and contents under the prefix of the run.
+func Test_Publisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "enos.log")
+	missing := filepath.Join(dir, "missing.tfstate")
+	denied := filepath.Join(dir, "denied.txt")
+	require.NoError(t, os.WriteFile(logFile, []byte("log line\n"), 0o644))
+	require.NoError(t, os.WriteFile(denied, []byte("secret"), 0o644))
+
+	store := newMemStore()
+	store.fail["nightly/run-1/denied.txt"] = true
+	pub := NewPublisher(store, WithPublisherPrefix("/nightly/"), WithPublisherRunID("run-1"))
+	require.Equal(t, "run-1", pub.RunID())
+	require.Equal(t, "nightly/run-1/logs/enos.log", pub.Key("/logs/enos.log"))
+
+	artifacts, err := pub.Publish(context.Background(),
+		&File{Path: logFile, Name: "logs/enos.log"},
+		&File{Path: missing, Name: "missing.tfstate"},
+		&File{Path: denied, Name: "denied.txt"},
+	)
+	require.Error(t, err)
+	require.ErrorContains(t, err, "uploading "+missing)
+	require.ErrorContains(t, err, "uploading "+denied+": access denied")
+	require.Equal(t, []*pb.Artifact{{Path: logFile, Uri: "mem://nightly/run-1/logs/enos.log"}}, artifacts)
+	require.Equal(t, "log line\n", store.objects["nightly/run-1/logs/enos.log"])
+	require.Equal(t, "text/plain; charset=utf-8", store.types["nightly/run-1/logs/enos.log"])
+
+	report, err := pub.PublishContents(context.Background(), "report.json", []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, &pb.Artifact{Path: "report.json", Uri: "mem://nightly/run-1/report.json"}, report)
+	require.Equal(t, "application/json", store.types["nightly/run-1/report.json"])
+}
+
+// Test_NewRunID tests that run IDs are generated from the time of the run.
+func Test_NewRunID(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("PST", -8*60*60))
+	id := NewRunID(now)
+	require.Regexp(t, regexp.MustCompile(`^20240102T230405Z-[0-9a-f]{8}$`), id)
+	require.NotEqual(t, id, NewRunID(now))
+	require.Regexp(t, regexp.MustCompile(`^\d{8}T\d{6}Z-[0-9a-f]{8}$`), NewPublisher(newMemStore()).RunID())
+}
@@ -0,0 +1,210 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package artifact
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureAPIVersion is the version of the Blob Storage API of requests.
+const azureAPIVersion = "2021-08-06"
+
+var _ Store = (*Azure)(nil)
+
+// Azure uploads block blobs to an Azure Blob Storage container. Requests are authorized with a
+// shared access signature or signed with the shared key of the storage account.
+type Azure struct {
+	account    string
+	container  string
+	sasToken   string
+	accountKey string
+	endpoint   string
+	client     *http.Client
+}
+
+// AzureOpt is a functional option.
+type AzureOpt func(*Azure)
+
+// NewAzure takes options and returns a new Azure store.
+func NewAzure(opts ...AzureOpt) *Azure {
+	a := &Azure{
+		client: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// WithAzureAccount sets the storage account.
+func WithAzureAccount(account string) AzureOpt {
+	return func(a *Azure) {
+		a.account = account
+	}
+}
+
+// WithAzureContainer sets the container.
+func WithAzureContainer(container string) AzureOpt {
+	return func(a *Azure) {
+		a.container = container
+	}
+}
+
+// WithAzureSASToken sets the shared access signature.
+func WithAzureSASToken(token string) AzureOpt {
+	return func(a *Azure) {
+		a.sasToken = strings.TrimPrefix(token, "?")
+	}
+}
+
+// WithAzureAccountKey sets the base64 encoded shared key of the storage account.
+func WithAzureAccountKey(key string) AzureOpt {
+	return func(a *Azure) {
+		a.accountKey = key
+	}
+}
+
+// WithAzureEndpoint sets the URL of the blob service, e.g. of Azurite. It defaults to
+// https://<account>.blob.core.windows.net.
+func WithAzureEndpoint(endpoint string) AzureOpt {
+	return func(a *Azure) {
+		a.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// WithAzureHTTPClient sets the HTTP client.
+func WithAzureHTTPClient(client *http.Client) AzureOpt {
+	return func(a *Azure) {
+		a.client = client
+	}
+}
+
+// Upload uploads the contents to the block blob with the key and returns its URL.
+func (a *Azure) Upload(ctx context.Context, key string, contentType string, body io.Reader, size int64) (string, error) {
+	if a.account == "" {
+		return "", errors.New("no azure storage account has been configured")
+	}
+	if a.container == "" {
+		return "", errors.New("no azure container has been configured")
+	}
+	if a.sasToken == "" && a.accountKey == "" {
+		return "", errors.New("no azure sas token or account key has been configured")
+	}
+
+	blobURL := a.blobURL(key)
+	reqURL := blobURL
+	if a.sasToken != "" {
+		reqURL += "?" + a.sasToken
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, reqURL, body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+
+	if a.sasToken == "" {
+		sig, err := a.sharedKeySignature(req)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "SharedKey "+a.account+":"+sig)
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+
+		return "", fmt.Errorf("azure returned %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return blobURL, nil
+}
+
+// blobURL returns the URL of the blob of the key without any shared access signature.
+func (a *Azure) blobURL(key string) string {
+	endpoint := a.endpoint
+	if endpoint == "" {
+		endpoint = "https://" + a.account + ".blob.core.windows.net"
+	}
+
+	return endpoint + "/" + url.PathEscape(a.container) + (&url.URL{Path: "/" + key}).EscapedPath()
+}
+
+// sharedKeySignature returns the Shared Key signature of the request.
+// See https://learn.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (a *Azure) sharedKeySignature(req *http.Request) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(a.accountKey)
+	if err != nil {
+		return "", fmt.Errorf("decoding azure account key: %w", err)
+	}
+
+	length := ""
+	if req.ContentLength > 0 {
+		length = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	msHeaders := []string{}
+	for name := range req.Header {
+		if name := strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			msHeaders = append(msHeaders, name)
+		}
+	}
+	sort.Strings(msHeaders)
+
+	canonicalHeaders := strings.Builder{}
+	for _, name := range msHeaders {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	// Blob service URLs either have the account in the host or, for emulators like Azurite, as the
+	// first segment of the path.
+	resource := req.URL.EscapedPath()
+	if !strings.HasPrefix(resource, "/"+a.account+"/") {
+		resource = "/" + a.account + resource
+	}
+
+	toSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		length,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, which is set with x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + canonicalHeaders.String() + resource
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(toSign))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package artifact
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Azure_Upload tests uploading block blobs with a shared access signature and a shared key.
+func Test_Azure_Upload(t *testing.T) {
+	t.Parallel()
+
+	accountKey := base64.StdEncoding.EncodeToString([]byte("account-key"))
+	var req *http.Request
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+
+		if !strings.HasPrefix(r.URL.Path, "/enos/runs/") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("ContainerNotFound"))
+
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	azure := NewAzure(
+		WithAzureAccount("enos"),
+		WithAzureContainer("runs"),
+		WithAzureSASToken("?sv=2021-08-06&sig=abc"),
+		WithAzureEndpoint(srv.URL+"/enos/"),
+	)
+	uri, err := azure.Upload(context.Background(), "nightly/run 1/enos.log", "text/plain", strings.NewReader("log"), 3)
+	require.NoError(t, err)
+	require.Equal(t, srv.URL+"/enos/runs/nightly/run%201/enos.log", uri)
+	require.Equal(t, http.MethodPut, req.Method)
+	require.Equal(t, "sv=2021-08-06&sig=abc", req.URL.RawQuery)
+	require.Equal(t, "BlockBlob", req.Header.Get("x-ms-blob-type"))
+	require.Equal(t, azureAPIVersion, req.Header.Get("x-ms-version"))
+	require.Empty(t, req.Header.Get("Authorization"))
+	require.Equal(t, "log", body)
+
+	azure = NewAzure(
+		WithAzureAccount("enos"),
+		WithAzureContainer("runs"),
+		WithAzureAccountKey(accountKey),
+		WithAzureEndpoint(srv.URL+"/enos"),
+	)
+	_, err = azure.Upload(context.Background(), "report.json", "application/json", strings.NewReader(`{}`), 2)
+	require.NoError(t, err)
+
+	toSign := "PUT\n\n\n2\n\napplication/json\n\n\n\n\n\n\n" +
+		"x-ms-blob-type:BlockBlob\n" +
+		"x-ms-date:" + req.Header.Get("x-ms-date") + "\n" +
+		"x-ms-version:" + azureAPIVersion + "\n" +
+		"/enos/runs/report.json"
+	mac := hmac.New(sha256.New, []byte("account-key"))
+	mac.Write([]byte(toSign))
+	require.Equal(t, "SharedKey enos:"+base64.StdEncoding.EncodeToString(mac.Sum(nil)), req.Header.Get("Authorization"))
+
+	azure = NewAzure(
+		WithAzureAccount("enos"),
+		WithAzureContainer("missing"),
+		WithAzureAccountKey(accountKey),
+		WithAzureEndpoint(srv.URL+"/enos"),
+	)
+	_, err = azure.Upload(context.Background(), "report.json", "application/json", strings.NewReader(`{}`), 2)
+	require.ErrorContains(t, err, "azure returned 404 Not Found: ContainerNotFound")
+
+	_, err = NewAzure(WithAzureAccount("enos"), WithAzureContainer("runs")).
+		Upload(context.Background(), "report.json", "application/json", strings.NewReader(`{}`), 2)
+	require.ErrorContains(t, err, "no azure sas token or account key has been configured")
+}
@@ -0,0 +1,313 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package artifact
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultGCSAPIURL is the URL of the Cloud Storage API.
+	DefaultGCSAPIURL = "https://storage.googleapis.com"
+	// DefaultGCSMetadataURL is the URL of the metadata server of Google Cloud instances.
+	DefaultGCSMetadataURL = "http://metadata.google.internal"
+	// gcsScope is the OAuth scope of uploads.
+	gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+var _ Store = (*GCS)(nil)
+
+// GCS uploads objects to a Google Cloud Storage bucket. Requests are authorized with an access
+// token, a token of a service account key file, or a token of the metadata server of the instance,
+// in that order.
+type GCS struct {
+	bucket          string
+	token           string
+	credentialsFile string
+	apiURL          string
+	metadataURL     string
+	client          *http.Client
+
+	mu          sync.Mutex
+	cachedToken string
+	expires     time.Time
+}
+
+// GCSOpt is a functional option.
+type GCSOpt func(*GCS)
+
+// NewGCS takes options and returns a new GCS store.
+func NewGCS(opts ...GCSOpt) *GCS {
+	g := &GCS{
+		apiURL:      DefaultGCSAPIURL,
+		metadataURL: DefaultGCSMetadataURL,
+		client:      http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// WithGCSBucket sets the bucket.
+func WithGCSBucket(bucket string) GCSOpt {
+	return func(g *GCS) {
+		g.bucket = bucket
+	}
+}
+
+// WithGCSToken sets the OAuth access token.
+func WithGCSToken(token string) GCSOpt {
+	return func(g *GCS) {
+		g.token = token
+	}
+}
+
+// WithGCSCredentialsFile sets the path of a service account key file.
+func WithGCSCredentialsFile(path string) GCSOpt {
+	return func(g *GCS) {
+		g.credentialsFile = path
+	}
+}
+
+// WithGCSAPIURL sets the URL of the Cloud Storage API.
+func WithGCSAPIURL(url string) GCSOpt {
+	return func(g *GCS) {
+		if url != "" {
+			g.apiURL = strings.TrimSuffix(url, "/")
+		}
+	}
+}
+
+// WithGCSMetadataURL sets the URL of the metadata server.
+func WithGCSMetadataURL(url string) GCSOpt {
+	return func(g *GCS) {
+		if url != "" {
+			g.metadataURL = strings.TrimSuffix(url, "/")
+		}
+	}
+}
+
+// WithGCSHTTPClient sets the HTTP client.
+func WithGCSHTTPClient(client *http.Client) GCSOpt {
+	return func(g *GCS) {
+		g.client = client
+	}
+}
+
+type gcsServiceAccount struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+type gcsToken struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Upload uploads the contents to the object with the key and returns its gs:// URI.
+func (g *GCS) Upload(ctx context.Context, key string, contentType string, body io.Reader, size int64) (string, error) {
+	if g.bucket == "" {
+		return "", errors.New("no gcs bucket has been configured")
+	}
+
+	token, err := g.authToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		g.apiURL, url.PathEscape(g.bucket), url.QueryEscape(key),
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if err := g.do(req, nil); err != nil {
+		return "", err
+	}
+
+	return "gs://" + g.bucket + "/" + key, nil
+}
+
+// authToken returns the access token of requests. Tokens of service accounts and the metadata
+// server are cached until shortly before they expire.
+func (g *GCS) authToken(ctx context.Context) (string, error) {
+	if g.token != "" {
+		return g.token, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.cachedToken != "" && time.Now().Before(g.expires) {
+		return g.cachedToken, nil
+	}
+
+	var token *gcsToken
+	var err error
+	if g.credentialsFile != "" {
+		token, err = g.serviceAccountToken(ctx)
+	} else {
+		token, err = g.metadataToken(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	g.cachedToken = token.AccessToken
+	g.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+
+	return g.cachedToken, nil
+}
+
+// serviceAccountToken exchanges a JWT that is signed with the key of the service account for an
+// access token.
+func (g *GCS) serviceAccountToken(ctx context.Context) (*gcsToken, error) {
+	b, err := os.ReadFile(g.credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading gcs credentials: %w", err)
+	}
+
+	sa := &gcsServiceAccount{}
+	if err := json.Unmarshal(b, sa); err != nil {
+		return nil, fmt.Errorf("decoding gcs credentials: %w", err)
+	}
+	if sa.Type != "service_account" {
+		return nil, fmt.Errorf("unsupported gcs credentials type %q, expected a service_account key", sa.Type)
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	assertion, err := gcsJWT(sa, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sa.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	token := &gcsToken{}
+	if err := g.do(req, token); err != nil {
+		return nil, fmt.Errorf("retrieving gcs access token: %w", err)
+	}
+
+	return token, nil
+}
+
+// metadataToken returns the access token of the service account of the instance.
+func (g *GCS) metadataToken(ctx context.Context) (*gcsToken, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		g.metadataURL+"/computeMetadata/v1/instance/service-accounts/default/token", nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	token := &gcsToken{}
+	if err := g.do(req, token); err != nil {
+		return nil, fmt.Errorf("retrieving gcs access token from the metadata server: %w", err)
+	}
+
+	return token, nil
+}
+
+// do sends the request and decodes the response into out if it is not nil.
+func (g *GCS) do(req *http.Request, out any) error {
+	res, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+
+		return fmt.Errorf("gcs returned %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// gcsJWT returns the JWT that is exchanged for an access token of the service account.
+func gcsJWT(sa *gcsServiceAccount, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return "", errors.New("gcs service account private key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("parsing gcs service account private key: %w", err)
+		}
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("gcs service account private key is not an RSA key")
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   sa.ClientEmail,
+		"scope": gcsScope,
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(nil, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing gcs jwt: %w", err)
+	}
+
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package artifact
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testGCSServer returns a test Cloud Storage API, token endpoint, and metadata server, and a func
+// that returns the authorization headers and bodies of the uploads it received.
+func testGCSServer(t *testing.T, key *rsa.PublicKey) (*httptest.Server, func() map[string][2]string) {
+	t.Helper()
+
+	mu := sync.Mutex{}
+	uploads := map[string][2]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+			parts := strings.Split(r.Form.Get("assertion"), ".")
+			require.Len(t, parts, 3)
+			sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+			require.NoError(t, err)
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			require.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig))
+			claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+			require.NoError(t, err)
+			require.Contains(t, string(claims), `"iss":"enos@example.iam.gserviceaccount.com"`)
+
+			_, _ = w.Write([]byte(`{"access_token":"sa-token","expires_in":3600}`))
+		case r.URL.Path == "/computeMetadata/v1/instance/service-accounts/default/token":
+			require.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+			_, _ = w.Write([]byte(`{"access_token":"metadata-token","expires_in":3600}`))
+		case r.URL.Path == "/upload/storage/v1/b/enos-runs/o":
+			require.Equal(t, "media", r.URL.Query().Get("uploadType"))
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			mu.Lock()
+			uploads[r.URL.Query().Get("name")] = [2]string{r.Header.Get("Authorization"), string(b)}
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"The specified bucket does not exist."}}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() map[string][2]string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return uploads
+	}
+}
+
+// Test_GCS_Upload tests uploading objects with every kind of credentials.
+func Test_GCS_Upload(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	srv, uploads := testGCSServer(t, &key.PublicKey)
+
+	credsFile := filepath.Join(t.TempDir(), "credentials.json")
+	creds, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "enos@example.iam.gserviceaccount.com",
+		"private_key": string(pem.EncodeToMemory(&pem.Block{
+			Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})),
+		"token_uri": srv.URL + "/token",
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(credsFile, creds, 0o600))
+
+	for name, test := range map[string]struct {
+		opts []GCSOpt
+		auth string
+	}{
+		"token": {
+			[]GCSOpt{WithGCSToken("static-token")},
+			"Bearer static-token",
+		},
+		"service account": {
+			[]GCSOpt{WithGCSCredentialsFile(credsFile)},
+			"Bearer sa-token",
+		},
+		"metadata": {
+			[]GCSOpt{WithGCSMetadataURL(srv.URL)},
+			"Bearer metadata-token",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			key := "nightly/" + name + "/report.json"
+			gcs := NewGCS(append([]GCSOpt{WithGCSBucket("enos-runs"), WithGCSAPIURL(srv.URL)}, test.opts...)...)
+			uri, err := gcs.Upload(context.Background(), key, "application/json", strings.NewReader(`{}`), 2)
+			require.NoError(t, err)
+			require.Equal(t, "gs://enos-runs/"+key, uri)
+			require.Equal(t, [2]string{test.auth, `{}`}, uploads()[key])
+		})
+	}
+
+	gcs := NewGCS(WithGCSBucket("missing"), WithGCSAPIURL(srv.URL), WithGCSToken("static-token"))
+	_, err = gcs.Upload(context.Background(), "report.json", "application/json", strings.NewReader(`{}`), 2)
+	require.ErrorContains(t, err, "gcs returned 404 Not Found")
+
+	gcs = NewGCS(WithGCSBucket("enos-runs"), WithGCSAPIURL(srv.URL), WithGCSCredentialsFile(filepath.Join(t.TempDir(), "missing.json")))
+	_, err = gcs.Upload(context.Background(), "report.json", "application/json", strings.NewReader(`{}`), 2)
+	require.ErrorContains(t, err, "reading gcs credentials")
+}
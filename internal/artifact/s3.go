@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// s3UnsignedPayload is the payload hash of uploads that are streamed rather than hashed.
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+var _ Store = (*S3)(nil)
+
+// S3 uploads objects to an S3 bucket. Credentials and the region are resolved like the AWS CLI
+// does, e.g. from AWS_ACCESS_KEY_ID or AWS_PROFILE, unless they are set.
+type S3 struct {
+	bucket      string
+	region      string
+	endpoint    string
+	credentials aws.CredentialsProvider
+	client      *http.Client
+}
+
+// S3Opt is a functional option.
+type S3Opt func(*S3)
+
+// NewS3 takes options and returns a new S3 store.
+func NewS3(opts ...S3Opt) *S3 {
+	s := &S3{
+		client: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithS3Bucket sets the bucket.
+func WithS3Bucket(bucket string) S3Opt {
+	return func(s *S3) {
+		s.bucket = bucket
+	}
+}
+
+// WithS3Region sets the region of the bucket.
+func WithS3Region(region string) S3Opt {
+	return func(s *S3) {
+		s.region = region
+	}
+}
+
+// WithS3Endpoint sets the URL of an S3 compatible API, e.g. of MinIO. Objects of custom endpoints
+// are addressed by path, i.e. <endpoint>/<bucket>/<key>.
+func WithS3Endpoint(endpoint string) S3Opt {
+	return func(s *S3) {
+		s.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// WithS3Credentials sets the credentials.
+func WithS3Credentials(creds aws.CredentialsProvider) S3Opt {
+	return func(s *S3) {
+		s.credentials = creds
+	}
+}
+
+// WithS3HTTPClient sets the HTTP client.
+func WithS3HTTPClient(client *http.Client) S3Opt {
+	return func(s *S3) {
+		s.client = client
+	}
+}
+
+// Upload uploads the contents to the object with the key and returns its s3:// URI.
+func (s *S3) Upload(ctx context.Context, key string, contentType string, body io.Reader, size int64) (string, error) {
+	if s.bucket == "" {
+		return "", errors.New("no s3 bucket has been configured")
+	}
+
+	if err := s.resolveConfig(ctx); err != nil {
+		return "", err
+	}
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("retrieving aws credentials: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	err = v4.NewSigner().SignHTTP(ctx, creds, req, s3UnsignedPayload, "s3", s.region, time.Now(),
+		func(o *v4.SignerOptions) {
+			// S3 object keys are escaped once rather than twice like other services.
+			o.DisableURIPathEscaping = true
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("signing s3 request: %w", err)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+
+		return "", fmt.Errorf("s3 returned %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return "s3://" + s.bucket + "/" + key, nil
+}
+
+// resolveConfig resolves the region and credentials that have not been set from the default AWS
+// configuration.
+func (s *S3) resolveConfig(ctx context.Context) error {
+	if s.credentials != nil && s.region != "" {
+		return nil
+	}
+
+	opts := []func(*config.LoadOptions) error{}
+	if s.region != "" {
+		opts = append(opts, config.WithRegion(s.region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("loading aws configuration: %w", err)
+	}
+
+	if s.region == "" {
+		s.region = cfg.Region
+	}
+	if s.region == "" {
+		return errors.New("no s3 region has been configured")
+	}
+
+	if s.credentials == nil {
+		s.credentials = cfg.Credentials
+	}
+	if s.credentials == nil {
+		return errors.New("no aws credentials have been configured")
+	}
+
+	return nil
+}
+
+// objectURL returns the URL of the object of the key.
+func (s *S3) objectURL(key string) string {
+	path := (&url.URL{Path: "/" + key}).EscapedPath()
+	if s.endpoint != "" {
+		return s.endpoint + "/" + url.PathEscape(s.bucket) + path
+	}
+
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com%s", s.bucket, s.region, path)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package artifact
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_S3_Upload tests uploading an object to an S3 compatible endpoint.
+func Test_S3_Upload(t *testing.T) {
+	t.Parallel()
+
+	var path, auth, sha, contentType, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		auth = r.Header.Get("Authorization")
+		sha = r.Header.Get("X-Amz-Content-Sha256")
+		contentType = r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+
+		if !strings.HasPrefix(r.URL.Path, "/enos-runs/") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("<Error><Code>NoSuchBucket</Code></Error>"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	creds := aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+	})
+
+	s3 := NewS3(
+		WithS3Bucket("enos-runs"),
+		WithS3Region("us-west-2"),
+		WithS3Endpoint(srv.URL+"/"),
+		WithS3Credentials(creds),
+	)
+	uri, err := s3.Upload(context.Background(), "nightly/run 1/report.json", "application/json",
+		strings.NewReader(`{}`), 2,
+	)
+	require.NoError(t, err)
+	require.Equal(t, "s3://enos-runs/nightly/run 1/report.json", uri)
+	require.Equal(t, "/enos-runs/nightly/run%201/report.json", path)
+	require.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"), auth)
+	require.Contains(t, auth, "/us-west-2/s3/aws4_request")
+	require.Equal(t, s3UnsignedPayload, sha)
+	require.Equal(t, "application/json", contentType)
+	require.Equal(t, `{}`, body)
+
+	s3 = NewS3(
+		WithS3Bucket("missing"),
+		WithS3Region("us-west-2"),
+		WithS3Endpoint(srv.URL),
+		WithS3Credentials(creds),
+	)
+	_, err = s3.Upload(context.Background(), "report.json", "application/json", strings.NewReader(`{}`), 2)
+	require.ErrorContains(t, err, "s3 returned 404 Not Found: <Error><Code>NoSuchBucket</Code></Error>")
+
+	_, err = NewS3().Upload(context.Background(), "report.json", "application/json", strings.NewReader(`{}`), 2)
+	require.ErrorContains(t, err, "no s3 bucket has been configured")
+}
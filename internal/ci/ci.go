@@ -0,0 +1,217 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package ci detects the CI environment that enos is running in and tailors output to it, e.g.
+// with collapsible log groups, annotations, and summaries of runs.
+package ci
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Detect returns the CI environment of the environment variables. It returns
+// CI_UNSPECIFIED if we're not running in a supported CI environment.
+func Detect(getenv func(string) string) pb.UI_Settings_CI {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		return pb.UI_Settings_CI_GITHUB_ACTIONS
+	case getenv("GITLAB_CI") == "true":
+		return pb.UI_Settings_CI_GITLAB
+	case getenv("CIRCLECI") == "true":
+		return pb.UI_Settings_CI_CIRCLECI
+	case getenv("BUILDKITE") == "true":
+		return pb.UI_Settings_CI_BUILDKITE
+	default:
+		return pb.UI_Settings_CI_UNSPECIFIED
+	}
+}
+
+// Parse parses the name of a CI environment. "auto" detects the CI environment from the
+// environment variables and "none" disables it.
+func Parse(name string, getenv func(string) string) (pb.UI_Settings_CI, error) {
+	switch strings.ToLower(name) {
+	case "auto", "":
+		return Detect(getenv), nil
+	case "none":
+		return pb.UI_Settings_CI_UNSPECIFIED, nil
+	case "github-actions":
+		return pb.UI_Settings_CI_GITHUB_ACTIONS, nil
+	case "gitlab":
+		return pb.UI_Settings_CI_GITLAB, nil
+	case "circleci":
+		return pb.UI_Settings_CI_CIRCLECI, nil
+	case "buildkite":
+		return pb.UI_Settings_CI_BUILDKITE, nil
+	default:
+		return pb.UI_Settings_CI_UNSPECIFIED, fmt.Errorf(
+			"unsupported CI environment %q, expected one of auto, none, github-actions, gitlab, circleci, or buildkite", name,
+		)
+	}
+}
+
+// Workspace returns the directory of the checkout of the repository in the CI environment.
+func Workspace(ci pb.UI_Settings_CI, getenv func(string) string) string {
+	switch ci {
+	case pb.UI_Settings_CI_GITHUB_ACTIONS:
+		return getenv("GITHUB_WORKSPACE")
+	case pb.UI_Settings_CI_GITLAB:
+		return getenv("CI_PROJECT_DIR")
+	case pb.UI_Settings_CI_CIRCLECI:
+		return getenv("CIRCLE_WORKING_DIRECTORY")
+	case pb.UI_Settings_CI_BUILDKITE:
+		return getenv("BUILDKITE_BUILD_CHECKOUT_PATH")
+	default:
+		return ""
+	}
+}
+
+// StartGroup returns the line that starts a collapsible group of log lines with the title, or an
+// empty string if the CI environment doesn't support them. Groups that are expanded are shown
+// expanded if the CI environment supports it, e.g. the output of failed scenarios. The id is
+// used to match the end of the group where that is required.
+func StartGroup(ci pb.UI_Settings_CI, id string, title string, expanded bool, now time.Time) string {
+	switch ci {
+	case pb.UI_Settings_CI_GITHUB_ACTIONS:
+		return "::group::" + escapeData(title)
+	case pb.UI_Settings_CI_GITLAB:
+		collapsed := "[collapsed=true]"
+		if expanded {
+			collapsed = ""
+		}
+
+		return fmt.Sprintf("\x1b[0Ksection_start:%d:%s%s\r\x1b[0K%s",
+			now.Unix(), sectionName(id), collapsed, oneLine(title),
+		)
+	case pb.UI_Settings_CI_BUILDKITE:
+		if expanded {
+			return "+++ " + oneLine(title)
+		}
+
+		return "--- " + oneLine(title)
+	default:
+		return ""
+	}
+}
+
+// EndGroup returns the line that ends the collapsible group with the id, or an empty string if
+// the CI environment doesn't support them or groups end when the next one starts.
+func EndGroup(ci pb.UI_Settings_CI, id string, now time.Time) string {
+	switch ci {
+	case pb.UI_Settings_CI_GITHUB_ACTIONS:
+		return "::endgroup::"
+	case pb.UI_Settings_CI_GITLAB:
+		return fmt.Sprintf("\x1b[0Ksection_end:%d:%s\r\x1b[0K", now.Unix(), sectionName(id))
+	default:
+		return ""
+	}
+}
+
+// Annotation returns the workflow command that annotates the file of the diagnostic, or an
+// empty string if the CI environment doesn't support annotations or the diagnostic isn't in a
+// file of the workspace. The path of the file is relative to the workspace.
+func Annotation(ci pb.UI_Settings_CI, diag *pb.Diagnostic, workspace string) string {
+	if ci != pb.UI_Settings_CI_GITHUB_ACTIONS || diag == nil {
+		return ""
+	}
+
+	var cmd string
+	switch diag.GetSeverity() {
+	case pb.Diagnostic_SEVERITY_ERROR:
+		cmd = "error"
+	case pb.Diagnostic_SEVERITY_WARNING:
+		cmd = "warning"
+	default:
+		return ""
+	}
+
+	rng := diag.GetRange()
+	path, ok := workspacePath(rng.GetFilename(), workspace)
+	if !ok || rng.GetStart().GetLine() < 1 {
+		return ""
+	}
+
+	props := []string{
+		"file=" + escapeProperty(path),
+		"line=" + strconv.FormatInt(rng.GetStart().GetLine(), 10),
+	}
+	endLine := max(rng.GetEnd().GetLine(), rng.GetStart().GetLine())
+	if endLine != rng.GetStart().GetLine() {
+		props = append(props, "endLine="+strconv.FormatInt(endLine, 10))
+	} else if rng.GetStart().GetColumn() > 0 {
+		// Columns can only be set for annotations of a single line.
+		props = append(props, "col="+strconv.FormatInt(rng.GetStart().GetColumn(), 10))
+		if rng.GetEnd().GetColumn() > 0 {
+			props = append(props, "endColumn="+strconv.FormatInt(rng.GetEnd().GetColumn(), 10))
+		}
+	}
+	props = append(props, "title="+escapeProperty(diag.GetSummary()))
+
+	msg := diag.GetDetail()
+	if msg == "" {
+		msg = diag.GetSummary()
+	}
+
+	return fmt.Sprintf("::%s %s::%s", cmd, strings.Join(props, ","), escapeData(msg))
+}
+
+// workspacePath returns the path of the file relative to the workspace, or false if the file is
+// not in the workspace. Relative paths are returned as they are.
+func workspacePath(filename string, workspace string) (string, bool) {
+	if filename == "" {
+		return "", false
+	}
+
+	if !filepath.IsAbs(filename) {
+		return filepath.ToSlash(filename), true
+	}
+
+	if workspace == "" {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(workspace, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// sectionName returns the id as a GitLab section name, which may only contain letters, numbers,
+// and "_", ".", or "-".
+func sectionName(id string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, id)
+	if name == "" {
+		return "enos"
+	}
+
+	return name
+}
+
+// oneLine replaces the line breaks of text so that it stays on a single line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// escapeData escapes the data of a GitHub Actions workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property of a GitHub Actions workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ci
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_Parse tests detecting and parsing CI environments.
+func Test_Parse(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		name     string
+		env      map[string]string
+		expected pb.UI_Settings_CI
+		fail     bool
+	}{
+		"auto none": {
+			name:     "auto",
+			env:      map[string]string{"CI": "true"},
+			expected: pb.UI_Settings_CI_UNSPECIFIED,
+		},
+		"auto github actions": {
+			name:     "auto",
+			env:      map[string]string{"CI": "true", "GITHUB_ACTIONS": "true"},
+			expected: pb.UI_Settings_CI_GITHUB_ACTIONS,
+		},
+		"auto gitlab": {
+			name:     "auto",
+			env:      map[string]string{"GITLAB_CI": "true"},
+			expected: pb.UI_Settings_CI_GITLAB,
+		},
+		"auto circleci": {
+			name:     "",
+			env:      map[string]string{"CIRCLECI": "true"},
+			expected: pb.UI_Settings_CI_CIRCLECI,
+		},
+		"auto buildkite": {
+			name:     "auto",
+			env:      map[string]string{"BUILDKITE": "true"},
+			expected: pb.UI_Settings_CI_BUILDKITE,
+		},
+		"none": {
+			name:     "none",
+			env:      map[string]string{"GITHUB_ACTIONS": "true"},
+			expected: pb.UI_Settings_CI_UNSPECIFIED,
+		},
+		"explicit": {
+			name:     "gitlab",
+			env:      map[string]string{"GITHUB_ACTIONS": "true"},
+			expected: pb.UI_Settings_CI_GITLAB,
+		},
+		"unknown": {
+			name: "jenkins",
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			ci, err := Parse(test.name, func(key string) string { return test.env[key] })
+			if test.fail {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, ci)
+		})
+	}
+}
+
+// Test_Group tests starting and ending collapsible groups.
+func Test_Group(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	for desc, test := range map[string]struct {
+		ci       pb.UI_Settings_CI
+		expanded bool
+		start    string
+		end      string
+	}{
+		"github actions": {
+			ci:    pb.UI_Settings_CI_GITHUB_ACTIONS,
+			start: "::group::Scenario: test [arch:amd64] failed!",
+			end:   "::endgroup::",
+		},
+		"gitlab collapsed": {
+			ci:    pb.UI_Settings_CI_GITLAB,
+			start: "\x1b[0Ksection_start:1700000000:test_arch_amd64[collapsed=true]\r\x1b[0KScenario: test [arch:amd64] failed!",
+			end:   "\x1b[0Ksection_end:1700000000:test_arch_amd64\r\x1b[0K",
+		},
+		"gitlab expanded": {
+			ci:       pb.UI_Settings_CI_GITLAB,
+			expanded: true,
+			start:    "\x1b[0Ksection_start:1700000000:test_arch_amd64\r\x1b[0KScenario: test [arch:amd64] failed!",
+			end:      "\x1b[0Ksection_end:1700000000:test_arch_amd64\r\x1b[0K",
+		},
+		"buildkite collapsed": {
+			ci:    pb.UI_Settings_CI_BUILDKITE,
+			start: "--- Scenario: test [arch:amd64] failed!",
+		},
+		"buildkite expanded": {
+			ci:       pb.UI_Settings_CI_BUILDKITE,
+			expanded: true,
+			start:    "+++ Scenario: test [arch:amd64] failed!",
+		},
+		"circleci": {
+			ci: pb.UI_Settings_CI_CIRCLECI,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.start, StartGroup(test.ci, "test arch:amd64", "Scenario: test [arch:amd64] failed!", test.expanded, now))
+			require.Equal(t, test.end, EndGroup(test.ci, "test arch:amd64", now))
+		})
+	}
+}
+
+// Test_Annotation tests the workflow commands of annotations.
+func Test_Annotation(t *testing.T) {
+	t.Parallel()
+
+	diag := func(sev pb.Diagnostic_Severity, filename string, start, end *pb.Range_Pos) *pb.Diagnostic {
+		return &pb.Diagnostic{
+			Severity: sev,
+			Summary:  "Unsupported attribute",
+			Detail:   "This object does not have an attribute named \"bar\".\nDid you mean \"foo\"?",
+			Range: &pb.Range{
+				Filename: filename,
+				Start:    start,
+				End:      end,
+			},
+		}
+	}
+
+	for desc, test := range map[string]struct {
+		ci       pb.UI_Settings_CI
+		diag     *pb.Diagnostic
+		expected string
+	}{
+		"error single line": {
+			ci: pb.UI_Settings_CI_GITHUB_ACTIONS,
+			diag: diag(pb.Diagnostic_SEVERITY_ERROR, "/work/repo/enos/enos.hcl",
+				&pb.Range_Pos{Line: 10, Column: 20}, &pb.Range_Pos{Line: 10, Column: 24},
+			),
+			expected: "::error file=enos/enos.hcl,line=10,col=20,endColumn=24,title=Unsupported attribute::" +
+				"This object does not have an attribute named \"bar\".%0ADid you mean \"foo\"?",
+		},
+		"warning multiple lines": {
+			ci: pb.UI_Settings_CI_GITHUB_ACTIONS,
+			diag: diag(pb.Diagnostic_SEVERITY_WARNING, "enos/enos.hcl",
+				&pb.Range_Pos{Line: 10, Column: 20}, &pb.Range_Pos{Line: 12, Column: 1},
+			),
+			expected: "::warning file=enos/enos.hcl,line=10,endLine=12,title=Unsupported attribute::" +
+				"This object does not have an attribute named \"bar\".%0ADid you mean \"foo\"?",
+		},
+		"outside of the workspace": {
+			ci: pb.UI_Settings_CI_GITHUB_ACTIONS,
+			diag: diag(pb.Diagnostic_SEVERITY_ERROR, "/tmp/enos.hcl",
+				&pb.Range_Pos{Line: 10}, &pb.Range_Pos{Line: 10},
+			),
+		},
+		"no range": {
+			ci:   pb.UI_Settings_CI_GITHUB_ACTIONS,
+			diag: &pb.Diagnostic{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "failed"},
+		},
+		"unsupported": {
+			ci: pb.UI_Settings_CI_GITLAB,
+			diag: diag(pb.Diagnostic_SEVERITY_ERROR, "/work/repo/enos/enos.hcl",
+				&pb.Range_Pos{Line: 10}, &pb.Range_Pos{Line: 10},
+			),
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.expected, Annotation(test.ci, test.diag, "/work/repo"))
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ci
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/enos/internal/notify"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// JUnitRunClassName is the class name of the test cases of the errors of runs that don't belong to
+// a scenario.
+const JUnitRunClassName = "enos"
+
+// JUnitTestSuites is a JUnit XML report of runs. Each run is a test suite and each scenario
+// is a test case.
+type JUnitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr,omitempty"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite is the test suite of a run.
+type JUnitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr,omitempty"`
+	Time     string           `xml:"time,attr"`
+	Cases    []*JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase is the test case of a scenario.
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+}
+
+// JUnitFailure is the failure of a scenario.
+type JUnitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// JUnitSkipped is why a scenario was skipped.
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// NewJUnit takes the summary of a run and returns its JUnit report. Errors of the run that don't
+// belong to a scenario are reported as a failed test case of the command and skipped scenarios
+// are reported as skipped test cases with the reason.
+func NewJUnit(summary *notify.Summary) *JUnitTestSuites {
+	suite := &JUnitTestSuite{
+		Name:  summary.Command,
+		Time:  junitTime(summary.Duration),
+		Cases: []*JUnitTestCase{},
+	}
+
+	if len(summary.Errors) > 0 {
+		suite.Cases = append(suite.Cases, &JUnitTestCase{
+			Name:      summary.Command,
+			ClassName: JUnitRunClassName,
+			Time:      junitTime(0),
+			Failure: &JUnitFailure{
+				Message:  summary.Errors[0],
+				Contents: junitDiagnostics(summary.Diagnostics),
+			},
+		})
+	}
+
+	for _, scenario := range summary.Scenarios {
+		tc := &JUnitTestCase{
+			Name:      scenario.Name,
+			ClassName: scenario.Name,
+			Time:      junitTime(scenario.Duration),
+		}
+		if scenario.Scenario != nil {
+			tc.ClassName = scenario.Scenario.Name
+		}
+		if scenario.Failed {
+			tc.Failure = &JUnitFailure{
+				Message:  scenario.Error,
+				Contents: junitDiagnostics(scenario.Diagnostics),
+			}
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	for _, skipped := range summary.Skipped {
+		tc := &JUnitTestCase{
+			Name:      skipped.Name,
+			ClassName: skipped.Name,
+			Time:      junitTime(0),
+			Skipped:   &JUnitSkipped{Message: skipped.Reason},
+		}
+		if skipped.Scenario != nil {
+			tc.ClassName = skipped.Scenario.Name
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	for _, tc := range suite.Cases {
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+
+	return &JUnitTestSuites{
+		Name:     summary.Command,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []*JUnitTestSuite{suite},
+	}
+}
+
+// Write writes the report as XML.
+func (j *JUnitTestSuites) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(j); err != nil {
+		return fmt.Errorf("encoding junit report: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// junitTime returns the duration in seconds.
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// junitDiagnostics returns the errors and warnings of the diagnostics as plain text.
+func junitDiagnostics(diags []*pb.Diagnostic) string {
+	b := &strings.Builder{}
+	for _, diag := range diags {
+		switch diag.GetSeverity() {
+		case pb.Diagnostic_SEVERITY_ERROR:
+			b.WriteString("Error: ")
+		case pb.Diagnostic_SEVERITY_WARNING:
+			b.WriteString("Warning: ")
+		default:
+			continue
+		}
+
+		b.WriteString(diag.GetSummary() + "\n")
+		if rng := diag.GetRange(); rng.GetFilename() != "" {
+			fmt.Fprintf(b, "  on %s line %d\n", rng.GetFilename(), rng.GetStart().GetLine())
+		}
+		if detail := diag.GetDetail(); detail != "" {
+			b.WriteString("\n" + detail + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,189 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ci
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/enos/internal/notify"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// JUnitReportFile is the name of the JUnit report that is written to the report directory.
+const JUnitReportFile = "enos-junit.xml"
+
+var _ notify.Notifier = (*SummaryWriter)(nil)
+
+// SummaryWriter writes the summaries of runs to the summary and artifact mechanisms of the CI
+// environment. A JUnit report is written to the report directory in every CI environment so that
+// it can be collected as a test report and artifact. In GitHub Actions the summary is also added
+// to the job summary, and in Buildkite it's added as an annotation of the build and the report is
+// uploaded as an artifact.
+type SummaryWriter struct {
+	ci             pb.UI_Settings_CI
+	reportDir      string
+	stepSummary    string
+	buildkiteAgent string
+}
+
+// SummaryWriterOpt is a functional option.
+type SummaryWriterOpt func(*SummaryWriter)
+
+// NewSummaryWriter takes options and returns a new SummaryWriter.
+func NewSummaryWriter(opts ...SummaryWriterOpt) *SummaryWriter {
+	s := &SummaryWriter{
+		buildkiteAgent: "buildkite-agent",
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithSummaryCI sets the CI environment.
+func WithSummaryCI(ci pb.UI_Settings_CI) SummaryWriterOpt {
+	return func(s *SummaryWriter) {
+		s.ci = ci
+	}
+}
+
+// WithSummaryReportDir sets the directory that the JUnit report is written to. No report is
+// written if it's empty.
+func WithSummaryReportDir(dir string) SummaryWriterOpt {
+	return func(s *SummaryWriter) {
+		s.reportDir = dir
+	}
+}
+
+// WithSummaryStepSummary sets the path of the job summary file of GitHub Actions, i.e.
+// GITHUB_STEP_SUMMARY.
+func WithSummaryStepSummary(path string) SummaryWriterOpt {
+	return func(s *SummaryWriter) {
+		s.stepSummary = path
+	}
+}
+
+// WithSummaryBuildkiteAgent sets the path of the buildkite-agent binary.
+func WithSummaryBuildkiteAgent(path string) SummaryWriterOpt {
+	return func(s *SummaryWriter) {
+		if path != "" {
+			s.buildkiteAgent = path
+		}
+	}
+}
+
+// Notify writes the summary of the run.
+func (s *SummaryWriter) Notify(ctx context.Context, summary *notify.Summary) error {
+	if summary == nil {
+		return nil
+	}
+
+	report, err := s.writeJUnit(summary)
+
+	switch s.ci {
+	case pb.UI_Settings_CI_GITHUB_ACTIONS:
+		err = errors.Join(err, s.writeStepSummary(summary))
+	case pb.UI_Settings_CI_BUILDKITE:
+		err = errors.Join(err, s.annotateBuildkite(ctx, summary))
+		if report != "" {
+			err = errors.Join(err, s.runBuildkiteAgent(ctx, nil, "artifact", "upload", report))
+		}
+	default:
+	}
+
+	return err
+}
+
+// writeJUnit writes the JUnit report of the summary to the report directory and returns its path.
+func (s *SummaryWriter) writeJUnit(summary *notify.Summary) (string, error) {
+	if s.reportDir == "" {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(s.reportDir, 0o755); err != nil {
+		return "", fmt.Errorf("creating report directory: %w", err)
+	}
+
+	path := filepath.Join(s.reportDir, JUnitReportFile)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("creating junit report: %w", err)
+	}
+	defer f.Close()
+
+	if err := NewJUnit(summary).Write(f); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// writeStepSummary appends the summary to the job summary of GitHub Actions.
+func (s *SummaryWriter) writeStepSummary(summary *notify.Summary) error {
+	if s.stepSummary == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(s.stepSummary, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening job summary: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(summaryMarkdown(summary)); err != nil {
+		return fmt.Errorf("writing job summary: %w", err)
+	}
+
+	return nil
+}
+
+// annotateBuildkite adds the summary as an annotation of the Buildkite build. Annotations of
+// the same command replace each other.
+func (s *SummaryWriter) annotateBuildkite(ctx context.Context, summary *notify.Summary) error {
+	style := "success"
+	if summary.HasFailed() {
+		style = "error"
+	}
+
+	return s.runBuildkiteAgent(ctx, strings.NewReader(summaryMarkdown(summary)),
+		"annotate", "--style", style, "--context", sectionName(summary.Command),
+	)
+}
+
+// runBuildkiteAgent runs the buildkite-agent with the arguments.
+func (s *SummaryWriter) runBuildkiteAgent(ctx context.Context, stdin *strings.Reader, args ...string) error {
+	cmd := exec.CommandContext(ctx, s.buildkiteAgent, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running buildkite-agent %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// summaryMarkdown returns the summary as Markdown with a heading.
+func summaryMarkdown(summary *notify.Summary) string {
+	skipped := ""
+	if len(summary.Skipped) > 0 {
+		skipped = fmt.Sprintf(", %d skipped", len(summary.Skipped))
+	}
+
+	return fmt.Sprintf("### Enos: %d passed, %d failed%s\n\n%s\n",
+		summary.Passed(), summary.Failed(), skipped, summary.Markdown(),
+	)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ci
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/notify"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// testSummary returns the summary of a run with a passed and a failed scenario.
+func testSummary() *notify.Summary {
+	passed := flightplan.NewScenario()
+	passed.Name = "smoke"
+
+	failed := flightplan.NewScenario()
+	failed.Name = "upgrade"
+	failed.Variants = flightplan.NewVector()
+	failed.Variants.Add(flightplan.NewElement("arch", "amd64"))
+
+	return &notify.Summary{
+		Command: "enos scenario run",
+		Scenarios: []*notify.ScenarioResult{
+			{
+				Name:     failed.String(),
+				Scenario: failed,
+				Failed:   true,
+				Duration: 90 * time.Second,
+				Error:    "timeout waiting for cluster",
+				Diagnostics: []*pb.Diagnostic{{
+					Severity: pb.Diagnostic_SEVERITY_ERROR,
+					Summary:  "timeout waiting for cluster",
+					Detail:   "the cluster did not become healthy in 5m",
+					Range:    &pb.Range{Filename: "enos.hcl", Start: &pb.Range_Pos{Line: 3}},
+				}},
+			},
+			{
+				Name:     passed.String(),
+				Scenario: passed,
+				Duration: 30 * time.Second,
+			},
+		},
+		Duration: 2 * time.Minute,
+	}
+}
+
+// Test_JUnit tests the JUnit report of a summary.
+func Test_JUnit(t *testing.T) {
+	t.Parallel()
+
+	report := NewJUnit(testSummary())
+	require.Equal(t, 2, report.Tests)
+	require.Equal(t, 1, report.Failures)
+	require.Equal(t, "120.000", report.Time)
+	require.Len(t, report.Suites, 1)
+
+	cases := report.Suites[0].Cases
+	require.Len(t, cases, 2)
+	require.Equal(t, "upgrade [arch:amd64]", cases[0].Name)
+	require.Equal(t, "upgrade", cases[0].ClassName)
+	require.Equal(t, "90.000", cases[0].Time)
+	require.Equal(t, &JUnitFailure{
+		Message:  "timeout waiting for cluster",
+		Contents: "Error: timeout waiting for cluster\n  on enos.hcl line 3\n\nthe cluster did not become healthy in 5m",
+	}, cases[0].Failure)
+	require.Nil(t, cases[1].Failure)
+
+	summary := &notify.Summary{
+		Command:     "enos scenario run",
+		Errors:      []string{"unable to decode scenarios"},
+		Diagnostics: []*pb.Diagnostic{{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "unable to decode scenarios"}},
+	}
+	report = NewJUnit(summary)
+	require.Equal(t, 1, report.Failures)
+	require.Equal(t, "enos scenario run", report.Suites[0].Cases[0].Name)
+	require.Equal(t, "unable to decode scenarios", report.Suites[0].Cases[0].Failure.Message)
+
+	summary = testSummary()
+	summary.Skipped = []*notify.SkippedResult{{
+		Name:     "upgrade [arch:arm64]",
+		Scenario: &flightplan.Scenario{Name: "upgrade"},
+		Reason:   "known bad until the fix is released",
+	}}
+	report = NewJUnit(summary)
+	require.Equal(t, 3, report.Tests)
+	require.Equal(t, 1, report.Failures)
+	require.Equal(t, 1, report.Skipped)
+	skipped := report.Suites[0].Cases[2]
+	require.Equal(t, "upgrade [arch:arm64]", skipped.Name)
+	require.Equal(t, "upgrade", skipped.ClassName)
+	require.Nil(t, skipped.Failure)
+	require.Equal(t, &JUnitSkipped{Message: "known bad until the fix is released"}, skipped.Skipped)
+
+	out, err := xml.Marshal(skipped)
+	require.NoError(t, err)
+	require.Contains(t, string(out), `<skipped message="known bad until the fix is released"></skipped>`)
+}
+
+// Test_SummaryWriter_GitHubActions tests writing the JUnit report and the job summary.
+func Test_SummaryWriter_GitHubActions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	stepSummary := filepath.Join(dir, "step_summary.md")
+	require.NoError(t, os.WriteFile(stepSummary, []byte("previous step\n"), 0o644))
+
+	writer := NewSummaryWriter(
+		WithSummaryCI(pb.UI_Settings_CI_GITHUB_ACTIONS),
+		WithSummaryReportDir(filepath.Join(dir, "reports")),
+		WithSummaryStepSummary(stepSummary),
+	)
+	require.NoError(t, writer.Notify(context.Background(), testSummary()))
+
+	md, err := os.ReadFile(stepSummary)
+	require.NoError(t, err)
+	require.Contains(t, string(md), "previous step\n### Enos: 1 passed, 1 failed\n\n`enos scenario run` failed.\n")
+	require.Contains(t, string(md), "| :x: | `upgrade [arch:amd64]` | 1m30s | timeout waiting for cluster |\n")
+
+	junit, err := os.ReadFile(filepath.Join(dir, "reports", JUnitReportFile))
+	require.NoError(t, err)
+	report := &JUnitTestSuites{}
+	require.NoError(t, xml.Unmarshal(junit, report))
+	require.Equal(t, NewJUnit(testSummary()).Suites[0].Cases, report.Suites[0].Cases)
+}
+
+// Test_SummaryWriter_Buildkite tests annotating the build and uploading the JUnit report.
+func Test_SummaryWriter_Buildkite(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	agent := filepath.Join(dir, "buildkite-agent")
+	calls := filepath.Join(dir, "calls")
+	require.NoError(t, os.WriteFile(agent, []byte("#!/bin/sh\necho \"$@\" >> "+calls+"\ncat > /dev/null\n"), 0o755))
+
+	writer := NewSummaryWriter(
+		WithSummaryCI(pb.UI_Settings_CI_BUILDKITE),
+		WithSummaryReportDir(filepath.Join(dir, "reports")),
+		WithSummaryBuildkiteAgent(agent),
+	)
+	require.NoError(t, writer.Notify(context.Background(), testSummary()))
+
+	out, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Equal(t,
+		"annotate --style error --context enos_scenario_run\n"+
+			"artifact upload "+filepath.Join(dir, "reports", JUnitReportFile)+"\n",
+		string(out),
+	)
+
+	writer = NewSummaryWriter(
+		WithSummaryCI(pb.UI_Settings_CI_BUILDKITE),
+		WithSummaryBuildkiteAgent(filepath.Join(dir, "missing")),
+	)
+	require.ErrorContains(t, writer.Notify(context.Background(), testSummary()), "running buildkite-agent annotate")
+}
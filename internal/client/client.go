@@ -6,7 +6,6 @@ package client
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net"
 	"net/url"
 	"time"
@@ -26,6 +25,8 @@ type Connection struct {
 	Log      hclog.Logger
 	Level    pb.UI_Settings_Level
 	DialOpts []grpc.DialOption
+	// Conn is the underlying gRPC client connection.
+	Conn *grpc.ClientConn
 }
 
 // Opt is a client connection option.
@@ -39,11 +40,8 @@ func WithGRPCListenURL(url *url.URL) Opt {
 		case "unix", "unixpacket":
 			c.Addr, err = net.ResolveUnixAddr(url.Scheme, url.Host)
 		default:
-			addr := url.Host
-			if p := url.Port(); p != "" {
-				addr = fmt.Sprintf("%s:%s", addr, p)
-			}
-			c.Addr, err = net.ResolveTCPAddr("tcp", addr)
+			// The host of the URL includes the port.
+			c.Addr, err = net.ResolveTCPAddr("tcp", url.Host)
 		}
 
 		return err
@@ -132,7 +130,17 @@ func Connect(ctx context.Context, opts ...Opt) (*Connection, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.Conn = conn
 	c.Client = pb.NewEnosServiceClient(conn)
 
 	return c, nil
 }
+
+// Close closes the connection to the server.
+func (c *Connection) Close() error {
+	if c.Conn == nil {
+		return nil
+	}
+
+	return c.Conn.Close()
+}
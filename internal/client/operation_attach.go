@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// attachResizeInterval is how often we check whether the terminal has been resized while we're
+// attached to an operation.
+const attachResizeInterval = 250 * time.Millisecond
+
+// AttachOperations attaches the terminal to each of the operations in turn, proxying its input to
+// their sub-commands and writing their output to out. The terminal is put into raw mode while
+// we're attached so that every key press is sent as is, e.g. to answer prompts.
+func (c *Connection) AttachOperations(
+	ctx context.Context,
+	ops []*pb.Ref_Operation,
+	in *os.File,
+	out io.Writer,
+) []*pb.Diagnostic {
+	diags := []*pb.Diagnostic{}
+	if len(ops) == 0 {
+		return diags
+	}
+
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return diagnostics.FromErr(errors.New("attaching to operations requires a terminal"))
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return diagnostics.FromErr(err)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	// Reading stdin blocks so we read it once for every operation that we attach to.
+	stdinC := make(chan []byte)
+	go func() {
+		defer close(stdinC)
+
+		for {
+			buf := make([]byte, 1024)
+			n, err := in.Read(buf)
+			if n > 0 {
+				stdinC <- buf[:n]
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, op := range ops {
+		diags = append(diags, c.attachOperation(ctx, op, fd, stdinC, out)...)
+	}
+
+	return diags
+}
+
+// attachOperation attaches to the operation until its sub-command has exited.
+func (c *Connection) attachOperation(
+	ctx context.Context,
+	op *pb.Ref_Operation,
+	fd int,
+	stdinC <-chan []byte,
+	out io.Writer,
+) []*pb.Diagnostic {
+	log := c.Log.With("operation_id", op.GetId())
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.Client.AttachOperation(ctx)
+	if err != nil {
+		return diagnostics.FromErr(err)
+	}
+
+	// Streams can't be sent to concurrently
+	mu := sync.Mutex{}
+	send := func(req *pb.AttachOperationRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return stream.Send(req)
+	}
+
+	size := terminalSize(fd)
+	if err := send(&pb.AttachOperationRequest{Op: op, Size: size}); err != nil {
+		return diagnostics.FromErr(err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(attachResizeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case b, ok := <-stdinC:
+				if !ok {
+					return
+				}
+				if err := send(&pb.AttachOperationRequest{Stdin: b}); err != nil {
+					log.Debug("failed to send stdin", "error", err)
+
+					return
+				}
+			case <-ticker.C:
+				newSize := terminalSize(fd)
+				if newSize.GetRows() == size.GetRows() && newSize.GetCols() == size.GetCols() {
+					continue
+				}
+				size = newSize
+				if err := send(&pb.AttachOperationRequest{Size: size}); err != nil {
+					log.Debug("failed to send terminal size", "error", err)
+
+					return
+				}
+			}
+		}
+	}()
+
+	diags := []*pb.Diagnostic{}
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				diags = append(diags, diagnostics.FromErr(err)...)
+			}
+
+			return diags
+		}
+
+		diags = append(diags, res.GetDiagnostics()...)
+		if len(res.GetOutput()) > 0 {
+			_, _ = out.Write(res.GetOutput())
+		}
+	}
+}
+
+// terminalSize returns the size of the terminal, or nil if it can't be determined.
+func terminalSize(fd int) *pb.TerminalSize {
+	cols, rows, err := term.GetSize(fd)
+	if err != nil {
+		return nil
+	}
+
+	return &pb.TerminalSize{Rows: uint32(rows), Cols: uint32(cols)}
+}
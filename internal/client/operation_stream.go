@@ -21,13 +21,35 @@ type opRes interface {
 	GetOperations() []*pb.Ref_Operation
 }
 
+// StreamOpt is an option for streaming operations.
+type StreamOpt func(*streamOpts)
+
+type streamOpts struct {
+	onResponse []func(*pb.Operation_Response)
+}
+
+// WithOnResponse sets a function that is called with the response of each
+// operation as soon as it has completed. It is called concurrently for
+// operations that complete at the same time.
+func WithOnResponse(f func(*pb.Operation_Response)) StreamOpt {
+	return func(o *streamOpts) {
+		o.onResponse = append(o.onResponse, f)
+	}
+}
+
 // StreamOperations handles streaming responses from the server and writing
 // their responses to the UI.
 func (c *Connection) StreamOperations(
 	ctx context.Context,
 	opRes opRes,
 	ui uipkg.View,
+	opts ...StreamOpt,
 ) *pb.OperationResponses {
+	sOpts := &streamOpts{}
+	for _, opt := range opts {
+		opt(sOpts)
+	}
+
 	res := &pb.OperationResponses{
 		Decode:      opRes.GetDecode(),
 		Diagnostics: opRes.GetDiagnostics(),
@@ -42,9 +64,13 @@ func (c *Connection) StreamOperations(
 	}
 
 	var moreDiags []*pb.Diagnostic
-	res.Responses, moreDiags = c.streamResponses(ctx, opRes.GetOperations(), ui)
+	res.Responses, moreDiags = c.streamResponses(ctx, opRes.GetOperations(), ui, sOpts)
 	res.Diagnostics = append(res.GetDiagnostics(), moreDiags...)
 
+	if len(res.GetResponses()) > 1 {
+		res.Timing = NewOperationTiming(res.GetResponses())
+	}
+
 	return res
 }
 
@@ -57,6 +83,7 @@ func (c *Connection) streamResponses(
 	ctx context.Context,
 	refs []*pb.Ref_Operation,
 	ui uipkg.View,
+	opts *streamOpts,
 ) ([]*pb.Operation_Response, []*pb.Diagnostic) {
 	mu := sync.Mutex{}
 	wg := sync.WaitGroup{}
@@ -156,13 +183,18 @@ func (c *Connection) streamResponses(
 					"operation_id", ref.GetId(),
 					"error", err,
 				)
-				mu.Lock()
-				res = append(res, &pb.Operation_Response{
+				errRes := &pb.Operation_Response{
 					Diagnostics: diagnostics.FromErr(err),
 					Op:          ref,
-				})
+				}
+				mu.Lock()
+				res = append(res, errRes)
 				mu.Unlock()
 
+				for _, f := range opts.onResponse {
+					f(errRes)
+				}
+
 				return
 			}
 
@@ -248,6 +280,10 @@ func (c *Connection) streamResponses(
 			mu.Lock()
 			res = append(res, opRes.GetResponse())
 			mu.Unlock()
+
+			for _, f := range opts.onResponse {
+				f(opRes.GetResponse())
+			}
 		}()
 	}
 
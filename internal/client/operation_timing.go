@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// slowestSteps is how many of the slowest phases are included in the timing summary.
+const slowestSteps = 5
+
+// NewOperationTiming takes the responses of operations and returns the summary of how long each
+// phase of each scenario took, the slowest phases, and the wall-clock duration of all operations.
+func NewOperationTiming(responses []*pb.Operation_Response) *pb.Operation_Timing {
+	timing := &pb.Operation_Timing{
+		Scenarios: []*pb.Operation_Timing_Scenario{},
+		Slowest:   []*pb.Operation_Timing_Step{},
+	}
+
+	var started, completed time.Time
+	for _, res := range responses {
+		scenario := &pb.Operation_Timing_Scenario{
+			Scenario: res.GetOp().GetScenario(),
+		}
+
+		switch res.GetValue().(type) {
+		case *pb.Operation_Response_Generate_:
+			scenario.Generate = res.GetGenerate().GetElapsed()
+		case *pb.Operation_Response_Check_:
+			scenario.Generate = res.GetCheck().GetGenerate().GetElapsed()
+			scenario.Init = res.GetCheck().GetInit().GetElapsed()
+			scenario.Validate = res.GetCheck().GetValidate().GetElapsed()
+			scenario.Plan = res.GetCheck().GetPlan().GetElapsed()
+		case *pb.Operation_Response_Launch_:
+			scenario.Generate = res.GetLaunch().GetGenerate().GetElapsed()
+			scenario.Init = res.GetLaunch().GetInit().GetElapsed()
+			scenario.Validate = res.GetLaunch().GetValidate().GetElapsed()
+			scenario.Plan = res.GetLaunch().GetPlan().GetElapsed()
+			scenario.Apply = res.GetLaunch().GetApply().GetElapsed()
+		case *pb.Operation_Response_Destroy_:
+			scenario.Generate = res.GetDestroy().GetGenerate().GetElapsed()
+			scenario.Init = res.GetDestroy().GetInit().GetElapsed()
+			scenario.Destroy = res.GetDestroy().GetDestroy().GetElapsed()
+		case *pb.Operation_Response_Run_:
+			scenario.Generate = res.GetRun().GetGenerate().GetElapsed()
+			scenario.Init = res.GetRun().GetInit().GetElapsed()
+			scenario.Validate = res.GetRun().GetValidate().GetElapsed()
+			scenario.Plan = res.GetRun().GetPlan().GetElapsed()
+			scenario.Apply = res.GetRun().GetApply().GetElapsed()
+			scenario.Destroy = res.GetRun().GetDestroy().GetElapsed()
+		case *pb.Operation_Response_Lock_:
+			scenario.Generate = res.GetLock().GetGenerate().GetElapsed()
+			scenario.Init = res.GetLock().GetInit().GetElapsed()
+		default:
+		}
+
+		if res.GetStartedAt() != nil && res.GetCompletedAt() != nil {
+			start := res.GetStartedAt().AsTime()
+			end := res.GetCompletedAt().AsTime()
+			scenario.Total = durationpb.New(end.Sub(start))
+
+			if started.IsZero() || start.Before(started) {
+				started = start
+			}
+			if end.After(completed) {
+				completed = end
+			}
+		}
+
+		timing.Scenarios = append(timing.GetScenarios(), scenario)
+
+		for _, phase := range []struct {
+			name    string
+			elapsed *durationpb.Duration
+		}{
+			{"generate", scenario.GetGenerate()},
+			{"init", scenario.GetInit()},
+			{"validate", scenario.GetValidate()},
+			{"plan", scenario.GetPlan()},
+			{"apply", scenario.GetApply()},
+			{"destroy", scenario.GetDestroy()},
+		} {
+			if phase.elapsed == nil {
+				continue
+			}
+
+			timing.Slowest = append(timing.GetSlowest(), &pb.Operation_Timing_Step{
+				Scenario: scenario.GetScenario(),
+				Phase:    phase.name,
+				Elapsed:  phase.elapsed,
+			})
+		}
+	}
+
+	slices.SortStableFunc(timing.GetSlowest(), func(a, b *pb.Operation_Timing_Step) int {
+		return cmp.Compare(b.GetElapsed().AsDuration(), a.GetElapsed().AsDuration())
+	})
+	timing.Slowest = timing.GetSlowest()[:min(len(timing.GetSlowest()), slowestSteps)]
+
+	if !started.IsZero() {
+		timing.Total = durationpb.New(completed.Sub(started))
+	}
+
+	return timing
+}
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// workspaceRequest is any request that carries a workspace.
+type workspaceRequest interface {
+	GetWorkspace() *pb.Workspace
+}
+
+// operationRequest is any request that carries a reference to an operation.
+type operationRequest interface {
+	GetOp() *pb.Ref_Operation
+}
+
+// WithProject scopes the workspaces and operations of every request to the project that has been
+// registered with the server. Requests that already select a project are left as they are.
+func WithProject(name string) Opt {
+	return func(c *Connection) error {
+		if name == "" {
+			return nil
+		}
+
+		c.DialOpts = append(c.DialOpts,
+			grpc.WithChainUnaryInterceptor(projectUnaryInterceptor(name)),
+			grpc.WithChainStreamInterceptor(projectStreamInterceptor(name)),
+		)
+
+		return nil
+	}
+}
+
+// setRequestProject sets the project of the workspace or operation of the request if it doesn't
+// have one.
+func setRequestProject(name string, req any) {
+	if wsReq, ok := req.(workspaceRequest); ok {
+		if ws := wsReq.GetWorkspace(); ws != nil && ws.GetProject() == "" {
+			ws.Project = name
+		}
+	}
+
+	if opReq, ok := req.(operationRequest); ok {
+		if op := opReq.GetOp(); op != nil && op.GetProject() == "" {
+			op.Project = name
+		}
+	}
+}
+
+// projectUnaryInterceptor returns a gRPC unary interceptor that scopes requests to the project.
+func projectUnaryInterceptor(name string) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		setRequestProject(name, req)
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// projectStreamInterceptor returns a gRPC stream interceptor that scopes the requests of streams to
+// the project.
+func projectStreamInterceptor(name string) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &projectStream{ClientStream: stream, name: name}, nil
+	}
+}
+
+type projectStream struct {
+	grpc.ClientStream
+	name string
+}
+
+func (s *projectStream) SendMsg(m any) error {
+	setRequestProject(s.name, m)
+
+	return s.ClientStream.SendMsg(m)
+}
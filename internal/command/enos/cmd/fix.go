@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var fixState = &struct {
+	write bool
+}{}
+
+// newFixCmd returns a new 'fix' command.
+func newFixCmd() *cobra.Command {
+	fixCmd := &cobra.Command{
+		Use:               "fix [FILTER]",
+		Short:             "Fix the flight plan",
+		Long:              "Decode the scenarios in the flight plan and apply the fixes of any diagnostics that can be fixed automatically, e.g. quoting values that should be strings. The diff of every fixed file is displayed. Some fixes only become available after others have been applied so it can be necessary to fix the flight plan more than once. " + scenarioFilterDesc,
+		PersistentPreRunE: scenarioCmdPreRun,
+		PersistentPostRun: scenarioCmdPostRun,
+		RunE:              runFixCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	fixCmd.PersistentFlags().DurationVar(&scenarioState.timeout, "timeout", 1*time.Hour, "The command timeout")
+	fixCmd.PersistentFlags().BoolVarP(&fixState.write, "write", "w", true, "Write the fixes to the files, otherwise only display the diff of the fixes")
+	fixCmd.PersistentFlags().BoolVar(&scenarioState.tfConfig.FailOnWarnings, "fail-on-warnings", false, "Fail if any diagnostic that could not be fixed is a warning")
+	fixCmd.PersistentFlags().StringVarP(&scenarioState.baseDir, "chdir", "d", "", "Use the given directory as the working directory")
+	fixCmd.PersistentFlags().StringSliceVar(&scenarioState.varsFilesPaths, "var-file", []string{}, "The path to use for variable values files. By default enos will load all enos*.vars.hcl files in the working directory.")
+
+	return fixCmd
+}
+
+// runFixCmd runs a flight plan fix.
+func runFixCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, err := flightplan.ParseScenarioFilter(args)
+	if err != nil {
+		return ui.ShowScenarioFix(&pb.FixScenariosResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := rootState.enosConnection.Client.FixScenarios(
+		ctx, &pb.FixScenariosRequest{
+			Workspace: &pb.Workspace{
+				Flightplan: scenarioState.protoFp,
+				TfExecCfg:  scenarioState.tfConfig.Proto(),
+				Limits:     rootState.limits,
+			},
+			Filter: sf.Proto(),
+			Write:  fixState.write,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowScenarioFix(res)
+}
@@ -0,0 +1,22 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newFlightPlanCmd returns a new 'flightplan' command.
+func newFlightPlanCmd() *cobra.Command {
+	flightPlanCmd := &cobra.Command{
+		Use:   "flightplan",
+		Short: "Enos flight plans",
+		Long:  "Work with the decoded model of the flight plan",
+	}
+
+	flightPlanCmd.AddCommand(newFlightPlanExportCmd())
+	flightPlanCmd.AddCommand(newFlightPlanVerifyCmd())
+
+	return flightPlanCmd
+}
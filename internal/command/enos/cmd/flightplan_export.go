@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// newFlightPlanExportCmd returns a new 'flightplan export' sub-command.
+func newFlightPlanExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:               "export [FILTER]",
+		Short:             "Export the decoded flight plan",
+		Long:              "Export the entire decoded flight plan, i.e. the modules, providers, scenarios with their steps and resolved variables, samples and qualities, for documentation and analysis tools. Use --format json to write the model. Variables that refer to the outputs of other steps are exported as references. " + scenarioFilterDesc,
+		PersistentPreRunE: scenarioCmdPreRun,
+		PersistentPostRun: scenarioCmdPostRun,
+		RunE:              runFlightPlanExportCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	exportCmd.PersistentFlags().DurationVar(&scenarioState.timeout, "timeout", 1*time.Hour, "The command timeout")
+	exportCmd.PersistentFlags().BoolVar(&scenarioState.tfConfig.FailOnWarnings, "fail-on-warnings", false, "Fail if the decode creates warning diagnostics")
+	exportCmd.PersistentFlags().StringVarP(&scenarioState.baseDir, "chdir", "d", "", "Use the given directory as the working directory")
+	exportCmd.PersistentFlags().StringSliceVar(&scenarioState.varsFilesPaths, "var-file", []string{}, "The path to use for variable values files. By default enos will load all enos*.vars.hcl files in the working directory.")
+
+	return exportCmd
+}
+
+// runFlightPlanExportCmd runs a flight plan export.
+func runFlightPlanExportCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, err := flightplan.ParseScenarioFilter(args)
+	if err != nil {
+		return ui.ShowFlightPlanExport(&pb.ExportFlightPlanResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := rootState.enosConnection.Client.ExportFlightPlan(
+		ctx, &pb.ExportFlightPlanRequest{
+			Workspace: &pb.Workspace{
+				Flightplan: scenarioState.protoFp,
+				TfExecCfg:  scenarioState.tfConfig.Proto(),
+				Limits:     rootState.limits,
+			},
+			Filter: sf.Proto(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowFlightPlanExport(res)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// newFlightPlanVerifyCmd returns a new 'flightplan verify' sub-command.
+func newFlightPlanVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:               "verify <export.json> [FILTER]",
+		Short:             "Verify the flight plan against a previous export",
+		Long:              "Decode the current flight plan and compare its decoded model with a previous JSON export of it, e.g. of another branch. Only semantic changes are differences: blocks are matched by their names and their locations are ignored. Any differences cause a non-zero exit code. Use the same filter as the export. " + scenarioFilterDesc,
+		Args:              cobra.MinimumNArgs(1),
+		PersistentPreRunE: scenarioCmdPreRun,
+		PersistentPostRun: scenarioCmdPostRun,
+		RunE:              runFlightPlanVerifyCmd,
+	}
+
+	verifyCmd.PersistentFlags().DurationVar(&scenarioState.timeout, "timeout", 1*time.Hour, "The command timeout")
+	verifyCmd.PersistentFlags().BoolVar(&scenarioState.tfConfig.FailOnWarnings, "fail-on-warnings", false, "Fail if the decode creates warning diagnostics")
+	verifyCmd.PersistentFlags().StringVarP(&scenarioState.baseDir, "chdir", "d", "", "Use the given directory as the working directory")
+	verifyCmd.PersistentFlags().StringSliceVar(&scenarioState.varsFilesPaths, "var-file", []string{}, "The path to use for variable values files. By default enos will load all enos*.vars.hcl files in the working directory.")
+
+	return verifyCmd
+}
+
+// runFlightPlanVerifyCmd verifies the flight plan against an export.
+func runFlightPlanVerifyCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	b, err := os.ReadFile(args[0])
+	if err != nil {
+		return ui.ShowFlightPlanVerify(&pb.VerifyFlightPlanResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	export, err := flightplan.UnmarshalExport(b)
+	if err != nil {
+		return ui.ShowFlightPlanVerify(&pb.VerifyFlightPlanResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	sf, err := flightplan.ParseScenarioFilter(args[1:])
+	if err != nil {
+		return ui.ShowFlightPlanVerify(&pb.VerifyFlightPlanResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := rootState.enosConnection.Client.VerifyFlightPlan(
+		ctx, &pb.VerifyFlightPlanRequest{
+			Workspace: &pb.Workspace{
+				Flightplan: scenarioState.protoFp,
+				TfExecCfg:  scenarioState.tfConfig.Proto(),
+				Limits:     rootState.limits,
+			},
+			Filter: sf.Proto(),
+			Export: export,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowFlightPlanVerify(res)
+}
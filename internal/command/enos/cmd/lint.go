@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// newLintCmd returns a new 'lint' command.
+func newLintCmd() *cobra.Command {
+	lintCmd := &cobra.Command{
+		Use:               "lint [FILTER]",
+		Short:             "Lint the flight plan",
+		Long:              "Lint the scenarios in the flight plan with the lint rules that are configured in enos*.lint.hcl files in the working directory. " + scenarioFilterDesc,
+		PersistentPreRunE: scenarioCmdPreRun,
+		PersistentPostRun: scenarioCmdPostRun,
+		RunE:              runLintCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	lintCmd.PersistentFlags().DurationVar(&scenarioState.timeout, "timeout", 1*time.Hour, "The command timeout")
+	lintCmd.PersistentFlags().BoolVar(&scenarioState.tfConfig.FailOnWarnings, "fail-on-warnings", false, "Fail if any lint rule or the decode creates warning diagnostics")
+	lintCmd.PersistentFlags().StringVarP(&scenarioState.baseDir, "chdir", "d", "", "Use the given directory as the working directory")
+	lintCmd.PersistentFlags().StringSliceVar(&scenarioState.varsFilesPaths, "var-file", []string{}, "The path to use for variable values files. By default enos will load all enos*.vars.hcl files in the working directory.")
+
+	return lintCmd
+}
+
+// runLintCmd runs a flight plan lint.
+func runLintCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, err := flightplan.ParseScenarioFilter(args)
+	if err != nil {
+		return ui.ShowScenarioLint(&pb.LintScenariosResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := rootState.enosConnection.Client.LintScenarios(
+		ctx, &pb.LintScenariosRequest{
+			Workspace: &pb.Workspace{
+				Flightplan: scenarioState.protoFp,
+				TfExecCfg:  scenarioState.tfConfig.Proto(),
+				Limits:     rootState.limits,
+			},
+			Filter: sf.Proto(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowScenarioLint(res)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/logfile"
+)
+
+// openLogFile opens the log file if one has been configured. Relative paths are relative to the
+// out directory, which defaults to the .enos directory of the working directory.
+func openLogFile(cmd *cobra.Command) error {
+	if rootState.logFile == "" {
+		return nil
+	}
+
+	path := rootState.logFile
+	if !filepath.IsAbs(path) {
+		dir, err := outDir(cmd)
+		if err != nil {
+			return err
+		}
+
+		path = filepath.Join(dir, path)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolving log file path: %w", err)
+	}
+
+	rootState.logFileOut, err = logfile.Open(path)
+
+	return err
+}
+
+// logFilePath returns the path of the log file or an empty string if logs are not being written
+// to a file.
+func (r *rootStateS) logFilePath() string {
+	if r.logFileOut == nil {
+		return ""
+	}
+
+	return r.logFileOut.Path()
+}
+
+// newLogger returns a new logger that writes to stderr at the level. If a log file has been
+// opened every message is also written to it, regardless of the level, so that the log file
+// always has the complete trace logs.
+func newLogger(level hclog.Level) hclog.Logger {
+	if rootState.logFileOut == nil {
+		return hclog.New(&hclog.LoggerOptions{
+			Name:  "enos",
+			Level: level,
+		})
+	}
+
+	log := hclog.NewInterceptLogger(&hclog.LoggerOptions{
+		Name:   "enos",
+		Level:  hclog.Trace,
+		Output: rootState.logFileOut,
+	})
+	log.RegisterSink(hclog.NewSinkAdapter(&hclog.LoggerOptions{
+		Level: level,
+	}))
+
+	return log
+}
@@ -0,0 +1,34 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/lsp"
+)
+
+func newLSPCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lsp",
+		Short: "Start a language server for enos HCL files",
+		Long:  "Start a Language Server Protocol server that communicates over STDIN and STDOUT. When flight plan or variables files are opened or saved the entire flight plan of their directory is decoded and its diagnostics are published. It completes the names of blocks and attributes and module, step, matrix, var, global, and local references, goes to the definitions of references, and documents blocks, attributes, and references on hover. Logs are written to STDERR.",
+		Args:  cobra.NoArgs,
+		RunE:  runLSPCmd,
+	}
+}
+
+func runLSPCmd(cmd *cobra.Command, args []string) error {
+	server, err := lsp.NewServer(
+		lsp.WithLogger(newLogger(hclog.LevelFromString(rootState.logLevel)).Named("lsp")),
+	)
+	if err != nil {
+		return err
+	}
+
+	return server.Serve(cmd.Context(), os.Stdin, os.Stdout)
+}
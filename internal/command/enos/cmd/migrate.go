@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var migrateOutDirState = struct {
+	dryRun bool
+}{}
+
+// newMigrateCmd returns a new 'migrate' command.
+func newMigrateCmd() *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use:               "migrate",
+		Short:             "Migrate enos state between enos versions",
+		Long:              "Migrate the state that enos keeps on disk, e.g. the generated modules of the out directory, from the format of the version of enos that created it to the format of this version.",
+		PersistentPreRunE: scenarioCmdPreRun,
+		PersistentPostRun: scenarioCmdPostRun,
+	}
+
+	migrateCmd.PersistentFlags().DurationVar(&scenarioState.timeout, "timeout", 15*time.Minute, "The command timeout")
+	migrateCmd.PersistentFlags().BoolVar(&scenarioState.tfConfig.FailOnWarnings, "fail-on-warnings", false, "Fail if the decode creates warning diagnostics")
+	migrateCmd.PersistentFlags().StringVarP(&scenarioState.baseDir, "chdir", "d", "", "Use the given directory as the working directory")
+	migrateCmd.PersistentFlags().StringVarP(&scenarioState.outDir, "out", "o", "", "Configure the base directory where generated modules will be created")
+	migrateCmd.PersistentFlags().StringSliceVar(&scenarioState.varsFilesPaths, "var-file", []string{}, "The path to use for variable values files. By default enos will load all enos*.vars.hcl files in the working directory.")
+
+	migrateCmd.AddCommand(newMigrateOutDirCmd())
+
+	return migrateCmd
+}
+
+// newMigrateOutDirCmd returns a new 'migrate out-dir' sub-command.
+func newMigrateOutDirCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "out-dir",
+		Short: "Migrate generated modules to the out directory format of this version of enos",
+		Long:  "Migrate the generated Terraform modules of every scenario in the flight plan, along with their Terraform state, from the out directory format and layout of the version of enos that generated them to the format and layout of this version. Every generated module directory is stamped with an " + "enos.metadata.json file that records the version of enos, the flight plan hash, and the scenario it was generated for. Run it after upgrading enos so that scenarios that have been launched can still be destroyed without being recreated. Modules of scenarios that are no longer in the flight plan are reported and left as they are.",
+		Args:  cobra.NoArgs,
+		RunE:  runMigrateOutDirCmd,
+	}
+
+	cmd.PersistentFlags().BoolVar(&migrateOutDirState.dryRun, "dry-run", false, "Show the modules that would be migrated without migrating them")
+
+	return cmd
+}
+
+// runMigrateOutDirCmd runs a migrate out-dir.
+func runMigrateOutDirCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	_, ws, err := prepareScenarioOpReq(args)
+	if err != nil {
+		return err
+	}
+
+	res, err := rootState.enosConnection.Client.MigrateOutDir(
+		ctx, &pb.MigrateOutDirRequest{
+			Workspace: ws,
+			DryRun:    migrateOutDirState.dryRun,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowOutDirMigrate(res)
+}
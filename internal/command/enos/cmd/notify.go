@@ -0,0 +1,247 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/ci"
+	"github.com/hashicorp/enos/internal/client"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/notify"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// notifyTimeout is how long each notification is allowed to take.
+const notifyTimeout = 30 * time.Second
+
+// notifications are the notifiers of the project configuration and the CI environment for a run
+// of scenario operations. Notifications are best effort, failing to send one is logged but doesn't
+// fail the command.
+type notifications struct {
+	cmd       *cobra.Command
+	log       hclog.Logger
+	notifiers []*configuredNotifier
+}
+
+type configuredNotifier struct {
+	name     string
+	links    map[string]string
+	notifier notify.Notifier
+}
+
+// startNotifications returns the notifications of the run of the operations and starts the
+// notifiers that report the progress of runs. It returns nil if there are no notifiers.
+func startNotifications(cmd *cobra.Command, res interface{ GetOperations() []*pb.Ref_Operation }) *notifications {
+	n := &notifications{
+		cmd: cmd,
+		log: rootState.enosConnection.Log.Named("notify"),
+	}
+
+	if !rootState.noNotify && rootState.projectConfig != nil {
+		for _, cfg := range rootState.projectConfig.Notifiers {
+			notifier, err := newNotifier(cmd, cfg)
+			if err != nil {
+				n.log.Warn("unable to configure notifier", "type", cfg.Type, "error", err)
+
+				continue
+			}
+			n.notifiers = append(n.notifiers, &configuredNotifier{
+				name:     cfg.Type,
+				links:    cfg.Links,
+				notifier: notifier,
+			})
+		}
+	}
+
+	if !rootState.noNotify {
+		n.notifiers = append(n.notifiers, pluginNotifiers()...)
+	}
+
+	// Summaries are always written in CI environments, --no-notify only disables the notifiers of
+	// the project configuration.
+	if rootState.ciEnv != pb.UI_Settings_CI_UNSPECIFIED {
+		notifier, err := newCISummaryWriter(cmd)
+		if err != nil {
+			n.log.Warn("unable to configure CI summary", "error", err)
+		} else {
+			n.notifiers = append(n.notifiers, &configuredNotifier{name: "ci", notifier: notifier})
+		}
+	}
+
+	if len(n.notifiers) == 0 {
+		return nil
+	}
+
+	for _, cn := range n.notifiers {
+		reporter, ok := cn.notifier.(notify.Reporter)
+		if !ok {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := reporter.Start(ctx, cmd.CommandPath(), len(res.GetOperations())); err != nil {
+			n.log.Warn("unable to start reporting", "type", cn.name, "error", err)
+		}
+		cancel()
+	}
+
+	return n
+}
+
+// streamOpts returns the options that report the responses of operations as they complete.
+func (n *notifications) streamOpts() []client.StreamOpt {
+	if n == nil {
+		return nil
+	}
+
+	return []client.StreamOpt{client.WithOnResponse(n.report)}
+}
+
+// report reports the result of the scenario of the response to the notifiers that report the
+// progress of runs.
+func (n *notifications) report(res *pb.Operation_Response) {
+	if res == nil {
+		return
+	}
+
+	result := notify.NewScenarioResult(res, scenarioState.tfConfig.FailOnWarnings)
+	for _, cn := range n.notifiers {
+		reporter, ok := cn.notifier.(notify.Reporter)
+		if !ok {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := reporter.Report(ctx, result); err != nil {
+			n.log.Warn("unable to report scenario result", "type", cn.name, "error", err)
+		}
+		cancel()
+	}
+}
+
+// notify sends the summary of the operation responses to the notifiers.
+func (n *notifications) notify(res *pb.OperationResponses) {
+	if n == nil {
+		return
+	}
+
+	for _, cn := range n.notifiers {
+		links := map[string]string{}
+		for name, url := range cn.links {
+			links[name] = os.ExpandEnv(url)
+		}
+
+		summary := notify.NewSummary(n.cmd.CommandPath(), res, scenarioState.tfConfig.FailOnWarnings,
+			notify.WithSummaryLinks(links),
+			notify.WithSummaryLogFile(rootState.logFilePath()),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := cn.notifier.Notify(ctx, summary); err != nil {
+			n.log.Warn("unable to send notification", "type", cn.name, "error", err)
+		}
+		cancel()
+	}
+}
+
+// newNotifier returns a new notifier for the notifier configuration. Values are expanded with the
+// environment. GitHub notifiers default to the repository, commit, and API of GitHub Actions and
+// Datadog notifiers default to the site and agent of the Datadog environment variables.
+func newNotifier(cmd *cobra.Command, cfg *flightplan.ProjectNotifier) (notify.Notifier, error) {
+	expandOr := func(val string, env string) string {
+		if val == "" {
+			return os.Getenv(env)
+		}
+
+		return os.ExpandEnv(val)
+	}
+
+	switch cfg.Type {
+	case flightplan.NotifierTypeGitHub:
+		dir, err := workingDir(cmd)
+		if err != nil {
+			return nil, err
+		}
+		dir, err = filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		// Annotations are relative to the root of the repository, which is the workspace of GitHub
+		// Actions, or the working directory.
+		rootDir := dir
+		if ws := os.Getenv("GITHUB_WORKSPACE"); ws != "" {
+			rootDir = ws
+		}
+
+		return notify.NewGitHub(
+			notify.WithGitHubAPIURL(expandOr(cfg.APIURL, "GITHUB_API_URL")),
+			notify.WithGitHubToken(os.ExpandEnv(cfg.Token)),
+			notify.WithGitHubApp(
+				os.ExpandEnv(cfg.AppID),
+				os.ExpandEnv(cfg.InstallationID),
+				os.ExpandEnv(cfg.PrivateKey),
+			),
+			notify.WithGitHubRepository(expandOr(cfg.Repository, "GITHUB_REPOSITORY")),
+			notify.WithGitHubSHA(expandOr(cfg.SHA, "GITHUB_SHA")),
+			notify.WithGitHubCheckName(os.ExpandEnv(cfg.CheckName)),
+			notify.WithGitHubDirs(rootDir, dir),
+		), nil
+	case flightplan.NotifierTypeDatadog:
+		statsdAddr := os.ExpandEnv(cfg.StatsDAddress)
+		if statsdAddr == "" && (os.Getenv("DD_AGENT_HOST") != "" || os.Getenv("DD_DOGSTATSD_PORT") != "") {
+			host := cmp.Or(os.Getenv("DD_AGENT_HOST"), "127.0.0.1")
+			port := cmp.Or(os.Getenv("DD_DOGSTATSD_PORT"), "8125")
+			statsdAddr = net.JoinHostPort(host, port)
+		}
+
+		tags := make([]string, len(cfg.Tags))
+		for i, tag := range cfg.Tags {
+			tags[i] = os.ExpandEnv(tag)
+		}
+
+		return notify.NewDatadog(
+			notify.WithDatadogAPIKey(os.ExpandEnv(cfg.APIKey)),
+			notify.WithDatadogSite(expandOr(cfg.Site, "DD_SITE")),
+			notify.WithDatadogStatsDAddress(statsdAddr),
+			notify.WithDatadogMetricPrefix(os.ExpandEnv(cfg.MetricPrefix)),
+			notify.WithDatadogTags(tags...),
+		), nil
+	default:
+		return notify.NewSlack(
+			notify.WithSlackWebhookURL(os.ExpandEnv(cfg.WebhookURL)),
+			notify.WithSlackToken(os.ExpandEnv(cfg.Token)),
+			notify.WithSlackChannel(os.ExpandEnv(cfg.Channel)),
+			notify.WithSlackFailuresOnly(cfg.FailuresOnly),
+		), nil
+	}
+}
+
+// newCISummaryWriter returns a new writer of the summaries of runs in the CI environment. The
+// JUnit report is written to the reports directory of the out directory.
+func newCISummaryWriter(cmd *cobra.Command) (*ci.SummaryWriter, error) {
+	dir, err := outDir(cmd)
+	if err != nil {
+		return nil, err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return ci.NewSummaryWriter(
+		ci.WithSummaryCI(rootState.ciEnv),
+		ci.WithSummaryReportDir(filepath.Join(dir, "reports")),
+		ci.WithSummaryStepSummary(os.Getenv("GITHUB_STEP_SUMMARY")),
+	), nil
+}
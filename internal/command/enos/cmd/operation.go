@@ -0,0 +1,302 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/operation"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+var operationListState = struct {
+	statuses []string
+	limit    int32
+}{}
+
+var operationExecuteArgs struct {
+	baseDir       string
+	outDir        string
+	terraformPath string
+}
+
+// newOperationCmd returns the command to show the operations that have finished. It also has the
+// hidden command that remote executors, e.g. Nomad jobs, run to execute operations. Its output is
+// the protocol of the executor so we don't set up our UI or server for it.
+func newOperationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "operation",
+		Short:             "Show the operations that have finished",
+		Long:              "Show the operations that have finished, e.g. a scenario launch, after the command that executed them has returned. The response of every operation is kept in the operations directory of the out directory.",
+		PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+		PersistentPostRun: func(*cobra.Command, []string) {},
+	}
+
+	cmd.AddCommand(newOperationListCmd())
+	cmd.AddCommand(newOperationShowCmd())
+	cmd.AddCommand(newOperationDiffCmd())
+	cmd.AddCommand(newOperationExecuteCmd())
+
+	return cmd
+}
+
+// setOperationHistoryFlags sets the flags and the pre and post runs of commands that show the
+// operations of the out directory.
+func setOperationHistoryFlags(cmd *cobra.Command) {
+	cmd.PersistentPreRunE = scenarioCmdPreRun
+	cmd.PersistentPostRun = scenarioCmdPostRun
+
+	cmd.PersistentFlags().DurationVar(&scenarioState.timeout, "timeout", 1*time.Minute, "The command timeout")
+	cmd.PersistentFlags().StringVarP(&scenarioState.baseDir, "chdir", "d", "", "Use the given directory as the working directory")
+	cmd.PersistentFlags().StringVarP(&scenarioState.outDir, "out", "o", "", "The base directory where generated modules and the operations are kept")
+}
+
+// newOperationListCmd returns a new 'operation list' sub-command.
+func newOperationListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list [FILTER]",
+		Short:             "List the operations that have finished, most recent first",
+		Long:              "List the operations that have finished, most recent first, with their type, scenario, status, and how long they took. " + scenarioFilterDesc,
+		RunE:              runOperationListCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+	setOperationHistoryFlags(cmd)
+
+	cmd.PersistentFlags().StringSliceVar(&operationListState.statuses, "status", nil, "Only list operations with the status, e.g. failed. Can be given multiple times")
+	cmd.PersistentFlags().Int32Var(&operationListState.limit, "limit", 20, "The maximum number of operations to list. Zero lists all of them")
+
+	return cmd
+}
+
+// newOperationShowCmd returns a new 'operation show' sub-command.
+func newOperationShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <ID>",
+		Short: "Show an operation that has finished",
+		Long:  "Show an operation that has finished, along with its diagnostics, timings, the Terraform module, and the output of its Terraform commands. The ID can be a unique prefix of the ID of the operation.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runOperationShowCmd,
+	}
+	setOperationHistoryFlags(cmd)
+
+	return cmd
+}
+
+// newOperationDiffCmd returns a new 'operation diff' sub-command.
+func newOperationDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <FROM_ID> <TO_ID>",
+		Short: "Compare two operations that have finished, e.g. two runs of the same scenario",
+		Long:  "Compare two operations that have finished, e.g. two runs of the same scenario, and show the output values that changed, how many resources each step has, how long each phase took, and the diagnostics of the second operation that the first didn't have. The IDs can be unique prefixes of the IDs of the operations.",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runOperationDiffCmd,
+	}
+	setOperationHistoryFlags(cmd)
+
+	return cmd
+}
+
+// runOperationListCmd runs an operation list.
+func runOperationListCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	statuses, err := parseOperationStatuses(operationListState.statuses)
+	if err != nil {
+		return ui.ShowError(err)
+	}
+
+	sf, ws, err := prepareScenarioOpReq(args)
+	if err != nil {
+		return err
+	}
+
+	res, err := rootState.enosConnection.Client.ListOperations(
+		ctx, &pb.ListOperationsRequest{
+			Workspace: ws,
+			Filter:    sf,
+			Statuses:  statuses,
+			Limit:     operationListState.limit,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowOperationList(res)
+}
+
+// runOperationShowCmd runs an operation show.
+func runOperationShowCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	_, ws, err := prepareScenarioOpReq(nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := rootState.enosConnection.Client.ShowOperation(
+		ctx, &pb.ShowOperationRequest{
+			Workspace: ws,
+			Id:        args[0],
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowOperation(res)
+}
+
+// runOperationDiffCmd runs an operation diff.
+func runOperationDiffCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	_, ws, err := prepareScenarioOpReq(nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := rootState.enosConnection.Client.DiffOperations(
+		ctx, &pb.DiffOperationsRequest{
+			Workspace: ws,
+			From:      args[0],
+			To:        args[1],
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowOperationDiff(res)
+}
+
+// parseOperationStatuses parses the statuses of operations, e.g. failed or completed_warning.
+func parseOperationStatuses(names []string) ([]pb.Operation_Status, error) {
+	statuses := []pb.Operation_Status{}
+	for _, name := range names {
+		status, ok := pb.Operation_Status_value["STATUS_"+strings.ToUpper(strings.TrimSpace(name))]
+		if !ok || status == int32(pb.Operation_STATUS_UNSPECIFIED) {
+			return nil, fmt.Errorf("unknown operation status %q, expected one of running, failed, completed, cancelled, queued, waiting, running_warning, or completed_warning", name)
+		}
+		statuses = append(statuses, pb.Operation_Status(status))
+	}
+
+	return statuses, nil
+}
+
+func newOperationExecuteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "execute <REQUEST_FILE>",
+		Short:         "Execute the operation request and write its events and response as JSON lines to stdout",
+		Long:          "Execute the operation request, which is JSON or gzipped JSON, and write its events and finally its response as JSON lines to stdout.",
+		Args:          cobra.ExactArgs(1),
+		Hidden:        true,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runOperationExecute(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+
+				return &status.ErrExit{Err: err, ExitCode: 1}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&operationExecuteArgs.baseDir, "base-dir", "", "Execute the operation with the flight plan in the directory instead of the directory of the request, e.g. a checkout of the repository in the job")
+	cmd.Flags().StringVar(&operationExecuteArgs.outDir, "out-dir", "", "Write generated modules and Terraform state to the directory instead of the out directory of the request")
+	cmd.Flags().StringVar(&operationExecuteArgs.terraformPath, "terraform-path", "", "Execute the Terraform binary at the path instead of the binary of the request")
+
+	return cmd
+}
+
+// runOperationExecute executes the operation request in the file.
+func runOperationExecute(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	req, err := operation.UnmarshalRequest(b)
+	if err != nil {
+		return err
+	}
+
+	if err := overrideOperationWorkspace(req.GetWorkspace()); err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	log := newLogger(hclog.LevelFromString(rootState.logLevel)).Named("operation")
+
+	return operation.ExecuteRequest(ctx, req, os.Stdout, log)
+}
+
+// overrideOperationWorkspace sets the directories and the Terraform binary of the workspace of the
+// request to those of the flags. The log file of the request is on the machine that dispatched it
+// so we never point to it.
+func overrideOperationWorkspace(ws *pb.Workspace) error {
+	if ws == nil {
+		return nil
+	}
+	ws.LogFile = ""
+
+	if operationExecuteArgs.baseDir != "" {
+		dir, err := filepath.Abs(operationExecuteArgs.baseDir)
+		if err != nil {
+			return err
+		}
+		// Containers mount and targets sync the flight plan directory so it has to move with it.
+		prev := ws.GetFlightplan().GetBaseDir()
+		for _, dirs := range [][]string{
+			ws.GetTfExecCfg().GetContainer().GetReadOnlyMounts(),
+			ws.GetTfExecCfg().GetTarget().GetSyncDirs(),
+		} {
+			for i := range dirs {
+				if prev != "" && dirs[i] == prev {
+					dirs[i] = dir
+				}
+			}
+		}
+		ws.Dir = dir
+		if ws.GetFlightplan() != nil {
+			ws.Flightplan.BaseDir = dir
+		}
+	}
+
+	if operationExecuteArgs.outDir != "" {
+		dir, err := filepath.Abs(operationExecuteArgs.outDir)
+		if err != nil {
+			return err
+		}
+		ws.OutDir = dir
+	}
+
+	if operationExecuteArgs.terraformPath != "" {
+		if ws.GetTfExecCfg() == nil {
+			ws.TfExecCfg = &pb.Terraform_Runner_Config{}
+		}
+		ws.TfExecCfg.BinPath = operationExecuteArgs.terraformPath
+	}
+
+	return nil
+}
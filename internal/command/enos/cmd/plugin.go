@@ -0,0 +1,238 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/plugin"
+	"github.com/hashicorp/enos/internal/schema"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// pluginTimeout is how long each variable source and report format of a plugin is allowed to take.
+const pluginTimeout = time.Minute
+
+// registerPlugins adds the commands of the plugins to the root command. Discovering plugins runs
+// every plugin, so we only do it when the command line doesn't run one of our commands, e.g. when
+// it runs the command of a plugin or shows the help or completions of the root command. Commands
+// that would shadow another command are errors of the registry, which are logged once we've set up
+// logging.
+func registerPlugins(root *cobra.Command, args []string) {
+	cmd, _, err := root.Find(args)
+	if err == nil && cmd != root {
+		return
+	}
+
+	for _, p := range plugins().Plugins {
+		for _, c := range p.Manifest.Commands {
+			if existing := findCommand(root, c.Name); existing != nil {
+				rootState.plugins.Errors = append(rootState.plugins.Errors, fmt.Errorf(
+					"%s: command %s of plugin %s conflicts with the %s command", p.Path, c.Name, p.Name(), existing.Name(),
+				))
+
+				continue
+			}
+
+			root.AddCommand(newPluginCmd(p, c))
+		}
+	}
+}
+
+var discoverPlugins sync.Once
+
+// plugins returns the plugins of the plugin directories. They're discovered the first time a
+// command needs them.
+func plugins() *plugin.Registry {
+	discoverPlugins.Do(func() {
+		rootState.plugins = plugin.NewRegistry(plugin.WithRegistryDirs(plugin.DefaultDirs(os.Getenv)...))
+		rootState.plugins.Discover(context.Background())
+		logPluginErrors()
+	})
+
+	return rootState.plugins
+}
+
+// findCommand returns the sub-command of the command with the name or alias, or nil.
+func findCommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, c := range cmd.Commands() {
+		if c.Name() == name || slices.Contains(c.Aliases, name) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// newPluginCmd returns a new command that runs the command of the plugin. Plugin commands handle
+// their own flags and output so we don't set up our UI or server for them and pass the exit code
+// of the plugin through.
+func newPluginCmd(p *plugin.Plugin, c *plugin.Command) *cobra.Command {
+	short := c.Short
+	if short == "" {
+		short = "Run the " + c.Name + " command of the " + p.Name() + " plugin"
+	}
+
+	return &cobra.Command{
+		Use:                c.Name,
+		Short:              short,
+		Long:               c.Long,
+		DisableFlagParsing: true,
+		SilenceErrors:      true,
+		SilenceUsage:       true,
+		PersistentPreRunE:  func(*cobra.Command, []string) error { return nil },
+		PersistentPostRun:  func(*cobra.Command, []string) {},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pc := p.Command(context.Background(), c.Name, args...)
+			pc.Stdin = os.Stdin
+			pc.Stdout = os.Stdout
+			pc.Stderr = os.Stderr
+
+			err := pc.Run()
+			if err == nil {
+				return nil
+			}
+
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return &status.ErrExit{Err: err, ExitCode: exitErr.ExitCode()}
+			}
+			fmt.Fprintf(os.Stderr, "Error: unable to run plugin %s: %s\n", p.Name(), err)
+
+			return &status.ErrExit{Err: err, ExitCode: 1}
+		},
+	}
+}
+
+// logPluginErrors logs the errors of discovering and registering plugins.
+func logPluginErrors() {
+	// Plugins that are discovered before we've set up logging are logged once we have.
+	if rootState.plugins == nil || rootState.enosConnection == nil {
+		return
+	}
+
+	log := rootState.enosConnection.Log.Named("plugin")
+	for _, err := range rootState.plugins.Errors {
+		log.Warn("unable to register plugin", "error", err)
+	}
+}
+
+// pluginNotifiers returns the notifiers of the plugins.
+func pluginNotifiers() []*configuredNotifier {
+	notifiers := []*configuredNotifier{}
+	for _, p := range plugins().Plugins {
+		for _, name := range p.Manifest.Notifiers {
+			notifiers = append(notifiers, &configuredNotifier{
+				name:     p.Name() + "/" + name,
+				notifier: plugin.NewNotifier(p, name),
+			})
+		}
+	}
+
+	return notifiers
+}
+
+// pluginVariables returns the environment with the values of the variable sources of the plugins
+// for the flight plan in the directory. Values are set like ENOS_VAR_ environment variables that
+// have not been set in the environment, so the environment takes precedence over variable sources
+// and the variable sources of later plugins take precedence over earlier ones.
+func pluginVariables(dir string, env []string) ([]string, error) {
+	isSet := map[string]bool{}
+	for _, e := range env {
+		if name, _, ok := strings.Cut(e, "="); ok && strings.HasPrefix(name, flightplan.EnvVarPrefix) {
+			isSet[name] = true
+		}
+	}
+
+	values := map[string]string{}
+	for _, p := range plugins().Plugins {
+		for _, name := range p.Manifest.VariableSources {
+			ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+			vars, err := p.Variables(ctx, name, dir)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("loading variables from variable source %s of plugin %s: %w", name, p.Name(), err)
+			}
+
+			for variable, val := range vars {
+				values[flightplan.EnvVarPrefix+variable] = val
+			}
+		}
+	}
+
+	added := []string{}
+	for name, val := range values {
+		if !isSet[name] {
+			added = append(added, name+"="+val)
+		}
+	}
+	// Keep the environment stable so that it doesn't invalidate cached decodes.
+	slices.Sort(added)
+
+	return append(slices.Clone(env), added...), nil
+}
+
+// writePluginReports writes the report of the run of the operations in the report formats of the
+// plugins to the reports directory of the out directory. Reports are best effort, failing to
+// write one is logged but doesn't fail the command.
+func writePluginReports(cmd *cobra.Command, res *pb.OperationResponses) {
+	if res == nil || !slices.ContainsFunc(plugins().Plugins, func(p *plugin.Plugin) bool {
+		return len(p.Manifest.ReportFormats) > 0
+	}) {
+		return
+	}
+
+	log := rootState.enosConnection.Log.Named("plugin")
+
+	dir, err := outDir(cmd)
+	if err == nil {
+		dir = filepath.Join(dir, "reports")
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		log.Warn("unable to write plugin reports", "error", err)
+
+		return
+	}
+
+	schema.Stamp(res)
+	report, err := protojson.Marshal(res)
+	if err != nil {
+		log.Warn("unable to encode run report", "error", err)
+
+		return
+	}
+
+	for _, p := range plugins().Plugins {
+		for _, format := range p.Manifest.ReportFormats {
+			ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+			out, err := p.Report(ctx, format.Name, report)
+			cancel()
+			if err != nil {
+				log.Warn("unable to format report", "plugin", p.Name(), "format", format.Name, "error", err)
+
+				continue
+			}
+
+			path := filepath.Join(dir, "enos-"+format.Name+format.Extension)
+			if err := os.WriteFile(path, out, 0o644); err != nil {
+				log.Warn("unable to write report", "plugin", p.Name(), "format", format.Name, "error", err)
+			}
+		}
+	}
+}
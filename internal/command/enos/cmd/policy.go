@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var policyCheckState = struct {
+	plan bool
+}{}
+
+// newPolicyCmd returns a new 'policy' command.
+func newPolicyCmd() *cobra.Command {
+	policyCmd := &cobra.Command{
+		Use:               "policy",
+		Short:             "Evaluate Rego policies",
+		Long:              "Evaluate Rego policies with OPA. Policies are the deny and warn rules of the enos.scenario, enos.module, and enos.plan packages, which are evaluated against the decoded scenarios, the generated modules in the Terraform JSON configuration syntax, and the JSON of the plans. Deny rules fail with error diagnostics and warn rules create warning diagnostics.",
+		PersistentPreRunE: scenarioCmdPreRun,
+		PersistentPostRun: scenarioCmdPostRun,
+	}
+
+	policyCmd.PersistentFlags().DurationVar(&scenarioState.timeout, "timeout", 1*time.Hour, "The command timeout")
+	policyCmd.PersistentFlags().BoolVar(&scenarioState.tfConfig.FailOnWarnings, "fail-on-warnings", false, "Fail if any warn rule or the decode creates warning diagnostics")
+	policyCmd.PersistentFlags().StringVarP(&scenarioState.baseDir, "chdir", "d", "", "Use the given directory as the working directory")
+	policyCmd.PersistentFlags().StringVarP(&scenarioState.outDir, "out", "o", "", "Configure the base directory where generated modules will be created")
+	policyCmd.PersistentFlags().StringSliceVar(&scenarioState.varsFilesPaths, "var-file", []string{}, "The path to use for variable values files. By default enos will load all enos*.vars.hcl files in the working directory.")
+	policyCmd.PersistentFlags().StringSliceVar(&scenarioState.regoPaths, "policy", []string{}, "The files or directories of Rego policies. Overrides the rego of the project configuration policy")
+	policyCmd.PersistentFlags().StringVar(&scenarioState.opaPath, "opa-path", "", "The path to the opa binary, which defaults to opa in the PATH")
+
+	policyCmd.AddCommand(newPolicyCheckCmd())
+
+	return policyCmd
+}
+
+// newPolicyCheckCmd returns a new 'policy check' sub-command.
+func newPolicyCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "check [FILTER]",
+		Short:             "Check scenarios against Rego policies",
+		Long:              "Generate the Terraform modules of scenarios and evaluate the scenario and module policies against them. With --plan the modules are also initialized, validated, and planned and the plan policies are evaluated against the plans. " + scenarioFilterDesc,
+		RunE:              runPolicyCheckCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	cmd.PersistentFlags().BoolVar(&policyCheckState.plan, "plan", false, "Also plan the scenarios and evaluate the plan policies")
+
+	return cmd
+}
+
+// runPolicyCheckCmd runs a policy check.
+func runPolicyCheckCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, ws, err := prepareScenarioOpReq(args)
+	if err != nil {
+		return err
+	}
+
+	if policyCheckState.plan {
+		res, err := rootState.enosConnection.Client.CheckScenarios(
+			ctx, &pb.CheckScenariosRequest{
+				Workspace: ws,
+				Filter:    sf,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		return ui.ShowOperationResponses(rootState.enosConnection.StreamOperations(ctx, res, ui))
+	}
+
+	res, err := rootState.enosConnection.Client.GenerateScenarios(
+		ctx, &pb.GenerateScenariosRequest{
+			Workspace: ws,
+			Filter:    sf,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowOperationResponses(rootState.enosConnection.StreamOperations(ctx, res, ui))
+}
@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// projectSettings maps the settings of the project configuration file to the flags that they
+// configure. Every setting can also be set with an ENOS_<SETTING> environment variable.
+var projectSettings = []struct {
+	setting string
+	flag    string
+}{
+	{setting: "out_dir", flag: "out"},
+	{setting: "format", flag: "format"},
+	{setting: "worker_count", flag: "worker-count"},
+	{setting: "max_decode_workers", flag: "max-decode-workers"},
+	{setting: "timeout", flag: "timeout"},
+	{setting: "lock_timeout", flag: "lock-timeout"},
+	{setting: "strict_schema", flag: "strict-schema"},
+	{setting: "fail_on_warnings", flag: "fail-on-warnings"},
+	{setting: "log_file", flag: "log-file"},
+	{setting: "locale", flag: "locale"},
+	{setting: "message_catalog", flag: "message-catalog"},
+	{setting: "statsd_address", flag: "statsd-address"},
+	{setting: "statsd_prefix", flag: "statsd-prefix"},
+	{setting: "ci", flag: "ci"},
+	{setting: "reuse_terraform_dir", flag: "reuse-terraform-dir"},
+}
+
+// projectSettingEnvVar returns the name of the environment variable for a project setting.
+func projectSettingEnvVar(setting string) string {
+	return "ENOS_" + strings.ToUpper(setting)
+}
+
+// workingDir returns the working directory of the command, which is either set with --chdir or
+// the current working directory.
+func workingDir(cmd *cobra.Command) (string, error) {
+	if f := cmd.Flags().Lookup("chdir"); f != nil && f.Value.String() != "" {
+		return f.Value.String(), nil
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine current working directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// outDir returns the out directory of the command, which is either set with --out or defaults to
+// the .enos directory of the working directory.
+func outDir(cmd *cobra.Command) (string, error) {
+	if f := cmd.Flags().Lookup("out"); f != nil && f.Value.String() != "" {
+		return f.Value.String(), nil
+	}
+
+	dir, err := workingDir(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, ".enos"), nil
+}
+
+// applyProjectConfig loads the project configuration file from the working directory and uses it
+// and the environment to set the defaults of any flags that have not been set. Flags take
+// precedence over the environment, which takes precedence over the project configuration file.
+func applyProjectConfig(cmd *cobra.Command) error {
+	dir, err := workingDir(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg := flightplan.NewProjectConfig()
+	diags := cfg.Load(dir)
+	if diags.HasErrors() {
+		return &diagnostics.Error{Diags: diagnostics.FromHCL(cfg.Files(), diags)}
+	}
+	rootState.projectConfig = cfg
+	settings := cfg.Settings()
+
+	for _, s := range projectSettings {
+		f := cmd.Flags().Lookup(s.flag)
+		if f == nil || f.Changed {
+			continue
+		}
+
+		envVar := projectSettingEnvVar(s.setting)
+		if val, ok := os.LookupEnv(envVar); ok {
+			if err := f.Value.Set(val); err != nil {
+				return fmt.Errorf("invalid value %q for %s: %w", val, envVar, err)
+			}
+
+			continue
+		}
+
+		if val, ok := settings[s.setting]; ok {
+			if err := f.Value.Set(val); err != nil {
+				return fmt.Errorf("invalid value %q for %s in %s: %w", val, s.setting, cfg.Path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// warningBudgetDiagnostics returns the error diagnostics of the warnings of the diagnostics that
+// exceed the warning budget of the project configuration, if it has one.
+func warningBudgetDiagnostics(diags ...[]*pb.Diagnostic) []*pb.Diagnostic {
+	if rootState.projectConfig == nil {
+		return nil
+	}
+
+	return rootState.projectConfig.Warnings.Check(diagnostics.Concat(diags...))
+}
+
+// withWarningBudget adds the diagnostics of the warnings of the operations that exceed the warning
+// budget of the project configuration to the operation responses.
+func withWarningBudget(res *pb.OperationResponses) {
+	diags := [][]*pb.Diagnostic{res.GetDecode().GetDiagnostics(), res.GetDiagnostics()}
+	for _, r := range res.GetResponses() {
+		diags = append(diags, diagnostics.OpResDiags(r))
+	}
+
+	res.Diagnostics = append(res.GetDiagnostics(), warningBudgetDiagnostics(diags...)...)
+}
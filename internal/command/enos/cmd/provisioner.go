@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/plugin"
+	"github.com/hashicorp/enos/internal/provisioner"
+	"github.com/hashicorp/enos/internal/ui/status"
+)
+
+// newProvisionerCmd returns the hidden command that the generated modules of provisioner steps
+// execute. It runs with the stdio of Terraform so we don't set up our UI or server for it.
+func newProvisionerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "provisioner",
+		Short:             "Execute the provisioner of a provisioner step",
+		Hidden:            true,
+		PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+		PersistentPostRun: func(*cobra.Command, []string) {},
+	}
+
+	cmd.AddCommand(newProvisionerOperationCmd(provisioner.OperationApply))
+	cmd.AddCommand(newProvisionerOperationCmd(provisioner.OperationDestroy))
+
+	return cmd
+}
+
+// newProvisionerOperationCmd returns the command that executes the operation of the provisioner of
+// the request in the ENOS_PROVISIONER_REQUEST environment variable for the module in the directory.
+func newProvisionerOperationCmd(op string) *cobra.Command {
+	return &cobra.Command{
+		Use:           op + " <MODULE_DIR>",
+		Short:         "Execute the " + op + " operation of the provisioner of a provisioner step",
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runProvisioner(op, args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+
+				return &status.ErrExit{Err: err, ExitCode: 1}
+			}
+
+			return nil
+		},
+	}
+}
+
+// runProvisioner executes the operation of the provisioner of the request for the module in the
+// directory.
+func runProvisioner(op string, dir string) error {
+	req, err := provisioner.DecodeRequest(os.Getenv(provisioner.EnvRequest))
+	if err != nil {
+		return err
+	}
+	req.Operation = op
+
+	var p provisioner.Provisioner
+	switch req.Provisioner {
+	case flightplan.ProvisionerTypeExec:
+		p = provisioner.NewExec()
+	case flightplan.ProvisionerTypeAnsible:
+		p = provisioner.NewAnsible()
+	default:
+		p, err = pluginProvisioner(req.Provisioner)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	return provisioner.Run(ctx, p, req, dir)
+}
+
+// pluginProvisioner returns the provisioner of the plugins with the name.
+func pluginProvisioner(name string) (provisioner.Provisioner, error) {
+	for _, p := range plugins().Plugins {
+		if slices.Contains(p.Manifest.Provisioners, name) {
+			return plugin.NewProvisioner(p, name, os.Stderr), nil
+		}
+	}
+
+	return nil, fmt.Errorf("provisioner %s is not a built-in provisioner or the provisioner of a plugin", name)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/hashicorp/enos/internal/artifact"
+	"github.com/hashicorp/enos/internal/ci"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/notify"
+	"github.com/hashicorp/enos/internal/schema"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// publishTimeout is how long uploading the artifacts of a run to each publisher is allowed to take.
+const publishTimeout = 10 * time.Minute
+
+// publishArtifacts uploads the artifacts, reports, and logs of the run of the operations to the
+// publishers of the project configuration and adds the artifacts that have been uploaded to the
+// responses. The files of the paths of publishers are uploaded under artifacts/, the log file
+// under logs/, and the JSON and JUnit reports of the run under reports/. The JSON report includes
+// the artifacts of every other file. Publishing is best effort, failing to upload a file is
+// logged but doesn't fail the command.
+func publishArtifacts(cmd *cobra.Command, res *pb.OperationResponses) {
+	if res == nil || rootState.noPublish || rootState.projectConfig == nil ||
+		len(rootState.projectConfig.Publishers) == 0 {
+		return
+	}
+
+	log := rootState.enosConnection.Log.Named("publish")
+
+	dir, err := workingDir(cmd)
+	if err != nil {
+		log.Warn("unable to publish artifacts", "error", err)
+
+		return
+	}
+
+	junit := &bytes.Buffer{}
+	summary := notify.NewSummary(cmd.CommandPath(), res, scenarioState.tfConfig.FailOnWarnings)
+	if err := ci.NewJUnit(summary).Write(junit); err != nil {
+		log.Warn("unable to encode junit report", "error", err)
+	}
+
+	publishers := make([]*artifact.Publisher, len(rootState.projectConfig.Publishers))
+	for i, cfg := range rootState.projectConfig.Publishers {
+		publishers[i] = newPublisher(cfg)
+
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		artifacts, err := publishers[i].Publish(ctx, publishFiles(log, dir, cfg.Paths)...)
+		if err != nil {
+			log.Warn("unable to upload artifacts", "type", cfg.Type, "error", err)
+		}
+		res.Artifacts = append(res.Artifacts, artifacts...)
+
+		if junit.Len() > 0 {
+			a, err := publishers[i].PublishContents(ctx, "reports/"+ci.JUnitReportFile, junit.Bytes())
+			if err != nil {
+				log.Warn("unable to upload junit report", "type", cfg.Type, "error", err)
+			} else {
+				res.Artifacts = append(res.Artifacts, a)
+			}
+		}
+		cancel()
+	}
+
+	schema.Stamp(res)
+	report, err := protojson.Marshal(res)
+	if err != nil {
+		log.Warn("unable to encode run report", "error", err)
+
+		return
+	}
+
+	for i, pub := range publishers {
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		a, err := pub.PublishContents(ctx, "reports/report.json", report)
+		cancel()
+		if err != nil {
+			log.Warn("unable to upload run report", "type", rootState.projectConfig.Publishers[i].Type, "error", err)
+
+			continue
+		}
+		res.Artifacts = append(res.Artifacts, a)
+	}
+}
+
+// newPublisher returns a new publisher for the publisher configuration. Values are expanded with
+// the environment.
+func newPublisher(cfg *flightplan.ProjectPublisher) *artifact.Publisher {
+	var store artifact.Store
+
+	switch cfg.Type {
+	case flightplan.PublisherTypeGCS:
+		store = artifact.NewGCS(
+			artifact.WithGCSBucket(os.ExpandEnv(cfg.Bucket)),
+			artifact.WithGCSToken(os.ExpandEnv(cfg.Token)),
+			artifact.WithGCSCredentialsFile(os.ExpandEnv(cfg.Credentials)),
+		)
+	case flightplan.PublisherTypeAzure:
+		store = artifact.NewAzure(
+			artifact.WithAzureAccount(os.ExpandEnv(cfg.Account)),
+			artifact.WithAzureContainer(os.ExpandEnv(cfg.Container)),
+			artifact.WithAzureSASToken(os.ExpandEnv(cfg.SASToken)),
+			artifact.WithAzureAccountKey(os.ExpandEnv(cfg.AccountKey)),
+			artifact.WithAzureEndpoint(os.ExpandEnv(cfg.Endpoint)),
+		)
+	default:
+		store = artifact.NewS3(
+			artifact.WithS3Bucket(os.ExpandEnv(cfg.Bucket)),
+			artifact.WithS3Region(os.ExpandEnv(cfg.Region)),
+			artifact.WithS3Endpoint(os.ExpandEnv(cfg.Endpoint)),
+		)
+	}
+
+	return artifact.NewPublisher(store,
+		artifact.WithPublisherPrefix(os.ExpandEnv(cfg.Prefix)),
+		artifact.WithPublisherRunID(os.ExpandEnv(cfg.RunID)),
+	)
+}
+
+// publishFiles returns the files of the log file and the globs of the paths, which are relative to
+// the working directory. Directories that match a glob are published recursively.
+func publishFiles(log hclog.Logger, dir string, paths []string) []*artifact.File {
+	files := []*artifact.File{}
+
+	if logFile := rootState.logFilePath(); logFile != "" {
+		files = append(files, &artifact.File{Path: logFile, Name: "logs/" + filepath.Base(logFile)})
+	}
+
+	seen := map[string]bool{}
+	for _, glob := range paths {
+		glob = os.ExpandEnv(glob)
+		if !filepath.IsAbs(glob) {
+			glob = filepath.Join(dir, glob)
+		}
+
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			log.Warn("invalid artifact path", "path", glob, "error", err)
+
+			continue
+		}
+
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() || seen[path] {
+					return nil
+				}
+				seen[path] = true
+
+				name, err := filepath.Rel(dir, path)
+				if err != nil || !filepath.IsLocal(name) {
+					name = filepath.Base(path)
+				}
+				files = append(files, &artifact.File{Path: path, Name: "artifacts/" + filepath.ToSlash(name)})
+
+				return nil
+			})
+			if err != nil {
+				log.Warn("unable to read artifact path", "path", match, "error", err)
+			}
+		}
+	}
+
+	return files
+}
@@ -0,0 +1,22 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newReportCmd returns a new 'report' command.
+func newReportCmd() *cobra.Command {
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Enos run reports",
+		Long:  "Work with the JSON and JUnit reports of scenario runs",
+	}
+
+	reportCmd.AddCommand(newReportMergeCmd())
+	reportCmd.AddCommand(newReportTrendsCmd())
+
+	return reportCmd
+}
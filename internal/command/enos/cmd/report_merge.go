@@ -0,0 +1,189 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/report"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var reportMergeState = &struct {
+	baseDir        string
+	varsFilesPaths []string
+	filter         string
+	noCoverage     bool
+	junitFile      string
+	failOnWarnings bool
+	timeout        time.Duration
+}{}
+
+// newReportMergeCmd returns a new 'report merge' sub-command.
+func newReportMergeCmd() *cobra.Command {
+	mergeCmd := &cobra.Command{
+		Use:   "merge <REPORT>...",
+		Short: "Merge the reports of CI shards",
+		Long:  "Merge the JSON reports, i.e. the --format json output of scenario check, launch, run, and destroy, and the JUnit reports of CI shards that each ran a slice of the matrix into a single report. Directories are searched recursively for .json and .xml reports. The merged report fails if any scenario failed, if any run had errors, or if any scenario of the flight plan in the working directory has not been reported. Scenarios that have been reported more than once are warnings.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runReportMergeCmd,
+	}
+
+	mergeCmd.PersistentFlags().StringVarP(&reportMergeState.baseDir, "chdir", "d", "", "Use the given directory as the working directory")
+	mergeCmd.PersistentFlags().StringSliceVar(&reportMergeState.varsFilesPaths, "var-file", []string{}, "The path to use for variable values files. By default enos will load all enos*.vars.hcl files in the working directory.")
+	mergeCmd.PersistentFlags().StringVar(&reportMergeState.filter, "filter", "", "Only expect the scenarios of the flight plan that match the scenario filter to be covered, e.g. when the shards ran a filtered matrix")
+	mergeCmd.PersistentFlags().BoolVar(&reportMergeState.noCoverage, "no-coverage", false, "Don't determine the coverage of the scenarios of the flight plan")
+	mergeCmd.PersistentFlags().StringVar(&reportMergeState.junitFile, "junit-file", "", "Write the merged JUnit report to the path")
+	mergeCmd.PersistentFlags().BoolVar(&reportMergeState.failOnWarnings, "fail-on-warnings", false, "Fail if scenarios of JSON reports or the merged report have warnings, e.g. of duplicated scenarios")
+	mergeCmd.PersistentFlags().DurationVar(&reportMergeState.timeout, "timeout", 10*time.Minute, "The command timeout")
+
+	return mergeCmd
+}
+
+// runReportMergeCmd merges the reports.
+func runReportMergeCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), reportMergeState.timeout)
+	defer cancel()
+
+	ui.Settings().FailOnWarnings = reportMergeState.failOnWarnings
+	opts := []report.MergerOpt{report.WithMergerFailOnWarnings(reportMergeState.failOnWarnings)}
+
+	var decode *pb.DecodeResponse
+	if !reportMergeState.noCoverage {
+		expected, res, err := reportMergeExpected(ctx, cmd)
+		if err != nil {
+			return ui.ShowReportMerge(&pb.MergeReportsResponse{Diagnostics: diagnostics.FromErr(err)})
+		}
+		if res != nil {
+			decode = res.GetDecode()
+			if diagnostics.HasErrors(res.GetDiagnostics(), decode.GetDiagnostics()) {
+				return ui.ShowReportMerge(&pb.MergeReportsResponse{
+					Diagnostics: res.GetDiagnostics(),
+					Decode:      decode,
+				})
+			}
+			opts = append(opts, report.WithMergerExpected(expected))
+		}
+	}
+
+	merger := report.NewMerger(opts...)
+	merger.AddDiagnostics(addReportFiles(merger, args))
+
+	res := merger.Response()
+	res.Decode = decode
+
+	if reportMergeState.junitFile != "" {
+		if err := writeReportMergeJUnit(merger, reportMergeState.junitFile); err != nil {
+			res.Diagnostics = append(res.Diagnostics, diagnostics.FromErr(err)...)
+		}
+	}
+
+	return ui.ShowReportMerge(res)
+}
+
+// reportMergeExpected returns the scenarios of the flight plan in the working directory that
+// match the filter. It returns nil responses if there is no flight plan.
+func reportMergeExpected(ctx context.Context, cmd *cobra.Command) ([]*pb.Ref_Scenario, *pb.ListScenariosResponse, error) {
+	dir, err := workingDir(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fp, err := readFlightPlanConfig(dir, reportMergeState.varsFilesPaths)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(fp.GetEnosHcl()) == 0 {
+		return nil, nil, nil
+	}
+
+	sf, err := flightplan.ParseScenarioFilter(strings.Fields(reportMergeState.filter))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := listScenarios(ctx, &pb.ListScenariosRequest{
+		Workspace: &pb.Workspace{
+			Flightplan: fp,
+			Limits:     rootState.limits,
+		},
+		Filter: sf.Proto(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res.GetScenarios(), res, nil
+}
+
+// addReportFiles adds the reports of the args to the merger. It returns the diagnostics of the
+// reports that can't be read or decoded.
+func addReportFiles(merger *report.Merger, args []string) []*pb.Diagnostic {
+	paths, diags := reportMergeFiles(args)
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if err == nil {
+			err = merger.Add(path, contents)
+		}
+		diags = append(diags, diagnostics.FromErr(err)...)
+	}
+
+	return diags
+}
+
+// reportMergeFiles returns the paths of the reports of the args. Directories are searched
+// recursively for .json and .xml files. It returns the diagnostics of the paths that can't be read.
+func reportMergeFiles(args []string) ([]string, []*pb.Diagnostic) {
+	paths := []string{}
+	diags := []*pb.Diagnostic{}
+
+	for _, arg := range args {
+		err := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			// Files that are given explicitly are always merged.
+			if path == arg || filepath.Ext(path) == ".json" || filepath.Ext(path) == ".xml" {
+				paths = append(paths, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			diags = append(diags, diagnostics.FromErr(fmt.Errorf("reading reports: %w", err))...)
+		}
+	}
+
+	return paths, diags
+}
+
+// writeReportMergeJUnit writes the merged JUnit report to the path.
+func writeReportMergeJUnit(merger *report.Merger, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return merger.JUnit().Write(f)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/report"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var reportTrendsState = &struct {
+	durationThreshold float64
+	minDuration       time.Duration
+	markdownFile      string
+}{}
+
+// newReportTrendsCmd returns a new 'report trends' sub-command.
+func newReportTrendsCmd() *cobra.Command {
+	trendsCmd := &cobra.Command{
+		Use:   "trends <CURRENT> <PREVIOUS>...",
+		Short: "Compare a run with previous runs",
+		Long:  "Compare the reports of the current run with the reports of previous runs and show the newly failing and newly fixed scenarios, the scenarios whose duration regressed, and flaky scenarios. Enos doesn't keep the history of runs, so each run is given as a JSON or JUnit report or as a directory of reports, e.g. the reports of shards or the reports that have been uploaded by publish blocks. The first run is the current run, the previous runs follow from the most recent to the oldest. The reports of each run are merged like 'report merge' merges them.",
+		Args:  cobra.MinimumNArgs(2),
+		RunE:  runReportTrendsCmd,
+	}
+
+	trendsCmd.PersistentFlags().Float64Var(&reportTrendsState.durationThreshold, "duration-threshold", report.DefaultDurationThreshold, "The change of the duration of a scenario relative to the median of its previous durations that is a regression, e.g. 0.2 for 20% slower")
+	trendsCmd.PersistentFlags().DurationVar(&reportTrendsState.minDuration, "min-duration", report.DefaultMinDurationRegression, "The minimum increase of the duration of a scenario that is a regression")
+	trendsCmd.PersistentFlags().StringVar(&reportTrendsState.markdownFile, "markdown-file", "", "Write the trends as Markdown to the path, e.g. $GITHUB_STEP_SUMMARY")
+
+	return trendsCmd
+}
+
+// runReportTrendsCmd compares the reports of the runs.
+func runReportTrendsCmd(cmd *cobra.Command, args []string) error {
+	// The failures of the runs are what the trends are about, so only the diagnostics of reports
+	// that can't be read or decoded fail the comparison.
+	diags := []*pb.Diagnostic{}
+	runs := make([]*pb.MergeReportsResponse, len(args))
+	for i, arg := range args {
+		merger := report.NewMerger()
+		diags = append(diags, addReportFiles(merger, []string{arg})...)
+		runs[i] = merger.Response()
+	}
+
+	res := report.NewTrends(
+		report.WithTrendsDurationThreshold(reportTrendsState.durationThreshold),
+		report.WithTrendsMinDuration(reportTrendsState.minDuration),
+	).Compare(runs[0], runs[1:]...)
+	res.Diagnostics = append(res.Diagnostics, diags...)
+
+	if reportTrendsState.markdownFile != "" {
+		if err := writeReportTrendsMarkdown(res, reportTrendsState.markdownFile); err != nil {
+			res.Diagnostics = append(res.Diagnostics, diagnostics.FromErr(err)...)
+		}
+	}
+
+	return ui.ShowReportTrends(res)
+}
+
+// writeReportTrendsMarkdown appends the trends as Markdown to the path.
+func writeReportTrendsMarkdown(res *pb.ReportTrendsResponse, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(report.TrendsMarkdown(res))
+
+	return err
+}
@@ -12,14 +12,23 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"runtime/trace"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 
+	"github.com/hashicorp/enos/internal/ci"
 	"github.com/hashicorp/enos/internal/client"
 	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/i18n"
+	"github.com/hashicorp/enos/internal/logfile"
+	"github.com/hashicorp/enos/internal/metrics"
+	"github.com/hashicorp/enos/internal/plugin"
 	"github.com/hashicorp/enos/internal/server"
 	uipkg "github.com/hashicorp/enos/internal/ui"
 	"github.com/hashicorp/enos/internal/ui/status"
@@ -38,6 +47,8 @@ var rootCmd = &cobra.Command{
 type rootStateS struct {
 	logLevel       string // client log level
 	logLevelServer string // server log level
+	quiet          bool
+	verbosity      int
 	grpcListenAddr string
 	grpcMaxSend    int
 	grpcMaxRecv    int
@@ -47,12 +58,39 @@ type rootStateS struct {
 	enosServer     *server.ServiceV1
 	enosConnection *client.Connection
 	operatorConfig *pb.Operator_Config
-	profile        bool
+	limits         *pb.ResourceLimits
+	profile        []string
+	decodeCache    bool
+	strictSchema   bool
+	debugTiming    bool
+	diagPalette    string
+	diagASCII      bool
+	diagColors     map[string]string
+	hyperlinks     string
+	groupDiags     bool
+	width          uint32
+	noTruncate     bool
+	locale         string
+	msgCatalog     string
+	ci             string
+	ciEnv          pb.UI_Settings_CI
+	statsdAddr     string
+	statsdPrefix   string
+	metrics        metrics.Sink
+	noNotify       bool
+	noPublish      bool
+	projectConfig  *flightplan.ProjectConfig
+	project        string
+	plugins        *plugin.Registry
+	logFile        string
+	logFileOut     *logfile.File
 	cpuProfileOut  io.ReadWriteCloser
+	traceOut       io.ReadWriteCloser
 }
 
 var rootState = &rootStateS{
-	operatorConfig: &pb.Operator_Config{},
+	operatorConfig: &pb.Operator_Config{Nomad: &pb.Operator_Nomad{}},
+	limits:         &pb.ResourceLimits{},
 }
 
 // ui is our default CLI UI for things that have not been migrated to use
@@ -64,18 +102,62 @@ func Execute() {
 	rootCmd.AddCommand(newVersionCmd())
 	rootCmd.AddCommand(newScenarioCmd())
 	rootCmd.AddCommand(newFmtCmd())
+	rootCmd.AddCommand(newLSPCmd())
+	rootCmd.AddCommand(newLintCmd())
+	rootCmd.AddCommand(newFlightPlanCmd())
+	rootCmd.AddCommand(newPolicyCmd())
+	rootCmd.AddCommand(newFixCmd())
+	rootCmd.AddCommand(newMigrateCmd())
+	rootCmd.AddCommand(newSchemaCmd())
+	rootCmd.AddCommand(newReportCmd())
+	rootCmd.AddCommand(newServerCmd())
+	rootCmd.AddCommand(newProvisionerCmd())
+	rootCmd.AddCommand(newOperationCmd())
+	rootCmd.AddCommand(newTargetCmd())
 
 	rootCmd.PersistentFlags().StringVar(&rootState.logLevel, "log-level", "info", "The log level for client output. Supported levels are error, warn, info, debug, and trace")
 	rootCmd.PersistentFlags().StringVar(&rootState.logLevelServer, "server-log-level", "error", "The log level for server output. Supported leves are error, warn, info, and debug")
+	rootCmd.PersistentFlags().BoolVarP(&rootState.quiet, "quiet", "q", false, "Only show errors. Overrides --log-level and --server-log-level")
+	rootCmd.PersistentFlags().CountVarP(&rootState.verbosity, "verbose", "v", "Show debug output, including decode timings, Terraform command lines, and environment resolution. Repeat for trace output, e.g. -vv. Overrides --log-level and --server-log-level")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	rootCmd.PersistentFlags().StringVar(&rootState.logFile, "log-file", "", "Also write the complete trace logs, including the output of every Terraform command, to the file. Relative paths are relative to the out directory. Log files are rotated when they reach 10MiB")
 	rootCmd.PersistentFlags().StringVar(&rootState.grpcListenAddr, "grpc-listen", "http://localhost:3205", "The gRPC server listen address")
 	rootCmd.PersistentFlags().IntVar(&rootState.grpcMaxRecv, "grpc-max-recv", 1024*1025*20, "The gRPC max message receive size in bytes")
 	rootCmd.PersistentFlags().IntVar(&rootState.grpcMaxSend, "grpc-max-send", 1024*1025*20, "The gRPC max message send size in bytes")
-	rootCmd.PersistentFlags().StringVar(&rootState.format, "format", "text", "The output format to use: text or json")
+	rootCmd.PersistentFlags().StringVar(&rootState.format, "format", "text", "The output format to use: text, json, yaml, or html")
 	rootCmd.PersistentFlags().StringVar(&rootState.stdoutPath, "stdout", "", "The path to write output. (default $STDOUT)")
 	rootCmd.PersistentFlags().StringVar(&rootState.stderrPath, "stderr", "", "The path to write error output. (default $STDERR)")
 	rootCmd.PersistentFlags().Int32Var(&rootState.operatorConfig.WorkerCount, "worker-count", 4, "The number of scenario operation workers")
-	rootCmd.PersistentFlags().BoolVar(&rootState.profile, "profile", false, "Enable Go profiling")
-	_ = rootCmd.PersistentFlags().MarkHidden("profile")
+	rootCmd.PersistentFlags().StringVar(&rootState.operatorConfig.Nomad.Job, "nomad-job", "", "Execute operations by dispatching the parameterized Nomad job for each operation instead of executing them locally. The number of operations that execute concurrently is limited by --worker-count")
+	rootCmd.PersistentFlags().StringVar(&rootState.operatorConfig.Nomad.Address, "nomad-address", "", "The address of the Nomad API. (default $NOMAD_ADDR or http://127.0.0.1:4646)")
+	rootCmd.PersistentFlags().StringVar(&rootState.operatorConfig.Nomad.Namespace, "nomad-namespace", "", "The Nomad namespace of the --nomad-job. (default $NOMAD_NAMESPACE)")
+	rootCmd.PersistentFlags().StringVar(&rootState.operatorConfig.Nomad.Region, "nomad-region", "", "The Nomad region of the --nomad-job. (default $NOMAD_REGION)")
+	rootCmd.PersistentFlags().StringVar(&rootState.operatorConfig.Nomad.Task, "nomad-task", "", "The task of the --nomad-job that executes operations. (default the only task of the job)")
+	rootCmd.PersistentFlags().Int32Var(&rootState.limits.MaxDecodeWorkers, "max-decode-workers", 0, "The maximum number of goroutines that will decode flight plans concurrently. (default one per CPU)")
+	rootCmd.PersistentFlags().Uint64Var(&rootState.limits.MaxMatrixMemory, "max-matrix-memory", 0, "A hint of the maximum memory in bytes that expanding a single scenario matrix may use. Decoding fails if the hint is exceeded. (default no limit)")
+	rootCmd.PersistentFlags().Int32Var(&rootState.limits.Niceness, "niceness", 0, "The niceness between 0 and 19 used to execute Terraform during operations. Only supported on Linux")
+	rootCmd.PersistentFlags().BoolVar(&rootState.decodeCache, "decode-cache", true, "Reuse parsed flight plan files and cache decoded scenario lists in the out directory while the flight plan has not changed")
+	rootCmd.PersistentFlags().StringSliceVar(&rootState.profile, "profile", nil, "Write Go profiles of decoding and operation execution to the current directory. Supported profiles are cpu, mem, and trace")
+	rootCmd.PersistentFlags().BoolVar(&rootState.strictSchema, "strict-schema", false, "Fail to decode flight plans that contain configuration that is not part of a blocks schema instead of warning")
+	rootCmd.PersistentFlags().BoolVar(&rootState.debugTiming, "debug-timing", false, "Write a summary of where flight plan decode time went for each block to stderr")
+	rootCmd.PersistentFlags().StringVar(&rootState.diagPalette, "diagnostic-palette", "default", "The color palette of diagnostics: default or colorblind")
+	rootCmd.PersistentFlags().BoolVar(&rootState.diagASCII, "diagnostic-ascii", false, "Render diagnostics with ASCII characters only")
+	rootCmd.PersistentFlags().StringVar(&rootState.hyperlinks, "hyperlinks", "auto", "Render file locations as terminal hyperlinks: auto, always, or never. When auto, hyperlinks are rendered in terminals that are known to support them")
+	rootCmd.PersistentFlags().StringToStringVar(&rootState.diagColors, "diagnostic-color", nil, "Override a color of the diagnostic palette, e.g. error=light_red. Supported keys are error, warning, highlight, and value")
+	rootCmd.PersistentFlags().BoolVar(&rootState.groupDiags, "group-diagnostics", false, "Group diagnostics by file with a count of errors and warnings for each file and a summary of all files")
+	rootCmd.PersistentFlags().Uint32Var(&rootState.width, "width", 0, "The width of text output. (default the width of the terminal or 78)")
+	rootCmd.PersistentFlags().BoolVar(&rootState.noTruncate, "no-truncate", false, "Don't truncate tables and lists that are wider than the terminal")
+	rootCmd.PersistentFlags().StringVar(&rootState.locale, "locale", "", "The locale of text output, e.g. de-DE. (default the locale of LC_ALL, LC_MESSAGES, or LANG)")
+	rootCmd.PersistentFlags().BoolVar(&rootState.noNotify, "no-notify", false, "Don't send the summaries of scenario runs to the notifiers of the project configuration")
+	rootCmd.PersistentFlags().BoolVar(&rootState.noPublish, "no-publish", false, "Don't upload the artifacts, reports, and logs of scenario runs to the publishers of the project configuration")
+	rootCmd.PersistentFlags().StringVar(&rootState.statsdAddr, "statsd-address", "", "Emit the metrics of decodes and operations to the StatsD server at the host and port, e.g. localhost:8125")
+	rootCmd.PersistentFlags().StringVar(&rootState.statsdPrefix, "statsd-prefix", metrics.DefaultStatsDPrefix, "The prefix of the names of StatsD metrics")
+	rootCmd.PersistentFlags().StringVar(&rootState.ci, "ci", "auto", "Tailor output to the CI environment: auto, none, github-actions, gitlab, circleci, or buildkite. When auto, the CI environment is detected from its environment variables")
+	rootCmd.PersistentFlags().StringVar(&rootState.project, "project", "", "Scope the workspace and the operations to the named project. The project is registered with the server with the flight plan directory of the command, and only the operations of the project are shown")
+	rootCmd.PersistentFlags().StringVar(&rootState.msgCatalog, "message-catalog", "", "The path to a JSON message catalog that adds to or overrides the built-in messages of the locale")
+
+	// Plugins add commands so we have to register them before we execute.
+	registerPlugins(rootCmd, os.Args[1:])
 
 	if err := rootCmd.Execute(); err != nil {
 		var exitErr *status.ErrExit
@@ -102,15 +184,21 @@ func Execute() {
 
 func setupCLIUI() error {
 	uiCfg := &pb.UI_Settings{
-		Width:          78,
-		Format:         pb.UI_Settings_FORMAT_BASIC_TEXT,
-		FailOnWarnings: scenarioState.tfConfig.FailOnWarnings,
+		Width:            rootState.width,
+		Format:           pb.UI_Settings_FORMAT_BASIC_TEXT,
+		FailOnWarnings:   scenarioState.tfConfig.FailOnWarnings,
+		GroupDiagnostics: rootState.groupDiags,
+		NoTruncate:       rootState.noTruncate,
 	}
 
 	if rootState.format == "json" {
 		uiCfg.Format = pb.UI_Settings_FORMAT_JSON
 	}
 
+	if rootState.format == "yaml" {
+		uiCfg.Format = pb.UI_Settings_FORMAT_YAML
+	}
+
 	if rootState.format == "html" {
 		uiCfg.Format = pb.UI_Settings_FORMAT_HTML
 	}
@@ -118,16 +206,52 @@ func setupCLIUI() error {
 	if term.IsTerminal(int(os.Stdout.Fd())) {
 		uiCfg.IsTty = true
 		uiCfg.UseColor = true
-		width, _, err := term.GetSize(int(os.Stdout.Fd()))
-		if err != nil {
-			uiCfg.Width = uint32(width)
-		}
+	}
+
+	if uiCfg.GetWidth() == 0 {
+		uiCfg.Width = terminalWidth()
+	}
+
+	// CI environments get collapsible log groups, annotations, and no output that is meant for
+	// terminals, even when they run us in a pseudo terminal. If it's invalid we'll not tailor the
+	// output so that we're still able to show the error.
+	var ciErr error
+	rootState.ciEnv, ciErr = ci.Parse(rootState.ci, os.Getenv)
+	if rootState.ciEnv != pb.UI_Settings_CI_UNSPECIFIED {
+		uiCfg.Ci = rootState.ciEnv
+		uiCfg.IsTty = false
 	}
 
 	if rootState.stderrPath != "" {
 		uiCfg.StderrPath = rootState.stderrPath
 	}
 
+	switch rootState.hyperlinks {
+	case "always":
+		uiCfg.Hyperlinks = true
+	case "never":
+	case "auto":
+		uiCfg.Hyperlinks = uiCfg.GetIsTty() && uiCfg.GetStdoutPath() == "" && uiCfg.GetStderrPath() == "" &&
+			terminalSupportsHyperlinks()
+	default:
+		return fmt.Errorf("unsupported hyperlinks mode %q, expected one of auto, always, or never", rootState.hyperlinks)
+	}
+
+	// Configure our diagnostic theme. If it's invalid we'll use the default theme so that we're
+	// still able to show the error.
+	palette, themeErr := diagnostics.ParseThemePalette(rootState.diagPalette)
+	if themeErr == nil {
+		uiCfg.DiagnosticTheme = &pb.UI_DiagnosticTheme{
+			Palette: palette,
+			Ascii:   rootState.diagASCII,
+			Colors:  rootState.diagColors,
+		}
+		_, themeErr = diagnostics.ThemeFromProto(uiCfg.GetDiagnosticTheme())
+		if themeErr != nil {
+			uiCfg.DiagnosticTheme = nil
+		}
+	}
+
 	if rootState.stdoutPath != "" {
 		uiCfg.StdoutPath = rootState.stdoutPath
 	}
@@ -146,9 +270,102 @@ func setupCLIUI() error {
 	}
 
 	var err error
+	// Configure our message catalog. If it's invalid we'll use the default messages so that we're
+	// still able to show the error.
+	_, catalogErr := i18n.New(rootState.locale, i18n.WithCatalogFile(rootState.msgCatalog))
+	if catalogErr == nil {
+		uiCfg.Locale = rootState.locale
+		uiCfg.MessageCatalog = rootState.msgCatalog
+	}
+
 	ui, err = uipkg.New(uiCfg)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if themeErr != nil {
+		return themeErr
+	}
+
+	if ciErr != nil {
+		return ciErr
+	}
+
+	return catalogErr
+}
+
+// terminalSupportsHyperlinks returns whether or not the terminal is known to support OSC 8
+// hyperlinks. We can't query the terminal so we rely on the environment that they set.
+func terminalSupportsHyperlinks() bool {
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "vscode", "WezTerm", "Hyper", "ghostty":
+		return true
+	default:
+	}
+
+	for _, env := range []string{"WT_SESSION", "KITTY_WINDOW_ID", "DOMTERM"} {
+		if os.Getenv(env) != "" {
+			return true
+		}
+	}
+
+	// VTE based terminals, e.g. GNOME Terminal, support hyperlinks since 0.50.
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+
+	return false
+}
+
+// terminalWidth returns the width of the terminal. If stdout is not a terminal the width is taken
+// from the COLUMNS environment variable and otherwise defaults to 78.
+func terminalWidth() uint32 {
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+			return uint32(width)
+		}
+	}
+
+	if width, err := strconv.ParseUint(os.Getenv("COLUMNS"), 10, 32); err == nil && width > 0 {
+		return uint32(width)
+	}
+
+	return 78
+}
+
+// applyVerbosity sets the client and server log levels from --quiet and --verbose, which take
+// precedence over the log level flags.
+func applyVerbosity() {
+	switch {
+	case rootState.quiet:
+		rootState.logLevel = "error"
+		rootState.logLevelServer = "error"
+	case rootState.verbosity == 1:
+		rootState.logLevel = "debug"
+		rootState.logLevelServer = "debug"
+	case rootState.verbosity > 1:
+		rootState.logLevel = "trace"
+		rootState.logLevelServer = "trace"
+	default:
+	}
+}
+
+// profileEnabled returns whether or not a given profile has been enabled.
+func profileEnabled(profile string) bool {
+	return slices.Contains(rootState.profile, profile)
+}
+
+// validateProfiles ensures that all requested profiles are supported.
+func validateProfiles() error {
+	for _, profile := range rootState.profile {
+		switch profile {
+		case "cpu", "mem", "trace":
+		default:
+			return fmt.Errorf("unsupported profile %q, expected one of cpu, mem, or trace", profile)
+		}
+	}
+
+	return nil
 }
 
 func startCPUProfiling() error {
@@ -169,6 +386,24 @@ func startCPUProfiling() error {
 	return nil
 }
 
+func startTracing() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	rootState.traceOut, err = os.Create(filepath.Join(wd, "trace.out"))
+	if err != nil {
+		return err
+	}
+
+	if err := trace.Start(rootState.traceOut); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func runMemoryProfiling() error {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -193,11 +428,10 @@ func runMemoryProfiling() error {
 func rootCmdPreRun(cmd *cobra.Command, args []string) error {
 	cmd.SilenceErrors = true // we handle this ourselves
 
-	if rootState.profile {
-		if err := startCPUProfiling(); err != nil {
-			return err
-		}
-	}
+	// Set any flag defaults from the project configuration and environment before we use them.
+	// We can only show errors after we've set up the UI.
+	projectErr := applyProjectConfig(cmd)
+	applyVerbosity()
 
 	// Setup our UI configuration first
 	err := setupCLIUI()
@@ -205,9 +439,36 @@ func rootCmdPreRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if projectErr != nil {
+		// This isn't a usage error so don't show the usage.
+		cmd.SilenceUsage = true
+
+		return projectErr
+	}
+
+	if err := validateProfiles(); err != nil {
+		return err
+	}
+
+	if profileEnabled("cpu") {
+		if err := startCPUProfiling(); err != nil {
+			return err
+		}
+	}
+
+	if profileEnabled("trace") {
+		if err := startTracing(); err != nil {
+			return err
+		}
+	}
+
 	// If we're this far they've given use valid usage and we'll handle it
 	cmd.SilenceUsage = true
 
+	if err := openLogFile(cmd); err != nil {
+		return err
+	}
+
 	// Create our gRPC server and client
 	rootState.enosServer, rootState.enosConnection, err = startServer(
 		context.Background(),
@@ -217,17 +478,29 @@ func rootCmdPreRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if err := registerClientProject(cmd); err != nil {
+		return err
+	}
+	logPluginErrors()
+
 	return err
 }
 
 func rootCmdPostRun(cmd *cobra.Command, args []string) {
-	if rootState.profile {
+	if profileEnabled("cpu") {
 		if rootState.cpuProfileOut != nil {
 			defer rootState.cpuProfileOut.Close()
 		}
 		defer pprof.StopCPUProfile()
 	}
 
+	if profileEnabled("trace") {
+		if rootState.traceOut != nil {
+			defer rootState.traceOut.Close()
+		}
+		defer trace.Stop()
+	}
+
 	if rootState.enosServer != nil {
 		err := rootState.enosServer.Stop()
 		if err != nil {
@@ -237,11 +510,23 @@ func rootCmdPostRun(cmd *cobra.Command, args []string) {
 
 	// Run memory profiling after we've shut everything down everything but
 	// our UI
-	if rootState.profile {
+	if profileEnabled("mem") {
 		if err := runMemoryProfiling(); err != nil {
 			_ = ui.ShowError(err)
 		}
 	}
 
+	if rootState.metrics != nil {
+		if err := rootState.metrics.Close(); err != nil {
+			_ = ui.ShowError(err)
+		}
+	}
+
+	if rootState.logFileOut != nil {
+		if err := rootState.logFileOut.Close(); err != nil {
+			_ = ui.ShowError(err)
+		}
+	}
+
 	ui.Close()
 }
@@ -6,8 +6,10 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -16,6 +18,7 @@ import (
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan"
 	"github.com/hashicorp/enos/internal/operation/terraform"
+	"github.com/hashicorp/enos/internal/target"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -31,6 +34,19 @@ type scenarioStateType struct {
 	sampleFilter        *sampleObserveFilter
 	noValidateSamples   bool
 	noValidateScenarios bool
+	unusedAsErrors      bool
+	verifyModuleSources bool
+	containerRuntime    string
+	containerImage      string
+	containerEnv        []string
+	target              string
+	regoPaths           []string
+	opaPath             string
+	reuseTerraformDir   string
+	unionFilters        []string
+	intersectFilters    []string
+	differenceFilters   []string
+	shard               string
 }
 
 // scenarioState is the 'scenario' sub-command configuration.
@@ -53,7 +69,15 @@ include spaces, VARIANT SUBFILTERS cannot include spaces. E.g.
 
 VARIANT SUBFILTER = '[!]KEY:PATTERN|WILDCARD|ABSOLUTE'
 
-FILTER = '[SCENARIO NAME] [...VARIANT SUBFILTER]'`
+FILTER = '[SCENARIO NAME] [...VARIANT SUBFILTER]'
+
+Filters can be combined with the --filter, --intersect-filter and
+--exclude-filter flags, which each take a FILTER and can be repeated. The
+scenarios and variants of every --filter are selected along with those of the
+FILTER argument, those that don't match every --intersect-filter or match any
+--exclude-filter are not. E.g.
+
+enos scenario list --filter 'upgrade arch:arm64' --filter smoke --exclude-filter 'distro:rhel'`
 
 // newScenarioCmd returns a new instance of the 'scenario' sub-command.
 func newScenarioCmd() *cobra.Command {
@@ -70,18 +94,39 @@ func newScenarioCmd() *cobra.Command {
 	scenarioCmd.PersistentFlags().StringVarP(&scenarioState.baseDir, "chdir", "d", "", "Use the given directory as the working directory")
 	scenarioCmd.PersistentFlags().StringVarP(&scenarioState.outDir, "out", "o", "", "Configure the base directory where generated modules will be created")
 	scenarioCmd.PersistentFlags().StringSliceVar(&scenarioState.varsFilesPaths, "var-file", []string{}, "The path to use for variable values files. By default enos will load all enos*.vars.hcl files in the working directory.")
+	scenarioCmd.PersistentFlags().StringVar(&scenarioState.containerImage, "container-image", "", "Execute Terraform in a container of the image, which has Terraform and the providers, instead of on the host. Only the module directory of the scenario is writable in the container")
+	scenarioCmd.PersistentFlags().StringVar(&scenarioState.containerRuntime, "container-runtime", terraform.DefaultContainerRuntime, "The container runtime of the --container-image, e.g. docker or podman")
+	scenarioCmd.PersistentFlags().StringSliceVar(&scenarioState.containerEnv, "container-env", []string{}, "The names of environment variables, e.g. provider credentials, to pass to the --container-image")
+	scenarioCmd.PersistentFlags().StringSliceVar(&scenarioState.regoPaths, "policy", []string{}, "The files or directories of Rego policies to evaluate against the scenarios, generated modules, and plans. Overrides the rego of the project configuration policy")
+	scenarioCmd.PersistentFlags().StringVar(&scenarioState.opaPath, "opa-path", "", "The path to the opa binary, which defaults to opa in the PATH")
+	scenarioCmd.PersistentFlags().StringVar(&scenarioState.target, "target", "", "Generate modules locally but execute Terraform on the remote target, e.g. ssh://user@host. The module directory of the scenario is synced to the target before and back from it after every Terraform command")
+	scenarioCmd.PersistentFlags().StringVar(&scenarioState.reuseTerraformDir, "reuse-terraform-dir", "none", "Reuse the providers and modules of scenarios that have already been initialized with the same lock file and modules by either symlinking or copying them. One of symlink, copy, or none")
+	scenarioCmd.PersistentFlags().StringArrayVar(&scenarioState.unionFilters, "filter", []string{}, "Also select the scenarios and variants of the FILTER. Can be repeated")
+	scenarioCmd.PersistentFlags().StringArrayVar(&scenarioState.intersectFilters, "intersect-filter", []string{}, "Only select the scenarios and variants that also match the FILTER. Can be repeated")
+	scenarioCmd.PersistentFlags().StringArrayVar(&scenarioState.differenceFilters, "exclude-filter", []string{}, "Never select the scenarios and variants that match the FILTER. Can be repeated")
+	scenarioCmd.MarkFlagsMutuallyExclusive("container-image", "target")
 
 	scenarioCmd.AddCommand(newScenarioListCmd())
+	scenarioCmd.AddCommand(newScenarioIDsCmd())
 	scenarioCmd.AddCommand(newScenarioGenerateCmd())
+	scenarioCmd.AddCommand(newScenarioMigrateCmd())
+	scenarioCmd.AddCommand(newScenarioLockCmd())
 	scenarioCmd.AddCommand(newScenarioCheckCmd())
 	scenarioCmd.AddCommand(newScenarioLaunchCmd())
 	scenarioCmd.AddCommand(newScenarioDestroyCmd())
 	scenarioCmd.AddCommand(newScenarioRunCmd())
 	scenarioCmd.AddCommand(newScenarioExecCmd())
 	scenarioCmd.AddCommand(newScenarioOutputCmd())
+	scenarioCmd.AddCommand(newScenarioStateCmd())
 	scenarioCmd.AddCommand(newScenarioValidateConfigCmd())
+	scenarioCmd.AddCommand(newScenarioFetchCmd())
 	scenarioCmd.AddCommand(newScenarioSampleCmd())
 	scenarioCmd.AddCommand(newScenarioOutlineCmd())
+	scenarioCmd.AddCommand(newScenarioBenchmarkCmd())
+	scenarioCmd.AddCommand(newScenarioShowAtCmd())
+	scenarioCmd.AddCommand(newScenarioEvalCmd())
+	scenarioCmd.AddCommand(newScenarioCoverageCmd())
+	scenarioCmd.AddCommand(newScenarioMatrixCmd())
 
 	return scenarioCmd
 }
@@ -103,6 +148,42 @@ func scenarioCmdPreRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if scenarioState.containerImage != "" {
+		// The flight plan directory is mounted so that local module sources resolve.
+		scenarioState.tfConfig.Container = &pb.Terraform_Runner_Config_Container{
+			Runtime:        scenarioState.containerRuntime,
+			Image:          scenarioState.containerImage,
+			Env:            scenarioState.containerEnv,
+			ReadOnlyMounts: []string{scenarioState.baseDir},
+		}
+	}
+
+	if scenarioState.target != "" {
+		if _, err := target.ParseSSH(scenarioState.target); err != nil {
+			return err
+		}
+
+		// The flight plan directory is synced so that local module sources resolve.
+		scenarioState.tfConfig.Target = &pb.Terraform_Runner_Config_Target{
+			Address:  scenarioState.target,
+			SyncDirs: []string{scenarioState.baseDir},
+		}
+	}
+
+	switch scenarioState.reuseTerraformDir {
+	case "symlink":
+		scenarioState.tfConfig.Reuse = &pb.Terraform_Runner_Config_Reuse{
+			Mode: pb.Terraform_Runner_Config_Reuse_MODE_SYMLINK,
+		}
+	case "copy":
+		scenarioState.tfConfig.Reuse = &pb.Terraform_Runner_Config_Reuse{
+			Mode: pb.Terraform_Runner_Config_Reuse_MODE_COPY,
+		}
+	case "", "none":
+	default:
+		return fmt.Errorf("--reuse-terraform-dir must be one of symlink, copy, or none, got %s", scenarioState.reuseTerraformDir)
+	}
+
 	// Load the configuration from our working dir
 	scenarioState.protoFp, err = readFlightPlanConfig(scenarioState.baseDir, scenarioState.varsFilesPaths)
 
@@ -228,8 +309,9 @@ func scenarioTimeoutContext() (context.Context, func()) {
 // a new instance of FlightPlan.
 func readFlightPlanConfig(dir string, varFilePaths []string) (*pb.FlightPlan, error) {
 	fp := &pb.FlightPlan{
-		BaseDir:     dir,
-		EnosVarsEnv: os.Environ(),
+		BaseDir:      dir,
+		EnosVarsEnv:  os.Environ(),
+		StrictSchema: rootState.strictSchema,
 	}
 
 	cfgFiles, err := flightplan.FindRawFiles(dir, flightplan.FlightPlanFileNamePattern)
@@ -247,12 +329,93 @@ func readFlightPlanConfig(dir string, varFilePaths []string) (*pb.FlightPlan, er
 		return nil, err
 	}
 
+	lintFiles, err := flightplan.FindRawFiles(dir, flightplan.LintConfigNamePattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// The project configuration can also configure lint rules.
+	projectFiles, err := flightplan.FindRawFiles(dir, flightplan.ProjectConfigNamePattern)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(lintFiles, projectFiles)
+
+	skipFiles, err := flightplan.FindRawFiles(dir, flightplan.SkipConfigNamePattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfgFiles) > 0 {
+		fp.EnosVarsEnv, err = pluginVariables(dir, fp.GetEnosVarsEnv())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	fp.EnosHcl = cfgFiles
 	fp.EnosVarsHcl = varsFiles
+	fp.EnosLintHcl = lintFiles
+	fp.EnosSkipHcl = skipFiles
 
 	return fp, nil
 }
 
+// parseScenarioFilter takes command args and returns a scenario filter of them combined with the
+// filters of the --filter, --intersect-filter and --exclude-filter flags. When there are no args the
+// first --filter is used in their place.
+func parseScenarioFilter(args []string) (*flightplan.ScenarioFilter, error) {
+	unions := scenarioState.unionFilters
+	if len(args) == 0 && len(unions) > 0 {
+		args = strings.Fields(unions[0])
+		unions = unions[1:]
+	}
+
+	sf, err := flightplan.ParseScenarioFilter(args)
+	if err != nil {
+		return nil, err
+	}
+
+	parse := func(flag string, filters []string) ([]*flightplan.ScenarioFilter, error) {
+		res := []*flightplan.ScenarioFilter{}
+		for _, filter := range filters {
+			f, err := flightplan.ParseScenarioFilter(strings.Fields(filter))
+			if err != nil {
+				return nil, fmt.Errorf("invalid --%s %q: %w", flag, filter, err)
+			}
+			res = append(res, f)
+		}
+
+		return res, nil
+	}
+
+	union, err := parse("filter", unions)
+	if err != nil {
+		return nil, err
+	}
+	intersect, err := parse("intersect-filter", scenarioState.intersectFilters)
+	if err != nil {
+		return nil, err
+	}
+	difference, err := parse("exclude-filter", scenarioState.differenceFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	sf.Union = append(sf.Union, union...)
+	sf.Intersect = append(sf.Intersect, intersect...)
+	sf.Difference = append(sf.Difference, difference...)
+
+	if scenarioState.shard != "" {
+		sf.Shard, err = flightplan.ParseScenarioShard(scenarioState.shard)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sf, nil
+}
+
 // prepareScenarioOpReq takes commands args, parses them to build a filter, and
 // returns a proto filter and proto workspace to use in requests.
 func prepareScenarioOpReq(
@@ -262,7 +425,7 @@ func prepareScenarioOpReq(
 	*pb.Workspace,
 	error,
 ) {
-	sf, err := flightplan.ParseScenarioFilter(args)
+	sf, err := parseScenarioFilter(args)
 	if err != nil {
 		ui.ShowOperationEvent(&pb.Operation_Event{
 			Diagnostics: diagnostics.FromErr(err),
@@ -276,6 +439,57 @@ func prepareScenarioOpReq(
 		Flightplan: scenarioState.protoFp,
 		OutDir:     scenarioState.outDir,
 		TfExecCfg:  scenarioState.tfConfig.Proto(),
+		Limits:     rootState.limits,
+		LogFile:    rootState.logFilePath(),
+	}
+
+	if rootState.projectConfig != nil {
+		ws.Tags = rootState.projectConfig.Tags
+		if l := rootState.projectConfig.Layout; l != nil {
+			ws.Layout = &pb.Layout{Nested: l.Nested}
+			switch l.Naming {
+			case "slug":
+				ws.Layout.Naming = pb.Layout_NAMING_SLUG
+			case "uid":
+				ws.Layout.Naming = pb.Layout_NAMING_UID
+			}
+		}
+		if l := rootState.projectConfig.Lock; l != nil {
+			ws.Lock = &pb.Lock{File: l.File, Platforms: l.Platforms}
+		}
+		if p := rootState.projectConfig.Policy; p != nil {
+			ws.Policy = &pb.Policy{
+				MaxHourlyCost:     p.MaxHourlyCost,
+				MaxMonthlyCost:    p.MaxMonthlyCost,
+				DenyResourceTypes: p.DenyResourceTypes,
+				DenyInstanceTypes: p.DenyInstanceTypes,
+				RegoPaths:         p.Rego,
+				OpaBinPath:        p.OPAPath,
+				AllowExec:         p.AllowExec,
+				DenyExec:          p.DenyExec,
+			}
+		}
+	}
+
+	if len(scenarioState.regoPaths) > 0 || scenarioState.opaPath != "" {
+		if ws.GetPolicy() == nil {
+			ws.Policy = &pb.Policy{}
+		}
+
+		if len(scenarioState.regoPaths) > 0 {
+			ws.Policy.RegoPaths = []string{}
+			for _, path := range scenarioState.regoPaths {
+				abs, err := filepath.Abs(path)
+				if err != nil {
+					return nil, nil, err
+				}
+				ws.Policy.RegoPaths = append(ws.GetPolicy().GetRegoPaths(), abs)
+			}
+		}
+
+		if scenarioState.opaPath != "" {
+			ws.Policy.OpaBinPath = scenarioState.opaPath
+		}
 	}
 
 	return sf.Proto(), ws, nil
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var scenarioBenchmarkState = struct {
+	target     string
+	iterations int32
+	generate   bool
+}{}
+
+// newScenarioBenchmarkCmd returns a new 'scenario benchmark' sub-command.
+func newScenarioBenchmarkCmd() *cobra.Command {
+	benchmarkCmd := &cobra.Command{
+		Use:               "benchmark [FILTER]",
+		Short:             "Benchmark decoding and generating scenarios",
+		Long:              "Repeatedly decode, and optionally generate, the scenarios in the flight plan and report the timing and allocation statistics. Modules are always generated into a temporary directory. " + scenarioFilterDesc,
+		RunE:              runScenarioBenchmarkCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	benchmarkCmd.PersistentFlags().StringVar(&scenarioBenchmarkState.target, "target", "all", fmt.Sprintf("The decode target. Supported targets are %s", strings.Join(flightplan.DecodeTargetNames(), ", ")))
+	benchmarkCmd.PersistentFlags().Int32VarP(&scenarioBenchmarkState.iterations, "iterations", "n", 10, "The number of times to decode and generate")
+	benchmarkCmd.PersistentFlags().BoolVar(&scenarioBenchmarkState.generate, "generate", false, "Benchmark generating the Terraform modules for the scenarios")
+
+	return benchmarkCmd
+}
+
+// runScenarioBenchmarkCmd runs a scenario benchmark.
+func runScenarioBenchmarkCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, err := parseScenarioFilter(args)
+	if err != nil {
+		return ui.ShowScenarioBenchmark(&pb.BenchmarkScenariosResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := rootState.enosConnection.Client.BenchmarkScenarios(
+		ctx, &pb.BenchmarkScenariosRequest{
+			Workspace: &pb.Workspace{
+				Flightplan: scenarioState.protoFp,
+				TfExecCfg:  scenarioState.tfConfig.Proto(),
+				Limits:     rootState.limits,
+			},
+			Filter:       sf.Proto(),
+			DecodeTarget: scenarioBenchmarkState.target,
+			Iterations:   scenarioBenchmarkState.iterations,
+			Generate:     scenarioBenchmarkState.generate,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowScenarioBenchmark(res)
+}
@@ -11,12 +11,18 @@ import (
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
+var scenarioCheckState = struct {
+	cost          bool
+	infracostPath string
+}{}
+
 // newScenarioCheckCmd returns a new 'scenario check' sub-command.
 func newScenarioCheckCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:               "check [FILTER]",
+		Aliases:           []string{"plan"},
 		Short:             "Check that scenarios are valid",
-		Long:              "Check that scenarios are valid by generating the Scenario's Terraform Root Module, initializing it, validating it, and planning. " + scenarioFilterDesc,
+		Long:              "Check that scenarios are valid by generating the Scenario's Terraform Root Module, initializing it, validating it, and planning. With --cost the hourly and monthly cost of the plans are estimated with Infracost, which requires an INFRACOST_API_KEY. " + scenarioFilterDesc,
 		RunE:              runScenarioCheckCmd,
 		ValidArgsFunction: scenarioNameCompletion,
 	}
@@ -30,6 +36,8 @@ func newScenarioCheckCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&scenarioState.tfConfig.Flags.NoReconfigure, "no-reconfigure", false, "Don't reconfigure the backend during init")
 	cmd.PersistentFlags().Uint32Var(&scenarioState.tfConfig.Flags.Parallelism, "tf-parallelism", 10, "Terraform scenario parallelism")
 	cmd.PersistentFlags().DurationVar(&scenarioState.lockTimeout, "lock-timeout", 1*time.Minute, "Duration to wait for the Terraform lock")
+	cmd.PersistentFlags().BoolVar(&scenarioCheckState.cost, "cost", false, "Estimate the cost of the plans of scenarios with Infracost")
+	cmd.PersistentFlags().StringVar(&scenarioCheckState.infracostPath, "infracost-path", "", "The path to the infracost binary, which defaults to infracost in the PATH")
 
 	_ = cmd.Flags().MarkHidden("out") // Allow passing out for testing but mark it hidden
 
@@ -41,6 +49,13 @@ func runScenarioCheckCmd(cmd *cobra.Command, args []string) error {
 	ctx, cancel := scenarioTimeoutContext()
 	defer cancel()
 
+	if scenarioCheckState.cost {
+		scenarioState.tfConfig.Cost = &pb.Terraform_Runner_Config_Cost{
+			Enabled: true,
+			BinPath: scenarioCheckState.infracostPath,
+		}
+	}
+
 	sf, ws, err := prepareScenarioOpReq(args)
 	if err != nil {
 		return err
@@ -57,5 +72,13 @@ func runScenarioCheckCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return ui.ShowOperationResponses(rootState.enosConnection.StreamOperations(ctx, res, ui))
+	n := startNotifications(cmd, res)
+	opRes := rootState.enosConnection.StreamOperations(ctx, res, ui, n.streamOpts()...)
+	withWarningBudget(opRes)
+	writePluginReports(cmd, opRes)
+	publishArtifacts(cmd, opRes)
+	err = ui.ShowOperationResponses(opRes)
+	n.notify(opRes)
+
+	return err
 }
@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// newScenarioCoverageCmd returns a new 'scenario coverage' sub-command.
+func newScenarioCoverageCmd() *cobra.Command {
+	coverageCmd := &cobra.Command{
+		Use:               "coverage [FILTER]",
+		Short:             "Check that every quality is verified by a scenario",
+		Long:              "Decode the scenarios and show which scenario variants verify each declared quality with the verifies attribute of their steps or outputs. Any qualities that no scenario variant verifies cause a non-zero exit code. With --sample-name only the scenario variants in the frame of the sample are considered. " + scenarioFilterDesc,
+		RunE:              runScenarioCoverageCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	coverageCmd.PersistentFlags().StringVarP(&scenarioState.sampleFilter.SampleName, "sample-name", "s", "", "Only consider the scenario variants in the frame of the sample")
+	coverageCmd.PersistentFlags().StringSliceVarP(&scenarioState.sampleFilter.OnlySubsets, "include", "i", nil, "Limit the sample frame to the given subset(s)")
+	coverageCmd.PersistentFlags().StringSliceVarP(&scenarioState.sampleFilter.ExcludeSubsets, "exclude", "e", nil, "Exclude the given subset(s) from the sample frame")
+
+	return coverageCmd
+}
+
+// runScenarioCoverageCmd checks the quality coverage of the scenarios.
+func runScenarioCoverageCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, err := parseScenarioFilter(args)
+	if err != nil {
+		return ui.ShowQualityCoverage(&pb.CheckQualityCoverageResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	req := &pb.CheckQualityCoverageRequest{
+		Workspace: &pb.Workspace{
+			Flightplan: scenarioState.protoFp,
+			TfExecCfg:  scenarioState.tfConfig.Proto(),
+			Limits:     rootState.limits,
+		},
+		Filter: sf.Proto(),
+	}
+	if scenarioState.sampleFilter.SampleName != "" {
+		req.SampleFilter = scenarioState.sampleFilter.Proto()
+	}
+
+	res, err := rootState.enosConnection.Client.CheckQualityCoverage(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowQualityCoverage(res)
+}
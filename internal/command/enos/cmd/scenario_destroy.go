@@ -54,5 +54,12 @@ func runScenarioDestroyCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return ui.ShowOperationResponses(rootState.enosConnection.StreamOperations(ctx, res, ui))
+	n := startNotifications(cmd, res)
+	opRes := rootState.enosConnection.StreamOperations(ctx, res, ui, n.streamOpts()...)
+	writePluginReports(cmd, opRes)
+	publishArtifacts(cmd, opRes)
+	err = ui.ShowOperationResponses(opRes)
+	n.notify(opRes)
+
+	return err
 }
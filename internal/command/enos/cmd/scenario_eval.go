@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var scenarioEvalState = struct {
+	scenario string
+}{}
+
+// newScenarioEvalCmd returns a new 'scenario eval' sub-command.
+func newScenarioEvalCmd() *cobra.Command {
+	evalCmd := &cobra.Command{
+		Use:   "eval --scenario <filter> <expression>",
+		Short: "Evaluate an expression in the context of scenarios",
+		Long:  "Evaluate an HCL expression in the context of every scenario variant that matches the filter, with its matrix, variables, globals, locals, and steps, and show the value and type of the result. The expression is evaluated the same way as a step variable, so references to outputs of steps, fixtures, and scenarios are shown as the expression that is deferred to Terraform. " + scenarioFilterDesc,
+		Args:  cobra.ExactArgs(1),
+		RunE:  runScenarioEvalCmd,
+	}
+
+	evalCmd.PersistentFlags().StringVar(&scenarioEvalState.scenario, "scenario", "", "The filter of the scenarios, e.g. 'upgrade arch:amd64'")
+	_ = evalCmd.MarkPersistentFlagRequired("scenario")
+
+	return evalCmd
+}
+
+// runScenarioEvalCmd evaluates an expression in the context of scenarios.
+func runScenarioEvalCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, err := parseScenarioFilter(strings.Fields(scenarioEvalState.scenario))
+	if err != nil {
+		return ui.ShowScenarioEval(&pb.EvalScenarioExpressionResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := rootState.enosConnection.Client.EvalScenarioExpression(
+		ctx, &pb.EvalScenarioExpressionRequest{
+			Workspace: &pb.Workspace{
+				Flightplan: scenarioState.protoFp,
+				TfExecCfg:  scenarioState.tfConfig.Proto(),
+				Limits:     rootState.limits,
+			},
+			Filter:     sf.Proto(),
+			Expression: args[0],
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowScenarioEval(res)
+}
@@ -4,11 +4,19 @@
 package cmd
 
 import (
+	"errors"
+	"os"
+
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
+var scenarioExecState = struct {
+	tty bool
+}{}
+
 func newScenarioExecCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:               "exec [FILTER] [ARGS] --cmd TERRAFORM-SUB-COMMAND",
@@ -19,6 +27,7 @@ func newScenarioExecCmd() *cobra.Command {
 	}
 
 	cmd.PersistentFlags().StringVar(&scenarioState.tfConfig.ExecSubCmd, "cmd", "", "The Terraform sub-command")
+	cmd.PersistentFlags().BoolVarP(&scenarioExecState.tty, "tty", "t", false, "Execute the sub-command in a pseudo-terminal attached to this terminal, e.g. for terraform console or prompts. Matching scenarios are attached to in turn")
 
 	_ = cmd.MarkFlagRequired("cmd")
 	_ = cmd.Flags().MarkHidden("out") // Allow passing out for testing but mark it hidden
@@ -31,6 +40,11 @@ func runScenarioExecCmd(cmd *cobra.Command, args []string) error {
 	ctx, cancel := scenarioTimeoutContext()
 	defer cancel()
 
+	// Operations wait for a terminal to attach to them so make sure we have one before we start any
+	if scenarioExecState.tty && !term.IsTerminal(int(os.Stdin.Fd())) {
+		return errors.New("--tty requires stdin to be a terminal")
+	}
+
 	sf, ws, err := prepareScenarioOpReq(args)
 	if err != nil {
 		return err
@@ -40,11 +54,19 @@ func runScenarioExecCmd(cmd *cobra.Command, args []string) error {
 		ctx, &pb.ExecScenariosRequest{
 			Workspace: ws,
 			Filter:    sf,
+			Tty:       scenarioExecState.tty,
 		},
 	)
 	if err != nil {
 		return err
 	}
 
+	if scenarioExecState.tty {
+		diags := rootState.enosConnection.AttachOperations(ctx, res.GetOperations(), os.Stdin, os.Stdout)
+		if len(diags) > 0 {
+			_ = ui.ShowDiagnostics(diags)
+		}
+	}
+
 	return ui.ShowOperationResponses(rootState.enosConnection.StreamOperations(ctx, res, ui))
 }
@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// newScenarioFetchCmd returns a new 'scenario fetch' sub-command.
+func newScenarioFetchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "fetch [FILTER]",
+		Short:             "Fetch remote modules of scenarios",
+		Long:              "Resolve the registry and git sources of the step modules of scenarios, verify that they exist, and fetch them into the module cache in the out directory. " + scenarioFilterDesc,
+		RunE:              runScenarioFetchCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+}
+
+// runScenarioFetchCmd runs a scenario fetch.
+func runScenarioFetchCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, err := parseScenarioFilter(args)
+	if err != nil {
+		return ui.ShowScenarioModuleFetch(&pb.FetchScenarioModulesResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := rootState.enosConnection.Client.FetchScenarioModules(ctx, &pb.FetchScenarioModulesRequest{
+		Workspace: &pb.Workspace{
+			Flightplan: scenarioState.protoFp,
+			OutDir:     scenarioState.outDir,
+			TfExecCfg:  scenarioState.tfConfig.Proto(),
+			Limits:     rootState.limits,
+		},
+		Filter: sf.Proto(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowScenarioModuleFetch(res)
+}
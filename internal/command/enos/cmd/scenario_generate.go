@@ -4,22 +4,32 @@
 package cmd
 
 import (
+	"errors"
+
 	"github.com/spf13/cobra"
 
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
+var scenarioGenerateState = struct {
+	dryRun bool
+	diff   bool
+}{}
+
 // newScenarioGenerateCmd returns a new 'scenario generate' sub-command.
 func newScenarioGenerateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:               "generate [FILTER]",
 		Short:             "Generate Terraform modules from matching scenarios",
-		Long:              "Generate a Terraform modules from matching scenarios. " + scenarioFilterDesc,
+		Long:              "Generate a Terraform modules from matching scenarios. With --dry-run the modules are rendered in memory and the files that would change are shown without writing anything. " + scenarioFilterDesc,
 		RunE:              runScenarioGenerateCmd,
 		ValidArgsFunction: scenarioNameCompletion,
 		Hidden:            true, // This is hidden because it is intended for debug only
 	}
 
+	cmd.PersistentFlags().BoolVar(&scenarioGenerateState.dryRun, "dry-run", false, "Render the modules in memory and show the files that would change without writing them")
+	cmd.PersistentFlags().BoolVar(&scenarioGenerateState.diff, "diff", false, "Show a unified diff of the rendered modules against the existing modules. Requires --dry-run")
+
 	return cmd
 }
 
@@ -28,6 +38,10 @@ func runScenarioGenerateCmd(cmd *cobra.Command, args []string) error {
 	ctx, cancel := scenarioTimeoutContext()
 	defer cancel()
 
+	if scenarioGenerateState.diff && !scenarioGenerateState.dryRun {
+		return errors.New("--diff requires --dry-run")
+	}
+
 	sf, ws, err := prepareScenarioOpReq(args)
 	if err != nil {
 		return err
@@ -37,6 +51,8 @@ func runScenarioGenerateCmd(cmd *cobra.Command, args []string) error {
 		ctx, &pb.GenerateScenariosRequest{
 			Workspace: ws,
 			Filter:    sf,
+			DryRun:    scenarioGenerateState.dryRun,
+			Diff:      scenarioGenerateState.diff,
 		},
 	)
 	if err != nil {
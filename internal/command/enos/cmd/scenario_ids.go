@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var scenarioIDsState = struct {
+	id string
+}{}
+
+// newScenarioIDsCmd returns a new 'scenario ids' sub-command.
+func newScenarioIDsCmd() *cobra.Command {
+	idsCmd := &cobra.Command{
+		Use:               "ids [FILTER]",
+		Short:             "Map scenarios to their slugs and UIDs",
+		Long:              "Show the slug and UID of scenario and variant combinations. Generated Terraform modules are written to directories in the out directory that are named after the slug, or the UID if they were generated by a prior version of enos or the layout of the project configuration names them after the UID. " + scenarioFilterDesc,
+		RunE:              runScenarioIDsCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	idsCmd.PersistentFlags().StringVar(&scenarioIDsState.id, "id", "", "Only show the scenario whose slug or UID matches, e.g. the name of a directory in the out directory")
+
+	return idsCmd
+}
+
+// runScenarioIDsCmd runs a scenario ids.
+func runScenarioIDsCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, err := parseScenarioFilter(args)
+	if err != nil {
+		return ui.ShowScenarioIDs(&pb.ListScenariosResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := listScenarios(ctx, &pb.ListScenariosRequest{
+		Workspace: &pb.Workspace{
+			Flightplan: scenarioState.protoFp,
+			Limits:     rootState.limits,
+		},
+		Filter: sf.Proto(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if id := scenarioIDsState.id; id != "" {
+		scenarios := []*pb.Ref_Scenario{}
+		for _, ref := range res.GetScenarios() {
+			if ref.GetId().GetSlug() == id || ref.GetId().GetUid() == id {
+				scenarios = append(scenarios, ref)
+			}
+		}
+		res.Scenarios = scenarios
+	}
+
+	return ui.ShowScenarioIDs(res)
+}
@@ -30,6 +30,7 @@ func newScenarioLaunchCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&scenarioState.tfConfig.Flags.NoReconfigure, "no-reconfigure", false, "Don't reconfigure the backend during init")
 	cmd.PersistentFlags().Uint32Var(&scenarioState.tfConfig.Flags.Parallelism, "tf-parallelism", 10, "The Terraform scenario parallelism")
 	cmd.PersistentFlags().DurationVar(&scenarioState.lockTimeout, "lock-timeout", 1*time.Minute, "The Duration to wait for the Terraform lock")
+	cmd.PersistentFlags().StringVar(&scenarioState.shard, "shard", "", "Only select the scenarios and variants of the one based shard i of n stable shards, e.g. 1/4. Scenarios and variants are sharded by the hash of their UID")
 
 	_ = cmd.Flags().MarkHidden("out") // Allow passing out for testing but mark it hidden
 
@@ -56,5 +57,13 @@ func runScenarioLaunchCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return ui.ShowOperationResponses(rootState.enosConnection.StreamOperations(ctx, res, ui))
+	n := startNotifications(cmd, res)
+	opRes := rootState.enosConnection.StreamOperations(ctx, res, ui, n.streamOpts()...)
+	withWarningBudget(opRes)
+	writePluginReports(cmd, opRes)
+	publishArtifacts(cmd, opRes)
+	err = ui.ShowOperationResponses(opRes)
+	n.notify(opRes)
+
+	return err
 }
@@ -4,7 +4,9 @@
 package cmd
 
 import (
+	"context"
 	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -13,15 +15,34 @@ import (
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
+var scenarioListState = struct {
+	pageSize  int32
+	pageToken string
+	stream    bool
+	explain   bool
+	metadata  bool
+	sort      string
+}{}
+
 // newScenarioListCmd returns a new 'scenario list' sub-command.
 func newScenarioListCmd() *cobra.Command {
-	return &cobra.Command{
+	listCmd := &cobra.Command{
 		Use:               "list [FILTER]",
 		Short:             "List scenarios",
 		Long:              "List all scenario and variant combinations",
 		RunE:              runScenarioListCmd,
 		ValidArgsFunction: scenarioNameCompletion,
 	}
+
+	listCmd.PersistentFlags().Int32Var(&scenarioListState.pageSize, "page-size", 0, "The maximum number of scenarios to list. Paginated scenarios are sorted by name and variants")
+	listCmd.PersistentFlags().StringVar(&scenarioListState.pageToken, "page-token", "", "The next page token of a previous paginated list")
+	listCmd.PersistentFlags().BoolVar(&scenarioListState.stream, "stream", false, "Show each scenario as soon as it has been decoded")
+	listCmd.PersistentFlags().BoolVar(&scenarioListState.explain, "explain", false, "Also show which scenarios and variants were excluded and why")
+	listCmd.PersistentFlags().BoolVar(&scenarioListState.metadata, "metadata", false, "Also show the description, tags, source location, and verified qualities of scenarios. Requires decoding scenarios completely")
+	listCmd.PersistentFlags().StringVar(&scenarioListState.sort, "sort", "", "The order of the scenarios: "+strings.Join(flightplan.ScenarioSorts, ", ")+". Tag and source orders require decoding scenarios completely and the duration order estimates durations from the operation history")
+	listCmd.PersistentFlags().StringVar(&scenarioState.shard, "shard", "", "Only select the scenarios and variants of the one based shard i of n stable shards, e.g. 1/4. Scenarios and variants are sharded by the hash of their UID")
+
+	return listCmd
 }
 
 // runScenarioListCmd runs a scenario list.
@@ -29,25 +50,58 @@ func runScenarioListCmd(cmd *cobra.Command, args []string) error {
 	ctx, cancel := scenarioTimeoutContext()
 	defer cancel()
 
-	sf, err := flightplan.ParseScenarioFilter(args)
+	sf, err := parseScenarioFilter(args)
 	if err != nil {
 		return ui.ShowScenarioList(&pb.ListScenariosResponse{
 			Diagnostics: diagnostics.FromErr(err),
 		})
 	}
 
-	stream, err := rootState.enosConnection.Client.ListScenarios(
-		ctx, &pb.ListScenariosRequest{
-			Workspace: &pb.Workspace{
-				Flightplan: scenarioState.protoFp,
-			},
-			Filter: sf.Proto(),
+	order, err := flightplan.ParseScenarioSort(scenarioListState.sort)
+	if err != nil {
+		return ui.ShowScenarioList(&pb.ListScenariosResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	req := &pb.ListScenariosRequest{
+		Workspace: &pb.Workspace{
+			Flightplan: scenarioState.protoFp,
+			OutDir:     scenarioState.outDir,
+			Limits:     rootState.limits,
 		},
-	)
+		Filter:    sf.Proto(),
+		PageSize:  scenarioListState.pageSize,
+		PageToken: scenarioListState.pageToken,
+		Explain:   scenarioListState.explain,
+		Metadata:  scenarioListState.metadata,
+		Sort:      order,
+	}
+
+	if scenarioListState.stream {
+		stream, err := rootState.enosConnection.Client.ListScenarios(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return ui.ShowScenarioListStream(stream)
+	}
+
+	res, err := listScenarios(ctx, req)
 	if err != nil {
 		return err
 	}
 
+	return ui.ShowScenarioList(res)
+}
+
+// listScenarios lists scenarios and collects the streamed responses.
+func listScenarios(ctx context.Context, req *pb.ListScenariosRequest) (*pb.ListScenariosResponse, error) {
+	stream, err := rootState.enosConnection.Client.ListScenarios(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	res := &pb.ListScenariosResponse{}
 	for {
 		msg, err := stream.Recv()
@@ -55,7 +109,7 @@ func runScenarioListCmd(cmd *cobra.Command, args []string) error {
 			break
 		}
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		switch val := msg.GetResponse().(type) {
@@ -63,9 +117,14 @@ func runScenarioListCmd(cmd *cobra.Command, args []string) error {
 			res.Decode = val.Decode
 		case *pb.EnosServiceListScenariosResponse_Scenario:
 			res.Scenarios = append(res.Scenarios, val.Scenario)
+		case *pb.EnosServiceListScenariosResponse_Page_:
+			res.NextPageToken = val.Page.GetNextPageToken()
+			res.TotalScenarios = val.Page.GetTotalScenarios()
+		case *pb.EnosServiceListScenariosResponse_Explanation:
+			res.Explanation = val.Explanation
 		default:
 		}
 	}
 
-	return ui.ShowScenarioList(res)
+	return res, nil
 }
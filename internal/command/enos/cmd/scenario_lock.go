@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var scenarioLockState = struct {
+	platforms []string
+}{}
+
+// newScenarioLockCmd returns a new 'scenario lock' sub-command.
+func newScenarioLockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "lock [FILTER]",
+		Short:             "Lock the providers of scenarios for all platforms",
+		Long:              "Regenerate the Terraform dependency lock files of matching scenarios by generating the Scenario's Terraform Root Module, initializing it without the backend, and locking its providers for the platforms of the project configuration lock. Modules without a lock file are seeded with the lock file of the project configuration. The lock files are verified to have hashes of every provider for all platforms. " + scenarioFilterDesc,
+		RunE:              runScenarioLockCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	cmd.PersistentFlags().StringSliceVar(&scenarioLockState.platforms, "platform", []string{}, "The platforms to lock providers for, e.g. linux_amd64. Overrides the platforms of the project configuration lock")
+	cmd.PersistentFlags().BoolVar(&scenarioState.tfConfig.Flags.Upgrade, "upgrade", false, "Upgrade providers to the newest versions that match their constraints")
+
+	return cmd
+}
+
+// runScenarioLockCmd runs a scenario lock.
+func runScenarioLockCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	// Providers are locked without any state so there's no need for the backend
+	scenarioState.tfConfig.Flags.NoBackend = true
+
+	sf, ws, err := prepareScenarioOpReq(args)
+	if err != nil {
+		return err
+	}
+
+	if len(scenarioLockState.platforms) > 0 {
+		if ws.GetLock() == nil {
+			ws.Lock = &pb.Lock{}
+		}
+		ws.Lock.Platforms = scenarioLockState.platforms
+	}
+
+	res, err := rootState.enosConnection.Client.LockScenarios(
+		ctx, &pb.LockScenariosRequest{
+			Workspace: ws,
+			Filter:    sf,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowOperationResponses(rootState.enosConnection.StreamOperations(ctx, res, ui))
+}
@@ -0,0 +1,22 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newScenarioMatrixCmd returns a new 'scenario matrix' sub-command.
+func newScenarioMatrixCmd() *cobra.Command {
+	matrixCmd := &cobra.Command{
+		Use:   "matrix",
+		Short: "Enos scenario matrices",
+		Long:  "Inspect the matrices of scenarios",
+	}
+
+	matrixCmd.AddCommand(newScenarioMatrixShowCmd())
+	matrixCmd.AddCommand(newScenarioMatrixDiffCmd())
+
+	return matrixCmd
+}
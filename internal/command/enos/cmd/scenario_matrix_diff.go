@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var scenarioMatrixDiffState = struct {
+	base    string
+	baseDir string
+}{}
+
+// newScenarioMatrixDiffCmd returns a new 'scenario matrix diff' sub-command.
+func newScenarioMatrixDiffCmd() *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:               "diff [FILTER] --base <git-ref>",
+		Short:             "Show the scenario variants that changed compared to a base",
+		Long:              "Decode the scenario matrices of the flight plan and of the flight plan at a base git reference or in a base directory, and show the scenario variants that were added and removed. The base git reference is checked out into a temporary git worktree and the flight plan is read from the same directory relative to the root of the repository. " + scenarioFilterDesc,
+		RunE:              runScenarioMatrixDiffCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	diffCmd.PersistentFlags().StringVar(&scenarioMatrixDiffState.base, "base", "", "The git reference of the base flight plan, e.g. main")
+	diffCmd.PersistentFlags().StringVar(&scenarioMatrixDiffState.baseDir, "base-dir", "", "The directory of the base flight plan")
+	diffCmd.MarkFlagsMutuallyExclusive("base", "base-dir")
+	diffCmd.MarkFlagsOneRequired("base", "base-dir")
+
+	return diffCmd
+}
+
+// runScenarioMatrixDiffCmd diffs the scenario matrices with those of the base.
+func runScenarioMatrixDiffCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	showErr := func(err error) error {
+		return ui.ShowScenarioMatrixDiff(&pb.DiffScenarioMatricesResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	sf, err := parseScenarioFilter(args)
+	if err != nil {
+		return showErr(err)
+	}
+
+	baseDir := scenarioMatrixDiffState.baseDir
+	if scenarioMatrixDiffState.base != "" {
+		var cleanup func()
+		baseDir, cleanup, err = checkoutScenarioMatrixDiffBase(ctx, scenarioMatrixDiffState.base)
+		if err != nil {
+			return showErr(err)
+		}
+		defer cleanup()
+	}
+
+	baseDir, err = filepath.Abs(baseDir)
+	if err != nil {
+		return showErr(err)
+	}
+
+	baseFp, err := readFlightPlanConfig(baseDir, scenarioState.varsFilesPaths)
+	if err != nil {
+		return showErr(fmt.Errorf("reading the base flight plan: %w", err))
+	}
+
+	res, err := rootState.enosConnection.Client.DiffScenarioMatrices(
+		ctx, &pb.DiffScenarioMatricesRequest{
+			Workspace: &pb.Workspace{
+				Flightplan: scenarioState.protoFp,
+				TfExecCfg:  scenarioState.tfConfig.Proto(),
+				Limits:     rootState.limits,
+			},
+			Base:   baseFp,
+			Filter: sf.Proto(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowScenarioMatrixDiff(res)
+}
+
+// checkoutScenarioMatrixDiffBase checks out the git reference into a temporary detached worktree
+// of the repository of the flight plan and returns the directory of the flight plan in it, along
+// with a function that removes the worktree.
+func checkoutScenarioMatrixDiffBase(ctx context.Context, ref string) (string, func(), error) {
+	// Resolve symlinks as git always returns the real path of the repository
+	dir, err := filepath.EvalSymlinks(scenarioState.baseDir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	top, err := runScenarioMatrixDiffGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", nil, fmt.Errorf("finding the git repository of the flight plan: %w", err)
+	}
+
+	rel, err := filepath.Rel(top, dir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Resolve the reference to a commit so that it can't be parsed as an option of the worktree
+	sha, err := runScenarioMatrixDiffGit(ctx, top, "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+	if err != nil || sha == "" {
+		return "", nil, fmt.Errorf("%s is not a known commit, branch, or tag of the repository %s", ref, top)
+	}
+
+	tmp, err := os.MkdirTemp("", "enos-matrix-diff-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	worktree := filepath.Join(tmp, "base")
+	if _, err := runScenarioMatrixDiffGit(ctx, top, "worktree", "add", "--detach", worktree, sha); err != nil {
+		_ = os.RemoveAll(tmp)
+
+		return "", nil, fmt.Errorf("checking out %s: %w", ref, err)
+	}
+
+	cleanup := func() {
+		// Remove the worktree even if our context has been canceled
+		_, _ = runScenarioMatrixDiffGit(context.Background(), top, "worktree", "remove", "--force", worktree)
+		_ = os.RemoveAll(tmp)
+	}
+
+	return filepath.Join(worktree, rel), cleanup, nil
+}
+
+// runScenarioMatrixDiffGit executes git in the directory and returns its trimmed output.
+func runScenarioMatrixDiffGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var scenarioMatrixShowState = struct {
+	preview int32
+}{}
+
+// newScenarioMatrixShowCmd returns a new 'scenario matrix show' sub-command.
+func newScenarioMatrixShowCmd() *cobra.Command {
+	showCmd := &cobra.Command{
+		Use:               "show <scenario> [FILTER]",
+		Short:             "Show the dimensions and size of a scenario matrix",
+		Long:              "Show each dimension of the matrix of a scenario and its values, how many vectors the matrix has before and after its excludes and the filter, and an evenly spaced preview of the filtered vectors. " + scenarioFilterDesc,
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              runScenarioMatrixShowCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	showCmd.PersistentFlags().Int32Var(&scenarioMatrixShowState.preview, "preview", 10, "The maximum number of filtered vectors to preview")
+
+	return showCmd
+}
+
+// runScenarioMatrixShowCmd shows the matrix of a scenario.
+func runScenarioMatrixShowCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, err := parseScenarioFilter(args)
+	if err != nil {
+		return ui.ShowScenarioMatrix(&pb.ShowScenarioMatrixResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := rootState.enosConnection.Client.ShowScenarioMatrix(
+		ctx, &pb.ShowScenarioMatrixRequest{
+			Workspace: &pb.Workspace{
+				Flightplan: scenarioState.protoFp,
+				TfExecCfg:  scenarioState.tfConfig.Proto(),
+				Limits:     rootState.limits,
+			},
+			Filter:  sf.Proto(),
+			Preview: scenarioMatrixShowState.preview,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowScenarioMatrix(res)
+}
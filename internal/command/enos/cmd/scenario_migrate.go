@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var scenarioMigrateState = struct {
+	dryRun bool
+}{}
+
+// newScenarioMigrateCmd returns a new 'scenario migrate' sub-command.
+func newScenarioMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "migrate [FILTER]",
+		Short:             "Move generated modules into the layout of the out directory",
+		Long:              "Move the generated Terraform modules of matching scenarios, along with their Terraform state, from the directories of any other layout into the directories of the layout that has been configured in the project configuration. Run it after changing the layout so that scenarios that have been launched can still be destroyed. " + scenarioFilterDesc,
+		RunE:              runScenarioMigrateCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	cmd.PersistentFlags().BoolVar(&scenarioMigrateState.dryRun, "dry-run", false, "Show the modules that would be moved without moving them")
+
+	return cmd
+}
+
+// runScenarioMigrateCmd runs a scenario migrate.
+func runScenarioMigrateCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, ws, err := prepareScenarioOpReq(args)
+	if err != nil {
+		return err
+	}
+
+	res, err := rootState.enosConnection.Client.MigrateScenarios(
+		ctx, &pb.MigrateScenariosRequest{
+			Workspace: ws,
+			Filter:    sf,
+			DryRun:    scenarioMigrateState.dryRun,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowScenarioMigrate(res)
+}
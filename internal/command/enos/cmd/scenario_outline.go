@@ -4,6 +4,10 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
@@ -11,17 +15,27 @@ import (
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
+var scenarioOutlineState = struct {
+	stream       bool
+	markdownFile string
+	sort         string
+}{}
+
 // newScenarioOutlineCmd returns a new 'scenario outline' sub-command.
 func newScenarioOutlineCmd() *cobra.Command {
 	outlineCmd := &cobra.Command{
 		Use:               "outline [NAME]",
 		Short:             "Provide an outline of the function of scenarios",
-		Long:              "Provide an outline of the function of scenarios. This includes the description, matrix variants, steps. and quality verifications that are performed",
+		Long:              "Provide an outline of the function of scenarios. This includes the description, matrix variants, steps, outputs, and quality verifications that are performed",
 		RunE:              runScenarioOutlineCmd,
 		ValidArgsFunction: scenarioNameCompletion,
 	}
 
 	outlineCmd.PersistentFlags().StringVar(&rootState.format, "format", "text", "The output format to use: text, json, html")
+	outlineCmd.PersistentFlags().BoolVar(&scenarioOutlineState.stream, "stream", false, "Show each scenario outline as soon as it has been decoded")
+	outlineCmd.PersistentFlags().StringVar(&scenarioOutlineState.markdownFile, "markdown-file", "", "Also write the outlines as Markdown test documentation to the path, e.g. $GITHUB_STEP_SUMMARY")
+	outlineCmd.PersistentFlags().StringVar(&scenarioOutlineState.sort, "sort", "", "The order of the scenario outlines: "+strings.Join(flightplan.ScenarioSorts, ", ")+". The duration order estimates durations from the operation history")
+	outlineCmd.MarkFlagsMutuallyExclusive("stream", "markdown-file")
 
 	return outlineCmd
 }
@@ -31,24 +45,66 @@ func runScenarioOutlineCmd(cmd *cobra.Command, args []string) error {
 	ctx, cancel := scenarioTimeoutContext()
 	defer cancel()
 
-	sf, err := flightplan.ParseScenarioFilter(args)
+	sf, err := parseScenarioFilter(args)
 	if err != nil {
 		return ui.ShowScenarioOutline(&pb.OutlineScenariosResponse{
 			Diagnostics: diagnostics.FromErr(err),
 		})
 	}
 
-	res, err := rootState.enosConnection.Client.OutlineScenarios(
-		ctx, &pb.OutlineScenariosRequest{
-			Workspace: &pb.Workspace{
-				Flightplan: scenarioState.protoFp,
-			},
-			Filter: sf.Proto(),
+	order, err := flightplan.ParseScenarioSort(scenarioOutlineState.sort)
+	if err != nil {
+		return ui.ShowScenarioOutline(&pb.OutlineScenariosResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	req := &pb.OutlineScenariosRequest{
+		Workspace: &pb.Workspace{
+			Flightplan: scenarioState.protoFp,
+			OutDir:     scenarioState.outDir,
+			Limits:     rootState.limits,
 		},
-	)
+		Filter: sf.Proto(),
+		Sort:   order,
+	}
+
+	if scenarioOutlineState.stream {
+		stream, err := rootState.enosConnection.Client.StreamOutlineScenarios(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return ui.ShowScenarioOutlineStream(stream)
+	}
+
+	res, err := rootState.enosConnection.Client.OutlineScenarios(ctx, req)
 	if err != nil {
 		return err
 	}
 
+	if scenarioOutlineState.markdownFile != "" && len(res.GetOutlines()) > 0 {
+		if err := writeScenarioOutlineMarkdown(res, scenarioOutlineState.markdownFile); err != nil {
+			res.Diagnostics = append(res.Diagnostics, diagnostics.FromErr(err)...)
+		}
+	}
+
 	return ui.ShowScenarioOutline(res)
 }
+
+// writeScenarioOutlineMarkdown appends the outlines as Markdown to the path.
+func writeScenarioOutlineMarkdown(res *pb.OutlineScenariosResponse, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(flightplan.OutlinesMarkdown(res.GetOutlines()))
+
+	return err
+}
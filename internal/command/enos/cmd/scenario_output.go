@@ -10,17 +10,22 @@ import (
 	"github.com/hashicorp/go-multierror"
 )
 
+var scenarioOutputState = struct {
+	showSensitive bool
+}{}
+
 // newScenarioOutputCmd returns a new 'scenario output' command.
 func newScenarioOutputCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:               "output [FILTER]",
 		Short:             "Show the output of selected scenarios",
-		Long:              "Show the output of selected scenarios. " + scenarioFilterDesc,
+		Long:              "Show the output of selected scenarios. The values of sensitive outputs are redacted unless --show-sensitive is set. " + scenarioFilterDesc,
 		RunE:              runScenarioOutputCmd,
 		ValidArgsFunction: scenarioNameCompletion,
 	}
 
 	cmd.PersistentFlags().StringVar(&scenarioState.tfConfig.OutputName, "name", "", "The Terraform state value to show")
+	cmd.PersistentFlags().BoolVar(&scenarioOutputState.showSensitive, "show-sensitive", false, "Show the values of sensitive outputs")
 
 	_ = cmd.Flags().MarkHidden("out") // Allow passing out for testing but mark it hidden
 
@@ -39,8 +44,9 @@ func runScenarioOutputCmd(cmd *cobra.Command, args []string) error {
 
 	res, err := rootState.enosConnection.Client.OutputScenarios(
 		ctx, &pb.OutputScenariosRequest{
-			Workspace: ws,
-			Filter:    sf,
+			Workspace:     ws,
+			Filter:        sf,
+			ShowSensitive: scenarioOutputState.showSensitive,
 		},
 	)
 	if err != nil {
@@ -29,6 +29,7 @@ func runSampleListCmd(cmd *cobra.Command, args []string) error {
 		ctx, &pb.ListSamplesRequest{
 			Workspace: &pb.Workspace{
 				Flightplan: scenarioState.protoFp,
+				Limits:     rootState.limits,
 			},
 		},
 	)
@@ -45,6 +45,10 @@ func (t *sampleObserveFilter) Proto() *pb.Sample_Filter {
 	return f
 }
 
+var sampleObserveState = struct {
+	stream bool
+}{}
+
 // NewScenarioSampleObserveCmd returns a new 'scenario samples observe' sub-command.
 func NewScenarioSampleObserveCmd() *cobra.Command {
 	sampleObserveCmd := &cobra.Command{
@@ -61,6 +65,7 @@ func NewScenarioSampleObserveCmd() *cobra.Command {
 	sampleObserveCmd.PersistentFlags().Int32Var(&scenarioState.sampleFilter.Max, "max", -1, "The maximum number of sample elements to return")
 	sampleObserveCmd.PersistentFlags().Float32Var(&scenarioState.sampleFilter.Pct, "pct", -1, "The percentage of sample elements to return")
 	sampleObserveCmd.PersistentFlags().Int64Var(&scenarioState.sampleFilter.Seed, "seed", -1, "The entropy seed for the sampling random source")
+	sampleObserveCmd.PersistentFlags().BoolVar(&sampleObserveState.stream, "stream", false, "Show each sample element as soon as it has been observed")
 
 	return sampleObserveCmd
 }
@@ -72,14 +77,24 @@ func runSampleShowCmd(cmd *cobra.Command, args []string) error {
 
 	scenarioState.sampleFilter.SampleName = args[0]
 
-	res, err := rootState.enosConnection.Client.ObserveSample(
-		ctx, &pb.ObserveSampleRequest{
-			Workspace: &pb.Workspace{
-				Flightplan: scenarioState.protoFp,
-			},
-			Filter: scenarioState.sampleFilter.Proto(),
+	req := &pb.ObserveSampleRequest{
+		Workspace: &pb.Workspace{
+			Flightplan: scenarioState.protoFp,
+			Limits:     rootState.limits,
 		},
-	)
+		Filter: scenarioState.sampleFilter.Proto(),
+	}
+
+	if sampleObserveState.stream {
+		stream, err := rootState.enosConnection.Client.StreamObserveSample(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return ui.ShowSampleObservationStream(stream)
+	}
+
+	res, err := rootState.enosConnection.Client.ObserveSample(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var scenarioShowAtState = struct {
+	file   string
+	line   int64
+	column int64
+}{}
+
+// newScenarioShowAtCmd returns a new 'scenario show-at' sub-command.
+func newScenarioShowAtCmd() *cobra.Command {
+	showAtCmd := &cobra.Command{
+		Use:   "show-at --file <enos.hcl> --line <N> [--column <M>]",
+		Short: "Show the scenario at a position of a flight plan file",
+		Long:  "Show the scenario, step, output, block, and attribute, e.g. a matrix dimension, that enclose a one based line and column of a flight plan file. If the position is in a scenario the resolved values of its variants are shown, narrowed to the step or output that encloses it. Editor integrations can use it with --format json instead of a language server.",
+		Args:  cobra.NoArgs,
+		RunE:  runScenarioShowAtCmd,
+	}
+
+	showAtCmd.PersistentFlags().StringVar(&scenarioShowAtState.file, "file", "", "The flight plan file")
+	showAtCmd.PersistentFlags().Int64Var(&scenarioShowAtState.line, "line", 0, "The one based line of the position")
+	showAtCmd.PersistentFlags().Int64Var(&scenarioShowAtState.column, "column", 1, "The one based column of the position in characters")
+	_ = showAtCmd.MarkPersistentFlagRequired("file")
+	_ = showAtCmd.MarkPersistentFlagRequired("line")
+
+	return showAtCmd
+}
+
+// runScenarioShowAtCmd runs a scenario show-at.
+func runScenarioShowAtCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	file, err := filepath.Abs(scenarioShowAtState.file)
+	if err != nil {
+		return ui.ShowScenarioAt(&pb.ShowScenarioAtResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	res, err := rootState.enosConnection.Client.ShowScenarioAt(
+		ctx, &pb.ShowScenarioAtRequest{
+			Workspace: &pb.Workspace{
+				Flightplan: scenarioState.protoFp,
+				TfExecCfg:  scenarioState.tfConfig.Proto(),
+				Limits:     rootState.limits,
+			},
+			File:   file,
+			Line:   scenarioShowAtState.line,
+			Column: scenarioShowAtState.column,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowScenarioAt(res)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var scenarioStateRestoreState = struct {
+	snapshot string
+}{}
+
+// newScenarioStateCmd returns a new 'scenario state' sub-command.
+func newScenarioStateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Back up and restore the state of launched scenarios",
+		Long:  "Back up the Terraform state and variables of launched scenarios into timestamped snapshots and restore them, e.g. to recover from a bad exec or manual state surgery.",
+	}
+
+	cmd.AddCommand(newScenarioStateBackupCmd())
+	cmd.AddCommand(newScenarioStateRestoreCmd())
+
+	return cmd
+}
+
+// newScenarioStateBackupCmd returns a new 'scenario state backup' sub-command.
+func newScenarioStateBackupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "backup [FILTER]",
+		Short:             "Back up the state of scenarios into snapshots",
+		Long:              "Pull the Terraform state of matching scenarios from their backends and write it, along with the variables files of the Scenario's Terraform Root Module, into a snapshot that is identified by the time it was taken. Snapshots are kept in the snapshots directory of the module. " + scenarioFilterDesc,
+		RunE:              runScenarioStateBackupCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+}
+
+// newScenarioStateRestoreCmd returns a new 'scenario state restore' sub-command.
+func newScenarioStateRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "restore [FILTER]",
+		Short:             "Restore the state of scenarios from snapshots",
+		Long:              "Restore the variables files of a snapshot into the Scenario's Terraform Root Module of matching scenarios and push the state of the snapshot to their backends, replacing the current state. " + scenarioFilterDesc,
+		RunE:              runScenarioStateRestoreCmd,
+		ValidArgsFunction: scenarioNameCompletion,
+	}
+
+	cmd.PersistentFlags().StringVar(&scenarioStateRestoreState.snapshot, "snapshot", "", "The ID of the snapshot to restore. Defaults to the latest snapshot of each scenario")
+
+	return cmd
+}
+
+// runScenarioStateBackupCmd runs a scenario state backup.
+func runScenarioStateBackupCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, ws, err := prepareScenarioOpReq(args)
+	if err != nil {
+		return err
+	}
+
+	res, err := rootState.enosConnection.Client.BackupScenarioStates(
+		ctx, &pb.BackupScenarioStatesRequest{
+			Workspace: ws,
+			Filter:    sf,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowOperationResponses(rootState.enosConnection.StreamOperations(ctx, res, ui))
+}
+
+// runScenarioStateRestoreCmd runs a scenario state restore.
+func runScenarioStateRestoreCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := scenarioTimeoutContext()
+	defer cancel()
+
+	sf, ws, err := prepareScenarioOpReq(args)
+	if err != nil {
+		return err
+	}
+
+	res, err := rootState.enosConnection.Client.RestoreScenarioStates(
+		ctx, &pb.RestoreScenarioStatesRequest{
+			Workspace: ws,
+			Filter:    sf,
+			Snapshot:  scenarioStateRestoreState.snapshot,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowOperationResponses(rootState.enosConnection.StreamOperations(ctx, res, ui))
+}
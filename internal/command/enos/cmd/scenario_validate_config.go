@@ -7,7 +7,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
-	"github.com/hashicorp/enos/internal/flightplan"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -25,6 +24,8 @@ func newScenarioValidateConfigCmd() *cobra.Command {
 	scenarioValidateCmd.PersistentFlags().StringVarP(&scenarioState.sampleFilter.SampleName, "sample-name", "s", "", "Focus on a sample by name")
 	scenarioValidateCmd.PersistentFlags().BoolVar(&scenarioState.noValidateScenarios, "no-scenarios", false, "Do not validate scenarios")
 	scenarioValidateCmd.PersistentFlags().BoolVar(&scenarioState.noValidateSamples, "no-samples", false, "Do not validate scenario samples")
+	scenarioValidateCmd.PersistentFlags().BoolVar(&scenarioState.unusedAsErrors, "unused-as-errors", false, "Report unused modules, providers, and variables as errors instead of warnings")
+	scenarioValidateCmd.PersistentFlags().BoolVar(&scenarioState.verifyModuleSources, "verify-module-sources", false, "Verify that the registry and git sources of step modules exist")
 
 	return scenarioValidateCmd
 }
@@ -34,7 +35,7 @@ func runScenarioValidateCfgCmd(cmd *cobra.Command, args []string) error {
 	ctx, cancel := scenarioTimeoutContext()
 	defer cancel()
 
-	sf, err := flightplan.ParseScenarioFilter(args)
+	sf, err := parseScenarioFilter(args)
 	if err != nil {
 		return ui.ShowScenariosValidateConfig(&pb.ValidateScenariosConfigurationResponse{
 			Diagnostics: diagnostics.FromErr(err),
@@ -45,16 +46,25 @@ func runScenarioValidateCfgCmd(cmd *cobra.Command, args []string) error {
 		ctx, &pb.ValidateScenariosConfigurationRequest{
 			Workspace: &pb.Workspace{
 				Flightplan: scenarioState.protoFp,
+				Limits:     rootState.limits,
 			},
 			Filter:              sf.Proto(),
 			SampleFilter:        scenarioState.sampleFilter.Proto(),
 			NoValidateSamples:   scenarioState.noValidateSamples,
 			NoValidateScenarios: scenarioState.noValidateScenarios,
+			UnusedAsErrors:      scenarioState.unusedAsErrors,
+			VerifyModuleSources: scenarioState.verifyModuleSources,
 		},
 	)
 	if err != nil {
 		return err
 	}
 
+	res.Diagnostics = append(res.GetDiagnostics(), warningBudgetDiagnostics(
+		res.GetDiagnostics(),
+		res.GetDecode().GetDiagnostics(),
+		res.GetSampleDecode().GetDiagnostics(),
+	)...)
+
 	return ui.ShowScenariosValidateConfig(res)
 }
@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/schema"
+)
+
+var schemaPrintState = &struct {
+	stream bool
+}{}
+
+// newSchemaCmd returns a new 'schema' command.
+func newSchemaCmd() *cobra.Command {
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "JSON output and HCL schemas",
+		Long:  "Display the schemas of the JSON output of commands and of enos HCL files. Every JSON response includes the schema_version of its schema. The schema only evolves in backwards compatible ways, any incompatible change results in a new schema version.",
+	}
+
+	schemaCmd.AddCommand(newSchemaPrintCmd())
+	schemaCmd.AddCommand(newSchemaHCLCmd())
+
+	return schemaCmd
+}
+
+// newSchemaPrintCmd returns a new 'schema print' sub-command.
+func newSchemaPrintCmd() *cobra.Command {
+	printCmd := &cobra.Command{
+		Use:       "print <command>",
+		Short:     "Print the JSON Schema of the JSON output of a command",
+		Long:      "Print the JSON Schema of the response that the command writes with --format json, e.g. enos schema print scenario list. The supported commands are: " + strings.Join(schema.CommandNames(), ", "),
+		Args:      cobra.MinimumNArgs(1),
+		ValidArgs: schema.CommandNames(),
+		RunE:      runSchemaPrintCmd,
+	}
+
+	printCmd.PersistentFlags().BoolVar(&schemaPrintState.stream, "stream", false, "Print the schema of each message that the command writes with --stream")
+
+	return printCmd
+}
+
+// runSchemaPrintCmd prints the JSON Schema of a command.
+func runSchemaPrintCmd(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	command, err := schema.FindCommand(strings.Join(args, " "), schemaPrintState.stream)
+	if err != nil {
+		return err
+	}
+
+	out, err := command.JSONSchema()
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowSchema(out)
+}
+
+// newSchemaHCLCmd returns a new 'schema hcl' sub-command.
+func newSchemaHCLCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hcl",
+		Short: "Print the schema of the blocks, attributes, and types of enos HCL files",
+		Long:  "Print a machine readable JSON schema of every block, attribute, and type that is supported in flight plan, variables, lint, skip, and project configuration files, for editor validation and third-party generators. It's generated from the schemas of the decoder. Block schemas are modeled on those of 'terraform providers schema -json', where the types of attributes are JSON encoded cty types, and freeform blocks accept attributes that aren't in their schema.",
+		Args:  cobra.NoArgs,
+		RunE:  runSchemaHCLCmd,
+	}
+}
+
+// runSchemaHCLCmd prints the HCL schema.
+func runSchemaHCLCmd(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	hclSchema, err := flightplan.NewHCLSchema()
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(hclSchema, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ui.ShowSchema(out)
+}
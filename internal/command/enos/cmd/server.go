@@ -5,20 +5,190 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
 	"net/url"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 
 	"github.com/hashicorp/enos/internal/client"
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/metrics"
+	"github.com/hashicorp/enos/internal/nomad"
 	"github.com/hashicorp/enos/internal/operation"
 	"github.com/hashicorp/enos/internal/server"
 	"github.com/hashicorp/enos/internal/state"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	"github.com/hashicorp/go-hclog"
 )
 
+var serverArgs struct {
+	projects    []string
+	pprofListen string
+}
+
+func newServerCmd() *cobra.Command {
+	serverCmd := &cobra.Command{
+		Use:   "server",
+		Short: "Run the enos gRPC server",
+		Long:  "Run the enos gRPC server on the grpc-listen address until interrupted. The server implements the grpc.health.v1 service and reports itself as serving once it is ready",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			if err := registerServerProjects(ctx, serverArgs.projects); err != nil {
+				return err
+			}
+
+			if serverArgs.pprofListen != "" {
+				stop, err := startPprofServer(serverArgs.pprofListen)
+				if err != nil {
+					return err
+				}
+				defer stop()
+			}
+
+			rootState.enosConnection.Log.Info("enos server is running",
+				"listen_grpc", rootState.enosConnection.Addr.String(),
+			)
+
+			<-ctx.Done()
+
+			return nil
+		},
+	}
+
+	serverCmd.PersistentFlags().StringSliceVar(&serverArgs.projects, "project", nil, "Register a project with the server as name=dir. Can be given multiple times")
+	serverCmd.PersistentFlags().StringVar(&serverArgs.pprofListen, "pprof-listen", "", "Serve Go pprof profiles over HTTP on the given address, e.g. localhost:6060")
+
+	return serverCmd
+}
+
+// registerServerProjects takes name=dir project pairs and registers them with the server.
+func registerServerProjects(ctx context.Context, projects []string) error {
+	for _, project := range projects {
+		name, dir, ok := strings.Cut(project, "=")
+		if !ok || name == "" || dir == "" {
+			return fmt.Errorf("invalid project %q, expected name=dir", project)
+		}
+
+		res, err := rootState.enosConnection.Client.RegisterProject(ctx, &pb.RegisterProjectRequest{
+			Project: &pb.Project{Name: name, Dir: dir, Limits: rootState.limits},
+		})
+		if err != nil {
+			return err
+		}
+
+		if diagnostics.HasErrors(res.GetDiagnostics()) {
+			return &diagnostics.Error{Diags: res.GetDiagnostics()}
+		}
+
+		rootState.enosConnection.Log.Info("registered project",
+			"name", res.GetProject().GetName(),
+			"dir", res.GetProject().GetDir(),
+		)
+	}
+
+	return nil
+}
+
+// registerClientProject registers the project that has been selected with --project with the
+// server. The directory of the project is the flight plan directory of the command so that the out
+// directory of the project is the same as when no project has been selected.
+func registerClientProject(cmd *cobra.Command) error {
+	if rootState.project == "" {
+		return nil
+	}
+
+	dir := "."
+	if chdir := cmd.Flags().Lookup("chdir"); chdir != nil && chdir.Value.String() != "" {
+		dir = chdir.Value.String()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := rootState.enosConnection.Client.RegisterProject(ctx, &pb.RegisterProjectRequest{
+		Project: &pb.Project{Name: rootState.project, Dir: dir, Limits: rootState.limits},
+	})
+	if err != nil {
+		return err
+	}
+
+	if diagnostics.HasErrors(res.GetDiagnostics()) {
+		return &diagnostics.Error{Diags: res.GetDiagnostics()}
+	}
+
+	return nil
+}
+
+// startPprofServer serves the runtime profiling data over HTTP on the address. It returns a
+// function that shuts the HTTP server down.
+func startPprofServer(addr string) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("starting pprof listener: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			rootState.enosConnection.Log.Error("pprof server failed", "error", err)
+		}
+	}()
+
+	rootState.enosConnection.Log.Info("serving pprof profiles",
+		"listen_http", "http://"+listener.Addr().String()+"/debug/pprof/",
+	)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}, nil
+}
+
+// serverTerraformConfig returns how the server executes Terraform according to our flags. The
+// flags have been parsed by the time we start the server but the Terraform configuration of
+// scenarios is only set up afterwards.
+func serverTerraformConfig() *pb.Terraform_Runner_Config {
+	cfg := &pb.Terraform_Runner_Config{}
+
+	if scenarioState.containerImage != "" {
+		cfg.Container = &pb.Terraform_Runner_Config_Container{
+			Runtime: scenarioState.containerRuntime,
+			Image:   scenarioState.containerImage,
+		}
+	}
+
+	if scenarioState.target != "" {
+		cfg.Target = &pb.Terraform_Runner_Config_Target{Address: scenarioState.target}
+	}
+
+	return cfg
+}
+
 // startServer starts the enos gRPC server and returns the instance and client to
 // the server.
 func startServer(
@@ -34,10 +204,7 @@ func startServer(
 		return nil, nil, fmt.Errorf("parsing grpc-listen value: %w", err)
 	}
 
-	svrLog := hclog.New(&hclog.LoggerOptions{
-		Name:  "enos",
-		Level: hclog.LevelFromString(rootState.logLevelServer),
-	}).Named("server")
+	svrLog := newLogger(hclog.LevelFromString(rootState.logLevelServer)).Named("server")
 
 	// hclog doesn't support a trace level but we want it for client side. This
 	// allows us to have pretty debug logging but manually handle trace logging
@@ -48,10 +215,38 @@ func startServer(
 		cll = "debug"
 	default:
 	}
-	clientLog := hclog.New(&hclog.LoggerOptions{
-		Name:  "enos",
-		Level: hclog.LevelFromString(cll),
-	}).Named("client")
+	clientLog := newLogger(hclog.LevelFromString(cll)).Named("client")
+
+	// Decode timing is written regardless of the server log level when it's been requested. It's
+	// also written to the server log when debug logging is enabled.
+	var decodeTimingLog hclog.Logger
+	if rootState.debugTiming {
+		decodeTimingLog = hclog.New(&hclog.LoggerOptions{
+			Name:  "enos",
+			Level: hclog.Info,
+		}).Named("decode-timing")
+	} else if svrLog.IsDebug() {
+		decodeTimingLog = svrLog.Named("decode-timing")
+	}
+
+	rootState.metrics = metrics.Discard
+	if rootState.statsdAddr != "" {
+		rootState.metrics, err = metrics.NewStatsD(
+			metrics.WithStatsDAddress(rootState.statsdAddr),
+			metrics.WithStatsDPrefix(rootState.statsdPrefix),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var executor operation.Executor
+	if rootState.operatorConfig.GetNomad().GetJob() != "" {
+		executor, err = nomad.NewExecutor(nomad.WithExecutorConfig(rootState.operatorConfig.GetNomad()))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
 	svr, err := server.New(
 		server.WithGRPCListenURL(listenURL),
@@ -60,11 +255,17 @@ func startServer(
 			grpc.MaxSendMsgSize(rootState.grpcMaxSend),
 		),
 		server.WithLogger(svrLog),
+		server.WithDecodeCache(rootState.decodeCache),
+		server.WithDecodeTimingLogger(decodeTimingLog),
+		server.WithMetrics(rootState.metrics),
+		server.WithTerraformConfig(serverTerraformConfig()),
 		server.WithOperator(
 			operation.NewLocalOperator(
 				operation.WithLocalOperatorLog(svrLog.Named("operator")),
+				operation.WithLocalOperatorMetrics(rootState.metrics),
 				operation.WithLocalOperatorState(state.NewInMemoryState()),
 				operation.WithLocalOperatorConfig(rootState.operatorConfig),
+				operation.WithLocalOperatorExecutor(executor),
 			),
 		),
 	)
@@ -107,6 +308,7 @@ func startServer(
 				),
 				client.WithGRPCListenAddr(cfg.ListenAddr),
 				client.WithLogger(clientLog),
+				client.WithProject(rootState.project),
 			)
 			if err == nil {
 				return svr, enosConnection, nil
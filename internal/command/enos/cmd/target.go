@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hashicorp/enos/internal/target"
+	"github.com/hashicorp/enos/internal/ui/status"
+)
+
+var targetExecArgs struct {
+	address  string
+	dir      string
+	syncDirs []string
+	env      []string
+}
+
+// newTargetCmd returns the hidden command that the Terraform wrapper of remote targets executes.
+// It runs with the stdio of terraform-exec so we don't set up our UI or server for it.
+func newTargetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "target",
+		Short:             "Execute Terraform on remote targets",
+		Hidden:            true,
+		PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+		PersistentPostRun: func(*cobra.Command, []string) {},
+	}
+
+	cmd.AddCommand(newTargetExecCmd())
+
+	return cmd
+}
+
+func newTargetExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "exec -- [TERRAFORM ARGS...]",
+		Short:         "Sync the module directory to the target, execute Terraform on it, and sync the module directory back",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			code, err := runTargetExec(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			}
+
+			if code != 0 || err != nil {
+				return &status.ErrExit{Err: err, ExitCode: max(code, 1)}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&targetExecArgs.address, "target", "", "The address of the target, e.g. ssh://user@host")
+	cmd.Flags().StringVar(&targetExecArgs.dir, "dir", "", "The module directory")
+	cmd.Flags().StringSliceVar(&targetExecArgs.syncDirs, "sync-dir", nil, "More directories to sync to the target")
+	cmd.Flags().StringSliceVar(&targetExecArgs.env, "env", nil, "The names of environment variables to pass to Terraform in addition to the TF_ variables")
+
+	return cmd
+}
+
+// runTargetExec executes the Terraform command on the target and returns its exit code.
+func runTargetExec(args []string) (int, error) {
+	ssh, err := target.ParseSSH(targetExecArgs.address)
+	if err != nil {
+		return 1, err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	return ssh.Exec(ctx, &target.ExecRequest{
+		Dir:      targetExecArgs.dir,
+		SyncDirs: targetExecArgs.syncDirs,
+		Env:      targetExecEnv(ssh),
+		Args:     args,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+	})
+}
+
+// targetExecEnv returns the environment of Terraform on the target, which are the TF_ variables
+// that terraform-exec sets and the variables of the flags. Paths of the Terraform CLI config are
+// local so they are translated to the synced path on the target.
+func targetExecEnv(ssh *target.SSH) map[string]string {
+	env := map[string]string{}
+	for _, val := range os.Environ() {
+		k, v, ok := strings.Cut(val, "=")
+		if ok && strings.HasPrefix(k, "TF_") {
+			env[k] = v
+		}
+	}
+
+	for _, name := range targetExecArgs.env {
+		if v, ok := os.LookupEnv(name); ok {
+			env[name] = v
+		}
+	}
+
+	if p := env["TF_CLI_CONFIG_FILE"]; filepath.IsAbs(p) {
+		env["TF_CLI_CONFIG_FILE"] = ssh.RemotePath(p)
+	}
+
+	return env
+}
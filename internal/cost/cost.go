@@ -0,0 +1,197 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package cost estimates the cost of the resources of Terraform plans with Infracost.
+package cost
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// DefaultCurrency is the currency of estimates when Infracost doesn't report one.
+const DefaultCurrency = "USD"
+
+// Estimator estimates the cost of plans with the infracost CLI. Infracost uses the Infracost
+// pricing API, which requires an INFRACOST_API_KEY in the environment.
+type Estimator struct {
+	binary string
+	env    map[string]string
+}
+
+// EstimatorOpt is a functional option for an Estimator.
+type EstimatorOpt func(*Estimator)
+
+// NewEstimator returns a new Estimator.
+func NewEstimator(opts ...EstimatorOpt) *Estimator {
+	e := &Estimator{
+		binary: "infracost",
+		env:    map[string]string{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// WithEstimatorBinary sets the path to the infracost binary.
+func WithEstimatorBinary(path string) EstimatorOpt {
+	return func(e *Estimator) {
+		if path != "" {
+			e.binary = path
+		}
+	}
+}
+
+// WithEstimatorEnv sets additional environment variables for infracost.
+func WithEstimatorEnv(env map[string]string) EstimatorOpt {
+	return func(e *Estimator) {
+		for k, v := range env {
+			e.env[k] = v
+		}
+	}
+}
+
+// Estimate returns the estimated cost of the resources of the JSON plan at the path.
+func (e *Estimator) Estimate(ctx context.Context, planJSONPath string) (*pb.Terraform_CostEstimate, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	//nolint:gosec // G204 the path of infracost is configurable by design.
+	cmd := exec.CommandContext(ctx, e.binary,
+		"breakdown", "--path", planJSONPath, "--format", "json", "--no-color", "--log-level", "warn",
+	)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = os.Environ()
+	for k, v := range e.env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("estimating cost with infracost: %w: %s", err, msg)
+		}
+
+		return nil, fmt.Errorf("estimating cost with infracost: %w", err)
+	}
+
+	return ParseBreakdown(stdout.Bytes())
+}
+
+// breakdown is the subset of the JSON output of infracost breakdown that we use. Costs are
+// decimal strings, or null for resources that are free or can't be priced.
+type breakdown struct {
+	Currency         string  `json:"currency"`
+	TotalHourlyCost  *string `json:"totalHourlyCost"`
+	TotalMonthlyCost *string `json:"totalMonthlyCost"`
+	Projects         []struct {
+		Breakdown struct {
+			Resources []struct {
+				Name        string  `json:"name"`
+				HourlyCost  *string `json:"hourlyCost"`
+				MonthlyCost *string `json:"monthlyCost"`
+			} `json:"resources"`
+		} `json:"breakdown"`
+	} `json:"projects"`
+}
+
+// ParseBreakdown parses the JSON output of infracost breakdown into a cost estimate. Resources
+// are sorted by the most expensive first.
+func ParseBreakdown(b []byte) (*pb.Terraform_CostEstimate, error) {
+	out := &breakdown{}
+	if err := json.Unmarshal(b, out); err != nil {
+		return nil, fmt.Errorf("parsing infracost breakdown: %w", err)
+	}
+
+	var err error
+	est := &pb.Terraform_CostEstimate{Currency: cmp.Or(out.Currency, DefaultCurrency)}
+	if est.Hourly, err = parseCost(out.TotalHourlyCost); err != nil {
+		return nil, err
+	}
+	if est.Monthly, err = parseCost(out.TotalMonthlyCost); err != nil {
+		return nil, err
+	}
+
+	for _, project := range out.Projects {
+		for _, r := range project.Breakdown.Resources {
+			res := &pb.Terraform_CostEstimate_Resource{Address: r.Name}
+			if res.Hourly, err = parseCost(r.HourlyCost); err != nil {
+				return nil, err
+			}
+			if res.Monthly, err = parseCost(r.MonthlyCost); err != nil {
+				return nil, err
+			}
+			est.Resources = append(est.Resources, res)
+		}
+	}
+
+	slices.SortStableFunc(est.Resources, func(a, b *pb.Terraform_CostEstimate_Resource) int {
+		if c := cmp.Compare(b.GetMonthly(), a.GetMonthly()); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.GetAddress(), b.GetAddress())
+	})
+
+	return est, nil
+}
+
+// Total returns the total of the estimates, e.g. of all scenarios of a matrix. Estimates in other
+// currencies than the first are ignored, as are nil estimates. The total doesn't have resources.
+func Total(estimates ...*pb.Terraform_CostEstimate) *pb.Terraform_CostEstimate {
+	var total *pb.Terraform_CostEstimate
+	for _, est := range estimates {
+		if est == nil {
+			continue
+		}
+
+		if total == nil {
+			total = &pb.Terraform_CostEstimate{Currency: est.GetCurrency()}
+		}
+
+		if est.GetCurrency() != total.GetCurrency() {
+			continue
+		}
+
+		total.Hourly += est.GetHourly()
+		total.Monthly += est.GetMonthly()
+	}
+
+	return total
+}
+
+// Format returns the amount formatted in the currency, e.g. 7.59 USD.
+func Format(amount float64, currency string) string {
+	precision := 2
+	if amount != 0 && amount < 0.01 && amount > -0.01 {
+		precision = 4
+	}
+
+	return strconv.FormatFloat(amount, 'f', precision, 64) + " " + currency
+}
+
+func parseCost(s *string) (float64, error) {
+	if s == nil || *s == "" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(*s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing infracost cost %q: %w", *s, err)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cost
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var testBreakdown = `{
+  "version": "0.2",
+  "currency": "USD",
+  "projects": [
+    {
+      "name": "plan.json",
+      "breakdown": {
+        "resources": [
+          {"name": "module.target.aws_instance.target[0]", "hourlyCost": "0.0104", "monthlyCost": "7.592"},
+          {"name": "module.target.aws_eip.target", "hourlyCost": null, "monthlyCost": null},
+          {"name": "module.backend.aws_instance.backend", "hourlyCost": "0.0416", "monthlyCost": "30.368"}
+        ],
+        "totalHourlyCost": "0.052",
+        "totalMonthlyCost": "37.96"
+      }
+    }
+  ],
+  "totalHourlyCost": "0.052",
+  "totalMonthlyCost": "37.96"
+}`
+
+// Test_ParseBreakdown tests parsing the JSON output of infracost breakdown.
+func Test_ParseBreakdown(t *testing.T) {
+	t.Parallel()
+
+	expected := &pb.Terraform_CostEstimate{
+		Currency: "USD",
+		Hourly:   0.052,
+		Monthly:  37.96,
+		Resources: []*pb.Terraform_CostEstimate_Resource{
+			{Address: "module.backend.aws_instance.backend", Hourly: 0.0416, Monthly: 30.368},
+			{Address: "module.target.aws_instance.target[0]", Hourly: 0.0104, Monthly: 7.592},
+			{Address: "module.target.aws_eip.target"},
+		},
+	}
+
+	got, err := ParseBreakdown([]byte(testBreakdown))
+	require.NoError(t, err)
+	require.True(t, proto.Equal(expected, got), "expected %v, got %v", expected, got)
+
+	got, err = ParseBreakdown([]byte(`{"totalHourlyCost": null, "totalMonthlyCost": null}`))
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&pb.Terraform_CostEstimate{Currency: DefaultCurrency}, got))
+
+	_, err = ParseBreakdown([]byte(`{"totalMonthlyCost": "lots"}`))
+	require.Error(t, err)
+}
+
+// Test_Estimate tests estimating costs with the infracost binary.
+func Test_Estimate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "breakdown.json")
+	require.NoError(t, os.WriteFile(out, []byte(testBreakdown), 0o600))
+	bin := filepath.Join(dir, "infracost")
+	require.NoError(t, os.WriteFile(bin, []byte(`#!/bin/sh
+if [ "$1 $2 $3" != "breakdown --path plan.json" ] || [ "$INFRACOST_API_KEY" != "key" ]; then
+  echo "unexpected arguments: $@" >&2
+  exit 1
+fi
+cat `+out+`
+`), 0o700))
+
+	est, err := NewEstimator(
+		WithEstimatorBinary(bin),
+		WithEstimatorEnv(map[string]string{"INFRACOST_API_KEY": "key"}),
+	).Estimate(context.Background(), "plan.json")
+	require.NoError(t, err)
+	require.InDelta(t, 37.96, est.GetMonthly(), 0.0001)
+
+	_, err = NewEstimator(WithEstimatorBinary(bin)).Estimate(context.Background(), "plan.json")
+	require.ErrorContains(t, err, "unexpected arguments")
+}
+
+// Test_Total tests totaling estimates.
+func Test_Total(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, Total())
+	require.Nil(t, Total(nil, nil))
+
+	total := Total(
+		&pb.Terraform_CostEstimate{Currency: "USD", Hourly: 0.5, Monthly: 365},
+		nil,
+		&pb.Terraform_CostEstimate{Currency: "USD", Hourly: 0.25, Monthly: 182.5},
+		&pb.Terraform_CostEstimate{Currency: "EUR", Hourly: 1, Monthly: 730},
+	)
+	require.Equal(t, "USD", total.GetCurrency())
+	require.InDelta(t, 0.75, total.GetHourly(), 0.0001)
+	require.InDelta(t, 547.5, total.GetMonthly(), 0.0001)
+
+	require.Equal(t, "547.50 USD", Format(total.GetMonthly(), total.GetCurrency()))
+	require.Equal(t, "0.0052 USD", Format(0.0052, "USD"))
+	require.Equal(t, "0.00 USD", Format(0, "USD"))
+}
@@ -19,9 +19,16 @@ import (
 	"github.com/hashicorp/hcl/v2/hclparse"
 	tfjson "github.com/hashicorp/terraform-json"
 
+	"github.com/hashicorp/enos/internal/i18n"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
+// DiagnosticCode can be implemented by the Extra of an hcl.Diagnostic to give the diagnostic a
+// stable code.
+type DiagnosticCode interface {
+	DiagnosticCode() string
+}
+
 // HasErrors returns true if any diagnostic has an error severity.
 func HasErrors(diags ...[]*pb.Diagnostic) bool {
 	return hasSeverity(pb.Diagnostic_SEVERITY_ERROR, diags...)
@@ -194,6 +201,14 @@ func FromHCL(files map[string]*hcl.File, diags hcl.Diagnostics) []*pb.Diagnostic
 			Detail:  diag.Detail,
 		}
 
+		if code, ok := hcl.DiagnosticExtra[DiagnosticCode](diag); ok {
+			pbDiag.Code = code.DiagnosticCode()
+		}
+
+		if fixes, ok := hcl.DiagnosticExtra[DiagnosticFixes](diag); ok {
+			pbDiag.Fixes = fixes.DiagnosticFixes()
+		}
+
 		switch diag.Severity {
 		case hcl.DiagError:
 			pbDiag.Severity = pb.Diagnostic_SEVERITY_ERROR
@@ -231,7 +246,7 @@ func FromHCL(files map[string]*hcl.File, diags hcl.Diagnostics) []*pb.Diagnostic
 				highlightRange.End.Column++
 			}
 
-			pbDiag.Range = hclRangeToProtoRange(highlightRange)
+			pbDiag.Range = FromHCLRange(highlightRange)
 
 			file := files[diag.Subject.Filename]
 			if file != nil && file.Bytes != nil {
@@ -296,49 +311,7 @@ func FromHCL(files map[string]*hcl.File, diags hcl.Diagnostics) []*pb.Diagnostic
 					// This is particularly useful for expressions that get evaluated
 					// multiple times with different values, such as blocks using
 					// "count" and "for_each", or within "for" expressions.
-					expr := diag.Expression
-					ctx := diag.EvalContext
-					vars := expr.Variables()
-					values := make([]*pb.Diagnostic_ExpressionValue, 0, len(vars))
-					seen := make(map[string]struct{}, len(vars))
-				Traversals:
-					for _, traversal := range vars {
-						for len(traversal) > 1 {
-							val, diags := traversal.TraverseAbs(ctx)
-							if diags.HasErrors() {
-								// Skip anything that generates errors, since we probably
-								// already have the same error in our diagnostics set
-								// already.
-								traversal = traversal[:len(traversal)-1]
-
-								continue
-							}
-
-							traversalStr := traversalStr(traversal)
-							if _, exists := seen[traversalStr]; exists {
-								continue Traversals // don't show duplicates when the same variable is referenced multiple times
-							}
-							value := &pb.Diagnostic_ExpressionValue{
-								Traversal: traversalStr,
-							}
-							switch {
-							case !val.IsKnown():
-								if ty := val.Type(); ty != cty.DynamicPseudoType {
-									value.Statement = fmt.Sprintf("is a %s, known only after apply", ty.FriendlyName())
-								} else {
-									value.Statement = "will be known only after apply"
-								}
-							default:
-								value.Statement = "is " + compactValueStr(val)
-							}
-							values = append(values, value)
-							seen[traversalStr] = struct{}{}
-						}
-					}
-					sort.Slice(values, func(i, j int) bool {
-						return values[i].GetTraversal() < values[j].GetTraversal()
-					})
-					pbDiag.Snippet.Values = values
+					pbDiag.Snippet.Values = ExpressionValues(diag.Expression, diag.EvalContext)
 				}
 			}
 		}
@@ -353,6 +326,8 @@ type stringOptConfig struct {
 	showSnippet bool
 	color       *colorstring.Colorize
 	uiSettings  *pb.UI_Settings
+	theme       *Theme
+	catalog     *i18n.Catalog
 }
 
 // StringOpt is an option to the string formatter.
@@ -379,6 +354,21 @@ func WithStringColor(color *colorstring.Colorize) StringOpt {
 	}
 }
 
+// WithStringTheme passes the diagnostic theme to the formatter. Unless set, the theme of the UI
+// settings is used.
+func WithStringTheme(theme *Theme) StringOpt {
+	return func(cfg *stringOptConfig) {
+		cfg.theme = theme
+	}
+}
+
+// WithStringCatalog passes the message catalog that the severity and summary are translated with.
+func WithStringCatalog(catalog *i18n.Catalog) StringOpt {
+	return func(cfg *stringOptConfig) {
+		cfg.catalog = catalog
+	}
+}
+
 // String writes the diagnostic as a string. It takes optional configuration
 // settings to modify the format.
 func String(diag *pb.Diagnostic, opts ...StringOpt) string {
@@ -396,6 +386,15 @@ func String(diag *pb.Diagnostic, opts ...StringOpt) string {
 		cfg.color.Disable = cfg.uiSettings.GetUseColor()
 	}
 
+	if cfg.theme == nil {
+		var err error
+		cfg.theme, err = ThemeFromProto(cfg.uiSettings.GetDiagnosticTheme())
+		if err != nil {
+			cfg.theme = DefaultTheme()
+		}
+	}
+	theme := cfg.theme
+
 	if diag == nil {
 		return ""
 	}
@@ -409,17 +408,16 @@ func String(diag *pb.Diagnostic, opts ...StringOpt) string {
 	}
 
 	switch diag.GetSeverity() {
-	case pb.Diagnostic_SEVERITY_ERROR:
-		buf.WriteString(cfg.color.Color("[bold][red]Error: [reset]"))
-		leftRuleLine = cfg.color.Color("[red]│[reset] ")
-		leftRuleStart = cfg.color.Color("[red]╷[reset]")
-		leftRuleEnd = cfg.color.Color("[red]╵[reset]")
-		leftRuleWidth = 4
-	case pb.Diagnostic_SEVERITY_WARNING:
-		buf.WriteString(cfg.color.Color("[bold][yellow]Warning: [reset]"))
-		leftRuleLine = cfg.color.Color("[yellow]│[reset] ")
-		leftRuleStart = cfg.color.Color("[yellow]╷[reset]")
-		leftRuleEnd = cfg.color.Color("[yellow]╵[reset]")
+	case pb.Diagnostic_SEVERITY_ERROR, pb.Diagnostic_SEVERITY_WARNING:
+		sevColor := theme.severityColor(diag.GetSeverity())
+		if diag.GetSeverity() == pb.Diagnostic_SEVERITY_ERROR {
+			buf.WriteString(cfg.color.Color("[bold]" + theme.color(sevColor, cfg.catalog.T("Error: "))))
+		} else {
+			buf.WriteString(cfg.color.Color("[bold]" + theme.color(sevColor, cfg.catalog.T("Warning: "))))
+		}
+		leftRuleLine = cfg.color.Color(theme.color(sevColor, theme.RuleLine) + " ")
+		leftRuleStart = cfg.color.Color(theme.color(sevColor, theme.RuleStart))
+		leftRuleEnd = cfg.color.Color(theme.color(sevColor, theme.RuleEnd))
 		leftRuleWidth = 4
 	case pb.Diagnostic_SEVERITY_UNSPECIFIED, pb.Diagnostic_SEVERITY_UNKNOWN:
 		buf.WriteString(cfg.color.Color("\n[reset]"))
@@ -430,9 +428,13 @@ func String(diag *pb.Diagnostic, opts ...StringOpt) string {
 	// We don't wrap the summary, since we expect it to be terse, and since
 	// this is where we put the text of a native Go error it may not always
 	// be pure text that lends itself well to word-wrapping.
-	fmt.Fprintf(&buf, cfg.color.Color("[bold]%s[reset]\n\n"), diag.GetSummary())
+	fmt.Fprintf(&buf, cfg.color.Color("[bold]%s[reset]"), cfg.catalog.Translate(diag.GetSummary()))
+	if diag.GetCode() != "" {
+		fmt.Fprintf(&buf, " [%s]", diag.GetCode())
+	}
+	buf.WriteString("\n\n")
 
-	appendSourceSnippets(&buf, diag, cfg.color)
+	appendSourceSnippets(&buf, diag, cfg.color, theme, cfg.uiSettings.GetHyperlinks())
 
 	if diag.GetDetail() != "" {
 		paraWidth := width - leftRuleWidth - 1 // leave room for the left rule
@@ -475,7 +477,13 @@ func String(diag *pb.Diagnostic, opts ...StringOpt) string {
 	return strings.TrimSpace(ruleBuf.String())
 }
 
-func appendSourceSnippets(buf *bytes.Buffer, diag *pb.Diagnostic, color *colorstring.Colorize) {
+func appendSourceSnippets(
+	buf *bytes.Buffer,
+	diag *pb.Diagnostic,
+	color *colorstring.Colorize,
+	theme *Theme,
+	hyperlinks bool,
+) {
 	if diag.GetRange() == nil {
 		fmt.Fprintf(buf, "  (source code not available)\n")
 
@@ -488,7 +496,11 @@ func appendSourceSnippets(buf *bytes.Buffer, diag *pb.Diagnostic, color *colorst
 	if diag.GetSnippet().GetContext() != "" {
 		contextStr = ", in " + diag.GetSnippet().GetContext()
 	}
-	fmt.Fprintf(buf, "  on %s line %d%s:\n", diag.GetRange().GetFilename(), diag.GetRange().GetStart().GetLine(), contextStr)
+	filename := diag.GetRange().GetFilename()
+	if hyperlinks {
+		filename = Hyperlink(diag.GetRange(), filename)
+	}
+	fmt.Fprintf(buf, "  on %s line %d%s:\n", filename, diag.GetRange().GetStart().GetLine(), contextStr)
 
 	// Split the snippet and render the highlighted section with underlines
 	start := int(diag.GetSnippet().GetHighlightStartOffset())
@@ -519,7 +531,7 @@ func appendSourceSnippets(buf *bytes.Buffer, diag *pb.Diagnostic, color *colorst
 	}
 
 	before, highlight, after := code[0:start], code[start:end], code[end:]
-	code = fmt.Sprintf(color.Color("%s[underline]%s[reset]%s"), before, highlight, after)
+	code = fmt.Sprintf(color.Color("%s"+theme.color(theme.HighlightColor, "%s")+"%s"), before, highlight, after)
 
 	// Split the snippet into lines and render one at a time
 	lines := strings.Split(code, "\n")
@@ -543,16 +555,17 @@ func appendSourceSnippets(buf *bytes.Buffer, diag *pb.Diagnostic, color *colorst
 			return values[i].GetTraversal() < values[j].GetTraversal()
 		})
 
-		fmt.Fprint(buf, color.Color("    [dark_gray]├────────────────[reset]\n"))
+		fmt.Fprint(buf, color.Color("    "+theme.color(theme.ValueColor, theme.ValuesStart)+"\n"))
 		for _, value := range values {
-			fmt.Fprintf(buf, color.Color("    [dark_gray]│[reset] [bold]%s[reset] %s\n"), value.GetTraversal(), value.GetStatement())
+			fmt.Fprintf(buf, color.Color("    "+theme.color(theme.ValueColor, theme.RuleLine)+" [bold]%s[reset] %s\n"), value.GetTraversal(), value.GetStatement())
 		}
 	}
 
 	buf.WriteByte('\n')
 }
 
-func hclRangeToProtoRange(rng hcl.Range) *pb.Range {
+// FromHCLRange returns the HCL range as a proto range.
+func FromHCLRange(rng hcl.Range) *pb.Range {
 	return &pb.Range{
 		Filename: rng.Filename,
 		Start: &pb.Range_Pos{
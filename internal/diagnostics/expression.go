@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diagnostics
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/hcl/v2"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ExpressionValues returns the values of the traversals that an expression refers to in the eval
+// context, e.g. var.region is "us-east-1", sorted by traversal.
+func ExpressionValues(expr hcl.Expression, ctx *hcl.EvalContext) []*pb.Diagnostic_ExpressionValue {
+	values := []*pb.Diagnostic_ExpressionValue{}
+	walkExpressionValues(expr, ctx, func(traversal string, val cty.Value) {
+		value := &pb.Diagnostic_ExpressionValue{
+			Traversal: traversal,
+		}
+		switch {
+		case !val.IsKnown():
+			if ty := val.Type(); ty != cty.DynamicPseudoType {
+				value.Statement = fmt.Sprintf("is a %s, known only after apply", ty.FriendlyName())
+			} else {
+				value.Statement = "will be known only after apply"
+			}
+		default:
+			value.Statement = "is " + compactValueStr(val)
+		}
+		values = append(values, value)
+	})
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].GetTraversal() < values[j].GetTraversal()
+	})
+
+	return values
+}
+
+// UnknownTraversal is a traversal that an expression refers to whose value is not wholly known in
+// an eval context, or that can't be resolved in it at all.
+type UnknownTraversal struct {
+	Traversal hcl.Traversal
+	// Unresolved are the diagnostics of resolving the traversal if it can't be resolved, e.g. of
+	// var.nope if no such variable has been declared.
+	Unresolved hcl.Diagnostics
+}
+
+// UnknownTraversals returns the traversals that an expression refers to whose values are not
+// wholly known in the eval context, e.g. var.region if the variable has no value, or that can't be
+// resolved in it, e.g. var.nope if no such variable has been declared, sorted by traversal.
+func UnknownTraversals(expr hcl.Expression, ctx *hcl.EvalContext) []*UnknownTraversal {
+	traversals := map[string]*UnknownTraversal{}
+	for _, traversal := range expr.Variables() {
+		val, diags := traversal.TraverseAbs(ctx)
+		switch {
+		case diags.HasErrors():
+			traversals[traversalStr(traversal)] = &UnknownTraversal{Traversal: traversal, Unresolved: diags}
+		case !val.IsWhollyKnown():
+			traversals[traversalStr(traversal)] = &UnknownTraversal{Traversal: traversal}
+		default:
+		}
+	}
+
+	names := make([]string, 0, len(traversals))
+	for name := range traversals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]*UnknownTraversal, 0, len(names))
+	for _, name := range names {
+		res = append(res, traversals[name])
+	}
+
+	return res
+}
+
+// walkExpressionValues calls the function with the value of every traversal that the expression
+// refers to. Traversals that can't be traversed in full are shortened until they can be, as the
+// errors of the traversals are probably already in the diagnostics.
+func walkExpressionValues(expr hcl.Expression, ctx *hcl.EvalContext, f func(traversal string, val cty.Value)) {
+	vars := expr.Variables()
+	seen := make(map[string]struct{}, len(vars))
+Traversals:
+	for _, traversal := range vars {
+		for len(traversal) > 1 {
+			val, diags := traversal.TraverseAbs(ctx)
+			if diags.HasErrors() {
+				// Skip anything that generates errors, since we probably
+				// already have the same error in our diagnostics set
+				// already.
+				traversal = traversal[:len(traversal)-1]
+
+				continue
+			}
+
+			traversalStr := traversalStr(traversal)
+			if _, exists := seen[traversalStr]; exists {
+				continue Traversals // don't show duplicates when the same variable is referenced multiple times
+			}
+			f(traversalStr, val)
+			seen[traversalStr] = struct{}{}
+		}
+	}
+}
+
+// TraversalString returns a compact representation of the traversal that resembles the native HCL
+// syntax, e.g. var.regions["east"].
+func TraversalString(traversal hcl.Traversal) string {
+	return traversalStr(traversal)
+}
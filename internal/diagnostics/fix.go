@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diagnostics
+
+import (
+	"github.com/hashicorp/hcl/v2"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// DiagnosticFixes can be implemented by the Extra of an hcl.Diagnostic to attach machine-applicable
+// fixes to the diagnostic.
+type DiagnosticFixes interface {
+	DiagnosticFixes() []*pb.Diagnostic_Fix
+}
+
+// fixesExtra is an hcl.Diagnostic extra that carries fixes. Any extra that the diagnostic already
+// had is wrapped so that it can still be found with hcl.DiagnosticExtra.
+type fixesExtra struct {
+	fixes   []*pb.Diagnostic_Fix
+	wrapped any
+}
+
+// DiagnosticFixes implements DiagnosticFixes.
+func (f *fixesExtra) DiagnosticFixes() []*pb.Diagnostic_Fix {
+	return f.fixes
+}
+
+// UnwrapDiagnosticExtra implements hcl.DiagnosticExtraUnwrapper.
+func (f *fixesExtra) UnwrapDiagnosticExtra() any {
+	return f.wrapped
+}
+
+// WithFixes attaches the fixes to the diagnostic and returns it.
+func WithFixes(diag *hcl.Diagnostic, fixes ...*pb.Diagnostic_Fix) *hcl.Diagnostic {
+	if diag == nil || len(fixes) < 1 {
+		return diag
+	}
+
+	if existing, ok := hcl.DiagnosticExtra[DiagnosticFixes](diag); ok {
+		fixes = append(existing.DiagnosticFixes(), fixes...)
+	}
+	diag.Extra = &fixesExtra{fixes: fixes, wrapped: diag.Extra}
+
+	return diag
+}
+
+// NewFix returns a new fix that makes the edits.
+func NewFix(description string, edits ...*pb.Diagnostic_Edit) *pb.Diagnostic_Fix {
+	return &pb.Diagnostic_Fix{
+		Description: description,
+		Edits:       edits,
+	}
+}
+
+// ReplaceEdit returns an edit that replaces the range with the text.
+func ReplaceEdit(rng hcl.Range, text string) *pb.Diagnostic_Edit {
+	return &pb.Diagnostic_Edit{
+		Range: FromHCLRange(rng),
+		Text:  text,
+	}
+}
+
+// InsertEdit returns an edit that inserts the text at the position of the file.
+func InsertEdit(filename string, pos hcl.Pos, text string) *pb.Diagnostic_Edit {
+	return ReplaceEdit(hcl.Range{Filename: filename, Start: pos, End: pos}, text)
+}
+
+// HasFixes returns true if any of the diagnostics has a fix.
+func HasFixes(diags ...[]*pb.Diagnostic) bool {
+	for _, d := range diags {
+		for _, diag := range d {
+			if len(diag.GetFixes()) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
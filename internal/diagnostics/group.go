@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diagnostics
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// FileDiagnostics are the diagnostics of a file.
+type FileDiagnostics struct {
+	Filename    string
+	Diagnostics []*pb.Diagnostic
+}
+
+// GroupByFile groups the diagnostics by the file of their range. Files are sorted by name and
+// diagnostics keep their order within a file. Diagnostics that don't have a file are grouped last
+// with an empty filename.
+func GroupByFile(diags []*pb.Diagnostic) []*FileDiagnostics {
+	groups := []*FileDiagnostics{}
+	byFile := map[string]*FileDiagnostics{}
+
+	for _, diag := range diags {
+		filename := diag.GetRange().GetFilename()
+		group, ok := byFile[filename]
+		if !ok {
+			group = &FileDiagnostics{Filename: filename}
+			byFile[filename] = group
+			groups = append(groups, group)
+		}
+		group.Diagnostics = append(group.Diagnostics, diag)
+	}
+
+	slices.SortStableFunc(groups, func(a, b *FileDiagnostics) int {
+		switch {
+		case a.Filename == b.Filename:
+			return 0
+		case a.Filename == "":
+			return 1
+		case b.Filename == "":
+			return -1
+		default:
+			return strings.Compare(a.Filename, b.Filename)
+		}
+	})
+
+	return groups
+}
+
+// Summary returns the number of errors and warnings of the diagnostics, e.g. "2 errors, 1 warning".
+// Diagnostics of an unknown severity are counted as errors.
+func Summary(diags []*pb.Diagnostic) string {
+	errs, warnings := Counts(diags)
+
+	return pluralize(errs, "error") + ", " + pluralize(warnings, "warning")
+}
+
+// Counts returns the number of errors and warnings of the diagnostics. Diagnostics of an unknown
+// severity are counted as errors.
+func Counts(diags []*pb.Diagnostic) (int, int) {
+	errs := 0
+	warnings := 0
+	for _, diag := range diags {
+		if diag.GetSeverity() == pb.Diagnostic_SEVERITY_WARNING {
+			warnings++
+		} else {
+			errs++
+		}
+	}
+
+	return errs, warnings
+}
+
+// pluralize returns the count and the noun, pluralized if the count isn't one.
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
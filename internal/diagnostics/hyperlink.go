@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diagnostics
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Hyperlink returns the text as an OSC 8 terminal hyperlink to the file and line of the range.
+// If the range has no file the text is returned as-is.
+func Hyperlink(rng *pb.Range, text string) string {
+	uri := FileURI(rng)
+	if uri == "" {
+		return text
+	}
+
+	return "\x1b]8;;" + uri + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// FileURI returns a file:// URI of the file of the range. Terminals and editors don't agree on how
+// to address a line in a file URI so we use the common #L<line> fragment.
+func FileURI(rng *pb.Range) string {
+	if rng.GetFilename() == "" {
+		return ""
+	}
+
+	path, err := filepath.Abs(rng.GetFilename())
+	if err != nil {
+		return ""
+	}
+
+	// Including the hostname allows terminals to ignore links to files on other hosts, e.g. when
+	// running enos over SSH.
+	host, err := os.Hostname()
+	if err != nil {
+		host = ""
+	}
+
+	uri := url.URL{Scheme: "file", Host: host, Path: filepath.ToSlash(path)}
+	if line := rng.GetStart().GetLine(); line > 0 {
+		uri.Fragment = fmt.Sprintf("L%d", line)
+	}
+
+	return uri.String()
+}
@@ -18,8 +18,13 @@ func Status(failOnWarn bool, diags ...*pb.Diagnostic) pb.Operation_Status {
 	return status
 }
 
-// OpResFailed checks an operation response for failure diagnostics.
+// OpResFailed checks an operation response for failure diagnostics. Operations of scenarios that
+// failed as expected haven't failed.
 func OpResFailed(failOnWarn bool, res *pb.Operation_Response) bool {
+	if res.GetXfail().GetFailed() {
+		return false
+	}
+
 	return OpResErrors(res) || (failOnWarn && OpResWarnings(res))
 }
 
@@ -54,6 +59,11 @@ func OperationStatus(failOnWarn bool, res *pb.Operation_Response) pb.Operation_S
 	return status
 }
 
+// OpResDiags returns all of the diagnostics of an operation response.
+func OpResDiags(res *pb.Operation_Response) []*pb.Diagnostic {
+	return resDiags(res)
+}
+
 // resDiags returns all of the diagnostics that might be included in a response.
 func resDiags(res *pb.Operation_Response) []*pb.Diagnostic {
 	return Concat(
@@ -82,6 +92,14 @@ func resDiags(res *pb.Operation_Response) []*pb.Diagnostic {
 		res.GetExec().GetExec().GetDiagnostics(),
 		res.GetOutput().GetDiagnostics(),
 		res.GetOutput().GetOutput().GetDiagnostics(),
+		res.GetLock().GetDiagnostics(),
+		res.GetLock().GetInit().GetDiagnostics(),
+		res.GetLock().GetProvidersLock().GetDiagnostics(),
+		res.GetStateBackup().GetDiagnostics(),
+		res.GetStateBackup().GetStatePull().GetDiagnostics(),
+		res.GetStateRestore().GetDiagnostics(),
+		res.GetStateRestore().GetStatePull().GetDiagnostics(),
+		res.GetStateRestore().GetStatePush().GetDiagnostics(),
 	)
 }
 
@@ -98,5 +116,8 @@ func eventDiags(e *pb.Operation_Event) []*pb.Diagnostic {
 		e.GetExec().GetDiagnostics(),
 		e.GetOutput().GetDiagnostics(),
 		e.GetShow().GetDiagnostics(),
+		e.GetProvidersLock().GetDiagnostics(),
+		e.GetStatePull().GetDiagnostics(),
+		e.GetStatePush().GetDiagnostics(),
 	)
 }
@@ -0,0 +1,118 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diagnostics
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mitchellh/colorstring"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Theme is how diagnostics are rendered as text. Colors are colorstring color codes, e.g. "red" or
+// "underline", and the glyphs are the left rule that delimits each diagnostic.
+type Theme struct {
+	ErrorColor     string
+	WarningColor   string
+	HighlightColor string
+	ValueColor     string
+	RuleStart      string
+	RuleLine       string
+	RuleEnd        string
+	ValuesStart    string
+}
+
+// DefaultTheme returns the default diagnostic theme.
+func DefaultTheme() *Theme {
+	return &Theme{
+		ErrorColor:     "red",
+		WarningColor:   "yellow",
+		HighlightColor: "underline",
+		ValueColor:     "dark_gray",
+		RuleStart:      "╷",
+		RuleLine:       "│",
+		RuleEnd:        "╵",
+		ValuesStart:    "├────────────────",
+	}
+}
+
+// ThemeFromProto returns the diagnostic theme for the proto theme. Colors are validated so that
+// unknown colors are not silently ignored.
+func ThemeFromProto(in *pb.UI_DiagnosticTheme) (*Theme, error) {
+	theme := DefaultTheme()
+	if in == nil {
+		return theme, nil
+	}
+
+	// The colorblind palette avoids distinguishing errors and warnings by red and yellow or green
+	// alone, which are the colors that are most often confused.
+	if in.GetPalette() == pb.UI_DiagnosticTheme_PALETTE_COLORBLIND {
+		theme.ErrorColor = "magenta"
+		theme.WarningColor = "blue"
+	}
+
+	if in.GetAscii() {
+		theme.RuleStart = ","
+		theme.RuleLine = "|"
+		theme.RuleEnd = "'"
+		theme.ValuesStart = "+----------------"
+	}
+
+	names := []string{}
+	for name := range in.GetColors() {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		color := in.GetColors()[name]
+		if _, ok := colorstring.DefaultColors[color]; !ok {
+			return nil, fmt.Errorf("unknown diagnostic %s color %q", name, color)
+		}
+
+		switch name {
+		case "error":
+			theme.ErrorColor = color
+		case "warning":
+			theme.WarningColor = color
+		case "highlight":
+			theme.HighlightColor = color
+		case "value":
+			theme.ValueColor = color
+		default:
+			return nil, fmt.Errorf("unknown diagnostic theme color %q, expected one of error, warning, highlight, or value", name)
+		}
+	}
+
+	return theme, nil
+}
+
+// ParseThemePalette parses the name of a diagnostic theme palette.
+func ParseThemePalette(name string) (pb.UI_DiagnosticTheme_Palette, error) {
+	switch strings.ToLower(name) {
+	case "", "default":
+		return pb.UI_DiagnosticTheme_PALETTE_DEFAULT, nil
+	case "colorblind":
+		return pb.UI_DiagnosticTheme_PALETTE_COLORBLIND, nil
+	default:
+		return pb.UI_DiagnosticTheme_PALETTE_UNSPECIFIED, fmt.Errorf("unknown diagnostic palette %q, expected default or colorblind", name)
+	}
+}
+
+// color wraps the text with the color code.
+func (t *Theme) color(color string, text string) string {
+	return "[" + color + "]" + text + "[reset]"
+}
+
+// severityColor returns the color for the diagnostic severity.
+func (t *Theme) severityColor(sev pb.Diagnostic_Severity) string {
+	if sev == pb.Diagnostic_SEVERITY_WARNING {
+		return t.WarningColor
+	}
+
+	return t.ErrorColor
+}
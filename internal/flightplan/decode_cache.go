@@ -0,0 +1,425 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/enos/version"
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+const (
+	decodeCacheManifestFile = "manifest.json"
+	decodeCacheScenariosExt = ".scenarios.jsonl"
+	// decodeCacheVersion is part of every cache key, along with the version of enos. Increment it
+	// whenever the content of cached scenario references changes so that development builds with
+	// the same enos version never return references from older versions.
+	decodeCacheVersion = 3
+)
+
+// DecodeCache caches parsed flight plan files and decoded scenario references keyed on the
+// content hashes of the flight plan. Parsed files are only cached in memory and only the latest
+// contents of each path are kept. Decoded scenario references are also persisted to the cache
+// directory, if one has been configured, so that subsequent scenario lists can skip decoding
+// unchanged flight plans altogether.
+type DecodeCache struct {
+	mu        sync.RWMutex
+	dir       string
+	version   string
+	files     map[string]*decodeCacheFile
+	scenarios map[string][]*pb.Ref_Scenario
+}
+
+// decodeCacheFile is a parsed file and the hash of the contents it was parsed from.
+type decodeCacheFile struct {
+	hash string
+	file *hcl.File
+}
+
+// DecodeCacheOpt is a functional option for a new decode cache.
+type DecodeCacheOpt func(*DecodeCache)
+
+// DecodeCacheInvalidation describes a file that has changed since the last time a flight plan
+// was decoded with the cache.
+type DecodeCacheInvalidation struct {
+	Path   string
+	Reason string
+}
+
+// NewDecodeCache takes optional functional options and returns a new DecodeCache.
+func NewDecodeCache(opts ...DecodeCacheOpt) *DecodeCache {
+	c := &DecodeCache{
+		version:   buildVersion(),
+		files:     map[string]*decodeCacheFile{},
+		scenarios: map[string][]*pb.Ref_Scenario{},
+	}
+
+	for i := range opts {
+		opts[i](c)
+	}
+
+	return c
+}
+
+// WithDecodeCacheDir sets the directory where the cache persists decoded scenarios.
+func WithDecodeCacheDir(dir string) DecodeCacheOpt {
+	return func(c *DecodeCache) {
+		c.dir = dir
+	}
+}
+
+// WithDecoderCache configures the decoder to use a decode cache when parsing.
+func WithDecoderCache(cache *DecodeCache) DecoderOpt {
+	return func(d *Decoder) error {
+		d.cache = cache
+
+		return nil
+	}
+}
+
+// Dir returns the cache directory.
+func (c *DecodeCache) Dir() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.dir
+}
+
+// ParseHCL parses the source with the parser. If the same file with the same contents has been
+// parsed previously the cached file will be added to the parser instead. A file that is parsed
+// with new contents replaces the previous one so the cache never grows beyond the files in use.
+func (c *DecodeCache) ParseHCL(parser *hclparse.Parser, src []byte, path string) (*hcl.File, hcl.Diagnostics) {
+	if c == nil {
+		return parser.ParseHCL(src, path)
+	}
+
+	hash := hashFile(path, src)
+
+	c.mu.RLock()
+	cached, ok := c.files[path]
+	c.mu.RUnlock()
+	if ok && cached.hash == hash {
+		parser.AddFile(path, cached.file)
+
+		return cached.file, nil
+	}
+
+	file, diags := parser.ParseHCL(src, path)
+	if diags.HasErrors() {
+		// Don't cache files that we failed to parse so that we always return the diagnostics.
+		return file, diags
+	}
+
+	c.mu.Lock()
+	c.files[path] = &decodeCacheFile{hash: hash, file: file}
+	c.mu.Unlock()
+
+	return file, diags
+}
+
+// Key returns a cache key for a decode of the flight plan to the given target with the given
+// filter by this build of enos.
+func (c *DecodeCache) Key(pfp *pb.FlightPlan, target DecodeTarget, filter *pb.Scenario_Filter) (string, error) {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "version:%d\nenos_version:%s\nbase_dir:%s\ntarget:%d\nstrict_schema:%t\n",
+		decodeCacheVersion, c.keyVersion(), pfp.GetBaseDir(), target, pfp.GetStrictSchema(),
+	)
+	writeRawFilesHash(h, "enos_hcl", pfp.GetEnosHcl())
+	writeRawFilesHash(h, "enos_vars_hcl", pfp.GetEnosVarsHcl())
+	writeRawFilesHash(h, "enos_skip_hcl", pfp.GetEnosSkipHcl())
+	writeOutputsFilesHash(h, pfp)
+
+	// Only our variable environment variables can change the result of a decode.
+	env := []string{}
+	for _, v := range pfp.GetEnosVarsEnv() {
+		if strings.HasPrefix(v, EnvVarPrefix) {
+			env = append(env, v)
+		}
+	}
+	slices.Sort(env)
+	for _, v := range env {
+		fmt.Fprintf(h, "env:%s\n", v)
+	}
+
+	if filter != nil {
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(filter)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "filter:%x\n", b)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// keyVersion returns the version of enos that is part of the cache keys.
+func (c *DecodeCache) keyVersion() string {
+	if c == nil {
+		return buildVersion()
+	}
+
+	return c.version
+}
+
+// Hash returns a hash of the flight plan and variables files of the flight plan and of the outputs
+// files that its variables read. Unlike Key it only depends on the files, so that it can be used to
+// tell whether a module was generated from the current flight plan.
+func Hash(pfp *pb.FlightPlan) string {
+	h := sha256.New()
+	writeRawFilesHash(h, "enos_hcl", pfp.GetEnosHcl())
+	writeRawFilesHash(h, "enos_vars_hcl", pfp.GetEnosVarsHcl())
+	writeOutputsFilesHash(h, pfp)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Scenarios returns cached scenario references for the given key. If they are not in memory they
+// will be loaded from the cache directory.
+func (c *DecodeCache) Scenarios(key string) ([]*pb.Ref_Scenario, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	refs, ok := c.scenarios[key]
+	c.mu.RUnlock()
+	if ok {
+		return refs, true
+	}
+
+	if c.dir == "" {
+		return nil, false
+	}
+
+	refs, err := readScenarioRefs(filepath.Join(c.dir, key+decodeCacheScenariosExt))
+	if err != nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	c.scenarios[key] = refs
+	c.mu.Unlock()
+
+	return refs, true
+}
+
+// SetScenarios caches the scenario references for the given key. If a cache directory has been
+// configured the references will also be persisted.
+func (c *DecodeCache) SetScenarios(key string, refs []*pb.Ref_Scenario) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	c.scenarios[key] = refs
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	return writeScenarioRefs(filepath.Join(c.dir, key+decodeCacheScenariosExt), refs)
+}
+
+// Invalidations compares the flight plan with the manifest of the previous decode and returns
+// any files that have been added, changed or removed. The manifest is updated to match the
+// flight plan. It requires a cache directory.
+func (c *DecodeCache) Invalidations(pfp *pb.FlightPlan) ([]*DecodeCacheInvalidation, error) {
+	if c == nil || c.dir == "" {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := filepath.Join(c.dir, decodeCacheManifestFile)
+	prev := map[string]string{}
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, &prev); err != nil {
+			// A corrupted manifest invalidates everything, we'll write a new one below.
+			prev = map[string]string{}
+		}
+	}
+
+	cur := map[string]string{}
+	for _, files := range []map[string][]byte{pfp.GetEnosHcl(), pfp.GetEnosVarsHcl()} {
+		for name, src := range files {
+			cur[name] = hashFile(name, src)
+		}
+	}
+
+	invalidations := []*DecodeCacheInvalidation{}
+	for name, hash := range cur {
+		prevHash, ok := prev[name]
+		switch {
+		case !ok && len(prev) > 0:
+			invalidations = append(invalidations, &DecodeCacheInvalidation{Path: name, Reason: "file was added"})
+		case ok && prevHash != hash:
+			invalidations = append(invalidations, &DecodeCacheInvalidation{Path: name, Reason: "file contents changed"})
+		default:
+		}
+	}
+	for name := range prev {
+		if _, ok := cur[name]; !ok {
+			invalidations = append(invalidations, &DecodeCacheInvalidation{Path: name, Reason: "file was removed"})
+		}
+	}
+	slices.SortStableFunc(invalidations, func(a, b *DecodeCacheInvalidation) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return invalidations, err
+	}
+
+	if len(invalidations) > 0 {
+		// Our cached scenarios are keyed on content hashes so they'll never be returned for a
+		// changed flight plan, but we don't want them to accumulate either.
+		c.scenarios = map[string][]*pb.Ref_Scenario{}
+		stale, err := filepath.Glob(filepath.Join(c.dir, "*"+decodeCacheScenariosExt))
+		if err != nil {
+			return invalidations, err
+		}
+		for _, path := range stale {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return invalidations, err
+			}
+		}
+	}
+
+	b, err = json.Marshal(cur)
+	if err != nil {
+		return invalidations, err
+	}
+
+	return invalidations, os.WriteFile(path, b, 0o600)
+}
+
+// String returns the invalidation as a string.
+func (i *DecodeCacheInvalidation) String() string {
+	if i == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s: %s", i.Path, i.Reason)
+}
+
+// buildVersion returns the version and commit of this build of enos. Development builds share a
+// version so we also need the commit to tell them apart.
+func buildVersion() string {
+	return version.GetHumanVersion() + "+" + version.GitCommit
+}
+
+func hashFile(path string, src []byte) string {
+	h := sha256.New()
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(src)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeRawFilesHash(h interface{ Write([]byte) (int, error) }, kind string, files map[string][]byte) {
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		fmt.Fprintf(h, "%s:%s\n", kind, hashFile(name, files[name]))
+	}
+}
+
+// writeOutputsFilesHash writes the hashes of the outputs files that the variables of the flight
+// plan read, as the values of the variables change with them.
+func writeOutputsFilesHash(h interface{ Write([]byte) (int, error) }, pfp *pb.FlightPlan) {
+	for _, path := range outputsFiles(pfp) {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			// Decoding fails without the file so it must never share a key with a decode that had it.
+			fmt.Fprintf(h, "outputs_file:%s:unreadable\n", path)
+
+			continue
+		}
+		fmt.Fprintf(h, "outputs_file:%s\n", hashFile(path, src))
+	}
+}
+
+func readScenarioRefs(path string) ([]*pb.Ref_Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	refs := []*pb.Ref_Scenario{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		ref := &pb.Ref_Scenario{}
+		if err := protojson.Unmarshal(scanner.Bytes(), ref); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, scanner.Err()
+}
+
+func writeScenarioRefs(path string, refs []*pb.Ref_Scenario) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, ref := range refs {
+		b, err := protojson.Marshal(ref)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		_, _ = w.Write(b)
+		_ = w.WriteByte('\n')
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
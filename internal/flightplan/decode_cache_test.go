@@ -0,0 +1,207 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// Test_DecodeCache_ParseHCL tests that unchanged files are only parsed once.
+func Test_DecodeCache_ParseHCL(t *testing.T) {
+	t.Parallel()
+
+	cache := NewDecodeCache()
+	src := []byte("globals {\n  foo = \"bar\"\n}\n")
+
+	first, diags := cache.ParseHCL(hclparse.NewParser(), src, "enos.hcl")
+	require.False(t, diags.HasErrors())
+
+	parser := hclparse.NewParser()
+	second, diags := cache.ParseHCL(parser, src, "enos.hcl")
+	require.False(t, diags.HasErrors())
+	require.Same(t, first, second)
+	require.Same(t, first, parser.Files()["enos.hcl"])
+
+	changed, diags := cache.ParseHCL(hclparse.NewParser(), append(src, '\n'), "enos.hcl")
+	require.False(t, diags.HasErrors())
+	require.NotSame(t, first, changed)
+
+	// Changed files replace the previous contents of the path
+	require.Len(t, cache.files, 1)
+	again, diags := cache.ParseHCL(hclparse.NewParser(), append(src, '\n'), "enos.hcl")
+	require.False(t, diags.HasErrors())
+	require.Same(t, changed, again)
+	_, diags = cache.ParseHCL(hclparse.NewParser(), src, "other.hcl")
+	require.False(t, diags.HasErrors())
+	require.Len(t, cache.files, 2)
+
+	// Files that fail to parse are never cached
+	_, diags = cache.ParseHCL(hclparse.NewParser(), []byte("globals {"), "bad.hcl")
+	require.True(t, diags.HasErrors())
+	_, diags = cache.ParseHCL(hclparse.NewParser(), []byte("globals {"), "bad.hcl")
+	require.True(t, diags.HasErrors())
+}
+
+// Test_DecodeCache_Key tests that our cache key changes when the result of the decode could.
+func Test_DecodeCache_Key(t *testing.T) {
+	t.Parallel()
+
+	base := &pb.FlightPlan{
+		BaseDir:     "/enos",
+		EnosHcl:     map[string][]byte{"/enos/enos.hcl": []byte("scenario \"foo\" {}")},
+		EnosVarsHcl: map[string][]byte{"/enos/enos.vars.hcl": []byte("foo = \"bar\"")},
+		EnosVarsEnv: []string{"HOME=/home/enos", "ENOS_VAR_foo=bar"},
+	}
+
+	cache := NewDecodeCache()
+	baseKey, err := cache.Key(base, DecodeTargetScenariosNamesExpandVariants, nil)
+	require.NoError(t, err)
+
+	for desc, mutate := range map[string]func(*pb.FlightPlan) (DecodeTarget, *pb.Scenario_Filter){
+		"flight plan file changed": func(fp *pb.FlightPlan) (DecodeTarget, *pb.Scenario_Filter) {
+			fp.EnosHcl["/enos/enos.hcl"] = []byte("scenario \"bar\" {}")
+			return DecodeTargetScenariosNamesExpandVariants, nil
+		},
+		"vars file changed": func(fp *pb.FlightPlan) (DecodeTarget, *pb.Scenario_Filter) {
+			fp.EnosVarsHcl["/enos/enos.vars.hcl"] = []byte("foo = \"baz\"")
+			return DecodeTargetScenariosNamesExpandVariants, nil
+		},
+		"var env changed": func(fp *pb.FlightPlan) (DecodeTarget, *pb.Scenario_Filter) {
+			fp.EnosVarsEnv = []string{"HOME=/home/enos", "ENOS_VAR_foo=baz"}
+			return DecodeTargetScenariosNamesExpandVariants, nil
+		},
+		"target changed": func(fp *pb.FlightPlan) (DecodeTarget, *pb.Scenario_Filter) {
+			return DecodeTargetAll, nil
+		},
+		"filter changed": func(fp *pb.FlightPlan) (DecodeTarget, *pb.Scenario_Filter) {
+			return DecodeTargetScenariosNamesExpandVariants, &pb.Scenario_Filter{Name: "foo"}
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			fp, ok := proto.Clone(base).(*pb.FlightPlan)
+			require.True(t, ok)
+			target, filter := mutate(fp)
+			key, err := cache.Key(fp, target, filter)
+			require.NoError(t, err)
+			require.NotEqual(t, baseKey, key)
+		})
+	}
+
+	t.Run("enos version changed", func(t *testing.T) {
+		t.Parallel()
+
+		other := NewDecodeCache()
+		other.version = "0.0.0-dev+abcdef"
+		key, err := other.Key(base, DecodeTargetScenariosNamesExpandVariants, nil)
+		require.NoError(t, err)
+		require.NotEqual(t, baseKey, key)
+	})
+
+	t.Run("non variable env changed", func(t *testing.T) {
+		t.Parallel()
+
+		fp, ok := proto.Clone(base).(*pb.FlightPlan)
+		require.True(t, ok)
+		fp.EnosVarsEnv = []string{"ENOS_VAR_foo=bar", "HOME=/root"}
+		key, err := cache.Key(fp, DecodeTargetScenariosNamesExpandVariants, nil)
+		require.NoError(t, err)
+		require.Equal(t, baseKey, key)
+	})
+}
+
+// Test_DecodeCache_Key_OutputsFile tests that our cache key and the flight plan hash change when
+// an outputs file that a variable reads changes.
+func Test_DecodeCache_Key_OutputsFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "outputs.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"responses":[]}`), 0o644))
+
+	fp := &pb.FlightPlan{
+		BaseDir: dir,
+		EnosHcl: map[string][]byte{filepath.Join(dir, "enos.hcl"): []byte(`
+variable "vpc_id" {
+  type         = string
+  outputs_file = "outputs.json"
+}
+`)},
+	}
+
+	cache := NewDecodeCache()
+	key, err := cache.Key(fp, DecodeTargetScenariosNamesExpandVariants, nil)
+	require.NoError(t, err)
+	hash := Hash(fp)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"responses":[{}]}`), 0o644))
+	changedKey, err := cache.Key(fp, DecodeTargetScenariosNamesExpandVariants, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, key, changedKey)
+	require.NotEqual(t, hash, Hash(fp))
+
+	require.NoError(t, os.Remove(path))
+	missingKey, err := cache.Key(fp, DecodeTargetScenariosNamesExpandVariants, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, changedKey, missingKey)
+	require.NotEqual(t, key, missingKey)
+}
+
+// Test_DecodeCache_Scenarios tests that cached scenarios are persisted and invalidated.
+func Test_DecodeCache_Scenarios(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fp := &pb.FlightPlan{
+		BaseDir: "/enos",
+		EnosHcl: map[string][]byte{"/enos/enos.hcl": []byte("scenario \"foo\" {}")},
+	}
+	refs := []*pb.Ref_Scenario{
+		NewScenario().Ref(),
+		{Id: &pb.Scenario_ID{Name: "foo", Variants: NewVector(NewElement("backend", "raft")).Proto()}},
+	}
+
+	cache := NewDecodeCache(WithDecodeCacheDir(dir))
+	invalidations, err := cache.Invalidations(fp)
+	require.NoError(t, err)
+	require.Empty(t, invalidations)
+
+	key, err := cache.Key(fp, DecodeTargetScenariosNamesExpandVariants, nil)
+	require.NoError(t, err)
+	_, ok := cache.Scenarios(key)
+	require.False(t, ok)
+	require.NoError(t, cache.SetScenarios(key, refs))
+
+	// A new cache with the same directory should load our scenarios from disk
+	cache = NewDecodeCache(WithDecodeCacheDir(dir))
+	invalidations, err = cache.Invalidations(fp)
+	require.NoError(t, err)
+	require.Empty(t, invalidations)
+	got, ok := cache.Scenarios(key)
+	require.True(t, ok)
+	require.Len(t, got, len(refs))
+	for i := range refs {
+		require.True(t, proto.Equal(refs[i], got[i]))
+	}
+
+	// Changing the flight plan should invalidate and remove the cached scenarios
+	fp.EnosHcl["/enos/enos.hcl"] = []byte("scenario \"bar\" {}")
+	fp.EnosHcl["/enos/enos-new.hcl"] = []byte("")
+	invalidations, err = cache.Invalidations(fp)
+	require.NoError(t, err)
+	require.Len(t, invalidations, 2)
+	require.Equal(t, "/enos/enos-new.hcl: file was added", invalidations[0].String())
+	require.Equal(t, "/enos/enos.hcl: file contents changed", invalidations[1].String())
+	_, ok = cache.Scenarios(key)
+	require.False(t, ok)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// decodeStage is a top-level block decoder whose result is exposed in the eval context as a
+// single variable that shares the name of the block type.
+type decodeStage struct {
+	target    DecodeTarget
+	blockType string
+	decode    func(*hcl.EvalContext) hcl.Diagnostics
+	// implicitDeps are the block types of the stages that the stage depends on without
+	// referring to them, e.g. for default values.
+	implicitDeps []string
+}
+
+// decodeStageResult is the result of decoding a stage.
+type decodeStageResult struct {
+	done   chan struct{}
+	diags  hcl.Diagnostics
+	failed bool
+	val    cty.Value
+}
+
+// decodeStages returns our top-level block decoders in the order in which they have always been
+// decoded. A stage is only able to refer to the variables of stages that come before it.
+func (fp *FlightPlan) decodeStages() []*decodeStage {
+	return []*decodeStage{
+		{target: DecodeTargetTerraformSettings, blockType: blockTypeTerraformSetting, decode: fp.decodeTerraformSettings},
+		{target: DecodeTargetTerraformCLIs, blockType: blockTypeTerraformCLI, decode: fp.decodeTerraformCLIs},
+		{target: DecodeTargetProviders, blockType: blockTypeProvider, decode: fp.decodeProviders},
+		{target: DecodeTargetModules, blockType: blockTypeModule, decode: fp.decodeModules},
+		{
+			target:       DecodeTargetFixtures,
+			blockType:    blockTypeFixture,
+			decode:       fp.decodeFixtures,
+			implicitDeps: []string{blockTypeTerraformSetting, blockTypeTerraformCLI},
+		},
+	}
+}
+
+// scheduleDecodeStages concurrently decodes all top-level block stages that are required for the
+// target. A stage that refers to the variable of a prior stage will wait for that stage to be
+// decoded, otherwise it is decoded immediately. Every stage is decoded with its own eval context
+// that contains only the base variables and those of the stages it depends on, so the result is
+// the same as decoding the stages in order. As with ordered decoding, we'll stop at the first
+// stage that fails and return its diagnostics.
+func (fp *FlightPlan) scheduleDecodeStages(
+	ctx *hcl.EvalContext,
+	target DecodeTarget,
+	timer *DecodeTimer,
+) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	stages := []*decodeStage{}
+	for _, stage := range fp.decodeStages() {
+		if target >= stage.target {
+			stages = append(stages, stage)
+		}
+	}
+	if len(stages) == 0 {
+		return diags
+	}
+
+	base := maps.Clone(ctx.Variables)
+	results := make([]*decodeStageResult, len(stages))
+	for i := range stages {
+		results[i] = &decodeStageResult{done: make(chan struct{})}
+	}
+
+	wg := sync.WaitGroup{}
+	for i := range stages {
+		deps := fp.decodeStageDependencies(stages, i)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(results[i].done)
+
+			vars := maps.Clone(base)
+			if vars == nil {
+				vars = map[string]cty.Value{}
+			}
+			for _, dep := range deps {
+				<-results[dep].done
+				if results[dep].failed {
+					results[i].failed = true
+
+					return
+				}
+				vars[stages[dep].blockType] = results[dep].val
+			}
+
+			stageCtx := &hcl.EvalContext{Variables: vars, Functions: ctx.Functions}
+			start := time.Now()
+			results[i].diags = stages[i].decode(stageCtx)
+			timer.Record(stages[i].blockType, "", start)
+			results[i].failed = results[i].diags.HasErrors()
+			results[i].val = stageCtx.Variables[stages[i].blockType]
+		}()
+	}
+	wg.Wait()
+
+	for i := range stages {
+		diags = diags.Extend(results[i].diags)
+		if results[i].failed {
+			return diags
+		}
+
+		ctx.Variables[stages[i].blockType] = results[i].val
+	}
+
+	return diags
+}
+
+// decodeStageDependencies returns the indices of the prior stages that the stage at idx refers
+// to. If we're unable to determine the references of a block we'll assume that it depends on
+// every prior stage.
+func (fp *FlightPlan) decodeStageDependencies(stages []*decodeStage, idx int) []int {
+	deps := []int{}
+	if idx == 0 {
+		return deps
+	}
+
+	refs := map[string]struct{}{}
+	for _, dep := range stages[idx].implicitDeps {
+		refs[dep] = struct{}{}
+	}
+	known := true
+	for _, block := range fp.BodyContent.Blocks.OfType(stages[idx].blockType) {
+		if !bodyVariableRoots(block.Body, refs) {
+			known = false
+
+			break
+		}
+	}
+
+	for i := range idx {
+		if _, ok := refs[stages[i].blockType]; ok || !known {
+			deps = append(deps, i)
+		}
+	}
+
+	return deps
+}
+
+// bodyVariableRoots adds the root name of every variable that is referenced in the body and any
+// nested blocks to refs. It returns false if the body is not a native syntax body.
+func bodyVariableRoots(body hcl.Body, refs map[string]struct{}) bool {
+	synBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return false
+	}
+
+	for _, attr := range synBody.Attributes {
+		for _, traversal := range attr.Expr.Variables() {
+			refs[traversal.RootName()] = struct{}{}
+		}
+	}
+
+	for _, block := range synBody.Blocks {
+		if !bodyVariableRoots(block.Body, refs) {
+			return false
+		}
+	}
+
+	return true
+}
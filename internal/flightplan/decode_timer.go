@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DecodeTimer records how much time is spent decoding each part of a flight plan. Blocks that are
+// decoded more than once, e.g. a scenario block that is decoded once per variant, are accumulated.
+// A nil timer records nothing.
+type DecodeTimer struct {
+	mu      sync.Mutex
+	timings map[string]*DecodeTiming
+}
+
+// DecodeTiming is the accumulated decode time of a block.
+type DecodeTiming struct {
+	Block    string
+	Name     string
+	Count    int
+	Duration time.Duration
+}
+
+// NewDecodeTimer returns a new DecodeTimer.
+func NewDecodeTimer() *DecodeTimer {
+	return &DecodeTimer{timings: map[string]*DecodeTiming{}}
+}
+
+// WithDecoderTimer configures the decoder to record decode timings.
+func WithDecoderTimer(timer *DecodeTimer) DecoderOpt {
+	return func(d *Decoder) error {
+		d.timer = timer
+
+		return nil
+	}
+}
+
+// Record adds the time since start to the timing for the block with the name. It is designed to be
+// deferred, e.g. defer timer.Record("globals", "", time.Now()).
+func (t *DecodeTimer) Record(block string, name string, start time.Time) {
+	if t == nil {
+		return
+	}
+
+	elapsed := time.Since(start)
+	key := block + "." + name
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timing, ok := t.timings[key]
+	if !ok {
+		timing = &DecodeTiming{Block: block, Name: name}
+		t.timings[key] = timing
+	}
+	timing.Count++
+	timing.Duration += elapsed
+}
+
+// Timings returns a copy of all recorded timings, sorted from the slowest to the fastest.
+func (t *DecodeTimer) Timings() []*DecodeTiming {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := []*DecodeTiming{}
+	for _, timing := range t.timings {
+		cp := *timing
+		timings = append(timings, &cp)
+	}
+
+	slices.SortStableFunc(timings, func(a, b *DecodeTiming) int {
+		if i := cmp.Compare(b.Duration, a.Duration); i != 0 {
+			return i
+		}
+
+		return cmp.Compare(a.String(), b.String())
+	})
+
+	return timings
+}
+
+// String returns the timing as a string.
+func (t *DecodeTiming) String() string {
+	if t == nil {
+		return ""
+	}
+
+	if t.Name == "" {
+		return t.Block
+	}
+
+	return fmt.Sprintf("%s.%s", t.Block, t.Name)
+}
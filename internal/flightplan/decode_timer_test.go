@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_DecodeTimer tests that we record timings for each block that we decode.
+func Test_DecodeTimer(t *testing.T) {
+	t.Parallel()
+
+	timer := NewDecodeTimer()
+	fp, scenarioDecoder, decRes := DecodeProto(
+		context.Background(),
+		&pb.FlightPlan{
+			BaseDir: t.TempDir(),
+			EnosHcl: map[string][]byte{
+				"enos.hcl": []byte(`
+globals {
+  foo = "bar"
+}
+
+scenario "test" {
+  matrix {
+    backend = ["raft", "consul"]
+  }
+}
+`),
+			},
+		},
+		DecodeTargetScenariosNamesExpandVariants,
+		nil,
+		WithDecoderTimer(timer),
+	)
+	require.Empty(t, decRes.GetDiagnostics())
+	require.Empty(t, scenarioDecoder.DecodeAll(context.Background(), fp))
+
+	timings := map[string]*DecodeTiming{}
+	for _, timing := range timer.Timings() {
+		timings[timing.String()] = timing
+	}
+
+	for _, name := range []string{"parse", "variables", "globals", "matrix.test", "scenario.test"} {
+		require.Contains(t, timings, name)
+	}
+	require.Equal(t, 2, timings["scenario.test"].Count)
+	require.NotContains(t, timings, "module")
+
+	// A nil timer is safe to use
+	var nilTimer *DecodeTimer
+	nilTimer.Record("globals", "", time.Now())
+	require.Nil(t, nilTimer.Timings())
+}
@@ -6,7 +6,12 @@ package flightplan
 import (
 	"context"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	yaml "github.com/zclconf/go-cty-yaml"
 	"github.com/zclconf/go-cty/cty"
@@ -16,6 +21,8 @@ import (
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan/funcs"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/enos/version"
+	"github.com/hashicorp/go-hclog"
 	hcl "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/ext/tryfunc"
 	"github.com/hashicorp/hcl/v2/hclparse"
@@ -38,11 +45,63 @@ const (
 	DecodeTargetTerraformCLIs
 	DecodeTargetProviders
 	DecodeTargetModules
+	DecodeTargetFixtures
 	DecodeTargetScenariosOutlines
 	DecodeTargetScenariosComplete
 	DecodeTargetAll // Make sure this is always the latest decoding target
 )
 
+// decodeTargetNames are the human friendly names of our decode targets.
+var decodeTargetNames = map[DecodeTarget]string{
+	DecodeTargetVariables:                    "variables",
+	DecodeTargetGlobals:                      "globals",
+	DecodeTargetSamples:                      "samples",
+	DecodeTargetQualities:                    "qualities",
+	DecodeTargetScenariosNamesNoVariants:     "scenario-names",
+	DecodeTargetScenariosMatrixOnly:          "scenario-matrices",
+	DecodeTargetScenariosNamesExpandVariants: "scenario-variants",
+	DecodeTargetTerraformSettings:            "terraform-settings",
+	DecodeTargetTerraformCLIs:                "terraform-clis",
+	DecodeTargetProviders:                    "providers",
+	DecodeTargetModules:                      "modules",
+	DecodeTargetFixtures:                     "fixtures",
+	DecodeTargetScenariosOutlines:            "scenario-outlines",
+	DecodeTargetScenariosComplete:            "scenarios",
+	DecodeTargetAll:                          "all",
+}
+
+// ParseDecodeTarget takes the name of a decode target and returns the decode target.
+func ParseDecodeTarget(name string) (DecodeTarget, error) {
+	for target, targetName := range decodeTargetNames {
+		if targetName == name {
+			return target, nil
+		}
+	}
+
+	return DecodeTargetUnset, fmt.Errorf("unknown decode target %q, expected one of %s",
+		name, strings.Join(DecodeTargetNames(), ", "),
+	)
+}
+
+// DecodeTargetNames returns the names of all decode targets in order.
+func DecodeTargetNames() []string {
+	names := []string{}
+	for target := DecodeTarget(DecodeTargetVariables); target <= DecodeTargetAll; target++ {
+		names = append(names, decodeTargetNames[target])
+	}
+
+	return names
+}
+
+// String returns the name of the decode target.
+func (t DecodeTarget) String() string {
+	if name, ok := decodeTargetNames[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("DecodeTarget(%d)", int(t))
+}
+
 // DecoderOpt is a functional option for a new flight plan.
 type DecoderOpt func(*Decoder) error
 
@@ -52,6 +111,7 @@ func NewDecoder(opts ...DecoderOpt) (*Decoder, error) {
 		FPParser:   hclparse.NewParser(),
 		VarsParser: hclparse.NewParser(),
 		target:     DecodeTargetAll,
+		log:        hclog.NewNullLogger(),
 	}
 
 	for _, opt := range opts {
@@ -91,6 +151,15 @@ func WithDecoderEnv(vars []string) DecoderOpt {
 	}
 }
 
+// WithDecoderLogger sets the logger.
+func WithDecoderLogger(log hclog.Logger) DecoderOpt {
+	return func(fp *Decoder) error {
+		fp.log = log
+
+		return nil
+	}
+}
+
 // WithDecoderBaseDir sets the flight plan base directory.
 func WithDecoderBaseDir(path string) DecoderOpt {
 	return func(fp *Decoder) error {
@@ -119,22 +188,38 @@ func WithDecoderScenarioFilter(filter *ScenarioFilter) DecoderOpt {
 	}
 }
 
+// WithDecoderSkips sets the skips that exclude scenarios and variants from the decode.
+func WithDecoderSkips(skips []*Skip) DecoderOpt {
+	return func(fp *Decoder) error {
+		fp.skips = skips
+
+		return nil
+	}
+}
+
 // Decoder is our Enos flight plan, or, our representation of the HCL file(s)
 // an author has composed.
 type Decoder struct {
-	FPParser   *hclparse.Parser
-	VarsParser *hclparse.Parser
-	fpFiles    RawFiles
-	varFiles   RawFiles
-	varEnvVars []string
-	dir        string
-	target     DecodeTarget
-	filter     *ScenarioFilter
+	FPParser     *hclparse.Parser
+	VarsParser   *hclparse.Parser
+	fpFiles      RawFiles
+	varFiles     RawFiles
+	varEnvVars   []string
+	dir          string
+	target       DecodeTarget
+	filter       *ScenarioFilter
+	skips        []*Skip
+	cache        *DecodeCache
+	timer        *DecodeTimer
+	limits       *ResourceLimits
+	strictSchema bool
+	log          hclog.Logger
 }
 
 // Parse locates enos configuration files and parses them.
 func (d *Decoder) Parse() hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
+	defer d.timer.Record("parse", "", time.Now())
 
 	// Parse and raw configuration bytes we've been configured with
 	return diags.Extend(d.parseRawFiles())
@@ -142,15 +227,52 @@ func (d *Decoder) Parse() hcl.Diagnostics {
 
 func (d *Decoder) parseRawFiles() hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
+	diags = diags.Extend(d.parseFiles(d.FPParser, d.fpFiles))
+
+	return diags.Extend(d.parseFiles(d.VarsParser, d.varFiles))
+}
 
-	for path, bytes := range d.fpFiles {
-		_, moreDiags := d.FPParser.ParseHCL(bytes, path)
-		diags = diags.Extend(moreDiags)
+// parseFiles concurrently parses the files and adds them to the parser. The parser is not safe
+// for concurrent use so each worker parses with its own and we add the results in path order
+// to keep our diagnostics stable.
+func (d *Decoder) parseFiles(parser *hclparse.Parser, files RawFiles) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	if len(files) == 0 {
+		return diags
 	}
 
-	for path, bytes := range d.varFiles {
-		_, moreDiags := d.VarsParser.ParseHCL(bytes, path)
-		diags = diags.Extend(moreDiags)
+	paths := []string{}
+	for path := range files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	parsed := make([]*hcl.File, len(paths))
+	parsedDiags := make([]hcl.Diagnostics, len(paths))
+
+	jobsC := make(chan int)
+	wg := sync.WaitGroup{}
+	for range min(len(paths), d.limits.Workers()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			workerParser := hclparse.NewParser()
+			for i := range jobsC {
+				parsed[i], parsedDiags[i] = d.cache.ParseHCL(workerParser, files[paths[i]], paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		jobsC <- i
+	}
+	close(jobsC)
+	wg.Wait()
+
+	for i, path := range paths {
+		if parsed[i] != nil {
+			parser.AddFile(path, parsed[i])
+		}
+		diags = diags.Extend(parsedDiags[i])
 	}
 
 	return diags
@@ -159,7 +281,8 @@ func (d *Decoder) parseRawFiles() hcl.Diagnostics {
 // ParserFiles returns combined parser files. These files can be used to add
 // context to diagnostics.
 func (d *Decoder) ParserFiles() map[string]*hcl.File {
-	files := d.FPParser.Files()
+	// Copy the flight plan files as the parser returns its own map.
+	files := maps.Clone(d.FPParser.Files())
 	for name, file := range d.VarsParser.Files() {
 		files[name] = file
 	}
@@ -295,11 +418,19 @@ func (d *Decoder) scenarioDecoder(
 ) (*ScenarioDecoder, hcl.Diagnostics) {
 	diags := hcl.Diagnostics{}
 
+	// Scenarios can refer to the outputs of other scenarios
+	blocks := fp.BodyContent.Blocks.OfType(blockTypeScenario)
+	evalCtx = evalCtx.NewChild()
+	evalCtx.Variables = map[string]cty.Value{blockTypeScenario: scenarioProducersVal(blocks)}
+
 	scenarioDecoder, err := NewScenarioDecoder(
 		WithScenarioDecoderEvalContext(evalCtx),
 		WithScenarioDecoderDecodeTarget(target),
 		WithScenarioDecoderScenarioFilter(filter),
-		WithScenarioDecoderBlocks(fp.BodyContent.Blocks.OfType(blockTypeScenario)),
+		WithScenarioDecoderBlocks(blocks),
+		WithScenarioDecoderTimer(d.timer),
+		WithScenarioDecoderResourceLimits(d.limits),
+		WithScenarioDecoderSkips(d.skips),
 	)
 	if err != nil {
 		return nil, diags.Append(&hcl.Diagnostic{
@@ -319,8 +450,16 @@ func (d *Decoder) scenarioDecoder(
 //nolint:cyclop // it's a complex func
 func (d *Decoder) Decode(ctx context.Context) (*FlightPlan, *ScenarioDecoder, hcl.Diagnostics) {
 	diags := hcl.Diagnostics{}
-
-	fp, err := NewFlightPlan(WithFlightPlanBaseDirectory(d.dir))
+	log := d.log.With("target", d.target.String())
+	log.Debug("decoding flight plan", "dir", d.dir)
+	defer func(start time.Time) {
+		log.Debug("decoded flight plan", "duration", time.Since(start).String())
+	}(time.Now())
+
+	fp, err := NewFlightPlan(
+		WithFlightPlanBaseDirectory(d.dir),
+		WithFlightPlanStrictSchema(d.strictSchema),
+	)
 	if err != nil {
 		return fp, nil, diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -346,6 +485,7 @@ func (d *Decoder) Decode(ctx context.Context) (*FlightPlan, *ScenarioDecoder, hc
 
 	fpFiles := d.FPParser.Files()
 	varsFiles := d.VarsParser.Files()
+	fp.Files = d.ParserFiles()
 
 	// Create a "unified" body of all flightplan files to use for decoding
 	files := []*hcl.File{}
@@ -362,6 +502,13 @@ func (d *Decoder) Decode(ctx context.Context) (*FlightPlan, *ScenarioDecoder, hc
 		return fp, nil, diags
 	}
 
+	// Make sure that we're a version of enos that supports the flight plan before we decode
+	// anything else.
+	diags = diags.Extend(fp.decodeEnosSettings(version.Version))
+	if diags.HasErrors() {
+		return fp, nil, diags
+	}
+
 	// Decode to our desired target level. Start with the lowest level and continue until we've
 	// reached our desired target. Each target level includes more blocks. Where appropriate, each
 	// decoder is responsible for extending the eval context and/or falling through to the next
@@ -388,7 +535,9 @@ func (d *Decoder) Decode(ctx context.Context) (*FlightPlan, *ScenarioDecoder, hc
 
 		if d.target >= DecodeTargetVariables {
 			// Decode and validate our variables and add them to the eval context.
-			diags = diags.Extend(fp.decodeVariables(evalCtx, varsFiles, d.varEnvVars))
+			start := time.Now()
+			diags = diags.Extend(fp.decodeVariables(evalCtx, varsFiles, d.varEnvVars, log))
+			d.timer.Record("variables", "", start)
 			if diags != nil && diags.HasErrors() {
 				return diags
 			}
@@ -396,7 +545,18 @@ func (d *Decoder) Decode(ctx context.Context) (*FlightPlan, *ScenarioDecoder, hc
 
 		if d.target >= DecodeTargetGlobals {
 			// Decode our globals and add them to the eval context.
+			start := time.Now()
 			diags = diags.Extend(fp.decodeGlobals(evalCtx))
+			d.timer.Record("globals", "", start)
+			if diags != nil && diags.HasErrors() {
+				return diags
+			}
+
+			// Shared matrix excludes can refer to variables and globals and are needed by the
+			// matrices of samples and scenarios.
+			start = time.Now()
+			diags = diags.Extend(fp.decodeMatrixExcludes(evalCtx))
+			d.timer.Record("matrix_excludes", "", start)
 			if diags != nil && diags.HasErrors() {
 				return diags
 			}
@@ -404,7 +564,9 @@ func (d *Decoder) Decode(ctx context.Context) (*FlightPlan, *ScenarioDecoder, hc
 
 		if d.target >= DecodeTargetSamples {
 			// Decode to only our samples but does not verify correctness or an intersection with scenarios.
+			start := time.Now()
 			diags = diags.Extend(fp.decodeSamples(evalCtx))
+			d.timer.Record("sample", "", start)
 			if diags != nil && diags.HasErrors() {
 				return diags
 			}
@@ -412,7 +574,9 @@ func (d *Decoder) Decode(ctx context.Context) (*FlightPlan, *ScenarioDecoder, hc
 
 		if d.target >= DecodeTargetQualities {
 			// Decode out qualities and add them to the eval context.
+			start := time.Now()
 			diags = diags.Extend(fp.decodeQualities(evalCtx))
+			d.timer.Record("quality", "", start)
 			if diags != nil && diags.HasErrors() {
 				return diags
 			}
@@ -433,32 +597,11 @@ func (d *Decoder) Decode(ctx context.Context) (*FlightPlan, *ScenarioDecoder, hc
 		default:
 		}
 
-		if d.target >= DecodeTargetTerraformSettings {
-			diags = diags.Extend(fp.decodeTerraformSettings(evalCtx))
-			if diags != nil && diags.HasErrors() {
-				return diags
-			}
-		}
-
-		if d.target >= DecodeTargetTerraformCLIs {
-			diags = diags.Extend(fp.decodeTerraformCLIs(evalCtx))
-			if diags != nil && diags.HasErrors() {
-				return diags
-			}
-		}
-
-		if d.target >= DecodeTargetProviders {
-			diags = diags.Extend(fp.decodeProviders(evalCtx))
-			if diags != nil && diags.HasErrors() {
-				return diags
-			}
-		}
-
-		if d.target >= DecodeTargetModules {
-			diags = diags.Extend(fp.decodeModules(evalCtx))
-			if diags != nil && diags.HasErrors() {
-				return diags
-			}
+		// Our remaining top-level blocks can be decoded concurrently as long as we wait for any
+		// blocks they depend on.
+		diags = diags.Extend(fp.scheduleDecodeStages(evalCtx, d.target, d.timer))
+		if diags != nil && diags.HasErrors() {
+			return diags
 		}
 
 		return diags
@@ -487,12 +630,14 @@ func (d *Decoder) Decode(ctx context.Context) (*FlightPlan, *ScenarioDecoder, hc
 
 // DecodeProto takes a wire request of a FlightPlan and returns a new flight plan and a wire encodable
 // decode response. It's up to the caller to utilize the ScenarioDecoder to decode individual
-// scenarios.
+// scenarios. Any additional decoder options will be applied after those derived from the
+// wire request.
 func DecodeProto(
 	ctx context.Context,
 	pfp *pb.FlightPlan,
 	target DecodeTarget,
 	f *pb.Scenario_Filter,
+	decOpts ...DecoderOpt,
 ) (*FlightPlan, *ScenarioDecoder, *pb.DecodeResponse) {
 	res := &pb.DecodeResponse{
 		Diagnostics: []*pb.Diagnostic{},
@@ -504,6 +649,7 @@ func DecodeProto(
 		WithDecoderVarFiles(pfp.GetEnosVarsHcl()),
 		WithDecoderEnv(pfp.GetEnosVarsEnv()),
 		WithDecoderDecodeTarget(target),
+		WithDecoderStrictSchema(pfp.GetStrictSchema()),
 	}
 
 	sf, err := NewScenarioFilter(WithScenarioFilterDecode(f))
@@ -513,7 +659,13 @@ func DecodeProto(
 		opts = append(opts, WithDecoderScenarioFilter(sf))
 	}
 
-	dec, err := NewDecoder(opts...)
+	skips, skipFiles, skipDiags := DecodeSkips(pfp.GetEnosSkipHcl())
+	if len(skipDiags) > 0 {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromHCL(skipFiles, skipDiags)...)
+	}
+	opts = append(opts, WithDecoderSkips(skips))
+
+	dec, err := NewDecoder(append(opts, decOpts...)...)
 	if err != nil {
 		res.Diagnostics = diagnostics.FromErr(err)
 
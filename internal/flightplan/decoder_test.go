@@ -148,21 +148,10 @@ func testDiagsToError(files map[string]*hcl.File, diags hcl.Diagnostics) error {
 	return errors.New(msg.String())
 }
 
-func testDecodeHCL(t *testing.T, hcl []byte, dt DecodeTarget, env ...string) (*FlightPlan, error) {
+func testDecodeHCL(t *testing.T, hcl []byte, dt DecodeTarget, opts ...DecoderOpt) (*FlightPlan, error) {
 	t.Helper()
 
-	cwd, err := os.Getwd()
-	require.NoError(t, err)
-	decoder, err := NewDecoder(
-		WithDecoderBaseDir(cwd),
-		WithDecoderEnv(env),
-		WithDecoderDecodeTarget(dt),
-	)
-	require.NoError(t, err)
-	_, diags := decoder.FPParser.ParseHCL(hcl, "decoder-test.hcl")
-	if diags != nil {
-		require.False(t, diags.HasErrors(), testDiagsToError(decoder.ParserFiles(), diags))
-	}
+	decoder, diags := testNewDecoder(t, hcl, append([]DecoderOpt{WithDecoderDecodeTarget(dt)}, opts...)...)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	fp, scenarioDecoder, moreDiags := decoder.Decode(ctx)
@@ -174,6 +163,54 @@ func testDecodeHCL(t *testing.T, hcl []byte, dt DecodeTarget, env ...string) (*F
 	return fp, testDiagsToError(decoder.ParserFiles(), diags)
 }
 
+// testDecodeHCLScenarios decodes the HCL and every scenario of it and returns the decoded scenarios
+// and all of the diagnostics, including the warnings of each scenario that DecodeAll doesn't return.
+// Scenarios aren't decoded if the flight plan fails to decode or doesn't have any.
+func testDecodeHCLScenarios(t *testing.T, src []byte, opts ...DecoderOpt) ([]*ScenarioDecodeResponse, hcl.Diagnostics) {
+	t.Helper()
+
+	decoder, diags := testNewDecoder(t, src, opts...)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, scenarioDecoder, moreDiags := decoder.Decode(ctx)
+	diags = diags.Extend(moreDiags)
+	if diags.HasErrors() || scenarioDecoder == nil || len(scenarioDecoder.Blocks) == 0 {
+		return nil, diags
+	}
+
+	scenarios := []*ScenarioDecodeResponse{}
+	iter := scenarioDecoder.Iterator()
+	diags = diags.Extend(iter.Start(ctx))
+	defer iter.Stop()
+	for iter.Next(ctx) {
+		scenarios = append(scenarios, iter.Scenario())
+		diags = diags.Extend(iter.Scenario().Diagnostics)
+	}
+
+	return scenarios, diags.Extend(iter.Diagnostics())
+}
+
+// testNewDecoder returns a new decoder of every target in the working directory of the test that
+// has parsed the HCL, and the diagnostics of parsing it. The options are applied after our
+// defaults.
+func testNewDecoder(t *testing.T, src []byte, opts ...DecoderOpt) (*Decoder, hcl.Diagnostics) {
+	t.Helper()
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	decoder, err := NewDecoder(append([]DecoderOpt{
+		WithDecoderBaseDir(cwd),
+		WithDecoderDecodeTarget(DecodeTargetAll),
+	}, opts...)...)
+	require.NoError(t, err)
+	_, diags := decoder.FPParser.ParseHCL(src, "decoder-test.hcl")
+	if diags != nil {
+		require.False(t, diags.HasErrors(), testDiagsToError(decoder.ParserFiles(), diags))
+	}
+
+	return decoder, diags
+}
+
 type testCreateWireWorkspaceOpt func(*pb.Workspace)
 
 func withTestCreateWireWorkspaceBody(body string) testCreateWireWorkspaceOpt {
@@ -299,6 +336,7 @@ func testRequireEqualFP(t *testing.T, fp, expected *FlightPlan) {
 					require.Equal(t, expected.ScenarioBlocks[i].Scenarios[j].Outputs[oi].Name, gotBlock.Scenarios[j].Outputs[oi].Name)
 					require.Equal(t, expected.ScenarioBlocks[i].Scenarios[j].Outputs[oi].Description, gotBlock.Scenarios[j].Outputs[oi].Description)
 					require.Equal(t, expected.ScenarioBlocks[i].Scenarios[j].Outputs[oi].Sensitive, gotBlock.Scenarios[j].Outputs[oi].Sensitive)
+					require.EqualValues(t, expected.ScenarioBlocks[i].Scenarios[j].Outputs[oi].Verifies, gotBlock.Scenarios[j].Outputs[oi].Verifies)
 					eVal := expected.ScenarioBlocks[i].Scenarios[j].Outputs[oi].Value
 					aVal := gotBlock.Scenarios[j].Outputs[oi].Value
 
@@ -387,3 +425,97 @@ func testMostlyEqualStepVar(t *testing.T, expected cty.Value, got cty.Value) {
 		require.EqualValues(t, eAttr.Name, aAttr.Name)
 	}
 }
+
+// Test_Decoder_parseFiles tests that concurrently parsed files are all added to the parser and
+// that diagnostics are returned in a stable order.
+func Test_Decoder_parseFiles(t *testing.T) {
+	t.Parallel()
+
+	files := RawFiles{}
+	for i := range 20 {
+		files[fmt.Sprintf("enos-%02d.hcl", i)] = []byte(fmt.Sprintf("globals {\n  foo%d = \"bar\"\n}\n", i))
+	}
+	files["enos-bad-a.hcl"] = []byte("globals {")
+	files["enos-bad-b.hcl"] = []byte("globals {")
+
+	decoder, err := NewDecoder(WithDecoderFPFiles(files))
+	require.NoError(t, err)
+
+	diags := decoder.Parse()
+	require.True(t, diags.HasErrors())
+	require.Len(t, decoder.FPParser.Files(), len(files))
+	require.Len(t, diags, 2)
+	require.Equal(t, "enos-bad-a.hcl", diags[0].Subject.Filename)
+	require.Equal(t, "enos-bad-b.hcl", diags[1].Subject.Filename)
+}
+
+// Test_Decode_FlightPlan_stage_dependencies tests that top-level blocks that are decoded
+// concurrently are able to refer to the blocks they depend on.
+func Test_Decode_FlightPlan_stage_dependencies(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+terraform_cli "custom" {
+  path = "/usr/local/bin/terraform"
+}
+
+provider "aws" "east" {
+  region = "us-east-1"
+}
+
+module "backend" {
+  source = "%s"
+  region = provider.aws.east.config.attrs.region
+  cli    = terraform_cli.custom.path
+}
+
+scenario "basic" {
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+	require.Len(t, fp.Modules, 1)
+	require.Equal(t, "us-east-1", fp.Modules[0].Attrs["region"].AsString())
+	require.Equal(t, "/usr/local/bin/terraform", fp.Modules[0].Attrs["cli"].AsString())
+
+	// Referring to a stage that is decoded after ours should fail as it always has.
+	_, err = testDecodeHCL(t, []byte(fmt.Sprintf(`
+provider "aws" "east" {
+  region = module.backend.source
+}
+
+module "backend" {
+  source = "%s"
+}
+
+scenario "basic" {
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unknown variable")
+}
+
+// Test_ParseDecodeTarget tests that every decode target can be parsed from its name.
+func Test_ParseDecodeTarget(t *testing.T) {
+	t.Parallel()
+
+	names := DecodeTargetNames()
+	require.Len(t, names, DecodeTargetAll)
+	for _, name := range names {
+		target, err := ParseDecodeTarget(name)
+		require.NoError(t, err)
+		require.Equal(t, name, target.String())
+	}
+
+	_, err := ParseDecodeTarget("nope")
+	require.Error(t, err)
+	require.Equal(t, "DecodeTarget(0)", DecodeTarget(DecodeTargetUnset).String())
+}
@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+var enosSettingSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "required_version", Required: false},
+	},
+}
+
+// EnosSetting is the "enos" block. It configures requirements of enos itself for the flight plan.
+type EnosSetting struct {
+	RequiredVersion      string
+	requiredVersionRange hcl.Range
+}
+
+// NewEnosSetting returns a new EnosSetting.
+func NewEnosSetting() *EnosSetting {
+	return &EnosSetting{}
+}
+
+// decode takes in an HCL block of an enos setting and decodes from the block onto itself. As the
+// enos settings are decoded before anything else the values must not refer to anything.
+func (e *EnosSetting) decode(block *hcl.Block) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := block.Body.Content(enosSettingSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	attr, ok := content.Attributes["required_version"]
+	if !ok {
+		return diags
+	}
+
+	val, moreDiags := attr.Expr.Value(nil)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	if val.IsNull() || !val.IsWhollyKnown() || !val.Type().Equals(cty.String) {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid value",
+			Detail:   "required_version must be a known string value",
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+	}
+
+	e.RequiredVersion = val.AsString()
+	e.requiredVersionRange = attr.Expr.Range()
+
+	return diags
+}
+
+// verifyRequiredVersion verifies that the version of enos satisfies the required version
+// constraint, if one has been set.
+func (e *EnosSetting) verifyRequiredVersion(block *hcl.Block, enosVersion string) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if e.RequiredVersion == "" {
+		return diags
+	}
+
+	constraints, err := newVersionConstraint(e.RequiredVersion)
+	if err != nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid version constraint",
+			Detail:   fmt.Sprintf("unable to parse required_version constraint %q: %s", e.RequiredVersion, err),
+			Subject:  e.requiredVersionRange.Ptr(),
+			Context:  block.DefRange.Ptr(),
+		})
+	}
+
+	v, err := semver.NewVersion(enosVersion)
+	if err != nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid enos version",
+			Detail:   fmt.Sprintf("unable to parse enos version %q: %s", enosVersion, err),
+			Subject:  e.requiredVersionRange.Ptr(),
+			Context:  block.DefRange.Ptr(),
+		})
+	}
+
+	if !constraints.Check(v) {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "unsupported enos version",
+			Detail: fmt.Sprintf(
+				"this flight plan requires enos %s but the running version is %s, install a compatible version of enos",
+				e.RequiredVersion, enosVersion,
+			),
+			Subject: e.requiredVersionRange.Ptr(),
+			Context: block.DefRange.Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// decodeEnosSettings decodes the "enos" blocks that are defined in the top-level schema and
+// verifies that the running version of enos satisfies their requirements.
+func (fp *FlightPlan) decodeEnosSettings(enosVersion string) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for i, block := range fp.BodyContent.Blocks.OfType(blockTypeEnos) {
+		if i != 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "redefined block",
+				Detail:   "only one enos block is allowed to be defined",
+				Subject:  block.TypeRange.Ptr(),
+				Context:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+
+		setting := NewEnosSetting()
+		moreDiags := setting.decode(block)
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		moreDiags = setting.verifyRequiredVersion(block, enosVersion)
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		fp.EnosSetting = setting
+	}
+
+	return diags
+}
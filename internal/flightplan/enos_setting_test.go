@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Decode_EnosSetting tests decoding the enos block and verifying the required version.
+func Test_Decode_EnosSetting(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		hcl      string
+		expected *EnosSetting
+		fail     bool
+	}{
+		"no block": {
+			hcl: `
+variable "version" {
+  default = "0.0.1"
+}
+`,
+		},
+		"empty block": {
+			hcl: `
+enos {}
+`,
+			expected: &EnosSetting{},
+		},
+		"satisfied": {
+			hcl: `
+enos {
+  required_version = ">= 0.0.1"
+}
+`,
+			expected: &EnosSetting{RequiredVersion: ">= 0.0.1"},
+		},
+		"unsatisfied": {
+			hcl: `
+enos {
+  required_version = ">= 1000.0.0"
+}
+`,
+			fail: true,
+		},
+		"invalid constraint": {
+			hcl: `
+enos {
+  required_version = "not a constraint"
+}
+`,
+			fail: true,
+		},
+		"invalid type": {
+			hcl: `
+enos {
+  required_version = ["0.0.1"]
+}
+`,
+			fail: true,
+		},
+		"unknown attribute": {
+			hcl: `
+enos {
+  required_versions = ">= 0.0.1"
+}
+`,
+			fail: true,
+		},
+		"redefined": {
+			hcl: `
+enos {}
+
+enos {}
+`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			fp, err := testDecodeHCL(t, []byte(test.hcl), DecodeTargetVariables)
+			if test.fail {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			if test.expected == nil {
+				require.Nil(t, fp.EnosSetting)
+
+				return
+			}
+			require.Equal(t, test.expected.RequiredVersion, fp.EnosSetting.RequiredVersion)
+		})
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Export returns the entire decoded flight plan model. The flight plan must have been decoded
+// with DecodeTargetAll. Modules, providers, samples and qualities are sorted by name, scenarios by
+// their names and variants, and steps are in the order they're defined.
+func (fp *FlightPlan) Export() (*pb.FlightPlanExport, error) {
+	out := &pb.FlightPlanExport{}
+	if fp == nil {
+		return out, nil
+	}
+
+	for _, m := range fp.Modules {
+		mod := &pb.FlightPlanExport_Module{
+			Name:    m.Name,
+			Source:  m.Source,
+			Version: m.Version,
+		}
+		vars, err := exportValues(m.Attrs)
+		if err != nil {
+			return nil, fmt.Errorf("exporting module %s: %w", m.Name, err)
+		}
+		mod.Variables = vars
+		out.Modules = append(out.GetModules(), mod)
+	}
+	slices.SortStableFunc(out.GetModules(), func(a, b *pb.FlightPlanExport_Module) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	for _, p := range fp.Providers {
+		provider, err := exportProvider(p)
+		if err != nil {
+			return nil, err
+		}
+		out.Providers = append(out.GetProviders(), provider)
+	}
+	slices.SortStableFunc(out.GetProviders(), func(a, b *pb.FlightPlanExport_Provider) int {
+		return strings.Compare(a.GetType()+"."+a.GetAlias(), b.GetType()+"."+b.GetAlias())
+	})
+
+	for _, s := range fp.Scenarios() {
+		scenario, err := s.export()
+		if err != nil {
+			return nil, fmt.Errorf("exporting scenario %s: %w", s.String(), err)
+		}
+		out.Scenarios = append(out.GetScenarios(), scenario)
+	}
+	slices.SortStableFunc(out.GetScenarios(), func(a, b *pb.FlightPlanExport_Scenario) int {
+		return strings.Compare(a.GetScenario().GetId().GetFilter(), b.GetScenario().GetId().GetFilter())
+	})
+
+	for _, s := range fp.Samples {
+		sample, err := exportSample(s)
+		if err != nil {
+			return nil, fmt.Errorf("exporting sample %s: %w", s.Name, err)
+		}
+		out.Samples = append(out.GetSamples(), sample)
+	}
+	slices.SortStableFunc(out.GetSamples(), func(a, b *pb.FlightPlanExport_Sample) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	for _, q := range fp.Qualities {
+		out.Qualities = append(out.GetQualities(), q.ToProto())
+	}
+	slices.SortStableFunc(out.GetQualities(), CompareQualityProto)
+
+	return out, nil
+}
+
+// export returns the scenario as it is exported with the flight plan.
+func (s *Scenario) export() (*pb.FlightPlanExport_Scenario, error) {
+	out := &pb.FlightPlanExport_Scenario{
+		Scenario: s.RefWithMetadata(),
+		Fixtures: slices.Clone(s.Fixtures),
+	}
+
+	if s.TerraformCLI != nil {
+		out.TerraformCli = s.TerraformCLI.Name
+	}
+
+	if s.TerraformSetting != nil {
+		out.TerraformSetting = s.TerraformSetting.Name
+	}
+
+	for _, p := range s.Providers {
+		out.Providers = append(out.GetProviders(), providerName(p))
+	}
+
+	for _, step := range s.Steps {
+		exported, err := step.export()
+		if err != nil {
+			return nil, fmt.Errorf("exporting step %s: %w", step.Name, err)
+		}
+		out.Steps = append(out.GetSteps(), exported)
+	}
+
+	for _, o := range s.Outputs {
+		output := &pb.FlightPlanExport_Output{
+			Name:        o.Name,
+			Description: o.Description,
+			Sensitive:   o.Sensitive,
+		}
+		for _, q := range o.Verifies {
+			output.Verifies = append(output.GetVerifies(), q.ToProto())
+		}
+		if o.Value != cty.NilVal {
+			val, err := exportValue(o.Value)
+			if err != nil {
+				return nil, fmt.Errorf("exporting output %s: %w", o.Name, err)
+			}
+			output.Value = val
+		}
+		out.Outputs = append(out.GetOutputs(), output)
+	}
+
+	return out, nil
+}
+
+// export returns the step as it is exported with its scenario.
+func (ss *ScenarioStep) export() (*pb.FlightPlanExport_Step, error) {
+	out := &pb.FlightPlanExport_Step{
+		Name:        ss.Name,
+		Description: ss.Description,
+		DependsOn:   slices.Clone(ss.DependsOn),
+		Skip:        ss.Skip,
+	}
+
+	for _, q := range ss.Verifies {
+		out.Verifies = append(out.GetVerifies(), q.ToProto())
+	}
+
+	if len(ss.Providers) > 0 {
+		out.Providers = map[string]string{}
+		for name, p := range ss.Providers {
+			out.Providers[name] = providerName(p)
+		}
+	}
+
+	var attrs map[string]cty.Value
+	switch {
+	case ss.Provisioner != nil:
+		out.Provisioner = ss.Provisioner.Type
+		attrs = ss.Provisioner.Attrs
+	case ss.Module != nil:
+		out.Module = ss.Module.Name
+		attrs = ss.Module.Attrs
+	}
+
+	vars, err := exportValues(attrs)
+	if err != nil {
+		return nil, err
+	}
+	out.Variables = vars
+
+	return out, nil
+}
+
+// providerName returns the type and alias of the provider as type.alias.
+func providerName(p *Provider) string {
+	return p.Type + "." + p.Alias
+}
+
+// exportProvider returns the provider as it is exported with the flight plan.
+func exportProvider(p *Provider) (*pb.FlightPlanExport_Provider, error) {
+	out := &pb.FlightPlanExport_Provider{
+		Type:  p.Type,
+		Alias: p.Alias,
+	}
+
+	if p.Config == nil {
+		return out, nil
+	}
+
+	config, err := exportSchemalessBlock(p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("exporting provider %s: %w", providerName(p), err)
+	}
+	out.Config = config
+
+	return out, nil
+}
+
+// exportSchemalessBlock returns the attributes and nested blocks of the block as a struct.
+// Nested blocks are lists of structs keyed by their type.
+func exportSchemalessBlock(block *SchemalessBlock) (*structpb.Struct, error) {
+	out := &structpb.Struct{Fields: map[string]*structpb.Value{}}
+	for k, v := range block.Attrs {
+		val, err := exportKnownValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("exporting attribute %s: %w", k, err)
+		}
+		out.Fields[k] = val
+	}
+
+	for _, child := range block.Children {
+		val, err := exportSchemalessBlock(child)
+		if err != nil {
+			return nil, fmt.Errorf("exporting block %s: %w", child.Type, err)
+		}
+
+		list, ok := out.GetFields()[child.Type]
+		if !ok || list.GetListValue() == nil {
+			list = structpb.NewListValue(&structpb.ListValue{})
+			out.Fields[child.Type] = list
+		}
+		list.GetListValue().Values = append(list.GetListValue().GetValues(), structpb.NewStructValue(val))
+	}
+
+	return out, nil
+}
+
+// exportSample returns the sample as it is exported with the flight plan.
+func exportSample(s *Sample) (*pb.FlightPlanExport_Sample, error) {
+	out := &pb.FlightPlanExport_Sample{Name: s.Name}
+
+	if s.Attributes != cty.NilVal {
+		attrs, err := exportKnownValue(s.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		out.Attributes = attrs
+	}
+
+	for _, sub := range s.Subsets {
+		subset := &pb.FlightPlanExport_Sample_Subset{
+			Name:           sub.Name,
+			ScenarioName:   sub.ScenarioName,
+			ScenarioFilter: sub.ScenarioFilter,
+		}
+		if sub.Attributes != cty.NilVal {
+			attrs, err := exportKnownValue(sub.Attributes)
+			if err != nil {
+				return nil, fmt.Errorf("exporting subset %s: %w", sub.Name, err)
+			}
+			subset.Attributes = attrs
+		}
+		if sub.Matrix != nil {
+			subset.Matrix = sub.Matrix.Proto()
+		}
+		out.Subsets = append(out.GetSubsets(), subset)
+	}
+
+	return out, nil
+}
+
+// exportValues returns the values as they are exported with the flight plan.
+func exportValues(attrs map[string]cty.Value) (map[string]*pb.FlightPlanExport_Value, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+
+	out := map[string]*pb.FlightPlanExport_Value{}
+	for k, v := range attrs {
+		val, err := exportValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("exporting variable %s: %w", k, err)
+		}
+		out[k] = val
+	}
+
+	return out, nil
+}
+
+// exportValue returns the value as it is exported with the flight plan. Step variables are
+// exported as their absolute values if they have them, otherwise as their references.
+func exportValue(val cty.Value) (*pb.FlightPlanExport_Value, error) {
+	if val.Type().Equals(StepVariableType) {
+		stepVar, diags := StepVariableFromVal(val)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		if stepVar.Value == cty.NilVal && stepVar.Expression != "" {
+			return &pb.FlightPlanExport_Value{
+				Value: &pb.FlightPlanExport_Value_Reference{Reference: stepVar.Expression},
+			}, nil
+		}
+
+		if stepVar.Value == cty.NilVal && stepVar.Traversal != nil {
+			return &pb.FlightPlanExport_Value{
+				Value: &pb.FlightPlanExport_Value_Reference{
+					Reference: strings.TrimSpace(string(hclwrite.TokensForTraversal(stepVar.Traversal).Bytes())),
+				},
+			}, nil
+		}
+		val = stepVar.Value
+	}
+
+	known, err := exportKnownValue(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.FlightPlanExport_Value{
+		Value: &pb.FlightPlanExport_Value_Known{Known: known},
+	}, nil
+}
+
+// exportKnownValue returns the value as JSON. Null and unknown values are null.
+func exportKnownValue(val cty.Value) (*structpb.Value, error) {
+	val, _ = val.UnmarkDeep()
+	if val == cty.NilVal || val.IsNull() || !val.IsWhollyKnown() {
+		return structpb.NewNullValue(), nil
+	}
+
+	b, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	out := &structpb.Value{}
+	if err := protojson.Unmarshal(b, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// UnmarshalExport takes the JSON of a flight plan export, either as it is written by
+// 'enos flightplan export --format json' or the export alone, and returns the export.
+func UnmarshalExport(b []byte) (*pb.FlightPlanExport, error) {
+	opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+
+	res := &pb.ExportFlightPlanResponse{}
+	if err := opts.Unmarshal(b, res); err == nil && res.GetExport() != nil {
+		return res.GetExport(), nil
+	}
+
+	export := &pb.FlightPlanExport{}
+	if err := opts.Unmarshal(b, export); err != nil {
+		return nil, fmt.Errorf("unmarshaling flight plan export: %w", err)
+	}
+
+	return export, nil
+}
@@ -0,0 +1,247 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// exportLeafMessages are the messages of an export that are compared and shown as a whole.
+var exportLeafMessages = []protoreflect.FullName{
+	"google.protobuf.Struct",
+	"google.protobuf.Value",
+	"hashicorp.enos.v1.FlightPlanExport.Value",
+	"hashicorp.enos.v1.Matrix",
+}
+
+// exportIgnoredMessages are the messages of an export that aren't semantic and are never compared.
+var exportIgnoredMessages = []protoreflect.FullName{
+	"hashicorp.enos.v1.Range",
+}
+
+// DiffExports takes two exports of a flight plan and returns the semantic differences between
+// them. Modules, providers, scenarios, steps, outputs, samples and qualities are matched by their
+// names rather than their position, and the locations of blocks are ignored, so that only changes
+// to the decoded model are differences.
+func DiffExports(from, to *pb.FlightPlanExport) []*pb.FlightPlanExport_Difference {
+	d := &exportDiffer{}
+	d.message("", from.ProtoReflect(), to.ProtoReflect())
+
+	return d.diffs
+}
+
+type exportDiffer struct {
+	diffs []*pb.FlightPlanExport_Difference
+}
+
+func (d *exportDiffer) add(path string, change pb.DiffOperationsResponse_Change, from, to string) {
+	d.diffs = append(d.diffs, &pb.FlightPlanExport_Difference{
+		Path:   path,
+		Change: change,
+		From:   from,
+		To:     to,
+	})
+}
+
+// message compares the fields of two messages of the same type.
+func (d *exportDiffer) message(path string, from, to protoreflect.Message) {
+	fields := from.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.Message() != nil && slices.Contains(exportIgnoredMessages, fd.Message().FullName()) {
+			continue
+		}
+
+		fieldPath := fd.JSONName()
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		switch {
+		case fd.IsList():
+			if fd.Message() != nil && isKeyedExportMessage(fd.Message()) {
+				d.keyedList(fieldPath, from.Get(fd).List(), to.Get(fd).List())
+			} else if f, t := renderExportValue(fd, from.Get(fd)), renderExportValue(fd, to.Get(fd)); f != t {
+				d.change(fieldPath, from.Has(fd), to.Has(fd), f, t)
+			}
+		case fd.IsMap():
+			d.mapField(fieldPath, fd, from.Get(fd).Map(), to.Get(fd).Map())
+		case fd.Message() != nil && !slices.Contains(exportLeafMessages, fd.Message().FullName()):
+			switch {
+			case !from.Has(fd) && !to.Has(fd):
+			case !from.Has(fd):
+				d.add(fieldPath, pb.DiffOperationsResponse_CHANGE_ADDED, "", renderExportValue(fd, to.Get(fd)))
+			case !to.Has(fd):
+				d.add(fieldPath, pb.DiffOperationsResponse_CHANGE_REMOVED, renderExportValue(fd, from.Get(fd)), "")
+			default:
+				d.message(fieldPath, from.Get(fd).Message(), to.Get(fd).Message())
+			}
+		default:
+			if f, t := renderExportValue(fd, from.Get(fd)), renderExportValue(fd, to.Get(fd)); f != t {
+				d.change(fieldPath, from.Has(fd), to.Has(fd), f, t)
+			}
+		}
+	}
+}
+
+// change adds the change of a value depending on whether or not it's set in each export.
+func (d *exportDiffer) change(path string, fromSet, toSet bool, from, to string) {
+	switch {
+	case !fromSet:
+		d.add(path, pb.DiffOperationsResponse_CHANGE_ADDED, "", to)
+	case !toSet:
+		d.add(path, pb.DiffOperationsResponse_CHANGE_REMOVED, from, "")
+	default:
+		d.add(path, pb.DiffOperationsResponse_CHANGE_CHANGED, from, to)
+	}
+}
+
+// keyedList compares two lists of messages by their keys. Entries are in the order of the from
+// list followed by any entries that have been added.
+func (d *exportDiffer) keyedList(path string, from, to protoreflect.List) {
+	fromByKey := map[string]protoreflect.Message{}
+	toByKey := map[string]protoreflect.Message{}
+	keys := []string{}
+	for i := range from.Len() {
+		key := exportMessageKey(from.Get(i).Message())
+		fromByKey[key] = from.Get(i).Message()
+		keys = append(keys, key)
+	}
+	for i := range to.Len() {
+		key := exportMessageKey(to.Get(i).Message())
+		toByKey[key] = to.Get(i).Message()
+		if _, ok := fromByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		keyPath := fmt.Sprintf("%s[%q]", path, key)
+		f, inFrom := fromByKey[key]
+		t, inTo := toByKey[key]
+		switch {
+		case !inFrom:
+			d.add(keyPath, pb.DiffOperationsResponse_CHANGE_ADDED, "", "")
+		case !inTo:
+			d.add(keyPath, pb.DiffOperationsResponse_CHANGE_REMOVED, "", "")
+		default:
+			d.message(keyPath, f, t)
+		}
+	}
+}
+
+// mapField compares two maps by their keys, in the order of the keys.
+func (d *exportDiffer) mapField(path string, fd protoreflect.FieldDescriptor, from, to protoreflect.Map) {
+	keys := []string{}
+	from.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, k.String())
+		return true
+	})
+	to.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		if !from.Has(k) {
+			keys = append(keys, k.String())
+		}
+		return true
+	})
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		mk := protoreflect.ValueOfString(key).MapKey()
+		keyPath := fmt.Sprintf("%s[%q]", path, key)
+		f, t := "", ""
+		if from.Has(mk) {
+			f = renderExportValue(fd.MapValue(), from.Get(mk))
+		}
+		if to.Has(mk) {
+			t = renderExportValue(fd.MapValue(), to.Get(mk))
+		}
+		if from.Has(mk) && to.Has(mk) && f == t {
+			continue
+		}
+		d.change(keyPath, from.Has(mk), to.Has(mk), f, t)
+	}
+}
+
+// isKeyedExportMessage returns whether lists of the message are matched by their keys.
+func isKeyedExportMessage(md protoreflect.MessageDescriptor) bool {
+	switch md.FullName() {
+	case "hashicorp.enos.v1.FlightPlanExport.Provider", "hashicorp.enos.v1.FlightPlanExport.Scenario":
+		return true
+	default:
+		fd := md.Fields().ByName("name")
+
+		return fd != nil && fd.Kind() == protoreflect.StringKind && fd.Cardinality() != protoreflect.Repeated
+	}
+}
+
+// exportMessageKey returns the key that identifies the message in a list.
+func exportMessageKey(m protoreflect.Message) string {
+	switch msg := m.Interface().(type) {
+	case *pb.FlightPlanExport_Provider:
+		return msg.GetType() + "." + msg.GetAlias()
+	case *pb.FlightPlanExport_Scenario:
+		return msg.GetScenario().GetId().GetFilter()
+	default:
+		return m.Get(m.Descriptor().Fields().ByName("name")).String()
+	}
+}
+
+// renderExportValue returns the value of the field as compact JSON. References are returned as
+// they are.
+func renderExportValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	if fd.IsList() {
+		elms := []string{}
+		for i := range v.List().Len() {
+			elms = append(elms, renderExportSingular(fd, v.List().Get(i)))
+		}
+
+		return "[" + strings.Join(elms, ",") + "]"
+	}
+
+	return renderExportSingular(fd, v)
+}
+
+func renderExportSingular(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	if fd.Message() == nil {
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return v.String()
+		}
+
+		return string(b)
+	}
+
+	msg := v.Message().Interface()
+	if val, ok := msg.(*pb.FlightPlanExport_Value); ok {
+		if val.GetReference() != "" {
+			return val.GetReference()
+		}
+		msg = val.GetKnown()
+	}
+	if !msg.ProtoReflect().IsValid() {
+		return "null"
+	}
+
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		return ""
+	}
+
+	// protojson randomizes its whitespace so we compact it to compare values
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, b); err != nil {
+		return string(b)
+	}
+
+	return buf.String()
+}
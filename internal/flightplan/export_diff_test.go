@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_DiffExports tests the semantic differences between two exports of a flight plan.
+func Test_DiffExports(t *testing.T) {
+	t.Parallel()
+
+	export := func(hcl string) *pb.FlightPlanExport {
+		t.Helper()
+
+		fp, err := testDecodeHCL(t, []byte(hcl), DecodeTargetAll)
+		require.NoError(t, err)
+		export, err := fp.Export()
+		require.NoError(t, err)
+
+		return export
+	}
+
+	from := export(`
+module "infra" {
+  source = "./infra"
+}
+
+module "backend" {
+  source = "./backend"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch = ["amd64"]
+  }
+
+  step "infra" {
+    module = module.infra
+  }
+
+  step "backend" {
+    module = module.backend
+
+    variables {
+      size   = 1
+      vpc_id = step.infra.vpc_id
+    }
+  }
+}
+`)
+
+	t.Run("moved blocks", func(t *testing.T) {
+		t.Parallel()
+
+		to := export(`
+module "backend" {
+  source = "./backend"
+}
+
+
+
+module "infra" {
+  source = "./infra"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch = ["amd64"]
+  }
+
+  step "infra" {
+    module = module.infra
+  }
+
+  step "backend" {
+    module = module.backend
+
+    variables {
+      vpc_id = step.infra.vpc_id
+      size   = 1
+    }
+  }
+}
+`)
+		require.Empty(t, DiffExports(from, to))
+	})
+
+	t.Run("semantic changes", func(t *testing.T) {
+		t.Parallel()
+
+		to := export(`
+module "backend" {
+  source  = "./backend"
+  version = "1.0.0"
+}
+
+scenario "upgrade" {
+  tags = ["upgrade"]
+
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  step "backend" {
+    module = module.backend
+
+    variables {
+      size    = 3
+      enabled = true
+    }
+  }
+}
+`)
+
+		diffs := []string{}
+		for _, diff := range DiffExports(from, to) {
+			diffs = append(diffs, diff.GetChange().String()+" "+diff.GetPath()+" "+diff.GetFrom()+" "+diff.GetTo())
+		}
+		require.Equal(t, []string{
+			`CHANGE_ADDED modules["backend"].version  "1.0.0"`,
+			`CHANGE_REMOVED modules["infra"]  `,
+			`CHANGE_ADDED scenarios["upgrade arch:amd64"].scenario.metadata.tags  ["upgrade"]`,
+			`CHANGE_REMOVED scenarios["upgrade arch:amd64"].steps["infra"]  `,
+			`CHANGE_ADDED scenarios["upgrade arch:amd64"].steps["backend"].variables["enabled"]  true`,
+			`CHANGE_CHANGED scenarios["upgrade arch:amd64"].steps["backend"].variables["size"] 1 3`,
+			`CHANGE_REMOVED scenarios["upgrade arch:amd64"].steps["backend"].variables["vpc_id"] step.infra.vpc_id `,
+			`CHANGE_ADDED scenarios["upgrade arch:arm64"]  `,
+		}, diffs)
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := protojson.Marshal(&pb.ExportFlightPlanResponse{Export: from, SchemaVersion: "1"})
+		require.NoError(t, err)
+		res, err := UnmarshalExport(b)
+		require.NoError(t, err)
+		require.Empty(t, DiffExports(from, res))
+
+		b, err = protojson.Marshal(from)
+		require.NoError(t, err)
+		res, err = UnmarshalExport(b)
+		require.NoError(t, err)
+		require.Empty(t, DiffExports(from, res))
+	})
+}
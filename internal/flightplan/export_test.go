@@ -0,0 +1,174 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_FlightPlan_Export tests exporting the decoded flight plan model.
+func Test_FlightPlan_Export(t *testing.T) {
+	t.Parallel()
+
+	fp, err := testDecodeHCL(t, []byte(`
+quality "the_tests_pass" {
+  description = "The tests pass"
+}
+
+provider "aws" "east" {
+  region = "us-east-1"
+
+  default_tags {
+    tags = { owner = "enos" }
+  }
+}
+
+module "infra" {
+  source = "./infra"
+  size   = 3
+}
+
+module "backend" {
+  source  = "hashicorp/backend/aws"
+  version = "1.2.3"
+}
+
+sample "all" {
+  attributes = { region = ["us-east-1"] }
+
+  subset "upgrade" {
+    scenario_name = "upgrade"
+  }
+}
+
+scenario "upgrade" {
+  description = "Upgrade the backend"
+  tags        = ["upgrade"]
+  providers   = [provider.aws.east]
+
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  step "infra" {
+    module = module.infra
+  }
+
+  step "backend" {
+    description = "Create the backend"
+    module      = module.backend
+    depends_on  = [step.infra]
+    verifies    = [quality.the_tests_pass]
+
+    providers = {
+      aws = provider.aws.east
+    }
+
+    variables {
+      arch   = matrix.arch
+      vpc_id = step.infra.vpc_id
+    }
+  }
+
+  output "addr" {
+    description = "The address"
+    value       = step.backend.addr
+  }
+}
+`), DecodeTargetAll)
+	require.NoError(t, err)
+
+	export, err := fp.Export()
+	require.NoError(t, err)
+
+	known := func(v any) *pb.FlightPlanExport_Value {
+		val, err := structpb.NewValue(v)
+		require.NoError(t, err)
+
+		return &pb.FlightPlanExport_Value{Value: &pb.FlightPlanExport_Value_Known{Known: val}}
+	}
+	reference := func(ref string) *pb.FlightPlanExport_Value {
+		return &pb.FlightPlanExport_Value{Value: &pb.FlightPlanExport_Value_Reference{Reference: ref}}
+	}
+
+	require.Len(t, export.GetModules(), 2)
+	require.True(t, proto.Equal(&pb.FlightPlanExport_Module{
+		Name:    "backend",
+		Source:  "hashicorp/backend/aws",
+		Version: "1.2.3",
+	}, export.GetModules()[0]), export.GetModules()[0].String())
+	require.True(t, proto.Equal(&pb.FlightPlanExport_Module{
+		Name:      "infra",
+		Source:    "./infra",
+		Variables: map[string]*pb.FlightPlanExport_Value{"size": known(3)},
+	}, export.GetModules()[1]), export.GetModules()[1].String())
+
+	config, err := structpb.NewStruct(map[string]any{
+		"region": "us-east-1",
+		"default_tags": []any{
+			map[string]any{"tags": map[string]any{"owner": "enos"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, export.GetProviders(), 1)
+	require.True(t, proto.Equal(&pb.FlightPlanExport_Provider{
+		Type:   "aws",
+		Alias:  "east",
+		Config: config,
+	}, export.GetProviders()[0]), export.GetProviders()[0].String())
+
+	require.Len(t, export.GetScenarios(), 2)
+	for i, arch := range []string{"amd64", "arm64"} {
+		scenario := export.GetScenarios()[i]
+		require.Equal(t, "upgrade arch:"+arch, scenario.GetScenario().GetId().GetFilter())
+		require.Equal(t, []string{"upgrade"}, scenario.GetScenario().GetMetadata().GetTags())
+		require.Equal(t, "default", scenario.GetTerraformCli())
+		require.Equal(t, []string{"aws.east"}, scenario.GetProviders())
+		require.Len(t, scenario.GetSteps(), 2)
+		require.True(t, proto.Equal(&pb.FlightPlanExport_Step{
+			Name:        "backend",
+			Description: "Create the backend",
+			Module:      "backend",
+			Variables: map[string]*pb.FlightPlanExport_Value{
+				"arch":   known(arch),
+				"vpc_id": reference("step.infra.vpc_id"),
+			},
+			Providers: map[string]string{"aws": "aws.east"},
+			DependsOn: []string{"infra"},
+			Verifies:  []*pb.Quality{{Name: "the_tests_pass", Description: "The tests pass"}},
+		}, scenario.GetSteps()[1]), scenario.GetSteps()[1].String())
+		require.True(t, proto.Equal(&pb.FlightPlanExport_Output{
+			Name:        "addr",
+			Description: "The address",
+			Value:       reference("step.backend.addr"),
+		}, scenario.GetOutputs()[0]), scenario.GetOutputs()[0].String())
+	}
+
+	attrs, err := structpb.NewValue(map[string]any{"region": []any{"us-east-1"}})
+	require.NoError(t, err)
+	require.Len(t, export.GetSamples(), 1)
+	require.True(t, proto.Equal(&pb.FlightPlanExport_Sample{
+		Name:       "all",
+		Attributes: attrs,
+		Subsets: []*pb.FlightPlanExport_Sample_Subset{
+			{Name: "upgrade", ScenarioName: "upgrade"},
+		},
+	}, export.GetSamples()[0]), export.GetSamples()[0].String())
+
+	require.True(t, proto.Equal(&pb.Quality{Name: "the_tests_pass", Description: "The tests pass"}, export.GetQualities()[0]))
+
+	// The export can be read back
+	b, err := protojson.Marshal(export)
+	require.NoError(t, err)
+	read := &pb.FlightPlanExport{}
+	require.NoError(t, protojson.Unmarshal(b, read))
+	require.True(t, proto.Equal(export, read))
+}
@@ -16,6 +16,9 @@ import (
 var (
 	FlightPlanFileNamePattern = regexp.MustCompile(`^enos[-\w]*?\.hcl$`)
 	VariablesNamePattern      = regexp.MustCompile(`^enos[-\w]*?\.vars\.hcl$`)
+	LintConfigNamePattern     = regexp.MustCompile(`^enos[-\w]*?\.lint\.hcl$`)
+	// Skip files can be written in HCL or in the JSON syntax of HCL.
+	SkipConfigNamePattern = regexp.MustCompile(`^enos[-\w]*?\.skip\.(hcl|json)$`)
 )
 
 // RawFiles are a map of flightplan configuration files and their contents.
@@ -0,0 +1,283 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// placeholderValue is the value of required attributes that are added by fixes. It is expected to
+// be replaced by the author.
+const placeholderValue = "TODO"
+
+// FixedFile is a flight plan file that fixes have been applied to.
+type FixedFile struct {
+	Path string
+	// Src is the source of the file before it was fixed
+	Src []byte
+	// Fixed is the formatted source of the file after it was fixed
+	Fixed []byte
+	// Diagnostics are the diagnostics whose fixes have been applied
+	Diagnostics []*pb.Diagnostic
+}
+
+// fileEdit is an edit of a file as byte offsets.
+type fileEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// ApplyFixes applies the fixes of the diagnostics to the files and returns the files that have
+// changed, sorted by path. Only the first fix of a diagnostic is applied as any others are
+// alternatives. Decoding scenarios with a matrix reports the same diagnostic for every variant so
+// duplicate fixes are only applied once. Fixes that overlap a fix that has already been applied
+// are skipped, they can be applied by fixing the files again. Fixed files are formatted.
+func ApplyFixes(files map[string][]byte, diags []*pb.Diagnostic) ([]*FixedFile, hcl.Diagnostics) {
+	hclDiags := hcl.Diagnostics{}
+	edits := map[string][]*fileEdit{}
+	fixed := map[string][]*pb.Diagnostic{}
+	seen := map[string]struct{}{}
+
+	overlaps := func(path string, edit *fileEdit) bool {
+		for _, e := range edits[path] {
+			if edit.start < e.end && e.start < edit.end {
+				return true
+			}
+
+			// Two insertions at the same position would have an undefined order
+			if edit.start == e.start && (edit.start == edit.end || e.start == e.end) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+NextDiag:
+	for _, diag := range diags {
+		if len(diag.GetFixes()) < 1 || len(diag.GetFixes()[0].GetEdits()) < 1 {
+			continue
+		}
+		fix := diag.GetFixes()[0]
+
+		key := fixKey(fix)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		fixEdits := map[string][]*fileEdit{}
+		for _, edit := range fix.GetEdits() {
+			path := edit.GetRange().GetFilename()
+			src, ok := files[path]
+			if !ok {
+				hclDiags = hclDiags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "unable to apply fix",
+					Detail:   fmt.Sprintf("unable to %s, the file %s is not part of the flight plan", fix.GetDescription(), path),
+				})
+
+				continue NextDiag
+			}
+
+			fe := &fileEdit{
+				start: int(edit.GetRange().GetStart().GetByte()),
+				end:   int(edit.GetRange().GetEnd().GetByte()),
+				text:  edit.GetText(),
+			}
+			if fe.start < 0 || fe.end < fe.start || fe.end > len(src) {
+				hclDiags = hclDiags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "unable to apply fix",
+					Detail:   fmt.Sprintf("unable to %s, the edit is outside of the file %s", fix.GetDescription(), path),
+				})
+
+				continue NextDiag
+			}
+
+			if overlaps(path, fe) {
+				continue NextDiag
+			}
+			fixEdits[path] = append(fixEdits[path], fe)
+		}
+
+		for path, fe := range fixEdits {
+			edits[path] = append(edits[path], fe...)
+		}
+		path := fix.GetEdits()[0].GetRange().GetFilename()
+		fixed[path] = append(fixed[path], diag)
+	}
+
+	res := []*FixedFile{}
+	for path, fileEdits := range edits {
+		src := files[path]
+
+		// Apply the edits from the end of the file so that the offsets of the remaining edits
+		// remain valid.
+		slices.SortFunc(fileEdits, func(a, b *fileEdit) int {
+			return cmp.Compare(b.start, a.start)
+		})
+		out := slices.Clone(src)
+		for _, edit := range fileEdits {
+			out = slices.Concat(out[:edit.start], []byte(edit.text), out[edit.end:])
+		}
+
+		if _, moreDiags := hclwrite.ParseConfig(out, path, hcl.InitialPos); moreDiags.HasErrors() {
+			hclDiags = hclDiags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "unable to apply fixes",
+				Detail: fmt.Sprintf("applying the fixes to %s would result in invalid configuration: %s",
+					path, moreDiags.Error(),
+				),
+			})
+
+			continue
+		}
+
+		res = append(res, &FixedFile{
+			Path:        path,
+			Src:         src,
+			Fixed:       hclwrite.Format(out),
+			Diagnostics: fixed[path],
+		})
+	}
+
+	slices.SortFunc(res, func(a, b *FixedFile) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	return res, hclDiags
+}
+
+// fixKey returns a key that identifies the changes of the fix.
+func fixKey(fix *pb.Diagnostic_Fix) string {
+	key := strings.Builder{}
+	for _, edit := range fix.GetEdits() {
+		fmt.Fprintf(&key, "%s:%d-%d:%q;",
+			edit.GetRange().GetFilename(),
+			edit.GetRange().GetStart().GetByte(),
+			edit.GetRange().GetEnd().GetByte(),
+			edit.GetText(),
+		)
+	}
+
+	return key.String()
+}
+
+// quoteString returns the string as a quoted HCL string literal.
+func quoteString(s string) string {
+	return string(hclwrite.TokensForValue(cty.StringVal(s)).Bytes())
+}
+
+// withQuoteLiteralFix attaches a fix to the diagnostic that replaces the expression with the value
+// as a quoted string. Only literal expressions are quoted as quoting anything else would change
+// what the expression refers to.
+func withQuoteLiteralFix(diag *hcl.Diagnostic, expr hcl.Expression, val cty.Value) *hcl.Diagnostic {
+	if _, ok := expr.(*hclsyntax.LiteralValueExpr); !ok || !val.IsKnown() || val.IsNull() {
+		return diag
+	}
+
+	return diagnostics.WithFixes(diag, diagnostics.NewFix(
+		"quote the value "+val.AsString(),
+		diagnostics.ReplaceEdit(expr.Range(), quoteString(val.AsString())),
+	))
+}
+
+// withQuoteIdentifierFixes attaches fixes to diagnostics of references to unknown variables that
+// are a single bare identifier, e.g. arch = [amd64], which are almost always strings that have not
+// been quoted.
+func withQuoteIdentifierFixes(diags hcl.Diagnostics) hcl.Diagnostics {
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+
+		expr, ok := diag.Expression.(*hclsyntax.ScopeTraversalExpr)
+		if !ok || len(expr.Traversal) != 1 {
+			continue
+		}
+
+		name := expr.Traversal.RootName()
+		diagnostics.WithFixes(diag, diagnostics.NewFix(
+			"quote the identifier "+name,
+			diagnostics.ReplaceEdit(expr.SrcRange, quoteString(name)),
+		))
+	}
+
+	return diags
+}
+
+// withMissingAttributeFix attaches a fix to the diagnostic that adds the missing required
+// attribute to the block with a placeholder value.
+func withMissingAttributeFix(diag *hcl.Diagnostic, block *hcl.Block, name string) *hcl.Diagnostic {
+	body, ok := block.Body.(*hclsyntax.Body)
+	if !ok {
+		return diag
+	}
+
+	attr := fmt.Sprintf("%s = %s", name, quoteString(placeholderValue))
+	desc := fmt.Sprintf("add the required attribute %s with a placeholder value", name)
+	open := body.SrcRange.Start
+	end := body.SrcRange.End
+
+	// Multi-line bodies get the attribute as their first line. Single line bodies can only have a
+	// single attribute so we only fix them when they are empty.
+	if open.Line != end.Line {
+		return diagnostics.WithFixes(diag, diagnostics.NewFix(desc, diagnostics.InsertEdit(
+			body.SrcRange.Filename,
+			hcl.Pos{Line: open.Line, Column: open.Column + 1, Byte: open.Byte + 1},
+			"\n"+attr,
+		)))
+	}
+
+	if len(body.Attributes) > 0 || len(body.Blocks) > 0 {
+		return diag
+	}
+
+	return diagnostics.WithFixes(diag, diagnostics.NewFix(desc, diagnostics.ReplaceEdit(
+		body.SrcRange,
+		"{\n"+attr+"\n}",
+	)))
+}
+
+// withMissingAttributeFixes attaches fixes to the diagnostics of the block that report that one of
+// the named required attributes is missing. These diagnostics are created when the content of the
+// block is decoded with a schema.
+func withMissingAttributeFixes(diags hcl.Diagnostics, block *hcl.Block, names ...string) hcl.Diagnostics {
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError || diag.Summary != "Missing required argument" {
+			continue
+		}
+
+		for _, name := range names {
+			if strings.Contains(diag.Detail, fmt.Sprintf("%q", name)) {
+				withMissingAttributeFix(diag, block, name)
+
+				break
+			}
+		}
+	}
+
+	return diags
+}
+
+// withRenameFix attaches a fix to the diagnostic that replaces the name at the range.
+func withRenameFix(diag *hcl.Diagnostic, rng hcl.Range, from string, to string) *hcl.Diagnostic {
+	return diagnostics.WithFixes(diag, diagnostics.NewFix(
+		fmt.Sprintf("rename %s to %s", from, to),
+		diagnostics.ReplaceEdit(rng, to),
+	))
+}
@@ -0,0 +1,257 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_ApplyFixes tests that the fixes of diagnostics are applied to the flight plan.
+func Test_ApplyFixes(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		hcl      string
+		expected string
+		fixes    []string
+	}{
+		"quote module values": {
+			hcl: `
+module "foo" {
+  source  = "hashicorp/consul/aws"
+  version = 1.2
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			expected: `
+module "foo" {
+  source  = "hashicorp/consul/aws"
+  version = "1.2"
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			fixes: []string{"quote the value 1.2"},
+		},
+		"quote matrix identifiers": {
+			hcl: `
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  matrix {
+    arch = [amd64, "arm64"]
+    distro = [ubuntu]
+  }
+
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			expected: `
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  matrix {
+    arch   = ["amd64", "arm64"]
+    distro = ["ubuntu"]
+  }
+
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			fixes: []string{"quote the identifier amd64", "quote the identifier ubuntu"},
+		},
+		"missing required module attributes": {
+			hcl: `
+module "foo" {
+}
+
+module "bar" {}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			expected: `
+module "foo" {
+  source = "TODO"
+}
+
+module "bar" {
+  source = "TODO"
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			fixes: []string{
+				"add the required attribute source with a placeholder value",
+				"add the required attribute source with a placeholder value",
+			},
+		},
+		"missing required quality attributes": {
+			hcl: `
+quality "baz" {
+}
+
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			expected: `
+quality "baz" {
+  description = "TODO"
+}
+
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			fixes: []string{"add the required attribute description with a placeholder value"},
+		},
+		"rename backend workspace blocks": {
+			hcl: `
+terraform "default" {
+  backend "s3" {
+    workspace {
+      prefix = "foo"
+    }
+  }
+}
+
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			expected: `
+terraform "default" {
+  backend "s3" {
+    workspaces {
+      prefix = "foo"
+    }
+  }
+}
+
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			fixes: []string{"rename workspace to workspaces"},
+		},
+		"nothing to fix": {
+			hcl: `
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(test.hcl)
+			_, diags := testDecodeHCLScenarios(t, src)
+			fixed, hclDiags := ApplyFixes(map[string][]byte{"decoder-test.hcl": src}, diagnostics.FromHCL(nil, diags))
+			require.False(t, hclDiags.HasErrors(), hclDiags.Error())
+
+			if test.expected == "" {
+				require.Empty(t, fixed)
+
+				return
+			}
+
+			require.Len(t, fixed, 1)
+			require.Equal(t, "decoder-test.hcl", fixed[0].Path)
+			require.Equal(t, test.expected, string(fixed[0].Fixed))
+
+			fixes := []string{}
+			for _, diag := range fixed[0].Diagnostics {
+				fixes = append(fixes, diag.GetFixes()[0].GetDescription())
+			}
+			require.ElementsMatch(t, test.fixes, fixes)
+		})
+	}
+}
+
+// Test_ApplyFixes_Overlapping tests that fixes that overlap a fix that has been applied are
+// skipped.
+func Test_ApplyFixes_Overlapping(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("module foo {\n  source = 1\n}\n")
+	rng := hcl.Range{
+		Filename: "enos.hcl",
+		Start:    hcl.Pos{Line: 2, Column: 12, Byte: 24},
+		End:      hcl.Pos{Line: 2, Column: 13, Byte: 25},
+	}
+	diags := []*pb.Diagnostic{
+		{Fixes: []*pb.Diagnostic_Fix{diagnostics.NewFix("quote 1", diagnostics.ReplaceEdit(rng, `"1"`))}},
+		{Fixes: []*pb.Diagnostic_Fix{diagnostics.NewFix("replace 1", diagnostics.ReplaceEdit(rng, `"2"`))}},
+		{Fixes: []*pb.Diagnostic_Fix{diagnostics.NewFix("quote 1", diagnostics.ReplaceEdit(rng, `"1"`))}},
+	}
+
+	fixed, hclDiags := ApplyFixes(map[string][]byte{"enos.hcl": src}, diags)
+	require.False(t, hclDiags.HasErrors(), hclDiags.Error())
+	require.Len(t, fixed, 1)
+	require.Equal(t, "module foo {\n  source = \"1\"\n}\n", string(fixed[0].Fixed))
+	require.Len(t, fixed[0].Diagnostics, 1)
+	require.Equal(t, "quote 1", fixed[0].Diagnostics[0].GetFixes()[0].GetDescription())
+}
@@ -0,0 +1,248 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// FixtureOutputName is the name of the output of a fixture module that has the outputs of the
+// fixture.
+const FixtureOutputName = "outputs"
+
+var fixtureSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "description"},
+		{Name: "module", Required: true},
+		{Name: "providers"},
+		{Name: "terraform_cli"},
+		{Name: "terraform"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeVariables},
+	},
+}
+
+// Fixture represents a "fixture" block. Fixtures are shared, long-lived infrastructure, e.g. a VPC
+// or a license server, that scenarios consume by referring to their outputs in step variables,
+// e.g. fixture.network.vpc_id. Fixtures are executed as scenarios with a single step that has the
+// name of the fixture.
+type Fixture struct {
+	Name        string
+	Description string
+	Scenario    *Scenario
+}
+
+// NewFixture returns a new Fixture.
+func NewFixture() *Fixture {
+	return &Fixture{
+		Scenario: NewScenario(),
+	}
+}
+
+// decode takes in an HCL block of a fixture and an eval context and decodes from the block onto
+// itself. Any errors that are encountered are returned as hcl diagnostics.
+func (f *Fixture) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := block.Body.Content(fixtureSchema)
+	diags = diags.Extend(withMissingAttributeFixes(moreDiags, block, "module"))
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	f.Name = block.Labels[0]
+	f.Scenario.Name = f.Name
+	f.Scenario.DefRange = block.DefRange
+
+	if desc, ok := content.Attributes["description"]; ok {
+		val, moreDiags := desc.Expr.Value(ctx)
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			return diags
+		}
+
+		if val.IsNull() || !val.IsWhollyKnown() || !val.Type().Equals(cty.String) {
+			return diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "fixture description must be a known string",
+				Subject:  desc.Expr.Range().Ptr(),
+				Context:  desc.Range.Ptr(),
+			})
+		}
+		f.Description = val.AsString()
+		f.Scenario.Description = f.Description
+	}
+
+	// Fixtures are configured like scenarios.
+	for _, decode := range []func(*hcl.BodyContent, *hcl.EvalContext) hcl.Diagnostics{
+		f.Scenario.decodeAndValidateTerraformCLIAttribute,
+		f.Scenario.decodeAndValidateTerraformSettingsAttribute,
+		f.Scenario.decodeAndValidateProvidersAttribute,
+	} {
+		moreDiags = decode(content, ctx)
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			return diags
+		}
+	}
+
+	// The fixture module is decoded like the module of a step.
+	step := NewScenarioStep()
+	step.Name = f.Name
+	step.Description = f.Description
+
+	moduleAttr, moreDiags := step.decodeModuleAttribute(block, content, ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	moduleVal, moreDiags := step.validateModuleAttributeReference(moduleAttr, ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	step.copyModuleAttributes(moduleVal)
+
+	moreDiags = decodeStepVariables(step.Module.Attrs, content.Blocks.OfType(blockTypeVariables), ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	diags = diags.Extend(step.verifyModuleVariables(block, content, ctx))
+
+	// The outputs of the fixture are all of the outputs of its module.
+	f.Scenario.Steps = []*ScenarioStep{step}
+	f.Scenario.Outputs = []*ScenarioOutput{{
+		Name:      FixtureOutputName,
+		Sensitive: true,
+		Value: StepVariableVal(&StepVariable{
+			Value: cty.NilVal,
+			Traversal: hcl.Traversal{
+				hcl.TraverseRoot{Name: "step", SrcRange: block.DefRange},
+				hcl.TraverseAttr{Name: f.Name, SrcRange: block.DefRange},
+			},
+		}),
+	}}
+
+	return diags
+}
+
+// ToCtyValue returns the fixture contents as an object cty.Value.
+func (f *Fixture) ToCtyValue() cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"name":        cty.StringVal(f.Name),
+		"description": cty.StringVal(f.Description),
+	})
+}
+
+// verifyFixtureTraversal verifies that the traversal refers to a fixture that has been defined.
+func verifyFixtureTraversal(traversal hcl.Traversal, expr hcl.Expression, ctx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	fixtures, err := findEvalContextVariable("fixture", ctx)
+	if err != nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "no fixtures have been defined",
+			Subject:  traversal.SourceRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+	}
+
+	if len(traversal) < 2 {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid fixture traversal",
+			Detail:   "fixture references must refer to a fixture, e.g. fixture.name.output",
+			Subject:  traversal.SourceRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+	}
+
+	name, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid fixture traversal",
+			Subject:  traversal.SourceRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+	}
+
+	if _, ok := fixtures.AsValueMap()[name.Name]; !ok {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("no fixture named %s has been defined", name.Name),
+			Subject:  name.SourceRange().Ptr(),
+			Context:  traversal.SourceRange().Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// FixtureName returns the name of the fixture that the traversal refers to, or an empty string if
+// it doesn't refer to a fixture.
+func FixtureName(traversal hcl.Traversal) string {
+	if len(traversal) < 2 || traversal.IsRelative() || traversal.RootName() != blockTypeFixture {
+		return ""
+	}
+
+	name, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return ""
+	}
+
+	return name.Name
+}
+
+// fixtureReferences returns the sorted names of the fixtures that the steps and outputs of the
+// scenario refer to.
+func (s *Scenario) fixtureReferences() []string {
+	names := []string{}
+	add := func(val cty.Value) {
+		stepVar, diags := StepVariableFromVal(val)
+		if diags.HasErrors() {
+			return
+		}
+
+		for _, traversal := range append([]hcl.Traversal{stepVar.Traversal}, stepVar.References...) {
+			if name := FixtureName(traversal); name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	for _, step := range s.Steps {
+		switch {
+		case step.Module != nil:
+			for _, val := range step.Module.Attrs {
+				add(val)
+			}
+		case step.Provisioner != nil:
+			for _, val := range step.Provisioner.Attrs {
+				add(val)
+			}
+		}
+	}
+
+	for _, out := range s.Outputs {
+		if out.Value != cty.NilVal {
+			add(out.Value)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
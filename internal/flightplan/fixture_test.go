@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Test_Decode_Fixture tests decoding fixtures and references to their outputs.
+func Test_Decode_Fixture(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "network" {
+  source = "%[1]s"
+  cidr   = "10.0.0.0/8"
+}
+
+module "backend" {
+  source = "%[1]s"
+}
+
+provider "aws" "east" {
+  region = "us-east-1"
+}
+
+fixture "network" {
+  description = "shared network"
+  module      = module.network
+  providers   = [provider.aws.east]
+
+  variables {
+    cidr = "10.1.0.0/16"
+  }
+}
+
+scenario "basic" {
+  step "backend" {
+    module = module.backend
+
+    variables {
+      vpc_id  = fixture.network.vpc_id
+      subnets = fixture.network.subnets[0]
+    }
+  }
+
+  output "network" {
+    value = fixture.network
+  }
+}
+
+scenario "standalone" {
+  step "backend" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+
+	require.Len(t, fp.Fixtures, 1)
+	fixture := fp.Fixture("network")
+	require.NotNil(t, fixture)
+	require.Nil(t, fp.Fixture("missing"))
+	require.Equal(t, "shared network", fixture.Description)
+	require.Equal(t, "network", fixture.Scenario.Name)
+	require.Len(t, fixture.Scenario.Providers, 1)
+	require.Equal(t, "east", fixture.Scenario.Providers[0].Alias)
+	require.Len(t, fixture.Scenario.Steps, 1)
+	require.Equal(t, "network", fixture.Scenario.Steps[0].Name)
+	testMostlyEqualStepVar(t, testMakeStepVarValue(cty.StringVal("10.1.0.0/16")), fixture.Scenario.Steps[0].Module.Attrs["cidr"])
+	require.Len(t, fixture.Scenario.Outputs, 1)
+	require.Equal(t, FixtureOutputName, fixture.Scenario.Outputs[0].Name)
+	require.True(t, fixture.Scenario.Outputs[0].Sensitive)
+	testMostlyEqualStepVar(t, testMakeStepVarTraversal("step", "network"), fixture.Scenario.Outputs[0].Value)
+
+	scenarios := fp.Scenarios()
+	require.Len(t, scenarios, 2)
+	for _, scenario := range scenarios {
+		switch scenario.Name {
+		case "basic":
+			require.Equal(t, []string{"network"}, scenario.Fixtures)
+			testMostlyEqualStepVar(t,
+				testMakeStepVarTraversal("fixture", "network", "vpc_id"),
+				scenario.Steps[0].Module.Attrs["vpc_id"],
+			)
+			subnets, diags := StepVariableFromVal(scenario.Steps[0].Module.Attrs["subnets"])
+			require.False(t, diags.HasErrors())
+			require.Equal(t, "network", FixtureName(subnets.Traversal))
+			require.Len(t, subnets.Traversal, 4)
+		default:
+			require.Empty(t, scenario.Fixtures)
+		}
+	}
+}
+
+// Test_Decode_Fixture_Invalid tests decoding invalid fixtures and fixture references.
+func Test_Decode_Fixture_Invalid(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	for desc, hcl := range map[string]string{
+		"missing module": `
+fixture "network" {
+}
+`,
+		"undefined module": `
+fixture "network" {
+  module = module.missing
+}
+`,
+		"duplicate": `
+fixture "network" {
+  module = module.backend
+}
+
+fixture "network" {
+  module = module.backend
+}
+`,
+		"undefined fixture": `
+scenario "basic" {
+  step "backend" {
+    module = module.backend
+
+    variables {
+      vpc_id = fixture.missing.vpc_id
+    }
+  }
+}
+`,
+		"step reference": `
+fixture "network" {
+  module = module.backend
+
+  variables {
+    vpc_id = step.backend.vpc_id
+  }
+}
+`,
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+%s`, modulePath, hcl)), DecodeTargetAll)
+			require.Error(t, err)
+		})
+	}
+}
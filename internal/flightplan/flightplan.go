@@ -12,6 +12,7 @@ import (
 
 	"github.com/zclconf/go-cty/cty"
 
+	"github.com/hashicorp/go-hclog"
 	hcl "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
@@ -23,13 +24,18 @@ const (
 
 	blockTypeBackend           = "backend"
 	blockTypeCloud             = "cloud"
+	blockTypeEnos              = "enos"
+	blockTypeFixture           = "fixture"
 	blockTypeMatrixExclude     = "exclude"
 	blockTypeGlobals           = "globals"
 	blockTypeMatrixInclude     = "include"
+	blockTypeMatrixKeys        = "keys"
 	blockTypeLocals            = "locals"
 	blockTypeMatrix            = "matrix"
+	blockTypeMatrixConstraints = "constraints"
 	blockTypeModule            = "module"
 	blockTypeOutput            = "output"
+	blockTypeOverrides         = "overrides"
 	blockTypeProvider          = "provider"
 	blockTypeProviderMeta      = "provider_meta"
 	blockTypeQuality           = "quality"
@@ -47,6 +53,7 @@ const (
 
 var flightPlanSchema = &hcl.BodySchema{
 	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeEnos},
 		{Type: blockTypeGlobals},
 		{Type: blockTypeSample, LabelNames: []string{attrLabelNameDefault}},
 		{Type: blockTypeTerraformSetting, LabelNames: []string{attrLabelNameDefault}},
@@ -55,7 +62,9 @@ var flightPlanSchema = &hcl.BodySchema{
 		{Type: blockTypeQuality, LabelNames: []string{attrLabelNameDefault}},
 		{Type: blockTypeScenario, LabelNames: []string{attrLabelNameDefault}},
 		{Type: blockTypeModule, LabelNames: []string{attrLabelNameDefault}},
+		{Type: blockTypeFixture, LabelNames: []string{attrLabelNameDefault}},
 		{Type: blockTypeVariable, LabelNames: []string{attrLabelNameDefault}},
+		{Type: blockTypeMatrixExcludes, LabelNames: []string{attrLabelNameDefault}},
 	},
 }
 
@@ -69,6 +78,7 @@ func NewFlightPlan(opts ...Opt) (*FlightPlan, error) {
 		Providers:         []*Provider{},
 		ScenarioBlocks:    ScenarioBlocks{},
 		Modules:           []*Module{},
+		Fixtures:          []*Fixture{},
 	}
 
 	for _, opt := range opts {
@@ -99,7 +109,10 @@ type Opt func(*FlightPlan) error
 type FlightPlan struct {
 	BaseDir           string
 	BodyContent       *hcl.BodyContent
+	EnosSetting       *EnosSetting
 	Files             map[string]*hcl.File
+	Fixtures          []*Fixture
+	MatrixExcludes    []*MatrixExcludes
 	Modules           []*Module
 	Providers         []*Provider
 	Qualities         []*Quality
@@ -107,6 +120,11 @@ type FlightPlan struct {
 	TerraformCLIs     []*TerraformCLI
 	Samples           []*Sample
 	ScenarioBlocks    ScenarioBlocks
+	Skipped           []*SkippedScenario
+	StrictSchema      bool
+
+	// sensitiveVars are the names of the variables that are sensitive
+	sensitiveVars map[string]bool
 }
 
 func (fp *FlightPlan) Scenarios() []*Scenario {
@@ -124,6 +142,7 @@ func (fp *FlightPlan) decodeVariables(
 	ctx *hcl.EvalContext,
 	varFiles map[string]*hcl.File,
 	envVars []string,
+	log hclog.Logger,
 ) hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
 	values := map[string]*VariableValue{}
@@ -192,6 +211,9 @@ func (fp *FlightPlan) decodeVariables(
 			continue
 		}
 
+		log.Debug("setting variable value from the environment",
+			"variable", trimmed[:idx], "env", EnvVarPrefix+trimmed[:idx],
+		)
 		values[trimmed[:idx]] = &VariableValue{
 			EnvVarRaw: trimmed[idx+1:],
 			Source:    VariableValueSourceEnvVar,
@@ -214,18 +236,24 @@ func (fp *FlightPlan) decodeVariables(
 		}
 
 		variable := NewVariable()
-		moreDiags = variable.decode(block, values)
+		moreDiags = variable.decode(block, values, fp.BaseDir)
 		diags = diags.Extend(moreDiags)
 		if moreDiags != nil && moreDiags.HasErrors() {
 			continue
 		}
 
 		vars[variable.Name] = variable.Value()
+		if variable.Sensitive {
+			if fp.sensitiveVars == nil {
+				fp.sensitiveVars = map[string]bool{}
+			}
+			fp.sensitiveVars[variable.Name] = true
+		}
 	}
 
-	// NOTE: We only keep track of variable values in the eval context. That is
-	// fine for now but if we ever want to handle things like "sensitive" we'll
-	// have to keep them around in the flight plan.
+	// NOTE: We only keep track of variable values in the eval context. The
+	// names of sensitive variables are kept in the flight plan so that values
+	// which reference them, e.g. terraform_cli env, can be masked.
 	ctx.Variables["var"] = cty.ObjectVal(vars)
 
 	return diags
@@ -321,6 +349,7 @@ func (fp *FlightPlan) decodeTerraformSettings(ctx *hcl.EvalContext) hcl.Diagnost
 
 		setting := NewTerraformSetting()
 		moreDiags = setting.decode(block, ctx.NewChild())
+		moreDiags = moreDiags.Extend(verifyTerraformSettingSchema(block, fp.schemaSeverity()))
 		diags = diags.Extend(moreDiags)
 		if moreDiags != nil && moreDiags.HasErrors() {
 			continue
@@ -335,6 +364,46 @@ func (fp *FlightPlan) decodeTerraformSettings(ctx *hcl.EvalContext) hcl.Diagnost
 	return diags
 }
 
+// decodeMatrixExcludes decodes "matrix_excludes" blocks that are defined in the top-level schema.
+func (fp *FlightPlan) decodeMatrixExcludes(ctx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	excludes := map[string]cty.Value{}
+
+	for _, block := range fp.BodyContent.Blocks.OfType(blockTypeMatrixExcludes) {
+		moreDiags := verifyBlockLabelsAreValidIdentifiers(block)
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		me := NewMatrixExcludes()
+		moreDiags = me.decode(block, ctx.NewChild())
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		_, previouslyDefined := excludes[me.Name]
+		if previouslyDefined {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "matrix_excludes has previously been defined",
+				Detail:   fmt.Sprintf(`matrix_excludes %s has already been defined`, me.Name),
+				Subject:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+
+		excludes[me.Name] = me.ToCtyValue()
+		fp.MatrixExcludes = append(fp.MatrixExcludes, me)
+	}
+
+	ctx.Variables["matrix_excludes"] = cty.ObjectVal(excludes)
+
+	return diags
+}
+
 // decodeQualities decodes "quality" blocks that are defined in the top-level schema.
 func (fp *FlightPlan) decodeQualities(ctx *hcl.EvalContext) hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
@@ -389,7 +458,7 @@ func (fp *FlightPlan) decodeTerraformCLIs(ctx *hcl.EvalContext) hcl.Diagnostics
 		}
 
 		cli := NewTerraformCLI()
-		moreDiags = cli.decode(block, ctx.NewChild())
+		moreDiags = cli.decode(block, ctx.NewChild(), fp.sensitiveVars)
 		diags = diags.Extend(moreDiags)
 		if moreDiags != nil && moreDiags.HasErrors() {
 			continue
@@ -433,6 +502,7 @@ func (fp *FlightPlan) decodeProviders(ctx *hcl.EvalContext) hcl.Diagnostics {
 
 		provider := NewProvider()
 		moreDiags = provider.decode(block, ctx.NewChild())
+		moreDiags = moreDiags.Extend(verifyProviderSchema(block, fp.schemaSeverity()))
 		diags = diags.Extend(moreDiags)
 		if moreDiags != nil && moreDiags.HasErrors() {
 			continue
@@ -501,11 +571,71 @@ func (fp *FlightPlan) decodeModules(ctx *hcl.EvalContext) hcl.Diagnostics {
 	return diags
 }
 
+// decodeFixtures decodes "fixture" blocks that are defined in the top-level schema.
+func (fp *FlightPlan) decodeFixtures(ctx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	fixtures := map[string]cty.Value{}
+
+	for _, block := range fp.BodyContent.Blocks.OfType(blockTypeFixture) {
+		moreDiags := verifyBlockLabelsAreValidIdentifiers(block)
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		if _, previouslyDefined := fixtures[block.Labels[0]]; previouslyDefined {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "fixture has previously been defined",
+				Detail:   fmt.Sprintf(`fixture %s has already been defined`, block.Labels[0]),
+				Subject:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+
+		fixture := NewFixture()
+		moreDiags = fixture.decode(block, ctx.NewChild())
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		fp.Fixtures = append(fp.Fixtures, fixture)
+		fixtures[fixture.Name] = fixture.ToCtyValue()
+	}
+
+	ctx.Variables["fixture"] = cty.ObjectVal(fixtures)
+
+	return diags
+}
+
+// Fixture returns the fixture with the name, or nil if it has not been defined.
+func (fp *FlightPlan) Fixture(name string) *Fixture {
+	if fp == nil {
+		return nil
+	}
+
+	for _, fixture := range fp.Fixtures {
+		if fixture.Name == name {
+			return fixture
+		}
+	}
+
+	return nil
+}
+
 // decodeMatrix takes an eval context and scenario blocks and decodes only the
 // matrix block. It returns a unique matrix with vectors for all unique variant
-// value combinations.
-func decodeMatrix(ctx *hcl.EvalContext, block *hcl.Block) (*MatrixBlock, hcl.Diagnostics) {
-	decoder := newMatrixDecoder()
+// value combinations that match the filter, if one is given. If the limits
+// include a matrix memory hint the expansion will fail if it would use more.
+func decodeMatrix(
+	ctx *hcl.EvalContext,
+	block *hcl.Block,
+	filter *ScenarioFilter,
+	limits *ResourceLimits,
+) (*MatrixBlock, hcl.Diagnostics) {
+	decoder := newMatrixDecoder(filter, limits.matrixBytes())
 	return decoder.decodeMatrix(ctx, block)
 }
 
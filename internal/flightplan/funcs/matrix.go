@@ -0,0 +1,226 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// CrossProductFunc constructs a function that takes any number of lists and returns a list of
+// every combination of their elements, in order, where the elements of the first list vary the
+// slowest.
+var CrossProductFunc = function.New(&function.Spec{
+	VarParam: &function.Parameter{
+		Name: "lists",
+		Type: cty.DynamicPseudoType,
+	},
+	Type: function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		lists, err := matrixFuncLists(args)
+		if err != nil {
+			return cty.DynamicVal, err
+		}
+
+		if len(lists) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+
+		products := [][]cty.Value{{}}
+		for _, list := range lists {
+			next := [][]cty.Value{}
+			for _, product := range products {
+				for _, elm := range list {
+					combination := make([]cty.Value, len(product), len(product)+1)
+					copy(combination, product)
+					next = append(next, append(combination, elm))
+				}
+			}
+			products = next
+		}
+
+		return matrixFuncTuples(products), nil
+	},
+})
+
+// ZipFunc constructs a function that takes any number of lists of the same length and returns a
+// list of lists where the nth list has the nth element of each list.
+var ZipFunc = function.New(&function.Spec{
+	VarParam: &function.Parameter{
+		Name: "lists",
+		Type: cty.DynamicPseudoType,
+	},
+	Type: function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		lists, err := matrixFuncLists(args)
+		if err != nil {
+			return cty.DynamicVal, err
+		}
+
+		if len(lists) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+
+		zipped := make([][]cty.Value, len(lists[0]))
+		for i, list := range lists {
+			if len(list) != len(lists[0]) {
+				return cty.DynamicVal, function.NewArgErrorf(i,
+					"all lists must have the same length, expected %d elements, found %d", len(lists[0]), len(list),
+				)
+			}
+
+			for j, elm := range list {
+				zipped[j] = append(zipped[j], elm)
+			}
+		}
+
+		return matrixFuncTuples(zipped), nil
+	},
+})
+
+// SemverRangeFunc constructs a function that takes a start and end version and returns a list of
+// every version between them, inclusive. The versions can use x as a wildcard for trailing
+// components, e.g. semverrange("1.15.x", "1.17.x") returns ["1.15.x", "1.16.x", "1.17.x"]. The
+// versions must only differ in a single component, which is the one that is expanded.
+var SemverRangeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "start",
+			Type: cty.String,
+		},
+		{
+			Name: "end",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.List(cty.String)),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		prefix, start, err := parseSemverRangeVersion(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+
+		_, end, err := parseSemverRangeVersion(args[1].AsString())
+		if err != nil {
+			return cty.NilVal, function.NewArgError(1, err)
+		}
+
+		if len(start) != len(end) {
+			return cty.NilVal, errors.New("start and end versions must have the same number of components")
+		}
+
+		// Find the component that we're expanding. Every other component must be the same.
+		expand := -1
+		for i := range start {
+			if start[i] == end[i] {
+				continue
+			}
+
+			if expand != -1 {
+				return cty.NilVal, errors.New("start and end versions must only differ in a single component")
+			}
+			expand = i
+		}
+
+		if expand == -1 {
+			return cty.ListVal([]cty.Value{cty.StringVal(prefix + strings.Join(start, "."))}), nil
+		}
+
+		from, err := strconv.Atoi(start[expand])
+		if err != nil {
+			return cty.NilVal, function.NewArgErrorf(0, "cannot expand wildcard component %s", start[expand])
+		}
+
+		to, err := strconv.Atoi(end[expand])
+		if err != nil {
+			return cty.NilVal, function.NewArgErrorf(1, "cannot expand wildcard component %s", end[expand])
+		}
+
+		if from > to {
+			return cty.NilVal, fmt.Errorf("start version %s is greater than end version %s",
+				args[0].AsString(), args[1].AsString(),
+			)
+		}
+
+		versions := []cty.Value{}
+		version := make([]string, len(start))
+		copy(version, start)
+		for i := from; i <= to; i++ {
+			version[expand] = strconv.Itoa(i)
+			versions = append(versions, cty.StringVal(prefix+strings.Join(version, ".")))
+		}
+
+		return cty.ListVal(versions), nil
+	},
+})
+
+// parseSemverRangeVersion parses a version of semverrange into its optional v prefix and its
+// components. Components must be numbers or wildcards and wildcards can only be trailing.
+func parseSemverRangeVersion(version string) (string, []string, error) {
+	prefix := ""
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+
+	components := strings.Split(strings.TrimPrefix(version, prefix), ".")
+	wildcard := false
+	for i, component := range components {
+		switch component {
+		case "x", "X", "*":
+			wildcard = true
+			components[i] = "x"
+
+			continue
+		default:
+		}
+
+		if wildcard {
+			return "", nil, fmt.Errorf("invalid version %s, wildcards must be the last components", version)
+		}
+
+		if _, err := strconv.ParseUint(component, 10, 64); err != nil {
+			return "", nil, fmt.Errorf("invalid version %s, components must be numbers or wildcards", version)
+		}
+	}
+
+	return prefix, components, nil
+}
+
+// matrixFuncLists returns the elements of each list argument.
+func matrixFuncLists(args []cty.Value) ([][]cty.Value, error) {
+	lists := [][]cty.Value{}
+	for i, arg := range args {
+		if !arg.IsWhollyKnown() {
+			return nil, function.NewArgErrorf(i, "list must be known")
+		}
+
+		if arg.IsNull() || !(arg.Type().IsListType() || arg.Type().IsTupleType() || arg.Type().IsSetType()) {
+			return nil, function.NewArgErrorf(i, "expected a list, found %s", arg.Type().FriendlyName())
+		}
+
+		lists = append(lists, arg.AsValueSlice())
+	}
+
+	return lists, nil
+}
+
+// matrixFuncTuples returns the lists of values as a tuple of tuples as their element types can
+// differ.
+func matrixFuncTuples(lists [][]cty.Value) cty.Value {
+	if len(lists) == 0 {
+		return cty.EmptyTupleVal
+	}
+
+	tuples := []cty.Value{}
+	for _, list := range lists {
+		tuples = append(tuples, cty.TupleVal(list))
+	}
+
+	return cty.TupleVal(tuples)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCrossProductFunc(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		lists    []cty.Value
+		expected cty.Value
+		err      string
+	}{
+		"no lists": {
+			lists:    []cty.Value{},
+			expected: cty.EmptyTupleVal,
+		},
+		"lists": {
+			lists: []cty.Value{
+				cty.ListVal([]cty.Value{cty.StringVal("ubuntu"), cty.StringVal("rhel")}),
+				cty.TupleVal([]cty.Value{cty.StringVal("amd64"), cty.NumberIntVal(64)}),
+			},
+			expected: cty.TupleVal([]cty.Value{
+				cty.TupleVal([]cty.Value{cty.StringVal("ubuntu"), cty.StringVal("amd64")}),
+				cty.TupleVal([]cty.Value{cty.StringVal("ubuntu"), cty.NumberIntVal(64)}),
+				cty.TupleVal([]cty.Value{cty.StringVal("rhel"), cty.StringVal("amd64")}),
+				cty.TupleVal([]cty.Value{cty.StringVal("rhel"), cty.NumberIntVal(64)}),
+			}),
+		},
+		"empty list": {
+			lists: []cty.Value{
+				cty.ListVal([]cty.Value{cty.StringVal("ubuntu")}),
+				cty.ListValEmpty(cty.String),
+			},
+			expected: cty.EmptyTupleVal,
+		},
+		"not a list": {
+			lists: []cty.Value{cty.StringVal("ubuntu")},
+			err:   "expected a list, found string",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			val, err := CrossProductFunc.Call(test.lists)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, test.expected.RawEquals(val), val.GoString())
+		})
+	}
+}
+
+func TestZipFunc(t *testing.T) {
+	t.Parallel()
+
+	val, err := ZipFunc.Call([]cty.Value{
+		cty.ListVal([]cty.Value{cty.StringVal("1.15.0"), cty.StringVal("1.16.0")}),
+		cty.TupleVal([]cty.Value{cty.StringVal("go1.20"), cty.StringVal("go1.21")}),
+	})
+	require.NoError(t, err)
+	require.True(t, cty.TupleVal([]cty.Value{
+		cty.TupleVal([]cty.Value{cty.StringVal("1.15.0"), cty.StringVal("go1.20")}),
+		cty.TupleVal([]cty.Value{cty.StringVal("1.16.0"), cty.StringVal("go1.21")}),
+	}).RawEquals(val), val.GoString())
+
+	_, err = ZipFunc.Call([]cty.Value{
+		cty.ListVal([]cty.Value{cty.StringVal("1.15.0"), cty.StringVal("1.16.0")}),
+		cty.ListVal([]cty.Value{cty.StringVal("go1.20")}),
+	})
+	require.ErrorContains(t, err, "all lists must have the same length, expected 2 elements, found 1")
+}
+
+func TestSemverRangeFunc(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		start    string
+		end      string
+		expected []string
+		err      string
+	}{
+		"minor wildcards": {
+			start:    "1.15.x",
+			end:      "1.17.x",
+			expected: []string{"1.15.x", "1.16.x", "1.17.x"},
+		},
+		"patches": {
+			start:    "v1.15.0",
+			end:      "v1.15.2",
+			expected: []string{"v1.15.0", "v1.15.1", "v1.15.2"},
+		},
+		"majors": {
+			start:    "1.*",
+			end:      "3.X",
+			expected: []string{"1.x", "2.x", "3.x"},
+		},
+		"same": {
+			start:    "1.15.1",
+			end:      "1.15.1",
+			expected: []string{"1.15.1"},
+		},
+		"more than one component differs": {
+			start: "1.15.0",
+			end:   "1.16.1",
+			err:   "start and end versions must only differ in a single component",
+		},
+		"different lengths": {
+			start: "1.15",
+			end:   "1.16.x",
+			err:   "start and end versions must have the same number of components",
+		},
+		"start greater than end": {
+			start: "1.17.x",
+			end:   "1.15.x",
+			err:   "start version 1.17.x is greater than end version 1.15.x",
+		},
+		"expanding a wildcard": {
+			start: "1.15.x",
+			end:   "1.15.1",
+			err:   "cannot expand wildcard component x",
+		},
+		"leading wildcard": {
+			start: "1.x.1",
+			end:   "1.x.2",
+			err:   "wildcards must be the last components",
+		},
+		"prerelease": {
+			start: "1.15.0-rc1",
+			end:   "1.15.2",
+			err:   "components must be numbers or wildcards",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			val, err := SemverRangeFunc.Call([]cty.Value{cty.StringVal(test.start), cty.StringVal(test.end)})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			versions := []string{}
+			for _, v := range val.AsValueSlice() {
+				versions = append(versions, v.AsString())
+			}
+			require.Equal(t, test.expected, versions)
+		})
+	}
+}
@@ -0,0 +1,538 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// HCLSchemaFormatVersion is the version of the format of the HCL schema.
+const HCLSchemaFormatVersion = "1"
+
+// HCLSchema is the machine readable schema of the blocks, attributes and types of every kind of
+// enos HCL file, for editor validation and third-party generators. Block schemas are modeled on
+// those of 'terraform providers schema -json'.
+type HCLSchema struct {
+	FormatVersion string           `json:"format_version"`
+	Files         []*HCLSchemaFile `json:"files"`
+}
+
+// HCLSchemaFile is the schema of a kind of HCL file.
+type HCLSchemaFile struct {
+	Name string `json:"name"`
+	// Pattern is the regular expression that the file names match
+	Pattern string          `json:"pattern"`
+	Body    *HCLBlockSchema `json:"body"`
+}
+
+// HCLBlockSchema is the schema of the body of a block. Freeform bodies also accept attributes
+// that are not in their schema, e.g. the attributes of locals blocks or the configuration of
+// providers, whose types are unknown.
+type HCLBlockSchema struct {
+	Attributes map[string]*HCLAttributeSchema `json:"attributes,omitempty"`
+	BlockTypes map[string]*HCLBlockTypeSchema `json:"block_types,omitempty"`
+	Freeform   bool                           `json:"freeform,omitempty"`
+}
+
+// HCLAttributeSchema is the schema of an attribute. The type is a JSON encoded cty type, where
+// "dynamic" is any type, e.g. a reference to a module.
+type HCLAttributeSchema struct {
+	Type     json.RawMessage `json:"type"`
+	Required bool            `json:"required,omitempty"`
+	Optional bool            `json:"optional,omitempty"`
+}
+
+// HCLBlockTypeSchema is the schema of a nested block type. Blocks whose body depends on their
+// first label, e.g. the type of a notify block, have the body of each label instead.
+type HCLBlockTypeSchema struct {
+	Labels      []string                   `json:"labels,omitempty"`
+	Block       *HCLBlockSchema            `json:"block,omitempty"`
+	LabelBlocks map[string]*HCLBlockSchema `json:"label_blocks,omitempty"`
+}
+
+// hclSchemaBody describes the body of a block for the HCL schema. The names of the attributes,
+// whether they're required, and the headers of nested blocks come from the body schema that the
+// decoder uses, while their types and the bodies of nested blocks are described here.
+type hclSchemaBody struct {
+	schema *hcl.BodySchema
+	types  map[string]cty.Type
+	blocks map[string]*hclSchemaBody
+	// labelBlocks are the bodies of each first label of blocks whose schema depends on it
+	labelBlocks map[string]*hclSchemaBody
+	freeform    bool
+}
+
+// freeformSchemaBody is the body of blocks that accept any attributes, e.g. locals.
+var freeformSchemaBody = &hclSchemaBody{schema: &hcl.BodySchema{}, freeform: true}
+
+// hclSchemaFiles returns the schema bodies of every kind of HCL file.
+func hclSchemaFiles() []struct {
+	name    string
+	pattern string
+	body    *hclSchemaBody
+} {
+	matrix := &hclSchemaBody{
+		schema: matrixIncludeExcludeSchema,
+		blocks: map[string]*hclSchemaBody{
+			blockTypeMatrixInclude: freeformSchemaBody,
+			blockTypeMatrixExclude: freeformSchemaBody,
+			blockTypeMatrixKeys:    freeformSchemaBody,
+		},
+		freeform: true,
+	}
+	matrix.blocks[blockTypeMatrixConstraints] = &hclSchemaBody{
+		schema: matrixConstraintsSchema,
+		types:  map[string]cty.Type{"excludes": cty.Map(cty.List(cty.String))},
+	}
+	matrix.blocks[blockTypeMatrixSharedExcludes] = &hclSchemaBody{
+		schema: matrixSharedExcludesSchema,
+		types:  map[string]cty.Type{"from": cty.List(cty.List(cty.List(cty.String)))},
+	}
+
+	flightPlan := &hclSchemaBody{
+		schema: flightPlanSchema,
+		blocks: map[string]*hclSchemaBody{
+			blockTypeEnos: {
+				schema: enosSettingSchema,
+				types:  map[string]cty.Type{"required_version": cty.String},
+			},
+			blockTypeGlobals: freeformSchemaBody,
+			blockTypeSample: {
+				schema: sampleSchema,
+				types:  map[string]cty.Type{"attributes": cty.DynamicPseudoType},
+				blocks: map[string]*hclSchemaBody{
+					blockTypeSampleSubset: {
+						schema: sampleSubsetSchema,
+						types: map[string]cty.Type{
+							"attributes":      cty.DynamicPseudoType,
+							"scenario_name":   cty.String,
+							"scenario_filter": cty.String,
+						},
+						blocks: map[string]*hclSchemaBody{blockTypeMatrix: matrix},
+					},
+				},
+			},
+			blockTypeMatrixExcludes: {
+				schema: matrixExcludesSchema,
+				blocks: map[string]*hclSchemaBody{blockTypeMatrixExclude: freeformSchemaBody},
+			},
+			blockTypeTerraformSetting: terraformSettingSchemaBody(),
+			blockTypeTerraformCLI: mergeSchemaBodies(&hclSchemaBody{
+				schema: terraformCLISchema,
+				types: map[string]cty.Type{
+					"path": cty.String,
+					"env":  cty.Map(cty.String),
+				},
+			}, specSchemaBody(terraformCLISpec)),
+			blockTypeProvider: freeformSchemaBody,
+			blockTypeQuality: {
+				schema: qualitySchema,
+				types:  map[string]cty.Type{"description": cty.String},
+			},
+			blockTypeScenario: {
+				schema: scenarioSchema,
+				types: map[string]cty.Type{
+					"description":   cty.String,
+					"tags":          cty.List(cty.String),
+					"xfail":         cty.String,
+					"terraform_cli": cty.DynamicPseudoType,
+					"terraform":     cty.DynamicPseudoType,
+					"providers":     cty.DynamicPseudoType,
+				},
+				blocks: map[string]*hclSchemaBody{
+					blockTypeScenarioStep: {
+						schema: scenarioStepSchema,
+						types: map[string]cty.Type{
+							"description": cty.String,
+							"module":      cty.DynamicPseudoType,
+							"provisioner": cty.String,
+							"outputs":     cty.List(cty.String),
+							"providers":   cty.DynamicPseudoType,
+							"depends_on":  cty.DynamicPseudoType,
+							"skip_step":   cty.Bool,
+							"verifies":    cty.DynamicPseudoType,
+						},
+						blocks: map[string]*hclSchemaBody{blockTypeVariables: freeformSchemaBody},
+					},
+					blockTypeOutput: specSchemaBody(scenarioOutputSpec()),
+					blockTypeMatrix: matrix,
+					blockTypeLocals: freeformSchemaBody,
+					blockTypeOverrides: {
+						schema: overridesSchema,
+						blocks: map[string]*hclSchemaBody{
+							blockTypeRequiredProviders: freeformSchemaBody,
+							blockTypeBackend:           freeformSchemaBody,
+						},
+					},
+				},
+			},
+			blockTypeModule: {
+				schema: moduleSchema,
+				types: map[string]cty.Type{
+					"source":  cty.String,
+					"version": cty.String,
+				},
+				freeform: true,
+			},
+			blockTypeFixture: {
+				schema: fixtureSchema,
+				types: map[string]cty.Type{
+					"description":   cty.String,
+					"module":        cty.DynamicPseudoType,
+					"providers":     cty.DynamicPseudoType,
+					"terraform_cli": cty.DynamicPseudoType,
+					"terraform":     cty.DynamicPseudoType,
+				},
+				blocks: map[string]*hclSchemaBody{blockTypeVariables: freeformSchemaBody},
+			},
+			blockTypeVariable: {
+				schema: variableSchema,
+				types: map[string]cty.Type{
+					"description":      cty.String,
+					"default":          cty.DynamicPseudoType,
+					"type":             cty.DynamicPseudoType,
+					"sensitive":        cty.Bool,
+					"outputs_file":     cty.String,
+					"outputs_scenario": cty.String,
+					"output":           cty.String,
+				},
+				blocks: map[string]*hclSchemaBody{blockTypeValidation: freeformSchemaBody},
+			},
+		},
+	}
+
+	lint := &hclSchemaBody{
+		schema: lintConfigSchema,
+		blocks: map[string]*hclSchemaBody{
+			// Rules have their own settings, e.g. the max of matrix_dimensions.
+			blockTypeLintRule: {
+				schema: lintRuleSchema,
+				types: map[string]cty.Type{
+					"enabled":  cty.Bool,
+					"severity": cty.String,
+				},
+				freeform: true,
+			},
+		},
+	}
+
+	skip := &hclSchemaBody{
+		schema: skipConfigSchema,
+		blocks: map[string]*hclSchemaBody{
+			blockTypeSkip: {
+				schema: skipSchema,
+				types: map[string]cty.Type{
+					"scenario_filter": cty.String,
+					"reason":          cty.String,
+				},
+			},
+		},
+	}
+
+	return []struct {
+		name    string
+		pattern string
+		body    *hclSchemaBody
+	}{
+		{"flight plan", FlightPlanFileNamePattern.String(), flightPlan},
+		{"variables", VariablesNamePattern.String(), freeformSchemaBody},
+		{"lint", LintConfigNamePattern.String(), lint},
+		{"skip", SkipConfigNamePattern.String(), skip},
+		{"project", ProjectConfigNamePattern.String(), projectConfigSchemaBody(lint)},
+	}
+}
+
+// terraformSettingSchemaBody returns the schema body of terraform blocks, which are decoded
+// partially with specs and the rest with a body schema.
+func terraformSettingSchemaBody() *hclSchemaBody {
+	return mergeSchemaBodies(
+		specSchemaBody(terraformSettingRequiredVersionSpec),
+		specSchemaBody(terraformSettingExperimentsSpec),
+		specSchemaBody(terraformSettingCloudSpec),
+		&hclSchemaBody{
+			schema: terraformSettingSchema,
+			blocks: map[string]*hclSchemaBody{
+				blockTypeRequiredProviders: freeformSchemaBody,
+				blockTypeProviderMeta:      freeformSchemaBody,
+				blockTypeBackend:           freeformSchemaBody,
+			},
+		},
+	)
+}
+
+// projectConfigSchemaBody returns the schema body of project configuration files, whose lint
+// blocks have the body of lint files.
+func projectConfigSchemaBody(lint *hclSchemaBody) *hclSchemaBody {
+	notifiers := map[string]*hclSchemaBody{
+		NotifierTypeSlack: {
+			schema: projectNotifierSchemas[NotifierTypeSlack],
+			types: map[string]cty.Type{
+				"webhook_url":   cty.String,
+				"token":         cty.String,
+				"channel":       cty.String,
+				"failures_only": cty.Bool,
+				"links":         cty.Map(cty.String),
+			},
+		},
+		NotifierTypeGitHub: {
+			schema: projectNotifierSchemas[NotifierTypeGitHub],
+			types: map[string]cty.Type{
+				"token":           cty.String,
+				"app_id":          cty.String,
+				"installation_id": cty.String,
+				"private_key":     cty.String,
+				"repository":      cty.String,
+				"sha":             cty.String,
+				"check_name":      cty.String,
+				"api_url":         cty.String,
+				"links":           cty.Map(cty.String),
+			},
+		},
+		NotifierTypeDatadog: {
+			schema: projectNotifierSchemas[NotifierTypeDatadog],
+			types: map[string]cty.Type{
+				"api_key":        cty.String,
+				"site":           cty.String,
+				"statsd_address": cty.String,
+				"metric_prefix":  cty.String,
+				"tags":           cty.List(cty.String),
+			},
+		},
+	}
+
+	publisherTypes := map[string]cty.Type{
+		"bucket":      cty.String,
+		"region":      cty.String,
+		"endpoint":    cty.String,
+		"prefix":      cty.String,
+		"run_id":      cty.String,
+		"paths":       cty.List(cty.String),
+		"credentials": cty.String,
+		"token":       cty.String,
+		"account":     cty.String,
+		"container":   cty.String,
+		"sas_token":   cty.String,
+		"account_key": cty.String,
+	}
+	publishers := map[string]*hclSchemaBody{}
+	for typ, schema := range projectPublisherSchemas {
+		types := map[string]cty.Type{}
+		for _, attr := range schema.Attributes {
+			if ty, ok := publisherTypes[attr.Name]; ok {
+				types[attr.Name] = ty
+			}
+		}
+		publishers[typ] = &hclSchemaBody{schema: schema, types: types}
+	}
+
+	return &hclSchemaBody{
+		schema: projectConfigSchema,
+		types: map[string]cty.Type{
+			"out_dir":             cty.String,
+			"format":              cty.String,
+			"worker_count":        cty.Number,
+			"max_decode_workers":  cty.Number,
+			"timeout":             cty.String,
+			"lock_timeout":        cty.String,
+			"strict_schema":       cty.Bool,
+			"fail_on_warnings":    cty.Bool,
+			"log_file":            cty.String,
+			"locale":              cty.String,
+			"message_catalog":     cty.String,
+			"statsd_address":      cty.String,
+			"statsd_prefix":       cty.String,
+			"ci":                  cty.String,
+			"tags":                cty.Map(cty.String),
+			"reuse_terraform_dir": cty.String,
+		},
+		blocks: map[string]*hclSchemaBody{
+			blockTypeProjectLint:    lint,
+			blockTypeProjectNotify:  {labelBlocks: notifiers},
+			blockTypeProjectPublish: {labelBlocks: publishers},
+			blockTypeProjectPolicy: {
+				schema: projectPolicySchema,
+				types: map[string]cty.Type{
+					"max_hourly_cost":     cty.Number,
+					"max_monthly_cost":    cty.Number,
+					"deny_resource_types": cty.List(cty.String),
+					"deny_instance_types": cty.List(cty.String),
+					"rego":                cty.List(cty.String),
+					"opa_path":            cty.String,
+					"allow_exec":          cty.List(cty.String),
+					"deny_exec":           cty.List(cty.String),
+				},
+			},
+			blockTypeProjectLayout: {
+				schema: projectLayoutSchema,
+				types: map[string]cty.Type{
+					"naming": cty.String,
+					"nested": cty.Bool,
+				},
+			},
+			blockTypeProjectLock: {
+				schema: projectLockSchema,
+				types: map[string]cty.Type{
+					"file":      cty.String,
+					"platforms": cty.List(cty.String),
+				},
+			},
+			blockTypeProjectWarnings: {
+				schema: projectWarningsSchema,
+				types: map[string]cty.Type{
+					"max":  cty.Number,
+					"deny": cty.List(cty.String),
+				},
+			},
+		},
+	}
+}
+
+// specSchemaBody returns the schema body of a body that is decoded with the spec.
+func specSchemaBody(spec hcldec.Spec) *hclSchemaBody {
+	body := &hclSchemaBody{
+		schema: hcldec.ImpliedSchema(spec),
+		types:  map[string]cty.Type{},
+		blocks: map[string]*hclSchemaBody{},
+	}
+
+	obj, ok := spec.(hcldec.ObjectSpec)
+	if !ok {
+		obj = hcldec.ObjectSpec{"": spec}
+	}
+
+	for _, s := range obj {
+		switch s := s.(type) {
+		case *hcldec.AttrSpec:
+			body.types[s.Name] = s.Type
+		case *hcldec.BlockSpec:
+			body.blocks[s.TypeName] = specSchemaBody(s.Nested)
+		case *hcldec.BlockListSpec:
+			body.blocks[s.TypeName] = specSchemaBody(s.Nested)
+		case *hcldec.BlockSetSpec:
+			body.blocks[s.TypeName] = specSchemaBody(s.Nested)
+		case *hcldec.BlockMapSpec:
+			body.blocks[s.TypeName] = specSchemaBody(s.Nested)
+		case *hcldec.BlockAttrsSpec:
+			body.blocks[s.TypeName] = freeformSchemaBody
+		}
+	}
+
+	return body
+}
+
+// mergeSchemaBodies returns the schema body of a body that is decoded partially with each of the
+// bodies.
+func mergeSchemaBodies(bodies ...*hclSchemaBody) *hclSchemaBody {
+	merged := &hclSchemaBody{
+		schema: &hcl.BodySchema{},
+		types:  map[string]cty.Type{},
+		blocks: map[string]*hclSchemaBody{},
+	}
+
+	for _, body := range bodies {
+		merged.schema.Attributes = append(merged.schema.Attributes, body.schema.Attributes...)
+		merged.schema.Blocks = append(merged.schema.Blocks, body.schema.Blocks...)
+		for k, v := range body.types {
+			merged.types[k] = v
+		}
+		for k, v := range body.blocks {
+			merged.blocks[k] = v
+		}
+		merged.freeform = merged.freeform || body.freeform
+	}
+
+	return merged
+}
+
+// NewHCLSchema returns the schema of every kind of enos HCL file.
+func NewHCLSchema() (*HCLSchema, error) {
+	schema := &HCLSchema{FormatVersion: HCLSchemaFormatVersion}
+
+	for _, file := range hclSchemaFiles() {
+		body, err := file.body.blockSchema()
+		if err != nil {
+			return nil, fmt.Errorf("%s files: %w", file.name, err)
+		}
+
+		schema.Files = append(schema.Files, &HCLSchemaFile{
+			Name:    file.name,
+			Pattern: file.pattern,
+			Body:    body,
+		})
+	}
+
+	return schema, nil
+}
+
+// blockSchema returns the schema of the body.
+func (b *hclSchemaBody) blockSchema() (*HCLBlockSchema, error) {
+	out := &HCLBlockSchema{Freeform: b.freeform}
+	if b.schema == nil {
+		return out, nil
+	}
+
+	for _, attr := range b.schema.Attributes {
+		ty, ok := b.types[attr.Name]
+		if !ok {
+			return nil, fmt.Errorf("attribute %s has no type", attr.Name)
+		}
+		// Step variables and other capsules can be any value in the configuration.
+		if ty.IsCapsuleType() {
+			ty = cty.DynamicPseudoType
+		}
+
+		typ, err := ctyjson.MarshalType(ty)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", attr.Name, err)
+		}
+
+		if out.Attributes == nil {
+			out.Attributes = map[string]*HCLAttributeSchema{}
+		}
+		out.Attributes[attr.Name] = &HCLAttributeSchema{
+			Type:     typ,
+			Required: attr.Required,
+			Optional: !attr.Required,
+		}
+	}
+
+	for _, header := range b.schema.Blocks {
+		body, ok := b.blocks[header.Type]
+		if !ok {
+			return nil, fmt.Errorf("block %s has no body", header.Type)
+		}
+
+		blockType := &HCLBlockTypeSchema{Labels: slices.Clone(header.LabelNames)}
+		if body.labelBlocks != nil {
+			blockType.LabelBlocks = map[string]*HCLBlockSchema{}
+			for label, labelBody := range body.labelBlocks {
+				schema, err := labelBody.blockSchema()
+				if err != nil {
+					return nil, fmt.Errorf("block %s %q: %w", header.Type, label, err)
+				}
+				blockType.LabelBlocks[label] = schema
+			}
+		} else {
+			schema, err := body.blockSchema()
+			if err != nil {
+				return nil, fmt.Errorf("block %s: %w", header.Type, err)
+			}
+			blockType.Block = schema
+		}
+
+		if out.BlockTypes == nil {
+			out.BlockTypes = map[string]*HCLBlockTypeSchema{}
+		}
+		out.BlockTypes[header.Type] = blockType
+	}
+
+	return out, nil
+}
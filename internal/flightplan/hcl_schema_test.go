@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_HCLSchema_InSync tests that the HCL schema describes exactly the attributes and blocks of
+// the body schemas that the decoder uses.
+func Test_HCLSchema_InSync(t *testing.T) {
+	t.Parallel()
+
+	var check func(t *testing.T, path string, body *hclSchemaBody)
+	check = func(t *testing.T, path string, body *hclSchemaBody) {
+		t.Helper()
+
+		for label, labelBody := range body.labelBlocks {
+			check(t, path+"."+label, labelBody)
+		}
+		if body.schema == nil {
+			return
+		}
+
+		for name := range body.types {
+			require.Truef(t, slices.ContainsFunc(body.schema.Attributes, func(attr hcl.AttributeSchema) bool {
+				return attr.Name == name
+			}), "%s.%s has a type but isn't an attribute of the schema", path, name)
+		}
+
+		for name, nested := range body.blocks {
+			require.Truef(t, slices.ContainsFunc(body.schema.Blocks, func(header hcl.BlockHeaderSchema) bool {
+				return header.Type == name
+			}), "%s.%s has a body but isn't a block of the schema", path, name)
+			check(t, path+"."+name, nested)
+		}
+	}
+
+	for _, file := range hclSchemaFiles() {
+		check(t, file.name, file.body)
+	}
+
+	_, err := NewHCLSchema()
+	require.NoError(t, err)
+}
+
+// Test_NewHCLSchema tests the JSON of the HCL schema.
+func Test_NewHCLSchema(t *testing.T) {
+	t.Parallel()
+
+	schema, err := NewHCLSchema()
+	require.NoError(t, err)
+	require.Equal(t, HCLSchemaFormatVersion, schema.FormatVersion)
+
+	files := map[string]*HCLSchemaFile{}
+	for _, file := range schema.Files {
+		files[file.Name] = file
+	}
+	require.Len(t, files, 5)
+	require.True(t, files["variables"].Body.Freeform)
+
+	fp := files["flight plan"]
+	require.Equal(t, FlightPlanFileNamePattern.String(), fp.Pattern)
+
+	scenario := fp.Body.BlockTypes[blockTypeScenario]
+	require.Equal(t, []string{"name"}, scenario.Labels)
+	require.JSONEq(t, `["list","string"]`, string(scenario.Block.Attributes["tags"].Type))
+
+	step := scenario.Block.BlockTypes[blockTypeScenarioStep].Block
+	require.True(t, step.Attributes["module"].Optional)
+	require.JSONEq(t, `"dynamic"`, string(step.Attributes["module"].Type))
+	require.True(t, step.BlockTypes[blockTypeVariables].Block.Freeform)
+
+	output := scenario.Block.BlockTypes[blockTypeOutput].Block
+	require.True(t, output.Attributes["value"].Required)
+	require.JSONEq(t, `"dynamic"`, string(output.Attributes["value"].Type))
+	require.JSONEq(t, `"bool"`, string(output.Attributes["sensitive"].Type))
+
+	cloud := fp.Body.BlockTypes[blockTypeTerraformSetting].Block.BlockTypes[blockTypeCloud].Block
+	require.True(t, cloud.Attributes["organization"].Required)
+	require.Contains(t, cloud.BlockTypes, "workspaces")
+
+	project := files["project"].Body
+	notify := project.BlockTypes[blockTypeProjectNotify]
+	require.Nil(t, notify.Block)
+	require.JSONEq(t, `"bool"`, string(notify.LabelBlocks[NotifierTypeSlack].Attributes["failures_only"].Type))
+	require.Equal(t, files["lint"].Body, project.BlockTypes[blockTypeProjectLint].Block)
+
+	b, err := json.Marshal(schema)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), `"block_types":{}`)
+}
@@ -0,0 +1,549 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+const blockTypeLintRule = "rule"
+
+var lintConfigSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeLintRule, LabelNames: []string{attrLabelNameDefault}},
+	},
+}
+
+var lintRuleSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "enabled", Required: false},
+		{Name: "severity", Required: false},
+	},
+}
+
+// LintRule is a rule that a decoded flight plan should satisfy. Rules are configured with "rule"
+// blocks in enos*.lint.hcl files.
+type LintRule interface {
+	// Name is the name of the rule. It is used as the label of the rule block and as the code of
+	// any diagnostics that the rule creates.
+	Name() string
+	// Description describes what the rule checks.
+	Description() string
+	// Configure configures the rule with all attributes of the rule block other than "enabled"
+	// and "severity".
+	Configure(body hcl.Body) hcl.Diagnostics
+	// Lint lints the flight plan and returns a diagnostic for every violation of the rule.
+	Lint(fp *FlightPlan) hcl.Diagnostics
+}
+
+// lintCode is an hcl.Diagnostic extra that carries the name of the lint rule that created the
+// diagnostic.
+type lintCode string
+
+// DiagnosticCode implements diagnostics.DiagnosticCode.
+func (c lintCode) DiagnosticCode() string {
+	return string(c)
+}
+
+// lintRuleState is the state of a lint rule.
+type lintRuleState struct {
+	rule     LintRule
+	enabled  bool
+	severity hcl.DiagnosticSeverity
+}
+
+// Linter lints decoded flight plans with a set of lint rules.
+type Linter struct {
+	Rules       []LintRule
+	ConfigFiles RawFiles
+	parser      *hclparse.Parser
+	rules       map[string]*lintRuleState
+}
+
+// LinterOpt is a Linter option.
+type LinterOpt func(*Linter)
+
+// NewLinter returns a new Linter. Unless otherwise configured it will use the default lint rules.
+func NewLinter(opts ...LinterOpt) *Linter {
+	l := &Linter{
+		Rules:       DefaultLintRules(),
+		ConfigFiles: RawFiles{},
+		parser:      hclparse.NewParser(),
+	}
+
+	for i := range opts {
+		opts[i](l)
+	}
+
+	return l
+}
+
+// WithLinterRules sets the lint rules. Custom rules can be added to the default rules with
+// WithLinterRules(append(DefaultLintRules(), rule)...).
+func WithLinterRules(rules ...LintRule) LinterOpt {
+	return func(l *Linter) {
+		l.Rules = rules
+	}
+}
+
+// WithLinterConfigFiles sets the raw lint configuration files.
+func WithLinterConfigFiles(files RawFiles) LinterOpt {
+	return func(l *Linter) {
+		l.ConfigFiles = files
+	}
+}
+
+// DefaultLintRules returns new instances of the default lint rules.
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		newLintRuleScenarioDescription(),
+		newLintRuleMatrixDimensions(),
+		newLintRuleStepName(),
+	}
+}
+
+// Files returns the parsed lint configuration files. They can be used to add snippets to
+// diagnostics of the lint configuration.
+func (l *Linter) Files() map[string]*hcl.File {
+	if l == nil || l.parser == nil {
+		return nil
+	}
+
+	return l.parser.Files()
+}
+
+// Configure parses and decodes the lint configuration files and configures the lint rules. Rules
+// are enabled with a warning severity unless they've been configured otherwise.
+func (l *Linter) Configure() hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	l.rules = map[string]*lintRuleState{}
+	for _, rule := range l.Rules {
+		l.rules[rule.Name()] = &lintRuleState{
+			rule:     rule,
+			enabled:  true,
+			severity: hcl.DiagWarning,
+		}
+	}
+
+	// Sort the paths so that our diagnostics are deterministic. Project configuration files are
+	// configured first as the rules in them are defaults for the lint configuration files.
+	paths := []string{}
+	for path := range l.ConfigFiles {
+		paths = append(paths, path)
+	}
+	slices.SortFunc(paths, func(a, b string) int {
+		if isProjectConfigFile(a) != isProjectConfigFile(b) {
+			if isProjectConfigFile(a) {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	var configured map[string]*hcl.Block
+	for i, path := range paths {
+		if i == 0 || isProjectConfigFile(paths[i-1]) != isProjectConfigFile(path) {
+			configured = map[string]*hcl.Block{}
+		}
+
+		file, moreDiags := l.parser.ParseHCL(l.ConfigFiles[path], path)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		blocks, moreDiags := l.ruleBlocks(path, file)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		for _, block := range blocks {
+			if prev, ok := configured[block.Labels[0]]; ok {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "lint rule has previously been configured",
+					Detail: fmt.Sprintf("lint rule %s has already been configured at %s",
+						block.Labels[0], prev.DefRange.String(),
+					),
+					Subject: block.LabelRanges[0].Ptr(),
+					Context: block.DefRange.Ptr(),
+				})
+
+				continue
+			}
+			configured[block.Labels[0]] = block
+
+			diags = diags.Extend(l.configureRule(block))
+		}
+	}
+
+	return diags
+}
+
+// ruleBlocks returns the "rule" blocks of a lint configuration file. The rules of project
+// configuration files are nested in "lint" blocks.
+func (l *Linter) ruleBlocks(path string, file *hcl.File) (hcl.Blocks, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	if !isProjectConfigFile(path) {
+		content, moreDiags := file.Body.Content(lintConfigSchema)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil, diags
+		}
+
+		return content.Blocks.OfType(blockTypeLintRule), diags
+	}
+
+	content, _, moreDiags := file.Body.PartialContent(projectConfigLintSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	blocks := hcl.Blocks{}
+	for _, lint := range content.Blocks.OfType(blockTypeProjectLint) {
+		lintContent, moreDiags := lint.Body.Content(lintConfigSchema)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		blocks = append(blocks, lintContent.Blocks.OfType(blockTypeLintRule)...)
+	}
+
+	return blocks, diags
+}
+
+// configureRule decodes a "rule" block and configures the rule.
+func (l *Linter) configureRule(block *hcl.Block) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	state, ok := l.rules[block.Labels[0]]
+	if !ok {
+		names := []string{}
+		for name := range l.rules {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "unknown lint rule",
+			Detail: fmt.Sprintf("lint rule %s does not exist, supported rules are %s",
+				block.Labels[0], strings.Join(names, ", "),
+			),
+			Subject: block.LabelRanges[0].Ptr(),
+			Context: block.DefRange.Ptr(),
+		})
+	}
+
+	content, remain, moreDiags := block.Body.PartialContent(lintRuleSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	if attr, ok := content.Attributes["enabled"]; ok {
+		diags = diags.Extend(gohcl.DecodeExpression(attr.Expr, nil, &state.enabled))
+	}
+
+	if attr, ok := content.Attributes["severity"]; ok {
+		var severity string
+		moreDiags := gohcl.DecodeExpression(attr.Expr, nil, &severity)
+		diags = diags.Extend(moreDiags)
+		if !moreDiags.HasErrors() {
+			switch severity {
+			case "error":
+				state.severity = hcl.DiagError
+			case "warning":
+				state.severity = hcl.DiagWarning
+			default:
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "invalid lint rule severity",
+					Detail:   fmt.Sprintf("the severity must be either error or warning, got %s", severity),
+					Subject:  attr.Expr.Range().Ptr(),
+					Context:  attr.Range.Ptr(),
+				})
+			}
+		}
+	}
+
+	return diags.Extend(state.rule.Configure(remain))
+}
+
+// Lint lints the flight plan with all enabled rules. Every diagnostic of a rule has the configured
+// severity of the rule and the rule name as its code. Configure must be called before linting.
+func (l *Linter) Lint(fp *FlightPlan) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if l.rules == nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "linter has not been configured",
+		})
+	}
+
+	for _, rule := range l.Rules {
+		state := l.rules[rule.Name()]
+		if !state.enabled {
+			continue
+		}
+
+		for _, diag := range rule.Lint(fp) {
+			diag.Severity = state.severity
+			diag.Extra = lintCode(rule.Name())
+			diags = diags.Append(diag)
+		}
+	}
+
+	return diags
+}
+
+// lintRuleScenarioDescription requires every scenario to have a description.
+type lintRuleScenarioDescription struct{}
+
+func newLintRuleScenarioDescription() *lintRuleScenarioDescription {
+	return &lintRuleScenarioDescription{}
+}
+
+func (r *lintRuleScenarioDescription) Name() string {
+	return "scenario_description"
+}
+
+func (r *lintRuleScenarioDescription) Description() string {
+	return "scenarios must have a description"
+}
+
+func (r *lintRuleScenarioDescription) Configure(body hcl.Body) hcl.Diagnostics {
+	_, diags := body.Content(&hcl.BodySchema{})
+
+	return diags
+}
+
+func (r *lintRuleScenarioDescription) Lint(fp *FlightPlan) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for _, sb := range fp.ScenarioBlocks {
+		content, _, _ := sb.Block.Body.PartialContent(&hcl.BodySchema{
+			Attributes: []hcl.AttributeSchema{{Name: "description"}},
+		})
+
+		if attr, ok := content.Attributes["description"]; ok {
+			// Descriptions that we can't evaluate without a variant, e.g. because they refer to the
+			// matrix, are not empty.
+			val, moreDiags := attr.Expr.Value(sb.EvalContext)
+			if moreDiags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.String {
+				continue
+			}
+
+			if strings.TrimSpace(val.AsString()) != "" {
+				continue
+			}
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "scenario has no description",
+			Detail:   fmt.Sprintf("scenario %s must have a description", sb.Name),
+			Subject:  sb.Block.LabelRanges[0].Ptr(),
+			Context:  sb.Block.DefRange.Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// lintRuleMatrixDimensions limits the number of dimensions, i.e. variant keys, of a scenario
+// matrix.
+type lintRuleMatrixDimensions struct {
+	max int
+}
+
+func newLintRuleMatrixDimensions() *lintRuleMatrixDimensions {
+	return &lintRuleMatrixDimensions{max: 8}
+}
+
+func (r *lintRuleMatrixDimensions) Name() string {
+	return "matrix_dimensions"
+}
+
+func (r *lintRuleMatrixDimensions) Description() string {
+	return fmt.Sprintf("scenario matrices must have no more than %d dimensions", r.max)
+}
+
+func (r *lintRuleMatrixDimensions) Configure(body hcl.Body) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "max"}},
+	})
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	attr, ok := content.Attributes["max"]
+	if !ok {
+		return diags
+	}
+
+	moreDiags = gohcl.DecodeExpression(attr.Expr, nil, &r.max)
+	diags = diags.Extend(moreDiags)
+	if !moreDiags.HasErrors() && r.max < 1 {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid maximum matrix dimensions",
+			Detail:   fmt.Sprintf("the maximum must be greater than zero, got %d", r.max),
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+	}
+
+	return diags
+}
+
+func (r *lintRuleMatrixDimensions) Lint(fp *FlightPlan) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for _, sb := range fp.ScenarioBlocks {
+		if sb.MatrixBlock == nil {
+			continue
+		}
+
+		matrices := append([]*Matrix{sb.MatrixBlock.GetOriginal()}, sb.MatrixBlock.GetIncludeProducts()...)
+		keys := map[string]struct{}{}
+		for _, m := range matrices {
+			for _, vec := range m.GetVectors() {
+				for _, elm := range vec.Elements() {
+					keys[elm.Key] = struct{}{}
+				}
+			}
+		}
+
+		if len(keys) <= r.max {
+			continue
+		}
+
+		subject := sb.Block.DefRange
+		content, _, _ := sb.Block.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeMatrix}},
+		})
+		if blocks := content.Blocks.OfType(blockTypeMatrix); len(blocks) > 0 {
+			subject = blocks[0].DefRange
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "scenario matrix has too many dimensions",
+			Detail: fmt.Sprintf("the matrix of scenario %s has %d dimensions, the maximum is %d",
+				sb.Name, len(keys), r.max,
+			),
+			Subject: subject.Ptr(),
+			Context: sb.Block.DefRange.Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// lintRuleStepName requires every step name to match a pattern.
+type lintRuleStepName struct {
+	pattern *regexp.Regexp
+}
+
+func newLintRuleStepName() *lintRuleStepName {
+	return &lintRuleStepName{pattern: regexp.MustCompile(`^[a-z][a-z0-9_]*$`)}
+}
+
+func (r *lintRuleStepName) Name() string {
+	return "step_name"
+}
+
+func (r *lintRuleStepName) Description() string {
+	return fmt.Sprintf("step names must match the pattern %s", r.pattern.String())
+}
+
+func (r *lintRuleStepName) Configure(body hcl.Body) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "pattern"}},
+	})
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	attr, ok := content.Attributes["pattern"]
+	if !ok {
+		return diags
+	}
+
+	var pattern string
+	moreDiags = gohcl.DecodeExpression(attr.Expr, nil, &pattern)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid step name pattern",
+			Detail:   err.Error(),
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+	}
+	r.pattern = re
+
+	return diags
+}
+
+func (r *lintRuleStepName) Lint(fp *FlightPlan) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for _, sb := range fp.ScenarioBlocks {
+		content, _, _ := sb.Block.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{
+				{Type: blockTypeScenarioStep, LabelNames: []string{attrLabelNameDefault}},
+			},
+		})
+
+		for _, step := range content.Blocks.OfType(blockTypeScenarioStep) {
+			if r.pattern.MatchString(step.Labels[0]) {
+				continue
+			}
+
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "step name does not match the pattern",
+				Detail: fmt.Sprintf("step %s of scenario %s must match the pattern %s",
+					step.Labels[0], sb.Name, r.pattern.String(),
+				),
+				Subject: step.LabelRanges[0].Ptr(),
+				Context: step.DefRange.Ptr(),
+			})
+		}
+	}
+
+	return diags
+}
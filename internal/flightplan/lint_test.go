@@ -0,0 +1,196 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_Linter_Lint tests linting flight plans with the default rules.
+func Test_Linter_Lint(t *testing.T) {
+	t.Parallel()
+
+	fpHCL := `
+module "backend" {
+  source = "./backend"
+}
+
+scenario "described" {
+  description = "A described scenario"
+
+  matrix {
+    backend = ["raft", "consul"]
+    distro  = ["ubuntu", "rhel"]
+  }
+
+  step "first_step" {
+    module = module.backend
+  }
+}
+
+scenario "undescribed" {
+  step "SecondStep" {
+    module = module.backend
+  }
+}
+`
+
+	for desc, test := range map[string]struct {
+		config   string
+		project  string
+		expected []*pb.Diagnostic
+		fail     bool
+	}{
+		"defaults": {
+			expected: []*pb.Diagnostic{
+				{
+					Severity: pb.Diagnostic_SEVERITY_WARNING,
+					Summary:  "scenario has no description",
+					Code:     "scenario_description",
+				},
+				{
+					Severity: pb.Diagnostic_SEVERITY_WARNING,
+					Summary:  "step name does not match the pattern",
+					Code:     "step_name",
+				},
+			},
+		},
+		"configured": {
+			config: `
+rule "scenario_description" {
+  severity = "error"
+}
+
+rule "matrix_dimensions" {
+  max = 1
+}
+
+rule "step_name" {
+  enabled = false
+}
+`,
+			expected: []*pb.Diagnostic{
+				{
+					Severity: pb.Diagnostic_SEVERITY_ERROR,
+					Summary:  "scenario has no description",
+					Code:     "scenario_description",
+				},
+				{
+					Severity: pb.Diagnostic_SEVERITY_WARNING,
+					Summary:  "scenario matrix has too many dimensions",
+					Code:     "matrix_dimensions",
+				},
+			},
+		},
+		"custom pattern": {
+			config: `
+rule "scenario_description" {
+  enabled = false
+}
+
+rule "step_name" {
+  pattern = "^[A-Za-z_]+$"
+}
+`,
+			expected: []*pb.Diagnostic{},
+		},
+		"project defaults": {
+			project: `
+format = "json"
+
+lint {
+  rule "scenario_description" {
+    enabled = false
+  }
+
+  rule "step_name" {
+    severity = "error"
+  }
+}
+`,
+			config: `
+rule "scenario_description" {
+  enabled = true
+}
+`,
+			expected: []*pb.Diagnostic{
+				{
+					Severity: pb.Diagnostic_SEVERITY_WARNING,
+					Summary:  "scenario has no description",
+					Code:     "scenario_description",
+				},
+				{
+					Severity: pb.Diagnostic_SEVERITY_ERROR,
+					Summary:  "step name does not match the pattern",
+					Code:     "step_name",
+				},
+			},
+		},
+		"unknown rule": {
+			config: `
+rule "scenario_name" {
+  enabled = false
+}
+`,
+			fail: true,
+		},
+		"invalid severity": {
+			config: `
+rule "step_name" {
+  severity = "info"
+}
+`,
+			fail: true,
+		},
+		"invalid pattern": {
+			config: `
+rule "step_name" {
+  pattern = "["
+}
+`,
+			fail: true,
+		},
+		"unknown attribute": {
+			config: `
+rule "matrix_dimensions" {
+  min = 1
+}
+`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			fp, err := testDecodeHCL(t, []byte(fpHCL), DecodeTargetAll)
+			require.NoError(t, err)
+
+			files := RawFiles{"enos.lint.hcl": []byte(test.config)}
+			if test.project != "" {
+				files[".enos.hcl"] = []byte(test.project)
+			}
+			linter := NewLinter(WithLinterConfigFiles(files))
+			diags := linter.Configure()
+			if test.fail {
+				require.True(t, diags.HasErrors())
+
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			lints := diagnostics.FromHCL(nil, linter.Lint(fp))
+			require.Len(t, lints, len(test.expected))
+			for i := range test.expected {
+				require.Equal(t, test.expected[i].GetSeverity(), lints[i].GetSeverity())
+				require.Equal(t, test.expected[i].GetSummary(), lints[i].GetSummary())
+				require.Equal(t, test.expected[i].GetCode(), lints[i].GetCode())
+			}
+		})
+	}
+}
@@ -20,6 +20,10 @@ type Element struct {
 	Key             string
 	Val             string
 	formattedString string // cached version of the element as a string
+	// Value is the typed value of an element of a dimension whose values are numbers, bools,
+	// objects, or maps. Val is then the value as a string, or the value of the declared key of an
+	// object, which identifies the element in filters, strings, and UIDs.
+	Value cty.Value
 }
 
 // Vector is an ordered collection of matrix elements.
@@ -46,6 +50,12 @@ func NewElement(key string, val string) Element {
 	return Element{Key: key, Val: val}
 }
 
+// NewElementWithValue takes an element key, the value of its declared key, and its structured
+// value and returns a new Element.
+func NewElementWithValue(key string, val string, value cty.Value) Element {
+	return Element{Key: key, Val: val, Value: value}
+}
+
 // NewExclude takes an ExcludeMode and Vector, validates the ExcludeMode and returns a pointer to a
 // new instance of Exclude and any errors encountered.
 func NewExclude(mode pb.Matrix_Exclude_Mode, vec *Vector) (*Exclude, error) {
@@ -80,12 +90,35 @@ func NewVector(elms ...Element) *Vector {
 func NewVectorFromProto(pbv *pb.Matrix_Vector) *Vector {
 	v := NewVector()
 	for _, elm := range pbv.GetElements() {
-		v.Add(NewElement(elm.GetKey(), elm.GetValue()))
+		v.Add(NewElementFromProto(elm))
 	}
 
 	return v
 }
 
+// NewElementFromProto takes a proto matrix element and returns a new Element with a value of its
+// type.
+func NewElementFromProto(pbe *pb.Matrix_Element) Element {
+	switch pbe.GetType() {
+	case pb.Matrix_Element_TYPE_NUMBER:
+		if num, err := cty.ParseNumberVal(pbe.GetValue()); err == nil {
+			return NewElementWithValue(pbe.GetKey(), pbe.GetValue(), num)
+		}
+	case pb.Matrix_Element_TYPE_BOOL:
+		switch pbe.GetValue() {
+		case "true":
+			return NewElementWithValue(pbe.GetKey(), pbe.GetValue(), cty.True)
+		case "false":
+			return NewElementWithValue(pbe.GetKey(), pbe.GetValue(), cty.False)
+		default:
+		}
+	case pb.Matrix_Element_TYPE_UNSPECIFIED, pb.Matrix_Element_TYPE_STRING:
+	default:
+	}
+
+	return NewElement(pbe.GetKey(), pbe.GetValue())
+}
+
 // String returns the element as a string.
 func (e Element) String() string {
 	// Matrix and vector comparison operations often required the element as
@@ -101,7 +134,24 @@ func (e Element) String() string {
 
 // Proto returns the element as a proto message.
 func (e Element) Proto() *pb.Matrix_Element {
-	return &pb.Matrix_Element{Key: e.Key, Value: e.Val}
+	return &pb.Matrix_Element{Key: e.Key, Value: e.Val, Type: e.protoType()}
+}
+
+// protoType returns the proto type of the element value. Structured values are identified by
+// their keys, which are strings.
+func (e Element) protoType() pb.Matrix_Element_Type {
+	if e.Value.IsNull() {
+		return pb.Matrix_Element_TYPE_UNSPECIFIED
+	}
+
+	switch {
+	case e.Value.Type().Equals(cty.Number):
+		return pb.Matrix_Element_TYPE_NUMBER
+	case e.Value.Type().Equals(cty.Bool):
+		return pb.Matrix_Element_TYPE_BOOL
+	default:
+		return pb.Matrix_Element_TYPE_UNSPECIFIED
+	}
 }
 
 // Equals compares the element with another Element.
@@ -236,6 +286,7 @@ func (v *Vector) ContainsUnordered(other *Vector) bool {
 }
 
 // CtyVal returns the vector as a cty.Value. This is lossy as duplicate keys will be overwritten.
+// Elements with structured values are their structured values.
 func (v *Vector) CtyVal() cty.Value {
 	if v == nil {
 		return cty.NilVal
@@ -243,6 +294,11 @@ func (v *Vector) CtyVal() cty.Value {
 
 	vals := map[string]cty.Value{}
 	for _, vec := range v.elements {
+		if !vec.Value.IsNull() {
+			vals[vec.Key] = vec.Value
+
+			continue
+		}
 		vals[vec.Key] = cty.StringVal(vec.Val)
 	}
 
@@ -272,7 +328,7 @@ func (v *Vector) Equal(other *Vector) bool {
 	}
 
 	for i := range v.elements {
-		if v.elements[i] != other.elements[i] {
+		if !v.elements[i].Equal(other.elements[i]) {
 			return false
 		}
 	}
@@ -347,10 +403,7 @@ func (v *Vector) Proto() *pb.Matrix_Vector {
 	}
 
 	for _, elm := range v.elements {
-		pbv.Elements = append(pbv.GetElements(), &pb.Matrix_Element{
-			Key:   elm.Key,
-			Value: elm.Val,
-		})
+		pbv.Elements = append(pbv.GetElements(), elm.Proto())
 	}
 
 	return pbv
@@ -428,57 +481,13 @@ func (m *Matrix) GetVectors() []*Vector {
 
 // CartesianProduct returns a pointer to a new Matrix whose Vectors are the
 // Cartesian product of combining all possible Vector Elements from the Matrix.
+// Use CartesianProductIter to lazily iterate over large products.
 func (m *Matrix) CartesianProduct() *Matrix {
 	if m == nil {
 		return nil
 	}
 
-	product := NewMatrix()
-	vlen := len(m.Vectors)
-	if vlen == 0 {
-		return product
-	}
-	// vecIdx is where we'll keep track the Element index for each Vector.
-	vecIdx := make([]int, vlen)
-
-	for {
-		// Create our next product Vector by reading our Element index address
-		// for each Vector in our vector index.
-		vec := NewVector()
-		for i := range vlen {
-			vec.Add(m.Vectors[i].elements[vecIdx[i]])
-		}
-		product.Vectors = append(product.Vectors, vec)
-
-		// Starting from the last Vector in the Matrix, walk backwards until
-		// we find a Vector's whose element index can be incremented.
-		next := vlen - 1
-		for {
-			if next >= 0 && (vecIdx[next]+1 >= len(m.Vectors[next].elements)) {
-				// We can't increment this Vector, keep walking back
-				next = next - 1
-			} else {
-				// We found a Vector index to increment or we've exhausted our
-				// search for a Vector that can be incremented.
-				break
-			}
-		}
-
-		// We walked back past the first Vector. We're done.
-		if next < 0 {
-			break
-		}
-
-		// Increment the Element index for the Vector we walked back to.
-		vecIdx[next]++
-
-		// Reset all Element indices in Vectors past our walked to Vector.
-		for i := next + 1; i < vlen; i++ {
-			vecIdx[i] = 0
-		}
-	}
-
-	return product
+	return m.CartesianProductIter().Collect()
 }
 
 // Compact removes duplicate Vectors from a Matrix.
@@ -621,10 +630,22 @@ func (m *Matrix) Filter(filter *ScenarioFilter) *Matrix {
 		return m
 	}
 
-	if filter.SelectAll {
+	if filter.SelectAll && !filter.hasSetOperations() {
 		return m.Copy()
 	}
 
+	// Filters that are combined with other filters are matched one vector at a time
+	if filter.hasSetOperations() {
+		nm := NewMatrix()
+		for _, vec := range m.GetVectors() {
+			if filter.matchScenarioVector("", vec) {
+				nm.AddVector(vec)
+			}
+		}
+
+		return nm
+	}
+
 	var nm *Matrix
 	if filter.Include != nil && len(filter.Include.elements) > 0 {
 		// If we have an include filter we'll generate a new sub-matrix with matching vectors
@@ -7,25 +7,91 @@ import (
 	"cmp"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
 
+	"github.com/hashicorp/enos/internal/flightplan/funcs"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	hcl "github.com/hashicorp/hcl/v2"
 )
 
-type matrixDecoder struct{}
+// matrixIncludeExcludeSchema are the blocks of a matrix block. The rest of its attributes are
+// variants.
+var matrixIncludeExcludeSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeMatrixInclude},
+		{Type: blockTypeMatrixExclude},
+		{Type: blockTypeMatrixKeys},
+		{Type: blockTypeMatrixConstraints},
+		{Type: blockTypeMatrixSharedExcludes},
+	},
+}
+
+// matrixConstraintsSchema is the schema of the constraints block of a matrix block. Its excludes
+// are a map of variants to the variants that they exclude, e.g. { "edition:ce" = ["fips:true"] }.
+var matrixConstraintsSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "excludes", Required: true},
+	},
+}
+
+// matrixKeysSchema is the schema of the keys block of a matrix block.
+var matrixKeysSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeMatrixKeys},
+	},
+}
+
+// matrixDefaultKey is the attribute of structured variant values that identifies them if their
+// dimension doesn't declare a key.
+const matrixDefaultKey = "name"
+
+// matrixFunctions returns the functions of the eval context of a matrix block, which are the
+// functions of the parent context and the matrix construction functions.
+func matrixFunctions(parent map[string]function.Function) map[string]function.Function {
+	fns := map[string]function.Function{
+		"crossproduct": funcs.CrossProductFunc,
+		"semverrange":  funcs.SemverRangeFunc,
+		"zip":          funcs.ZipFunc,
+	}
+	for name, fn := range parent {
+		fns[name] = fn
+	}
+
+	return fns
+}
+
+type matrixDecoder struct {
+	filter   *ScenarioFilter
+	maxBytes uint64 // a hint of how much memory the final product may use, zero is unlimited
+	// keys are the declared keys of dimensions with structured values and values are those
+	// structured values keyed by dimension and the value of their key. They allow includes and
+	// excludes to refer to structured values by their keys.
+	keys   map[string]string
+	values map[string]map[string]cty.Value
+	// visited is the number of product vectors that have been created while expanding matrices
+	visited int
+}
 
 // MatrixBlock represent a full "matrix" block at various stages.
 type MatrixBlock struct {
 	Original        *Matrix
 	IncludeProducts []*Matrix
 	Excludes        []*Exclude
+	Constraints     []*Exclude // constraints apply to the original and every include
 	FinalProduct    *Matrix
 }
 
-func newMatrixDecoder() *matrixDecoder {
-	return &matrixDecoder{}
+func newMatrixDecoder(filter *ScenarioFilter, maxBytes uint64) *matrixDecoder {
+	return &matrixDecoder{
+		filter:   filter,
+		maxBytes: maxBytes,
+		keys:     map[string]string{},
+		values:   map[string]map[string]cty.Value{},
+	}
 }
 
 func (d *MatrixBlock) Matrix() *Matrix {
@@ -92,12 +158,19 @@ func (d *MatrixBlock) Set(m *Matrix) {
 // decodeMatrix takes an eval context and scenario blocks and decodes only the matrix block.
 // As the matrix block can contain variants, includes, and excludes, the response will contain
 // the original variants as a Matrix with those vectors, the decoded includes, decoded excludes
-// and the final cartesian product of unique values.
+// and the final cartesian product of unique values. If the decoder has a scenario filter it will
+// be applied while building the final product so that we never create vectors that we'd
+// immediately discard.
 func (md *matrixDecoder) decodeMatrix(
 	ctx *hcl.EvalContext,
 	block *hcl.Block,
 ) (*MatrixBlock, hcl.Diagnostics) {
 	diags := hcl.Diagnostics{}
+	scenario := ""
+	if block.Type == blockTypeScenario && len(block.Labels) > 0 {
+		scenario = block.Labels[0]
+	}
+
 	mContent, _, moreDiags := block.Body.PartialContent(matrixSchema)
 	diags = diags.Extend(moreDiags)
 	if moreDiags != nil && moreDiags.HasErrors() {
@@ -126,13 +199,22 @@ func (md *matrixDecoder) decodeMatrix(
 	block = mBlocks[0]
 
 	// We'll use our own copy of the eval context so that we can be sure our 'matrix' eval context
-	// doesn't leak out.
+	// doesn't leak out. It also has our matrix construction functions, while every other function
+	// is resolved from the parent contexts.
 	evalCtx := ctx.NewChild()
 	evalCtx.Variables = ctx.Variables
-	evalCtx.Functions = ctx.Functions
+	evalCtx.Functions = matrixFunctions(ctx.Functions)
+
+	// Structured variant values are identified by their declared keys, which we need before we
+	// can decode the variants.
+	moreDiags = md.decodeMatrixKeys(evalCtx, block.Body)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return nil, diags
+	}
 
 	// Each attribute in the matrix should be a variant name whose value must
-	// be a list of strings. Convert the value into a matrix vector and add it.
+	// be a list of strings or objects. Convert the value into a matrix vector and add it.
 	matrix, moreDiags := md.decodeAndVerifyMatrixBlock(evalCtx, block.Body, false)
 	diags = diags.Extend(moreDiags)
 	if moreDiags != nil && moreDiags.HasErrors() {
@@ -140,29 +222,33 @@ func (md *matrixDecoder) decodeMatrix(
 	}
 	res := &MatrixBlock{Original: matrix}
 
-	// Now that we have our basic variant vectors in our matrix, we need to combine
-	// all vectors into a product that matches all possible unique value combinations.
-	res.FinalProduct = res.Original.CartesianProduct().UniqueValues()
-
 	// Now we need to go through all of our blocks and process include and exclude
 	// directives. Since HCL allows us to use ordering we'll apply them in the
-	// order in which they're defined.
-	blockC, remain, moreDiags := block.Body.PartialContent(&hcl.BodySchema{
-		Blocks: []hcl.BlockHeaderSchema{
-			{Type: blockTypeMatrixInclude},
-			{Type: blockTypeMatrixExclude},
-		},
-	})
+	// order in which they're defined. Rather than materializing the entire product
+	// and then removing excluded vectors, we keep track the excludes that follow each
+	// include so that every product can be lazily iterated with only the excludes
+	// that apply to it.
+	includes := []*Matrix{}
+	includeExcludeIdx := []int{}
+	blockC, remain, moreDiags := block.Body.PartialContent(matrixIncludeExcludeSchema)
 	diags = diags.Extend(moreDiags)
 	if moreDiags != nil && moreDiags.HasErrors() {
 		return nil, diags
 	}
 	diags = diags.Extend(verifyBodyOnlyHasBlocksWithLabels(
-		remain, blockTypeMatrixInclude, blockTypeMatrixExclude,
+		remain, blockTypeMatrixInclude, blockTypeMatrixExclude, blockTypeMatrixKeys,
+		blockTypeMatrixConstraints, blockTypeMatrixSharedExcludes,
 	))
 
+	constraints := []*hcl.Block{}
 	for _, mBlock := range blockC.Blocks {
 		switch mBlock.Type {
+		case blockTypeMatrixKeys:
+			// The keys have already been decoded
+			continue
+		case blockTypeMatrixConstraints:
+			// Constraints can refer to variants of any include so we decode them afterwards
+			constraints = append(constraints, mBlock)
 		case "include":
 			iMatrix, moreDiags := md.decodeAndVerifyMatrixBlock(evalCtx, mBlock.Body, true)
 			diags = diags.Extend(moreDiags)
@@ -170,13 +256,11 @@ func (md *matrixDecoder) decodeMatrix(
 				continue
 			}
 
-			// Generate our possible include vectors and add them to our main matrix.
-			iMatrixProduct := iMatrix.CartesianProduct().UniqueValues()
-			res.IncludeProducts = append(res.IncludeProducts, iMatrixProduct)
-
-			for _, vec := range iMatrixProduct.GetVectors() {
-				res.FinalProduct.AddVectorSorted(vec)
-			}
+			// Generate our possible include vectors. Only excludes that are defined after this
+			// include will apply to them.
+			res.IncludeProducts = append(res.IncludeProducts, iMatrix.CartesianProduct().UniqueValues())
+			includes = append(includes, iMatrix)
+			includeExcludeIdx = append(includeExcludeIdx, len(res.Excludes))
 		case "exclude":
 			eMatrix, moreDiags := md.decodeAndVerifyMatrixBlock(evalCtx, mBlock.Body, true)
 			diags = diags.Extend(moreDiags)
@@ -201,9 +285,14 @@ func (md *matrixDecoder) decodeMatrix(
 				excludes = append(excludes, ex)
 			}
 			res.Excludes = append(res.Excludes, excludes...)
-
-			// Update our matrix to a copy which has vectors which match our exclusions
-			res.FinalProduct = res.FinalProduct.Exclude(excludes...)
+		case blockTypeMatrixSharedExcludes:
+			// Shared excludes apply in the order in which they're defined, just like excludes
+			excludes, moreDiags := decodeMatrixSharedExcludes(evalCtx, mBlock)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+			res.Excludes = append(res.Excludes, excludes...)
 		default:
 			diags = diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -217,6 +306,69 @@ func (md *matrixDecoder) decodeMatrix(
 		}
 	}
 
+	res.Constraints, moreDiags = md.decodeMatrixConstraints(evalCtx, constraints, res)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	// Now that we have our basic variant vectors, includes, and excludes, we need to
+	// combine all vectors into a product that matches all possible unique value
+	// combinations that also match our filter.
+	res.FinalProduct = NewMatrix()
+	productBytes := uint64(0)
+	addVectors := func(iter *VectorIterator) hcl.Diagnostics {
+		for iter.Next() {
+			vec := iter.Vector()
+			productBytes += vectorBytes(vec)
+			if md.maxBytes > 0 && productBytes > md.maxBytes {
+				return hcl.Diagnostics{{
+					Severity: hcl.DiagError,
+					Summary:  "matrix expansion exceeds the memory limit",
+					Detail: fmt.Sprintf(
+						"expanding the matrix would use more than the %d byte memory hint after %d vectors, use a scenario filter to expand fewer variants or raise the limit",
+						md.maxBytes, len(res.FinalProduct.Vectors),
+					),
+					Subject: block.DefRange.Ptr(),
+				}}
+			}
+			res.FinalProduct.AddVector(vec)
+		}
+		md.visited += iter.Visited()
+
+		return nil
+	}
+
+	// Only expand the values of each dimension that the filter allows. The filter still has to match
+	// each vector of the product but this way we never create vectors that it excludes outright.
+	moreDiags = addVectors(md.filter.pruneMatrix(scenario, res.Original).CartesianProductIter().
+		Exclude(slices.Concat(res.Constraints, res.Excludes)...).
+		FilterScenario(scenario, md.filter).
+		UniqueValues(),
+	)
+	for i := range includes {
+		if moreDiags.HasErrors() {
+			break
+		}
+		moreDiags = addVectors(md.filter.pruneMatrix(scenario, includes[i]).CartesianProductIter().
+			Exclude(slices.Concat(res.Constraints, res.Excludes[includeExcludeIdx[i]:])...).
+			FilterScenario(scenario, md.filter).
+			UniqueValues(),
+		)
+	}
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	// A filter that doesn't match any vectors results in no matrix, just as it would have had we
+	// filtered the entire product with Matrix.Filter().
+	if !md.filter.selectsAllWithoutSetOperations() && len(res.FinalProduct.Vectors) == 0 {
+		res.FinalProduct = nil
+
+		return res, diags
+	}
+
 	// Return our matrix but do one final pass removing any duplicates that might
 	// have been introduced during our inclusions.
 	res.FinalProduct = res.FinalProduct.UniqueValues()
@@ -225,6 +377,178 @@ func (md *matrixDecoder) decodeMatrix(
 	return res, diags
 }
 
+// decodeMatrixConstraints decodes the constraints block of a matrix block, if it has one, into
+// excludes. Each variant that another variant excludes can't be in a vector with it, which is
+// symmetric, so "edition:ce" excluding "fips:true" also means that "fips:true" excludes
+// "edition:ce". The variants must be variants of the matrix or of its includes.
+func (md *matrixDecoder) decodeMatrixConstraints(
+	ctx *hcl.EvalContext,
+	blocks []*hcl.Block,
+	mb *MatrixBlock,
+) ([]*Exclude, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	excludes := []*Exclude{}
+
+	if len(blocks) == 0 {
+		return excludes, diags
+	}
+
+	if len(blocks) > 1 {
+		return excludes, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "matrix has more than one constraints block defined",
+			Detail:   "a single constraints block can be set in a matrix block",
+			Subject:  blocks[1].TypeRange.Ptr(),
+			Context:  blocks[1].DefRange.Ptr(),
+		})
+	}
+
+	content, moreDiags := blocks[0].Body.Content(matrixConstraintsSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return excludes, diags
+	}
+
+	attr := content.Attributes["excludes"]
+	val, moreDiags := attr.Expr.Value(ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return excludes, diags
+	}
+
+	invalid := func(detail string) hcl.Diagnostics {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid matrix constraint",
+			Detail:   detail,
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+	}
+
+	if !val.IsWhollyKnown() || val.IsNull() || !(val.Type().IsObjectType() || val.Type().IsMapType()) {
+		return excludes, invalid("constraint excludes must be a map of variants to the lists of variants that they exclude")
+	}
+
+	// Every variant of the matrix and its includes that a constraint can refer to.
+	variants := map[string]struct{}{}
+	for _, matrix := range append([]*Matrix{mb.Original}, mb.IncludeProducts...) {
+		for _, vec := range matrix.GetVectors() {
+			for _, elm := range vec.Elements() {
+				variants[elm.String()] = struct{}{}
+			}
+		}
+	}
+
+	parse := func(variant string) (Element, hcl.Diagnostics) {
+		key, val, ok := strings.Cut(variant, ":")
+		if !ok || key == "" || val == "" {
+			return Element{}, invalid(fmt.Sprintf("constraint variants must be key:value, found %s", variant))
+		}
+
+		if _, ok := variants[variant]; !ok {
+			return Element{}, invalid(fmt.Sprintf("%s is not a variant of the matrix", variant))
+		}
+
+		return NewElement(key, val), nil
+	}
+
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		elm, moreDiags := parse(k.AsString())
+		if moreDiags.HasErrors() {
+			return excludes, moreDiags
+		}
+
+		others, err := convert.Convert(v, cty.List(cty.String))
+		if err != nil || others.IsNull() {
+			return excludes, invalid(fmt.Sprintf("the variants that %s excludes must be a list of strings", k.AsString()))
+		}
+
+		for _, other := range others.AsValueSlice() {
+			if other.IsNull() {
+				return excludes, invalid(fmt.Sprintf("the variants that %s excludes cannot be null", k.AsString()))
+			}
+
+			otherElm, moreDiags := parse(other.AsString())
+			if moreDiags.HasErrors() {
+				return excludes, moreDiags
+			}
+
+			if otherElm.Key == elm.Key {
+				return excludes, invalid(fmt.Sprintf(
+					"%s cannot exclude %s as variants always exclude the other values of their dimension",
+					elm.String(), otherElm.String(),
+				))
+			}
+
+			ex, err := NewExclude(pb.Matrix_Exclude_MODE_CONTAINS, NewVector(elm, otherElm))
+			if err != nil {
+				return excludes, invalid(err.Error())
+			}
+			excludes = append(excludes, ex)
+		}
+	}
+
+	return excludes, diags
+}
+
+// decodeMatrixKeys decodes the keys block of a matrix block, if it has one. Each attribute is a
+// dimension whose value is the name of the attribute that identifies its structured values.
+func (md *matrixDecoder) decodeMatrixKeys(ctx *hcl.EvalContext, body hcl.Body) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	content, _, moreDiags := body.PartialContent(matrixKeysSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	for i, block := range content.Blocks.OfType(blockTypeMatrixKeys) {
+		if i > 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "matrix has more than one keys block defined",
+				Detail:   "a single keys block can be set in a matrix block",
+				Subject:  block.TypeRange.Ptr(),
+				Context:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+
+		attrs, moreDiags := block.Body.JustAttributes()
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		for _, attr := range md.sortAttributesByStartByte(attrs) {
+			val, moreDiags := attr.Expr.Value(ctx)
+			diags = diags.Extend(moreDiags)
+			if moreDiags != nil && moreDiags.HasErrors() {
+				continue
+			}
+
+			if !val.IsWhollyKnown() || val.IsNull() || !val.Type().Equals(cty.String) || val.AsString() == "" {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "invalid matrix key",
+					Detail:   "the key of matrix dimension " + attr.Name + " must be the name of an attribute of its values",
+					Subject:  attr.Expr.Range().Ptr(),
+					Context:  attr.Range.Ptr(),
+				})
+
+				continue
+			}
+
+			md.keys[attr.Name] = val.AsString()
+		}
+	}
+
+	return diags
+}
+
 // decodeAndVerifyMatrixBlock takes an HCL EvalContext, an HCL Block, and a boolean that determines
 // whether or not the block must include attributes only. It then decodes the blocks attributes as
 // if they are matrix vectors and returns a new matrix and any diagnostics. Only the initial
@@ -311,16 +635,38 @@ func (md *matrixDecoder) decodeMatrixAttribute(
 	vec := NewVector()
 
 	val, moreDiags := attr.Expr.Value(ctx)
-	diags = diags.Extend(moreDiags)
+	diags = diags.Extend(withQuoteIdentifierFixes(moreDiags))
 	if moreDiags != nil && moreDiags.HasErrors() {
 		return val, vec, diags
 	}
 
+	// Matrix values can be any expression of variables, globals, and functions, but we have to know
+	// the values before we can expand the matrix.
+	if val.Type().Equals(cty.NilType) || !val.IsWhollyKnown() {
+		return val, vec, diags.Append(unknownValueDiagnostic(
+			"matrix attribute value is unknown",
+			fmt.Sprintf(
+				"the value of %s must be known to expand the matrix, it can be any expression of variables, globals, and functions of known values",
+				attr.Name,
+			),
+			attr.Expr, ctx, attr.Expr.Range().Ptr(), attr.Range.Ptr(),
+		))
+	}
+
+	if val.IsNull() {
+		return val, vec, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "matrix attribute value cannot be null",
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+	}
+
 	if !val.CanIterateElements() {
 		return val, vec, diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
-			Summary:  "matrix attribute value must be a list of strings",
-			Detail:   fmt.Sprintf("expected value for %s to be a list of strings, found %s", attr.Name, val.Type().GoString()),
+			Summary:  "matrix attribute value must be a list",
+			Detail:   fmt.Sprintf("expected value for %s to be a list of strings, numbers, bools, or objects, found %s", attr.Name, val.Type().GoString()),
 			Subject:  attr.NameRange.Ptr(),
 			Context:  attr.Range.Ptr(),
 		})
@@ -335,19 +681,150 @@ func (md *matrixDecoder) decodeMatrixAttribute(
 		})
 	}
 
-	for _, elm := range val.AsValueSlice() {
-		if !elm.Type().Equals(cty.String) {
+	elms := val.AsValueSlice()
+	kind := matrixValueKind(elms[0])
+	for _, elm := range elms {
+		if elm.IsNull() {
 			return val, vec, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
-				Summary:  "matrix attribute value must be a list of strings",
-				Detail:   "found element with type " + elm.GoString(),
+				Summary:  "matrix attribute values cannot be null",
+				Detail:   fmt.Sprintf("the values of %s contain a null value", attr.Name),
+				Subject:  attr.Expr.Range().Ptr(),
+				Context:  attr.Range.Ptr(),
+			})
+		}
+
+		if elmKind := matrixValueKind(elm); elmKind != kind {
+			return val, vec, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "matrix attribute values must all have the same type",
+				Detail:   fmt.Sprintf("the values of %s mix %s and %s values", attr.Name, kind, elmKind),
 				Subject:  attr.NameRange.Ptr(),
 				Context:  attr.Range.Ptr(),
 			})
 		}
 
-		vec.Add(NewElement(attr.Name, elm.AsString()))
+		switch kind {
+		case "object":
+			elm, moreDiags := md.decodeStructuredMatrixValue(attr, elm)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				return val, vec, diags
+			}
+			vec.Add(elm)
+		case "number", "bool":
+			// Numbers and bools are identified by their string form but keep their type in the
+			// matrix eval context.
+			str, err := convert.Convert(elm, cty.String)
+			if err != nil {
+				return val, vec, diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "invalid matrix attribute value",
+					Detail:   fmt.Sprintf("unable to convert %s value %s to a string: %s", attr.Name, elm.GoString(), err),
+					Subject:  attr.NameRange.Ptr(),
+					Context:  attr.Range.Ptr(),
+				})
+			}
+			if md.values[attr.Name] == nil {
+				md.values[attr.Name] = map[string]cty.Value{}
+			}
+			md.values[attr.Name][str.AsString()] = elm
+			vec.Add(NewElementWithValue(attr.Name, str.AsString(), elm))
+		case "string":
+			// Includes and excludes can refer to structured, number, and bool values by their
+			// string keys.
+			if value, ok := md.values[attr.Name][elm.AsString()]; ok {
+				vec.Add(NewElementWithValue(attr.Name, elm.AsString(), value))
+
+				continue
+			}
+
+			vec.Add(NewElement(attr.Name, elm.AsString()))
+		default:
+			return val, vec, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "matrix attribute value must be a list of strings, numbers, bools, or objects",
+				Detail:   "found element with type " + elm.GoString(),
+				Subject:  attr.NameRange.Ptr(),
+				Context:  attr.Range.Ptr(),
+			})
+		}
 	}
 
 	return val, vec, diags
 }
+
+// decodeStructuredMatrixValue takes a matrix attribute and one of its object or map values and
+// returns it as an element that is identified by the value of the declared key of the dimension.
+// Keys must be unique within a dimension as they identify the values in filters and UIDs.
+func (md *matrixDecoder) decodeStructuredMatrixValue(
+	attr *hcl.Attribute,
+	val cty.Value,
+) (Element, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	key, ok := md.keys[attr.Name]
+	if !ok {
+		key = matrixDefaultKey
+	}
+
+	keyVal := cty.NullVal(cty.String)
+	switch {
+	case !val.IsWhollyKnown() || val.IsNull():
+	case val.Type().IsObjectType() && val.Type().HasAttribute(key):
+		keyVal = val.GetAttr(key)
+	case val.Type().IsMapType() && val.HasIndex(cty.StringVal(key)).True():
+		keyVal = val.Index(cty.StringVal(key))
+	}
+
+	keyVal, err := convert.Convert(keyVal, cty.String)
+	if err != nil || !keyVal.IsKnown() || keyVal.IsNull() || keyVal.AsString() == "" {
+		return Element{}, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "matrix attribute value is missing its key",
+			Detail: fmt.Sprintf(
+				"the values of %s must have a non-empty string %s attribute that identifies them, or declare another key in a keys block",
+				attr.Name, key,
+			),
+			Subject: attr.NameRange.Ptr(),
+			Context: attr.Range.Ptr(),
+		})
+	}
+
+	name := keyVal.AsString()
+	if prior, ok := md.values[attr.Name][name]; ok && !prior.RawEquals(val) {
+		return Element{}, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "matrix attribute values have duplicate keys",
+			Detail: fmt.Sprintf("more than one value of %s has the %s %s, keys must identify a single value",
+				attr.Name, key, name,
+			),
+			Subject: attr.NameRange.Ptr(),
+			Context: attr.Range.Ptr(),
+		})
+	}
+
+	if md.values[attr.Name] == nil {
+		md.values[attr.Name] = map[string]cty.Value{}
+	}
+	md.values[attr.Name][name] = val
+
+	return NewElementWithValue(attr.Name, name, val), diags
+}
+
+// matrixValueKind returns the kind of a value of a matrix attribute. Objects and maps are both
+// structured values, any other non-primitive type is an invalid value.
+func matrixValueKind(val cty.Value) string {
+	switch {
+	case val.Type().IsObjectType(), val.Type().IsMapType():
+		return "object"
+	case val.Type().Equals(cty.String):
+		return "string"
+	case val.Type().Equals(cty.Number):
+		return "number"
+	case val.Type().Equals(cty.Bool):
+		return "bool"
+	default:
+		return val.Type().FriendlyName()
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DiffScenarioBlocks takes the scenario blocks of a base flight plan and the scenario blocks of
+// another flight plan, whose matrices have been decoded, and returns the scenario variants that
+// were added, the scenario variants that were removed, and the number of scenario variants that
+// both of them have. Added and removed scenarios are sorted by their names and variants.
+func DiffScenarioBlocks(base ScenarioBlocks, blocks ScenarioBlocks) ([]*Scenario, []*Scenario, int) {
+	baseScenarios := scenarioBlocksVariants(base)
+	scenarios := scenarioBlocksVariants(blocks)
+
+	added := []*Scenario{}
+	unchanged := 0
+	for str, scenario := range scenarios {
+		if _, ok := baseScenarios[str]; ok {
+			unchanged++
+
+			continue
+		}
+		added = append(added, scenario)
+	}
+
+	removed := []*Scenario{}
+	for str, scenario := range baseScenarios {
+		if _, ok := scenarios[str]; !ok {
+			removed = append(removed, scenario)
+		}
+	}
+
+	sortScenarios := func(scenarios []*Scenario) {
+		slices.SortStableFunc(scenarios, func(a, b *Scenario) int {
+			return cmp.Compare(a.String(), b.String())
+		})
+	}
+	sortScenarios(added)
+	sortScenarios(removed)
+
+	return added, removed, unchanged
+}
+
+// scenarioBlocksVariants returns a scenario for each variant of the scenario blocks, or for the
+// scenario block itself if it doesn't have a matrix, keyed by their string representation.
+func scenarioBlocksVariants(blocks ScenarioBlocks) map[string]*Scenario {
+	res := map[string]*Scenario{}
+	for _, block := range blocks {
+		if block.Matrix() == nil || len(block.Matrix().GetVectors()) == 0 {
+			scenario := NewScenario()
+			scenario.Name = block.Name
+			res[scenario.String()] = scenario
+
+			continue
+		}
+
+		for _, vec := range block.Matrix().GetVectors() {
+			scenario := NewScenario()
+			scenario.Name = block.Name
+			scenario.Variants = vec
+			res[scenario.String()] = scenario
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_DiffScenarioBlocks tests comparing the scenario variants of two flight plans.
+func Test_DiffScenarioBlocks(t *testing.T) {
+	t.Parallel()
+
+	base, err := testDecodeHCL(t, []byte(`
+module "backend" {
+  source = "./backend"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch   = ["amd64", "arm64"]
+    distro = ["ubuntu", "rhel"]
+
+    exclude {
+      arch   = ["arm64"]
+      distro = ["rhel"]
+    }
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+
+scenario "smoke" {
+  step "first" {
+    module = module.backend
+  }
+}
+`), DecodeTargetScenariosNamesExpandVariants)
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(`
+module "backend" {
+  source = "./backend"
+}
+
+scenario "upgrade" {
+  matrix {
+    distro = ["ubuntu", "rhel", "amzn"]
+    arch   = ["amd64", "arm64"]
+
+    exclude {
+      distro = ["amzn"]
+      arch   = ["arm64"]
+    }
+
+    exclude {
+      distro = ["ubuntu"]
+      arch   = ["arm64"]
+    }
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+
+scenario "fresh_install" {
+  step "first" {
+    module = module.backend
+  }
+}
+`), DecodeTargetScenariosNamesExpandVariants)
+	require.NoError(t, err)
+
+	strs := func(scenarios []*Scenario) []string {
+		res := []string{}
+		for _, s := range scenarios {
+			res = append(res, s.String())
+		}
+
+		return res
+	}
+
+	added, removed, unchanged := DiffScenarioBlocks(base.ScenarioBlocks, fp.ScenarioBlocks)
+	require.Equal(t, []string{
+		"fresh_install",
+		"upgrade [arch:amd64 distro:amzn]",
+		"upgrade [arch:arm64 distro:rhel]",
+	}, strs(added))
+	require.Equal(t, []string{
+		"smoke",
+		"upgrade [arch:arm64 distro:ubuntu]",
+	}, strs(removed))
+	// Reordering the dimensions doesn't change the variants
+	require.Equal(t, 2, unchanged)
+
+	added, removed, unchanged = DiffScenarioBlocks(fp.ScenarioBlocks, fp.ScenarioBlocks)
+	require.Empty(t, added)
+	require.Empty(t, removed)
+	require.Equal(t, 5, unchanged)
+}
@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+const (
+	blockTypeMatrixExcludes       = "matrix_excludes"
+	blockTypeMatrixSharedExcludes = "shared_excludes"
+)
+
+// matrixExcludesSchema is the schema of matrix_excludes blocks.
+var matrixExcludesSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeMatrixExclude},
+	},
+}
+
+// matrixSharedExcludesSchema is the schema of the shared_excludes block of a matrix block.
+var matrixSharedExcludesSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "from", Required: true},
+	},
+}
+
+// MatrixExcludes are excludes that are defined once and shared by the matrices of scenarios and
+// sample subsets that refer to them in shared_excludes blocks.
+type MatrixExcludes struct {
+	Name     string
+	Excludes []*Exclude
+}
+
+// NewMatrixExcludes returns a new MatrixExcludes.
+func NewMatrixExcludes() *MatrixExcludes {
+	return &MatrixExcludes{Excludes: []*Exclude{}}
+}
+
+// decode takes an HCL block and an eval context and decodes itself from the block. Each exclude
+// block has the same body as the exclude blocks of matrices.
+func (m *MatrixExcludes) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	m.Name = block.Labels[0]
+
+	content, moreDiags := block.Body.Content(matrixExcludesSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	excludes := content.Blocks.OfType(blockTypeMatrixExclude)
+	if len(excludes) == 0 {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "matrix_excludes has no exclude blocks",
+			Detail:   fmt.Sprintf("matrix_excludes %s must have at least one exclude block", m.Name),
+			Subject:  block.DefRange.Ptr(),
+		})
+	}
+
+	md := newMatrixDecoder(nil, 0)
+	for _, eBlock := range excludes {
+		eMatrix, moreDiags := md.decodeAndVerifyMatrixBlock(ctx, eBlock.Body, true)
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		for _, vec := range eMatrix.CartesianProduct().UniqueValues().GetVectors() {
+			ex, err := NewExclude(pb.Matrix_Exclude_MODE_CONTAINS, vec)
+			if err != nil {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "unable to generate exclusion filter",
+					Detail:   err.Error(),
+					Subject:  eBlock.DefRange.Ptr(),
+				})
+
+				continue
+			}
+			m.Excludes = append(m.Excludes, ex)
+		}
+	}
+
+	return diags
+}
+
+// ToCtyValue returns the excludes as a list of the variants of each exclude, e.g.
+// [["arch:arm64", "distro:rhel"]], which is how shared_excludes blocks refer to them.
+func (m *MatrixExcludes) ToCtyValue() cty.Value {
+	excludes := []cty.Value{}
+	for _, ex := range m.Excludes {
+		elms := []cty.Value{}
+		for _, elm := range ex.Vector.Elements() {
+			elms = append(elms, cty.StringVal(elm.String()))
+		}
+		excludes = append(excludes, cty.ListVal(elms))
+	}
+
+	if len(excludes) == 0 {
+		return cty.ListValEmpty(cty.List(cty.String))
+	}
+
+	return cty.ListVal(excludes)
+}
+
+// decodeMatrixSharedExcludes decodes a shared_excludes block of a matrix block into excludes. Its
+// from attribute is a list of matrix_excludes, or of any other value with the same shape.
+func decodeMatrixSharedExcludes(ctx *hcl.EvalContext, block *hcl.Block) ([]*Exclude, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	excludes := []*Exclude{}
+
+	content, moreDiags := block.Body.Content(matrixSharedExcludesSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return excludes, diags
+	}
+
+	attr := content.Attributes["from"]
+	val, moreDiags := attr.Expr.Value(ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return excludes, diags
+	}
+
+	invalid := func(detail string) hcl.Diagnostics {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid matrix shared excludes",
+			Detail:   detail,
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+	}
+
+	from, err := convert.Convert(val, cty.List(cty.List(cty.List(cty.String))))
+	if err != nil || !from.IsWhollyKnown() || from.IsNull() {
+		return excludes, invalid("shared excludes must be a list of matrix_excludes, e.g. [matrix_excludes.unsupported]")
+	}
+
+	for _, shared := range from.AsValueSlice() {
+		if shared.IsNull() {
+			return excludes, invalid("shared excludes cannot be null")
+		}
+
+		for _, exclude := range shared.AsValueSlice() {
+			if exclude.IsNull() {
+				return excludes, invalid("shared excludes cannot be null")
+			}
+
+			if exclude.LengthInt() == 0 {
+				return excludes, invalid("shared excludes must have at least one variant")
+			}
+
+			vec := NewVector()
+			for _, variant := range exclude.AsValueSlice() {
+				if variant.IsNull() {
+					return excludes, invalid("shared exclude variants cannot be null")
+				}
+
+				key, val, ok := strings.Cut(variant.AsString(), ":")
+				if !ok || key == "" || val == "" {
+					return excludes, invalid(fmt.Sprintf(
+						"shared exclude variants must be key:value, found %s", variant.AsString(),
+					))
+				}
+				vec.Add(NewElement(key, val))
+			}
+
+			ex, err := NewExclude(pb.Matrix_Exclude_MODE_CONTAINS, vec)
+			if err != nil {
+				return excludes, invalid(err.Error())
+			}
+			excludes = append(excludes, ex)
+		}
+	}
+
+	return excludes, diags
+}
@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Decode_MatrixExcludes tests sharing matrix_excludes between the matrices of scenarios.
+func Test_Decode_MatrixExcludes(t *testing.T) {
+	t.Parallel()
+
+	fp, err := testDecodeHCL(t, []byte(`
+module "backend" {
+  source = "./backend"
+}
+
+globals {
+  unsupported_arch = "s390x"
+}
+
+matrix_excludes "unsupported" {
+  exclude {
+    arch   = ["arm64"]
+    distro = ["rhel"]
+  }
+
+  exclude {
+    arch = [global.unsupported_arch]
+  }
+}
+
+scenario "upgrade" {
+  matrix {
+    arch   = ["amd64", "arm64", "s390x"]
+    distro = ["ubuntu", "rhel"]
+
+    shared_excludes {
+      from = [matrix_excludes.unsupported]
+    }
+
+    // Includes that follow the shared excludes aren't excluded
+    include {
+      arch   = ["s390x"]
+      distro = ["ubuntu"]
+    }
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+
+scenario "smoke" {
+  matrix {
+    arch = ["amd64", "arm64", "s390x"]
+
+    shared_excludes {
+      from = [matrix_excludes.unsupported, [["arch:amd64"]]]
+    }
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+`), DecodeTargetScenariosNamesExpandVariants)
+	require.NoError(t, err)
+	require.Len(t, fp.MatrixExcludes, 1)
+	require.Equal(t, "unsupported", fp.MatrixExcludes[0].Name)
+	require.Len(t, fp.MatrixExcludes[0].Excludes, 2)
+
+	variants := map[string][]string{}
+	for _, sb := range fp.ScenarioBlocks {
+		for _, vec := range sb.MatrixBlock.Matrix().GetVectors() {
+			variants[sb.Name] = append(variants[sb.Name], vec.String())
+		}
+	}
+	require.ElementsMatch(t, []string{
+		"[arch:amd64 distro:ubuntu]",
+		"[arch:amd64 distro:rhel]",
+		"[arch:arm64 distro:ubuntu]",
+		"[arch:s390x distro:ubuntu]",
+	}, variants["upgrade"])
+	require.ElementsMatch(t, []string{"[arch:arm64]"}, variants["smoke"])
+
+	for desc, test := range map[string]struct {
+		hcl string
+		err string
+	}{
+		"undefined": {
+			hcl: `
+scenario "upgrade" {
+  matrix {
+    arch = ["amd64"]
+
+    shared_excludes {
+      from = [matrix_excludes.undefined]
+    }
+  }
+}`,
+			err: "Unsupported attribute",
+		},
+		"invalid variant": {
+			hcl: `
+scenario "upgrade" {
+  matrix {
+    arch = ["amd64"]
+
+    shared_excludes {
+      from = [[["arch"]]]
+    }
+  }
+}`,
+			err: "shared exclude variants must be key:value, found arch",
+		},
+		"no exclude blocks": {
+			hcl: `
+matrix_excludes "unsupported" {
+}`,
+			err: "matrix_excludes unsupported must have at least one exclude block",
+		},
+		"previously defined": {
+			hcl: `
+matrix_excludes "unsupported" {
+  exclude {
+    arch = ["arm64"]
+  }
+}
+
+matrix_excludes "unsupported" {
+  exclude {
+    arch = ["s390x"]
+  }
+}`,
+			err: "matrix_excludes unsupported has already been defined",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "./backend"
+}
+%s
+`, test.hcl)), DecodeTargetScenariosNamesExpandVariants)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"math"
+	"slices"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// VectorIterator lazily iterates over the Cartesian product of a Matrix. Product Vectors are only
+// created as the iterator is advanced and excluded or filtered Vectors are discarded immediately.
+// This allows us to filter and sample matrices with millions of theoretical combinations without
+// ever holding the entire product in memory.
+type VectorIterator struct {
+	vectors  []*Vector
+	vecIdx   []int
+	started  bool
+	done     bool
+	cur      *Vector
+	excludes []*Exclude
+	filters  []*ScenarioFilter
+	scenario string // the name of the scenario that filters match, if known
+	unique   bool
+	seen     *Matrix // sorted copies of the vectors we've returned, only used with unique
+	visited  int     // the number of product vectors that we've created
+}
+
+// CartesianProductIter returns a new VectorIterator that will lazily produce the Cartesian product
+// of combining all possible Vector Elements from the Matrix.
+func (m *Matrix) CartesianProductIter() *VectorIterator {
+	vi := &VectorIterator{}
+	if m == nil {
+		vi.done = true
+
+		return vi
+	}
+
+	for _, vec := range m.Vectors {
+		if vec == nil || len(vec.elements) == 0 {
+			// A product with an empty vector has no vectors.
+			vi.done = true
+
+			return vi
+		}
+	}
+
+	vi.vectors = m.Vectors
+	vi.vecIdx = make([]int, len(m.Vectors))
+	if len(vi.vectors) == 0 {
+		vi.done = true
+	}
+
+	return vi
+}
+
+// Exclude configures the iterator to skip any vectors that match any of the given excludes.
+func (vi *VectorIterator) Exclude(excludes ...*Exclude) *VectorIterator {
+	if vi == nil {
+		return nil
+	}
+
+	vi.excludes = append(vi.excludes, excludes...)
+
+	return vi
+}
+
+// Filter configures the iterator to only return vectors that match the scenario filter. Unlike
+// Matrix.Filter, only vectors that are a part of the product will ever be returned.
+func (vi *VectorIterator) Filter(filter *ScenarioFilter) *VectorIterator {
+	if vi == nil {
+		return nil
+	}
+
+	if filter != nil {
+		vi.filters = append(vi.filters, filter)
+	}
+
+	return vi
+}
+
+// FilterScenario configures the iterator to only return vectors of the named scenario that match
+// the scenario filter. Unlike Filter, filters that are combined with filters of other scenarios
+// will only match the vectors of those scenarios by name.
+func (vi *VectorIterator) FilterScenario(name string, filter *ScenarioFilter) *VectorIterator {
+	if vi == nil {
+		return nil
+	}
+
+	vi.scenario = name
+
+	return vi.Filter(filter)
+}
+
+// UniqueValues configures the iterator to only return vectors that have unique values.
+func (vi *VectorIterator) UniqueValues() *VectorIterator {
+	if vi == nil {
+		return nil
+	}
+
+	vi.unique = true
+	vi.seen = NewMatrix()
+
+	return vi
+}
+
+// Size returns the number of vectors in the product before any excludes or filters are applied.
+// If the size would overflow an int it returns math.MaxInt.
+func (vi *VectorIterator) Size() int {
+	if vi == nil || len(vi.vectors) == 0 {
+		return 0
+	}
+
+	size := 1
+	for _, vec := range vi.vectors {
+		l := len(vec.elements)
+		if l == 0 {
+			return 0
+		}
+		if size > math.MaxInt/l {
+			return math.MaxInt
+		}
+		size *= l
+	}
+
+	return size
+}
+
+// Visited returns the number of product vectors that the iterator has created so far, including
+// those that have been excluded or filtered.
+func (vi *VectorIterator) Visited() int {
+	if vi == nil {
+		return 0
+	}
+
+	return vi.visited
+}
+
+// Next advances the iterator to the next matching vector. It returns false when the product has
+// been exhausted.
+func (vi *VectorIterator) Next() bool {
+	if vi == nil {
+		return false
+	}
+
+	for {
+		if !vi.advance() {
+			vi.cur = nil
+
+			return false
+		}
+
+		vec := NewVector()
+		for i := range vi.vectors {
+			vec.Add(vi.vectors[i].elements[vi.vecIdx[i]])
+		}
+		vi.visited++
+
+		if !vi.match(vec) {
+			continue
+		}
+
+		if vi.unique {
+			sorted := vec.Copy()
+			sorted.Sort()
+			if vi.seen.HasVectorSorted(sorted) {
+				continue
+			}
+			vi.seen.AddVectorSorted(sorted)
+		}
+
+		vi.cur = vec
+
+		return true
+	}
+}
+
+// Vector returns the current vector.
+func (vi *VectorIterator) Vector() *Vector {
+	if vi == nil {
+		return nil
+	}
+
+	return vi.cur
+}
+
+// Collect exhausts the iterator and returns a new Matrix with all matching vectors.
+func (vi *VectorIterator) Collect() *Matrix {
+	m := NewMatrix()
+	for vi.Next() {
+		m.AddVector(vi.Vector())
+	}
+
+	return m
+}
+
+// advance moves our element indices to the next product vector.
+func (vi *VectorIterator) advance() bool {
+	if vi.done {
+		return false
+	}
+
+	if !vi.started {
+		vi.started = true
+
+		return true
+	}
+
+	// Starting from the last Vector in the Matrix, walk backwards until we find a Vector's whose
+	// element index can be incremented.
+	next := len(vi.vectors) - 1
+	for next >= 0 && vi.vecIdx[next]+1 >= len(vi.vectors[next].elements) {
+		next--
+	}
+
+	// We walked back past the first Vector. We're done.
+	if next < 0 {
+		vi.done = true
+
+		return false
+	}
+
+	// Increment the Element index for the Vector we walked back to and reset all Element indices
+	// in Vectors past it.
+	vi.vecIdx[next]++
+	for i := next + 1; i < len(vi.vectors); i++ {
+		vi.vecIdx[i] = 0
+	}
+
+	return true
+}
+
+// match determines whether or not a product vector passes our excludes and filters.
+func (vi *VectorIterator) match(vec *Vector) bool {
+	for _, ex := range vi.excludes {
+		if ex.Match(vec) {
+			return false
+		}
+	}
+
+	for _, filter := range vi.filters {
+		if !filter.matchScenarioVector(vi.scenario, vec) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchVector determines whether or not a single vector matches the filter.
+func (sf *ScenarioFilter) matchVector(vec *Vector) bool {
+	if sf == nil || sf.SelectAll {
+		return true
+	}
+
+	if sf.Include != nil && len(sf.Include.elements) > 0 && !vec.ContainsUnordered(sf.Include) {
+		return false
+	}
+
+	for _, ex := range sf.Exclude {
+		if ex.Match(vec) {
+			return false
+		}
+	}
+
+	if sf.IntersectionMatrix != nil && len(sf.IntersectionMatrix.Vectors) > 0 {
+		for _, iv := range sf.IntersectionMatrix.Vectors {
+			if vec.ContainsUnordered(iv) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// pruneMatrix returns a copy of the matrix whose dimensions only have the values that the filter
+// allows for vectors of the named scenario, so that products of the matrix never have vectors that
+// the filter would discard because of a single value. Filters that are combined with other filters
+// can match any value and don't prune the matrix.
+func (sf *ScenarioFilter) pruneMatrix(name string, m *Matrix) *Matrix {
+	if m == nil || sf == nil || sf.SelectAll || sf.hasSetOperations() {
+		return m
+	}
+
+	// A matrix with an empty dimension has no product vectors
+	none := &Matrix{Vectors: []*Vector{NewVector()}}
+	if name != "" && sf.Name != "" && sf.Name != name {
+		return none
+	}
+
+	pruned := NewMatrix()
+	for _, dim := range m.Vectors {
+		vec := NewVector()
+		for _, elm := range dim.elements {
+			if sf.allowsElement(elm) {
+				vec.Add(elm)
+			}
+		}
+		if len(vec.elements) == 0 {
+			return none
+		}
+		pruned.AddVector(vec)
+	}
+
+	// Vectors must have every element of the include, which they can't without its dimension.
+	if sf.Include != nil {
+		for _, inc := range sf.Include.elements {
+			if !slices.ContainsFunc(m.Vectors, func(dim *Vector) bool {
+				return len(dim.elements) > 0 && dim.elements[0].Key == inc.Key
+			}) {
+				return none
+			}
+		}
+	}
+
+	return pruned
+}
+
+// allowsElement returns whether vectors with the element can match the filter, as far as that
+// can be determined by the element alone.
+func (sf *ScenarioFilter) allowsElement(elm Element) bool {
+	if sf.Include != nil {
+		for _, inc := range sf.Include.elements {
+			if inc.Key == elm.Key && !inc.Equal(elm) {
+				return false
+			}
+		}
+	}
+
+	for _, ex := range sf.Exclude {
+		if ex == nil || ex.Mode != pb.Matrix_Exclude_MODE_CONTAINS || ex.Vector == nil || len(ex.Vector.elements) != 1 {
+			continue
+		}
+
+		if ex.Vector.elements[0].Equal(elm) {
+			return false
+		}
+	}
+
+	return true
+}
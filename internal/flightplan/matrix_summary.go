@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// SummarizeMatrix takes a scenario block whose matrix has been decoded without a filter, the
+// filter, and the maximum number of vectors to preview, and returns the dimensions of the matrix
+// and how many vectors remain after its excludes and after the filter. The preview is an evenly
+// spaced sample of the filtered vectors so that it represents the whole matrix rather than only
+// the variants of the first dimension values.
+func SummarizeMatrix(block *ScenarioBlock, filter *ScenarioFilter, preview int) *pb.Matrix_Summary {
+	if block == nil {
+		return nil
+	}
+
+	res := &pb.Matrix_Summary{Scenario: block.Name}
+	mb := block.MatrixBlock
+	if mb == nil || mb.Original == nil {
+		return res
+	}
+
+	// The original matrix has a vector of elements for each dimension. Includes can add values
+	// and keys that the original matrix doesn't have so we add those after the original values.
+	dims := []*pb.Matrix_Dimension{}
+	addValue := func(elm Element) {
+		for _, dim := range dims {
+			if dim.GetKey() != elm.Key {
+				continue
+			}
+
+			for _, val := range dim.GetValues() {
+				if val == elm.Val {
+					return
+				}
+			}
+			dim.Values = append(dim.Values, elm.Val)
+
+			return
+		}
+		dims = append(dims, &pb.Matrix_Dimension{Key: elm.Key, Values: []string{elm.Val}})
+	}
+	for _, vec := range mb.Original.GetVectors() {
+		for _, elm := range vec.Elements() {
+			addValue(elm)
+		}
+	}
+	for _, product := range mb.IncludeProducts {
+		for _, vec := range product.GetVectors() {
+			for _, elm := range vec.Elements() {
+				addValue(elm)
+			}
+		}
+	}
+	res.Dimensions = dims
+	res.Vectors = int64(len(matrixBlockVectors(mb)))
+
+	filtered := []*Vector{}
+	for _, vec := range mb.FinalProduct.GetVectors() {
+		res.Excluded++
+		if filter.matchScenarioVector(block.Name, vec) {
+			filtered = append(filtered, vec)
+		}
+	}
+	res.Filtered = int64(len(filtered))
+
+	if preview <= 0 {
+		return res
+	}
+
+	if preview >= len(filtered) {
+		for _, vec := range filtered {
+			res.Preview = append(res.Preview, vec.Proto())
+		}
+
+		return res
+	}
+
+	for i := range preview {
+		res.Preview = append(res.Preview, filtered[i*len(filtered)/preview].Proto())
+	}
+
+	return res
+}
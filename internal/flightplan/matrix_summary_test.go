@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_SummarizeMatrix tests summarizing the dimensions and vector counts of a scenario matrix.
+func Test_SummarizeMatrix(t *testing.T) {
+	t.Parallel()
+
+	fp, err := testDecodeHCL(t, []byte(`
+module "backend" {
+  source = "./backend"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch   = ["amd64", "arm64"]
+    distro = ["ubuntu", "rhel", "amzn"]
+
+    exclude {
+      arch   = ["arm64"]
+      distro = ["amzn"]
+    }
+
+    include {
+      arch   = ["s390x"]
+      distro = ["rhel"]
+    }
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+`), DecodeTargetScenariosNamesExpandVariants)
+	require.NoError(t, err)
+	block, ok := fp.ScenarioBlocks.FindByName("upgrade")
+	require.True(t, ok)
+
+	dimensions := func(block *ScenarioBlock, preview int) map[string][]string {
+		res := map[string][]string{}
+		for _, dim := range SummarizeMatrix(block, nil, preview).GetDimensions() {
+			res[dim.GetKey()] = dim.GetValues()
+		}
+
+		return res
+	}
+	require.Equal(t, map[string][]string{
+		"arch":   {"amd64", "arm64", "s390x"},
+		"distro": {"ubuntu", "rhel", "amzn"},
+	}, dimensions(block, 0))
+
+	for desc, test := range map[string]struct {
+		filter   []string
+		preview  int
+		filtered int64
+		expected []string
+	}{
+		"no filter": {
+			preview:  2,
+			filtered: 6,
+			expected: []string{"[arch:amd64 distro:amzn]", "[arch:arm64 distro:rhel]"},
+		},
+		"filter": {
+			filter:   []string{"upgrade", "arch:arm64"},
+			preview:  10,
+			filtered: 2,
+			expected: []string{"[arch:arm64 distro:ubuntu]", "[arch:arm64 distro:rhel]"},
+		},
+		"no preview": {
+			filter:   []string{"upgrade", "distro:rhel"},
+			filtered: 3,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			var filter *ScenarioFilter
+			if test.filter != nil {
+				var err error
+				filter, err = NewScenarioFilter(WithScenarioFilterParse(test.filter))
+				require.NoError(t, err)
+			}
+
+			summary := SummarizeMatrix(block, filter, test.preview)
+			require.Equal(t, "upgrade", summary.GetScenario())
+			require.Equal(t, int64(7), summary.GetVectors())
+			require.Equal(t, int64(6), summary.GetExcluded())
+			require.Equal(t, test.filtered, summary.GetFiltered())
+
+			preview := []string{}
+			for _, vec := range summary.GetPreview() {
+				preview = append(preview, NewVectorFromProto(vec).String())
+			}
+			require.ElementsMatch(t, test.expected, preview)
+		})
+	}
+}
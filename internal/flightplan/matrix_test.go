@@ -13,6 +13,8 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
 )
 
 // Test_Decode_Scenario_Matrix tests decoding of a matrix in scenarios.
@@ -298,6 +300,670 @@ scenario "nighttime" {
 	}
 }
 
+// Test_Decode_Scenario_Matrix_filter tests that filtering while decoding the matrix block results
+// in the same product as filtering the entire product after decoding.
+func Test_Decode_Scenario_Matrix_filter(t *testing.T) {
+	t.Parallel()
+
+	f, diags := hclparse.NewParser().ParseHCL([]byte(`
+scenario "test" {
+  matrix {
+    arch    = ["amd64", "arm64"]
+    backend = ["raft", "consul"]
+    distro  = ["ubuntu", "rhel"]
+
+    exclude {
+      arch    = ["arm64"]
+      backend = ["consul"]
+    }
+
+    include {
+      arch    = ["s390x"]
+      backend = ["raft"]
+      distro  = ["ubuntu"]
+    }
+
+    exclude {
+      distro = ["rhel"]
+      arch   = ["amd64"]
+    }
+  }
+}`), "matrix-filter.hcl")
+	require.False(t, diags.HasErrors(), diags.Error())
+	content, diags := f.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeScenario, LabelNames: []string{"name"}}},
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+	block := content.Blocks[0]
+
+	unfiltered, diags := decodeMatrix(&hcl.EvalContext{}, block, nil, nil)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	for _, filter := range []string{
+		"test",
+		"test arch:amd64",
+		"test arch:s390x",
+		"test arch:arm64 distro:rhel",
+		"test !backend:raft",
+		"test backend:raft !distro:ubuntu",
+		"test arch:ppc64le",
+	} {
+		t.Run(filter, func(t *testing.T) {
+			t.Parallel()
+
+			sf, err := ParseScenarioFilter(strings.Split(filter, " "))
+			require.NoError(t, err)
+
+			filtered, diags := decodeMatrix(&hcl.EvalContext{}, block, sf, nil)
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			expected := unfiltered.FinalProduct.Filter(sf)
+			if expected == nil {
+				require.Nil(t, filtered.Matrix())
+
+				return
+			}
+			expected.Sort()
+			require.True(t, expected.Equal(filtered.Matrix()), filtered.Matrix().String())
+		})
+	}
+}
+
+// Test_Decode_Scenario_Matrix_filter_pruned tests that expanding a matrix with a filter only
+// visits the points of the product whose values the filter allows.
+func Test_Decode_Scenario_Matrix_filter_pruned(t *testing.T) {
+	t.Parallel()
+
+	f, diags := hclparse.NewParser().ParseHCL([]byte(`
+scenario "test" {
+  matrix {
+    arch    = ["amd64", "arm64", "s390x"]
+    backend = ["raft", "consul"]
+    distro  = ["ubuntu", "rhel", "sles", "amzn"]
+
+    include {
+      arch    = ["ppc64le"]
+      backend = ["raft"]
+      distro  = ["ubuntu", "rhel"]
+    }
+  }
+}`), "matrix-filter-pruned.hcl")
+	require.False(t, diags.HasErrors(), diags.Error())
+	content, diags := f.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeScenario, LabelNames: []string{"name"}}},
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+	block := content.Blocks[0]
+
+	for filter, test := range map[string]struct {
+		visited  int
+		vectors  int
+		noMatrix bool
+	}{
+		"test":                                        {visited: 26, vectors: 26},
+		"test arch:amd64":                             {visited: 8, vectors: 8},
+		"test arch:amd64 distro:rhel":                 {visited: 2, vectors: 2},
+		"test !distro:sles !distro:amzn":              {visited: 14, vectors: 14},
+		"test arch:ppc64le !backend:raft":             {visited: 0, noMatrix: true},
+		"test !arch:amd64 !arch:arm64 backend:raft":   {visited: 6, vectors: 6},
+		"test arch:amd64 backend:raft !distro:ubuntu": {visited: 3, vectors: 3},
+		"other": {visited: 0, noMatrix: true},
+	} {
+		t.Run(filter, func(t *testing.T) {
+			t.Parallel()
+
+			sf, err := ParseScenarioFilter(strings.Split(filter, " "))
+			require.NoError(t, err)
+
+			decoder := newMatrixDecoder(sf, 0)
+			res, diags := decoder.decodeMatrix(&hcl.EvalContext{}, block)
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.visited, decoder.visited)
+			if test.noMatrix {
+				require.Nil(t, res.Matrix())
+
+				return
+			}
+			require.Len(t, res.Matrix().GetVectors(), test.vectors)
+		})
+	}
+}
+
+// Test_Decode_Scenario_Matrix_limits tests that expanding a matrix fails when the product would
+// use more memory than our limit.
+func Test_Decode_Scenario_Matrix_limits(t *testing.T) {
+	t.Parallel()
+
+	f, diags := hclparse.NewParser().ParseHCL([]byte(`
+scenario "test" {
+  matrix {
+    arch    = ["amd64", "arm64"]
+    backend = ["raft", "consul"]
+    distro  = ["ubuntu", "rhel"]
+  }
+}`), "matrix-limits.hcl")
+	require.False(t, diags.HasErrors(), diags.Error())
+	content, diags := f.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeScenario, LabelNames: []string{"name"}}},
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+	block := content.Blocks[0]
+
+	unlimited, diags := decodeMatrix(&hcl.EvalContext{}, block, nil, &ResourceLimits{})
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.Len(t, unlimited.Matrix().GetVectors(), 8)
+
+	productBytes := uint64(0)
+	for _, vec := range unlimited.Matrix().GetVectors() {
+		productBytes += vectorBytes(vec)
+	}
+
+	limited, diags := decodeMatrix(&hcl.EvalContext{}, block, nil, &ResourceLimits{MaxMatrixBytes: productBytes})
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.True(t, unlimited.Matrix().Equal(limited.Matrix()))
+
+	_, diags = decodeMatrix(&hcl.EvalContext{}, block, nil, &ResourceLimits{MaxMatrixBytes: productBytes - 1})
+	require.True(t, diags.HasErrors())
+	require.Equal(t, "matrix expansion exceeds the memory limit", diags[0].Summary)
+}
+
+// Test_Decode_Scenario_Matrix_structured tests decoding matrix dimensions whose values are objects.
+func Test_Decode_Scenario_Matrix_structured(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "backend" {
+  matrix {
+    arch   = ["amd64", "arm64"]
+    distro = [
+      { name = "ubuntu", version = "22.04" },
+      { name = "rhel", version = "9.3" },
+    ]
+    edition = [
+      { id = "ce", license = false },
+      { id = "ent", license = true },
+    ]
+
+    keys {
+      edition = "id"
+    }
+
+    exclude {
+      arch    = ["arm64"]
+      distro  = ["rhel"]
+      edition = [{ id = "ce", license = false }]
+    }
+  }
+
+  xfail = matrix.distro.version == "9.3" && matrix.edition.license ? "rhel ${matrix.distro.version}" : null
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+	require.Len(t, fp.ScenarioBlocks[0].Scenarios, 7)
+
+	xfails := map[string]string{}
+	for _, scenario := range fp.ScenarioBlocks[0].Scenarios {
+		xfails[scenario.Variants.String()] = scenario.XFail
+	}
+	require.Equal(t, map[string]string{
+		"[arch:amd64 distro:ubuntu edition:ce]":  "",
+		"[arch:amd64 distro:ubuntu edition:ent]": "",
+		"[arch:amd64 distro:rhel edition:ce]":    "",
+		"[arch:amd64 distro:rhel edition:ent]":   "rhel 9.3",
+		"[arch:arm64 distro:ubuntu edition:ce]":  "",
+		"[arch:arm64 distro:ubuntu edition:ent]": "",
+		"[arch:arm64 distro:rhel edition:ent]":   "rhel 9.3",
+	}, xfails)
+
+	t.Run("filter and include by key", func(t *testing.T) {
+		t.Parallel()
+
+		f, diags := hclparse.NewParser().ParseHCL([]byte(`
+scenario "test" {
+  matrix {
+    distro = [
+      { name = "ubuntu", version = "22.04" },
+      { name = "rhel", version = "9.3" },
+    ]
+
+    include {
+      distro = ["ubuntu"]
+    }
+  }
+}`), "matrix-structured.hcl")
+		require.False(t, diags.HasErrors(), diags.Error())
+		content, diags := f.Body.Content(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeScenario, LabelNames: []string{"name"}}},
+		})
+		require.False(t, diags.HasErrors(), diags.Error())
+
+		sf, err := ParseScenarioFilter([]string{"test", "distro:rhel"})
+		require.NoError(t, err)
+		filtered, diags := decodeMatrix(&hcl.EvalContext{}, content.Blocks[0], sf, nil)
+		require.False(t, diags.HasErrors(), diags.Error())
+		require.Len(t, filtered.Matrix().GetVectors(), 1)
+		vec := filtered.Matrix().GetVectors()[0]
+		require.Equal(t, "[distro:rhel]", vec.String())
+		require.Equal(t, "9.3", vec.CtyVal().GetAttr("distro").GetAttr("version").AsString())
+
+		unfiltered, diags := decodeMatrix(&hcl.EvalContext{}, content.Blocks[0], nil, nil)
+		require.False(t, diags.HasErrors(), diags.Error())
+		require.Len(t, unfiltered.Matrix().GetVectors(), 2)
+		for _, vec := range unfiltered.Matrix().GetVectors() {
+			require.Equal(t,
+				vec.Elements()[0].Val,
+				vec.CtyVal().GetAttr("distro").GetAttr("name").AsString(),
+			)
+		}
+	})
+
+	for desc, test := range map[string]struct {
+		matrix string
+		err    string
+	}{
+		"mixed": {
+			matrix: `distro = ["ubuntu", { name = "rhel" }]`,
+			err:    "matrix attribute values must all have the same type",
+		},
+		"missing key": {
+			matrix: `distro = [{ version = "22.04" }]`,
+			err:    "matrix attribute value is missing its key",
+		},
+		"empty key": {
+			matrix: `distro = [{ name = "" }]`,
+			err:    "matrix attribute value is missing its key",
+		},
+		"duplicate keys": {
+			matrix: `distro = [{ name = "ubuntu", version = "22.04" }, { name = "ubuntu", version = "24.04" }]`,
+			err:    "matrix attribute values have duplicate keys",
+		},
+		"invalid key": {
+			matrix: `
+    distro = [{ name = "ubuntu" }]
+    keys {
+      distro = 1
+    }`,
+			err: "invalid matrix key",
+		},
+		"more than one keys block": {
+			matrix: `
+    distro = [{ id = "ubuntu" }]
+    keys {
+      distro = "id"
+    }
+    keys {
+      distro = "id"
+    }`,
+			err: "matrix has more than one keys block defined",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "backend" {
+  matrix {
+    %s
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath, test.matrix)), DecodeTargetAll)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+// Test_Decode_Scenario_Matrix_constraints tests that matrix constraints exclude the vectors that
+// combine variants that exclude each other.
+func Test_Decode_Scenario_Matrix_constraints(t *testing.T) {
+	t.Parallel()
+
+	fp, err := testDecodeHCL(t, []byte(`
+module "backend" {
+  source = "./backend"
+}
+
+scenario "backend" {
+  matrix {
+    edition = ["ce", "ent"]
+    fips    = [true, false]
+    arch    = ["amd64", "arm64"]
+
+    include {
+      edition = ["ce"]
+      fips    = [true]
+      arch    = ["s390x"]
+    }
+
+    constraints {
+      excludes = {
+        "fips:true"  = ["edition:ce"]
+        "arch:s390x" = ["edition:ent"]
+      }
+    }
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+`), DecodeTargetScenariosNamesExpandVariants)
+	require.NoError(t, err)
+
+	variants := []string{}
+	for _, vec := range fp.ScenarioBlocks[0].MatrixBlock.Matrix().GetVectors() {
+		variants = append(variants, vec.String())
+	}
+	require.ElementsMatch(t, []string{
+		"[arch:amd64 edition:ce fips:false]",
+		"[arch:arm64 edition:ce fips:false]",
+		"[arch:amd64 edition:ent fips:true]",
+		"[arch:amd64 edition:ent fips:false]",
+		"[arch:arm64 edition:ent fips:true]",
+		"[arch:arm64 edition:ent fips:false]",
+	}, variants)
+
+	reason, detail := explainVectorExclusion("backend", fp.ScenarioBlocks[0].MatrixBlock, nil,
+		NewVector(NewElement("edition", "ce"), NewElement("fips", "true"), NewElement("arch", "amd64")), false,
+	)
+	require.Equal(t, pb.Scenario_Filter_Explanation_REASON_MATRIX_EXCLUDE, reason)
+	require.Equal(t, "violates the matrix constraint fips:true excludes edition:ce", detail)
+
+	for desc, test := range map[string]struct {
+		constraints string
+		err         string
+	}{
+		"unknown variant": {
+			constraints: `
+    constraints {
+      excludes = { "edition:ce" = ["fips:maybe"] }
+    }`,
+			err: "fips:maybe is not a variant of the matrix",
+		},
+		"invalid variant": {
+			constraints: `
+    constraints {
+      excludes = { "edition" = ["fips:true"] }
+    }`,
+			err: "constraint variants must be key:value, found edition",
+		},
+		"same dimension": {
+			constraints: `
+    constraints {
+      excludes = { "edition:ce" = ["edition:ent"] }
+    }`,
+			err: "edition:ce cannot exclude edition:ent as variants always exclude the other values of their dimension",
+		},
+		"not a map": {
+			constraints: `
+    constraints {
+      excludes = ["edition:ce", "fips:true"]
+    }`,
+			err: "constraint excludes must be a map of variants to the lists of variants that they exclude",
+		},
+		"more than one constraints block": {
+			constraints: `
+    constraints {
+      excludes = { "edition:ce" = ["fips:true"] }
+    }
+    constraints {
+      excludes = { "edition:ce" = ["fips:true"] }
+    }`,
+			err: "matrix has more than one constraints block defined",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "./backend"
+}
+
+scenario "backend" {
+  matrix {
+    edition = ["ce", "ent"]
+    fips    = [true, false]
+    %s
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, test.constraints)), DecodeTargetScenariosNamesExpandVariants)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+// Test_Decode_Scenario_Matrix_types tests decoding matrix dimensions whose values are numbers and
+// bools.
+func Test_Decode_Scenario_Matrix_types(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "backend" {
+  matrix {
+    replicas = [1, 3]
+    fips     = [true, false]
+
+    exclude {
+      replicas = ["1"]
+      fips     = [true]
+    }
+
+    include {
+      replicas = [5]
+      fips     = ["true"]
+    }
+  }
+
+  xfail = matrix.replicas > 2 && matrix.fips ? "fips with ${matrix.replicas} replicas" : null
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+
+	xfails := map[string]string{}
+	for _, scenario := range fp.ScenarioBlocks[0].Scenarios {
+		xfails[scenario.Variants.String()] = scenario.XFail
+	}
+	require.Equal(t, map[string]string{
+		"[fips:false replicas:1]": "",
+		"[fips:false replicas:3]": "",
+		"[fips:true replicas:3]":  "fips with 3 replicas",
+		"[fips:true replicas:5]":  "fips with 5 replicas",
+	}, xfails)
+
+	f, diags := hclparse.NewParser().ParseHCL([]byte(`
+scenario "test" {
+  matrix {
+    replicas = [1, 3, 1.5]
+  }
+}`), "matrix-types.hcl")
+	require.False(t, diags.HasErrors(), diags.Error())
+	content, diags := f.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeScenario, LabelNames: []string{"name"}}},
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	sf, err := ParseScenarioFilter([]string{"test", "replicas:1.5"})
+	require.NoError(t, err)
+	filtered, diags := decodeMatrix(&hcl.EvalContext{}, content.Blocks[0], sf, nil)
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.Len(t, filtered.Matrix().GetVectors(), 1)
+	require.True(t, cty.NumberFloatVal(1.5).RawEquals(
+		filtered.Matrix().GetVectors()[0].CtyVal().GetAttr("replicas"),
+	))
+
+	_, err = testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "backend" {
+  matrix {
+    replicas = [1, "3"]
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.ErrorContains(t, err, "the values of replicas mix number and string values")
+}
+
+// Test_Decode_Scenario_Matrix_expressions tests decoding matrix dimensions whose values are
+// computed from variables, globals, and functions.
+func Test_Decode_Scenario_Matrix_expressions(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	flightPlan := func(matrix string) []byte {
+		return []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+variable "artifacts" {
+  type    = map(string)
+  default = { amd64 = "vault_amd64.zip", arm64 = "vault_arm64.zip" }
+}
+
+variable "editions" {
+  type = list(string)
+}
+
+variable "nothing" {
+  type    = list(string)
+  default = null
+}
+
+globals {
+  distros = ["ubuntu", "rhel", "ubuntu"]
+}
+
+scenario "backend" {
+  matrix {
+    %s
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath, matrix))
+	}
+
+	for desc, test := range map[string]struct {
+		matrix   string
+		env      []string
+		variants []string
+		err      string
+	}{
+		"keys of a variable": {
+			matrix:   `arch = keys(var.artifacts)`,
+			variants: []string{"[arch:amd64]", "[arch:arm64]"},
+		},
+		"globals and functions": {
+			matrix:   `distro = distinct(global.distros)`,
+			variants: []string{"[distro:rhel]", "[distro:ubuntu]"},
+		},
+		"for expression in includes": {
+			matrix: `
+    arch = ["amd64"]
+    include {
+      arch = [for a, _ in var.artifacts : a if a != "amd64"]
+    }`,
+			variants: []string{"[arch:amd64]", "[arch:arm64]"},
+		},
+		"semver range": {
+			matrix:   `version = semverrange("1.15.x", "1.17.x")`,
+			variants: []string{"[version:1.15.x]", "[version:1.16.x]", "[version:1.17.x]"},
+		},
+		"cross product": {
+			matrix: `
+    target = [for p in crossproduct(distinct(global.distros), keys(var.artifacts)) : {
+      name   = join("_", p)
+      distro = p[0]
+      arch   = p[1]
+    } if p[0] != "rhel"]`,
+			variants: []string{"[target:ubuntu_amd64]", "[target:ubuntu_arm64]"},
+		},
+		"zip": {
+			matrix:   `artifact = [for p in zip(keys(var.artifacts), values(var.artifacts)) : { name = p[0], file = p[1] }]`,
+			variants: []string{"[artifact:amd64]", "[artifact:arm64]"},
+		},
+		"variable set in the environment": {
+			matrix:   `edition = var.editions`,
+			env:      []string{`ENOS_VAR_editions=["ce", "ent"]`},
+			variants: []string{"[edition:ce]", "[edition:ent]"},
+		},
+		"unset variable": {
+			matrix: `edition = var.editions`,
+			err:    "matrix attribute value is unknown",
+		},
+		"null variable": {
+			matrix: `edition = var.nothing`,
+			err:    "matrix attribute value cannot be null",
+		},
+		"null element": {
+			matrix: `edition = ["ce", null]`,
+			err:    "matrix attribute values cannot be null",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			fp, err := testDecodeHCL(t, flightPlan(test.matrix), DecodeTargetAll, WithDecoderEnv(test.env))
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			variants := []string{}
+			for _, scenario := range fp.ScenarioBlocks[0].Scenarios {
+				variants = append(variants, scenario.Variants.String())
+			}
+			require.ElementsMatch(t, test.variants, variants)
+		})
+	}
+}
+
 func Test_Matrix_Vector_Equal(t *testing.T) {
 	t.Parallel()
 
@@ -479,6 +1145,85 @@ func Test_Matrix_CartesianProduct_empty_vector(t *testing.T) {
 	require.Equal(t, &Matrix{}, m.CartesianProduct())
 }
 
+// Test_Matrix_CartesianProductIter tests lazily iterating over a matrix product.
+func Test_Matrix_CartesianProductIter(t *testing.T) {
+	t.Parallel()
+
+	m := &Matrix{Vectors: []*Vector{
+		NewVector(NewElement("backend", "raft"), NewElement("backend", "consul")),
+		NewVector(NewElement("arch", "arm64"), NewElement("arch", "amd64")),
+	}}
+
+	t.Run("product", func(t *testing.T) {
+		t.Parallel()
+
+		iter := m.CartesianProductIter()
+		require.Equal(t, 4, iter.Size())
+		require.Equal(t, m.CartesianProduct().Vectors, iter.Collect().Vectors)
+		require.False(t, iter.Next())
+		require.Nil(t, iter.Vector())
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		t.Parallel()
+
+		ex, err := NewExclude(pb.Matrix_Exclude_MODE_CONTAINS, NewVector(NewElement("backend", "consul")))
+		require.NoError(t, err)
+		require.Equal(t, []*Vector{
+			NewVector(NewElement("backend", "raft"), NewElement("arch", "arm64")),
+			NewVector(NewElement("backend", "raft"), NewElement("arch", "amd64")),
+		}, m.CartesianProductIter().Exclude(ex).Collect().Vectors)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		t.Parallel()
+
+		ex, err := NewExclude(pb.Matrix_Exclude_MODE_CONTAINS, NewVector(NewElement("backend", "consul")))
+		require.NoError(t, err)
+		require.Equal(t, []*Vector{
+			NewVector(NewElement("backend", "raft"), NewElement("arch", "amd64")),
+		}, m.CartesianProductIter().Filter(&ScenarioFilter{
+			Include: NewVector(NewElement("arch", "amd64")),
+			Exclude: []*Exclude{ex},
+		}).Collect().Vectors)
+	})
+
+	t.Run("unique values", func(t *testing.T) {
+		t.Parallel()
+
+		dup := &Matrix{Vectors: []*Vector{
+			NewVector(NewElement("backend", "raft"), NewElement("backend", "raft")),
+			NewVector(NewElement("arch", "arm64")),
+		}}
+		require.Len(t, dup.CartesianProductIter().Collect().Vectors, 2)
+		require.Equal(t, []*Vector{
+			NewVector(NewElement("backend", "raft"), NewElement("arch", "arm64")),
+		}, dup.CartesianProductIter().UniqueValues().Collect().Vectors)
+	})
+
+	t.Run("large product", func(t *testing.T) {
+		t.Parallel()
+
+		// Ten billion theoretical vectors. We should be able to find our vectors without
+		// materializing the product.
+		large := NewMatrix()
+		for i := range 10 {
+			vec := NewVector()
+			for j := range 10 {
+				vec.Add(NewElement(fmt.Sprintf("variant%d", i), fmt.Sprintf("value%d", j)))
+			}
+			large.AddVector(vec)
+		}
+
+		iter := large.CartesianProductIter()
+		require.Equal(t, 10_000_000_000, iter.Size())
+		for range 3 {
+			require.True(t, iter.Next())
+		}
+		require.Equal(t, "value2", iter.Vector().Elements()[9].Val)
+	})
+}
+
 func Test_Matrix_UniqueValues(t *testing.T) {
 	t.Parallel()
 
@@ -570,6 +1315,43 @@ func Test_Matrix_Proto_RoundTrip(t *testing.T) {
 	require.EqualValues(t, expected, got)
 }
 
+// Test_Matrix_Proto_RoundTrip_types tests that number and bool element values keep their types
+// when they're serialized.
+func Test_Matrix_Proto_RoundTrip_types(t *testing.T) {
+	t.Parallel()
+
+	vec := NewVector(
+		NewElement("arch", "amd64"),
+		NewElementWithValue("replicas", "3", cty.NumberIntVal(3)),
+		NewElementWithValue("fips", "true", cty.True),
+		NewElementWithValue("distro", "ubuntu", cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal("ubuntu"),
+		})),
+	)
+
+	pbv := vec.Proto()
+	require.Equal(t, []pb.Matrix_Element_Type{
+		pb.Matrix_Element_TYPE_UNSPECIFIED,
+		pb.Matrix_Element_TYPE_NUMBER,
+		pb.Matrix_Element_TYPE_BOOL,
+		pb.Matrix_Element_TYPE_UNSPECIFIED,
+	}, []pb.Matrix_Element_Type{
+		pbv.GetElements()[0].GetType(),
+		pbv.GetElements()[1].GetType(),
+		pbv.GetElements()[2].GetType(),
+		pbv.GetElements()[3].GetType(),
+	})
+
+	got := NewVectorFromProto(pbv)
+	require.True(t, vec.Equal(got))
+	require.True(t, cty.ObjectVal(map[string]cty.Value{
+		"arch":     cty.StringVal("amd64"),
+		"replicas": cty.NumberIntVal(3),
+		"fips":     cty.True,
+		"distro":   cty.StringVal("ubuntu"),
+	}).Equals(got.CtyVal()).True(), got.CtyVal().GoString())
+}
+
 func Test_Matrix_Unique(t *testing.T) {
 	t.Parallel()
 
@@ -41,7 +41,7 @@ func (m *Module) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics
 	diags := hcl.Diagnostics{}
 
 	content, remain, moreDiags := block.Body.PartialContent(moduleSchema)
-	diags = diags.Extend(moreDiags)
+	diags = diags.Extend(withMissingAttributeFixes(moreDiags, block, "source"))
 	if moreDiags != nil && moreDiags.HasErrors() {
 		return diags
 	}
@@ -49,12 +49,12 @@ func (m *Module) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics
 	m.Name = block.Labels[0]
 	src, ok := content.Attributes["source"]
 	if !ok {
-		diags = diags.Append(&hcl.Diagnostic{
+		diags = diags.Append(withMissingAttributeFix(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "missing required module attribute source",
 			Detail:   "the 'source' attribute must be set for a module",
 			Subject:  block.Body.MissingItemRange().Ptr(),
-		})
+		}, block, "source"))
 	} else {
 		val, moreDiags := src.Expr.Value(ctx)
 		diags = diags.Extend(moreDiags)
@@ -75,16 +75,18 @@ func (m *Module) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics
 					Context:  hcl.RangeBetween(src.Expr.StartRange(), src.Expr.Range()).Ptr(),
 				})
 			} else {
-				diags = diags.Append(&hcl.Diagnostic{
+				diags = diags.Append(withQuoteLiteralFix(&hcl.Diagnostic{
 					Severity: hcl.DiagWarning,
 					Summary:  "invalid value",
 					Detail:   "source should be a string value, consider changing it",
 					Subject:  src.Expr.Range().Ptr(),
 					Context:  hcl.RangeBetween(src.Expr.StartRange(), src.Expr.Range()).Ptr(),
-				})
+				}, src.Expr, sourceVal))
 				m.Source = sourceVal.AsString()
 			}
 		}
+
+		diags = diags.Extend(verifyLocalModuleSourceExists(m, src, ctx))
 	}
 
 	// "version" isn't required attribute but it is allowed. Handle it manually.
@@ -109,13 +111,13 @@ func (m *Module) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics
 					Context:  hcl.RangeBetween(version.Expr.StartRange(), version.Expr.Range()).Ptr(),
 				})
 			} else {
-				diags = diags.Append(&hcl.Diagnostic{
+				diags = diags.Append(withQuoteLiteralFix(&hcl.Diagnostic{
 					Severity: hcl.DiagWarning,
 					Summary:  "invalid value",
 					Detail:   "version should be a string value, consider changing it",
 					Subject:  version.Expr.Range().Ptr(),
 					Context:  hcl.RangeBetween(version.Expr.StartRange(), version.Expr.Range()).Ptr(),
-				})
+				}, version.Expr, versionVal))
 				m.Version = versionVal.AsString()
 			}
 		}
@@ -0,0 +1,548 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	semver "github.com/Masterminds/semver/v3"
+)
+
+// ModuleSourceType is the type of a module source address.
+type ModuleSourceType int
+
+const (
+	// ModuleSourceTypeUnsupported are sources that we're unable to resolve, e.g. S3 buckets.
+	ModuleSourceTypeUnsupported ModuleSourceType = iota
+	// ModuleSourceTypeLocal are local paths.
+	ModuleSourceTypeLocal
+	// ModuleSourceTypeRegistry are module registry addresses.
+	ModuleSourceTypeRegistry
+	// ModuleSourceTypeGit are git repositories.
+	ModuleSourceTypeGit
+)
+
+const defaultModuleRegistryHost = "registry.terraform.io"
+
+var (
+	moduleRegistryPartRegexp = regexp.MustCompile(`^[0-9A-Za-z](?:[0-9A-Za-z-_]{0,62}[0-9A-Za-z])?$`)
+	gitCommitRegexp          = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+)
+
+// ModuleSource is a parsed module source address.
+type ModuleSource struct {
+	Type    ModuleSourceType
+	Source  string
+	Version string
+	// Subdir is the sub-directory of the module in the fetched source.
+	Subdir string
+	// Registry addresses
+	Host      string
+	Namespace string
+	Name      string
+	System    string
+	// Git repositories
+	URL string
+	Ref string
+}
+
+// ParseModuleSource parses a module source address and version. Only local paths, registry
+// addresses, and git repositories are fully parsed. Any other sources that Terraform supports are
+// returned as unsupported.
+func ParseModuleSource(source string, version string) (*ModuleSource, error) {
+	src := &ModuleSource{Source: source, Version: version}
+
+	switch {
+	case source == "":
+		return nil, errors.New("module source must not be empty")
+	case filepath.IsAbs(source), strings.HasPrefix(source, "./"), strings.HasPrefix(source, "../"):
+		src.Type = ModuleSourceTypeLocal
+
+		return src, nil
+	case strings.HasPrefix(source, "git::git@"):
+		return src, src.parseGit("ssh://" + strings.Replace(strings.TrimPrefix(source, "git::"), ":", "/", 1))
+	case strings.HasPrefix(source, "git::"):
+		return src, src.parseGit(strings.TrimPrefix(source, "git::"))
+	case strings.HasPrefix(source, "github.com/"):
+		return src, src.parseGit("https://" + source)
+	case strings.HasPrefix(source, "git@"):
+		return src, src.parseGit("ssh://" + strings.Replace(source, ":", "/", 1))
+	case strings.Contains(source, "::"), strings.Contains(source, "://"):
+		src.Type = ModuleSourceTypeUnsupported
+
+		return src, nil
+	default:
+	}
+
+	return src, src.parseRegistry()
+}
+
+// parseRegistry parses a [<hostname>/]<namespace>/<name>/<system>[//subdir] registry address.
+func (m *ModuleSource) parseRegistry() error {
+	m.Type = ModuleSourceTypeRegistry
+
+	addr, subdir, _ := strings.Cut(m.Source, "//")
+	m.Subdir = subdir
+
+	parts := strings.Split(addr, "/")
+	m.Host = defaultModuleRegistryHost
+	if len(parts) == 4 {
+		m.Host = parts[0]
+		parts = parts[1:]
+	}
+
+	if len(parts) != 3 {
+		return fmt.Errorf("module source %s is not a local path, a registry address, or a supported remote source", m.Source)
+	}
+
+	for _, part := range parts {
+		if !moduleRegistryPartRegexp.MatchString(part) {
+			return fmt.Errorf("module source %s is not a valid registry address, %q is not a valid name", m.Source, part)
+		}
+	}
+
+	m.Namespace, m.Name, m.System = parts[0], parts[1], parts[2]
+
+	if m.Version != "" {
+		if _, err := newVersionConstraint(m.Version); err != nil {
+			return fmt.Errorf("module source %s version %s is not a valid version constraint: %w", m.Source, m.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// parseGit parses a git repository address with an optional ref and sub-directory.
+func (m *ModuleSource) parseGit(addr string) error {
+	m.Type = ModuleSourceTypeGit
+
+	if m.Version != "" {
+		return fmt.Errorf("module source %s: version constraints are only supported for registry modules, use a ref instead", m.Source)
+	}
+
+	// The sub-directory comes after the first double slash that isn't part of the scheme.
+	scheme, rest, ok := strings.Cut(addr, "://")
+	if !ok {
+		return fmt.Errorf("module source %s is not a valid git URL", m.Source)
+	}
+	rest, subdir, _ := strings.Cut(rest, "//")
+	if i := strings.Index(subdir, "?"); i >= 0 {
+		rest += subdir[i:]
+		subdir = subdir[:i]
+	}
+	m.Subdir = subdir
+
+	u, err := url.Parse(scheme + "://" + rest)
+	if err != nil {
+		return fmt.Errorf("module source %s is not a valid git URL: %w", m.Source, err)
+	}
+
+	query := u.Query()
+	m.Ref = query.Get("ref")
+	query.Del("ref")
+	u.RawQuery = query.Encode()
+	m.URL = u.String()
+
+	return nil
+}
+
+// ModuleFetcher resolves remote module sources, verifies that they exist, and fetches them into a
+// local cache directory.
+type ModuleFetcher struct {
+	Dir    string
+	Client *http.Client
+	Git    func(ctx context.Context, dir string, args ...string) ([]byte, error)
+	// modulesURLs are the discovered modules API URLs of each registry host
+	modulesURLs map[string]*url.URL
+}
+
+// ModuleFetcherOpt is a functional option for a module fetcher.
+type ModuleFetcherOpt func(*ModuleFetcher)
+
+// NewModuleFetcher returns a new ModuleFetcher.
+func NewModuleFetcher(opts ...ModuleFetcherOpt) *ModuleFetcher {
+	f := &ModuleFetcher{
+		Client:      http.DefaultClient,
+		Git:         runGit,
+		modulesURLs: map[string]*url.URL{},
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// WithModuleFetcherDir sets the directory that modules are fetched into.
+func WithModuleFetcherDir(dir string) ModuleFetcherOpt {
+	return func(f *ModuleFetcher) {
+		f.Dir = dir
+	}
+}
+
+// WithModuleFetcherHTTPClient sets the HTTP client that is used to talk to module registries.
+func WithModuleFetcherHTTPClient(client *http.Client) ModuleFetcherOpt {
+	return func(f *ModuleFetcher) {
+		f.Client = client
+	}
+}
+
+// WithModuleFetcherGit sets the function that executes git commands.
+func WithModuleFetcherGit(git func(ctx context.Context, dir string, args ...string) ([]byte, error)) ModuleFetcherOpt {
+	return func(f *ModuleFetcher) {
+		f.Git = git
+	}
+}
+
+// Verify verifies that a remote module source exists. Local and unsupported sources are not
+// verified.
+func (f *ModuleFetcher) Verify(ctx context.Context, src *ModuleSource) error {
+	switch src.Type {
+	case ModuleSourceTypeRegistry:
+		_, err := f.registryVersion(ctx, src)
+
+		return err
+	case ModuleSourceTypeGit:
+		_, err := f.gitRefExists(ctx, src)
+
+		return err
+	default:
+		return nil
+	}
+}
+
+// Fetch fetches a remote module source into the fetcher directory and returns the directory of
+// the module. Modules that have already been fetched are not fetched again.
+func (f *ModuleFetcher) Fetch(ctx context.Context, src *ModuleSource) (string, error) {
+	if f.Dir == "" {
+		return "", errors.New("no module fetch directory has been configured")
+	}
+
+	switch src.Type {
+	case ModuleSourceTypeRegistry:
+		version, err := f.registryVersion(ctx, src)
+		if err != nil {
+			return "", err
+		}
+
+		gitSrc, err := f.registryDownloadSource(ctx, src, version)
+		if err != nil {
+			return "", err
+		}
+
+		dir, err := f.fetchGit(ctx, src.Source+"@"+version, gitSrc)
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(dir, gitSrc.Subdir, src.Subdir), nil
+	case ModuleSourceTypeGit:
+		dir, err := f.fetchGit(ctx, src.Source, src)
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(dir, src.Subdir), nil
+	default:
+		return "", fmt.Errorf("module source %s cannot be fetched, only registry and git modules are supported", src.Source)
+	}
+}
+
+// fetchGit clones the git repository into the fetcher directory keyed by the cache key.
+func (f *ModuleFetcher) fetchGit(ctx context.Context, key string, src *ModuleSource) (string, error) {
+	sum := sha256.Sum256([]byte(key))
+	dir := filepath.Join(f.Dir, hex.EncodeToString(sum[:])[:16])
+
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.MkdirTemp(f.Dir, ".fetch-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	isBranchOrTag, err := f.gitRefExists(ctx, src)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"clone", "--quiet"}
+	if src.Ref == "" || isBranchOrTag {
+		args = append(args, "--depth", "1")
+		if src.Ref != "" {
+			args = append(args, "--branch", src.Ref)
+		}
+	}
+	if out, err := f.Git(ctx, "", append(args, src.URL, tmp)...); err != nil {
+		return "", fmt.Errorf("cloning %s: %w: %s", src.URL, err, bytes.TrimSpace(out))
+	}
+
+	if src.Ref != "" && !isBranchOrTag {
+		if out, err := f.Git(ctx, tmp, "checkout", "--quiet", src.Ref); err != nil {
+			return "", fmt.Errorf("checking out %s of %s: %w: %s", src.Ref, src.URL, err, bytes.TrimSpace(out))
+		}
+	}
+
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// gitRefExists verifies that the git repository exists. If the source has a ref it returns whether
+// or not the ref is a branch or tag. Refs that aren't branches or tags are assumed to be commits.
+func (f *ModuleFetcher) gitRefExists(ctx context.Context, src *ModuleSource) (bool, error) {
+	out, err := f.Git(ctx, "", "ls-remote", src.URL)
+	if err != nil {
+		return false, fmt.Errorf("module source %s: git repository %s does not exist or is not accessible: %w: %s",
+			src.Source, src.URL, err, bytes.TrimSpace(out),
+		)
+	}
+
+	if src.Ref == "" {
+		return false, nil
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		_, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+
+		ref = strings.TrimSuffix(ref, "^{}")
+		if ref == "refs/heads/"+src.Ref || ref == "refs/tags/"+src.Ref {
+			return true, nil
+		}
+	}
+
+	if gitCommitRegexp.MatchString(src.Ref) {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("module source %s: git repository %s has no branch or tag named %s",
+		src.Source, src.URL, src.Ref,
+	)
+}
+
+// registryVersion returns the latest version of the registry module that satisfies the version
+// constraint of the source.
+func (f *ModuleFetcher) registryVersion(ctx context.Context, src *ModuleSource) (string, error) {
+	base, err := f.registryModulesURL(ctx, src.Host)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := f.registryGet(ctx, src.Host, base.JoinPath(src.Namespace, src.Name, src.System, "versions"))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("module %s does not exist in registry %s", src.Source, src.Host)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("listing versions of module %s: unexpected status %s", src.Source, res.Status)
+	}
+
+	body := struct {
+		Modules []struct {
+			Versions []struct {
+				Version string `json:"version"`
+			} `json:"versions"`
+		} `json:"modules"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("listing versions of module %s: %w", src.Source, err)
+	}
+
+	var constraint *semver.Constraints
+	if src.Version != "" {
+		constraint, err = newVersionConstraint(src.Version)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	versions := []*semver.Version{}
+	for _, mod := range body.Modules {
+		for _, v := range mod.Versions {
+			version, err := semver.NewVersion(v.Version)
+			if err != nil {
+				continue
+			}
+			if constraint == nil || constraint.Check(version) {
+				versions = append(versions, version)
+			}
+		}
+	}
+
+	if len(versions) == 0 {
+		if src.Version != "" {
+			return "", fmt.Errorf("module %s has no version that satisfies %s", src.Source, src.Version)
+		}
+
+		return "", fmt.Errorf("module %s has no versions", src.Source)
+	}
+
+	return slices.MaxFunc(versions, func(a, b *semver.Version) int {
+		return a.Compare(b)
+	}).Original(), nil
+}
+
+// registryDownloadSource returns the git source that the registry module version is downloaded
+// from.
+func (f *ModuleFetcher) registryDownloadSource(ctx context.Context, src *ModuleSource, version string) (*ModuleSource, error) {
+	base, err := f.registryModulesURL(ctx, src.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := f.registryGet(ctx, src.Host, base.JoinPath(src.Namespace, src.Name, src.System, version, "download"))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading module %s %s: unexpected status %s", src.Source, version, res.Status)
+	}
+
+	get := res.Header.Get("X-Terraform-Get")
+	if get == "" {
+		return nil, fmt.Errorf("downloading module %s %s: registry did not return a download location", src.Source, version)
+	}
+
+	gitSrc, err := ParseModuleSource(get, "")
+	if err != nil {
+		return nil, err
+	}
+	if gitSrc.Type != ModuleSourceTypeGit {
+		return nil, fmt.Errorf("module %s %s is downloaded from %s, only git download locations are supported", src.Source, version, get)
+	}
+
+	return gitSrc, nil
+}
+
+// registryModulesURL discovers the modules API of the registry host.
+func (f *ModuleFetcher) registryModulesURL(ctx context.Context, host string) (*url.URL, error) {
+	if u, ok := f.modulesURLs[host]; ok {
+		return u, nil
+	}
+
+	base := &url.URL{Scheme: "https", Host: host, Path: "/"}
+
+	res, err := f.registryGet(ctx, host, base.JoinPath(".well-known", "terraform.json"))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovering services of registry %s: unexpected status %s", host, res.Status)
+	}
+
+	services := map[string]any{}
+	if err := json.NewDecoder(res.Body).Decode(&services); err != nil {
+		return nil, fmt.Errorf("discovering services of registry %s: %w", host, err)
+	}
+
+	modules, ok := services["modules.v1"].(string)
+	if !ok {
+		return nil, fmt.Errorf("registry %s does not support modules", host)
+	}
+
+	u, err := url.Parse(modules)
+	if err != nil {
+		return nil, fmt.Errorf("discovering services of registry %s: %w", host, err)
+	}
+
+	f.modulesURLs[host] = base.ResolveReference(u)
+
+	return f.modulesURLs[host], nil
+}
+
+// registryGet makes a GET request to a registry. If a TF_TOKEN_<host> environment variable has
+// been set for the host it is used as the bearer token, the same as Terraform.
+func (f *ModuleFetcher) registryGet(ctx context.Context, host string, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenVar := "TF_TOKEN_" + strings.NewReplacer(".", "_", "-", "__").Replace(host)
+	if token := os.Getenv(tokenVar); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting %s: %w", u.String(), err)
+	}
+
+	return res, nil
+}
+
+// runGit executes git in the directory and returns the combined output.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	return cmd.CombinedOutput()
+}
+
+// newVersionConstraint returns new version constraints from a Terraform style version constraint
+// string. Terraform's pessimistic constraint operator only allows the right-most version component
+// to increment, e.g. "~> 1.2" allows any 1.x version that is >= 1.2, which differs from the tilde
+// range operator of semver.
+func newVersionConstraint(constraint string) (*semver.Constraints, error) {
+	parts := strings.Split(constraint, ",")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		v, ok := strings.CutPrefix(part, "~>")
+		if !ok {
+			continue
+		}
+
+		v = strings.TrimSpace(v)
+		version, err := semver.NewVersion(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %s: %w", v, err)
+		}
+
+		switch strings.Count(v, ".") {
+		case 0:
+			parts[i] = ">= " + v
+		case 1:
+			parts[i] = fmt.Sprintf(">= %s, < %d.0.0", v, version.Major()+1)
+		default:
+			parts[i] = fmt.Sprintf(">= %s, < %d.%d.0", v, version.Major(), version.Minor()+1)
+		}
+	}
+
+	return semver.NewConstraint(strings.Join(parts, ","))
+}
@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_ParseModuleSource tests parsing module source addresses.
+func Test_ParseModuleSource(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		source   string
+		version  string
+		expected *ModuleSource
+		fail     bool
+	}{
+		"local": {
+			source:   "./modules/backend",
+			expected: &ModuleSource{Type: ModuleSourceTypeLocal},
+		},
+		"registry": {
+			source:  "hashicorp/consul/aws//modules/server",
+			version: ">= 0.1.0",
+			expected: &ModuleSource{
+				Type:      ModuleSourceTypeRegistry,
+				Host:      "registry.terraform.io",
+				Namespace: "hashicorp",
+				Name:      "consul",
+				System:    "aws",
+				Subdir:    "modules/server",
+			},
+		},
+		"registry with host": {
+			source: "app.terraform.io/example/vpc/aws",
+			expected: &ModuleSource{
+				Type:      ModuleSourceTypeRegistry,
+				Host:      "app.terraform.io",
+				Namespace: "example",
+				Name:      "vpc",
+				System:    "aws",
+			},
+		},
+		"registry invalid version": {
+			source:  "hashicorp/consul/aws",
+			version: "not a version",
+			fail:    true,
+		},
+		"invalid": {
+			source: "hashicorp/consul",
+			fail:   true,
+		},
+		"git": {
+			source: "git::https://example.com/modules.git//vpc?ref=v1.2.0",
+			expected: &ModuleSource{
+				Type:   ModuleSourceTypeGit,
+				URL:    "https://example.com/modules.git",
+				Ref:    "v1.2.0",
+				Subdir: "vpc",
+			},
+		},
+		"git ssh": {
+			source: "git@github.com:hashicorp/example.git",
+			expected: &ModuleSource{
+				Type: ModuleSourceTypeGit,
+				URL:  "ssh://git@github.com/hashicorp/example.git",
+			},
+		},
+		"github": {
+			source: "github.com/hashicorp/example?ref=main",
+			expected: &ModuleSource{
+				Type: ModuleSourceTypeGit,
+				URL:  "https://github.com/hashicorp/example",
+				Ref:  "main",
+			},
+		},
+		"git with version": {
+			source:  "github.com/hashicorp/example",
+			version: "1.0.0",
+			fail:    true,
+		},
+		"unsupported": {
+			source:   "s3::https://s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip",
+			expected: &ModuleSource{Type: ModuleSourceTypeUnsupported},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := ParseModuleSource(test.source, test.version)
+			if test.fail {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			test.expected.Source = test.source
+			test.expected.Version = test.version
+			require.Equal(t, test.expected, src)
+		})
+	}
+}
+
+// Test_ModuleFetcher_Registry tests verifying and fetching registry modules.
+func Test_ModuleFetcher_Registry(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/terraform.json":
+			fmt.Fprint(w, `{"modules.v1": "/api/modules/"}`)
+		case "/api/modules/example/vpc/aws/versions":
+			fmt.Fprint(w, `{"modules": [{"versions": [{"version": "1.0.0"}, {"version": "1.2.0"}, {"version": "2.0.0"}]}]}`)
+		case "/api/modules/example/vpc/aws/1.2.0/download":
+			w.Header().Set("X-Terraform-Get", "git::https://example.com/vpc.git?ref=v1.2.0")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	clones := 0
+	fetcher := NewModuleFetcher(
+		WithModuleFetcherDir(t.TempDir()),
+		WithModuleFetcherHTTPClient(srv.Client()),
+		WithModuleFetcherGit(func(ctx context.Context, dir string, args ...string) ([]byte, error) {
+			switch args[0] {
+			case "ls-remote":
+				if args[1] != "https://example.com/vpc.git" {
+					return nil, errors.New("repository not found")
+				}
+
+				return []byte("abc123\trefs/heads/main\nabc123\trefs/tags/v1.2.0\n"), nil
+			case "clone":
+				clones++
+				require.Equal(t, []string{"clone", "--quiet", "--depth", "1", "--branch", "v1.2.0"}, args[:6])
+
+				return nil, os.MkdirAll(args[len(args)-1], 0o755)
+			default:
+				return nil, fmt.Errorf("unexpected git command %s", strings.Join(args, " "))
+			}
+		}),
+	)
+
+	src, err := ParseModuleSource(u.Host+"/example/vpc/aws", "~> 1.0")
+	require.NoError(t, err)
+	require.NoError(t, fetcher.Verify(context.Background(), src))
+
+	// Fetching twice should only clone once since the module has been cached.
+	for range 2 {
+		dir, err := fetcher.Fetch(context.Background(), src)
+		require.NoError(t, err)
+		require.DirExists(t, dir)
+		require.Equal(t, fetcher.Dir, filepath.Dir(dir))
+	}
+	require.Equal(t, 1, clones)
+
+	src, err = ParseModuleSource(u.Host+"/example/vpc/aws", ">= 3.0")
+	require.NoError(t, err)
+	require.ErrorContains(t, fetcher.Verify(context.Background(), src), "has no version that satisfies")
+
+	src, err = ParseModuleSource(u.Host+"/example/missing/aws", "")
+	require.NoError(t, err)
+	require.ErrorContains(t, fetcher.Verify(context.Background(), src), "does not exist")
+
+	src, err = ParseModuleSource("git::https://example.com/vpc.git?ref=v2.0.0", "")
+	require.NoError(t, err)
+	require.ErrorContains(t, fetcher.Verify(context.Background(), src), "has no branch or tag named v2.0.0")
+
+	src, err = ParseModuleSource("git::https://example.com/missing.git", "")
+	require.NoError(t, err)
+	require.ErrorContains(t, fetcher.Verify(context.Background(), src), "does not exist or is not accessible")
+}
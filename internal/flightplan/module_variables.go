@@ -0,0 +1,238 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// moduleVariablesCache caches the variables of local modules. As every variant of a scenario
+// decodes its steps we only want to parse the module once, unless its files have changed.
+var moduleVariablesCache = &moduleVariables{
+	modules: map[string]*moduleVariablesEntry{},
+}
+
+type moduleVariables struct {
+	mu      sync.Mutex
+	modules map[string]*moduleVariablesEntry
+}
+
+type moduleVariablesEntry struct {
+	sig  string
+	vars map[string]bool
+}
+
+// localModuleDir returns the directory of a module if the source is a local path. Relative paths
+// are relative to the base directory.
+func localModuleDir(source string, baseDir string) (string, bool) {
+	switch {
+	case filepath.IsAbs(source):
+		return source, true
+	case strings.HasPrefix(source, "./"), strings.HasPrefix(source, "../"):
+		return filepath.Join(baseDir, source), true
+	default:
+		return "", false
+	}
+}
+
+// evalContextBaseDir returns the flight plan base directory from the path.root variable in the
+// eval context.
+func evalContextBaseDir(ctx *hcl.EvalContext) string {
+	path, err := findEvalContextVariable("path", ctx)
+	if err != nil || path.IsNull() || !path.Type().IsObjectType() || !path.Type().HasAttribute("root") {
+		return ""
+	}
+
+	root := path.GetAttr("root")
+	if root.IsNull() || !root.IsKnown() || !root.Type().Equals(cty.String) {
+		return ""
+	}
+
+	return root.AsString()
+}
+
+// verifyLocalModuleSourceExists returns a warning diagnostic if the module has a local source
+// that does not exist.
+func verifyLocalModuleSourceExists(module *Module, src *hcl.Attribute, ctx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	dir, ok := localModuleDir(module.Source, evalContextBaseDir(ctx))
+	if !ok {
+		return diags
+	}
+
+	info, err := os.Stat(dir)
+	switch {
+	case err != nil:
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "module source does not exist",
+			Detail:   fmt.Sprintf("the source %s of module %s does not exist: %s", module.Source, module.Name, dir),
+			Subject:  src.Expr.Range().Ptr(),
+		})
+	case !info.IsDir():
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "module source is not a directory",
+			Detail:   fmt.Sprintf("the source %s of module %s is not a directory: %s", module.Source, module.Name, dir),
+			Subject:  src.Expr.Range().Ptr(),
+		})
+	default:
+	}
+
+	return diags
+}
+
+// verifyModuleVariables returns warning diagnostics for step variables that are not declared by
+// the local module of the step and for required module variables that the step doesn't set. If
+// the module isn't local or its variables can't be determined nothing is verified.
+func (ss *ScenarioStep) verifyModuleVariables(
+	block *hcl.Block,
+	content *hcl.BodyContent,
+	ctx *hcl.EvalContext,
+) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if ss.Module == nil {
+		return diags
+	}
+
+	dir, ok := localModuleDir(ss.Module.Source, evalContextBaseDir(ctx))
+	if !ok {
+		return diags
+	}
+
+	vars, ok := moduleVariablesCache.read(dir)
+	if !ok {
+		return diags
+	}
+
+	// Step variables are reported at their attribute, inherited module variables at the step.
+	ranges := map[string]hcl.Range{}
+	for _, varBlock := range content.Blocks.OfType("variables") {
+		attrs, _ := varBlock.Body.JustAttributes()
+		for name, attr := range attrs {
+			ranges[name] = attr.NameRange
+		}
+	}
+
+	names := []string{}
+	for name := range ss.Module.Attrs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if _, ok := vars[name]; ok {
+			continue
+		}
+
+		rng, ok := ranges[name]
+		if !ok {
+			rng = block.DefRange
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "unexpected module variable",
+			Detail: fmt.Sprintf("step %s sets the variable %s but module %s does not declare it",
+				ss.Name, name, ss.Module.Name,
+			),
+			Subject: rng.Ptr(),
+		})
+	}
+
+	required := []string{}
+	for name, isRequired := range vars {
+		if _, ok := ss.Module.Attrs[name]; isRequired && !ok {
+			required = append(required, name)
+		}
+	}
+	slices.Sort(required)
+
+	for _, name := range required {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "missing module variable",
+			Detail: fmt.Sprintf("module %s of step %s requires the variable %s but it is not set",
+				ss.Module.Name, ss.Name, name,
+			),
+			Subject: block.DefRange.Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// read returns the variables of the module in the directory and whether or not they are required.
+// If the directory can't be read, doesn't contain any Terraform files, or they can't be parsed we
+// return false as we're unable to determine the variables.
+func (c *moduleVariables) read(dir string) (map[string]bool, bool) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil || len(paths) == 0 {
+		return nil, false
+	}
+
+	sig := strings.Builder{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, false
+		}
+		sig.WriteString(fmt.Sprintf("%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano()))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.modules[dir]; ok && entry.sig == sig.String() {
+		return entry.vars, entry.vars != nil
+	}
+
+	vars := readModuleVariables(paths)
+	c.modules[dir] = &moduleVariablesEntry{sig: sig.String(), vars: vars}
+
+	return vars, vars != nil
+}
+
+// readModuleVariables reads the variable blocks from the Terraform files. Variables without a
+// default value are required.
+func readModuleVariables(paths []string) map[string]bool {
+	vars := map[string]bool{}
+	parser := hclparse.NewParser()
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		file, diags := parser.ParseHCL(src, path)
+		if diags.HasErrors() {
+			return nil
+		}
+
+		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeVariable, LabelNames: []string{"name"}}},
+		})
+		for _, variable := range content.Blocks {
+			varContent, _, _ := variable.Body.PartialContent(&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{{Name: "default"}},
+			})
+			_, hasDefault := varContent.Attributes["default"]
+			vars[variable.Labels[0]] = !hasDefault
+		}
+	}
+
+	return vars
+}
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// Test_Decode_ModuleVariables tests that local module sources are verified to exist and that step
+// variables are compatible with the variables of the module.
+func Test_Decode_ModuleVariables(t *testing.T) {
+	t.Parallel()
+
+	modulePath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(modulePath, "variables.tf"), []byte(`
+variable "region" {
+  type = string
+}
+
+variable "instance_type" {
+  type    = string
+  default = "t3.micro"
+}
+`), 0o600))
+
+	for desc, test := range map[string]struct {
+		source    string
+		module    string
+		variables string
+		expected  []string
+	}{
+		"compatible": {
+			source:    modulePath,
+			variables: `region = "us-east-1"`,
+			expected:  []string{},
+		},
+		"inherited from module": {
+			source:   modulePath,
+			module:   `region = "us-east-1"`,
+			expected: []string{},
+		},
+		"missing required": {
+			source:    modulePath,
+			variables: `instance_type = "t3.large"`,
+			expected: []string{
+				"module backend of step backend requires the variable region but it is not set",
+			},
+		},
+		"undeclared": {
+			source: modulePath,
+			variables: `
+    region = "us-east-1"
+    zone   = "a"
+`,
+			expected: []string{
+				"step backend sets the variable zone but module backend does not declare it",
+			},
+		},
+		"missing source": {
+			source: filepath.Join(modulePath, "missing"),
+			expected: []string{
+				fmt.Sprintf("the source %[1]s of module backend does not exist: %[1]s", filepath.Join(modulePath, "missing")),
+			},
+		},
+		"remote source": {
+			source:   "hashicorp/consul/aws",
+			expected: []string{},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, diags := testDecodeHCLScenarios(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+  %s
+}
+
+scenario "test" {
+  step "backend" {
+    module = module.backend
+
+    variables {
+      %s
+    }
+  }
+}
+`, test.source, test.module, test.variables)))
+
+			details := []string{}
+			for _, diag := range diags {
+				require.Equal(t, hcl.DiagWarning, diag.Severity, diag.Error())
+				details = append(details, diag.Detail)
+			}
+			require.ElementsMatch(t, test.expected, details)
+		})
+	}
+}
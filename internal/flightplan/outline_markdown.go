@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// OutlinesMarkdown returns the scenario outlines as GitHub flavored Markdown test documentation,
+// with a section for each scenario that documents its variants, the quality characteristics that
+// it verifies, and its steps and outputs.
+func OutlinesMarkdown(outlines []*pb.Scenario_Outline) string {
+	b := &strings.Builder{}
+
+	for i, out := range outlines {
+		if i != 0 {
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(b, "## %s\n", out.GetScenario().GetId().GetName())
+		if description := strings.TrimSpace(out.GetScenario().GetId().GetDescription()); description != "" {
+			fmt.Fprintf(b, "\n%s\n", description)
+		}
+
+		details := []string{}
+		if tags := out.GetScenario().GetMetadata().GetTags(); len(tags) > 0 {
+			details = append(details, "**Tags:** "+markdownCodeList(tags))
+		}
+		if rng := out.GetRange(); rng.GetFilename() != "" {
+			details = append(details, fmt.Sprintf("**Source:** `%s:%d`", rng.GetFilename(), rng.GetStart().GetLine()))
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(b, "\n%s\n", strings.Join(details, "<br>\n"))
+		}
+
+		if vectors := out.GetMatrix().GetVectors(); len(vectors) > 0 {
+			b.WriteString("\n### Variants\n\n")
+			for _, vec := range vectors {
+				if len(vec.GetElements()) == 0 {
+					continue
+				}
+				values := []string{}
+				for _, elm := range vec.GetElements() {
+					values = append(values, elm.GetValue())
+				}
+				fmt.Fprintf(b, "- `%s`: %s\n", vec.GetElements()[0].GetKey(), markdownCodeList(values))
+			}
+		}
+
+		if qualities := out.GetVerifies(); len(qualities) > 0 {
+			b.WriteString("\n### Verifies\n\n")
+			for _, q := range qualities {
+				writeMarkdownItem(b, "- ", q.GetName(), q.GetDescription(), nil)
+			}
+		}
+
+		if steps := out.GetSteps(); len(steps) > 0 {
+			b.WriteString("\n### Steps\n\n")
+			for i, step := range steps {
+				writeMarkdownItem(b, fmt.Sprintf("%d. ", i+1), step.GetName(), step.GetDescription(), step.GetVerifies())
+			}
+		}
+
+		if outputs := out.GetOutputs(); len(outputs) > 0 {
+			b.WriteString("\n### Outputs\n\n")
+			for _, output := range outputs {
+				writeMarkdownItem(b, "- ", output.GetName(), output.GetDescription(), output.GetVerifies())
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// writeMarkdownItem writes a list item of a name, its description, and the qualities that it
+// verifies. Continuation lines are indented to the width of the marker so that they belong to
+// the item.
+func writeMarkdownItem(b *strings.Builder, marker string, name string, description string, verifies []*pb.Quality) {
+	indent := strings.Repeat(" ", len(marker))
+	fmt.Fprintf(b, "%s**%s**", marker, name)
+
+	lines := strings.Split(strings.TrimSpace(description), "\n")
+	if lines[0] != "" {
+		fmt.Fprintf(b, ": %s", strings.TrimSpace(lines[0]))
+		for _, line := range lines[1:] {
+			fmt.Fprintf(b, "<br>\n%s%s", indent, strings.TrimSpace(line))
+		}
+	}
+	b.WriteString("\n")
+
+	if len(verifies) > 0 {
+		names := []string{}
+		for _, q := range verifies {
+			names = append(names, q.GetName())
+		}
+		fmt.Fprintf(b, "%s- Verifies: %s\n", indent, markdownCodeList(names))
+	}
+}
+
+// markdownCodeList returns the values as a comma separated list of code spans.
+func markdownCodeList(values []string) string {
+	return "`" + strings.Join(values, "`, `") + "`"
+}
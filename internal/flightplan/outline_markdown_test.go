@@ -0,0 +1,55 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_OutlinesMarkdown tests rendering scenario outlines as Markdown.
+func Test_OutlinesMarkdown(t *testing.T) {
+	t.Parallel()
+
+	durable := &pb.Quality{Name: "data_is_durable", Description: "The data is durable"}
+	passes := &pb.Quality{Name: "tests_pass", Description: "The tests pass"}
+
+	outlines := []*pb.Scenario_Outline{
+		{
+			Scenario: &pb.Ref_Scenario{
+				Id:       &pb.Scenario_ID{Name: "upgrade", Description: "Upgrade the backend\n"},
+				Metadata: &pb.Scenario_Metadata{Tags: []string{"nightly"}},
+			},
+			Range: &pb.Range{Filename: "enos.hcl", Start: &pb.Range_Pos{Line: 10}},
+			Matrix: &pb.Matrix{Vectors: []*pb.Matrix_Vector{
+				{Elements: []*pb.Matrix_Element{{Key: "arch", Value: "amd64"}, {Key: "arch", Value: "arm64"}}},
+			}},
+			Verifies: []*pb.Quality{durable, passes},
+			Steps: []*pb.Scenario_Outline_Step{
+				{Name: "infra", Description: "Create the infrastructure\nin the region"},
+				{Name: "backend", Verifies: []*pb.Quality{passes}},
+			},
+			Outputs: []*pb.Scenario_Outline_Output{
+				{Name: "addrs", Description: "The backend addresses", Verifies: []*pb.Quality{durable}},
+			},
+		},
+		{
+			Scenario: &pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: "smoke"}},
+		},
+	}
+
+	require.Equal(t, "## upgrade\n"+
+		"\nUpgrade the backend\n"+
+		"\n**Tags:** `nightly`<br>\n**Source:** `enos.hcl:10`\n"+
+		"\n### Variants\n\n- `arch`: `amd64`, `arm64`\n"+
+		"\n### Verifies\n\n- **data_is_durable**: The data is durable\n- **tests_pass**: The tests pass\n"+
+		"\n### Steps\n\n1. **infra**: Create the infrastructure<br>\n   in the region\n2. **backend**\n   - Verifies: `tests_pass`\n"+
+		"\n### Outputs\n\n- **addrs**: The backend addresses\n  - Verifies: `data_is_durable`\n"+
+		"\n## smoke\n",
+		OutlinesMarkdown(outlines),
+	)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// EnclosingAt parses the flight plan file and returns the scenario, step, output, block, and
+// attribute that enclose the one based line and column. Like the columns of HCL positions the
+// column counts characters. Positions that are not in a scenario only return the innermost block
+// and attribute, and positions that are not in a block return nothing.
+func EnclosingAt(
+	src []byte,
+	filename string,
+	line int,
+	column int,
+) (*pb.ShowScenarioAtResponse_Enclosing, hcl.Diagnostics) {
+	offset, err := byteOffset(src, line, column)
+	if err != nil {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "invalid position",
+			Detail:   fmt.Sprintf("%s: %s", filename, err),
+		}}
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, diags
+	}
+
+	enclosing := &pb.ShowScenarioAtResponse_Enclosing{}
+	contains := func(rng hcl.Range) bool {
+		return rng.Start.Byte <= offset && offset < rng.End.Byte
+	}
+
+	for depth := 0; ; depth++ {
+		var inner *hclsyntax.Block
+		for _, block := range body.Blocks {
+			if contains(block.Range()) {
+				inner = block
+				break
+			}
+		}
+
+		if inner == nil {
+			for _, attr := range body.Attributes {
+				if contains(attr.SrcRange) {
+					enclosing.Attribute = attr.Name
+					enclosing.Range = diagnostics.FromHCLRange(attr.SrcRange)
+				}
+			}
+
+			return enclosing, diags
+		}
+
+		enclosing.Block = inner.Type
+		enclosing.Range = diagnostics.FromHCLRange(inner.Range())
+		if len(inner.Labels) > 0 {
+			switch {
+			case depth == 0 && inner.Type == blockTypeScenario:
+				enclosing.Scenario = inner.Labels[0]
+			case depth == 1 && enclosing.GetScenario() != "" && inner.Type == blockTypeScenarioStep:
+				enclosing.Step = inner.Labels[0]
+			case depth == 1 && enclosing.GetScenario() != "" && inner.Type == blockTypeOutput:
+				enclosing.Output = inner.Labels[0]
+			}
+		}
+		body = inner.Body
+	}
+}
+
+// byteOffset returns the byte offset of the one based line and column of the source. The column
+// may be one past the end of the line.
+func byteOffset(src []byte, line int, column int) (int, error) {
+	if line < 1 || column < 1 {
+		return 0, fmt.Errorf("line %d, column %d is not a one based position", line, column)
+	}
+
+	offset := 0
+	for range line - 1 {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return 0, fmt.Errorf("line %d is past the end of the file", line)
+		}
+		offset += i + 1
+	}
+
+	for col := 1; col < column; col++ {
+		r, size := utf8.DecodeRune(src[offset:])
+		if size == 0 || r == '\n' {
+			return 0, fmt.Errorf("column %d is past the end of line %d", column, line)
+		}
+		offset += size
+	}
+
+	return offset, nil
+}
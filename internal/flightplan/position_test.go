@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_EnclosingAt tests finding the elements that enclose positions of a flight plan file.
+func Test_EnclosingAt(t *testing.T) {
+	t.Parallel()
+
+	src := []byte(`module "infra" {
+  source = "./modules/infra"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  step "infra" {
+    module = module.infra
+
+    variables {
+      arch = matrix.arch
+    }
+  }
+
+  output "arch" {
+    value = matrix.arch
+  }
+}
+`)
+
+	for desc, test := range map[string]struct {
+		line     int
+		column   int
+		expected []string // scenario, step, output, block, attribute
+	}{
+		"outside of blocks":   {4, 1, []string{"", "", "", "", ""}},
+		"module":              {2, 5, []string{"", "", "", "module", "source"}},
+		"scenario header":     {5, 3, []string{"upgrade", "", "", "scenario", ""}},
+		"matrix dimension":    {7, 12, []string{"upgrade", "", "", "matrix", "arch"}},
+		"step":                {11, 5, []string{"upgrade", "infra", "", "step", "module"}},
+		"step variables":      {14, 15, []string{"upgrade", "infra", "", "variables", "arch"}},
+		"step blank line":     {12, 1, []string{"upgrade", "infra", "", "step", ""}},
+		"output":              {19, 8, []string{"upgrade", "", "arch", "output", "value"}},
+		"end of scenario":     {21, 1, []string{"upgrade", "", "", "scenario", ""}},
+		"after last line":     {22, 1, []string{"", "", "", "", ""}},
+		"scenario close line": {21, 2, []string{"", "", "", "", ""}},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			enclosing, diags := EnclosingAt(src, "enos.hcl", test.line, test.column)
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.expected, []string{
+				enclosing.GetScenario(),
+				enclosing.GetStep(),
+				enclosing.GetOutput(),
+				enclosing.GetBlock(),
+				enclosing.GetAttribute(),
+			})
+		})
+	}
+
+	t.Run("invalid positions", func(t *testing.T) {
+		t.Parallel()
+
+		for _, pos := range [][2]int{{0, 1}, {1, 0}, {30, 1}, {1, 100}} {
+			_, diags := EnclosingAt(src, "enos.hcl", pos[0], pos[1])
+			require.True(t, diags.HasErrors())
+		}
+	})
+}
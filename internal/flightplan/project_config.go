@@ -0,0 +1,1163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/hashicorp/enos/internal/i18n"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ProjectConfigNamePattern is what file names match valid enos project configuration files.
+var ProjectConfigNamePattern = regexp.MustCompile(`^(\.enos|enos\.project)\.hcl$`)
+
+// lockPlatformPattern is what the platforms of the lock block must match.
+var lockPlatformPattern = regexp.MustCompile(`^[a-z0-9]+_[a-z0-9]+$`)
+
+const (
+	blockTypeProjectLint    = "lint"
+	blockTypeProjectNotify  = "notify"
+	blockTypeProjectPublish = "publish"
+	blockTypeProjectPolicy  = "policy"
+	blockTypeProjectLayout  = "layout"
+	blockTypeProjectLock    = "lock"
+)
+
+// blockTypeProjectWarnings is the type of the block that configures the warning budget.
+const blockTypeProjectWarnings = "warnings"
+
+const (
+	// NotifierTypeSlack is the type of notifiers that post to Slack.
+	NotifierTypeSlack = "slack"
+	// NotifierTypeGitHub is the type of notifiers that report runs as GitHub check runs.
+	NotifierTypeGitHub = "github"
+	// NotifierTypeDatadog is the type of notifiers that emit the metrics and events of runs to
+	// Datadog.
+	NotifierTypeDatadog = "datadog"
+)
+
+const (
+	// PublisherTypeS3 is the type of publishers that upload to S3 or S3 compatible storage.
+	PublisherTypeS3 = "s3"
+	// PublisherTypeGCS is the type of publishers that upload to Google Cloud Storage.
+	PublisherTypeGCS = "gcs"
+	// PublisherTypeAzure is the type of publishers that upload to Azure Blob Storage.
+	PublisherTypeAzure = "azure"
+)
+
+var projectConfigSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "out_dir"},
+		{Name: "format"},
+		{Name: "worker_count"},
+		{Name: "max_decode_workers"},
+		{Name: "timeout"},
+		{Name: "lock_timeout"},
+		{Name: "strict_schema"},
+		{Name: "fail_on_warnings"},
+		{Name: "log_file"},
+		{Name: "locale"},
+		{Name: "message_catalog"},
+		{Name: "statsd_address"},
+		{Name: "statsd_prefix"},
+		{Name: "ci"},
+		{Name: "tags"},
+		{Name: "reuse_terraform_dir"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeProjectLint},
+		{Type: blockTypeProjectNotify, LabelNames: []string{"type"}},
+		{Type: blockTypeProjectPublish, LabelNames: []string{"type"}},
+		{Type: blockTypeProjectPolicy},
+		{Type: blockTypeProjectLayout},
+		{Type: blockTypeProjectLock},
+		{Type: blockTypeProjectWarnings},
+	},
+}
+
+// projectLockSchema is the schema of the lock block.
+var projectLockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "file"},
+		{Name: "platforms"},
+	},
+}
+
+// projectWarningsSchema is the schema of the warnings block.
+var projectWarningsSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "max"},
+		{Name: "deny"},
+	},
+}
+
+// projectLayoutSchema is the schema of the layout block.
+var projectLayoutSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "naming"},
+		{Name: "nested"},
+	},
+}
+
+// projectPolicySchema is the schema of the policy block.
+var projectPolicySchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "max_hourly_cost"},
+		{Name: "max_monthly_cost"},
+		{Name: "deny_resource_types"},
+		{Name: "deny_instance_types"},
+		{Name: "rego"},
+		{Name: "opa_path"},
+		{Name: "allow_exec"},
+		{Name: "deny_exec"},
+	},
+}
+
+// projectNotifierSchemas are the schemas of the notify blocks, keyed by the notifier type.
+var projectNotifierSchemas = map[string]*hcl.BodySchema{
+	NotifierTypeSlack: {
+		Attributes: []hcl.AttributeSchema{
+			{Name: "webhook_url"},
+			{Name: "token"},
+			{Name: "channel"},
+			{Name: "failures_only"},
+			{Name: "links"},
+		},
+	},
+	NotifierTypeGitHub: {
+		Attributes: []hcl.AttributeSchema{
+			{Name: "token"},
+			{Name: "app_id"},
+			{Name: "installation_id"},
+			{Name: "private_key"},
+			{Name: "repository"},
+			{Name: "sha"},
+			{Name: "check_name"},
+			{Name: "api_url"},
+			{Name: "links"},
+		},
+	},
+	NotifierTypeDatadog: {
+		Attributes: []hcl.AttributeSchema{
+			{Name: "api_key"},
+			{Name: "site"},
+			{Name: "statsd_address"},
+			{Name: "metric_prefix"},
+			{Name: "tags"},
+		},
+	},
+}
+
+// projectPublisherSchemas are the schemas of the publish blocks, keyed by the publisher type.
+var projectPublisherSchemas = map[string]*hcl.BodySchema{
+	PublisherTypeS3: {
+		Attributes: []hcl.AttributeSchema{
+			{Name: "bucket", Required: true},
+			{Name: "region"},
+			{Name: "endpoint"},
+			{Name: "prefix"},
+			{Name: "run_id"},
+			{Name: "paths"},
+		},
+	},
+	PublisherTypeGCS: {
+		Attributes: []hcl.AttributeSchema{
+			{Name: "bucket", Required: true},
+			{Name: "credentials"},
+			{Name: "token"},
+			{Name: "prefix"},
+			{Name: "run_id"},
+			{Name: "paths"},
+		},
+	},
+	PublisherTypeAzure: {
+		Attributes: []hcl.AttributeSchema{
+			{Name: "account", Required: true},
+			{Name: "container", Required: true},
+			{Name: "sas_token"},
+			{Name: "account_key"},
+			{Name: "endpoint"},
+			{Name: "prefix"},
+			{Name: "run_id"},
+			{Name: "paths"},
+		},
+	},
+}
+
+// projectConfigLintSchema is the schema of a project configuration file for the linter, which
+// only cares about the lint blocks.
+var projectConfigLintSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeProjectLint},
+	},
+}
+
+// ProjectConfig is a checked-in .enos.hcl or enos.project.hcl file that configures the default
+// values of CLI flags for a flight plan directory. Settings that have not been set are nil. Lint
+// rules in "lint" blocks are configured by the Linter. Tags are added to the resources of
+// generated modules. The policy is enforced on the plans of scenarios before they are applied. The
+// layout configures the directories of generated modules in the out directory and the lock
+// configures their dependency lock files. The warnings are the warning budget of validating and
+// launching scenarios.
+type ProjectConfig struct {
+	Path             string
+	OutDir           *string
+	Format           *string
+	WorkerCount      *int32
+	MaxDecodeWorkers *int32
+	Timeout          *time.Duration
+	LockTimeout      *time.Duration
+	StrictSchema     *bool
+	FailOnWarnings   *bool
+	LogFile          *string
+	Locale           *string
+	MessageCatalog   *string
+	StatsDAddress    *string
+	StatsDPrefix     *string
+	CI               *string
+	ReuseTFDir       *string
+	Tags             map[string]string
+	Notifiers        []*ProjectNotifier
+	Publishers       []*ProjectPublisher
+	Policy           *ProjectPolicy
+	Layout           *ProjectLayout
+	Lock             *ProjectLock
+	Warnings         *ProjectWarnings
+	parser           *hclparse.Parser
+}
+
+// ProjectNotifier is a "notify" block of the project configuration that configures where the
+// summaries of scenario runs are sent. Values are literals, as with every other setting, and are
+// expanded with the environment when the notifier is used so that secrets don't have to be
+// checked in, e.g. webhook_url = "$SLACK_WEBHOOK_URL".
+type ProjectNotifier struct {
+	Type         string
+	WebhookURL   string
+	Token        string
+	Channel      string
+	FailuresOnly bool
+	Links        map[string]string
+	// The settings of GitHub notifiers. The repository and SHA default to the environment of
+	// GitHub Actions.
+	AppID          string
+	InstallationID string
+	PrivateKey     string
+	Repository     string
+	SHA            string
+	CheckName      string
+	APIURL         string
+	// The settings of Datadog notifiers. Metrics are sent to DogStatsD unless an API key is set.
+	APIKey        string
+	Site          string
+	StatsDAddress string
+	MetricPrefix  string
+	Tags          []string
+}
+
+// ProjectPublisher is a "publish" block of the project configuration that configures where the
+// artifacts, reports, and logs of runs are uploaded. Objects are uploaded under a prefix that is
+// scoped to the run, i.e. <prefix>/<run_id>/<name>. Paths are globs of files or directories
+// relative to the working directory. Like notifiers, values are expanded with the environment
+// when the publisher is used.
+type ProjectPublisher struct {
+	Type     string
+	Bucket   string
+	Prefix   string
+	RunID    string
+	Paths    []string
+	Endpoint string
+	// The settings of S3 publishers. The region and credentials default to the AWS environment.
+	Region string
+	// The settings of GCS publishers. Without a token or the path of service account credentials
+	// the token of the metadata server is used.
+	Credentials string
+	Token       string
+	// The settings of Azure publishers, which need either a sas_token or an account_key.
+	Account    string
+	Container  string
+	SASToken   string
+	AccountKey string
+}
+
+// ProjectPolicy is the "policy" block of the project configuration that configures the guardrails
+// of the resources that scenarios create. Scenarios whose plans exceed the maximum costs or create
+// resources of denied types or instance types fail before anything is applied. Types are glob
+// patterns, e.g. "*.metal". Costs are in the currency of Infracost and require it to estimate
+// the cost of plans. Rego are the files or directories of Rego policies that are evaluated with
+// OPA, relative to the project configuration file. AllowExec and DenyExec restrict the Terraform
+// sub-commands that "scenario exec" may run, e.g. "state show". A sub-command is denied if it
+// begins with any of the denied commands or if allowed commands are set and it doesn't begin with
+// any of them.
+type ProjectPolicy struct {
+	MaxHourlyCost     float64
+	MaxMonthlyCost    float64
+	DenyResourceTypes []string
+	DenyInstanceTypes []string
+	Rego              []string
+	OPAPath           string
+	AllowExec         []string
+	DenyExec          []string
+}
+
+// ProjectLayout is the "layout" block of the project configuration that configures the layout of
+// the generated modules in the out directory. Naming is either "slug" or "uid", which is the
+// layout of older versions of Enos. Nested modules are generated into a directory named after the
+// scenario. Without a naming, modules that have been generated into a directory named after the
+// UID continue to use it.
+type ProjectLayout struct {
+	Naming string
+	Nested bool
+}
+
+// ProjectLock is the "lock" block of the project configuration that configures the Terraform
+// dependency lock files of the generated modules. File is the path of a project lock file, relative
+// to the project configuration file, that modules without a lock file are seeded with. Platforms
+// are the platforms that providers must be locked for, e.g. "linux_amd64".
+type ProjectLock struct {
+	File      string
+	Platforms []string
+}
+
+// ProjectWarnings is the "warnings" block of the project configuration that configures the warning
+// budget of validating and launching scenarios, which lets teams ratchet down their warnings
+// without failing on every warning. Validation and operations fail if they have more than Max
+// warnings or any warning whose code is one of the denied codes, e.g. the name of a lint rule.
+// Without a Max any number of warnings is allowed.
+type ProjectWarnings struct {
+	Max  *int32
+	Deny []string
+}
+
+// NewProjectConfig returns a new ProjectConfig.
+func NewProjectConfig() *ProjectConfig {
+	return &ProjectConfig{parser: hclparse.NewParser()}
+}
+
+// Files returns the parsed project configuration files. They can be used to add snippets to
+// diagnostics of the project configuration.
+func (p *ProjectConfig) Files() map[string]*hcl.File {
+	if p == nil || p.parser == nil {
+		return nil
+	}
+
+	return p.parser.Files()
+}
+
+// Load finds and decodes the project configuration file in the directory. It is not an error for
+// the directory to not have a project configuration file but it is to have more than one.
+func (p *ProjectConfig) Load(dir string) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	files, err := FindRawFiles(dir, ProjectConfigNamePattern)
+	if err != nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "unable to find project configuration",
+			Detail:   err.Error(),
+		})
+	}
+
+	paths := []string{}
+	for path := range files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	switch len(paths) {
+	case 0:
+		return diags
+	case 1:
+	default:
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "multiple project configuration files",
+			Detail: fmt.Sprintf("only one project configuration file is allowed in %s, found %s and %s",
+				dir, filepath.Base(paths[0]), filepath.Base(paths[1]),
+			),
+		})
+	}
+
+	return p.Decode(paths[0], files[paths[0]])
+}
+
+// Decode parses and decodes the project configuration file onto itself. Values must be literals
+// as the project configuration is decoded before anything else. A relative out_dir is relative
+// to the directory of the file.
+func (p *ProjectConfig) Decode(path string, src []byte) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	p.Path = path
+
+	file, moreDiags := p.parser.ParseHCL(src, path)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	content, moreDiags := file.Body.Content(projectConfigSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	decodeString := func(name string) *string {
+		attr, ok := content.Attributes[name]
+		if !ok {
+			return nil
+		}
+
+		var val string
+		moreDiags := gohcl.DecodeExpression(attr.Expr, nil, &val)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil
+		}
+
+		return &val
+	}
+
+	decodeBool := func(name string) *bool {
+		attr, ok := content.Attributes[name]
+		if !ok {
+			return nil
+		}
+
+		var val bool
+		moreDiags := gohcl.DecodeExpression(attr.Expr, nil, &val)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil
+		}
+
+		return &val
+	}
+
+	decodeCount := func(name string, minVal int32) *int32 {
+		attr, ok := content.Attributes[name]
+		if !ok {
+			return nil
+		}
+
+		var val int32
+		moreDiags := gohcl.DecodeExpression(attr.Expr, nil, &val)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil
+		}
+
+		if val < minVal {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid value",
+				Detail:   fmt.Sprintf("%s must be at least %d, got %d", name, minVal, val),
+				Subject:  attr.Expr.Range().Ptr(),
+				Context:  attr.Range.Ptr(),
+			})
+
+			return nil
+		}
+
+		return &val
+	}
+
+	decodeDuration := func(name string) *time.Duration {
+		attr, ok := content.Attributes[name]
+		if !ok {
+			return nil
+		}
+
+		var val string
+		moreDiags := gohcl.DecodeExpression(attr.Expr, nil, &val)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil
+		}
+
+		dur, err := time.ParseDuration(val)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid value",
+				Detail:   fmt.Sprintf("%s must be a duration, e.g. 30m: %s", name, err.Error()),
+				Subject:  attr.Expr.Range().Ptr(),
+				Context:  attr.Range.Ptr(),
+			})
+
+			return nil
+		}
+
+		return &dur
+	}
+
+	p.OutDir = decodeString("out_dir")
+	if p.OutDir != nil && !filepath.IsAbs(*p.OutDir) {
+		outDir := filepath.Join(filepath.Dir(path), *p.OutDir)
+		p.OutDir = &outDir
+	}
+
+	p.Format = decodeString("format")
+	if p.Format != nil && !slices.Contains([]string{"text", "json", "yaml", "html"}, *p.Format) {
+		attr := content.Attributes["format"]
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid value",
+			Detail:   "format must be one of text, json, yaml, or html, got " + *p.Format,
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+		p.Format = nil
+	}
+
+	p.WorkerCount = decodeCount("worker_count", 1)
+	p.MaxDecodeWorkers = decodeCount("max_decode_workers", 0)
+	p.Timeout = decodeDuration("timeout")
+	p.LockTimeout = decodeDuration("lock_timeout")
+	p.StrictSchema = decodeBool("strict_schema")
+	p.FailOnWarnings = decodeBool("fail_on_warnings")
+	p.LogFile = decodeString("log_file")
+
+	p.Locale = decodeString("locale")
+	if p.Locale != nil {
+		if _, err := i18n.ParseLocale(*p.Locale); err != nil {
+			attr := content.Attributes["locale"]
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid value",
+				Detail:   err.Error(),
+				Subject:  attr.Expr.Range().Ptr(),
+				Context:  attr.Range.Ptr(),
+			})
+			p.Locale = nil
+		}
+	}
+
+	p.MessageCatalog = decodeString("message_catalog")
+	if p.MessageCatalog != nil && !filepath.IsAbs(*p.MessageCatalog) {
+		msgCatalog := filepath.Join(filepath.Dir(path), *p.MessageCatalog)
+		p.MessageCatalog = &msgCatalog
+	}
+
+	p.StatsDAddress = decodeString("statsd_address")
+	p.StatsDPrefix = decodeString("statsd_prefix")
+
+	p.CI = decodeString("ci")
+	if p.CI != nil && !slices.Contains([]string{"auto", "none", "github-actions", "gitlab", "circleci", "buildkite"}, *p.CI) {
+		attr := content.Attributes["ci"]
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid value",
+			Detail:   "ci must be one of auto, none, github-actions, gitlab, circleci, or buildkite, got " + *p.CI,
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+		p.CI = nil
+	}
+
+	p.ReuseTFDir = decodeString("reuse_terraform_dir")
+	if p.ReuseTFDir != nil && !slices.Contains([]string{"symlink", "copy", "none"}, *p.ReuseTFDir) {
+		attr := content.Attributes["reuse_terraform_dir"]
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid value",
+			Detail:   "reuse_terraform_dir must be one of symlink, copy, or none, got " + *p.ReuseTFDir,
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+		p.ReuseTFDir = nil
+	}
+
+	p.Tags = nil
+	if attr, ok := content.Attributes["tags"]; ok {
+		moreDiags := gohcl.DecodeExpression(attr.Expr, nil, &p.Tags)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			p.Tags = nil
+		}
+	}
+
+	p.Notifiers = nil
+	for _, block := range content.Blocks.OfType(blockTypeProjectNotify) {
+		notifier, moreDiags := decodeProjectNotifier(block)
+		diags = diags.Extend(moreDiags)
+		if notifier != nil {
+			p.Notifiers = append(p.Notifiers, notifier)
+		}
+	}
+
+	p.Publishers = nil
+	for _, block := range content.Blocks.OfType(blockTypeProjectPublish) {
+		publisher, moreDiags := decodeProjectPublisher(block)
+		diags = diags.Extend(moreDiags)
+		if publisher != nil {
+			p.Publishers = append(p.Publishers, publisher)
+		}
+	}
+
+	p.Policy = nil
+	for i, block := range content.Blocks.OfType(blockTypeProjectPolicy) {
+		if i > 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "duplicate policy block",
+				Detail:   "only one policy block is allowed in the project configuration",
+				Subject:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+
+		policy, moreDiags := decodeProjectPolicy(block, filepath.Dir(path))
+		diags = diags.Extend(moreDiags)
+		p.Policy = policy
+	}
+
+	p.Layout = nil
+	for i, block := range content.Blocks.OfType(blockTypeProjectLayout) {
+		if i > 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "duplicate layout block",
+				Detail:   "only one layout block is allowed in the project configuration",
+				Subject:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+
+		layout, moreDiags := decodeProjectLayout(block)
+		diags = diags.Extend(moreDiags)
+		p.Layout = layout
+	}
+
+	p.Lock = nil
+	for i, block := range content.Blocks.OfType(blockTypeProjectLock) {
+		if i > 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "duplicate lock block",
+				Detail:   "only one lock block is allowed in the project configuration",
+				Subject:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+
+		lock, moreDiags := decodeProjectLock(block, filepath.Dir(path))
+		diags = diags.Extend(moreDiags)
+		p.Lock = lock
+	}
+
+	p.Warnings = nil
+	for i, block := range content.Blocks.OfType(blockTypeProjectWarnings) {
+		if i > 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "duplicate warnings block",
+				Detail:   "only one warnings block is allowed in the project configuration",
+				Subject:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+
+		warnings, moreDiags := decodeProjectWarnings(block)
+		diags = diags.Extend(moreDiags)
+		p.Warnings = warnings
+	}
+
+	return diags
+}
+
+// Settings returns the string values of all settings that have been set, keyed by the setting
+// name.
+func (p *ProjectConfig) Settings() map[string]string {
+	settings := map[string]string{}
+	if p == nil {
+		return settings
+	}
+
+	if p.OutDir != nil {
+		settings["out_dir"] = *p.OutDir
+	}
+
+	if p.Format != nil {
+		settings["format"] = *p.Format
+	}
+
+	if p.WorkerCount != nil {
+		settings["worker_count"] = strconv.FormatInt(int64(*p.WorkerCount), 10)
+	}
+
+	if p.MaxDecodeWorkers != nil {
+		settings["max_decode_workers"] = strconv.FormatInt(int64(*p.MaxDecodeWorkers), 10)
+	}
+
+	if p.Timeout != nil {
+		settings["timeout"] = p.Timeout.String()
+	}
+
+	if p.LockTimeout != nil {
+		settings["lock_timeout"] = p.LockTimeout.String()
+	}
+
+	if p.StrictSchema != nil {
+		settings["strict_schema"] = strconv.FormatBool(*p.StrictSchema)
+	}
+
+	if p.FailOnWarnings != nil {
+		settings["fail_on_warnings"] = strconv.FormatBool(*p.FailOnWarnings)
+	}
+
+	if p.LogFile != nil {
+		settings["log_file"] = *p.LogFile
+	}
+
+	if p.Locale != nil {
+		settings["locale"] = *p.Locale
+	}
+
+	if p.MessageCatalog != nil {
+		settings["message_catalog"] = *p.MessageCatalog
+	}
+
+	if p.StatsDAddress != nil {
+		settings["statsd_address"] = *p.StatsDAddress
+	}
+
+	if p.StatsDPrefix != nil {
+		settings["statsd_prefix"] = *p.StatsDPrefix
+	}
+
+	if p.CI != nil {
+		settings["ci"] = *p.CI
+	}
+
+	if p.ReuseTFDir != nil {
+		settings["reuse_terraform_dir"] = *p.ReuseTFDir
+	}
+
+	return settings
+}
+
+// decodeProjectLayout decodes the "layout" block. The naming must be slug or uid.
+func decodeProjectLayout(block *hcl.Block) (*ProjectLayout, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	layout := &ProjectLayout{}
+
+	content, moreDiags := block.Body.Content(projectLayoutSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	if a, ok := content.Attributes["naming"]; ok {
+		moreDiags := gohcl.DecodeExpression(a.Expr, nil, &layout.Naming)
+		diags = diags.Extend(moreDiags)
+		if !moreDiags.HasErrors() && !slices.Contains([]string{"slug", "uid"}, layout.Naming) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid value",
+				Detail:   "naming must be one of slug or uid, got " + layout.Naming,
+				Subject:  a.Expr.Range().Ptr(),
+				Context:  a.Range.Ptr(),
+			})
+		}
+	}
+
+	if a, ok := content.Attributes["nested"]; ok {
+		diags = diags.Extend(gohcl.DecodeExpression(a.Expr, nil, &layout.Nested))
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return layout, diags
+}
+
+// decodeProjectLock decodes the "lock" block. Platforms must be an OS and architecture, e.g.
+// linux_amd64. A relative file is relative to the directory of the project configuration file.
+func decodeProjectLock(block *hcl.Block, dir string) (*ProjectLock, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	lock := &ProjectLock{}
+
+	content, moreDiags := block.Body.Content(projectLockSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	if a, ok := content.Attributes["file"]; ok {
+		diags = diags.Extend(gohcl.DecodeExpression(a.Expr, nil, &lock.File))
+	}
+
+	if a, ok := content.Attributes["platforms"]; ok {
+		moreDiags := gohcl.DecodeExpression(a.Expr, nil, &lock.Platforms)
+		diags = diags.Extend(moreDiags)
+		if !moreDiags.HasErrors() {
+			for _, platform := range lock.Platforms {
+				if !lockPlatformPattern.MatchString(platform) {
+					diags = diags.Append(&hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "invalid value",
+						Detail:   "platforms must be an OS and architecture, e.g. linux_amd64, got " + platform,
+						Subject:  a.Expr.Range().Ptr(),
+						Context:  a.Range.Ptr(),
+					})
+				}
+			}
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if lock.File != "" && !filepath.IsAbs(lock.File) {
+		lock.File = filepath.Join(dir, lock.File)
+	}
+
+	return lock, diags
+}
+
+// decodeProjectWarnings decodes the "warnings" block. The max must not be negative and denied codes
+// must not be empty.
+func decodeProjectWarnings(block *hcl.Block) (*ProjectWarnings, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	warnings := &ProjectWarnings{}
+
+	content, moreDiags := block.Body.Content(projectWarningsSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	if a, ok := content.Attributes["max"]; ok {
+		var maxWarnings int32
+		moreDiags := gohcl.DecodeExpression(a.Expr, nil, &maxWarnings)
+		diags = diags.Extend(moreDiags)
+		if !moreDiags.HasErrors() {
+			if maxWarnings < 0 {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "invalid value",
+					Detail:   "max must not be negative",
+					Subject:  a.Expr.Range().Ptr(),
+					Context:  a.Range.Ptr(),
+				})
+			}
+			warnings.Max = &maxWarnings
+		}
+	}
+
+	if a, ok := content.Attributes["deny"]; ok {
+		moreDiags := gohcl.DecodeExpression(a.Expr, nil, &warnings.Deny)
+		diags = diags.Extend(moreDiags)
+		if !moreDiags.HasErrors() && slices.ContainsFunc(warnings.Deny, func(code string) bool {
+			return strings.TrimSpace(code) == ""
+		}) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid value",
+				Detail:   "deny must not contain empty codes",
+				Subject:  a.Expr.Range().Ptr(),
+				Context:  a.Range.Ptr(),
+			})
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return warnings, diags
+}
+
+// Check returns error diagnostics if the warnings of the diagnostics exceed the warning budget:
+// one for each denied code that some warnings have and one if there are more than the maximum
+// number of warnings.
+func (w *ProjectWarnings) Check(diags []*pb.Diagnostic) []*pb.Diagnostic {
+	res := []*pb.Diagnostic{}
+	if w == nil {
+		return res
+	}
+
+	count := 0
+	codes := map[string]int{}
+	for _, diag := range diags {
+		if diag.GetSeverity() != pb.Diagnostic_SEVERITY_WARNING {
+			continue
+		}
+
+		count++
+		if code := diag.GetCode(); code != "" {
+			codes[code]++
+		}
+	}
+
+	warnings := func(n int) string {
+		if n == 1 {
+			return "1 warning"
+		}
+
+		return fmt.Sprintf("%d warnings", n)
+	}
+
+	for _, code := range w.Deny {
+		n := codes[code]
+		if n == 0 {
+			continue
+		}
+
+		res = append(res, &pb.Diagnostic{
+			Severity: pb.Diagnostic_SEVERITY_ERROR,
+			Summary:  "denied warning " + code,
+			Detail: fmt.Sprintf("found %s with the code %s, which the project configuration denies",
+				warnings(n), code,
+			),
+		})
+	}
+
+	if w.Max != nil && count > int(*w.Max) {
+		res = append(res, &pb.Diagnostic{
+			Severity: pb.Diagnostic_SEVERITY_ERROR,
+			Summary:  "warning budget exceeded",
+			Detail: fmt.Sprintf("found %s, the project configuration allows at most %d",
+				warnings(count), *w.Max,
+			),
+		})
+	}
+
+	return res
+}
+
+// decodeProjectPolicy decodes the "policy" block. Costs must not be negative. Relative Rego paths
+// are relative to the directory of the project configuration file.
+func decodeProjectPolicy(block *hcl.Block, dir string) (*ProjectPolicy, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	policy := &ProjectPolicy{}
+
+	content, moreDiags := block.Body.Content(projectPolicySchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	for _, attr := range []struct {
+		name string
+		val  any
+	}{
+		{"max_hourly_cost", &policy.MaxHourlyCost},
+		{"max_monthly_cost", &policy.MaxMonthlyCost},
+		{"deny_resource_types", &policy.DenyResourceTypes},
+		{"deny_instance_types", &policy.DenyInstanceTypes},
+		{"rego", &policy.Rego},
+		{"opa_path", &policy.OPAPath},
+		{"allow_exec", &policy.AllowExec},
+		{"deny_exec", &policy.DenyExec},
+	} {
+		a, ok := content.Attributes[attr.name]
+		if !ok {
+			continue
+		}
+
+		moreDiags := gohcl.DecodeExpression(a.Expr, nil, attr.val)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		if c, ok := attr.val.(*float64); ok && *c < 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid value",
+				Detail:   attr.name + " must not be negative",
+				Subject:  a.Expr.Range().Ptr(),
+				Context:  a.Range.Ptr(),
+			})
+		}
+
+		if cmds, ok := attr.val.(*[]string); ok && (attr.name == "allow_exec" || attr.name == "deny_exec") {
+			for _, cmd := range *cmds {
+				if len(strings.Fields(cmd)) == 0 {
+					diags = diags.Append(&hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "invalid value",
+						Detail:   attr.name + " must not contain empty commands",
+						Subject:  a.Expr.Range().Ptr(),
+						Context:  a.Range.Ptr(),
+					})
+				}
+			}
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	for i, path := range policy.Rego {
+		if !filepath.IsAbs(path) {
+			policy.Rego[i] = filepath.Join(dir, path)
+		}
+	}
+
+	return policy, diags
+}
+
+// decodeProjectNotifier decodes a "notify" block. Slack notifiers need either a webhook_url or a
+// token and channel. GitHub notifiers need either a token or the app_id, installation_id, and
+// private_key of a GitHub App. Datadog notifiers don't need any settings.
+func decodeProjectNotifier(block *hcl.Block) (*ProjectNotifier, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	notifier := &ProjectNotifier{Type: block.Labels[0]}
+
+	schema, ok := projectNotifierSchemas[notifier.Type]
+	if !ok {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "unsupported notifier",
+			Detail: fmt.Sprintf("notifier type must be %s, %s, or %s, got %s",
+				NotifierTypeSlack, NotifierTypeGitHub, NotifierTypeDatadog, notifier.Type,
+			),
+			Subject: block.LabelRanges[0].Ptr(),
+			Context: block.DefRange.Ptr(),
+		})
+	}
+
+	content, moreDiags := block.Body.Content(schema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	for _, attr := range []struct {
+		name string
+		val  any
+	}{
+		{"webhook_url", &notifier.WebhookURL},
+		{"token", &notifier.Token},
+		{"channel", &notifier.Channel},
+		{"failures_only", &notifier.FailuresOnly},
+		{"links", &notifier.Links},
+		{"app_id", &notifier.AppID},
+		{"installation_id", &notifier.InstallationID},
+		{"private_key", &notifier.PrivateKey},
+		{"repository", &notifier.Repository},
+		{"sha", &notifier.SHA},
+		{"check_name", &notifier.CheckName},
+		{"api_url", &notifier.APIURL},
+		{"api_key", &notifier.APIKey},
+		{"site", &notifier.Site},
+		{"statsd_address", &notifier.StatsDAddress},
+		{"metric_prefix", &notifier.MetricPrefix},
+		{"tags", &notifier.Tags},
+	} {
+		if a, ok := content.Attributes[attr.name]; ok {
+			diags = diags.Extend(gohcl.DecodeExpression(a.Expr, nil, attr.val))
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	invalid := ""
+	switch notifier.Type {
+	case NotifierTypeSlack:
+		if notifier.WebhookURL == "" && (notifier.Token == "" || notifier.Channel == "") {
+			invalid = "slack notifiers must have a webhook_url, or a token and a channel"
+		}
+	case NotifierTypeGitHub:
+		if notifier.Token == "" &&
+			(notifier.AppID == "" || notifier.InstallationID == "" || notifier.PrivateKey == "") {
+			invalid = "github notifiers must have a token, or an app_id, installation_id, and private_key"
+		}
+	}
+	if invalid != "" {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid notifier",
+			Detail:   invalid,
+			Subject:  block.DefRange.Ptr(),
+		})
+	}
+
+	return notifier, diags
+}
+
+// decodeProjectPublisher decodes a "publish" block. Azure publishers need either a sas_token or an
+// account_key.
+func decodeProjectPublisher(block *hcl.Block) (*ProjectPublisher, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	publisher := &ProjectPublisher{Type: block.Labels[0]}
+
+	schema, ok := projectPublisherSchemas[publisher.Type]
+	if !ok {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "unsupported publisher",
+			Detail: fmt.Sprintf("publisher type must be %s, %s, or %s, got %s",
+				PublisherTypeS3, PublisherTypeGCS, PublisherTypeAzure, publisher.Type,
+			),
+			Subject: block.LabelRanges[0].Ptr(),
+			Context: block.DefRange.Ptr(),
+		})
+	}
+
+	content, moreDiags := block.Body.Content(schema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	for _, attr := range []struct {
+		name string
+		val  any
+	}{
+		{"bucket", &publisher.Bucket},
+		{"prefix", &publisher.Prefix},
+		{"run_id", &publisher.RunID},
+		{"paths", &publisher.Paths},
+		{"endpoint", &publisher.Endpoint},
+		{"region", &publisher.Region},
+		{"credentials", &publisher.Credentials},
+		{"token", &publisher.Token},
+		{"account", &publisher.Account},
+		{"container", &publisher.Container},
+		{"sas_token", &publisher.SASToken},
+		{"account_key", &publisher.AccountKey},
+	} {
+		if a, ok := content.Attributes[attr.name]; ok {
+			diags = diags.Extend(gohcl.DecodeExpression(a.Expr, nil, attr.val))
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if publisher.Type == PublisherTypeAzure && publisher.SASToken == "" && publisher.AccountKey == "" {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid publisher",
+			Detail:   "azure publishers must have a sas_token or an account_key",
+			Subject:  block.DefRange.Ptr(),
+		})
+	}
+
+	return publisher, diags
+}
+
+// isProjectConfigFile returns whether or not the path is a project configuration file.
+func isProjectConfigFile(path string) bool {
+	return ProjectConfigNamePattern.MatchString(filepath.Base(path))
+}
@@ -0,0 +1,786 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_ProjectConfig_Load tests loading project configuration files.
+func Test_ProjectConfig_Load(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		files    map[string]string
+		expected map[string]string
+		fail     bool
+	}{
+		"none": {
+			files:    map[string]string{},
+			expected: map[string]string{},
+		},
+		"dot file": {
+			files: map[string]string{
+				".enos.hcl": `
+out_dir            = "build"
+format             = "json"
+worker_count       = 8
+max_decode_workers = 0
+timeout            = "90m"
+lock_timeout       = "30s"
+strict_schema      = true
+fail_on_warnings   = false
+log_file           = "logs/enos.log"
+locale             = "de_DE.UTF-8"
+message_catalog    = "messages/de.json"
+statsd_address     = "statsd.example.com:8125"
+statsd_prefix      = "ci.enos"
+ci                 = "github-actions"
+
+lint {
+  rule "step_name" {
+    enabled = false
+  }
+}
+`,
+			},
+			expected: map[string]string{
+				"out_dir":            "build",
+				"format":             "json",
+				"worker_count":       "8",
+				"max_decode_workers": "0",
+				"timeout":            "1h30m0s",
+				"lock_timeout":       "30s",
+				"strict_schema":      "true",
+				"fail_on_warnings":   "false",
+				"log_file":           "logs/enos.log",
+				"locale":             "de_DE.UTF-8",
+				"message_catalog":    "messages/de.json",
+				"statsd_address":     "statsd.example.com:8125",
+				"statsd_prefix":      "ci.enos",
+				"ci":                 "github-actions",
+			},
+		},
+		"project file": {
+			files: map[string]string{
+				"enos.project.hcl": `timeout = "5m"`,
+			},
+			expected: map[string]string{
+				"timeout": "5m0s",
+			},
+		},
+		"multiple files": {
+			files: map[string]string{
+				".enos.hcl":        `timeout = "5m"`,
+				"enos.project.hcl": `timeout = "5m"`,
+			},
+			fail: true,
+		},
+		"unknown setting": {
+			files: map[string]string{
+				".enos.hcl": `parallelism = 4`,
+			},
+			fail: true,
+		},
+		"invalid format": {
+			files: map[string]string{
+				".enos.hcl": `format = "xml"`,
+			},
+			fail: true,
+		},
+		"invalid ci": {
+			files: map[string]string{
+				".enos.hcl": `ci = "jenkins"`,
+			},
+			fail: true,
+		},
+		"reuse terraform dir": {
+			files: map[string]string{
+				".enos.hcl": `reuse_terraform_dir = "copy"`,
+			},
+			expected: map[string]string{
+				"reuse_terraform_dir": "copy",
+			},
+		},
+		"invalid reuse terraform dir": {
+			files: map[string]string{
+				".enos.hcl": `reuse_terraform_dir = "hardlink"`,
+			},
+			fail: true,
+		},
+		"invalid locale": {
+			files: map[string]string{
+				".enos.hcl": `locale = "not a locale"`,
+			},
+			fail: true,
+		},
+		"invalid duration": {
+			files: map[string]string{
+				".enos.hcl": `timeout = "forever"`,
+			},
+			fail: true,
+		},
+		"invalid worker count": {
+			files: map[string]string{
+				".enos.hcl": `worker_count = 0`,
+			},
+			fail: true,
+		},
+		"not a literal": {
+			files: map[string]string{
+				".enos.hcl": `out_dir = var.out_dir`,
+			},
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for name, content := range test.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+			}
+
+			cfg := NewProjectConfig()
+			diags := cfg.Load(dir)
+			if test.fail {
+				require.True(t, diags.HasErrors())
+
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			// Relative out directories and message catalogs are relative to the project
+			// configuration file.
+			for _, setting := range []string{"out_dir", "message_catalog"} {
+				if path, ok := test.expected[setting]; ok {
+					test.expected[setting] = filepath.Join(dir, path)
+				}
+			}
+			require.Equal(t, test.expected, cfg.Settings())
+		})
+	}
+}
+
+// Test_ProjectConfig_Notifiers tests decoding the notify blocks of project configuration files.
+func Test_ProjectConfig_Notifiers(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		src      string
+		expected []*ProjectNotifier
+		fail     bool
+	}{
+		"webhook": {
+			src: `
+notify "slack" {
+  webhook_url   = "$SLACK_WEBHOOK_URL"
+  failures_only = true
+  links = {
+    "CI run" = "https://ci.example.com/runs/$CI_RUN_ID"
+  }
+}
+`,
+			expected: []*ProjectNotifier{
+				{
+					Type:         NotifierTypeSlack,
+					WebhookURL:   "$SLACK_WEBHOOK_URL",
+					FailuresOnly: true,
+					Links:        map[string]string{"CI run": "https://ci.example.com/runs/$CI_RUN_ID"},
+				},
+			},
+		},
+		"token and channel": {
+			src: `
+notify "slack" {
+  token   = "$SLACK_TOKEN"
+  channel = "#quality"
+}
+`,
+			expected: []*ProjectNotifier{
+				{
+					Type:    NotifierTypeSlack,
+					Token:   "$SLACK_TOKEN",
+					Channel: "#quality",
+				},
+			},
+		},
+		"token without channel": {
+			src:  `notify "slack" { token = "$SLACK_TOKEN" }`,
+			fail: true,
+		},
+		"github token": {
+			src: `
+notify "github" {
+  token      = "$GITHUB_TOKEN"
+  check_name = "enos / scenarios"
+}
+`,
+			expected: []*ProjectNotifier{
+				{
+					Type:      NotifierTypeGitHub,
+					Token:     "$GITHUB_TOKEN",
+					CheckName: "enos / scenarios",
+				},
+			},
+		},
+		"github app": {
+			src: `
+notify "github" {
+  app_id          = 1234
+  installation_id = "$GITHUB_INSTALLATION_ID"
+  private_key     = "$GITHUB_APP_PRIVATE_KEY"
+  repository      = "hashicorp/enos"
+}
+`,
+			expected: []*ProjectNotifier{
+				{
+					Type:           NotifierTypeGitHub,
+					AppID:          "1234",
+					InstallationID: "$GITHUB_INSTALLATION_ID",
+					PrivateKey:     "$GITHUB_APP_PRIVATE_KEY",
+					Repository:     "hashicorp/enos",
+				},
+			},
+		},
+		"github app without private key": {
+			src:  `notify "github" { app_id = 1234 }`,
+			fail: true,
+		},
+		"slack attribute of github": {
+			src:  `notify "github" { webhook_url = "https://example.com" }`,
+			fail: true,
+		},
+		"datadog": {
+			src: `
+notify "datadog" {
+  api_key = "$DD_API_KEY"
+  site    = "datadoghq.eu"
+  tags    = ["team:quality"]
+}
+`,
+			expected: []*ProjectNotifier{
+				{
+					Type:   NotifierTypeDatadog,
+					APIKey: "$DD_API_KEY",
+					Site:   "datadoghq.eu",
+					Tags:   []string{"team:quality"},
+				},
+			},
+		},
+		"unsupported type": {
+			src:  `notify "email" { webhook_url = "https://example.com" }`,
+			fail: true,
+		},
+		"unknown attribute": {
+			src: `
+notify "slack" {
+  webhook_url = "https://example.com"
+  on          = "failure"
+}
+`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewProjectConfig()
+			diags := cfg.Decode(filepath.Join(t.TempDir(), ".enos.hcl"), []byte(test.src))
+			if test.fail {
+				require.True(t, diags.HasErrors())
+
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.expected, cfg.Notifiers)
+		})
+	}
+}
+
+// Test_ProjectConfig_Publishers tests decoding the publish blocks of project configuration files.
+func Test_ProjectConfig_Publishers(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		src      string
+		expected []*ProjectPublisher
+		fail     bool
+	}{
+		"s3": {
+			src: `
+publish "s3" {
+  bucket = "enos-nightly"
+  region = "us-west-2"
+  prefix = "vault/$GITHUB_REF_NAME"
+  run_id = "$GITHUB_RUN_ID"
+  paths  = [".enos/reports", "*.tfstate"]
+}
+`,
+			expected: []*ProjectPublisher{
+				{
+					Type:   PublisherTypeS3,
+					Bucket: "enos-nightly",
+					Region: "us-west-2",
+					Prefix: "vault/$GITHUB_REF_NAME",
+					RunID:  "$GITHUB_RUN_ID",
+					Paths:  []string{".enos/reports", "*.tfstate"},
+				},
+			},
+		},
+		"gcs": {
+			src: `
+publish "gcs" {
+  bucket      = "enos-nightly"
+  credentials = "$GOOGLE_APPLICATION_CREDENTIALS"
+}
+`,
+			expected: []*ProjectPublisher{
+				{
+					Type:        PublisherTypeGCS,
+					Bucket:      "enos-nightly",
+					Credentials: "$GOOGLE_APPLICATION_CREDENTIALS",
+				},
+			},
+		},
+		"azure": {
+			src: `
+publish "azure" {
+  account   = "enosnightly"
+  container = "runs"
+  sas_token = "$AZURE_STORAGE_SAS_TOKEN"
+}
+`,
+			expected: []*ProjectPublisher{
+				{
+					Type:      PublisherTypeAzure,
+					Account:   "enosnightly",
+					Container: "runs",
+					SASToken:  "$AZURE_STORAGE_SAS_TOKEN",
+				},
+			},
+		},
+		"azure without credentials": {
+			src: `
+publish "azure" {
+  account   = "enosnightly"
+  container = "runs"
+}
+`,
+			fail: true,
+		},
+		"missing bucket": {
+			src:  `publish "s3" { region = "us-west-2" }`,
+			fail: true,
+		},
+		"azure attribute of s3": {
+			src:  `publish "s3" { bucket = "enos-nightly", sas_token = "sig" }`,
+			fail: true,
+		},
+		"unsupported type": {
+			src:  `publish "ftp" { bucket = "enos-nightly" }`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewProjectConfig()
+			diags := cfg.Decode(filepath.Join(t.TempDir(), ".enos.hcl"), []byte(test.src))
+			if test.fail {
+				require.True(t, diags.HasErrors())
+
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.expected, cfg.Publishers)
+		})
+	}
+}
+
+// Test_ProjectConfig_Tags tests decoding the tags of project configuration files.
+func Test_ProjectConfig_Tags(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		src      string
+		expected map[string]string
+		fail     bool
+	}{
+		"none": {
+			src: `timeout = "5m"`,
+		},
+		"tags": {
+			src: `
+tags = {
+  Project = "vault"
+  "cost-center" = "eng"
+}`,
+			expected: map[string]string{"Project": "vault", "cost-center": "eng"},
+		},
+		"not a map of strings": {
+			src:  `tags = ["vault"]`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewProjectConfig()
+			diags := cfg.Decode(filepath.Join(t.TempDir(), ".enos.hcl"), []byte(test.src))
+			if test.fail {
+				require.True(t, diags.HasErrors())
+
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.expected, cfg.Tags)
+		})
+	}
+}
+
+// Test_ProjectConfig_Policy tests decoding the policy block of project configuration.
+func Test_ProjectConfig_Policy(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		src      string
+		expected *ProjectPolicy
+		fail     bool
+	}{
+		"none": {
+			src: `timeout = "5m"`,
+		},
+		"policy": {
+			src: `
+policy {
+  max_monthly_cost    = 500
+  deny_resource_types = ["aws_nat_gateway"]
+  deny_instance_types = ["*.metal", "p4d.*"]
+}`,
+			expected: &ProjectPolicy{
+				MaxMonthlyCost:    500,
+				DenyResourceTypes: []string{"aws_nat_gateway"},
+				DenyInstanceTypes: []string{"*.metal", "p4d.*"},
+			},
+		},
+		"rego": {
+			src: `
+policy {
+  rego     = ["policies", "/etc/enos/policies"]
+  opa_path = "/usr/local/bin/opa"
+}`,
+			expected: &ProjectPolicy{
+				Rego:    []string{"<dir>/policies", "/etc/enos/policies"},
+				OPAPath: "/usr/local/bin/opa",
+			},
+		},
+		"exec": {
+			src: `
+policy {
+  allow_exec = ["plan", "output", "state show"]
+  deny_exec  = ["apply", "destroy", "import"]
+}`,
+			expected: &ProjectPolicy{
+				AllowExec: []string{"plan", "output", "state show"},
+				DenyExec:  []string{"apply", "destroy", "import"},
+			},
+		},
+		"empty exec command": {
+			src: `
+policy {
+  deny_exec = ["apply", " "]
+}`,
+			fail: true,
+		},
+		"negative cost": {
+			src: `
+policy {
+  max_hourly_cost = -1
+}`,
+			fail: true,
+		},
+		"unknown attribute": {
+			src: `
+policy {
+  max_cost = 1
+}`,
+			fail: true,
+		},
+		"duplicate": {
+			src: `
+policy {
+  max_hourly_cost = 1
+}
+policy {
+  max_hourly_cost = 2
+}`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			cfg := NewProjectConfig()
+			diags := cfg.Decode(filepath.Join(dir, ".enos.hcl"), []byte(test.src))
+			if test.fail {
+				require.True(t, diags.HasErrors())
+
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			if test.expected != nil {
+				for i, path := range test.expected.Rego {
+					test.expected.Rego[i] = strings.Replace(path, "<dir>", dir, 1)
+				}
+			}
+			require.Equal(t, test.expected, cfg.Policy)
+		})
+	}
+}
+
+// Test_ProjectConfig_Layout tests decoding the layout block of project configuration.
+func Test_ProjectConfig_Layout(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		src      string
+		expected *ProjectLayout
+		fail     bool
+	}{
+		"none": {
+			src: `timeout = "5m"`,
+		},
+		"layout": {
+			src: `
+layout {
+  naming = "uid"
+  nested = true
+}`,
+			expected: &ProjectLayout{Naming: "uid", Nested: true},
+		},
+		"nested": {
+			src: `
+layout {
+  nested = true
+}`,
+			expected: &ProjectLayout{Nested: true},
+		},
+		"invalid naming": {
+			src: `
+layout {
+  naming = "sha256"
+}`,
+			fail: true,
+		},
+		"duplicate": {
+			src: `
+layout {
+  naming = "slug"
+}
+layout {
+  naming = "uid"
+}`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewProjectConfig()
+			diags := cfg.Decode(filepath.Join(t.TempDir(), ".enos.hcl"), []byte(test.src))
+			if test.fail {
+				require.True(t, diags.HasErrors())
+
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.expected, cfg.Layout)
+		})
+	}
+}
+
+// Test_ProjectConfig_Lock tests decoding the lock block.
+func Test_ProjectConfig_Lock(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for desc, test := range map[string]struct {
+		src      string
+		expected *ProjectLock
+		fail     bool
+	}{
+		"none": {
+			src: `timeout = "5m"`,
+		},
+		"lock": {
+			src: `
+lock {
+  file      = "./locks/.terraform.lock.hcl"
+  platforms = ["linux_amd64", "darwin_arm64"]
+}`,
+			expected: &ProjectLock{
+				File:      filepath.Join(dir, "locks", ".terraform.lock.hcl"),
+				Platforms: []string{"linux_amd64", "darwin_arm64"},
+			},
+		},
+		"absolute file": {
+			src: `
+lock {
+  file = "/etc/enos/.terraform.lock.hcl"
+}`,
+			expected: &ProjectLock{File: "/etc/enos/.terraform.lock.hcl"},
+		},
+		"invalid platform": {
+			src: `
+lock {
+  platforms = ["linux"]
+}`,
+			fail: true,
+		},
+		"duplicate": {
+			src: `
+lock {
+  platforms = ["linux_amd64"]
+}
+lock {
+  platforms = ["darwin_arm64"]
+}`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewProjectConfig()
+			diags := cfg.Decode(filepath.Join(dir, ".enos.hcl"), []byte(test.src))
+			if test.fail {
+				require.True(t, diags.HasErrors())
+
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.expected, cfg.Lock)
+		})
+	}
+}
+
+// Test_ProjectConfig_Warnings tests decoding the warnings block and checking warning budgets.
+func Test_ProjectConfig_Warnings(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	maxWarnings := int32(2)
+
+	for desc, test := range map[string]struct {
+		src      string
+		expected *ProjectWarnings
+		fail     bool
+	}{
+		"none": {
+			src: `timeout = "5m"`,
+		},
+		"warnings": {
+			src: `
+warnings {
+  max  = 2
+  deny = ["scenario_description"]
+}`,
+			expected: &ProjectWarnings{Max: &maxWarnings, Deny: []string{"scenario_description"}},
+		},
+		"negative max": {
+			src: `
+warnings {
+  max = -1
+}`,
+			fail: true,
+		},
+		"empty code": {
+			src: `
+warnings {
+  deny = [""]
+}`,
+			fail: true,
+		},
+		"duplicate": {
+			src: `
+warnings {
+  max = 1
+}
+warnings {
+  max = 2
+}`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewProjectConfig()
+			diags := cfg.Decode(filepath.Join(dir, ".enos.hcl"), []byte(test.src))
+			if test.fail {
+				require.True(t, diags.HasErrors())
+
+				return
+			}
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.expected, cfg.Warnings)
+		})
+	}
+
+	warning := func(code string) *pb.Diagnostic {
+		return &pb.Diagnostic{Severity: pb.Diagnostic_SEVERITY_WARNING, Summary: "warning", Code: code}
+	}
+	errDiag := &pb.Diagnostic{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "error"}
+
+	for desc, test := range map[string]struct {
+		warnings *ProjectWarnings
+		diags    []*pb.Diagnostic
+		expected []string
+	}{
+		"no budget": {
+			diags:    []*pb.Diagnostic{warning(""), warning(""), warning("")},
+			expected: []string{},
+		},
+		"within budget": {
+			warnings: &ProjectWarnings{Max: &maxWarnings, Deny: []string{"step_name"}},
+			diags:    []*pb.Diagnostic{warning(""), warning("scenario_description"), errDiag},
+			expected: []string{},
+		},
+		"exceeds max": {
+			warnings: &ProjectWarnings{Max: &maxWarnings},
+			diags:    []*pb.Diagnostic{warning(""), warning(""), warning("")},
+			expected: []string{"found 3 warnings, the project configuration allows at most 2"},
+		},
+		"denied codes": {
+			warnings: &ProjectWarnings{Max: &maxWarnings, Deny: []string{"step_name", "scenario_description"}},
+			diags:    []*pb.Diagnostic{warning("step_name"), warning("scenario_description"), warning("scenario_description")},
+			expected: []string{
+				"found 1 warning with the code step_name, which the project configuration denies",
+				"found 2 warnings with the code scenario_description, which the project configuration denies",
+				"found 3 warnings, the project configuration allows at most 2",
+			},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			details := []string{}
+			for _, diag := range test.warnings.Check(test.diags) {
+				require.Equal(t, pb.Diagnostic_SEVERITY_ERROR, diag.GetSeverity())
+				details = append(details, diag.GetDetail())
+			}
+			require.Equal(t, test.expected, details)
+		})
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// moduleProviderRequirement is a required provider of a Terraform module.
+type moduleProviderRequirement struct {
+	source  string
+	aliases []string
+}
+
+// ProviderRequirementsValidator validates that the providers that scenarios supply to their steps
+// satisfy the required_providers of the step modules. Only modules with local sources are
+// inspected as any others would need to be downloaded during terraform init.
+type ProviderRequirementsValidator struct {
+	fp      *FlightPlan
+	modules map[string]map[string]*moduleProviderRequirement
+	seen    map[string]struct{}
+}
+
+// NewProviderRequirementsValidator returns a new ProviderRequirementsValidator for the flight plan.
+func NewProviderRequirementsValidator(fp *FlightPlan) *ProviderRequirementsValidator {
+	return &ProviderRequirementsValidator{
+		fp:      fp,
+		modules: map[string]map[string]*moduleProviderRequirement{},
+		seen:    map[string]struct{}{},
+	}
+}
+
+// Validate returns warning diagnostics for every step of the scenario whose providers don't satisfy
+// the required providers of its module. As the variants of a scenario usually share their steps,
+// diagnostics that have already been returned for a prior scenario are not returned again.
+func (v *ProviderRequirementsValidator) Validate(scenario *Scenario) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if v == nil || scenario == nil {
+		return diags
+	}
+
+	for _, step := range scenario.Steps {
+		if step.Module == nil {
+			continue
+		}
+
+		reqs, ok := v.moduleRequirements(step.Module.Source)
+		if !ok {
+			continue
+		}
+
+		rng := v.stepRange(scenario.Name, step.Name)
+		warn := func(summary, detail string) {
+			key := fmt.Sprintf("%s:%s:%s", rng.String(), summary, detail)
+			if _, ok := v.seen[key]; ok {
+				return
+			}
+			v.seen[key] = struct{}{}
+
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  summary,
+				Detail:   detail,
+				Subject:  rng.Ptr(),
+			})
+		}
+
+		names := []string{}
+		for name := range reqs {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		// Make sure every configuration alias that the module requires is passed by the step and
+		// that any default provider configuration that the module inherits has the same source.
+		for _, name := range names {
+			req := reqs[name]
+			for _, alias := range req.aliases {
+				if _, ok := step.Providers[alias]; !ok {
+					warn("missing provider configuration", fmt.Sprintf(
+						"module %s of step %s requires the provider configuration %s but the step does not pass it in its providers attribute",
+						step.Module.Name, step.Name, alias,
+					))
+				}
+			}
+
+			if _, ok := step.Providers[name]; ok {
+				continue
+			}
+
+			if src, ok := rootProviderSource(scenario, name); ok && src != req.source {
+				warn("provider source mismatch", fmt.Sprintf(
+					"module %s of step %s requires provider %s from %s but the scenario configures it from %s",
+					step.Module.Name, step.Name, name, req.source, src,
+				))
+			}
+		}
+
+		imports := []string{}
+		for importName := range step.Providers {
+			imports = append(imports, importName)
+		}
+		slices.Sort(imports)
+
+		// Make sure every provider that the step passes is declared by the module and has the
+		// same source.
+		for _, importName := range imports {
+			provider := step.Providers[importName]
+			name, _, isAlias := strings.Cut(importName, ".")
+
+			req, ok := reqs[name]
+			if !ok {
+				warn("unexpected provider configuration", fmt.Sprintf(
+					"step %s passes the provider configuration %s but module %s does not declare a required provider named %s",
+					step.Name, importName, step.Module.Name, name,
+				))
+
+				continue
+			}
+
+			if isAlias && !slices.Contains(req.aliases, importName) {
+				warn("unexpected provider configuration", fmt.Sprintf(
+					"step %s passes the provider configuration %s but module %s does not declare it in the configuration_aliases of provider %s",
+					step.Name, importName, step.Module.Name, name,
+				))
+			}
+
+			if src, ok := rootProviderSource(scenario, provider.Type); ok && src != req.source {
+				warn("provider source mismatch", fmt.Sprintf(
+					"step %s passes provider %s.%s from %s as %s but module %s requires it from %s",
+					step.Name, provider.Type, provider.Alias, src, importName, step.Module.Name, req.source,
+				))
+			}
+		}
+	}
+
+	return diags
+}
+
+// moduleRequirements returns the required providers of a module with a local source. The result
+// will be cached for any further steps that use the same module.
+func (v *ProviderRequirementsValidator) moduleRequirements(source string) (map[string]*moduleProviderRequirement, bool) {
+	dir, ok := localModuleDir(source, v.fp.BaseDir)
+	if !ok {
+		return nil, false
+	}
+
+	if reqs, ok := v.modules[dir]; ok {
+		return reqs, reqs != nil
+	}
+
+	reqs := readModuleProviderRequirements(dir)
+	v.modules[dir] = reqs
+
+	return reqs, reqs != nil
+}
+
+// stepRange returns the definition range of the step block of the scenario.
+func (v *ProviderRequirementsValidator) stepRange(scenarioName, stepName string) hcl.Range {
+	if v.fp.BodyContent == nil {
+		return hcl.Range{}
+	}
+
+	for _, block := range v.fp.BodyContent.Blocks.OfType(blockTypeScenario) {
+		if block.Labels[0] != scenarioName {
+			continue
+		}
+
+		body, ok := block.Body.(*hclsyntax.Body)
+		if !ok {
+			return block.DefRange
+		}
+
+		for _, step := range body.Blocks {
+			if step.Type == blockTypeScenarioStep && len(step.Labels) > 0 && step.Labels[0] == stepName {
+				if attr, ok := step.Body.Attributes["providers"]; ok {
+					return attr.SrcRange
+				}
+
+				return step.AsHCLBlock().DefRange
+			}
+		}
+
+		return block.DefRange
+	}
+
+	return hcl.Range{}
+}
+
+// readModuleProviderRequirements reads the required providers from the Terraform files in the
+// module directory. If the directory can't be read or doesn't contain any Terraform files we
+// return nil as we're unable to determine the requirements.
+func readModuleProviderRequirements(dir string) map[string]*moduleProviderRequirement {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil || len(paths) == 0 {
+		return nil
+	}
+
+	reqs := map[string]*moduleProviderRequirement{}
+	parser := hclparse.NewParser()
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		file, diags := parser.ParseHCL(src, path)
+		if diags.HasErrors() {
+			return nil
+		}
+
+		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeTerraformSetting}},
+		})
+		for _, tf := range content.Blocks {
+			tfContent, _, _ := tf.Body.PartialContent(&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeRequiredProviders}},
+			})
+			for _, rp := range tfContent.Blocks {
+				attrs, _ := rp.Body.JustAttributes()
+				for name, attr := range attrs {
+					reqs[name] = decodeModuleProviderRequirement(name, attr.Expr)
+				}
+			}
+		}
+	}
+
+	return reqs
+}
+
+// decodeModuleProviderRequirement decodes a required provider of a Terraform module. Required
+// providers are either objects or version constraint strings for legacy modules.
+func decodeModuleProviderRequirement(name string, expr hcl.Expression) *moduleProviderRequirement {
+	req := &moduleProviderRequirement{source: "hashicorp/" + name}
+
+	pairs, diags := hcl.ExprMap(expr)
+	if diags.HasErrors() {
+		return req
+	}
+
+	for _, pair := range pairs {
+		key := hcl.ExprAsKeyword(pair.Key)
+		if key == "" {
+			val, diags := pair.Key.Value(nil)
+			if diags.HasErrors() || !val.Type().Equals(cty.String) || !val.IsKnown() || val.IsNull() {
+				continue
+			}
+			key = val.AsString()
+		}
+
+		switch key {
+		case "source":
+			val, diags := pair.Value.Value(nil)
+			if diags.HasErrors() || !val.Type().Equals(cty.String) || !val.IsKnown() || val.IsNull() {
+				continue
+			}
+			req.source = normalizeProviderSource(val.AsString())
+		case "configuration_aliases":
+			exprs, diags := hcl.ExprList(pair.Value)
+			if diags.HasErrors() {
+				continue
+			}
+
+			for _, expr := range exprs {
+				traversal, diags := hcl.AbsTraversalForExpr(expr)
+				if diags.HasErrors() || len(traversal) != 2 {
+					continue
+				}
+
+				attr, ok := traversal[1].(hcl.TraverseAttr)
+				if !ok {
+					continue
+				}
+				req.aliases = append(req.aliases, traversal.RootName()+"."+attr.Name)
+			}
+		default:
+		}
+	}
+
+	return req
+}
+
+// rootProviderSource returns the source of the provider in the scenario root module, if the
+// scenario configures the required providers of the root module.
+func rootProviderSource(scenario *Scenario, name string) (string, bool) {
+	if scenario.TerraformSetting == nil || len(scenario.TerraformSetting.RequiredProviders) == 0 {
+		return "", false
+	}
+
+	val, ok := scenario.TerraformSetting.RequiredProviders[name]
+	if !ok || val.IsNull() || !val.IsWhollyKnown() || !val.CanIterateElements() {
+		return "", false
+	}
+
+	src, ok := val.AsValueMap()["source"]
+	if !ok || src.IsNull() || !src.Type().Equals(cty.String) {
+		return "hashicorp/" + name, true
+	}
+
+	return normalizeProviderSource(src.AsString()), true
+}
+
+// normalizeProviderSource returns the provider source address without the default registry
+// hostname so that sources that only differ by whether or not they include it are the same.
+func normalizeProviderSource(source string) string {
+	source = strings.ToLower(source)
+
+	return strings.TrimPrefix(source, "registry.terraform.io/")
+}
@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_ProviderRequirementsValidator_Validate tests validating the providers that steps supply
+// against the required providers of their modules.
+func Test_ProviderRequirementsValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	modulePath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(modulePath, "main.tf"), []byte(`
+terraform {
+  required_providers {
+    aws = {
+      source                = "hashicorp/aws"
+      configuration_aliases = [aws.east]
+    }
+
+    enos = {
+      source = "registry.terraform.io/hashicorp-forge/enos"
+    }
+  }
+}
+`), 0o600))
+
+	for desc, test := range map[string]struct {
+		providers string
+		enos      string
+		expected  []string
+	}{
+		"satisfied": {
+			providers: `{ "aws.east" = provider.aws.east }`,
+			enos:      "hashicorp-forge/enos",
+			expected:  []string{},
+		},
+		"missing alias": {
+			providers: `{}`,
+			enos:      "hashicorp-forge/enos",
+			expected: []string{
+				"module backend of step backend requires the provider configuration aws.east but the step does not pass it in its providers attribute",
+			},
+		},
+		"wrong source": {
+			providers: `{ "aws.east" = provider.aws.east }`,
+			enos:      "hashicorp/enos",
+			expected: []string{
+				"module backend of step backend requires provider enos from hashicorp-forge/enos but the scenario configures it from hashicorp/enos",
+			},
+		},
+		"undeclared": {
+			providers: `{ "aws.east" = provider.aws.east, "aws.west" = provider.aws.west }`,
+			enos:      "hashicorp-forge/enos",
+			expected: []string{
+				"step backend passes the provider configuration aws.west but module backend does not declare it in the configuration_aliases of provider aws",
+			},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+terraform "default" {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+
+    enos = {
+      source = "%s"
+    }
+  }
+}
+
+provider "aws" "east" {
+  region = "us-east-1"
+}
+
+provider "aws" "west" {
+  region = "us-west-1"
+}
+
+module "backend" {
+  source = "%s"
+}
+
+scenario "test" {
+  terraform = terraform.default
+
+  step "backend" {
+    module    = module.backend
+    providers = %s
+  }
+}
+`, test.enos, modulePath, test.providers)), DecodeTargetAll)
+			require.NoError(t, err)
+
+			validator := NewProviderRequirementsValidator(fp)
+			details := []string{}
+			for _, scenario := range fp.Scenarios() {
+				// Validate twice to make sure diagnostics are only returned once.
+				for range 2 {
+					for _, diag := range validator.Validate(scenario) {
+						details = append(details, diag.Detail)
+					}
+				}
+			}
+			require.ElementsMatch(t, test.expected, details)
+		})
+	}
+}
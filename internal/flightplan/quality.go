@@ -138,7 +138,7 @@ func (q *Quality) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics
 	diags := hcl.Diagnostics{}
 
 	content, moreDiags := block.Body.Content(qualitySchema)
-	diags = diags.Extend(moreDiags)
+	diags = diags.Extend(withMissingAttributeFixes(moreDiags, block, "description"))
 	if moreDiags != nil && moreDiags.HasErrors() {
 		return diags
 	}
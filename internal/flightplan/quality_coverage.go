@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"slices"
+	"strings"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// QualityCoverage tracks which of the qualities of a flight plan are verified by the steps and
+// outputs of decoded scenarios so that it can report the qualities that no scenario verifies.
+// If it has a sample frame only the scenarios in the frame are tracked.
+type QualityCoverage struct {
+	fp        *FlightPlan
+	frame     *SampleFrame
+	scenarios map[string][]string // quality name -> scenario filters
+}
+
+// QualityCoverageOpt is a functional option for NewQualityCoverage.
+type QualityCoverageOpt func(*QualityCoverage)
+
+// NewQualityCoverage returns a new QualityCoverage for the flight plan.
+func NewQualityCoverage(fp *FlightPlan, opts ...QualityCoverageOpt) *QualityCoverage {
+	c := &QualityCoverage{
+		fp:        fp,
+		scenarios: map[string][]string{},
+	}
+
+	for i := range opts {
+		opts[i](c)
+	}
+
+	return c
+}
+
+// WithQualityCoverageSampleFrame only tracks the scenarios that are in the sample frame.
+func WithQualityCoverageSampleFrame(frame *SampleFrame) QualityCoverageOpt {
+	return func(c *QualityCoverage) {
+		c.frame = frame
+	}
+}
+
+// Track records the qualities that the steps and outputs of a decoded scenario verify.
+func (c *QualityCoverage) Track(scenario *Scenario) {
+	if c == nil || scenario == nil || !c.inFrame(scenario) {
+		return
+	}
+
+	filter := scenario.String()
+	for _, qual := range scenario.verifies() {
+		c.scenarios[qual.GetName()] = append(c.scenarios[qual.GetName()], filter)
+	}
+}
+
+// Coverage returns the coverage of every quality of the flight plan, sorted by name, and the
+// qualities that none of the tracked scenarios verify. It must only be called after every scenario
+// has been tracked.
+func (c *QualityCoverage) Coverage() ([]*pb.Quality_Coverage, []*pb.Quality) {
+	coverage := []*pb.Quality_Coverage{}
+	unverified := []*pb.Quality{}
+	if c == nil || c.fp == nil {
+		return coverage, unverified
+	}
+
+	for _, qual := range c.fp.Qualities {
+		scenarios := slices.Clone(c.scenarios[qual.Name])
+		slices.Sort(scenarios)
+		coverage = append(coverage, &pb.Quality_Coverage{
+			Quality:   qual.ToProto(),
+			Scenarios: scenarios,
+		})
+		if len(scenarios) == 0 {
+			unverified = append(unverified, qual.ToProto())
+		}
+	}
+
+	slices.SortStableFunc(coverage, func(a, b *pb.Quality_Coverage) int {
+		return strings.Compare(a.GetQuality().GetName(), b.GetQuality().GetName())
+	})
+	slices.SortStableFunc(unverified, CompareQualityProto)
+
+	return coverage, unverified
+}
+
+// inFrame returns whether or not the scenario is an element of any subset frame of the sample
+// frame. Every scenario is in the frame if we don't have one.
+func (c *QualityCoverage) inFrame(scenario *Scenario) bool {
+	if c.frame == nil {
+		return true
+	}
+
+	for _, subset := range c.frame.SubsetFrames {
+		if subset == nil || subset.ScenarioFilter.GetName() != scenario.Name {
+			continue
+		}
+
+		// Subset frames without a matrix are either a scenario without variants or a matrix
+		// that didn't match any variants.
+		if subset.Matrix == nil {
+			if subset.SampleSubset == nil || subset.SampleSubset.Matrix == nil ||
+				len(subset.SampleSubset.Matrix.Vectors) == 0 {
+				return scenario.Variants == nil || len(scenario.Variants.Elements()) == 0
+			}
+
+			continue
+		}
+
+		if subset.Matrix.ContainsVectorUnordered(scenario.Variants) {
+			return true
+		}
+	}
+
+	return false
+}
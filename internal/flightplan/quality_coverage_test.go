@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_QualityCoverage tests tracking which scenarios verify the qualities of a flight plan.
+func Test_QualityCoverage(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+quality "tests_pass" {
+  description = "The tests pass"
+}
+
+quality "data_is_durable" {
+  description = "The data is durable"
+}
+
+quality "is_fast" {
+  description = "It is fast"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  step "backend" {
+    module   = module.backend
+    verifies = quality.tests_pass
+  }
+
+  output "addrs" {
+    value    = step.backend.addrs
+    verifies = matrix.arch == "arm64" ? quality.data_is_durable : quality.tests_pass
+  }
+}
+
+scenario "smoke" {
+  step "backend" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+
+	qualityScenarios := func(coverage []*pb.Quality_Coverage) map[string][]string {
+		res := map[string][]string{}
+		for _, c := range coverage {
+			res[c.GetQuality().GetName()] = c.GetScenarios()
+		}
+
+		return res
+	}
+	names := func(qualities []*pb.Quality) []string {
+		res := []string{}
+		for _, q := range qualities {
+			res = append(res, q.GetName())
+		}
+
+		return res
+	}
+
+	t.Run("all scenarios", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewQualityCoverage(fp)
+		for _, s := range fp.Scenarios() {
+			c.Track(s)
+		}
+
+		coverage, unverified := c.Coverage()
+		require.Equal(t, []string{"data_is_durable", "is_fast", "tests_pass"}, names([]*pb.Quality{
+			coverage[0].GetQuality(), coverage[1].GetQuality(), coverage[2].GetQuality(),
+		}))
+		require.Equal(t, map[string][]string{
+			"data_is_durable": {"upgrade [arch:arm64]"},
+			"is_fast":         nil,
+			"tests_pass":      {"upgrade [arch:amd64]", "upgrade [arch:arm64]"},
+		}, qualityScenarios(coverage))
+		require.Equal(t, []string{"is_fast"}, names(unverified))
+	})
+
+	t.Run("sample frame", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewQualityCoverage(fp, WithQualityCoverageSampleFrame(&SampleFrame{
+			SubsetFrames: SampleSubsetFrames{
+				"amd64": {
+					SampleSubset:   &SampleSubset{Name: "amd64"},
+					ScenarioFilter: &pb.Scenario_Filter{Name: "upgrade"},
+					Matrix:         &Matrix{Vectors: []*Vector{NewVector(NewElement("arch", "amd64"))}},
+				},
+				"smoke": {
+					SampleSubset:   &SampleSubset{Name: "smoke"},
+					ScenarioFilter: &pb.Scenario_Filter{Name: "smoke"},
+				},
+			},
+		}))
+		for _, s := range fp.Scenarios() {
+			c.Track(s)
+		}
+
+		coverage, unverified := c.Coverage()
+		require.Equal(t, map[string][]string{
+			"data_is_durable": nil,
+			"is_fast":         nil,
+			"tests_pass":      {"upgrade [arch:amd64]"},
+		}, qualityScenarios(coverage))
+		require.Equal(t, []string{"data_is_durable", "is_fast"}, names(unverified))
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"context"
+	"fmt"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// RemoteModuleTracker tracks the unique remote module sources of the steps of decoded scenarios so
+// that they can be verified or fetched without generating and initializing the scenarios.
+type RemoteModuleTracker struct {
+	fp      *FlightPlan
+	modules []*remoteModule
+	seen    map[string]struct{}
+}
+
+type remoteModule struct {
+	name   string
+	source *ModuleSource
+	err    error
+}
+
+// NewRemoteModuleTracker returns a new RemoteModuleTracker for the flight plan.
+func NewRemoteModuleTracker(fp *FlightPlan) *RemoteModuleTracker {
+	return &RemoteModuleTracker{
+		fp:   fp,
+		seen: map[string]struct{}{},
+	}
+}
+
+// Track records the module sources of the scenario steps.
+func (t *RemoteModuleTracker) Track(scenario *Scenario) {
+	if t == nil || scenario == nil {
+		return
+	}
+
+	for _, step := range scenario.Steps {
+		if step.Module == nil {
+			continue
+		}
+
+		key := step.Module.Name + "\x00" + step.Module.Source + "\x00" + step.Module.Version
+		if _, ok := t.seen[key]; ok {
+			continue
+		}
+		t.seen[key] = struct{}{}
+
+		src, err := ParseModuleSource(step.Module.Source, step.Module.Version)
+		if err == nil && src.Type == ModuleSourceTypeLocal {
+			continue
+		}
+
+		t.modules = append(t.modules, &remoteModule{name: step.Module.Name, source: src, err: err})
+	}
+}
+
+// Verify verifies that all tracked module sources are valid and exist.
+func (t *RemoteModuleTracker) Verify(ctx context.Context, fetcher *ModuleFetcher) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if t == nil {
+		return diags
+	}
+
+	for _, mod := range t.modules {
+		err := mod.err
+		if err == nil {
+			err = fetcher.Verify(ctx, mod.source)
+		}
+
+		if err != nil {
+			diags = diags.Append(t.moduleDiagnostic(mod.name, "invalid module source", err))
+		}
+	}
+
+	return diags
+}
+
+// Fetch fetches all tracked module sources that can be fetched and returns the fetched modules.
+func (t *RemoteModuleTracker) Fetch(
+	ctx context.Context,
+	fetcher *ModuleFetcher,
+) ([]*pb.FetchScenarioModulesResponse_Module, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	fetched := []*pb.FetchScenarioModulesResponse_Module{}
+
+	if t == nil {
+		return fetched, diags
+	}
+
+	for _, mod := range t.modules {
+		if mod.err != nil {
+			diags = diags.Append(t.moduleDiagnostic(mod.name, "invalid module source", mod.err))
+
+			continue
+		}
+
+		if mod.source.Type == ModuleSourceTypeUnsupported {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "unable to fetch module",
+				Detail: fmt.Sprintf("module %s source %s cannot be fetched, only registry and git modules are supported",
+					mod.name, mod.source.Source,
+				),
+				Subject: t.moduleSourceRange(mod.name),
+			})
+
+			continue
+		}
+
+		dir, err := fetcher.Fetch(ctx, mod.source)
+		if err != nil {
+			diags = diags.Append(t.moduleDiagnostic(mod.name, "unable to fetch module", err))
+
+			continue
+		}
+
+		fetched = append(fetched, &pb.FetchScenarioModulesResponse_Module{
+			Name:    mod.name,
+			Source:  mod.source.Source,
+			Version: mod.source.Version,
+			Dir:     dir,
+		})
+	}
+
+	return fetched, diags
+}
+
+func (t *RemoteModuleTracker) moduleDiagnostic(name string, summary string, err error) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  summary,
+		Detail:   err.Error(),
+		Subject:  t.moduleSourceRange(name),
+	}
+}
+
+// moduleSourceRange returns the range of the source attribute of the module block.
+func (t *RemoteModuleTracker) moduleSourceRange(name string) *hcl.Range {
+	if t.fp == nil || t.fp.BodyContent == nil {
+		return nil
+	}
+
+	for _, block := range t.fp.BodyContent.Blocks.OfType(blockTypeModule) {
+		if block.Labels[0] != name {
+			continue
+		}
+
+		if body, ok := block.Body.(*hclsyntax.Body); ok {
+			if attr, ok := body.Attributes["source"]; ok {
+				return attr.Expr.Range().Ptr()
+			}
+		}
+
+		return block.DefRange.Ptr()
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"runtime"
+	"unsafe"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ResourceLimits limit the resources that we'll use when decoding a flight plan. A nil or zero
+// value ResourceLimits does not limit anything.
+type ResourceLimits struct {
+	// MaxWorkers is the maximum number of goroutines that will decode concurrently.
+	MaxWorkers int
+	// MaxMatrixBytes is a hint of the maximum memory that expanding a single matrix may use.
+	MaxMatrixBytes uint64
+}
+
+// NewResourceLimits takes the wire resource limits and returns new ResourceLimits.
+func NewResourceLimits(l *pb.ResourceLimits) *ResourceLimits {
+	if l == nil {
+		return nil
+	}
+
+	return &ResourceLimits{
+		MaxWorkers:     int(l.GetMaxDecodeWorkers()),
+		MaxMatrixBytes: l.GetMaxMatrixMemory(),
+	}
+}
+
+// WithDecoderResourceLimits sets the resource limits for the decoder.
+func WithDecoderResourceLimits(limits *pb.ResourceLimits) DecoderOpt {
+	return func(d *Decoder) error {
+		d.limits = NewResourceLimits(limits)
+
+		return nil
+	}
+}
+
+// Workers returns the number of decode workers to use. Without a limit we'll use one per CPU.
+func (l *ResourceLimits) Workers() int {
+	workers := runtime.NumCPU()
+	if l != nil && l.MaxWorkers > 0 {
+		workers = min(workers, l.MaxWorkers)
+	}
+
+	return workers
+}
+
+// matrixBytes returns the maximum number of bytes that expanding a matrix may use. Zero means
+// there is no limit.
+func (l *ResourceLimits) matrixBytes() uint64 {
+	if l == nil {
+		return 0
+	}
+
+	return l.MaxMatrixBytes
+}
+
+// vectorBytes returns an estimate of the memory used by the vector when it's part of a matrix. The
+// element strings are shared with the vectors that the product was created from so we only
+// count the element headers.
+func vectorBytes(vec *Vector) uint64 {
+	if vec == nil {
+		return 0
+	}
+
+	return uint64(unsafe.Sizeof(vec)) + uint64(unsafe.Sizeof(*vec)) +
+		uint64(len(vec.elements))*uint64(unsafe.Sizeof(Element{}))
+}
@@ -198,13 +198,12 @@ func decodeAndValidateSampleAttrs(attr *hcl.Attribute, ctx *hcl.EvalContext) (ct
 	}
 
 	if !val.IsWhollyKnown() {
-		return cty.UnknownVal(val.Type()), diags.Append(&hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "sample attributes must be knowable",
-			Detail:   "all sample attributes must be knowable when decoding samples",
-			Subject:  attr.NameRange.Ptr(),
-			Context:  attr.Range.Ptr(),
-		})
+		return cty.UnknownVal(val.Type()), diags.Append(unknownValueDiagnostic(
+			"sample attributes must be knowable",
+			"all sample attributes must be knowable when decoding samples, they can refer to variables "+
+				"and functions of known values but not to the outputs of steps, fixtures, or scenarios",
+			attr.Expr, ctx, attr.NameRange.Ptr(), attr.Range.Ptr(),
+		))
 	}
 
 	if !val.CanIterateElements() {
@@ -253,6 +252,7 @@ func decodeSamples(
 		ws.GetFlightplan(),
 		DecodeTargetSamples,
 		nil,
+		WithDecoderResourceLimits(ws.GetLimits()),
 	)
 	if diagnostics.HasFailed(
 		ws.GetTfExecCfg().GetFailOnWarnings(),
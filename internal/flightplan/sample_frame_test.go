@@ -153,6 +153,7 @@ sample "all" {
 							Filter:   "baz",
 							Variants: nil,
 							Uid:      "baa5a0964d3320fbc0c6a922140453c8513ea24ab8fd0577034804a967248096",
+							Slug:     "baz_baa5a096",
 						},
 					},
 					Attributes: &structpb.Struct{
@@ -188,7 +189,8 @@ sample "all" {
 								{Key: "length", Value: "fl1"},
 								{Key: "width", Value: "fw1"},
 							}},
-							Uid: "ed19801704ae6d375ac09ff073d79284b20e62f60d49763558bcd0916997e7a4",
+							Uid:  "ed19801704ae6d375ac09ff073d79284b20e62f60d49763558bcd0916997e7a4",
+							Slug: "foo_fl1_fw1_ed198017",
 						},
 					},
 					Attributes: &structpb.Struct{
@@ -213,7 +215,8 @@ sample "all" {
 								{Key: "length", Value: "fl1"},
 								{Key: "width", Value: "fw2"},
 							}},
-							Uid: "eb2c78bab08044b69415f9f3ba3efdb7a467e86af7012963c6b53b1bd847f708",
+							Uid:  "eb2c78bab08044b69415f9f3ba3efdb7a467e86af7012963c6b53b1bd847f708",
+							Slug: "foo_fl1_fw2_eb2c78ba",
 						},
 					},
 					Attributes: &structpb.Struct{
@@ -238,7 +241,8 @@ sample "all" {
 								{Key: "length", Value: "fl2"},
 								{Key: "width", Value: "fw1"},
 							}},
-							Uid: "5c36259d9aea446acb34e78a6633e8b3155febe562b8a4d813b724582d36f040",
+							Uid:  "5c36259d9aea446acb34e78a6633e8b3155febe562b8a4d813b724582d36f040",
+							Slug: "foo_fl2_fw1_5c36259d",
 						},
 					},
 					Attributes: &structpb.Struct{
@@ -263,7 +267,8 @@ sample "all" {
 								{Key: "length", Value: "fl2"},
 								{Key: "width", Value: "fw2"},
 							}},
-							Uid: "349a31acc232614b257b1ced5ac5ed5d393a4dc8f771ac97457d94f2587336e2",
+							Uid:  "349a31acc232614b257b1ced5ac5ed5d393a4dc8f771ac97457d94f2587336e2",
+							Slug: "foo_fl2_fw2_349a31ac",
 						},
 					},
 					Attributes: &structpb.Struct{
@@ -300,7 +305,8 @@ sample "all" {
 								{Key: "length", Value: "bl1"},
 								{Key: "width", Value: "bw1"},
 							}},
-							Uid: "de8d9dc11abad83db3fd15f12b8dce9b146ab0853bf48bcf16ded3997db7f0a0",
+							Uid:  "de8d9dc11abad83db3fd15f12b8dce9b146ab0853bf48bcf16ded3997db7f0a0",
+							Slug: "bar_bl1_bw1_de8d9dc1",
 						},
 					},
 					Attributes: &structpb.Struct{
@@ -325,7 +331,8 @@ sample "all" {
 								{Key: "length", Value: "bl1"},
 								{Key: "width", Value: "bw2"},
 							}},
-							Uid: "25b3607735cd66c02cd84aadffaa754a2ace079c5927b6f1c34ab65c76aacc95",
+							Uid:  "25b3607735cd66c02cd84aadffaa754a2ace079c5927b6f1c34ab65c76aacc95",
+							Slug: "bar_bl1_bw2_25b36077",
 						},
 					},
 					Attributes: &structpb.Struct{
@@ -350,7 +357,8 @@ sample "all" {
 								{Key: "length", Value: "bl2"},
 								{Key: "width", Value: "bw1"},
 							}},
-							Uid: "89e14db1d1403490173b1667ebbf41f455944ba2ef300388fa4acd49dca562d6",
+							Uid:  "89e14db1d1403490173b1667ebbf41f455944ba2ef300388fa4acd49dca562d6",
+							Slug: "bar_bl2_bw1_89e14db1",
 						},
 					},
 					Attributes: &structpb.Struct{
@@ -375,7 +383,8 @@ sample "all" {
 								{Key: "length", Value: "bl2"},
 								{Key: "width", Value: "bw2"},
 							}},
-							Uid: "b8f6d457be68608102b3603c16e5b96be64d3583ec5a18a6f34d95caae62c040",
+							Uid:  "b8f6d457be68608102b3603c16e5b96be64d3583ec5a18a6f34d95caae62c040",
+							Slug: "bar_bl2_bw2_b8f6d457",
 						},
 					},
 					Attributes: &structpb.Struct{
@@ -123,6 +123,54 @@ func WithSampleObservationReqRandSeed(seed int64) SampleObservationOpt {
 
 // Observe returns a sample observation.
 func (s *SampleObservationReq) Observe(ctx context.Context) (*pb.Sample_Observation, *pb.DecodeResponse) {
+	res, sampleObservation, decRes := s.observe(ctx)
+	if sampleObservation == nil {
+		return res, decRes
+	}
+
+	// Convert our observation to wire elements and expand attributes.
+	var err error
+	res.Elements, err = sampleObservation.Elements(s.Rand)
+	if err != nil {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(err)...)
+	}
+
+	return res, decRes
+}
+
+// ObserveEach takes a func that is called with the elements of each subset as soon as they have
+// been expanded. The elements are given in the same order as Observe(). It returns the observation
+// without any elements. An error is only returned if the func returns an error.
+func (s *SampleObservationReq) ObserveEach(
+	ctx context.Context,
+	fn func([]*pb.Sample_Element) error,
+) (*pb.Sample_Observation, *pb.DecodeResponse, error) {
+	res, sampleObservation, decRes := s.observe(ctx)
+	if sampleObservation == nil {
+		return res, decRes, nil
+	}
+
+	var fnErr error
+	err := sampleObservation.EachElements(s.Rand, func(elements []*pb.Sample_Element) error {
+		fnErr = fn(elements)
+
+		return fnErr
+	})
+	if fnErr != nil {
+		return res, decRes, fnErr
+	}
+	if err != nil {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(err)...)
+	}
+
+	return res, decRes, nil
+}
+
+// observe decodes the sample frame and takes an observation of it. If the decode has failed the
+// sample observation will be nil.
+func (s *SampleObservationReq) observe(
+	ctx context.Context,
+) (*pb.Sample_Observation, *SampleObservation, *pb.DecodeResponse) {
 	res := &pb.Sample_Observation{
 		Filter: s.Filter,
 	}
@@ -133,7 +181,7 @@ func (s *SampleObservationReq) Observe(ctx context.Context) (*pb.Sample_Observat
 		s.Ws.GetTfExecCfg().GetFailOnWarnings(),
 		decRes.GetDiagnostics(),
 	) {
-		return res, decRes
+		return res, nil, decRes
 	}
 	if decRes == nil {
 		decRes = &pb.DecodeResponse{}
@@ -145,13 +193,7 @@ func (s *SampleObservationReq) Observe(ctx context.Context) (*pb.Sample_Observat
 		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(err)...)
 	}
 
-	// Convert our observation to wire elements and expand attributes.
-	res.Elements, err = sampleObservation.Elements(s.Rand)
-	if err != nil {
-		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(err)...)
-	}
-
-	return res, decRes
+	return res, sampleObservation, decRes
 }
 
 // Frame returns the valid sample Frame.
@@ -176,40 +218,62 @@ func (s *SampleObservation) Size() int32 {
 // Elements takes a random source, expands all of the sample elements in all frames, and returns
 // the elements in the wire format.
 func (s *SampleObservation) Elements(r *rand.Rand) ([]*pb.Sample_Element, error) {
+	res := []*pb.Sample_Element{}
+	err := s.EachElements(r, func(elements []*pb.Sample_Element) error {
+		res = append(res, elements...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure our elements are sorted by sample, subset, and scenario
+	slices.SortStableFunc(res, compareSampleElements)
+
+	return res, nil
+}
+
+// EachElements takes a random source and a func that is called with the expanded sample elements
+// of each subset in the wire format. Subsets are handled in order and each subsets elements are
+// sorted by scenario. If the func returns an error we'll stop and return it.
+func (s *SampleObservation) EachElements(r *rand.Rand, fn func([]*pb.Sample_Element) error) error {
 	if s == nil || s.SampleFrame == nil || len(s.SubsetObservations) < 1 {
-		return []*pb.Sample_Element{}, nil
+		return nil
 	}
 
-	res := []*pb.Sample_Element{}
 	for _, name := range s.SubsetObservations.Keys() {
 		subsetObsv, ok := s.SubsetObservations[name]
 		if !ok {
 			// This should never happen but because Keys() isn't a range over the collection directly we
 			// could theoretically nil panic here if Keys() gives us invalid results.
-			return nil, errors.New("failed to get observation elements for subset")
+			return errors.New("failed to get observation elements for subset")
 		}
 		subElements, err := s.SampleFrame.Elements(subsetObsv.SampleSubset.Name, r, subsetObsv.Matrix)
 		if err != nil {
-			return nil, fmt.Errorf("attemping to get elements from subset %s: %w", subsetObsv.SampleSubset.Name, err)
+			return fmt.Errorf("attemping to get elements from subset %s: %w", subsetObsv.SampleSubset.Name, err)
 		}
 
-		res = append(res, subElements...)
+		slices.SortStableFunc(subElements, compareSampleElements)
+		if err := fn(subElements); err != nil {
+			return err
+		}
 	}
 
-	// Make sure our elements are sorted by sample, subset, and scenario
-	slices.SortStableFunc(res, func(a, b *pb.Sample_Element) int {
-		if n := cmp.Compare(a.GetSample().GetId().GetName(), b.GetSample().GetId().GetName()); n != 0 {
-			return n
-		}
+	return nil
+}
 
-		if n := cmp.Compare(a.GetSubset().GetId().GetName(), b.GetSubset().GetId().GetName()); n != 0 {
-			return n
-		}
+// compareSampleElements compares sample elements by sample, subset, and scenario.
+func compareSampleElements(a, b *pb.Sample_Element) int {
+	if n := cmp.Compare(a.GetSample().GetId().GetName(), b.GetSample().GetId().GetName()); n != 0 {
+		return n
+	}
 
-		return cmp.Compare(a.GetScenario().GetId().GetFilter(), b.GetScenario().GetId().GetFilter())
-	})
+	if n := cmp.Compare(a.GetSubset().GetId().GetName(), b.GetSubset().GetId().GetName()); n != 0 {
+		return n
+	}
 
-	return res, nil
+	return cmp.Compare(a.GetScenario().GetId().GetFilter(), b.GetScenario().GetId().GetFilter())
 }
 
 // Size is the size of the sample subset observation.
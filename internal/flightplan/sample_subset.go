@@ -70,6 +70,7 @@ func (s *SampleSubset) Frame(ctx context.Context, ws *pb.Workspace) (*SampleSubs
 	// in a combined matrix that we can use as the frame matrix.
 	fp, scenarioDecoder, decRes := DecodeProto(
 		ctx, ws.GetFlightplan(), DecodeTargetScenariosMatrixOnly, sf.Proto(),
+		WithDecoderResourceLimits(ws.GetLimits()),
 	)
 	if diagnostics.HasFailed(ws.GetTfExecCfg().GetFailOnWarnings(), decRes.GetDiagnostics()) {
 		return nil, decRes
@@ -166,7 +167,7 @@ func (s *SampleSubset) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagno
 	}
 
 	// Decode the matrix block if there is one.
-	decodedMatrices, moreDiags := decodeMatrix(ctx, block)
+	decodedMatrices, moreDiags := decodeMatrix(ctx, block, nil, nil)
 	diags = diags.Extend(moreDiags)
 	if moreDiags != nil && moreDiags.HasErrors() {
 		return diags
@@ -221,12 +222,12 @@ func decodeSampleSubsetFieldString(name string, attrs hcl.Attributes, ctx *hcl.E
 	}
 
 	if !val.IsWhollyKnown() {
-		return "", diags.Append(&hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  fmt.Sprintf("value of %s must be knowable", name),
-			Subject:  f.NameRange.Ptr(),
-			Context:  f.Range.Ptr(),
-		})
+		return "", diags.Append(unknownValueDiagnostic(
+			fmt.Sprintf("value of %s must be knowable", name),
+			fmt.Sprintf("the value of %s must be known when decoding samples, it can refer to variables "+
+				"and functions of known values", name),
+			f.Expr, ctx, f.NameRange.Ptr(), f.Range.Ptr(),
+		))
 	}
 
 	if !val.Type().Equals(cty.String) {
@@ -319,7 +319,7 @@ sample "foo" {
 		t.Run(desc, func(t *testing.T) {
 			t.Parallel()
 
-			fp, err := testDecodeHCL(t, []byte(test.body), DecodeTargetSamples, test.env...)
+			fp, err := testDecodeHCL(t, []byte(test.body), DecodeTargetSamples, WithDecoderEnv(test.env))
 			if test.fail {
 				require.Error(t, err)
 
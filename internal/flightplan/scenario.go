@@ -14,6 +14,7 @@ import (
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
 
+	"github.com/hashicorp/enos/internal/diagnostics"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	hcl "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -22,6 +23,8 @@ import (
 var scenarioSchema = &hcl.BodySchema{
 	Attributes: []hcl.AttributeSchema{
 		{Name: "description", Required: false},
+		{Name: "tags", Required: false},
+		{Name: "xfail", Required: false},
 		{Name: "terraform_cli", Required: false},
 		{Name: "terraform", Required: false},
 		{Name: "providers", Required: false},
@@ -33,9 +36,24 @@ var scenarioSchema = &hcl.BodySchema{
 		// so we can decode without using partials.
 		{Type: blockTypeMatrix},
 		{Type: blockTypeLocals},
+		{Type: blockTypeOverrides},
 	},
 }
 
+var overridesSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeRequiredProviders},
+		{Type: blockTypeBackend, LabelNames: []string{attrLabelNameDefault}},
+	},
+}
+
+const (
+	// scenarioSlugMaxPrefixLen is the maximum length of the name and variants in a slug.
+	scenarioSlugMaxPrefixLen = 64
+	// scenarioSlugUIDLen is the length of the UID prefix in a slug.
+	scenarioSlugUIDLen = 8
+)
+
 var matrixSchema = &hcl.BodySchema{
 	Blocks: []hcl.BlockHeaderSchema{
 		{Type: blockTypeMatrix},
@@ -46,12 +64,24 @@ var matrixSchema = &hcl.BodySchema{
 type Scenario struct {
 	Name             string
 	Description      string
+	Tags             []string
 	Variants         *Vector
 	TerraformCLI     *TerraformCLI
 	TerraformSetting *TerraformSetting
 	Steps            []*ScenarioStep
 	Providers        []*Provider
 	Outputs          []*ScenarioOutput
+	// Overrides are the required_providers and backend that are written to an override file of
+	// the generated module, which take precedence over those of the terraform settings.
+	Overrides *TerraformSetting
+	// Fixtures are the names of the fixtures that the scenario refers to.
+	Fixtures []string
+	// Producers are the names of the scenarios whose outputs the scenario refers to.
+	Producers []string
+	// XFail is the reason that the scenario is expected to fail, e.g. a link to the bug that it
+	// reproduces. Scenarios without a reason are expected to pass.
+	XFail    string
+	DefRange hcl.Range
 }
 
 // NewScenario returns a new Scenario.
@@ -79,6 +109,31 @@ func (s *Scenario) UID() string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(s.String())))
 }
 
+// Slug returns a short, stable, and filesystem safe identifier from the name and variant values.
+// As variant values alone might be ambiguous, it always ends with the prefix of the UID.
+func (s *Scenario) Slug() string {
+	parts := []string{s.Name}
+	if s.Variants != nil {
+		for _, elm := range s.Variants.elements {
+			parts = append(parts, elm.Val)
+		}
+	}
+
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, strings.Join(parts, "_"))
+	if len(slug) > scenarioSlugMaxPrefixLen {
+		slug = slug[:scenarioSlugMaxPrefixLen]
+	}
+
+	return slug + "_" + s.UID()[:scenarioSlugUIDLen]
+}
+
 // Ref returns the proto reference.
 func (s *Scenario) Ref() *pb.Ref_Scenario {
 	return &pb.Ref_Scenario{
@@ -87,11 +142,39 @@ func (s *Scenario) Ref() *pb.Ref_Scenario {
 			Description: s.Description,
 			Variants:    s.Variants.Proto(),
 			Uid:         s.UID(),
+			Slug:        s.Slug(),
 			Filter:      s.FilterStr(),
 		},
 	}
 }
 
+// RefWithMetadata returns the scenario as a reference that includes its metadata.
+func (s *Scenario) RefWithMetadata() *pb.Ref_Scenario {
+	ref := s.Ref()
+	ref.Metadata = s.Metadata()
+
+	return ref
+}
+
+// Metadata returns the tags, the location, the qualities that the steps of the scenario verify,
+// and why it's expected to fail, or nil if the scenario has none of them.
+func (s *Scenario) Metadata() *pb.Scenario_Metadata {
+	md := &pb.Scenario_Metadata{
+		Tags:     slices.Clone(s.Tags),
+		Verifies: s.verifies(),
+		Xfail:    s.XFail,
+	}
+	if s.DefRange.Filename != "" {
+		md.Range = diagnostics.FromHCLRange(s.DefRange)
+	}
+
+	if len(md.GetTags()) == 0 && md.GetRange() == nil && len(md.GetVerifies()) == 0 && md.GetXfail() == "" {
+		return nil
+	}
+
+	return md
+}
+
 func (s *Scenario) FilterStr() string {
 	str := s.Name
 	if s.Variants != nil && len(s.Variants.elements) > 0 {
@@ -118,6 +201,12 @@ func (s *Scenario) Match(filter *ScenarioFilter) bool {
 		return false
 	}
 
+	return filter.matchSets(s.matchFilter)
+}
+
+// matchFilter determines whether or not the scenario matches the filter without the filters that
+// it is combined with.
+func (s *Scenario) matchFilter(filter *ScenarioFilter) bool {
 	if filter.SelectAll {
 		return true
 	}
@@ -167,17 +256,43 @@ func (s *Scenario) Outline() *pb.Scenario_Outline {
 	}
 	// Outlines are not currently specific to individual scenarios so we'll remove that metadata
 	out.Scenario.Id.Uid = ""
+	out.Scenario.Id.Slug = ""
 	out.Scenario.Id.Filter = ""
 	out.Scenario.Id.Variants = nil
 
+	if s.DefRange.Filename != "" {
+		out.Range = diagnostics.FromHCLRange(s.DefRange)
+	}
+
+	if len(s.Tags) > 0 {
+		out.Scenario.Metadata = &pb.Scenario_Metadata{Tags: slices.Clone(s.Tags)}
+	}
+
+	for _, step := range s.Steps {
+		out.Steps = append(out.GetSteps(), step.outline())
+	}
+	for _, output := range s.Outputs {
+		out.Outputs = append(out.GetOutputs(), output.outline())
+	}
+	out.Verifies = s.verifies()
+
+	return out
+}
+
+// verifies returns the unique qualities that the steps and outputs of the scenario verify, sorted.
+func (s *Scenario) verifies() []*pb.Quality {
 	// Create a set of qualities we verify
 	qualities := map[string]*pb.Quality{}
 	for _, step := range s.Steps {
-		out.Steps = append(out.GetSteps(), step.outline())
 		for _, qual := range step.Verifies {
 			qualities[qual.Name] = qual.ToProto()
 		}
 	}
+	for _, output := range s.Outputs {
+		for _, qual := range output.Verifies {
+			qualities[qual.Name] = qual.ToProto()
+		}
+	}
 
 	// Sort them
 	verifies := []*pb.Quality{}
@@ -185,9 +300,8 @@ func (s *Scenario) Outline() *pb.Scenario_Outline {
 		verifies = append(verifies, qual)
 	}
 	slices.SortStableFunc(verifies, CompareQualityProto)
-	out.Verifies = verifies
 
-	return out
+	return verifies
 }
 
 // decode takes an HCL block and an evaluation context and it decodes itself
@@ -197,6 +311,7 @@ func (s *Scenario) decode(block *hcl.Block, ctx *hcl.EvalContext, target DecodeT
 	diags := hcl.Diagnostics{}
 
 	s.Name = block.Labels[0]
+	s.DefRange = block.DefRange
 
 	if target < DecodeTargetScenariosComplete {
 		return diags
@@ -229,6 +344,13 @@ func (s *Scenario) decode(block *hcl.Block, ctx *hcl.EvalContext, target DecodeT
 		s.Description = val.AsString()
 	}
 
+	// Decode our scenario tags
+	moreDiags = s.decodeAndValidateTagsAttribute(content, ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
 	// Decode our locals
 	moreDiags = s.decodeAndValidateLocalsBlock(content, ctx)
 	diags = diags.Extend(moreDiags)
@@ -236,6 +358,13 @@ func (s *Scenario) decode(block *hcl.Block, ctx *hcl.EvalContext, target DecodeT
 		return diags
 	}
 
+	// Decode why the scenario is expected to fail
+	moreDiags = s.decodeAndValidateXFailAttribute(content, ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
 	// Decode the scenario terraform_cli reference
 	moreDiags = s.decodeAndValidateTerraformCLIAttribute(content, ctx)
 	diags = diags.Extend(moreDiags)
@@ -250,6 +379,13 @@ func (s *Scenario) decode(block *hcl.Block, ctx *hcl.EvalContext, target DecodeT
 		return diags
 	}
 
+	// Decode the scenario overrides
+	moreDiags = s.decodeAndValidateOverridesBlock(content, ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
 	// Decode the scenario providers
 	moreDiags = s.decodeAndValidateProvidersAttribute(content, ctx)
 	diags = diags.Extend(moreDiags)
@@ -271,6 +407,18 @@ func (s *Scenario) decode(block *hcl.Block, ctx *hcl.EvalContext, target DecodeT
 		return diags
 	}
 
+	s.Fixtures = s.fixtureReferences()
+	s.Producers = s.producerReferences()
+	if slices.Contains(s.Producers, s.Name) {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "scenario refers to its own outputs",
+			Detail:   fmt.Sprintf("scenario %s can only refer to the outputs of other scenarios", s.Name),
+			Subject:  block.TypeRange.Ptr(),
+			Context:  hcl.RangeBetween(block.TypeRange, block.DefRange).Ptr(),
+		})
+	}
+
 	return diags
 }
 
@@ -334,6 +482,49 @@ func (s *Scenario) decodeAndValidateLocalsBlock(
 	return diags
 }
 
+// decodeAndValidateOverridesBlock decodes the overrides block. Only a single overrides block is
+// allowed.
+func (s *Scenario) decodeAndValidateOverridesBlock(
+	content *hcl.BodyContent,
+	ctx *hcl.EvalContext,
+) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for i, block := range content.Blocks.OfType(blockTypeOverrides) {
+		if i != 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "redefined block",
+				Detail:   "only one overrides block is allowed to be defined",
+				Subject:  block.TypeRange.Ptr(),
+				Context:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+
+		moreDiags := verifyBlockHasNLabels(block, 0)
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		overridesContent, moreDiags := block.Body.Content(overridesSchema)
+		diags = diags.Extend(moreDiags)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			continue
+		}
+
+		overrides := NewTerraformSetting()
+		diags = diags.Extend(overrides.decodeRequiredProviders(ctx, overridesContent))
+		diags = diags.Extend(overrides.decodeBackend(ctx, overridesContent))
+
+		s.Overrides = overrides
+	}
+
+	return diags
+}
+
 // decodeAndValidateTerraformCLIAttribute decodess the terraform_cli attribute
 // from the content and validates that it refers to an existing terraform_cli.
 func (s *Scenario) decodeAndValidateTerraformCLIAttribute(
@@ -537,7 +728,7 @@ func (s *Scenario) decodeAndValidateTerraformSettingsAttribute(
 	return diags
 }
 
-// decodeAndValidateProvidersAttribute decodess the providers attribute
+// decodeAndValidateProvidersAttribute decodes the providers attribute
 // from the content and validates that each sub-attribute references a defined
 // provider.
 func (s *Scenario) decodeAndValidateProvidersAttribute(
@@ -710,6 +901,85 @@ func (s *Scenario) decodeAndValidateProvidersAttribute(
 	return diags
 }
 
+// decodeAndValidateTagsAttribute decodes the tags attribute, which must be a list or set of
+// non-empty strings. Tags are sorted and duplicates are removed.
+func (s *Scenario) decodeAndValidateTagsAttribute(
+	content *hcl.BodyContent,
+	ctx *hcl.EvalContext,
+) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	tags, ok := content.Attributes["tags"]
+	if !ok {
+		return diags
+	}
+
+	s.Tags = []string{}
+	moreDiags := gohcl.DecodeExpression(tags.Expr, ctx, &s.Tags)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		s.Tags = nil
+
+		return diags
+	}
+
+	for _, tag := range s.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid scenario tag",
+				Detail:   "scenario tags must be non-empty strings",
+				Subject:  tags.Expr.Range().Ptr(),
+				Context:  tags.Range.Ptr(),
+			})
+		}
+	}
+
+	slices.Sort(s.Tags)
+	s.Tags = slices.Compact(s.Tags)
+
+	return diags
+}
+
+// decodeAndValidateXFailAttribute decodes the xfail attribute, which must be a non-empty string or
+// null. As it's evaluated per variant it can depend on the matrix to only expect some variants to
+// fail.
+func (s *Scenario) decodeAndValidateXFailAttribute(
+	content *hcl.BodyContent,
+	ctx *hcl.EvalContext,
+) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	xfail, ok := content.Attributes["xfail"]
+	if !ok {
+		return diags
+	}
+
+	val, moreDiags := xfail.Expr.Value(ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	if val.IsNull() {
+		return diags
+	}
+
+	if !val.IsWhollyKnown() || !val.Type().Equals(cty.String) || strings.TrimSpace(val.AsString()) == "" {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid scenario xfail",
+			Detail:   "scenario xfail must be a non-empty string that describes why the scenario is expected to fail, or null",
+			Subject:  xfail.Expr.Range().Ptr(),
+			Context:  xfail.Range.Ptr(),
+		})
+	}
+
+	s.XFail = strings.TrimSpace(val.AsString())
+
+	return diags
+}
+
 func (s *Scenario) decodeAndValidateStepBlocks(
 	content *hcl.BodyContent,
 	ctx *hcl.EvalContext,
@@ -717,6 +987,13 @@ func (s *Scenario) decodeAndValidateStepBlocks(
 	diags := hcl.Diagnostics{}
 	foundSteps := map[string]struct{}{}
 
+	// Make sure our steps don't refer to each other in a cycle before we decode them, otherwise
+	// we'd only fail on references to steps that have not been decoded yet.
+	moreDiags := verifyStepReferencesAreAcyclic(content.Blocks.OfType(blockTypeScenarioStep))
+	if moreDiags.HasErrors() {
+		return diags.Extend(moreDiags)
+	}
+
 	for _, childBlock := range content.Blocks.OfType(blockTypeScenarioStep) {
 		if _, dupeStep := foundSteps[childBlock.Labels[0]]; dupeStep {
 			diags = diags.Append(&hcl.Diagnostic{
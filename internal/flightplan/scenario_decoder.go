@@ -19,6 +19,9 @@ type ScenarioDecoder struct {
 	DecodeTarget
 	*ScenarioFilter
 	Blocks []*hcl.Block
+	Timer  *DecodeTimer
+	Limits *ResourceLimits
+	Skips  []*Skip
 }
 
 // ScenarioBlock represents a decoded "scenario" block. It, along with a vector from the MatrixBlock,
@@ -47,6 +50,10 @@ type ScenarioDecodeResponse struct {
 	*Scenario
 	hcl.Diagnostics
 	*ScenarioDecodeRequest
+
+	// ScenarioEvalContext is the eval context that the scenario was decoded with. It has the
+	// matrix, locals, and steps of the scenario.
+	ScenarioEvalContext *hcl.EvalContext
 }
 
 // ScenarioDecoderOpt is a scenario decoder option.
@@ -80,6 +87,27 @@ func WithScenarioDecoderBlocks(b []*hcl.Block) func(*ScenarioDecoder) {
 	}
 }
 
+// WithScenarioDecoderTimer sets the timer used to record scenario block decode timings.
+func WithScenarioDecoderTimer(t *DecodeTimer) func(*ScenarioDecoder) {
+	return func(d *ScenarioDecoder) {
+		d.Timer = t
+	}
+}
+
+// WithScenarioDecoderResourceLimits sets the resource limits used when decoding scenarios.
+func WithScenarioDecoderResourceLimits(l *ResourceLimits) func(*ScenarioDecoder) {
+	return func(d *ScenarioDecoder) {
+		d.Limits = l
+	}
+}
+
+// WithScenarioDecoderSkips sets the skips that exclude scenarios and variants from the decode.
+func WithScenarioDecoderSkips(skips []*Skip) func(*ScenarioDecoder) {
+	return func(d *ScenarioDecoder) {
+		d.Skips = skips
+	}
+}
+
 // NewScenarioDecoder takes any number of scenario decoder opts and returns a new scenario decoder.
 // If the scenario decoder has not been configured in a valid way an error will be returned.
 func NewScenarioDecoder(opts ...ScenarioDecoderOpt) (*ScenarioDecoder, error) {
@@ -175,7 +203,8 @@ func (d *ScenarioDecoder) DecodeAll(ctx context.Context, fp *FlightPlan) hcl.Dia
 		return diags
 	}
 
-	if iter.Count() == 0 {
+	fp.Skipped = iter.Skipped()
+	if iter.Count() == 0 && len(fp.Skipped) == 0 {
 		return diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  fmt.Sprintf("no scenarios matched filter criteria: %+v", iter.filter),
@@ -194,7 +223,12 @@ func (d *ScenarioDecoder) Iterator() *ScenarioDecoderIterator {
 		return nil
 	}
 
-	return NewScenarioDecoderIterator(d.EvalContext, d.DecodeTarget, d.ScenarioFilter, d.Blocks)
+	iter := NewScenarioDecoderIterator(d.EvalContext, d.DecodeTarget, d.ScenarioFilter, d.Blocks)
+	iter.timer = d.Timer
+	iter.limits = d.Limits
+	iter.skips = d.Skips
+
+	return iter
 }
 
 // Matrix returns the Scenario matrices Cartesian Product.
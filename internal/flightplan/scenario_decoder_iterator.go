@@ -7,8 +7,6 @@ import (
 	"cmp"
 	"context"
 	"fmt"
-	"math"
-	"runtime"
 	"slices"
 	"sync"
 	"time"
@@ -35,6 +33,12 @@ type ScenarioDecoderIterator struct {
 	mustDispatch   int
 	haveReturned   int
 	cancel         func()
+	timer          *DecodeTimer
+	limits         *ResourceLimits
+	skips          []*Skip
+	skippedMu      sync.Mutex
+	skipped        []*SkippedScenario
+	skippedBlocks  map[string]bool // blocks whose every variant has been skipped
 }
 
 func NewScenarioDecoderIterator(
@@ -244,7 +248,8 @@ func (d *ScenarioDecoderIterator) decodeScenarioBlocks(ctx context.Context) hcl.
 		return diags.Extend(moreDiags)
 	}
 
-	return diags
+	// Make sure that none of our scenarios would share an output directory
+	return diags.Extend(verifyUniqueScenarioIdentifiers(d.scenarioBlocks))
 }
 
 // decodeScenarioBlocksMatrix decodes the matrix block for each scenario block. We do this in
@@ -280,11 +285,20 @@ func (d *ScenarioDecoderIterator) decodeScenarioBlocksMatrix(ctx context.Context
 		}
 	}()
 
-	// Decode and filter each matrix in parallel
+	// Decode and filter each matrix in parallel. If we've been limited to a number of workers we'll
+	// only decode that many matrices at a time.
+	var workerC chan struct{}
+	if d.limits != nil && d.limits.MaxWorkers > 0 {
+		workerC = make(chan struct{}, d.limits.Workers())
+	}
 	for _, scenarioBlock := range d.scenarioBlocks {
 		wgDecode.Add(1)
 		go func() {
 			defer wgDecode.Done()
+			if workerC != nil {
+				workerC <- struct{}{}
+				defer func() { <-workerC }()
+			}
 			d.decodeMatrix(ctx, scenarioBlock, diagC)
 		}()
 	}
@@ -294,6 +308,16 @@ func (d *ScenarioDecoderIterator) decodeScenarioBlocksMatrix(ctx context.Context
 	close(diagCloseC)
 	wgDiag.Wait()
 
+	// Remove any scenario blocks whose variants have all been skipped
+	d.scenarioBlocks = slices.DeleteFunc(d.scenarioBlocks, func(block *ScenarioBlock) bool {
+		return d.skippedBlocks[block.Name]
+	})
+
+	// Only keep the scenarios and variants of our shard
+	if d.filter != nil {
+		d.scenarioBlocks = d.filter.Shard.shardScenarioBlocks(d.scenarioBlocks)
+	}
+
 	// Calculate our expected decode range
 	for _, scenarioBlock := range d.scenarioBlocks {
 		if scenarioBlock.Matrix() == nil {
@@ -337,7 +361,7 @@ func (d *ScenarioDecoderIterator) startDecoder(ctx context.Context) hcl.Diagnost
 		// number of scenarios. It's not worth the concurrency machinery on weak machines or when we
 		// only have a few scenarios blocks to decode.
 		switch {
-		case d.mustDispatch < 100, runtime.NumCPU() < 2:
+		case d.mustDispatch < 100, d.limits.Workers() < 2:
 			d.decodeWorkerWg.Add(1)
 			go d.startDecodingScenariosSerially(ctx, d.decodeTarget)
 		default:
@@ -377,8 +401,12 @@ func (d *ScenarioDecoderIterator) decodeMatrix(
 		return
 	}
 
+	defer d.timer.Record(blockTypeMatrix, block.Name, time.Now())
+
+	// Our filter is applied while we build the matrix product so we only ever create the vectors
+	// that would have matched it.
 	var moreDiags hcl.Diagnostics
-	block.MatrixBlock, moreDiags = decodeMatrix(d.evalCtx.NewChild(), block.Block)
+	block.MatrixBlock, moreDiags = decodeMatrix(d.evalCtx.NewChild(), block.Block, d.filter, d.limits)
 	if moreDiags.HasErrors() {
 		select {
 		case <-ctx.Done():
@@ -386,16 +414,10 @@ func (d *ScenarioDecoderIterator) decodeMatrix(
 		}
 	}
 
-	if block.Matrix() != nil && len(block.Matrix().GetVectors()) > 1 {
-		if d.filter != nil {
-			// Filter if we've been given one.
-			block.MatrixBlock.Filter(d.filter)
-		}
-
-		// Always sort our matrix so that we give deterministic results on small sets. The nature of
-		// our streaming decoding does not guarantee ordering.
-		block.MatrixBlock.Sort()
-	}
+	// Always sort our matrix so that we give deterministic results on small sets. The nature of
+	// our streaming decoding does not guarantee ordering.
+	block.MatrixBlock.Sort()
+	d.skipMatrixVectors(block)
 }
 
 // filterHCLBlocks takes a slice of hcl.Blocks's and creates our initial collection of
@@ -409,7 +431,12 @@ func (d *ScenarioDecoderIterator) filterHCLBlocks() hcl.Diagnostics {
 	for i := range d.blocks {
 		// If we've got a filter that includes a name and our scenario block doesn't
 		// match we don't need to decode anything.
-		if d.filter != nil && d.filter.Name != "" && d.blocks[i].Labels[0] != d.filter.Name {
+		if len(d.blocks[i].Labels) > 0 && !d.filter.matchScenarioName(d.blocks[i].Labels[0]) {
+			continue
+		}
+
+		// Likewise if we've got a skip for the whole scenario block.
+		if len(d.blocks[i].Labels) > 0 && d.skipScenarioBlock(d.blocks[i].Labels[0]) {
 			continue
 		}
 
@@ -486,7 +513,7 @@ func (d *ScenarioDecoderIterator) startDecodingScenariosSerially(
 			default:
 			}
 
-			res := decodeScenario(&ScenarioDecodeRequest{
+			res := d.decodeScenario(&ScenarioDecodeRequest{
 				Vector:        nil,
 				ScenarioBlock: sb,
 				DecodeTarget:  decodeTarget,
@@ -508,7 +535,7 @@ func (d *ScenarioDecoderIterator) startDecodingScenariosSerially(
 			default:
 			}
 
-			res := decodeScenario(&ScenarioDecodeRequest{
+			res := d.decodeScenario(&ScenarioDecodeRequest{
 				Vector:        sb.Matrix().GetVectors()[i],
 				ScenarioBlock: sb,
 				DecodeTarget:  decodeTarget,
@@ -543,7 +570,7 @@ func (d *ScenarioDecoderIterator) startDecodingScenariosConcurrently(
 			case <-ctx.Done():
 				return
 			case req := <-reqC:
-				res := decodeScenario(req)
+				res := d.decodeScenario(req)
 				select {
 				case <-ctx.Done():
 					return
@@ -554,7 +581,7 @@ func (d *ScenarioDecoderIterator) startDecodingScenariosConcurrently(
 	}
 
 	// Start decode workers
-	for range int(math.Max(float64(runtime.NumCPU()), float64(2))) {
+	for range max(d.limits.Workers(), 2) {
 		d.decodeWorkerWg.Add(1)
 		go decodeScenarioWorker(ctx)
 	}
@@ -603,6 +630,13 @@ func (d *ScenarioDecoderIterator) startDecodingScenariosConcurrently(
 	}
 }
 
+// decodeScenario decodes the scenario and records how long it took.
+func (d *ScenarioDecoderIterator) decodeScenario(req *ScenarioDecodeRequest) *ScenarioDecodeResponse {
+	defer d.timer.Record(blockTypeScenario, req.ScenarioBlock.Name, time.Now())
+
+	return decodeScenario(req)
+}
+
 // decodeScenario configures a child eval context and decodes the scenario.
 func decodeScenario(req *ScenarioDecodeRequest) *ScenarioDecodeResponse {
 	res := &ScenarioDecodeResponse{
@@ -619,6 +653,92 @@ func decodeScenario(req *ScenarioDecodeRequest) *ScenarioDecodeResponse {
 	}
 
 	res.Diagnostics = res.Scenario.decode(req.ScenarioBlock.Block, evalCtx, req.DecodeTarget)
+	res.ScenarioEvalContext = evalCtx
 
 	return res
 }
+
+// Skipped returns the scenarios and variants that matched the filter but have been skipped, sorted
+// by their names and variants. Must be called after Start() otherwise nothing will have been
+// skipped.
+func (d *ScenarioDecoderIterator) Skipped() []*SkippedScenario {
+	if d == nil {
+		return nil
+	}
+
+	d.skippedMu.Lock()
+	defer d.skippedMu.Unlock()
+
+	skipped := slices.Clone(d.skipped)
+	slices.SortStableFunc(skipped, func(a, b *SkippedScenario) int {
+		return cmp.Compare(a.Scenario.String(), b.Scenario.String())
+	})
+
+	return skipped
+}
+
+// skipScenarioBlock determines whether a skip skips every variant of the scenario block. If so
+// the scenario is recorded as skipped.
+func (d *ScenarioDecoderIterator) skipScenarioBlock(name string) bool {
+	for _, skip := range d.skips {
+		if !skip.matchesScenarioBlock(name) {
+			continue
+		}
+
+		scenario := NewScenario()
+		scenario.Name = name
+		d.addSkipped(&SkippedScenario{Scenario: scenario, Reason: skip.Reason})
+
+		return true
+	}
+
+	return false
+}
+
+// skipMatrixVectors removes the vectors that match a skip from the final product of the scenario
+// block matrix and records them as skipped. If every vector has been skipped the whole scenario
+// block is skipped.
+func (d *ScenarioDecoderIterator) skipMatrixVectors(block *ScenarioBlock) {
+	if len(d.skips) == 0 || block.Matrix() == nil || len(block.Matrix().GetVectors()) == 0 {
+		return
+	}
+
+	kept := NewMatrix()
+	for _, vec := range block.Matrix().GetVectors() {
+		scenario := NewScenario()
+		scenario.Name = block.Name
+		scenario.Variants = vec
+
+		skipped := false
+		for _, skip := range d.skips {
+			if scenario.Match(skip.Filter) {
+				d.addSkipped(&SkippedScenario{Scenario: scenario, Reason: skip.Reason})
+				skipped = true
+
+				break
+			}
+		}
+
+		if !skipped {
+			kept.AddVector(vec)
+		}
+	}
+
+	block.MatrixBlock.FinalProduct = kept
+	if len(kept.GetVectors()) == 0 {
+		d.skippedMu.Lock()
+		if d.skippedBlocks == nil {
+			d.skippedBlocks = map[string]bool{}
+		}
+		d.skippedBlocks[block.Name] = true
+		d.skippedMu.Unlock()
+	}
+}
+
+// addSkipped records a skipped scenario.
+func (d *ScenarioDecoderIterator) addSkipped(skipped *SkippedScenario) {
+	d.skippedMu.Lock()
+	defer d.skippedMu.Unlock()
+
+	d.skipped = append(d.skipped, skipped)
+}
@@ -0,0 +1,262 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/customdecode"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// EvalScenarioExpression evaluates an expression in the eval context of a decoded scenario the
+// same way that step variables are decoded. Expressions of known values, e.g. of the matrix,
+// variables, globals, locals, and the variables of steps, are evaluated to their values, while
+// expressions that refer to outputs of steps, fixtures, or scenarios are composed of the references
+// that are deferred to Terraform.
+func EvalScenarioExpression(
+	expr hcl.Expression,
+	ctx *hcl.EvalContext,
+) (*pb.EvalScenarioExpressionResponse_Result, hcl.Diagnostics) {
+	res := &pb.EvalScenarioExpressionResponse_Result{}
+
+	// Expressions that don't refer to outputs are evaluated as they are so that their diagnostics
+	// are those of the evaluation.
+	decode := customdecode.CustomExpressionDecoderForType(StepVariableType)
+	if !refersToOutputs(expr) {
+		decode = func(expr hcl.Expression, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+			val, diags := expr.Value(ctx)
+			if val.Type().Equals(StepVariableType) {
+				return val, diags
+			}
+
+			return StepVariableVal(&StepVariable{Value: val}), diags
+		}
+	}
+
+	val, diags := decode(expr, ctx)
+	if diags.HasErrors() {
+		return res, diags
+	}
+
+	stepVar, moreDiags := StepVariableFromVal(val)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return res, diags
+	}
+
+	switch {
+	case stepVar.Expression != "":
+		res.Deferred = true
+		res.Value = formatEvalHCL(stepVar.Expression)
+		for _, ref := range stepVar.References {
+			res.References = append(res.GetReferences(), traversalHCL(ref))
+		}
+	case stepVar.Traversal != nil:
+		res.Deferred = true
+		res.Value = traversalHCL(stepVar.Traversal)
+		res.References = []string{res.GetValue()}
+	default:
+		val, refs := unwrapStepVariables(stepVar.Value)
+		res.Value = formatEvalHCL(evalValueHCL(stepVar.Value))
+		res.References = refs
+		res.Deferred = len(refs) > 0 || !val.IsWhollyKnown()
+		// Variables that are declared without a value are nil and don't have a type.
+		if val != cty.NilVal && !val.Type().Equals(cty.NilType) && !val.Type().Equals(cty.DynamicPseudoType) {
+			res.Type = typeexpr.TypeString(val.Type())
+		}
+	}
+
+	return res, diags
+}
+
+// refersToOutputs returns whether the expression refers to steps, fixtures, or scenarios, whose
+// outputs are deferred to Terraform.
+func refersToOutputs(expr hcl.Expression) bool {
+	for _, traversal := range expr.Variables() {
+		switch traversal.RootName() {
+		case "step", blockTypeFixture, blockTypeScenario:
+			return true
+		default:
+		}
+	}
+
+	return false
+}
+
+// unwrapStepVariables returns the value with the step variables that it contains replaced by their
+// values, e.g. the value of step.backend.variables, and the references of the step variables that
+// are deferred to Terraform, which are replaced by unknown values. Collections that contain step
+// variables are returned as objects and tuples as the types of their elements can differ. Nil
+// values, e.g. of variables that are declared without a value, are returned as null.
+func unwrapStepVariables(val cty.Value) (cty.Value, []string) {
+	refs := []string{}
+	if val == cty.NilVal {
+		return cty.NullVal(cty.DynamicPseudoType), refs
+	}
+
+	val, _ = val.UnmarkDeep()
+	ty := val.Type()
+
+	switch {
+	case !val.IsKnown() || val.IsNull():
+		return val, refs
+	case ty.Equals(StepVariableType):
+		stepVar, _ := StepVariableFromVal(val)
+		switch {
+		case stepVar.Expression != "":
+			for _, ref := range stepVar.References {
+				refs = append(refs, traversalHCL(ref))
+			}
+		case stepVar.Traversal != nil:
+			refs = append(refs, traversalHCL(stepVar.Traversal))
+		case stepVar.Value != cty.NilVal:
+			return unwrapStepVariables(stepVar.Value)
+		}
+
+		return cty.DynamicVal, refs
+	case !containsStepVariables(ty) && !containsNilType(ty):
+		return val, refs
+	case ty.IsObjectType() || ty.IsMapType():
+		vals := map[string]cty.Value{}
+		for key, elm := range val.AsValueMap() {
+			v, moreRefs := unwrapStepVariables(elm)
+			vals[key] = v
+			refs = append(refs, moreRefs...)
+		}
+
+		return cty.ObjectVal(vals), refs
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		vals := []cty.Value{}
+		for _, elm := range val.AsValueSlice() {
+			v, moreRefs := unwrapStepVariables(elm)
+			vals = append(vals, v)
+			refs = append(refs, moreRefs...)
+		}
+
+		return cty.TupleVal(vals), refs
+	default:
+		return val, refs
+	}
+}
+
+// containsStepVariables returns whether values of the type can contain step variables.
+func containsStepVariables(ty cty.Type) bool {
+	switch {
+	case ty.Equals(StepVariableType):
+		return true
+	case ty.IsObjectType():
+		for _, aty := range ty.AttributeTypes() {
+			if containsStepVariables(aty) {
+				return true
+			}
+		}
+	case ty.IsTupleType():
+		for _, ety := range ty.TupleElementTypes() {
+			if containsStepVariables(ety) {
+				return true
+			}
+		}
+	case ty.IsCollectionType():
+		return containsStepVariables(ty.ElementType())
+	}
+
+	return false
+}
+
+// containsNilType returns whether values of the type can contain nil values, e.g. objects of
+// variables that are declared without a value.
+func containsNilType(ty cty.Type) bool {
+	switch {
+	case ty.Equals(cty.NilType):
+		return true
+	case ty.IsObjectType():
+		for _, aty := range ty.AttributeTypes() {
+			if containsNilType(aty) {
+				return true
+			}
+		}
+	case ty.IsTupleType():
+		for _, ety := range ty.TupleElementTypes() {
+			if containsNilType(ety) {
+				return true
+			}
+		}
+	case ty.IsCollectionType():
+		return containsNilType(ty.ElementType())
+	}
+
+	return false
+}
+
+// evalValueHCL returns the HCL of a value that can contain step variables and unknown values. Step
+// variables that are deferred to Terraform are written as their references.
+func evalValueHCL(val cty.Value) string {
+	val, _ = val.UnmarkDeep()
+	ty := val.Type()
+
+	switch {
+	case ty.Equals(StepVariableType) && val.IsKnown() && !val.IsNull():
+		stepVar, _ := StepVariableFromVal(val)
+		switch {
+		case stepVar.Expression != "":
+			return stepVar.Expression
+		case stepVar.Traversal != nil:
+			return traversalHCL(stepVar.Traversal)
+		case stepVar.Value != cty.NilVal:
+			return evalValueHCL(stepVar.Value)
+		default:
+			return "null"
+		}
+	case !val.IsKnown():
+		return "(known after apply)"
+	case val.IsNull():
+		return "null"
+	case ty.IsObjectType() || ty.IsMapType():
+		keys := []string{}
+		for key := range val.AsValueMap() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items := []string{}
+		for _, key := range keys {
+			name := key
+			if !hclsyntax.ValidIdentifier(key) {
+				name = strings.TrimSpace(string(hclwrite.TokensForValue(cty.StringVal(key)).Bytes()))
+			}
+			items = append(items, name+" = "+evalValueHCL(val.AsValueMap()[key]))
+		}
+		if len(items) == 0 {
+			return "{}"
+		}
+
+		return "{\n" + strings.Join(items, "\n") + "\n}"
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		items := []string{}
+		for _, elm := range val.AsValueSlice() {
+			items = append(items, evalValueHCL(elm))
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case ty.IsCapsuleType():
+		return fmt.Sprintf("(%s)", ty.FriendlyName())
+	default:
+		return strings.TrimSpace(string(hclwrite.TokensForValue(val).Bytes()))
+	}
+}
+
+// formatEvalHCL returns the canonically formatted HCL of a value or expression.
+func formatEvalHCL(src string) string {
+	return strings.TrimSpace(string(hclwrite.Format([]byte(src))))
+}
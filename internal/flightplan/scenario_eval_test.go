@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Test_EvalScenarioExpression tests evaluating expressions in the eval context of a scenario.
+func Test_EvalScenarioExpression(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	scenarios, diags := testDecodeHCLScenarios(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+globals {
+  owner = "qa"
+}
+
+variable "version" {
+  type = string
+}
+
+variable "region" {
+  type    = string
+  default = "us-east-1"
+}
+
+scenario "test" {
+  matrix {
+    arch = ["amd64"]
+  }
+
+  locals {
+    name = "${global.owner}-${matrix.arch}"
+  }
+
+  step "backend" {
+    module = module.backend
+
+    variables {
+      name = local.name
+      port = 8080
+    }
+  }
+
+  step "frontend" {
+    module = module.backend
+
+    variables {
+      backend = step.backend.addr
+    }
+  }
+}
+`, modulePath)))
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.Len(t, scenarios, 1)
+	evalCtx := scenarios[0].ScenarioEvalContext
+	require.NotNil(t, evalCtx)
+
+	for desc, test := range map[string]struct {
+		expr       string
+		value      string
+		ty         string
+		deferred   bool
+		references []string
+		fail       string
+	}{
+		"known value": {
+			expr:  `upper(local.name)`,
+			value: `"QA-AMD64"`,
+			ty:    "string",
+		},
+		"matrix": {
+			expr:  `matrix`,
+			value: "{\n  arch = \"amd64\"\n}",
+			ty:    "object({arch=string})",
+		},
+		"step variables": {
+			expr:  `step.backend.variables.port`,
+			value: "8080",
+			ty:    "number",
+		},
+		"step variable reference": {
+			expr:       `step.frontend.variables.backend`,
+			value:      "step.backend.addr",
+			deferred:   true,
+			references: []string{"step.backend.addr"},
+		},
+		"deferred expression": {
+			expr:       `join(",", [step.backend.addr, local.name])`,
+			value:      `join(",", [step.backend.addr, "qa-amd64"])`,
+			deferred:   true,
+			references: []string{"step.backend.addr"},
+		},
+		"unset variable": {
+			expr:  `var.version`,
+			value: "null",
+		},
+		"variables": {
+			expr:  `var`,
+			value: "{\n  region  = \"us-east-1\"\n  version = null\n}",
+			ty:    "object({region=string,version=any})",
+		},
+		"unknown local": {
+			expr: `local.nope`,
+			fail: "Unsupported attribute",
+		},
+		"undefined step": {
+			expr: `upper(step.nope.addr)`,
+			fail: "no step named nope",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			expr, diags := hclsyntax.ParseExpression([]byte(test.expr), "test.hcl", hcl.InitialPos)
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			res, diags := EvalScenarioExpression(expr, evalCtx)
+			if test.fail != "" {
+				require.True(t, diags.HasErrors())
+				require.ErrorContains(t, diags, test.fail)
+
+				return
+			}
+
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.value, res.GetValue())
+			require.Equal(t, test.ty, res.GetType())
+			require.Equal(t, test.deferred, res.GetDeferred())
+			if len(test.references) > 0 {
+				require.Equal(t, test.references, res.GetReferences())
+			} else {
+				require.Empty(t, res.GetReferences())
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@
 package flightplan
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"strings"
@@ -18,6 +19,13 @@ type ScenarioFilter struct {
 	Exclude            []*Exclude // Explicit scenario/variant exclusions
 	SelectAll          bool       // Get all scenarios and variants
 	IntersectionMatrix *Matrix    // Like Include but can contain more than one Vector
+	// Scenarios and variants matching any Union filter are selected as well, those that don't
+	// match every Intersect filter or that match any Difference filter are not.
+	Union      []*ScenarioFilter
+	Intersect  []*ScenarioFilter
+	Difference []*ScenarioFilter
+	// Shard only selects the scenarios and variants of one of a number of stable partitions.
+	Shard *ScenarioShard
 }
 
 // String returns the scenario filter as a string.
@@ -28,18 +36,32 @@ func (sf *ScenarioFilter) String() string {
 
 	str := sf.Name
 
-	if sf.SelectAll {
-		return str
+	if !sf.SelectAll {
+		for _, i := range sf.Include.Elements() {
+			str = fmt.Sprintf("%s %s", str, i.String())
+		}
+
+		for _, e := range sf.Exclude {
+			for _, elm := range e.Vector.Elements() {
+				str = fmt.Sprintf("%s !%s:%s", str, elm.Key, elm.Val)
+			}
+		}
 	}
 
-	for _, i := range sf.Include.Elements() {
-		str = fmt.Sprintf("%s %s", str, i.String())
+	if !sf.hasSetOperations() {
+		return str
 	}
 
-	for _, e := range sf.Exclude {
-		for _, elm := range e.Vector.Elements() {
-			str = fmt.Sprintf("%s !%s:%s", str, elm.Key, elm.Val)
-		}
+	// Unions are applied first, then intersections and then differences
+	str = fmt.Sprintf("(%s)", cmp.Or(strings.TrimSpace(str), "*"))
+	for _, u := range sf.Union {
+		str = fmt.Sprintf("%s | (%s)", str, strings.TrimSpace(u.String()))
+	}
+	for _, i := range sf.Intersect {
+		str = fmt.Sprintf("%s & (%s)", str, strings.TrimSpace(i.String()))
+	}
+	for _, d := range sf.Difference {
+		str = fmt.Sprintf("%s - (%s)", str, strings.TrimSpace(d.String()))
 	}
 
 	return str
@@ -112,7 +134,7 @@ func WithScenarioFilterDecode(filter *pb.Scenario_Filter) ScenarioFilterOpt {
 	return func(f *ScenarioFilter) error {
 		f.FromProto(filter)
 
-		return nil
+		return f.Shard.validate()
 	}
 }
 
@@ -215,9 +237,140 @@ func (sf *ScenarioFilter) Proto() *pb.Scenario_Filter {
 		pbf.IntersectionMatrix = sf.IntersectionMatrix.Proto()
 	}
 
+	for _, u := range sf.Union {
+		pbf.Union = append(pbf.GetUnion(), u.Proto())
+	}
+
+	for _, i := range sf.Intersect {
+		pbf.Intersect = append(pbf.GetIntersect(), i.Proto())
+	}
+
+	for _, d := range sf.Difference {
+		pbf.Difference = append(pbf.GetDifference(), d.Proto())
+	}
+
+	pbf.Shard = sf.Shard.Proto()
+
 	return pbf
 }
 
+// SelectsAll returns whether the filter selects all scenarios and variants.
+func (sf *ScenarioFilter) SelectsAll() bool {
+	if sf == nil {
+		return true
+	}
+
+	if len(sf.Intersect) > 0 || len(sf.Difference) > 0 {
+		return false
+	}
+
+	if sf.SelectAll || sf.Name == "" && sf.Include == nil && len(sf.Exclude) == 0 && sf.IntersectionMatrix == nil {
+		return true
+	}
+
+	for _, u := range sf.Union {
+		if u.SelectsAll() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasSetOperations returns whether the filter is combined with other filters.
+func (sf *ScenarioFilter) hasSetOperations() bool {
+	return sf != nil && (len(sf.Union) > 0 || len(sf.Intersect) > 0 || len(sf.Difference) > 0)
+}
+
+// selectsAllWithoutSetOperations returns whether the filter selects all scenarios and variants
+// without having to match the filters that it is combined with.
+func (sf *ScenarioFilter) selectsAllWithoutSetOperations() bool {
+	return sf == nil || sf.SelectAll && !sf.hasSetOperations()
+}
+
+// matchSets takes a function that matches a single filter and determines whether the filter
+// combined with its union, intersect and difference filters matches.
+func (sf *ScenarioFilter) matchSets(match func(*ScenarioFilter) bool) bool {
+	matched := match(sf)
+	for i := 0; !matched && i < len(sf.Union); i++ {
+		matched = sf.Union[i].matchSets(match)
+	}
+	if !matched {
+		return false
+	}
+
+	for _, i := range sf.Intersect {
+		if !i.matchSets(match) {
+			return false
+		}
+	}
+
+	for _, d := range sf.Difference {
+		if d.matchSets(match) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchScenarioName determines whether a scenario with the name might have variants that match
+// the filter. It's only false when the name alone rules out every variant of the scenario.
+func (sf *ScenarioFilter) matchScenarioName(name string) bool {
+	if sf == nil {
+		return true
+	}
+
+	matched := sf.Name == "" || sf.Name == name
+	for i := 0; !matched && i < len(sf.Union); i++ {
+		matched = sf.Union[i].matchScenarioName(name)
+	}
+	if !matched {
+		return false
+	}
+
+	for _, i := range sf.Intersect {
+		if !i.matchScenarioName(name) {
+			return false
+		}
+	}
+
+	// A difference only rules out the whole scenario when it matches every variant
+	for _, d := range sf.Difference {
+		if d.matchesAllVariants() && (d.SelectAll || d.Name == "" || d.Name == name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesAllVariants returns whether the filter matches every variant of the scenarios whose
+// name it matches.
+func (sf *ScenarioFilter) matchesAllVariants() bool {
+	if sf.hasSetOperations() {
+		return false
+	}
+
+	return sf.SelectAll || sf.Include == nil && len(sf.Exclude) == 0 && sf.IntersectionMatrix == nil
+}
+
+// matchScenarioVector determines whether a variant vector of the scenario with the name matches
+// the filter. An empty name matches the name of any filter.
+func (sf *ScenarioFilter) matchScenarioVector(name string, vec *Vector) bool {
+	if sf == nil {
+		return true
+	}
+
+	return sf.matchSets(func(f *ScenarioFilter) bool {
+		if !f.SelectAll && name != "" && f.Name != "" && f.Name != name {
+			return false
+		}
+
+		return f.matchVector(vec)
+	})
+}
+
 // FromProto unmarshals a proto filter into itself.
 func (sf *ScenarioFilter) FromProto(filter *pb.Scenario_Filter) {
 	if filter == nil {
@@ -248,6 +401,27 @@ func (sf *ScenarioFilter) FromProto(filter *pb.Scenario_Filter) {
 		nm.FromProto(sim)
 		sf.IntersectionMatrix = nm
 	}
+
+	sf.Union = scenarioFiltersFromProto(filter.GetUnion())
+	sf.Intersect = scenarioFiltersFromProto(filter.GetIntersect())
+	sf.Difference = scenarioFiltersFromProto(filter.GetDifference())
+	sf.Shard = NewScenarioShardFromProto(filter.GetShard())
+}
+
+// scenarioFiltersFromProto unmarshals proto filters into scenario filters.
+func scenarioFiltersFromProto(filters []*pb.Scenario_Filter) []*ScenarioFilter {
+	if len(filters) == 0 {
+		return nil
+	}
+
+	res := []*ScenarioFilter{}
+	for _, f := range filters {
+		sf := &ScenarioFilter{}
+		sf.FromProto(f)
+		res = append(res, sf)
+	}
+
+	return res
 }
 
 // FromScenarioRef takes a reference to a scenario and returns a filter for it.
@@ -327,7 +501,7 @@ func (fp *FlightPlan) ScenariosSelect(f *ScenarioFilter) []*Scenario {
 		return nil
 	}
 
-	if f.SelectAll {
+	if f.selectsAllWithoutSetOperations() {
 		return fp.Scenarios()
 	}
 
@@ -0,0 +1,228 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ExplainScenarioFilter takes scenario blocks whose matrices have been decoded without a filter,
+// the scenarios and variants that were skipped while decoding them, the filter, and the maximum
+// number of exclusions, and returns which scenario blocks and variants the filter, the matrices
+// or the skips excluded and why. Exclusions beyond the maximum are only counted. A maximum of
+// zero or less includes all of them.
+func ExplainScenarioFilter(
+	blocks ScenarioBlocks,
+	skipped []*SkippedScenario,
+	filter *ScenarioFilter,
+	maxExclusions int,
+) *pb.Scenario_Filter_Explanation {
+	res := &pb.Scenario_Filter_Explanation{}
+	add := func(ex *pb.Scenario_Filter_Explanation_Exclusion) {
+		if maxExclusions > 0 && len(res.GetExclusions()) >= maxExclusions {
+			res.Omitted++
+
+			return
+		}
+		res.Exclusions = append(res.Exclusions, ex)
+	}
+
+	// Skipped scenarios are only explained if the filter would have selected them
+	for _, s := range skipped {
+		if !s.Scenario.Match(filter) && (s.Scenario.Variants != nil || !filter.matchScenarioName(s.Scenario.Name)) {
+			continue
+		}
+
+		ex := &pb.Scenario_Filter_Explanation_Exclusion{
+			Scenario: s.Scenario.Name,
+			Reason:   pb.Scenario_Filter_Explanation_REASON_SKIP,
+			Detail:   s.Reason,
+		}
+		if s.Scenario.Variants != nil {
+			ex.Variants = s.Scenario.Variants.Proto()
+		}
+		add(ex)
+	}
+
+	for _, block := range blocks {
+		if filter.hasSetOperations() && !filter.matchScenarioName(block.Name) {
+			add(&pb.Scenario_Filter_Explanation_Exclusion{
+				Scenario: block.Name,
+				Reason:   pb.Scenario_Filter_Explanation_REASON_NAME,
+				Detail:   "the scenario isn't selected by the combined filters " + filter.String(),
+			})
+
+			continue
+		}
+
+		if !filter.hasSetOperations() && filter != nil && filter.Name != "" && block.Name != filter.Name {
+			add(&pb.Scenario_Filter_Explanation_Exclusion{
+				Scenario: block.Name,
+				Reason:   pb.Scenario_Filter_Explanation_REASON_NAME,
+				Detail:   fmt.Sprintf("the scenario name doesn't match %s", filter.Name),
+			})
+
+			continue
+		}
+
+		for _, vec := range matrixBlockVectors(block.MatrixBlock) {
+			reason, detail := explainVectorExclusion(
+				block.Name, block.MatrixBlock, filter, vec, isSkipped(skipped, block.Name, vec),
+			)
+			if reason == pb.Scenario_Filter_Explanation_REASON_UNSPECIFIED {
+				continue
+			}
+
+			add(&pb.Scenario_Filter_Explanation_Exclusion{
+				Scenario: block.Name,
+				Variants: vec.Proto(),
+				Reason:   reason,
+				Detail:   detail,
+			})
+		}
+	}
+
+	return res
+}
+
+// isSkipped returns whether the vector of the named scenario has been skipped.
+func isSkipped(skipped []*SkippedScenario, name string, vec *Vector) bool {
+	for _, s := range skipped {
+		if s.Scenario.Name == name && s.Scenario.Variants != nil && s.Scenario.Variants.EqualUnordered(vec) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matrixBlockVectors returns every unique vector of the matrix block and its includes before any
+// excludes have been applied, sorted.
+func matrixBlockVectors(mb *MatrixBlock) []*Vector {
+	if mb == nil || mb.Original == nil {
+		return nil
+	}
+
+	all := mb.Original.CartesianProduct()
+	for _, product := range mb.IncludeProducts {
+		for _, vec := range product.GetVectors() {
+			all.AddVector(vec)
+		}
+	}
+	all = all.UniqueValues()
+	all.Sort()
+
+	return all.GetVectors()
+}
+
+// explainVectorExclusion returns why the vector of the matrix block of the named scenario was
+// excluded and what excluded it. The reason is unspecified if the vector wasn't excluded, or if it
+// was only excluded because it has been skipped, as skips are explained separately.
+func explainVectorExclusion(
+	name string,
+	mb *MatrixBlock,
+	filter *ScenarioFilter,
+	vec *Vector,
+	skipped bool,
+) (pb.Scenario_Filter_Explanation_Reason, string) {
+	if !skipped && !mb.FinalProduct.HasVectorUnordered(vec) {
+		for _, ex := range mb.Constraints {
+			if ex.Match(vec) {
+				elms := ex.Vector.Elements()
+
+				return pb.Scenario_Filter_Explanation_REASON_MATRIX_EXCLUDE,
+					fmt.Sprintf("violates the matrix constraint %s excludes %s", elms[0].String(), elms[1].String())
+			}
+		}
+
+		for _, ex := range mb.Excludes {
+			if ex.Match(vec) {
+				return pb.Scenario_Filter_Explanation_REASON_MATRIX_EXCLUDE,
+					fmt.Sprintf("matches the matrix exclude %s", ex.Vector.String())
+			}
+		}
+
+		return pb.Scenario_Filter_Explanation_REASON_MATRIX_EXCLUDE, "excluded by the matrix"
+	}
+
+	if filter.hasSetOperations() {
+		return explainSetsVectorExclusion(name, filter, vec)
+	}
+
+	if filter == nil || filter.SelectAll {
+		return pb.Scenario_Filter_Explanation_REASON_UNSPECIFIED, ""
+	}
+
+	if filter.Include != nil && len(filter.Include.elements) > 0 && !vec.ContainsUnordered(filter.Include) {
+		missing := []string{}
+		for _, elm := range filter.Include.Elements() {
+			if !vec.ContainsUnordered(NewVector(elm)) {
+				missing = append(missing, elm.String())
+			}
+		}
+
+		return pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+			"doesn't have the filter variants " + strings.Join(missing, " ")
+	}
+
+	for _, ex := range filter.Exclude {
+		if ex.Match(vec) {
+			elms := []string{}
+			for _, elm := range ex.Vector.Elements() {
+				elms = append(elms, "!"+elm.String())
+			}
+
+			return pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+				"matches the filter exclusion " + strings.Join(elms, " ")
+		}
+	}
+
+	// Filters of sample subsets have the matrix of the subset as their intersection matrix, which is
+	// all that is left that could not match.
+	if !filter.matchVector(vec) {
+		return pb.Scenario_Filter_Explanation_REASON_SAMPLE, "not in the matrix of the sample subset"
+	}
+
+	return pb.Scenario_Filter_Explanation_REASON_UNSPECIFIED, ""
+}
+
+// explainSetsVectorExclusion returns why the vector of the named scenario was excluded by a filter
+// that is combined with other filters and which of them excluded it. The reason is unspecified if
+// the vector wasn't excluded.
+func explainSetsVectorExclusion(
+	name string,
+	filter *ScenarioFilter,
+	vec *Vector,
+) (pb.Scenario_Filter_Explanation_Reason, string) {
+	if filter.matchScenarioVector(name, vec) {
+		return pb.Scenario_Filter_Explanation_REASON_UNSPECIFIED, ""
+	}
+
+	base := *filter
+	base.Intersect = nil
+	base.Difference = nil
+	if !base.matchScenarioVector(name, vec) {
+		return pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+			"doesn't match any of the filters " + base.String()
+	}
+
+	for _, i := range filter.Intersect {
+		if !i.matchScenarioVector(name, vec) {
+			return pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+				"doesn't match the intersected filter " + strings.TrimSpace(i.String())
+		}
+	}
+
+	for _, d := range filter.Difference {
+		if d.matchScenarioVector(name, vec) {
+			return pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+				"matches the excluded filter " + strings.TrimSpace(d.String())
+		}
+	}
+
+	return pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER, "excluded by the combined filters"
+}
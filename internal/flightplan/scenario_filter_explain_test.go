@@ -0,0 +1,202 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_ExplainScenarioFilter tests explaining which scenario blocks and variants a filter excluded.
+func Test_ExplainScenarioFilter(t *testing.T) {
+	t.Parallel()
+
+	fp, err := testDecodeHCL(t, []byte(`
+module "backend" {
+  source = "./backend"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch   = ["amd64", "arm64"]
+    distro = ["ubuntu", "rhel"]
+
+    exclude {
+      arch   = ["arm64"]
+      distro = ["rhel"]
+    }
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+
+scenario "smoke" {
+  step "first" {
+    module = module.backend
+  }
+}
+`), DecodeTargetScenariosNamesExpandVariants)
+	require.NoError(t, err)
+
+	exclusion := func(
+		scenario string,
+		reason pb.Scenario_Filter_Explanation_Reason,
+		detail string,
+		elms ...Element,
+	) *pb.Scenario_Filter_Explanation_Exclusion {
+		ex := &pb.Scenario_Filter_Explanation_Exclusion{Scenario: scenario, Reason: reason, Detail: detail}
+		if len(elms) > 0 {
+			ex.Variants = NewVector(elms...).Proto()
+		}
+
+		return ex
+	}
+	matrixExcluded := exclusion("upgrade", pb.Scenario_Filter_Explanation_REASON_MATRIX_EXCLUDE,
+		"matches the matrix exclude [arch:arm64 distro:rhel]",
+		NewElement("arch", "arm64"), NewElement("distro", "rhel"),
+	)
+
+	for desc, test := range map[string]struct {
+		args       []string
+		union      [][]string
+		difference [][]string
+		max        int
+		expected   *pb.Scenario_Filter_Explanation
+	}{
+		"select all": {
+			expected: &pb.Scenario_Filter_Explanation{
+				Exclusions: []*pb.Scenario_Filter_Explanation_Exclusion{matrixExcluded},
+			},
+		},
+		"name": {
+			args: []string{"upgrade"},
+			expected: &pb.Scenario_Filter_Explanation{
+				Exclusions: []*pb.Scenario_Filter_Explanation_Exclusion{
+					exclusion("smoke", pb.Scenario_Filter_Explanation_REASON_NAME, "the scenario name doesn't match upgrade"),
+					matrixExcluded,
+				},
+			},
+		},
+		"variants": {
+			args: []string{"upgrade", "arch:arm64", "!distro:ubuntu"},
+			expected: &pb.Scenario_Filter_Explanation{
+				Exclusions: []*pb.Scenario_Filter_Explanation_Exclusion{
+					exclusion("smoke", pb.Scenario_Filter_Explanation_REASON_NAME, "the scenario name doesn't match upgrade"),
+					exclusion("upgrade", pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+						"doesn't have the filter variants arch:arm64",
+						NewElement("arch", "amd64"), NewElement("distro", "rhel"),
+					),
+					exclusion("upgrade", pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+						"doesn't have the filter variants arch:arm64",
+						NewElement("arch", "amd64"), NewElement("distro", "ubuntu"),
+					),
+					matrixExcluded,
+					exclusion("upgrade", pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+						"matches the filter exclusion !distro:ubuntu",
+						NewElement("arch", "arm64"), NewElement("distro", "ubuntu"),
+					),
+				},
+			},
+		},
+		"max exclusions": {
+			args: []string{"upgrade", "arch:arm64", "!distro:ubuntu"},
+			max:  2,
+			expected: &pb.Scenario_Filter_Explanation{
+				Exclusions: []*pb.Scenario_Filter_Explanation_Exclusion{
+					exclusion("smoke", pb.Scenario_Filter_Explanation_REASON_NAME, "the scenario name doesn't match upgrade"),
+					exclusion("upgrade", pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+						"doesn't have the filter variants arch:arm64",
+						NewElement("arch", "amd64"), NewElement("distro", "rhel"),
+					),
+				},
+				Omitted: 3,
+			},
+		},
+		"combined": {
+			args:       []string{"upgrade", "arch:amd64"},
+			union:      [][]string{{"upgrade", "distro:rhel"}},
+			difference: [][]string{{"smoke"}, {"distro:ubuntu"}},
+			expected: &pb.Scenario_Filter_Explanation{
+				Exclusions: []*pb.Scenario_Filter_Explanation_Exclusion{
+					exclusion("smoke", pb.Scenario_Filter_Explanation_REASON_NAME,
+						"the scenario isn't selected by the combined filters (upgrade arch:amd64) | (upgrade distro:rhel) - (smoke) - (distro:ubuntu)",
+					),
+					exclusion("upgrade", pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+						"matches the excluded filter distro:ubuntu",
+						NewElement("arch", "amd64"), NewElement("distro", "ubuntu"),
+					),
+					matrixExcluded,
+					exclusion("upgrade", pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER,
+						"doesn't match any of the filters (upgrade arch:amd64) | (upgrade distro:rhel)",
+						NewElement("arch", "arm64"), NewElement("distro", "ubuntu"),
+					),
+				},
+			},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			sf, err := ParseScenarioFilter(test.args)
+			require.NoError(t, err)
+			for _, args := range test.union {
+				f, err := ParseScenarioFilter(args)
+				require.NoError(t, err)
+				sf.Union = append(sf.Union, f)
+			}
+			for _, args := range test.difference {
+				f, err := ParseScenarioFilter(args)
+				require.NoError(t, err)
+				sf.Difference = append(sf.Difference, f)
+			}
+
+			explanation := ExplainScenarioFilter(fp.ScenarioBlocks, nil, sf, test.max)
+			require.True(t, proto.Equal(test.expected, explanation), explanation.String())
+		})
+	}
+}
+
+// Test_ExplainScenarioFilter_Sample tests explaining the variants that a sample subset excluded.
+func Test_ExplainScenarioFilter_Sample(t *testing.T) {
+	t.Parallel()
+
+	fp, err := testDecodeHCL(t, []byte(`
+module "backend" {
+  source = "./backend"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+`), DecodeTargetScenariosNamesExpandVariants)
+	require.NoError(t, err)
+
+	subset := NewMatrix()
+	subset.AddVector(NewVector(NewElement("arch", "amd64")))
+	sf, err := NewScenarioFilter(WithScenarioFilterName("upgrade"))
+	require.NoError(t, err)
+	sf.IntersectionMatrix = subset
+
+	explanation := ExplainScenarioFilter(fp.ScenarioBlocks, nil, sf, 0)
+	require.True(t, proto.Equal(&pb.Scenario_Filter_Explanation{
+		Exclusions: []*pb.Scenario_Filter_Explanation_Exclusion{{
+			Scenario: "upgrade",
+			Variants: NewVector(NewElement("arch", "arm64")).Proto(),
+			Reason:   pb.Scenario_Filter_Explanation_REASON_SAMPLE,
+			Detail:   "not in the matrix of the sample subset",
+		}},
+	}, explanation), explanation.String())
+}
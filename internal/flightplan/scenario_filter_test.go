@@ -144,6 +144,9 @@ func Test_ScenarioFilter_Proto_RoundTrip(t *testing.T) {
 			NewVector(NewElement("backend", "raft"), NewElement("arch", "arm64")),
 			NewVector(NewElement("backend", "consul"), NewElement("arch", "arm64")),
 		}},
+		Union:      []*ScenarioFilter{{Name: "bar"}},
+		Intersect:  []*ScenarioFilter{{Include: NewVector(NewElement("arch", "amd64"))}},
+		Difference: []*ScenarioFilter{{Name: "foo", Include: NewVector(NewElement("cloud", "aws"))}},
 	}
 	got := &ScenarioFilter{}
 	got.FromProto(expected.Proto())
@@ -232,6 +235,59 @@ func Test_ScenarioFilter_ScenariosSelect(t *testing.T) {
 			},
 			[]*Scenario{},
 		},
+		{
+			"union",
+			scenarios,
+			&ScenarioFilter{
+				Name:    "upgrade",
+				Include: NewVector(NewElement("backend", "raft")),
+				Union: []*ScenarioFilter{
+					{Name: "fresh-install", Include: NewVector(NewElement("arch", "amd64"))},
+					{Name: "no-variant"},
+				},
+			},
+			[]*Scenario{scenarios[1], scenarios[3], scenarios[4], scenarios[5], scenarios[8]},
+		},
+		{
+			"intersect",
+			scenarios,
+			&ScenarioFilter{
+				Include: NewVector(NewElement("backend", "raft")),
+				Intersect: []*ScenarioFilter{
+					{Include: NewVector(NewElement("arch", "arm64"))},
+				},
+			},
+			[]*Scenario{scenarios[0], scenarios[4]},
+		},
+		{
+			"difference from all",
+			scenarios,
+			&ScenarioFilter{
+				SelectAll: true,
+				Difference: []*ScenarioFilter{
+					{Name: "upgrade"},
+					{Include: NewVector(NewElement("backend", "consul"))},
+				},
+			},
+			[]*Scenario{scenarios[0], scenarios[1], scenarios[8]},
+		},
+		{
+			"union intersect and difference",
+			scenarios,
+			&ScenarioFilter{
+				Name: "upgrade",
+				Union: []*ScenarioFilter{
+					{Name: "fresh-install"},
+				},
+				Intersect: []*ScenarioFilter{
+					{Include: NewVector(NewElement("arch", "amd64"))},
+				},
+				Difference: []*ScenarioFilter{
+					{Name: "upgrade", Include: NewVector(NewElement("backend", "consul"))},
+				},
+			},
+			[]*Scenario{scenarios[1], scenarios[3], scenarios[5]},
+		},
 	} {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
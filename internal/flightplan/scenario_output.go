@@ -8,6 +8,8 @@ import (
 
 	hcl "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hcldec"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
 // ScenarioOutput represents an "output" block in a scenario.
@@ -16,6 +18,7 @@ type ScenarioOutput struct {
 	Description string
 	Sensitive   bool
 	Value       cty.Value
+	Verifies    []*Quality
 }
 
 // NewScenarioOutput returns a new Output.
@@ -23,13 +26,10 @@ func NewScenarioOutput() *ScenarioOutput {
 	return &ScenarioOutput{Value: cty.NilVal}
 }
 
-// decode takes in an HCL block of an output and unmarshal the value into itself.
-func (v *ScenarioOutput) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics {
-	diags := hcl.Diagnostics{}
-	v.Name = block.Labels[0]
-
-	// Define this here so StepVariableType is our cty.Type and not cty.Nil
-	scenarioOutputSpec := hcldec.ObjectSpec{
+// scenarioOutputSpec returns the spec of output blocks. It's a function so that StepVariableType
+// is our cty.Type and not cty.Nil.
+func scenarioOutputSpec() hcldec.Spec {
+	return hcldec.ObjectSpec{
 		"description": &hcldec.AttrSpec{
 			Name:     "description",
 			Type:     cty.String,
@@ -45,14 +45,51 @@ func (v *ScenarioOutput) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diag
 			Type:     StepVariableType,
 			Required: true,
 		},
+		"verifies": &hcldec.AttrSpec{
+			Name:     "verifies",
+			Type:     cty.DynamicPseudoType,
+			Required: false,
+		},
 	}
+}
+
+// decode takes in an HCL block of an output and unmarshal the value into itself.
+func (v *ScenarioOutput) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	v.Name = block.Labels[0]
 
-	val, moreDiags := hcldec.Decode(block.Body, scenarioOutputSpec, ctx)
+	// Values that are composed of references to the outputs of steps, e.g. a template that joins
+	// two outputs, can't be step variables and are composed into an expression that Terraform
+	// evaluates when it applies the scenario.
+	spec := scenarioOutputSpec().(hcldec.ObjectSpec)
+	content, moreDiags := block.Body.Content(hcldec.ImpliedSchema(spec))
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	if attr, ok := content.Attributes["value"]; ok && isComposedOutputExpression(attr.Expr, ctx) {
+		delete(spec, "value")
+		v.Value, moreDiags = composeOutputExpression(attr.Expr, ctx, "output")
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return diags
+		}
+	}
+
+	val, _, moreDiags := hcldec.PartialDecode(block.Body, spec, ctx)
 	diags = diags.Extend(moreDiags)
 	if moreDiags != nil && moreDiags.HasErrors() {
 		return diags
 	}
 
+	// Like the verifies of steps they must be known.
+	if verifies := val.GetAttr("verifies"); !verifies.IsNull() {
+		rng := hcldec.SourceRange(block.Body, &hcldec.AttrSpec{Name: "verifies", Type: cty.DynamicPseudoType})
+		v.Verifies, moreDiags = decodeVerifies(verifies, &rng, block.DefRange.Ptr())
+		diags = diags.Extend(moreDiags)
+	}
+
 	for attr, val := range val.AsValueMap() {
 		if val.IsNull() || !val.IsWhollyKnown() {
 			continue
@@ -71,3 +108,20 @@ func (v *ScenarioOutput) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diag
 
 	return diags
 }
+
+func (v *ScenarioOutput) outline() *pb.Scenario_Outline_Output {
+	if v == nil {
+		return nil
+	}
+
+	out := &pb.Scenario_Outline_Output{
+		Name:        v.Name,
+		Description: v.Description,
+	}
+
+	for _, qual := range v.Verifies {
+		out.Verifies = append(out.GetVerifies(), qual.ToProto())
+	}
+
+	return out
+}
@@ -0,0 +1,467 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// isComposedOutputExpression returns whether the expression is composed of references to the
+// outputs of steps or fixtures, rather than being a known value or a single reference to an output
+// that a step variable can be.
+func isComposedOutputExpression(expr hcl.Expression, ctx *hcl.EvalContext) bool {
+	if _, diags := hcl.AbsTraversalForExpr(expr); !diags.HasErrors() {
+		return false
+	}
+
+	composed := false
+	for _, traversal := range expr.Variables() {
+		if root := traversal.RootName(); root == "step" || root == blockTypeFixture || root == blockTypeScenario {
+			composed = true
+
+			break
+		}
+	}
+	if !composed {
+		return false
+	}
+
+	// References that we can expand from the eval context, e.g. step.backend[matrix.backend],
+	// are still single references.
+	_, diags := absTraversalForExpr(expr, ctx)
+
+	return diags.HasErrors()
+}
+
+// composeOutputExpression returns a step variable with the expression composed of references to
+// outputs of steps and fixtures. As the outputs are unknown until the scenario has been launched
+// the references are kept in the expression, while every other part of the expression that can be
+// known is evaluated and kept as its value. The kind is what the expression is the value of, e.g.
+// output or step variable, which diagnostics refer to.
+func composeOutputExpression(expr hcl.Expression, ctx *hcl.EvalContext, kind string) (cty.Value, hcl.Diagnostics) {
+	syntaxExpr, ok := expr.(hclsyntax.Expression)
+	if !ok {
+		return cty.NilVal, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("unsupported %s expression", kind),
+			Detail:   fmt.Sprintf("%s expressions that refer to outputs of steps must be written in the native HCL syntax", kind),
+			Subject:  expr.Range().Ptr(),
+		}}
+	}
+
+	c := &outputExpressionComposer{ctx: ctx, kind: kind, symbols: map[string]bool{}}
+	src, diags := c.compose(syntaxExpr)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+
+	return StepVariableVal(&StepVariable{
+		Value:      cty.NilVal,
+		Expression: src,
+		References: c.references,
+	}), diags
+}
+
+// outputExpressionComposer composes the HCL of an output expression.
+type outputExpressionComposer struct {
+	ctx  *hcl.EvalContext
+	kind string
+	// symbols are the names of the symbols of for expressions that are in scope.
+	symbols    map[string]bool
+	references []hcl.Traversal
+}
+
+// compose returns the HCL of the expression. As the parser keeps the parentheses of the
+// expression their HCL has the same precedence as the expression.
+func (c *outputExpressionComposer) compose(expr hclsyntax.Expression) (string, hcl.Diagnostics) {
+	if !c.isUnknowable(expr) {
+		return c.value(expr)
+	}
+
+	diags := hcl.Diagnostics{}
+	composeAll := func(exprs ...hclsyntax.Expression) []string {
+		srcs := []string{}
+		for _, e := range exprs {
+			src, moreDiags := c.compose(e)
+			diags = diags.Extend(moreDiags)
+			srcs = append(srcs, src)
+		}
+
+		return srcs
+	}
+
+	switch t := expr.(type) {
+	case *hclsyntax.ParenthesesExpr:
+		return "(" + composeAll(t.Expression)[0] + ")", diags
+	case *hclsyntax.ScopeTraversalExpr:
+		return c.traversal(t.Traversal, t)
+	case *hclsyntax.RelativeTraversalExpr:
+		return composeAll(t.Source)[0] + traversalHCL(t.Traversal), diags
+	case *hclsyntax.AnonSymbolExpr:
+		return "", diags
+	case *hclsyntax.FunctionCallExpr:
+		args := composeAll(t.Args...)
+		if t.ExpandFinal && len(args) > 0 {
+			args[len(args)-1] += "..."
+		}
+
+		return t.Name + "(" + strings.Join(args, ", ") + ")", diags
+	case *hclsyntax.ConditionalExpr:
+		// Only keep the condition if it refers to outputs
+		if !c.isUnknowable(t.Condition) {
+			cond, moreDiags := c.known(t.Condition)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				return "", diags
+			}
+			cond, err := convert.Convert(cond, cty.Bool)
+			if err != nil || cond.IsNull() {
+				return "", diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Incorrect condition type",
+					Detail:   "The condition expression must be of type bool.",
+					Subject:  t.Condition.Range().Ptr(),
+				})
+			}
+			if cond.True() {
+				return composeAll(t.TrueResult)[0], diags
+			}
+
+			return composeAll(t.FalseResult)[0], diags
+		}
+		srcs := composeAll(t.Condition, t.TrueResult, t.FalseResult)
+
+		return fmt.Sprintf("%s ? %s : %s", srcs[0], srcs[1], srcs[2]), diags
+	case *hclsyntax.BinaryOpExpr:
+		op, ok := binaryOperators[t.Op]
+		if !ok {
+			break
+		}
+		srcs := composeAll(t.LHS, t.RHS)
+
+		return fmt.Sprintf("%s %s %s", srcs[0], op, srcs[1]), diags
+	case *hclsyntax.UnaryOpExpr:
+		op := "-"
+		if t.Op == hclsyntax.OpLogicalNot {
+			op = "!"
+		}
+
+		return op + composeAll(t.Val)[0], diags
+	case *hclsyntax.TupleConsExpr:
+		return "[" + strings.Join(composeAll(t.Exprs...), ", ") + "]", diags
+	case *hclsyntax.ObjectConsExpr:
+		items := []string{}
+		for _, item := range t.Items {
+			srcs := composeAll(item.KeyExpr, item.ValueExpr)
+			if key, ok := item.KeyExpr.(*hclsyntax.ObjectConsKeyExpr); ok && !key.ForceNonLiteral {
+				if keyword := hcl.ExprAsKeyword(key.Wrapped); keyword != "" {
+					srcs[0] = keyword
+				}
+			}
+			items = append(items, srcs[0]+" = "+srcs[1])
+		}
+
+		return "{\n" + strings.Join(items, "\n") + "\n}", diags
+	case *hclsyntax.ObjectConsKeyExpr:
+		return "(" + composeAll(t.Wrapped)[0] + ")", diags
+	case *hclsyntax.TemplateWrapExpr:
+		return composeAll(t.Wrapped)[0], diags
+	case *hclsyntax.TemplateExpr:
+		var src strings.Builder
+		src.WriteString(`"`)
+		for _, part := range t.Parts {
+			if !c.isUnknowable(part) {
+				val, moreDiags := c.known(part)
+				diags = diags.Extend(moreDiags)
+				if moreDiags.HasErrors() {
+					continue
+				}
+				if str, err := convert.Convert(val, cty.String); err == nil && !str.IsNull() {
+					src.WriteString(quotedStringContents(str.AsString()))
+
+					continue
+				}
+			}
+			src.WriteString("${" + composeAll(part)[0] + "}")
+		}
+		src.WriteString(`"`)
+
+		return src.String(), diags
+	case *hclsyntax.IndexExpr:
+		srcs := composeAll(t.Collection, t.Key)
+
+		return srcs[0] + "[" + srcs[1] + "]", diags
+	case *hclsyntax.SplatExpr:
+		srcs := composeAll(t.Source, t.Each)
+
+		return srcs[0] + "[*]" + srcs[1], diags
+	case *hclsyntax.ForExpr:
+		return c.forExpr(t)
+	default:
+	}
+
+	return "", diags.Append(&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  fmt.Sprintf("unsupported %s expression", c.kind),
+		Detail: fmt.Sprintf(
+			"%T expressions that refer to outputs of steps can't be deferred to Terraform, use a function or a for expression instead",
+			expr,
+		),
+		Subject: expr.Range().Ptr(),
+	})
+}
+
+// forExpr returns the HCL of the for expression. Its key and value symbols are only in scope of the
+// expressions that are evaluated for each element.
+func (c *outputExpressionComposer) forExpr(expr *hclsyntax.ForExpr) (string, hcl.Diagnostics) {
+	coll, diags := c.compose(expr.CollExpr)
+
+	symbols := c.symbols
+	c.symbols = map[string]bool{}
+	for name := range symbols {
+		c.symbols[name] = true
+	}
+	for _, name := range []string{expr.KeyVar, expr.ValVar} {
+		if name != "" {
+			c.symbols[name] = true
+		}
+	}
+	defer func() { c.symbols = symbols }()
+
+	vars := expr.ValVar
+	if expr.KeyVar != "" {
+		vars = expr.KeyVar + ", " + expr.ValVar
+	}
+
+	var src strings.Builder
+	open, closing := "[", "]"
+	if expr.KeyExpr != nil {
+		open, closing = "{", "}"
+	}
+	fmt.Fprintf(&src, "%sfor %s in %s : ", open, vars, coll)
+
+	if expr.KeyExpr != nil {
+		key, moreDiags := c.compose(expr.KeyExpr)
+		diags = diags.Extend(moreDiags)
+		src.WriteString(key + " => ")
+	}
+
+	val, moreDiags := c.compose(expr.ValExpr)
+	diags = diags.Extend(moreDiags)
+	src.WriteString(val)
+	if expr.Group {
+		src.WriteString("...")
+	}
+
+	if expr.CondExpr != nil {
+		cond, moreDiags := c.compose(expr.CondExpr)
+		diags = diags.Extend(moreDiags)
+		src.WriteString(" if " + cond)
+	}
+	src.WriteString(closing)
+
+	return src.String(), diags
+}
+
+// traversal returns the HCL of a traversal that can't be evaluated. It must either refer to an
+// output of a step or fixture or to a symbol of a for expression.
+func (c *outputExpressionComposer) traversal(traversal hcl.Traversal, expr hcl.Expression) (string, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	switch root := traversal.RootName(); {
+	case c.symbols[root]:
+	case root == "step":
+		if src, ok := c.stepValue(traversal); ok {
+			return src, diags
+		}
+		diags = diags.Extend(verifyStepTraversal(traversal, expr, c.ctx))
+		c.references = append(c.references, traversal)
+	case root == blockTypeFixture:
+		diags = diags.Extend(verifyFixtureTraversal(traversal, expr, c.ctx))
+		c.references = append(c.references, traversal)
+	case root == blockTypeScenario:
+		diags = diags.Extend(verifyScenarioTraversal(traversal, expr, c.ctx))
+		c.references = append(c.references, traversal)
+	default:
+		return c.value(expr.(hclsyntax.Expression))
+	}
+
+	return traversalHCL(traversal), diags
+}
+
+// stepValue returns the HCL of a reference to a step that is known when the scenario is decoded,
+// e.g. step.backend.variables.port or step.backend.name, rather than to an output of the module of
+// the step. Known step variables are replaced by their values and step variables that are
+// references themselves are replaced by the references.
+func (c *outputExpressionComposer) stepValue(traversal hcl.Traversal) (string, bool) {
+	val, diags := traversal.TraverseAbs(c.ctx)
+	if diags.HasErrors() || val == cty.NilVal {
+		return "", false
+	}
+
+	if !val.Type().Equals(StepVariableType) {
+		if !val.Type().IsPrimitiveType() || !val.IsWhollyKnown() {
+			return "", false
+		}
+		val, _ = val.UnmarkDeep()
+
+		return strings.TrimSpace(string(hclwrite.TokensForValue(val).Bytes())), true
+	}
+
+	stepVar, diags := StepVariableFromVal(val)
+	if diags.HasErrors() {
+		return "", false
+	}
+
+	switch {
+	case stepVar.Value != cty.NilVal:
+		return strings.TrimSpace(string(hclwrite.TokensForValue(stepVar.Value).Bytes())), true
+	case stepVar.Expression != "":
+		c.references = append(c.references, stepVar.References...)
+
+		return "(" + stepVar.Expression + ")", true
+	case stepVar.Traversal != nil:
+		c.references = append(c.references, stepVar.Traversal)
+
+		return traversalHCL(stepVar.Traversal), true
+	default:
+		return "", false
+	}
+}
+
+// value returns the HCL of the value of an expression that must be known.
+func (c *outputExpressionComposer) value(expr hclsyntax.Expression) (string, hcl.Diagnostics) {
+	val, diags := c.known(expr)
+	if diags.HasErrors() {
+		return "", diags
+	}
+
+	return strings.TrimSpace(string(hclwrite.TokensForValue(val).Bytes())), diags
+}
+
+// known evaluates the expression, which must be known.
+func (c *outputExpressionComposer) known(expr hclsyntax.Expression) (cty.Value, hcl.Diagnostics) {
+	val, diags := expr.Value(c.ctx)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+
+	if !val.IsWhollyKnown() {
+		return cty.NilVal, diags.Append(unknownValueDiagnostic(
+			c.kind+" value is unknowable",
+			"only the references to outputs of steps, fixtures, and scenarios in an expression are "+
+				"deferred to Terraform, every other part of the expression must be known when the scenario is decoded",
+			expr, c.ctx, expr.Range().Ptr(), nil,
+		))
+	}
+
+	val, _ = val.UnmarkDeep()
+
+	return val, diags
+}
+
+// isUnknowable returns whether the expression refers to outputs of steps or fixtures or to symbols
+// that are only known when Terraform evaluates it.
+func (c *outputExpressionComposer) isUnknowable(expr hclsyntax.Expression) bool {
+	unknowable := false
+	_ = hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		switch t := node.(type) {
+		case *hclsyntax.AnonSymbolExpr:
+			unknowable = true
+		case *hclsyntax.ScopeTraversalExpr:
+			root := t.Traversal.RootName()
+			if root == "step" || root == blockTypeFixture || root == blockTypeScenario || c.symbols[root] {
+				unknowable = true
+			}
+		default:
+		}
+
+		return nil
+	})
+
+	return unknowable
+}
+
+// verifyStepTraversal verifies that the traversal refers to a step that has been defined.
+func verifyStepTraversal(traversal hcl.Traversal, expr hcl.Expression, ctx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	steps, err := findEvalContextVariable("step", ctx)
+	if err != nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "no previous steps have been defined",
+			Subject:  traversal.SourceRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+	}
+
+	if len(traversal) < 2 {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid step traversal",
+			Detail:   "step references must refer to a step, e.g. step.name.output",
+			Subject:  traversal.SourceRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+	}
+
+	name, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid step traversal",
+			Subject:  traversal.SourceRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+	}
+
+	if _, ok := steps.AsValueMap()[name.Name]; !ok {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("no step named %s has been previously defined", name.Name),
+			Subject:  name.SourceRange().Ptr(),
+			Context:  traversal.SourceRange().Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// binaryOperators are the HCL of binary operations.
+var binaryOperators = map[*hclsyntax.Operation]string{
+	hclsyntax.OpLogicalOr:          "||",
+	hclsyntax.OpLogicalAnd:         "&&",
+	hclsyntax.OpEqual:              "==",
+	hclsyntax.OpNotEqual:           "!=",
+	hclsyntax.OpGreaterThan:        ">",
+	hclsyntax.OpGreaterThanOrEqual: ">=",
+	hclsyntax.OpLessThan:           "<",
+	hclsyntax.OpLessThanOrEqual:    "<=",
+	hclsyntax.OpAdd:                "+",
+	hclsyntax.OpSubtract:           "-",
+	hclsyntax.OpMultiply:           "*",
+	hclsyntax.OpDivide:             "/",
+	hclsyntax.OpModulo:             "%",
+}
+
+// traversalHCL returns the HCL of an absolute or relative traversal.
+func traversalHCL(traversal hcl.Traversal) string {
+	return strings.TrimSpace(string(hclwrite.TokensForTraversal(traversal).Bytes()))
+}
+
+// quotedStringContents returns the string escaped as the contents of a quoted HCL string.
+func quotedStringContents(str string) string {
+	quoted := strings.TrimSpace(string(hclwrite.TokensForValue(cty.StringVal(str)).Bytes()))
+
+	return strings.TrimSuffix(strings.TrimPrefix(quoted, `"`), `"`)
+}
@@ -111,6 +111,114 @@ scenario "basic" {
 				},
 			},
 		},
+		{
+			desc: "outputs with verifies",
+			hcl: fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+quality "data_is_durable" {
+  description = "The data is durable"
+}
+
+quality "tests_pass" {
+  description = "The tests pass"
+}
+
+scenario "basic" {
+  step "backend" {
+    module = module.backend
+  }
+
+  output "single" {
+    description = "a single quality"
+    value       = "veryknown"
+    verifies    = quality.tests_pass
+  }
+
+  output "multiple" {
+    value    = "veryknown"
+    verifies = [quality.tests_pass, quality.data_is_durable]
+  }
+}
+`, modulePath),
+			expected: &FlightPlan{
+				TerraformCLIs: []*TerraformCLI{
+					DefaultTerraformCLI(),
+				},
+				Modules: []*Module{
+					{
+						Name:   "backend",
+						Source: modulePath,
+						Attrs:  map[string]cty.Value{},
+					},
+				},
+				ScenarioBlocks: ScenarioBlocks{
+					{
+						Name: "basic",
+						Scenarios: []*Scenario{
+							{
+								Name:         "basic",
+								TerraformCLI: DefaultTerraformCLI(),
+								Steps: []*ScenarioStep{
+									{
+										Name: "backend",
+										Module: &Module{
+											Name:   "backend",
+											Source: modulePath,
+											Attrs:  map[string]cty.Value{},
+										},
+									},
+								},
+								Outputs: []*ScenarioOutput{
+									{
+										Name:        "single",
+										Description: "a single quality",
+										Value:       testMakeStepVarValue(cty.StringVal("veryknown")),
+										Verifies: []*Quality{
+											{Name: "tests_pass", Description: "The tests pass"},
+										},
+									},
+									{
+										Name:  "multiple",
+										Value: testMakeStepVarValue(cty.StringVal("veryknown")),
+										Verifies: []*Quality{
+											{Name: "data_is_durable", Description: "The data is durable"},
+											{Name: "tests_pass", Description: "The tests pass"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "duplicate verifies",
+			fail: true,
+			hcl: fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+quality "tests_pass" {
+  description = "The tests pass"
+}
+
+scenario "basic" {
+  step "backend" {
+    module = module.backend
+  }
+
+  output "static" {
+    value    = "veryknown"
+    verifies = [quality.tests_pass, quality.tests_pass]
+  }
+}
+`, modulePath),
+		},
 		{
 			desc: "invalid identifier",
 			fail: true,
@@ -200,7 +308,7 @@ scenario "backend" {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			fp, err := testDecodeHCL(t, []byte(test.hcl), DecodeTargetAll, "ENOS_VAR_input=fromenv")
+			fp, err := testDecodeHCL(t, []byte(test.hcl), DecodeTargetAll, WithDecoderEnv([]string{"ENOS_VAR_input=fromenv"}))
 			if test.fail {
 				require.Error(t, err)
 
@@ -211,3 +319,172 @@ scenario "backend" {
 		})
 	}
 }
+
+// Test_Decode_Scenario_Output_Expression tests decoding outputs whose values are composed of
+// references to the outputs of steps.
+func Test_Decode_Scenario_Output_Expression(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	for desc, test := range map[string]struct {
+		value      string
+		expected   string
+		references []string
+		fail       bool
+	}{
+		"template": {
+			value:      `"${var.input}-${matrix.arch}: ${step.backend.addr}:${step.frontend.port}"`,
+			expected:   `"fromenv-amd64: ${step.backend.addr}:${step.frontend.port}"`,
+			references: []string{"step.backend.addr", "step.frontend.port"},
+		},
+		"function": {
+			value:      `coalesce(step.backend.addr, local.fallback)`,
+			expected:   `coalesce(step.backend.addr, "none")`,
+			references: []string{"step.backend.addr"},
+		},
+		"object": {
+			value: `{
+      addrs = [step.backend.addr, step.frontend.addr]
+      count = length(step.backend.hosts) + 1
+      arch  = upper(matrix.arch)
+    }`,
+			expected: `{
+addrs = [step.backend.addr, step.frontend.addr]
+count = length(step.backend.hosts) + 1
+arch = "AMD64"
+}`,
+			references: []string{"step.backend.addr", "step.frontend.addr", "step.backend.hosts"},
+		},
+		"for": {
+			value:      `[for h in step.backend.hosts : upper(h) if h != var.input]`,
+			expected:   `[for h in step.backend.hosts : upper(h) if h != "fromenv"]`,
+			references: []string{"step.backend.hosts"},
+		},
+		"splat": {
+			value:      `step.backend.instances[*].id`,
+			expected:   `step.backend.instances[*].id`,
+			references: []string{"step.backend.instances"},
+		},
+		"known condition": {
+			value:      `matrix.arch == "amd64" ? "${step.backend.addr}:80" : step.frontend.addr`,
+			expected:   `"${step.backend.addr}:80"`,
+			references: []string{"step.backend.addr"},
+		},
+		"unknown condition": {
+			value:      `step.backend.enabled ? step.backend.addr : "none"`,
+			expected:   `step.backend.enabled ? step.backend.addr : "none"`,
+			references: []string{"step.backend.enabled", "step.backend.addr"},
+		},
+		"unknown step": {
+			value: `"${step.backend.addr}:${step.nope.port}"`,
+			fail:  true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+variable "input" {
+  type    = string
+  default = "defaultval"
+}
+
+module "backend" {
+  source = "%s"
+}
+
+scenario "basic" {
+  matrix {
+    arch = ["amd64"]
+  }
+
+  locals {
+    fallback = "none"
+  }
+
+  step "backend" {
+    module = module.backend
+  }
+
+  step "frontend" {
+    module = module.backend
+  }
+
+  output "composed" {
+    value = %s
+  }
+}
+`, modulePath, test.value)), DecodeTargetAll, WithDecoderEnv([]string{"ENOS_VAR_input=fromenv"}))
+			if test.fail {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			outputs := fp.ScenarioBlocks[0].Scenarios[0].Outputs
+			require.Len(t, outputs, 1)
+			stepVar, diags := StepVariableFromVal(outputs[0].Value)
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.expected, stepVar.Expression)
+
+			references := []string{}
+			for _, ref := range stepVar.References {
+				references = append(references, traversalHCL(ref))
+			}
+			require.Equal(t, test.references, references)
+		})
+	}
+}
+
+// Test_Scenario_Outline_Outputs tests that outlines document outputs and the qualities that they
+// verify.
+func Test_Scenario_Outline_Outputs(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+quality "data_is_durable" {
+  description = "The data is durable"
+}
+
+quality "tests_pass" {
+  description = "The tests pass"
+}
+
+scenario "basic" {
+  step "backend" {
+    module   = module.backend
+    verifies = quality.tests_pass
+  }
+
+  output "addrs" {
+    description = "The backend addresses"
+    value       = step.backend.addrs
+    verifies    = quality.data_is_durable
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+
+	outline := fp.ScenarioBlocks[0].Scenarios[0].Outline()
+	require.Len(t, outline.GetOutputs(), 1)
+	require.Equal(t, "addrs", outline.GetOutputs()[0].GetName())
+	require.Equal(t, "The backend addresses", outline.GetOutputs()[0].GetDescription())
+	require.Len(t, outline.GetOutputs()[0].GetVerifies(), 1)
+	require.Equal(t, "data_is_durable", outline.GetOutputs()[0].GetVerifies()[0].GetName())
+
+	verifies := []string{}
+	for _, q := range outline.GetVerifies() {
+		verifies = append(verifies, q.GetName())
+	}
+	require.Equal(t, []string{"data_is_durable", "tests_pass"}, verifies)
+}
@@ -0,0 +1,261 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// attrNameScenarioOutputs is the attribute of the scenario references of producers that has their
+// outputs, e.g. scenario.shared_vpc.outputs.vpc_id.
+const attrNameScenarioOutputs = "outputs"
+
+// scenarioProducersVal returns the value of the "scenario" variable of the eval context of scenarios.
+// It has the names of the outputs of every scenario so that references to the outputs of producers
+// can be verified before the producers have been decoded. The values of the outputs are unknown
+// until the producers have been launched.
+func scenarioProducersVal(blocks []*hcl.Block) cty.Value {
+	scenarios := map[string]cty.Value{}
+	for _, block := range blocks {
+		if len(block.Labels) != 1 {
+			continue
+		}
+
+		outputs := map[string]cty.Value{}
+		content, _, _ := block.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{
+				{Type: blockTypeOutput, LabelNames: []string{attrLabelNameDefault}},
+			},
+		})
+		for _, output := range content.Blocks.OfType(blockTypeOutput) {
+			outputs[output.Labels[0]] = cty.DynamicVal
+		}
+
+		scenarios[block.Labels[0]] = cty.ObjectVal(map[string]cty.Value{
+			attrNameScenarioOutputs: cty.ObjectVal(outputs),
+		})
+	}
+
+	return cty.ObjectVal(scenarios)
+}
+
+// verifyScenarioTraversal verifies that the traversal refers to an output of a scenario that has
+// been defined, e.g. scenario.shared_vpc.outputs.vpc_id.
+func verifyScenarioTraversal(traversal hcl.Traversal, expr hcl.Expression, ctx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	scenarios, err := findEvalContextVariable(blockTypeScenario, ctx)
+	if err != nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "no scenarios have been defined",
+			Subject:  traversal.SourceRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+	}
+
+	invalid := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "invalid scenario traversal",
+		Detail:   "scenario references must refer to an output of a scenario, e.g. scenario.name.outputs.output",
+		Subject:  traversal.SourceRange().Ptr(),
+		Context:  expr.Range().Ptr(),
+	}
+	if len(traversal) < 4 {
+		return diags.Append(invalid)
+	}
+
+	name, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return diags.Append(invalid)
+	}
+	if outputs, ok := traversal[2].(hcl.TraverseAttr); !ok || outputs.Name != attrNameScenarioOutputs {
+		return diags.Append(invalid)
+	}
+	output, ok := traversal[3].(hcl.TraverseAttr)
+	if !ok {
+		return diags.Append(invalid)
+	}
+
+	scenario, ok := scenarios.AsValueMap()[name.Name]
+	if !ok {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("no scenario named %s has been defined", name.Name),
+			Subject:  name.SourceRange().Ptr(),
+			Context:  traversal.SourceRange().Ptr(),
+		})
+	}
+
+	if !scenario.GetAttr(attrNameScenarioOutputs).Type().HasAttribute(output.Name) {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("scenario %s has no output named %s", name.Name, output.Name),
+			Subject:  output.SourceRange().Ptr(),
+			Context:  traversal.SourceRange().Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// ProducerName returns the name of the scenario whose outputs the traversal refers to, or an empty
+// string if it doesn't refer to the outputs of a scenario.
+func ProducerName(traversal hcl.Traversal) string {
+	if len(traversal) < 3 || traversal.IsRelative() || traversal.RootName() != blockTypeScenario {
+		return ""
+	}
+
+	name, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return ""
+	}
+
+	return name.Name
+}
+
+// producerReferences returns the sorted names of the scenarios whose outputs the steps and outputs
+// of the scenario refer to.
+func (s *Scenario) producerReferences() []string {
+	names := []string{}
+	add := func(val cty.Value) {
+		stepVar, diags := StepVariableFromVal(val)
+		if diags.HasErrors() {
+			return
+		}
+
+		for _, traversal := range append([]hcl.Traversal{stepVar.Traversal}, stepVar.References...) {
+			if name := ProducerName(traversal); name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	for _, step := range s.Steps {
+		switch {
+		case step.Module != nil:
+			for _, val := range step.Module.Attrs {
+				add(val)
+			}
+		case step.Provisioner != nil:
+			for _, val := range step.Provisioner.Attrs {
+				add(val)
+			}
+		}
+	}
+
+	for _, out := range s.Outputs {
+		if out.Value != cty.NilVal {
+			add(out.Value)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// MatchProducer returns the variant of the producer whose outputs the scenario consumes. The
+// variant is the only one whose values of the matrix dimensions that it shares with the scenario
+// are the same as those of the scenario, e.g. the shared_vpc [region:us-east-1] variant for the
+// upgrade [region:us-east-1 distro:ubuntu] scenario.
+func MatchProducer(scenario *Scenario, variants []*Scenario) (*Scenario, error) {
+	matches := []*Scenario{}
+	for _, variant := range variants {
+		if producerVariantMatches(scenario.Variants, variant.Variants) {
+			matches = append(matches, variant)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, fmt.Errorf("%s refers to the outputs of scenario %s but none of its variants match",
+			scenario.String(), producerVariantsName(variants),
+		)
+	default:
+		names := []string{}
+		for _, match := range matches {
+			names = append(names, match.String())
+		}
+
+		return nil, fmt.Errorf("%s refers to the outputs of scenario %s but more than one of its variants match: %s",
+			scenario.String(), producerVariantsName(variants), strings.Join(names, ", "),
+		)
+	}
+}
+
+// producerVariantsName returns the name of the scenario of the producer variants.
+func producerVariantsName(variants []*Scenario) string {
+	if len(variants) == 0 {
+		return ""
+	}
+
+	return variants[0].Name
+}
+
+// producerVariantMatches returns whether the values of the dimensions that the vectors share are
+// the same.
+func producerVariantMatches(consumer, producer *Vector) bool {
+	for _, elm := range producer.Elements() {
+		for _, other := range consumer.Elements() {
+			if elm.Key == other.Key && elm.Val != other.Val {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// SortScenariosByProducers sorts the scenarios so that every scenario comes after the scenarios
+// whose outputs it consumes, which makes it possible to launch them in order. Otherwise the order
+// of the scenarios is kept. The producers of a scenario are the indexes of the scenarios whose
+// outputs it consumes. Scenarios that consume their own outputs, directly or through other
+// scenarios, can't be sorted.
+func SortScenariosByProducers(scenarios []*Scenario, producers map[int][]int) ([]*Scenario, error) {
+	sorted := make([]*Scenario, 0, len(scenarios))
+	visited := make([]bool, len(scenarios))
+	visiting := make([]bool, len(scenarios))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		if visited[i] {
+			return nil
+		}
+
+		path = append(path, scenarios[i].String())
+		if visiting[i] {
+			return fmt.Errorf("scenarios consume their own outputs: %s", strings.Join(path, " -> "))
+		}
+		visiting[i] = true
+
+		for _, producer := range producers[i] {
+			if err := visit(producer, path); err != nil {
+				return err
+			}
+		}
+
+		visiting[i] = false
+		visited[i] = true
+		sorted = append(sorted, scenarios[i])
+
+		return nil
+	}
+
+	for i := range scenarios {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,231 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Decode_Scenario_Producer tests decoding references to the outputs of other scenarios.
+func Test_Decode_Scenario_Producer(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "shared_vpc" {
+  step "vpc" {
+    module = module.backend
+  }
+
+  output "vpc_id" {
+    value = step.vpc.vpc_id
+  }
+
+  output "subnets" {
+    value = step.vpc.subnets
+  }
+}
+
+scenario "upgrade" {
+  step "backend" {
+    module = module.backend
+
+    variables {
+      vpc_id = scenario.shared_vpc.outputs.vpc_id
+      subnet = scenario.shared_vpc.outputs.subnets[0]
+    }
+  }
+
+  output "summary" {
+    value = "${scenario.shared_vpc.outputs.vpc_id}-${step.backend.id}"
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+
+	scenarios := fp.Scenarios()
+	require.Len(t, scenarios, 2)
+	for _, scenario := range scenarios {
+		switch scenario.Name {
+		case "upgrade":
+			require.Equal(t, []string{"shared_vpc"}, scenario.Producers)
+			testMostlyEqualStepVar(t,
+				testMakeStepVarTraversal("scenario", "shared_vpc", "outputs", "vpc_id"),
+				scenario.Steps[0].Module.Attrs["vpc_id"],
+			)
+			subnet, diags := StepVariableFromVal(scenario.Steps[0].Module.Attrs["subnet"])
+			require.False(t, diags.HasErrors())
+			require.Equal(t, "shared_vpc", ProducerName(subnet.Traversal))
+			require.Len(t, subnet.Traversal, 5)
+
+			summary, diags := StepVariableFromVal(scenario.Outputs[0].Value)
+			require.False(t, diags.HasErrors())
+			require.NotEmpty(t, summary.Expression)
+			require.Len(t, summary.References, 2)
+		default:
+			require.Empty(t, scenario.Producers)
+		}
+	}
+}
+
+// Test_Decode_Scenario_Producer_Invalid tests decoding invalid references to the outputs of
+// other scenarios.
+func Test_Decode_Scenario_Producer_Invalid(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	for desc, ref := range map[string]string{
+		"undefined scenario": "scenario.missing.outputs.vpc_id",
+		"undefined output":   "scenario.shared_vpc.outputs.missing",
+		"no outputs":         "scenario.shared_vpc.vpc_id",
+		"no output":          "scenario.shared_vpc.outputs",
+		"own outputs":        "scenario.upgrade.outputs.id",
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "shared_vpc" {
+  step "vpc" {
+    module = module.backend
+  }
+
+  output "vpc_id" {
+    value = step.vpc.vpc_id
+  }
+}
+
+scenario "upgrade" {
+  step "backend" {
+    module = module.backend
+
+    variables {
+      vpc_id = %s
+    }
+  }
+
+  output "id" {
+    value = step.backend.id
+  }
+}
+`, modulePath, ref)), DecodeTargetAll)
+			require.Error(t, err)
+		})
+	}
+}
+
+// Test_MatchProducer tests matching the variant of a producer to its consumer.
+func Test_MatchProducer(t *testing.T) {
+	t.Parallel()
+
+	newScenario := func(name string, elms ...Element) *Scenario {
+		scenario := NewScenario()
+		scenario.Name = name
+		if len(elms) > 0 {
+			scenario.Variants = NewVector(elms...)
+		}
+
+		return scenario
+	}
+
+	consumer := newScenario("upgrade", NewElement("distro", "ubuntu"), NewElement("region", "east"))
+
+	for desc, test := range map[string]struct {
+		variants []*Scenario
+		expected string
+		err      string
+	}{
+		"shared dimension": {
+			variants: []*Scenario{
+				newScenario("shared_vpc", NewElement("region", "east")),
+				newScenario("shared_vpc", NewElement("region", "west")),
+			},
+			expected: "shared_vpc [region:east]",
+		},
+		"no matrix": {
+			variants: []*Scenario{newScenario("shared_vpc")},
+			expected: "shared_vpc",
+		},
+		"no match": {
+			variants: []*Scenario{newScenario("shared_vpc", NewElement("region", "west"))},
+			err:      "none of its variants match",
+		},
+		"more than one match": {
+			variants: []*Scenario{
+				newScenario("shared_vpc", NewElement("region", "east"), NewElement("size", "small")),
+				newScenario("shared_vpc", NewElement("region", "east"), NewElement("size", "large")),
+			},
+			err: "more than one of its variants match",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			match, err := MatchProducer(consumer, test.variants)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, match.String())
+		})
+	}
+}
+
+// Test_SortScenariosByProducers tests sorting scenarios so that producers come before their
+// consumers.
+func Test_SortScenariosByProducers(t *testing.T) {
+	t.Parallel()
+
+	newScenarios := func(names ...string) []*Scenario {
+		scenarios := []*Scenario{}
+		for _, name := range names {
+			scenario := NewScenario()
+			scenario.Name = name
+			scenarios = append(scenarios, scenario)
+		}
+
+		return scenarios
+	}
+
+	names := func(scenarios []*Scenario) []string {
+		res := []string{}
+		for _, scenario := range scenarios {
+			res = append(res, scenario.Name)
+		}
+
+		return res
+	}
+
+	sorted, err := SortScenariosByProducers(
+		newScenarios("upgrade", "smoke", "shared_vpc", "network"),
+		map[int][]int{0: {2}, 2: {3}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"network", "shared_vpc", "upgrade", "smoke"}, names(sorted))
+
+	_, err = SortScenariosByProducers(
+		newScenarios("a", "b", "c"),
+		map[int][]int{0: {1}, 1: {2}, 2: {0}},
+	)
+	require.ErrorContains(t, err, "scenarios consume their own outputs: a -> b -> c -> a")
+}
@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ScenarioShard is one of a number of stable partitions of the scenarios and variants that a
+// filter selects. Scenarios and variants are assigned to shards by the hash of their UID so the
+// shard of a scenario variant never depends on the other scenarios and variants that are selected.
+type ScenarioShard struct {
+	Index int32 // The one based index of the shard
+	Count int32 // The number of shards
+}
+
+// ParseScenarioShard parses a shard in the form of index/count, e.g. 1/4.
+func ParseScenarioShard(shard string) (*ScenarioShard, error) {
+	index, count, ok := strings.Cut(shard, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid shard %s, expected index/count, e.g. 1/4", shard)
+	}
+
+	i, err := strconv.ParseInt(strings.TrimSpace(index), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard %s, the index must be a number: %w", shard, err)
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(count), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard %s, the count must be a number: %w", shard, err)
+	}
+
+	s := &ScenarioShard{Index: int32(i), Count: int32(n)}
+
+	return s, s.validate()
+}
+
+// NewScenarioShardFromProto returns a new shard from its proto representation.
+func NewScenarioShardFromProto(in *pb.Scenario_Filter_Shard) *ScenarioShard {
+	if in == nil {
+		return nil
+	}
+
+	return &ScenarioShard{Index: in.GetIndex(), Count: in.GetCount()}
+}
+
+// String returns the shard as a string.
+func (s *ScenarioShard) String() string {
+	if s == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// Proto returns the shard as a proto shard.
+func (s *ScenarioShard) Proto() *pb.Scenario_Filter_Shard {
+	if s == nil {
+		return nil
+	}
+
+	return &pb.Scenario_Filter_Shard{Index: s.Index, Count: s.Count}
+}
+
+// Match returns whether the scenario belongs to the shard.
+func (s *ScenarioShard) Match(scenario *Scenario) bool {
+	if s == nil || s.Count < 1 {
+		return true
+	}
+
+	// The UID is a hex encoded sha256 hash so its prefix is a uniformly distributed number.
+	hash, err := strconv.ParseUint(scenario.UID()[:16], 16, 64)
+	if err != nil {
+		return false
+	}
+
+	return hash%uint64(s.Count) == uint64(s.Index-1)
+}
+
+// validate validates the shard.
+func (s *ScenarioShard) validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Count < 1 {
+		return fmt.Errorf("invalid shard %s, the count must be at least 1", s)
+	}
+
+	if s.Index < 1 || s.Index > s.Count {
+		return fmt.Errorf("invalid shard %s, the index must be between 1 and %d", s, s.Count)
+	}
+
+	return nil
+}
+
+// shardScenarioBlocks returns the scenario blocks whose matrices only have the vectors of the
+// shard. Scenario blocks without any vectors in the shard are removed.
+func (s *ScenarioShard) shardScenarioBlocks(blocks ScenarioBlocks) ScenarioBlocks {
+	if s == nil {
+		return blocks
+	}
+
+	res := ScenarioBlocks{}
+	for _, block := range blocks {
+		scenario := NewScenario()
+		scenario.Name = block.Name
+
+		if block.Matrix() == nil || len(block.Matrix().GetVectors()) == 0 {
+			if s.Match(scenario) {
+				res = append(res, block)
+			}
+
+			continue
+		}
+
+		kept := NewMatrix()
+		for _, vec := range block.Matrix().GetVectors() {
+			scenario.Variants = vec
+			if s.Match(scenario) {
+				kept.AddVector(vec)
+			}
+		}
+
+		if len(kept.GetVectors()) > 0 {
+			block.MatrixBlock.FinalProduct = kept
+			res = append(res, block)
+		}
+	}
+
+	return res
+}
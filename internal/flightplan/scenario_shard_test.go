@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+func Test_ParseScenarioShard(t *testing.T) {
+	t.Parallel()
+
+	for shard, test := range map[string]struct {
+		expected *ScenarioShard
+		err      string
+	}{
+		"1/4":  {expected: &ScenarioShard{Index: 1, Count: 4}},
+		"4/4":  {expected: &ScenarioShard{Index: 4, Count: 4}},
+		"1":    {err: "expected index/count"},
+		"a/4":  {err: "the index must be a number"},
+		"1/b":  {err: "the count must be a number"},
+		"0/4":  {err: "the index must be between 1 and 4"},
+		"5/4":  {err: "the index must be between 1 and 4"},
+		"1/0":  {err: "the count must be at least 1"},
+		"1/-1": {err: "the count must be at least 1"},
+	} {
+		t.Run(shard, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := ParseScenarioShard(shard)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, s)
+			require.Equal(t, shard, s.String())
+		})
+	}
+}
+
+// Test_Decode_ScenarioShard tests that shards partition the filtered scenarios and variants.
+func Test_Decode_ScenarioShard(t *testing.T) {
+	t.Parallel()
+
+	fp := &pb.FlightPlan{
+		BaseDir: t.TempDir(),
+		EnosHcl: map[string][]byte{
+			"enos.hcl": []byte(`
+scenario "upgrade" {
+  matrix {
+    arch    = ["amd64", "arm64", "s390x"]
+    distro  = ["ubuntu", "rhel", "amzn", "sles"]
+    backend = ["raft", "consul"]
+  }
+}
+
+scenario "smoke" {
+}
+`),
+		},
+	}
+
+	decode := func(filter *pb.Scenario_Filter) []string {
+		t.Helper()
+
+		decFp, scenarioDecoder, decRes := DecodeProto(
+			context.Background(), fp, DecodeTargetScenariosNamesExpandVariants, filter,
+		)
+		require.Empty(t, decRes.GetDiagnostics())
+		require.Empty(t, scenarioDecoder.DecodeAll(context.Background(), decFp))
+
+		scenarios := []string{}
+		for _, s := range decFp.Scenarios() {
+			scenarios = append(scenarios, s.String())
+		}
+
+		return scenarios
+	}
+
+	all := decode(nil)
+	require.Len(t, all, 25)
+
+	for desc, filter := range map[string]*pb.Scenario_Filter{
+		"all": {},
+		"filter": {
+			Name:    "upgrade",
+			Include: NewVector(NewElement("arch", "arm64")).Proto(),
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			filtered := decode(filter)
+			sharded := []string{}
+			for i := int32(1); i <= 3; i++ {
+				shardFilter := &pb.Scenario_Filter{
+					Name:    filter.GetName(),
+					Include: filter.GetInclude(),
+					Shard:   &pb.Scenario_Filter_Shard{Index: i, Count: 3},
+				}
+				shard := decode(shardFilter)
+				require.NotEmpty(t, shard)
+				// Shards are stable
+				require.Equal(t, shard, decode(shardFilter))
+				sharded = append(sharded, shard...)
+			}
+
+			// Every filtered scenario is in exactly one shard
+			require.ElementsMatch(t, filtered, sharded)
+		})
+	}
+
+	_, _, decRes := DecodeProto(context.Background(), fp, DecodeTargetScenariosNamesExpandVariants,
+		&pb.Scenario_Filter{Shard: &pb.Scenario_Filter_Shard{Index: 4, Count: 3}},
+	)
+	require.NotEmpty(t, decRes.GetDiagnostics())
+}
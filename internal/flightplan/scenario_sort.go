@@ -0,0 +1,254 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ScenarioSorts are the names of the scenario sort orders.
+var ScenarioSorts = []string{"name", "variants", "tag", "duration", "source"}
+
+// ParseScenarioSort parses the name of a scenario sort order, e.g. duration. An empty name is the
+// default order.
+func ParseScenarioSort(name string) (pb.Scenario_Sort, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return pb.Scenario_SORT_UNSPECIFIED, nil
+	}
+
+	order, ok := pb.Scenario_Sort_value["SORT_"+strings.ToUpper(name)]
+	if !ok || order == int32(pb.Scenario_SORT_UNSPECIFIED) {
+		return pb.Scenario_SORT_UNSPECIFIED, fmt.Errorf(
+			"unknown scenario sort %q, expected one of %s", name, strings.Join(ScenarioSorts, ", "),
+		)
+	}
+
+	return pb.Scenario_Sort(order), nil
+}
+
+// ScenarioSortNeedsMetadata returns whether sorting scenarios by the order requires their
+// metadata.
+func ScenarioSortNeedsMetadata(order pb.Scenario_Sort) bool {
+	return order == pb.Scenario_SORT_TAG || order == pb.Scenario_SORT_SOURCE
+}
+
+// ScenarioDurations are the estimated durations of scenarios and their variants.
+type ScenarioDurations struct {
+	variants  map[string]*scenarioDuration
+	scenarios map[string]*scenarioDuration
+}
+
+// scenarioDuration is the total duration of a number of observations.
+type scenarioDuration struct {
+	total time.Duration
+	count int
+}
+
+// average returns the average duration of the observations.
+func (d *scenarioDuration) average() time.Duration {
+	return d.total / time.Duration(d.count)
+}
+
+// NewScenarioDurations returns new empty scenario durations.
+func NewScenarioDurations() *ScenarioDurations {
+	return &ScenarioDurations{
+		variants:  map[string]*scenarioDuration{},
+		scenarios: map[string]*scenarioDuration{},
+	}
+}
+
+// Add adds an observed duration of the scenario. The estimated duration of a scenario is the
+// average of the observed durations.
+func (d *ScenarioDurations) Add(ref *pb.Ref_Scenario, dur time.Duration) {
+	if d == nil || ref.GetId().GetName() == "" {
+		return
+	}
+
+	add := func(durs map[string]*scenarioDuration, key string) {
+		sd, ok := durs[key]
+		if !ok {
+			sd = &scenarioDuration{}
+			durs[key] = sd
+		}
+		sd.total += dur
+		sd.count++
+	}
+
+	add(d.scenarios, ref.GetId().GetName())
+	if uid := ref.GetId().GetUid(); uid != "" {
+		add(d.variants, uid)
+	}
+}
+
+// Variant returns the estimated duration of the scenario variant.
+func (d *ScenarioDurations) Variant(ref *pb.Ref_Scenario) (time.Duration, bool) {
+	if d == nil {
+		return 0, false
+	}
+
+	sd, ok := d.variants[ref.GetId().GetUid()]
+	if !ok {
+		return 0, false
+	}
+
+	return sd.average(), true
+}
+
+// Scenario returns the estimated duration of all variants of the scenario with the name.
+func (d *ScenarioDurations) Scenario(name string) (time.Duration, bool) {
+	if d == nil {
+		return 0, false
+	}
+
+	sd, ok := d.scenarios[name]
+	if !ok {
+		return 0, false
+	}
+
+	return sd.average(), true
+}
+
+// scenarioSortKey is what a scenario is sorted by.
+type scenarioSortKey struct {
+	index       int
+	ref         *pb.Ref_Scenario
+	rng         *pb.Range
+	duration    time.Duration
+	hasDuration bool
+}
+
+// SortScenarioRefs sorts the scenario references by the order. The durations are only required
+// to sort by duration.
+func SortScenarioRefs(refs []*pb.Ref_Scenario, order pb.Scenario_Sort, durations *ScenarioDurations) {
+	keys := make([]scenarioSortKey, len(refs))
+	for i, ref := range refs {
+		keys[i] = scenarioSortKey{index: i, ref: ref, rng: ref.GetMetadata().GetRange()}
+		keys[i].duration, keys[i].hasDuration = durations.Variant(ref)
+	}
+
+	sortScenarioKeys(keys, order)
+	for i := range keys {
+		refs[i] = keys[i].ref
+	}
+}
+
+// SortScenarioOutlines sorts the scenario outlines by the order. As outlines are not specific to
+// variants the duration of an outline is the estimated duration of all variants of its scenario.
+// The durations are only required to sort by duration.
+func SortScenarioOutlines(outlines []*pb.Scenario_Outline, order pb.Scenario_Sort, durations *ScenarioDurations) {
+	keys := make([]scenarioSortKey, len(outlines))
+	for i, out := range outlines {
+		keys[i] = scenarioSortKey{index: i, ref: out.GetScenario(), rng: out.GetRange()}
+		keys[i].duration, keys[i].hasDuration = durations.Scenario(out.GetScenario().GetId().GetName())
+	}
+
+	sortScenarioKeys(keys, order)
+	unsorted := slices.Clone(outlines)
+	for i := range keys {
+		outlines[i] = unsorted[keys[i].index]
+	}
+}
+
+// sortScenarioKeys sorts the keys by the order. Keys that are equal in the order are sorted by
+// name and variants.
+func sortScenarioKeys(keys []scenarioSortKey, order pb.Scenario_Sort) {
+	if order == pb.Scenario_SORT_UNSPECIFIED {
+		order = pb.Scenario_SORT_NAME
+	}
+
+	slices.SortStableFunc(keys, func(a, b scenarioSortKey) int {
+		var i int
+		switch order {
+		case pb.Scenario_SORT_VARIANTS:
+			i = compareScenarioVariants(a.ref, b.ref)
+		case pb.Scenario_SORT_TAG:
+			i = compareScenarioTags(a.ref, b.ref)
+		case pb.Scenario_SORT_DURATION:
+			i = compareScenarioDurations(a, b)
+		case pb.Scenario_SORT_SOURCE:
+			i = compareScenarioSources(a.rng, b.rng)
+		case pb.Scenario_SORT_NAME, pb.Scenario_SORT_UNSPECIFIED:
+		default:
+		}
+
+		return cmp.Or(
+			i,
+			cmp.Compare(a.ref.GetId().GetName(), b.ref.GetId().GetName()),
+			cmp.Compare(a.ref.GetId().GetFilter(), b.ref.GetId().GetFilter()),
+		)
+	})
+}
+
+// compareScenarioVariants compares the values of the variants of the scenarios. Variants are
+// sorted by their keys so the values of the same keys are compared first.
+func compareScenarioVariants(a, b *pb.Ref_Scenario) int {
+	aElms := a.GetId().GetVariants().GetElements()
+	bElms := b.GetId().GetVariants().GetElements()
+	for i := range min(len(aElms), len(bElms)) {
+		if c := cmp.Compare(aElms[i].GetValue(), bElms[i].GetValue()); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(aElms), len(bElms))
+}
+
+// compareScenarioTags compares the sorted tags of the scenarios. Scenarios without tags are last.
+func compareScenarioTags(a, b *pb.Ref_Scenario) int {
+	aTags := slices.Clone(a.GetMetadata().GetTags())
+	bTags := slices.Clone(b.GetMetadata().GetTags())
+	switch {
+	case len(aTags) == 0 && len(bTags) == 0:
+		return 0
+	case len(aTags) == 0:
+		return 1
+	case len(bTags) == 0:
+		return -1
+	}
+	slices.Sort(aTags)
+	slices.Sort(bTags)
+
+	return slices.Compare(aTags, bTags)
+}
+
+// compareScenarioDurations compares the durations of the scenarios, the longest first. Scenarios
+// without a duration are last.
+func compareScenarioDurations(a, b scenarioSortKey) int {
+	switch {
+	case !a.hasDuration && !b.hasDuration:
+		return 0
+	case !a.hasDuration:
+		return 1
+	case !b.hasDuration:
+		return -1
+	}
+
+	return cmp.Compare(b.duration, a.duration)
+}
+
+// compareScenarioSources compares the locations of the scenario blocks. Scenarios without a
+// location are last.
+func compareScenarioSources(a, b *pb.Range) int {
+	switch {
+	case a.GetFilename() == "" && b.GetFilename() == "":
+		return 0
+	case a.GetFilename() == "":
+		return 1
+	case b.GetFilename() == "":
+		return -1
+	}
+
+	return cmp.Or(
+		cmp.Compare(a.GetFilename(), b.GetFilename()),
+		cmp.Compare(a.GetStart().GetLine(), b.GetStart().GetLine()),
+		cmp.Compare(a.GetStart().GetColumn(), b.GetStart().GetColumn()),
+	)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+func Test_ParseScenarioSort(t *testing.T) {
+	t.Parallel()
+
+	for name, test := range map[string]struct {
+		expected pb.Scenario_Sort
+		err      string
+	}{
+		"":            {expected: pb.Scenario_SORT_UNSPECIFIED},
+		"name":        {expected: pb.Scenario_SORT_NAME},
+		"variants":    {expected: pb.Scenario_SORT_VARIANTS},
+		"tag":         {expected: pb.Scenario_SORT_TAG},
+		"Duration":    {expected: pb.Scenario_SORT_DURATION},
+		"source":      {expected: pb.Scenario_SORT_SOURCE},
+		"unspecified": {err: "unknown scenario sort"},
+		"cost":        {err: "unknown scenario sort"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			order, err := ParseScenarioSort(name)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, order)
+		})
+	}
+}
+
+// Test_SortScenarioRefs tests sorting scenario references by each order.
+func Test_SortScenarioRefs(t *testing.T) {
+	t.Parallel()
+
+	newRef := func(name string, vec *Vector, tags []string, line int64) *pb.Ref_Scenario {
+		scenario := NewScenario()
+		scenario.Name = name
+		scenario.Variants = vec
+		ref := scenario.Ref()
+		ref.Metadata = &pb.Scenario_Metadata{
+			Tags: tags,
+			Range: &pb.Range{
+				Filename: "enos.hcl",
+				Start:    &pb.Range_Pos{Line: line},
+			},
+		}
+
+		return ref
+	}
+
+	refs := func() []*pb.Ref_Scenario {
+		return []*pb.Ref_Scenario{
+			newRef("upgrade", NewVector(NewElement("arch", "arm64"), NewElement("distro", "rhel")), nil, 10),
+			newRef("smoke", nil, []string{"nightly"}, 30),
+			newRef("upgrade", NewVector(NewElement("arch", "amd64"), NewElement("distro", "ubuntu")), []string{"pr"}, 10),
+			newRef("fresh", NewVector(NewElement("arch", "amd64")), []string{"nightly", "arm"}, 20),
+		}
+	}
+
+	durations := NewScenarioDurations()
+	all := refs()
+	durations.Add(all[0], 10*time.Minute)
+	durations.Add(all[0], 20*time.Minute)
+	durations.Add(all[1], 5*time.Minute)
+	durations.Add(all[3], 30*time.Minute)
+
+	for order, expected := range map[pb.Scenario_Sort][]string{
+		pb.Scenario_SORT_UNSPECIFIED: {
+			"fresh [arch:amd64]",
+			"smoke",
+			"upgrade [arch:amd64 distro:ubuntu]",
+			"upgrade [arch:arm64 distro:rhel]",
+		},
+		pb.Scenario_SORT_NAME: {
+			"fresh [arch:amd64]",
+			"smoke",
+			"upgrade [arch:amd64 distro:ubuntu]",
+			"upgrade [arch:arm64 distro:rhel]",
+		},
+		pb.Scenario_SORT_VARIANTS: {
+			"smoke",
+			"fresh [arch:amd64]",
+			"upgrade [arch:amd64 distro:ubuntu]",
+			"upgrade [arch:arm64 distro:rhel]",
+		},
+		pb.Scenario_SORT_TAG: {
+			"fresh [arch:amd64]",
+			"smoke",
+			"upgrade [arch:amd64 distro:ubuntu]",
+			"upgrade [arch:arm64 distro:rhel]",
+		},
+		pb.Scenario_SORT_DURATION: {
+			"fresh [arch:amd64]",
+			"upgrade [arch:arm64 distro:rhel]",
+			"smoke",
+			"upgrade [arch:amd64 distro:ubuntu]",
+		},
+		pb.Scenario_SORT_SOURCE: {
+			"upgrade [arch:amd64 distro:ubuntu]",
+			"upgrade [arch:arm64 distro:rhel]",
+			"fresh [arch:amd64]",
+			"smoke",
+		},
+	} {
+		t.Run(order.String(), func(t *testing.T) {
+			t.Parallel()
+
+			sorted := refs()
+			SortScenarioRefs(sorted, order, durations)
+
+			names := []string{}
+			for _, ref := range sorted {
+				scenario := NewScenario()
+				scenario.FromRef(ref)
+				names = append(names, scenario.String())
+			}
+			require.Equal(t, expected, names)
+		})
+	}
+}
+
+// Test_SortScenarioOutlines tests that outlines are sorted by the durations of all variants of
+// their scenarios.
+func Test_SortScenarioOutlines(t *testing.T) {
+	t.Parallel()
+
+	newOutline := func(name string) *pb.Scenario_Outline {
+		return &pb.Scenario_Outline{
+			Scenario: &pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: name}},
+		}
+	}
+
+	durations := NewScenarioDurations()
+	durations.Add(&pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: "upgrade", Uid: "a"}}, 10*time.Minute)
+	durations.Add(&pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: "upgrade", Uid: "b"}}, 30*time.Minute)
+	durations.Add(&pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: "smoke", Uid: "c"}}, 15*time.Minute)
+
+	dur, ok := durations.Scenario("upgrade")
+	require.True(t, ok)
+	require.Equal(t, 20*time.Minute, dur)
+
+	outlines := []*pb.Scenario_Outline{newOutline("fresh"), newOutline("smoke"), newOutline("upgrade")}
+	SortScenarioOutlines(outlines, pb.Scenario_SORT_DURATION, durations)
+
+	names := []string{}
+	for _, out := range outlines {
+		names = append(names, out.GetScenario().GetId().GetName())
+	}
+	require.Equal(t, []string{"upgrade", "smoke", "fresh"}, names)
+}
@@ -22,7 +22,9 @@ import (
 var scenarioStepSchema = &hcl.BodySchema{
 	Attributes: []hcl.AttributeSchema{
 		{Name: "description", Required: false},
-		{Name: "module", Required: true},
+		{Name: "module", Required: false},
+		{Name: "provisioner", Required: false},
+		{Name: "outputs", Required: false},
 		{Name: "providers", Required: false},
 		{Name: "depends_on", Required: false},
 		{Name: "skip_step", Required: false},
@@ -33,11 +35,13 @@ var scenarioStepSchema = &hcl.BodySchema{
 	},
 }
 
-// ScenarioStep is a step in an Enos scenario.
+// ScenarioStep is a step in an Enos scenario. Steps either call a Terraform module or, if they have
+// a provisioner, provision with another tool, in which case the Module is nil.
 type ScenarioStep struct {
 	Name        string
 	Description string
 	Module      *Module
+	Provisioner *StepProvisioner
 	Providers   map[string]*Provider
 	DependsOn   []string
 	Verifies    []*Quality
@@ -104,6 +108,21 @@ func (ss *ScenarioStep) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagn
 		return diags
 	}
 
+	// Steps with a provisioner don't have a module
+	if _, ok := content.Attributes["provisioner"]; ok {
+		return diags.Extend(ss.decodeProvisioner(block, content, ctx))
+	}
+
+	if outputs, ok := content.Attributes["outputs"]; ok {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "outputs can only be declared by provisioner steps",
+			Detail:   "the outputs of a step with a module are the outputs of the module",
+			Subject:  outputs.NameRange.Ptr(),
+			Context:  outputs.Range.Ptr(),
+		})
+	}
+
 	// Decode the step module reference
 	moduleAttr, moreDiags := ss.decodeModuleAttribute(block, content, ctx)
 	diags = diags.Extend(moreDiags)
@@ -131,7 +150,14 @@ func (ss *ScenarioStep) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagn
 
 	// Decode step variables. This will decode all variables and set them or
 	// override any inherited values from the module.
-	diags = diags.Extend(ss.decodeVariables(content.Blocks.OfType("variables"), ctx))
+	moreDiags = decodeStepVariables(ss.Module.Attrs, content.Blocks.OfType("variables"), ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	// Verify our variables against those of the module, if we can read them.
+	diags = diags.Extend(ss.verifyModuleVariables(block, content, ctx))
 
 	return diags
 }
@@ -158,12 +184,21 @@ func (ss *ScenarioStep) decodeSkip(
 		return diags, false
 	}
 
-	if val.IsNull() || !val.IsWhollyKnown() {
+	switch {
+	case !val.IsWhollyKnown():
+		diags = diags.Append(unknownValueDiagnostic(
+			"skip_step must be a known value",
+			"steps are skipped when the scenario is decoded, so skip_step can refer to variables, "+
+				"matrix values, and locals but not to the outputs of steps, fixtures, or scenarios",
+			skip.Expr, ctx, skip.Expr.Range().Ptr(), nil,
+		))
+	case val.IsNull():
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "skip_step must be a known value",
 			Subject:  skip.Expr.Range().Ptr(),
 		})
+	default:
 	}
 
 	if val.Type() != cty.Bool {
@@ -575,12 +610,24 @@ func (ss *ScenarioStep) decodeAndValidateVerifies(content *hcl.BodyContent, ctx
 		return diags
 	}
 
+	ss.Verifies, moreDiags = decodeVerifies(verifiesVal, verifies.Expr.Range().Ptr(), verifies.Range.Ptr())
+
+	return diags.Extend(moreDiags)
+}
+
+// decodeVerifies decodes the value of a verifies attribute of a step or an output, which is either
+// a single quality or a tuple of unique qualities. The subject and context are the ranges of the
+// diagnostics.
+func decodeVerifies(verifiesVal cty.Value, subject *hcl.Range, context *hcl.Range) ([]*Quality, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	qualities := []*Quality{}
+
 	if verifiesVal.IsNull() || !verifiesVal.IsWhollyKnown() {
-		return diags.Append(&hcl.Diagnostic{
+		return qualities, diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "verifies value must be knowable at compile time",
-			Subject:  verifies.Expr.Range().Ptr(),
-			Context:  verifies.Range.Ptr(),
+			Subject:  subject,
+			Context:  context,
 		})
 	}
 
@@ -590,11 +637,11 @@ func (ss *ScenarioStep) decodeAndValidateVerifies(content *hcl.BodyContent, ctx
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "invalid input for 'verifies' attribute. Must be one-or-more qualities",
-			Subject:  verifies.Expr.Range().Ptr(),
-			Context:  verifies.Range.Ptr(),
+			Subject:  subject,
+			Context:  context,
 		})
 
-		return diags
+		return qualities, diags
 	}
 
 	if !verifiesVal.Type().IsTupleType() {
@@ -605,14 +652,14 @@ func (ss *ScenarioStep) decodeAndValidateVerifies(content *hcl.BodyContent, ctx
 				Severity: hcl.DiagError,
 				Summary:  "invalid input for 'verifies' attribute. Must be one-or-more qualities",
 				Detail:   err.Error(),
-				Subject:  verifies.Expr.Range().Ptr(),
-				Context:  verifies.Range.Ptr(),
+				Subject:  subject,
+				Context:  context,
 			})
 		}
 
-		ss.Verifies = append(ss.Verifies, quality)
+		qualities = append(qualities, quality)
 
-		return diags
+		return qualities, diags
 	}
 
 	// Iterate over the verifies attributes and decode them. Prevent the same quality from being
@@ -626,8 +673,8 @@ func (ss *ScenarioStep) decodeAndValidateVerifies(content *hcl.BodyContent, ctx
 				Severity: hcl.DiagError,
 				Summary:  "invalid input for 'verifies' attribute. Must be one-or-more qualities",
 				Detail:   err.Error(),
-				Subject:  verifies.Expr.Range().Ptr(),
-				Context:  verifies.Range.Ptr(),
+				Subject:  subject,
+				Context:  context,
 			})
 
 			continue
@@ -638,20 +685,20 @@ func (ss *ScenarioStep) decodeAndValidateVerifies(content *hcl.BodyContent, ctx
 				Severity: hcl.DiagError,
 				Detail:   "cannot define the same quality more than once",
 				Summary:  "quality verification of " + quality.Name + " cannot be specified more than once",
-				Subject:  verifies.Expr.Range().Ptr(),
-				Context:  verifies.Range.Ptr(),
+				Subject:  subject,
+				Context:  context,
 			})
 
 			continue
 		}
 
 		verifiesSet[quality.Name] = struct{}{}
-		ss.Verifies = append(ss.Verifies, quality)
+		qualities = append(qualities, quality)
 	}
 
-	slices.SortStableFunc(ss.Verifies, CompareQuality)
+	slices.SortStableFunc(qualities, CompareQuality)
 
-	return diags
+	return qualities, diags
 }
 
 // decodeAndValidateProvidersAttribute decodess the providers attribute
@@ -879,7 +926,48 @@ func (ss *ScenarioStep) copyModuleAttributes(module cty.Value) {
 	}
 }
 
-func (ss *ScenarioStep) decodeVariables(varBlocks hcl.Blocks, ctx *hcl.EvalContext) hcl.Diagnostics {
+// decodeProvisioner decodes the provisioner, outputs, and variables of a step with a provisioner.
+func (ss *ScenarioStep) decodeProvisioner(
+	block *hcl.Block,
+	content *hcl.BodyContent,
+	ctx *hcl.EvalContext,
+) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for _, name := range []string{"module", "providers"} {
+		if attr, ok := content.Attributes[name]; ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("provisioner steps cannot have a %s", name),
+				Detail:   fmt.Sprintf("step %s has a provisioner and is not executed by a Terraform module", ss.Name),
+				Subject:  attr.NameRange.Ptr(),
+				Context:  attr.Range.Ptr(),
+			})
+		}
+	}
+	if diags.HasErrors() {
+		return diags
+	}
+
+	ss.Module = nil
+	ss.Provisioner = NewStepProvisioner()
+	moreDiags := ss.Provisioner.decodeProvisionerAttributes(content, ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	moreDiags = decodeStepVariables(ss.Provisioner.Attrs, content.Blocks.OfType("variables"), ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	return diags.Extend(ss.Provisioner.verifyRequiredVariables(block))
+}
+
+// decodeStepVariables decodes the variables blocks of a step into the step attributes.
+func decodeStepVariables(stepAttrs map[string]cty.Value, varBlocks hcl.Blocks, ctx *hcl.EvalContext) hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
 
 	for _, varBlock := range varBlocks {
@@ -917,7 +1005,7 @@ func (ss *ScenarioStep) decodeVariables(varBlocks hcl.Blocks, ctx *hcl.EvalConte
 		}
 
 		for attrName, attrVal := range val.AsValueMap() {
-			ss.Module.Attrs[attrName] = attrVal
+			stepAttrs[attrName] = attrVal
 		}
 	}
 
@@ -955,13 +1043,22 @@ func (ss *ScenarioStep) insertIntoCtx(ctx *hcl.EvalContext) hcl.Diagnostics {
 		steps = map[string]cty.Value{}
 	}
 
-	vals := map[string]cty.Value{
-		"source":    cty.StringVal(ss.Module.Source),
-		"name":      cty.StringVal(ss.Name),
-		"variables": cty.ObjectVal(ss.Module.Attrs),
-	}
-	if ss.Module.Version != "" {
-		vals["version"] = cty.StringVal(ss.Module.Version)
+	var vals map[string]cty.Value
+	if ss.Provisioner != nil {
+		vals = map[string]cty.Value{
+			"provisioner": cty.StringVal(ss.Provisioner.Type),
+			"name":        cty.StringVal(ss.Name),
+			"variables":   cty.ObjectVal(ss.Provisioner.Attrs),
+		}
+	} else {
+		vals = map[string]cty.Value{
+			"source":    cty.StringVal(ss.Module.Source),
+			"name":      cty.StringVal(ss.Name),
+			"variables": cty.ObjectVal(ss.Module.Attrs),
+		}
+		if ss.Module.Version != "" {
+			vals["version"] = cty.StringVal(ss.Module.Version)
+		}
 	}
 
 	steps[ss.Name] = cty.ObjectVal(vals)
@@ -936,3 +936,211 @@ scenario "boundary" {
 		})
 	}
 }
+
+// Test_Decode_Scenario_Step_reference_cycle tests that step reference cycles are reported with
+// the full cycle path.
+func Test_Decode_Scenario_Step_reference_cycle(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		steps string
+		cycle string
+	}{
+		"variables": {
+			steps: `
+  step "a" {
+    module = module.foo
+
+    variables {
+      input = step.c.out
+    }
+  }
+
+  step "b" {
+    module = module.foo
+
+    variables {
+      input = step.a.out
+    }
+  }
+
+  step "c" {
+    module = module.foo
+
+    variables {
+      input = [step.b.out]
+    }
+  }
+`,
+			cycle: "a -> c -> b -> a",
+		},
+		"depends_on": {
+			steps: `
+  step "a" {
+    module     = module.foo
+    depends_on = ["b"]
+  }
+
+  step "b" {
+    module = module.foo
+
+    variables {
+      input = step.a.out
+    }
+  }
+`,
+			cycle: "a -> b -> a",
+		},
+		"self": {
+			steps: `
+  step "a" {
+    module = module.foo
+
+    variables {
+      input = step.a.out
+    }
+  }
+`,
+			cycle: "a -> a",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "foo" {
+  source = "./foo"
+}
+
+scenario "test" {
+%s
+}
+`, test.steps)), DecodeTargetAll)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "step reference cycle")
+			require.Contains(t, err.Error(), test.cycle)
+		})
+	}
+}
+
+// Test_Decode_Scenario_Step_Variable_Expression tests decoding step variables that apply functions
+// and conditionals to the outputs of other steps.
+func Test_Decode_Scenario_Step_Variable_Expression(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	for desc, test := range map[string]struct {
+		value      string
+		expected   string
+		references []string
+		fail       string
+	}{
+		"function": {
+			value:      `join(",", step.backend.hosts)`,
+			expected:   `join(",", step.backend.hosts)`,
+			references: []string{"step.backend.hosts"},
+		},
+		"known values": {
+			value:      `coalesce(step.backend.addr, local.fallback, var.input)`,
+			expected:   `coalesce(step.backend.addr, "none", "fromenv")`,
+			references: []string{"step.backend.addr"},
+		},
+		"known condition": {
+			value:      `matrix.arch == "amd64" ? upper(step.backend.addr) : step.backend.name`,
+			expected:   `upper(step.backend.addr)`,
+			references: []string{"step.backend.addr"},
+		},
+		"unknown condition": {
+			value:      `step.backend.enabled ? step.backend.addr : local.fallback`,
+			expected:   `step.backend.enabled ? step.backend.addr : "none"`,
+			references: []string{"step.backend.enabled", "step.backend.addr"},
+		},
+		"known step variable": {
+			value:      `"${step.backend.variables.port}:${step.backend.addr}"`,
+			expected:   `"${8080}:${step.backend.addr}"`,
+			references: []string{"step.backend.addr"},
+		},
+		"step variable reference": {
+			value:      `format("%s-%s", step.frontend.variables.backend, step.backend.port)`,
+			expected:   `format("%s-%s", step.backend.addr, step.backend.port)`,
+			references: []string{"step.backend.addr", "step.backend.port"},
+		},
+		"undefined step": {
+			value: `upper(step.nope.addr)`,
+			fail:  "no step named nope",
+		},
+		"template directive": {
+			value: `"%{for h in step.backend.hosts}${h},%{endfor}"`,
+			fail:  "unsupported step variable expression",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+variable "input" {
+  type    = string
+  default = "defaultval"
+}
+
+module "backend" {
+  source = "%s"
+}
+
+scenario "basic" {
+  matrix {
+    arch = ["amd64"]
+  }
+
+  locals {
+    fallback = "none"
+  }
+
+  step "backend" {
+    module = module.backend
+
+    variables {
+      port = 8080
+    }
+  }
+
+  step "frontend" {
+    module = module.backend
+
+    variables {
+      backend = step.backend.addr
+    }
+  }
+
+  step "composed" {
+    module = module.backend
+
+    variables {
+      input = %s
+    }
+  }
+}
+`, modulePath, test.value)), DecodeTargetAll, WithDecoderEnv([]string{"ENOS_VAR_input=fromenv"}))
+			if test.fail != "" {
+				require.ErrorContains(t, err, test.fail)
+
+				return
+			}
+			require.NoError(t, err)
+
+			steps := fp.ScenarioBlocks[0].Scenarios[0].Steps
+			require.Len(t, steps, 3)
+			stepVar, diags := StepVariableFromVal(steps[2].Module.Attrs["input"])
+			require.False(t, diags.HasErrors(), diags.Error())
+			require.Equal(t, test.expected, stepVar.Expression)
+
+			references := []string{}
+			for _, ref := range stepVar.References {
+				references = append(references, traversalHCL(ref))
+			}
+			require.Equal(t, test.references, references)
+		})
+	}
+}
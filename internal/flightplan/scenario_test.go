@@ -6,6 +6,7 @@ package flightplan
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -220,3 +221,188 @@ scenario "backend" {
 		})
 	}
 }
+
+// Test_Scenario_Slug tests that scenario slugs are short, filesystem safe, and unique.
+func Test_Scenario_Slug(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		name     string
+		variants *Vector
+		expected string
+	}{
+		"no variants": {
+			name:     "test",
+			expected: "test_9f86d081",
+		},
+		"variants": {
+			name:     "test",
+			variants: NewVector(NewElement("backend", "consul"), NewElement("arch", "amd64")),
+			expected: "test_consul_amd64_",
+		},
+		"unsafe characters": {
+			name:     "test",
+			variants: NewVector(NewElement("edition", "ent.fips1402"), NewElement("artifact", "docker/latest")),
+			expected: "test_ent-fips1402_docker-latest_",
+		},
+		"long variants": {
+			name: "test",
+			variants: NewVector(
+				NewElement("one", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				NewElement("two", "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"),
+			),
+			expected: "test_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa_bbbbbbbbbbbbbbbbb_",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			scenario := NewScenario()
+			scenario.Name = test.name
+			scenario.Variants = test.variants
+			slug := scenario.Slug()
+			require.Regexp(t, "^[A-Za-z0-9_-]+$", slug)
+			require.LessOrEqual(t, len(slug), scenarioSlugMaxPrefixLen+1+scenarioSlugUIDLen)
+			require.True(t, strings.HasPrefix(slug, test.expected), slug)
+			require.True(t, strings.HasSuffix(slug, scenario.UID()[:scenarioSlugUIDLen]), slug)
+		})
+	}
+
+	// Variants whose values are identical should still have unique slugs.
+	a := NewScenario()
+	a.Name = "test"
+	a.Variants = NewVector(NewElement("client", "amd64"), NewElement("server", "arm64"))
+	b := NewScenario()
+	b.Name = "test"
+	b.Variants = NewVector(NewElement("client", "arm64"), NewElement("server", "amd64"))
+	c := NewScenario()
+	c.Name = "test"
+	c.Variants = NewVector(NewElement("client", "arm64"), NewElement("server", "amd64"), NewElement("x", "y"))
+	require.NotEqual(t, a.Slug(), b.Slug())
+	require.NotEqual(t, b.Slug(), c.Slug())
+}
+
+// Test_Scenario_Metadata tests decoding scenario tags and the metadata of scenario references.
+func Test_Scenario_Metadata(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+quality "tests_pass" {
+  description = "the tests pass"
+}
+
+scenario "backend" {
+  description = "the backend scenario"
+  tags        = ["team:vault", "nightly", "nightly"]
+
+  step "first" {
+    module   = module.backend
+    verifies = quality.tests_pass
+  }
+}
+
+scenario "untagged" {
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+	require.Len(t, fp.ScenarioBlocks, 2)
+
+	scenario := fp.ScenarioBlocks[0].Scenarios[0]
+	require.Equal(t, []string{"nightly", "team:vault"}, scenario.Tags)
+	require.Nil(t, scenario.Ref().GetMetadata())
+
+	ref := scenario.RefWithMetadata()
+	require.Equal(t, "the backend scenario", ref.GetId().GetDescription())
+	require.Equal(t, []string{"nightly", "team:vault"}, ref.GetMetadata().GetTags())
+	require.Equal(t, "decoder-test.hcl", ref.GetMetadata().GetRange().GetFilename())
+	require.Equal(t, int64(10), ref.GetMetadata().GetRange().GetStart().GetLine())
+	require.Len(t, ref.GetMetadata().GetVerifies(), 1)
+	require.Equal(t, "tests_pass", ref.GetMetadata().GetVerifies()[0].GetName())
+
+	outline := scenario.Outline()
+	require.Equal(t, []string{"nightly", "team:vault"}, outline.GetScenario().GetMetadata().GetTags())
+	require.Len(t, outline.GetVerifies(), 1)
+
+	untagged := fp.ScenarioBlocks[1].Scenarios[0]
+	require.Empty(t, untagged.RefWithMetadata().GetMetadata().GetTags())
+	require.Empty(t, untagged.RefWithMetadata().GetMetadata().GetVerifies())
+	require.Nil(t, untagged.Outline().GetScenario().GetMetadata())
+
+	_, err = testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "backend" {
+  tags = ["nightly", ""]
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.ErrorContains(t, err, "scenario tags must be non-empty strings")
+}
+
+// Test_Scenario_XFail tests decoding why scenario variants are expected to fail.
+func Test_Scenario_XFail(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "backend" {
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  xfail = matrix.arch == "arm64" ? "https://github.com/hashicorp/enos/issues/1" : null
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+	require.Len(t, fp.ScenarioBlocks[0].Scenarios, 2)
+
+	for _, scenario := range fp.ScenarioBlocks[0].Scenarios {
+		if scenario.Variants.String() == "[arch:arm64]" {
+			require.Equal(t, "https://github.com/hashicorp/enos/issues/1", scenario.XFail)
+			require.Equal(t, scenario.XFail, scenario.RefWithMetadata().GetMetadata().GetXfail())
+		} else {
+			require.Empty(t, scenario.XFail)
+			require.Empty(t, scenario.RefWithMetadata().GetMetadata().GetXfail())
+		}
+	}
+
+	_, err = testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "backend" {
+  xfail = " "
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.ErrorContains(t, err, "scenario xfail must be a non-empty string")
+}
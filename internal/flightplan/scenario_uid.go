@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// scenarioDefinition is where a scenario variant has been defined.
+type scenarioDefinition struct {
+	block    int
+	scenario *Scenario
+}
+
+// verifyUniqueScenarioIdentifiers verifies that no two scenarios of the scenario blocks have the
+// same UID or slug. As both are used to determine the directory of the generated Terraform module,
+// scenarios that share them would share and clobber each others Terraform state. This usually
+// happens when a scenario block has been copied without being renamed. Only the first collision of
+// each pair of scenario blocks is reported.
+func verifyUniqueScenarioIdentifiers(blocks ScenarioBlocks) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	uids := map[string]*scenarioDefinition{}
+	slugs := map[string]*scenarioDefinition{}
+	reported := map[[2]int]struct{}{}
+
+	collide := func(kind string, prior *scenarioDefinition, def *scenarioDefinition) {
+		pair := [2]int{prior.block, def.block}
+		if _, ok := reported[pair]; ok {
+			return
+		}
+		reported[pair] = struct{}{}
+
+		priorRange := blocks[prior.block].Block.DefRange
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "duplicate scenario " + kind,
+			Detail: fmt.Sprintf("scenario %s has the same %s as scenario %s defined at %s, scenarios must be unique "+
+				"as they would otherwise share the same output directory and Terraform state",
+				def.scenario.String(), kind, prior.scenario.String(), priorRange.String(),
+			),
+			Subject: blocks[def.block].Block.DefRange.Ptr(),
+		})
+	}
+
+	for i, sb := range blocks {
+		scenarios := []*Scenario{}
+		if sb.Matrix() == nil || len(sb.Matrix().GetVectors()) < 1 {
+			scenarios = append(scenarios, &Scenario{Name: sb.Name})
+		} else {
+			for _, vec := range sb.Matrix().GetVectors() {
+				scenarios = append(scenarios, &Scenario{Name: sb.Name, Variants: vec})
+			}
+		}
+
+		for _, scenario := range scenarios {
+			def := &scenarioDefinition{block: i, scenario: scenario}
+
+			uid := scenario.UID()
+			if prior, ok := uids[uid]; ok {
+				collide("UID", prior, def)
+
+				continue
+			}
+			uids[uid] = def
+
+			slug := scenario.Slug()
+			if prior, ok := slugs[slug]; ok {
+				collide("output directory", prior, def)
+
+				continue
+			}
+			slugs[slug] = def
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Decode_Scenario_DuplicateIdentifiers tests that scenarios which would share the same UID
+// are rejected.
+func Test_Decode_Scenario_DuplicateIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		hcl  string
+		fail string
+	}{
+		"copied block": {
+			hcl: `
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+
+scenario "test" {
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			fail: "scenario test has the same UID as scenario test defined at decoder-test.hcl:6,1-16",
+		},
+		"copied block with overlapping variants": {
+			hcl: `
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  step "one" {
+    module = module.foo
+  }
+}
+
+scenario "test" {
+  matrix {
+    arch = ["arm64", "s390x"]
+  }
+
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+			fail: "scenario test [arch:arm64] has the same UID as scenario test [arch:arm64] defined at decoder-test.hcl:6,1-16",
+		},
+		"unique variants": {
+			hcl: `
+module "foo" {
+  source = "hashicorp/consul/aws"
+}
+
+scenario "test" {
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  step "one" {
+    module = module.foo
+  }
+}
+
+scenario "test_other" {
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  step "one" {
+    module = module.foo
+  }
+}
+`,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testDecodeHCL(t, []byte(test.hcl), DecodeTargetScenariosNamesExpandVariants)
+			if test.fail == "" {
+				require.NoError(t, err)
+
+				return
+			}
+			require.ErrorContains(t, err, test.fail)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+const blockTypeSkip = "skip"
+
+var skipConfigSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeSkip},
+	},
+}
+
+var skipSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "scenario_filter", Required: true},
+		{Name: "reason", Required: true},
+	},
+}
+
+// Skip excludes the scenarios and variants that match its filter from every operation, e.g. known
+// bad variants that are waiting on a fix. Skips are configured with "skip" blocks in
+// enos*.skip.hcl or enos*.skip.json files.
+type Skip struct {
+	Filter    *ScenarioFilter
+	Reason    string
+	DeclRange hcl.Range
+}
+
+// SkippedScenario is a scenario, or a variant of it, that has been skipped. The scenario doesn't
+// have variants when all of its variants have been skipped.
+type SkippedScenario struct {
+	Scenario *Scenario
+	Reason   string
+}
+
+// DecodeSkips parses and decodes the skip files and returns their skips along with the parsed
+// files, which can be used to add snippets to diagnostics.
+func DecodeSkips(files RawFiles) ([]*Skip, map[string]*hcl.File, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	skips := []*Skip{}
+	parser := hclparse.NewParser()
+
+	// Sort the paths so that our skips and diagnostics are deterministic.
+	paths := []string{}
+	for path := range files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		var file *hcl.File
+		var moreDiags hcl.Diagnostics
+		if strings.HasSuffix(path, ".json") {
+			file, moreDiags = parser.ParseJSON(files[path], path)
+		} else {
+			file, moreDiags = parser.ParseHCL(files[path], path)
+		}
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		content, moreDiags := file.Body.Content(skipConfigSchema)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		for _, block := range content.Blocks.OfType(blockTypeSkip) {
+			skip, moreDiags := decodeSkip(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			skips = append(skips, skip)
+		}
+	}
+
+	return skips, parser.Files(), diags
+}
+
+// decodeSkip decodes a "skip" block.
+func decodeSkip(block *hcl.Block) (*Skip, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := block.Body.Content(skipSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	skip := &Skip{DeclRange: block.DefRange}
+	attrs := map[string]string{}
+	for _, name := range []string{"scenario_filter", "reason"} {
+		attr := content.Attributes[name]
+		val, moreDiags := attr.Expr.Value(nil)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		if val.IsNull() || !val.IsKnown() || !val.Type().Equals(cty.String) || strings.TrimSpace(val.AsString()) == "" {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid skip " + name,
+				Detail:   fmt.Sprintf("the %s of a skip must be a non-empty string", name),
+				Subject:  attr.Expr.Range().Ptr(),
+				Context:  block.DefRange.Ptr(),
+			})
+
+			continue
+		}
+		attrs[name] = val.AsString()
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	filter, err := ParseScenarioFilter(strings.Fields(attrs["scenario_filter"]))
+	if err != nil {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid skip scenario_filter",
+			Detail:   err.Error(),
+			Subject:  content.Attributes["scenario_filter"].Expr.Range().Ptr(),
+			Context:  block.DefRange.Ptr(),
+		})
+	}
+	skip.Filter = filter
+	skip.Reason = attrs["reason"]
+
+	return skip, diags
+}
+
+// matchesScenarioBlock returns whether the skip skips every variant of the scenario block.
+func (s *Skip) matchesScenarioBlock(name string) bool {
+	return s.Filter.Name == name && s.Filter.matchesAllVariants()
+}
+
+// Proto returns the skipped scenario as a proto message.
+func (s *SkippedScenario) Proto() *pb.Scenario_Skipped {
+	return &pb.Scenario_Skipped{
+		Scenario: s.Scenario.Ref(),
+		Reason:   s.Reason,
+	}
+}
+
+// String returns the skipped scenario and the reason as a string.
+func (s *SkippedScenario) String() string {
+	return fmt.Sprintf("%s: %s", s.Scenario.String(), s.Reason)
+}
+
+// SkippedProto returns the skipped scenarios of the flight plan as proto messages.
+func (fp *FlightPlan) SkippedProto() []*pb.Scenario_Skipped {
+	if fp == nil || len(fp.Skipped) == 0 {
+		return nil
+	}
+
+	res := []*pb.Scenario_Skipped{}
+	for _, s := range fp.Skipped {
+		res = append(res, s.Proto())
+	}
+
+	return res
+}
@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_DecodeSkips tests decoding skip files in HCL and JSON.
+func Test_DecodeSkips(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		files    RawFiles
+		expected []string
+		err      string
+	}{
+		"hcl and json": {
+			files: RawFiles{
+				"enos.skip.hcl": []byte(`
+skip {
+  scenario_filter = "upgrade arch:arm64"
+  reason          = "the arm64 AMI is broken"
+}
+
+skip {
+  scenario_filter = "smoke"
+  reason          = "flaky until the timeout is raised"
+}
+`),
+				"enos-ci.skip.json": []byte(`{
+  "skip": [
+    {"scenario_filter": "upgrade !distro:ubuntu", "reason": "waiting on a fix"}
+  ]
+}`),
+			},
+			expected: []string{
+				"upgrade !distro:ubuntu: waiting on a fix",
+				"upgrade arch:arm64: the arm64 AMI is broken",
+				"smoke: flaky until the timeout is raised",
+			},
+		},
+		"missing reason": {
+			files: RawFiles{"enos.skip.hcl": []byte(`
+skip {
+  scenario_filter = "smoke"
+}
+`)},
+			err: `The argument "reason" is required`,
+		},
+		"empty reason": {
+			files: RawFiles{"enos.skip.hcl": []byte(`
+skip {
+  scenario_filter = "smoke"
+  reason          = " "
+}
+`)},
+			err: "the reason of a skip must be a non-empty string",
+		},
+		"invalid filter": {
+			files: RawFiles{"enos.skip.hcl": []byte(`
+skip {
+  scenario_filter = "smoke arch"
+  reason          = "broken"
+}
+`)},
+			err: "invalid skip scenario_filter",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			skips, files, diags := DecodeSkips(test.files)
+			if test.err != "" {
+				require.True(t, diags.HasErrors())
+				require.ErrorContains(t, testDiagsToError(files, diags), test.err)
+
+				return
+			}
+
+			require.False(t, diags.HasErrors(), testDiagsToError(files, diags))
+			got := []string{}
+			for _, skip := range skips {
+				got = append(got, skip.Filter.String()+": "+skip.Reason)
+			}
+			require.Equal(t, test.expected, got)
+		})
+	}
+}
+
+// Test_Decode_Skips tests that skipped scenarios and variants are excluded when decoding and are
+// recorded with their reasons.
+func Test_Decode_Skips(t *testing.T) {
+	t.Parallel()
+
+	skips, files, diags := DecodeSkips(RawFiles{"enos.skip.hcl": []byte(`
+skip {
+  scenario_filter = "upgrade arch:arm64"
+  reason          = "the arm64 AMI is broken"
+}
+
+skip {
+  scenario_filter = "smoke"
+  reason          = "flaky"
+}
+`)})
+	require.False(t, diags.HasErrors(), testDiagsToError(files, diags))
+
+	fp, err := testDecodeHCL(t, []byte(`
+module "backend" {
+  source = "./backend"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch   = ["amd64", "arm64"]
+    distro = ["ubuntu", "rhel"]
+  }
+
+  step "first" {
+    module = module.backend
+  }
+}
+
+scenario "smoke" {
+  step "first" {
+    module = module.backend
+  }
+}
+`), DecodeTargetScenariosNamesExpandVariants, WithDecoderSkips(skips))
+	require.NoError(t, err)
+
+	scenarios := []string{}
+	for _, s := range fp.Scenarios() {
+		scenarios = append(scenarios, s.String())
+	}
+	require.ElementsMatch(t, []string{
+		"upgrade [arch:amd64 distro:rhel]",
+		"upgrade [arch:amd64 distro:ubuntu]",
+	}, scenarios)
+
+	skipped := []string{}
+	for _, s := range fp.Skipped {
+		skipped = append(skipped, s.String())
+	}
+	require.Equal(t, []string{
+		"smoke: flaky",
+		"upgrade [arch:arm64 distro:rhel]: the arm64 AMI is broken",
+		"upgrade [arch:arm64 distro:ubuntu]: the arm64 AMI is broken",
+	}, skipped)
+
+	// Skipped scenarios are explained when the filter would have selected them
+	sf, err := ParseScenarioFilter([]string{"upgrade", "distro:rhel"})
+	require.NoError(t, err)
+	all, err := ParseScenarioFilter(nil)
+	require.NoError(t, err)
+	explanation := ExplainScenarioFilter(fp.ScenarioBlocks, fp.Skipped, sf, 0)
+	exclusions := []string{}
+	for _, ex := range explanation.GetExclusions() {
+		exclusions = append(exclusions, ex.GetScenario()+" "+ex.GetReason().String()+" "+ex.GetDetail())
+	}
+	require.Contains(t, exclusions, "upgrade REASON_SKIP the arm64 AMI is broken")
+	require.NotContains(t, exclusions, "smoke REASON_SKIP flaky")
+	require.Len(t, ExplainScenarioFilter(fp.ScenarioBlocks, fp.Skipped, all, 0).GetExclusions(), 3)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Built-in step provisioner types. Any other provisioner type is the name of a provisioner of a
+// plugin.
+const (
+	ProvisionerTypeExec    = "exec"
+	ProvisionerTypeAnsible = "ansible"
+)
+
+// provisionerRequiredVariables are the variables that the built-in provisioners require.
+var provisionerRequiredVariables = map[string][]string{
+	ProvisionerTypeExec:    {"command"},
+	ProvisionerTypeAnsible: {"playbook"},
+}
+
+// StepProvisioner is the provisioner of a step that provisions with a tool other than Terraform.
+// The step is generated into a Terraform module that executes the provisioner with the variables
+// of the step and exposes the outputs of the provisioner as the outputs of the module.
+type StepProvisioner struct {
+	// Type is exec, ansible, or the name of the provisioner of a plugin.
+	Type string
+	// Attrs are the variables of the step.
+	Attrs map[string]cty.Value
+	// Outputs are the names of the outputs of the provisioner, which other steps can refer to.
+	Outputs []string
+}
+
+// NewStepProvisioner returns a new StepProvisioner.
+func NewStepProvisioner() *StepProvisioner {
+	return &StepProvisioner{
+		Attrs:   map[string]cty.Value{},
+		Outputs: []string{},
+	}
+}
+
+// decodeProvisionerAttributes decodes the "provisioner" and "outputs" attributes of a provisioner
+// step.
+func (p *StepProvisioner) decodeProvisionerAttributes(
+	content *hcl.BodyContent,
+	ctx *hcl.EvalContext,
+) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	attr := content.Attributes["provisioner"]
+	val, moreDiags := attr.Expr.Value(ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	if val.IsNull() || !val.IsWhollyKnown() || !val.Type().Equals(cty.String) || !hclsyntax.ValidIdentifier(val.AsString()) {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid provisioner value",
+			Detail:   `provisioner must be "exec", "ansible", or the name of a provisioner of a plugin`,
+			Subject:  attr.Expr.Range().Ptr(),
+			Context:  attr.Range.Ptr(),
+		})
+	}
+	p.Type = val.AsString()
+
+	outputs, ok := content.Attributes["outputs"]
+	if !ok {
+		return diags
+	}
+
+	val, moreDiags = outputs.Expr.Value(ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return diags
+	}
+
+	if val.IsNull() || !val.IsWhollyKnown() || !val.CanIterateElements() || val.Type().IsObjectType() || val.Type().IsMapType() {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid outputs value",
+			Detail:   "outputs must be a list of output names",
+			Subject:  outputs.Expr.Range().Ptr(),
+			Context:  outputs.Range.Ptr(),
+		})
+	}
+
+	for _, out := range val.AsValueSlice() {
+		if !out.Type().Equals(cty.String) || out.IsNull() || !hclsyntax.ValidIdentifier(out.AsString()) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid output name",
+				Detail:   "outputs must be valid identifiers",
+				Subject:  outputs.Expr.Range().Ptr(),
+				Context:  outputs.Range.Ptr(),
+			})
+
+			continue
+		}
+
+		if slices.Contains(p.Outputs, out.AsString()) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "duplicate output name",
+				Detail:   fmt.Sprintf("output %s has already been declared", out.AsString()),
+				Subject:  outputs.Expr.Range().Ptr(),
+				Context:  outputs.Range.Ptr(),
+			})
+
+			continue
+		}
+
+		p.Outputs = append(p.Outputs, out.AsString())
+	}
+
+	return diags
+}
+
+// verifyRequiredVariables returns error diagnostics for variables that the built-in provisioner
+// requires and the step doesn't set.
+func (p *StepProvisioner) verifyRequiredVariables(block *hcl.Block) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for _, name := range provisionerRequiredVariables[p.Type] {
+		if _, ok := p.Attrs[name]; !ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "missing provisioner variable",
+				Detail:   fmt.Sprintf("the %s provisioner of step %s requires the %s variable", p.Type, block.Labels[0], name),
+				Subject:  block.DefRange.Ptr(),
+			})
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Test_Decode_Scenario_Step_Provisioner tests decoding steps with provisioners.
+func Test_Decode_Scenario_Step_Provisioner(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "infra" {
+  source = "%s"
+}
+
+scenario "mixed" {
+  step "infra" {
+    module = module.infra
+  }
+
+  step "configure" {
+    depends_on  = [step.infra]
+    provisioner = "ansible"
+    outputs     = ["version", "leader"]
+
+    variables {
+      playbook = "playbooks/site.yml"
+      hosts    = step.infra.hosts
+    }
+  }
+
+  step "verify" {
+    provisioner = "acme_checks"
+
+    variables {
+      leader = step.configure.leader
+    }
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+
+	steps := fp.ScenarioBlocks[0].Scenarios[0].Steps
+	require.Len(t, steps, 3)
+	require.Nil(t, steps[0].Provisioner)
+	require.NotNil(t, steps[0].Module)
+
+	require.Nil(t, steps[1].Module)
+	require.Equal(t, []string{"infra"}, steps[1].DependsOn)
+	require.Equal(t, ProvisionerTypeAnsible, steps[1].Provisioner.Type)
+	require.Equal(t, []string{"version", "leader"}, steps[1].Provisioner.Outputs)
+	require.Len(t, steps[1].Provisioner.Attrs, 2)
+	testMostlyEqualStepVar(t, testMakeStepVarValue(cty.StringVal("playbooks/site.yml")), steps[1].Provisioner.Attrs["playbook"])
+	testMostlyEqualStepVar(t, testMakeStepVarTraversal("step", "infra", "hosts"), steps[1].Provisioner.Attrs["hosts"])
+
+	require.Equal(t, "acme_checks", steps[2].Provisioner.Type)
+	require.Empty(t, steps[2].Provisioner.Outputs)
+	testMostlyEqualStepVar(t, testMakeStepVarTraversal("step", "configure", "leader"), steps[2].Provisioner.Attrs["leader"])
+}
+
+// Test_Decode_Scenario_Step_Provisioner_invalid tests that invalid provisioner steps fail to decode.
+func Test_Decode_Scenario_Step_Provisioner_invalid(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	for desc, test := range map[string]struct {
+		step string
+		err  string
+	}{
+		"module and provisioner": {
+			step: `
+    module      = module.infra
+    provisioner = "exec"
+
+    variables {
+      command = "true"
+    }
+`,
+			err: "provisioner steps cannot have a module",
+		},
+		"invalid provisioner": {
+			step: `
+    provisioner = "not valid"
+`,
+			err: "invalid provisioner value",
+		},
+		"missing required variable": {
+			step: `
+    provisioner = "exec"
+`,
+			err: "the exec provisioner of step test requires the command variable",
+		},
+		"invalid outputs": {
+			step: `
+    provisioner = "exec"
+    outputs     = ["ok", "not ok"]
+
+    variables {
+      command = "true"
+    }
+`,
+			err: "outputs must be valid identifiers",
+		},
+		"duplicate outputs": {
+			step: `
+    provisioner = "exec"
+    outputs     = ["ok", "ok"]
+
+    variables {
+      command = "true"
+    }
+`,
+			err: "output ok has already been declared",
+		},
+		"outputs of module step": {
+			step: `
+    module  = module.infra
+    outputs = ["ok"]
+`,
+			err: "outputs can only be declared by provisioner steps",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "infra" {
+  source = "%s"
+}
+
+scenario "test" {
+  step "test" {%s  }
+}
+`, modulePath, test.step)), DecodeTargetAll)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// stepReference is a reference from a step to another step, either by referring to the step in an
+// expression or by depending on it.
+type stepReference struct {
+	to  string
+	rng hcl.Range
+}
+
+// findStepReferences returns the references that a step block makes to any of the defined steps.
+func findStepReferences(block *hcl.Block, defined map[string]struct{}) []stepReference {
+	refs := []stepReference{}
+
+	body, ok := block.Body.(*hclsyntax.Body)
+	if !ok {
+		return refs
+	}
+
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		expr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+		if !ok || expr.Traversal.RootName() != "step" || len(expr.Traversal) < 2 {
+			return nil
+		}
+
+		attr, ok := expr.Traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			return nil
+		}
+
+		if _, ok := defined[attr.Name]; ok {
+			refs = append(refs, stepReference{to: attr.Name, rng: expr.Range()})
+		}
+
+		return nil
+	})
+
+	// Steps can also depend on other steps by name.
+	if attr, ok := body.Attributes["depends_on"]; ok {
+		if tuple, ok := attr.Expr.(*hclsyntax.TupleConsExpr); ok {
+			for _, expr := range tuple.Exprs {
+				val, diags := expr.Value(nil)
+				if diags.HasErrors() || !val.IsKnown() || val.IsNull() || !val.Type().Equals(cty.String) {
+					continue
+				}
+
+				if _, ok := defined[val.AsString()]; ok {
+					refs = append(refs, stepReference{to: val.AsString(), rng: expr.Range()})
+				}
+			}
+		}
+	}
+
+	return refs
+}
+
+// verifyStepReferencesAreAcyclic verifies that the step blocks of a scenario don't reference each
+// other in a cycle. Steps can only ever refer to steps that have been decoded before them so a
+// cycle would otherwise fail with diagnostics about undefined steps that don't explain why.
+func verifyStepReferencesAreAcyclic(blocks hcl.Blocks) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	defined := map[string]struct{}{}
+	for _, block := range blocks {
+		if len(block.Labels) > 0 {
+			defined[block.Labels[0]] = struct{}{}
+		}
+	}
+
+	refs := map[string][]stepReference{}
+	blocksByName := map[string]*hcl.Block{}
+	names := []string{}
+	for _, block := range blocks {
+		if len(block.Labels) < 1 {
+			continue
+		}
+
+		name := block.Labels[0]
+		if _, ok := blocksByName[name]; ok {
+			// Redeclared steps are reported when we decode the steps.
+			continue
+		}
+		blocksByName[name] = block
+		names = append(names, name)
+		refs[name] = findStepReferences(block, defined)
+	}
+
+	// Walk the references depth first in the order that the steps have been defined. Any
+	// reference to a step that is still on our path is a cycle.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	path := []string{}
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, ref := range refs[name] {
+			switch state[ref.to] {
+			case unvisited:
+				visit(ref.to)
+			case visiting:
+				cycle := append(slices.Clone(path[slices.Index(path, ref.to):]), ref.to)
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "step reference cycle",
+					Detail: fmt.Sprintf("steps must not refer to each other in a cycle, found: %s",
+						strings.Join(cycle, " -> "),
+					),
+					Subject: ref.rng.Ptr(),
+					Context: blocksByName[name].DefRange.Ptr(),
+				})
+			default:
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+
+	return diags
+}
@@ -25,6 +25,13 @@ var StepVariableType cty.Type
 type StepVariable struct {
 	Value     cty.Value
 	Traversal hcl.Traversal
+
+	// Expression is the HCL of an expression that is composed of references to the outputs of
+	// steps and fixtures, e.g. the value of a scenario output that joins the outputs of two steps,
+	// or a step variable that applies a function to the output of another step.
+	// References are the references to steps and fixtures in the expression.
+	Expression string
+	References []hcl.Traversal
 }
 
 // StepVariableVal returns a new cty.Value of type StepVariableType.
@@ -186,6 +193,24 @@ func init() {
 							Value: cty.NilVal,
 						}
 
+						// References to the outputs of fixtures are unknown until the
+						// fixture has been launched.
+						if traversal, moreDiags := hcl.AbsTraversalForExpr(expr); !moreDiags.HasErrors() &&
+							traversal.RootName() == blockTypeFixture {
+							stepVar.Traversal = traversal
+
+							return StepVariableVal(stepVar), diags.Extend(verifyFixtureTraversal(traversal, expr, ctx))
+						}
+
+						// References to the outputs of other scenarios are unknown until the
+						// scenario has been launched.
+						if traversal, moreDiags := hcl.AbsTraversalForExpr(expr); !moreDiags.HasErrors() &&
+							traversal.RootName() == blockTypeScenario {
+							stepVar.Traversal = traversal
+
+							return StepVariableVal(stepVar), diags.Extend(verifyScenarioTraversal(traversal, expr, ctx))
+						}
+
 						// Try and get an absolute value. This should work if
 						// the value is knowable: an known value of primitive
 						// types or a previously decoded step variable, in the
@@ -204,6 +229,14 @@ func init() {
 							return StepVariableVal(stepVar), diags
 						}
 
+						// Expressions like functions and conditionals over the
+						// outputs of steps are unknown until Terraform applies
+						// the scenario. Everything in them that is known is
+						// evaluated and the references are deferred to Terraform.
+						if isComposedOutputExpression(expr, ctx) {
+							return composeOutputExpression(expr, ctx, "step variable")
+						}
+
 						// We have an unknown value. Let's find out if it's a
 						// valid traversal to another "step".
 						traversal, moreDiags := absTraversalForExpr(expr, ctx)
@@ -217,13 +250,12 @@ func init() {
 						if traversal.RootName() != "step" {
 							// It's an unknowable value that isn't a reference to
 							// a step output.
-							return StepVariableVal(stepVar), diags.Append(&hcl.Diagnostic{
-								Severity: hcl.DiagError,
-								Subject:  traversal.SourceRange().Ptr(),
-								Context:  expr.Range().Ptr(),
-								Summary:  "step variable is unknowable",
-								Detail:   "step variables can only be unknown if the value is a reference to a step module output",
-							})
+							return StepVariableVal(stepVar), diags.Append(unknownValueDiagnostic(
+								"step variable is unknowable",
+								"step variables can only be unknown if they refer to outputs of steps, fixtures, or "+
+									"scenarios, which are deferred to Terraform",
+								expr, ctx, traversal.SourceRange().Ptr(), expr.Range().Ptr(),
+							))
 						}
 
 						// Make sure we're referencing a known step.
@@ -279,7 +311,8 @@ func init() {
 			stepVarB, _ := b.(*StepVariable)
 
 			return (stepVarA.Value == stepVarB.Value) &&
-				reflect.DeepEqual(stepVarA.Traversal, stepVarB.Traversal)
+				reflect.DeepEqual(stepVarA.Traversal, stepVarB.Traversal) &&
+				stepVarA.Expression == stepVarB.Expression
 		},
 	})
 }
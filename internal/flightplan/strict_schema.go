@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// WithFlightPlanStrictSchema sets whether or not the flight plan is decoded with a strict schema.
+func WithFlightPlanStrictSchema(strict bool) Opt {
+	return func(fp *FlightPlan) error {
+		fp.StrictSchema = strict
+
+		return nil
+	}
+}
+
+// WithDecoderStrictSchema sets whether or not the decoder rejects configuration that isn't part of
+// a blocks schema but would otherwise be ignored.
+func WithDecoderStrictSchema(strict bool) DecoderOpt {
+	return func(d *Decoder) error {
+		d.strictSchema = strict
+
+		return nil
+	}
+}
+
+// schemaSeverity returns the severity of diagnostics for configuration that isn't part of a blocks
+// schema but that we'd otherwise silently ignore. Flight plans that are decoded with a strict
+// schema will fail, otherwise we only warn.
+func (fp *FlightPlan) schemaSeverity() hcl.DiagnosticSeverity {
+	if fp != nil && fp.StrictSchema {
+		return hcl.DiagError
+	}
+
+	return hcl.DiagWarning
+}
+
+// verifyTerraformSettingSchema verifies that the backend blocks of a terraform block don't contain
+// any nested blocks other than "workspaces". Backend configuration is otherwise freeform so any
+// other nested block would be dropped when we generate the Terraform module.
+func verifyTerraformSettingSchema(block *hcl.Block, severity hcl.DiagnosticSeverity) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	body, ok := block.Body.(*hclsyntax.Body)
+	if !ok {
+		return diags
+	}
+
+	for _, backend := range body.Blocks {
+		if backend.Type != blockTypeBackend {
+			continue
+		}
+
+		for _, nested := range backend.Body.Blocks {
+			if nested.Type == "workspaces" {
+				continue
+			}
+
+			diag := &hcl.Diagnostic{
+				Severity: severity,
+				Summary:  "unexpected block",
+				Detail: fmt.Sprintf(
+					"blocks of type %q are not expected in backend blocks, only workspaces blocks are supported",
+					nested.Type,
+				),
+				Subject: nested.TypeRange.Ptr(),
+				Context: hcl.RangeBetween(nested.TypeRange, nested.CloseBraceRange).Ptr(),
+			}
+			// "workspace" is a common misspelling of "workspaces"
+			if nested.Type == "workspace" {
+				diag = withRenameFix(diag, nested.TypeRange, nested.Type, "workspaces")
+			}
+			diags = diags.Append(diag)
+		}
+	}
+
+	return diags
+}
+
+// verifyProviderSchema verifies that a provider block doesn't set the "alias" attribute. The alias
+// of a provider is always the second label of the provider block and any alias attribute would be
+// overwritten or ignored when we generate the Terraform module.
+func verifyProviderSchema(block *hcl.Block, severity hcl.DiagnosticSeverity) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	body, ok := block.Body.(*hclsyntax.Body)
+	if !ok {
+		return diags
+	}
+
+	if attr, ok := body.Attributes["alias"]; ok {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: severity,
+			Summary:  "unexpected attribute",
+			Detail: fmt.Sprintf(
+				"the alias attribute is not supported in provider blocks, the alias is set by the provider block label %q",
+				block.Labels[1],
+			),
+			Subject: attr.NameRange.Ptr(),
+			Context: attr.SrcRange.Ptr(),
+		})
+	}
+
+	return diags
+}
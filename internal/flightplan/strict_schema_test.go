@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// Test_Decode_StrictSchema tests that configuration that isn't part of a blocks schema is warned
+// about by default and rejected when decoding with a strict schema.
+func Test_Decode_StrictSchema(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		hcl     string
+		summary string
+		line    int
+	}{
+		"backend nested block": {
+			hcl: `
+terraform "default" {
+  backend "consul" {
+    path = "enos"
+
+    workspace {
+      name = "enos"
+    }
+  }
+}
+`,
+			summary: "unexpected block",
+			line:    6,
+		},
+		"provider alias": {
+			hcl: `
+provider "aws" "east" {
+  region = "us-east-1"
+  alias  = "west"
+}
+`,
+			summary: "unexpected attribute",
+			line:    4,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			for _, strict := range []bool{false, true} {
+				_, diags := testDecodeHCLScenarios(t, []byte(test.hcl), WithDecoderStrictSchema(strict))
+				require.Len(t, diags, 1)
+				require.Equal(t, test.summary, diags[0].Summary)
+				require.Equal(t, test.line, diags[0].Subject.Start.Line)
+				if strict {
+					require.Equal(t, hcl.DiagError, diags[0].Severity)
+				} else {
+					require.Equal(t, hcl.DiagWarning, diags[0].Severity)
+				}
+			}
+		})
+	}
+}
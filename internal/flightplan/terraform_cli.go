@@ -5,12 +5,14 @@ package flightplan
 
 import (
 	"os/exec"
+	"slices"
 
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
 
 	hcl "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
 // terraformCLISpec represets a Terraform CLI config. Our intention is for
@@ -137,6 +139,10 @@ type TerraformCLI struct {
 	Path      string            `cty:"path"   hcl:"path,optional"`
 	Env       map[string]string `cty:"env"    hcl:"env,optional"`
 	ConfigVal cty.Value         `cty:"config" hcl:"config,optional"`
+
+	// SensitiveEnv are the sorted names of the env variables whose values reference sensitive
+	// variables.
+	SensitiveEnv []string `cty:"sensitive_env"`
 }
 
 // NewTerraformCLI returns a new TerraformCLI.
@@ -159,10 +165,10 @@ func DefaultTerraformCLI() *TerraformCLI {
 	return cli
 }
 
-// decode takes in an HCL block of a terraform_cli and an eval context and
-// decodes from the block onto itself. Any errors that are encountered are
-// returned as hcl diagnostics.
-func (m *TerraformCLI) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagnostics {
+// decode takes in an HCL block of a terraform_cli, an eval context, and the
+// names of the sensitive variables and decodes from the block onto itself. Any
+// errors that are encountered are returned as hcl diagnostics.
+func (m *TerraformCLI) decode(block *hcl.Block, ctx *hcl.EvalContext, sensitiveVars map[string]bool) hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
 
 	content, remain, moreDiags := block.Body.PartialContent(terraformCLISchema)
@@ -194,13 +200,13 @@ func (m *TerraformCLI) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagno
 					Context:  hcl.RangeBetween(path.Expr.StartRange(), path.Expr.Range()).Ptr(),
 				})
 			} else {
-				diags = diags.Append(&hcl.Diagnostic{
+				diags = diags.Append(withQuoteLiteralFix(&hcl.Diagnostic{
 					Severity: hcl.DiagWarning,
 					Summary:  "invalid value",
 					Detail:   "terraform_cli should be a string value, consider changing it",
 					Subject:  path.Expr.Range().Ptr(),
 					Context:  hcl.RangeBetween(path.Expr.StartRange(), path.Expr.Range()).Ptr(),
-				})
+				}, path.Expr, pathVal))
 				m.Path = pathVal.AsString()
 			}
 		}
@@ -241,6 +247,7 @@ func (m *TerraformCLI) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Diagno
 				}
 			}
 		}
+		m.SensitiveEnv = sensitiveEnvNames(env.Expr, ctx, sensitiveVars, m.Env)
 	}
 
 	// The remaining portion of our HCL body is our Terraform CLI config. We'll
@@ -275,5 +282,75 @@ func (m *TerraformCLI) evalCtx() cty.Value {
 		vals["env"] = cty.NullVal(cty.Map(cty.String))
 	}
 
+	sensitiveEnvVals := []cty.Value{}
+	for _, name := range m.SensitiveEnv {
+		sensitiveEnvVals = append(sensitiveEnvVals, cty.StringVal(name))
+	}
+	if len(sensitiveEnvVals) > 0 {
+		vals["sensitive_env"] = cty.ListVal(sensitiveEnvVals)
+	} else {
+		vals["sensitive_env"] = cty.NullVal(cty.List(cty.String))
+	}
+
 	return cty.ObjectVal(vals)
 }
+
+// sensitiveEnvNames takes the expression of a terraform_cli env, an eval context, the names of the
+// sensitive variables, and the decoded env and returns the sorted names of the env variables whose
+// values reference sensitive variables. If the env isn't a map literal we can't tell which values
+// reference them so every value is sensitive if the expression does.
+func sensitiveEnvNames(
+	expr hcl.Expression,
+	ctx *hcl.EvalContext,
+	sensitiveVars map[string]bool,
+	env map[string]string,
+) []string {
+	if len(sensitiveVars) == 0 {
+		return nil
+	}
+
+	names := []string{}
+	obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		if referencesSensitiveVariable(expr, sensitiveVars) {
+			for name := range env {
+				names = append(names, name)
+			}
+		}
+	} else {
+		for _, item := range obj.Items {
+			if !referencesSensitiveVariable(item.ValueExpr, sensitiveVars) {
+				continue
+			}
+
+			key, diags := item.KeyExpr.Value(ctx)
+			if diags.HasErrors() || !key.IsKnown() || key.IsNull() || key.Type() != cty.String {
+				continue
+			}
+			names = append(names, key.AsString())
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// referencesSensitiveVariable returns whether the expression references any of the sensitive
+// variables.
+func referencesSensitiveVariable(expr hcl.Expression, sensitiveVars map[string]bool) bool {
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "var" || len(traversal) < 2 {
+			continue
+		}
+
+		if attr, ok := traversal[1].(hcl.TraverseAttr); ok && sensitiveVars[attr.Name] {
+			return true
+		}
+	}
+
+	return false
+}
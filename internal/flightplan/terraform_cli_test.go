@@ -464,3 +464,79 @@ scenario "ref" {
 		})
 	}
 }
+
+// Test_Decode_TerraformCLI_SensitiveEnv tests that env values which reference sensitive variables
+// are sensitive in the terraform_cli of the scenarios.
+func Test_Decode_TerraformCLI_SensitiveEnv(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+variable "token" {
+  sensitive = true
+  default   = "supersecret"
+}
+
+variable "cache_dir" {
+  default = "/tmp/cache"
+}
+
+variable "secret_env" {
+  sensitive = true
+  default = {
+    AWS_ACCESS_KEY_ID     = "key"
+    AWS_SECRET_ACCESS_KEY = "secret"
+  }
+}
+
+terraform_cli "literal" {
+  env = {
+    TF_PLUGIN_CACHE_DIR = var.cache_dir
+    TF_TOKEN_app_terraform_io = var.token
+    "VAULT_TOKEN" = "prefix-${var.token}"
+  }
+}
+
+terraform_cli "indirect" {
+  env = var.secret_env
+}
+
+module "backend" {
+  source = "%s"
+}
+
+scenario "literal" {
+  terraform_cli = terraform_cli.literal
+
+  step "first" {
+    module = module.backend
+  }
+}
+
+scenario "indirect" {
+  terraform_cli = "indirect"
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.NoError(t, err)
+
+	expected := map[string][]string{
+		"literal":  {"TF_TOKEN_app_terraform_io", "VAULT_TOKEN"},
+		"indirect": {"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"},
+		"default":  nil,
+	}
+	for _, cli := range fp.TerraformCLIs {
+		require.Equal(t, expected[cli.Name], cli.SensitiveEnv, cli.Name)
+	}
+
+	require.Len(t, fp.Scenarios(), 2)
+	for _, scenario := range fp.Scenarios() {
+		require.NotNil(t, scenario.TerraformCLI)
+		require.Equal(t, expected[scenario.Name], scenario.TerraformCLI.SensitiveEnv, scenario.Name)
+	}
+}
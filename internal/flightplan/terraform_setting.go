@@ -13,6 +13,68 @@ import (
 	"github.com/hashicorp/hcl/v2/hcldec"
 )
 
+// terraformSettingSchema are the blocks of terraform settings that aren't strictly defined.
+var terraformSettingSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: blockTypeRequiredProviders},
+		{Type: blockTypeProviderMeta, LabelNames: []string{attrLabelNameDefault}},
+		{Type: blockTypeBackend, LabelNames: []string{attrLabelNameDefault}},
+	},
+}
+
+// terraformSettingRequiredVersionSpec is the spec of the required_version attribute.
+var terraformSettingRequiredVersionSpec = &hcldec.AttrSpec{
+	Name:     "required_version",
+	Type:     cty.String,
+	Required: false,
+}
+
+// terraformSettingExperimentsSpec is the spec of the experiments attribute.
+var terraformSettingExperimentsSpec = &hcldec.AttrSpec{
+	Name:     "experiments",
+	Type:     cty.List(cty.String),
+	Required: false,
+}
+
+// terraformSettingCloudSpec is the spec of the cloud block.
+var terraformSettingCloudSpec = hcldec.ObjectSpec{
+	"cloud": &hcldec.BlockListSpec{
+		TypeName: "cloud",
+		Nested: hcldec.ObjectSpec{
+			"organization": &hcldec.AttrSpec{
+				Name:     "organization",
+				Type:     cty.String,
+				Required: true,
+			},
+			"hostname": &hcldec.AttrSpec{
+				Name:     "hostname",
+				Type:     cty.String,
+				Required: false,
+			},
+			"token": &hcldec.AttrSpec{
+				Name:     "token",
+				Type:     cty.String,
+				Required: false,
+			},
+			"workspaces": &hcldec.BlockListSpec{
+				TypeName: "workspaces",
+				Nested: hcldec.ObjectSpec{
+					"name": &hcldec.AttrSpec{
+						Name:     "name",
+						Type:     cty.String,
+						Required: false,
+					},
+					"tags": &hcldec.AttrSpec{
+						Name:     "tags",
+						Type:     cty.List(cty.String),
+						Required: false,
+					},
+				},
+			},
+		},
+	},
+}
+
 // TerraformSetting is a terraform settings configuration.
 type TerraformSetting struct {
 	Name            string
@@ -78,13 +140,7 @@ func (t *TerraformSetting) decode(block *hcl.Block, ctx *hcl.EvalContext) hcl.Di
 	}
 
 	// Handle the rest of our schema manually since it isn't strictly defined
-	content, moreDiags := remain.Content(&hcl.BodySchema{
-		Blocks: []hcl.BlockHeaderSchema{
-			{Type: blockTypeRequiredProviders},
-			{Type: blockTypeProviderMeta, LabelNames: []string{attrLabelNameDefault}},
-			{Type: blockTypeBackend, LabelNames: []string{attrLabelNameDefault}},
-		},
-	})
+	content, moreDiags := remain.Content(terraformSettingSchema)
 	diags = diags.Extend(moreDiags)
 	if moreDiags != nil && moreDiags.HasErrors() {
 		return diags
@@ -146,11 +202,7 @@ func (t *TerraformSetting) decodeRequiredVersion(ctx *hcl.EvalContext, body hcl.
 	var remain hcl.Body
 
 	t.RequiredVersion, remain, diags = hcldec.PartialDecode(
-		body, &hcldec.AttrSpec{
-			Name:     "required_version",
-			Type:     cty.String,
-			Required: false,
-		}, ctx,
+		body, terraformSettingRequiredVersionSpec, ctx,
 	)
 
 	return remain, diags
@@ -164,11 +216,7 @@ func (t *TerraformSetting) decodeExperiments(ctx *hcl.EvalContext, body hcl.Body
 	var remain hcl.Body
 
 	t.Experiments, remain, diags = hcldec.PartialDecode(
-		body, &hcldec.AttrSpec{
-			Name:     "experiments",
-			Type:     cty.List(cty.String),
-			Required: false,
-		}, ctx,
+		body, terraformSettingExperimentsSpec, ctx,
 	)
 
 	return remain, diags
@@ -180,43 +228,7 @@ func (t *TerraformSetting) decodeCloud(ctx *hcl.EvalContext, body hcl.Body) (hcl
 	var remain hcl.Body
 
 	t.Cloud, remain, diags = hcldec.PartialDecode(
-		body, hcldec.ObjectSpec{
-			"cloud": &hcldec.BlockListSpec{
-				TypeName: "cloud",
-				Nested: hcldec.ObjectSpec{
-					"organization": &hcldec.AttrSpec{
-						Name:     "organization",
-						Type:     cty.String,
-						Required: true,
-					},
-					"hostname": &hcldec.AttrSpec{
-						Name:     "hostname",
-						Type:     cty.String,
-						Required: false,
-					},
-					"token": &hcldec.AttrSpec{
-						Name:     "token",
-						Type:     cty.String,
-						Required: false,
-					},
-					"workspaces": &hcldec.BlockListSpec{
-						TypeName: "workspaces",
-						Nested: hcldec.ObjectSpec{
-							"name": &hcldec.AttrSpec{
-								Name:     "name",
-								Type:     cty.String,
-								Required: false,
-							},
-							"tags": &hcldec.AttrSpec{
-								Name:     "tags",
-								Type:     cty.List(cty.String),
-								Required: false,
-							},
-						},
-					},
-				},
-			},
-		}, ctx,
+		body, terraformSettingCloudSpec, ctx,
 	)
 
 	return remain, diags
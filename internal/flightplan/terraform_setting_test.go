@@ -998,3 +998,108 @@ func Test_TerraformSettings_Cty_RoundTrip(t *testing.T) {
 	require.NoError(t, clone.FromCtyValue(setting.ToCtyValue()))
 	require.EqualValues(t, setting, clone)
 }
+
+// Test_Decode_Scenario_Overrides tests decoding the overrides block of scenarios.
+func Test_Decode_Scenario_Overrides(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	for desc, test := range map[string]struct {
+		overrides string
+		fail      bool
+	}{
+		"valid": {
+			overrides: `
+  overrides {
+    required_providers {
+      aws = {
+        source  = "hashicorp/aws"
+        version = "5.31.0"
+      }
+    }
+
+    backend "s3" {
+      bucket = "enos-state"
+      key    = "${matrix.backend}/terraform.tfstate"
+    }
+  }`,
+		},
+		"more than one": {
+			overrides: `
+  overrides {
+  }
+
+  overrides {
+  }`,
+			fail: true,
+		},
+		"more than one backend": {
+			overrides: `
+  overrides {
+    backend "s3" {
+    }
+
+    backend "consul" {
+    }
+  }`,
+			fail: true,
+		},
+		"invalid block": {
+			overrides: `
+  overrides {
+    provider "aws" {
+    }
+  }`,
+			fail: true,
+		},
+		"step references": {
+			overrides: `
+  overrides {
+    backend "s3" {
+      bucket = step.first.bucket
+    }
+  }`,
+			fail: true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "overrides" {
+  matrix {
+    backend = ["raft"]
+  }
+%s
+
+  step "first" {
+    module = module.backend
+  }
+}
+`, modulePath, test.overrides)), DecodeTargetAll)
+			if test.fail {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, fp.ScenarioBlocks, 1)
+			require.Len(t, fp.ScenarioBlocks[0].Scenarios, 1)
+
+			overrides := fp.ScenarioBlocks[0].Scenarios[0].Overrides
+			require.NotNil(t, overrides)
+			require.Equal(t, cty.ObjectVal(map[string]cty.Value{
+				"source":  cty.StringVal("hashicorp/aws"),
+				"version": cty.StringVal("5.31.0"),
+			}), overrides.RequiredProviders["aws"])
+			require.Equal(t, "s3", overrides.Backend.Name)
+			require.Equal(t, cty.StringVal("raft/terraform.tfstate"), overrides.Backend.Attrs["key"])
+		})
+	}
+}
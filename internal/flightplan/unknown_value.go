@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+)
+
+// unknownValueDiagnostic returns a diagnostic for an expression whose value must be known when it
+// is decoded but is not. The detail says which traversals of the expression are unknown and why,
+// followed by what is allowed in place of the expression. The expression and eval context are set
+// so that the values of the traversals are included in the snippet of the diagnostic.
+func unknownValueDiagnostic(
+	summary string,
+	allowed string,
+	expr hcl.Expression,
+	ctx *hcl.EvalContext,
+	subject *hcl.Range,
+	context *hcl.Range,
+) *hcl.Diagnostic {
+	details := []string{}
+	for _, unknown := range diagnostics.UnknownTraversals(expr, ctx) {
+		details = append(details, unknownTraversalDetail(unknown))
+	}
+	if len(details) == 0 {
+		details = append(details, "the value of the expression is unknown")
+	}
+	if allowed != "" {
+		details = append(details, allowed)
+	}
+
+	return &hcl.Diagnostic{
+		Severity:    hcl.DiagError,
+		Summary:     summary,
+		Detail:      strings.Join(details, ", "),
+		Subject:     subject,
+		Context:     context,
+		Expression:  expr,
+		EvalContext: ctx,
+	}
+}
+
+// unknownTraversalDetail returns why the value of a traversal is unknown when it is decoded.
+func unknownTraversalDetail(unknown *diagnostics.UnknownTraversal) string {
+	traversal := diagnostics.TraversalString(unknown.Traversal)
+	if len(unknown.Unresolved) > 0 {
+		reason := unknown.Unresolved[0].Summary
+		if detail := unknown.Unresolved[0].Detail; detail != "" {
+			reason = detail
+		}
+		reason = strings.TrimSuffix(reason, ".")
+		if reason != "" {
+			reason = strings.ToLower(reason[:1]) + reason[1:]
+		}
+
+		return fmt.Sprintf("%s can't be resolved: %s", traversal, reason)
+	}
+
+	return fmt.Sprintf("the value of %s is unknown because %s", traversal, unknownTraversalReason(unknown.Traversal))
+}
+
+// unknownTraversalReason returns why the value of a traversal is unknown when it is decoded.
+func unknownTraversalReason(traversal hcl.Traversal) string {
+	switch traversal.RootName() {
+	case "var":
+		return "the variable has no valid value of its type"
+	case "step":
+		return "the outputs of steps are only known after Terraform has applied them"
+	case blockTypeFixture:
+		return "the outputs of fixtures are only known after the fixture has been launched"
+	case blockTypeScenario:
+		return "the outputs of other scenarios are only known after they have been launched"
+	default:
+		return "it refers to values that are only known after the scenario has been launched"
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Test_UnknownValueDiagnostic tests that diagnostics of unknown values explain which traversals
+// are unknown and why.
+func Test_UnknownValueDiagnostic(t *testing.T) {
+	t.Parallel()
+
+	expr, diags := hclsyntax.ParseExpression(
+		[]byte(`"${var.region}-${var.distro}-${scenario.shared_vpc.outputs.vpc_id}"`), "in.hcl", hcl.InitialPos,
+	)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	ctx := &hcl.EvalContext{Variables: map[string]cty.Value{
+		"var": cty.ObjectVal(map[string]cty.Value{
+			"region": cty.UnknownVal(cty.String),
+			"distro": cty.StringVal("ubuntu"),
+		}),
+		"scenario": cty.ObjectVal(map[string]cty.Value{
+			"shared_vpc": cty.ObjectVal(map[string]cty.Value{
+				"outputs": cty.ObjectVal(map[string]cty.Value{
+					"vpc_id": cty.DynamicVal,
+				}),
+			}),
+		}),
+	}}
+
+	diag := unknownValueDiagnostic("value is unknown", "values must be known", expr, ctx, expr.Range().Ptr(), nil)
+	require.Equal(t, "value is unknown", diag.Summary)
+	require.Equal(t, "the value of scenario.shared_vpc.outputs.vpc_id is unknown because the outputs of "+
+		"other scenarios are only known after they have been launched, the value of var.region is unknown "+
+		"because the variable has no valid value of its type, values must be known", diag.Detail)
+	require.Equal(t, expr, diag.Expression)
+	require.Equal(t, ctx, diag.EvalContext)
+
+	expr, diags = hclsyntax.ParseExpression([]byte(`upper("known")`), "in.hcl", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	diag = unknownValueDiagnostic("value is unknown", "", expr, ctx, expr.Range().Ptr(), nil)
+	require.Equal(t, "the value of the expression is unknown", diag.Detail)
+
+	expr, diags = hclsyntax.ParseExpression([]byte(`var.nope`), "in.hcl", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	diag = unknownValueDiagnostic("value is unknown", "", expr, ctx, expr.Range().Ptr(), nil)
+	require.Equal(t, `var.nope can't be resolved: this object does not have an attribute named "nope"`, diag.Detail)
+}
+
+// Test_Decode_Unknown_Value tests that decoding values that must be known but refer to unknown
+// values fails with diagnostics that explain why they are unknown.
+func Test_Decode_Unknown_Value(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	_, err = testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "shared_vpc" {
+  step "vpc" {
+    module = module.backend
+  }
+
+  output "enabled" {
+    value = step.vpc.enabled
+  }
+}
+
+scenario "upgrade" {
+  step "backend" {
+    module    = module.backend
+    skip_step = scenario.shared_vpc.outputs.enabled
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.ErrorContains(t, err, "skip_step must be a known value")
+	require.ErrorContains(t, err, "the value of scenario.shared_vpc.outputs.enabled is unknown because "+
+		"the outputs of other scenarios are only known after they have been launched")
+}
+
+// Test_Decode_Unknown_Value_Undeclared_Variable tests that diagnostics of step variables that refer
+// to variables that haven't been declared name the variable.
+func Test_Decode_Unknown_Value_Undeclared_Variable(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	_, err = testDecodeHCL(t, []byte(fmt.Sprintf(`
+module "backend" {
+  source = "%s"
+}
+
+scenario "upgrade" {
+  step "backend" {
+    module = module.backend
+
+    variables {
+      distro_version = var.nope
+    }
+  }
+}
+`, modulePath)), DecodeTargetAll)
+	require.ErrorContains(t, err, "step variable is unknowable")
+	require.ErrorContains(t, err, `var.nope can't be resolved: this object does not have an attribute named "nope"`)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// UsageTracker tracks which top-level modules and providers of a flight plan are referenced by
+// decoded scenarios so that it can report any modules, providers, and variables that are never
+// used.
+type UsageTracker struct {
+	fp        *FlightPlan
+	modules   map[string]struct{}
+	providers map[string]struct{}
+}
+
+// NewUsageTracker returns a new UsageTracker for the flight plan.
+func NewUsageTracker(fp *FlightPlan) *UsageTracker {
+	return &UsageTracker{
+		fp:        fp,
+		modules:   map[string]struct{}{},
+		providers: map[string]struct{}{},
+	}
+}
+
+// Track records the modules and providers that a decoded scenario references.
+func (t *UsageTracker) Track(scenario *Scenario) {
+	if t == nil || scenario == nil {
+		return
+	}
+
+	for _, provider := range scenario.Providers {
+		t.providers[providerKey(provider.Type, provider.Alias)] = struct{}{}
+	}
+
+	for _, step := range scenario.Steps {
+		if step.Module != nil {
+			t.modules[step.Module.Name] = struct{}{}
+		}
+
+		for _, provider := range step.Providers {
+			t.providers[providerKey(provider.Type, provider.Alias)] = struct{}{}
+		}
+	}
+}
+
+// Unused returns a diagnostic with the given severity for every top-level module and provider
+// that none of the tracked scenarios reference, and for every variable that isn't referenced
+// anywhere in the flight plan. Unused must only be called after every scenario in the flight plan
+// has been tracked, otherwise it will report modules and providers of untracked scenarios.
+func (t *UsageTracker) Unused(severity hcl.DiagnosticSeverity) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if t == nil || t.fp == nil || t.fp.BodyContent == nil {
+		return diags
+	}
+
+	// Find every variable that is referenced outside of variable blocks.
+	vars := map[string]struct{}{}
+	for _, block := range t.fp.BodyContent.Blocks {
+		if block.Type == blockTypeVariable {
+			continue
+		}
+
+		body, ok := block.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+			expr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+			if !ok || expr.Traversal.RootName() != "var" || len(expr.Traversal) < 2 {
+				return nil
+			}
+
+			if attr, ok := expr.Traversal[1].(hcl.TraverseAttr); ok {
+				vars[attr.Name] = struct{}{}
+			}
+
+			return nil
+		})
+	}
+
+	for _, block := range t.fp.BodyContent.Blocks {
+		var used bool
+		switch block.Type {
+		case blockTypeModule:
+			_, used = t.modules[block.Labels[0]]
+		case blockTypeProvider:
+			_, used = t.providers[providerKey(block.Labels[0], block.Labels[1])]
+		case blockTypeVariable:
+			_, used = vars[block.Labels[0]]
+		default:
+			continue
+		}
+
+		if used {
+			continue
+		}
+
+		detail := fmt.Sprintf("%s %s is not referenced by any scenario and can be removed",
+			block.Type, unusedBlockName(block),
+		)
+		if block.Type == blockTypeVariable {
+			detail = fmt.Sprintf("variable %s is not referenced anywhere in the flight plan and can be removed",
+				block.Labels[0],
+			)
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: severity,
+			Summary:  block.Type + " is never used",
+			Detail:   detail,
+			Subject:  block.LabelRanges[0].Ptr(),
+			Context:  block.DefRange.Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// providerKey returns a key for a provider type and alias.
+func providerKey(typ, alias string) string {
+	return typ + "." + alias
+}
+
+// unusedBlockName returns the name of a module, provider, or variable block.
+func unusedBlockName(block *hcl.Block) string {
+	if block.Type == blockTypeProvider {
+		return providerKey(block.Labels[0], block.Labels[1])
+	}
+
+	return block.Labels[0]
+}
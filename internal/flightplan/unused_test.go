@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// Test_UsageTracker_Unused tests finding unused modules, providers, and variables.
+func Test_UsageTracker_Unused(t *testing.T) {
+	t.Parallel()
+
+	fp, err := testDecodeHCL(t, []byte(`
+variable "region" {
+  default = "us-east-1"
+}
+
+variable "unused" {
+  default = "unused"
+}
+
+provider "aws" "default" {
+  region = var.region
+}
+
+provider "aws" "east" {
+  region = "us-east-2"
+}
+
+module "backend" {
+  source = "./backend"
+}
+
+module "unused" {
+  source = "./unused"
+}
+
+scenario "test" {
+  providers = [provider.aws.default]
+
+  matrix {
+    backend = ["backend"]
+  }
+
+  step "backend" {
+    module = matrix.backend
+  }
+}
+`), DecodeTargetAll)
+	require.NoError(t, err)
+
+	usage := NewUsageTracker(fp)
+	for _, scenario := range fp.Scenarios() {
+		usage.Track(scenario)
+	}
+
+	for _, severity := range []hcl.DiagnosticSeverity{hcl.DiagWarning, hcl.DiagError} {
+		diags := usage.Unused(severity)
+		require.Len(t, diags, 3)
+		details := []string{}
+		for _, diag := range diags {
+			require.Equal(t, severity, diag.Severity)
+			details = append(details, diag.Detail)
+		}
+		require.ElementsMatch(t, []string{
+			"variable unused is not referenced anywhere in the flight plan and can be removed",
+			"provider aws.east is not referenced by any scenario and can be removed",
+			"module unused is not referenced by any scenario and can be removed",
+		}, details)
+	}
+}
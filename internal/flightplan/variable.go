@@ -21,6 +21,9 @@ var variableSchema = &hcl.BodySchema{
 		{Name: "default"},
 		{Name: "type"},
 		{Name: "sensitive"},
+		{Name: "outputs_file"},
+		{Name: "outputs_scenario"},
+		{Name: "output"},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{Type: blockTypeValidation},
@@ -36,6 +39,15 @@ type Variable struct {
 	SetValue       cty.Value
 	Type           cty.Type
 	ConstraintType cty.Type
+	// OutputsFile is the path of a file of the captured outputs of a previous run whose output
+	// is the value of the variable unless a value has been set.
+	OutputsFile string
+	// OutputsScenario is the filter of the scenario in the outputs file whose output is used.
+	OutputsScenario string
+	// Output is the name of the output, which defaults to the name of the variable.
+	Output string
+	// OutputValue is the value of the output in the outputs file.
+	OutputValue cty.Value
 }
 
 // VariableValue is a user supplied variable value.
@@ -61,8 +73,9 @@ func NewVariable() *Variable {
 	return &Variable{}
 }
 
-// decode takes in an HCL block of a variable and any set variable values and decodes itself.
-func (v *Variable) decode(block *hcl.Block, values map[string]*VariableValue) hcl.Diagnostics {
+// decode takes in an HCL block of a variable and any set variable values and decodes itself. The
+// base directory is the directory that the path of an outputs file is relative to.
+func (v *Variable) decode(block *hcl.Block, values map[string]*VariableValue, baseDir string) hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
 
 	content, moreDiags := block.Body.Content(variableSchema)
@@ -88,8 +101,6 @@ func (v *Variable) decode(block *hcl.Block, values map[string]*VariableValue) hc
 	}
 
 	if attr, ok := content.Attributes["sensitive"]; ok {
-		// NOTE: We don't actually do anything with sensitive variables yet
-		// but we're reserving them here.
 		diags = diags.Extend(gohcl.DecodeExpression(attr.Expr, nil, &v.Sensitive))
 	}
 
@@ -114,6 +125,22 @@ func (v *Variable) decode(block *hcl.Block, values map[string]*VariableValue) hc
 		v.Default = val
 	}
 
+	if attr, ok := content.Attributes["outputs_file"]; ok {
+		_, set := values[v.Name]
+		diags = diags.Extend(v.decodeOutputsFile(attr, content, baseDir, !set))
+	} else {
+		for _, name := range []string{"outputs_scenario", "output"} {
+			if attr, ok := content.Attributes[name]; ok {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Missing outputs_file for variable",
+					Detail:   fmt.Sprintf("The %s attribute can only be set with the outputs_file attribute.", name),
+					Subject:  attr.Range.Ptr(),
+				})
+			}
+		}
+	}
+
 	if setVal, ok := values[v.Name]; ok {
 		switch setVal.Source {
 		case VariableValueSourceEnvVar:
@@ -188,13 +215,68 @@ func (v *Variable) decode(block *hcl.Block, values map[string]*VariableValue) hc
 	return diags
 }
 
-// Value returns either the user-supplied value or the default. If no values have
-// been set it will always return a NilVal.
+// decodeOutputsFile decodes the outputs file attributes of the variable and reads the value of
+// the output from the outputs file. The outputs file is not read if a value has been set, so that
+// the value can be set when the outputs file isn't available.
+func (v *Variable) decodeOutputsFile(
+	attr *hcl.Attribute,
+	content *hcl.BodyContent,
+	baseDir string,
+	read bool,
+) hcl.Diagnostics {
+	diags := gohcl.DecodeExpression(attr.Expr, nil, &v.OutputsFile)
+
+	if attr, ok := content.Attributes["outputs_scenario"]; ok {
+		diags = diags.Extend(gohcl.DecodeExpression(attr.Expr, nil, &v.OutputsScenario))
+	}
+
+	v.Output = v.Name
+	if attr, ok := content.Attributes["output"]; ok {
+		diags = diags.Extend(gohcl.DecodeExpression(attr.Expr, nil, &v.Output))
+	}
+
+	if diags.HasErrors() || !read {
+		return diags
+	}
+
+	val, err := readOutputsFileValue(baseDir, v.OutputsFile, v.OutputsScenario, v.Output)
+	if err != nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid outputs file for variable",
+			Detail:   err.Error(),
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+	}
+
+	if v.ConstraintType != cty.NilType {
+		val, err = convert.Convert(val, v.ConstraintType)
+		if err != nil {
+			return diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid output value for variable",
+				Detail:   fmt.Sprintf("The value of output %s is not compatible with the variable's type constraint: %s.", v.Output, err),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	v.OutputValue = val
+
+	return diags
+}
+
+// Value returns either the user-supplied value, the value of the output of the outputs file, or
+// the default. If no values have been set it will always return a NilVal.
 func (v *Variable) Value() cty.Value {
 	if v.SetValue != cty.NilVal {
 		return v.SetValue
 	}
 
+	if v.OutputValue != cty.NilVal {
+		return v.OutputValue
+	}
+
 	if v.Default != cty.NilVal {
 		return v.Default
 	}
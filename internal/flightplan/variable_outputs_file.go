@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package flightplan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// outputsFiles returns the paths of the outputs files that the variables of the flight plan read
+// their values from. As outputs files can only be set with literal values we only have to parse
+// the flight plan to find them, which allows including them in the keys of cached decodes.
+func outputsFiles(pfp *pb.FlightPlan) []string {
+	schema := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: blockTypeVariable, LabelNames: []string{attrLabelNameDefault}}},
+	}
+	parser := hclparse.NewParser()
+	paths := []string{}
+
+	for name, src := range pfp.GetEnosHcl() {
+		var file *hcl.File
+		if strings.HasSuffix(name, ".json") {
+			file, _ = parser.ParseJSON(src, name)
+		} else {
+			file, _ = parser.ParseHCL(src, name)
+		}
+		if file == nil || file.Body == nil {
+			continue
+		}
+
+		content, _, _ := file.Body.PartialContent(schema)
+		for _, block := range content.Blocks {
+			attrs, _ := block.Body.JustAttributes()
+			attr, ok := attrs["outputs_file"]
+			if !ok {
+				continue
+			}
+
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !val.IsKnown() || val.IsNull() || !val.Type().Equals(cty.String) {
+				continue
+			}
+
+			path := val.AsString()
+			if !filepath.IsAbs(path) && pfp.GetBaseDir() != "" {
+				path = filepath.Join(pfp.GetBaseDir(), path)
+			}
+			if !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	slices.Sort(paths)
+
+	return paths
+}
+
+// readOutputsFileValue reads the value of an output from a file of the captured outputs of a
+// previous run, i.e. the JSON output of 'enos scenario output --format json'. If the file has the
+// outputs of more than one scenario that have the output the scenario filter is required to
+// choose one of them. Relative paths are relative to the base directory of the flight plan.
+func readOutputsFileValue(baseDir string, path string, filter string, name string) (cty.Value, error) {
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("reading outputs file: %w", err)
+	}
+
+	responses := &pb.OperationResponses{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(bytes, responses); err != nil {
+		return cty.NilVal, fmt.Errorf("decoding outputs file %s: %w", path, err)
+	}
+
+	var sf *ScenarioFilter
+	if filter != "" {
+		sf, err = NewScenarioFilter(WithScenarioFilterParse(strings.Fields(filter)))
+		if err != nil {
+			return cty.NilVal, err
+		}
+	}
+
+	scenarios := []string{}
+	var meta *pb.Terraform_Command_Output_Response_Meta
+	for _, res := range responses.GetResponses() {
+		scenario := NewScenario()
+		scenario.FromRef(res.GetOp().GetScenario())
+		if !sf.matchScenarioVector(scenario.Name, scenario.Variants) {
+			continue
+		}
+
+		for _, out := range res.GetOutput().GetOutput().GetMeta() {
+			if out.GetName() == name {
+				scenarios = append(scenarios, scenario.String())
+				meta = out
+			}
+		}
+	}
+
+	switch len(scenarios) {
+	case 0:
+		return cty.NilVal, fmt.Errorf("no scenario in outputs file %s has an output named %s", path, name)
+	case 1:
+	default:
+		return cty.NilVal, fmt.Errorf(
+			"more than one scenario in outputs file %s has an output named %s: %s, set outputs_scenario to choose one of them",
+			path, name, strings.Join(scenarios, ", "),
+		)
+	}
+
+	if len(meta.GetValue()) == 0 {
+		if meta.GetSensitive() {
+			return cty.NilVal, fmt.Errorf(
+				"output %s of scenario %s is sensitive and has not been captured, capture it with 'enos scenario output --show-sensitive'",
+				name, scenarios[0],
+			)
+		}
+
+		return cty.NilVal, fmt.Errorf("output %s of scenario %s has no value", name, scenarios[0])
+	}
+
+	ty := cty.DynamicPseudoType
+	if len(meta.GetType()) > 0 {
+		ty, err = ctyjson.UnmarshalType(meta.GetType())
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("decoding the type of output %s: %w", name, err)
+		}
+	}
+
+	if ty == cty.DynamicPseudoType {
+		ty, err = ctyjson.ImpliedType(meta.GetValue())
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("decoding the type of output %s: %w", name, err)
+		}
+	}
+
+	val, err := ctyjson.Unmarshal(meta.GetValue(), ty)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("decoding the value of output %s: %w", name, err)
+	}
+
+	return val, nil
+}
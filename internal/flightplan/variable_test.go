@@ -4,13 +4,20 @@
 package flightplan
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	hcl "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
 func Test_Decode_Variable(t *testing.T) {
@@ -170,7 +177,7 @@ variable "complex" {
 			require.False(t, diags.HasErrors(), diags.Error())
 			block := content.Blocks.OfType(blockTypeVariable)[0]
 			variable := NewVariable()
-			diags = variable.decode(block, test.vars)
+			diags = variable.decode(block, test.vars, "")
 			if test.fail {
 				require.True(t, diags.HasErrors(), diags.Error())
 			} else {
@@ -180,3 +187,185 @@ variable "complex" {
 		})
 	}
 }
+
+// Test_Decode_Variable_OutputsFile tests decoding variables whose values are the captured outputs
+// of a previous run.
+func Test_Decode_Variable_OutputsFile(t *testing.T) {
+	t.Parallel()
+
+	modulePath, err := filepath.Abs("./tests/simple_module")
+	require.NoError(t, err)
+
+	newResponse := func(name string, vec *Vector, metas ...*pb.Terraform_Command_Output_Response_Meta) *pb.Operation_Response {
+		scenario := NewScenario()
+		scenario.Name = name
+		scenario.Variants = vec
+
+		return &pb.Operation_Response{
+			Op: &pb.Ref_Operation{Scenario: scenario.Ref()},
+			Value: &pb.Operation_Response_Output_{
+				Output: &pb.Operation_Response_Output{
+					Output: &pb.Terraform_Command_Output_Response{Meta: metas},
+				},
+			},
+		}
+	}
+
+	outputsFile := filepath.Join(t.TempDir(), "outputs.json")
+	bytes, err := protojson.Marshal(&pb.OperationResponses{
+		Responses: []*pb.Operation_Response{
+			newResponse("build", NewVector(NewElement("arch", "amd64")),
+				&pb.Terraform_Command_Output_Response_Meta{
+					Name:  "artifact_url",
+					Type:  []byte(`"string"`),
+					Value: []byte(`"https://example.com/amd64.zip"`),
+				},
+				&pb.Terraform_Command_Output_Response_Meta{
+					Name:  "versions",
+					Type:  []byte(`["list","string"]`),
+					Value: []byte(`["1.0.0","1.1.0"]`),
+				},
+				&pb.Terraform_Command_Output_Response_Meta{
+					Name:      "token",
+					Type:      []byte(`"string"`),
+					Sensitive: true,
+				},
+			),
+			newResponse("build", NewVector(NewElement("arch", "arm64")),
+				&pb.Terraform_Command_Output_Response_Meta{
+					Name:  "artifact_url",
+					Type:  []byte(`"string"`),
+					Value: []byte(`"https://example.com/arm64.zip"`),
+				},
+			),
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(outputsFile, bytes, 0o600))
+
+	for desc, test := range map[string]struct {
+		variable string
+		env      []string
+		expected cty.Value
+		err      string
+	}{
+		"output of a scenario": {
+			variable: `
+  outputs_file     = "%s"
+  outputs_scenario = "build arch:amd64"
+  output           = "artifact_url"
+`,
+			expected: cty.StringVal("https://example.com/amd64.zip"),
+		},
+		"output with the name of the variable": {
+			variable: `
+  outputs_file     = "%s"
+  outputs_scenario = "build arch:arm64"
+`,
+			expected: cty.StringVal("https://example.com/arm64.zip"),
+		},
+		"only scenario with the output": {
+			variable: `
+  type         = list(string)
+  outputs_file = "%s"
+  output       = "versions"
+`,
+			expected: cty.ListVal([]cty.Value{cty.StringVal("1.0.0"), cty.StringVal("1.1.0")}),
+		},
+		"set value": {
+			variable: `
+  outputs_file     = "%s"
+  outputs_scenario = "build arch:arm64"
+`,
+			env:      []string{"ENOS_VAR_artifact_url=fromenv"},
+			expected: cty.StringVal("fromenv"),
+		},
+		"set value without outputs file": {
+			variable: `
+  outputs_file = "%s.missing"
+`,
+			env:      []string{"ENOS_VAR_artifact_url=fromenv"},
+			expected: cty.StringVal("fromenv"),
+		},
+		"more than one scenario": {
+			variable: `
+  outputs_file = "%s"
+`,
+			err: "more than one scenario",
+		},
+		"missing output": {
+			variable: `
+  outputs_file = "%s"
+  output       = "missing"
+`,
+			err: "has an output named missing",
+		},
+		"sensitive output": {
+			variable: `
+  outputs_file = "%s"
+  output       = "token"
+`,
+			err: "is sensitive and has not been captured",
+		},
+		"incompatible type": {
+			variable: `
+  type         = number
+  outputs_file = "%s"
+  output       = "versions"
+`,
+			err: "not compatible with the variable's type constraint",
+		},
+		"missing file": {
+			variable: `
+  outputs_file = "%s.missing"
+`,
+			err: "reading outputs file",
+		},
+		"output without outputs file": {
+			variable: `
+  output = "artifact_url"
+`,
+			err: "Missing outputs_file",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			variable := test.variable
+			if strings.Contains(variable, "%s") {
+				variable = fmt.Sprintf(variable, outputsFile)
+			}
+
+			fp, err := testDecodeHCL(t, []byte(fmt.Sprintf(`
+variable "artifact_url" {
+%s}
+
+module "backend" {
+  source = "%s"
+}
+
+scenario "upgrade" {
+  step "backend" {
+    module = module.backend
+
+    variables {
+      artifact_url = var.artifact_url
+    }
+  }
+}
+`, variable, modulePath)), DecodeTargetAll, WithDecoderEnv(test.env))
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, fp.Scenarios(), 1)
+			testMostlyEqualStepVar(t,
+				testMakeStepVarValue(test.expected),
+				fp.Scenarios()[0].Steps[0].Module.Attrs["artifact_url"],
+			)
+		})
+	}
+}
@@ -4,16 +4,19 @@
 package generate
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/mitchellh/cli"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
@@ -28,11 +31,38 @@ type Generator struct {
 	BaseDir  string
 	OutDir   string
 	UI       cli.Ui
+	// EnosBinary is the path to the enos binary that provisioner steps execute. It defaults to
+	// the path of the running executable.
+	EnosBinary string
+	// FixtureOutputs are the JSON encoded outputs of the fixtures that the scenario refers to,
+	// keyed by fixture name.
+	FixtureOutputs map[string][]byte
+	// ProducerOutputs are the JSON encoded outputs of the scenarios whose outputs the scenario
+	// refers to, keyed by scenario name.
+	ProducerOutputs map[string][]byte
+	// Tags are added to the resources of the module as the default tags of the providers that
+	// support them, along with the RunID and the UID of the scenario.
+	Tags  map[string]string
+	RunID string
+	// Layout is the layout of the out directory. See ModuleDir.
+	Layout *pb.Layout
+	// LockFile is the path of a project dependency lock file that modules without a lock file are
+	// seeded with.
+	LockFile string
+	// FlightPlanHash is the hash of the flight plan that the module is generated from, which the
+	// module directory is stamped with.
+	FlightPlanHash string
+	// DryRun renders the module in memory rather than writing it to the out directory. The
+	// rendered files are in Files.
+	DryRun bool
+	// Files are the contents of the files that a dry run would have written, keyed by path. A
+	// nil value is a file that would have been removed.
+	Files map[string][]byte
 }
 
 // NewGenerator takes options and returns a new validated generator.
 func NewGenerator(opts ...Opt) (*Generator, error) {
-	req := &Generator{UI: cli.NewMockUi()}
+	req := &Generator{UI: cli.NewMockUi(), Files: map[string][]byte{}}
 
 	for _, opt := range opts {
 		err := opt(req)
@@ -101,6 +131,78 @@ func WithUI(ui cli.Ui) Opt {
 	}
 }
 
+// WithEnosBinary is the path to the enos binary that provisioner steps execute.
+func WithEnosBinary(path string) Opt {
+	return func(req *Generator) error {
+		req.EnosBinary = path
+
+		return nil
+	}
+}
+
+// WithFixtureOutputs are the JSON encoded outputs of launched fixtures, keyed by fixture name.
+func WithFixtureOutputs(outputs map[string][]byte) Opt {
+	return func(req *Generator) error {
+		req.FixtureOutputs = outputs
+
+		return nil
+	}
+}
+
+// WithTags are the tags to add to the resources of the module.
+func WithTags(tags map[string]string) Opt {
+	return func(req *Generator) error {
+		req.Tags = tags
+
+		return nil
+	}
+}
+
+// WithRunID is the ID of the run that the module is generated for.
+func WithRunID(id string) Opt {
+	return func(req *Generator) error {
+		req.RunID = id
+
+		return nil
+	}
+}
+
+// WithLayout is the layout of the out directory.
+func WithLayout(layout *pb.Layout) Opt {
+	return func(req *Generator) error {
+		req.Layout = layout
+
+		return nil
+	}
+}
+
+// WithLockFile seeds modules that don't have a dependency lock file with the lock file at the path.
+func WithLockFile(path string) Opt {
+	return func(req *Generator) error {
+		req.LockFile = path
+
+		return nil
+	}
+}
+
+// WithFlightPlanHash is the hash of the flight plan that the module is generated from.
+func WithFlightPlanHash(hash string) Opt {
+	return func(req *Generator) error {
+		req.FlightPlanHash = hash
+
+		return nil
+	}
+}
+
+// WithDryRun renders the module in memory rather than writing it to the out directory.
+func WithDryRun() Opt {
+	return func(req *Generator) error {
+		req.DryRun = true
+
+		return nil
+	}
+}
+
 func ensureDir(dir string) (string, error) {
 	d, err := os.Open(dir)
 	if err != nil {
@@ -137,7 +239,12 @@ func absoluteNoSymlinks(path string) (string, error) {
 
 // Generate converts the Scenario into a terraform module.
 func (g *Generator) Generate() error {
-	err := g.generateCLIConfig()
+	err := g.checkMetadata()
+	if err != nil {
+		return err
+	}
+
+	err = g.generateCLIConfig()
 	if err != nil {
 		return err
 	}
@@ -151,15 +258,53 @@ func (g *Generator) TerraformRCPath() string {
 	return filepath.Join(g.TerraformModuleDir(), "terraform.rc")
 }
 
+// WithProducerOutputs are the JSON encoded outputs of launched scenarios whose outputs the scenario
+// refers to, keyed by scenario name.
+func WithProducerOutputs(outputs map[string][]byte) Opt {
+	return func(req *Generator) error {
+		req.ProducerOutputs = outputs
+
+		return nil
+	}
+}
+
 // TerraformModulePath is where the generated Terraform module file will
 // be written.
 func (g *Generator) TerraformModulePath() string {
 	return filepath.Join(g.TerraformModuleDir(), "scenario.tf")
 }
 
-// TerraformModuleDir is the directory where the generated Terraform.
+// TerraformFixtureVarsPath is where the outputs of fixtures will be written as Terraform
+// variables.
+func (g *Generator) TerraformFixtureVarsPath() string {
+	return filepath.Join(g.TerraformModuleDir(), "fixtures.auto.tfvars.json")
+}
+
+// TerraformProducerVarsPath is where the outputs of the scenarios whose outputs the scenario refers
+// to will be written as Terraform variables.
+func (g *Generator) TerraformProducerVarsPath() string {
+	return filepath.Join(g.TerraformModuleDir(), "scenarios.auto.tfvars.json")
+}
+
+// TerraformOverridesPath is the path of the generated override file of the scenario overrides.
+func (g *Generator) TerraformOverridesPath() string {
+	return filepath.Join(g.TerraformModuleDir(), "enos_override.tf")
+}
+
+// TerraformLockFilePath is the path of the dependency lock file of the module.
+func (g *Generator) TerraformLockFilePath() string {
+	return filepath.Join(g.TerraformModuleDir(), LockFileName)
+}
+
+// TerraformMetadataPath is the path of the metadata file that the module directory is stamped with.
+func (g *Generator) TerraformMetadataPath() string {
+	return filepath.Join(g.TerraformModuleDir(), MetadataFileName)
+}
+
+// TerraformModuleDir is the directory where the generated Terraform module is written, which
+// depends on the layout of the out directory.
 func (g *Generator) TerraformModuleDir() string {
-	return filepath.Join(g.OutDir, g.Scenario.UID())
+	return ModuleDir(g.OutDir, g.Scenario, g.Layout)
 }
 
 // generateCLIConfig converts the Scenario's terraform_cli into a terraformrc
@@ -186,70 +331,78 @@ func (g *Generator) generateCLIConfig() error {
 	rc := hclwrite.NewEmptyFile()
 	body := rc.Body()
 
+	// Blocks and attributes are separated by a newline. Null values and empty blocks are not
+	// written.
 	blocks := 0
-	for attr, val := range g.Scenario.TerraformCLI.ConfigVal.AsValueMap() {
-		if blocks != 0 {
+	appendBlock := func(body *hclwrite.Body, count *int, typ string, labels []string) *hclwrite.Body {
+		if *count > 0 {
 			body.AppendNewline()
 		}
-		blocks++
+		*count++
+
+		return body.AppendNewBlock(typ, labels).Body()
+	}
+
+	config := g.Scenario.TerraformCLI.ConfigVal.AsValueMap()
+	for _, attr := range sortedKeys(config) {
+		val := config[attr]
+		if val.IsNull() || !val.IsWhollyKnown() {
+			continue
+		}
 
 		switch attr {
-		case "credentials", "credentials_helpers":
-			for blkLabel, blkVal := range val.AsValueMap() {
-				blk := body.AppendNewBlock(attr, []string{blkLabel})
-				for blkAttr, blkAttrVal := range blkVal.AsValueMap() {
-					if blkAttrVal.IsNull() {
-						continue
+		case "credentials", "credentials_helper":
+			blks := val.AsValueMap()
+			for _, blkLabel := range sortedKeys(blks) {
+				blk := appendBlock(body, &blocks, attr, []string{blkLabel})
+				blkAttrs := blks[blkLabel].AsValueMap()
+				for _, blkAttr := range sortedKeys(blkAttrs) {
+					if blkAttrVal := blkAttrs[blkAttr]; !blkAttrVal.IsNull() {
+						blk.SetAttributeValue(blkAttr, blkAttrVal)
 					}
-					blk.Body().SetAttributeValue(blkAttr, blkAttrVal)
 				}
 			}
 		case "provider_installation":
 			for _, pi := range val.AsValueSlice() {
 				piBlocks := 0
-				piblk := body.AppendNewBlock(attr, []string{})
+				piBody := appendBlock(body, &blocks, attr, nil)
 				piBlks := pi.AsValueMap()
 
 				// dev_overrides always needs to come first if it exists
 				// so that it can properly bypass other methods.
-				if devOverride, ok := piBlks["dev_overrides"]; ok {
-					if !devOverride.IsNull() {
-						blk := piblk.Body().AppendNewBlock("dev_overrides", []string{})
-						for blkAttr, blkVal := range devOverride.AsValueMap() {
-							blk.Body().AppendUnstructuredTokens(devOverridesTokens(blkAttr, blkVal))
-						}
-						piBlocks++
+				if devOverride, ok := piBlks["dev_overrides"]; ok && !devOverride.IsNull() {
+					blk := appendBlock(piBody, &piBlocks, "dev_overrides", nil)
+					overrides := devOverride.AsValueMap()
+					for _, blkAttr := range sortedKeys(overrides) {
+						blk.AppendUnstructuredTokens(devOverridesTokens(blkAttr, overrides[blkAttr]))
 					}
 				}
 
-				for piBlkName, piBlkVal := range piBlks {
-					switch piBlkName {
-					case "dev_overrides":
+				for _, piBlkName := range sortedKeys(piBlks) {
+					piBlkVal := piBlks[piBlkName]
+					if piBlkName == "dev_overrides" || piBlkVal.IsNull() {
 						continue // we already handled it
-					default:
-						piBlocks++
-						if piBlocks != 0 {
-							piblk.Body().AppendNewline()
-						}
-						for _, blkVals := range piBlkVal.AsValueSlice() {
-							blk := piblk.Body().AppendNewBlock(piBlkName, []string{})
-							for blkAttr, blkVal := range blkVals.AsValueMap() {
-								if blkVal.IsNull() {
-									continue
-								}
-								blk.Body().SetAttributeValue(blkAttr, blkVal)
+					}
+
+					for _, blkVals := range piBlkVal.AsValueSlice() {
+						blk := appendBlock(piBody, &piBlocks, piBlkName, nil)
+						blkAttrs := blkVals.AsValueMap()
+						for _, blkAttr := range sortedKeys(blkAttrs) {
+							if blkVal := blkAttrs[blkAttr]; !blkVal.IsNull() {
+								blk.SetAttributeValue(blkAttr, blkVal)
 							}
 						}
 					}
 				}
 			}
 		default:
-			if !val.IsNull() {
-				body.SetAttributeValue(attr, val)
+			if blocks > 0 {
+				body.AppendNewline()
 			}
+			blocks++
+			body.SetAttributeValue(attr, val)
 		}
 	}
-
 	// Make sure our out directory exists and is a directory
 	err := g.ensureOutDir()
 	if err != nil {
@@ -277,9 +430,16 @@ func (g *Generator) generateModule() error {
 	// Write "terraform" settings block
 	g.maybeWriteTerraformSettings(modBody)
 
+	// Write the variables and locals of our tags
+	g.maybeWriteTags(modBody)
+
 	// Write provider level config
 	g.maybeWriteProviderConfig(modBody)
 
+	// Write variables for the outputs of fixtures and other scenarios
+	g.maybeWriteFixtureVariables(modBody)
+	g.maybeWriteProducerVariables(modBody)
+
 	// Convert each step into a Terraform module
 	err = g.convertStepsToModules(modBody)
 	if err != nil {
@@ -293,20 +453,93 @@ func (g *Generator) generateModule() error {
 	}
 
 	// Write our module to disk
-	return g.write(g.TerraformModulePath(), mod.Bytes())
+	err = g.write(g.TerraformModulePath(), mod.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = g.writeOverrides()
+	if err != nil {
+		return err
+	}
+
+	err = g.maybeSeedLockFile()
+	if err != nil {
+		return err
+	}
+
+	err = g.writeFixtureVars()
+	if err != nil {
+		return err
+	}
+
+	err = g.writeProducerVars()
+	if err != nil {
+		return err
+	}
+
+	return g.writeMetadata()
+}
+
+// maybeSeedLockFile copies the project lock file into the module if the module doesn't have a lock
+// file yet. Existing lock files are never changed as Terraform maintains them when the module is
+// initialized.
+func (g *Generator) maybeSeedLockFile() error {
+	if g.LockFile == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(g.TerraformLockFilePath()); err == nil {
+		return nil
+	}
+
+	lock, err := os.ReadFile(g.LockFile)
+	if err != nil {
+		return fmt.Errorf("reading project lock file: %w", err)
+	}
+
+	return g.write(g.TerraformLockFilePath(), lock)
+}
+
+// writeOverrides writes the overrides of the scenario to an override file, which Terraform merges
+// into the module after every other file. Any existing override file is removed if the scenario
+// has no overrides.
+func (g *Generator) writeOverrides() error {
+	if g.Scenario.Overrides == nil {
+		return g.remove(g.TerraformOverridesPath())
+	}
+
+	file := hclwrite.NewEmptyFile()
+	writeTerraformSettings(file.Body(), g.Scenario.Overrides)
+
+	return g.write(g.TerraformOverridesPath(), file.Bytes())
 }
 
 func (g *Generator) ensureOutDir() error {
-	_, err := ensureDir(g.TerraformModuleDir())
+	_, err := g.ensureDir(g.TerraformModuleDir())
 
 	return err
 }
 
+// ensureDir ensures that the directory exists unless we're doing a dry run, in which case nothing
+// is created.
+func (g *Generator) ensureDir(dir string) (string, error) {
+	if g.DryRun {
+		return filepath.Abs(dir)
+	}
+
+	return ensureDir(dir)
+}
+
 // maybeWriteTerraformSettings writes any configured "terraform" settings
+func (g *Generator) maybeWriteTerraformSettings(rootBody *hclwrite.Body) {
+	writeTerraformSettings(rootBody, g.Scenario.TerraformSetting)
+}
+
+// writeTerraformSettings writes the settings as a "terraform" block.
 //
 //nolint:cyclop // writing out our terraform settings is complicated.
-func (g *Generator) maybeWriteTerraformSettings(rootBody *hclwrite.Body) {
-	s := g.Scenario.TerraformSetting
+func writeTerraformSettings(rootBody *hclwrite.Body, s *flightplan.TerraformSetting) {
 	if s == nil {
 		return
 	}
@@ -332,18 +565,19 @@ func (g *Generator) maybeWriteTerraformSettings(rootBody *hclwrite.Body) {
 		rpBlock := body.AppendNewBlock("required_providers", []string{})
 		rpBody := rpBlock.Body()
 
-		for k, v := range s.RequiredProviders {
-			rpBody.SetAttributeValue(k, v)
+		for _, k := range sortedKeys(s.RequiredProviders) {
+			rpBody.SetAttributeValue(k, s.RequiredProviders[k])
 		}
 		body.AppendNewline()
 	}
 
 	if s.ProviderMetas != nil {
-		for pmName, pmAttrs := range s.ProviderMetas {
+		for _, pmName := range sortedKeys(s.ProviderMetas) {
+			pmAttrs := s.ProviderMetas[pmName]
 			pmBlock := body.AppendNewBlock("provider_meta", []string{pmName})
 			pmBody := pmBlock.Body()
-			for k, v := range pmAttrs {
-				pmBody.SetAttributeValue(k, v)
+			for _, k := range sortedKeys(pmAttrs) {
+				pmBody.SetAttributeValue(k, pmAttrs[k])
 			}
 			body.AppendNewline()
 		}
@@ -353,22 +587,20 @@ func (g *Generator) maybeWriteTerraformSettings(rootBody *hclwrite.Body) {
 		beBlock := body.AppendNewBlock("backend", []string{s.Backend.Name})
 		beBody := beBlock.Body()
 
-		for k, v := range s.Backend.Attrs {
-			beBody.SetAttributeValue(k, v)
-		}
-		if len(s.Backend.Attrs) > 0 {
-			beBody.AppendNewline()
+		for _, k := range sortedKeys(s.Backend.Attrs) {
+			beBody.SetAttributeValue(k, s.Backend.Attrs[k])
 		}
 
 		if !s.Backend.Workspaces.IsNull() && s.Backend.Workspaces.IsWhollyKnown() {
 			for i, wksp := range s.Backend.Workspaces.AsValueSlice() {
-				if i != 0 {
+				if i != 0 || len(s.Backend.Attrs) > 0 {
 					beBody.AppendNewline()
 				}
 				wkspBlock := beBody.AppendNewBlock("workspaces", []string{})
 				wkspBody := wkspBlock.Body()
-				for k, v := range wksp.AsValueMap() {
-					if !v.IsNull() && v.IsWhollyKnown() {
+				wkspAttrs := wksp.AsValueMap()
+				for _, k := range sortedKeys(wkspAttrs) {
+					if v := wkspAttrs[k]; !v.IsNull() && v.IsWhollyKnown() {
 						wkspBody.SetAttributeValue(k, v)
 					}
 				}
@@ -384,7 +616,9 @@ func (g *Generator) maybeWriteTerraformSettings(rootBody *hclwrite.Body) {
 				cBlock := body.AppendNewBlock("cloud", nil)
 				cBody := cBlock.Body()
 
-				for k, v := range cloud.AsValueMap() {
+				cloudAttrs := cloud.AsValueMap()
+				for _, k := range sortedKeys(cloudAttrs) {
+					v := cloudAttrs[k]
 					switch k {
 					case "hostname", "organization", "token":
 						cBody.SetAttributeValue(k, v)
@@ -395,8 +629,9 @@ func (g *Generator) maybeWriteTerraformSettings(rootBody *hclwrite.Body) {
 							}
 							wkspBlock := cBody.AppendNewBlock("workspaces", []string{})
 							wkspBody := wkspBlock.Body()
-							for wk, wv := range wksp.AsValueMap() {
-								if !wv.IsNull() && wv.IsWhollyKnown() {
+							wkspAttrs := wksp.AsValueMap()
+							for _, wk := range sortedKeys(wkspAttrs) {
+								if wv := wkspAttrs[wk]; !wv.IsNull() && wv.IsWhollyKnown() {
 									wkspBody.SetAttributeValue(wk, wv)
 								}
 							}
@@ -422,8 +657,8 @@ func (g *Generator) writeSchemalessBlocks(
 		hclBlock := body.AppendNewBlock(block.Type, block.Labels)
 		blockBody := hclBlock.Body()
 
-		for name, val := range block.Attrs {
-			blockBody.SetAttributeValue(name, val)
+		for _, name := range sortedKeys(block.Attrs) {
+			blockBody.SetAttributeValue(name, block.Attrs[name])
 		}
 
 		if len(block.Children) > 0 {
@@ -444,12 +679,16 @@ func (g *Generator) maybeWriteProviderConfig(rootBody *hclwrite.Body) {
 		block := rootBody.AppendNewBlock("provider", []string{provider.Type})
 		body := block.Body()
 
-		for name, val := range provider.Config.Attrs {
-			body.SetAttributeValue(name, val)
+		for _, name := range sortedKeys(provider.Config.Attrs) {
+			body.SetAttributeValue(name, provider.Config.Attrs[name])
 		}
 
 		g.writeSchemalessBlocks(body, provider.Config.Children)
 
+		if len(g.Tags) > 0 {
+			writeProviderDefaultTags(provider.Type, body)
+		}
+
 		// Don't alias "default" providers to ensure that step modules inherit
 		// their configuration by default.
 		if provider.Alias != "" && provider.Alias != "default" {
@@ -460,6 +699,162 @@ func (g *Generator) maybeWriteProviderConfig(rootBody *hclwrite.Body) {
 	rootBody.AppendNewline()
 }
 
+// maybeWriteTags writes variables for the run ID and the scenario UID and a local that has the
+// tags to add to resources.
+func (g *Generator) maybeWriteTags(rootBody *hclwrite.Body) {
+	if len(g.Tags) == 0 {
+		return
+	}
+
+	for _, v := range []struct {
+		name string
+		desc string
+		val  string
+	}{
+		{tagRunID, "The ID of the enos run that last applied the scenario", g.RunID},
+		{tagScenarioUID, "The UID of the enos scenario", g.Scenario.UID()},
+	} {
+		block := rootBody.AppendNewBlock("variable", []string{v.name})
+		body := block.Body()
+		body.SetAttributeValue("description", cty.StringVal(v.desc))
+		body.SetAttributeRaw("type", hclwrite.TokensForIdentifier("string"))
+		body.SetAttributeValue("default", cty.StringVal(v.val))
+		rootBody.AppendNewline()
+	}
+
+	tags := []hclwrite.ObjectAttrTokens{}
+	for _, name := range sortedKeys(g.Tags) {
+		tags = append(tags, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForValue(cty.StringVal(name)),
+			Value: hclwrite.TokensForValue(cty.StringVal(g.Tags[name])),
+		})
+	}
+	for _, name := range []string{tagRunID, tagScenarioUID} {
+		tags = append(tags, hclwrite.ObjectAttrTokens{
+			Name: hclwrite.TokensForIdentifier(name),
+			Value: hclwrite.TokensForTraversal(hcl.Traversal{
+				hcl.TraverseRoot{Name: "var"},
+				hcl.TraverseAttr{Name: name},
+			}),
+		})
+	}
+
+	block := rootBody.AppendNewBlock("locals", nil)
+	block.Body().SetAttributeRaw(tagsLocal, hclwrite.TokensForObject(tags))
+	rootBody.AppendNewline()
+}
+
+// writeProviderDefaultTags adds the tags to the default tags of providers that support them. Any
+// default tags that the provider has been configured with take precedence.
+func writeProviderDefaultTags(typ string, body *hclwrite.Body) {
+	tagsTokens := func(attr *hclwrite.Attribute) hclwrite.Tokens {
+		local := hclwrite.TokensForTraversal(hcl.Traversal{
+			hcl.TraverseRoot{Name: "local"},
+			hcl.TraverseAttr{Name: tagsLocal},
+		})
+		if attr == nil {
+			return local
+		}
+
+		return hclwrite.TokensForFunctionCall("merge", local, attr.Expr().BuildTokens(nil))
+	}
+
+	switch typ {
+	case "aws":
+		block := body.FirstMatchingBlock("default_tags", nil)
+		if block == nil {
+			block = body.AppendNewBlock("default_tags", nil)
+		}
+		block.Body().SetAttributeRaw("tags", tagsTokens(block.Body().GetAttribute("tags")))
+	case "google", "google-beta":
+		body.SetAttributeRaw("default_labels", tagsTokens(body.GetAttribute("default_labels")))
+	default:
+	}
+}
+
+// maybeWriteFixtureVariables writes a variable for each fixture that the scenario refers to. The
+// values of the variables are the outputs of the fixtures.
+func (g *Generator) maybeWriteFixtureVariables(rootBody *hclwrite.Body) {
+	for _, name := range g.Scenario.Fixtures {
+		block := rootBody.AppendNewBlock("variable", []string{fixtureVariableName(name)})
+		body := block.Body()
+		body.SetAttributeValue("description", cty.StringVal("The outputs of the "+name+" fixture"))
+		body.SetAttributeRaw("type", hclwrite.TokensForIdentifier("any"))
+		body.SetAttributeValue("default", cty.NullVal(cty.DynamicPseudoType))
+		rootBody.AppendNewline()
+	}
+}
+
+// maybeWriteProducerVariables writes a variable for each scenario whose outputs the scenario refers
+// to. The values of the variables are objects with the outputs of the scenarios so that references
+// like scenario.name.outputs.output become var.scenario_name.outputs.output.
+func (g *Generator) maybeWriteProducerVariables(rootBody *hclwrite.Body) {
+	for _, name := range g.Scenario.Producers {
+		block := rootBody.AppendNewBlock("variable", []string{producerVariableName(name)})
+		body := block.Body()
+		body.SetAttributeValue("description", cty.StringVal("The outputs of the "+name+" scenario"))
+		body.SetAttributeRaw("type", hclwrite.TokensForIdentifier("any"))
+		body.SetAttributeValue("default", cty.NullVal(cty.DynamicPseudoType))
+		rootBody.AppendNewline()
+	}
+}
+
+// writeFixtureVars writes the outputs of the fixtures that the scenario refers to as a variables
+// file that Terraform loads automatically. Any existing variables file is removed if we have no
+// fixture outputs.
+func (g *Generator) writeFixtureVars() error {
+	vars := map[string]json.RawMessage{}
+	for _, name := range g.Scenario.Fixtures {
+		if out, ok := g.FixtureOutputs[name]; ok && len(out) > 0 {
+			vars[fixtureVariableName(name)] = json.RawMessage(out)
+		}
+	}
+
+	return g.writeVars(g.TerraformFixtureVarsPath(), vars)
+}
+
+// writeProducerVars writes the outputs of the scenarios whose outputs the scenario refers to as a
+// variables file that Terraform loads automatically. Any existing variables file is removed if we
+// have no outputs of scenarios.
+func (g *Generator) writeProducerVars() error {
+	vars := map[string]json.RawMessage{}
+	for _, name := range g.Scenario.Producers {
+		if out, ok := g.ProducerOutputs[name]; ok && len(out) > 0 {
+			val, err := json.Marshal(map[string]json.RawMessage{"outputs": json.RawMessage(out)})
+			if err != nil {
+				return err
+			}
+			vars[producerVariableName(name)] = val
+		}
+	}
+
+	return g.writeVars(g.TerraformProducerVarsPath(), vars)
+}
+
+// writeVars writes the variables file, or removes it if there are no variables.
+func (g *Generator) writeVars(path string, vars map[string]json.RawMessage) error {
+	if len(vars) == 0 {
+		return g.remove(path)
+	}
+
+	bytes, err := json.MarshalIndent(vars, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if g.DryRun {
+		g.Files[path] = bytes
+
+		return nil
+	}
+
+	if g.UI != nil {
+		g.UI.Info("writing to " + path)
+	}
+
+	return os.WriteFile(path, bytes, 0o600)
+}
+
 func (g *Generator) convertStepsToModules(rootBody *hclwrite.Body) error {
 	// module for each step
 	for i, step := range g.Scenario.Steps {
@@ -474,6 +869,26 @@ func (g *Generator) convertStepsToModules(rootBody *hclwrite.Body) error {
 			body.SetAttributeRaw("depends_on", dependsOnTokens(step.DependsOn))
 		}
 
+		// Provisioner steps execute a generated module
+		if step.Provisioner != nil {
+			src, err := g.generateProvisionerModule(step)
+			if err != nil {
+				return err
+			}
+			body.SetAttributeValue("source", cty.StringVal(src))
+
+			err = writeStepVariables(body, step.Provisioner.Attrs)
+			if err != nil {
+				return err
+			}
+
+			if i+1 < len(g.Scenario.Steps) {
+				rootBody.AppendNewline()
+			}
+
+			continue
+		}
+
 		// source
 		src, err := maybeUpdateRelativeSourcePaths(
 			step.Module.Source, g.BaseDir, g.TerraformModuleDir(),
@@ -495,38 +910,62 @@ func (g *Generator) convertStepsToModules(rootBody *hclwrite.Body) error {
 		}
 
 		// variable attributes
-		if len(step.Module.Attrs) > 0 {
-			body.AppendNewline()
+		err = writeStepVariables(body, step.Module.Attrs)
+		if err != nil {
+			return err
 		}
-		for k, v := range step.Module.Attrs {
-			stepVar, diags := flightplan.StepVariableFromVal(v)
-			if diags.HasErrors() {
-				return errors.New(diags.Error())
-			}
 
-			// Use the absolute value
-			if stepVar.Value != cty.NilVal {
-				body.SetAttributeValue(k, stepVar.Value)
+		if i+1 < len(g.Scenario.Steps) {
+			rootBody.AppendNewline()
+		}
+	}
 
-				continue
-			}
+	return nil
+}
 
-			if stepVar.Traversal == nil {
-				continue
-			}
+// writeStepVariables writes the variables of a step as the attributes of its module block in the
+// order of their names.
+func writeStepVariables(body *hclwrite.Body, attrs map[string]cty.Value) error {
+	if len(attrs) > 0 {
+		body.AppendNewline()
+	}
+
+	for _, k := range sortedKeys(attrs) {
+		v := attrs[k]
+		stepVar, diags := flightplan.StepVariableFromVal(v)
+		if diags.HasErrors() {
+			return errors.New(diags.Error())
+		}
 
-			// It's a module reference
-			// Rename the root of the traversal to "module" and write it out
-			err := stepToModuleTraversal(stepVar.Traversal)
+		// Use the absolute value
+		if stepVar.Value != cty.NilVal {
+			body.SetAttributeValue(k, stepVar.Value)
+
+			continue
+		}
+
+		// It's an expression over references that Terraform evaluates
+		if stepVar.Expression != "" {
+			tokens, err := referenceExpressionTokens(stepVar)
 			if err != nil {
-				return err
+				return fmt.Errorf("writing variable %s: %w", k, err)
 			}
-			body.SetAttributeTraversal(k, stepVar.Traversal)
+			body.SetAttributeRaw(k, tokens)
+
+			continue
 		}
 
-		if i+1 < len(g.Scenario.Steps) {
-			rootBody.AppendNewline()
+		if stepVar.Traversal == nil {
+			continue
 		}
+
+		// It's a module, fixture or scenario reference
+		// Rename the root of the traversal and write it out
+		traversal, err := referenceTraversal(stepVar.Traversal)
+		if err != nil {
+			return err
+		}
+		body.SetAttributeTraversal(k, traversal)
 	}
 
 	return nil
@@ -567,12 +1006,22 @@ func (g *Generator) maybeWriteOutputs(rootBody *hclwrite.Body) error {
 				return nil
 			}
 
+			if stepVar.Expression != "" {
+				tokens, err := referenceExpressionTokens(stepVar)
+				if err != nil {
+					return fmt.Errorf("writing output %s: %w", output.Name, err)
+				}
+				body.SetAttributeRaw("value", tokens)
+
+				return nil
+			}
+
 			if stepVar.Traversal != nil {
-				err := stepToModuleTraversal(stepVar.Traversal)
+				traversal, err := referenceTraversal(stepVar.Traversal)
 				if err != nil {
 					return err
 				}
-				body.SetAttributeTraversal("value", stepVar.Traversal)
+				body.SetAttributeTraversal("value", traversal)
 			}
 
 			return nil
@@ -591,7 +1040,44 @@ func (g *Generator) maybeWriteOutputs(rootBody *hclwrite.Body) error {
 	return nil
 }
 
+// sortedKeys returns the keys of the map in order so that generated modules don't depend on the
+// order of map iteration.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// remove removes a file that we no longer generate if it exists.
+func (g *Generator) remove(path string) error {
+	if g.DryRun {
+		if _, err := os.Stat(path); err == nil {
+			g.Files[path] = nil
+		}
+
+		return nil
+	}
+
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
 func (g *Generator) write(path string, bytes []byte) error {
+	if g.DryRun {
+		// Empty files are empty rather than nil as nil files would be removed
+		g.Files[path] = append([]byte{}, hclwrite.Format(bytes)...)
+
+		return nil
+	}
+
 	if g.UI != nil {
 		g.UI.Info("writing to " + path)
 	}
@@ -666,8 +1152,8 @@ func stepProviderTokens(providers map[string]*flightplan.Provider) hclwrite.Toke
 		},
 	}
 
-	i := 0
-	for importName, provider := range providers {
+	for i, importName := range sortedKeys(providers) {
+		provider := providers[importName]
 		if i > 0 {
 			tokens = append(tokens,
 				&hclwrite.Token{
@@ -676,7 +1162,6 @@ func stepProviderTokens(providers map[string]*flightplan.Provider) hclwrite.Toke
 				},
 			)
 		}
-		i++
 
 		tokens = append(tokens,
 			&hclwrite.Token{
@@ -838,6 +1323,27 @@ func maybeUpdateRelativeSourcePaths(source, baseDir, outDir string) (string, err
 	return relativePath(outDir, filepath.Join(baseDir, source))
 }
 
+// evalSymlinksOfExisting evaluates the symlinks of the path, or of its closest ancestor that exists
+// if the path does not exist.
+func evalSymlinksOfExisting(path string) (string, error) {
+	p, err := filepath.EvalSymlinks(path)
+	if err == nil || !os.IsNotExist(err) {
+		return p, err
+	}
+
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+
+	p, err = evalSymlinksOfExisting(parent)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(p, filepath.Base(path)), nil
+}
+
 // relativePath builds the shortest relative path from one path to another. This
 // function differs from the standard `filepath.Rel()` because it evaluates symlinks
 // and returns the path with "./" or "../" prepended to adhere to the Terraform
@@ -854,7 +1360,8 @@ func relativePath(from, to string) (string, error) {
 		return "", err
 	}
 
-	from, err = filepath.EvalSymlinks(from)
+	// The directory we're generating into doesn't exist yet when doing a dry run
+	from, err = evalSymlinksOfExisting(from)
 	if err != nil {
 		return "", err
 	}
@@ -880,6 +1387,73 @@ func relativePath(from, to string) (string, error) {
 	return rel, nil
 }
 
+// The names of the tags and variables of the run ID and scenario UID, and of the local that has
+// all of the tags.
+const (
+	tagRunID       = "enos_run_id"
+	tagScenarioUID = "enos_scenario_uid"
+	tagsLocal      = "enos_tags"
+)
+
+// referenceTraversal takes a "step", "fixture" or "scenario" traversal and returns the traversal
+// of the module or variable that it refers to in the generated module.
+func referenceTraversal(in hcl.Traversal) (hcl.Traversal, error) {
+	if name := flightplan.ProducerName(in); name != "" {
+		out := hcl.Traversal{
+			hcl.TraverseRoot{Name: "var", SrcRange: in[0].SourceRange()},
+			hcl.TraverseAttr{Name: producerVariableName(name), SrcRange: in[1].SourceRange()},
+		}
+
+		return append(out, in[2:]...), nil
+	}
+
+	name := flightplan.FixtureName(in)
+	if name == "" {
+		err := stepToModuleTraversal(in)
+
+		return in, err
+	}
+
+	out := hcl.Traversal{
+		hcl.TraverseRoot{Name: "var", SrcRange: in[0].SourceRange()},
+		hcl.TraverseAttr{Name: fixtureVariableName(name), SrcRange: in[1].SourceRange()},
+	}
+
+	return append(out, in[2:]...), nil
+}
+
+// referenceExpressionTokens returns the tokens of the expression of the step variable with its
+// references to steps, fixtures and scenarios renamed like referenceTraversal.
+func referenceExpressionTokens(stepVar *flightplan.StepVariable) (hclwrite.Tokens, error) {
+	file, diags := hclwrite.ParseConfig([]byte("value = "+stepVar.Expression+"\n"), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, errors.New(diags.Error())
+	}
+
+	expr := file.Body().GetAttribute("value").Expr()
+	for _, ref := range stepVar.References {
+		if name := flightplan.FixtureName(ref); name != "" {
+			expr.RenameVariablePrefix([]string{"fixture", name}, []string{"var", fixtureVariableName(name)})
+		}
+		if name := flightplan.ProducerName(ref); name != "" {
+			expr.RenameVariablePrefix([]string{"scenario", name}, []string{"var", producerVariableName(name)})
+		}
+	}
+	expr.RenameVariablePrefix([]string{"step"}, []string{"module"})
+
+	return expr.BuildTokens(nil), nil
+}
+
+// fixtureVariableName returns the name of the variable that has the outputs of the fixture.
+func fixtureVariableName(name string) string {
+	return "fixture_" + name
+}
+
+// producerVariableName returns the name of the variable that has the outputs of the scenario.
+func producerVariableName(name string) string {
+	return "scenario_" + name
+}
+
 // stepToModuleTraversal takes a "step" traversal and updates the root of the
 // traversal to "module".
 func stepToModuleTraversal(in hcl.Traversal) error {
@@ -4,11 +4,18 @@
 package generate
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 )
 
 // Test_maybeUpdateRelativeSourcePaths verifies that we rewrite source paths
@@ -137,3 +144,579 @@ func Test_MaybeUpdateRelativeSourcePaths(t *testing.T) {
 		})
 	}
 }
+
+// Test_TerraformModuleDir verifies that we generate modules into a directory named after the
+// scenario slug unless a module was previously generated into a directory named after the UID.
+func Test_TerraformModuleDir(t *testing.T) {
+	t.Parallel()
+
+	scenario := flightplan.NewScenario()
+	scenario.Name = "test"
+	scenario.Variants = flightplan.NewVector(flightplan.NewElement("backend", "raft"))
+
+	outDir := t.TempDir()
+	gen, err := NewGenerator(
+		WithScenario(scenario),
+		WithScenarioBaseDirectory(t.TempDir()),
+		WithOutBaseDirectory(outDir),
+	)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(outDir, scenario.Slug()), gen.TerraformModuleDir())
+
+	require.NoError(t, os.MkdirAll(filepath.Join(outDir, scenario.UID()), 0o755))
+	require.Equal(t, filepath.Join(outDir, scenario.UID()), gen.TerraformModuleDir())
+
+	require.NoError(t, os.MkdirAll(filepath.Join(outDir, scenario.Slug()), 0o755))
+	require.Equal(t, filepath.Join(outDir, scenario.Slug()), gen.TerraformModuleDir())
+}
+
+// Test_ProvisionerSteps verifies that provisioner steps are generated into modules that execute
+// the provisioner and read its outputs.
+func Test_ProvisionerSteps(t *testing.T) {
+	t.Parallel()
+
+	scenario := flightplan.NewScenario()
+	scenario.Name = "test"
+
+	infra := flightplan.NewScenarioStep()
+	infra.Name = "infra"
+	infra.Module.Source = "hashicorp/infra/aws"
+
+	configure := flightplan.NewScenarioStep()
+	configure.Name = "configure"
+	configure.DependsOn = []string{"infra"}
+	configure.Module = nil
+	configure.Provisioner = flightplan.NewStepProvisioner()
+	configure.Provisioner.Type = flightplan.ProvisionerTypeAnsible
+	configure.Provisioner.Outputs = []string{"leader", "version"}
+	configure.Provisioner.Attrs["playbook"] = flightplan.StepVariableVal(&flightplan.StepVariable{
+		Value: cty.StringVal("site.yml"),
+	})
+	configure.Provisioner.Attrs["hosts"] = flightplan.StepVariableVal(&flightplan.StepVariable{
+		Traversal: hcl.Traversal{
+			hcl.TraverseRoot{Name: "step"},
+			hcl.TraverseAttr{Name: "infra"},
+			hcl.TraverseAttr{Name: "hosts"},
+		},
+	})
+	scenario.Steps = []*flightplan.ScenarioStep{infra, configure}
+
+	baseDir := t.TempDir()
+	gen, err := NewGenerator(
+		WithScenario(scenario),
+		WithScenarioBaseDirectory(baseDir),
+		WithOutBaseDirectory(t.TempDir()),
+		WithEnosBinary("/usr/local/bin/enos"),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	mod, err := os.ReadFile(gen.TerraformModulePath())
+	require.NoError(t, err)
+	require.Contains(t, string(mod), `module "configure" {
+  depends_on = [module.infra]
+  source     = "./provisioners/configure"
+
+  hosts    = module.infra.hosts
+  playbook = "site.yml"
+}`)
+
+	provisioner, err := os.ReadFile(filepath.Join(gen.ProvisionerModuleDir("configure"), "main.tf"))
+	require.NoError(t, err)
+	require.Equal(t, `variable "hosts" {
+  type    = any
+  default = null
+}
+
+variable "playbook" {
+  type    = any
+  default = null
+}
+
+locals {
+  request = jsonencode({
+    step        = "configure"
+    provisioner = "ansible"
+    base_dir    = "`+gen.BaseDir+`"
+    variables = {
+      hosts    = var.hosts
+      playbook = var.playbook
+    }
+  })
+}
+
+resource "terraform_data" "provisioner" {
+  input            = local.request
+  triggers_replace = local.request
+
+  provisioner "local-exec" {
+    interpreter = ["/usr/local/bin/enos", "provisioner", "apply"]
+    command     = path.module
+    environment = {
+      ENOS_PROVISIONER_REQUEST = self.input
+    }
+  }
+
+  provisioner "local-exec" {
+    when        = destroy
+    interpreter = ["/usr/local/bin/enos", "provisioner", "destroy"]
+    command     = path.module
+    environment = {
+      ENOS_PROVISIONER_REQUEST = self.input
+    }
+  }
+}
+
+data "terraform_remote_state" "outputs" {
+  backend = "local"
+  config = {
+    path = "${path.module}/outputs.tfstate"
+  }
+  defaults = {
+    leader  = null
+    version = null
+  }
+  depends_on = [terraform_data.provisioner]
+}
+
+output "leader" {
+  value = data.terraform_remote_state.outputs.outputs.leader
+}
+
+output "version" {
+  value = data.terraform_remote_state.outputs.outputs.version
+}
+`, string(provisioner))
+}
+
+// Test_FixtureReferences verifies that references to fixtures are generated into variables that
+// are set to the outputs of the fixtures.
+func Test_FixtureReferences(t *testing.T) {
+	t.Parallel()
+
+	scenario := flightplan.NewScenario()
+	scenario.Name = "test"
+	scenario.Fixtures = []string{"network"}
+
+	backend := flightplan.NewScenarioStep()
+	backend.Name = "backend"
+	backend.Module.Source = "hashicorp/backend/aws"
+	backend.Module.Attrs["vpc_id"] = flightplan.StepVariableVal(&flightplan.StepVariable{
+		Traversal: hcl.Traversal{
+			hcl.TraverseRoot{Name: "fixture"},
+			hcl.TraverseAttr{Name: "network"},
+			hcl.TraverseAttr{Name: "vpc_id"},
+		},
+	})
+	scenario.Steps = []*flightplan.ScenarioStep{backend}
+	scenario.Outputs = []*flightplan.ScenarioOutput{{
+		Name: "network",
+		Value: flightplan.StepVariableVal(&flightplan.StepVariable{
+			Traversal: hcl.Traversal{
+				hcl.TraverseRoot{Name: "fixture"},
+				hcl.TraverseAttr{Name: "network"},
+			},
+		}),
+	}}
+
+	outDir := t.TempDir()
+	gen, err := NewGenerator(
+		WithScenario(scenario),
+		WithScenarioBaseDirectory(t.TempDir()),
+		WithOutBaseDirectory(outDir),
+		WithFixtureOutputs(map[string][]byte{"network": []byte(`{"vpc_id":"vpc-1234"}`)}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	mod, err := os.ReadFile(gen.TerraformModulePath())
+	require.NoError(t, err)
+	require.Contains(t, string(mod), `variable "fixture_network" {
+  description = "The outputs of the network fixture"
+  type        = any
+  default     = null
+}`)
+	require.Contains(t, string(mod), `  vpc_id = var.fixture_network.vpc_id`)
+	require.Contains(t, string(mod), `  value = var.fixture_network`)
+
+	vars, err := os.ReadFile(gen.TerraformFixtureVarsPath())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"fixture_network":{"vpc_id":"vpc-1234"}}`, string(vars))
+
+	// Generating without outputs removes the variables of the prior outputs
+	gen, err = NewGenerator(
+		WithScenario(scenario),
+		WithScenarioBaseDirectory(t.TempDir()),
+		WithOutBaseDirectory(outDir),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+	require.NoFileExists(t, gen.TerraformFixtureVarsPath())
+}
+
+// Test_ProducerReferences verifies that references to the outputs of other scenarios are generated
+// into variables that are set to the outputs of the scenarios.
+func Test_ProducerReferences(t *testing.T) {
+	t.Parallel()
+
+	scenario := flightplan.NewScenario()
+	scenario.Name = "upgrade"
+	scenario.Producers = []string{"shared_vpc"}
+
+	backend := flightplan.NewScenarioStep()
+	backend.Name = "backend"
+	backend.Module.Source = "hashicorp/backend/aws"
+	backend.Module.Attrs["vpc_id"] = flightplan.StepVariableVal(&flightplan.StepVariable{
+		Traversal: hcl.Traversal{
+			hcl.TraverseRoot{Name: "scenario"},
+			hcl.TraverseAttr{Name: "shared_vpc"},
+			hcl.TraverseAttr{Name: "outputs"},
+			hcl.TraverseAttr{Name: "vpc_id"},
+		},
+	})
+	scenario.Steps = []*flightplan.ScenarioStep{backend}
+	scenario.Outputs = []*flightplan.ScenarioOutput{{
+		Name: "summary",
+		Value: flightplan.StepVariableVal(&flightplan.StepVariable{
+			Expression: `"${scenario.shared_vpc.outputs.vpc_id}-${step.backend.id}"`,
+			References: []hcl.Traversal{
+				{
+					hcl.TraverseRoot{Name: "scenario"},
+					hcl.TraverseAttr{Name: "shared_vpc"},
+					hcl.TraverseAttr{Name: "outputs"},
+					hcl.TraverseAttr{Name: "vpc_id"},
+				},
+				{
+					hcl.TraverseRoot{Name: "step"},
+					hcl.TraverseAttr{Name: "backend"},
+					hcl.TraverseAttr{Name: "id"},
+				},
+			},
+		}),
+	}}
+
+	outDir := t.TempDir()
+	gen, err := NewGenerator(
+		WithScenario(scenario),
+		WithScenarioBaseDirectory(t.TempDir()),
+		WithOutBaseDirectory(outDir),
+		WithProducerOutputs(map[string][]byte{"shared_vpc": []byte(`{"vpc_id":"vpc-1234"}`)}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	mod, err := os.ReadFile(gen.TerraformModulePath())
+	require.NoError(t, err)
+	require.Contains(t, string(mod), `variable "scenario_shared_vpc" {
+  description = "The outputs of the shared_vpc scenario"
+  type        = any
+  default     = null
+}`)
+	require.Contains(t, string(mod), `  vpc_id = var.scenario_shared_vpc.outputs.vpc_id`)
+	require.Contains(t, string(mod), `  value = "${var.scenario_shared_vpc.outputs.vpc_id}-${module.backend.id}"`)
+
+	vars, err := os.ReadFile(gen.TerraformProducerVarsPath())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"scenario_shared_vpc":{"outputs":{"vpc_id":"vpc-1234"}}}`, string(vars))
+
+	// Generating without outputs removes the variables of the prior outputs
+	gen, err = NewGenerator(
+		WithScenario(scenario),
+		WithScenarioBaseDirectory(t.TempDir()),
+		WithOutBaseDirectory(outDir),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+	require.NoFileExists(t, gen.TerraformProducerVarsPath())
+}
+
+// Test_OutputExpressions verifies that outputs composed of references to steps and fixtures are
+// generated with references to the modules and fixture variables.
+func Test_OutputExpressions(t *testing.T) {
+	t.Parallel()
+
+	scenario := flightplan.NewScenario()
+	scenario.Name = "test"
+	scenario.Fixtures = []string{"network"}
+
+	backend := flightplan.NewScenarioStep()
+	backend.Name = "backend"
+	backend.Module.Source = "hashicorp/backend/aws"
+	scenario.Steps = []*flightplan.ScenarioStep{backend}
+	scenario.Outputs = []*flightplan.ScenarioOutput{{
+		Name: "endpoint",
+		Value: flightplan.StepVariableVal(&flightplan.StepVariable{
+			Expression: `"${step.backend.addr}:${fixture.network.port}"`,
+			References: []hcl.Traversal{
+				{hcl.TraverseRoot{Name: "step"}, hcl.TraverseAttr{Name: "backend"}, hcl.TraverseAttr{Name: "addr"}},
+				{hcl.TraverseRoot{Name: "fixture"}, hcl.TraverseAttr{Name: "network"}, hcl.TraverseAttr{Name: "port"}},
+			},
+		}),
+	}}
+
+	gen, err := NewGenerator(
+		WithScenario(scenario),
+		WithScenarioBaseDirectory(t.TempDir()),
+		WithOutBaseDirectory(t.TempDir()),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	mod, err := os.ReadFile(gen.TerraformModulePath())
+	require.NoError(t, err)
+	require.Contains(t, string(mod), `variable "fixture_network" {`)
+	require.Contains(t, string(mod), `  value = "${module.backend.addr}:${var.fixture_network.port}"`)
+}
+
+// Test_StepVariableExpressions verifies that step variables composed of references to steps and
+// fixtures are generated with references to the modules and fixture variables.
+func Test_StepVariableExpressions(t *testing.T) {
+	t.Parallel()
+
+	scenario := flightplan.NewScenario()
+	scenario.Name = "test"
+	scenario.Fixtures = []string{"network"}
+
+	backend := flightplan.NewScenarioStep()
+	backend.Name = "backend"
+	backend.Module.Source = "hashicorp/backend/aws"
+
+	frontend := flightplan.NewScenarioStep()
+	frontend.Name = "frontend"
+	frontend.Module.Source = "hashicorp/frontend/aws"
+	frontend.Module.Attrs["backends"] = flightplan.StepVariableVal(&flightplan.StepVariable{
+		Expression: `step.backend.enabled ? join(",", step.backend.hosts) : fixture.network.cidr`,
+		References: []hcl.Traversal{
+			{hcl.TraverseRoot{Name: "step"}, hcl.TraverseAttr{Name: "backend"}, hcl.TraverseAttr{Name: "enabled"}},
+			{hcl.TraverseRoot{Name: "step"}, hcl.TraverseAttr{Name: "backend"}, hcl.TraverseAttr{Name: "hosts"}},
+			{hcl.TraverseRoot{Name: "fixture"}, hcl.TraverseAttr{Name: "network"}, hcl.TraverseAttr{Name: "cidr"}},
+		},
+	})
+	scenario.Steps = []*flightplan.ScenarioStep{backend, frontend}
+
+	gen, err := NewGenerator(
+		WithScenario(scenario),
+		WithScenarioBaseDirectory(t.TempDir()),
+		WithOutBaseDirectory(t.TempDir()),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	mod, err := os.ReadFile(gen.TerraformModulePath())
+	require.NoError(t, err)
+	require.Contains(t, string(mod),
+		`  backends = module.backend.enabled ? join(",", module.backend.hosts) : var.fixture_network.cidr`,
+	)
+}
+
+// Test_Tags verifies that tags are added to the default tags of providers that support them along
+// with the run ID and the scenario UID.
+func Test_Tags(t *testing.T) {
+	t.Parallel()
+
+	scenario := flightplan.NewScenario()
+	scenario.Name = "test"
+
+	aws := flightplan.NewProvider()
+	aws.Type = "aws"
+	aws.Alias = "default"
+	aws.Config.Attrs["region"] = cty.StringVal("us-east-1")
+	defaultTags := flightplan.NewSchemalessBlock()
+	defaultTags.Type = "default_tags"
+	defaultTags.Attrs["tags"] = cty.MapVal(map[string]cty.Value{"Project": cty.StringVal("consul")})
+	aws.Config.Children = []*flightplan.SchemalessBlock{defaultTags}
+
+	google := flightplan.NewProvider()
+	google.Type = "google"
+	google.Alias = "default"
+
+	azure := flightplan.NewProvider()
+	azure.Type = "azurerm"
+	azure.Alias = "default"
+
+	scenario.Providers = []*flightplan.Provider{aws, google, azure}
+
+	step := flightplan.NewScenarioStep()
+	step.Name = "target"
+	step.Module.Source = "hashicorp/target/aws"
+	scenario.Steps = []*flightplan.ScenarioStep{step}
+
+	gen, err := NewGenerator(
+		WithScenario(scenario),
+		WithScenarioBaseDirectory(t.TempDir()),
+		WithOutBaseDirectory(t.TempDir()),
+		WithTags(map[string]string{"Project": "vault", "cost-center": "eng"}),
+		WithRunID("1234"),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	mod, err := os.ReadFile(gen.TerraformModulePath())
+	require.NoError(t, err)
+	require.Contains(t, string(mod), `variable "enos_run_id" {
+  description = "The ID of the enos run that last applied the scenario"
+  type        = string
+  default     = "1234"
+}
+
+variable "enos_scenario_uid" {
+  description = "The UID of the enos scenario"
+  type        = string
+  default     = "`+scenario.UID()+`"
+}
+
+locals {
+  enos_tags = {
+    "Project"         = "vault"
+    "cost-center"     = "eng"
+    enos_run_id       = var.enos_run_id
+    enos_scenario_uid = var.enos_scenario_uid
+  }
+}`)
+	require.Contains(t, string(mod), `provider "aws" {
+  region = "us-east-1"
+  default_tags {
+    tags = merge(local.enos_tags, {
+      Project = "consul"
+    })
+  }
+}`)
+	require.Contains(t, string(mod), `provider "google" {
+  default_labels = local.enos_tags
+}`)
+	require.Contains(t, string(mod), `provider "azurerm" {
+}`)
+}
+
+// Test_Generate_Golden tests that generated modules and CLI configuration are formatted and don't
+// depend on the order of map iteration. Set ENOS_UPDATE_GOLDEN=1 to update the golden files after
+// changing the generator.
+func Test_Generate_Golden(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join("testdata", "golden")
+	fp, scenarios := decodeGolden(t, dir)
+
+	generated := func() map[string][]byte {
+		gen, err := NewGenerator(
+			WithScenario(scenarios[0]),
+			WithScenarioBaseDirectory(fp.BaseDir),
+			WithOutBaseDirectory(t.TempDir()),
+			WithRunID("1234"),
+		)
+		require.NoError(t, err)
+		require.NoError(t, gen.Generate())
+
+		files := map[string][]byte{}
+		for name, path := range map[string]string{
+			"scenario.tf":      gen.TerraformModulePath(),
+			"terraform.rc":     gen.TerraformRCPath(),
+			"enos_override.tf": gen.TerraformOverridesPath(),
+		} {
+			files[name], err = os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, string(hclwrite.Format(files[name])), string(files[name]), "%s is not formatted", name)
+		}
+
+		return files
+	}
+
+	files := generated()
+	if os.Getenv("ENOS_UPDATE_GOLDEN") != "" {
+		for name, b := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name+".golden"), b, 0o600))
+		}
+	}
+
+	for range 10 {
+		for name, b := range generated() {
+			golden, err := os.ReadFile(filepath.Join(dir, name+".golden"))
+			require.NoError(t, err, "run the tests with ENOS_UPDATE_GOLDEN=1 to create the golden files")
+			require.Equal(t, string(golden), string(b), "%s does not match its golden file", name)
+		}
+	}
+}
+
+// Test_Generate_DryRun tests that a dry run renders the module in memory without writing anything.
+func Test_Generate_DryRun(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join("testdata", "golden")
+	fp, scenarios := decodeGolden(t, dir)
+
+	gen, err := NewGenerator(
+		WithScenario(scenarios[0]),
+		WithScenarioBaseDirectory(fp.BaseDir),
+		WithOutBaseDirectory(t.TempDir()),
+		WithRunID("1234"),
+		WithDryRun(),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	_, err = os.Stat(gen.TerraformModuleDir())
+	require.True(t, os.IsNotExist(err), "dry run created the module directory")
+
+	require.Len(t, gen.Files, 4)
+	require.Contains(t, gen.Files, gen.TerraformMetadataPath())
+	for name, path := range map[string]string{
+		"scenario.tf":      gen.TerraformModulePath(),
+		"terraform.rc":     gen.TerraformRCPath(),
+		"enos_override.tf": gen.TerraformOverridesPath(),
+	} {
+		golden, err := os.ReadFile(filepath.Join(dir, name+".golden"))
+		require.NoError(t, err)
+		require.Equal(t, string(golden), string(gen.Files[path]), "%s does not match its golden file", name)
+	}
+}
+
+// Test_Generate_Overrides_Removed tests that the override file is removed when the scenario no
+// longer has overrides.
+func Test_Generate_Overrides_Removed(t *testing.T) {
+	t.Parallel()
+
+	fp, scenarios := decodeGolden(t, filepath.Join("testdata", "golden"))
+	out := t.TempDir()
+
+	generate := func() *Generator {
+		gen, err := NewGenerator(
+			WithScenario(scenarios[0]),
+			WithScenarioBaseDirectory(fp.BaseDir),
+			WithOutBaseDirectory(out),
+		)
+		require.NoError(t, err)
+		require.NoError(t, gen.Generate())
+
+		return gen
+	}
+
+	require.FileExists(t, generate().TerraformOverridesPath())
+
+	scenarios[0].Overrides = nil
+	require.NoFileExists(t, generate().TerraformOverridesPath())
+}
+
+// decodeGolden decodes the flight plan of the golden tests in the directory.
+func decodeGolden(t *testing.T, dir string) (*flightplan.FlightPlan, []*flightplan.Scenario) {
+	t.Helper()
+
+	src, err := os.ReadFile(filepath.Join(dir, "enos.hcl"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	fp, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		&pb.FlightPlan{
+			BaseDir: t.TempDir(),
+			EnosHcl: map[string][]byte{"enos.hcl": src},
+		},
+		flightplan.DecodeTargetAll,
+		nil,
+	)
+	require.Empty(t, decRes.GetDiagnostics())
+	require.Empty(t, scenarioDecoder.DecodeAll(ctx, fp))
+	scenarios := fp.Scenarios()
+	require.Len(t, scenarios, 1)
+
+	return fp, scenarios
+}
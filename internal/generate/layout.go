@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ModuleDir returns the directory in the out directory that the module of the scenario is
+// generated into with the layout. Without a naming the directory is named after the scenario slug,
+// unless a module has already been generated into a directory named after the scenario UID by a
+// prior version, so that we don't lose track of any existing Terraform state.
+func ModuleDir(outDir string, scenario *flightplan.Scenario, layout *pb.Layout) string {
+	base := outDir
+	if layout.GetNested() {
+		base = filepath.Join(outDir, scenario.Name)
+	}
+
+	switch layout.GetNaming() {
+	case pb.Layout_NAMING_SLUG:
+		return filepath.Join(base, scenario.Slug())
+	case pb.Layout_NAMING_UID:
+		return filepath.Join(base, scenario.UID())
+	case pb.Layout_NAMING_UNSPECIFIED:
+	}
+
+	dir := filepath.Join(base, scenario.Slug())
+	if _, err := os.Stat(dir); err == nil {
+		return dir
+	}
+
+	legacyDir := filepath.Join(base, scenario.UID())
+	if info, err := os.Stat(legacyDir); err == nil && info.IsDir() {
+		return legacyDir
+	}
+
+	return dir
+}
+
+// MigrateModuleDir moves the module of the scenario that has been generated with any other layout
+// into the directory of the layout. It returns the directory that the module was in and the
+// directory of the layout. If no module has been generated with another layout there is nothing to
+// move and from is empty. With dry run nothing is moved. It is an error for the module to exist in
+// more than one directory as we can't know which has the Terraform state of the scenario.
+func MigrateModuleDir(
+	outDir string,
+	scenario *flightplan.Scenario,
+	layout *pb.Layout,
+	dryRun bool,
+) (string, string, error) {
+	to := ModuleDir(outDir, scenario, layout)
+
+	existing := []string{}
+	if info, err := os.Stat(to); err == nil && info.IsDir() {
+		existing = append(existing, to)
+	}
+
+	from := ""
+	for _, nested := range []bool{false, true} {
+		for _, naming := range []pb.Layout_Naming{pb.Layout_NAMING_SLUG, pb.Layout_NAMING_UID} {
+			dir := ModuleDir(outDir, scenario, &pb.Layout{Naming: naming, Nested: nested})
+			if dir == to {
+				continue
+			}
+
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				existing = append(existing, dir)
+				from = dir
+			}
+		}
+	}
+
+	switch {
+	case from == "":
+		return "", to, nil
+	case len(existing) > 1:
+		return "", to, fmt.Errorf(
+			"unable to migrate the module of scenario %s as it has been generated into more than one directory: %v",
+			scenario.String(), existing,
+		)
+	case dryRun:
+		return from, to, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return "", to, err
+	}
+
+	if err := os.Rename(from, to); err != nil {
+		return "", to, err
+	}
+
+	// Remove the directory of the scenario if we've moved the last module out of it
+	if parent := filepath.Dir(from); parent != filepath.Clean(outDir) {
+		if entries, err := os.ReadDir(parent); err == nil && len(entries) == 0 {
+			_ = os.Remove(parent)
+		}
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+func testLayoutScenario() *flightplan.Scenario {
+	sc := flightplan.NewScenario()
+	sc.Name = "test"
+	sc.Variants = flightplan.NewVector(flightplan.NewElement("backend", "raft"))
+
+	return sc
+}
+
+// Test_ModuleDir tests the directories of generated modules with layouts.
+func Test_ModuleDir(t *testing.T) {
+	t.Parallel()
+
+	sc := testLayoutScenario()
+	out := t.TempDir()
+
+	require.Equal(t, filepath.Join(out, sc.Slug()), ModuleDir(out, sc, nil))
+	require.Equal(t, filepath.Join(out, sc.UID()), ModuleDir(out, sc, &pb.Layout{Naming: pb.Layout_NAMING_UID}))
+	require.Equal(t, filepath.Join(out, "test", sc.Slug()), ModuleDir(out, sc, &pb.Layout{Nested: true}))
+	require.Equal(t, filepath.Join(out, "test", sc.UID()), ModuleDir(out, sc, &pb.Layout{
+		Naming: pb.Layout_NAMING_UID,
+		Nested: true,
+	}))
+
+	// Without a naming existing modules in UID directories continue to be used
+	require.NoError(t, os.Mkdir(filepath.Join(out, sc.UID()), 0o755))
+	require.Equal(t, filepath.Join(out, sc.UID()), ModuleDir(out, sc, nil))
+	require.Equal(t, filepath.Join(out, sc.Slug()), ModuleDir(out, sc, &pb.Layout{Naming: pb.Layout_NAMING_SLUG}))
+}
+
+// Test_MigrateModuleDir tests moving generated modules into the directories of layouts.
+func Test_MigrateModuleDir(t *testing.T) {
+	t.Parallel()
+
+	sc := testLayoutScenario()
+	out := t.TempDir()
+	legacy := filepath.Join(out, sc.UID())
+	require.NoError(t, os.Mkdir(legacy, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "terraform.tfstate"), []byte("{}"), 0o600))
+
+	nested := &pb.Layout{Naming: pb.Layout_NAMING_SLUG, Nested: true}
+	to := filepath.Join(out, "test", sc.Slug())
+
+	// Dry runs don't move anything
+	from, got, err := MigrateModuleDir(out, sc, nested, true)
+	require.NoError(t, err)
+	require.Equal(t, legacy, from)
+	require.Equal(t, to, got)
+	require.DirExists(t, legacy)
+	require.NoDirExists(t, to)
+
+	from, got, err = MigrateModuleDir(out, sc, nested, false)
+	require.NoError(t, err)
+	require.Equal(t, legacy, from)
+	require.Equal(t, to, got)
+	require.NoDirExists(t, legacy)
+	require.FileExists(t, filepath.Join(to, "terraform.tfstate"))
+
+	// Migrating again has nothing to move
+	from, _, err = MigrateModuleDir(out, sc, nested, false)
+	require.NoError(t, err)
+	require.Empty(t, from)
+
+	// Back to the flat UID layout, which removes the empty scenario directory
+	from, got, err = MigrateModuleDir(out, sc, &pb.Layout{Naming: pb.Layout_NAMING_UID}, false)
+	require.NoError(t, err)
+	require.Equal(t, to, from)
+	require.Equal(t, legacy, got)
+	require.NoDirExists(t, filepath.Join(out, "test"))
+
+	// Modules in more than one directory are ambiguous
+	require.NoError(t, os.Mkdir(filepath.Join(out, sc.Slug()), 0o755))
+	_, _, err = MigrateModuleDir(out, sc, nested, false)
+	require.ErrorContains(t, err, "more than one directory")
+}
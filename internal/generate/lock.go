@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// LockFileName is the name of the Terraform dependency lock file of a module.
+const LockFileName = ".terraform.lock.hcl"
+
+var lockFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "provider", LabelNames: []string{"source"}},
+	},
+}
+
+// LockedProvider is a provider of a dependency lock file.
+type LockedProvider struct {
+	Source      string
+	Version     string   `hcl:"version,optional"`
+	Constraints string   `hcl:"constraints,optional"`
+	Hashes      []string `hcl:"hashes,optional"`
+}
+
+// ReadLockFile returns the providers of the dependency lock file at the path.
+func ReadLockFile(path string) ([]*LockedProvider, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parsing lock file: %s", diags.Error())
+	}
+
+	content, diags := file.Body.Content(lockFileSchema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parsing lock file: %s", diags.Error())
+	}
+
+	providers := []*LockedProvider{}
+	for _, block := range content.Blocks {
+		provider := &LockedProvider{Source: block.Labels[0]}
+		if diags := gohcl.DecodeBody(block.Body, nil, provider); diags.HasErrors() {
+			return nil, fmt.Errorf("parsing lock file: %s", diags.Error())
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
+
+// VerifyLockFile verifies that every provider of the dependency lock file at the path has been
+// locked for all of the platforms. Terraform records an h1 hash of the package of every platform
+// that a provider has been locked for, so a provider with fewer h1 hashes than platforms has not
+// been locked for some of them.
+func VerifyLockFile(path string, platforms []string) error {
+	providers, err := ReadLockFile(path)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, provider := range providers {
+		h1 := 0
+		for _, hash := range provider.Hashes {
+			if strings.HasPrefix(hash, "h1:") {
+				h1++
+			}
+		}
+
+		if h1 < len(platforms) {
+			errs = errors.Join(errs, fmt.Errorf(
+				"provider %s %s has %d h1 hashes but must be locked for %d platforms: %s",
+				provider.Source, provider.Version, h1, len(platforms), strings.Join(platforms, ", "),
+			))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testLockFile = `# This file is maintained automatically by "terraform init".
+# Manual edits may be lost in future updates.
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = ">= 5.0.0"
+  hashes = [
+    "h1:ltxyuBWIy9cq0kIKDJH1jeWJy/y7XJLjS4QrsQK4plA=",
+    "h1:Yp7IGqZpxfqlrAb/nEd5ZuKVN7Ej4T0ATalIYLsuG2k=",
+    "zh:0cdb9c2083bf0902442384f7309367791e4640581652dda456f2d6d7abf0de8d",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/random" {
+  version = "3.6.0"
+  hashes = [
+    "h1:R5Ucn26riKIEijcsiOMBR3uOAjuOMfI1x7XvH4P6B1w=",
+    "zh:03360ed3ecd31e8c5dac9c95fe0858be50f3e9a0d0c654b5e504109c2159287d",
+  ]
+}
+`
+
+// Test_VerifyLockFile tests verifying that providers have been locked for all platforms.
+func Test_VerifyLockFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), LockFileName)
+	require.NoError(t, os.WriteFile(path, []byte(testLockFile), 0o600))
+
+	providers, err := ReadLockFile(path)
+	require.NoError(t, err)
+	require.Len(t, providers, 2)
+	require.Equal(t, "registry.terraform.io/hashicorp/aws", providers[0].Source)
+	require.Equal(t, "5.31.0", providers[0].Version)
+	require.Equal(t, ">= 5.0.0", providers[0].Constraints)
+	require.Len(t, providers[0].Hashes, 3)
+
+	require.NoError(t, VerifyLockFile(path, nil))
+	require.NoError(t, VerifyLockFile(path, []string{"linux_amd64"}))
+
+	err = VerifyLockFile(path, []string{"linux_amd64", "darwin_arm64"})
+	require.ErrorContains(t, err, "provider registry.terraform.io/hashicorp/random 3.6.0 has 1 h1 hashes")
+	require.NotContains(t, err.Error(), "hashicorp/aws")
+
+	_, err = ReadLockFile(filepath.Join(t.TempDir(), LockFileName))
+	require.Error(t, err)
+}
+
+// Test_Generate_LockFile tests seeding modules with the project lock file.
+func Test_Generate_LockFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	project := filepath.Join(dir, LockFileName)
+	require.NoError(t, os.WriteFile(project, []byte(testLockFile), 0o600))
+
+	gen, err := NewGenerator(
+		WithScenario(testLayoutScenario()),
+		WithScenarioBaseDirectory(dir),
+		WithOutBaseDirectory(filepath.Join(dir, ".enos")),
+		WithLockFile(project),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	lock, err := os.ReadFile(gen.TerraformLockFilePath())
+	require.NoError(t, err)
+	require.Equal(t, testLockFile, string(lock))
+
+	// Lock files that exist are maintained by Terraform and never changed
+	require.NoError(t, os.WriteFile(gen.TerraformLockFilePath(), []byte("# init\n"), 0o600))
+	require.NoError(t, gen.Generate())
+	lock, err = os.ReadFile(gen.TerraformLockFilePath())
+	require.NoError(t, err)
+	require.Equal(t, "# init\n", string(lock))
+}
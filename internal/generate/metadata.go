@@ -0,0 +1,222 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/enos/version"
+)
+
+// MetadataFileName is the name of the file that generated module directories are stamped with.
+const MetadataFileName = "enos.metadata.json"
+
+// OutDirFormatVersion is the version of the format of generated module directories. Whenever a
+// change to enos requires existing module directories to be changed it must be incremented and the
+// migration from the prior version added to outDirMigrations. Module directories that were
+// generated before they were stamped have a format version of zero.
+const OutDirFormatVersion = 1
+
+// outDirMigrations are the migrations of module directories, keyed by the format version that they
+// migrate from. Each migration migrates the directory to the next format version.
+var outDirMigrations = map[int]func(dir string) error{
+	// Stamping the directory with the metadata is the only change of the first format version
+	0: func(string) error { return nil },
+}
+
+// Metadata is the metadata of a generated module directory.
+type Metadata struct {
+	FormatVersion  int              `json:"format_version"`
+	EnosVersion    string           `json:"enos_version"`
+	FlightPlanHash string           `json:"flight_plan_hash,omitempty"`
+	Scenario       MetadataScenario `json:"scenario"`
+}
+
+// MetadataScenario is the scenario that the module directory has been generated for.
+type MetadataScenario struct {
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+	UID    string `json:"uid"`
+}
+
+// OutDirMigration is the migration of the module directory of a scenario.
+type OutDirMigration struct {
+	// From is the directory that the module was in if it was moved into the layout, otherwise
+	// it is the same as To.
+	From        string
+	To          string
+	FromVersion int
+	ToVersion   int
+}
+
+// NewMetadata returns the metadata of a module directory generated for the scenario by the
+// running version of enos.
+func NewMetadata(scenario *flightplan.Scenario, flightPlanHash string) *Metadata {
+	return &Metadata{
+		FormatVersion:  OutDirFormatVersion,
+		EnosVersion:    version.GetHumanVersion(),
+		FlightPlanHash: flightPlanHash,
+		Scenario: MetadataScenario{
+			Name:   scenario.Name,
+			Filter: scenario.FilterStr(),
+			UID:    scenario.UID(),
+		},
+	}
+}
+
+// ReadMetadata reads the metadata of the module directory. Directories that were generated before
+// they were stamped have no metadata and nil is returned.
+func ReadMetadata(dir string) (*Metadata, error) {
+	bytes, err := os.ReadFile(filepath.Join(dir, MetadataFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	meta := &Metadata{}
+	if err := json.Unmarshal(bytes, meta); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, MetadataFileName), err)
+	}
+
+	return meta, nil
+}
+
+// checkMetadataVersion returns an error if the module directory has been generated with a newer
+// format than this version of enos supports, as we can't know what it would break.
+func checkMetadataVersion(dir string, meta *Metadata) error {
+	if meta == nil || meta.FormatVersion <= OutDirFormatVersion {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"the module in %s has been generated by enos %s with out directory format version %d but this version of enos only supports up to version %d, upgrade enos to use it",
+		dir, meta.EnosVersion, meta.FormatVersion, OutDirFormatVersion,
+	)
+}
+
+// MigrateOutDir migrates the module directory of the scenario to the current format version. The
+// module is moved into the directory of the layout, any migrations for the format version that it
+// has been stamped with are applied, and it is stamped with the current metadata. If no module has
+// been generated for the scenario, or it is already in the current format and layout, there is
+// nothing to migrate and nil is returned. With dry run nothing is changed.
+func MigrateOutDir(
+	outDir string,
+	scenario *flightplan.Scenario,
+	layout *pb.Layout,
+	flightPlanHash string,
+	dryRun bool,
+) (*OutDirMigration, error) {
+	from, to, err := MigrateModuleDir(outDir, scenario, layout, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := to
+	if from == "" {
+		from = to
+	} else if dryRun {
+		dir = from
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+
+	meta, err := ReadMetadata(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMetadataVersion(dir, meta); err != nil {
+		return nil, err
+	}
+
+	migration := &OutDirMigration{From: from, To: to, ToVersion: OutDirFormatVersion}
+	if meta != nil {
+		migration.FromVersion = meta.FormatVersion
+	}
+
+	if from == to && migration.FromVersion == OutDirFormatVersion {
+		return nil, nil
+	}
+
+	if dryRun {
+		return migration, nil
+	}
+
+	for v := migration.FromVersion; v < OutDirFormatVersion; v++ {
+		migrate, ok := outDirMigrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration from out directory format version %d", v)
+		}
+
+		if err := migrate(dir); err != nil {
+			return nil, fmt.Errorf("migrating %s from out directory format version %d: %w", dir, v, err)
+		}
+	}
+
+	if err := writeMetadata(dir, NewMetadata(scenario, flightPlanHash)); err != nil {
+		return nil, err
+	}
+
+	return migration, nil
+}
+
+func marshalMetadata(meta *Metadata) ([]byte, error) {
+	bytes, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bytes, '\n'), nil
+}
+
+func writeMetadata(dir string, meta *Metadata) error {
+	bytes, err := marshalMetadata(meta)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, MetadataFileName), bytes, 0o600)
+}
+
+// checkMetadata returns an error if the module directory has been stamped by a newer format version
+// of enos, in which case we must not generate into it.
+func (g *Generator) checkMetadata() error {
+	meta, err := ReadMetadata(g.TerraformModuleDir())
+	if err != nil {
+		return err
+	}
+
+	return checkMetadataVersion(g.TerraformModuleDir(), meta)
+}
+
+// writeMetadata stamps the module directory with the metadata of the module.
+func (g *Generator) writeMetadata() error {
+	bytes, err := marshalMetadata(NewMetadata(g.Scenario, g.FlightPlanHash))
+	if err != nil {
+		return err
+	}
+
+	if g.DryRun {
+		g.Files[g.TerraformMetadataPath()] = bytes
+
+		return nil
+	}
+
+	if g.UI != nil {
+		g.UI.Info("writing to " + g.TerraformMetadataPath())
+	}
+
+	return os.WriteFile(g.TerraformMetadataPath(), bytes, 0o600)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/enos/version"
+)
+
+// Test_Generate_Metadata tests stamping generated modules with metadata.
+func Test_Generate_Metadata(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	sc := testLayoutScenario()
+	gen, err := NewGenerator(
+		WithScenario(sc),
+		WithScenarioBaseDirectory(dir),
+		WithOutBaseDirectory(filepath.Join(dir, ".enos")),
+		WithFlightPlanHash("1234"),
+	)
+	require.NoError(t, err)
+	require.NoError(t, gen.Generate())
+
+	meta, err := ReadMetadata(gen.TerraformModuleDir())
+	require.NoError(t, err)
+	require.Equal(t, &Metadata{
+		FormatVersion:  OutDirFormatVersion,
+		EnosVersion:    version.GetHumanVersion(),
+		FlightPlanHash: "1234",
+		Scenario: MetadataScenario{
+			Name:   "test",
+			Filter: "test backend:raft",
+			UID:    sc.UID(),
+		},
+	}, meta)
+
+	// Modules that were generated by a newer format version are never generated into
+	require.NoError(t, os.WriteFile(gen.TerraformMetadataPath(), []byte(`{"format_version":99,"enos_version":"9.9.9"}`), 0o600))
+	require.ErrorContains(t, gen.Generate(), "generated by enos 9.9.9 with out directory format version 99")
+}
+
+// Test_MigrateOutDir tests migrating modules that were generated by prior versions of enos.
+func Test_MigrateOutDir(t *testing.T) {
+	t.Parallel()
+
+	sc := testLayoutScenario()
+	out := t.TempDir()
+	layout := &pb.Layout{Nested: true}
+
+	// Nothing has been generated
+	migration, err := MigrateOutDir(out, sc, layout, "1234", false)
+	require.NoError(t, err)
+	require.Nil(t, migration)
+
+	// Modules that were generated by prior versions are in UID directories without metadata
+	legacy := filepath.Join(out, sc.UID())
+	require.NoError(t, os.MkdirAll(legacy, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "terraform.tfstate"), []byte("{}"), 0o600))
+
+	to := filepath.Join(out, "test", sc.Slug())
+	migration, err = MigrateOutDir(out, sc, layout, "1234", true)
+	require.NoError(t, err)
+	require.Equal(t, &OutDirMigration{From: legacy, To: to, FromVersion: 0, ToVersion: OutDirFormatVersion}, migration)
+	require.DirExists(t, legacy)
+	require.NoFileExists(t, filepath.Join(legacy, MetadataFileName))
+
+	migration, err = MigrateOutDir(out, sc, layout, "1234", false)
+	require.NoError(t, err)
+	require.Equal(t, &OutDirMigration{From: legacy, To: to, FromVersion: 0, ToVersion: OutDirFormatVersion}, migration)
+	require.NoDirExists(t, legacy)
+	require.FileExists(t, filepath.Join(to, "terraform.tfstate"))
+	meta, err := ReadMetadata(to)
+	require.NoError(t, err)
+	require.Equal(t, OutDirFormatVersion, meta.FormatVersion)
+	require.Equal(t, sc.UID(), meta.Scenario.UID)
+
+	// Migrated modules are already in the current format
+	migration, err = MigrateOutDir(out, sc, layout, "1234", false)
+	require.NoError(t, err)
+	require.Nil(t, migration)
+
+	// Modules in newer formats can't be migrated
+	require.NoError(t, os.WriteFile(filepath.Join(to, MetadataFileName), []byte(`{"format_version":99}`), 0o600))
+	_, err = MigrateOutDir(out, sc, layout, "1234", false)
+	require.ErrorContains(t, err, "only supports up to version")
+}
@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/provisioner"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// ProvisionerModuleDir is the directory where the generated module of the provisioner step will be
+// written.
+func (g *Generator) ProvisionerModuleDir(step string) string {
+	return filepath.Join(g.TerraformModuleDir(), "provisioners", step)
+}
+
+// generateProvisionerModule generates the Terraform module of a provisioner step and returns its
+// source. The module executes "enos provisioner" with the request of the step when its
+// terraform_data resource is created or destroyed, which happens whenever the request changes,
+// and reads the outputs of the provisioner from the outputs state file that it writes.
+func (g *Generator) generateProvisionerModule(step *flightplan.ScenarioStep) (string, error) {
+	bin := g.EnosBinary
+	if bin == "" {
+		var err error
+		bin, err = os.Executable()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	dir, err := g.ensureDir(g.ProvisionerModuleDir(step.Name))
+	if err != nil {
+		return "", err
+	}
+
+	names := sortedKeys(step.Provisioner.Attrs)
+
+	mod := hclwrite.NewEmptyFile()
+	body := mod.Body()
+
+	// A variable for each variable of the step
+	vars := []hclwrite.ObjectAttrTokens{}
+	for _, name := range names {
+		varBody := body.AppendNewBlock("variable", []string{name}).Body()
+		varBody.SetAttributeRaw("type", hclwrite.TokensForIdentifier("any"))
+		varBody.SetAttributeValue("default", cty.NullVal(cty.DynamicPseudoType))
+		body.AppendNewline()
+
+		vars = append(vars, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForIdentifier(name),
+			Value: hclwrite.TokensForTraversal(traversal("var", name)),
+		})
+	}
+
+	// The request of the step
+	localsBody := body.AppendNewBlock("locals", nil).Body()
+	localsBody.SetAttributeRaw("request", hclwrite.TokensForFunctionCall("jsonencode",
+		hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
+			{Name: hclwrite.TokensForIdentifier("step"), Value: hclwrite.TokensForValue(cty.StringVal(step.Name))},
+			{Name: hclwrite.TokensForIdentifier("provisioner"), Value: hclwrite.TokensForValue(cty.StringVal(step.Provisioner.Type))},
+			{Name: hclwrite.TokensForIdentifier("base_dir"), Value: hclwrite.TokensForValue(cty.StringVal(g.BaseDir))},
+			{Name: hclwrite.TokensForIdentifier("variables"), Value: hclwrite.TokensForObject(vars)},
+		}),
+	))
+	body.AppendNewline()
+
+	// The resource that executes the provisioner
+	resBody := body.AppendNewBlock("resource", []string{"terraform_data", "provisioner"}).Body()
+	resBody.SetAttributeTraversal("input", traversal("local", "request"))
+	resBody.SetAttributeTraversal("triggers_replace", traversal("local", "request"))
+	for _, op := range []string{provisioner.OperationApply, provisioner.OperationDestroy} {
+		resBody.AppendNewline()
+		execBody := resBody.AppendNewBlock("provisioner", []string{"local-exec"}).Body()
+		if op == provisioner.OperationDestroy {
+			execBody.SetAttributeRaw("when", hclwrite.TokensForIdentifier("destroy"))
+		}
+		execBody.SetAttributeValue("interpreter", cty.ListVal([]cty.Value{
+			cty.StringVal(bin), cty.StringVal("provisioner"), cty.StringVal(op),
+		}))
+		execBody.SetAttributeTraversal("command", traversal("path", "module"))
+		execBody.SetAttributeRaw("environment", hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{{
+			Name:  hclwrite.TokensForIdentifier(provisioner.EnvRequest),
+			Value: hclwrite.TokensForTraversal(traversal("self", "input")),
+		}}))
+	}
+
+	// The outputs of the provisioner
+	if len(step.Provisioner.Outputs) > 0 {
+		body.AppendNewline()
+
+		defaults := []hclwrite.ObjectAttrTokens{}
+		for _, out := range step.Provisioner.Outputs {
+			defaults = append(defaults, hclwrite.ObjectAttrTokens{
+				Name:  hclwrite.TokensForIdentifier(out),
+				Value: hclwrite.TokensForValue(cty.NullVal(cty.DynamicPseudoType)),
+			})
+		}
+
+		dataBody := body.AppendNewBlock("data", []string{"terraform_remote_state", "outputs"}).Body()
+		dataBody.SetAttributeValue("backend", cty.StringVal("local"))
+		dataBody.SetAttributeRaw("config", hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{{
+			Name:  hclwrite.TokensForIdentifier("path"),
+			Value: outputsStatePathTokens(),
+		}}))
+		dataBody.SetAttributeRaw("defaults", hclwrite.TokensForObject(defaults))
+		dataBody.SetAttributeRaw("depends_on", hclwrite.TokensForTuple([]hclwrite.Tokens{
+			hclwrite.TokensForTraversal(traversal("terraform_data", "provisioner")),
+		}))
+
+		for _, out := range step.Provisioner.Outputs {
+			body.AppendNewline()
+			outBody := body.AppendNewBlock("output", []string{out}).Body()
+			outBody.SetAttributeTraversal("value", traversal("data", "terraform_remote_state", "outputs", "outputs", out))
+		}
+	}
+
+	err = g.write(filepath.Join(dir, "main.tf"), mod.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	return "./" + filepath.ToSlash(filepath.Join("provisioners", step.Name)), nil
+}
+
+// traversal returns the traversal of the root and attribute names.
+func traversal(root string, attrs ...string) hcl.Traversal {
+	t := hcl.Traversal{hcl.TraverseRoot{Name: root}}
+	for _, attr := range attrs {
+		t = append(t, hcl.TraverseAttr{Name: attr})
+	}
+
+	return t
+}
+
+// outputsStatePathTokens returns the tokens of the path to the outputs state file of the module. We
+// do this manually because hclwrite does not include a helper for writing templates.
+func outputsStatePathTokens() hclwrite.Tokens {
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
+		{Type: hclsyntax.TokenTemplateInterp, Bytes: []byte("${")},
+	}
+	tokens = append(tokens, hclwrite.TokensForTraversal(traversal("path", "module"))...)
+
+	return append(tokens,
+		&hclwrite.Token{Type: hclsyntax.TokenTemplateSeqEnd, Bytes: []byte("}")},
+		&hclwrite.Token{Type: hclsyntax.TokenQuotedLit, Bytes: []byte("/" + provisioner.OutputsStateFile)},
+		&hclwrite.Token{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	)
+}
@@ -0,0 +1,305 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ReuseDirName is the name of the directory in the out directory that the providers and modules
+// of initialized modules are kept in for reuse.
+const ReuseDirName = ".terraform-cache"
+
+// reusedDirs are the directories of the .terraform directory that are reused. Everything else,
+// e.g. the backend configuration, is specific to the module.
+var reusedDirs = []string{"providers", "modules"}
+
+// reusedLinkDepths are the depths of the directories of the reused directories that are symlinked,
+// i.e. of the provider packages, e.g. registry.terraform.io/hashicorp/aws/5.0.0/linux_amd64, and
+// of the installed modules.
+var reusedLinkDepths = map[string]int{"providers": 5, "modules": 1}
+
+// ReuseKey returns the key of the initialized .terraform directories that the module in the
+// directory can reuse, which is the hash of its lock file and module calls. Modules that don't have
+// a lock file can't reuse anything and the key is empty.
+func ReuseKey(moduleDir string) (string, error) {
+	lock, err := os.ReadFile(filepath.Join(moduleDir, LockFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	path := filepath.Join(moduleDir, "scenario.tf")
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", fmt.Errorf("parsing generated module: %s", diags.Error())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return "", fmt.Errorf("parsing generated module: unexpected body type %T", file.Body)
+	}
+
+	// The module calls are keyed by their name in the modules manifest so we key them by their
+	// name, source, and version. Sources are relative to the module directory.
+	calls := []string{}
+	for _, block := range body.Blocks {
+		if block.Type != "module" || len(block.Labels) != 1 {
+			continue
+		}
+
+		call := block.Labels[0]
+		for _, name := range []string{"source", "version"} {
+			if attr, ok := block.Body.Attributes[name]; ok {
+				call += "\x00" + string(attr.Expr.Range().SliceBytes(src))
+			}
+		}
+		calls = append(calls, call)
+	}
+	slices.Sort(calls)
+
+	h := sha256.New()
+	h.Write(lock)
+	for _, call := range calls {
+		h.Write([]byte("\x00" + call))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReuseTerraformDir reuses the providers and modules that have been kept for the key in the cache
+// directory in the .terraform directory of the module. They're either symlinked or copied
+// depending on the mode. Directories that the module already has are never replaced. It returns
+// whether anything has been reused.
+//
+// Symlinked providers and modules have their own directories in the module that only link the
+// provider packages and installed modules of the cache, like the providers of a Terraform plugin
+// cache. Terraform replaces the links rather than writing through them when it installs a package
+// or module again, e.g. with init -upgrade, so the cache is never written to by concurrent inits.
+func ReuseTerraformDir(
+	cacheDir string,
+	key string,
+	moduleDir string,
+	mode pb.Terraform_Runner_Config_Reuse_Mode,
+) (bool, error) {
+	if key == "" || mode == pb.Terraform_Runner_Config_Reuse_MODE_UNSPECIFIED {
+		return false, nil
+	}
+
+	src := filepath.Join(cacheDir, key)
+	if _, err := os.Stat(src); err != nil {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(moduleDir, ".terraform"), 0o755); err != nil {
+		return false, err
+	}
+
+	reused := false
+	for _, name := range reusedDirs {
+		from := filepath.Join(src, name)
+		to := filepath.Join(moduleDir, ".terraform", name)
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(to); err == nil {
+			continue
+		}
+
+		var err error
+		if mode == pb.Terraform_Runner_Config_Reuse_MODE_SYMLINK {
+			err = linkDir(from, to, reusedLinkDepths[name])
+		} else {
+			err = copyDir(from, to)
+		}
+		if err != nil {
+			return reused, fmt.Errorf("reusing %s: %w", to, err)
+		}
+		reused = true
+	}
+
+	return reused, nil
+}
+
+// WarmTerraformDir keeps the providers and modules of the .terraform directory of an initialized
+// module in the cache directory for the key so that other modules can reuse them. Nothing is kept
+// if the cache already has them. As modules are initialized concurrently they're copied into a
+// temporary directory that is renamed into place.
+func WarmTerraformDir(cacheDir string, key string, moduleDir string) error {
+	if key == "" {
+		return nil
+	}
+
+	dst := filepath.Join(cacheDir, key)
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp(cacheDir, "."+key+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// Temporary directories are only accessible by the owner
+	if err := os.Chmod(tmp, 0o755); err != nil {
+		return err
+	}
+
+	for _, name := range reusedDirs {
+		from := filepath.Join(moduleDir, ".terraform", name)
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+
+		if err := copyDir(from, filepath.Join(tmp, name)); err != nil {
+			return fmt.Errorf("warming %s: %w", dst, err)
+		}
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		// Another module with the same key has already warmed it
+		if _, statErr := os.Stat(dst); statErr == nil {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// copyDir recursively copies the directory, following any symlinks of the source directory
+// itself but preserving any symlinks within it, e.g. of providers in a plugin cache.
+func copyDir(from, to string) error {
+	from, err := filepath.EvalSymlinks(from)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(to, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(dst, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(target, dst)
+		default:
+			return copyFile(path, dst, info.Mode().Perm())
+		}
+	})
+}
+
+// linkDir recursively recreates the directories of the directory down to the depth and symlinks
+// the directories at the depth. Files are always copied, e.g. the modules manifest that init
+// rewrites, so that writing them never writes to the source directory.
+func linkDir(from, to string, depth int) error {
+	root, err := filepath.EvalSymlinks(from)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(to, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir() && rel != "." && len(strings.Split(rel, string(filepath.Separator))) >= depth:
+			if err := os.Symlink(filepath.Join(from, rel), dst); err != nil {
+				return err
+			}
+
+			return fs.SkipDir
+		case d.IsDir():
+			return os.MkdirAll(dst, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(target, dst)
+		default:
+			return copyFile(path, dst, info.Mode().Perm())
+		}
+	})
+}
+
+func copyFile(from, to string, perm fs.FileMode) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+
+		return err
+	}
+
+	return dst.Close()
+}
@@ -0,0 +1,162 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// writeTestReuseModule writes a module with the lock file and module calls into the directory.
+func writeTestReuseModule(t *testing.T, dir string, lock string, module string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scenario.tf"), []byte(module), 0o600))
+	if lock != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, LockFileName), []byte(lock), 0o600))
+	}
+}
+
+// Test_ReuseKey tests that modules have the same key when their lock files and module calls are
+// identical.
+func Test_ReuseKey(t *testing.T) {
+	t.Parallel()
+
+	out := t.TempDir()
+	module := `
+module "target" {
+  source  = "app.terraform.io/hashicorp/target/aws"
+  version = "1.0.0"
+
+  instance_type = "t3.small"
+}
+
+module "vault" {
+  source = "../../modules/vault"
+}
+`
+	writeTestReuseModule(t, filepath.Join(out, "a"), testLockFile, module)
+	key, err := ReuseKey(filepath.Join(out, "a"))
+	require.NoError(t, err)
+	require.NotEmpty(t, key)
+
+	// Variables and the order of module calls don't matter
+	writeTestReuseModule(t, filepath.Join(out, "b"), testLockFile, `
+module "vault" {
+  source = "../../modules/vault"
+}
+
+module "target" {
+  source  = "app.terraform.io/hashicorp/target/aws"
+  version = "1.0.0"
+
+  instance_type = "t3.large"
+}
+`)
+	same, err := ReuseKey(filepath.Join(out, "b"))
+	require.NoError(t, err)
+	require.Equal(t, key, same)
+
+	for desc, dir := range map[string]struct {
+		lock   string
+		module string
+	}{
+		"lock file":      {lock: testLockFile + "\n# changed\n", module: module},
+		"module version": {lock: testLockFile, module: `module "target" { source = "app.terraform.io/hashicorp/target/aws" }`},
+		"module name":    {lock: testLockFile, module: `module "vault_cluster" { source = "../../modules/vault" }`},
+	} {
+		writeTestReuseModule(t, filepath.Join(out, desc), dir.lock, dir.module)
+		other, err := ReuseKey(filepath.Join(out, desc))
+		require.NoError(t, err)
+		require.NotEqual(t, key, other, desc)
+	}
+
+	// Modules without a lock file can't reuse anything
+	writeTestReuseModule(t, filepath.Join(out, "unlocked"), "", module)
+	key, err = ReuseKey(filepath.Join(out, "unlocked"))
+	require.NoError(t, err)
+	require.Empty(t, key)
+}
+
+// Test_ReuseTerraformDir tests warming the cache and reusing the providers and modules of it.
+func Test_ReuseTerraformDir(t *testing.T) {
+	t.Parallel()
+
+	out := t.TempDir()
+	cache := filepath.Join(out, ReuseDirName)
+	warmed := filepath.Join(out, "warmed")
+	pkg := filepath.Join(".terraform", "providers", "registry.terraform.io", "hashicorp", "aws", "5.0.0", "linux_amd64")
+	provider := filepath.Join(pkg, "terraform-provider-aws")
+	require.NoError(t, os.MkdirAll(filepath.Join(warmed, pkg), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(warmed, provider), []byte("provider"), 0o755))
+	module := filepath.Join(".terraform", "modules", "target")
+	require.NoError(t, os.MkdirAll(filepath.Join(warmed, module), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(warmed, module, "main.tf"), []byte(""), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(warmed, ".terraform", "modules", "modules.json"), []byte("{}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(warmed, ".terraform", "terraform.tfstate"), []byte("{}"), 0o600))
+
+	// Nothing is reused until the cache has been warmed
+	reused, err := ReuseTerraformDir(cache, "key", filepath.Join(out, "copy"), pb.Terraform_Runner_Config_Reuse_MODE_COPY)
+	require.NoError(t, err)
+	require.False(t, reused)
+
+	require.NoError(t, WarmTerraformDir(cache, "key", warmed))
+	require.FileExists(t, filepath.Join(cache, "key", "providers", "registry.terraform.io", "hashicorp", "aws", "5.0.0", "linux_amd64", "terraform-provider-aws"))
+	require.FileExists(t, filepath.Join(cache, "key", "modules", "modules.json"))
+	require.NoFileExists(t, filepath.Join(cache, "key", "terraform.tfstate"))
+	// Warming it again is a no-op
+	require.NoError(t, WarmTerraformDir(cache, "key", warmed))
+
+	reused, err = ReuseTerraformDir(cache, "key", filepath.Join(out, "copy"), pb.Terraform_Runner_Config_Reuse_MODE_COPY)
+	require.NoError(t, err)
+	require.True(t, reused)
+	info, err := os.Lstat(filepath.Join(out, "copy", provider))
+	require.NoError(t, err)
+	require.True(t, info.Mode().IsRegular())
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	reused, err = ReuseTerraformDir(cache, "key", filepath.Join(out, "symlink"), pb.Terraform_Runner_Config_Reuse_MODE_SYMLINK)
+	require.NoError(t, err)
+	require.True(t, reused)
+	require.FileExists(t, filepath.Join(out, "symlink", provider))
+	// Only the provider packages and installed modules are linked, the directories that contain
+	// them and the modules manifest belong to the module so that init never writes to the cache.
+	for dir, linked := range map[string]string{
+		pkg:    filepath.Join(cache, "key", "providers", "registry.terraform.io", "hashicorp", "aws", "5.0.0", "linux_amd64"),
+		module: filepath.Join(cache, "key", "modules", "target"),
+	} {
+		target, err := os.Readlink(filepath.Join(out, "symlink", dir))
+		require.NoError(t, err)
+		require.Equal(t, linked, target)
+	}
+	for _, path := range []string{
+		filepath.Join(".terraform", "providers"),
+		filepath.Dir(pkg),
+		filepath.Join(".terraform", "modules"),
+		filepath.Join(".terraform", "modules", "modules.json"),
+	} {
+		info, err := os.Lstat(filepath.Join(out, "symlink", path))
+		require.NoError(t, err)
+		require.Zero(t, info.Mode()&os.ModeSymlink, path)
+	}
+
+	// Directories that modules already have are never replaced
+	reused, err = ReuseTerraformDir(cache, "key", filepath.Join(out, "symlink"), pb.Terraform_Runner_Config_Reuse_MODE_COPY)
+	require.NoError(t, err)
+	require.False(t, reused)
+
+	// Nothing is reused without a mode or key
+	reused, err = ReuseTerraformDir(cache, "key", filepath.Join(out, "none"), pb.Terraform_Runner_Config_Reuse_MODE_UNSPECIFIED)
+	require.NoError(t, err)
+	require.False(t, reused)
+	reused, err = ReuseTerraformDir(cache, "", filepath.Join(out, "none"), pb.Terraform_Runner_Config_Reuse_MODE_COPY)
+	require.NoError(t, err)
+	require.False(t, reused)
+}
@@ -0,0 +1,200 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// SnapshotsDirName is the name of the directory in the module directory that snapshots of the state
+// of the module are kept in.
+const SnapshotsDirName = "snapshots"
+
+// SnapshotStateFileName is the name of the state file in a snapshot.
+const SnapshotStateFileName = "terraform.tfstate"
+
+// snapshotIDFormat is the format of the timestamps that snapshots are identified by. They sort
+// chronologically.
+const snapshotIDFormat = "20060102T150405Z"
+
+// NewStateSnapshotID returns the ID of a snapshot taken at the time.
+func NewStateSnapshotID(t time.Time) string {
+	return t.UTC().Format(snapshotIDFormat)
+}
+
+// WriteStateSnapshot writes a snapshot of the state and the variables files of the module in the
+// directory. The snapshot is written into a temporary directory that is renamed into place so that
+// we never leave partial snapshots behind.
+func WriteStateSnapshot(moduleDir string, id string, state []byte) (*pb.Terraform_StateSnapshot, error) {
+	snapshotsDir := filepath.Join(moduleDir, SnapshotsDirName)
+	dst := filepath.Join(snapshotsDir, id)
+	if _, err := os.Stat(dst); err == nil {
+		return nil, fmt.Errorf("snapshot %s already exists", id)
+	}
+
+	if err := os.MkdirAll(snapshotsDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp(snapshotsDir, "."+id+"-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := os.WriteFile(filepath.Join(tmp, SnapshotStateFileName), state, 0o600); err != nil {
+		return nil, err
+	}
+
+	vars, err := varsFiles(moduleDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range vars {
+		if err := copyFile(filepath.Join(moduleDir, name), filepath.Join(tmp, name), 0o600); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		return nil, err
+	}
+
+	return ReadStateSnapshot(moduleDir, id)
+}
+
+// StateSnapshotIDs returns the IDs of the snapshots of the module in the directory, oldest first.
+func StateSnapshotIDs(moduleDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(moduleDir, SnapshotsDirName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		if _, err := time.Parse(snapshotIDFormat, entry.Name()); err == nil {
+			ids = append(ids, entry.Name())
+		}
+	}
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+// ReadStateSnapshot returns the snapshot of the module in the directory. Without an ID the latest
+// snapshot is returned.
+func ReadStateSnapshot(moduleDir string, id string) (*pb.Terraform_StateSnapshot, error) {
+	if id == "" {
+		ids, err := StateSnapshotIDs(moduleDir)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no snapshots of the state of the module in %s", moduleDir)
+		}
+		id = ids[len(ids)-1]
+	}
+
+	createdAt, err := time.Parse(snapshotIDFormat, id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot ID %s, snapshot IDs are timestamps like %s", id, snapshotIDFormat)
+	}
+
+	path := filepath.Join(moduleDir, SnapshotsDirName, id)
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no snapshot %s of the state of the module in %s", id, moduleDir)
+		}
+
+		return nil, err
+	}
+
+	snapshot := &pb.Terraform_StateSnapshot{
+		Id:        id,
+		Path:      path,
+		CreatedAt: timestamppb.New(createdAt),
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			snapshot.Files = append(snapshot.Files, entry.Name())
+		}
+	}
+
+	if !slices.Contains(snapshot.GetFiles(), SnapshotStateFileName) {
+		return nil, fmt.Errorf("snapshot %s has no %s", id, SnapshotStateFileName)
+	}
+
+	return snapshot, nil
+}
+
+// RestoreStateSnapshotVars restores the variables files of the snapshot into the module directory
+// and removes any variables files of the module that the snapshot doesn't have. The state itself is
+// restored by pushing it to the backend of the module.
+func RestoreStateSnapshotVars(moduleDir string, snapshot *pb.Terraform_StateSnapshot) error {
+	vars, err := varsFiles(moduleDir)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range vars {
+		if slices.Contains(snapshot.GetFiles(), name) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(moduleDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing %s: %w", name, err)
+		}
+	}
+
+	for _, name := range snapshot.GetFiles() {
+		if name == SnapshotStateFileName {
+			continue
+		}
+
+		if err := copyFile(filepath.Join(snapshot.GetPath(), name), filepath.Join(moduleDir, name), 0o600); err != nil {
+			return fmt.Errorf("restoring %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// varsFiles returns the names of the variables files of the module in the directory.
+func varsFiles(moduleDir string) ([]string, error) {
+	entries, err := os.ReadDir(moduleDir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && (strings.HasSuffix(name, ".tfvars") || strings.HasSuffix(name, ".tfvars.json")) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_StateSnapshot tests writing, reading, and restoring snapshots of the state of modules.
+func Test_StateSnapshot(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scenario.tf"), []byte(`module "foo" {}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fixtures.auto.tfvars.json"), []byte(`{"a":1}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfvars"), []byte(`b = 2`), 0o600))
+
+	_, err := ReadStateSnapshot(dir, "")
+	require.ErrorContains(t, err, "no snapshots")
+
+	first := NewStateSnapshotID(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	require.Equal(t, "20240102T030405Z", first)
+	snapshot, err := WriteStateSnapshot(dir, first, []byte(`{"serial":1}`))
+	require.NoError(t, err)
+	require.Equal(t, first, snapshot.GetId())
+	require.Equal(t, filepath.Join(dir, SnapshotsDirName, first), snapshot.GetPath())
+	require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), snapshot.GetCreatedAt().AsTime())
+	require.ElementsMatch(t, []string{SnapshotStateFileName, "fixtures.auto.tfvars.json", "terraform.tfvars"}, snapshot.GetFiles())
+
+	_, err = WriteStateSnapshot(dir, first, []byte(`{"serial":1}`))
+	require.ErrorContains(t, err, "already exists")
+
+	second := NewStateSnapshotID(time.Date(2024, 1, 2, 4, 0, 0, 0, time.UTC))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfvars"), []byte(`b = 3`), 0o600))
+	_, err = WriteStateSnapshot(dir, second, []byte(`{"serial":2}`))
+	require.NoError(t, err)
+
+	ids, err := StateSnapshotIDs(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{first, second}, ids)
+
+	// The latest snapshot is read without an ID
+	latest, err := ReadStateSnapshot(dir, "")
+	require.NoError(t, err)
+	require.Equal(t, second, latest.GetId())
+
+	_, err = ReadStateSnapshot(dir, "yesterday")
+	require.ErrorContains(t, err, "invalid snapshot ID")
+	_, err = ReadStateSnapshot(dir, "20200101T000000Z")
+	require.ErrorContains(t, err, "no snapshot 20200101T000000Z")
+
+	// Restoring a snapshot restores its variables files and removes those it doesn't have
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extra.auto.tfvars"), []byte(`c = 4`), 0o600))
+	require.NoError(t, RestoreStateSnapshotVars(dir, snapshot))
+	require.NoFileExists(t, filepath.Join(dir, "extra.auto.tfvars"))
+	require.FileExists(t, filepath.Join(dir, "scenario.tf"))
+	vars, err := os.ReadFile(filepath.Join(dir, "terraform.tfvars"))
+	require.NoError(t, err)
+	require.Equal(t, "b = 2", string(vars))
+	require.NoFileExists(t, filepath.Join(dir, SnapshotStateFileName))
+}
@@ -0,0 +1,155 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+terraform_cli "default" {
+  credentials "app.terraform.io" {
+    token = "token"
+  }
+
+  credentials "registry.example.com" {
+    token = "other"
+  }
+
+  credentials_helper "vault" {
+    args = ["--host", "vault.example.com"]
+  }
+
+  disable_checkpoint = true
+
+  provider_installation {
+    dev_overrides = {
+      "hashicorp.com/qti/enos" = "/tmp/enos"
+    }
+    network_mirror {
+      url     = "https://mirror.example.com/"
+      include = ["hashicorp.com/qti/enos"]
+    }
+    direct {
+      exclude = ["hashicorp.com/qti/enos"]
+    }
+  }
+}
+
+terraform "default" {
+  required_version = ">= 1.2.0"
+
+  required_providers {
+    random = {
+      source = "hashicorp/random"
+    }
+
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0.0"
+    }
+
+    enos = {
+      source = "hashicorp.com/qti/enos"
+    }
+  }
+
+  backend "s3" {
+    region = "us-east-1"
+    key    = "enos.tfstate"
+    bucket = "enos-state"
+  }
+}
+
+provider "aws" "east" {
+  skip_credentials_validation = true
+  region                      = "us-east-1"
+  profile                     = "enos"
+  max_retries                 = 3
+}
+
+provider "aws" "west" {
+  region = "us-west-2"
+}
+
+provider "enos" "ubuntu" {
+  transport = {
+    ssh = {
+      user             = "ubuntu"
+      private_key_path = "/tmp/key"
+    }
+  }
+}
+
+module "network" {
+  source  = "hashicorp/network/aws"
+  version = "1.0.0"
+
+  zone_count = 3
+  cidr       = "10.0.0.0/16"
+}
+
+module "target" {
+  source = "hashicorp/target/aws"
+
+  instance_type = "t3.small"
+}
+
+scenario "golden" {
+  matrix {
+    arch = ["arm64"]
+  }
+
+  terraform_cli = terraform_cli.default
+  terraform     = terraform.default
+  providers = [
+    provider.aws.east,
+    provider.aws.west,
+    provider.enos.ubuntu,
+  ]
+
+  overrides {
+    required_providers {
+      aws = {
+        source  = "hashicorp/aws"
+        version = "5.31.0"
+      }
+    }
+
+    backend "s3" {
+      bucket = "enos-overrides"
+      key    = "${matrix.arch}/enos.tfstate"
+      region = "us-west-2"
+    }
+  }
+
+  step "network" {
+    module = module.network
+
+    providers = {
+      aws = provider.aws.east
+    }
+  }
+
+  step "target" {
+    module = module.target
+
+    providers = {
+      enos = provider.enos.ubuntu
+      aws  = provider.aws.west
+    }
+
+    variables {
+      vpc_id     = step.network.vpc_id
+      subnet_ids = step.network.subnet_ids
+      tags = {
+        Name = "target"
+        Arch = matrix.arch
+      }
+      instance_count = 2
+    }
+  }
+
+  output "target_ips" {
+    description = "The IPs of the targets"
+    value       = step.target.ips
+  }
+
+  output "vpc_id" {
+    value = step.network.vpc_id
+  }
+}
@@ -0,0 +1,318 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package i18n is the message catalog of the user facing messages of enos. Messages are keyed by
+// their English text, which is also used when a message has not been translated. It only
+// translates messages that are produced by enos itself, never the output of Terraform.
+package i18n
+
+import (
+	"cmp"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the locale of the messages in the source code.
+const DefaultLocale = "en"
+
+//go:embed catalogs/*.json
+var catalogs embed.FS
+
+// verbPattern matches the formatting verbs of a message, e.g. %s or %[2]d.
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[+#]?[a-zA-Z]`)
+
+// CatalogFile is the format of a message catalog file. Messages are keyed by their English text
+// and can contain the same formatting verbs, e.g. "provider type %s is not defined". Explicit
+// argument indexes like %[2]s can be used to change the order of the arguments.
+type CatalogFile struct {
+	Locale   string            `json:"locale"`
+	Messages map[string]string `json:"messages"`
+}
+
+// Catalog is the message catalog of a locale. A nil Catalog returns every message in English.
+type Catalog struct {
+	locale      string
+	catalogFile string
+	messages    map[string]string
+	patterns    []*pattern
+}
+
+// pattern matches a message that has already been formatted, like the summary of a diagnostic,
+// so that it can be translated with its arguments.
+type pattern struct {
+	re          *regexp.Regexp
+	translation string
+}
+
+// Opt is a functional option.
+type Opt func(*Catalog)
+
+// New takes a locale and options and returns the message catalog of the locale. The built-in
+// catalog that best matches the locale is used, e.g. the "de" catalog for "de_AT.UTF-8". An empty
+// locale is detected from the environment.
+func New(locale string, opts ...Opt) (*Catalog, error) {
+	c := &Catalog{
+		messages: map[string]string{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	var tag language.Tag
+	var err error
+	if locale == "" {
+		// Invalid locales of the environment use the default locale rather than failing.
+		tag, err = ParseLocale(DetectLocale())
+		if err != nil {
+			tag = language.Make(DefaultLocale)
+		}
+	} else {
+		tag, err = ParseLocale(locale)
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.locale = tag.String()
+
+	for _, candidate := range candidates(tag) {
+		name := path.Join("catalogs", candidate+".json")
+		b, err := catalogs.ReadFile(name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("reading message catalog: %w", err)
+		}
+
+		f, err := decodeCatalogFile(name, b)
+		if err != nil {
+			return nil, err
+		}
+		c.add(f.Messages)
+
+		break
+	}
+
+	if c.catalogFile != "" {
+		b, err := os.ReadFile(c.catalogFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading message catalog: %w", err)
+		}
+
+		f, err := decodeCatalogFile(c.catalogFile, b)
+		if err != nil {
+			return nil, err
+		}
+
+		// Catalog files of other locales are ignored so that teams can share the configuration
+		// without everyone having to use the same locale.
+		fileTag, err := ParseLocale(f.Locale)
+		if err != nil {
+			return nil, fmt.Errorf("message catalog %s: %w", c.catalogFile, err)
+		}
+		for _, candidate := range candidates(tag) {
+			if fileTag.String() == candidate {
+				c.add(f.Messages)
+
+				break
+			}
+		}
+	}
+	c.compile()
+
+	return c, nil
+}
+
+// WithCatalogFile adds the messages of the catalog file to the built-in messages of the locale.
+func WithCatalogFile(path string) Opt {
+	return func(c *Catalog) {
+		c.catalogFile = path
+	}
+}
+
+// DetectLocale returns the locale of the environment, which is determined by the LC_ALL,
+// LC_MESSAGES, and LANG environment variables, or the default locale.
+func DetectLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if val := os.Getenv(env); val != "" {
+			return val
+		}
+	}
+
+	return DefaultLocale
+}
+
+// ParseLocale parses a BCP 47 language tag or a POSIX locale, e.g. "de-DE" or "de_DE.UTF-8". The
+// POSIX "C" locale is the default locale.
+func ParseLocale(locale string) (language.Tag, error) {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		locale = DefaultLocale
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil {
+		return language.Und, fmt.Errorf("invalid locale %q: %w", locale, err)
+	}
+
+	return tag, nil
+}
+
+// Locale returns the locale of the catalog.
+func (c *Catalog) Locale() string {
+	if c == nil {
+		return DefaultLocale
+	}
+
+	return c.locale
+}
+
+// T translates the message and formats it with the arguments. Leading whitespace is not part of
+// the message and is kept, so that indented messages share the same translation.
+func (c *Catalog) T(msg string, args ...any) string {
+	trimmed := strings.TrimLeft(msg, " ")
+	indent := msg[:len(msg)-len(trimmed)]
+
+	if c != nil {
+		if translation, ok := c.messages[trimmed]; ok {
+			trimmed = translation
+		}
+	}
+
+	if len(args) == 0 {
+		return indent + trimmed
+	}
+
+	return indent + fmt.Sprintf(trimmed, args...)
+}
+
+// Plural translates the message of the count, either the message for one or for any other count,
+// and formats it with the count.
+func (c *Catalog) Plural(count int, one string, other string) string {
+	if count == 1 {
+		return c.T(one, count)
+	}
+
+	return c.T(other, count)
+}
+
+// Translate translates a message that has already been formatted, like the summary of a
+// diagnostic. Messages with arguments are matched with the formatting verbs of the catalog.
+func (c *Catalog) Translate(msg string) string {
+	if c == nil {
+		return msg
+	}
+
+	if translation, ok := c.messages[msg]; ok {
+		return translation
+	}
+
+	for _, p := range c.patterns {
+		matches := p.re.FindStringSubmatch(msg)
+		if matches == nil {
+			continue
+		}
+
+		args := make([]any, len(matches)-1)
+		for i, match := range matches[1:] {
+			args[i] = match
+		}
+
+		return fmt.Sprintf(p.translation, args...)
+	}
+
+	return msg
+}
+
+// add adds the messages to the catalog. Messages that are already in the catalog are replaced.
+func (c *Catalog) add(messages map[string]string) {
+	for msg, translation := range messages {
+		c.messages[msg] = translation
+	}
+}
+
+// compile compiles the patterns of the messages that have formatting verbs. Messages with longer
+// text are matched first so that the most specific message wins.
+func (c *Catalog) compile() {
+	msgs := []string{}
+	for msg := range c.messages {
+		if verbPattern.MatchString(msg) {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	literalLen := func(msg string) int {
+		return len(verbPattern.ReplaceAllString(msg, ""))
+	}
+	slices.SortFunc(msgs, func(a, b string) int {
+		if n := cmp.Compare(literalLen(b), literalLen(a)); n != 0 {
+			return n
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	c.patterns = make([]*pattern, len(msgs))
+	for i, msg := range msgs {
+		c.patterns[i] = newPattern(msg, c.messages[msg])
+	}
+}
+
+// newPattern returns a new pattern for the message. Integer verbs only match integers and every
+// other verb matches any text. The arguments of formatted messages are always strings so the verbs
+// of the translation are replaced with string verbs.
+func newPattern(msg string, translation string) *pattern {
+	re := strings.Builder{}
+	re.WriteString("^")
+	last := 0
+	for _, loc := range verbPattern.FindAllStringIndex(msg, -1) {
+		re.WriteString(regexp.QuoteMeta(msg[last:loc[0]]))
+		if msg[loc[1]-1] == 'd' {
+			re.WriteString(`(-?\d+)`)
+		} else {
+			re.WriteString("(.+?)")
+		}
+		last = loc[1]
+	}
+	re.WriteString(regexp.QuoteMeta(msg[last:]))
+	re.WriteString("$")
+
+	return &pattern{
+		re:          regexp.MustCompile(re.String()),
+		translation: verbPattern.ReplaceAllString(translation, "%${1}s"),
+	}
+}
+
+// candidates returns the names of the catalogs for the language tag from the most to the least
+// specific, e.g. "de-AT" and "de".
+func candidates(tag language.Tag) []string {
+	res := []string{tag.String()}
+	if base, conf := tag.Base(); conf != language.No && base.String() != tag.String() {
+		res = append(res, base.String())
+	}
+
+	return res
+}
+
+// decodeCatalogFile decodes a catalog file.
+func decodeCatalogFile(name string, b []byte) (*CatalogFile, error) {
+	f := &CatalogFile{}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("decoding message catalog %s: %w", name, err)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Catalog_T tests translating and formatting messages.
+func Test_Catalog_T(t *testing.T) {
+	t.Parallel()
+
+	de, err := New("de_AT.UTF-8")
+	require.NoError(t, err)
+	require.Equal(t, "de-AT", de.Locale())
+
+	en, err := New("C")
+	require.NoError(t, err)
+	require.Equal(t, DefaultLocale, en.Locale())
+
+	for desc, test := range map[string]struct {
+		catalog  *Catalog
+		msg      string
+		args     []any
+		expected string
+	}{
+		"translated": {
+			catalog:  de,
+			msg:      "Enos operations finished!",
+			expected: "Enos-Operationen abgeschlossen!",
+		},
+		"translated with args": {
+			catalog:  de,
+			msg:      "Scenario: %s %s",
+			args:     []any{"test [backend:raft]", "✅"},
+			expected: "Szenario: test [backend:raft] ✅",
+		},
+		"indented": {
+			catalog:  de,
+			msg:      "  failed!",
+			expected: "  fehlgeschlagen!",
+		},
+		"not translated": {
+			catalog:  de,
+			msg:      "not a message %d",
+			args:     []any{1},
+			expected: "not a message 1",
+		},
+		"default locale": {
+			catalog:  en,
+			msg:      "Enos operations finished!",
+			expected: "Enos operations finished!",
+		},
+		"nil catalog": {
+			msg:      "Scenario: %s %s",
+			args:     []any{"test", "✅"},
+			expected: "Scenario: test ✅",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.expected, test.catalog.T(test.msg, test.args...))
+		})
+	}
+}
+
+// Test_Catalog_Translate tests translating messages that have already been formatted.
+func Test_Catalog_Translate(t *testing.T) {
+	t.Parallel()
+
+	de, err := New("de")
+	require.NoError(t, err)
+
+	for msg, expected := range map[string]string{
+		"invalid value":                                   "ungültiger Wert",
+		"provider type aws is not defined":                "der Provider-Typ aws ist nicht definiert",
+		"alias east for provider type aws is not defined": "der Alias east für den Provider-Typ aws ist nicht definiert",
+		"Error: A Terraform error":                        "Error: A Terraform error",
+	} {
+		require.Equal(t, expected, de.Translate(msg))
+	}
+}
+
+// Test_Catalog_CatalogFile tests that catalog files add to and override the built-in messages of
+// their locale and that catalog files of other locales are ignored.
+func Test_Catalog_CatalogFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "locale": "de",
+  "messages": {
+    "Enos operations finished!": "Fertig!",
+    "step %[1]s depends on %[2]s": "%[2]s ist eine Abhängigkeit von %[1]s"
+  }
+}`), 0o644))
+
+	de, err := New("de-DE", WithCatalogFile(path))
+	require.NoError(t, err)
+	require.Equal(t, "Fertig!", de.T("Enos operations finished!"))
+	require.Equal(t, "Enos-Operationen fehlgeschlagen!", de.T("Enos operations failed!"))
+	require.Equal(t, "init ist eine Abhängigkeit von apply", de.Translate("step apply depends on init"))
+
+	fr, err := New("fr", WithCatalogFile(path))
+	require.NoError(t, err)
+	require.Equal(t, "Enos operations finished!", fr.T("Enos operations finished!"))
+
+	_, err = New("de", WithCatalogFile(filepath.Join(t.TempDir(), "missing.json")))
+	require.Error(t, err)
+}
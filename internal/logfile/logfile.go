@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package logfile writes logs to a file that is rotated when it reaches a maximum size.
+package logfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// DefaultMaxSize is the default size in bytes at which the log file is rotated.
+	DefaultMaxSize int64 = 10 << 20
+	// DefaultMaxBackups is the default number of rotated log files that are kept.
+	DefaultMaxBackups = 5
+)
+
+// File is a log file that is rotated when writing to it would exceed its maximum size. Rotated
+// files are renamed with a numbered suffix, e.g. enos.log.1, where higher numbers are older.
+// Files beyond the maximum number of backups are removed. File is safe for concurrent use.
+type File struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Opt is a functional option.
+type Opt func(*File)
+
+// Open takes the path of the log file and options and returns a new File. The log file and its
+// parent directories are created if they don't exist. Logs are appended to existing log files.
+func Open(path string, opts ...Opt) (*File, error) {
+	f := &File{
+		path:       path,
+		maxSize:    DefaultMaxSize,
+		maxBackups: DefaultMaxBackups,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating log file directory: %w", err)
+	}
+
+	if err := f.open(os.O_APPEND); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// WithMaxSize sets the size in bytes at which the log file is rotated.
+func WithMaxSize(size int64) Opt {
+	return func(f *File) {
+		f.maxSize = size
+	}
+}
+
+// WithMaxBackups sets the number of rotated log files that are kept.
+func WithMaxBackups(backups int) Opt {
+	return func(f *File) {
+		f.maxBackups = backups
+	}
+}
+
+// Path returns the path of the log file.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Write writes to the log file, rotating it first if the write would exceed the maximum size.
+// Writes are never split between files.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, fs.ErrClosed
+	}
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// Close closes the log file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
+
+// open opens the log file with the flag and determines its size. The log file has the trace logs
+// and Terraform output of operations, which might include variables and the environment, so only
+// the user can read it.
+func (f *File) open(flag int) error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|flag, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+
+	// Log files that we append to might have been created with broader permissions.
+	if err := file.Chmod(0o600); err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("opening log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("opening log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+
+	return nil
+}
+
+// rotate closes the log file, shifts the backups, and opens a new empty log file.
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	f.file = nil
+
+	if f.maxBackups < 1 {
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+	} else {
+		if err := os.Remove(f.backupPath(f.maxBackups)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+
+		for i := f.maxBackups - 1; i > 0; i-- {
+			err := os.Rename(f.backupPath(i), f.backupPath(i+1))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("rotating log file: %w", err)
+			}
+		}
+
+		if err := os.Rename(f.path, f.backupPath(1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+	}
+
+	return f.open(os.O_TRUNC)
+}
+
+// backupPath returns the path of the nth rotated log file.
+func (f *File) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", f.path, n)
+}
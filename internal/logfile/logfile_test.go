@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_File_Rotate tests that log files are rotated when they reach their maximum size and that
+// only the maximum number of backups are kept.
+func Test_File_Rotate(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "logs", "enos.log")
+	f, err := Open(path, WithMaxSize(10), WithMaxBackups(2))
+	require.NoError(t, err)
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n", "six\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	for path, expected := range map[string]string{
+		path:        "six\n",
+		path + ".1": "four\nfive\n",
+		path + ".2": "three\n",
+	} {
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(got), path)
+	}
+	require.NoFileExists(t, path+".3")
+
+	for _, path := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm(), path)
+	}
+
+	_, err = f.Write([]byte("seven\n"))
+	require.ErrorIs(t, err, os.ErrClosed)
+}
+
+// Test_File_Append tests that logs are appended to existing log files.
+func Test_File_Append(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "enos.log")
+	require.NoError(t, os.WriteFile(path, []byte("one\n"), 0o644))
+
+	f, err := Open(path)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("two\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "one\ntwo\n", string(got))
+
+	// Only the user can read the log file, even if it was created with broader permissions
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
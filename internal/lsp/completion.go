@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+// referencePattern matches a reference that is being typed, e.g. "step.".
+var referencePattern = regexp.MustCompile(`\b(module|step|matrix|var|global|local)\.[\w-]*$`)
+
+// namePattern matches the name of an attribute or block that is being typed.
+var namePattern = regexp.MustCompile(`^\s*[\w-]*$`)
+
+// completion completes references and the names of attributes and blocks at the position.
+func (s *Server) completion(uri string, pos Position) []CompletionItem {
+	path := uriToPath(uri)
+	text, err := s.docs.read(path)
+	if err != nil {
+		return []CompletionItem{}
+	}
+
+	off := offset(text, pos)
+	lineStart := bytes.LastIndexByte(text[:off], '\n') + 1
+	line := text[lineStart:off]
+
+	if loc := referencePattern.FindSubmatchIndex(line); loc != nil {
+		// Incomplete references aren't valid syntax so we replace them with null before we parse.
+		blank := bytes.Repeat([]byte{' '}, loc[1]-loc[0])
+		copy(blank, "null")
+		file := parse(path, replace(text, lineStart+loc[0], off, blank))
+
+		return s.referenceCompletion(file, string(line[loc[2]:loc[3]]), off)
+	}
+
+	if namePattern.Match(line) {
+		// Blank what's being typed so that we parse the body as it was.
+		file := parse(path, replace(text, lineStart, off, bytes.Repeat([]byte{' '}, len(line))))
+
+		return s.nameCompletion(file, off)
+	}
+
+	return []CompletionItem{}
+}
+
+// referenceCompletion completes the names of the things that the references of the root refer
+// to.
+func (s *Server) referenceCompletion(file *syntaxFile, root string, off int) []CompletionItem {
+	blocks := blocksAt(file.body, off)
+	scenario := enclosingBlock(blocks, "scenario")
+	names := []string{}
+	kind := completionKindVariable
+
+	switch root {
+	case "module":
+		kind = completionKindModule
+		for _, f := range s.filesWith(file) {
+			for _, module := range blocksOfType(f.body, "module") {
+				names = append(names, module.Labels...)
+			}
+		}
+	case "step":
+		if scenario != nil {
+			for _, step := range blocksOfType(scenario.Body, "step") {
+				names = append(names, step.Labels...)
+			}
+		}
+	case "matrix":
+		for _, attr := range matrixAttributes(scenario) {
+			names = append(names, attr.Name)
+		}
+	case "var":
+		for _, f := range s.filesWith(file) {
+			for _, variable := range blocksOfType(f.body, "variable") {
+				names = append(names, variable.Labels...)
+			}
+		}
+	case "global":
+		for _, f := range s.filesWith(file) {
+			for _, globals := range blocksOfType(f.body, "globals") {
+				for _, attr := range sortedAttributes(globals.Body) {
+					names = append(names, attr.Name)
+				}
+			}
+		}
+	case "local":
+		if scenario != nil {
+			for _, locals := range blocksOfType(scenario.Body, "locals") {
+				for _, attr := range sortedAttributes(locals.Body) {
+					names = append(names, attr.Name)
+				}
+			}
+		}
+	default:
+	}
+
+	items := []CompletionItem{}
+	for _, name := range names {
+		if slices.ContainsFunc(items, func(item CompletionItem) bool { return item.Label == name }) {
+			continue
+		}
+		items = append(items, CompletionItem{Label: name, Kind: kind, Detail: root})
+	}
+
+	return items
+}
+
+// nameCompletion completes the names of the attributes of the schema of the innermost block that
+// haven't been defined yet and the names of its blocks, which can be repeated.
+func (s *Server) nameCompletion(file *syntaxFile, off int) []CompletionItem {
+	blocks := blocksAt(file.body, off)
+	schema := s.schemaAt(file.path, blocks)
+	if schema == nil {
+		return []CompletionItem{}
+	}
+	body := innermostBody(file, blocks)
+
+	items := []CompletionItem{}
+	for _, name := range sortedKeys(schema.Attributes) {
+		if _, ok := body.Attributes[name]; ok {
+			continue
+		}
+		attr := schema.Attributes[name]
+		items = append(items, CompletionItem{
+			Label:      name,
+			Kind:       completionKindProperty,
+			Detail:     attributeDetail(attr),
+			InsertText: name + " = ",
+		})
+	}
+
+	for _, name := range sortedKeys(schema.BlockTypes) {
+		blockType := schema.BlockTypes[name]
+		insert := name
+		for _, label := range blockType.Labels {
+			insert += ` "` + label + `"`
+		}
+		items = append(items, CompletionItem{
+			Label:      name,
+			Kind:       completionKindStruct,
+			Detail:     "block",
+			InsertText: insert + " {\n}",
+		})
+	}
+
+	return items
+}
+
+// filesWith returns the flight plan files in the directory of the file, with the file instead of
+// what has been read of it.
+func (s *Server) filesWith(file *syntaxFile) []*syntaxFile {
+	files := s.flightPlanFiles(filepath.Dir(file.path))
+	for i := range files {
+		if files[i].path == file.path {
+			files[i] = file
+		}
+	}
+
+	return files
+}
+
+// replace returns a copy of the text with the bytes between start and end replaced.
+func replace(text []byte, start, end int, with []byte) []byte {
+	out := slices.Clone(text[:start])
+	out = append(out, with...)
+
+	return append(out, text[end:]...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// target is the block or attribute that a reference refers to.
+type target struct {
+	file  *syntaxFile
+	block *hclsyntax.Block
+	attr  *hclsyntax.Attribute
+}
+
+// rng returns the range of the definition of the target.
+func (t *target) rng() hcl.Range {
+	if t.block != nil {
+		return t.block.DefRange()
+	}
+
+	return t.attr.SrcRange
+}
+
+// fileAt returns the syntax of the document and the offset of the position.
+func (s *Server) fileAt(uri string, pos Position) (*syntaxFile, int, bool) {
+	path := uriToPath(uri)
+	text, err := s.docs.read(path)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	return parse(path, text), offset(text, pos), true
+}
+
+// definition returns the location of the definition of the reference at the position.
+func (s *Server) definition(uri string, pos Position) []Location {
+	file, off, ok := s.fileAt(uri, pos)
+	if !ok {
+		return []Location{}
+	}
+
+	traversal, _ := traversalAt(file.body, off)
+	t := s.resolve(file, off, traversal)
+	if t == nil {
+		return []Location{}
+	}
+
+	return []Location{{URI: pathToURI(t.file.path), Range: toRange(t.file.text, t.rng())}}
+}
+
+// resolve returns what the reference at the offset refers to, or nil if it doesn't refer to
+// anything that's been defined.
+func (s *Server) resolve(file *syntaxFile, off int, traversal hcl.Traversal) *target {
+	names := []string{}
+	for _, step := range traversal.SimpleSplit().Rel {
+		attr, ok := step.(hcl.TraverseAttr)
+		if !ok {
+			break
+		}
+		names = append(names, attr.Name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	scenario := enclosingBlock(blocksAt(file.body, off), "scenario")
+	// Steps and locals are scoped to their scenario.
+	var scenarioFiles []*syntaxFile
+	if scenario != nil {
+		scenarioFiles = []*syntaxFile{{path: file.path, text: file.text, body: scenario.Body}}
+	}
+
+	switch traversal.RootName() {
+	case "module":
+		return findBlock(s.filesWith(file), "module", names[0])
+	case "var":
+		return findBlock(s.filesWith(file), "variable", names[0])
+	case "quality":
+		return findBlock(s.filesWith(file), "quality", names[0])
+	case "matrix_excludes":
+		return findBlock(s.filesWith(file), "matrix_excludes", names[0])
+	case "provider":
+		if len(names) < 2 {
+			return nil
+		}
+
+		return findBlock(s.filesWith(file), "provider", names[0], names[1])
+	case "step":
+		return findBlock(scenarioFiles, "step", names[0])
+	case "matrix":
+		for _, attr := range matrixAttributes(scenario) {
+			if attr.Name == names[0] {
+				return &target{file: file, attr: attr}
+			}
+		}
+	case "global":
+		return findAttribute(s.filesWith(file), "globals", names[0])
+	case "local":
+		return findAttribute(scenarioFiles, "locals", names[0])
+	default:
+	}
+
+	return nil
+}
+
+// findBlock returns the first block of the type with the labels in the files.
+func findBlock(files []*syntaxFile, typ string, labels ...string) *target {
+	for _, file := range files {
+		if blocks := blocksOfType(file.body, typ, labels...); len(blocks) > 0 {
+			return &target{file: file, block: blocks[0]}
+		}
+	}
+
+	return nil
+}
+
+// findAttribute returns the first attribute with the name in the blocks of the type in the files.
+func findAttribute(files []*syntaxFile, typ string, name string) *target {
+	for _, file := range files {
+		for _, block := range blocksOfType(file.body, typ) {
+			if attr, ok := block.Body.Attributes[name]; ok {
+				return &target{file: file, attr: attr}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	hcl "github.com/hashicorp/hcl/v2"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+)
+
+// rawFiles returns the files in the directory that match the pattern, with the text of any
+// open documents instead of what's on disk.
+func (s *Server) rawFiles(dir string, pattern *regexp.Regexp) flightplan.RawFiles {
+	files, err := flightplan.FindRawFiles(dir, pattern)
+	if err != nil {
+		s.log.Error("finding files", "dir", dir, "error", err)
+		files = flightplan.RawFiles{}
+	}
+
+	s.docs.mu.RLock()
+	defer s.docs.mu.RUnlock()
+	for path, text := range s.docs.text {
+		if filepath.Dir(path) == dir && pattern.MatchString(filepath.Base(path)) {
+			files[path] = text
+		}
+	}
+
+	return files
+}
+
+// decode decodes the entire flight plan in the directory and returns the diagnostics.
+func (s *Server) decode(ctx context.Context, dir string, fpFiles, varFiles flightplan.RawFiles) hcl.Diagnostics {
+	decoder, err := flightplan.NewDecoder(
+		flightplan.WithDecoderBaseDir(dir),
+		flightplan.WithDecoderFPFiles(fpFiles),
+		flightplan.WithDecoderVarFiles(varFiles),
+		flightplan.WithDecoderEnv(s.env),
+		flightplan.WithDecoderDecodeTarget(flightplan.DecodeTargetAll),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+	if err != nil {
+		return hcl.Diagnostics{{Severity: hcl.DiagError, Summary: err.Error()}}
+	}
+
+	diags := decoder.Parse()
+	if diags.HasErrors() {
+		return diags
+	}
+
+	fp, scenarioDecoder, moreDiags := decoder.Decode(ctx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() || scenarioDecoder == nil {
+		return diags
+	}
+
+	return diags.Extend(scenarioDecoder.DecodeAll(ctx, fp))
+}
+
+// publishDiagnostics decodes the flight plan of the document and publishes the diagnostics of
+// every file of the flight plan. Files whose diagnostics have been fixed are published without
+// diagnostics to clear them.
+func (s *Server) publishDiagnostics(ctx context.Context, uri string) {
+	path := uriToPath(uri)
+	dir := filepath.Dir(path)
+	if !flightplan.FlightPlanFileNamePattern.MatchString(filepath.Base(path)) &&
+		!flightplan.VariablesNamePattern.MatchString(filepath.Base(path)) {
+		return
+	}
+
+	fpFiles := s.rawFiles(dir, flightplan.FlightPlanFileNamePattern)
+	varFiles := s.rawFiles(dir, flightplan.VariablesNamePattern)
+	text := map[string][]byte{}
+	for _, files := range []flightplan.RawFiles{fpFiles, varFiles} {
+		for path, b := range files {
+			text[path] = b
+		}
+	}
+
+	byPath := map[string][]Diagnostic{}
+	for path := range text {
+		byPath[path] = []Diagnostic{}
+	}
+	for published := range s.published[dir] {
+		if _, ok := byPath[published]; !ok {
+			byPath[published] = []Diagnostic{}
+		}
+	}
+
+	for _, diag := range s.decode(ctx, dir, fpFiles, varFiles) {
+		diagPath := path
+		rng := Range{}
+		if diag.Subject != nil {
+			if _, ok := text[diag.Subject.Filename]; ok {
+				diagPath = diag.Subject.Filename
+				rng = toRange(text[diagPath], *diag.Subject)
+			}
+		}
+
+		byPath[diagPath] = append(byPath[diagPath], toDiagnostic(diag, rng))
+	}
+
+	paths := []string{}
+	for diagPath := range byPath {
+		paths = append(paths, diagPath)
+	}
+	slices.Sort(paths)
+
+	s.published[dir] = map[string]struct{}{}
+	for _, diagPath := range paths {
+		diags := byPath[diagPath]
+		if len(diags) > 0 {
+			s.published[dir][diagPath] = struct{}{}
+		}
+		s.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{
+			URI:         pathToURI(diagPath),
+			Diagnostics: diags,
+		})
+	}
+}
+
+// toDiagnostic returns the HCL diagnostic as a diagnostic of the range.
+func toDiagnostic(diag *hcl.Diagnostic, rng Range) Diagnostic {
+	msg := diag.Summary
+	if diag.Detail != "" {
+		msg += ": " + diag.Detail
+	}
+
+	severity := severityError
+	if diag.Severity == hcl.DiagWarning {
+		severity = severityWarning
+	}
+
+	return Diagnostic{
+		Range:    rng,
+		Severity: severity,
+		Source:   "enos",
+		Message:  msg,
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// documents are the open documents, keyed by their path. The text of open documents takes
+// precedence over the files on disk.
+type documents struct {
+	mu   sync.RWMutex
+	text map[string][]byte
+}
+
+func newDocuments() *documents {
+	return &documents{text: map[string][]byte{}}
+}
+
+func (d *documents) set(uri string, text []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.text[uriToPath(uri)] = text
+}
+
+func (d *documents) remove(uri string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.text, uriToPath(uri))
+}
+
+// read returns the text of the open document or the file at the path.
+func (d *documents) read(path string) ([]byte, error) {
+	d.mu.RLock()
+	text, ok := d.text[path]
+	d.mu.RUnlock()
+	if ok {
+		return text, nil
+	}
+
+	return os.ReadFile(path)
+}
+
+// uriToPath returns the path of a file URI.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+
+	return filepath.Clean(filepath.FromSlash(u.Path))
+}
+
+// pathToURI returns the file URI of a path.
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// offset returns the byte offset of the position in the text. Positions past the end of a line
+// are at its end.
+func offset(text []byte, pos Position) int {
+	off := 0
+	for range pos.Line {
+		i := bytes.IndexByte(text[off:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+		off += i + 1
+	}
+
+	for chars := 0; chars < pos.Character && off < len(text) && text[off] != '\n'; {
+		r, size := utf8.DecodeRune(text[off:])
+		chars += utf16Len(r)
+		off += size
+	}
+
+	return off
+}
+
+// position returns the position of the HCL position in the text.
+func position(text []byte, pos hcl.Pos) Position {
+	off := min(max(pos.Byte, 0), len(text))
+	start := bytes.LastIndexByte(text[:off], '\n') + 1
+
+	chars := 0
+	for _, r := range string(text[start:off]) {
+		chars += utf16Len(r)
+	}
+
+	return Position{Line: bytes.Count(text[:start], []byte{'\n'}), Character: chars}
+}
+
+// toRange returns the range of the HCL range in the text.
+func toRange(text []byte, rng hcl.Range) Range {
+	return Range{Start: position(text, rng.Start), End: position(text, rng.End)}
+}
+
+// utf16Len returns the number of UTF-16 code units of the rune.
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
@@ -0,0 +1,47 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"testing"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Position tests converting positions to and from UTF-16 line and character offsets.
+func Test_Position(t *testing.T) {
+	t.Parallel()
+
+	text := []byte("a = 1\nb = \"ü😀\" # x\nc = 3")
+
+	for desc, test := range map[string]struct {
+		pos Position
+		off int
+	}{
+		"start":             {Position{0, 0}, 0},
+		"second line":       {Position{1, 0}, 6},
+		"after multibyte":   {Position{1, 8}, 17},
+		"past end of line":  {Position{1, 100}, 22},
+		"last line":         {Position{2, 4}, 27},
+		"past end of lines": {Position{10, 0}, 28},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.off, offset(text, test.pos))
+			if test.pos.Line < 2 && test.pos.Character < 100 {
+				require.Equal(t, test.pos, position(text, hcl.Pos{Byte: test.off}))
+			}
+		})
+	}
+}
+
+// Test_URI tests converting file URIs to and from paths.
+func Test_URI(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "file:///tmp/my%20dir/enos.hcl", pathToURI("/tmp/my dir/enos.hcl"))
+	require.Equal(t, "/tmp/my dir/enos.hcl", uriToPath("file:///tmp/my%20dir/enos.hcl"))
+}
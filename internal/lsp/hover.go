@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+)
+
+// blockDocs are the documentation of blocks, keyed by their type.
+var blockDocs = map[string]string{
+	"backend":            "The Terraform backend of the generated modules.",
+	"cloud":              "The HCP Terraform configuration of the generated modules.",
+	"constraints":        "Variants that can't be combined, e.g. one edition excludes another variant.",
+	"enos":               "Settings of enos itself, e.g. the required version.",
+	"exclude":            "Excludes the variants that match from the matrix.",
+	"fixture":            "A reusable step that scenarios can share.",
+	"globals":            "Values that can be referenced from anywhere as global.<name>.",
+	"include":            "Includes the product of the variants in the matrix.",
+	"keys":               "The attributes that identify the object values of matrix variants.",
+	"layout":             "The layout of the generated modules in the out directory.",
+	"lint":               "The configuration of the linter.",
+	"locals":             "Values that can be referenced from the scenario as local.<name>.",
+	"lock":               "The dependency lock files of the generated modules.",
+	"matrix":             "The variants of the scenario. Every combination of them is a scenario.",
+	"matrix_excludes":    "Excludes that matrices can share, referenced as matrix_excludes.<name>.",
+	"module":             "A Terraform module that steps can execute.",
+	"notify":             "Where the summaries of scenario runs are sent.",
+	"output":             "An output of the scenario.",
+	"overrides":          "Overrides of the terraform settings of the scenario.",
+	"policy":             "The policy that the plans of scenarios must satisfy before they're applied.",
+	"provider":           "A Terraform provider configuration that scenarios can use.",
+	"provider_meta":      "Terraform provider metadata of the generated modules.",
+	"publish":            "Where the artifacts, reports, and logs of runs are uploaded.",
+	"quality":            "A quality that steps can verify.",
+	"required_providers": "The Terraform providers that the generated modules require.",
+	"rule":               "The configuration of a lint rule.",
+	"sample":             "A sample of the scenario variants of the flight plan.",
+	"scenario":           "A scenario of quality requirements that are verified by its steps.",
+	"shared_excludes":    "Applies the excludes of matrix_excludes to the matrix.",
+	"skip":               "Variants that are excluded from every command, with the reason.",
+	"step":               "A step of the scenario that executes a module or provisioner.",
+	"subset":             "A subset of the scenarios of the sample.",
+	"terraform":          "Terraform settings that scenarios can use.",
+	"terraform_cli":      "A Terraform CLI configuration that scenarios can use.",
+	"validation":         "A validation rule of the variable.",
+	"variable":           "An input variable of the flight plan, referenced as var.<name>.",
+	"variables":          "The input variables of the module of the step.",
+}
+
+// hover returns the documentation of the reference, attribute, or block at the position.
+func (s *Server) hover(uri string, pos Position) *Hover {
+	file, off, ok := s.fileAt(uri, pos)
+	if !ok {
+		return nil
+	}
+
+	if traversal, rng := traversalAt(file.body, off); traversal != nil {
+		if t := s.resolve(file, off, traversal); t != nil {
+			return newHover(file, rng, t.doc())
+		}
+	}
+
+	blocks := blocksAt(file.body, off)
+	schema := s.schemaAt(file.path, blocks)
+	if schema == nil {
+		return nil
+	}
+	body := innermostBody(file, blocks)
+
+	for _, attr := range body.Attributes {
+		if contains(attr.NameRange, off) {
+			attrSchema, ok := schema.Attributes[attr.Name]
+			if !ok {
+				return nil
+			}
+
+			return newHover(file, attr.NameRange, fmt.Sprintf("**%s** attribute\n\n%s", attr.Name, attributeDetail(attrSchema)))
+		}
+	}
+
+	for _, block := range body.Blocks {
+		if !contains(block.TypeRange, off) {
+			continue
+		}
+
+		blockType, ok := schema.BlockTypes[block.Type]
+		if !ok {
+			return nil
+		}
+
+		doc := fmt.Sprintf("**%s** block", block.Type)
+		if len(blockType.Labels) > 0 {
+			doc += " with labels " + strings.Join(blockType.Labels, ", ")
+		}
+		if blockDoc, ok := blockDocs[block.Type]; ok {
+			doc += "\n\n" + blockDoc
+		}
+
+		return newHover(file, block.TypeRange, doc)
+	}
+
+	return nil
+}
+
+// doc returns the documentation of the target: its source, or the header of a block and any of
+// the attributes that identify it, followed by its description.
+func (t *target) doc() string {
+	src := func(rng hcl.Range) string {
+		return strings.TrimSpace(string(rng.SliceBytes(t.file.text)))
+	}
+
+	if t.attr != nil {
+		return "```hcl\n" + src(t.attr.SrcRange) + "\n```"
+	}
+
+	lines := []string{src(hcl.Range{Start: t.block.TypeRange.Start, End: t.block.OpenBraceRange.Start}) + " {"}
+	for _, name := range []string{"source", "version", "module", "provisioner"} {
+		if attr, ok := t.block.Body.Attributes[name]; ok {
+			lines = append(lines, "  "+src(attr.SrcRange))
+		}
+	}
+	lines = append(lines, "}")
+	doc := "```hcl\n" + strings.Join(lines, "\n") + "\n```"
+
+	if attr, ok := t.block.Body.Attributes["description"]; ok {
+		val, diags := attr.Expr.Value(nil)
+		if !diags.HasErrors() && val.Type() == cty.String && val.IsKnown() && !val.IsNull() {
+			doc += "\n\n" + strings.TrimSpace(val.AsString())
+		}
+	}
+
+	return doc
+}
+
+// attributeDetail returns whether the attribute is required and its type.
+func attributeDetail(attr *flightplan.HCLAttributeSchema) string {
+	detail := "optional"
+	if attr.Required {
+		detail = "required"
+	}
+
+	return detail + " " + typeString(attr.Type)
+}
+
+// typeString returns the JSON encoded cty type as a type expression.
+func typeString(raw json.RawMessage) string {
+	ty, err := ctyjson.UnmarshalType(raw)
+	if err != nil {
+		return string(raw)
+	}
+
+	return typeexpr.TypeString(ty)
+}
+
+func newHover(file *syntaxFile, rng hcl.Range, doc string) *Hover {
+	r := toRange(file.text, rng)
+
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: doc},
+		Range:    &r,
+	}
+}
+
+// contains returns whether the range contains the offset, including its end.
+func contains(rng hcl.Range, off int) bool {
+	return rng.Start.Byte <= off && off <= rng.End.Byte
+}
@@ -0,0 +1,252 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package lsp is a Language Server Protocol server for enos HCL files. It publishes the
+// diagnostics of decoding the flight plan when documents are opened and saved, and completes,
+// resolves, and documents block and attribute names and references from the syntax of the
+// flight plan.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/version"
+)
+
+// Server is a language server.
+type Server struct {
+	log       hclog.Logger
+	env       []string
+	schema    *flightplan.HCLSchema
+	docs      *documents
+	published map[string]map[string]struct{} // the URIs that we've published diagnostics of by directory
+	out       io.Writer
+	outMu     sync.Mutex
+	shutdown  bool
+}
+
+// Opt is a functional option.
+type Opt func(*Server)
+
+// NewServer returns a new language server.
+func NewServer(opts ...Opt) (*Server, error) {
+	schema, err := flightplan.NewHCLSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		log:       hclog.NewNullLogger(),
+		env:       os.Environ(),
+		schema:    schema,
+		docs:      newDocuments(),
+		published: map[string]map[string]struct{}{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// WithLogger sets the logger.
+func WithLogger(log hclog.Logger) Opt {
+	return func(s *Server) {
+		s.log = log
+	}
+}
+
+// WithEnv sets the environment that ENOS_VAR_ variables are decoded from.
+func WithEnv(env []string) Opt {
+	return func(s *Server) {
+		s.env = env
+	}
+}
+
+// Serve reads requests from the reader and writes responses to the writer until the client
+// exits or the context is done.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		msg, err := readMessage(r)
+		if err != nil {
+			var resErr *responseError
+			if errors.As(err, &resErr) {
+				if err := s.write(&message{ID: json.RawMessage("null"), Error: resErr}); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if isClosed(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exited before shutdown")
+			}
+
+			return nil
+		}
+
+		if err := s.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles a request or notification and responds to requests.
+func (s *Server) handle(ctx context.Context, msg *message) error {
+	s.log.Debug("handling message", "method", msg.Method)
+
+	var result any
+	var err error
+	switch msg.Method {
+	case "initialize":
+		result = s.initialize()
+	case "initialized", "$/setTrace", "$/cancelRequest", "workspace/didChangeConfiguration":
+		return nil
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		params := &didOpenParams{}
+		if err = unmarshalParams(msg, params); err == nil {
+			s.docs.set(params.TextDocument.URI, []byte(params.TextDocument.Text))
+			s.publishDiagnostics(ctx, params.TextDocument.URI)
+		}
+	case "textDocument/didChange":
+		params := &didChangeParams{}
+		if err = unmarshalParams(msg, params); err == nil && len(params.ContentChanges) > 0 {
+			// We only support full document sync so the last change is the entire document.
+			s.docs.set(params.TextDocument.URI, []byte(params.ContentChanges[len(params.ContentChanges)-1].Text))
+		}
+	case "textDocument/didSave":
+		params := &didSaveParams{}
+		if err = unmarshalParams(msg, params); err == nil {
+			if params.Text != nil {
+				s.docs.set(params.TextDocument.URI, []byte(*params.Text))
+			}
+			s.publishDiagnostics(ctx, params.TextDocument.URI)
+		}
+	case "textDocument/didClose":
+		params := &didCloseParams{}
+		if err = unmarshalParams(msg, params); err == nil {
+			s.docs.remove(params.TextDocument.URI)
+		}
+	case "textDocument/completion":
+		params := &textDocumentPositionParams{}
+		if err = unmarshalParams(msg, params); err == nil {
+			result = s.completion(params.TextDocument.URI, params.Position)
+		}
+	case "textDocument/definition":
+		params := &textDocumentPositionParams{}
+		if err = unmarshalParams(msg, params); err == nil {
+			result = s.definition(params.TextDocument.URI, params.Position)
+		}
+	case "textDocument/hover":
+		params := &textDocumentPositionParams{}
+		if err = unmarshalParams(msg, params); err == nil {
+			result = s.hover(params.TextDocument.URI, params.Position)
+		}
+	default:
+		if msg.isNotification() {
+			return nil
+		}
+		err = &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+
+	if msg.isNotification() {
+		if err != nil {
+			s.log.Error("handling notification", "method", msg.Method, "error", err)
+		}
+
+		return nil
+	}
+
+	res := &message{ID: msg.ID, Result: result}
+	if err != nil {
+		var resErr *responseError
+		if !errors.As(err, &resErr) {
+			resErr = &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		res.Error = resErr
+		res.Result = nil
+	} else if result == nil {
+		res.Result = json.RawMessage("null")
+	}
+
+	return s.write(res)
+}
+
+// initialize returns the capabilities of the server.
+func (s *Server) initialize() any {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync": map[string]any{
+				"openClose": true,
+				"change":    1, // full
+				"save":      map[string]any{"includeText": true},
+			},
+			"completionProvider": map[string]any{
+				"triggerCharacters": []string{"."},
+			},
+			"definitionProvider": true,
+			"hoverProvider":      true,
+		},
+		"serverInfo": map[string]any{
+			"name":    "enos",
+			"version": version.Version,
+		},
+	}
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params any) {
+	b, err := json.Marshal(params)
+	if err != nil {
+		s.log.Error("marshaling notification", "method", method, "error", err)
+		return
+	}
+
+	if err := s.write(&message{Method: method, Params: b}); err != nil {
+		s.log.Error("sending notification", "method", method, "error", err)
+	}
+}
+
+func (s *Server) write(msg *message) error {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+
+	return writeMessage(s.out, msg)
+}
+
+func unmarshalParams(msg *message, params any) error {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testFlightPlan = `module "infra" {
+  source = "./modules/infra"
+}
+
+module "backend" {
+  source  = "./modules/backend"
+  version = "1.0.0"
+}
+
+scenario "upgrade" {
+  matrix {
+    arch = ["amd64", "arm64"]
+  }
+
+  locals {
+    size = 3
+  }
+
+  step "infra" {
+    description = "Create the infrastructure"
+    module      = module.infra
+  }
+
+  step "backend" {
+    module = module.backend
+
+    variables {
+      arch   = matrix.arch
+      vpc_id = step.infra.vpc_id
+    }
+  }
+}
+`
+
+// testClient is a language client of a test server.
+type testClient struct {
+	t   *testing.T
+	in  io.WriteCloser
+	out chan *message
+	id  int
+	// notifications that have been received while waiting for responses
+	notifications []*message
+	done          chan error
+}
+
+func newTestClient(t *testing.T) *testClient {
+	t.Helper()
+
+	s, err := NewServer(WithEnv([]string{}))
+	require.NoError(t, err)
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &testClient{t: t, in: inW, out: make(chan *message, 100), done: make(chan error, 1)}
+	go func() {
+		c.done <- s.Serve(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	// Pipes are synchronous so we have to read while we write.
+	go func() {
+		defer close(c.out)
+		r := bufio.NewReader(outR)
+		for {
+			msg, err := readMessage(r)
+			if err != nil {
+				return
+			}
+			c.out <- msg
+		}
+	}()
+	t.Cleanup(func() { inW.Close() })
+
+	return c
+}
+
+func (c *testClient) send(method string, params any, notification bool) json.RawMessage {
+	c.t.Helper()
+
+	b, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	msg := &message{Method: method, Params: b}
+	if !notification {
+		c.id++
+		msg.ID = json.RawMessage(strings.TrimSpace(string(mustMarshal(c.t, c.id))))
+	}
+	require.NoError(c.t, writeMessage(c.in, msg))
+
+	if notification {
+		return nil
+	}
+
+	for res := range c.out {
+		if res.isNotification() {
+			c.notifications = append(c.notifications, res)
+			continue
+		}
+		require.Nil(c.t, res.Error)
+
+		b, err := json.Marshal(res.Result)
+		require.NoError(c.t, err)
+
+		return b
+	}
+	c.t.Fatal("the server closed the connection")
+
+	return nil
+}
+
+func (c *testClient) request(method string, params any, result any) {
+	c.t.Helper()
+
+	require.NoError(c.t, json.Unmarshal(c.send(method, params, false), result))
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return b
+}
+
+func positionParams(uri string, line, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+// Test_Server tests the language server over its protocol.
+func Test_Server(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "enos.hcl")
+	require.NoError(t, os.WriteFile(path, []byte(testFlightPlan), 0o644))
+	for _, module := range []string{"infra", "backend"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "modules", module), 0o755))
+	}
+	uri := pathToURI(path)
+	lines := strings.Split(testFlightPlan, "\n")
+	lineOf := func(substr string) int {
+		for i, line := range lines {
+			if strings.Contains(line, substr) {
+				return i
+			}
+		}
+		t.Fatalf("%s is not in the flight plan", substr)
+
+		return 0
+	}
+
+	c := newTestClient(t)
+
+	init := map[string]any{}
+	c.request("initialize", map[string]any{}, &init)
+	require.Contains(t, init, "capabilities")
+	c.send("initialized", map[string]any{}, true)
+
+	t.Run("diagnostics", func(t *testing.T) {
+		c.send("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{"uri": uri, "text": testFlightPlan + "\nscenario \"broken\" {\n  step \"one\" {}\n}\n"},
+		}, true)
+		// Wait for the diagnostics by sending a request.
+		c.request("textDocument/hover", positionParams(uri, 0, 0), &json.RawMessage{})
+
+		var params *publishDiagnosticsParams
+		for _, n := range c.notifications {
+			if n.Method == "textDocument/publishDiagnostics" {
+				params = &publishDiagnosticsParams{}
+				require.NoError(t, json.Unmarshal(n.Params, params))
+			}
+		}
+		require.NotNil(t, params)
+		require.Equal(t, uri, params.URI)
+		require.Len(t, params.Diagnostics, 1)
+		require.Equal(t, severityError, params.Diagnostics[0].Severity)
+		require.Equal(t, len(lines)+1, params.Diagnostics[0].Range.Start.Line)
+
+		// Saving the fixed document clears them.
+		c.notifications = nil
+		c.send("textDocument/didSave", map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"text":         testFlightPlan,
+		}, true)
+		c.request("textDocument/hover", positionParams(uri, 0, 0), &json.RawMessage{})
+		require.Len(t, c.notifications, 1)
+		params = &publishDiagnosticsParams{}
+		require.NoError(t, json.Unmarshal(c.notifications[0].Params, params))
+		require.Empty(t, params.Diagnostics)
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		for desc, test := range map[string]struct {
+			text     string
+			line     string
+			expected []string
+		}{
+			"module references": {
+				text:     "    module      = module.",
+				line:     `module      = module.infra`,
+				expected: []string{"infra", "backend"},
+			},
+			"step references": {
+				text:     "      vpc_id = step.",
+				line:     `vpc_id = step.infra.vpc_id`,
+				expected: []string{"infra", "backend"},
+			},
+			"matrix references": {
+				text:     "      arch   = matrix.ar",
+				line:     `arch   = matrix.arch`,
+				expected: []string{"arch"},
+			},
+			"local references": {
+				text:     "      arch   = local.",
+				line:     `arch   = matrix.arch`,
+				expected: []string{"size"},
+			},
+			"step attributes and blocks": {
+				text:     "    desc",
+				line:     `module      = module.infra`,
+				expected: []string{"depends_on", "module", "outputs", "providers", "provisioner", "skip_step", "verifies", "variables"},
+			},
+		} {
+			t.Run(desc, func(t *testing.T) {
+				line := lineOf(test.line)
+				edited := append(append(append([]string{}, lines[:line]...), test.text), lines[line+1:]...)
+				c.send("textDocument/didChange", map[string]any{
+					"textDocument":   map[string]any{"uri": uri},
+					"contentChanges": []map[string]any{{"text": strings.Join(edited, "\n")}},
+				}, true)
+
+				items := []CompletionItem{}
+				c.request("textDocument/completion", positionParams(uri, line, len(test.text)), &items)
+				labels := []string{}
+				for _, item := range items {
+					labels = append(labels, item.Label)
+				}
+				require.Equal(t, test.expected, labels)
+			})
+		}
+
+		c.send("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri},
+			"contentChanges": []map[string]any{{"text": testFlightPlan}},
+		}, true)
+	})
+
+	t.Run("definition", func(t *testing.T) {
+		for desc, test := range map[string]struct {
+			line     string
+			ref      string
+			expected string
+		}{
+			"module": {`module      = module.infra`, "module.infra", `module "infra" {`},
+			"step":   {`vpc_id = step.infra.vpc_id`, "step.infra", `step "infra" {`},
+			"matrix": {`arch   = matrix.arch`, "matrix.arch", `arch = ["amd64", "arm64"]`},
+		} {
+			t.Run(desc, func(t *testing.T) {
+				line := lineOf(test.line)
+				locs := []Location{}
+				c.request("textDocument/definition", positionParams(uri, line, strings.Index(lines[line], test.ref)+len(test.ref)-1), &locs)
+				require.Len(t, locs, 1)
+				require.Equal(t, uri, locs[0].URI)
+				require.Equal(t, lineOf(test.expected), locs[0].Range.Start.Line)
+			})
+		}
+	})
+
+	t.Run("hover", func(t *testing.T) {
+		line := lineOf(`module      = module.infra`)
+		hover := &Hover{}
+		c.request("textDocument/hover", positionParams(uri, lineOf(`module = module.backend`), 14), hover)
+		require.Equal(t, "```hcl\nmodule \"backend\" {\n  source  = \"./modules/backend\"\n  version = \"1.0.0\"\n}\n```", hover.Contents.Value)
+
+		c.request("textDocument/hover", positionParams(uri, lineOf(`vpc_id = step.infra`), 20), hover)
+		require.Contains(t, hover.Contents.Value, "Create the infrastructure")
+
+		c.request("textDocument/hover", positionParams(uri, line, 5), hover)
+		require.Equal(t, "**module** attribute\n\noptional any", hover.Contents.Value)
+
+		c.request("textDocument/hover", positionParams(uri, lineOf(`scenario "upgrade"`), 2), hover)
+		require.Contains(t, hover.Contents.Value, "**scenario** block with labels name")
+	})
+
+	c.request("shutdown", nil, &json.RawMessage{})
+	c.send("exit", nil, true)
+	require.NoError(t, <-c.done)
+}
@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lsp
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+)
+
+// syntaxFile is the syntax of a file. Documents are analyzed by their syntax rather than by
+// decoding them so that they can be analyzed while they're being edited.
+type syntaxFile struct {
+	path string
+	text []byte
+	body *hclsyntax.Body
+}
+
+// parse parses the text of the file. Files that fail to parse have a partial body.
+func parse(path string, text []byte) *syntaxFile {
+	f := &syntaxFile{path: path, text: text, body: &hclsyntax.Body{}}
+
+	file, _ := hclsyntax.ParseConfig(text, path, hcl.InitialPos)
+	if file != nil {
+		if body, ok := file.Body.(*hclsyntax.Body); ok {
+			f.body = body
+		}
+	}
+
+	return f
+}
+
+// flightPlanFiles returns the syntax of every flight plan file in the directory.
+func (s *Server) flightPlanFiles(dir string) []*syntaxFile {
+	raw := s.rawFiles(dir, flightplan.FlightPlanFileNamePattern)
+	paths := []string{}
+	for path := range raw {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	files := []*syntaxFile{}
+	for _, path := range paths {
+		files = append(files, parse(path, raw[path]))
+	}
+
+	return files
+}
+
+// blocksAt returns the blocks whose bodies contain the offset, from the outermost to the
+// innermost.
+func blocksAt(body *hclsyntax.Body, off int) []*hclsyntax.Block {
+	blocks := []*hclsyntax.Block{}
+	for {
+		var found *hclsyntax.Block
+		for _, block := range body.Blocks {
+			if block.OpenBraceRange.Start.Byte < off && off <= block.CloseBraceRange.Start.Byte {
+				found = block
+				break
+			}
+		}
+		if found == nil {
+			return blocks
+		}
+
+		blocks = append(blocks, found)
+		body = found.Body
+	}
+}
+
+// innermostBody returns the body of the innermost block or the file.
+func innermostBody(file *syntaxFile, blocks []*hclsyntax.Block) *hclsyntax.Body {
+	if len(blocks) == 0 {
+		return file.body
+	}
+
+	return blocks[len(blocks)-1].Body
+}
+
+// enclosingBlock returns the innermost block of the type.
+func enclosingBlock(blocks []*hclsyntax.Block, typ string) *hclsyntax.Block {
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if blocks[i].Type == typ {
+			return blocks[i]
+		}
+	}
+
+	return nil
+}
+
+// blocksOfType returns the blocks of the body of the type, optionally with the labels.
+func blocksOfType(body *hclsyntax.Body, typ string, labels ...string) []*hclsyntax.Block {
+	blocks := []*hclsyntax.Block{}
+	if body == nil {
+		return blocks
+	}
+
+	for _, block := range body.Blocks {
+		if block.Type != typ || len(block.Labels) < len(labels) {
+			continue
+		}
+		if slices.Equal(block.Labels[:len(labels)], labels) {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks
+}
+
+// matrixAttributes returns the variants of matrix blocks, including those of include blocks.
+func matrixAttributes(scenario *hclsyntax.Block) []*hclsyntax.Attribute {
+	attrs := []*hclsyntax.Attribute{}
+	if scenario == nil {
+		return attrs
+	}
+
+	for _, matrix := range blocksOfType(scenario.Body, "matrix") {
+		attrs = append(attrs, sortedAttributes(matrix.Body)...)
+		for _, include := range blocksOfType(matrix.Body, "include") {
+			attrs = append(attrs, sortedAttributes(include.Body)...)
+		}
+	}
+
+	return attrs
+}
+
+// sortedAttributes returns the attributes of the body in the order they're defined.
+func sortedAttributes(body *hclsyntax.Body) []*hclsyntax.Attribute {
+	attrs := []*hclsyntax.Attribute{}
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+	slices.SortFunc(attrs, func(a, b *hclsyntax.Attribute) int {
+		return a.SrcRange.Start.Byte - b.SrcRange.Start.Byte
+	})
+
+	return attrs
+}
+
+// traversalAt returns the traversal whose range contains the offset.
+func traversalAt(body *hclsyntax.Body, off int) (hcl.Traversal, hcl.Range) {
+	var traversal hcl.Traversal
+	var rng hcl.Range
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		expr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+		if ok && contains(expr.SrcRange, off) {
+			traversal = expr.Traversal
+			rng = expr.SrcRange
+		}
+
+		return nil
+	})
+
+	return traversal, rng
+}
+
+// schemaAt returns the schema of the innermost block, or of the file, if the file is an enos
+// HCL file and the blocks are in its schema.
+func (s *Server) schemaAt(path string, blocks []*hclsyntax.Block) *flightplan.HCLBlockSchema {
+	var schema *flightplan.HCLBlockSchema
+	for _, file := range s.schema.Files {
+		pattern, err := regexp.Compile(file.Pattern)
+		if err == nil && pattern.MatchString(filepath.Base(path)) {
+			schema = file.Body
+			break
+		}
+	}
+
+	for _, block := range blocks {
+		if schema == nil {
+			return nil
+		}
+		schema = blockSchema(schema, block)
+	}
+
+	return schema
+}
+
+// blockSchema returns the schema of the body of a block of the parent schema.
+func blockSchema(parent *flightplan.HCLBlockSchema, block *hclsyntax.Block) *flightplan.HCLBlockSchema {
+	blockType, ok := parent.BlockTypes[block.Type]
+	if !ok {
+		return nil
+	}
+
+	if blockType.LabelBlocks != nil {
+		if len(block.Labels) == 0 {
+			return nil
+		}
+
+		return blockType.LabelBlocks[block.Labels[0]]
+	}
+
+	return blockType.Block
+}
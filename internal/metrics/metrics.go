@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package metrics is the interface that the server emits the metrics of decodes and operations
+// with, and the sinks that send them to monitoring systems.
+package metrics
+
+import (
+	"time"
+)
+
+// Sink receives metrics. Sinks must be safe to use concurrently and must never block the caller
+// on the monitoring system, metrics are best effort.
+type Sink interface {
+	// IncrCounter increments the counter by the value.
+	IncrCounter(name string, val float64, tags ...Tag)
+	// AddTiming adds a sample of the duration of something, e.g. a decode.
+	AddTiming(name string, dur time.Duration, tags ...Tag)
+	// Close flushes and closes the sink.
+	Close() error
+}
+
+// Tag is a dimension of a metric, e.g. the type of an operation.
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Discard is a Sink that discards every metric.
+var Discard Sink = discard{}
+
+type discard struct{}
+
+func (discard) IncrCounter(string, float64, ...Tag)     {}
+func (discard) AddTiming(string, time.Duration, ...Tag) {}
+func (discard) Close() error                            { return nil }
+
+// Names of the metrics.
+const (
+	// OperationCount counts completed operations, tagged with their type and status.
+	OperationCount = "operation.count"
+	// OperationDuration is the duration of completed operations, tagged with their type and status.
+	OperationDuration = "operation.duration"
+	// DecodeDuration is the duration of flight plan decodes, tagged with the method of the request.
+	DecodeDuration = "decode.duration"
+	// DecodeBlockDuration is the total duration of decoding the blocks of a type during a decode,
+	// tagged with the method of the request and the block type.
+	DecodeBlockDuration = "decode.block.duration"
+)
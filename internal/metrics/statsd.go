@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultStatsDAddress is the address of a local StatsD server.
+	DefaultStatsDAddress = "127.0.0.1:8125"
+	// DefaultStatsDPrefix is the prefix of the names of metrics.
+	DefaultStatsDPrefix = "enos"
+)
+
+var _ Sink = (*StatsD)(nil)
+
+// StatsD sends metrics to a StatsD server over UDP. Plain StatsD doesn't support tags so the values
+// of the tags are appended to the name of the metric in order, e.g. the duration of a completed run
+// operation is enos.operation.duration.run.completed.
+type StatsD struct {
+	addr   string
+	prefix string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// StatsDOpt is a functional option.
+type StatsDOpt func(*StatsD)
+
+// NewStatsD takes options and returns a new StatsD sink that is connected to the server.
+func NewStatsD(opts ...StatsDOpt) (*StatsD, error) {
+	s := &StatsD{
+		addr:   DefaultStatsDAddress,
+		prefix: DefaultStatsDPrefix,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	var err error
+	s.conn, err = net.Dial("udp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to statsd: %w", err)
+	}
+
+	return s, nil
+}
+
+// WithStatsDAddress sets the host and port of the StatsD server.
+func WithStatsDAddress(addr string) StatsDOpt {
+	return func(s *StatsD) {
+		if addr != "" {
+			s.addr = addr
+		}
+	}
+}
+
+// WithStatsDPrefix sets the prefix of the names of metrics. An empty prefix doesn't prefix them.
+func WithStatsDPrefix(prefix string) StatsDOpt {
+	return func(s *StatsD) {
+		s.prefix = strings.TrimSuffix(prefix, ".")
+	}
+}
+
+// IncrCounter increments the counter by the value.
+func (s *StatsD) IncrCounter(name string, val float64, tags ...Tag) {
+	s.send(name, strconv.FormatFloat(val, 'f', -1, 64), "c", tags)
+}
+
+// AddTiming adds a sample of the duration in milliseconds.
+func (s *StatsD) AddTiming(name string, dur time.Duration, tags ...Tag) {
+	ms := float64(dur) / float64(time.Millisecond)
+	s.send(name, strconv.FormatFloat(ms, 'f', -1, 64), "ms", tags)
+}
+
+// Close closes the connection to the server.
+func (s *StatsD) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
+
+// send sends the metric. Metrics are sent as they're emitted rather than buffered as there are
+// only a few per operation. Write errors are ignored because UDP is best effort anyway.
+func (s *StatsD) send(name string, val string, typ string, tags []Tag) {
+	parts := []string{}
+	if s.prefix != "" {
+		parts = append(parts, s.prefix)
+	}
+	parts = append(parts, name)
+	for _, tag := range tags {
+		parts = append(parts, sanitizeStatsD(tag.Value))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return
+	}
+
+	_, _ = s.conn.Write([]byte(strings.Join(parts, ".") + ":" + val + "|" + typ))
+}
+
+// sanitizeStatsD replaces the characters that have a meaning in the StatsD protocol or in the
+// names of metrics, e.g. in the name of a block.
+func sanitizeStatsD(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', '.', ' ', '\n', '#':
+			return '_'
+		default:
+			return r
+		}
+	}, s)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_StatsD tests sending counters and timings to a StatsD server.
+func Test_StatsD(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	read := func() string {
+		buf := make([]byte, 1024)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+
+		return string(buf[:n])
+	}
+
+	sink, err := NewStatsD(WithStatsDAddress(conn.LocalAddr().String()))
+	require.NoError(t, err)
+
+	sink.IncrCounter(OperationCount, 1, Tag{Name: "type", Value: "run"}, Tag{Name: "status", Value: "completed"})
+	require.Equal(t, "enos.operation.count.run.completed:1|c", read())
+
+	sink.AddTiming(DecodeBlockDuration, 1500*time.Microsecond,
+		Tag{Name: "method", Value: "ListScenarios"},
+		Tag{Name: "block", Value: "scenario.step"},
+	)
+	require.Equal(t, "enos.decode.block.duration.ListScenarios.scenario_step:1.5|ms", read())
+	require.NoError(t, sink.Close())
+
+	sink, err = NewStatsD(WithStatsDAddress(conn.LocalAddr().String()), WithStatsDPrefix("ci.enos."))
+	require.NoError(t, err)
+	t.Cleanup(func() { sink.Close() })
+
+	sink.AddTiming(DecodeDuration, 2*time.Second, Tag{Name: "method", Value: ""})
+	require.Equal(t, "ci.enos.decode.duration.unknown:2000|ms", read())
+}
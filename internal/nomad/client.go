@@ -0,0 +1,255 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package nomad executes scenario operations by dispatching parameterized Nomad jobs.
+package nomad
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAddress is the address of the Nomad API if neither the address nor NOMAD_ADDR are set.
+const DefaultAddress = "http://127.0.0.1:4646"
+
+// Statuses of allocations.
+const (
+	AllocClientStatusPending  = "pending"
+	AllocClientStatusRunning  = "running"
+	AllocClientStatusComplete = "complete"
+	AllocClientStatusFailed   = "failed"
+	AllocClientStatusLost     = "lost"
+)
+
+// Client is a minimal client of the Nomad HTTP API.
+type Client struct {
+	address   string
+	namespace string
+	region    string
+	token     string
+	client    *http.Client
+}
+
+// ClientOpt is a functional option.
+type ClientOpt func(*Client)
+
+// NewClient takes options and returns a new client. The address, namespace, region, and token
+// default to the NOMAD_ADDR, NOMAD_NAMESPACE, NOMAD_REGION, and NOMAD_TOKEN environment variables
+// of the getenv func.
+func NewClient(getenv func(string) string, opts ...ClientOpt) *Client {
+	c := &Client{
+		address:   getenv("NOMAD_ADDR"),
+		namespace: getenv("NOMAD_NAMESPACE"),
+		region:    getenv("NOMAD_REGION"),
+		token:     getenv("NOMAD_TOKEN"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.address == "" {
+		c.address = DefaultAddress
+	}
+
+	return c
+}
+
+// WithClientAddress sets the address of the Nomad API.
+func WithClientAddress(addr string) ClientOpt {
+	return func(c *Client) {
+		if addr != "" {
+			c.address = addr
+		}
+	}
+}
+
+// WithClientNamespace sets the namespace of requests.
+func WithClientNamespace(namespace string) ClientOpt {
+	return func(c *Client) {
+		if namespace != "" {
+			c.namespace = namespace
+		}
+	}
+}
+
+// WithClientRegion sets the region of requests.
+func WithClientRegion(region string) ClientOpt {
+	return func(c *Client) {
+		if region != "" {
+			c.region = region
+		}
+	}
+}
+
+// WithClientHTTPClient sets the HTTP client of requests.
+func WithClientHTTPClient(client *http.Client) ClientOpt {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// DispatchResponse is the response of dispatching a parameterized job.
+type DispatchResponse struct {
+	DispatchedJobID string `json:"DispatchedJobID"`
+	EvalID          string `json:"EvalID"`
+}
+
+// Allocation is an allocation of a job.
+type Allocation struct {
+	ID                string                `json:"ID"`
+	CreateIndex       uint64                `json:"CreateIndex"`
+	ClientStatus      string                `json:"ClientStatus"`
+	ClientDescription string                `json:"ClientDescription"`
+	TaskStates        map[string]*TaskState `json:"TaskStates"`
+}
+
+// TaskState is the state of a task of an allocation.
+type TaskState struct {
+	State  string       `json:"State"`
+	Failed bool         `json:"Failed"`
+	Events []*TaskEvent `json:"Events"`
+}
+
+// TaskEvent is an event of a task.
+type TaskEvent struct {
+	Type           string `json:"Type"`
+	DisplayMessage string `json:"DisplayMessage"`
+}
+
+// IsTerminal returns whether the allocation has stopped.
+func (a *Allocation) IsTerminal() bool {
+	switch a.ClientStatus {
+	case AllocClientStatusComplete, AllocClientStatusFailed, AllocClientStatusLost:
+		return true
+	default:
+		return false
+	}
+}
+
+// Dispatch dispatches the parameterized job with the payload.
+func (c *Client) Dispatch(ctx context.Context, job string, payload []byte) (*DispatchResponse, error) {
+	res := &DispatchResponse{}
+	err := c.do(ctx, http.MethodPost, "/v1/job/"+url.PathEscape(job)+"/dispatch", nil,
+		map[string]any{"Payload": payload}, res,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("dispatching job %s: %w", job, err)
+	}
+
+	return res, nil
+}
+
+// JobAllocations returns the allocations of the job.
+func (c *Client) JobAllocations(ctx context.Context, job string) ([]*Allocation, error) {
+	allocs := []*Allocation{}
+	err := c.do(ctx, http.MethodGet, "/v1/job/"+url.PathEscape(job)+"/allocations", nil, nil, &allocs)
+	if err != nil {
+		return nil, fmt.Errorf("listing allocations of job %s: %w", job, err)
+	}
+
+	return allocs, nil
+}
+
+// Logs returns the stdout of the task of the allocation from the offset.
+func (c *Client) Logs(ctx context.Context, allocID string, task string, offset int64) ([]byte, error) {
+	query := url.Values{}
+	query.Set("task", task)
+	query.Set("type", "stdout")
+	query.Set("origin", "start")
+	query.Set("offset", strconv.FormatInt(offset, 10))
+	query.Set("plain", "true")
+
+	buf := &bytes.Buffer{}
+	err := c.do(ctx, http.MethodGet, "/v1/client/fs/logs/"+url.PathEscape(allocID), query, nil, buf)
+	if err != nil {
+		return nil, fmt.Errorf("reading logs of task %s of allocation %s: %w", task, allocID, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// StopJob stops the job.
+func (c *Client) StopJob(ctx context.Context, job string) error {
+	err := c.do(ctx, http.MethodDelete, "/v1/job/"+url.PathEscape(job), nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("stopping job %s: %w", job, err)
+	}
+
+	return nil
+}
+
+// do executes the request and decodes the JSON response into out, or copies it if out is a buffer.
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, in any, out any) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	if c.namespace != "" {
+		query.Set("namespace", c.namespace)
+	}
+	if c.region != "" {
+		query.Set("region", c.region)
+	}
+
+	u := strings.TrimSuffix(c.address, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("X-Nomad-Token", c.token)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+
+		return fmt.Errorf("nomad API responded with %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	switch o := out.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		_, err = io.Copy(o, res.Body)
+
+		return err
+	default:
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		return nil
+	}
+}
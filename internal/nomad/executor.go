@@ -0,0 +1,334 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/operation"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+// MaxPayloadSize is the maximum size of the payloads of dispatched jobs that Nomad allows.
+const MaxPayloadSize = 16 * 1024
+
+// DefaultPollInterval is how often the allocations and logs of dispatched jobs are polled.
+const DefaultPollInterval = 2 * time.Second
+
+var _ operation.Executor = (*Executor)(nil)
+
+// Executor executes operations by dispatching a parameterized Nomad job for each operation. The
+// payload of the job is the gzipped JSON operation request. The job is expected to execute it
+// with "enos operation execute", whose stdout has the events and the response of the operation,
+// which the executor reads from the logs of the allocation while it tracks its status.
+type Executor struct {
+	client       *Client
+	job          string
+	task         string
+	pollInterval time.Duration
+}
+
+// ExecutorOpt is a functional option.
+type ExecutorOpt func(*Executor)
+
+// NewExecutor takes options and returns a new Nomad executor.
+func NewExecutor(opts ...ExecutorOpt) (*Executor, error) {
+	e := &Executor{
+		pollInterval: DefaultPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.job == "" {
+		return nil, errors.New("unable to execute operations in Nomad without a job")
+	}
+
+	if e.client == nil {
+		e.client = NewClient(os.Getenv)
+	}
+
+	return e, nil
+}
+
+// WithExecutorConfig configures the executor and its client with the Nomad operator config.
+func WithExecutorConfig(cfg *pb.Operator_Nomad) ExecutorOpt {
+	return func(e *Executor) {
+		e.job = cfg.GetJob()
+		e.task = cfg.GetTask()
+		e.client = NewClient(os.Getenv,
+			WithClientAddress(cfg.GetAddress()),
+			WithClientNamespace(cfg.GetNamespace()),
+			WithClientRegion(cfg.GetRegion()),
+		)
+	}
+}
+
+// WithExecutorClient sets the Nomad client.
+func WithExecutorClient(client *Client) ExecutorOpt {
+	return func(e *Executor) {
+		e.client = client
+	}
+}
+
+// WithExecutorJob sets the ID of the parameterized job that is dispatched.
+func WithExecutorJob(job string) ExecutorOpt {
+	return func(e *Executor) {
+		e.job = job
+	}
+}
+
+// WithExecutorTask sets the name of the task that executes operations.
+func WithExecutorTask(task string) ExecutorOpt {
+	return func(e *Executor) {
+		e.task = task
+	}
+}
+
+// WithExecutorPollInterval sets how often the allocations and logs of dispatched jobs are polled.
+func WithExecutorPollInterval(interval time.Duration) ExecutorOpt {
+	return func(e *Executor) {
+		e.pollInterval = interval
+	}
+}
+
+// WorkFunc returns the work function that executes the operation request in a dispatched job.
+func (e *Executor) WorkFunc(req *pb.Operation_Request) (operation.WorkFunc, error) {
+	// Make sure that we know how to execute the request before we dispatch it.
+	if _, err := operation.NewLocalExecutor().WorkFunc(req); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, events chan *pb.Operation_Event, log hclog.Logger) *pb.Operation_Response {
+		res, err := e.execute(ctx, req, events, log)
+		if err == nil {
+			return res
+		}
+
+		res, _ = operation.NewResponseFromRequest(req)
+		res.Status = pb.Operation_STATUS_FAILED
+		if ctx.Err() != nil {
+			res.Status = pb.Operation_STATUS_CANCELLED
+		}
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+
+		return res
+	}, nil
+}
+
+// execute dispatches the job for the request and forwards the events of the operation until its
+// allocation stops.
+func (e *Executor) execute(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	events chan *pb.Operation_Event,
+	log hclog.Logger,
+) (*pb.Operation_Response, error) {
+	payload, err := operation.MarshalRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) > MaxPayloadSize {
+		return nil, fmt.Errorf("the operation request is %d bytes compressed, which exceeds the %d byte limit of Nomad dispatch payloads",
+			len(payload), MaxPayloadSize,
+		)
+	}
+
+	dispatch, err := e.client.Dispatch(ctx, e.job, payload)
+	if err != nil {
+		return nil, err
+	}
+	job := dispatch.DispatchedJobID
+	log = log.With("nomad_job", job)
+	log.Info("dispatched operation to nomad")
+
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	logs := &allocationLogs{}
+	var res *pb.Operation_Response
+
+	for {
+		select {
+		case <-ctx.Done():
+			stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := e.client.StopJob(stopCtx, job); err != nil {
+				log.Error("unable to stop job of cancelled operation", "error", err)
+			}
+
+			return nil, fmt.Errorf("operation was cancelled, stopped job %s: %w", job, ctx.Err())
+		case <-ticker.C:
+		}
+
+		allocs, err := e.client.JobAllocations(ctx, job)
+		if err != nil {
+			log.Warn("unable to list allocations", "error", err)
+
+			continue
+		}
+
+		alloc := latestAllocation(allocs)
+		if alloc == nil {
+			continue
+		}
+
+		// Nomad might replace the allocation, in which case we start over with the new one.
+		if alloc.ID != logs.allocID {
+			log.Debug("tracking allocation", "allocation", alloc.ID, "status", alloc.ClientStatus)
+			logs = &allocationLogs{allocID: alloc.ID}
+		}
+
+		task, err := e.taskName(alloc)
+		if err != nil {
+			return nil, err
+		}
+
+		if state, ok := alloc.TaskStates[task]; ok && state.State != "pending" {
+			r, err := e.readLogs(ctx, alloc, task, logs, events, log)
+			if err != nil {
+				return nil, err
+			}
+			if r != nil {
+				res = r
+			}
+		}
+
+		if !alloc.IsTerminal() {
+			continue
+		}
+
+		log.Debug("allocation stopped", "allocation", alloc.ID, "status", alloc.ClientStatus)
+		if res == nil {
+			return nil, fmt.Errorf("allocation %s of job %s is %s without the response of the operation%s",
+				alloc.ID, job, alloc.ClientStatus, taskMessage(alloc, task),
+			)
+		}
+
+		return res, nil
+	}
+}
+
+// allocationLogs tracks how much of the logs of an allocation have been read. Pending are the
+// bytes of incomplete lines.
+type allocationLogs struct {
+	allocID string
+	offset  int64
+	pending []byte
+}
+
+// readLogs reads the logs of the task from the offset and forwards the events that they have. It
+// returns the response of the operation if the logs have it. Logs of allocations that have stopped
+// are read until they end, logs of running allocations only once per poll.
+func (e *Executor) readLogs(
+	ctx context.Context,
+	alloc *Allocation,
+	task string,
+	logs *allocationLogs,
+	events chan *pb.Operation_Event,
+	log hclog.Logger,
+) (*pb.Operation_Response, error) {
+	var res *pb.Operation_Response
+
+	for {
+		out, err := e.client.Logs(ctx, alloc.ID, task, logs.offset)
+		if err != nil {
+			log.Debug("unable to read logs", "error", err)
+
+			return res, nil
+		}
+
+		logs.offset += int64(len(out))
+		logs.pending = append(logs.pending, out...)
+
+		evts, r, n, err := operation.DecodeRemoteOutput(logs.pending)
+		if err != nil {
+			return nil, fmt.Errorf("decoding output of allocation %s: %w", alloc.ID, err)
+		}
+		logs.pending = logs.pending[n:]
+		if r != nil {
+			res = r
+		}
+
+		for _, event := range evts {
+			select {
+			case <-ctx.Done():
+				return res, nil
+			case events <- event:
+			}
+		}
+
+		if len(out) == 0 || !alloc.IsTerminal() {
+			return res, nil
+		}
+	}
+}
+
+// taskName returns the name of the task that executes the operation, which is either the
+// configured task or the only task of the allocation.
+func (e *Executor) taskName(alloc *Allocation) (string, error) {
+	if e.task != "" {
+		return e.task, nil
+	}
+
+	if len(alloc.TaskStates) == 1 {
+		for name := range alloc.TaskStates {
+			return name, nil
+		}
+	}
+
+	if len(alloc.TaskStates) == 0 {
+		return "", nil
+	}
+
+	names := []string{}
+	for name := range alloc.TaskStates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return "", fmt.Errorf("job %s has the tasks %s, the task that executes operations must be configured",
+		e.job, strings.Join(names, ", "),
+	)
+}
+
+// latestAllocation returns the most recently created allocation.
+func latestAllocation(allocs []*Allocation) *Allocation {
+	var latest *Allocation
+	for _, alloc := range allocs {
+		if latest == nil || alloc.CreateIndex > latest.CreateIndex {
+			latest = alloc
+		}
+	}
+
+	return latest
+}
+
+// taskMessage returns the last message of the events of the task, or the description of the
+// allocation, to explain why it stopped.
+func taskMessage(alloc *Allocation, task string) string {
+	if state, ok := alloc.TaskStates[task]; ok {
+		for i := len(state.Events) - 1; i >= 0; i-- {
+			if msg := state.Events[i].DisplayMessage; msg != "" {
+				return ": " + msg
+			}
+		}
+	}
+
+	if alloc.ClientDescription != "" {
+		return ": " + alloc.ClientDescription
+	}
+
+	return ""
+}
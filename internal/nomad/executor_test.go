@@ -0,0 +1,300 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/hashicorp/enos/internal/operation"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+// fakeNomad is a fake Nomad API with a single parameterized job. The allocation of dispatched jobs
+// runs for the number of polls and its logs are returned in chunks.
+type fakeNomad struct {
+	mu       sync.Mutex
+	t        *testing.T
+	polls    int
+	runFor   int
+	status   string
+	logs     string
+	chunk    int
+	payload  []byte
+	stopped  bool
+	tokens   []string
+	tasks    []string
+	failLogs bool
+}
+
+func (f *fakeNomad) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/job/enos/dispatch", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.tokens = append(f.tokens, r.Header.Get("X-Nomad-Token"))
+		require.Equal(f.t, "ci", r.URL.Query().Get("namespace"))
+		body := struct{ Payload []byte }{}
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+		f.payload = body.Payload
+
+		_, _ = w.Write([]byte(`{"DispatchedJobID":"enos/dispatch-1","EvalID":"e1"}`))
+	})
+
+	mux.HandleFunc("GET /v1/job/{job}/allocations", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if r.PathValue("job") != "enos/dispatch-1" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		f.polls++
+		status := AllocClientStatusRunning
+		if f.polls > f.runFor {
+			status = f.status
+		}
+
+		states := map[string]*TaskState{}
+		for _, task := range f.tasks {
+			states[task] = &TaskState{
+				State:  "running",
+				Events: []*TaskEvent{{Type: "Terminated", DisplayMessage: "Exit Code: 1"}},
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode([]*Allocation{
+			{ID: "old", CreateIndex: 1, ClientStatus: AllocClientStatusFailed},
+			{ID: "a1", CreateIndex: 2, ClientStatus: status, TaskStates: states},
+		})
+	})
+
+	mux.HandleFunc("GET /v1/client/fs/logs/a1", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if f.failLogs {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		require.Equal(f.t, "stdout", r.URL.Query().Get("type"))
+		require.Equal(f.t, "true", r.URL.Query().Get("plain"))
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		require.NoError(f.t, err)
+
+		end := min(offset+f.chunk, len(f.logs))
+		_, _ = w.Write([]byte(f.logs[offset:end]))
+	})
+
+	mux.HandleFunc("DELETE /v1/job/{job}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.stopped = r.PathValue("job") == "enos/dispatch-1"
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	return mux
+}
+
+// testRemoteOutput returns the output of "enos operation execute" with the events and response.
+func testRemoteOutput(t *testing.T, events []*pb.Operation_Event, res *pb.Operation_Response) string {
+	t.Helper()
+
+	out := "not an event\n"
+	for _, event := range events {
+		b, err := protojson.Marshal(event)
+		require.NoError(t, err)
+		out += `{"event":` + string(b) + "}\n"
+	}
+
+	if res != nil {
+		b, err := protojson.Marshal(res)
+		require.NoError(t, err)
+		out += `{"response":` + string(b) + "}\n"
+	}
+
+	return out
+}
+
+func testExecutor(t *testing.T, f *fakeNomad, opts ...ExecutorOpt) *Executor {
+	t.Helper()
+
+	f.t = t
+	srv := httptest.NewServer(f.handler())
+	t.Cleanup(srv.Close)
+
+	e, err := NewExecutor(append([]ExecutorOpt{
+		WithExecutorClient(NewClient(func(key string) string {
+			return map[string]string{"NOMAD_TOKEN": "secret"}[key]
+		}, WithClientAddress(srv.URL), WithClientNamespace("ci"))),
+		WithExecutorJob("enos"),
+		WithExecutorPollInterval(time.Millisecond),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	return e
+}
+
+func testGenerateRequest() *pb.Operation_Request {
+	return &pb.Operation_Request{
+		Id:    "op-1",
+		Value: &pb.Operation_Request_Generate_{Generate: &pb.Operation_Request_Generate{}},
+	}
+}
+
+// collectEvents runs the work func and returns its response and the events it sent.
+func collectEvents(ctx context.Context, f operation.WorkFunc) (*pb.Operation_Response, []*pb.Operation_Event) {
+	events := make(chan *pb.Operation_Event)
+	got := []*pb.Operation_Event{}
+	done := make(chan struct{})
+	go func() {
+		for event := range events {
+			got = append(got, event)
+		}
+		close(done)
+	}()
+
+	res := f(ctx, events, hclog.NewNullLogger())
+	close(events)
+	<-done
+
+	return res, got
+}
+
+// Test_Executor tests executing operations in dispatched Nomad jobs.
+func Test_Executor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("completed", func(t *testing.T) {
+		t.Parallel()
+
+		events := []*pb.Operation_Event{
+			{Op: &pb.Ref_Operation{Id: "op-1"}, Status: pb.Operation_STATUS_RUNNING},
+			{Op: &pb.Ref_Operation{Id: "op-1"}, Status: pb.Operation_STATUS_COMPLETED},
+		}
+		res := &pb.Operation_Response{
+			Op:     &pb.Ref_Operation{Id: "op-1"},
+			Status: pb.Operation_STATUS_COMPLETED,
+			Value:  &pb.Operation_Response_Generate_{Generate: &pb.Operation_Response_Generate{}},
+		}
+		f := &fakeNomad{
+			runFor: 3,
+			status: AllocClientStatusComplete,
+			logs:   testRemoteOutput(t, events, res),
+			// Split the output into chunks that end in the middle of lines.
+			chunk: 17,
+			tasks: []string{"enos"},
+		}
+
+		e := testExecutor(t, f)
+		work, err := e.WorkFunc(testGenerateRequest())
+		require.NoError(t, err)
+
+		gotRes, gotEvents := collectEvents(context.Background(), work)
+		require.Equal(t, pb.Operation_STATUS_COMPLETED, gotRes.GetStatus())
+		require.Len(t, gotEvents, 2)
+		require.Equal(t, pb.Operation_STATUS_RUNNING, gotEvents[0].GetStatus())
+		require.Equal(t, pb.Operation_STATUS_COMPLETED, gotEvents[1].GetStatus())
+
+		req, err := operation.UnmarshalRequest(f.payload)
+		require.NoError(t, err)
+		require.Equal(t, "op-1", req.GetId())
+		require.Equal(t, []string{"secret"}, f.tokens)
+	})
+
+	t.Run("failed without response", func(t *testing.T) {
+		t.Parallel()
+
+		f := &fakeNomad{
+			status: AllocClientStatusFailed,
+			logs:   "panic: oops\n",
+			chunk:  1024,
+			tasks:  []string{"enos"},
+		}
+
+		e := testExecutor(t, f)
+		work, err := e.WorkFunc(testGenerateRequest())
+		require.NoError(t, err)
+
+		res, _ := collectEvents(context.Background(), work)
+		require.Equal(t, pb.Operation_STATUS_FAILED, res.GetStatus())
+		require.Len(t, res.GetDiagnostics(), 1)
+		require.Equal(t, "allocation a1 of job enos/dispatch-1 is failed without the response of the operation: Exit Code: 1",
+			res.GetDiagnostics()[0].GetSummary(),
+		)
+	})
+
+	t.Run("multiple tasks", func(t *testing.T) {
+		t.Parallel()
+
+		f := &fakeNomad{
+			status: AllocClientStatusComplete,
+			tasks:  []string{"sidecar", "enos"},
+		}
+
+		e := testExecutor(t, f)
+		work, err := e.WorkFunc(testGenerateRequest())
+		require.NoError(t, err)
+
+		res, _ := collectEvents(context.Background(), work)
+		require.Equal(t, pb.Operation_STATUS_FAILED, res.GetStatus())
+		require.Contains(t, res.GetDiagnostics()[0].GetSummary(), "job enos has the tasks enos, sidecar")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		f := &fakeNomad{
+			runFor: 1 << 30,
+			tasks:  []string{"enos"},
+			chunk:  1024,
+		}
+
+		e := testExecutor(t, f, WithExecutorTask("enos"))
+		work, err := e.WorkFunc(testGenerateRequest())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		res, _ := collectEvents(ctx, work)
+		require.Equal(t, pb.Operation_STATUS_CANCELLED, res.GetStatus())
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		require.True(t, f.stopped)
+	})
+
+	t.Run("unknown request", func(t *testing.T) {
+		t.Parallel()
+
+		e := testExecutor(t, &fakeNomad{})
+		_, err := e.WorkFunc(&pb.Operation_Request{})
+		require.Error(t, err)
+	})
+}
+
+// Test_NewExecutor_requires_job tests that executors require a job.
+func Test_NewExecutor_requires_job(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewExecutor()
+	require.ErrorContains(t, err, "without a job")
+}
@@ -0,0 +1,405 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDatadogSite is the Datadog site that metrics and events are sent to with the API.
+	DefaultDatadogSite = "datadoghq.com"
+	// DefaultDogStatsDAddress is the address of the DogStatsD server of a local Datadog Agent.
+	DefaultDogStatsDAddress = "127.0.0.1:8125"
+	// DefaultDatadogMetricPrefix is the prefix of the names of metrics.
+	DefaultDatadogMetricPrefix = "enos"
+)
+
+// Datadog metric types of the series API.
+const (
+	datadogMetricCount = 1
+	datadogMetricGauge = 3
+)
+
+var _ Reporter = (*Datadog)(nil)
+
+// Datadog emits the metrics and events of runs to Datadog, either to the DogStatsD server of a
+// Datadog Agent or to the Datadog API with an API key. The duration and result of each scenario
+// are emitted as it completes, tagged with the name of the scenario and its variants, and the
+// duration and counts of the run are emitted when it has completed.
+type Datadog struct {
+	apiKey       string
+	apiURL       string
+	statsdAddr   string
+	metricPrefix string
+	tags         []string
+	client       *http.Client
+
+	mu      sync.Mutex
+	command string
+}
+
+// DatadogOpt is a functional option.
+type DatadogOpt func(*Datadog)
+
+// NewDatadog takes options and returns a new Datadog emitter.
+func NewDatadog(opts ...DatadogOpt) *Datadog {
+	d := &Datadog{
+		apiURL:       datadogAPIURL(DefaultDatadogSite),
+		statsdAddr:   DefaultDogStatsDAddress,
+		metricPrefix: DefaultDatadogMetricPrefix,
+		tags:         []string{},
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// WithDatadogAPIKey sets the API key. Metrics and events are sent to the API rather than to
+// DogStatsD if it is set.
+func WithDatadogAPIKey(key string) DatadogOpt {
+	return func(d *Datadog) {
+		d.apiKey = key
+	}
+}
+
+// WithDatadogSite sets the Datadog site of the API, e.g. "datadoghq.eu".
+func WithDatadogSite(site string) DatadogOpt {
+	return func(d *Datadog) {
+		if site != "" {
+			d.apiURL = datadogAPIURL(site)
+		}
+	}
+}
+
+// WithDatadogAPIURL sets the URL of the API. It takes precedence over the site.
+func WithDatadogAPIURL(url string) DatadogOpt {
+	return func(d *Datadog) {
+		if url != "" {
+			d.apiURL = strings.TrimSuffix(url, "/")
+		}
+	}
+}
+
+// WithDatadogStatsDAddress sets the address of the DogStatsD server.
+func WithDatadogStatsDAddress(addr string) DatadogOpt {
+	return func(d *Datadog) {
+		if addr != "" {
+			d.statsdAddr = addr
+		}
+	}
+}
+
+// WithDatadogMetricPrefix sets the prefix of the names of metrics.
+func WithDatadogMetricPrefix(prefix string) DatadogOpt {
+	return func(d *Datadog) {
+		if prefix != "" {
+			d.metricPrefix = strings.TrimSuffix(prefix, ".")
+		}
+	}
+}
+
+// WithDatadogTags sets tags that are added to every metric and event, e.g. "team:quality".
+func WithDatadogTags(tags ...string) DatadogOpt {
+	return func(d *Datadog) {
+		d.tags = append(d.tags, tags...)
+	}
+}
+
+// WithDatadogHTTPClient sets the HTTP client.
+func WithDatadogHTTPClient(client *http.Client) DatadogOpt {
+	return func(d *Datadog) {
+		d.client = client
+	}
+}
+
+// datadogMetric is a metric point.
+type datadogMetric struct {
+	name  string
+	typ   int
+	value float64
+	tags  []string
+}
+
+// datadogEvent is an event. The alert type is one of error, warning, info, or success.
+type datadogEvent struct {
+	Title     string   `json:"title"`
+	Text      string   `json:"text"`
+	AlertType string   `json:"alert_type"`
+	Tags      []string `json:"tags"`
+}
+
+type datadogSeries struct {
+	Series []*datadogSerie `json:"series"`
+}
+
+type datadogSerie struct {
+	Metric string          `json:"metric"`
+	Type   int             `json:"type"`
+	Points []*datadogPoint `json:"points"`
+	Tags   []string        `json:"tags"`
+}
+
+type datadogPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+type datadogError struct {
+	Errors []string `json:"errors"`
+}
+
+// Start keeps the command of the run so that its metrics can be tagged with it.
+func (d *Datadog) Start(ctx context.Context, command string, scenarios int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.command = command
+
+	return nil
+}
+
+// Report emits the duration and result of the scenario and an event for it.
+func (d *Datadog) Report(ctx context.Context, result *ScenarioResult) error {
+	if result == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	command := d.command
+	d.mu.Unlock()
+
+	tags := d.runTags(command)
+	if result.Scenario != nil {
+		tags = append(tags, datadogTag("scenario", result.Scenario.Name))
+		for _, elm := range result.Scenario.Variants.Elements() {
+			tags = append(tags, datadogTag(elm.Key, elm.Val))
+		}
+	}
+	tags = append(tags, datadogTag("result", datadogResult(result.Failed)))
+
+	event := &datadogEvent{
+		Title:     fmt.Sprintf("%s %s %s", command, result.Name, datadogResult(result.Failed)),
+		Text:      result.Error,
+		AlertType: "success",
+		Tags:      tags,
+	}
+	if result.Failed {
+		event.AlertType = "error"
+	}
+
+	return d.emit(ctx, []*datadogMetric{
+		{name: "scenario.duration", typ: datadogMetricGauge, value: result.Duration.Seconds(), tags: tags},
+		{name: "scenario.count", typ: datadogMetricCount, value: 1, tags: tags},
+	}, event)
+}
+
+// Notify emits the duration of the run and the number of scenarios that passed and failed.
+func (d *Datadog) Notify(ctx context.Context, summary *Summary) error {
+	if summary == nil {
+		return nil
+	}
+
+	tags := d.runTags(summary.Command)
+	resultTags := append(slices.Clone(tags), datadogTag("result", datadogResult(summary.HasFailed())))
+
+	event := &datadogEvent{
+		Title:     fmt.Sprintf("%s %s: %s", summary.Command, datadogResult(summary.HasFailed()), summary.Counts()),
+		Text:      strings.Join(summary.Errors, "\n"),
+		AlertType: "success",
+		Tags:      resultTags,
+	}
+	if summary.HasFailed() {
+		event.AlertType = "error"
+	}
+
+	return d.emit(ctx, []*datadogMetric{
+		{name: "run.duration", typ: datadogMetricGauge, value: summary.Duration.Seconds(), tags: resultTags},
+		{name: "run.count", typ: datadogMetricCount, value: 1, tags: resultTags},
+		{name: "run.scenarios.passed", typ: datadogMetricCount, value: float64(summary.Passed()), tags: tags},
+		{name: "run.scenarios.failed", typ: datadogMetricCount, value: float64(summary.Failed()), tags: tags},
+	}, event)
+}
+
+// runTags returns the tags of every metric and event of the run of the command.
+func (d *Datadog) runTags(command string) []string {
+	tags := []string{datadogTag("command", strings.TrimPrefix(command, "enos "))}
+
+	return append(tags, d.tags...)
+}
+
+// emit sends the metrics and the event to the API or to DogStatsD.
+func (d *Datadog) emit(ctx context.Context, metrics []*datadogMetric, event *datadogEvent) error {
+	if d.apiKey != "" {
+		return d.emitAPI(ctx, metrics, event)
+	}
+
+	return d.emitStatsD(ctx, metrics, event)
+}
+
+// emitStatsD sends the metrics and the event to DogStatsD. Each metric and event is sent in its
+// own datagram so that large events can't cause metrics to be dropped.
+func (d *Datadog) emitStatsD(ctx context.Context, metrics []*datadogMetric, event *datadogEvent) error {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "udp", d.statsdAddr)
+	if err != nil {
+		return fmt.Errorf("connecting to dogstatsd: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+	}
+
+	datagrams := []string{}
+	for _, m := range metrics {
+		typ := "g"
+		if m.typ == datadogMetricCount {
+			typ = "c"
+		}
+		datagrams = append(datagrams, fmt.Sprintf("%s.%s:%s|%s%s",
+			d.metricPrefix, m.name, strconv.FormatFloat(m.value, 'f', -1, 64), typ, statsdTags(m.tags),
+		))
+	}
+
+	if event != nil {
+		// Line breaks of the text of events are escaped as they would end the datagram.
+		text := strings.ReplaceAll(event.Text, "\n", `\n`)
+		datagrams = append(datagrams, fmt.Sprintf("_e{%d,%d}:%s|%s|t:%s%s",
+			len(event.Title), len(text), event.Title, text, event.AlertType, statsdTags(event.Tags),
+		))
+	}
+
+	for _, datagram := range datagrams {
+		if _, err := conn.Write([]byte(datagram)); err != nil {
+			return fmt.Errorf("sending to dogstatsd: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// emitAPI sends the metrics and the event to the Datadog API.
+func (d *Datadog) emitAPI(ctx context.Context, metrics []*datadogMetric, event *datadogEvent) error {
+	now := time.Now().Unix()
+	series := &datadogSeries{Series: []*datadogSerie{}}
+	for _, m := range metrics {
+		series.Series = append(series.Series, &datadogSerie{
+			Metric: d.metricPrefix + "." + m.name,
+			Type:   m.typ,
+			Points: []*datadogPoint{{Timestamp: now, Value: m.value}},
+			Tags:   m.tags,
+		})
+	}
+
+	if err := d.post(ctx, "/api/v2/series", series); err != nil {
+		return fmt.Errorf("submitting datadog metrics: %w", err)
+	}
+
+	if event == nil {
+		return nil
+	}
+
+	if err := d.post(ctx, "/api/v1/events", event); err != nil {
+		return fmt.Errorf("posting datadog event: %w", err)
+	}
+
+	return nil
+}
+
+// post posts the body to the path of the Datadog API.
+func (d *Datadog) post(ctx context.Context, path string, in any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encoding datadog request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.apiURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating datadog request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", d.apiKey)
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		return nil
+	}
+
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("reading datadog response: %w", err)
+	}
+
+	ddErr := &datadogError{}
+	if err := json.Unmarshal(resBody, ddErr); err == nil && len(ddErr.Errors) > 0 {
+		return errors.New(res.Status + ": " + strings.Join(ddErr.Errors, ", "))
+	}
+
+	return fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(resBody)))
+}
+
+// datadogAPIURL returns the URL of the API of the Datadog site.
+func datadogAPIURL(site string) string {
+	return "https://api." + site
+}
+
+// datadogResult returns the value of the result tag.
+func datadogResult(failed bool) string {
+	if failed {
+		return "failed"
+	}
+
+	return "passed"
+}
+
+// datadogTag returns the tag of the key and value. Datadog converts tags to lower case and
+// replaces unsupported characters with underscores, which is done here so that the tags of
+// emitted metrics match the tags that are queried.
+func datadogTag(key string, val string) string {
+	sanitize := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-', r == '.', r == '/':
+				return r
+			case r >= 'A' && r <= 'Z':
+				return r + ('a' - 'A')
+			default:
+				return '_'
+			}
+		}, s)
+	}
+
+	return sanitize(key) + ":" + sanitize(val)
+}
+
+// statsdTags returns the tags section of a DogStatsD datagram.
+func statsdTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	return "|#" + strings.Join(tags, ",")
+}
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+)
+
+// testScenarioResult returns the result of a failed scenario with variants.
+func testScenarioResult() *ScenarioResult {
+	scenario := flightplan.NewScenario()
+	scenario.Name = "upgrade"
+	scenario.Variants = flightplan.NewVector()
+	scenario.Variants.Add(flightplan.NewElement("arch", "amd64"))
+	scenario.Variants.Add(flightplan.NewElement("Edition", "ent hsm"))
+
+	return &ScenarioResult{
+		Name:     scenario.String(),
+		Scenario: scenario,
+		Failed:   true,
+		Duration: 90 * time.Second,
+		Error:    "timeout waiting for cluster",
+	}
+}
+
+// Test_Datadog_StatsD tests emitting metrics and events to DogStatsD.
+func Test_Datadog_StatsD(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	dd := NewDatadog(
+		WithDatadogStatsDAddress(conn.LocalAddr().String()),
+		WithDatadogTags("team:quality"),
+	)
+	ctx := context.Background()
+	require.NoError(t, dd.Start(ctx, "enos scenario run", 1))
+	require.NoError(t, dd.Report(ctx, testScenarioResult()))
+
+	tags := "|#command:scenario_run,team:quality,scenario:upgrade,arch:amd64,edition:ent_hsm,result:failed"
+	expected := []string{
+		"enos.scenario.duration:90|g" + tags,
+		"enos.scenario.count:1|c" + tags,
+		"_e{61,27}:enos scenario run upgrade [arch:amd64 Edition:ent hsm] failed|" +
+			"timeout waiting for cluster|t:error" + tags,
+	}
+
+	buf := make([]byte, 1024)
+	for _, datagram := range expected {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		require.Equal(t, datagram, string(buf[:n]))
+	}
+}
+
+// Test_Datadog_API tests submitting metrics and posting events to the Datadog API.
+func Test_Datadog_API(t *testing.T) {
+	t.Parallel()
+
+	mu := sync.Mutex{}
+	series := []*datadogSerie{}
+	events := []*datadogEvent{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("DD-API-KEY") != "test-key" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["Forbidden"]}`))
+
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/api/v2/series":
+			body := &datadogSeries{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(body))
+			series = append(series, body.Series...)
+		case "/api/v1/events":
+			event := &datadogEvent{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(event))
+			events = append(events, event)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+
+	dd := NewDatadog(WithDatadogAPIURL(srv.URL), WithDatadogAPIKey("test-key"), WithDatadogMetricPrefix("ci.enos"))
+	require.NoError(t, dd.Notify(context.Background(), testSummary(t)))
+
+	require.Len(t, series, 4)
+	require.Equal(t, "ci.enos.run.duration", series[0].Metric)
+	require.Equal(t, datadogMetricGauge, series[0].Type)
+	require.InDelta(t, 300, series[0].Points[0].Value, 0)
+	require.Equal(t, []string{"command:scenario_run", "result:failed"}, series[0].Tags)
+	require.Equal(t, "ci.enos.run.scenarios.failed", series[3].Metric)
+	require.Equal(t, datadogMetricCount, series[3].Type)
+	require.InDelta(t, 1, series[3].Points[0].Value, 0)
+	require.Equal(t, []string{"command:scenario_run"}, series[3].Tags)
+
+	require.Len(t, events, 1)
+	require.Equal(t, "enos scenario run failed: 1 passed, 1 failed", events[0].Title)
+	require.Equal(t, "error", events[0].AlertType)
+
+	dd = NewDatadog(WithDatadogAPIURL(srv.URL), WithDatadogAPIKey("wrong"))
+	require.ErrorContains(t, dd.Notify(context.Background(), testSummary(t)), "403 Forbidden: Forbidden")
+}
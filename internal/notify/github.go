@@ -0,0 +1,612 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+const (
+	// DefaultGitHubAPIURL is the URL of the GitHub REST API.
+	DefaultGitHubAPIURL = "https://api.github.com"
+	// DefaultGitHubCheckName is the name of check runs and the context of commit statuses.
+	DefaultGitHubCheckName = "enos"
+)
+
+const (
+	// maxGitHubAnnotations is the number of annotations GitHub accepts in a single update of a
+	// check run. More annotations are sent with more updates.
+	maxGitHubAnnotations = 50
+	// maxGitHubStatusDescription is the length that GitHub limits commit status descriptions to.
+	maxGitHubStatusDescription = 140
+)
+
+var _ Reporter = (*GitHub)(nil)
+
+// GitHub reports runs as GitHub check runs. The check run is created when the run starts, the
+// diagnostics of each scenario are added to it as annotations as soon as the scenario completes,
+// and it is completed with the summary of the run. The status of the commit is also set so that
+// branch protection rules can require it. Requests are authenticated with a token or as a GitHub
+// App installation.
+type GitHub struct {
+	apiURL         string
+	token          string
+	appID          string
+	installationID string
+	privateKey     string
+	repository     string
+	sha            string
+	checkName      string
+	rootDir        string
+	baseDir        string
+	client         *http.Client
+
+	mu                    sync.Mutex
+	command               string
+	scenarios             int
+	completed             int
+	failed                int
+	checkRunID            int64
+	checkRunURL           string
+	installToken          string
+	installTokenExpiresAt time.Time
+}
+
+// GitHubOpt is a functional option.
+type GitHubOpt func(*GitHub)
+
+// NewGitHub takes options and returns a new GitHub reporter.
+func NewGitHub(opts ...GitHubOpt) *GitHub {
+	g := &GitHub{
+		apiURL:    DefaultGitHubAPIURL,
+		checkName: DefaultGitHubCheckName,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// WithGitHubAPIURL sets the URL of the GitHub API, e.g. the API of GitHub Enterprise Server.
+func WithGitHubAPIURL(url string) GitHubOpt {
+	return func(g *GitHub) {
+		if url != "" {
+			g.apiURL = strings.TrimSuffix(url, "/")
+		}
+	}
+}
+
+// WithGitHubToken sets the token that requests are authenticated with.
+func WithGitHubToken(token string) GitHubOpt {
+	return func(g *GitHub) {
+		g.token = token
+	}
+}
+
+// WithGitHubApp sets the GitHub App installation that requests are authenticated as. The private
+// key is the PEM encoded private key of the App.
+func WithGitHubApp(appID string, installationID string, privateKey string) GitHubOpt {
+	return func(g *GitHub) {
+		g.appID = appID
+		g.installationID = installationID
+		g.privateKey = privateKey
+	}
+}
+
+// WithGitHubRepository sets the repository, e.g. "hashicorp/enos".
+func WithGitHubRepository(repository string) GitHubOpt {
+	return func(g *GitHub) {
+		g.repository = repository
+	}
+}
+
+// WithGitHubSHA sets the SHA of the commit that is checked.
+func WithGitHubSHA(sha string) GitHubOpt {
+	return func(g *GitHub) {
+		g.sha = sha
+	}
+}
+
+// WithGitHubCheckName sets the name of the check run and the context of the commit status.
+func WithGitHubCheckName(name string) GitHubOpt {
+	return func(g *GitHub) {
+		if name != "" {
+			g.checkName = name
+		}
+	}
+}
+
+// WithGitHubDirs sets the root directory of the repository and the directory that relative file
+// names of diagnostics are relative to. Only diagnostics of files in the repository are annotated.
+func WithGitHubDirs(rootDir string, baseDir string) GitHubOpt {
+	return func(g *GitHub) {
+		g.rootDir = rootDir
+		g.baseDir = baseDir
+	}
+}
+
+// WithGitHubHTTPClient sets the HTTP client.
+func WithGitHubHTTPClient(client *http.Client) GitHubOpt {
+	return func(g *GitHub) {
+		g.client = client
+	}
+}
+
+type githubCheckRun struct {
+	Name        string             `json:"name,omitempty"`
+	HeadSHA     string             `json:"head_sha,omitempty"`
+	Status      string             `json:"status,omitempty"`
+	Conclusion  string             `json:"conclusion,omitempty"`
+	StartedAt   string             `json:"started_at,omitempty"`
+	CompletedAt string             `json:"completed_at,omitempty"`
+	Output      *githubCheckOutput `json:"output,omitempty"`
+}
+
+type githubCheckOutput struct {
+	Title       string              `json:"title"`
+	Summary     string              `json:"summary"`
+	Annotations []*githubAnnotation `json:"annotations,omitempty"`
+}
+
+type githubAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int64  `json:"start_line"`
+	EndLine         int64  `json:"end_line"`
+	StartColumn     int64  `json:"start_column,omitempty"`
+	EndColumn       int64  `json:"end_column,omitempty"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+}
+
+type githubCheckRunResponse struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+}
+
+type githubStatus struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description"`
+	Context     string `json:"context"`
+}
+
+type githubInstallationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type githubError struct {
+	Message string `json:"message"`
+}
+
+// Start creates the check run and sets the status of the commit to pending.
+func (g *GitHub) Start(ctx context.Context, command string, scenarios int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.command = command
+	g.scenarios = scenarios
+
+	if err := g.createCheckRun(ctx, &githubCheckRun{
+		Status:    "in_progress",
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+		Output:    g.progressOutput(),
+	}); err != nil {
+		return err
+	}
+
+	return g.setStatus(ctx, "pending", g.progressOutput().Title)
+}
+
+// Report adds the diagnostics of the scenario to the check run as annotations and updates the
+// progress of the check run.
+func (g *GitHub) Report(ctx context.Context, result *ScenarioResult) error {
+	if result == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// The check run is created with every annotation when the run completes if it couldn't be
+	// created when the run started.
+	if g.checkRunID == 0 {
+		return nil
+	}
+
+	g.completed++
+	if result.Failed {
+		g.failed++
+	}
+
+	annotations := g.annotations(result.Name, result.Diagnostics)
+	for {
+		output := g.progressOutput()
+		n := min(len(annotations), maxGitHubAnnotations)
+		output.Annotations = annotations[:n]
+		annotations = annotations[n:]
+
+		if err := g.updateCheckRun(ctx, &githubCheckRun{Output: output}); err != nil {
+			return err
+		}
+
+		if len(annotations) == 0 {
+			return nil
+		}
+	}
+}
+
+// Notify completes the check run with the summary of the run and sets the status of the commit.
+// If the run hasn't been started the check run is created with the annotations of every scenario.
+func (g *GitHub) Notify(ctx context.Context, summary *Summary) error {
+	if summary == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	conclusion := "success"
+	if summary.HasFailed() {
+		conclusion = "failure"
+	}
+
+	annotations := g.annotations("", summary.Diagnostics)
+	if g.checkRunID == 0 {
+		for _, scenario := range summary.Scenarios {
+			annotations = append(annotations, g.annotations(scenario.Name, scenario.Diagnostics)...)
+		}
+	}
+
+	output := githubOutputForSummary(summary)
+	n := min(len(annotations), maxGitHubAnnotations)
+	output.Annotations = annotations[:n]
+	annotations = annotations[n:]
+
+	run := &githubCheckRun{
+		Status:      "completed",
+		Conclusion:  conclusion,
+		CompletedAt: time.Now().UTC().Format(time.RFC3339),
+		Output:      output,
+	}
+
+	var err error
+	if g.checkRunID == 0 {
+		err = g.createCheckRun(ctx, run)
+	} else {
+		err = g.updateCheckRun(ctx, run)
+	}
+	if err != nil {
+		return err
+	}
+
+	for len(annotations) > 0 {
+		n := min(len(annotations), maxGitHubAnnotations)
+		err := g.updateCheckRun(ctx, &githubCheckRun{Output: &githubCheckOutput{
+			Title:       output.Title,
+			Summary:     output.Summary,
+			Annotations: annotations[:n],
+		}})
+		if err != nil {
+			return err
+		}
+		annotations = annotations[n:]
+	}
+
+	// Commit statuses and check run conclusions happen to share the same values.
+	return g.setStatus(ctx, conclusion, output.Title)
+}
+
+// progressOutput returns the output of a check run that is in progress.
+func (g *GitHub) progressOutput() *githubCheckOutput {
+	title := fmt.Sprintf("%d of %d scenarios completed", g.completed, g.scenarios)
+	if g.failed > 0 {
+		title += fmt.Sprintf(", %d failed", g.failed)
+	}
+
+	return &githubCheckOutput{
+		Title:   title,
+		Summary: fmt.Sprintf("`%s` is running.", g.command),
+	}
+}
+
+// createCheckRun creates the check run and keeps its ID for later updates.
+func (g *GitHub) createCheckRun(ctx context.Context, run *githubCheckRun) error {
+	if g.repository == "" || g.sha == "" {
+		return errors.New("github reporter requires a repository and a commit SHA")
+	}
+
+	run.Name = g.checkName
+	run.HeadSHA = g.sha
+
+	res := &githubCheckRunResponse{}
+	if err := g.do(ctx, http.MethodPost, "/repos/"+g.repository+"/check-runs", run, res); err != nil {
+		return fmt.Errorf("creating check run: %w", err)
+	}
+
+	g.checkRunID = res.ID
+	g.checkRunURL = res.HTMLURL
+
+	return nil
+}
+
+// updateCheckRun updates the check run. Annotations are added to the annotations of the check run.
+func (g *GitHub) updateCheckRun(ctx context.Context, run *githubCheckRun) error {
+	path := fmt.Sprintf("/repos/%s/check-runs/%d", g.repository, g.checkRunID)
+	if err := g.do(ctx, http.MethodPatch, path, run, nil); err != nil {
+		return fmt.Errorf("updating check run: %w", err)
+	}
+
+	return nil
+}
+
+// setStatus sets the status of the commit. The status links to the check run.
+func (g *GitHub) setStatus(ctx context.Context, state string, description string) error {
+	if len(description) > maxGitHubStatusDescription {
+		description = description[:maxGitHubStatusDescription-3] + "..."
+	}
+
+	err := g.do(ctx, http.MethodPost, "/repos/"+g.repository+"/statuses/"+g.sha, &githubStatus{
+		State:       state,
+		TargetURL:   g.checkRunURL,
+		Description: description,
+		Context:     g.checkName,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("setting commit status: %w", err)
+	}
+
+	return nil
+}
+
+// annotations returns the annotations of the diagnostics that have a range in a file of the
+// repository. Annotations of a scenario are titled with the name of the scenario.
+func (g *GitHub) annotations(scenario string, diags []*pb.Diagnostic) []*githubAnnotation {
+	annotations := []*githubAnnotation{}
+	for _, diag := range diags {
+		rng := diag.GetRange()
+		path, ok := g.annotationPath(rng.GetFilename())
+		if !ok || rng.GetStart().GetLine() < 1 {
+			continue
+		}
+
+		level := "notice"
+		switch diag.GetSeverity() {
+		case pb.Diagnostic_SEVERITY_ERROR:
+			level = "failure"
+		case pb.Diagnostic_SEVERITY_WARNING:
+			level = "warning"
+		default:
+		}
+
+		annotation := &githubAnnotation{
+			Path:            path,
+			StartLine:       rng.GetStart().GetLine(),
+			EndLine:         max(rng.GetEnd().GetLine(), rng.GetStart().GetLine()),
+			AnnotationLevel: level,
+			Title:           diag.GetSummary(),
+			Message:         diag.GetDetail(),
+		}
+		if scenario != "" {
+			annotation.Title = scenario + ": " + annotation.Title
+		}
+		if annotation.Message == "" {
+			annotation.Message = diag.GetSummary()
+		}
+		// Columns can only be set for annotations of a single line.
+		if annotation.StartLine == annotation.EndLine {
+			annotation.StartColumn = rng.GetStart().GetColumn()
+			annotation.EndColumn = rng.GetEnd().GetColumn()
+		}
+
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations
+}
+
+// annotationPath returns the path of the file relative to the root of the repository, or false if
+// the file is not in the repository.
+func (g *GitHub) annotationPath(filename string) (string, bool) {
+	if filename == "" || g.rootDir == "" {
+		return "", false
+	}
+
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(g.baseDir, filename)
+	}
+
+	rel, err := filepath.Rel(g.rootDir, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// do sends the request to the GitHub API and decodes the response into out if it is not nil.
+func (g *GitHub) do(ctx context.Context, method string, path string, in any, out any) error {
+	token, err := g.authToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return g.request(ctx, method, path, token, in, out)
+}
+
+// request sends the request to the GitHub API with the bearer token.
+func (g *GitHub) request(ctx context.Context, method string, path string, token string, in any, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encoding github request: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.apiURL+path, body)
+	if err != nil {
+		return fmt.Errorf("creating github request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("reading github response: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		ghErr := &githubError{}
+		if err := json.Unmarshal(resBody, ghErr); err == nil && ghErr.Message != "" {
+			return fmt.Errorf("%s: %s", res.Status, ghErr.Message)
+		}
+
+		return fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(resBody)))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(resBody, out); err != nil {
+			return fmt.Errorf("decoding github response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// authToken returns the token that requests are authenticated with. GitHub App installation
+// tokens are created when they're first needed and renewed before they expire.
+func (g *GitHub) authToken(ctx context.Context) (string, error) {
+	if g.token != "" {
+		return g.token, nil
+	}
+
+	if g.appID == "" || g.installationID == "" || g.privateKey == "" {
+		return "", errors.New("github reporter requires a token, or an app ID, installation ID, and private key")
+	}
+
+	if g.installToken != "" && time.Now().Before(g.installTokenExpiresAt.Add(-time.Minute)) {
+		return g.installToken, nil
+	}
+
+	jwt, err := githubAppJWT(g.appID, g.privateKey, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	res := &githubInstallationToken{}
+	path := "/app/installations/" + g.installationID + "/access_tokens"
+	if err := g.request(ctx, http.MethodPost, path, jwt, nil, res); err != nil {
+		return "", fmt.Errorf("creating github app installation token: %w", err)
+	}
+
+	g.installToken = res.Token
+	g.installTokenExpiresAt = res.ExpiresAt
+
+	return g.installToken, nil
+}
+
+// githubAppJWT returns the JSON Web Token that authenticates as the GitHub App. It is issued a
+// minute in the past to allow for clock drift and expires before the ten minutes GitHub allows.
+func githubAppJWT(appID string, privateKey string, now time.Time) (string, error) {
+	key, err := parseRSAPrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("signing github app token: %w", err)
+	}
+
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// parseRSAPrivateKey parses a PEM encoded PKCS #1 or PKCS #8 RSA private key.
+func parseRSAPrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, errors.New("github app private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing github app private key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("github app private key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// githubOutputForSummary returns the output of the completed check run of the summary.
+func githubOutputForSummary(summary *Summary) *githubCheckOutput {
+	title := summary.Counts()
+	if dur := summary.Duration.Round(time.Second); dur > 0 {
+		title += " in " + dur.String()
+	}
+
+	return &githubCheckOutput{
+		Title:   title,
+		Summary: summary.Markdown(),
+	}
+}
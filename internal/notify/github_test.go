@@ -0,0 +1,249 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package notify
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// githubRequest is a request that was sent to the test GitHub API.
+type githubRequest struct {
+	method string
+	path   string
+	auth   string
+	body   map[string]any
+}
+
+// testGitHubServer returns a test GitHub API that records the requests that it receives.
+func testGitHubServer(t *testing.T) (*httptest.Server, func() []*githubRequest) {
+	t.Helper()
+
+	mu := sync.Mutex{}
+	reqs := []*githubRequest{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &githubRequest{
+			method: r.Method,
+			path:   r.URL.Path,
+			auth:   r.Header.Get("Authorization"),
+			body:   map[string]any{},
+		}
+		if r.ContentLength > 0 {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req.body))
+		}
+		mu.Lock()
+		reqs = append(reqs, req)
+		mu.Unlock()
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/app/installations/"):
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token":"ghs_install","expires_at":"` +
+				time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/check-runs"):
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":42,"html_url":"https://github.com/hashicorp/enos/runs/42"}`))
+		case strings.HasPrefix(r.URL.Path, "/repos/hashicorp/enos/"):
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []*githubRequest {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return reqs
+	}
+}
+
+// Test_GitHub_Report tests reporting a run as a check run with annotations and a commit status.
+func Test_GitHub_Report(t *testing.T) {
+	t.Parallel()
+
+	srv, requests := testGitHubServer(t)
+	gh := NewGitHub(
+		WithGitHubAPIURL(srv.URL),
+		WithGitHubToken("ghp_test"),
+		WithGitHubRepository("hashicorp/enos"),
+		WithGitHubSHA("abc123"),
+		WithGitHubDirs("/src/enos", "/src/enos/enos"),
+	)
+
+	ctx := context.Background()
+	require.NoError(t, gh.Start(ctx, "enos scenario run", 2))
+	require.NoError(t, gh.Report(ctx, &ScenarioResult{
+		Name:   "upgrade",
+		Failed: true,
+		Diagnostics: []*pb.Diagnostic{
+			{
+				Severity: pb.Diagnostic_SEVERITY_ERROR,
+				Summary:  "Unsupported argument",
+				Detail:   `An argument named "foo" is not expected here.`,
+				Range: &pb.Range{
+					Filename: "enos-scenario-upgrade.hcl",
+					Start:    &pb.Range_Pos{Line: 12, Column: 5},
+					End:      &pb.Range_Pos{Line: 12, Column: 8},
+				},
+			},
+			{
+				// Files outside of the repository are not annotated.
+				Severity: pb.Diagnostic_SEVERITY_ERROR,
+				Summary:  "Module error",
+				Range:    &pb.Range{Filename: "/tmp/module/main.tf", Start: &pb.Range_Pos{Line: 1}},
+			},
+		},
+	}))
+	require.NoError(t, gh.Notify(ctx, testSummary(t)))
+
+	reqs := requests()
+	require.Len(t, reqs, 5)
+	for _, req := range reqs {
+		require.Equal(t, "Bearer ghp_test", req.auth)
+	}
+
+	require.Equal(t, http.MethodPost, reqs[0].method)
+	require.Equal(t, "/repos/hashicorp/enos/check-runs", reqs[0].path)
+	require.Equal(t, "enos", reqs[0].body["name"])
+	require.Equal(t, "abc123", reqs[0].body["head_sha"])
+	require.Equal(t, "in_progress", reqs[0].body["status"])
+
+	require.Equal(t, "/repos/hashicorp/enos/statuses/abc123", reqs[1].path)
+	require.Equal(t, "pending", reqs[1].body["state"])
+	require.Equal(t, "https://github.com/hashicorp/enos/runs/42", reqs[1].body["target_url"])
+
+	require.Equal(t, http.MethodPatch, reqs[2].method)
+	require.Equal(t, "/repos/hashicorp/enos/check-runs/42", reqs[2].path)
+	output, ok := reqs[2].body["output"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "1 of 2 scenarios completed, 1 failed", output["title"])
+	require.Equal(t, []any{map[string]any{
+		"path":             "enos/enos-scenario-upgrade.hcl",
+		"start_line":       float64(12),
+		"end_line":         float64(12),
+		"start_column":     float64(5),
+		"end_column":       float64(8),
+		"annotation_level": "failure",
+		"title":            "upgrade: Unsupported argument",
+		"message":          `An argument named "foo" is not expected here.`,
+	}}, output["annotations"])
+
+	require.Equal(t, "completed", reqs[3].body["status"])
+	require.Equal(t, "failure", reqs[3].body["conclusion"])
+	output, ok = reqs[3].body["output"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "1 passed, 1 failed in 5m0s", output["title"])
+	require.Contains(t, output["summary"], "| :x: | `upgrade` | 5m0s | Error: <timeout> waiting for cluster |")
+	require.Contains(t, output["summary"], "- [CI run](https://ci.example.com/runs/1)")
+
+	require.Equal(t, "failure", reqs[4].body["state"])
+	require.Equal(t, "enos", reqs[4].body["context"])
+}
+
+// Test_GitHub_Notify_NotStarted tests that the check run is created when the run wasn't started.
+func Test_GitHub_Notify_NotStarted(t *testing.T) {
+	t.Parallel()
+
+	srv, requests := testGitHubServer(t)
+	gh := NewGitHub(
+		WithGitHubAPIURL(srv.URL),
+		WithGitHubToken("ghp_test"),
+		WithGitHubRepository("hashicorp/enos"),
+		WithGitHubSHA("abc123"),
+		WithGitHubCheckName("enos / scenarios"),
+	)
+
+	require.NoError(t, gh.Report(context.Background(), &ScenarioResult{Name: "smoke"}))
+	require.NoError(t, gh.Notify(context.Background(), NewSummary("enos scenario run", &pb.OperationResponses{}, false)))
+
+	// Results aren't reported before the check run has been created.
+	reqs := requests()
+	require.Len(t, reqs, 2)
+	require.Equal(t, "/repos/hashicorp/enos/check-runs", reqs[0].path)
+	require.Equal(t, "enos / scenarios", reqs[0].body["name"])
+	require.Equal(t, "success", reqs[0].body["conclusion"])
+	require.Equal(t, "success", reqs[1].body["state"])
+	require.Equal(t, "enos / scenarios", reqs[1].body["context"])
+
+	gh = NewGitHub(WithGitHubAPIURL(srv.URL), WithGitHubToken("ghp_test"))
+	require.ErrorContains(t, gh.Start(context.Background(), "enos scenario run", 1), "requires a repository")
+
+	gh = NewGitHub(
+		WithGitHubAPIURL(srv.URL),
+		WithGitHubToken("ghp_test"),
+		WithGitHubRepository("hashicorp/unknown"),
+		WithGitHubSHA("abc123"),
+	)
+	require.ErrorContains(t, gh.Start(context.Background(), "enos scenario run", 1), "404 Not Found: Not Found")
+}
+
+// Test_GitHub_App tests authenticating as a GitHub App installation.
+func Test_GitHub_App(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+
+	srv, requests := testGitHubServer(t)
+	gh := NewGitHub(
+		WithGitHubAPIURL(srv.URL),
+		WithGitHubApp("1234", "5678", privateKey),
+		WithGitHubRepository("hashicorp/enos"),
+		WithGitHubSHA("abc123"),
+	)
+	require.NoError(t, gh.Start(context.Background(), "enos scenario run", 1))
+
+	reqs := requests()
+	require.Len(t, reqs, 3)
+	require.Equal(t, "/app/installations/5678/access_tokens", reqs[0].path)
+	// The installation token is only created once.
+	require.Equal(t, "Bearer ghs_install", reqs[1].auth)
+	require.Equal(t, "Bearer ghs_install", reqs[2].auth)
+
+	jwt := strings.TrimPrefix(reqs[0].auth, "Bearer ")
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], sig))
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	claims := map[string]any{}
+	require.NoError(t, json.Unmarshal(claimsJSON, &claims))
+	require.Equal(t, "1234", claims["iss"])
+
+	gh = NewGitHub(
+		WithGitHubAPIURL(srv.URL),
+		WithGitHubApp("1234", "5678", "not a key"),
+		WithGitHubRepository("hashicorp/enos"),
+		WithGitHubSHA("abc123"),
+	)
+	require.ErrorContains(t, gh.Start(context.Background(), "enos scenario run", 1), "not PEM encoded")
+}
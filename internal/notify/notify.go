@@ -0,0 +1,382 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package notify sends the summaries of scenario runs to notification services like Slack and
+// reports the results of scenarios to services like GitHub as they complete.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/stepresult"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Notifier sends the summary of a run.
+type Notifier interface {
+	Notify(ctx context.Context, summary *Summary) error
+}
+
+// Reporter is a Notifier that also reports the progress of a run as it happens. Start is called
+// before the operations of the run are streamed, Report is called with the result of each scenario
+// as it completes, and Notify is called with the summary when the run has completed. Report can be
+// called concurrently.
+type Reporter interface {
+	Notifier
+	Start(ctx context.Context, command string, scenarios int) error
+	Report(ctx context.Context, result *ScenarioResult) error
+}
+
+// Summary is the summary of a run of scenario operations.
+type Summary struct {
+	// Command is the command of the run, e.g. "enos scenario run".
+	Command   string
+	Scenarios []*ScenarioResult
+	// Skipped are the scenarios that were skipped by skip files.
+	Skipped []*SkippedResult
+	// Errors are the summaries of the errors of the run that don't belong to a scenario, e.g. a
+	// flight plan that failed to decode.
+	Errors []string
+	// Diagnostics are the diagnostics of the errors.
+	Diagnostics []*pb.Diagnostic
+	Duration    time.Duration
+	Links       []*Link
+	LogFile     string
+}
+
+// ScenarioResult is the result of a scenario of a run.
+type ScenarioResult struct {
+	// Name is the name of the scenario and its variants.
+	Name     string
+	Scenario *flightplan.Scenario
+	Status   pb.Operation_Status
+	Failed   bool
+	Duration time.Duration
+	// XFail is the reason that the scenario is expected to fail. XFailed is whether it failed as
+	// expected, otherwise it passed unexpectedly.
+	XFail   string
+	XFailed bool
+	// Error is the summary of the first error of the scenario.
+	Error string
+	// Diagnostics are the diagnostics of the scenario and its Terraform commands.
+	Diagnostics []*pb.Diagnostic
+}
+
+// SkippedResult is a scenario of a run that was skipped by a skip file.
+type SkippedResult struct {
+	// Name is the name of the scenario and its variants.
+	Name     string
+	Scenario *flightplan.Scenario
+	Reason   string
+}
+
+// Link is a link to a report or artifact of a run.
+type Link struct {
+	Name string
+	URL  string
+}
+
+// SummaryOpt is a functional option.
+type SummaryOpt func(*Summary)
+
+// NewSummary takes the command and responses of a run and options and returns a new Summary. The
+// duration of the run is the time between the first operation starting and the last completing.
+func NewSummary(
+	command string,
+	res *pb.OperationResponses,
+	failOnWarnings bool,
+	opts ...SummaryOpt,
+) *Summary {
+	s := &Summary{
+		Command:     command,
+		Scenarios:   []*ScenarioResult{},
+		Skipped:     []*SkippedResult{},
+		Errors:      []string{},
+		Diagnostics: []*pb.Diagnostic{},
+		Links:       []*Link{},
+	}
+
+	for _, diag := range diagnostics.Concat(res.GetDecode().GetDiagnostics(), res.GetDiagnostics()) {
+		if diagnostics.HasFailed(failOnWarnings, []*pb.Diagnostic{diag}) {
+			s.Errors = append(s.Errors, diag.GetSummary())
+			s.Diagnostics = append(s.Diagnostics, diag)
+		}
+	}
+
+	var started, completed time.Time
+	for _, r := range res.GetResponses() {
+		result := NewScenarioResult(r, failOnWarnings)
+		if r.GetStartedAt() != nil && r.GetCompletedAt() != nil {
+			start := r.GetStartedAt().AsTime()
+			end := r.GetCompletedAt().AsTime()
+			if started.IsZero() || start.Before(started) {
+				started = start
+			}
+			if end.After(completed) {
+				completed = end
+			}
+		}
+
+		s.Scenarios = append(s.Scenarios, result)
+	}
+	s.Duration = completed.Sub(started)
+
+	for _, skipped := range res.GetDecode().GetSkipped() {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(skipped.GetScenario())
+		s.Skipped = append(s.Skipped, &SkippedResult{
+			Name:     scenario.String(),
+			Scenario: scenario,
+			Reason:   skipped.GetReason(),
+		})
+	}
+
+	// Show failed scenarios first as they're what people are looking for.
+	slices.SortStableFunc(s.Scenarios, func(a, b *ScenarioResult) int {
+		switch {
+		case a.Failed == b.Failed:
+			return strings.Compare(a.Name, b.Name)
+		case a.Failed:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// NewScenarioResult takes the response of a scenario operation and returns its result.
+func NewScenarioResult(res *pb.Operation_Response, failOnWarnings bool) *ScenarioResult {
+	scenario := flightplan.NewScenario()
+	scenario.FromRef(res.GetOp().GetScenario())
+
+	result := &ScenarioResult{
+		Name:        scenario.String(),
+		Scenario:    scenario,
+		Status:      res.GetStatus(),
+		Failed:      diagnostics.OpResFailed(failOnWarnings, res),
+		Diagnostics: diagnostics.OpResDiags(res),
+	}
+
+	if res.GetStartedAt() != nil && res.GetCompletedAt() != nil {
+		result.Duration = res.GetCompletedAt().AsTime().Sub(res.GetStartedAt().AsTime())
+	}
+
+	if xfail := res.GetXfail(); xfail != nil {
+		result.XFail = xfail.GetReason()
+		result.XFailed = xfail.GetFailed()
+	}
+
+	if result.Failed || result.XFailed {
+		result.Error = firstError(res)
+	}
+
+	return result
+}
+
+// WithSummaryLinks sets the links of the summary, keyed by their names. Links are sorted by name.
+func WithSummaryLinks(links map[string]string) SummaryOpt {
+	return func(s *Summary) {
+		for name, url := range links {
+			s.Links = append(s.Links, &Link{Name: name, URL: url})
+		}
+		slices.SortFunc(s.Links, func(a, b *Link) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+}
+
+// WithSummaryLogFile sets the path of the log file of the run.
+func WithSummaryLogFile(path string) SummaryOpt {
+	return func(s *Summary) {
+		s.LogFile = path
+	}
+}
+
+// Passed returns the number of scenarios that passed, excluding those that failed as expected.
+func (s *Summary) Passed() int {
+	return len(s.Scenarios) - s.Failed() - s.XFailed()
+}
+
+// XFailed returns the number of scenarios that failed as expected.
+func (s *Summary) XFailed() int {
+	xfailed := 0
+	for _, scenario := range s.Scenarios {
+		if scenario.XFailed {
+			xfailed++
+		}
+	}
+
+	return xfailed
+}
+
+// XPassed returns the number of scenarios that were expected to fail but passed.
+func (s *Summary) XPassed() int {
+	xpassed := 0
+	for _, scenario := range s.Scenarios {
+		if scenario.XFail != "" && !scenario.XFailed {
+			xpassed++
+		}
+	}
+
+	return xpassed
+}
+
+// Counts returns the number of scenarios that passed and failed, and the number that failed as
+// expected and passed unexpectedly if there are any, e.g. "3 passed, 1 failed, 1 xfailed".
+func (s *Summary) Counts() string {
+	counts := fmt.Sprintf("%d passed, %d failed", s.Passed(), s.Failed())
+	if xfailed := s.XFailed(); xfailed > 0 {
+		counts += fmt.Sprintf(", %d xfailed", xfailed)
+	}
+	if xpassed := s.XPassed(); xpassed > 0 {
+		counts += fmt.Sprintf(", %d xpassed", xpassed)
+	}
+
+	return counts
+}
+
+// Failed returns the number of scenarios that failed.
+func (s *Summary) Failed() int {
+	failed := 0
+	for _, scenario := range s.Scenarios {
+		if scenario.Failed {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// HasFailed returns whether or not the run failed.
+func (s *Summary) HasFailed() bool {
+	return len(s.Errors) > 0 || s.Failed() > 0
+}
+
+// Markdown returns the summary as GitHub flavored Markdown with a table of the scenarios.
+func (s *Summary) Markdown() string {
+	result := "passed"
+	if s.HasFailed() {
+		result = "failed"
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "`%s` %s.\n", s.Command, result)
+
+	if len(s.Errors) > 0 {
+		b.WriteString("\n")
+		for _, err := range s.Errors {
+			fmt.Fprintf(b, "- %s\n", markdownEscapeLine(err))
+		}
+	}
+
+	if len(s.Scenarios) > 0 || len(s.Skipped) > 0 {
+		b.WriteString("\n| | Scenario | Duration | Error |\n|---|---|---|---|\n")
+		for _, scenario := range s.Scenarios {
+			icon := ":white_check_mark:"
+			if scenario.Failed {
+				icon = ":x:"
+			}
+			if scenario.XFail != "" {
+				icon = ":construction:"
+				if !scenario.XFailed {
+					icon = ":warning:"
+				}
+			}
+			dur := ""
+			if d := scenario.Duration.Round(time.Second); d > 0 {
+				dur = d.String()
+			}
+			fmt.Fprintf(b, "| %s | `%s` | %s | %s |\n",
+				icon,
+				markdownEscapeCell(scenario.Name),
+				dur,
+				markdownEscapeCell(scenario.errorWithXFail()),
+			)
+		}
+		for _, skipped := range s.Skipped {
+			fmt.Fprintf(b, "| :fast_forward: | `%s` | | skipped: %s |\n",
+				markdownEscapeCell(skipped.Name),
+				markdownEscapeCell(skipped.Reason),
+			)
+		}
+	}
+
+	if len(s.Links) > 0 || s.LogFile != "" {
+		b.WriteString("\n")
+		for _, link := range s.Links {
+			fmt.Fprintf(b, "- [%s](%s)\n", markdownEscapeLine(link.Name), link.URL)
+		}
+		if s.LogFile != "" {
+			fmt.Fprintf(b, "- Logs: `%s`\n", s.LogFile)
+		}
+	}
+
+	return b.String()
+}
+
+// errorWithXFail returns the error of the scenario prefixed by whether it failed as expected or
+// passed unexpectedly if it's expected to fail.
+func (r *ScenarioResult) errorWithXFail() string {
+	switch {
+	case r.XFail == "":
+		return r.Error
+	case !r.XFailed:
+		return "unexpected pass, expected failure: " + r.XFail
+	case r.Error == "":
+		return "expected failure: " + r.XFail
+	default:
+		return "expected failure: " + r.XFail + ": " + r.Error
+	}
+}
+
+// firstError returns the summary of the first error of the response. The errors of the Terraform
+// commands of the operation are preferred as the errors of the response itself are usually about
+// the operation as a whole, and the errors of exec resources are preferred over both.
+func firstError(res *pb.Operation_Response) string {
+	// The errors of exec resources that failed to apply have the output of the commands that failed,
+	// which is what people are looking for.
+	for _, apply := range []*pb.Terraform_Command_Apply_Response{res.GetLaunch().GetApply(), res.GetRun().GetApply()} {
+		for _, result := range stepresult.Failed(apply.GetStepResults()) {
+			return stepresult.ErrorSummary(result.GetStep(), result.GetResources()[0])
+		}
+	}
+
+	var first string
+	for _, diag := range diagnostics.OpResDiags(res) {
+		if diag.GetSeverity() != pb.Diagnostic_SEVERITY_ERROR {
+			continue
+		}
+
+		if !slices.Contains(res.GetDiagnostics(), diag) {
+			return diag.GetSummary()
+		}
+
+		if first == "" {
+			first = diag.GetSummary()
+		}
+	}
+
+	return first
+}
+
+// markdownEscapeLine replaces the line breaks of text so that it stays within a Markdown list item.
+func markdownEscapeLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// markdownEscapeCell escapes text so that it stays within a Markdown table cell.
+func markdownEscapeCell(s string) string {
+	return strings.ReplaceAll(markdownEscapeLine(s), "|", `\|`)
+}
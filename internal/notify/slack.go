@@ -0,0 +1,269 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultSlackAPIURL is the URL of the Slack API method that posts messages to channels.
+const DefaultSlackAPIURL = "https://slack.com/api/chat.postMessage"
+
+// maxSlackScenarios is the number of scenarios that are listed in a message. Slack limits the
+// length of the text of a block so larger runs only list the first scenarios, failures first.
+const maxSlackScenarios = 25
+
+var _ Notifier = (*Slack)(nil)
+
+// Slack posts the summaries of runs to Slack, either to an incoming webhook or to a channel with
+// a bot token.
+type Slack struct {
+	webhookURL   string
+	token        string
+	channel      string
+	apiURL       string
+	failuresOnly bool
+	client       *http.Client
+}
+
+// SlackOpt is a functional option.
+type SlackOpt func(*Slack)
+
+// NewSlack takes options and returns a new Slack notifier.
+func NewSlack(opts ...SlackOpt) *Slack {
+	s := &Slack{
+		apiURL: DefaultSlackAPIURL,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithSlackWebhookURL sets the URL of the incoming webhook that messages are posted to.
+func WithSlackWebhookURL(url string) SlackOpt {
+	return func(s *Slack) {
+		s.webhookURL = url
+	}
+}
+
+// WithSlackToken sets the bot token that is used to post messages to the channel.
+func WithSlackToken(token string) SlackOpt {
+	return func(s *Slack) {
+		s.token = token
+	}
+}
+
+// WithSlackChannel sets the channel that messages are posted to. Incoming webhooks post to the
+// channel of the webhook unless it is set.
+func WithSlackChannel(channel string) SlackOpt {
+	return func(s *Slack) {
+		s.channel = channel
+	}
+}
+
+// WithSlackAPIURL sets the URL of the API method that posts messages with a token.
+func WithSlackAPIURL(url string) SlackOpt {
+	return func(s *Slack) {
+		s.apiURL = url
+	}
+}
+
+// WithSlackFailuresOnly sets whether or not only the summaries of failed runs are posted.
+func WithSlackFailuresOnly(failuresOnly bool) SlackOpt {
+	return func(s *Slack) {
+		s.failuresOnly = failuresOnly
+	}
+}
+
+// WithSlackHTTPClient sets the HTTP client.
+func WithSlackHTTPClient(client *http.Client) SlackOpt {
+	return func(s *Slack) {
+		s.client = client
+	}
+}
+
+// slackMessage is a Slack message with Block Kit blocks. The text is shown in notifications.
+type slackMessage struct {
+	Channel string        `json:"channel,omitempty"`
+	Text    string        `json:"text"`
+	Blocks  []*slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string       `json:"type"`
+	Text     *slackText   `json:"text,omitempty"`
+	Elements []*slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackResponse is the response of the Slack API. Incoming webhooks respond with plain text.
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// Notify posts the summary to Slack.
+func (s *Slack) Notify(ctx context.Context, summary *Summary) error {
+	if summary == nil || (s.failuresOnly && !summary.HasFailed()) {
+		return nil
+	}
+
+	msg := slackMessageForSummary(summary)
+	msg.Channel = s.channel
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encoding slack message: %w", err)
+	}
+
+	url := s.webhookURL
+	if url == "" {
+		if s.token == "" || s.channel == "" {
+			return errors.New("slack notifier requires a webhook URL, or a token and a channel")
+		}
+		url = s.apiURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if s.webhookURL == "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting slack message: %w", err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("reading slack response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("posting slack message: %s: %s", res.Status, strings.TrimSpace(string(resBody)))
+	}
+
+	// The API always responds with 200 OK and reports errors in the body.
+	if s.webhookURL == "" {
+		apiRes := &slackResponse{}
+		if err := json.Unmarshal(resBody, apiRes); err != nil {
+			return fmt.Errorf("decoding slack response: %w", err)
+		}
+
+		if !apiRes.OK {
+			return fmt.Errorf("posting slack message: %s", apiRes.Error)
+		}
+	}
+
+	return nil
+}
+
+// slackMessageForSummary returns the Slack message of the summary.
+func slackMessageForSummary(summary *Summary) *slackMessage {
+	icon := ":white_check_mark:"
+	result := "passed"
+	if summary.HasFailed() {
+		icon = ":x:"
+		result = "failed"
+	}
+
+	text := fmt.Sprintf("%s %s: %s", summary.Command, result, summary.Counts())
+	if dur := summary.Duration.Round(time.Second); dur > 0 {
+		text += " in " + dur.String()
+	}
+
+	msg := &slackMessage{
+		Text: text,
+		Blocks: []*slackBlock{
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: icon + " *" + slackEscape(text) + "*"}},
+		},
+	}
+
+	if len(summary.Errors) > 0 {
+		lines := []string{}
+		for _, err := range summary.Errors {
+			lines = append(lines, "• "+slackEscape(err))
+		}
+		msg.Blocks = append(msg.Blocks, &slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: strings.Join(lines, "\n")},
+		})
+	}
+
+	if len(summary.Scenarios) > 0 {
+		lines := []string{}
+		for i, scenario := range summary.Scenarios {
+			if i == maxSlackScenarios {
+				lines = append(lines, fmt.Sprintf("_and %d more_", len(summary.Scenarios)-i))
+
+				break
+			}
+
+			icon := ":white_check_mark:"
+			if scenario.Failed {
+				icon = ":x:"
+			}
+			if scenario.XFail != "" {
+				icon = ":construction:"
+				if !scenario.XFailed {
+					icon = ":warning:"
+				}
+			}
+			line := icon + " `" + slackEscape(scenario.Name) + "`"
+			if dur := scenario.Duration.Round(time.Second); dur > 0 {
+				line += " " + dur.String()
+			}
+			if err := scenario.errorWithXFail(); err != "" {
+				line += ": " + slackEscape(err)
+			}
+			lines = append(lines, line)
+		}
+		msg.Blocks = append(msg.Blocks, &slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: strings.Join(lines, "\n")},
+		})
+	}
+
+	footer := []*slackText{}
+	for _, link := range summary.Links {
+		footer = append(footer, &slackText{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("<%s|%s>", link.URL, slackEscape(link.Name)),
+		})
+	}
+	if summary.LogFile != "" {
+		footer = append(footer, &slackText{Type: "mrkdwn", Text: "Logs: `" + slackEscape(summary.LogFile) + "`"})
+	}
+	if len(footer) > 0 {
+		msg.Blocks = append(msg.Blocks, &slackBlock{Type: "context", Elements: footer})
+	}
+
+	return msg
+}
+
+// slackEscape escapes the characters that Slack uses for its markup.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
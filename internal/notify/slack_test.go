@@ -0,0 +1,226 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// testSummary returns the summary of a run with a passed and a failed scenario.
+func testSummary(t *testing.T) *Summary {
+	t.Helper()
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	response := func(name string, dur time.Duration, diags ...*pb.Diagnostic) *pb.Operation_Response {
+		return &pb.Operation_Response{
+			Op: &pb.Ref_Operation{
+				Scenario: &pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: name}},
+			},
+			StartedAt:   timestamppb.New(start),
+			CompletedAt: timestamppb.New(start.Add(dur)),
+			Value: &pb.Operation_Response_Run_{Run: &pb.Operation_Response_Run{
+				Apply: &pb.Terraform_Command_Apply_Response{Diagnostics: diags},
+			}},
+		}
+	}
+
+	return NewSummary("enos scenario run", &pb.OperationResponses{
+		Responses: []*pb.Operation_Response{
+			response("smoke", 2*time.Minute),
+			response("upgrade", 5*time.Minute, &pb.Diagnostic{
+				Severity: pb.Diagnostic_SEVERITY_ERROR,
+				Summary:  "Error: <timeout> waiting for cluster",
+			}),
+		},
+	}, false, WithSummaryLinks(map[string]string{"CI run": "https://ci.example.com/runs/1"}))
+}
+
+// Test_NewSummary tests creating summaries from operation responses.
+func Test_NewSummary(t *testing.T) {
+	t.Parallel()
+
+	summary := testSummary(t)
+	require.Equal(t, 1, summary.Passed())
+	require.Equal(t, 1, summary.Failed())
+	require.True(t, summary.HasFailed())
+	require.Equal(t, 5*time.Minute, summary.Duration)
+	require.Len(t, summary.Scenarios, 2)
+	require.Equal(t, "upgrade", summary.Scenarios[0].Name)
+	require.Equal(t, "Error: <timeout> waiting for cluster", summary.Scenarios[0].Error)
+	require.Equal(t, "smoke", summary.Scenarios[1].Name)
+}
+
+// Test_NewSummary_Skipped tests that skipped scenarios are summarized with their reasons.
+func Test_NewSummary_Skipped(t *testing.T) {
+	t.Parallel()
+
+	summary := NewSummary("enos scenario run", &pb.OperationResponses{
+		Decode: &pb.DecodeResponse{
+			Skipped: []*pb.Scenario_Skipped{{
+				Scenario: &pb.Ref_Scenario{Id: &pb.Scenario_ID{
+					Name:     "upgrade",
+					Variants: &pb.Matrix_Vector{Elements: []*pb.Matrix_Element{{Key: "arch", Value: "arm64"}}},
+				}},
+				Reason: "broken until | the fix is released",
+			}},
+		},
+	}, false)
+
+	require.False(t, summary.HasFailed())
+	require.Equal(t, 0, summary.Passed())
+	require.Len(t, summary.Skipped, 1)
+	require.Equal(t, "upgrade [arch:arm64]", summary.Skipped[0].Name)
+	require.Equal(t, "upgrade", summary.Skipped[0].Scenario.Name)
+	require.Contains(t, summary.Markdown(),
+		"| :fast_forward: | `upgrade [arch:arm64]` | | skipped: broken until \\| the fix is released |",
+	)
+}
+
+// Test_NewSummary_XFail tests that scenarios that are expected to fail are counted separately.
+func Test_NewSummary_XFail(t *testing.T) {
+	t.Parallel()
+
+	response := func(name string, xfail *pb.Operation_Response_XFail, diags ...*pb.Diagnostic) *pb.Operation_Response {
+		return &pb.Operation_Response{
+			Op: &pb.Ref_Operation{
+				Scenario: &pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: name}},
+			},
+			Xfail: xfail,
+			Value: &pb.Operation_Response_Run_{Run: &pb.Operation_Response_Run{
+				Apply: &pb.Terraform_Command_Apply_Response{Diagnostics: diags},
+			}},
+		}
+	}
+
+	summary := NewSummary("enos scenario run", &pb.OperationResponses{
+		Responses: []*pb.Operation_Response{
+			response("smoke", nil),
+			response("upgrade", &pb.Operation_Response_XFail{Reason: "GH-12", Failed: true}, &pb.Diagnostic{
+				Severity: pb.Diagnostic_SEVERITY_ERROR,
+				Summary:  "cluster is sealed",
+			}),
+			response("autopilot", &pb.Operation_Response_XFail{Reason: "GH-34"}),
+		},
+	}, false)
+
+	require.False(t, summary.HasFailed())
+	require.Equal(t, 2, summary.Passed())
+	require.Equal(t, 0, summary.Failed())
+	require.Equal(t, 1, summary.XFailed())
+	require.Equal(t, 1, summary.XPassed())
+	require.Equal(t, "2 passed, 0 failed, 1 xfailed, 1 xpassed", summary.Counts())
+	require.Contains(t, summary.Markdown(), "| :construction: | `upgrade` |  | expected failure: GH-12: cluster is sealed |")
+	require.Contains(t, summary.Markdown(), "| :warning: | `autopilot` |  | unexpected pass, expected failure: GH-34 |")
+}
+
+// Test_NewScenarioResult_StepResults tests that the errors of failed exec resources are preferred.
+func Test_NewScenarioResult_StepResults(t *testing.T) {
+	t.Parallel()
+
+	result := NewScenarioResult(&pb.Operation_Response{
+		Op: &pb.Ref_Operation{
+			Scenario: &pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: "smoke"}},
+		},
+		Value: &pb.Operation_Response_Launch_{Launch: &pb.Operation_Response_Launch{
+			Apply: &pb.Terraform_Command_Apply_Response{
+				Diagnostics: []*pb.Diagnostic{{
+					Severity: pb.Diagnostic_SEVERITY_ERROR,
+					Summary:  "exit status 1",
+				}},
+				StepResults: []*pb.Terraform_StepResult{{
+					Step: "verify",
+					Resources: []*pb.Terraform_StepResult_Resource{
+						{Address: "module.verify.enos_remote_exec.ok"},
+						{
+							Address: "module.verify.enos_remote_exec.check",
+							Failed:  true,
+							Error:   "Execution Error\n\nProcess exited with status 1: vault is sealed",
+						},
+					},
+				}},
+			},
+		}},
+	}, false)
+
+	require.True(t, result.Failed)
+	require.Equal(t, "step verify: enos_remote_exec.check: Process exited with status 1: vault is sealed", result.Error)
+}
+
+// Test_Slack_Notify_Webhook tests posting summaries to incoming webhooks.
+func Test_Slack_Notify_Webhook(t *testing.T) {
+	t.Parallel()
+
+	var msg *slackMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		msg = &slackMessage{}
+		require.NoError(t, json.Unmarshal(body, msg))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	slack := NewSlack(WithSlackWebhookURL(srv.URL))
+	require.NoError(t, slack.Notify(context.Background(), testSummary(t)))
+	require.NotNil(t, msg)
+	require.Equal(t, "enos scenario run failed: 1 passed, 1 failed in 5m0s", msg.Text)
+	require.Len(t, msg.Blocks, 3)
+	require.Equal(t,
+		":x: `upgrade` 5m0s: Error: &lt;timeout&gt; waiting for cluster\n:white_check_mark: `smoke` 2m0s",
+		msg.Blocks[1].Text.Text,
+	)
+	require.Equal(t, "<https://ci.example.com/runs/1|CI run>", msg.Blocks[2].Elements[0].Text)
+}
+
+// Test_Slack_Notify_FailuresOnly tests that only failed runs are posted when configured.
+func Test_Slack_Notify_FailuresOnly(t *testing.T) {
+	t.Parallel()
+
+	posted := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = true
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	slack := NewSlack(WithSlackWebhookURL(srv.URL), WithSlackFailuresOnly(true))
+	require.NoError(t, slack.Notify(context.Background(), NewSummary("enos scenario run", &pb.OperationResponses{}, false)))
+	require.False(t, posted)
+}
+
+// Test_Slack_Notify_Token tests posting summaries to channels with a token and that API errors
+// are returned.
+func Test_Slack_Notify_Token(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer xoxb-test", r.Header.Get("Authorization"))
+		msg := &slackMessage{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(msg))
+		if msg.Channel != "#quality" {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	slack := NewSlack(WithSlackAPIURL(srv.URL), WithSlackToken("xoxb-test"), WithSlackChannel("#quality"))
+	require.NoError(t, slack.Notify(context.Background(), testSummary(t)))
+
+	slack = NewSlack(WithSlackAPIURL(srv.URL), WithSlackToken("xoxb-test"), WithSlackChannel("#unknown"))
+	require.ErrorContains(t, slack.Notify(context.Background(), testSummary(t)), "channel_not_found")
+}
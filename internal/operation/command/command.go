@@ -17,6 +17,7 @@ import (
 type Command struct {
 	Name           string
 	EnvPassthrough bool
+	Niceness       int32
 	ExecOpts       []ExecOpt
 }
 
@@ -92,6 +93,13 @@ func WithEnvPassthrough() Opt {
 	}
 }
 
+// WithNiceness sets the niceness of the command process.
+func WithNiceness(niceness int32) Opt {
+	return func(cmd *Command) {
+		cmd.Niceness = niceness
+	}
+}
+
 // Cmd takes a context and returns an instance of *exec.Cmd.
 func (c *Command) Cmd(ctx context.Context) *exec.Cmd {
 	//nolint:gosec // G204 We know we're passing through to exec.
@@ -107,10 +115,20 @@ func (c *Command) Cmd(ctx context.Context) *exec.Cmd {
 }
 
 // Run takes a context.Context and executes itself. It returns an instance of
-// *exec.Cmd and an error.
+// *exec.Cmd and an error. If the command has a niceness the process is started with it.
 func (c *Command) Run(ctx context.Context) (*exec.Cmd, error) {
 	cmd := c.Cmd(ctx)
-	err := cmd.Run()
+	if c.Niceness == 0 {
+		err := cmd.Run()
+
+		return cmd, err
+	}
+
+	if err := startWithNiceness(cmd, c.Niceness); err != nil {
+		return cmd, err
+	}
+
+	err := cmd.Wait()
 
 	return cmd, err
 }
@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testProcessNiceness runs the command and returns the niceness of the process that it has started.
+func testProcessNiceness(t *testing.T, opts ...Opt) int {
+	t.Helper()
+
+	out := &strings.Builder{}
+	opts = append(opts,
+		WithArgs("-c", `cut -d " " -f 19 /proc/self/stat`),
+		func(cmd *Command) {
+			cmd.ExecOpts = append(cmd.ExecOpts, func(ecmd *exec.Cmd) {
+				ecmd.Stdout = out
+			})
+		},
+	)
+	_, err := NewCommand("sh", opts...).Run(context.Background())
+	require.NoError(t, err)
+
+	niceness, err := strconv.Atoi(strings.TrimSpace(out.String()))
+	require.NoError(t, err)
+
+	return niceness
+}
+
+// Test_Command_Run_Niceness tests that commands with a niceness are started with it.
+func Test_Command_Run_Niceness(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "linux" {
+		t.Skip("niceness is only supported on Linux")
+	}
+
+	// Unprivileged users can only raise the niceness.
+	base := testProcessNiceness(t)
+	if base >= 19 {
+		t.Skipf("the niceness of the test is already %d", base)
+	}
+	niceness := min(base+5, 19)
+
+	require.Equal(t, niceness, testProcessNiceness(t, WithNiceness(int32(niceness))))
+	// Commands without a niceness have the niceness of enos.
+	require.Equal(t, base, testProcessNiceness(t))
+}
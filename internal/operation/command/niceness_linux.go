@@ -0,0 +1,37 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package command
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// startWithNiceness starts the command from a new OS thread that has the niceness. On Linux the
+// niceness is per thread and processes inherit the niceness of the thread that started them, so
+// the process and every thread of it have the niceness from the start. Setting the niceness of the
+// process after it has started would miss the threads that it has already started. Unprivileged
+// users can't lower the niceness of the thread again so we never unlock it, which causes it to exit
+// along with the goroutine. The command must not set a parent death signal as it would be sent as
+// soon as the thread exits.
+func startWithNiceness(cmd *exec.Cmd, niceness int32) error {
+	errC := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, int(niceness)); err != nil {
+			errC <- fmt.Errorf("setting command niceness to %d: %w", niceness, err)
+
+			return
+		}
+
+		errC <- cmd.Start()
+	}()
+
+	return <-errC
+}
@@ -0,0 +1,18 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !linux
+
+package command
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// startWithNiceness is only supported on Linux as other platforms don't have a niceness per
+// thread.
+func startWithNiceness(_ *exec.Cmd, niceness int32) error {
+	return fmt.Errorf("setting command niceness to %d is not supported on %s", niceness, runtime.GOOS)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// execCapture captures the stdout and stderr of an exec sub-command into files in the out
+// directory.
+type execCapture struct {
+	stdout *os.File
+	stderr *os.File
+}
+
+// execCaptureDir returns the directory that the output of the exec sub-commands of the scenario is
+// captured into.
+func execCaptureDir(outDir string, scenario *flightplan.Scenario) string {
+	return filepath.Join(outDir, "exec", scenario.Slug())
+}
+
+// newExecCapture creates the files that the output of the exec sub-command of the request is
+// captured into. The files are named after the time and the sub-command so that every exec of a
+// scenario has its own files, e.g. 20240102T030405.000Z_state_list.stdout.log.
+func newExecCapture(req *pb.Operation_Request, subCmd string, now time.Time) (*execCapture, error) {
+	outDir, err := outDirForReq(req)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario := flightplan.NewScenario()
+	scenario.FromRef(req.GetScenario())
+	dir := execCaptureDir(outDir, scenario)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	base := filepath.Join(dir, now.UTC().Format("20060102T150405.000Z")+"_"+execCaptureName(subCmd))
+	capture := &execCapture{}
+	capture.stdout, err = os.OpenFile(base+".stdout.log", os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	capture.stderr, err = os.OpenFile(base+".stderr.log", os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		capture.stdout.Close()
+
+		return nil, err
+	}
+
+	return capture, nil
+}
+
+// execCaptureName returns a file name safe name of the sub-command from its words, ignoring any
+// flags and their values, e.g. "state list -id=foo" is state_list.
+func execCaptureName(subCmd string) string {
+	words := []string{}
+	for _, word := range strings.Fields(subCmd) {
+		if strings.HasPrefix(word, "-") || strings.ContainsFunc(word, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+		}) {
+			continue
+		}
+
+		words = append(words, word)
+		if len(words) == 3 {
+			break
+		}
+	}
+
+	if len(words) == 0 {
+		return "exec"
+	}
+
+	return strings.Join(words, "_")
+}
+
+// writers returns the stdout and stderr writers that write to both the files and the given writers.
+func (c *execCapture) writers(stdout io.Writer, stderr io.Writer) (io.Writer, io.Writer) {
+	if c == nil {
+		return stdout, stderr
+	}
+
+	return io.MultiWriter(stdout, c.stdout), io.MultiWriter(stderr, c.stderr)
+}
+
+// close closes the files and sets their paths on the response.
+func (c *execCapture) close(res *pb.Terraform_Command_Exec_Response) error {
+	if c == nil {
+		return nil
+	}
+
+	res.StdoutPath = c.stdout.Name()
+	res.StderrPath = c.stderr.Name()
+
+	return errors.Join(c.stdout.Close(), c.stderr.Close())
+}
+
+// execCaptureWarning returns a warning diagnostic for a failure to capture the output of a sub-command. The
+// output is still returned in the response so it is not an error.
+func execCaptureWarning(err error) *pb.Diagnostic {
+	return &pb.Diagnostic{
+		Severity: pb.Diagnostic_SEVERITY_WARNING,
+		Summary:  "unable to capture the output of the exec sub-command",
+		Detail:   err.Error(),
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_execCaptureName tests that the names of captured sub-commands are file name safe and are
+// truncated to the first three words.
+func Test_execCaptureName(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		subCmd   string
+		expected string
+	}{
+		"empty": {
+			subCmd:   "",
+			expected: "exec",
+		},
+		"single word": {
+			subCmd:   "console",
+			expected: "console",
+		},
+		"words": {
+			subCmd:   "state list",
+			expected: "state_list",
+		},
+		"flags": {
+			subCmd:   "state list -id=foo --state ./terraform.tfstate",
+			expected: "state_list",
+		},
+		"only flags": {
+			subCmd:   "-version -json",
+			expected: "exec",
+		},
+		"unsafe words": {
+			subCmd:   "state show aws_instance.foo module.vpc[0] ../../etc/passwd",
+			expected: "state_show",
+		},
+		"truncated": {
+			subCmd:   "workspace select new default other",
+			expected: "workspace_select_new",
+		},
+		"whitespace": {
+			subCmd:   "  output \t my_output\n",
+			expected: "output_my_output",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.expected, execCaptureName(test.subCmd))
+		})
+	}
+}
+
+// Test_newExecCapture tests that the output of exec sub-commands is captured into files that are
+// named after the scenario, the time, and the sub-command.
+func Test_newExecCapture(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6_000_000, time.FixedZone("PST", -8*60*60))
+	scenarioRef := &pb.Ref_Scenario{Id: &pb.Scenario_ID{
+		Name:     "upgrade",
+		Variants: &pb.Matrix_Vector{Elements: []*pb.Matrix_Element{{Key: "arch", Value: "arm64"}}},
+	}}
+	scenario := flightplan.NewScenario()
+	scenario.FromRef(scenarioRef)
+
+	for desc, test := range map[string]struct {
+		ws     func(t *testing.T) *pb.Workspace
+		outDir func(ws *pb.Workspace) string
+		subCmd string
+		base   string
+	}{
+		"out dir": {
+			ws: func(t *testing.T) *pb.Workspace {
+				t.Helper()
+
+				return &pb.Workspace{OutDir: t.TempDir()}
+			},
+			outDir: func(ws *pb.Workspace) string { return ws.GetOutDir() },
+			subCmd: "state list -id=foo",
+			base:   "20240102T110405.006Z_state_list",
+		},
+		"flight plan out dir": {
+			ws: func(t *testing.T) *pb.Workspace {
+				t.Helper()
+
+				return &pb.Workspace{Flightplan: &pb.FlightPlan{BaseDir: t.TempDir()}}
+			},
+			outDir: func(ws *pb.Workspace) string { return filepath.Join(ws.GetFlightplan().GetBaseDir(), ".enos") },
+			subCmd: "-version",
+			base:   "20240102T110405.006Z_exec",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			ws := test.ws(t)
+			req := &pb.Operation_Request{Workspace: ws, Scenario: scenarioRef}
+			capture, err := newExecCapture(req, test.subCmd, now)
+			require.NoError(t, err)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			outW, errW := capture.writers(stdout, stderr)
+			_, err = io.WriteString(outW, "out\n")
+			require.NoError(t, err)
+			_, err = io.WriteString(errW, "err\n")
+			require.NoError(t, err)
+
+			res := &pb.Terraform_Command_Exec_Response{}
+			require.NoError(t, capture.close(res))
+
+			base := filepath.Join(test.outDir(ws), "exec", scenario.Slug(), test.base)
+			require.Equal(t, base+".stdout.log", res.GetStdoutPath())
+			require.Equal(t, base+".stderr.log", res.GetStderrPath())
+			require.Equal(t, "out\n", stdout.String())
+			require.Equal(t, "err\n", stderr.String())
+
+			for path, expected := range map[string]string{
+				res.GetStdoutPath(): "out\n",
+				res.GetStderrPath(): "err\n",
+			} {
+				b, err := os.ReadFile(path)
+				require.NoError(t, err)
+				require.Equal(t, expected, string(b))
+
+				info, err := os.Stat(path)
+				require.NoError(t, err)
+				require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+			}
+
+			// Captures never overwrite the output of another sub-command.
+			_, err = newExecCapture(req, test.subCmd, now)
+			require.ErrorIs(t, err, os.ErrExist)
+		})
+	}
+}
+
+// Test_execCapture_nil tests that sub-commands whose output can't be captured still write to the
+// given writers.
+func Test_execCapture_nil(t *testing.T) {
+	t.Parallel()
+
+	var capture *execCapture
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	outW, errW := capture.writers(stdout, stderr)
+	require.Equal(t, stdout, outW)
+	require.Equal(t, stderr, errW)
+
+	res := &pb.Terraform_Command_Exec_Response{}
+	require.NoError(t, capture.close(res))
+	require.Empty(t, res.GetStdoutPath())
+	require.Empty(t, res.GetStderrPath())
+
+	diag := execCaptureWarning(os.ErrPermission)
+	require.Equal(t, pb.Diagnostic_SEVERITY_WARNING, diag.GetSeverity())
+	require.Equal(t, os.ErrPermission.Error(), diag.GetDetail())
+}
@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+var _ Executor = (*LocalExecutor)(nil)
+
+// Executor returns the work functions that the workers of the operator execute for operation
+// requests. The local executor executes operations in the operator process. Other executors
+// execute them elsewhere, e.g. in Nomad jobs, and stream their events back.
+type Executor interface {
+	WorkFunc(req *pb.Operation_Request) (WorkFunc, error)
+}
+
+// LocalExecutor executes operations in the current process.
+type LocalExecutor struct{}
+
+// NewLocalExecutor returns a new LocalExecutor.
+func NewLocalExecutor() *LocalExecutor {
+	return &LocalExecutor{}
+}
+
+// WorkFunc returns the work function of the operation request.
+func (e *LocalExecutor) WorkFunc(op *pb.Operation_Request) (WorkFunc, error) {
+	switch op.GetValue().(type) {
+	case *pb.Operation_Request_Generate_:
+		return GenerateScenario(op), nil
+	case *pb.Operation_Request_Check_:
+		return CheckScenario(op), nil
+	case *pb.Operation_Request_Launch_:
+		return LaunchScenario(op), nil
+	case *pb.Operation_Request_Destroy_:
+		return DestroyScenario(op), nil
+	case *pb.Operation_Request_Run_:
+		return RunScenario(op), nil
+	case *pb.Operation_Request_Exec_:
+		return ExecScenario(op), nil
+	case *pb.Operation_Request_Output_:
+		return OutputScenario(op), nil
+	case *pb.Operation_Request_Lock_:
+		return LockScenario(op), nil
+	case *pb.Operation_Request_StateBackup_:
+		return BackupScenarioState(op), nil
+	case *pb.Operation_Request_StateRestore_:
+		return RestoreScenarioState(op), nil
+	default:
+		return UnknownWorkFunc(op)
+	}
+}
+
+// MarshalRequest returns the operation request as gzipped JSON, e.g. for the payloads of remote
+// executors.
+func MarshalRequest(req *pb.Operation_Request) ([]byte, error) {
+	b, err := protojson.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalRequest decodes an operation request that is either JSON or gzipped JSON.
+func UnmarshalRequest(b []byte) (*pb.Operation_Request, error) {
+	if len(b) > 1 && b[0] == 0x1f && b[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		b, err = io.ReadAll(zr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req := &pb.Operation_Request{}
+	if err := protojson.Unmarshal(b, req); err != nil {
+		return nil, fmt.Errorf("decoding operation request: %w", err)
+	}
+
+	return req, nil
+}
+
+// remoteMessage is a line of the output of ExecuteRequest. Each line has either an event or the
+// response of the operation.
+type remoteMessage struct {
+	Event    json.RawMessage `json:"event,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// ExecuteRequest executes the operation request in the current process and writes its events and
+// finally its response as JSON lines to the writer. Executors that execute operations elsewhere
+// run it there and decode the output with DecodeRemoteOutput.
+func ExecuteRequest(ctx context.Context, req *pb.Operation_Request, w io.Writer, log hclog.Logger) error {
+	f, err := NewLocalExecutor().WorkFunc(req)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	events := make(chan *pb.Operation_Event)
+	encodeErrs := make(chan error, 1)
+
+	go func() {
+		var err error
+		for event := range events {
+			if err != nil {
+				continue
+			}
+
+			var b []byte
+			b, err = protojson.Marshal(event)
+			if err == nil {
+				err = enc.Encode(&remoteMessage{Event: b})
+			}
+		}
+		encodeErrs <- err
+	}()
+
+	res := f(ctx, events, log)
+	close(events)
+	if err := <-encodeErrs; err != nil {
+		return err
+	}
+
+	if res == nil {
+		return errors.New("operation did not return a response")
+	}
+
+	b, err := protojson.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	return enc.Encode(&remoteMessage{Response: b})
+}
+
+// DecodeRemoteOutput decodes the complete lines of output of ExecuteRequest. Lines that aren't
+// events or responses are ignored. It returns the events and the response, if the output includes
+// it, along with the number of bytes that have been decoded so that callers that read the output
+// incrementally can retain incomplete lines.
+func DecodeRemoteOutput(out []byte) ([]*pb.Operation_Event, *pb.Operation_Response, int, error) {
+	events := []*pb.Operation_Event{}
+	var res *pb.Operation_Response
+
+	end := bytes.LastIndexByte(out, '\n') + 1
+	for _, line := range bytes.Split(out[:end], []byte{'\n'}) {
+		msg := &remoteMessage{}
+		if err := json.Unmarshal(line, msg); err != nil {
+			continue
+		}
+
+		switch {
+		case len(msg.Event) > 0:
+			event := &pb.Operation_Event{}
+			if err := protojson.Unmarshal(msg.Event, event); err != nil {
+				return nil, nil, 0, err
+			}
+			events = append(events, event)
+		case len(msg.Response) > 0:
+			res = &pb.Operation_Response{}
+			if err := protojson.Unmarshal(msg.Response, res); err != nil {
+				return nil, nil, 0, err
+			}
+		}
+	}
+
+	return events, res, end, nil
+}
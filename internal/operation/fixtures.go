@@ -0,0 +1,451 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/generate"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+// fixtureOutputsFile is the name of the file in the fixture module directory that has the outputs
+// of a launched fixture.
+const fixtureOutputsFile = "enos-outputs.json"
+
+// Fixtures launches and destroys the fixtures that the scenarios of a run consume. Fixtures are
+// launched at most once per run by the first launch or run operation that consumes them. After
+// the last run or destroy operation that consumes a fixture has finished the fixture is destroyed,
+// as long as all of its consumers succeeded.
+type Fixtures struct {
+	mu       sync.Mutex
+	fixtures map[string]*fixture
+	// launchFixture and destroyFixture launch and destroy the module of a fixture
+	launchFixture  func(context.Context, *pb.Operation_Request, string, hclog.Logger) ([]byte, []*pb.Diagnostic)
+	destroyFixture func(context.Context, *pb.Operation_Request, string, hclog.Logger) []*pb.Diagnostic
+}
+
+// fixture is the state of a fixture in a run.
+type fixture struct {
+	mu        sync.Mutex
+	launched  bool
+	outputs   []byte
+	diags     []*pb.Diagnostic
+	remaining int32
+	failed    bool
+}
+
+// NewFixtures returns a new Fixtures.
+func NewFixtures() *Fixtures {
+	return &Fixtures{
+		fixtures:       map[string]*fixture{},
+		launchFixture:  launchFixture,
+		destroyFixture: destroyFixture,
+	}
+}
+
+// WorkFunc takes an operation request and a function that returns the work function of a request,
+// e.g. the WorkFunc of an Executor, and returns a work function that sets the outputs of the
+// fixtures that the request consumes before executing the work, and that destroys the fixtures
+// after their last consumer has finished. The outputs are set on a copy of the request that the
+// work is executed for so that the request is never modified.
+func (f *Fixtures) WorkFunc(
+	req *pb.Operation_Request,
+	newWork func(*pb.Operation_Request) (WorkFunc, error),
+) WorkFunc {
+	return func(
+		ctx context.Context,
+		eventC chan *pb.Operation_Event,
+		log hclog.Logger,
+	) *pb.Operation_Response {
+		log = log.With(RequestDebugArgs(req)...)
+		req, _ := proto.Clone(req).(*pb.Operation_Request)
+
+		var launch, release bool
+		switch req.GetValue().(type) {
+		case *pb.Operation_Request_Launch_:
+			launch = true
+		case *pb.Operation_Request_Run_:
+			launch = true
+			release = true
+		case *pb.Operation_Request_Destroy_:
+			release = true
+		default:
+		}
+
+		diags := []*pb.Diagnostic{}
+		for _, def := range req.GetFixtures() {
+			entry := f.fixture(def)
+
+			var outputs []byte
+			var moreDiags []*pb.Diagnostic
+			if launch {
+				outputs, moreDiags = entry.launch(ctx, req, def.GetName(), log, f.launchFixture)
+			} else {
+				outputs, moreDiags = readFixtureOutputs(req, def.GetName())
+			}
+			diags = append(diags, prefixFixtureDiags(def.GetName(), moreDiags)...)
+			def.Outputs = outputs
+		}
+
+		var work WorkFunc
+		if !diagnostics.HasFailed(req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(), diags) {
+			var err error
+			work, err = newWork(req)
+			if err != nil {
+				diags = append(diags, diagnostics.FromErr(err)...)
+			}
+		}
+
+		if diagnostics.HasFailed(req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(), diags) {
+			res, err := NewResponseFromRequest(req)
+			if err != nil {
+				diags = append(diags, diagnostics.FromErr(err)...)
+			}
+			res.Status = pb.Operation_STATUS_FAILED
+			res.Diagnostics = append(res.GetDiagnostics(), diags...)
+
+			if release {
+				f.release(ctx, req, false, log)
+			}
+
+			if err := NewEventSender(eventC).PublishResponse(res); err != nil {
+				log.Error("failed to send event", "error", err)
+			}
+
+			return res
+		}
+
+		res := work(ctx, eventC, log)
+		if res == nil || !release {
+			return res
+		}
+
+		res.Diagnostics = append(res.GetDiagnostics(), diags...)
+		destroyDiags := f.release(ctx, req, !hasFailedStatus(res.GetStatus()), log)
+		res.Diagnostics = append(res.GetDiagnostics(), destroyDiags...)
+		if diagnostics.HasErrors(destroyDiags) {
+			res.Status = pb.Operation_STATUS_FAILED
+		}
+
+		return res
+	}
+}
+
+// fixture returns the state of the fixture in the run of the fixture definition.
+func (f *Fixtures) fixture(def *pb.Fixture) *fixture {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := def.GetRunId() + "/" + def.GetName()
+	entry, ok := f.fixtures[key]
+	if !ok {
+		entry = &fixture{remaining: def.GetConsumers()}
+		f.fixtures[key] = entry
+	}
+
+	return entry
+}
+
+// release releases the fixtures of the request. Any fixtures that no longer have any consumers
+// are destroyed if all of their consumers succeeded.
+func (f *Fixtures) release(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	succeeded bool,
+	log hclog.Logger,
+) []*pb.Diagnostic {
+	diags := []*pb.Diagnostic{}
+
+	for _, def := range req.GetFixtures() {
+		entry := f.fixture(def)
+
+		entry.mu.Lock()
+		entry.remaining--
+		if !succeeded {
+			entry.failed = true
+		}
+		remaining := entry.remaining
+		destroy := remaining <= 0 && !entry.failed
+		entry.mu.Unlock()
+
+		if !destroy {
+			if remaining <= 0 {
+				log.Info("not destroying fixture because a consumer failed", "fixture", def.GetName())
+			}
+
+			continue
+		}
+
+		log.Info("destroying fixture", "fixture", def.GetName())
+		diags = append(diags, prefixFixtureDiags(def.GetName(), f.destroyFixture(ctx, req, def.GetName(), log))...)
+	}
+
+	return diags
+}
+
+// launch launches the fixture with the launch function if it has not been launched in the run and
+// returns its outputs.
+func (f *fixture) launch(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	name string,
+	log hclog.Logger,
+	launchFixture func(context.Context, *pb.Operation_Request, string, hclog.Logger) ([]byte, []*pb.Diagnostic),
+) ([]byte, []*pb.Diagnostic) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.launched {
+		return f.outputs, f.diags
+	}
+
+	log.Info("launching fixture", "fixture", name)
+	f.outputs, f.diags = launchFixture(ctx, req, name, log)
+	f.launched = true
+
+	return f.outputs, f.diags
+}
+
+// launchFixture generates and applies the module of the fixture and returns its outputs. The
+// outputs are also saved in the module directory so that later operations can consume them.
+func launchFixture(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	name string,
+	log hclog.Logger,
+) ([]byte, []*pb.Diagnostic) {
+	runner, fixReq, diags := fixtureRunner(ctx, req, name, log)
+	if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, diags) {
+		return nil, diags
+	}
+
+	events, done := drainingEventSender()
+	defer done()
+
+	launch := runner.scenarioLaunch(ctx, fixReq, events).Launch
+	if diagnostics.HasFailed(
+		runner.TFConfig.FailOnWarnings,
+		launch.GetDiagnostics(),
+		launch.GetInit().GetDiagnostics(),
+		launch.GetValidate().GetDiagnostics(),
+		launch.GetPlan().GetDiagnostics(),
+		launch.GetApply().GetDiagnostics(),
+	) {
+		return nil, append(diags, failedDiags(launch)...)
+	}
+
+	tf, err := runner.TFConfig.Terraform()
+	if err != nil {
+		return nil, append(diags, diagnostics.FromErr(err)...)
+	}
+	outText := NewTextOutput()
+	tf.SetStdout(outText.Stdout)
+	tf.SetStderr(outText.Stderr)
+
+	metas, err := tf.Output(ctx, runner.TFConfig.OutputOptions()...)
+	if err != nil {
+		return nil, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	meta, ok := metas[flightplan.FixtureOutputName]
+	if !ok {
+		return nil, append(diags, diagnostics.FromErr(fmt.Errorf("no output with key %s", flightplan.FixtureOutputName))...)
+	}
+
+	err = os.WriteFile(filepath.Join(runner.TFConfig.DirPath, fixtureOutputsFile), meta.Value, 0o600)
+	if err != nil {
+		return nil, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	return meta.Value, diags
+}
+
+// destroyFixture destroys the module of the fixture if it has any state and removes its outputs.
+func destroyFixture(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	name string,
+	log hclog.Logger,
+) []*pb.Diagnostic {
+	runner, fixReq, diags := fixtureRunner(ctx, req, name, log)
+	if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, diags) {
+		return diags
+	}
+
+	events, done := drainingEventSender()
+	defer done()
+
+	initRes := runner.terraformInit(ctx, fixReq, events)
+	if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, initRes.GetDiagnostics()) {
+		return append(diags, initRes.GetDiagnostics()...)
+	}
+
+	show := runner.terraformShow(ctx, fixReq, events)
+	if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, show.GetDiagnostics()) {
+		return append(diags, show.GetDiagnostics()...)
+	}
+
+	state := &tfjson.State{}
+	if err := state.UnmarshalJSON(show.GetState()); err != nil {
+		return append(diags, diagnostics.FromErr(err)...)
+	}
+
+	destroy := runner.terraformDestroy(ctx, fixReq, events, state)
+	if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, destroy.GetDiagnostics()) {
+		return append(diags, destroy.GetDiagnostics()...)
+	}
+
+	err := os.Remove(filepath.Join(runner.TFConfig.DirPath, fixtureOutputsFile))
+	if err != nil && !os.IsNotExist(err) {
+		diags = append(diags, diagnostics.FromErr(err)...)
+	}
+
+	return diags
+}
+
+// readFixtureOutputs returns the saved outputs of a fixture that has been launched. If the fixture
+// has not been launched no outputs are returned.
+func readFixtureOutputs(req *pb.Operation_Request, name string) ([]byte, []*pb.Diagnostic) {
+	outDir, err := outDirForReq(req)
+	if err != nil {
+		return nil, diagnostics.FromErr(err)
+	}
+
+	outputs, err := os.ReadFile(filepath.Join(fixturesOutDir(outDir), name, fixtureOutputsFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, diagnostics.FromErr(err)
+	}
+
+	return outputs, nil
+}
+
+// fixtureRunner decodes the fixture, generates its module, and returns a runner for the module
+// and a request for the fixture.
+func fixtureRunner(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	name string,
+	log hclog.Logger,
+) (*Runner, *pb.Operation_Request, []*pb.Diagnostic) {
+	runner := NewRunner(
+		WithRunnerTerraformConfig(req.GetWorkspace().GetTfExecCfg()),
+		WithLogger(log.With("fixture", name)),
+	)
+
+	fp, _, decRes := flightplan.DecodeProto(
+		ctx,
+		req.GetWorkspace().GetFlightplan(),
+		flightplan.DecodeTargetFixtures,
+		nil,
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+	)
+	diags := decRes.GetDiagnostics()
+	if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, diags) {
+		return runner, nil, diags
+	}
+
+	fix := fp.Fixture(name)
+	if fix == nil {
+		return runner, nil, append(diags, diagnostics.FromErr(fmt.Errorf("no fixture named %s has been defined", name))...)
+	}
+
+	baseDir, err := isAbs(req.GetWorkspace().GetFlightplan().GetBaseDir())
+	if err != nil {
+		return runner, nil, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	outDir, err := outDirForReq(req)
+	if err != nil {
+		return runner, nil, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	gen, err := generate.NewGenerator(
+		generate.WithScenario(fix.Scenario),
+		generate.WithScenarioBaseDirectory(baseDir),
+		generate.WithOutBaseDirectory(fixturesOutDir(outDir)),
+		generate.WithTags(req.GetWorkspace().GetTags()),
+		generate.WithRunID(req.GetRunId()),
+	)
+	if err != nil {
+		return runner, nil, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	if err = gen.Generate(); err != nil {
+		return runner, nil, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	mod := &pb.Terraform_Module{
+		ModulePath:  gen.TerraformModulePath(),
+		RcPath:      gen.TerraformRCPath(),
+		ScenarioRef: fix.Scenario.Ref(),
+	}
+	runner.TFConfig.WithModule(mod)
+
+	return runner, &pb.Operation_Request{
+		Id:        req.GetId(),
+		RunId:     req.GetRunId(),
+		Scenario:  mod.GetScenarioRef(),
+		Workspace: req.GetWorkspace(),
+	}, diags
+}
+
+// fixturesOutDir returns the directory that fixture modules are generated into.
+func fixturesOutDir(outDir string) string {
+	return filepath.Join(outDir, "fixtures")
+}
+
+// drainingEventSender returns an event sender whose events are discarded and a function to call
+// when done sending events.
+func drainingEventSender() (*EventSender, func()) {
+	eventC := make(chan *pb.Operation_Event)
+	go func() {
+		for range eventC { //nolint:revive // we're draining the channel
+		}
+	}()
+
+	return NewEventSender(eventC), func() { close(eventC) }
+}
+
+// failedDiags returns the diagnostics of all phases of the launch.
+func failedDiags(launch *pb.Operation_Response_Launch) []*pb.Diagnostic {
+	diags := []*pb.Diagnostic{}
+	diags = append(diags, launch.GetDiagnostics()...)
+	diags = append(diags, launch.GetInit().GetDiagnostics()...)
+	diags = append(diags, launch.GetValidate().GetDiagnostics()...)
+	diags = append(diags, launch.GetPlan().GetDiagnostics()...)
+
+	return append(diags, launch.GetApply().GetDiagnostics()...)
+}
+
+// prefixFixtureDiags returns copies of the diagnostics whose summaries are prefixed with the name
+// of the fixture.
+func prefixFixtureDiags(name string, diags []*pb.Diagnostic) []*pb.Diagnostic {
+	res := []*pb.Diagnostic{}
+	for _, diag := range diags {
+		diag, ok := proto.Clone(diag).(*pb.Diagnostic)
+		if !ok {
+			continue
+		}
+		diag.Summary = fmt.Sprintf("fixture %s: %s", name, diag.GetSummary())
+		res = append(res, diag)
+	}
+
+	return res
+}
@@ -0,0 +1,183 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// testFixtures returns fixtures whose modules are launched and destroyed by counting fakes.
+func testFixtures() (*Fixtures, *atomic.Int32, *atomic.Int32) {
+	launches := &atomic.Int32{}
+	destroys := &atomic.Int32{}
+
+	f := NewFixtures()
+	f.launchFixture = func(context.Context, *pb.Operation_Request, string, hclog.Logger) ([]byte, []*pb.Diagnostic) {
+		launches.Add(1)
+
+		return []byte(`{"vpc_id":"vpc-1"}`), nil
+	}
+	f.destroyFixture = func(context.Context, *pb.Operation_Request, string, hclog.Logger) []*pb.Diagnostic {
+		destroys.Add(1)
+
+		return nil
+	}
+
+	return f, launches, destroys
+}
+
+// testFixtureReq returns a run request of the scenario that consumes the fixture.
+func testFixtureReq(name string, consumers int32) *pb.Operation_Request {
+	return &pb.Operation_Request{
+		Id:       name,
+		RunId:    "run",
+		Scenario: &pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: name}},
+		Value:    &pb.Operation_Request_Run_{Run: &pb.Operation_Request_Run{}},
+		Fixtures: []*pb.Fixture{{Name: "network", RunId: "run", Consumers: consumers}},
+	}
+}
+
+// testFixtureWork returns a function that returns work functions that record the fixture outputs
+// of their request and finish with the status.
+func testFixtureWork(status pb.Operation_Status, outputs *sync.Map) func(*pb.Operation_Request) (WorkFunc, error) {
+	return func(req *pb.Operation_Request) (WorkFunc, error) {
+		return func(context.Context, chan *pb.Operation_Event, hclog.Logger) *pb.Operation_Response {
+			if outputs != nil {
+				outputs.Store(req.GetId(), string(req.GetFixtures()[0].GetOutputs()))
+			}
+
+			return &pb.Operation_Response{Status: status}
+		}, nil
+	}
+}
+
+// Test_Fixtures_WorkFunc tests that fixtures are launched once for all of their consumers and
+// destroyed after the last of them, unless any of them failed.
+func Test_Fixtures_WorkFunc(t *testing.T) {
+	t.Parallel()
+
+	t.Run("several consumers", func(t *testing.T) {
+		t.Parallel()
+
+		f, launches, destroys := testFixtures()
+		outputs := &sync.Map{}
+		reqs := []*pb.Operation_Request{}
+		for i := range 3 {
+			reqs = append(reqs, testFixtureReq(fmt.Sprintf("consumer-%d", i), 3))
+		}
+
+		for i, req := range reqs {
+			res := testRunWorkFunc(t, f.WorkFunc(req, testFixtureWork(pb.Operation_STATUS_COMPLETED, outputs)))
+			require.Equal(t, pb.Operation_STATUS_COMPLETED, res.GetStatus())
+			require.EqualValues(t, 1, launches.Load())
+
+			// The fixture is only destroyed after its last consumer
+			if i < len(reqs)-1 {
+				require.EqualValues(t, 0, destroys.Load())
+			} else {
+				require.EqualValues(t, 1, destroys.Load())
+			}
+		}
+
+		for _, req := range reqs {
+			out, ok := outputs.Load(req.GetId())
+			require.True(t, ok)
+			require.Equal(t, `{"vpc_id":"vpc-1"}`, out)
+
+			// The outputs are set on a copy of the request
+			require.Empty(t, req.GetFixtures()[0].GetOutputs())
+		}
+	})
+
+	t.Run("failed consumer", func(t *testing.T) {
+		t.Parallel()
+
+		f, launches, destroys := testFixtures()
+		res := testRunWorkFunc(t, f.WorkFunc(
+			testFixtureReq("consumer-0", 2), testFixtureWork(pb.Operation_STATUS_FAILED, nil),
+		))
+		require.Equal(t, pb.Operation_STATUS_FAILED, res.GetStatus())
+
+		res = testRunWorkFunc(t, f.WorkFunc(
+			testFixtureReq("consumer-1", 2), testFixtureWork(pb.Operation_STATUS_COMPLETED, nil),
+		))
+		require.Equal(t, pb.Operation_STATUS_COMPLETED, res.GetStatus())
+
+		// The fixture is kept so that the failure can be debugged
+		require.EqualValues(t, 1, launches.Load())
+		require.EqualValues(t, 0, destroys.Load())
+	})
+
+	t.Run("launch failed", func(t *testing.T) {
+		t.Parallel()
+
+		f, _, destroys := testFixtures()
+		f.launchFixture = func(context.Context, *pb.Operation_Request, string, hclog.Logger) ([]byte, []*pb.Diagnostic) {
+			return nil, []*pb.Diagnostic{{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "apply failed"}}
+		}
+
+		ran := false
+		res := testRunWorkFunc(t, f.WorkFunc(testFixtureReq("consumer-0", 1), func(*pb.Operation_Request) (WorkFunc, error) {
+			ran = true
+
+			return nil, nil
+		}))
+		require.False(t, ran)
+		require.Equal(t, pb.Operation_STATUS_FAILED, res.GetStatus())
+		require.Len(t, res.GetDiagnostics(), 1)
+		require.Equal(t, "fixture network: apply failed", res.GetDiagnostics()[0].GetSummary())
+		require.EqualValues(t, 0, destroys.Load())
+	})
+
+	t.Run("concurrent consumers", func(t *testing.T) {
+		t.Parallel()
+
+		f, launches, destroys := testFixtures()
+		consumers := 20
+		wg := sync.WaitGroup{}
+		for i := range consumers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				req := testFixtureReq(fmt.Sprintf("consumer-%d", i), int32(consumers))
+				testRunWorkFunc(t, f.WorkFunc(req, testFixtureWork(pb.Operation_STATUS_COMPLETED, nil)))
+			}()
+		}
+		wg.Wait()
+
+		require.EqualValues(t, 1, launches.Load())
+		require.EqualValues(t, 1, destroys.Load())
+	})
+}
+
+// Test_Fixtures_release tests that concurrent releases destroy a fixture exactly once.
+func Test_Fixtures_release(t *testing.T) {
+	t.Parallel()
+
+	f, _, destroys := testFixtures()
+	consumers := 50
+	wg := sync.WaitGroup{}
+	for i := range consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req := testFixtureReq(fmt.Sprintf("consumer-%d", i), int32(consumers))
+			f.release(context.Background(), req, true, hclog.NewNullLogger())
+		}()
+	}
+	wg.Wait()
+
+	require.EqualValues(t, 1, destroys.Load())
+}
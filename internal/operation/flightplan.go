@@ -20,3 +20,13 @@ func isAbs(path string) (string, error) {
 func outDirForWorkspace(w *pb.Workspace) string {
 	return filepath.Join(w.GetFlightplan().GetBaseDir(), ".enos")
 }
+
+// outDirForReq returns the absolute path of the output directory of the workspace of the request.
+func outDirForReq(req *pb.Operation_Request) (string, error) {
+	outDir := req.GetWorkspace().GetOutDir()
+	if outDir == "" {
+		outDir = outDirForWorkspace(req.GetWorkspace())
+	}
+
+	return isAbs(outDir)
+}
@@ -0,0 +1,544 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/operation/terraform/format"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+const (
+	// HistoryDirName is the name of the directory in the out directory that the responses of
+	// operations are written to when they finish.
+	HistoryDirName = "operations"
+	// historyLimit is how many operations we keep in the history. The oldest are removed first.
+	historyLimit = 200
+	// historyKeyFileName is the name of the file in the history directory that has the secret key
+	// that sensitive values are hashed with.
+	historyKeyFileName = ".key"
+	// sensitiveHashPrefix is the prefix of the hashes of sensitive values in the history.
+	sensitiveHashPrefix = "hmac-sha256:"
+)
+
+// HistoryDir returns the directory of the history of operations of the out directory.
+func HistoryDir(outDir string) string {
+	return filepath.Join(outDir, HistoryDirName)
+}
+
+// writeHistory writes the response of the operation that has finished to the history of the out
+// directory of the request so that it can be shown after enos has exited.
+func writeHistory(req *pb.Operation_Request, res *pb.Operation_Response) error {
+	if res.GetOp().GetId() == "" {
+		return errors.New("cannot write operation history without an operation ID")
+	}
+
+	outDir, err := outDirForReq(req)
+	if err != nil {
+		return err
+	}
+
+	// The history has the state of modules, so only the user can read it.
+	dir := HistoryDir(outDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return err
+	}
+
+	key, err := historyKey(outDir)
+	if err != nil {
+		return err
+	}
+
+	b, err := protojson.Marshal(redactHistory(res, key))
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that we never read partially written operations
+	path := filepath.Join(dir, res.GetOp().GetId()+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	return pruneHistory(dir)
+}
+
+// historyKey returns the secret key of the history of the out directory, creating it if it doesn't
+// exist yet. Sensitive values are hashed with the key so that they can't be brute-forced from the
+// history without it.
+func historyKey(outDir string) ([]byte, error) {
+	dir := HistoryDir(outDir)
+	path := filepath.Join(dir, historyKeyFileName)
+
+	key, err := os.ReadFile(path)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			// Another operation created the key before us.
+			return os.ReadFile(path)
+		}
+
+		return nil, err
+	}
+
+	_, err = f.Write(key)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// redactHistory returns the response without the values of sensitive outputs and of the
+// sensitive attributes of resources in the state of modules, which are never written to the
+// history even if they were shown. Sensitive outputs keep the keyed hash of their value so that
+// diffs of operations can still tell whether they have changed.
+func redactHistory(res *pb.Operation_Response, key []byte) *pb.Operation_Response {
+	shows := []*pb.Terraform_Command_Show_Response{}
+	for _, show := range []*pb.Terraform_Command_Show_Response{
+		res.GetRun().GetPriorStateShow(),
+		res.GetDestroy().GetPriorStateShow(),
+	} {
+		if len(show.GetState()) > 0 {
+			shows = append(shows, show)
+		}
+	}
+	if len(res.GetOutput().GetOutput().GetMeta()) == 0 && len(shows) == 0 {
+		return res
+	}
+
+	res, _ = proto.Clone(res).(*pb.Operation_Response)
+	for _, meta := range res.GetOutput().GetOutput().GetMeta() {
+		if !meta.GetSensitive() || len(meta.GetValue()) == 0 {
+			continue
+		}
+
+		hash, err := hashSensitiveValue(key, meta.GetValue())
+		if err != nil {
+			hash = ""
+		}
+		format.RedactTerraformOutput(meta)
+		meta.ValueHash = hash
+	}
+
+	for _, show := range []*pb.Terraform_Command_Show_Response{
+		res.GetRun().GetPriorStateShow(),
+		res.GetDestroy().GetPriorStateShow(),
+	} {
+		if len(show.GetState()) == 0 {
+			continue
+		}
+
+		// We'd rather lose the state than write values that we can't redact.
+		state, err := redactState(show.GetState(), key)
+		if err != nil {
+			state = nil
+		}
+		show.State = state
+	}
+
+	return res
+}
+
+// redactState returns the JSON encoded Terraform state with the values of sensitive outputs
+// replaced by their keyed hash and without the sensitive attributes of resources.
+func redactState(b []byte, key []byte) ([]byte, error) {
+	state := &tfjson.State{}
+	if err := state.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+
+	if state.Values == nil {
+		return b, nil
+	}
+
+	for _, out := range state.Values.Outputs {
+		if out == nil || !out.Sensitive || out.Value == nil {
+			continue
+		}
+
+		value, err := json.Marshal(out.Value)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := hashSensitiveValue(key, value)
+		if err != nil {
+			return nil, err
+		}
+		out.Value = hash
+	}
+
+	if err := redactStateModule(state.Values.RootModule); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(state)
+}
+
+// hashSensitiveValue returns the HMAC-SHA256 of the JSON encoded value with the key of the
+// history, e.g. hmac-sha256:<hex>. The value is encoded canonically first so that equal values have
+// the same hash whether they come from an output or from the state.
+func hashSensitiveValue(key []byte, value []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+
+	return fmt.Sprintf("%s%x", sensitiveHashPrefix, mac.Sum(nil)), nil
+}
+
+// redactStateModule removes the sensitive attributes of the resources of the module and its child
+// modules.
+func redactStateModule(module *tfjson.StateModule) error {
+	if module == nil {
+		return nil
+	}
+
+	for _, resource := range module.Resources {
+		if resource == nil || len(resource.SensitiveValues) == 0 {
+			continue
+		}
+
+		var sensitive any
+		if err := json.Unmarshal(resource.SensitiveValues, &sensitive); err != nil {
+			return err
+		}
+
+		attrs := map[string]any{}
+		for name, val := range resource.AttributeValues {
+			attrs[name] = val
+		}
+		redacted, _ := redactSensitiveValue(attrs, sensitive).(map[string]any)
+		resource.AttributeValues = redacted
+	}
+
+	for _, child := range module.ChildModules {
+		if err := redactStateModule(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// redactSensitiveValue returns the value with every part of it that is marked as sensitive
+// replaced by null. Sensitive marks are true for sensitive values and otherwise mirror the
+// structure of the value, as in the sensitive_values of resources in the JSON state.
+func redactSensitiveValue(val any, sensitive any) any {
+	switch mark := sensitive.(type) {
+	case bool:
+		if mark {
+			return nil
+		}
+	case map[string]any:
+		obj, ok := val.(map[string]any)
+		if !ok {
+			return val
+		}
+
+		for key, m := range mark {
+			if v, ok := obj[key]; ok {
+				obj[key] = redactSensitiveValue(v, m)
+			}
+		}
+
+		return obj
+	case []any:
+		list, ok := val.([]any)
+		if !ok {
+			return val
+		}
+
+		for i, m := range mark {
+			if i < len(list) {
+				list[i] = redactSensitiveValue(list[i], m)
+			}
+		}
+
+		return list
+	}
+
+	return val
+}
+
+// pruneHistory removes the oldest operations from the history when it has more than we keep.
+func pruneHistory(dir string) error {
+	files, err := historyFiles(dir)
+	if err != nil || len(files) <= historyLimit {
+		return err
+	}
+
+	errs := []error{}
+	for _, f := range files[historyLimit:] {
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// historyFile is a file of the history of operations.
+type historyFile struct {
+	path    string
+	id      string
+	modTime int64
+}
+
+// historyFiles returns the files of the history of operations, most recently written first.
+func historyFiles(dir string) ([]historyFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	files := []historyFile{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, historyFile{
+			path:    filepath.Join(dir, entry.Name()),
+			id:      id,
+			modTime: info.ModTime().UnixNano(),
+		})
+	}
+
+	slices.SortFunc(files, func(a, b historyFile) int {
+		return cmp.Or(cmp.Compare(b.modTime, a.modTime), cmp.Compare(a.id, b.id))
+	})
+
+	return files, nil
+}
+
+// readHistoryFile reads the response of an operation of the history.
+func readHistoryFile(path string) (*pb.Operation_Response, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.Operation_Response{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, res); err != nil {
+		return nil, fmt.Errorf("reading operation %s: %w", path, err)
+	}
+
+	return res, nil
+}
+
+// ListHistory takes the out directory and the request and returns the summaries of the operations
+// of its history that match the project of the workspace and the scenario filter and statuses of
+// the request, most recent first.
+func ListHistory(outDir string, req *pb.ListOperationsRequest) ([]*pb.ListOperationsResponse_Operation, error) {
+	files, err := historyFiles(HistoryDir(outDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var filter *flightplan.ScenarioFilter
+	if req.GetFilter() != nil {
+		filter, err = flightplan.NewScenarioFilter(flightplan.WithScenarioFilterDecode(req.GetFilter()))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ops := []*pb.ListOperationsResponse_Operation{}
+	errs := []error{}
+	for _, f := range files {
+		res, err := readHistoryFile(f.path)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if res.GetOp().GetProject() != req.GetWorkspace().GetProject() {
+			continue
+		}
+
+		if len(req.GetStatuses()) > 0 && !slices.Contains(req.GetStatuses(), res.GetStatus()) {
+			continue
+		}
+
+		if filter != nil {
+			scenario := flightplan.NewScenario()
+			scenario.FromRef(res.GetOp().GetScenario())
+			if !scenario.Match(filter) {
+				continue
+			}
+		}
+
+		ops = append(ops, historySummary(res))
+	}
+
+	// The files are sorted by when they were written, which is when the operations completed,
+	// but operations might have started in a different order.
+	slices.SortStableFunc(ops, func(a, b *pb.ListOperationsResponse_Operation) int {
+		return b.GetStartedAt().AsTime().Compare(a.GetStartedAt().AsTime())
+	})
+
+	if limit := int(req.GetLimit()); limit > 0 && len(ops) > limit {
+		ops = ops[:limit]
+	}
+
+	return ops, errors.Join(errs...)
+}
+
+// HistoryDurations takes the out directory and the name of a project and returns the estimated
+// durations of the scenarios of the project in its history. Only launch and run operations that
+// completed are observed.
+func HistoryDurations(outDir string, project string) (*flightplan.ScenarioDurations, error) {
+	ops, err := ListHistory(outDir, &pb.ListOperationsRequest{
+		Workspace: &pb.Workspace{Project: project},
+		Statuses: []pb.Operation_Status{
+			pb.Operation_STATUS_COMPLETED,
+			pb.Operation_STATUS_COMPLETED_WARNING,
+		},
+	})
+
+	durations := flightplan.NewScenarioDurations()
+	for _, op := range ops {
+		if op.GetType() != "launch" && op.GetType() != "run" {
+			continue
+		}
+
+		if op.GetStartedAt() == nil || op.GetCompletedAt() == nil {
+			continue
+		}
+
+		durations.Add(op.GetOp().GetScenario(), op.GetCompletedAt().AsTime().Sub(op.GetStartedAt().AsTime()))
+	}
+
+	return durations, err
+}
+
+// historySummary returns the summary of the response of an operation of the history.
+func historySummary(res *pb.Operation_Response) *pb.ListOperationsResponse_Operation {
+	return &pb.ListOperationsResponse_Operation{
+		Op:          res.GetOp(),
+		Type:        ResponseTypeString(res),
+		Status:      res.GetStatus(),
+		StartedAt:   res.GetStartedAt(),
+		CompletedAt: res.GetCompletedAt(),
+	}
+}
+
+// ReadHistory takes the out directory, the name of a project, and the ID of an operation, or a
+// unique prefix of it, and returns the response of the operation of the project and the path of
+// its file in the history. Operations of other projects are never returned.
+func ReadHistory(outDir string, project string, id string) (*pb.Operation_Response, string, error) {
+	if id == "" {
+		return nil, "", errors.New("an operation ID is required")
+	}
+
+	files, err := historyFiles(HistoryDir(outDir))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var match *pb.Operation_Response
+	var matchPath string
+	matches := 0
+	for _, f := range files {
+		if !strings.HasPrefix(f.id, id) {
+			continue
+		}
+
+		res, err := readHistoryFile(f.path)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if res.GetOp().GetProject() != project {
+			continue
+		}
+
+		if f.id == id {
+			return res, f.path, nil
+		}
+
+		match = res
+		matchPath = f.path
+		matches++
+	}
+
+	switch matches {
+	case 0:
+		if project != "" {
+			return nil, "", fmt.Errorf("operation %s of project %s was not found in %s", id, project, HistoryDir(outDir))
+		}
+
+		return nil, "", fmt.Errorf("operation %s was not found in %s", id, HistoryDir(outDir))
+	case 1:
+		return match, matchPath, nil
+	default:
+		return nil, "", fmt.Errorf("operation ID %s is ambiguous, it matches %d operations", id, matches)
+	}
+}
@@ -0,0 +1,451 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/stepresult"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// historyOutput is an output value of an operation of the history. The value is compact JSON.
+type historyOutput struct {
+	value     string
+	sensitive bool
+}
+
+// historyPhase is how long a phase of an operation took.
+type historyPhase struct {
+	name    string
+	elapsed *durationpb.Duration
+}
+
+// DiffHistory takes the out directory and the request and compares the output values, the
+// resources of the steps, the timings, and the diagnostics of the two operations of the project of
+// the workspace in the history.
+func DiffHistory(outDir string, req *pb.DiffOperationsRequest) *pb.DiffOperationsResponse {
+	res := &pb.DiffOperationsResponse{}
+
+	from, _, err := ReadHistory(outDir, req.GetWorkspace().GetProject(), req.GetFrom())
+	if err != nil {
+		res.Diagnostics = diagnostics.FromErr(err)
+
+		return res
+	}
+
+	to, _, err := ReadHistory(outDir, req.GetWorkspace().GetProject(), req.GetTo())
+	if err != nil {
+		res.Diagnostics = diagnostics.FromErr(err)
+
+		return res
+	}
+
+	res.From = historySummary(from)
+	res.To = historySummary(to)
+
+	key, err := historyKey(outDir)
+	if err != nil {
+		res.Diagnostics = diagnostics.FromErr(err)
+
+		return res
+	}
+
+	if fromUID, toUID := from.GetOp().GetScenario().GetId().GetUid(), to.GetOp().GetScenario().GetId().GetUid(); fromUID != toUID {
+		res.Diagnostics = append(res.GetDiagnostics(), &pb.Diagnostic{
+			Severity: pb.Diagnostic_SEVERITY_WARNING,
+			Summary:  "the operations are of different scenarios",
+			Detail: fmt.Sprintf("operation %s is of scenario %s and operation %s is of scenario %s",
+				from.GetOp().GetId(), from.GetOp().GetScenario().GetId().GetFilter(),
+				to.GetOp().GetId(), to.GetOp().GetScenario().GetId().GetFilter(),
+			),
+		})
+	}
+
+	outputs, err := diffOutputs(from, to, key)
+	if err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+	}
+	res.Outputs = outputs
+
+	resources, err := diffResources(from, to)
+	if err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+	}
+	res.Resources = resources
+
+	res.Durations = diffDurations(from, to)
+	res.NewDiagnostics = newDiagnostics(from, to)
+
+	return res
+}
+
+// diffOutputs returns the output values that differ between the operations. Outputs are only known
+// for operations that have read them, i.e. outputs or the state of the module before destroying it,
+// so if either operation doesn't have them there is nothing to compare.
+func diffOutputs(from, to *pb.Operation_Response, key []byte) ([]*pb.DiffOperationsResponse_Output, error) {
+	fromOutputs, err := responseOutputs(from, key)
+	if err != nil || fromOutputs == nil {
+		return nil, err
+	}
+
+	toOutputs, err := responseOutputs(to, key)
+	if err != nil || toOutputs == nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range fromOutputs {
+		names = append(names, name)
+	}
+	for name := range toOutputs {
+		if _, ok := fromOutputs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	outputs := []*pb.DiffOperationsResponse_Output{}
+	for _, name := range names {
+		fromOut, inFrom := fromOutputs[name]
+		toOut, inTo := toOutputs[name]
+
+		out := &pb.DiffOperationsResponse_Output{
+			Name:      name,
+			Sensitive: fromOut.sensitive || toOut.sensitive,
+		}
+		switch {
+		case !inFrom:
+			out.Change = pb.DiffOperationsResponse_CHANGE_ADDED
+		case !inTo:
+			out.Change = pb.DiffOperationsResponse_CHANGE_REMOVED
+		case fromOut.value != toOut.value:
+			out.Change = pb.DiffOperationsResponse_CHANGE_CHANGED
+		default:
+			continue
+		}
+
+		// Never include sensitive values, only that they have changed.
+		if !out.GetSensitive() {
+			out.From = fromOut.value
+			out.To = toOut.value
+		}
+
+		outputs = append(outputs, out)
+	}
+
+	return outputs, nil
+}
+
+// responseOutputs returns the output values of the operation, or nil if it doesn't know them.
+func responseOutputs(res *pb.Operation_Response, key []byte) (map[string]historyOutput, error) {
+	if res.GetOutput() != nil {
+		outputs := map[string]historyOutput{}
+		for _, meta := range res.GetOutput().GetOutput().GetMeta() {
+			var value string
+			var err error
+			switch {
+			case meta.GetSensitive() && len(meta.GetValue()) > 0:
+				value, err = sensitiveOutputValue(key, meta.GetValue())
+			case meta.GetSensitive() && meta.GetValueHash() != "":
+				// The value has been redacted from the history but we kept its hash.
+				value, err = jsonString(meta.GetValueHash())
+			default:
+				value, err = compactJSON(meta.GetValue())
+			}
+			if err != nil {
+				return nil, fmt.Errorf("reading output %s of operation %s: %w", meta.GetName(), res.GetOp().GetId(), err)
+			}
+			outputs[meta.GetName()] = historyOutput{value: value, sensitive: meta.GetSensitive()}
+		}
+
+		return outputs, nil
+	}
+
+	state, err := responsePriorState(res)
+	if err != nil || state == nil {
+		return nil, err
+	}
+
+	outputs := map[string]historyOutput{}
+	if state.Values == nil {
+		return outputs, nil
+	}
+
+	for name, out := range state.Values.Outputs {
+		b, err := json.Marshal(out.Value)
+		if err != nil {
+			return nil, fmt.Errorf("reading output %s of operation %s: %w", name, res.GetOp().GetId(), err)
+		}
+
+		value := string(b)
+		hash, ok := out.Value.(string)
+		switch {
+		case !out.Sensitive, out.Value == nil:
+		case ok && strings.HasPrefix(hash, sensitiveHashPrefix):
+			// The value has already been replaced by its hash in the history.
+		default:
+			value, err = sensitiveOutputValue(key, b)
+			if err != nil {
+				return nil, fmt.Errorf("reading output %s of operation %s: %w", name, res.GetOp().GetId(), err)
+			}
+		}
+		outputs[name] = historyOutput{value: value, sensitive: out.Sensitive}
+	}
+
+	return outputs, nil
+}
+
+// sensitiveOutputValue returns the hash of the value of a sensitive output as a JSON string, which
+// is how sensitive values are kept in the history, so that we compare the same representation of
+// sensitive values whether or not they have been redacted.
+func sensitiveOutputValue(key []byte, value []byte) (string, error) {
+	hash, err := hashSensitiveValue(key, value)
+	if err != nil {
+		return "", err
+	}
+
+	return jsonString(hash)
+}
+
+// jsonString returns the string as a JSON string.
+func jsonString(s string) (string, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// compactJSON returns the JSON value with insignificant whitespace removed.
+func compactJSON(value []byte) (string, error) {
+	if len(value) == 0 {
+		return "null", nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, value); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// responsePriorState returns the state of the module before it was destroyed, or nil if the
+// operation didn't destroy it.
+func responsePriorState(res *pb.Operation_Response) (*tfjson.State, error) {
+	var b []byte
+	switch {
+	case res.GetRun() != nil:
+		b = res.GetRun().GetPriorStateShow().GetState()
+	case res.GetDestroy() != nil:
+		b = res.GetDestroy().GetPriorStateShow().GetState()
+	}
+
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	state := &tfjson.State{}
+	if err := state.UnmarshalJSON(b); err != nil {
+		return nil, fmt.Errorf("reading state of operation %s: %w", res.GetOp().GetId(), err)
+	}
+
+	return state, nil
+}
+
+// diffResources returns how many resources each step has in the operations, for the steps whose
+// counts differ.
+func diffResources(from, to *pb.Operation_Response) ([]*pb.DiffOperationsResponse_Resources, error) {
+	fromCounts, err := responseResources(from)
+	if err != nil || fromCounts == nil {
+		return nil, err
+	}
+
+	toCounts, err := responseResources(to)
+	if err != nil || toCounts == nil {
+		return nil, err
+	}
+
+	steps := []string{}
+	for step := range fromCounts {
+		steps = append(steps, step)
+	}
+	for step := range toCounts {
+		if _, ok := fromCounts[step]; !ok {
+			steps = append(steps, step)
+		}
+	}
+	slices.Sort(steps)
+
+	resources := []*pb.DiffOperationsResponse_Resources{}
+	for _, step := range steps {
+		if fromCounts[step] == toCounts[step] {
+			continue
+		}
+
+		resources = append(resources, &pb.DiffOperationsResponse_Resources{
+			Step: step,
+			From: fromCounts[step],
+			To:   toCounts[step],
+		})
+	}
+
+	return resources, nil
+}
+
+// responseResources returns how many resources each step of the operation has, or nil if it
+// doesn't know. The state before destroying is preferred, otherwise it's how many resources were
+// applied.
+func responseResources(res *pb.Operation_Response) (map[string]int32, error) {
+	state, err := responsePriorState(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if state != nil {
+		counts := map[string]int32{}
+		if state.Values != nil && state.Values.RootModule != nil {
+			countStateResources(state.Values.RootModule, counts)
+		}
+
+		return counts, nil
+	}
+
+	var apply *pb.Terraform_Command_Apply_Response
+	switch {
+	case res.GetLaunch() != nil:
+		apply = res.GetLaunch().GetApply()
+	case res.GetRun() != nil:
+		apply = res.GetRun().GetApply()
+	}
+
+	if apply == nil {
+		return nil, nil
+	}
+
+	counts := map[string]int32{}
+	for _, step := range apply.GetSteps() {
+		counts[step.GetStep()] = step.GetResourcesDone()
+	}
+
+	return counts, nil
+}
+
+// countStateResources counts the resources of the module and its child modules by step.
+func countStateResources(mod *tfjson.StateModule, counts map[string]int32) {
+	for _, r := range mod.Resources {
+		if step := stepresult.Step(r.Address); step != "" {
+			counts[step]++
+		}
+	}
+
+	for _, child := range mod.ChildModules {
+		countStateResources(child, counts)
+	}
+}
+
+// diffDurations returns how long each phase took in the operations, starting with the whole
+// operation.
+func diffDurations(from, to *pb.Operation_Response) []*pb.DiffOperationsResponse_Duration {
+	durations := []*pb.DiffOperationsResponse_Duration{}
+	fromPhases := responsePhases(from)
+	toPhases := responsePhases(to)
+
+	for _, name := range []string{"total", "generate", "init", "validate", "plan", "apply", "destroy"} {
+		d := &pb.DiffOperationsResponse_Duration{Phase: name}
+		if i := slices.IndexFunc(fromPhases, func(p historyPhase) bool { return p.name == name }); i >= 0 {
+			d.From = fromPhases[i].elapsed
+		}
+		if i := slices.IndexFunc(toPhases, func(p historyPhase) bool { return p.name == name }); i >= 0 {
+			d.To = toPhases[i].elapsed
+		}
+
+		if d.GetFrom() != nil || d.GetTo() != nil {
+			durations = append(durations, d)
+		}
+	}
+
+	return durations
+}
+
+// responsePhases returns how long the operation and each of its phases took.
+func responsePhases(res *pb.Operation_Response) []historyPhase {
+	phases := []historyPhase{}
+	add := func(name string, elapsed *durationpb.Duration) {
+		if elapsed != nil {
+			phases = append(phases, historyPhase{name: name, elapsed: elapsed})
+		}
+	}
+
+	if res.GetStartedAt() != nil && res.GetCompletedAt() != nil {
+		add("total", durationpb.New(res.GetCompletedAt().AsTime().Sub(res.GetStartedAt().AsTime())))
+	}
+
+	switch {
+	case res.GetGenerate() != nil:
+		add("generate", res.GetGenerate().GetElapsed())
+	case res.GetCheck() != nil:
+		add("generate", res.GetCheck().GetGenerate().GetElapsed())
+		add("init", res.GetCheck().GetInit().GetElapsed())
+		add("validate", res.GetCheck().GetValidate().GetElapsed())
+		add("plan", res.GetCheck().GetPlan().GetElapsed())
+	case res.GetLaunch() != nil:
+		add("generate", res.GetLaunch().GetGenerate().GetElapsed())
+		add("init", res.GetLaunch().GetInit().GetElapsed())
+		add("validate", res.GetLaunch().GetValidate().GetElapsed())
+		add("plan", res.GetLaunch().GetPlan().GetElapsed())
+		add("apply", res.GetLaunch().GetApply().GetElapsed())
+	case res.GetRun() != nil:
+		add("generate", res.GetRun().GetGenerate().GetElapsed())
+		add("init", res.GetRun().GetInit().GetElapsed())
+		add("validate", res.GetRun().GetValidate().GetElapsed())
+		add("plan", res.GetRun().GetPlan().GetElapsed())
+		add("apply", res.GetRun().GetApply().GetElapsed())
+		add("destroy", res.GetRun().GetDestroy().GetElapsed())
+	case res.GetDestroy() != nil:
+		add("generate", res.GetDestroy().GetGenerate().GetElapsed())
+		add("init", res.GetDestroy().GetInit().GetElapsed())
+		add("destroy", res.GetDestroy().GetDestroy().GetElapsed())
+	case res.GetLock() != nil:
+		add("generate", res.GetLock().GetGenerate().GetElapsed())
+		add("init", res.GetLock().GetInit().GetElapsed())
+	}
+
+	return phases
+}
+
+// newDiagnostics returns the diagnostics of the to operation that the from operation didn't have.
+// Diagnostics are the same if they have the same severity, summary and detail.
+func newDiagnostics(from, to *pb.Operation_Response) []*pb.Diagnostic {
+	key := func(diag *pb.Diagnostic) string {
+		return strings.Join([]string{diag.GetSeverity().String(), diag.GetSummary(), diag.GetDetail()}, "\x00")
+	}
+
+	seen := map[string]struct{}{}
+	for _, diag := range diagnostics.OpResDiags(from) {
+		seen[key(diag)] = struct{}{}
+	}
+
+	diags := []*pb.Diagnostic{}
+	for _, diag := range diagnostics.OpResDiags(to) {
+		k := key(diag)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		diags = append(diags, diag)
+	}
+
+	return diags
+}
@@ -0,0 +1,565 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_redactHistory_SensitiveHash tests that sensitive values are kept in the history as keyed
+// hashes that can't be brute-forced without the key of the out directory.
+func Test_redactHistory_SensitiveHash(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	key, err := historyKey(outDir)
+	require.NoError(t, err)
+	require.Len(t, key, 32)
+
+	info, err := os.Stat(filepath.Join(HistoryDir(outDir), historyKeyFileName))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// The key is created once per out dir.
+	again, err := historyKey(outDir)
+	require.NoError(t, err)
+	require.Equal(t, key, again)
+
+	other, err := historyKey(t.TempDir())
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+
+	secret := []byte(`"hunter2"`)
+	plain := fmt.Sprintf("%x", sha256.Sum256(secret))
+
+	res := redactHistory(&pb.Operation_Response{
+		Value: &pb.Operation_Response_Output_{
+			Output: &pb.Operation_Response_Output{
+				Output: &pb.Terraform_Command_Output_Response{
+					Meta: []*pb.Terraform_Command_Output_Response_Meta{
+						{Name: "password", Value: secret, Sensitive: true},
+					},
+				},
+			},
+		},
+	}, key)
+	meta := res.GetOutput().GetOutput().GetMeta()[0]
+	require.Empty(t, meta.GetValue())
+	require.True(t, strings.HasPrefix(meta.GetValueHash(), sensitiveHashPrefix))
+	require.NotContains(t, meta.GetValueHash(), plain)
+
+	otherHash, err := hashSensitiveValue(other, secret)
+	require.NoError(t, err)
+	require.NotEqual(t, meta.GetValueHash(), otherHash)
+
+	state, err := redactState([]byte(`{
+  "format_version": "1.0",
+  "values": {
+    "outputs": {
+      "password": {"sensitive": true, "value": "hunter2"}
+    },
+    "root_module": {}
+  }
+}`), key)
+	require.NoError(t, err)
+	require.NotContains(t, string(state), "hunter2")
+	require.NotContains(t, string(state), plain)
+
+	redacted := map[string]any{}
+	require.NoError(t, json.Unmarshal(state, &redacted))
+	outputs, _ := redacted["values"].(map[string]any)["outputs"].(map[string]any)
+	password, _ := outputs["password"].(map[string]any)
+	require.Equal(t, meta.GetValueHash(), password["value"])
+}
+
+// Test_redactState tests that the sensitive attributes of resources are removed from the state of
+// every module and that sensitive outputs are replaced by their keyed hash.
+func Test_redactState(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	hash, err := hashSensitiveValue(key, []byte(`"hunter2"`))
+	require.NoError(t, err)
+
+	for desc, test := range map[string]struct {
+		state    string
+		outputs  map[string]any
+		expected map[string]map[string]any
+	}{
+		"nested modules": {
+			state: `{
+  "format_version": "1.0",
+  "values": {
+    "root_module": {
+      "resources": [{
+        "address": "random_string.root",
+        "mode": "managed",
+        "type": "random_string",
+        "name": "root",
+        "values": {"id": "root", "result": "hunter2"},
+        "sensitive_values": {"result": true}
+      }],
+      "child_modules": [{
+        "address": "module.infra",
+        "resources": [{
+          "address": "module.infra.aws_instance.target",
+          "mode": "managed",
+          "type": "aws_instance",
+          "name": "target",
+          "values": {
+            "id": "i-1234",
+            "tags": {"Name": "target", "Token": "hunter2"},
+            "keys": ["public", "hunter2"]
+          },
+          "sensitive_values": {"tags": {"Token": true}, "keys": [false, true]}
+        }],
+        "child_modules": [{
+          "address": "module.infra.module.db",
+          "resources": [{
+            "address": "module.infra.module.db.aws_db_instance.db",
+            "mode": "managed",
+            "type": "aws_db_instance",
+            "name": "db",
+            "values": {"id": "db", "password": "hunter2", "settings": {"password": "hunter2"}},
+            "sensitive_values": {"password": true, "settings": true}
+          }]
+        }]
+      }]
+    }
+  }
+}`,
+			expected: map[string]map[string]any{
+				"random_string.root": {"id": "root", "result": nil},
+				"module.infra.aws_instance.target": {
+					"id":   "i-1234",
+					"tags": map[string]any{"Name": "target", "Token": nil},
+					"keys": []any{"public", nil},
+				},
+				"module.infra.module.db.aws_db_instance.db": {"id": "db", "password": nil, "settings": nil},
+			},
+		},
+		"no sensitive values": {
+			state: `{
+  "format_version": "1.0",
+  "values": {
+    "outputs": {
+      "name": {"sensitive": false, "value": "target"}
+    },
+    "root_module": {
+      "child_modules": [{
+        "address": "module.infra",
+        "resources": [{
+          "address": "module.infra.aws_instance.target",
+          "mode": "managed",
+          "type": "aws_instance",
+          "name": "target",
+          "values": {"id": "i-1234", "tags": {"Name": "target"}},
+          "sensitive_values": {}
+        }]
+      }]
+    }
+  }
+}`,
+			outputs: map[string]any{"name": "target"},
+			expected: map[string]map[string]any{
+				"module.infra.aws_instance.target": {"id": "i-1234", "tags": map[string]any{"Name": "target"}},
+			},
+		},
+		"sensitive outputs": {
+			state: `{
+  "format_version": "1.0",
+  "values": {
+    "outputs": {
+      "name": {"sensitive": false, "value": "target"},
+      "password": {"sensitive": true, "value": "hunter2"}
+    },
+    "root_module": {}
+  }
+}`,
+			outputs:  map[string]any{"name": "target", "password": hash},
+			expected: map[string]map[string]any{},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := redactState([]byte(test.state), key)
+			require.NoError(t, err)
+			require.NotContains(t, string(b), "hunter2")
+
+			state := &tfjson.State{}
+			require.NoError(t, state.UnmarshalJSON(b))
+
+			outputs := map[string]any{}
+			for name, out := range state.Values.Outputs {
+				outputs[name] = out.Value
+			}
+			if test.outputs == nil {
+				test.outputs = map[string]any{}
+			}
+			require.Equal(t, test.outputs, outputs)
+
+			resources := map[string]map[string]any{}
+			var walk func(*tfjson.StateModule)
+			walk = func(mod *tfjson.StateModule) {
+				for _, r := range mod.Resources {
+					resources[r.Address] = r.AttributeValues
+				}
+				for _, child := range mod.ChildModules {
+					walk(child)
+				}
+			}
+			walk(state.Values.RootModule)
+			require.Equal(t, test.expected, resources)
+		})
+	}
+}
+
+// Test_writeHistory_Permissions tests that only the user can read the history.
+func Test_writeHistory_Permissions(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	dir := HistoryDir(outDir)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.Chmod(dir, 0o755))
+
+	req := &pb.Operation_Request{Workspace: &pb.Workspace{OutDir: outDir}}
+	require.NoError(t, writeHistory(req, &pb.Operation_Response{Op: &pb.Ref_Operation{Id: "abc"}}))
+
+	for path, mode := range map[string]os.FileMode{
+		dir:                                    0o700,
+		filepath.Join(dir, "abc.json"):         0o600,
+		filepath.Join(dir, historyKeyFileName): 0o600,
+	} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, mode, info.Mode().Perm(), path)
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "abc.json.tmp"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// Test_DiffHistory tests that we compare the outputs, the resources, and the diagnostics of
+// operations of the history.
+func Test_DiffHistory(t *testing.T) {
+	t.Parallel()
+
+	outputRes := func(meta ...*pb.Terraform_Command_Output_Response_Meta) *pb.Operation_Response {
+		return &pb.Operation_Response{
+			Value: &pb.Operation_Response_Output_{
+				Output: &pb.Operation_Response_Output{
+					Output: &pb.Terraform_Command_Output_Response{Meta: meta},
+				},
+			},
+		}
+	}
+
+	destroyRes := func(addresses ...string) *pb.Operation_Response {
+		root := map[string]any{}
+		children := map[string][]any{}
+		for _, addr := range addresses {
+			mod, _, _ := strings.Cut(strings.TrimPrefix(addr, "module."), ".")
+			children["module."+mod] = append(children["module."+mod], map[string]any{
+				"address": addr,
+				"mode":    "managed",
+				"type":    "null_resource",
+				"name":    "r",
+			})
+		}
+		mods := []any{}
+		for addr, resources := range children {
+			mods = append(mods, map[string]any{"address": addr, "resources": resources})
+		}
+		root["child_modules"] = mods
+		b, err := json.Marshal(map[string]any{
+			"format_version": "1.0",
+			"values":         map[string]any{"root_module": root},
+		})
+		require.NoError(t, err)
+
+		return &pb.Operation_Response{
+			Value: &pb.Operation_Response_Destroy_{
+				Destroy: &pb.Operation_Response_Destroy{
+					PriorStateShow: &pb.Terraform_Command_Show_Response{State: b},
+				},
+			},
+		}
+	}
+
+	for desc, test := range map[string]struct {
+		from      *pb.Operation_Response
+		to        *pb.Operation_Response
+		outputs   []*pb.DiffOperationsResponse_Output
+		resources []*pb.DiffOperationsResponse_Resources
+		diags     []string
+	}{
+		"outputs": {
+			from: outputRes(
+				&pb.Terraform_Command_Output_Response_Meta{Name: "addr", Value: []byte(`"10.0.0.1"`)},
+				&pb.Terraform_Command_Output_Response_Meta{Name: "password", Value: []byte(`"hunter2"`), Sensitive: true},
+				&pb.Terraform_Command_Output_Response_Meta{Name: "removed", Value: []byte(`1`)},
+				&pb.Terraform_Command_Output_Response_Meta{Name: "token", Value: []byte(`"secret"`), Sensitive: true},
+				&pb.Terraform_Command_Output_Response_Meta{Name: "unchanged", Value: []byte(`{"a": 1}`)},
+			),
+			to: outputRes(
+				&pb.Terraform_Command_Output_Response_Meta{Name: "added", Value: []byte(`[1, 2]`)},
+				&pb.Terraform_Command_Output_Response_Meta{Name: "addr", Value: []byte(`"10.0.0.2"`)},
+				&pb.Terraform_Command_Output_Response_Meta{Name: "password", Value: []byte(`"hunter3"`), Sensitive: true},
+				&pb.Terraform_Command_Output_Response_Meta{Name: "token", Value: []byte(`"secret"`), Sensitive: true},
+				&pb.Terraform_Command_Output_Response_Meta{Name: "unchanged", Value: []byte(`{"a":1}`)},
+			),
+			outputs: []*pb.DiffOperationsResponse_Output{
+				{Name: "added", Change: pb.DiffOperationsResponse_CHANGE_ADDED, To: "[1,2]"},
+				{Name: "addr", Change: pb.DiffOperationsResponse_CHANGE_CHANGED, From: `"10.0.0.1"`, To: `"10.0.0.2"`},
+				{Name: "password", Change: pb.DiffOperationsResponse_CHANGE_CHANGED, Sensitive: true},
+				{Name: "removed", Change: pb.DiffOperationsResponse_CHANGE_REMOVED, From: "1"},
+			},
+		},
+		"resources": {
+			from: destroyRes(
+				"module.infra.null_resource.a",
+				"module.infra.null_resource.b",
+				"module.app.null_resource.a",
+				"module.removed.null_resource.a",
+			),
+			to: destroyRes(
+				"module.infra.null_resource.a",
+				"module.infra.null_resource.b",
+				"module.app.null_resource.a",
+				"module.app.null_resource.b",
+				"module.app.module.nested.null_resource.c",
+				"module.added.null_resource.a",
+			),
+			resources: []*pb.DiffOperationsResponse_Resources{
+				{Step: "added", From: 0, To: 1},
+				{Step: "app", From: 1, To: 3},
+				{Step: "removed", From: 1, To: 0},
+			},
+		},
+		"diagnostics": {
+			from: &pb.Operation_Response{
+				Diagnostics: []*pb.Diagnostic{
+					{Severity: pb.Diagnostic_SEVERITY_WARNING, Summary: "old warning"},
+					{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "error", Detail: "timeout"},
+				},
+			},
+			to: &pb.Operation_Response{
+				Diagnostics: []*pb.Diagnostic{
+					{Severity: pb.Diagnostic_SEVERITY_WARNING, Summary: "old warning"},
+					{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "error", Detail: "connection refused"},
+					{Severity: pb.Diagnostic_SEVERITY_WARNING, Summary: "old warning"},
+				},
+				Value: &pb.Operation_Response_Launch_{Launch: &pb.Operation_Response_Launch{
+					Diagnostics: []*pb.Diagnostic{
+						{Severity: pb.Diagnostic_SEVERITY_WARNING, Summary: "new warning"},
+					},
+				}},
+			},
+			diags: []string{"error", "new warning"},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			outDir := t.TempDir()
+			req := &pb.Operation_Request{Workspace: &pb.Workspace{OutDir: outDir}}
+			test.from.Op = &pb.Ref_Operation{Id: "from"}
+			test.to.Op = &pb.Ref_Operation{Id: "to"}
+			require.NoError(t, writeHistory(req, test.from))
+			require.NoError(t, writeHistory(req, test.to))
+
+			res := DiffHistory(outDir, &pb.DiffOperationsRequest{From: "from", To: "to"})
+			require.Empty(t, res.GetDiagnostics())
+			require.Equal(t, "from", res.GetFrom().GetOp().GetId())
+			require.Equal(t, "to", res.GetTo().GetOp().GetId())
+
+			require.Len(t, res.GetOutputs(), len(test.outputs))
+			for i := range test.outputs {
+				require.True(t, proto.Equal(test.outputs[i], res.GetOutputs()[i]), res.GetOutputs()[i].String())
+			}
+
+			require.Len(t, res.GetResources(), len(test.resources))
+			for i := range test.resources {
+				require.True(t, proto.Equal(test.resources[i], res.GetResources()[i]), res.GetResources()[i].String())
+			}
+
+			diags := []string{}
+			for _, diag := range res.GetNewDiagnostics() {
+				diags = append(diags, diag.GetSummary())
+			}
+			if test.diags == nil {
+				test.diags = []string{}
+			}
+			require.Equal(t, test.diags, diags)
+		})
+	}
+}
+
+// Test_ReadHistory tests that operations of the history are read by their ID or by a unique prefix
+// of it.
+func Test_ReadHistory(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	req := &pb.Operation_Request{Workspace: &pb.Workspace{OutDir: outDir}}
+	for id, project := range map[string]string{
+		"abc":      "",
+		"abcd":     "",
+		"abd1234":  "",
+		"fedc9876": "",
+		"fe001122": "vault",
+	} {
+		require.NoError(t, writeHistory(req, &pb.Operation_Response{
+			Op: &pb.Ref_Operation{Id: id, Project: project},
+		}))
+	}
+
+	for desc, test := range map[string]struct {
+		project  string
+		id       string
+		expected string
+		err      string
+	}{
+		"id": {
+			id:       "abd1234",
+			expected: "abd1234",
+		},
+		"prefix": {
+			id:       "abd",
+			expected: "abd1234",
+		},
+		"id that is a prefix of another id": {
+			id:       "abc",
+			expected: "abc",
+		},
+		"ambiguous prefix": {
+			id:  "ab",
+			err: "operation ID ab is ambiguous, it matches 3 operations",
+		},
+		"prefix of another project": {
+			id:       "fe",
+			expected: "fedc9876",
+		},
+		"project": {
+			project:  "vault",
+			id:       "fe",
+			expected: "fe001122",
+		},
+		"missing": {
+			id:  "0123",
+			err: "operation 0123 was not found in " + HistoryDir(outDir),
+		},
+		"missing in project": {
+			project: "vault",
+			id:      "abc",
+			err:     "operation abc of project vault was not found in " + HistoryDir(outDir),
+		},
+		"no id": {
+			err: "an operation ID is required",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			res, path, err := ReadHistory(outDir, test.project, test.id)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, res.GetOp().GetId())
+			require.Equal(t, filepath.Join(HistoryDir(outDir), test.expected+".json"), path)
+		})
+	}
+
+	_, _, err := ReadHistory(filepath.Join(outDir, "missing"), "", "abc")
+	require.ErrorContains(t, err, "operation abc was not found")
+}
+
+// Test_ListHistory tests that the operations of the history are filtered by project, status, and
+// scenario, and are listed most recent first.
+func Test_ListHistory(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	req := &pb.Operation_Request{Workspace: &pb.Workspace{OutDir: outDir}}
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, op := range []struct {
+		id       string
+		project  string
+		scenario string
+		status   pb.Operation_Status
+	}{
+		{id: "one", scenario: "smoke", status: pb.Operation_STATUS_COMPLETED},
+		{id: "two", scenario: "upgrade", status: pb.Operation_STATUS_FAILED},
+		{id: "three", scenario: "smoke", status: pb.Operation_STATUS_FAILED},
+		{id: "four", project: "vault", scenario: "smoke", status: pb.Operation_STATUS_COMPLETED},
+	} {
+		require.NoError(t, writeHistory(req, &pb.Operation_Response{
+			Op: &pb.Ref_Operation{
+				Id:       op.id,
+				Project:  op.project,
+				Scenario: &pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: op.scenario}},
+			},
+			Status:    op.status,
+			StartedAt: timestamppb.New(started.Add(time.Duration(i) * time.Minute)),
+		}))
+	}
+
+	for desc, test := range map[string]struct {
+		req      *pb.ListOperationsRequest
+		expected []string
+	}{
+		"all": {
+			req:      &pb.ListOperationsRequest{},
+			expected: []string{"three", "two", "one"},
+		},
+		"project": {
+			req:      &pb.ListOperationsRequest{Workspace: &pb.Workspace{Project: "vault"}},
+			expected: []string{"four"},
+		},
+		"statuses": {
+			req:      &pb.ListOperationsRequest{Statuses: []pb.Operation_Status{pb.Operation_STATUS_FAILED}},
+			expected: []string{"three", "two"},
+		},
+		"filter": {
+			req:      &pb.ListOperationsRequest{Filter: &pb.Scenario_Filter{Name: "smoke"}},
+			expected: []string{"three", "one"},
+		},
+		"limit": {
+			req:      &pb.ListOperationsRequest{Limit: 1},
+			expected: []string{"three"},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			ops, err := ListHistory(outDir, test.req)
+			require.NoError(t, err)
+			ids := []string{}
+			for _, op := range ops {
+				ids = append(ids, op.GetOp().GetId())
+			}
+			require.Equal(t, test.expected, ids)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package operation
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+)
+
+// setThreadNiceness locks the calling goroutine to its OS thread and sets the niceness of the
+// thread. On Linux the niceness is per thread and is inherited by any processes that the thread
+// starts, e.g. Terraform. Unprivileged users can't lower the niceness again so the caller must
+// never unlock the thread, which will cause the thread to exit along with the goroutine.
+func setThreadNiceness(niceness int32) error {
+	runtime.LockOSThread()
+
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, int(niceness)); err != nil {
+		return fmt.Errorf("setting operation niceness to %d: %w", niceness, err)
+	}
+
+	return nil
+}
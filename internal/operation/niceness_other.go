@@ -0,0 +1,17 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !linux
+
+package operation
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// setThreadNiceness is only supported on Linux as other platforms don't have a niceness per
+// thread.
+func setThreadNiceness(niceness int32) error {
+	return fmt.Errorf("setting operation niceness to %d is not supported on %s", niceness, runtime.GOOS)
+}
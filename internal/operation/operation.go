@@ -140,6 +140,10 @@ func ReferenceDebugArgs(ref *pb.Ref_Operation) []any {
 		args = append(args, "operation_id", i)
 	}
 
+	if p := ref.GetProject(); p != "" {
+		args = append(args, "project", p)
+	}
+
 	return args
 }
 
@@ -165,6 +169,12 @@ func RequestTypeString(op *pb.Operation_Request) string {
 		return "exec"
 	case *pb.Operation_Request_Output_:
 		return "output"
+	case *pb.Operation_Request_Lock_:
+		return "lock"
+	case *pb.Operation_Request_StateBackup_:
+		return "state_backup"
+	case *pb.Operation_Request_StateRestore_:
+		return "state_restore"
 	default:
 		return "unknown"
 	}
@@ -196,6 +206,14 @@ func EventTypeString(event *pb.Operation_Event) string {
 		return "terraform_exec"
 	case *pb.Operation_Event_Output:
 		return "terraform_output"
+	case *pb.Operation_Event_ProvidersLock:
+		return "terraform_providers_lock"
+	case *pb.Operation_Event_StatePull:
+		return "terraform_state_pull"
+	case *pb.Operation_Event_StatePush:
+		return "terraform_state_push"
+	case *pb.Operation_Event_StepProgress:
+		return "enos_step_progress"
 	default:
 		return "unknown"
 	}
@@ -223,6 +241,12 @@ func ResponseTypeString(op *pb.Operation_Response) string {
 		return "exec"
 	case *pb.Operation_Response_Output_:
 		return "output"
+	case *pb.Operation_Response_Lock_:
+		return "lock"
+	case *pb.Operation_Response_StateBackup_:
+		return "state_backup"
+	case *pb.Operation_Response_StateRestore_:
+		return "state_restore"
 	default:
 		return "unknown"
 	}
@@ -290,6 +314,24 @@ func NewEventFromResponse(res *pb.Operation_Response) (*pb.Operation_Event, erro
 		event.Value = &pb.Operation_Event_Output{
 			Output: t.Output.GetOutput(),
 		}
+	case *pb.Operation_Response_Lock_:
+		if l := t.Lock.GetProvidersLock(); l != nil {
+			event.Value = &pb.Operation_Event_ProvidersLock{
+				ProvidersLock: l,
+			}
+		} else if i := t.Lock.GetInit(); i != nil {
+			event.Value = &pb.Operation_Event_Init{
+				Init: i,
+			}
+		}
+	case *pb.Operation_Response_StateBackup_:
+		event.Value = &pb.Operation_Event_StatePull{
+			StatePull: t.StateBackup.GetStatePull(),
+		}
+	case *pb.Operation_Response_StateRestore_:
+		event.Value = &pb.Operation_Event_StatePush{
+			StatePush: t.StateRestore.GetStatePush(),
+		}
 	default:
 		err := errors.New("cannot convert response type to event")
 		merr = multierror.Append(merr, err)
@@ -341,6 +383,12 @@ func NewResponseFromRequest(op *pb.Operation_Request) (*pb.Operation_Response, e
 		res.Value = &pb.Operation_Response_Exec_{}
 	case *pb.Operation_Request_Output_:
 		res.Value = &pb.Operation_Response_Output_{}
+	case *pb.Operation_Request_Lock_:
+		res.Value = &pb.Operation_Response_Lock_{}
+	case *pb.Operation_Request_StateBackup_:
+		res.Value = &pb.Operation_Response_StateBackup_{}
+	case *pb.Operation_Request_StateRestore_:
+		res.Value = &pb.Operation_Response_StateRestore_{}
 	default:
 		err := errors.New("cannot convert response type to event")
 		merr = multierror.Append(merr, err)
@@ -373,6 +421,7 @@ func NewReferenceFromRequest(op *pb.Operation_Request) (*pb.Ref_Operation, error
 	err := proto.Copy(&pb.Ref_Operation{
 		Id:       op.GetId(),
 		Scenario: op.GetScenario(),
+		Project:  op.GetWorkspace().GetProject(),
 	}, ref)
 
 	return ref, err
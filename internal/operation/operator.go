@@ -30,6 +30,7 @@ type Operator interface {
 	Dispatch(req *pb.Operation_Request) (*pb.Ref_Operation, []*pb.Diagnostic)
 	Stream(ref *pb.Ref_Operation) (*Subscriber, Unsubscriber, error)
 	Response(ref *pb.Ref_Operation) (*pb.Operation_Response, error)
+	Attach(ctx context.Context, ref *pb.Ref_Operation, session *TerminalSession) error
 	Stop() error
 	Start(ctx context.Context) error
 	State() state.State
@@ -15,6 +15,7 @@ import (
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/metrics"
 	"github.com/hashicorp/enos/internal/state"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	"github.com/hashicorp/go-hclog"
@@ -33,6 +34,12 @@ type LocalOperator struct {
 	workEvents   chan *pb.Operation_Event
 	log          hclog.Logger
 	publisher    *Publisher
+	metrics      metrics.Sink
+	executor     Executor
+	fixtures     *Fixtures
+	terminals    *Terminals
+	// producers waits for and reads the outputs of the scenarios that scenarios consume
+	producers *Producers
 }
 
 // LocalOperatorOpt is a functional option to configure a new LocalOperator.
@@ -49,6 +56,11 @@ func NewLocalOperator(opts ...LocalOperatorOpt) *LocalOperator {
 		workEvents:   make(chan *pb.Operation_Event, DefaultOperatorMaxOperationEventQueue),
 		log:          hclog.NewNullLogger(),
 		publisher:    NewPublisher(),
+		metrics:      metrics.Discard,
+		executor:     NewLocalExecutor(),
+		fixtures:     NewFixtures(),
+		terminals:    NewTerminals(),
+		producers:    NewProducers(),
 	}
 
 	for _, opt := range opts {
@@ -76,6 +88,24 @@ func WithLocalOperatorLog(log hclog.Logger) LocalOperatorOpt {
 	}
 }
 
+// WithLocalOperatorMetrics sets the sink that the metrics of completed operations are emitted to.
+func WithLocalOperatorMetrics(sink metrics.Sink) LocalOperatorOpt {
+	return func(l *LocalOperator) {
+		if sink != nil {
+			l.metrics = sink
+		}
+	}
+}
+
+// WithLocalOperatorExecutor sets the executor of the work functions of operations.
+func WithLocalOperatorExecutor(e Executor) LocalOperatorOpt {
+	return func(l *LocalOperator) {
+		if e != nil {
+			l.executor = e
+		}
+	}
+}
+
 // WithLocalOperatorConfig takes the operator configuration and sets it on
 // the local operator.
 func WithLocalOperatorConfig(cfg *pb.Operator_Config) LocalOperatorOpt {
@@ -107,6 +137,7 @@ func (o *LocalOperator) Start(ctx context.Context) error {
 			func(res *pb.Operation_Response) error {
 				return o.state.UpsertOperationResponse(res)
 			},
+			o.metrics,
 		).run(ctx)
 	}
 
@@ -143,6 +174,23 @@ func (o *LocalOperator) Dispatch(
 ) (
 	*pb.Ref_Operation,
 	[]*pb.Diagnostic,
+) {
+	ref, diags := o.dispatch(req)
+
+	// The consumers of the scenario in the run wait for it to be launched, so we have to tell
+	// them that it never will be.
+	if diagnostics.HasErrors(diags) && req.GetConsumers() > 0 {
+		o.producers.finish(req, true)
+	}
+
+	return ref, diags
+}
+
+func (o *LocalOperator) dispatch(
+	req *pb.Operation_Request,
+) (
+	*pb.Ref_Operation,
+	[]*pb.Diagnostic,
 ) {
 	log := o.log.With(RequestDebugArgs(req)...)
 
@@ -175,13 +223,34 @@ func (o *LocalOperator) Dispatch(
 	req.Id = ref.GetId()
 
 	// Create our worker request
-	workReq, err := newWorkReqForOpReq(req)
+	f, err := o.executor.WorkFunc(req)
 	if err != nil {
 		log.Error("failed to determine operation func for request", "error", err)
 
 		return ref, diagnostics.FromErr(err)
 	}
 
+	// Launch and destroy any fixtures that the scenario consumes around the operation. The
+	// operation is executed for a copy of the request that has the outputs of the fixtures.
+	if len(req.GetFixtures()) > 0 {
+		f = o.fixtures.WorkFunc(req, o.executor.WorkFunc)
+	}
+
+	// Set the outputs of any scenarios that the scenario consumes, waiting for them to be launched
+	// if they're launched by the same run, and notify the consumers of the scenario
+	if len(req.GetProducers()) > 0 || req.GetConsumers() > 0 {
+		f = o.producers.WorkFunc(req, f)
+	}
+
+	// Give interactive operations a terminal that clients can attach to
+	if req.GetExec().GetTty() {
+		f = o.terminals.WorkFunc(req, f)
+	}
+	workReq := &workReq{req: req, f: f}
+	if req.GetConsumers() > 0 {
+		workReq.cancelled = func() { o.producers.finish(req, true) }
+	}
+
 	// Set the status for the request as queued in the state and in the event
 	// stream
 	queueRes, err := NewResponseFromRequest(req)
@@ -263,11 +332,20 @@ func (o *LocalOperator) Response(op *pb.Ref_Operation) (*pb.Operation_Response,
 	return o.state.GetOperationResponse(op)
 }
 
+// Attach takes a reference to an operation and attaches the session to the terminal of the
+// operation. It blocks until the sub-command of the operation has exited.
+func (o *LocalOperator) Attach(ctx context.Context, op *pb.Ref_Operation, session *TerminalSession) error {
+	return o.terminals.Attach(ctx, op, session)
+}
+
 func (o *LocalOperator) drainWorkReqQueue() {
 	cancelWorkReq := func(req *workReq) {
 		log := o.log.With(RequestDebugArgs(req.req)...)
 
 		log.Debug("worker operation cancelled")
+		if req.cancelled != nil {
+			req.cancelled()
+		}
 		res, err := NewResponseFromRequest(req.req)
 		if err != nil {
 			err = fmt.Errorf("work request did not return a response: %w", err)
@@ -0,0 +1,241 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+// Producers sets the outputs of the scenarios whose outputs a scenario consumes. The outputs of a
+// producer are read from its state. Consumers that are launched by the same run as their producers
+// wait for the producers to be launched before reading their outputs, which relies on the
+// dispatcher queueing producers before their consumers. Producers that fail to be dispatched, or
+// that are cancelled before they're launched, fail their consumers immediately.
+type Producers struct {
+	mu        sync.Mutex
+	producers map[string]*producer
+}
+
+// producer is the state of the launch of a producer in a run.
+type producer struct {
+	done      chan struct{}
+	finished  bool
+	failed    bool
+	consumers int32
+	released  int32
+}
+
+// NewProducers returns a new Producers.
+func NewProducers() *Producers {
+	return &Producers{
+		producers: map[string]*producer{},
+	}
+}
+
+// WorkFunc takes an operation request and its work function and returns a work function that
+// sets the outputs of the producers that the request consumes before executing the work. If the
+// scenario of the request has consumers in the run they are notified when the work has finished.
+func (p *Producers) WorkFunc(req *pb.Operation_Request, work WorkFunc) WorkFunc {
+	return func(
+		ctx context.Context,
+		eventC chan *pb.Operation_Event,
+		log hclog.Logger,
+	) *pb.Operation_Response {
+		log = log.With(RequestDebugArgs(req)...)
+
+		var launch bool
+		switch req.GetValue().(type) {
+		case *pb.Operation_Request_Launch_, *pb.Operation_Request_Run_:
+			launch = true
+		default:
+		}
+
+		consumer := flightplan.NewScenario()
+		consumer.FromRef(req.GetScenario())
+
+		diags := []*pb.Diagnostic{}
+		for _, def := range req.GetProducers() {
+			scenario := flightplan.NewScenario()
+			scenario.FromRef(def.GetScenario())
+
+			if def.GetRunId() != "" {
+				log.Info("waiting for producer to be launched", "producer", scenario.String())
+				failed, err := p.wait(ctx, def.GetRunId(), def.GetScenario().GetId().GetUid())
+				if err != nil {
+					diags = append(diags, diagnostics.FromErr(err)...)
+
+					continue
+				}
+				if failed {
+					diags = append(diags, diagnostics.FromErr(fmt.Errorf("scenario %s failed to launch", scenario.String()))...)
+
+					continue
+				}
+			}
+
+			outputs, moreDiags := readProducerOutputs(ctx, req, def.GetScenario(), log)
+			diags = append(diags, moreDiags...)
+			if launch && len(outputs) == 0 && !diagnostics.HasErrors(moreDiags) {
+				diags = append(diags, diagnostics.FromErr(fmt.Errorf(
+					"scenario %s consumes the outputs of scenario %s but it has not been launched",
+					consumer.String(), scenario.String(),
+				))...)
+			}
+			def.Outputs = outputs
+		}
+
+		var res *pb.Operation_Response
+		if diagnostics.HasFailed(req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(), diags) {
+			var err error
+			res, err = NewResponseFromRequest(req)
+			if err != nil {
+				diags = append(diags, diagnostics.FromErr(err)...)
+			}
+			res.Status = pb.Operation_STATUS_FAILED
+			res.Diagnostics = append(res.GetDiagnostics(), diags...)
+
+			if err := NewEventSender(eventC).PublishResponse(res); err != nil {
+				log.Error("failed to send event", "error", err)
+			}
+		} else {
+			res = work(ctx, eventC, log)
+			if res != nil {
+				res.Diagnostics = append(res.GetDiagnostics(), diags...)
+			}
+		}
+
+		if req.GetConsumers() > 0 {
+			p.finish(req, res == nil || hasFailedStatus(res.GetStatus()))
+		}
+
+		return res
+	}
+}
+
+// wait waits for the producer to be launched in the run and returns whether it failed.
+func (p *Producers) wait(ctx context.Context, runID string, uid string) (bool, error) {
+	key := runID + "/" + uid
+	p.mu.Lock()
+	entry := p.producer(key)
+	p.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-entry.done:
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	failed := entry.failed
+	entry.released++
+	if entry.released >= entry.consumers {
+		delete(p.producers, key)
+	}
+
+	return failed, nil
+}
+
+// finish notifies the consumers of the scenario of the request that it has finished.
+func (p *Producers) finish(req *pb.Operation_Request, failed bool) {
+	key := req.GetRunId() + "/" + req.GetScenario().GetId().GetUid()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry := p.producer(key)
+	if entry.finished {
+		return
+	}
+	entry.finished = true
+	entry.failed = failed
+	entry.consumers = req.GetConsumers()
+	close(entry.done)
+
+	if entry.released >= entry.consumers {
+		delete(p.producers, key)
+	}
+}
+
+// producer returns the state of the producer with the key. It must be called with the lock held.
+func (p *Producers) producer(key string) *producer {
+	entry, ok := p.producers[key]
+	if !ok {
+		entry = &producer{done: make(chan struct{})}
+		p.producers[key] = entry
+	}
+
+	return entry
+}
+
+// readProducerOutputs returns the JSON encoded outputs of the producer from its state. If the
+// producer has not been launched no outputs are returned.
+func readProducerOutputs(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	ref *pb.Ref_Scenario,
+	log hclog.Logger,
+) ([]byte, []*pb.Diagnostic) {
+	mod, diags := moduleForReq(ctx, &pb.Operation_Request{
+		Scenario:  ref,
+		Workspace: req.GetWorkspace(),
+	})
+	if diagnostics.HasErrors(diags) {
+		return nil, diags
+	}
+
+	if _, err := os.Stat(filepath.Dir(mod.GetModulePath())); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, diags
+		}
+
+		return nil, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	runner := NewRunner(
+		WithRunnerTerraformConfig(req.GetWorkspace().GetTfExecCfg()),
+		WithLogger(log.With("producer", ref.GetId().GetName())),
+	)
+	runner.TFConfig.WithModule(mod)
+
+	tf, err := runner.TFConfig.Terraform()
+	if err != nil {
+		return nil, append(diags, diagnostics.FromErr(err)...)
+	}
+	outText := NewTextOutput()
+	tf.SetStdout(outText.Stdout)
+	tf.SetStderr(outText.Stderr)
+
+	metas, err := tf.Output(ctx, runner.TFConfig.OutputOptions()...)
+	if err != nil {
+		return nil, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	if len(metas) == 0 {
+		return nil, diags
+	}
+
+	outputs := map[string]json.RawMessage{}
+	for name, meta := range metas {
+		outputs[name] = meta.Value
+	}
+
+	bytes, err := json.Marshal(outputs)
+	if err != nil {
+		return nil, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	return bytes, diags
+}
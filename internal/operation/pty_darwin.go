@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build darwin
+
+package operation
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a new pseudo-terminal and returns its primary and secondary ends.
+func openPTY() (*os.File, *os.File, error) {
+	ptm, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fd := int(ptm.Fd())
+	for _, req := range []uint{unix.TIOCPTYGRANT, unix.TIOCPTYUNLK} {
+		if err := unix.IoctlSetInt(fd, req, 0); err != nil {
+			ptm.Close()
+
+			return nil, nil, fmt.Errorf("unlocking pseudo-terminal: %w", err)
+		}
+	}
+
+	// TIOCPTYGNAME writes the name of the secondary end into a buffer of 128 bytes
+	name := make([]byte, 128)
+	//nolint:gosec // G103 The buffer outlives the ioctl.
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(unix.TIOCPTYGNAME), uintptr(unsafe.Pointer(&name[0]))); errno != 0 {
+		ptm.Close()
+
+		return nil, nil, fmt.Errorf("getting pseudo-terminal name: %w", errno)
+	}
+
+	pts, err := os.OpenFile(string(name[:bytes.IndexByte(name, 0)]), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		ptm.Close()
+
+		return nil, nil, err
+	}
+
+	return ptm, pts, nil
+}
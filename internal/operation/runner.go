@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/enos/internal/operation/terraform"
+	"github.com/hashicorp/enos/internal/stepresult"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	"github.com/hashicorp/go-hclog"
 )
@@ -37,6 +38,51 @@ type TextOutput struct {
 	Stderr *strings.Builder
 }
 
+// newTextOutput returns a new TextOutput for a Terraform command. Terraform's stdout is kept when
+// trace logging is enabled so that the complete output of the command can be logged.
+func (r *Runner) newTextOutput() *TextOutput {
+	out := NewTextOutput()
+	if r.log.IsTrace() {
+		out.Stdout = &strings.Builder{}
+	}
+
+	return out
+}
+
+// logTextOutput logs the complete stdout and stderr of the Terraform command at trace level.
+func (r *Runner) logTextOutput(command string, out *TextOutput) {
+	if !r.log.IsTrace() {
+		return
+	}
+
+	stdout := ""
+	if sb, ok := out.Stdout.(*strings.Builder); ok {
+		stdout = sb.String()
+	}
+
+	r.log.Trace("terraform output",
+		"command", command,
+		"stdout", r.TFConfig.MaskSensitiveEnv(stdout),
+		"stderr", r.TFConfig.MaskSensitiveEnv(out.Stderr.String()),
+	)
+}
+
+// newStepProgress returns the progress of the scenario steps for the Terraform command, which
+// publishes a step progress event for every update.
+func (r *Runner) newStepProgress(
+	command string,
+	ref *pb.Ref_Operation,
+	events *EventSender,
+) *stepresult.Progress {
+	return stepresult.NewProgress(command, func(progress *pb.Terraform_StepProgress) {
+		event := newEvent(ref, stepresult.ProgressStatus(progress))
+		event.Value = &pb.Operation_Event_StepProgress{StepProgress: progress}
+		if err := events.Publish(event); err != nil {
+			r.log.Error("failed to publish event", "error", err)
+		}
+	})
+}
+
 // NewRunner takes options and returns a new validated generator.
 func NewRunner(opts ...RunnerOpt) *Runner {
 	ex := &Runner{
@@ -47,6 +93,10 @@ func NewRunner(opts ...RunnerOpt) *Runner {
 		opt(ex)
 	}
 
+	if ex.TFConfig != nil {
+		ex.TFConfig.Log = ex.log.Named("terraform")
+	}
+
 	return ex
 }
 
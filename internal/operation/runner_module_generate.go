@@ -4,13 +4,25 @@
 package operation
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan"
 	"github.com/hashicorp/enos/internal/generate"
+	"github.com/hashicorp/enos/internal/policy"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -30,6 +42,7 @@ func (r *Runner) moduleGenerate(
 	}
 
 	log := r.log.With(RequestDebugArgs(req)...)
+	start := time.Now()
 
 	ref, err := NewReferenceFromRequest(req)
 	if err != nil {
@@ -56,6 +69,7 @@ func (r *Runner) moduleGenerate(
 		resVal.Generate.Diagnostics = append(resVal.Generate.GetDiagnostics(), diags...)
 		event.Status = pb.Operation_STATUS_FAILED
 		event.Diagnostics = resVal.Generate.GetDiagnostics()
+		resVal.Generate.Elapsed = durationpb.New(time.Since(start))
 		eventVal.Generate = resVal.Generate
 
 		if err := events.Publish(event); err != nil {
@@ -91,6 +105,53 @@ func (r *Runner) moduleGenerate(
 		return resVal
 	}
 
+	// A dry run only previews the files. It doesn't evaluate policies or configure our Terraform
+	// executor as there's no module on disk.
+	if gen.DryRun {
+		resVal.Generate.Files, err = dryRunFiles(gen, req.GetGenerate().GetDiff())
+		if err != nil {
+			notifyFail(diagnostics.FromErr(err))
+
+			return resVal
+		}
+
+		resVal.Generate.TerraformModule = &pb.Terraform_Module{
+			ModulePath:  gen.TerraformModulePath(),
+			RcPath:      gen.TerraformRCPath(),
+			ScenarioRef: scenario.Ref(),
+		}
+		event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, resVal.Generate.GetDiagnostics()...)
+		event.Diagnostics = resVal.Generate.GetDiagnostics()
+		resVal.Generate.Elapsed = durationpb.New(time.Since(start))
+		eventVal.Generate = resVal.Generate
+
+		if err := events.Publish(event); err != nil {
+			resVal.Generate.Diagnostics = append(resVal.Generate.GetDiagnostics(), diagnostics.FromErr(err)...)
+			log.Error("failed to send event", "error", err)
+		}
+		log.Debug("finished generate dry run")
+
+		return resVal
+	}
+
+	// Evaluate the policies of the scenario and the generated module before anything is planned
+	if pol := req.GetWorkspace().GetPolicy(); policy.HasRego(pol) {
+		diags, err := evaluateModulePolicies(ctx, pol, scenario, gen.TerraformModulePath())
+		if err != nil {
+			notifyFail(diagnostics.FromErr(err))
+
+			return resVal
+		}
+
+		if diagnostics.HasFailed(r.TFConfig.FailOnWarnings, diags) {
+			log.Error("scenario or module violates policy")
+			notifyFail(diags)
+
+			return resVal
+		}
+		resVal.Generate.Diagnostics = append(resVal.Generate.GetDiagnostics(), diags...)
+	}
+
 	// Prepare our response
 	resVal.Generate.TerraformModule = &pb.Terraform_Module{
 		ModulePath:  gen.TerraformModulePath(),
@@ -104,6 +165,7 @@ func (r *Runner) moduleGenerate(
 	// Finalize our responses and event
 	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, resVal.Generate.GetDiagnostics()...)
 	event.Diagnostics = resVal.Generate.GetDiagnostics()
+	resVal.Generate.Elapsed = durationpb.New(time.Since(start))
 	eventVal.Generate = resVal.Generate
 
 	// Notify that we've finished
@@ -116,6 +178,73 @@ func (r *Runner) moduleGenerate(
 	return resVal
 }
 
+// dryRunFiles returns the files that the dry run of the generator would have written, sorted by
+// path. A file has changed if its contents differ from the file that currently exists. With diff,
+// changed files include a unified diff against the existing file.
+func dryRunFiles(gen *generate.Generator, diff bool) ([]*pb.Operation_Response_Generate_File, error) {
+	paths := make([]string, 0, len(gen.Files))
+	for path := range gen.Files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	files := []*pb.Operation_Response_Generate_File{}
+	for _, path := range paths {
+		content := gen.Files[path]
+		existing, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading existing generated file: %w", err)
+		}
+
+		file := &pb.Operation_Response_Generate_File{
+			Path:    path,
+			Changed: err != nil || !bytes.Equal(existing, content),
+		}
+		if content == nil && err != nil {
+			// The file would be removed but it doesn't exist
+			continue
+		}
+
+		if diff && file.GetChanged() {
+			edits := myers.ComputeEdits(span.URIFromPath(path), string(existing), string(content))
+			file.Diff = strings.TrimSuffix(
+				fmt.Sprint(gotextdiff.ToUnified(path, path, string(existing), edits)), "\n",
+			)
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// evaluateModulePolicies evaluates the Rego policies of the policy against the scenario and the
+// generated module.
+func evaluateModulePolicies(
+	ctx context.Context,
+	pol *pb.Policy,
+	scenario *flightplan.Scenario,
+	modulePath string,
+) ([]*pb.Diagnostic, error) {
+	rego := policy.NewRego(policy.WithRegoPolicy(pol))
+	diags, err := rego.Evaluate(ctx, policy.InputScenario, policy.ScenarioInput(scenario))
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := policy.ModuleInput(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	moreDiags, err := rego.Evaluate(ctx, policy.InputModule, mod)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(diags, moreDiags...), nil
+}
+
 func scenarioAndModuleGeneratorForReq(ctx context.Context, req *pb.Operation_Request) (
 	*generate.Generator,
 	*flightplan.Scenario,
@@ -133,6 +262,7 @@ func scenarioAndModuleGeneratorForReq(ctx context.Context, req *pb.Operation_Req
 		req.GetWorkspace().GetFlightplan(),
 		flightplan.DecodeTargetAll,
 		filter.Proto(),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
 	)
 	if scenarioDecoder != nil {
 		moreDiags := scenarioDecoder.DecodeAll(ctx, fp)
@@ -168,22 +298,45 @@ func scenarioAndModuleGeneratorForReq(ctx context.Context, req *pb.Operation_Req
 	}
 
 	// Determine our output directory
-	outDir := ws.GetOutDir()
-	if outDir == "" {
-		outDir = outDirForWorkspace(req.GetWorkspace())
-	}
-
-	outDir, err = isAbs(outDir)
+	outDir, err := outDirForReq(req)
 	if err != nil {
 		return nil, nil, diagnostics.FromErr(err)
 	}
 
+	// Set the outputs of any fixtures that have been launched
+	fixtureOutputs := map[string][]byte{}
+	for _, fixture := range req.GetFixtures() {
+		if len(fixture.GetOutputs()) > 0 {
+			fixtureOutputs[fixture.GetName()] = fixture.GetOutputs()
+		}
+	}
+
+	// Set the outputs of any scenarios that the scenario consumes
+	producerOutputs := map[string][]byte{}
+	for _, producer := range req.GetProducers() {
+		if len(producer.GetOutputs()) > 0 {
+			producerOutputs[producer.GetScenario().GetId().GetName()] = producer.GetOutputs()
+		}
+	}
+
 	// Generate the module
-	gen, err := generate.NewGenerator(
+	opts := []generate.Opt{
 		generate.WithScenario(scenarios[0]),
 		generate.WithScenarioBaseDirectory(baseDir),
 		generate.WithOutBaseDirectory(outDir),
-	)
+		generate.WithFixtureOutputs(fixtureOutputs),
+		generate.WithProducerOutputs(producerOutputs),
+		generate.WithTags(ws.GetTags()),
+		generate.WithRunID(req.GetRunId()),
+		generate.WithLayout(ws.GetLayout()),
+		generate.WithLockFile(ws.GetLock().GetFile()),
+		generate.WithFlightPlanHash(flightplan.Hash(ws.GetFlightplan())),
+	}
+	if req.GetGenerate().GetDryRun() {
+		opts = append(opts, generate.WithDryRun())
+	}
+
+	gen, err := generate.NewGenerator(opts...)
 	if err != nil {
 		return nil, nil, diagnostics.FromErr(err)
 	}
@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+// LockScenario takes an operation request for lock and returns a worker function that generates
+// and initializes a scenario Terraform module and locks its providers for all platforms.
+func LockScenario(req *pb.Operation_Request) WorkFunc {
+	return func(
+		ctx context.Context,
+		eventC chan *pb.Operation_Event,
+		log hclog.Logger,
+	) *pb.Operation_Response {
+		log = log.With(RequestDebugArgs(req)...)
+		events := NewEventSender(eventC)
+
+		runner := NewRunner(
+			WithRunnerTerraformConfig(req.GetWorkspace().GetTfExecCfg()),
+			WithLogger(log),
+		)
+
+		// Create our new response from our request.
+		res, err := NewResponseFromRequest(req)
+		if err != nil {
+			log.Debug("failed to create response")
+			if err := events.PublishResponse(res); err != nil {
+				res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+				log.Error("failed to send event", "error", err)
+			}
+
+			return res
+		}
+
+		// Create our response value
+		resVal := &pb.Operation_Response_Lock_{
+			Lock: &pb.Operation_Response_Lock{},
+		}
+		res.Value = resVal
+
+		// Generate our module
+		genVal := runner.moduleGenerate(ctx, req, events).Generate
+		resVal.Lock.Generate = genVal
+
+		// Determine our status
+		res.Status = diagnostics.Status(runner.TFConfig.FailOnWarnings, genVal.GetDiagnostics()...)
+
+		// Return early if we failed to generate our module
+		if hasFailedStatus(res.GetStatus()) {
+			return res
+		}
+
+		// Initialize our module, which locks the providers for the current platform
+		resVal.Lock.Init = runner.terraformInit(ctx, req, events)
+		if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, resVal.Lock.GetInit().GetDiagnostics()) {
+			res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+			return res
+		}
+
+		// Lock the providers for all platforms
+		resVal.Lock.ProvidersLock = runner.terraformProvidersLock(ctx, req, events)
+
+		// Determine our final status from all operations
+		res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+		return res
+	}
+}
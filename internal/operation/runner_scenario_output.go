@@ -7,8 +7,10 @@ import (
 	"context"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/stepresult"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	"github.com/hashicorp/go-hclog"
+	tfjson "github.com/hashicorp/terraform-json"
 )
 
 // func OutputScenario takes an operation request for generate and returns a worker
@@ -65,9 +67,34 @@ func OutputScenario(req *pb.Operation_Request) WorkFunc {
 		// exist
 		resVal.Output.Output = runner.terraformOutput(ctx, req, events)
 
+		// Show the results of the exec resources of the steps unless a single output was requested.
+		if runner.TFConfig.OutputName == "" {
+			resVal.Output.StepResults, diags = runner.stepResults(ctx, req, events)
+			resVal.Output.Diagnostics = append(resVal.Output.GetDiagnostics(), diags...)
+		}
+
 		// Determine our final status from all operations
 		res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
 
 		return res
 	}
 }
+
+// stepResults returns the results of the exec resources of the steps in the state of the module.
+func (r *Runner) stepResults(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	events *EventSender,
+) ([]*pb.Terraform_StepResult, []*pb.Diagnostic) {
+	show := r.terraformShow(ctx, req, events)
+	if diagnostics.HasFailed(r.TFConfig.FailOnWarnings, show.GetDiagnostics()) {
+		return nil, show.GetDiagnostics()
+	}
+
+	state := &tfjson.State{}
+	if err := state.UnmarshalJSON(show.GetState()); err != nil {
+		return nil, diagnostics.FromErr(err)
+	}
+
+	return stepresult.FromState(state), show.GetDiagnostics()
+}
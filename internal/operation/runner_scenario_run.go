@@ -69,6 +69,7 @@ func RunScenario(req *pb.Operation_Request) WorkFunc {
 		resVal.Run.Validate = run.GetValidate()
 		resVal.Run.Plan = run.GetPlan()
 		resVal.Run.Apply = run.GetApply()
+		resVal.Run.PriorStateShow = run.GetPriorStateShow()
 		resVal.Run.Destroy = run.GetDestroy()
 
 		// Determine our final status from all operations
@@ -0,0 +1,197 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/generate"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+// BackupScenarioState takes an operation request for a state backup and returns a worker function
+// that pulls the state of an existing scenario Terraform module and writes it, along with the
+// variables files of the module, into a timestamped snapshot.
+func BackupScenarioState(req *pb.Operation_Request) WorkFunc {
+	return func(
+		ctx context.Context,
+		eventC chan *pb.Operation_Event,
+		log hclog.Logger,
+	) *pb.Operation_Response {
+		log = log.With(RequestDebugArgs(req)...)
+		events := NewEventSender(eventC)
+
+		resVal := &pb.Operation_Response_StateBackup_{
+			StateBackup: &pb.Operation_Response_StateBackup{},
+		}
+
+		// Create our new response from our request
+		res, err := NewResponseFromRequest(req)
+		if err != nil {
+			log.Debug("failed to create response")
+			if err = events.PublishResponse(res); err != nil {
+				log.Error("failed to send event", "error", err)
+			}
+
+			return res
+		}
+		res.Value = resVal
+
+		runner := NewRunner(
+			WithRunnerTerraformConfig(req.GetWorkspace().GetTfExecCfg()),
+			WithLogger(log),
+		)
+
+		// Configure the runner with the existing Terraform module. If it doesn't
+		// exist there's no state to back up.
+		mod, diags := moduleForReq(ctx, req)
+		resVal.StateBackup.Diagnostics = append(resVal.StateBackup.GetDiagnostics(), diags...)
+		resVal.StateBackup.TerraformModule = mod
+		if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, resVal.StateBackup.GetDiagnostics()) {
+			log.Debug("failed to load Terraform module")
+			res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+			return res
+		}
+		runner.TFConfig.WithModule(mod)
+
+		var state string
+		resVal.StateBackup.StatePull, state = runner.terraformStatePull(ctx, req, events)
+		if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, resVal.StateBackup.GetStatePull().GetDiagnostics()) {
+			res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+			return res
+		}
+
+		if strings.TrimSpace(state) == "" {
+			resVal.StateBackup.Diagnostics = append(resVal.StateBackup.GetDiagnostics(), diagnostics.FromErr(
+				errors.New("the scenario has no state to back up, it has not been launched"),
+			)...)
+			res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+			return res
+		}
+
+		snapshot, err := generate.WriteStateSnapshot(
+			filepath.Dir(mod.GetModulePath()), generate.NewStateSnapshotID(time.Now()), []byte(state),
+		)
+		if err != nil {
+			resVal.StateBackup.Diagnostics = append(resVal.StateBackup.GetDiagnostics(), diagnostics.FromErr(err)...)
+		}
+		resVal.StateBackup.Snapshot = snapshot
+
+		// Determine our final status from all operations
+		res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+		return res
+	}
+}
+
+// RestoreScenarioState takes an operation request for a state restore and returns a worker
+// function that restores a snapshot into an existing scenario Terraform module. The current state
+// and variables files of the module are backed up into a new snapshot first so that the restore can
+// be undone. The state of the snapshot is pushed to the backend of the module before its variables
+// files are restored so that a failed push leaves the module as it was.
+func RestoreScenarioState(req *pb.Operation_Request) WorkFunc {
+	return func(
+		ctx context.Context,
+		eventC chan *pb.Operation_Event,
+		log hclog.Logger,
+	) *pb.Operation_Response {
+		log = log.With(RequestDebugArgs(req)...)
+		events := NewEventSender(eventC)
+
+		resVal := &pb.Operation_Response_StateRestore_{
+			StateRestore: &pb.Operation_Response_StateRestore{},
+		}
+
+		// Create our new response from our request
+		res, err := NewResponseFromRequest(req)
+		if err != nil {
+			log.Debug("failed to create response")
+			if err = events.PublishResponse(res); err != nil {
+				log.Error("failed to send event", "error", err)
+			}
+
+			return res
+		}
+		res.Value = resVal
+
+		runner := NewRunner(
+			WithRunnerTerraformConfig(req.GetWorkspace().GetTfExecCfg()),
+			WithLogger(log),
+		)
+
+		// Configure the runner with the existing Terraform module, which is
+		// where the snapshots are kept.
+		mod, diags := moduleForReq(ctx, req)
+		resVal.StateRestore.Diagnostics = append(resVal.StateRestore.GetDiagnostics(), diags...)
+		resVal.StateRestore.TerraformModule = mod
+		if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, resVal.StateRestore.GetDiagnostics()) {
+			log.Debug("failed to load Terraform module")
+			res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+			return res
+		}
+		runner.TFConfig.WithModule(mod)
+
+		moduleDir := filepath.Dir(mod.GetModulePath())
+		snapshot, err := generate.ReadStateSnapshot(moduleDir, req.GetStateRestore().GetSnapshot())
+		if err != nil {
+			resVal.StateRestore.Diagnostics = append(resVal.StateRestore.GetDiagnostics(), diagnostics.FromErr(err)...)
+			res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+			return res
+		}
+		resVal.StateRestore.Snapshot = snapshot
+
+		// Back up the current state before we replace it. If the module has no state there's
+		// nothing to lose.
+		var state string
+		resVal.StateRestore.StatePull, state = runner.terraformStatePull(ctx, req, events)
+		if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, resVal.StateRestore.GetStatePull().GetDiagnostics()) {
+			res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+			return res
+		}
+
+		if strings.TrimSpace(state) != "" {
+			backup, err := generate.WriteStateSnapshot(moduleDir, generate.NewStateSnapshotID(time.Now()), []byte(state))
+			if err != nil {
+				resVal.StateRestore.Diagnostics = append(resVal.StateRestore.GetDiagnostics(), diagnostics.FromErr(
+					fmt.Errorf("backing up the state before restoring: %w", err),
+				)...)
+				res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+				return res
+			}
+			resVal.StateRestore.Backup = backup
+		}
+
+		resVal.StateRestore.StatePush = runner.terraformStatePush(
+			ctx, req, events, filepath.Join(snapshot.GetPath(), generate.SnapshotStateFileName),
+		)
+		if diagnostics.HasFailed(runner.TFConfig.FailOnWarnings, resVal.StateRestore.GetStatePush().GetDiagnostics()) {
+			res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+			return res
+		}
+
+		if err := generate.RestoreStateSnapshotVars(moduleDir, snapshot); err != nil {
+			resVal.StateRestore.Diagnostics = append(resVal.StateRestore.GetDiagnostics(), diagnostics.FromErr(err)...)
+		}
+
+		// Determine our final status from all operations
+		res.Status = diagnostics.OperationStatus(runner.TFConfig.FailOnWarnings, res)
+
+		return res
+	}
+}
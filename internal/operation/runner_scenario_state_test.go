@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/generate"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// fakeStateTerraform is a Terraform binary whose backend is the terraform.tfstate file in the
+// module directory. Pushes fail if the module directory has a fail_push file.
+const fakeStateTerraform = `#!/bin/sh
+for arg; do last="$arg"; done
+case "$1 $2" in
+"state pull")
+  if [ -f terraform.tfstate ]; then cat terraform.tfstate; fi
+  ;;
+"state push")
+  if [ -f fail_push ]; then echo "failed to push" >&2; exit 1; fi
+  cp "$last" terraform.tfstate
+  ;;
+*)
+  exit 1
+  ;;
+esac
+`
+
+// testStateReq returns a state request for a scenario whose Terraform module directory exists and
+// uses a fake Terraform binary, and the module directory.
+func testStateReq(t *testing.T) (*pb.Operation_Request, string) {
+	t.Helper()
+
+	bin := filepath.Join(t.TempDir(), "terraform")
+	require.NoError(t, os.WriteFile(bin, []byte(fakeStateTerraform), 0o755))
+
+	req := &pb.Operation_Request{
+		Id:       "op",
+		Scenario: &pb.Ref_Scenario{Id: &pb.Scenario_ID{Name: "test"}},
+		Workspace: &pb.Workspace{
+			Flightplan: &pb.FlightPlan{
+				BaseDir: t.TempDir(),
+				EnosHcl: map[string][]byte{
+					"enos.hcl": []byte(`
+module "foo" {
+  source = "./foo"
+}
+
+scenario "test" {
+  step "foo" {
+    module = module.foo
+  }
+}
+`),
+				},
+			},
+			OutDir:    t.TempDir(),
+			TfExecCfg: &pb.Terraform_Runner_Config{BinPath: bin},
+		},
+	}
+
+	mod, diags := moduleForReq(context.Background(), req)
+	require.Empty(t, diags)
+	dir := filepath.Dir(mod.GetModulePath())
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	return req, dir
+}
+
+// testRunWorkFunc runs the work function and returns its response.
+func testRunWorkFunc(t *testing.T, f WorkFunc) *pb.Operation_Response {
+	t.Helper()
+
+	eventC := make(chan *pb.Operation_Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range eventC {
+		}
+	}()
+
+	res := f(context.Background(), eventC, hclog.NewNullLogger())
+	close(eventC)
+	<-done
+
+	return res
+}
+
+// Test_RestoreScenarioState tests that restoring a snapshot backs up the current state, pushes the
+// state of the snapshot, and only then restores its variables files.
+func Test_RestoreScenarioState(t *testing.T) {
+	t.Parallel()
+
+	req, dir := testStateReq(t)
+	write := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+
+		return string(b)
+	}
+
+	write("terraform.tfvars", "a = 1")
+	snapshot, err := generate.WriteStateSnapshot(
+		dir, generate.NewStateSnapshotID(time.Now().Add(-time.Hour)), []byte(`{"serial":1}`),
+	)
+	require.NoError(t, err)
+
+	write("terraform.tfstate", `{"serial":2}`)
+	write("terraform.tfvars", "a = 2")
+	write("extra.auto.tfvars.json", `{"b":2}`)
+
+	req.Value = &pb.Operation_Request_StateRestore_{
+		StateRestore: &pb.Operation_Request_StateRestore{Snapshot: snapshot.GetId()},
+	}
+	res := testRunWorkFunc(t, RestoreScenarioState(req))
+	require.Equal(t, pb.Operation_STATUS_COMPLETED, res.GetStatus(), res.String())
+
+	restore := res.GetStateRestore()
+	require.Equal(t, snapshot.GetId(), restore.GetSnapshot().GetId())
+	require.Equal(t, `{"serial":1}`, read("terraform.tfstate"))
+	require.Equal(t, "a = 1", read("terraform.tfvars"))
+	require.NoFileExists(t, filepath.Join(dir, "extra.auto.tfvars.json"))
+
+	// The state and variables files from before the restore are in the backup
+	backup := restore.GetBackup()
+	require.NotNil(t, backup)
+	require.NotEqual(t, snapshot.GetId(), backup.GetId())
+	require.ElementsMatch(t,
+		[]string{generate.SnapshotStateFileName, "terraform.tfvars", "extra.auto.tfvars.json"},
+		backup.GetFiles(),
+	)
+	b, err := os.ReadFile(filepath.Join(backup.GetPath(), generate.SnapshotStateFileName))
+	require.NoError(t, err)
+	require.Equal(t, `{"serial":2}`, string(b))
+	b, err = os.ReadFile(filepath.Join(backup.GetPath(), "terraform.tfvars"))
+	require.NoError(t, err)
+	require.Equal(t, "a = 2", string(b))
+}
+
+// Test_RestoreScenarioState_PushFailed tests that the variables files are left alone when pushing
+// the state of the snapshot fails.
+func Test_RestoreScenarioState_PushFailed(t *testing.T) {
+	t.Parallel()
+
+	req, dir := testStateReq(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfvars"), []byte("a = 1"), 0o600))
+	snapshot, err := generate.WriteStateSnapshot(
+		dir, generate.NewStateSnapshotID(time.Now().Add(-time.Hour)), []byte(`{"serial":1}`),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfstate"), []byte(`{"serial":2}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfvars"), []byte("a = 2"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fail_push"), nil, 0o600))
+
+	req.Value = &pb.Operation_Request_StateRestore_{
+		StateRestore: &pb.Operation_Request_StateRestore{Snapshot: snapshot.GetId()},
+	}
+	res := testRunWorkFunc(t, RestoreScenarioState(req))
+	require.Equal(t, pb.Operation_STATUS_FAILED, res.GetStatus())
+	require.NotEmpty(t, res.GetStateRestore().GetStatePush().GetDiagnostics())
+	require.NotNil(t, res.GetStateRestore().GetBackup())
+
+	b, err := os.ReadFile(filepath.Join(dir, "terraform.tfstate"))
+	require.NoError(t, err)
+	require.Equal(t, `{"serial":2}`, string(b))
+	b, err = os.ReadFile(filepath.Join(dir, "terraform.tfvars"))
+	require.NoError(t, err)
+	require.Equal(t, "a = 2", string(b))
+}
+
+// Test_BackupScenarioState tests backing up the state of a scenario into a snapshot.
+func Test_BackupScenarioState(t *testing.T) {
+	t.Parallel()
+
+	req, dir := testStateReq(t)
+	req.Value = &pb.Operation_Request_StateBackup_{StateBackup: &pb.Operation_Request_StateBackup{}}
+
+	// Without state there's nothing to back up
+	res := testRunWorkFunc(t, BackupScenarioState(req))
+	require.Equal(t, pb.Operation_STATUS_FAILED, res.GetStatus())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfstate"), []byte(`{"serial":3}`), 0o600))
+	res = testRunWorkFunc(t, BackupScenarioState(req))
+	require.Equal(t, pb.Operation_STATUS_COMPLETED, res.GetStatus(), res.String())
+
+	snapshot, err := generate.ReadStateSnapshot(dir, res.GetStateBackup().GetSnapshot().GetId())
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(snapshot.GetPath(), generate.SnapshotStateFileName))
+	require.NoError(t, err)
+	require.Equal(t, `{"serial":3}`, string(b))
+}
@@ -5,8 +5,13 @@ package operation
 
 import (
 	"context"
+	"io"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/stepresult"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -20,6 +25,7 @@ func (r *Runner) terraformApply(
 		Diagnostics: []*pb.Diagnostic{},
 	}
 	log := r.log.With(RequestDebugArgs(req)...)
+	start := time.Now()
 
 	ref, err := NewReferenceFromRequest(req)
 	if err != nil {
@@ -44,6 +50,7 @@ func (r *Runner) terraformApply(
 		event.Status = pb.Operation_STATUS_FAILED
 		res.Diagnostics = append(res.GetDiagnostics(), diags...)
 		event.Diagnostics = append(event.GetDiagnostics(), res.GetDiagnostics()...)
+		res.Elapsed = durationpb.New(time.Since(start))
 		eventVal.Apply = res
 
 		if err := events.Publish(event); err != nil {
@@ -61,12 +68,17 @@ func (r *Runner) terraformApply(
 	}
 
 	// terraform apply
-	applyOut := NewTextOutput()
-	tf.SetStdout(applyOut.Stdout)
-	tf.SetStderr(applyOut.Stderr)
+	applyOut := r.newTextOutput()
+	progress := r.newStepProgress("apply", ref, events)
+	tf.SetStdout(io.MultiWriter(applyOut.Stdout, progress))
+	tf.SetStderr(io.MultiWriter(applyOut.Stderr, progress.Stderr()))
 	err = tf.Apply(ctx, r.TFConfig.ApplyOptions()...)
+	progress.Finish()
+	res.Steps = progress.Steps()
 	res.Stderr = applyOut.Stderr.String()
+	r.logTextOutput("apply", applyOut)
 	if err != nil {
+		res.StepResults = stepresult.FromApplyStderr(res.GetStderr())
 		notifyFail(diagnostics.FromErr(err))
 
 		return res
@@ -75,6 +87,7 @@ func (r *Runner) terraformApply(
 	// Finalize our responses and event
 	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
 	event.Diagnostics = res.GetDiagnostics()
+	res.Elapsed = durationpb.New(time.Since(start))
 	eventVal.Apply = res
 
 	// Notify that we've finished
@@ -5,6 +5,10 @@ package operation
 
 import (
 	"context"
+	"io"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
@@ -22,6 +26,7 @@ func (r *Runner) terraformDestroy(
 		Diagnostics: []*pb.Diagnostic{},
 	}
 	log := r.log.With(RequestDebugArgs(req)...)
+	start := time.Now()
 
 	ref, err := NewReferenceFromRequest(req)
 	if err != nil {
@@ -46,6 +51,7 @@ func (r *Runner) terraformDestroy(
 		event.Status = pb.Operation_STATUS_FAILED
 		res.Diagnostics = append(res.GetDiagnostics(), diags...)
 		event.Diagnostics = append(event.GetDiagnostics(), res.GetDiagnostics()...)
+		res.Elapsed = durationpb.New(time.Since(start))
 		eventVal.Destroy = res
 
 		if err := events.Publish(event); err != nil {
@@ -63,6 +69,7 @@ func (r *Runner) terraformDestroy(
 		// Finalize our event
 		event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
 		event.Diagnostics = res.GetDiagnostics()
+		res.Elapsed = durationpb.New(time.Since(start))
 		eventVal.Destroy = res
 
 		// Notify that we've finished
@@ -83,11 +90,14 @@ func (r *Runner) terraformDestroy(
 		return res
 	}
 
-	destroyOut := NewTextOutput()
-	tf.SetStdout(destroyOut.Stdout)
-	tf.SetStderr(destroyOut.Stderr)
+	destroyOut := r.newTextOutput()
+	progress := r.newStepProgress("destroy", ref, events)
+	tf.SetStdout(io.MultiWriter(destroyOut.Stdout, progress))
+	tf.SetStderr(io.MultiWriter(destroyOut.Stderr, progress.Stderr()))
 	err = tf.Destroy(ctx, r.TFConfig.DestroyOptions()...)
+	progress.Finish()
 	res.Stderr = destroyOut.Stderr.String()
+	r.logTextOutput("destroy", destroyOut)
 	if err != nil {
 		notifyFail(diagnostics.FromErr(err))
 
@@ -97,6 +107,7 @@ func (r *Runner) terraformDestroy(
 	// Finalize our responses and event
 	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
 	event.Diagnostics = res.GetDiagnostics()
+	res.Elapsed = durationpb.New(time.Since(start))
 	eventVal.Destroy = res
 
 	// Notify that we've finished
@@ -5,10 +5,14 @@ package operation
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/policy"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -55,20 +59,59 @@ func (r *Runner) terraformExec(
 		}
 	}
 
+	// Make sure that the policy allows the sub-command before we run anything, e.g. so that shared
+	// CI credentials can't be used to apply or destroy scenarios with exec.
+	if diags := policy.EvaluateExec(req.GetWorkspace().GetPolicy(), r.TFConfig.ExecSubCmd); len(diags) > 0 {
+		res.SubCommand = r.TFConfig.ExecSubCmd
+		notifyFail(diags)
+		log.Error("exec violates policy", "sub_command", r.TFConfig.ExecSubCmd)
+
+		return res
+	}
+
+	// Capture the output of the sub-command into files in the out directory, e.g. so that CI can
+	// attach them as artifacts.
+	capture, err := newExecCapture(req, r.TFConfig.ExecSubCmd, time.Now())
+	if err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), execCaptureWarning(err))
+		log.Warn("failed to capture exec output", "error", err)
+	}
+
 	// terraform exec
 	execOut := NewTextOutput()
 	stdout := &strings.Builder{}
 	execOut.Stdout = stdout
 	cmd := r.TFConfig.NewExecSubCmd()
-	cmd.ExecOpts = append(cmd.ExecOpts, func(ecmd *exec.Cmd) {
-		ecmd.Stderr = execOut.Stderr
-		ecmd.Stdout = execOut.Stdout
-	})
+	// Set the niceness of the operation on the sub-command itself so that it doesn't depend on
+	// which thread starts it.
+	cmd.Niceness = req.GetWorkspace().GetLimits().GetNiceness()
+	if req.GetExec().GetTty() {
+		// Interactive sub-commands are executed in the terminal that the client has attached to,
+		// which has all of their output, so we only capture it.
+		term := terminalFromContext(ctx)
+		if term == nil {
+			res.SubCommand = r.TFConfig.ExecSubCmd
+			notifyFail(diagnostics.FromErr(errors.New("the operator is unable to execute sub-commands with a TTY")))
+
+			return res
+		}
 
-	_, err = cmd.Run(ctx)
+		out, _ := capture.writers(io.Discard, io.Discard)
+		err = term.run(ctx, cmd, out)
+	} else {
+		cmd.ExecOpts = append(cmd.ExecOpts, func(ecmd *exec.Cmd) {
+			ecmd.Stdout, ecmd.Stderr = capture.writers(execOut.Stdout, execOut.Stderr)
+		})
+
+		_, err = cmd.Run(ctx)
+	}
 	res.SubCommand = r.TFConfig.ExecSubCmd
 	res.Stdout = stdout.String()
 	res.Stderr = execOut.Stderr.String()
+	r.logTextOutput("exec", execOut)
+	if closeErr := capture.close(res); closeErr != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), execCaptureWarning(closeErr))
+	}
 	if err != nil {
 		notifyFail(diagnostics.FromErr(err))
 
@@ -5,8 +5,14 @@ package operation
 
 import (
 	"context"
+	"io"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/generate"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -20,6 +26,7 @@ func (r *Runner) terraformInit(
 		Diagnostics: []*pb.Diagnostic{},
 	}
 	log := r.log.With(RequestDebugArgs(req)...)
+	start := time.Now()
 
 	ref, err := NewReferenceFromRequest(req)
 	if err != nil {
@@ -44,6 +51,7 @@ func (r *Runner) terraformInit(
 		event.Status = pb.Operation_STATUS_FAILED
 		res.Diagnostics = append(res.GetDiagnostics(), diags...)
 		event.Diagnostics = append(event.GetDiagnostics(), res.GetDiagnostics()...)
+		res.Elapsed = durationpb.New(time.Since(start))
 		eventVal.Init = res
 
 		if err := events.Publish(event); err != nil {
@@ -60,21 +68,50 @@ func (r *Runner) terraformInit(
 		return res
 	}
 
+	// Reuse the providers and modules of a module that has already been initialized
+	reuse := r.newTerraformDirReuse(req)
+	res.Reused = reuse.reuse()
+
 	// terraform init
-	initOut := NewTextOutput()
-	tf.SetStdout(initOut.Stdout)
-	tf.SetStderr(initOut.Stderr)
+	initOut := r.newTextOutput()
+	progress := r.newStepProgress("init", ref, events)
+	tf.SetStdout(io.MultiWriter(initOut.Stdout, progress))
+	tf.SetStderr(io.MultiWriter(initOut.Stderr, progress.Stderr()))
 	err = tf.Init(ctx, r.TFConfig.InitOptions()...)
+	progress.Finish()
 	res.Stderr = initOut.Stderr.String()
+	r.logTextOutput("init", initOut)
 	if err != nil {
-		notifyFail(diagnostics.FromErr(err))
+		notifyFail(append(reuse.diags, diagnostics.FromErr(err)...))
 
 		return res
 	}
 
+	// Keep the providers and modules for other modules to reuse
+	if !res.GetReused() {
+		reuse.warm()
+	}
+	res.Diagnostics = append(res.GetDiagnostics(), reuse.diags...)
+
+	// Warn if init has locked providers for fewer platforms than the workspace requires, e.g. when a
+	// provider has been added to a module and is only locked for the current platform. Lock
+	// operations lock them for all platforms after init so there's no need to warn.
+	platforms := req.GetWorkspace().GetLock().GetPlatforms()
+	if _, isLock := req.GetValue().(*pb.Operation_Request_Lock_); len(platforms) > 0 && !isLock {
+		lockFile := filepath.Join(r.TFConfig.DirPath, generate.LockFileName)
+		if err := generate.VerifyLockFile(lockFile, platforms); err != nil {
+			res.Diagnostics = append(res.GetDiagnostics(), &pb.Diagnostic{
+				Severity: pb.Diagnostic_SEVERITY_WARNING,
+				Summary:  "providers are not locked for all platforms",
+				Detail:   err.Error() + "\nUse 'enos scenario lock' to lock them for all platforms.",
+			})
+		}
+	}
+
 	// Finalize our responses and event
 	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
 	event.Diagnostics = res.GetDiagnostics()
+	res.Elapsed = durationpb.New(time.Since(start))
 	eventVal.Init = res
 
 	// Notify that we've finished
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/operation/terraform/format"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -113,6 +114,18 @@ func (r *Runner) terraformOutput(
 		}
 	}
 
+	// Preserve the types of the outputs so that the JSON output has them as structured values
+	// rather than encoded bytes. Sensitive values never leave the runner unless they have been
+	// explicitly requested.
+	for _, meta := range res.GetMeta() {
+		if !req.GetOutput().GetShowSensitive() {
+			format.RedactTerraformOutput(meta)
+		}
+		if err := format.StructureTerraformOutput(meta); err != nil {
+			res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+		}
+	}
+
 	// Finalize our responses and event
 	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
 	event.Diagnostics = res.GetDiagnostics()
@@ -5,8 +5,20 @@ package operation
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hashicorp/enos/internal/cost"
 	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/operation/terraform"
+	"github.com/hashicorp/enos/internal/policy"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
@@ -20,6 +32,7 @@ func (r *Runner) terraformPlan(
 		Diagnostics: []*pb.Diagnostic{},
 	}
 	log := r.log.With(RequestDebugArgs(req)...)
+	start := time.Now()
 
 	ref, err := NewReferenceFromRequest(req)
 	if err != nil {
@@ -44,6 +57,7 @@ func (r *Runner) terraformPlan(
 		event.Status = pb.Operation_STATUS_FAILED
 		res.Diagnostics = append(res.GetDiagnostics(), diags...)
 		event.Diagnostics = append(event.GetDiagnostics(), res.GetDiagnostics()...)
+		res.Elapsed = durationpb.New(time.Since(start))
 		eventVal.Plan = res
 
 		if err := events.Publish(event); err != nil {
@@ -61,21 +75,62 @@ func (r *Runner) terraformPlan(
 	}
 
 	// terraform plan
-	planOut := NewTextOutput()
-	tf.SetStdout(planOut.Stdout)
-	tf.SetStderr(planOut.Stderr)
-	changes, err := tf.Plan(ctx, r.TFConfig.PlanOptions()...)
+	planOut := r.newTextOutput()
+	progress := r.newStepProgress("plan", ref, events)
+	tf.SetStdout(io.MultiWriter(planOut.Stdout, progress))
+	tf.SetStderr(io.MultiWriter(planOut.Stderr, progress.Stderr()))
+	pol := req.GetWorkspace().GetPolicy()
+	estimate := r.TFConfig.Cost.GetEnabled() || policy.NeedsCost(pol)
+	savePlan := estimate || policy.Enabled(pol) || policy.HasRego(pol)
+	opts := r.TFConfig.PlanOptions()
+	if savePlan {
+		opts = append(opts, tfexec.Out(terraform.PlanFileName))
+	}
+	changes, err := tf.Plan(ctx, opts...)
+	progress.Finish()
 	res.ChangesPresent = changes
 	res.Stderr = planOut.Stderr.String()
+	r.logTextOutput("plan", planOut)
 	if err != nil {
 		notifyFail(diagnostics.FromErr(err))
 
 		return res
 	}
 
+	if savePlan {
+		// Estimate the cost of the plan and enforce the policy before anything is applied
+		plan, err := r.planReview(ctx, tf, estimate)
+		if err != nil {
+			notifyFail(diagnostics.FromErr(err))
+
+			return res
+		}
+		res.Cost = plan.cost
+
+		diags := policy.Evaluate(pol, plan.plan, plan.cost)
+		if policy.HasRego(pol) {
+			regoDiags, err := policy.NewRego(policy.WithRegoPolicy(pol)).Evaluate(ctx, policy.InputPlan, plan.plan)
+			if err != nil {
+				notifyFail(diagnostics.FromErr(err))
+
+				return res
+			}
+			diags = append(diags, regoDiags...)
+		}
+
+		if diagnostics.HasFailed(r.TFConfig.FailOnWarnings, diags) {
+			log.Error("plan violates policy")
+			notifyFail(diags)
+
+			return res
+		}
+		res.Diagnostics = append(res.GetDiagnostics(), diags...)
+	}
+
 	// Finalize our responses and event
 	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
 	event.Diagnostics = res.GetDiagnostics()
+	res.Elapsed = durationpb.New(time.Since(start))
 	eventVal.Plan = res
 
 	// Notify that we've finished
@@ -87,3 +142,50 @@ func (r *Runner) terraformPlan(
 
 	return res
 }
+
+// reviewedPlan is a saved plan and its estimated cost.
+type reviewedPlan struct {
+	plan *tfjson.Plan
+	cost *pb.Terraform_CostEstimate
+}
+
+// planReview shows the saved plan and estimates its cost if requested. The plan and its JSON are
+// removed afterwards as they might have sensitive values.
+func (r *Runner) planReview(ctx context.Context, tf *tfexec.Terraform, estimate bool) (*reviewedPlan, error) {
+	planPath := filepath.Join(r.TFConfig.DirPath, terraform.PlanFileName)
+	jsonPath := planPath + ".json"
+	defer func() {
+		_ = os.Remove(planPath)
+		_ = os.Remove(jsonPath)
+	}()
+
+	var err error
+	res := &reviewedPlan{}
+	res.plan, err = tf.ShowPlanFile(ctx, terraform.PlanFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !estimate {
+		return res, nil
+	}
+
+	b, err := json.Marshal(res.plan)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.WriteFile(jsonPath, b, 0o600); err != nil {
+		return nil, err
+	}
+
+	res.cost, err = cost.NewEstimator(
+		cost.WithEstimatorBinary(r.TFConfig.Cost.GetBinPath()),
+		cost.WithEstimatorEnv(r.TFConfig.Env),
+	).Estimate(ctx, jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/generate"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// terraformProvidersLock locks the providers of a Terraform module for the platforms of the
+// workspace and verifies that the lock file has hashes for all of them. Without platforms the
+// providers are locked for the current platform.
+func (r *Runner) terraformProvidersLock(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	events *EventSender,
+) *pb.Terraform_Command_ProvidersLock_Response {
+	res := &pb.Terraform_Command_ProvidersLock_Response{
+		Diagnostics: []*pb.Diagnostic{},
+		Platforms:   req.GetWorkspace().GetLock().GetPlatforms(),
+	}
+	log := r.log.With(RequestDebugArgs(req)...)
+	start := time.Now()
+
+	ref, err := NewReferenceFromRequest(req)
+	if err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+		r.log.Error("failed to create reference from request", "error", err)
+
+		return res
+	}
+
+	// Notify running providers lock
+	eventVal := &pb.Operation_Event_ProvidersLock{}
+	event := newEvent(ref, pb.Operation_STATUS_RUNNING)
+	event.Value = eventVal
+	if err = events.Publish(event); err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+		log.Error("failed to publish event", "error", err)
+	}
+
+	// notifyFail prepares the response for failure and sends a failure
+	// event
+	notifyFail := func(diags []*pb.Diagnostic) {
+		event.Status = pb.Operation_STATUS_FAILED
+		res.Diagnostics = append(res.GetDiagnostics(), diags...)
+		event.Diagnostics = append(event.GetDiagnostics(), res.GetDiagnostics()...)
+		res.Elapsed = durationpb.New(time.Since(start))
+		eventVal.ProvidersLock = res
+
+		if err := events.Publish(event); err != nil {
+			res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+			log.Error("failed to publish event", "error", err)
+		}
+	}
+
+	// Create our terraform executor
+	tf, err := r.TFConfig.Terraform()
+	if err != nil {
+		notifyFail(diagnostics.FromErr(err))
+
+		return res
+	}
+
+	opts := []tfexec.ProvidersLockOption{}
+	for _, platform := range res.GetPlatforms() {
+		opts = append(opts, tfexec.Platform(platform))
+	}
+
+	// terraform providers lock
+	lockOut := r.newTextOutput()
+	tf.SetStdout(lockOut.Stdout)
+	tf.SetStderr(lockOut.Stderr)
+	err = tf.ProvidersLock(ctx, opts...)
+	res.Stderr = lockOut.Stderr.String()
+	r.logTextOutput("providers lock", lockOut)
+	if err != nil {
+		notifyFail(diagnostics.FromErr(err))
+
+		return res
+	}
+
+	// Make sure that Terraform has actually locked every provider for all platforms
+	err = generate.VerifyLockFile(filepath.Join(r.TFConfig.DirPath, generate.LockFileName), res.GetPlatforms())
+	if err != nil {
+		notifyFail(diagnostics.FromErr(err))
+
+		return res
+	}
+
+	// Finalize our responses and event
+	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
+	event.Diagnostics = res.GetDiagnostics()
+	res.Elapsed = durationpb.New(time.Since(start))
+	eventVal.ProvidersLock = res
+
+	// Notify that we've finished
+	if err := events.Publish(event); err != nil {
+		log.Error("failed to send event", "error", err)
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+	}
+	log.Debug("finished providers lock")
+
+	return res
+}
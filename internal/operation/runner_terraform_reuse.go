@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"path/filepath"
+
+	"github.com/hashicorp/enos/internal/generate"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// terraformDirReuse reuses the .terraform directories of modules that have already been
+// initialized with the same lock file and module calls. Failing to reuse them only means that init
+// has to install the providers and modules itself, so failures are warnings.
+type terraformDirReuse struct {
+	mode      pb.Terraform_Runner_Config_Reuse_Mode
+	cacheDir  string
+	moduleDir string
+	diags     []*pb.Diagnostic
+}
+
+// newTerraformDirReuse returns the reuse of the .terraform directory of the module of the runner.
+// Symlinks to the cache don't resolve in containers or on targets so it's copied instead.
+func (r *Runner) newTerraformDirReuse(req *pb.Operation_Request) *terraformDirReuse {
+	reuse := &terraformDirReuse{
+		mode:      r.TFConfig.Reuse.GetMode(),
+		moduleDir: r.TFConfig.DirPath,
+	}
+
+	if reuse.mode == pb.Terraform_Runner_Config_Reuse_MODE_SYMLINK &&
+		(r.TFConfig.Container != nil || r.TFConfig.Target != nil) {
+		reuse.mode = pb.Terraform_Runner_Config_Reuse_MODE_COPY
+	}
+
+	if reuse.mode == pb.Terraform_Runner_Config_Reuse_MODE_UNSPECIFIED {
+		return reuse
+	}
+
+	outDir, err := outDirForReq(req)
+	if err != nil {
+		reuse.warn(err)
+		reuse.mode = pb.Terraform_Runner_Config_Reuse_MODE_UNSPECIFIED
+
+		return reuse
+	}
+	reuse.cacheDir = filepath.Join(outDir, generate.ReuseDirName)
+
+	return reuse
+}
+
+// reuse reuses the providers and modules of the cache before the module is initialized. It returns
+// whether anything has been reused.
+func (t *terraformDirReuse) reuse() bool {
+	if t.mode == pb.Terraform_Runner_Config_Reuse_MODE_UNSPECIFIED {
+		return false
+	}
+
+	key, err := generate.ReuseKey(t.moduleDir)
+	if err != nil {
+		t.warn(err)
+
+		return false
+	}
+
+	reused, err := generate.ReuseTerraformDir(t.cacheDir, key, t.moduleDir, t.mode)
+	if err != nil {
+		t.warn(err)
+	}
+
+	return reused
+}
+
+// warm keeps the providers and modules of the module in the cache after it has been initialized.
+// The key is determined again as init might have created the lock file.
+func (t *terraformDirReuse) warm() {
+	if t.mode == pb.Terraform_Runner_Config_Reuse_MODE_UNSPECIFIED {
+		return
+	}
+
+	key, err := generate.ReuseKey(t.moduleDir)
+	if err != nil {
+		t.warn(err)
+
+		return
+	}
+
+	if err := generate.WarmTerraformDir(t.cacheDir, key, t.moduleDir); err != nil {
+		t.warn(err)
+	}
+}
+
+func (t *terraformDirReuse) warn(err error) {
+	t.diags = append(t.diags, &pb.Diagnostic{
+		Severity: pb.Diagnostic_SEVERITY_WARNING,
+		Summary:  "unable to reuse the .terraform directory",
+		Detail:   err.Error(),
+	})
+}
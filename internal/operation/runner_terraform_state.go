@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// terraformStatePull pulls the state of the Terraform module from its backend and returns it.
+func (r *Runner) terraformStatePull(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	events *EventSender,
+) (*pb.Terraform_Command_StatePull_Response, string) {
+	res := &pb.Terraform_Command_StatePull_Response{
+		Diagnostics: []*pb.Diagnostic{},
+	}
+	log := r.log.With(RequestDebugArgs(req)...)
+	start := time.Now()
+
+	ref, err := NewReferenceFromRequest(req)
+	if err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+		log.Error("failed to create reference from request", "error", err)
+
+		return res, ""
+	}
+
+	// Notify running state pull
+	eventVal := &pb.Operation_Event_StatePull{}
+	event := newEvent(ref, pb.Operation_STATUS_RUNNING)
+	event.Value = eventVal
+	if err = events.Publish(event); err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+		log.Error("failed to publish event", "error", err)
+	}
+
+	// notifyFail prepares the response for failure and sends a failure
+	// event
+	notifyFail := func(diags []*pb.Diagnostic) {
+		event.Status = pb.Operation_STATUS_FAILED
+		res.Diagnostics = append(res.GetDiagnostics(), diags...)
+		event.Diagnostics = append(event.GetDiagnostics(), res.GetDiagnostics()...)
+		res.Elapsed = durationpb.New(time.Since(start))
+		eventVal.StatePull = res
+
+		if err := events.Publish(event); err != nil {
+			res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+			log.Error("failed to publish event", "error", err)
+		}
+	}
+
+	// Create our terraform executor
+	tf, err := r.TFConfig.Terraform()
+	if err != nil {
+		notifyFail(diagnostics.FromErr(err))
+
+		return res, ""
+	}
+
+	// terraform state pull. The state is the stdout of the command so we only capture stderr.
+	pullOut := r.newTextOutput()
+	tf.SetStderr(pullOut.Stderr)
+	state, err := tf.StatePull(ctx)
+	res.Stderr = pullOut.Stderr.String()
+	r.logTextOutput("state pull", pullOut)
+	if err != nil {
+		notifyFail(diagnostics.FromErr(err))
+
+		return res, ""
+	}
+
+	// Finalize our responses and event
+	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
+	event.Diagnostics = res.GetDiagnostics()
+	res.Elapsed = durationpb.New(time.Since(start))
+	eventVal.StatePull = res
+
+	// Notify that we've finished
+	if err := events.Publish(event); err != nil {
+		log.Error("failed to send event", "error", err)
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+	}
+	log.Debug("finished state pull")
+
+	return res, state
+}
+
+// terraformStatePush pushes the state file at the path to the backend of the Terraform module. As
+// we restore snapshots of prior states the push is forced, otherwise Terraform would refuse to
+// replace a state with a newer serial.
+func (r *Runner) terraformStatePush(
+	ctx context.Context,
+	req *pb.Operation_Request,
+	events *EventSender,
+	path string,
+) *pb.Terraform_Command_StatePush_Response {
+	res := &pb.Terraform_Command_StatePush_Response{
+		Diagnostics: []*pb.Diagnostic{},
+	}
+	log := r.log.With(RequestDebugArgs(req)...)
+	start := time.Now()
+
+	ref, err := NewReferenceFromRequest(req)
+	if err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+		log.Error("failed to create reference from request", "error", err)
+
+		return res
+	}
+
+	// Notify running state push
+	eventVal := &pb.Operation_Event_StatePush{}
+	event := newEvent(ref, pb.Operation_STATUS_RUNNING)
+	event.Value = eventVal
+	if err = events.Publish(event); err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+		log.Error("failed to publish event", "error", err)
+	}
+
+	// notifyFail prepares the response for failure and sends a failure
+	// event
+	notifyFail := func(diags []*pb.Diagnostic) {
+		event.Status = pb.Operation_STATUS_FAILED
+		res.Diagnostics = append(res.GetDiagnostics(), diags...)
+		event.Diagnostics = append(event.GetDiagnostics(), res.GetDiagnostics()...)
+		res.Elapsed = durationpb.New(time.Since(start))
+		eventVal.StatePush = res
+
+		if err := events.Publish(event); err != nil {
+			res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+			log.Error("failed to publish event", "error", err)
+		}
+	}
+
+	// Create our terraform executor
+	tf, err := r.TFConfig.Terraform()
+	if err != nil {
+		notifyFail(diagnostics.FromErr(err))
+
+		return res
+	}
+
+	// terraform state push
+	pushOut := r.newTextOutput()
+	tf.SetStdout(pushOut.Stdout)
+	tf.SetStderr(pushOut.Stderr)
+	err = tf.StatePush(ctx, path, tfexec.Force(true), tfexec.Lock(true))
+	res.Stderr = pushOut.Stderr.String()
+	r.logTextOutput("state push", pushOut)
+	if err != nil {
+		notifyFail(diagnostics.FromErr(err))
+
+		return res
+	}
+
+	// Finalize our responses and event
+	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
+	event.Diagnostics = res.GetDiagnostics()
+	res.Elapsed = durationpb.New(time.Since(start))
+	eventVal.StatePush = res
+
+	// Notify that we've finished
+	if err := events.Publish(event); err != nil {
+		log.Error("failed to send event", "error", err)
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+	}
+	log.Debug("finished state push")
+
+	return res
+}
@@ -6,6 +6,9 @@ package operation
 import (
 	"context"
 	"errors"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
@@ -21,6 +24,7 @@ func (r *Runner) terraformValidate(
 		Diagnostics: []*pb.Diagnostic{},
 	}
 	log := r.log.With(RequestDebugArgs(req)...)
+	start := time.Now()
 
 	ref, err := NewReferenceFromRequest(req)
 	if err != nil {
@@ -45,6 +49,7 @@ func (r *Runner) terraformValidate(
 		event.Status = pb.Operation_STATUS_FAILED
 		res.Diagnostics = append(res.GetDiagnostics(), diags...)
 		event.Diagnostics = append(event.GetDiagnostics(), res.GetDiagnostics()...)
+		res.Elapsed = durationpb.New(time.Since(start))
 		eventVal.Validate = res
 
 		if err := events.Publish(event); err != nil {
@@ -86,6 +91,7 @@ func (r *Runner) terraformValidate(
 
 	// Finalize our responses and event
 	event.Status = diagnostics.Status(r.TFConfig.FailOnWarnings, res.GetDiagnostics()...)
+	res.Elapsed = durationpb.New(time.Since(start))
 	eventVal.Validate = res
 
 	// Notify that we've finished
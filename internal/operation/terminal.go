@@ -0,0 +1,218 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/hashicorp/enos/internal/operation/command"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+// terminalOutputDrainTimeout is how long we'll wait for the remaining output of the terminal after
+// the sub-command has exited, e.g. if it has left behind processes that still have the terminal.
+const terminalOutputDrainTimeout = 2 * time.Second
+
+// terminalCtxKey is the context key of the terminal of an operation.
+type terminalCtxKey struct{}
+
+// Terminals are the pseudo-terminals of the operations that execute their sub-commands
+// interactively, keyed by the operation ID. A client attaches to the terminal of an operation to
+// proxy its stdin to the sub-command and the output of the sub-command back to it.
+type Terminals struct {
+	mu        sync.Mutex
+	terminals map[string]*Terminal
+}
+
+// Terminal is the terminal of an operation. Only a single client can attach to it and the
+// sub-command isn't executed until one has.
+type Terminal struct {
+	mu       sync.Mutex
+	attached bool
+	sessionC chan *TerminalSession
+	doneC    chan struct{}
+	done     sync.Once
+}
+
+// TerminalSession is the session of a client that has attached to a terminal. The sub-command
+// reads Stdin and writes its output to Output. The terminal has the initial Size and is resized to
+// every size that is sent on Resize.
+type TerminalSession struct {
+	Stdin  io.Reader
+	Output io.Writer
+	Size   *pb.TerminalSize
+	Resize <-chan *pb.TerminalSize
+}
+
+// NewTerminals returns a new Terminals.
+func NewTerminals() *Terminals {
+	return &Terminals{
+		terminals: map[string]*Terminal{},
+	}
+}
+
+// WorkFunc takes an operation request and its work function and returns a work function that
+// executes the work with a terminal that clients can attach to for as long as the work is running.
+func (t *Terminals) WorkFunc(req *pb.Operation_Request, work WorkFunc) WorkFunc {
+	return func(
+		ctx context.Context,
+		eventC chan *pb.Operation_Event,
+		log hclog.Logger,
+	) *pb.Operation_Response {
+		term := &Terminal{
+			sessionC: make(chan *TerminalSession),
+			doneC:    make(chan struct{}),
+		}
+
+		t.mu.Lock()
+		t.terminals[req.GetId()] = term
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			delete(t.terminals, req.GetId())
+			t.mu.Unlock()
+			term.close()
+		}()
+
+		return work(context.WithValue(ctx, terminalCtxKey{}, term), eventC, log)
+	}
+}
+
+// Attach attaches the session to the terminal of the operation. It blocks until the sub-command of
+// the operation has exited or the context is done.
+func (t *Terminals) Attach(ctx context.Context, op *pb.Ref_Operation, session *TerminalSession) error {
+	t.mu.Lock()
+	term, ok := t.terminals[op.GetId()]
+	t.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("operation %s does not have a terminal, it has either finished or was not executed with a TTY", op.GetId())
+	}
+
+	return term.attach(ctx, session)
+}
+
+func (t *Terminal) attach(ctx context.Context, session *TerminalSession) error {
+	t.mu.Lock()
+	if t.attached {
+		t.mu.Unlock()
+
+		return errors.New("another client has already attached to the terminal of the operation")
+	}
+	t.attached = true
+	t.mu.Unlock()
+
+	select {
+	case t.sessionC <- session:
+	case <-t.doneC:
+		return errors.New("the operation finished before the client attached to its terminal")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-t.doneC:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close closes the terminal, which detaches any client that has attached to it.
+func (t *Terminal) close() {
+	t.done.Do(func() { close(t.doneC) })
+}
+
+// run waits for a client to attach to the terminal and then runs the command in a new
+// pseudo-terminal. The output of the command is written to both the client and out.
+func (t *Terminal) run(ctx context.Context, cmd *command.Command, out io.Writer) error {
+	var session *TerminalSession
+	select {
+	case session = <-t.sessionC:
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for a client to attach to the terminal: %w", ctx.Err())
+	}
+	defer t.close()
+
+	ptm, pts, err := openPTY()
+	if err != nil {
+		return fmt.Errorf("allocating a pseudo-terminal: %w", err)
+	}
+	defer ptm.Close()
+
+	if session.Size != nil {
+		_ = resizePTY(ptm, session.Size)
+	}
+
+	cmd.ExecOpts = append(cmd.ExecOpts, func(ecmd *exec.Cmd) {
+		ecmd.Stdin, ecmd.Stdout, ecmd.Stderr = pts, pts, pts
+		ecmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	})
+
+	resizeDone := make(chan struct{})
+	defer close(resizeDone)
+	go func() {
+		for {
+			select {
+			case size, ok := <-session.Resize:
+				if !ok {
+					return
+				}
+				_ = resizePTY(ptm, size)
+			case <-resizeDone:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		_, _ = io.Copy(ptm, session.Stdin)
+	}()
+
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		_, _ = io.Copy(io.MultiWriter(session.Output, out), ptm)
+	}()
+
+	_, err = cmd.Run(ctx)
+
+	// Reading the terminal fails once the sub-command has exited and we've closed our end of it.
+	pts.Close()
+	select {
+	case <-outputDone:
+	case <-time.After(terminalOutputDrainTimeout):
+	}
+
+	return err
+}
+
+// resizePTY resizes the pseudo-terminal.
+func resizePTY(ptm *os.File, size *pb.TerminalSize) error {
+	return unix.IoctlSetWinsize(int(ptm.Fd()), unix.TIOCSWINSZ, &unix.Winsize{
+		Row: uint16(size.GetRows()),
+		Col: uint16(size.GetCols()),
+	})
+}
+
+// terminalFromContext returns the terminal of the operation of the context, if it has one.
+func terminalFromContext(ctx context.Context) *Terminal {
+	term, ok := ctx.Value(terminalCtxKey{}).(*Terminal)
+	if !ok {
+		return nil
+	}
+
+	return term
+}
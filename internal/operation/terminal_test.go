@@ -0,0 +1,186 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/operation/command"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+// terminalTestBuffer is a buffer that the output of a terminal can be written to while we read it.
+type terminalTestBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *terminalTestBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *terminalTestBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// testTerminalWork starts the work of an operation that runs cat in its terminal and returns a
+// channel that receives the error of running it once the operation has finished.
+func testTerminalWork(
+	ctx context.Context,
+	t *testing.T,
+	terms *Terminals,
+	req *pb.Operation_Request,
+	out io.Writer,
+) chan error {
+	t.Helper()
+
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat is not available")
+	}
+
+	errC := make(chan error, 1)
+	work := terms.WorkFunc(req, func(ctx context.Context, _ chan *pb.Operation_Event, _ hclog.Logger) *pb.Operation_Response {
+		term := terminalFromContext(ctx)
+		if term == nil {
+			errC <- errors.New("the work does not have a terminal")
+
+			return &pb.Operation_Response{}
+		}
+		errC <- term.run(ctx, command.NewCommand("cat"), out)
+
+		return &pb.Operation_Response{}
+	})
+
+	go work(ctx, make(chan *pb.Operation_Event, 10), hclog.NewNullLogger())
+
+	require.Eventually(t, func() bool {
+		terms.mu.Lock()
+		defer terms.mu.Unlock()
+		_, ok := terms.terminals[req.GetId()]
+
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return errC
+}
+
+// Test_Terminals_WorkFunc tests that interactive sub-commands are executed in a terminal that a
+// client can attach to.
+func Test_Terminals_WorkFunc(t *testing.T) {
+	t.Parallel()
+
+	t.Run("client attached", func(t *testing.T) {
+		t.Parallel()
+
+		terms := NewTerminals()
+		req := &pb.Operation_Request{Id: "attached"}
+		captured := &terminalTestBuffer{}
+		errC := testTerminalWork(context.Background(), t, terms, req, captured)
+
+		output := &terminalTestBuffer{}
+		// cat exits when it reads the end of the input, which is ^D at the start of a line.
+		require.NoError(t, terms.Attach(context.Background(), &pb.Ref_Operation{Id: req.GetId()}, &TerminalSession{
+			Stdin:  strings.NewReader("hello enos\n\x04"),
+			Output: output,
+			Size:   &pb.TerminalSize{Rows: 24, Cols: 80},
+		}))
+
+		select {
+		case err := <-errC:
+			require.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the operation to finish")
+		}
+
+		// The terminal echoes the input and cat writes it back to us.
+		require.Equal(t, 2, strings.Count(output.String(), "hello enos"), output.String())
+		require.Equal(t, output.String(), captured.String())
+
+		err := terms.Attach(context.Background(), &pb.Ref_Operation{Id: req.GetId()}, &TerminalSession{})
+		require.ErrorContains(t, err, "does not have a terminal")
+	})
+
+	t.Run("no client attached", func(t *testing.T) {
+		t.Parallel()
+
+		terms := NewTerminals()
+		req := &pb.Operation_Request{Id: "detached"}
+		ctx, cancel := context.WithCancel(context.Background())
+		errC := testTerminalWork(ctx, t, terms, req, io.Discard)
+		cancel()
+
+		select {
+		case err := <-errC:
+			require.ErrorIs(t, err, context.Canceled)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the operation to finish")
+		}
+
+		require.Eventually(t, func() bool {
+			err := terms.Attach(context.Background(), &pb.Ref_Operation{Id: req.GetId()}, &TerminalSession{})
+			return err != nil && strings.Contains(err.Error(), "does not have a terminal")
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		terms := NewTerminals()
+		req := &pb.Operation_Request{Id: "cancelled"}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errC := testTerminalWork(ctx, t, terms, req, io.Discard)
+
+		// cat keeps running until it reads the end of the input, which we never send.
+		stdin, stdinW := io.Pipe()
+		defer stdinW.Close()
+		output := &terminalTestBuffer{}
+		attachErrC := make(chan error, 1)
+		go func() {
+			attachErrC <- terms.Attach(context.Background(), &pb.Ref_Operation{Id: req.GetId()}, &TerminalSession{
+				Stdin:  stdin,
+				Output: output,
+			})
+		}()
+
+		_, err := stdinW.Write([]byte("ping\n"))
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return strings.Count(output.String(), "ping") == 2
+		}, 10*time.Second, 10*time.Millisecond, output.String())
+
+		cancel()
+
+		select {
+		case err := <-errC:
+			require.Error(t, err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the operation to finish")
+		}
+
+		select {
+		case err := <-attachErrC:
+			require.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the client to detach")
+		}
+	})
+}
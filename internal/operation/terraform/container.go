@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package terraform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultContainerRuntime is the container runtime if none has been configured.
+const DefaultContainerRuntime = "docker"
+
+// ContainerWrapperFile is the name of the script in the module directory that executes Terraform
+// in the container.
+const ContainerWrapperFile = "terraform-container.sh"
+
+// containerWrapperPath writes the script that executes Terraform in the container to the module
+// directory and returns its path. terraform-exec executes the script as if it were the Terraform
+// binary so every sub-command is executed in a new container.
+func (c *Config) containerWrapperPath() (string, error) {
+	return c.writeWrapper(ContainerWrapperFile, c.containerWrapper)
+}
+
+// writeWrapper writes the wrapper script that the func returns for the absolute module directory
+// to the file in the module directory and returns its path.
+func (c *Config) writeWrapper(file string, script func(dir string) string) (string, error) {
+	if c.DirPath == "" {
+		return "", errors.New("executing terraform in a wrapper requires a module directory")
+	}
+
+	dir, err := filepath.Abs(c.DirPath)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, file)
+	//nolint:gosec // G306 the script has to be executable
+	err = os.WriteFile(path, []byte(script(dir)), 0o755)
+	if err != nil {
+		return "", fmt.Errorf("writing terraform wrapper: %w", err)
+	}
+
+	return path, nil
+}
+
+// containerWrapper returns the script that executes Terraform in the container. The module
+// directory is the only writable mount. Only the environment variables of the Terraform
+// configuration, the configured container environment variables, and the TF_ variables are
+// passed to the container so that no other credentials of the host leak into it. Stdin is always
+// proxied to the container and it's given a TTY when the script is executed in one, e.g. by
+// interactive exec sub-commands like terraform console.
+func (c *Config) containerWrapper(dir string) string {
+	runtime := c.Container.GetRuntime()
+	if runtime == "" {
+		runtime = DefaultContainerRuntime
+	}
+
+	args := []string{runtime, "run", "--rm", "--interactive"}
+
+	// Make sure that files the container writes to the module directory are owned by us.
+	if filepath.Base(runtime) == "podman" {
+		args = append(args, "--userns=keep-id")
+	} else if uid := os.Getuid(); uid >= 0 {
+		args = append(args, fmt.Sprintf("--user=%d:%d", uid, os.Getgid()))
+	}
+
+	args = append(args,
+		"--env=HOME=/tmp",
+		"--volume="+dir+":"+dir,
+	)
+
+	for _, mount := range c.Container.GetReadOnlyMounts() {
+		if mount == "" || mount == dir {
+			continue
+		}
+		args = append(args, "--volume="+mount+":"+mount+":ro")
+	}
+
+	for _, name := range containerEnvNames(c.Env, c.Container.GetEnv()) {
+		args = append(args, "--env="+name)
+	}
+
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Generated by enos to execute Terraform in the " + c.Container.GetImage() + " container.\n")
+	b.WriteString("env_args=\"\"\n")
+	b.WriteString("for name in $(env | sed -n 's/^\\(TF_[A-Za-z0-9_]*\\)=.*/\\1/p'); do\n")
+	b.WriteString("  env_args=\"$env_args --env=$name\"\n")
+	b.WriteString("done\n")
+	b.WriteString("tty_args=\"\"\n")
+	b.WriteString("if [ -t 0 ]; then\n")
+	b.WriteString("  tty_args=\"--tty\"\n")
+	b.WriteString("fi\n")
+	b.WriteString("# shellcheck disable=SC2086\n")
+	b.WriteString("exec " + strings.Join(quoted, " ") + " --workdir=\"$(pwd)\" $env_args $tty_args --entrypoint=terraform " +
+		shellQuote(c.Container.GetImage()) + " \"$@\"\n")
+
+	return b.String()
+}
+
+// containerEnvNames returns the sorted and unique names of the environment variables that are
+// passed to the container.
+func containerEnvNames(env map[string]string, names []string) []string {
+	res := EnvNames(env)
+	for _, name := range names {
+		if name != "" && !slices.Contains(res, name) {
+			res = append(res, name)
+		}
+	}
+	slices.Sort(res)
+
+	return res
+}
+
+// shellQuote quotes the value for sh.
+func shellQuote(val string) string {
+	return "'" + strings.ReplaceAll(val, "'", `'\''`) + "'"
+}
@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_Config_containerWrapperPath tests the script that executes Terraform in a container.
+func Test_Config_containerWrapperPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mount := t.TempDir()
+	cfg := &Config{
+		DirPath: dir,
+		Env:     map[string]string{"AWS_REGION": "us-east-1"},
+		Container: &pb.Terraform_Runner_Config_Container{
+			Runtime:        "docker",
+			Image:          "hashicorp/terraform:1.9",
+			Env:            []string{"AWS_PROFILE", "AWS_REGION"},
+			ReadOnlyMounts: []string{mount, dir},
+		},
+	}
+
+	path, err := cfg.containerWrapperPath()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, ContainerWrapperFile), path)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	script, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(script), "if [ -t 0 ]; then\n  tty_args=\"--tty\"\nfi\n")
+	require.Contains(t, string(script), fmt.Sprintf(
+		"exec 'docker' 'run' '--rm' '--interactive' '--user=%d:%d' '--env=HOME=/tmp' '--volume=%s:%s' "+
+			"'--volume=%s:%s:ro' '--env=AWS_PROFILE' '--env=AWS_REGION' --workdir=\"$(pwd)\" $env_args $tty_args "+
+			"--entrypoint=terraform 'hashicorp/terraform:1.9' \"$@\"\n",
+		os.Getuid(), os.Getgid(), dir, dir, mount, mount,
+	))
+}
+
+// Test_Config_containerWrapper_Args tests the arguments that the script executes the container
+// runtime with, with and without a TTY.
+func Test_Config_containerWrapper_Args(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the wrapper is a shell script")
+	}
+
+	// The container runtime writes its arguments, one per line.
+	runtimePath := filepath.Join(t.TempDir(), "podman")
+	//nolint:gosec // G306 the fake runtime has to be executable
+	require.NoError(t, os.WriteFile(runtimePath, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\"\n"), 0o755))
+
+	dir := t.TempDir()
+	cfg := &Config{
+		DirPath: dir,
+		Container: &pb.Terraform_Runner_Config_Container{
+			Runtime: runtimePath,
+			Image:   "hashicorp/terraform:1.9",
+		},
+	}
+	path, err := cfg.containerWrapperPath()
+	require.NoError(t, err)
+
+	t.Run("no tty", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := exec.Command(path, "console")
+		cmd.Dir = dir
+		cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "TF_LOG=trace"}
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"run",
+			"--rm",
+			"--interactive",
+			"--userns=keep-id",
+			"--env=HOME=/tmp",
+			"--volume=" + dir + ":" + dir,
+			"--workdir=" + dir,
+			"--env=TF_LOG",
+			"--entrypoint=terraform",
+			"hashicorp/terraform:1.9",
+			"console",
+		}, strings.Fields(string(out)))
+	})
+
+	t.Run("tty", func(t *testing.T) {
+		t.Parallel()
+
+		if runtime.GOOS != "linux" {
+			t.Skip("executing in a TTY uses util-linux script")
+		}
+		scriptPath, err := exec.LookPath("script")
+		if err != nil {
+			t.Skip("script is not available")
+		}
+
+		//nolint:gosec // G204 we know the command
+		cmd := exec.Command(scriptPath, "--quiet", "--return", "--command", path+" console", os.DevNull)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		args := strings.Fields(string(out))
+		require.Contains(t, args, "--interactive")
+		require.Contains(t, args, "--tty")
+		require.Equal(t, "console", args[len(args)-1])
+	})
+}
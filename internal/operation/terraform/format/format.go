@@ -13,30 +13,108 @@ import (
 
 	"github.com/zclconf/go-cty/cty"
 	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
 // TerraformOutput takes a terraform executor output metadata and returns it as
-// human friendly formatted string.
+// human friendly formatted string. Sensitive outputs are redacted unless their
+// value has been revealed, in which case the value is marked as sensitive.
 func TerraformOutput(out *pb.Terraform_Command_Output_Response_Meta, indent int) (string, error) {
-	if out.GetSensitive() {
+	if out.GetSensitive() && !terraformOutputRevealed(out) {
 		return "(sensitive)", nil
 	}
 
-	typ, err := ctyjson.UnmarshalType(out.GetType())
+	typJSON, valJSON, err := terraformOutputJSON(out)
+	if err != nil {
+		return "", err
+	}
+
+	typ, err := ctyjson.UnmarshalType(typJSON)
 	if err != nil {
 		return "", err
 	}
 
-	val, err := ctyjson.Unmarshal(out.GetValue(), typ)
+	val, err := ctyjson.Unmarshal(valJSON, typ)
 	if err != nil {
 		return "", err
 	}
 
+	if out.GetSensitive() {
+		return Value(val, indent) + " (sensitive)", nil
+	}
+
 	return Value(val, indent), nil
 }
 
+// RedactTerraformOutput removes the value of the terraform executor output metadata if the output
+// is sensitive.
+func RedactTerraformOutput(out *pb.Terraform_Command_Output_Response_Meta) {
+	if !out.GetSensitive() {
+		return
+	}
+
+	out.Value = nil
+	out.StructuredValue = nil
+}
+
+// terraformOutputRevealed returns whether the output has a value that has not been redacted.
+func terraformOutputRevealed(out *pb.Terraform_Command_Output_Response_Meta) bool {
+	return len(out.GetValue()) > 0 || out.GetStructuredValue() != nil
+}
+
+// StructureTerraformOutput takes a terraform executor output metadata and sets its structured
+// type and value from its JSON encoded type and value. Sensitive outputs only have a structured
+// value if their value has not been redacted.
+func StructureTerraformOutput(out *pb.Terraform_Command_Output_Response_Meta) error {
+	if out == nil || len(out.GetType()) == 0 {
+		return nil
+	}
+
+	typ := &structpb.Value{}
+	if err := protojson.Unmarshal(out.GetType(), typ); err != nil {
+		return fmt.Errorf("structuring the type of output %s: %w", out.GetName(), err)
+	}
+	out.StructuredType = typ
+
+	if len(out.GetValue()) == 0 {
+		return nil
+	}
+
+	val := &structpb.Value{}
+	if err := protojson.Unmarshal(out.GetValue(), val); err != nil {
+		return fmt.Errorf("structuring the value of output %s: %w", out.GetName(), err)
+	}
+	out.StructuredValue = val
+
+	return nil
+}
+
+// terraformOutputJSON returns the JSON encoded type and value of the output. If the output only
+// has a structured type and value they are encoded as JSON.
+func terraformOutputJSON(out *pb.Terraform_Command_Output_Response_Meta) ([]byte, []byte, error) {
+	if len(out.GetType()) > 0 || out.GetStructuredType() == nil {
+		return out.GetType(), out.GetValue(), nil
+	}
+
+	typ, err := protojson.Marshal(out.GetStructuredType())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	val := []byte("null")
+	if out.GetStructuredValue() != nil {
+		val, err = protojson.Marshal(out.GetStructuredValue())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return typ, val, nil
+}
+
 // Value formats a value in a way that resembles Terraform language syntax
 // and uses the type conversion functions where necessary to indicate exactly
 // what type it is given, so that equality test failures can be quickly
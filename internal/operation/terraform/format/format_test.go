@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_StructureTerraformOutput tests that the declared types and the values of Terraform outputs
+// are decoded into structured types and values that keep them.
+func Test_StructureTerraformOutput(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		out       *pb.Terraform_Command_Output_Response_Meta
+		typ       string
+		val       string
+		redact    bool
+		formatted string
+		err       string
+	}{
+		"string": {
+			out:       &pb.Terraform_Command_Output_Response_Meta{Type: []byte(`"string"`), Value: []byte(`"vault"`)},
+			typ:       `"string"`,
+			val:       `"vault"`,
+			formatted: `"vault"`,
+		},
+		"number": {
+			out:       &pb.Terraform_Command_Output_Response_Meta{Type: []byte(`"number"`), Value: []byte(`8200`)},
+			typ:       `"number"`,
+			val:       `8200`,
+			formatted: `8200`,
+		},
+		"bool": {
+			out:       &pb.Terraform_Command_Output_Response_Meta{Type: []byte(`"bool"`), Value: []byte(`true`)},
+			typ:       `"bool"`,
+			val:       `true`,
+			formatted: `true`,
+		},
+		"list": {
+			out:       &pb.Terraform_Command_Output_Response_Meta{Type: []byte(`["list","string"]`), Value: []byte(`["a","b"]`)},
+			typ:       `["list","string"]`,
+			val:       `["a","b"]`,
+			formatted: "tolist([\n  \"a\",\n  \"b\",\n])",
+		},
+		"set": {
+			out:       &pb.Terraform_Command_Output_Response_Meta{Type: []byte(`["set","number"]`), Value: []byte(`[1]`)},
+			typ:       `["set","number"]`,
+			val:       `[1]`,
+			formatted: "toset([\n  1,\n])",
+		},
+		"tuple": {
+			out:       &pb.Terraform_Command_Output_Response_Meta{Type: []byte(`["tuple",["string","number"]]`), Value: []byte(`["a",1]`)},
+			typ:       `["tuple",["string","number"]]`,
+			val:       `["a",1]`,
+			formatted: "[\n  \"a\",\n  1,\n]",
+		},
+		"map": {
+			out:       &pb.Terraform_Command_Output_Response_Meta{Type: []byte(`["map","string"]`), Value: []byte(`{"a":"b"}`)},
+			typ:       `["map","string"]`,
+			val:       `{"a":"b"}`,
+			formatted: "tomap({\n  \"a\" = \"b\"\n})",
+		},
+		"object": {
+			out: &pb.Terraform_Command_Output_Response_Meta{
+				Type:  []byte(`["object",{"port":"number","tls":"bool"}]`),
+				Value: []byte(`{"port":8200,"tls":false}`),
+			},
+			typ:       `["object",{"port":"number","tls":"bool"}]`,
+			val:       `{"port":8200,"tls":false}`,
+			formatted: "{\n  \"port\" = 8200\n  \"tls\" = false\n}",
+		},
+		"null": {
+			out:       &pb.Terraform_Command_Output_Response_Meta{Type: []byte(`"string"`), Value: []byte(`null`)},
+			typ:       `"string"`,
+			val:       `null`,
+			formatted: `tostring(null)`,
+		},
+		"sensitive": {
+			out: &pb.Terraform_Command_Output_Response_Meta{
+				Type:      []byte(`"string"`),
+				Value:     []byte(`"secret"`),
+				Sensitive: true,
+			},
+			typ:       `"string"`,
+			val:       `"secret"`,
+			formatted: `"secret" (sensitive)`,
+		},
+		"sensitive redacted": {
+			out: &pb.Terraform_Command_Output_Response_Meta{
+				Type:      []byte(`"string"`),
+				Value:     []byte(`"secret"`),
+				Sensitive: true,
+			},
+			redact:    true,
+			typ:       `"string"`,
+			formatted: "(sensitive)",
+		},
+		"invalid type": {
+			out: &pb.Terraform_Command_Output_Response_Meta{Name: "addr", Type: []byte(`["list"`), Value: []byte(`[]`)},
+			err: "structuring the type of output addr",
+		},
+		"invalid value": {
+			out: &pb.Terraform_Command_Output_Response_Meta{Name: "addr", Type: []byte(`"string"`), Value: []byte(`{`)},
+			err: "structuring the value of output addr",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := StructureTerraformOutput(test.out)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+
+				return
+			}
+			require.NoError(t, err)
+			if test.redact {
+				RedactTerraformOutput(test.out)
+			}
+
+			typ, err := protojson.Marshal(test.out.GetStructuredType())
+			require.NoError(t, err)
+			require.JSONEq(t, test.typ, string(typ))
+
+			if test.val == "" {
+				require.Nil(t, test.out.GetStructuredValue())
+			} else {
+				val, err := protojson.Marshal(test.out.GetStructuredValue())
+				require.NoError(t, err)
+				require.JSONEq(t, test.val, string(val))
+			}
+
+			formatted, err := TerraformOutput(test.out, 0)
+			require.NoError(t, err)
+			require.Equal(t, test.formatted, formatted)
+
+			// Outputs that only have the structured type and value are formatted the same.
+			structured := &pb.Terraform_Command_Output_Response_Meta{
+				StructuredType:  test.out.GetStructuredType(),
+				StructuredValue: test.out.GetStructuredValue(),
+				Sensitive:       test.out.GetSensitive(),
+			}
+			formatted, err = TerraformOutput(structured, 0)
+			require.NoError(t, err)
+			require.Equal(t, test.formatted, formatted)
+		})
+	}
+}
+
+// Test_TerraformOutput_TypeMismatch tests that outputs whose values don't match their declared
+// types fail to format.
+func Test_TerraformOutput_TypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	for desc, out := range map[string]*pb.Terraform_Command_Output_Response_Meta{
+		"string is not a number": {Type: []byte(`"number"`), Value: []byte(`"vault"`)},
+		"object is not a list":   {Type: []byte(`["list","string"]`), Value: []byte(`{"a":"b"}`)},
+		"missing attribute":      {Type: []byte(`["object",{"port":"number"}]`), Value: []byte(`{"addr":"localhost"}`)},
+		"wrong element type":     {Type: []byte(`["map","bool"]`), Value: []byte(`{"a":"b"}`)},
+		"unknown type":           {Type: []byte(`"decimal"`), Value: []byte(`1`)},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			// The structured type and value are kept as they are declared.
+			require.NoError(t, StructureTerraformOutput(out))
+			require.NotNil(t, out.GetStructuredType())
+
+			_, err := TerraformOutput(out, 0)
+			require.Error(t, err)
+		})
+	}
+}
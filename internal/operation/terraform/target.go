@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TargetWrapperFile is the name of the script in the module directory that executes Terraform on
+// the remote target.
+const TargetWrapperFile = "terraform-target.sh"
+
+// targetWrapperPath writes the script that executes Terraform on the target to the module
+// directory and returns its path. The script executes the hidden "enos target exec" command, which
+// syncs the module directory to the target and back for every sub-command.
+func (c *Config) targetWrapperPath() (string, error) {
+	enos, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("determining the path of enos: %w", err)
+	}
+
+	return c.writeWrapper(TargetWrapperFile, func(dir string) string {
+		return c.targetWrapper(enos, dir)
+	})
+}
+
+// targetWrapper returns the script that executes Terraform on the target with the enos binary.
+func (c *Config) targetWrapper(enos string, dir string) string {
+	args := []string{
+		enos, "target", "exec",
+		"--target=" + c.Target.GetAddress(),
+		"--dir=" + dir,
+	}
+
+	for _, syncDir := range c.Target.GetSyncDirs() {
+		if syncDir != "" {
+			args = append(args, "--sync-dir="+syncDir)
+		}
+	}
+
+	for _, name := range EnvNames(c.Env) {
+		args = append(args, "--env="+name)
+	}
+
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+
+	return "#!/bin/sh\n" +
+		"# Generated by enos to execute Terraform on " + c.Target.GetAddress() + ".\n" +
+		"exec " + strings.Join(quoted, " ") + " -- \"$@\"\n"
+}
@@ -4,10 +4,12 @@
 package terraform
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/hashicorp/enos/internal/operation/command"
 	"github.com/hashicorp/enos/internal/ui/terminal"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/terraform-exec/tfexec"
 )
 
@@ -26,10 +29,16 @@ type Config struct {
 	ConfigPath     string            // where the terraformrc config is
 	DirPath        string            // what directory to execute the command in
 	Env            map[string]string // environment variables
+	SensitiveEnv   []string          // names of environment variables with sensitive values
 	ExecSubCmd     string            // raw command to run
 	OutputName     string            // output name
 	FailOnWarnings bool              // fail on warning diagnostics
 	Flags          *pb.Terraform_Runner_Config_Flags
+	Container      *pb.Terraform_Runner_Config_Container
+	Target         *pb.Terraform_Runner_Config_Target
+	Cost           *pb.Terraform_Runner_Config_Cost
+	Reuse          *pb.Terraform_Runner_Config_Reuse
+	Log            hclog.Logger // logs command lines and environment resolution
 }
 
 // Proto returns the instance of config as proto terraform executor config.
@@ -40,9 +49,14 @@ func (c *Config) Proto() *pb.Terraform_Runner_Config {
 		ConfigPath:     c.ConfigPath,
 		WorkingDirPath: c.DirPath,
 		Env:            c.Env,
+		SensitiveEnv:   c.SensitiveEnv,
 		UserSubCommand: c.ExecSubCmd,
 		OutputFilter:   c.OutputName,
 		FailOnWarnings: c.FailOnWarnings,
+		Container:      c.Container,
+		Target:         c.Target,
+		Cost:           c.Cost,
+		Reuse:          c.Reuse,
 	}
 }
 
@@ -54,9 +68,14 @@ func (c *Config) FromProto(pcfg *pb.Terraform_Runner_Config) {
 	c.ConfigPath = pcfg.GetConfigPath()
 	c.DirPath = pcfg.GetWorkingDirPath()
 	c.Env = pcfg.GetEnv()
+	c.SensitiveEnv = pcfg.GetSensitiveEnv()
 	c.ExecSubCmd = pcfg.GetUserSubCommand()
 	c.OutputName = pcfg.GetOutputFilter()
 	c.FailOnWarnings = pcfg.GetFailOnWarnings()
+	c.Container = pcfg.GetContainer()
+	c.Target = pcfg.GetTarget()
+	c.Cost = pcfg.GetCost()
+	c.Reuse = pcfg.GetReuse()
 }
 
 // WithModule updates the module directory and config path of the Terraform config
@@ -69,7 +88,46 @@ func (c *Config) WithModule(mod *pb.Terraform_Module) {
 	c.ConfigPath = mod.GetRcPath()
 }
 
+// ResolveExecPath returns the path of the executable that executes Terraform with the
+// configuration without writing any wrapper scripts. Terraform that is executed in a container is
+// executed by the container runtime and Terraform that is executed on a target is executed by enos,
+// so we can only resolve those locally.
+func (c *Config) ResolveExecPath() (string, error) {
+	if c.Container.GetImage() != "" {
+		runtime := c.Container.GetRuntime()
+		if runtime == "" {
+			runtime = DefaultContainerRuntime
+		}
+
+		return exec.LookPath(runtime)
+	}
+
+	if c.Target.GetAddress() != "" {
+		return os.Executable()
+	}
+
+	if c.BinPath != "" {
+		path, err := filepath.Abs(c.BinPath)
+		if err != nil {
+			return "", err
+		}
+
+		// LookPath verifies that paths with separators are executable files.
+		return exec.LookPath(path)
+	}
+
+	return exec.LookPath("terraform")
+}
+
 func (c *Config) tfPath() (string, error) {
+	if c.Container.GetImage() != "" {
+		return c.containerWrapperPath()
+	}
+
+	if c.Target.GetAddress() != "" {
+		return c.targetWrapperPath()
+	}
+
 	if c.BinPath != "" {
 		return filepath.Abs(c.BinPath)
 	}
@@ -94,9 +152,63 @@ func (c *Config) tfEnv() map[string]string {
 		env["TF_CLI_CONFIG_FILE"] = c.ConfigPath
 	}
 
+	// Only log the names of the variables as their values might be sensitive.
+	c.log().Debug("resolved terraform environment",
+		"env", EnvNames(c.Env),
+		"config_path", c.ConfigPath,
+	)
+
 	return tfexec.CleanEnv(env)
 }
 
+// log returns the logger, or a null logger if none has been configured.
+func (c *Config) log() hclog.Logger {
+	if c.Log == nil {
+		return hclog.NewNullLogger()
+	}
+
+	return c.Log
+}
+
+// EnvNames returns the sorted names of the environment variables.
+func EnvNames(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// MaskSensitiveEnv returns the text with the values of the sensitive environment variables
+// replaced so that they can be logged.
+func (c *Config) MaskSensitiveEnv(text string) string {
+	if c == nil {
+		return text
+	}
+
+	vals := []string{}
+	for _, name := range c.SensitiveEnv {
+		if val := c.Env[name]; val != "" {
+			vals = append(vals, val)
+		}
+	}
+	if len(vals) == 0 {
+		return text
+	}
+
+	// Replace the longest values first in case a value contains another.
+	slices.SortFunc(vals, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+	for _, val := range vals {
+		text = strings.ReplaceAll(text, val, "(sensitive)")
+	}
+
+	return text
+}
+
 // NewExecSubCmd creates a new instance of a command to run a terraform
 // sub-command.
 func (c *Config) NewExecSubCmd() *command.Command {
@@ -118,6 +230,11 @@ func (c *Config) NewExecSubCmd() *command.Command {
 		opts = append(opts, command.WithUI(c.UI))
 	}
 
+	c.log().Debug("running terraform command",
+		"command", execPath+" "+c.ExecSubCmd,
+		"dir", c.DirPath,
+	)
+
 	return command.NewCommand(execPath, opts...)
 }
 
@@ -146,6 +263,13 @@ func (c *Config) Terraform() (*tfexec.Terraform, error) {
 		tf.SetStdout(c.UI.Stdout)
 	}
 
+	// terraform-exec logs the command line of every command that it runs.
+	if c.Log != nil {
+		tf.SetLogger(c.Log.StandardLogger(&hclog.StandardLoggerOptions{
+			ForceLevel: hclog.Debug,
+		}))
+	}
+
 	return tf, nil
 }
 
@@ -179,6 +303,13 @@ func WithUI(ui *terminal.UI) ConfigOpt {
 	}
 }
 
+// WithLog sets the logger.
+func WithLog(log hclog.Logger) ConfigOpt {
+	return func(cfg *Config) {
+		cfg.Log = log
+	}
+}
+
 // WithBinPath sets the terraform binary path.
 func WithBinPath(path string) ConfigOpt {
 	return func(cfg *Config) {
@@ -302,6 +433,10 @@ func (c *Config) InitOptions() []tfexec.InitOption {
 	}
 }
 
+// PlanFileName is the name of the file in the module directory that plans are saved to when
+// their cost is estimated or they are evaluated against a policy.
+const PlanFileName = "enos-plan.tfplan"
+
 // PlanOptions are the plan command options.
 func (c *Config) PlanOptions() []tfexec.PlanOption {
 	return []tfexec.PlanOption{
@@ -7,11 +7,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/metrics"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	"github.com/hashicorp/go-hclog"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // WorkFunc is a function that a worker can run.
@@ -28,11 +32,14 @@ type worker struct {
 	events    chan *pb.Operation_Event
 	log       hclog.Logger
 	saveState func(*pb.Operation_Response) error
+	metrics   metrics.Sink
 }
 
 type workReq struct {
 	req *pb.Operation_Request
 	f   WorkFunc
+	// cancelled, if set, is called when the request is cancelled before its work has run
+	cancelled func()
 }
 
 // newWorker takes a context, work request channel, and update channel and returns a new instance
@@ -43,6 +50,7 @@ func newWorker(
 	events chan *pb.Operation_Event,
 	log hclog.Logger,
 	saveState func(*pb.Operation_Response) error,
+	sink metrics.Sink,
 ) *worker {
 	return &worker{
 		id:        id,
@@ -50,37 +58,10 @@ func newWorker(
 		events:    events,
 		log:       log,
 		saveState: saveState,
+		metrics:   sink,
 	}
 }
 
-func newWorkReqForOpReq(op *pb.Operation_Request) (*workReq, error) {
-	var err error
-	req := &workReq{
-		req: op,
-	}
-
-	switch op.GetValue().(type) {
-	case *pb.Operation_Request_Generate_:
-		req.f = GenerateScenario(op)
-	case *pb.Operation_Request_Check_:
-		req.f = CheckScenario(op)
-	case *pb.Operation_Request_Launch_:
-		req.f = LaunchScenario(op)
-	case *pb.Operation_Request_Destroy_:
-		req.f = DestroyScenario(op)
-	case *pb.Operation_Request_Run_:
-		req.f = RunScenario(op)
-	case *pb.Operation_Request_Exec_:
-		req.f = ExecScenario(op)
-	case *pb.Operation_Request_Output_:
-		req.f = OutputScenario(op)
-	default:
-		req.f, err = UnknownWorkFunc(op)
-	}
-
-	return req, err
-}
-
 // Run runs the worker. It continuously polls the input channel for new work requests.
 func (w *worker) run(ctx context.Context) {
 	w.log.Debug("running")
@@ -119,6 +100,7 @@ func (w *worker) runRequest(ctx context.Context, req *workReq) {
 	eWg := sync.WaitGroup{}
 	rWg := sync.WaitGroup{}
 	log := w.log.With(RequestDebugArgs(req.req)...)
+	startedAt := timestamppb.Now()
 
 	// Start the event sender
 	eWg.Add(1)
@@ -166,6 +148,21 @@ func (w *worker) runRequest(ctx context.Context, req *workReq) {
 		default:
 		}
 
+		// Operations with a niceness are executed on their own OS thread so that the Terraform
+		// processes that terraform-exec starts on it inherit the niceness. The thread will exit
+		// along with this goroutine. Commands that we start ourselves set their own niceness.
+		if niceness := req.req.GetWorkspace().GetLimits().GetNiceness(); niceness > 0 {
+			if err := setThreadNiceness(niceness); err != nil {
+				log.Error("unable to set operation niceness", "error", err)
+				res, _ := NewResponseFromRequest(req.req)
+				res.Status = pb.Operation_STATUS_FAILED
+				res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+				resC <- res
+
+				return
+			}
+		}
+
 		select {
 		case <-workCtx.Done():
 			return
@@ -186,7 +183,8 @@ func (w *worker) runRequest(ctx context.Context, req *workReq) {
 	select {
 	case res := <-resC:
 		if res == nil {
-			res, err := NewResponseFromRequest(req.req)
+			var err error
+			res, err = NewResponseFromRequest(req.req)
 			if err != nil {
 				err = fmt.Errorf("work request did not return a response: %w", err)
 			} else {
@@ -195,7 +193,7 @@ func (w *worker) runRequest(ctx context.Context, req *workReq) {
 			res.Status = pb.Operation_STATUS_FAILED
 			res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
 		}
-		w.completeRequest(res)
+		w.completeRequest(req.req, res, startedAt)
 		log.Debug("worker operation completed")
 	default:
 		log.Debug("worker operation cancelled")
@@ -207,13 +205,24 @@ func (w *worker) runRequest(ctx context.Context, req *workReq) {
 		}
 		res.Status = pb.Operation_STATUS_CANCELLED
 		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
-		w.completeRequest(res)
+		if req.cancelled != nil {
+			req.cancelled()
+		}
+		w.completeRequest(req.req, res, startedAt)
 	}
 }
 
 // completeRequest is responsible for persisting the operation response into
-// the state and sending the done event.
-func (w *worker) completeRequest(res *pb.Operation_Response) {
+// the state and the history and sending the done event.
+func (w *worker) completeRequest(
+	req *pb.Operation_Request,
+	res *pb.Operation_Response,
+	startedAt *timestamppb.Timestamp,
+) {
+	res.StartedAt = startedAt
+	res.CompletedAt = timestamppb.Now()
+	withXFail(req, res)
+	withLogFileDiagnostic(req, res)
 	err := w.saveState(res)
 	log := w.log.With(ResponseDebugArgs(res)...)
 	if err != nil {
@@ -221,9 +230,131 @@ func (w *worker) completeRequest(res *pb.Operation_Response) {
 		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
 	}
 
+	// The history is only used to show operations after the fact so we don't fail them if we
+	// can't write it.
+	if err := writeHistory(req, res); err != nil {
+		log.Warn("unable to write operation history", "error", err)
+	}
+
 	event, err := NewEventFromResponse(res)
 	if err != nil {
 		res.Status = pb.Operation_STATUS_FAILED
 	}
+	w.emitMetrics(req, res)
 	w.sendEvent(event, true)
 }
+
+// emitMetrics emits the count and duration of the completed operation, tagged with the type of the
+// operation and its status, e.g. run and completed.
+func (w *worker) emitMetrics(req *pb.Operation_Request, res *pb.Operation_Response) {
+	if w.metrics == nil {
+		return
+	}
+
+	tags := []metrics.Tag{
+		{Name: "type", Value: requestType(req)},
+		{Name: "status", Value: strings.ToLower(strings.TrimPrefix(res.GetStatus().String(), "STATUS_"))},
+	}
+	w.metrics.IncrCounter(metrics.OperationCount, 1, tags...)
+	w.metrics.AddTiming(metrics.OperationDuration,
+		res.GetCompletedAt().AsTime().Sub(res.GetStartedAt().AsTime()),
+		tags...,
+	)
+}
+
+// requestType returns the type of the operation request, e.g. run.
+func requestType(req *pb.Operation_Request) string {
+	switch req.GetValue().(type) {
+	case *pb.Operation_Request_Generate_:
+		return "generate"
+	case *pb.Operation_Request_Check_:
+		return "check"
+	case *pb.Operation_Request_Launch_:
+		return "launch"
+	case *pb.Operation_Request_Destroy_:
+		return "destroy"
+	case *pb.Operation_Request_Run_:
+		return "run"
+	case *pb.Operation_Request_Exec_:
+		return "exec"
+	case *pb.Operation_Request_Output_:
+		return "output"
+	case *pb.Operation_Request_Lock_:
+		return "lock"
+	case *pb.Operation_Request_StateBackup_:
+		return "state_backup"
+	case *pb.Operation_Request_StateRestore_:
+		return "state_restore"
+	default:
+		return "unknown"
+	}
+}
+
+// withLogFileDiagnostic adds the path of the log file to the detail of the first error diagnostic
+// of failed responses. The log file has the complete Terraform output of the operation, which the
+// diagnostics might not. We don't add an error diagnostic of its own as it would count as another
+// error. Failed responses without diagnostics get a warning instead.
+func withLogFileDiagnostic(req *pb.Operation_Request, res *pb.Operation_Response) {
+	path := req.GetWorkspace().GetLogFile()
+	if res == nil || path == "" || !hasFailedStatus(res.GetStatus()) {
+		return
+	}
+
+	diags := diagnostics.OpResDiags(res)
+	if len(diags) == 0 {
+		res.Diagnostics = append(res.GetDiagnostics(), &pb.Diagnostic{
+			Severity: pb.Diagnostic_SEVERITY_WARNING,
+			Summary:  "the complete logs of the operation have been written to " + path,
+			Detail:   "The log file includes the complete trace logs and Terraform output of the operation.",
+		})
+
+		return
+	}
+
+	diag := diags[0]
+	if i := slices.IndexFunc(diags, func(d *pb.Diagnostic) bool {
+		return d.GetSeverity() == pb.Diagnostic_SEVERITY_ERROR
+	}); i >= 0 {
+		diag = diags[i]
+	}
+
+	msg := "The complete trace logs and Terraform output of the operation have been written to " + path + "."
+	if diag.GetDetail() == "" {
+		diag.Detail = msg
+	} else {
+		diag.Detail += "\n\n" + msg
+	}
+}
+
+// withXFail inverts the result of check, launch, and run operations of scenarios that are expected
+// to fail. Failed operations keep their status and diagnostics but are marked as having failed as
+// expected so that they don't fail the run, while completed operations get a warning diagnostic
+// because they passed unexpectedly.
+func withXFail(req *pb.Operation_Request, res *pb.Operation_Response) {
+	// Responses of operations that were executed elsewhere, e.g. by a Nomad job, might have already
+	// been inverted.
+	if res == nil || req.GetXfail() == "" || res.GetXfail() != nil {
+		return
+	}
+
+	switch req.GetValue().(type) {
+	case *pb.Operation_Request_Check_, *pb.Operation_Request_Launch_, *pb.Operation_Request_Run_:
+	default:
+		return
+	}
+
+	res.Xfail = &pb.Operation_Response_XFail{Reason: req.GetXfail()}
+	switch res.GetStatus() {
+	case pb.Operation_STATUS_FAILED:
+		res.Xfail.Failed = true
+	case pb.Operation_STATUS_COMPLETED, pb.Operation_STATUS_COMPLETED_WARNING:
+		res.Status = pb.Operation_STATUS_COMPLETED_WARNING
+		res.Diagnostics = append(res.GetDiagnostics(), &pb.Diagnostic{
+			Severity: pb.Diagnostic_SEVERITY_WARNING,
+			Summary:  "the scenario passed unexpectedly",
+			Detail: "The scenario is expected to fail: " + req.GetXfail() + ". If it has been fixed " +
+				"remove the xfail attribute of the scenario.",
+		})
+	default:
+	}
+}
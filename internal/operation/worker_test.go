@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package operation
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/go-hclog"
+)
+
+// Test_withLogFileDiagnostic tests that the path of the log file is added to the detail of the
+// first error of failed responses without adding another error.
+func Test_withLogFileDiagnostic(t *testing.T) {
+	t.Parallel()
+
+	const path = "/tmp/out/enos.log"
+	req := &pb.Operation_Request{Workspace: &pb.Workspace{LogFile: path}}
+
+	for desc, test := range map[string]struct {
+		res      *pb.Operation_Response
+		req      *pb.Operation_Request
+		expected []*pb.Diagnostic
+	}{
+		"failed": {
+			res: &pb.Operation_Response{
+				Status: pb.Operation_STATUS_FAILED,
+				Value: &pb.Operation_Response_Launch_{Launch: &pb.Operation_Response_Launch{
+					Diagnostics: []*pb.Diagnostic{
+						{Severity: pb.Diagnostic_SEVERITY_WARNING, Summary: "warning"},
+						{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "error", Detail: "detail"},
+						{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "another error"},
+					},
+				}},
+			},
+			req: req,
+			expected: []*pb.Diagnostic{
+				{Severity: pb.Diagnostic_SEVERITY_WARNING, Summary: "warning"},
+				{
+					Severity: pb.Diagnostic_SEVERITY_ERROR,
+					Summary:  "error",
+					Detail:   "detail\n\nThe complete trace logs and Terraform output of the operation have been written to " + path + ".",
+				},
+				{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "another error"},
+			},
+		},
+		"failed without diagnostics": {
+			res: &pb.Operation_Response{Status: pb.Operation_STATUS_CANCELLED},
+			req: req,
+			expected: []*pb.Diagnostic{
+				{
+					Severity: pb.Diagnostic_SEVERITY_WARNING,
+					Summary:  "the complete logs of the operation have been written to " + path,
+					Detail:   "The log file includes the complete trace logs and Terraform output of the operation.",
+				},
+			},
+		},
+		"completed": {
+			res: &pb.Operation_Response{
+				Status: pb.Operation_STATUS_COMPLETED_WARNING,
+				Diagnostics: []*pb.Diagnostic{
+					{Severity: pb.Diagnostic_SEVERITY_WARNING, Summary: "warning"},
+				},
+			},
+			req: req,
+			expected: []*pb.Diagnostic{
+				{Severity: pb.Diagnostic_SEVERITY_WARNING, Summary: "warning"},
+			},
+		},
+		"no log file": {
+			res: &pb.Operation_Response{
+				Status: pb.Operation_STATUS_FAILED,
+				Diagnostics: []*pb.Diagnostic{
+					{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "error"},
+				},
+			},
+			req: &pb.Operation_Request{},
+			expected: []*pb.Diagnostic{
+				{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: "error"},
+			},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			errors := countErrors(diagnostics.OpResDiags(test.res))
+			withLogFileDiagnostic(test.req, test.res)
+
+			diags := diagnostics.OpResDiags(test.res)
+			require.Len(t, diags, len(test.expected))
+			require.Equal(t, errors, countErrors(diags))
+			for i := range test.expected {
+				require.Equal(t, test.expected[i].GetSeverity(), diags[i].GetSeverity())
+				require.Equal(t, test.expected[i].GetSummary(), diags[i].GetSummary())
+				require.Equal(t, test.expected[i].GetDetail(), diags[i].GetDetail())
+			}
+		})
+	}
+}
+
+func countErrors(diags []*pb.Diagnostic) int {
+	count := 0
+	for _, diag := range diags {
+		if diag.GetSeverity() == pb.Diagnostic_SEVERITY_ERROR {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Test_worker_runRequest_Niceness tests that the processes that operations with a niceness start,
+// e.g. Terraform, have the niceness.
+func Test_worker_runRequest_Niceness(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "linux" {
+		t.Skip("niceness is only supported on Linux")
+	}
+
+	// processNiceness starts a process like terraform-exec does and returns its niceness.
+	processNiceness := func(ctx context.Context) (int, error) {
+		//nolint:gosec // G204 we know the command
+		out, err := exec.CommandContext(ctx, "sh", "-c", `cut -d " " -f 19 /proc/self/stat`).Output()
+		if err != nil {
+			return 0, err
+		}
+
+		return strconv.Atoi(strings.TrimSpace(string(out)))
+	}
+
+	// Unprivileged users can only raise the niceness.
+	base, err := processNiceness(context.Background())
+	require.NoError(t, err)
+	if base >= 19 {
+		t.Skipf("the niceness of the test is already %d", base)
+	}
+	niceness := min(base+5, 19)
+
+	events := make(chan *pb.Operation_Event, 10)
+	w := newWorker("test", nil, events, hclog.NewNullLogger(), func(*pb.Operation_Response) error {
+		return nil
+	}, nil)
+
+	var processErr error
+	var processNice int
+	w.runRequest(context.Background(), &workReq{
+		req: &pb.Operation_Request{
+			Id: "niceness",
+			Workspace: &pb.Workspace{
+				OutDir: t.TempDir(),
+				Limits: &pb.ResourceLimits{Niceness: int32(niceness)},
+			},
+		},
+		f: func(ctx context.Context, _ chan *pb.Operation_Event, _ hclog.Logger) *pb.Operation_Response {
+			processNice, processErr = processNiceness(ctx)
+
+			return &pb.Operation_Response{Status: pb.Operation_STATUS_COMPLETED}
+		},
+	})
+
+	require.NoError(t, processErr)
+	require.Equal(t, niceness, processNice)
+	require.True(t, (<-events).GetDone())
+
+	// The niceness of the operation never leaks to other goroutines.
+	after, err := processNiceness(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, base, after)
+}
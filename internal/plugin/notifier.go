@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+
+	"github.com/hashicorp/enos/internal/notify"
+)
+
+var _ notify.Notifier = (*Notifier)(nil)
+
+// Summary is the summary of a run that is sent to the notifiers of plugins.
+type Summary struct {
+	Command         string            `json:"command"`
+	Failed          bool              `json:"failed"`
+	Passed          int               `json:"passed"`
+	FailedScenarios int               `json:"failed_scenarios"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Scenarios       []*ScenarioResult `json:"scenarios"`
+	Errors          []string          `json:"errors"`
+	Links           map[string]string `json:"links,omitempty"`
+	LogFile         string            `json:"log_file,omitempty"`
+}
+
+// ScenarioResult is the result of a scenario of a run.
+type ScenarioResult struct {
+	Name            string  `json:"name"`
+	Filter          string  `json:"filter"`
+	UID             string  `json:"uid"`
+	Status          string  `json:"status"`
+	Failed          bool    `json:"failed"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// NewSummary returns the plugin summary of the summary of a run.
+func NewSummary(s *notify.Summary) *Summary {
+	summary := &Summary{
+		Command:         s.Command,
+		Failed:          s.HasFailed(),
+		Passed:          s.Passed(),
+		FailedScenarios: s.Failed(),
+		DurationSeconds: s.Duration.Seconds(),
+		Scenarios:       []*ScenarioResult{},
+		Errors:          s.Errors,
+		LogFile:         s.LogFile,
+	}
+
+	if len(s.Links) > 0 {
+		summary.Links = map[string]string{}
+		for _, link := range s.Links {
+			summary.Links[link.Name] = link.URL
+		}
+	}
+
+	for _, result := range s.Scenarios {
+		r := &ScenarioResult{
+			Name:            result.Name,
+			Status:          result.Status.String(),
+			Failed:          result.Failed,
+			DurationSeconds: result.Duration.Seconds(),
+			Error:           result.Error,
+		}
+		if result.Scenario != nil {
+			r.Filter = result.Scenario.FilterStr()
+			r.UID = result.Scenario.UID()
+		}
+		summary.Scenarios = append(summary.Scenarios, r)
+	}
+
+	return summary
+}
+
+// Notifier sends the summaries of runs to a notifier of a plugin.
+type Notifier struct {
+	plugin *Plugin
+	name   string
+}
+
+// NewNotifier returns a new notifier for the notifier of the plugin with the name.
+func NewNotifier(p *Plugin, name string) *Notifier {
+	return &Notifier{plugin: p, name: name}
+}
+
+// Notify sends the summary to the notifier of the plugin.
+func (n *Notifier) Notify(ctx context.Context, summary *notify.Summary) error {
+	return n.plugin.Notify(ctx, n.name, NewSummary(summary))
+}
@@ -0,0 +1,230 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package plugin discovers and executes plugins, external executables that add commands, notifiers,
+// variable sources, report formats, and step provisioners to enos.
+//
+// Plugins implement an exec based protocol. Enos executes the plugin with a verb and the name of
+// the extension as arguments, writes the input of the verb as JSON to stdin, and reads the output
+// from stdout. Anything the plugin writes to stderr is shown in the error if it fails. The verbs
+// are:
+//
+//	describe                    writes the Manifest of the plugin as JSON
+//	command <name> [ARGS...]    runs the command with the stdio of enos
+//	notify <name>               reads the Summary of a run as JSON
+//	variables <name>            writes the values of variables as a JSON object of strings
+//	report <name>               reads the JSON report of a run and writes the formatted report
+//	provision <name>            reads the provisioner.Request of a step and writes its outputs as
+//	                            a JSON object, stderr is streamed to the output of the step
+//
+// The version of the protocol is passed in the ENOS_PLUGIN_PROTOCOL_VERSION environment variable.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ProtocolVersion is the version of the plugin protocol.
+const ProtocolVersion = 1
+
+// Verbs of the plugin protocol.
+const (
+	VerbDescribe  = "describe"
+	VerbCommand   = "command"
+	VerbNotify    = "notify"
+	VerbVariables = "variables"
+	VerbReport    = "report"
+	VerbProvision = "provision"
+)
+
+// Environment variables that are passed to plugins.
+const (
+	EnvProtocolVersion = "ENOS_PLUGIN_PROTOCOL_VERSION"
+	EnvBinary          = "ENOS_BINARY"
+)
+
+// Manifest describes the extensions of a plugin.
+type Manifest struct {
+	Name            string          `json:"name"`
+	Version         string          `json:"version,omitempty"`
+	ProtocolVersion int             `json:"protocol_version"`
+	Commands        []*Command      `json:"commands,omitempty"`
+	Notifiers       []string        `json:"notifiers,omitempty"`
+	VariableSources []string        `json:"variable_sources,omitempty"`
+	ReportFormats   []*ReportFormat `json:"report_formats,omitempty"`
+	Provisioners    []string        `json:"provisioners,omitempty"`
+}
+
+// Command is a top-level command of enos that a plugin implements.
+type Command struct {
+	Name  string `json:"name"`
+	Short string `json:"short,omitempty"`
+	Long  string `json:"long,omitempty"`
+}
+
+// ReportFormat is a format of the reports of runs that a plugin implements. Reports are written
+// with the extension, e.g. ".html".
+type ReportFormat struct {
+	Name      string `json:"name"`
+	Extension string `json:"extension,omitempty"`
+}
+
+// Plugin is a plugin executable.
+type Plugin struct {
+	Path     string
+	Manifest *Manifest
+}
+
+// Validate validates the manifest of the plugin.
+func (m *Manifest) Validate() error {
+	if m.Name == "" {
+		return errors.New("plugin manifest does not have a name")
+	}
+
+	if m.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("plugin %s uses protocol version %d, expected version %d",
+			m.Name, m.ProtocolVersion, ProtocolVersion,
+		)
+	}
+
+	for _, cmd := range m.Commands {
+		if cmd.Name == "" || strings.ContainsAny(cmd.Name, " \t\n") {
+			return fmt.Errorf("plugin %s has an invalid command name %q", m.Name, cmd.Name)
+		}
+	}
+
+	for _, format := range m.ReportFormats {
+		if format.Name == "" {
+			return fmt.Errorf("plugin %s has a report format without a name", m.Name)
+		}
+	}
+
+	return nil
+}
+
+// Describe executes the plugin at the path and returns the plugin with its manifest.
+func Describe(ctx context.Context, path string) (*Plugin, error) {
+	p := &Plugin{Path: path}
+
+	out, err := p.exec(ctx, "", nil, VerbDescribe)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(out, m); err != nil {
+		return nil, fmt.Errorf("decoding manifest of plugin %s: %w", path, err)
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p.Manifest = m
+
+	return p, nil
+}
+
+// Name returns the name of the plugin.
+func (p *Plugin) Name() string {
+	return p.Manifest.Name
+}
+
+// Command returns the command of the plugin that runs the command with the args. The caller is
+// responsible for setting its stdio and running it.
+func (p *Plugin) Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, p.Path, append([]string{VerbCommand, name}, args...)...)
+	cmd.Env = p.env()
+
+	return cmd
+}
+
+// Notify sends the summary of a run to the notifier of the plugin.
+func (p *Plugin) Notify(ctx context.Context, name string, summary *Summary) error {
+	in, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.exec(ctx, "", in, VerbNotify, name)
+
+	return err
+}
+
+// Variables returns the values of variables of the variable source of the plugin for the flight
+// plan in the directory. Values are raw like the values of ENOS_VAR_ environment variables.
+func (p *Plugin) Variables(ctx context.Context, name string, dir string) (map[string]string, error) {
+	out, err := p.exec(ctx, dir, nil, VerbVariables, name)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[string]string{}
+	if err := json.Unmarshal(out, &vars); err != nil {
+		return nil, fmt.Errorf("decoding variables of variable source %s of plugin %s: %w", name, p.Name(), err)
+	}
+
+	return vars, nil
+}
+
+// Report returns the JSON report of a run in the report format of the plugin.
+func (p *Plugin) Report(ctx context.Context, name string, report []byte) ([]byte, error) {
+	return p.exec(ctx, "", report, VerbReport, name)
+}
+
+// Provision executes the provisioner of the plugin with the JSON request of a step and returns the
+// JSON outputs of the step. The stderr of the plugin is also written to the writer.
+func (p *Plugin) Provision(ctx context.Context, name string, req []byte, w io.Writer) ([]byte, error) {
+	return p.run(ctx, "", req, w, VerbProvision, name)
+}
+
+// exec executes the plugin in the directory with the input and returns its output.
+func (p *Plugin) exec(ctx context.Context, dir string, in []byte, args ...string) ([]byte, error) {
+	return p.run(ctx, dir, in, nil, args...)
+}
+
+// run executes the plugin in the directory with the input and returns its output. If the writer is
+// set the stderr of the plugin is also written to it.
+func (p *Plugin) run(ctx context.Context, dir string, in []byte, w io.Writer, args ...string) ([]byte, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Dir = dir
+	cmd.Env = p.env()
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if w != nil {
+		cmd.Stderr = io.MultiWriter(stderr, w)
+	}
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return nil, fmt.Errorf("executing plugin %s %s: %w", p.Path, args[0], err)
+		}
+
+		return nil, fmt.Errorf("executing plugin %s %s: %w: %s", p.Path, args[0], err, msg)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// env returns the environment of the plugin.
+func (p *Plugin) env() []string {
+	env := append(os.Environ(), EnvProtocolVersion+"="+strconv.Itoa(ProtocolVersion))
+	if bin, err := os.Executable(); err == nil {
+		env = append(env, EnvBinary+"="+bin)
+	}
+
+	return env
+}
@@ -0,0 +1,231 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/notify"
+	"github.com/hashicorp/enos/internal/provisioner"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// testPlugin is a plugin that implements every verb of the protocol as a shell script. Its notifier
+// writes the summary next to the plugin and its report format counts the bytes of the report.
+const testPlugin = `#!/bin/sh
+set -e
+case "$1" in
+describe)
+  echo '{"name":"acme","version":"1.0.0","protocol_version":'"$ENOS_PLUGIN_PROTOCOL_VERSION"',
+    "commands":[{"name":"deploy","short":"Deploy things"}],
+    "notifiers":["pager"],"variable_sources":["vault"],
+    "report_formats":[{"name":"count","extension":".txt"}],"provisioners":["checks"]}'
+  ;;
+command)
+  shift 2
+  echo "deploying $*"
+  ;;
+notify)
+  cat > "$(dirname "$0")/summary.json"
+  ;;
+variables)
+  printf '{"pwd":"%s","region":"\\"us-east-1\\""}' "$(pwd)"
+  ;;
+report)
+  wc -c | tr -d ' '
+  ;;
+provision)
+  echo "provisioning $2" >&2
+  printf '{"request":%s}' "$(cat)"
+  ;;
+*)
+  echo "unknown verb $1" >&2
+  exit 1
+  ;;
+esac
+`
+
+// writeTestPlugin writes the plugin script to the directory with the file name.
+func writeTestPlugin(t *testing.T, dir string, name string, script string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+
+	return path
+}
+
+// Test_Registry_Discover tests discovering plugins in plugin directories.
+func Test_Registry_Discover(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts require a POSIX shell")
+	}
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	acme := writeTestPlugin(t, dir1, FileNamePrefix+"acme", testPlugin)
+	writeTestPlugin(t, dir1, FileNamePrefix+"broken", "#!/bin/sh\necho 'broken' >&2\nexit 1\n")
+	writeTestPlugin(t, dir1, FileNamePrefix+"old", "#!/bin/sh\necho '{\"name\":\"old\",\"protocol_version\":0}'\n")
+	// Files without the prefix or that aren't executable are ignored.
+	writeTestPlugin(t, dir1, "acme", testPlugin)
+	require.NoError(t, os.WriteFile(filepath.Join(dir1, FileNamePrefix+"readme"), []byte("# readme"), 0o644))
+	// Later plugins with the same name are errors.
+	writeTestPlugin(t, dir2, FileNamePrefix+"acme", testPlugin)
+
+	r := NewRegistry(WithRegistryDirs(dir1, filepath.Join(dir1, "missing"), dir2))
+	r.Discover(context.Background())
+
+	require.Len(t, r.Plugins, 1)
+	require.Equal(t, acme, r.Plugins[0].Path)
+	require.Equal(t, &Manifest{
+		Name:            "acme",
+		Version:         "1.0.0",
+		ProtocolVersion: ProtocolVersion,
+		Commands:        []*Command{{Name: "deploy", Short: "Deploy things"}},
+		Notifiers:       []string{"pager"},
+		VariableSources: []string{"vault"},
+		ReportFormats:   []*ReportFormat{{Name: "count", Extension: ".txt"}},
+		Provisioners:    []string{"checks"},
+	}, r.Plugin("acme").Manifest)
+	require.Nil(t, r.Plugin("missing"))
+
+	require.Len(t, r.Errors, 3)
+	require.ErrorContains(t, r.Errors[0], "broken")
+	require.ErrorContains(t, r.Errors[1], "plugin old uses protocol version 0, expected version 1")
+	require.ErrorContains(t, r.Errors[2], "plugin acme has already been registered by "+acme)
+}
+
+// Test_Plugin_Verbs tests executing the verbs of the protocol.
+func Test_Plugin_Verbs(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts require a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	p, err := Describe(context.Background(), writeTestPlugin(t, dir, FileNamePrefix+"acme", testPlugin))
+	require.NoError(t, err)
+
+	t.Run("command", func(t *testing.T) {
+		t.Parallel()
+
+		out, err := p.Command(context.Background(), "deploy", "--fast", "now").Output()
+		require.NoError(t, err)
+		require.Equal(t, "deploying --fast now\n", string(out))
+	})
+
+	t.Run("variables", func(t *testing.T) {
+		t.Parallel()
+
+		workDir, err := filepath.EvalSymlinks(t.TempDir())
+		require.NoError(t, err)
+		vars, err := p.Variables(context.Background(), "vault", workDir)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"pwd": workDir, "region": `"us-east-1"`}, vars)
+	})
+
+	t.Run("report", func(t *testing.T) {
+		t.Parallel()
+
+		out, err := p.Report(context.Background(), "count", []byte(`{"responses":[]}`))
+		require.NoError(t, err)
+		require.Equal(t, "16\n", string(out))
+	})
+
+	t.Run("provision", func(t *testing.T) {
+		t.Parallel()
+
+		req := &provisioner.Request{
+			Step:        "verify",
+			Provisioner: "checks",
+			Operation:   provisioner.OperationApply,
+			Variables:   map[string]json.RawMessage{"leader": json.RawMessage(`"10.0.0.1"`)},
+		}
+		stderr := &bytes.Buffer{}
+		outputs, err := NewProvisioner(p, "checks", stderr).Provision(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, "provisioning checks\n", stderr.String())
+		require.JSONEq(t, `{
+			"step": "verify",
+			"provisioner": "checks",
+			"operation": "apply",
+			"base_dir": "",
+			"variables": {"leader": "10.0.0.1"}
+		}`, string(outputs["request"]))
+	})
+
+	t.Run("unknown verb", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := p.exec(context.Background(), "", nil, "frobnicate")
+		require.ErrorContains(t, err, "unknown verb frobnicate")
+	})
+}
+
+// Test_Notifier tests sending the summaries of runs to the notifiers of plugins.
+func Test_Notifier(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts require a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	p, err := Describe(context.Background(), writeTestPlugin(t, dir, FileNamePrefix+"acme", testPlugin))
+	require.NoError(t, err)
+
+	scenario := flightplan.NewScenario()
+	scenario.Name = "upgrade"
+	scenario.Variants = flightplan.NewVector()
+	scenario.Variants.Add(flightplan.NewElement("arch", "amd64"))
+
+	require.NoError(t, NewNotifier(p, "pager").Notify(context.Background(), &notify.Summary{
+		Command: "enos scenario run",
+		Scenarios: []*notify.ScenarioResult{{
+			Name:     scenario.String(),
+			Scenario: scenario,
+			Status:   pb.Operation_STATUS_FAILED,
+			Failed:   true,
+			Duration: 90 * time.Second,
+			Error:    "timeout waiting for cluster",
+		}},
+		Errors:   []string{},
+		Duration: 90 * time.Second,
+		Links:    []*notify.Link{{Name: "Build", URL: "https://ci.example.com/1"}},
+	}))
+
+	summary, err := os.ReadFile(filepath.Join(dir, "summary.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "enos scenario run",
+		"failed": true,
+		"passed": 0,
+		"failed_scenarios": 1,
+		"duration_seconds": 90,
+		"scenarios": [{
+			"name": "upgrade [arch:amd64]",
+			"filter": "upgrade arch:amd64",
+			"uid": "`+scenario.UID()+`",
+			"status": "STATUS_FAILED",
+			"failed": true,
+			"duration_seconds": 90,
+			"error": "timeout waiting for cluster"
+		}],
+		"errors": [],
+		"links": {"Build": "https://ci.example.com/1"}
+	}`, string(summary))
+}
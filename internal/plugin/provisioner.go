@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hashicorp/enos/internal/provisioner"
+)
+
+var _ provisioner.Provisioner = (*Provisioner)(nil)
+
+// Provisioner provisions steps with a provisioner of a plugin.
+type Provisioner struct {
+	plugin *Plugin
+	name   string
+	stderr io.Writer
+}
+
+// NewProvisioner returns a new provisioner for the provisioner of the plugin. The stderr of the
+// plugin is written to the writer.
+func NewProvisioner(p *Plugin, name string, stderr io.Writer) *Provisioner {
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	return &Provisioner{plugin: p, name: name, stderr: stderr}
+}
+
+// Provision executes the provisioner of the plugin with the request.
+func (p *Provisioner) Provision(ctx context.Context, req *provisioner.Request) (provisioner.Outputs, error) {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := p.plugin.Provision(ctx, p.name, in, p.stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := provisioner.Outputs{}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return outputs, nil
+	}
+
+	if err := json.Unmarshal(out, &outputs); err != nil {
+		return nil, fmt.Errorf("decoding outputs of provisioner %s of plugin %s: %w", p.name, p.plugin.Name(), err)
+	}
+
+	return outputs, nil
+}
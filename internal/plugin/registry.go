@@ -0,0 +1,150 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FileNamePrefix is the prefix of the file names of plugin executables in plugin directories.
+const FileNamePrefix = "enos-plugin-"
+
+// EnvDirs is the environment variable of the list of plugin directories.
+const EnvDirs = "ENOS_PLUGIN_DIR"
+
+// DefaultDescribeTimeout is how long describing each plugin is allowed to take.
+const DefaultDescribeTimeout = 5 * time.Second
+
+// Registry is the registry of the plugins that have been discovered in plugin directories.
+type Registry struct {
+	dirs            []string
+	describeTimeout time.Duration
+	// Plugins are the plugins that have been discovered, ordered by the directories and file names
+	// of their executables.
+	Plugins []*Plugin
+	// Errors are the errors of executables that could not be described.
+	Errors []error
+}
+
+// RegistryOpt is a functional option.
+type RegistryOpt func(*Registry)
+
+// NewRegistry takes options and returns a new Registry.
+func NewRegistry(opts ...RegistryOpt) *Registry {
+	r := &Registry{
+		describeTimeout: DefaultDescribeTimeout,
+		Plugins:         []*Plugin{},
+		Errors:          []error{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// WithRegistryDirs sets the plugin directories that are searched for plugins.
+func WithRegistryDirs(dirs ...string) RegistryOpt {
+	return func(r *Registry) {
+		r.dirs = dirs
+	}
+}
+
+// WithRegistryDescribeTimeout sets how long describing each plugin is allowed to take.
+func WithRegistryDescribeTimeout(timeout time.Duration) RegistryOpt {
+	return func(r *Registry) {
+		r.describeTimeout = timeout
+	}
+}
+
+// DefaultDirs returns the plugin directories of the environment. The list of directories of
+// ENOS_PLUGIN_DIR takes precedence over the enos/plugins directory of the user configuration
+// directory.
+func DefaultDirs(getenv func(string) string) []string {
+	if dirs := getenv(EnvDirs); dirs != "" {
+		return filepath.SplitList(dirs)
+	}
+
+	cfgDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+
+	return []string{filepath.Join(cfgDir, "enos", "plugins")}
+}
+
+// Discover describes the executables of the plugin directories. Directories that don't exist are
+// ignored. Plugins with the name of a plugin that has already been discovered are errors.
+func (r *Registry) Discover(ctx context.Context) {
+	for _, dir := range r.dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				r.Errors = append(r.Errors, fmt.Errorf("reading plugin directory: %w", err))
+			}
+
+			continue
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if !strings.HasPrefix(entry.Name(), FileNamePrefix) || !isExecutable(path) {
+				continue
+			}
+
+			dctx, cancel := context.WithTimeout(ctx, r.describeTimeout)
+			p, err := Describe(dctx, path)
+			cancel()
+			if err != nil {
+				r.Errors = append(r.Errors, err)
+
+				continue
+			}
+
+			if r.Plugin(p.Name()) != nil {
+				r.Errors = append(r.Errors, fmt.Errorf("%s: plugin %s has already been registered by %s",
+					path, p.Name(), r.Plugin(p.Name()).Path,
+				))
+
+				continue
+			}
+
+			r.Plugins = append(r.Plugins, p)
+		}
+	}
+}
+
+// Plugin returns the plugin with the name, or nil if it has not been discovered.
+func (r *Registry) Plugin(name string) *Plugin {
+	idx := slices.IndexFunc(r.Plugins, func(p *Plugin) bool {
+		return p.Name() == name
+	})
+	if idx < 0 {
+		return nil
+	}
+
+	return r.Plugins[idx]
+}
+
+// isExecutable returns whether or not the path is an executable file. Symlinks are followed to
+// the executables of plugins that are installed elsewhere. Every regular file is executable on
+// Windows.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0o111 != 0
+}
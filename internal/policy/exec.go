@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ExecEnabled returns whether the policy restricts the sub-commands of scenario exec.
+func ExecEnabled(p *pb.Policy) bool {
+	return len(p.GetAllowExec()) > 0 || len(p.GetDenyExec()) > 0
+}
+
+// EvaluateExec returns an error diagnostic if the policy doesn't allow scenario exec to run the
+// Terraform sub-command. Commands match sub-commands that begin with the same words, regardless of
+// any flags in between, i.e. "state show" matches "state show -json foo". Flags of commands must
+// be set in the sub-command, i.e. "plan -destroy" only matches destroy plans, including
+// "plan --destroy" and "plan -destroy=true" but not "plan -destroy=false". A sub-command is
+// denied if it matches any denied command, or if the policy allows commands and it matches none of
+// them.
+func EvaluateExec(p *pb.Policy, subCmd string) []*pb.Diagnostic {
+	diags := []*pb.Diagnostic{}
+	if !ExecEnabled(p) {
+		return diags
+	}
+
+	if cmd, ok := matchExec(p.GetDenyExec(), subCmd); ok {
+		return append(diags, violation(
+			"exec of denied command",
+			fmt.Sprintf("The sub-command %q matches the denied command %q", subCmd, cmd),
+			nil,
+		))
+	}
+
+	if len(p.GetAllowExec()) == 0 {
+		return diags
+	}
+
+	if _, ok := matchExec(p.GetAllowExec(), subCmd); !ok {
+		return append(diags, violation(
+			"exec of command that is not allowed",
+			fmt.Sprintf("The sub-command %q does not match any of the allowed commands:", subCmd),
+			p.GetAllowExec(),
+		))
+	}
+
+	return diags
+}
+
+// matchExec returns the first command that matches the sub-command.
+func matchExec(cmds []string, subCmd string) (string, bool) {
+	words, flags := execFields(subCmd)
+	for _, cmd := range cmds {
+		cmdWords, cmdFlags := execFields(cmd)
+		if len(cmdWords)+len(cmdFlags) == 0 || len(cmdWords) > len(words) {
+			continue
+		}
+
+		if !slices.Equal(cmdWords, words[:len(cmdWords)]) {
+			continue
+		}
+
+		if !slices.ContainsFunc(cmdFlags, func(flag execFlag) bool { return !hasExecFlag(flags, flag) }) {
+			return cmd, true
+		}
+	}
+
+	return "", false
+}
+
+// execFlag is a flag of a sub-command.
+type execFlag struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+// hasExecFlag returns whether the flags set the flag. A flag without a value matches any flag of
+// the same name that isn't set to a false boolean value, i.e. "-destroy" matches "--destroy" and
+// "-destroy=true" but not "-destroy=false". A flag with a value only matches flags of the same
+// name with an equal value.
+func hasExecFlag(flags []execFlag, flag execFlag) bool {
+	for _, f := range flags {
+		if f.name != flag.name {
+			continue
+		}
+
+		if !flag.hasValue {
+			if b, err := strconv.ParseBool(f.value); !f.hasValue || err != nil || b {
+				return true
+			}
+
+			continue
+		}
+
+		value := f.value
+		if !f.hasValue {
+			value = "true"
+		}
+
+		if value == flag.value {
+			return true
+		}
+
+		b1, err1 := strconv.ParseBool(value)
+		b2, err2 := strconv.ParseBool(flag.value)
+		if err1 == nil && err2 == nil && b1 == b2 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// execFields returns the words and the flags of the sub-command. Like Go's flag package that
+// Terraform uses to parse them, flags may be prefixed by one or two dashes.
+func execFields(subCmd string) ([]string, []execFlag) {
+	words := []string{}
+	flags := []execFlag{}
+	for _, field := range strings.Fields(subCmd) {
+		if !strings.HasPrefix(field, "-") {
+			words = append(words, field)
+
+			continue
+		}
+
+		name := "-" + strings.TrimLeft(field, "-")
+		name, value, hasValue := strings.Cut(name, "=")
+		flags = append(flags, execFlag{name: name, value: value, hasValue: hasValue})
+	}
+
+	return words, flags
+}
@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_EvaluateExec tests evaluating the allowed and denied exec commands of policies against
+// sub-commands.
+func Test_EvaluateExec(t *testing.T) {
+	t.Parallel()
+
+	pol := &pb.Policy{
+		AllowExec: []string{"plan", "output", "state list", "state show"},
+		DenyExec:  []string{"plan -destroy", "state show secret"},
+	}
+
+	for desc, test := range map[string]struct {
+		policy   *pb.Policy
+		subCmd   string
+		expected string
+	}{
+		"no policy": {
+			subCmd: "apply -auto-approve",
+		},
+		"allowed": {
+			policy: pol,
+			subCmd: "plan -var-file=foo.tfvars",
+		},
+		"allowed nested": {
+			policy: pol,
+			subCmd: "state show -json module.target.aws_instance.target[0]",
+		},
+		"flags first": {
+			policy: pol,
+			subCmd: "-chdir=. output -json",
+		},
+		"not allowed": {
+			policy:   pol,
+			subCmd:   "apply -auto-approve",
+			expected: "policy violation: exec of command that is not allowed",
+		},
+		"not allowed sub-command": {
+			policy:   pol,
+			subCmd:   "state rm module.target",
+			expected: "policy violation: exec of command that is not allowed",
+		},
+		"prefix of allowed": {
+			policy:   pol,
+			subCmd:   "state",
+			expected: "policy violation: exec of command that is not allowed",
+		},
+		"denied": {
+			policy:   pol,
+			subCmd:   "state show secret",
+			expected: "policy violation: exec of denied command",
+		},
+		"denied flag": {
+			policy:   pol,
+			subCmd:   "plan -out=tfplan -destroy",
+			expected: "policy violation: exec of denied command",
+		},
+		"denied ignores flags": {
+			policy:   &pb.Policy{DenyExec: []string{"apply", "destroy", "import"}},
+			subCmd:   "destroy -auto-approve",
+			expected: "policy violation: exec of denied command",
+		},
+		"denied flag only": {
+			policy:   &pb.Policy{DenyExec: []string{"-destroy"}},
+			subCmd:   "apply -destroy",
+			expected: "policy violation: exec of denied command",
+		},
+		"denied double dash flag": {
+			policy:   pol,
+			subCmd:   "plan --destroy",
+			expected: "policy violation: exec of denied command",
+		},
+		"denied flag only double dash": {
+			policy:   &pb.Policy{DenyExec: []string{"-destroy"}},
+			subCmd:   "apply --destroy",
+			expected: "policy violation: exec of denied command",
+		},
+		"denied flag true": {
+			policy:   pol,
+			subCmd:   "plan -destroy=true",
+			expected: "policy violation: exec of denied command",
+		},
+		"denied double dash flag true": {
+			policy:   pol,
+			subCmd:   "plan --destroy=true",
+			expected: "policy violation: exec of denied command",
+		},
+		"flag false": {
+			policy: pol,
+			subCmd: "plan -destroy=false",
+		},
+		"double dash flag false": {
+			policy: pol,
+			subCmd: "plan --destroy=false",
+		},
+		"denied flag value": {
+			policy:   &pb.Policy{DenyExec: []string{"apply -lock=false"}},
+			subCmd:   "apply --lock=0",
+			expected: "policy violation: exec of denied command",
+		},
+		"flag other value": {
+			policy: &pb.Policy{DenyExec: []string{"apply -lock=false"}},
+			subCmd: "apply -lock",
+		},
+		"only denied": {
+			policy: &pb.Policy{DenyExec: []string{"apply", "destroy", "import"}},
+			subCmd: "state list",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := EvaluateExec(test.policy, test.subCmd)
+			if test.expected == "" {
+				require.Empty(t, got)
+
+				return
+			}
+
+			require.Len(t, got, 1)
+			require.Equal(t, test.expected, got[0].GetSummary())
+			require.Equal(t, pb.Diagnostic_SEVERITY_ERROR, got[0].GetSeverity())
+			require.Equal(t, Code, got[0].GetCode())
+		})
+	}
+
+	require.False(t, ExecEnabled(nil))
+	require.True(t, ExecEnabled(pol))
+}
@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+)
+
+// ScenarioInput returns the input of scenario policies for the decoded scenario. Values that are
+// not known until the scenario is applied, e.g. references to the outputs of other steps, are null.
+func ScenarioInput(sc *flightplan.Scenario) map[string]any {
+	in := map[string]any{
+		"name":        sc.Name,
+		"description": sc.Description,
+		"uid":         sc.UID(),
+		"variants":    map[string]any{},
+		"fixtures":    append([]string{}, sc.Fixtures...),
+		"providers":   []any{},
+		"steps":       []any{},
+		"outputs":     []any{},
+	}
+
+	if sc.Variants != nil {
+		for _, elm := range sc.Variants.Elements() {
+			in["variants"].(map[string]any)[elm.Key] = elm.Val
+		}
+	}
+
+	if sc.TerraformCLI != nil {
+		in["terraform_cli"] = sc.TerraformCLI.Name
+	}
+
+	for _, p := range sc.Providers {
+		provider := map[string]any{"type": p.Type, "alias": p.Alias, "config": map[string]any{}}
+		if p.Config != nil {
+			provider["config"] = ctyMapToAny(p.Config.Attrs)
+		}
+		in["providers"] = append(in["providers"].([]any), provider)
+	}
+
+	for _, step := range sc.Steps {
+		s := map[string]any{
+			"name":        step.Name,
+			"description": step.Description,
+			"depends_on":  append([]string{}, step.DependsOn...),
+			"skip":        step.Skip,
+			"verifies":    []string{},
+		}
+
+		for _, q := range step.Verifies {
+			s["verifies"] = append(s["verifies"].([]string), q.Name)
+		}
+
+		providers := []string{}
+		for name := range step.Providers {
+			providers = append(providers, name)
+		}
+		slices.Sort(providers)
+		s["providers"] = providers
+
+		if step.Module != nil {
+			s["module"] = map[string]any{
+				"name":    step.Module.Name,
+				"source":  step.Module.Source,
+				"version": step.Module.Version,
+			}
+			s["variables"] = ctyMapToAny(step.Module.Attrs)
+		}
+
+		if step.Provisioner != nil {
+			s["provisioner"] = map[string]any{"type": step.Provisioner.Type}
+			s["variables"] = ctyMapToAny(step.Provisioner.Attrs)
+		}
+
+		in["steps"] = append(in["steps"].([]any), s)
+	}
+
+	for _, out := range sc.Outputs {
+		in["outputs"] = append(in["outputs"].([]any), map[string]any{
+			"name":        out.Name,
+			"description": out.Description,
+			"sensitive":   out.Sensitive,
+		})
+	}
+
+	return in
+}
+
+// ModuleInput returns the input of module policies for the generated Terraform module at the path,
+// which is the module in the Terraform JSON configuration syntax. Attributes that are not literal
+// values are the source of their expressions as a template, e.g. "${module.target.ips}".
+func ModuleInput(path string) (map[string]any, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parsing generated module: %s", diags.Error())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("parsing generated module: unexpected body type %T", file.Body)
+	}
+
+	return bodyToAny(body, src), nil
+}
+
+// bodyToAny converts the body into the Terraform JSON configuration syntax. Labeled blocks are
+// nested objects of their labels. Repeated blocks of the same type and labels are arrays.
+func bodyToAny(body *hclsyntax.Body, src []byte) map[string]any {
+	out := map[string]any{}
+
+	for name, attr := range body.Attributes {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || !val.IsWhollyKnown() {
+			expr := string(attr.Expr.Range().SliceBytes(src))
+			out[name] = "${" + strings.TrimSpace(expr) + "}"
+
+			continue
+		}
+		out[name] = ctyToAny(val)
+	}
+
+	for _, block := range body.Blocks {
+		parent := out
+		keys := append([]string{block.Type}, block.Labels...)
+		for _, key := range keys[:len(keys)-1] {
+			next, ok := parent[key].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				parent[key] = next
+			}
+			parent = next
+		}
+
+		key := keys[len(keys)-1]
+		child := bodyToAny(block.Body, src)
+		switch existing := parent[key].(type) {
+		case nil:
+			parent[key] = child
+		case []any:
+			parent[key] = append(existing, child)
+		default:
+			parent[key] = []any{existing, child}
+		}
+	}
+
+	return out
+}
+
+func ctyMapToAny(attrs map[string]cty.Value) map[string]any {
+	out := map[string]any{}
+	for k, v := range attrs {
+		out[k] = ctyToAny(v)
+	}
+
+	return out
+}
+
+// ctyToAny converts the value to its JSON equivalent. Unknown and null values are nil.
+func ctyToAny(val cty.Value) any {
+	val, _ = val.UnmarkDeep()
+	if val.IsNull() || !val.IsKnown() {
+		return nil
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return val.AsString()
+	case ty == cty.Number:
+		f, _ := val.AsBigFloat().Float64()
+
+		return f
+	case ty == cty.Bool:
+		return val.True()
+	case ty.IsListType(), ty.IsSetType(), ty.IsTupleType():
+		out := []any{}
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			out = append(out, ctyToAny(v))
+		}
+
+		return out
+	case ty.IsMapType(), ty.IsObjectType():
+		out := map[string]any{}
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			out[k.AsString()] = ctyToAny(v)
+		}
+
+		return out
+	default:
+		return nil
+	}
+}
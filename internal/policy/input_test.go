@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_ModuleInput tests converting generated modules to the Terraform JSON configuration syntax.
+func Test_ModuleInput(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "main.tf")
+	require.NoError(t, os.WriteFile(path, []byte(`
+terraform {
+  required_version = ">= 1.2.0"
+}
+
+provider "aws" {
+  region = "us-east-1"
+}
+
+provider "aws" {
+  alias  = "west"
+  region = "us-west-2"
+}
+
+variable "enos_run_id" {
+  type    = string
+  default = ""
+}
+
+module "target" {
+  source        = "./modules/target"
+  instance_type = "t3.small"
+  count         = 2
+  tags          = merge(local.enos_tags, { Name = "target" })
+}
+`), 0o600))
+
+	in, err := ModuleInput(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"terraform": map[string]any{"required_version": ">= 1.2.0"},
+		"provider": map[string]any{
+			"aws": []any{
+				map[string]any{"region": "us-east-1"},
+				map[string]any{"alias": "west", "region": "us-west-2"},
+			},
+		},
+		"variable": map[string]any{
+			"enos_run_id": map[string]any{"type": "${string}", "default": ""},
+		},
+		"module": map[string]any{
+			"target": map[string]any{
+				"source":        "./modules/target",
+				"instance_type": "t3.small",
+				"count":         float64(2),
+				"tags":          `${merge(local.enos_tags, { Name = "target" })}`,
+			},
+		},
+	}, in)
+
+	_, err = ModuleInput(filepath.Join(t.TempDir(), "missing.tf"))
+	require.Error(t, err)
+}
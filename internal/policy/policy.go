@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package policy enforces the guardrails of the resources that scenarios create on their plans,
+// i.e. the resource types and instance types that are denied and the maximum costs, and the
+// Terraform sub-commands that scenario exec may run.
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/hashicorp/enos/internal/cost"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Code is the code of the diagnostics of policy violations.
+const Code = "policy"
+
+// maxCostResources is how many of the most expensive resources are listed when a plan exceeds the
+// maximum cost.
+const maxCostResources = 5
+
+// instanceTypeAttrs are the attributes that have the instance type or size of resources of common
+// providers.
+var instanceTypeAttrs = []string{"instance_type", "machine_type", "node_type", "vm_size", "size"}
+
+// Enabled returns whether the policy has any guardrails.
+func Enabled(p *pb.Policy) bool {
+	return NeedsCost(p) || len(p.GetDenyResourceTypes()) > 0 || len(p.GetDenyInstanceTypes()) > 0
+}
+
+// NeedsCost returns whether the policy has maximum costs, which require a cost estimate.
+func NeedsCost(p *pb.Policy) bool {
+	return p.GetMaxHourlyCost() > 0 || p.GetMaxMonthlyCost() > 0
+}
+
+// Evaluate returns an error diagnostic for every guardrail of the policy that the plan violates.
+// The estimate is the estimated cost of the plan, which is required if the policy has maximum
+// costs.
+func Evaluate(p *pb.Policy, plan *tfjson.Plan, est *pb.Terraform_CostEstimate) []*pb.Diagnostic {
+	diags := []*pb.Diagnostic{}
+	if !Enabled(p) || plan == nil {
+		return diags
+	}
+
+	deniedTypes := []string{}
+	deniedInstances := []string{}
+	for _, rc := range plan.ResourceChanges {
+		if rc == nil || rc.Change == nil || rc.Mode != tfjson.ManagedResourceMode {
+			continue
+		}
+
+		actions := rc.Change.Actions
+		if !actions.Create() && !actions.Replace() && !actions.Update() {
+			continue
+		}
+
+		if !actions.Update() {
+			if pattern, ok := match(p.GetDenyResourceTypes(), rc.Type); ok {
+				deniedTypes = append(deniedTypes, fmt.Sprintf("%s: %s matches %s", rc.Address, rc.Type, pattern))
+			}
+		}
+
+		after, ok := rc.Change.After.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range instanceTypeAttrs {
+			val, ok := after[attr].(string)
+			if !ok || val == "" {
+				continue
+			}
+
+			if pattern, ok := match(p.GetDenyInstanceTypes(), val); ok {
+				deniedInstances = append(deniedInstances, fmt.Sprintf("%s: %s %s matches %s", rc.Address, attr, val, pattern))
+			}
+
+			break
+		}
+	}
+
+	if len(deniedTypes) > 0 {
+		diags = append(diags, violation(
+			"plan creates denied resource types",
+			"The plan creates resources whose types are denied by the policy:",
+			deniedTypes,
+		))
+	}
+
+	if len(deniedInstances) > 0 {
+		diags = append(diags, violation(
+			"plan creates denied instance types",
+			"The plan creates resources whose instance types are denied by the policy:",
+			deniedInstances,
+		))
+	}
+
+	if !NeedsCost(p) {
+		return diags
+	}
+
+	if est == nil {
+		return append(diags, &pb.Diagnostic{
+			Severity: pb.Diagnostic_SEVERITY_ERROR,
+			Summary:  "unable to enforce the maximum cost of the policy",
+			Detail:   "The cost of the plan could not be estimated",
+			Code:     Code,
+		})
+	}
+
+	for _, limit := range []struct {
+		period string
+		max    float64
+		val    float64
+	}{
+		{"hourly", p.GetMaxHourlyCost(), est.GetHourly()},
+		{"monthly", p.GetMaxMonthlyCost(), est.GetMonthly()},
+	} {
+		if limit.max <= 0 || limit.val <= limit.max {
+			continue
+		}
+
+		resources := []string{}
+		for i, r := range est.GetResources() {
+			if i == maxCostResources || r.GetMonthly() == 0 {
+				break
+			}
+			resources = append(resources, fmt.Sprintf("%s: %s per month", r.GetAddress(), cost.Format(r.GetMonthly(), est.GetCurrency())))
+		}
+
+		diags = append(diags, violation(
+			"plan exceeds the maximum "+limit.period+" cost",
+			fmt.Sprintf("The estimated %s cost of %s exceeds the maximum of %s. The most expensive resources are:",
+				limit.period, cost.Format(limit.val, est.GetCurrency()), cost.Format(limit.max, est.GetCurrency()),
+			),
+			resources,
+		))
+	}
+
+	return diags
+}
+
+// match returns the first pattern that matches the value.
+func match(patterns []string, val string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, val); err == nil && ok {
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
+
+// violation returns an error diagnostic of a policy violation that lists the offending resources.
+func violation(summary string, detail string, resources []string) *pb.Diagnostic {
+	if len(resources) > 0 {
+		detail += "\n  " + strings.Join(resources, "\n  ")
+	}
+
+	return &pb.Diagnostic{
+		Severity: pb.Diagnostic_SEVERITY_ERROR,
+		Summary:  "policy violation: " + summary,
+		Detail:   detail,
+		Code:     Code,
+	}
+}
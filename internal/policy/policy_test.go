@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+func testResourceChange(address string, typ string, after map[string]any, actions ...tfjson.Action) *tfjson.ResourceChange {
+	return &tfjson.ResourceChange{
+		Address: address,
+		Mode:    tfjson.ManagedResourceMode,
+		Type:    typ,
+		Change: &tfjson.Change{
+			Actions: actions,
+			After:   after,
+		},
+	}
+}
+
+// Test_Evaluate tests evaluating the guardrails of policies against plans.
+func Test_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	plan := &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			testResourceChange("module.network.aws_nat_gateway.this", "aws_nat_gateway", map[string]any{}, tfjson.ActionCreate),
+			testResourceChange("module.network.aws_vpc.this", "aws_vpc", map[string]any{}, tfjson.ActionCreate),
+			testResourceChange("module.target.aws_instance.target[0]", "aws_instance",
+				map[string]any{"instance_type": "m5.metal"}, tfjson.ActionCreate,
+			),
+			testResourceChange("module.target.aws_instance.target[1]", "aws_instance",
+				map[string]any{"instance_type": "t3.small"}, tfjson.ActionCreate,
+			),
+			testResourceChange("module.resized.aws_instance.target", "aws_instance",
+				map[string]any{"instance_type": "p4d.24xlarge"}, tfjson.ActionUpdate,
+			),
+			testResourceChange("module.old.aws_nat_gateway.this", "aws_nat_gateway", nil, tfjson.ActionDelete),
+			testResourceChange("module.same.aws_nat_gateway.this", "aws_nat_gateway", map[string]any{}, tfjson.ActionNoop),
+			testResourceChange("module.vm.google_compute_instance.vm", "google_compute_instance",
+				map[string]any{"machine_type": "a2-highgpu-8g"}, tfjson.ActionDelete, tfjson.ActionCreate,
+			),
+		},
+	}
+
+	est := &pb.Terraform_CostEstimate{
+		Currency: "USD",
+		Hourly:   1.5,
+		Monthly:  1095,
+		Resources: []*pb.Terraform_CostEstimate_Resource{
+			{Address: "module.target.aws_instance.target[0]", Hourly: 1.4, Monthly: 1022},
+			{Address: "module.network.aws_nat_gateway.this", Hourly: 0.1, Monthly: 73},
+			{Address: "module.network.aws_vpc.this"},
+		},
+	}
+
+	for desc, test := range map[string]struct {
+		policy   *pb.Policy
+		est      *pb.Terraform_CostEstimate
+		expected []*pb.Diagnostic
+	}{
+		"no policy": {
+			est: est,
+		},
+		"allowed": {
+			policy: &pb.Policy{
+				DenyResourceTypes: []string{"aws_eip"},
+				DenyInstanceTypes: []string{"*.48xlarge"},
+				MaxMonthlyCost:    2000,
+			},
+			est: &pb.Terraform_CostEstimate{Currency: "USD", Monthly: 1095},
+		},
+		"denied": {
+			policy: &pb.Policy{
+				DenyResourceTypes: []string{"aws_nat_*"},
+				DenyInstanceTypes: []string{"*.metal", "p4d.*", "a2-*"},
+				MaxHourlyCost:     2,
+				MaxMonthlyCost:    1000,
+			},
+			est: est,
+			expected: []*pb.Diagnostic{
+				{
+					Severity: pb.Diagnostic_SEVERITY_ERROR,
+					Summary:  "policy violation: plan creates denied resource types",
+					Detail: "The plan creates resources whose types are denied by the policy:\n" +
+						"  module.network.aws_nat_gateway.this: aws_nat_gateway matches aws_nat_*",
+					Code: Code,
+				},
+				{
+					Severity: pb.Diagnostic_SEVERITY_ERROR,
+					Summary:  "policy violation: plan creates denied instance types",
+					Detail: "The plan creates resources whose instance types are denied by the policy:\n" +
+						"  module.target.aws_instance.target[0]: instance_type m5.metal matches *.metal\n" +
+						"  module.resized.aws_instance.target: instance_type p4d.24xlarge matches p4d.*\n" +
+						"  module.vm.google_compute_instance.vm: machine_type a2-highgpu-8g matches a2-*",
+					Code: Code,
+				},
+				{
+					Severity: pb.Diagnostic_SEVERITY_ERROR,
+					Summary:  "policy violation: plan exceeds the maximum monthly cost",
+					Detail: "The estimated monthly cost of 1095.00 USD exceeds the maximum of 1000.00 USD. The most expensive resources are:\n" +
+						"  module.target.aws_instance.target[0]: 1022.00 USD per month\n" +
+						"  module.network.aws_nat_gateway.this: 73.00 USD per month",
+					Code: Code,
+				},
+			},
+		},
+		"no estimate": {
+			policy: &pb.Policy{MaxMonthlyCost: 1000},
+			expected: []*pb.Diagnostic{
+				{
+					Severity: pb.Diagnostic_SEVERITY_ERROR,
+					Summary:  "unable to enforce the maximum cost of the policy",
+					Detail:   "The cost of the plan could not be estimated",
+					Code:     Code,
+				},
+			},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := Evaluate(test.policy, plan, test.est)
+			require.Len(t, got, len(test.expected))
+			for i := range test.expected {
+				require.Equal(t, test.expected[i].GetSummary(), got[i].GetSummary())
+				require.Equal(t, test.expected[i].GetDetail(), got[i].GetDetail())
+				require.Equal(t, test.expected[i].GetSeverity(), got[i].GetSeverity())
+				require.Equal(t, test.expected[i].GetCode(), got[i].GetCode())
+			}
+		})
+	}
+
+	require.False(t, Enabled(nil))
+	require.True(t, Enabled(&pb.Policy{DenyInstanceTypes: []string{"*.metal"}}))
+	require.False(t, NeedsCost(&pb.Policy{DenyInstanceTypes: []string{"*.metal"}}))
+}
@@ -0,0 +1,213 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Input is the kind of input that Rego policies are evaluated against. Policies for an input are
+// the deny and warn rules of the enos.<input> package, e.g. data.enos.plan.deny.
+type Input string
+
+const (
+	// InputScenario is the decoded scenario.
+	InputScenario Input = "scenario"
+	// InputModule is the generated Terraform module in the JSON configuration syntax.
+	InputModule Input = "module"
+	// InputPlan is the JSON of the Terraform plan.
+	InputPlan Input = "plan"
+)
+
+// HasRego returns whether the policy has Rego policies.
+func HasRego(p *pb.Policy) bool {
+	return len(p.GetRegoPaths()) > 0
+}
+
+// Rego evaluates Rego policies with the opa CLI.
+type Rego struct {
+	binary string
+	paths  []string
+}
+
+// RegoOpt is a functional option for Rego.
+type RegoOpt func(*Rego)
+
+// NewRego returns a new Rego.
+func NewRego(opts ...RegoOpt) *Rego {
+	r := &Rego{
+		binary: "opa",
+		paths:  []string{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// WithRegoBinary sets the path to the opa binary.
+func WithRegoBinary(path string) RegoOpt {
+	return func(r *Rego) {
+		if path != "" {
+			r.binary = path
+		}
+	}
+}
+
+// WithRegoPaths sets the files or directories of the Rego policies.
+func WithRegoPaths(paths ...string) RegoOpt {
+	return func(r *Rego) {
+		r.paths = append(r.paths, paths...)
+	}
+}
+
+// WithRegoPolicy sets the opa binary and Rego policies of the policy.
+func WithRegoPolicy(p *pb.Policy) RegoOpt {
+	return func(r *Rego) {
+		WithRegoBinary(p.GetOpaBinPath())(r)
+		WithRegoPaths(p.GetRegoPaths()...)(r)
+	}
+}
+
+// Evaluate evaluates the policies for the kind of input against the input. Deny rules return
+// error diagnostics and warn rules return warning diagnostics.
+func (r *Rego) Evaluate(ctx context.Context, kind Input, input any) ([]*pb.Diagnostic, error) {
+	if len(r.paths) == 0 {
+		return []*pb.Diagnostic{}, nil
+	}
+
+	in, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling %s policy input: %w", kind, err)
+	}
+
+	args := []string{"eval", "--format", "json", "--stdin-input"}
+	for _, path := range r.paths {
+		args = append(args, "--data", path)
+	}
+	args = append(args, "data.enos."+string(kind))
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	//nolint:gosec // G204 the path of opa is configurable by design.
+	cmd := exec.CommandContext(ctx, r.binary, args...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			// opa writes evaluation and compile errors as JSON to stdout
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("evaluating %s policies with opa: %w: %s", kind, err, msg)
+		}
+
+		return nil, fmt.Errorf("evaluating %s policies with opa: %w", kind, err)
+	}
+
+	return ParseResult(kind, stdout.Bytes())
+}
+
+// result is the subset of the JSON output of opa eval that we use.
+type result struct {
+	Result []struct {
+		Expressions []struct {
+			Value struct {
+				Deny []json.RawMessage `json:"deny"`
+				Warn []json.RawMessage `json:"warn"`
+			} `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+// message is a result of a deny or warn rule. Rules return either a string or an object with a
+// msg and optionally the address of the offending resource.
+type message struct {
+	Msg      string `json:"msg"`
+	Resource string `json:"resource"`
+}
+
+// ParseResult parses the JSON output of opa eval of the enos.<kind> package into diagnostics.
+// Results of the same rule are sorted as opa returns sets in no particular order.
+func ParseResult(kind Input, b []byte) ([]*pb.Diagnostic, error) {
+	out := &result{}
+	if err := json.Unmarshal(b, out); err != nil {
+		return nil, fmt.Errorf("parsing opa result: %w", err)
+	}
+
+	diags := []*pb.Diagnostic{}
+	for _, res := range out.Result {
+		for _, expr := range res.Expressions {
+			for _, rule := range []struct {
+				msgs     []json.RawMessage
+				severity pb.Diagnostic_Severity
+				name     string
+			}{
+				{expr.Value.Deny, pb.Diagnostic_SEVERITY_ERROR, "deny"},
+				{expr.Value.Warn, pb.Diagnostic_SEVERITY_WARNING, "warn"},
+			} {
+				ruleDiags := []*pb.Diagnostic{}
+				for _, raw := range rule.msgs {
+					msg, err := parseMessage(raw)
+					if err != nil {
+						return nil, fmt.Errorf("parsing result of data.enos.%s.%s: %w", kind, rule.name, err)
+					}
+
+					summary := fmt.Sprintf("policy violation: %s policy", kind)
+					if rule.severity == pb.Diagnostic_SEVERITY_WARNING {
+						summary = fmt.Sprintf("policy warning: %s policy", kind)
+					}
+					detail := msg.Msg
+					if msg.Resource != "" {
+						detail = msg.Resource + ": " + detail
+					}
+
+					ruleDiags = append(ruleDiags, &pb.Diagnostic{
+						Severity: rule.severity,
+						Summary:  summary,
+						Detail:   detail,
+						Code:     Code,
+					})
+				}
+
+				slices.SortFunc(ruleDiags, func(a, b *pb.Diagnostic) int {
+					return strings.Compare(a.GetDetail(), b.GetDetail())
+				})
+				diags = append(diags, ruleDiags...)
+			}
+		}
+	}
+
+	return diags, nil
+}
+
+func parseMessage(raw json.RawMessage) (*message, error) {
+	msg := &message{}
+	if err := json.Unmarshal(raw, &msg.Msg); err == nil {
+		return msg, nil
+	}
+
+	if err := json.Unmarshal(raw, msg); err != nil {
+		return nil, fmt.Errorf("results must be strings or objects with a msg: %w", err)
+	}
+
+	if msg.Msg == "" {
+		return nil, fmt.Errorf("result %s does not have a msg", string(raw))
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+var testOPAResult = `{
+  "result": [
+    {
+      "expressions": [
+        {
+          "value": {
+            "deny": [
+              {"msg": "instances must be tagged", "resource": "module.target.aws_instance.target"},
+              "scenarios must not use the us-west-2 region"
+            ],
+            "warn": ["fixtures are preferred to networks in every scenario"],
+            "helper": true
+          },
+          "text": "data.enos.plan",
+          "location": {"row": 1, "col": 1}
+        }
+      ]
+    }
+  ]
+}`
+
+// Test_ParseResult tests parsing the JSON output of opa eval.
+func Test_ParseResult(t *testing.T) {
+	t.Parallel()
+
+	diags, err := ParseResult(InputPlan, []byte(testOPAResult))
+	require.NoError(t, err)
+	require.Len(t, diags, 3)
+	require.Equal(t, pb.Diagnostic_SEVERITY_ERROR, diags[0].GetSeverity())
+	require.Equal(t, "policy violation: plan policy", diags[0].GetSummary())
+	require.Equal(t, "module.target.aws_instance.target: instances must be tagged", diags[0].GetDetail())
+	require.Equal(t, "scenarios must not use the us-west-2 region", diags[1].GetDetail())
+	require.Equal(t, pb.Diagnostic_SEVERITY_WARNING, diags[2].GetSeverity())
+	require.Equal(t, "policy warning: plan policy", diags[2].GetSummary())
+	require.Equal(t, Code, diags[2].GetCode())
+
+	// Packages that don't exist are undefined and have no results
+	diags, err = ParseResult(InputModule, []byte(`{}`))
+	require.NoError(t, err)
+	require.Empty(t, diags)
+
+	_, err = ParseResult(InputModule, []byte(`{"result":[{"expressions":[{"value":{"deny":[{"resource":"x"}]}}]}]}`))
+	require.ErrorContains(t, err, "data.enos.module.deny")
+}
+
+// Test_Rego_Evaluate tests evaluating policies with the opa binary.
+func Test_Rego_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "result.json")
+	require.NoError(t, os.WriteFile(out, []byte(testOPAResult), 0o600))
+	in := filepath.Join(dir, "input.json")
+	bin := filepath.Join(dir, "opa")
+	require.NoError(t, os.WriteFile(bin, []byte(`#!/bin/sh
+if [ "$*" != "eval --format json --stdin-input --data policies --data more data.enos.scenario" ]; then
+  echo "unexpected arguments: $@" >&2
+  exit 2
+fi
+cat > `+in+`
+cat `+out+`
+`), 0o700))
+
+	sc := flightplan.NewScenario()
+	sc.Name = "test"
+	sc.Variants = flightplan.NewVector(flightplan.NewElement("arch", "arm64"))
+	step := flightplan.NewScenarioStep()
+	step.Name = "target"
+	step.Module.Name = "target"
+	step.Module.Source = "./modules/target"
+	step.Module.Attrs = map[string]cty.Value{
+		"instance_type": cty.StringVal("t3.small"),
+		"vpc_id":        cty.UnknownVal(cty.String),
+	}
+	sc.Steps = append(sc.Steps, step)
+
+	diags, err := NewRego(
+		WithRegoBinary(bin),
+		WithRegoPaths("policies", "more"),
+	).Evaluate(context.Background(), InputScenario, ScenarioInput(sc))
+	require.NoError(t, err)
+	require.Len(t, diags, 3)
+
+	b, err := os.ReadFile(in)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "name": "test",
+  "description": "",
+  "uid": "`+sc.UID()+`",
+  "variants": {"arch": "arm64"},
+  "fixtures": [],
+  "terraform_cli": "",
+  "providers": [],
+  "steps": [
+    {
+      "name": "target",
+      "description": "",
+      "depends_on": [],
+      "skip": false,
+      "verifies": [],
+      "providers": [],
+      "module": {"name": "target", "source": "./modules/target", "version": ""},
+      "variables": {"instance_type": "t3.small", "vpc_id": null}
+    }
+  ],
+  "outputs": []
+}`, string(b))
+
+	_, err = NewRego(WithRegoBinary(bin), WithRegoPaths("policies")).Evaluate(context.Background(), InputScenario, nil)
+	require.ErrorContains(t, err, "unexpected arguments")
+
+	// Without policies nothing is evaluated
+	diags, err = NewRego(WithRegoBinary(bin)).Evaluate(context.Background(), InputScenario, nil)
+	require.NoError(t, err)
+	require.Empty(t, diags)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var _ Provisioner = (*Ansible)(nil)
+
+// ansibleVariables are the variables of ansible steps that configure the provisioner rather than
+// being passed to the playbook as extra variables.
+var ansibleVariables = []string{"playbook", "destroy_playbook", "inventory", "ansible_args"}
+
+// Ansible is the ansible provisioner. It runs the playbook variable of the step with
+// ansible-playbook when the step is applied and the optional destroy_playbook variable when it is
+// destroyed. The inventory variable is either the path to an inventory or a list of hosts. Every
+// other variable, except for ansible_args, is passed to the playbook as an extra variable.
+type Ansible struct {
+	binary string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// AnsibleOpt is a functional option for the ansible provisioner.
+type AnsibleOpt func(*Ansible)
+
+// NewAnsible returns a new ansible provisioner.
+func NewAnsible(opts ...AnsibleOpt) *Ansible {
+	a := &Ansible{
+		binary: "ansible-playbook",
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// WithAnsibleBinary sets the path to the ansible-playbook binary.
+func WithAnsibleBinary(path string) AnsibleOpt {
+	return func(a *Ansible) {
+		a.binary = path
+	}
+}
+
+// WithAnsibleOutput sets the writers of the output of ansible-playbook.
+func WithAnsibleOutput(stdout io.Writer, stderr io.Writer) AnsibleOpt {
+	return func(a *Ansible) {
+		a.stdout = stdout
+		a.stderr = stderr
+	}
+}
+
+// Provision runs the playbook of the operation of the request.
+func (a *Ansible) Provision(ctx context.Context, req *Request) (Outputs, error) {
+	name := "playbook"
+	if req.Operation == OperationDestroy {
+		name = "destroy_playbook"
+	}
+
+	playbook, ok, err := req.Path(name)
+	if err != nil || !ok {
+		return Outputs{}, err
+	}
+
+	args := []string{}
+	inventory, err := a.inventory(req)
+	if err != nil {
+		return nil, err
+	}
+	if inventory != "" {
+		args = append(args, "-i", inventory)
+	}
+
+	if raw, ok := req.Variables["ansible_args"]; ok && string(raw) != "null" {
+		extra := []string{}
+		if err := json.Unmarshal(raw, &extra); err != nil {
+			return nil, fmt.Errorf("variable ansible_args of step %s must be a list of strings", req.Step)
+		}
+		args = append(args, extra...)
+	}
+
+	extraVars := map[string]json.RawMessage{}
+	for name, raw := range req.Variables {
+		if !slices.Contains(ansibleVariables, name) {
+			extraVars[name] = raw
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "enos-ansible")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if len(extraVars) > 0 {
+		b, err := json.Marshal(extraVars)
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(dir, "extra_vars.json")
+		if err := os.WriteFile(path, b, 0o600); err != nil {
+			return nil, err
+		}
+		args = append(args, "--extra-vars", "@"+path)
+	}
+
+	cmd := exec.CommandContext(ctx, a.binary, append(args, playbook)...)
+	cmd.Dir = req.BaseDir
+	cmd.Stdout = a.stdout
+	cmd.Stderr = a.stderr
+
+	return runCommand(cmd, req, ansibleVariables...)
+}
+
+// inventory returns the inventory argument of the request. Inventories that are lists of hosts are
+// passed as comma separated hosts with a trailing comma, which ansible parses as a list of hosts.
+func (a *Ansible) inventory(req *Request) (string, error) {
+	raw, ok := req.Variables["inventory"]
+	if !ok || string(raw) == "null" {
+		return "", nil
+	}
+
+	hosts := []string{}
+	if err := json.Unmarshal(raw, &hosts); err == nil {
+		if len(hosts) == 0 {
+			return "", fmt.Errorf("variable inventory of step %s must not be empty", req.Step)
+		}
+
+		return strings.Join(hosts, ",") + ",", nil
+	}
+
+	path, _, err := req.Path("inventory")
+	if err != nil {
+		return "", fmt.Errorf("variable inventory of step %s must be a path or a list of hosts", req.Step)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+var _ Provisioner = (*Exec)(nil)
+
+// Exec is the exec provisioner. It runs the command variable of the step when the step is applied
+// and the optional destroy_command variable when it is destroyed. Commands can either be a string,
+// which is run with the shell, or a list of the program and its arguments.
+type Exec struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// ExecOpt is a functional option for the exec provisioner.
+type ExecOpt func(*Exec)
+
+// NewExec returns a new exec provisioner.
+func NewExec(opts ...ExecOpt) *Exec {
+	e := &Exec{
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// WithExecOutput sets the writers of the output of commands.
+func WithExecOutput(stdout io.Writer, stderr io.Writer) ExecOpt {
+	return func(e *Exec) {
+		e.stdout = stdout
+		e.stderr = stderr
+	}
+}
+
+// Provision runs the command of the operation of the request.
+func (e *Exec) Provision(ctx context.Context, req *Request) (Outputs, error) {
+	name := "command"
+	if req.Operation == OperationDestroy {
+		name = "destroy_command"
+	}
+
+	args, ok, err := commandArgs(req, name)
+	if err != nil || !ok {
+		return Outputs{}, err
+	}
+
+	dir := req.BaseDir
+	if d, ok, err := req.Path("dir"); err != nil {
+		return nil, err
+	} else if ok {
+		dir = d
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = e.stdout
+	cmd.Stderr = e.stderr
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = bytes.NewReader(body)
+
+	return runCommand(cmd, req, "command", "destroy_command", "dir")
+}
+
+// commandArgs returns the program and arguments of the command variable, which is either a string
+// to run with the shell or a list of strings.
+func commandArgs(req *Request, name string) ([]string, bool, error) {
+	raw, ok := req.Variables[name]
+	if !ok || string(raw) == "null" {
+		return nil, false, nil
+	}
+
+	var command string
+	if err := json.Unmarshal(raw, &command); err == nil {
+		if runtime.GOOS == "windows" {
+			return []string{"cmd", "/C", command}, true, nil
+		}
+
+		return []string{"sh", "-c", command}, true, nil
+	}
+
+	args := []string{}
+	if err := json.Unmarshal(raw, &args); err != nil || len(args) == 0 {
+		return nil, true, fmt.Errorf("variable %s of step %s must be a string or a list of strings", name, req.Step)
+	}
+
+	return args, true, nil
+}
+
+// runCommand runs the command with the variables of the request in its environment, except for the
+// excluded variables, and returns the outputs that the command wrote to the outputs file.
+func runCommand(cmd *exec.Cmd, req *Request, exclude ...string) (Outputs, error) {
+	dir, err := os.MkdirTemp("", "enos-provisioner")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	outputsPath := filepath.Join(dir, "outputs.json")
+	cmd.Env = append(os.Environ(), EnvOutputs+"="+outputsPath)
+	cmd.Env = append(cmd.Env, variablesEnv(req, exclude...)...)
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running %s: %w", cmd.Args[0], err)
+	}
+
+	b, err := os.ReadFile(outputsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Outputs{}, nil
+		}
+
+		return nil, err
+	}
+
+	outputs := Outputs{}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return outputs, nil
+	}
+
+	if err := json.Unmarshal(b, &outputs); err != nil {
+		return nil, fmt.Errorf("outputs of step %s must be a JSON object: %w", req.Step, err)
+	}
+
+	return outputs, nil
+}
+
+// variablesEnv returns the variables of the request as environment variables with upper case names.
+// Strings are set as their values, other values as JSON.
+func variablesEnv(req *Request, exclude ...string) []string {
+	env := []string{}
+	for name, raw := range req.Variables {
+		if string(raw) == "null" || slices.Contains(exclude, name) {
+			continue
+		}
+
+		val := string(raw)
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			val = s
+		}
+
+		env = append(env, EnvVarPrefix+strings.ToUpper(name)+"="+val)
+	}
+
+	return env
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package provisioner executes the provisioners of scenario steps that provision with tools other
+// than Terraform, e.g. shell commands or Ansible playbooks.
+//
+// Provisioner steps are generated into Terraform modules whose terraform_data resource executes
+// "enos provisioner apply" when it is created and "enos provisioner destroy" when it is destroyed
+// with the Request of the step in the ENOS_PROVISIONER_REQUEST environment variable. The outputs of
+// the provisioner are written as the outputs of a Terraform state file in the directory of the
+// module, which the module reads with the built-in terraform_remote_state data source.
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Environment variables of provisioners.
+const (
+	// EnvRequest is the environment variable of the JSON request of a step.
+	EnvRequest = "ENOS_PROVISIONER_REQUEST"
+	// EnvOutputs is the environment variable of the path that commands write their outputs to as
+	// a JSON object.
+	EnvOutputs = "ENOS_PROVISIONER_OUTPUTS"
+	// EnvVarPrefix is the prefix of the environment variables of the variables of a step.
+	EnvVarPrefix = "ENOS_PROVISIONER_VAR_"
+)
+
+// OutputsStateFile is the name of the Terraform state file of the outputs of a provisioner.
+const OutputsStateFile = "outputs.tfstate"
+
+// Operations of provisioners.
+const (
+	OperationApply   = "apply"
+	OperationDestroy = "destroy"
+)
+
+// Request is a request to provision a step.
+type Request struct {
+	// Step is the name of the step.
+	Step string `json:"step"`
+	// Provisioner is the type of the provisioner.
+	Provisioner string `json:"provisioner"`
+	// Operation is either apply or destroy.
+	Operation string `json:"operation"`
+	// BaseDir is the directory of the flight plan, which relative paths are relative to.
+	BaseDir string `json:"base_dir"`
+	// Variables are the values of the variables of the step as JSON.
+	Variables map[string]json.RawMessage `json:"variables"`
+}
+
+// Outputs are the outputs of a provisioner as JSON.
+type Outputs map[string]json.RawMessage
+
+// Provisioner provisions steps.
+type Provisioner interface {
+	// Provision provisions the step of the request and returns its outputs.
+	Provision(ctx context.Context, req *Request) (Outputs, error)
+}
+
+// DecodeRequest decodes the JSON request of a step.
+func DecodeRequest(raw string) (*Request, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("%s has not been set", EnvRequest)
+	}
+
+	req := &Request{}
+	if err := json.Unmarshal([]byte(raw), req); err != nil {
+		return nil, fmt.Errorf("decoding provisioner request: %w", err)
+	}
+
+	if req.Variables == nil {
+		req.Variables = map[string]json.RawMessage{}
+	}
+
+	return req, nil
+}
+
+// String returns the value of the variable if it's a string.
+func (r *Request) String(name string) (string, bool, error) {
+	raw, ok := r.Variables[name]
+	if !ok || string(raw) == "null" {
+		return "", false, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", true, fmt.Errorf("variable %s of step %s must be a string", name, r.Step)
+	}
+
+	return s, true, nil
+}
+
+// Path returns the value of the variable as a path relative to the base directory.
+func (r *Request) Path(name string) (string, bool, error) {
+	s, ok, err := r.String(name)
+	if !ok || err != nil || filepath.IsAbs(s) {
+		return s, ok, err
+	}
+
+	return filepath.Join(r.BaseDir, s), true, nil
+}
+
+// Run executes the provisioner for the request in the directory of the module of the step. The
+// outputs of applies are written to the outputs state file of the directory, which destroys remove.
+func Run(ctx context.Context, p Provisioner, req *Request, dir string) error {
+	outputs, err := p.Provision(ctx, req)
+	if err != nil {
+		return fmt.Errorf("provisioning step %s with the %s provisioner: %w", req.Step, req.Provisioner, err)
+	}
+
+	path := filepath.Join(dir, OutputsStateFile)
+	if req.Operation == OperationDestroy {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	return WriteOutputsState(path, outputs)
+}
+
+// WriteOutputsState writes the outputs as the outputs of a Terraform state file to the path.
+func WriteOutputsState(path string, outputs Outputs) error {
+	type stateOutput struct {
+		Value json.RawMessage `json:"value"`
+		Type  json.RawMessage `json:"type"`
+	}
+
+	state := struct {
+		Version          int                     `json:"version"`
+		TerraformVersion string                  `json:"terraform_version"`
+		Serial           int                     `json:"serial"`
+		Lineage          string                  `json:"lineage"`
+		Outputs          map[string]*stateOutput `json:"outputs"`
+		Resources        []any                   `json:"resources"`
+	}{
+		Version:          4,
+		TerraformVersion: "1.4.0",
+		Serial:           1,
+		Lineage:          "enos-provisioner",
+		Outputs:          map[string]*stateOutput{},
+		Resources:        []any{},
+	}
+
+	for name, raw := range outputs {
+		ty, err := ctyjson.ImpliedType(raw)
+		if err != nil {
+			return fmt.Errorf("output %s is not valid JSON: %w", name, err)
+		}
+		if ty == cty.DynamicPseudoType {
+			// null values don't have a type
+			ty = cty.String
+		}
+
+		tyJSON, err := ctyjson.MarshalType(ty)
+		if err != nil {
+			return err
+		}
+
+		state.Outputs[name] = &stateOutput{Value: raw, Type: tyJSON}
+	}
+
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0o600)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testRequest returns a new request for the provisioner and operation with the variables as JSON.
+func testRequest(t *testing.T, provisioner string, op string, vars string) *Request {
+	t.Helper()
+
+	req, err := DecodeRequest(`{"step":"configure","provisioner":"` + provisioner + `","base_dir":"` +
+		filepath.ToSlash(t.TempDir()) + `","variables":` + vars + `}`)
+	require.NoError(t, err)
+	req.Operation = op
+
+	return req
+}
+
+// Test_Exec tests running commands with the exec provisioner.
+func Test_Exec(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("commands require a POSIX shell")
+	}
+
+	t.Run("shell command", func(t *testing.T) {
+		t.Parallel()
+
+		req := testRequest(t, "exec", OperationApply, `{
+			"command": "echo \"$ENOS_PROVISIONER_VAR_REGION\"; pwd; printf '{\"hosts\":%s,\"ok\":true}' \"$ENOS_PROVISIONER_VAR_HOSTS\" > \"$ENOS_PROVISIONER_OUTPUTS\"",
+			"region": "us-east-1",
+			"hosts": ["10.0.0.1","10.0.0.2"]
+		}`)
+		stdout := &bytes.Buffer{}
+		outputs, err := NewExec(WithExecOutput(stdout, &bytes.Buffer{})).Provision(context.Background(), req)
+		require.NoError(t, err)
+
+		dir, err := filepath.EvalSymlinks(req.BaseDir)
+		require.NoError(t, err)
+		require.Equal(t, "us-east-1\n"+dir+"\n", stdout.String())
+		require.Len(t, outputs, 2)
+		require.JSONEq(t, `["10.0.0.1","10.0.0.2"]`, string(outputs["hosts"]))
+		require.JSONEq(t, `true`, string(outputs["ok"]))
+	})
+
+	t.Run("argument list with request on stdin", func(t *testing.T) {
+		t.Parallel()
+
+		req := testRequest(t, "exec", OperationApply, `{"command": ["cat"]}`)
+		stdout := &bytes.Buffer{}
+		outputs, err := NewExec(WithExecOutput(stdout, &bytes.Buffer{})).Provision(context.Background(), req)
+		require.NoError(t, err)
+		require.Empty(t, outputs)
+
+		got := &Request{}
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), got))
+		require.Equal(t, req, got)
+	})
+
+	t.Run("destroy without destroy command", func(t *testing.T) {
+		t.Parallel()
+
+		req := testRequest(t, "exec", OperationDestroy, `{"command": "exit 1"}`)
+		outputs, err := NewExec().Provision(context.Background(), req)
+		require.NoError(t, err)
+		require.Empty(t, outputs)
+	})
+
+	t.Run("failing command", func(t *testing.T) {
+		t.Parallel()
+
+		req := testRequest(t, "exec", OperationApply, `{"command": "exit 3"}`)
+		_, err := NewExec(WithExecOutput(&bytes.Buffer{}, &bytes.Buffer{})).Provision(context.Background(), req)
+		require.ErrorContains(t, err, "exit status 3")
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		t.Parallel()
+
+		req := testRequest(t, "exec", OperationApply, `{"command": 1}`)
+		_, err := NewExec().Provision(context.Background(), req)
+		require.ErrorContains(t, err, "variable command of step configure must be a string or a list of strings")
+	})
+}
+
+// Test_Ansible tests the arguments and extra variables that the ansible provisioner runs
+// ansible-playbook with.
+func Test_Ansible(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ansible-playbook requires a POSIX shell")
+	}
+
+	// The fake ansible-playbook prints its arguments and extra variables and writes an output.
+	binary := filepath.Join(t.TempDir(), "ansible-playbook")
+	require.NoError(t, os.WriteFile(binary, []byte(`#!/bin/sh
+for arg in "$@"; do
+  case "$arg" in
+  @*) cat "${arg#@}"; echo ;;
+  *) echo "$arg" ;;
+  esac
+done
+echo '{"leader":"10.0.0.1"}' > "$ENOS_PROVISIONER_OUTPUTS"
+`), 0o755))
+
+	for desc, test := range map[string]struct {
+		op       string
+		vars     string
+		expected func(base string) string
+		outputs  Outputs
+	}{
+		"hosts": {
+			op:   OperationApply,
+			vars: `{"playbook":"site.yml","inventory":["10.0.0.1","10.0.0.2"],"ansible_args":["--check"],"version":"1.2.3"}`,
+			expected: func(base string) string {
+				return "-i\n10.0.0.1,10.0.0.2,\n--check\n--extra-vars\n{\"version\":\"1.2.3\"}\n" + base + "/site.yml\n"
+			},
+			outputs: Outputs{"leader": json.RawMessage(`"10.0.0.1"`)},
+		},
+		"inventory path": {
+			op:   OperationApply,
+			vars: `{"playbook":"/playbooks/site.yml","inventory":"hosts.ini"}`,
+			expected: func(base string) string {
+				return "-i\n" + base + "/hosts.ini\n/playbooks/site.yml\n"
+			},
+			outputs: Outputs{"leader": json.RawMessage(`"10.0.0.1"`)},
+		},
+		"destroy": {
+			op:   OperationDestroy,
+			vars: `{"playbook":"site.yml","destroy_playbook":"teardown.yml"}`,
+			expected: func(base string) string {
+				return base + "/teardown.yml\n"
+			},
+			outputs: Outputs{"leader": json.RawMessage(`"10.0.0.1"`)},
+		},
+		"destroy without destroy playbook": {
+			op:   OperationDestroy,
+			vars: `{"playbook":"site.yml"}`,
+			expected: func(string) string {
+				return ""
+			},
+			outputs: Outputs{},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := testRequest(t, "ansible", test.op, test.vars)
+			stdout := &bytes.Buffer{}
+			outputs, err := NewAnsible(
+				WithAnsibleBinary(binary),
+				WithAnsibleOutput(stdout, &bytes.Buffer{}),
+			).Provision(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, test.expected(req.BaseDir), stdout.String())
+			require.Equal(t, test.outputs, outputs)
+		})
+	}
+}
+
+// Test_Run tests writing and removing the outputs state file of provisioners.
+func Test_Run(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("commands require a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, OutputsStateFile)
+
+	req := testRequest(t, "exec", OperationApply, `{
+		"command": "echo '{\"hosts\":[\"a\",\"b\"],\"count\":2,\"tags\":{\"env\":\"ci\"},\"none\":null}' > \"$ENOS_PROVISIONER_OUTPUTS\""
+	}`)
+	require.NoError(t, Run(context.Background(), NewExec(), req, dir))
+
+	state, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"version": 4,
+		"terraform_version": "1.4.0",
+		"serial": 1,
+		"lineage": "enos-provisioner",
+		"outputs": {
+			"hosts": {"value": ["a","b"], "type": ["tuple",["string","string"]]},
+			"count": {"value": 2, "type": "number"},
+			"tags": {"value": {"env":"ci"}, "type": ["object",{"env":"string"}]},
+			"none": {"value": null, "type": "string"}
+		},
+		"resources": []
+	}`, string(state))
+
+	req.Operation = OperationDestroy
+	require.NoError(t, Run(context.Background(), NewExec(), req, dir))
+	require.NoFileExists(t, path)
+
+	req.Operation = OperationApply
+	req.Variables["command"] = json.RawMessage(`"exit 1"`)
+	require.ErrorContains(t, Run(context.Background(), NewExec(), req, dir), "provisioning step configure with the exec provisioner")
+}
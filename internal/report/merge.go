@@ -0,0 +1,384 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package report merges the reports of runs, e.g. of the CI shards that each ran a slice of the
+// matrix, into a single consolidated report.
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hashicorp/enos/internal/ci"
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/notify"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// MergeCommand is the name of the command of merged reports.
+const MergeCommand = "enos report merge"
+
+// Merger merges the JSON reports, i.e. the --format json output of scenario check, launch, run,
+// and destroy, and the JUnit reports of runs. Scenarios are identified by their name and variants.
+// A scenario that has been reported more than once passes only if every report of it passed, and
+// is skipped only if every report of it skipped it.
+type Merger struct {
+	failOnWarnings bool
+	expected       []*pb.Ref_Scenario
+	sources        []*pb.Report_Source
+	scenarios      map[string]*mergedScenario
+	diags          []*pb.Diagnostic
+	runCases       []*ci.JUnitTestCase
+}
+
+// mergedScenario is the merged result of the reports of a scenario.
+type mergedScenario struct {
+	scenario *flightplan.Scenario
+	result   *pb.Report_Scenario
+	// testCase is the test case of the first failed report of the scenario, or of the first report
+	// that didn't skip it if none have failed
+	testCase *ci.JUnitTestCase
+	// formats are the number of reports of the scenario of each format that didn't skip it
+	formats map[pb.Report_Format]int
+}
+
+// MergerOpt is a functional option.
+type MergerOpt func(*Merger)
+
+// NewMerger takes options and returns a new Merger.
+func NewMerger(opts ...MergerOpt) *Merger {
+	m := &Merger{
+		sources:   []*pb.Report_Source{},
+		scenarios: map[string]*mergedScenario{},
+		diags:     []*pb.Diagnostic{},
+		runCases:  []*ci.JUnitTestCase{},
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// WithMergerFailOnWarnings sets whether or not scenarios of JSON reports with warnings fail.
+func WithMergerFailOnWarnings(fail bool) MergerOpt {
+	return func(m *Merger) {
+		m.failOnWarnings = fail
+	}
+}
+
+// WithMergerExpected sets the scenarios that the merged reports are expected to cover, e.g. every
+// scenario of the flight plan. Without expected scenarios the coverage isn't determined.
+func WithMergerExpected(scenarios []*pb.Ref_Scenario) MergerOpt {
+	return func(m *Merger) {
+		m.expected = scenarios
+	}
+}
+
+// Add adds the report at the path. JUnit reports are detected by their XML contents, anything
+// else is decoded as a JSON report.
+func (m *Merger) Add(path string, contents []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(contents), []byte("<")) {
+		report := &ci.JUnitTestSuites{}
+		if err := xml.Unmarshal(contents, report); err != nil {
+			return fmt.Errorf("decoding junit report %s: %w", path, err)
+		}
+		m.AddJUnit(path, report)
+
+		return nil
+	}
+
+	res := &pb.OperationResponses{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(contents, res); err != nil {
+		return fmt.Errorf("decoding json report %s: %w", path, err)
+	}
+	m.AddOperationResponses(path, res)
+
+	return nil
+}
+
+// AddDiagnostics adds diagnostics to the merged report, e.g. of reports that can't be read.
+func (m *Merger) AddDiagnostics(diags []*pb.Diagnostic) {
+	m.diags = append(m.diags, diags...)
+}
+
+// AddOperationResponses adds the JSON report of the operation responses at the path. The
+// diagnostics of the run are added to the diagnostics of the merged report.
+func (m *Merger) AddOperationResponses(path string, res *pb.OperationResponses) {
+	m.diags = append(m.diags, diagnostics.Concat(res.GetDecode().GetDiagnostics(), res.GetDiagnostics())...)
+
+	summary := notify.NewSummary(MergeCommand, res, m.failOnWarnings)
+	m.addCases(path, pb.Report_FORMAT_JSON, ci.NewJUnit(summary), false)
+}
+
+// AddJUnit adds the JUnit report at the path. Failed test cases of the errors of runs are added
+// to the diagnostics of the merged report.
+func (m *Merger) AddJUnit(path string, report *ci.JUnitTestSuites) {
+	m.addCases(path, pb.Report_FORMAT_JUNIT, report, true)
+}
+
+func (m *Merger) addCases(path string, format pb.Report_Format, report *ci.JUnitTestSuites, runDiags bool) {
+	source := &pb.Report_Source{Path: path, Format: format}
+	m.sources = append(m.sources, source)
+
+	for _, suite := range report.Suites {
+		for _, tc := range suite.Cases {
+			if tc.ClassName == ci.JUnitRunClassName && tc.Name == suite.Name {
+				if tc.Failure == nil {
+					continue
+				}
+
+				m.runCases = append(m.runCases, tc)
+				if runDiags {
+					m.diags = append(m.diags, &pb.Diagnostic{
+						Severity: pb.Diagnostic_SEVERITY_ERROR,
+						Summary:  tc.Failure.Message,
+						Detail:   fmt.Sprintf("%s failed in %s", suite.Name, path),
+					})
+				}
+
+				continue
+			}
+
+			source.Scenarios++
+			if tc.Failure != nil {
+				source.Failed++
+			}
+			m.addCase(path, format, tc)
+		}
+	}
+}
+
+func (m *Merger) addCase(path string, format pb.Report_Format, tc *ci.JUnitTestCase) {
+	scenario := testCaseScenario(tc)
+	failed := tc.Failure != nil
+	skipped := tc.Skipped != nil
+
+	merged, ok := m.scenarios[scenario.UID()]
+	if !ok {
+		merged = &mergedScenario{
+			scenario: scenario,
+			result: &pb.Report_Scenario{
+				Scenario: scenario.Ref(),
+				Duration: durationpb.New(0),
+				Sources:  []string{},
+				Skipped:  skipped,
+			},
+			testCase: tc,
+			formats:  map[pb.Report_Format]int{},
+		}
+		if skipped {
+			merged.result.SkipReason = tc.Skipped.Message
+		}
+		m.scenarios[scenario.UID()] = merged
+	}
+
+	// Every shard of a run skips the same scenarios so they're never duplicates. A scenario that
+	// has been run by any report isn't skipped.
+	if !skipped {
+		merged.formats[format]++
+		if merged.formats[format] > 1 {
+			merged.result.Duplicate = true
+		}
+		if merged.result.GetSkipped() {
+			merged.result.Skipped = false
+			merged.result.SkipReason = ""
+			merged.testCase = tc
+		}
+	}
+	if !slices.Contains(merged.result.GetSources(), path) {
+		merged.result.Sources = append(merged.result.Sources, path)
+	}
+	if dur := testCaseDuration(tc); dur > merged.result.GetDuration().AsDuration() {
+		merged.result.Duration = durationpb.New(dur)
+	}
+	if failed && !merged.result.GetFailed() {
+		merged.result.Failed = true
+		merged.result.Error = tc.Failure.Message
+		merged.testCase = tc
+	}
+}
+
+// Response returns the merged report. Duplicated scenarios are reported as warnings and missing
+// scenarios of the expected coverage as errors.
+func (m *Merger) Response() *pb.MergeReportsResponse {
+	res := &pb.MergeReportsResponse{
+		Diagnostics: slices.Clone(m.diags),
+		Sources:     m.sources,
+		Scenarios:   []*pb.Report_Scenario{},
+		Summary:     &pb.Report_Summary{Reports: int32(len(m.sources))},
+	}
+
+	for _, merged := range m.sortedScenarios() {
+		res.Scenarios = append(res.Scenarios, merged.result)
+		res.Summary.Scenarios++
+		switch {
+		case merged.result.GetFailed():
+			res.Summary.Failed++
+		case merged.result.GetSkipped():
+			res.Summary.Skipped++
+		default:
+			res.Summary.Passed++
+		}
+
+		if merged.result.GetDuplicate() {
+			res.Summary.Duplicates++
+			res.Diagnostics = append(res.Diagnostics, &pb.Diagnostic{
+				Severity: pb.Diagnostic_SEVERITY_WARNING,
+				Summary:  "duplicated scenario",
+				Detail: fmt.Sprintf("%s has been reported more than once, by %s",
+					merged.scenario.String(), strings.Join(merged.result.GetSources(), ", "),
+				),
+			})
+		}
+	}
+
+	if m.expected != nil {
+		res.Coverage = m.coverage()
+		if missing := len(res.GetCoverage().GetMissing()); missing > 0 {
+			names := []string{}
+			for _, ref := range res.GetCoverage().GetMissing() {
+				scenario := flightplan.NewScenario()
+				scenario.FromRef(ref)
+				names = append(names, scenario.String())
+			}
+
+			res.Diagnostics = append(res.Diagnostics, &pb.Diagnostic{
+				Severity: pb.Diagnostic_SEVERITY_ERROR,
+				Summary:  "incomplete coverage",
+				Detail: fmt.Sprintf("%d of %d scenarios have not been reported: %s",
+					missing, res.GetCoverage().GetExpected(), strings.Join(names, ", "),
+				),
+			})
+		}
+	}
+
+	res.Summary.FailedRun = res.GetSummary().GetFailed() > 0 ||
+		diagnostics.HasFailed(m.failOnWarnings, res.GetDiagnostics())
+
+	return res
+}
+
+// JUnit returns the merged JUnit report. It has a test case for every scenario and the errors of
+// every run.
+func (m *Merger) JUnit() *ci.JUnitTestSuites {
+	suite := &ci.JUnitTestSuite{
+		Name:  MergeCommand,
+		Cases: slices.Clone(m.runCases),
+	}
+
+	var total time.Duration
+	for _, merged := range m.sortedScenarios() {
+		tc := *merged.testCase
+		tc.Time = fmt.Sprintf("%.3f", merged.result.GetDuration().AsDuration().Seconds())
+		total += merged.result.GetDuration().AsDuration()
+		suite.Cases = append(suite.Cases, &tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total.Seconds())
+
+	for _, tc := range suite.Cases {
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+
+	return &ci.JUnitTestSuites{
+		Name:     MergeCommand,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []*ci.JUnitTestSuite{suite},
+	}
+}
+
+// coverage returns the coverage of the expected scenarios.
+func (m *Merger) coverage() *pb.Report_Coverage {
+	coverage := &pb.Report_Coverage{
+		Expected:   int32(len(m.expected)),
+		Missing:    []*pb.Ref_Scenario{},
+		Unexpected: []*pb.Ref_Scenario{},
+	}
+
+	expected := map[string]bool{}
+	for _, ref := range m.expected {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(ref)
+		expected[scenario.UID()] = true
+
+		if _, ok := m.scenarios[scenario.UID()]; ok {
+			coverage.Covered++
+		} else {
+			coverage.Missing = append(coverage.Missing, scenario.Ref())
+		}
+	}
+
+	for _, merged := range m.sortedScenarios() {
+		if !expected[merged.scenario.UID()] {
+			coverage.Unexpected = append(coverage.Unexpected, merged.result.GetScenario())
+		}
+	}
+
+	return coverage
+}
+
+// sortedScenarios returns the merged scenarios sorted by their names and variants.
+func (m *Merger) sortedScenarios() []*mergedScenario {
+	scenarios := make([]*mergedScenario, 0, len(m.scenarios))
+	for _, merged := range m.scenarios {
+		scenarios = append(scenarios, merged)
+	}
+	slices.SortFunc(scenarios, func(a, b *mergedScenario) int {
+		return strings.Compare(a.scenario.String(), b.scenario.String())
+	})
+
+	return scenarios
+}
+
+// testCaseScenario returns the scenario of a test case. The class name is the name of the scenario
+// and the name is the name and variants of the scenario, e.g. upgrade [arch:amd64 distro:ubuntu].
+func testCaseScenario(tc *ci.JUnitTestCase) *flightplan.Scenario {
+	scenario := flightplan.NewScenario()
+	scenario.Name = tc.ClassName
+
+	variants := strings.TrimSpace(strings.TrimPrefix(tc.Name, tc.ClassName))
+	if !strings.HasPrefix(variants, "[") || !strings.HasSuffix(variants, "]") {
+		if variants != "" {
+			scenario.Name = tc.Name
+		}
+
+		return scenario
+	}
+
+	scenario.Variants = flightplan.NewVector()
+	for _, elm := range strings.Fields(strings.Trim(variants, "[]")) {
+		key, val, _ := strings.Cut(elm, ":")
+		scenario.Variants.Add(flightplan.NewElement(key, val))
+	}
+
+	return scenario
+}
+
+// testCaseDuration returns the duration of a test case.
+func testCaseDuration(tc *ci.JUnitTestCase) time.Duration {
+	secs, err := strconv.ParseFloat(tc.Time, 64)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(secs * float64(time.Second))
+}
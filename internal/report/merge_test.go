@@ -0,0 +1,263 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/hashicorp/enos/internal/ci"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/notify"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// testScenario returns the scenario with the name and arch variant.
+func testScenario(name string, arch string) *flightplan.Scenario {
+	scenario := flightplan.NewScenario()
+	scenario.Name = name
+	scenario.Variants = flightplan.NewVector()
+	scenario.Variants.Add(flightplan.NewElement("arch", arch))
+
+	return scenario
+}
+
+// testResponse returns the operation response of the scenario that took the duration.
+func testResponse(scenario *flightplan.Scenario, dur time.Duration, errSummary string) *pb.Operation_Response {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	res := &pb.Operation_Response{
+		Op:          &pb.Ref_Operation{Scenario: scenario.Ref()},
+		Status:      pb.Operation_STATUS_COMPLETED,
+		StartedAt:   timestamppb.New(start),
+		CompletedAt: timestamppb.New(start.Add(dur)),
+	}
+	if errSummary != "" {
+		res.Status = pb.Operation_STATUS_FAILED
+		res.Diagnostics = []*pb.Diagnostic{{Severity: pb.Diagnostic_SEVERITY_ERROR, Summary: errSummary}}
+	}
+
+	return res
+}
+
+// testJSON returns the JSON report of the responses.
+func testJSON(t *testing.T, responses ...*pb.Operation_Response) []byte {
+	t.Helper()
+
+	b, err := protojson.Marshal(&pb.OperationResponses{Responses: responses})
+	require.NoError(t, err)
+
+	return b
+}
+
+// testJUnitXML returns the JUnit report of the responses.
+func testJUnitXML(t *testing.T, responses ...*pb.Operation_Response) []byte {
+	t.Helper()
+
+	summary := notify.NewSummary("enos scenario run", &pb.OperationResponses{Responses: responses}, false)
+	b := &bytes.Buffer{}
+	require.NoError(t, ci.NewJUnit(summary).Write(b))
+
+	return b.Bytes()
+}
+
+// Test_Merger tests merging the JSON and JUnit reports of shards.
+func Test_Merger(t *testing.T) {
+	t.Parallel()
+
+	smokeAMD := testScenario("smoke", "amd64")
+	smokeARM := testScenario("smoke", "arm64")
+	upgradeAMD := testScenario("upgrade", "amd64")
+	upgradeARM := testScenario("upgrade", "arm64")
+
+	m := NewMerger(WithMergerExpected([]*pb.Ref_Scenario{
+		smokeAMD.Ref(), smokeARM.Ref(), upgradeAMD.Ref(), upgradeARM.Ref(),
+	}))
+	require.NoError(t, m.Add("shard-1/report.json", testJSON(t,
+		testResponse(smokeAMD, 30*time.Second, ""),
+		testResponse(upgradeAMD, 90*time.Second, "timeout waiting for cluster"),
+	)))
+	require.NoError(t, m.Add("shard-2/enos-junit.xml", testJUnitXML(t,
+		testResponse(smokeARM, 40*time.Second, ""),
+	)))
+	// The retry of a shard that reported a scenario again
+	require.NoError(t, m.Add("shard-1-retry/report.json", testJSON(t,
+		testResponse(smokeAMD, 45*time.Second, ""),
+	)))
+	require.Error(t, m.Add("shard-3/report.json", []byte("{not json")))
+
+	res := m.Response()
+	require.Equal(t, []*pb.Report_Source{
+		{Path: "shard-1/report.json", Format: pb.Report_FORMAT_JSON, Scenarios: 2, Failed: 1},
+		{Path: "shard-2/enos-junit.xml", Format: pb.Report_FORMAT_JUNIT, Scenarios: 1},
+		{Path: "shard-1-retry/report.json", Format: pb.Report_FORMAT_JSON, Scenarios: 1},
+	}, res.GetSources())
+
+	require.Len(t, res.GetScenarios(), 3)
+	smoke := res.GetScenarios()[0]
+	require.Equal(t, smokeAMD.UID(), smoke.GetScenario().GetId().GetUid())
+	require.True(t, smoke.GetDuplicate())
+	require.False(t, smoke.GetFailed())
+	require.Equal(t, 45*time.Second, smoke.GetDuration().AsDuration())
+	require.Equal(t, []string{"shard-1/report.json", "shard-1-retry/report.json"}, smoke.GetSources())
+	require.Equal(t, smokeARM.UID(), res.GetScenarios()[1].GetScenario().GetId().GetUid())
+	require.False(t, res.GetScenarios()[1].GetDuplicate())
+	upgrade := res.GetScenarios()[2]
+	require.Equal(t, upgradeAMD.UID(), upgrade.GetScenario().GetId().GetUid())
+	require.True(t, upgrade.GetFailed())
+	require.Equal(t, "timeout waiting for cluster", upgrade.GetError())
+
+	require.Equal(t, &pb.Report_Summary{
+		Reports:    3,
+		Scenarios:  3,
+		Passed:     2,
+		Failed:     1,
+		Duplicates: 1,
+		FailedRun:  true,
+	}, res.GetSummary())
+
+	require.Equal(t, int32(4), res.GetCoverage().GetExpected())
+	require.Equal(t, int32(3), res.GetCoverage().GetCovered())
+	require.Len(t, res.GetCoverage().GetMissing(), 1)
+	require.Equal(t, upgradeARM.UID(), res.GetCoverage().GetMissing()[0].GetId().GetUid())
+	require.Empty(t, res.GetCoverage().GetUnexpected())
+
+	require.Len(t, res.GetDiagnostics(), 2)
+	require.Equal(t, "duplicated scenario", res.GetDiagnostics()[0].GetSummary())
+	require.Equal(t, pb.Diagnostic_SEVERITY_WARNING, res.GetDiagnostics()[0].GetSeverity())
+	require.Equal(t, "incomplete coverage", res.GetDiagnostics()[1].GetSummary())
+	require.Equal(t, "1 of 4 scenarios have not been reported: upgrade [arch:arm64]", res.GetDiagnostics()[1].GetDetail())
+
+	junit := m.JUnit()
+	require.Equal(t, 3, junit.Tests)
+	require.Equal(t, 1, junit.Failures)
+	require.Equal(t, "175.000", junit.Time)
+	cases := junit.Suites[0].Cases
+	require.Equal(t, "smoke [arch:amd64]", cases[0].Name)
+	require.Equal(t, "45.000", cases[0].Time)
+	require.Equal(t, "upgrade [arch:amd64]", cases[2].Name)
+	require.Equal(t, "timeout waiting for cluster", cases[2].Failure.Message)
+}
+
+// Test_Merger_Skipped tests merging reports of shards that skipped scenarios.
+func Test_Merger_Skipped(t *testing.T) {
+	t.Parallel()
+
+	smokeAMD := testScenario("smoke", "amd64")
+	smokeARM := testScenario("smoke", "arm64")
+	upgradeARM := testScenario("upgrade", "arm64")
+	skippedJSON := func(responses []*pb.Operation_Response, skipped ...*flightplan.Scenario) []byte {
+		res := &pb.OperationResponses{Responses: responses, Decode: &pb.DecodeResponse{}}
+		for _, s := range skipped {
+			res.Decode.Skipped = append(res.Decode.Skipped, &pb.Scenario_Skipped{
+				Scenario: s.Ref(),
+				Reason:   "known bad",
+			})
+		}
+		b, err := protojson.Marshal(res)
+		require.NoError(t, err)
+
+		return b
+	}
+
+	m := NewMerger(WithMergerExpected([]*pb.Ref_Scenario{smokeAMD.Ref(), smokeARM.Ref(), upgradeARM.Ref()}))
+	require.NoError(t, m.Add("shard-1/report.json", skippedJSON(
+		[]*pb.Operation_Response{testResponse(smokeAMD, 30*time.Second, "")}, smokeARM, upgradeARM,
+	)))
+	require.NoError(t, m.Add("shard-2/report.json", skippedJSON(nil, upgradeARM)))
+	// A report that ran a scenario that another report skipped
+	require.NoError(t, m.Add("shard-3/enos-junit.xml", testJUnitXML(t,
+		testResponse(smokeARM, 40*time.Second, ""),
+	)))
+
+	res := m.Response()
+	require.Len(t, res.GetScenarios(), 3)
+	require.False(t, res.GetScenarios()[1].GetSkipped())
+	require.Empty(t, res.GetScenarios()[1].GetSkipReason())
+	upgrade := res.GetScenarios()[2]
+	require.Equal(t, upgradeARM.UID(), upgrade.GetScenario().GetId().GetUid())
+	require.True(t, upgrade.GetSkipped())
+	require.Equal(t, "known bad", upgrade.GetSkipReason())
+	require.False(t, upgrade.GetDuplicate())
+	require.False(t, upgrade.GetFailed())
+
+	require.Equal(t, &pb.Report_Summary{
+		Reports:   3,
+		Scenarios: 3,
+		Passed:    2,
+		Skipped:   1,
+	}, res.GetSummary())
+	require.Equal(t, int32(3), res.GetCoverage().GetCovered())
+	require.Empty(t, res.GetDiagnostics())
+
+	junit := m.JUnit()
+	require.Equal(t, 3, junit.Tests)
+	require.Equal(t, 1, junit.Skipped)
+	cases := junit.Suites[0].Cases
+	require.Nil(t, cases[1].Skipped)
+	require.Equal(t, &ci.JUnitSkipped{Message: "known bad"}, cases[2].Skipped)
+}
+
+// Test_Merger_RunErrors tests that errors of runs fail the merged report.
+func Test_Merger_RunErrors(t *testing.T) {
+	t.Parallel()
+
+	failedJUnit := &bytes.Buffer{}
+	require.NoError(t, ci.NewJUnit(&notify.Summary{
+		Command: "enos scenario run",
+		Errors:  []string{"unable to decode scenarios"},
+	}).Write(failedJUnit))
+
+	m := NewMerger()
+	require.NoError(t, m.Add("shard-1/enos-junit.xml", failedJUnit.Bytes()))
+	require.NoError(t, m.Add("shard-2/report.json", []byte(
+		`{"diagnostics":[{"severity":"SEVERITY_ERROR","summary":"unable to lock"}],"unknown_field":true}`,
+	)))
+
+	res := m.Response()
+	require.Nil(t, res.GetCoverage())
+	require.Equal(t, int32(0), res.GetSummary().GetScenarios())
+	require.True(t, res.GetSummary().GetFailedRun())
+	require.Len(t, res.GetDiagnostics(), 2)
+	require.Equal(t, "unable to decode scenarios", res.GetDiagnostics()[0].GetSummary())
+	require.Equal(t, "enos scenario run failed in shard-1/enos-junit.xml", res.GetDiagnostics()[0].GetDetail())
+	require.Equal(t, "unable to lock", res.GetDiagnostics()[1].GetSummary())
+
+	junit := m.JUnit()
+	require.Equal(t, 2, junit.Failures)
+	require.Equal(t, ci.JUnitRunClassName, junit.Suites[0].Cases[0].ClassName)
+}
+
+// Test_testCaseScenario tests parsing the scenarios of test cases.
+func Test_testCaseScenario(t *testing.T) {
+	t.Parallel()
+
+	for name, test := range map[string]struct {
+		tc       *ci.JUnitTestCase
+		expected string
+	}{
+		"variants": {
+			&ci.JUnitTestCase{Name: "upgrade [arch:amd64 distro:ubuntu]", ClassName: "upgrade"},
+			"upgrade [arch:amd64 distro:ubuntu]",
+		},
+		"no variants": {
+			&ci.JUnitTestCase{Name: "smoke", ClassName: "smoke"},
+			"smoke",
+		},
+		"foreign": {
+			&ci.JUnitTestCase{Name: "TestSmoke", ClassName: "github.com/hashicorp/enos"},
+			"TestSmoke",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.expected, testCaseScenario(test.tc).String())
+		})
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+const (
+	// DefaultDurationThreshold is the default change of the duration of a scenario relative to its
+	// baseline that is a regression.
+	DefaultDurationThreshold = 0.2
+	// DefaultMinDurationRegression is the default minimum increase of the duration of a scenario
+	// that is a regression, so that small scenarios don't regress because of noise.
+	DefaultMinDurationRegression = 30 * time.Second
+)
+
+// Trends compares the merged report of a run with the merged reports of previous runs.
+type Trends struct {
+	threshold   float64
+	minDuration time.Duration
+}
+
+// TrendsOpt is a functional option.
+type TrendsOpt func(*Trends)
+
+// NewTrends takes options and returns a new Trends.
+func NewTrends(opts ...TrendsOpt) *Trends {
+	t := &Trends{
+		threshold:   DefaultDurationThreshold,
+		minDuration: DefaultMinDurationRegression,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// WithTrendsDurationThreshold sets the change of the duration relative to the baseline that is a
+// regression, e.g. 0.2 for 20% slower.
+func WithTrendsDurationThreshold(threshold float64) TrendsOpt {
+	return func(t *Trends) {
+		t.threshold = threshold
+	}
+}
+
+// WithTrendsMinDuration sets the minimum increase of the duration that is a regression.
+func WithTrendsMinDuration(d time.Duration) TrendsOpt {
+	return func(t *Trends) {
+		t.minDuration = d
+	}
+}
+
+// Compare compares the current run with the previous runs, which are ordered from the most recent.
+// Scenarios of previous runs that are not in the current run are ignored.
+func (t *Trends) Compare(current *pb.MergeReportsResponse, previous ...*pb.MergeReportsResponse) *pb.ReportTrendsResponse {
+	res := &pb.ReportTrendsResponse{
+		Diagnostics:         []*pb.Diagnostic{},
+		Runs:                int32(1 + len(previous)),
+		NewlyFailing:        []*pb.Report_Trend{},
+		NewlyFixed:          []*pb.Report_Trend{},
+		DurationRegressions: []*pb.Report_Trend{},
+		Flaky:               []*pb.Report_Trend{},
+	}
+
+	runs := make([]map[string]*pb.Report_Scenario, len(previous))
+	for i, run := range previous {
+		runs[i] = map[string]*pb.Report_Scenario{}
+		for _, s := range run.GetScenarios() {
+			if s.GetSkipped() {
+				continue
+			}
+			runs[i][s.GetScenario().GetId().GetUid()] = s
+		}
+	}
+
+	for _, cur := range current.GetScenarios() {
+		// Skipped scenarios haven't run so they have no trend
+		if cur.GetSkipped() {
+			continue
+		}
+
+		history := []*pb.Report_Scenario{cur}
+		for _, run := range runs {
+			if s, ok := run[cur.GetScenario().GetId().GetUid()]; ok {
+				history = append(history, s)
+			}
+		}
+
+		trend := newTrend(history)
+
+		if len(history) > 1 {
+			prev := history[1]
+			switch {
+			case cur.GetFailed() && !prev.GetFailed():
+				res.NewlyFailing = append(res.NewlyFailing, trend)
+			case !cur.GetFailed() && prev.GetFailed():
+				res.NewlyFixed = append(res.NewlyFixed, trend)
+			}
+		}
+
+		baseline := trend.GetBaseline().AsDuration()
+		increase := cur.GetDuration().AsDuration() - baseline
+		if !cur.GetFailed() && baseline > 0 && trend.GetDurationChange() > t.threshold && increase >= t.minDuration {
+			res.DurationRegressions = append(res.DurationRegressions, trend)
+		}
+
+		if trend.GetFlips() > 1 {
+			res.Flaky = append(res.Flaky, trend)
+		}
+	}
+
+	slices.SortStableFunc(res.DurationRegressions, func(a, b *pb.Report_Trend) int {
+		return cmp.Compare(b.GetDurationChange(), a.GetDurationChange())
+	})
+	slices.SortStableFunc(res.Flaky, func(a, b *pb.Report_Trend) int {
+		return cmp.Compare(b.GetFlakiness(), a.GetFlakiness())
+	})
+
+	return res
+}
+
+// newTrend returns the trend of the history of a scenario, which starts with the current run.
+func newTrend(history []*pb.Report_Scenario) *pb.Report_Trend {
+	cur := history[0]
+	trend := &pb.Report_Trend{
+		Scenario: cur.GetScenario(),
+		Failed:   cur.GetFailed(),
+		Error:    cur.GetError(),
+		Duration: cur.GetDuration(),
+		Runs:     int32(len(history)),
+	}
+
+	durations := []time.Duration{}
+	for i, s := range history {
+		if s.GetFailed() {
+			trend.Failures++
+		}
+		if i > 0 && s.GetFailed() != history[i-1].GetFailed() {
+			trend.Flips++
+		}
+		if i > 0 && !s.GetFailed() && s.GetDuration() != nil {
+			durations = append(durations, s.GetDuration().AsDuration())
+		}
+	}
+
+	if len(history) > 1 {
+		trend.Flakiness = float64(trend.GetFlips()) / float64(len(history)-1)
+	}
+
+	if len(durations) > 0 {
+		slices.Sort(durations)
+		baseline := durations[len(durations)/2]
+		if len(durations)%2 == 0 {
+			baseline = (durations[len(durations)/2-1] + baseline) / 2
+		}
+		trend.Baseline = durationpb.New(baseline)
+		if baseline > 0 {
+			trend.DurationChange = float64(cur.GetDuration().AsDuration()-baseline) / float64(baseline)
+		}
+	}
+
+	return trend
+}
+
+// TrendsMarkdown returns the trends as GitHub flavored Markdown, e.g. for the job summary of a
+// workflow.
+func TrendsMarkdown(res *pb.ReportTrendsResponse) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "### Enos trends: %d newly failing, %d newly fixed, %d duration regressions, %d flaky\n\n",
+		len(res.GetNewlyFailing()), len(res.GetNewlyFixed()), len(res.GetDurationRegressions()), len(res.GetFlaky()),
+	)
+	fmt.Fprintf(b, "Compared %d runs.\n", res.GetRuns())
+
+	if len(res.GetNewlyFailing()) > 0 {
+		b.WriteString("\n#### Newly failing\n\n| Scenario | Error |\n| --- | --- |\n")
+		for _, trend := range res.GetNewlyFailing() {
+			fmt.Fprintf(b, "| `%s` | %s |\n", trendScenario(trend), markdownCell(trend.GetError()))
+		}
+	}
+
+	if len(res.GetNewlyFixed()) > 0 {
+		b.WriteString("\n#### Newly fixed\n\n| Scenario |\n| --- |\n")
+		for _, trend := range res.GetNewlyFixed() {
+			fmt.Fprintf(b, "| `%s` |\n", trendScenario(trend))
+		}
+	}
+
+	if len(res.GetDurationRegressions()) > 0 {
+		b.WriteString("\n#### Duration regressions\n\n| Scenario | Duration | Baseline | Change |\n| --- | --- | --- | --- |\n")
+		for _, trend := range res.GetDurationRegressions() {
+			fmt.Fprintf(b, "| `%s` | %s | %s | +%.0f%% |\n",
+				trendScenario(trend),
+				trend.GetDuration().AsDuration().Round(time.Second),
+				trend.GetBaseline().AsDuration().Round(time.Second),
+				trend.GetDurationChange()*100,
+			)
+		}
+	}
+
+	if len(res.GetFlaky()) > 0 {
+		b.WriteString("\n#### Flaky\n\n| Scenario | Runs | Failures | Flakiness |\n| --- | --- | --- | --- |\n")
+		for _, trend := range res.GetFlaky() {
+			fmt.Fprintf(b, "| `%s` | %d | %d | %.0f%% |\n",
+				trendScenario(trend), trend.GetRuns(), trend.GetFailures(), trend.GetFlakiness()*100,
+			)
+		}
+	}
+
+	return b.String()
+}
+
+// trendScenario returns the name and variants of the scenario of the trend.
+func trendScenario(trend *pb.Report_Trend) string {
+	scenario := flightplan.NewScenario()
+	scenario.FromRef(trend.GetScenario())
+
+	return scenario.String()
+}
+
+// markdownCell escapes text so that it stays within a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", `\|`)
+}
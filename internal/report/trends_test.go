@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// testRun returns the merged report of a run with the results of scenarios.
+func testRun(results ...*pb.Report_Scenario) *pb.MergeReportsResponse {
+	return &pb.MergeReportsResponse{Scenarios: results}
+}
+
+// testResult returns the merged result of a scenario.
+func testResult(name string, arch string, failed bool, dur time.Duration) *pb.Report_Scenario {
+	res := &pb.Report_Scenario{
+		Scenario: testScenario(name, arch).Ref(),
+		Failed:   failed,
+		Duration: durationpb.New(dur),
+	}
+	if failed {
+		res.Error = "timeout waiting for cluster"
+	}
+
+	return res
+}
+
+// Test_Trends_Compare tests comparing a run with previous runs.
+func Test_Trends_Compare(t *testing.T) {
+	t.Parallel()
+
+	current := testRun(
+		testResult("fixed", "amd64", false, time.Minute),
+		testResult("flaky", "amd64", true, time.Minute),
+		testResult("new", "amd64", true, time.Minute),
+		testResult("regressed", "amd64", true, time.Minute),
+		testResult("slow", "amd64", false, 10*time.Minute),
+		testResult("slow", "arm64", false, 70*time.Second),
+	)
+	previous := []*pb.MergeReportsResponse{
+		testRun(
+			testResult("fixed", "amd64", true, time.Minute),
+			testResult("flaky", "amd64", false, time.Minute),
+			testResult("regressed", "amd64", false, time.Minute),
+			testResult("slow", "amd64", false, 5*time.Minute),
+			testResult("slow", "arm64", false, time.Minute),
+		),
+		testRun(
+			testResult("flaky", "amd64", true, time.Minute),
+			testResult("slow", "amd64", false, 6*time.Minute),
+			testResult("slow", "arm64", false, time.Minute),
+			testResult("removed", "amd64", true, time.Minute),
+		),
+		testRun(
+			testResult("flaky", "amd64", false, time.Minute),
+			testResult("slow", "amd64", false, 20*time.Minute),
+		),
+	}
+
+	res := NewTrends().Compare(current, previous...)
+	require.Equal(t, int32(4), res.GetRuns())
+
+	names := func(trends []*pb.Report_Trend) []string {
+		n := []string{}
+		for _, trend := range trends {
+			n = append(n, trendScenario(trend))
+		}
+
+		return n
+	}
+	require.Equal(t, []string{"flaky [arch:amd64]", "regressed [arch:amd64]"}, names(res.GetNewlyFailing()))
+	require.Equal(t, []string{"fixed [arch:amd64]"}, names(res.GetNewlyFixed()))
+	// arm64 is 17% slower and only 10s slower, neither of which is a regression
+	require.Equal(t, []string{"slow [arch:amd64]"}, names(res.GetDurationRegressions()))
+	require.Equal(t, []string{"flaky [arch:amd64]"}, names(res.GetFlaky()))
+
+	slow := res.GetDurationRegressions()[0]
+	require.Equal(t, 6*time.Minute, slow.GetBaseline().AsDuration())
+	require.InDelta(t, 0.667, slow.GetDurationChange(), 0.001)
+
+	flaky := res.GetFlaky()[0]
+	require.Equal(t, int32(4), flaky.GetRuns())
+	require.Equal(t, int32(2), flaky.GetFailures())
+	require.Equal(t, int32(3), flaky.GetFlips())
+	require.InDelta(t, 1.0, flaky.GetFlakiness(), 0.001)
+
+	res = NewTrends(WithTrendsDurationThreshold(0.1), WithTrendsMinDuration(5*time.Second)).Compare(current, previous...)
+	require.Equal(t, []string{"slow [arch:amd64]", "slow [arch:arm64]"}, names(res.GetDurationRegressions()))
+
+	md := TrendsMarkdown(res)
+	require.Contains(t, md, "### Enos trends: 2 newly failing, 1 newly fixed, 2 duration regressions, 1 flaky\n\nCompared 4 runs.\n")
+	require.Contains(t, md, "| `regressed [arch:amd64]` | timeout waiting for cluster |\n")
+	require.Contains(t, md, "| `slow [arch:amd64]` | 10m0s | 6m0s | +67% |\n")
+	require.Contains(t, md, "| `flaky [arch:amd64]` | 4 | 2 | 100% |\n")
+}
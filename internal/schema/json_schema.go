@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// jsonSchemaDialect is the JSON Schema dialect of the generated schemas.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns the JSON Schema of the response of the command. The schema describes the
+// protojson encoding of the response message. Every message and enum is a definition that is
+// referenced by its full proto name. Objects allow additional properties as new fields can be
+// added without changing the schema version.
+func (c *Command) JSONSchema() ([]byte, error) {
+	if c == nil || c.Message == nil {
+		return nil, errors.New("command has no response")
+	}
+
+	gen := &jsonSchemaGenerator{defs: map[string]any{}}
+	desc := c.Message.ProtoReflect().Descriptor()
+
+	title := "enos " + c.Name
+	if c.Stream {
+		title += " --stream"
+	}
+
+	schema := map[string]any{
+		"$schema":     jsonSchemaDialect,
+		"title":       title,
+		"description": fmt.Sprintf("The JSON output of %s, schema version %s", title, Version),
+		"$ref":        gen.ref(desc),
+		"$defs":       gen.defs,
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// jsonSchemaGenerator generates the definitions of proto messages and enums.
+type jsonSchemaGenerator struct {
+	defs map[string]any
+}
+
+// ref returns the reference to the definition of the message, generating it if necessary.
+func (g *jsonSchemaGenerator) ref(desc protoreflect.MessageDescriptor) string {
+	name := string(desc.FullName())
+	if _, ok := g.defs[name]; !ok {
+		// Reserve the definition before generating it so that recursive messages terminate.
+		g.defs[name] = nil
+		g.defs[name] = g.message(desc)
+	}
+
+	return "#/$defs/" + name
+}
+
+// message returns the schema of the message.
+func (g *jsonSchemaGenerator) message(desc protoreflect.MessageDescriptor) map[string]any {
+	if wkt, ok := wellKnownTypeSchema(desc.FullName()); ok {
+		return wkt
+	}
+
+	props := map[string]any{}
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		props[fd.JSONName()] = g.field(fd)
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+}
+
+// field returns the schema of the field.
+func (g *jsonSchemaGenerator) field(fd protoreflect.FieldDescriptor) map[string]any {
+	switch {
+	case fd.IsMap():
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": g.singular(fd.MapValue()),
+		}
+	case fd.IsList():
+		return map[string]any{
+			"type":  "array",
+			"items": g.singular(fd),
+		}
+	default:
+		return g.singular(fd)
+	}
+}
+
+// singular returns the schema of a single value of the field.
+func (g *jsonSchemaGenerator) singular(fd protoreflect.FieldDescriptor) map[string]any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson encodes 64-bit integers as strings
+		return map[string]any{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]any{"type": "number"}
+	case protoreflect.StringKind:
+		return map[string]any{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.EnumKind:
+		return map[string]any{"$ref": g.enumRef(fd.Enum())}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return map[string]any{"$ref": g.ref(fd.Message())}
+	default:
+		return map[string]any{}
+	}
+}
+
+// enumRef returns the reference to the definition of the enum, generating it if necessary.
+func (g *jsonSchemaGenerator) enumRef(desc protoreflect.EnumDescriptor) string {
+	name := string(desc.FullName())
+	if _, ok := g.defs[name]; !ok {
+		values := []string{}
+		for i := range desc.Values().Len() {
+			values = append(values, string(desc.Values().Get(i).Name()))
+		}
+		g.defs[name] = map[string]any{
+			"type": "string",
+			"enum": values,
+		}
+	}
+
+	return "#/$defs/" + name
+}
+
+// wellKnownTypeSchema returns the schema of well known types, which have a special protojson
+// encoding.
+func wellKnownTypeSchema(name protoreflect.FullName) (map[string]any, bool) {
+	switch name {
+	case "google.protobuf.Duration":
+		return map[string]any{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`}, true
+	case "google.protobuf.Timestamp":
+		return map[string]any{"type": "string", "format": "date-time"}, true
+	case "google.protobuf.Struct":
+		return map[string]any{"type": "object"}, true
+	case "google.protobuf.ListValue":
+		return map[string]any{"type": "array"}, true
+	case "google.protobuf.Value":
+		return map[string]any{}, true
+	default:
+		return nil, false
+	}
+}
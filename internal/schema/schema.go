@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package schema describes the JSON output of enos commands. Every response that is written with
+// --format json is stamped with the schema Version and the JSON Schema of every response can be
+// generated from its proto message.
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Version is the version of the JSON output schema of every command. The schema only evolves in
+// backwards compatible ways: fields, enum values, and commands can be added but never removed,
+// renamed, or given a different type or meaning. Consumers must ignore fields that they don't
+// know. Any incompatible change requires a new version.
+const Version = "1"
+
+// versionField is the name of the field of response messages that is stamped with the Version.
+const versionField = "schema_version"
+
+// Command is an enos command that writes a JSON response.
+type Command struct {
+	// Name is the name of the command, e.g. "scenario list"
+	Name string
+	// Stream is whether or not the response is streamed, one message per line, with --stream
+	Stream  bool
+	Message proto.Message
+}
+
+var commands = []*Command{
+	{Name: "fix", Message: &pb.FixScenariosResponse{}},
+	{Name: "flightplan export", Message: &pb.ExportFlightPlanResponse{}},
+	{Name: "flightplan verify", Message: &pb.VerifyFlightPlanResponse{}},
+	{Name: "fmt", Message: &pb.FormatResponse{}},
+	{Name: "lint", Message: &pb.LintScenariosResponse{}},
+	{Name: "migrate out-dir", Message: &pb.MigrateOutDirResponse{}},
+	{Name: "operation diff", Message: &pb.DiffOperationsResponse{}},
+	{Name: "operation list", Message: &pb.ListOperationsResponse{}},
+	{Name: "operation show", Message: &pb.ShowOperationResponse{}},
+	{Name: "report merge", Message: &pb.MergeReportsResponse{}},
+	{Name: "report trends", Message: &pb.ReportTrendsResponse{}},
+	{Name: "scenario benchmark", Message: &pb.BenchmarkScenariosResponse{}},
+	{Name: "scenario check", Message: &pb.OperationResponses{}},
+	{Name: "scenario coverage", Message: &pb.CheckQualityCoverageResponse{}},
+	{Name: "scenario destroy", Message: &pb.OperationResponses{}},
+	{Name: "scenario exec", Message: &pb.OperationResponses{}},
+	{Name: "scenario fetch", Message: &pb.FetchScenarioModulesResponse{}},
+	{Name: "scenario generate", Message: &pb.OperationResponses{}},
+	{Name: "scenario ids", Message: &pb.ListScenariosResponse{}},
+	{Name: "scenario launch", Message: &pb.OperationResponses{}},
+	{Name: "scenario list", Message: &pb.ListScenariosResponse{}},
+	{Name: "scenario lock", Message: &pb.OperationResponses{}},
+	{Name: "scenario matrix diff", Message: &pb.DiffScenarioMatricesResponse{}},
+	{Name: "scenario matrix show", Message: &pb.ShowScenarioMatrixResponse{}},
+	{Name: "scenario migrate", Message: &pb.MigrateScenariosResponse{}},
+	{Name: "scenario list", Stream: true, Message: &pb.EnosServiceListScenariosResponse{}},
+	{Name: "scenario outline", Message: &pb.OutlineScenariosResponse{}},
+	{Name: "scenario outline", Stream: true, Message: &pb.EnosServiceOutlineScenariosResponse{}},
+	{Name: "scenario output", Message: &pb.OperationResponses{}},
+	{Name: "scenario run", Message: &pb.OperationResponses{}},
+	{Name: "scenario sample list", Message: &pb.ListSamplesResponse{}},
+	{Name: "scenario sample observe", Message: &pb.ObserveSampleResponse{}},
+	{Name: "scenario sample observe", Stream: true, Message: &pb.EnosServiceObserveSampleResponse{}},
+	{Name: "scenario show-at", Message: &pb.ShowScenarioAtResponse{}},
+	{Name: "scenario eval", Message: &pb.EvalScenarioExpressionResponse{}},
+	{Name: "scenario state backup", Message: &pb.OperationResponses{}},
+	{Name: "scenario state restore", Message: &pb.OperationResponses{}},
+	{Name: "scenario validate", Message: &pb.ValidateScenariosConfigurationResponse{}},
+	{Name: "version", Message: &pb.GetVersionResponse{}},
+}
+
+// Commands returns all commands that write a JSON response.
+func Commands() []*Command {
+	return slices.Clone(commands)
+}
+
+// CommandNames returns the unique names of all commands that write a JSON response.
+func CommandNames() []string {
+	names := []string{}
+	for _, cmd := range commands {
+		if !slices.Contains(names, cmd.Name) {
+			names = append(names, cmd.Name)
+		}
+	}
+
+	return names
+}
+
+// FindCommand returns the command with the name. Commands that don't stream their response are
+// also returned when stream is set as they write the same response either way.
+func FindCommand(name string, stream bool) (*Command, error) {
+	var found *Command
+	for _, cmd := range commands {
+		if cmd.Name != name {
+			continue
+		}
+
+		if cmd.Stream == stream {
+			return cmd, nil
+		}
+		found = cmd
+	}
+
+	if found != nil && !found.Stream {
+		return found, nil
+	}
+
+	return nil, fmt.Errorf("unknown command %q, expected one of: %s", name, strings.Join(CommandNames(), ", "))
+}
+
+// Stamp sets the schema version of the message if it is a response with a schema version.
+func Stamp(msg proto.Message) {
+	if msg == nil {
+		return
+	}
+
+	m := msg.ProtoReflect()
+	if !m.IsValid() {
+		return
+	}
+
+	fd := m.Descriptor().Fields().ByName(versionField)
+	if fd == nil || fd.Kind() != protoreflect.StringKind || fd.Cardinality() == protoreflect.Repeated {
+		return
+	}
+
+	m.Set(fd, protoreflect.ValueOfString(Version))
+}
@@ -0,0 +1,212 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// fieldsGoldenFile is the list of every field and enum value of the JSON output of schema Version.
+// Set ENOS_UPDATE_SCHEMA_FIELDS=1 to update it after adding fields or enum values.
+var fieldsGoldenFile = filepath.Join("testdata", "v"+Version+".fields")
+
+// Test_Stamp tests that responses are stamped with the schema version.
+func Test_Stamp(t *testing.T) {
+	t.Parallel()
+
+	for _, cmd := range Commands() {
+		t.Run(cmd.Name, func(t *testing.T) {
+			t.Parallel()
+
+			msg := cmd.Message.ProtoReflect().New().Interface()
+			Stamp(msg)
+			out, err := protojson.Marshal(msg)
+			require.NoError(t, err)
+			require.JSONEq(t, fmt.Sprintf(`{"schema_version": %q}`, Version), string(out))
+		})
+	}
+
+	// Messages without a schema version are left alone
+	diag := &pb.Diagnostic{}
+	Stamp(diag)
+	require.Empty(t, diag.String())
+}
+
+// Test_FindCommand tests finding commands by name.
+func Test_FindCommand(t *testing.T) {
+	t.Parallel()
+
+	cmd, err := FindCommand("scenario list", false)
+	require.NoError(t, err)
+	require.IsType(t, &pb.ListScenariosResponse{}, cmd.Message)
+
+	cmd, err = FindCommand("scenario list", true)
+	require.NoError(t, err)
+	require.IsType(t, &pb.EnosServiceListScenariosResponse{}, cmd.Message)
+
+	cmd, err = FindCommand("fmt", true)
+	require.NoError(t, err)
+	require.IsType(t, &pb.FormatResponse{}, cmd.Message)
+
+	_, err = FindCommand("scenario nope", false)
+	require.ErrorContains(t, err, `unknown command "scenario nope"`)
+}
+
+// Test_JSONSchema tests that the JSON Schema of every command is valid JSON and that every
+// reference refers to a definition.
+func Test_JSONSchema(t *testing.T) {
+	t.Parallel()
+
+	for _, cmd := range Commands() {
+		t.Run(fmt.Sprintf("%s stream:%t", cmd.Name, cmd.Stream), func(t *testing.T) {
+			t.Parallel()
+
+			out, err := cmd.JSONSchema()
+			require.NoError(t, err)
+
+			schema := map[string]any{}
+			require.NoError(t, json.Unmarshal(out, &schema))
+			require.Equal(t, jsonSchemaDialect, schema["$schema"])
+
+			defs, ok := schema["$defs"].(map[string]any)
+			require.True(t, ok)
+			for _, ref := range testSchemaRefs(schema) {
+				name := strings.TrimPrefix(ref, "#/$defs/")
+				require.Containsf(t, defs, name, "missing definition of reference %s", ref)
+			}
+
+			msg, ok := defs[string(cmd.Message.ProtoReflect().Descriptor().FullName())].(map[string]any)
+			require.True(t, ok)
+			props, ok := msg["properties"].(map[string]any)
+			require.True(t, ok)
+			require.Contains(t, props, versionField)
+		})
+	}
+}
+
+// Test_Compatibility tests that the JSON output of schema Version only evolves in backwards
+// compatible ways.
+func Test_Compatibility(t *testing.T) {
+	t.Parallel()
+
+	fields := testSchemaFields()
+
+	if os.Getenv("ENOS_UPDATE_SCHEMA_FIELDS") != "" {
+		require.NoError(t, os.WriteFile(fieldsGoldenFile, []byte(strings.Join(fields, "\n")+"\n"), 0o644))
+	}
+
+	golden, err := os.ReadFile(fieldsGoldenFile)
+	require.NoError(t, err)
+
+	goldenFields := strings.Split(strings.TrimSpace(string(golden)), "\n")
+	removed := []string{}
+	for _, field := range goldenFields {
+		if !slices.Contains(fields, field) {
+			removed = append(removed, field)
+		}
+	}
+	require.Emptyf(t, removed, "fields of schema version %s have been removed or changed, which requires a new schema version", Version)
+
+	added := []string{}
+	for _, field := range fields {
+		if !slices.Contains(goldenFields, field) {
+			added = append(added, field)
+		}
+	}
+	require.Emptyf(t, added, "fields have been added to schema version %s, run the tests with ENOS_UPDATE_SCHEMA_FIELDS=1 to update %s", Version, fieldsGoldenFile)
+}
+
+// testSchemaRefs returns every reference in the schema.
+func testSchemaRefs(schema any) []string {
+	refs := []string{}
+	switch s := schema.(type) {
+	case map[string]any:
+		for k, v := range s {
+			if ref, ok := v.(string); ok && k == "$ref" {
+				refs = append(refs, ref)
+
+				continue
+			}
+			refs = append(refs, testSchemaRefs(v)...)
+		}
+	case []any:
+		for _, v := range s {
+			refs = append(refs, testSchemaRefs(v)...)
+		}
+	}
+
+	return refs
+}
+
+// testSchemaFields returns every field and enum value of every message that is reachable from
+// the responses of all commands, sorted.
+func testSchemaFields() []string {
+	fields := []string{}
+	seen := map[protoreflect.FullName]struct{}{}
+
+	var walkEnum func(desc protoreflect.EnumDescriptor)
+	walkEnum = func(desc protoreflect.EnumDescriptor) {
+		if _, ok := seen[desc.FullName()]; ok {
+			return
+		}
+		seen[desc.FullName()] = struct{}{}
+
+		for i := range desc.Values().Len() {
+			fields = append(fields, fmt.Sprintf("%s: %s", desc.FullName(), desc.Values().Get(i).Name()))
+		}
+	}
+
+	var walkMessage func(desc protoreflect.MessageDescriptor)
+	walkMessage = func(desc protoreflect.MessageDescriptor) {
+		if _, ok := seen[desc.FullName()]; ok {
+			return
+		}
+		seen[desc.FullName()] = struct{}{}
+		if _, ok := wellKnownTypeSchema(desc.FullName()); ok {
+			return
+		}
+
+		for i := range desc.Fields().Len() {
+			fd := desc.Fields().Get(i)
+			value := fd
+			kind := fd.Cardinality().String()
+			if fd.IsMap() {
+				value = fd.MapValue()
+				kind = "map"
+			}
+
+			typ := value.Kind().String()
+			switch value.Kind() {
+			case protoreflect.EnumKind:
+				typ = string(value.Enum().FullName())
+				walkEnum(value.Enum())
+			case protoreflect.MessageKind, protoreflect.GroupKind:
+				typ = string(value.Message().FullName())
+				walkMessage(value.Message())
+			default:
+			}
+
+			fields = append(fields, fmt.Sprintf("%s.%s: %s %s", desc.FullName(), fd.JSONName(), kind, typ))
+		}
+	}
+
+	for _, cmd := range Commands() {
+		walkMessage(cmd.Message.ProtoReflect().Descriptor())
+	}
+	slices.Sort(fields)
+
+	return fields
+}
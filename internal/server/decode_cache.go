@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"path/filepath"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// WithDecodeCache enables or disables the flight plan decode cache.
+func WithDecodeCache(enabled bool) Opt {
+	return func(s *ServiceV1) error {
+		s.decodeCacheEnabled = enabled
+
+		return nil
+	}
+}
+
+// decodeCache returns the decode cache for a workspace. Caches are scoped to the workspace out
+// directory so that decoded scenarios can be persisted between invocations. If the cache has
+// been disabled a nil cache is returned, which is safe to use but never caches anything.
+func (s *ServiceV1) decodeCache(ws *pb.Workspace) *flightplan.DecodeCache {
+	if !s.decodeCacheEnabled {
+		return nil
+	}
+
+	dir := ws.GetOutDir()
+	if dir == "" && ws.GetFlightplan().GetBaseDir() != "" {
+		dir = filepath.Join(ws.GetFlightplan().GetBaseDir(), ".enos")
+	}
+	if dir != "" {
+		dir = filepath.Join(dir, "cache", "decode")
+	}
+
+	s.decodeCachesMu.Lock()
+	defer s.decodeCachesMu.Unlock()
+
+	cache, ok := s.decodeCaches[dir]
+	if !ok {
+		cache = flightplan.NewDecodeCache(flightplan.WithDecodeCacheDir(dir))
+		s.decodeCaches[dir] = cache
+	}
+
+	return cache
+}
+
+// checkDecodeCache compares the flight plan with the last one decoded with the cache and logs
+// any changes that have invalidated it.
+func (s *ServiceV1) checkDecodeCache(cache *flightplan.DecodeCache, pfp *pb.FlightPlan) {
+	if cache == nil {
+		return
+	}
+
+	invalidations, err := cache.Invalidations(pfp)
+	if err != nil {
+		s.log.Debug("unable to check decode cache", "dir", cache.Dir(), "error", err)
+	}
+
+	for _, inv := range invalidations {
+		s.log.Debug("decode cache invalidated", "dir", cache.Dir(), "path", inv.Path, "reason", inv.Reason)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"time"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/metrics"
+	"github.com/hashicorp/go-hclog"
+)
+
+// WithDecodeTimingLogger enables decode timing. A summary of where decode time went will be
+// written to the logger after every decode.
+func WithDecodeTimingLogger(log hclog.Logger) Opt {
+	return func(s *ServiceV1) error {
+		s.decodeTimingLog = log
+
+		return nil
+	}
+}
+
+// WithMetrics sets the sink that the metrics of decodes and operations are emitted to.
+func WithMetrics(sink metrics.Sink) Opt {
+	return func(s *ServiceV1) error {
+		if sink != nil {
+			s.metrics = sink
+		}
+
+		return nil
+	}
+}
+
+// decodeTimer returns a new decode timer if decode timing or metrics have been enabled. Otherwise
+// a nil timer is returned, which is safe to use but never records anything.
+func (s *ServiceV1) decodeTimer() *flightplan.DecodeTimer {
+	if s.decodeTimingLog == nil && s.metrics == metrics.Discard {
+		return nil
+	}
+
+	return flightplan.NewDecodeTimer()
+}
+
+// recordDecodeTiming writes a summary of the decode timings to the decode timing logger and emits
+// them as metrics. Block timings are emitted as the total of each block type. It is designed to be
+// deferred, e.g. defer s.recordDecodeTiming("ListScenarios", timer, time.Now()).
+func (s *ServiceV1) recordDecodeTiming(method string, timer *flightplan.DecodeTimer, start time.Time) {
+	if timer == nil {
+		return
+	}
+
+	total := time.Since(start)
+	timings := timer.Timings()
+
+	if s.metrics != metrics.Discard {
+		methodTag := metrics.Tag{Name: "method", Value: method}
+		s.metrics.AddTiming(metrics.DecodeDuration, total, methodTag)
+
+		blocks := map[string]time.Duration{}
+		for _, timing := range timings {
+			blocks[timing.Block] += timing.Duration
+		}
+		for block, dur := range blocks {
+			s.metrics.AddTiming(metrics.DecodeBlockDuration, dur, methodTag, metrics.Tag{Name: "block", Value: block})
+		}
+	}
+
+	if s.decodeTimingLog == nil {
+		return
+	}
+
+	s.decodeTimingLog.Info("decode timing", "method", method, "total", total.String())
+	for _, timing := range timings {
+		s.decodeTimingLog.Info("decode timing",
+			"method", method,
+			"block", timing.Block,
+			"name", timing.Name,
+			"count", timing.Count,
+			"duration", timing.Duration.String(),
+		)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/operation/terraform"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// DefaultReadinessInterval is how often we'll re-run failed readiness checks.
+var DefaultReadinessInterval = 5 * time.Second
+
+// ReadinessCheck is a named check that must pass before the service reports
+// itself as serving via the grpc.health.v1 service.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// WithReadinessChecks configures additional readiness checks that must pass
+// before the service is considered ready.
+func WithReadinessChecks(checks ...ReadinessCheck) Opt {
+	return func(s *ServiceV1) error {
+		s.readinessChecks = append(s.readinessChecks, checks...)
+
+		return nil
+	}
+}
+
+// WithReadinessInterval configures how often failed readiness checks are retried.
+func WithReadinessInterval(interval time.Duration) Opt {
+	return func(s *ServiceV1) error {
+		if interval <= 0 {
+			return errors.New("readiness interval must be greater than zero")
+		}
+		s.readinessInterval = interval
+
+		return nil
+	}
+}
+
+// WithTerraformConfig configures how the server executes Terraform, e.g. the path of the Terraform
+// binary or the container to execute it in. The readiness check resolves Terraform with it.
+func WithTerraformConfig(cfg *pb.Terraform_Runner_Config) Opt {
+	return func(s *ServiceV1) error {
+		s.tfExecCfg, _ = proto.Clone(cfg).(*pb.Terraform_Runner_Config)
+
+		return nil
+	}
+}
+
+// Ready returns whether or not all readiness checks have passed.
+func (s *ServiceV1) Ready() bool {
+	s.readyMu.RLock()
+	defer s.readyMu.RUnlock()
+
+	return s.ready
+}
+
+// defaultReadinessChecks returns the readiness checks that every service runs.
+func (s *ServiceV1) defaultReadinessChecks() []ReadinessCheck {
+	return []ReadinessCheck{
+		{Name: "operator", Check: s.checkOperatorRunning},
+		{Name: "decoder", Check: checkDecoderWarm},
+		{Name: "terraform", Check: s.checkTerraformResolved},
+	}
+}
+
+// registerHealth registers the health service with the gRPC server. All services start as not
+// serving until the readiness checks have passed.
+func (s *ServiceV1) registerHealth() {
+	healthpb.RegisterHealthServer(s.grpcServer, s.health)
+	s.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// setServingStatus sets the serving status for the overall server and the enos service.
+func (s *ServiceV1) setServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus("", status)
+	s.health.SetServingStatus(pb.EnosService_ServiceDesc.ServiceName, status)
+}
+
+// startReadiness runs our readiness checks until they've all passed or the context is done. Once
+// every check passes the health service will report as serving.
+func (s *ServiceV1) startReadiness(ctx context.Context) {
+	checks := append(s.defaultReadinessChecks(), s.readinessChecks...)
+	ticker := time.NewTicker(s.readinessInterval)
+	defer ticker.Stop()
+
+	for {
+		err := s.runReadinessChecks(ctx, checks)
+		if err == nil {
+			s.readyMu.Lock()
+			s.ready = true
+			s.readyMu.Unlock()
+			s.setServingStatus(healthpb.HealthCheckResponse_SERVING)
+			s.log.Debug("service is ready")
+
+			return
+		}
+
+		s.log.Debug("service is not ready", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runReadinessChecks runs all checks and returns the first error encountered.
+func (s *ServiceV1) runReadinessChecks(ctx context.Context, checks []ReadinessCheck) error {
+	for _, check := range checks {
+		if check.Check == nil {
+			continue
+		}
+
+		if err := check.Check(ctx); err != nil {
+			return fmt.Errorf("readiness check %s failed: %w", check.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// stopHealth marks all services as not serving and prevents future status changes.
+func (s *ServiceV1) stopHealth() {
+	s.readyMu.Lock()
+	s.ready = false
+	s.readyMu.Unlock()
+	s.health.Shutdown()
+}
+
+func (s *ServiceV1) checkOperatorRunning(_ context.Context) error {
+	if s.operator == nil {
+		return errors.New("no operator has been configured")
+	}
+
+	if s.operator.State() == nil {
+		return errors.New("operator has no state")
+	}
+
+	return nil
+}
+
+// checkDecoderWarm decodes a minimal flight plan. This ensures that the decoder and its function
+// tables are initialized before we accept requests.
+func checkDecoderWarm(ctx context.Context) error {
+	dec, err := flightplan.NewDecoder(
+		flightplan.WithDecoderBaseDir("."),
+		flightplan.WithDecoderDecodeTarget(flightplan.DecodeTargetGlobals),
+		flightplan.WithDecoderFPFiles(flightplan.RawFiles{
+			"enos.hcl": []byte("globals {\n  ready = true\n}\n"),
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	if diags := dec.Parse(); diags.HasErrors() {
+		return diags
+	}
+
+	if _, _, diags := dec.Decode(ctx); diags.HasErrors() {
+		return diags
+	}
+
+	return nil
+}
+
+// checkTerraformResolved ensures that we can resolve what executes Terraform with the Terraform
+// configuration of the server, e.g. the configured binary or the container runtime.
+func (s *ServiceV1) checkTerraformResolved(_ context.Context) error {
+	cfg := terraform.NewConfig()
+	if s.tfExecCfg != nil {
+		cfg.FromProto(s.tfExecCfg)
+	}
+
+	if _, err := cfg.ResolveExecPath(); err != nil {
+		return fmt.Errorf("resolving terraform: %w", err)
+	}
+
+	return nil
+}
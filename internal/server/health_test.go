@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// testExecutable writes an executable script to the directory and returns its path.
+func testExecutable(t *testing.T, dir string, name string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	//nolint:gosec // G306 the script has to be executable
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0o755))
+
+	return path
+}
+
+// Test_ServiceV1_checkTerraformResolved tests that we resolve Terraform with the Terraform
+// configuration of the server.
+func Test_ServiceV1_checkTerraformResolved(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	terraform := testExecutable(t, dir, "terraform")
+	runtime := testExecutable(t, dir, "podman")
+	notExecutable := filepath.Join(dir, "not-executable")
+	require.NoError(t, os.WriteFile(notExecutable, []byte("#!/bin/sh\n"), 0o600))
+
+	for desc, test := range map[string]struct {
+		cfg  *pb.Terraform_Runner_Config
+		fail bool
+	}{
+		"bin path": {
+			cfg: &pb.Terraform_Runner_Config{BinPath: terraform},
+		},
+		"bin path does not exist": {
+			cfg:  &pb.Terraform_Runner_Config{BinPath: filepath.Join(dir, "missing")},
+			fail: true,
+		},
+		"bin path is not executable": {
+			cfg:  &pb.Terraform_Runner_Config{BinPath: notExecutable},
+			fail: true,
+		},
+		"bin path is a directory": {
+			cfg:  &pb.Terraform_Runner_Config{BinPath: dir},
+			fail: true,
+		},
+		"container": {
+			cfg: &pb.Terraform_Runner_Config{
+				BinPath: filepath.Join(dir, "missing"),
+				Container: &pb.Terraform_Runner_Config_Container{
+					Runtime: runtime,
+					Image:   "hashicorp/terraform",
+				},
+			},
+		},
+		"container runtime does not exist": {
+			cfg: &pb.Terraform_Runner_Config{
+				BinPath: terraform,
+				Container: &pb.Terraform_Runner_Config_Container{
+					Runtime: filepath.Join(dir, "missing"),
+					Image:   "hashicorp/terraform",
+				},
+			},
+			fail: true,
+		},
+		"target": {
+			cfg: &pb.Terraform_Runner_Config{
+				BinPath: filepath.Join(dir, "missing"),
+				Target:  &pb.Terraform_Runner_Config_Target{Address: "ssh://enos@target"},
+			},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := New(WithTerraformConfig(test.cfg))
+			require.NoError(t, err)
+
+			err = svc.checkTerraformResolved(context.Background())
+			if test.fail {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// Test_ServiceV1_startReadiness tests that the service only reports as serving once every
+// readiness check has passed.
+func Test_ServiceV1_startReadiness(t *testing.T) {
+	t.Parallel()
+
+	attempts := atomic.Int32{}
+	svc, err := New(
+		WithTerraformConfig(&pb.Terraform_Runner_Config{BinPath: testExecutable(t, t.TempDir(), "terraform")}),
+		WithReadinessInterval(10*time.Millisecond),
+		WithReadinessChecks(ReadinessCheck{
+			Name: "flaky",
+			Check: func(context.Context) error {
+				if attempts.Add(1) < 3 {
+					return errors.New("not yet")
+				}
+
+				return nil
+			},
+		}),
+	)
+	require.NoError(t, err)
+	svc.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.startReadiness(ctx)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the readiness checks")
+	}
+
+	require.True(t, svc.Ready())
+	require.EqualValues(t, 3, attempts.Load())
+	for _, service := range []string{"", pb.EnosService_ServiceDesc.ServiceName} {
+		res, err := svc.health.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		require.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+	}
+
+	svc.stopHealth()
+	require.False(t, svc.Ready())
+}
+
+// Test_ServiceV1_runReadinessChecks tests that failed readiness checks are named in the error.
+func Test_ServiceV1_runReadinessChecks(t *testing.T) {
+	t.Parallel()
+
+	svc, err := New(WithTerraformConfig(&pb.Terraform_Runner_Config{BinPath: filepath.Join(t.TempDir(), "missing")}))
+	require.NoError(t, err)
+
+	err = svc.runReadinessChecks(context.Background(), append(svc.defaultReadinessChecks(), ReadinessCheck{
+		Name: "unset",
+	}))
+	require.ErrorContains(t, err, "readiness check terraform failed")
+
+	require.NoError(t, svc.runReadinessChecks(context.Background(), []ReadinessCheck{
+		{Name: "operator", Check: svc.checkOperatorRunning},
+		{Name: "decoder", Check: checkDecoderWarm},
+		{Name: "unset"},
+	}))
+}
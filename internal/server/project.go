@@ -0,0 +1,301 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// projects is a registry of projects that a server is managing. Projects allow a single long
+// running server to handle operations for multiple flight plan directories.
+type projects struct {
+	mu       sync.RWMutex
+	projects map[string]*pb.Project
+}
+
+// workspaceRequest is any request that carries a workspace.
+type workspaceRequest interface {
+	GetWorkspace() *pb.Workspace
+}
+
+func newProjects() *projects {
+	return &projects{projects: map[string]*pb.Project{}}
+}
+
+// register validates and registers a new project.
+func (p *projects) register(proj *pb.Project) (*pb.Project, error) {
+	if proj == nil {
+		return nil, errors.New("cannot register project that is nil")
+	}
+
+	if proj.GetName() == "" {
+		return nil, errors.New("cannot register project without a name")
+	}
+
+	if proj.GetDir() == "" {
+		return nil, fmt.Errorf("cannot register project %s without a directory", proj.GetName())
+	}
+
+	dir, err := filepath.Abs(proj.GetDir())
+	if err != nil {
+		return nil, fmt.Errorf("resolving project %s directory: %w", proj.GetName(), err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("registering project %s: %w", proj.GetName(), err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("registering project %s: %s is not a directory", proj.GetName(), dir)
+	}
+
+	if err := validateResourceLimits(proj.GetLimits()); err != nil {
+		return nil, fmt.Errorf("registering project %s: %w", proj.GetName(), err)
+	}
+
+	outDir := proj.GetOutDir()
+	if outDir == "" {
+		outDir = filepath.Join(dir, ".enos")
+	}
+	outDir, err = filepath.Abs(outDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving project %s out directory: %w", proj.GetName(), err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.projects[proj.GetName()]; ok {
+		return nil, fmt.Errorf("a project named %s has already been registered", proj.GetName())
+	}
+
+	registered := &pb.Project{
+		Name:   proj.GetName(),
+		Dir:    dir,
+		OutDir: outDir,
+		Limits: proj.GetLimits(),
+	}
+	p.projects[registered.GetName()] = registered
+
+	return registered, nil
+}
+
+// unregister removes a project from the registry.
+func (p *projects) unregister(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.projects[name]; !ok {
+		return fmt.Errorf("no project named %s has been registered", name)
+	}
+
+	delete(p.projects, name)
+
+	return nil
+}
+
+// get returns a registered project by name.
+func (p *projects) get(name string) (*pb.Project, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	proj, ok := p.projects[name]
+
+	return proj, ok
+}
+
+// list returns all registered projects sorted by name.
+func (p *projects) list() []*pb.Project {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	projs := []*pb.Project{}
+	for _, proj := range p.projects {
+		projs = append(projs, proj)
+	}
+
+	slices.SortStableFunc(projs, func(a, b *pb.Project) int {
+		return cmp.Compare(a.GetName(), b.GetName())
+	})
+
+	return projs
+}
+
+// resolveWorkspace scopes the workspace to its project if one has been selected. Any values that
+// are already set in the workspace take precedence over those of the project.
+func (p *projects) resolveWorkspace(ws *pb.Workspace) error {
+	if ws == nil || ws.GetProject() == "" {
+		return nil
+	}
+
+	proj, ok := p.get(ws.GetProject())
+	if !ok {
+		return fmt.Errorf("no project named %s has been registered", ws.GetProject())
+	}
+
+	if ws.GetDir() == "" {
+		ws.Dir = proj.GetDir()
+	}
+
+	if ws.GetOutDir() == "" {
+		ws.OutDir = proj.GetOutDir()
+	}
+
+	ws.Limits = mergeResourceLimits(ws.GetLimits(), proj.GetLimits())
+
+	if len(ws.GetFlightplan().GetEnosHcl()) > 0 {
+		return nil
+	}
+
+	if ws.GetFlightplan() == nil {
+		ws.Flightplan = &pb.FlightPlan{}
+	}
+
+	cfgFiles, err := flightplan.FindRawFiles(proj.GetDir(), flightplan.FlightPlanFileNamePattern)
+	if err != nil {
+		return fmt.Errorf("loading project %s flight plan: %w", proj.GetName(), err)
+	}
+
+	ws.Flightplan.BaseDir = proj.GetDir()
+	ws.Flightplan.EnosHcl = cfgFiles
+
+	if len(ws.GetFlightplan().GetEnosVarsHcl()) == 0 {
+		varsFiles, err := flightplan.FindRawFiles(proj.GetDir(), flightplan.VariablesNamePattern)
+		if err != nil {
+			return fmt.Errorf("loading project %s variables: %w", proj.GetName(), err)
+		}
+		ws.Flightplan.EnosVarsHcl = varsFiles
+	}
+
+	if len(ws.GetFlightplan().GetEnosLintHcl()) == 0 {
+		lintFiles, err := flightplan.FindRawFiles(proj.GetDir(), flightplan.LintConfigNamePattern)
+		if err != nil {
+			return fmt.Errorf("loading project %s lint configuration: %w", proj.GetName(), err)
+		}
+
+		projectFiles, err := flightplan.FindRawFiles(proj.GetDir(), flightplan.ProjectConfigNamePattern)
+		if err != nil {
+			return fmt.Errorf("loading project %s project configuration: %w", proj.GetName(), err)
+		}
+		maps.Copy(lintFiles, projectFiles)
+		ws.Flightplan.EnosLintHcl = lintFiles
+	}
+
+	if len(ws.GetFlightplan().GetEnosSkipHcl()) == 0 {
+		skipFiles, err := flightplan.FindRawFiles(proj.GetDir(), flightplan.SkipConfigNamePattern)
+		if err != nil {
+			return fmt.Errorf("loading project %s skip files: %w", proj.GetName(), err)
+		}
+		ws.Flightplan.EnosSkipHcl = skipFiles
+	}
+
+	return nil
+}
+
+// validateResourceLimits validates the resource limits.
+func validateResourceLimits(limits *pb.ResourceLimits) error {
+	if limits.GetMaxDecodeWorkers() < 0 {
+		return fmt.Errorf("max decode workers must not be negative, got %d", limits.GetMaxDecodeWorkers())
+	}
+
+	if n := limits.GetNiceness(); n < 0 || n > 19 {
+		return fmt.Errorf("niceness must be between 0 and 19, got %d", n)
+	}
+
+	return nil
+}
+
+// mergeResourceLimits returns the resource limits with any unset limits taken from the defaults.
+func mergeResourceLimits(limits *pb.ResourceLimits, defaults *pb.ResourceLimits) *pb.ResourceLimits {
+	if defaults == nil {
+		return limits
+	}
+
+	merged := &pb.ResourceLimits{
+		MaxDecodeWorkers: limits.GetMaxDecodeWorkers(),
+		MaxMatrixMemory:  limits.GetMaxMatrixMemory(),
+		Niceness:         limits.GetNiceness(),
+	}
+
+	if merged.GetMaxDecodeWorkers() == 0 {
+		merged.MaxDecodeWorkers = defaults.GetMaxDecodeWorkers()
+	}
+
+	if merged.GetMaxMatrixMemory() == 0 {
+		merged.MaxMatrixMemory = defaults.GetMaxMatrixMemory()
+	}
+
+	if merged.GetNiceness() == 0 {
+		merged.Niceness = defaults.GetNiceness()
+	}
+
+	return merged
+}
+
+// projectUnaryInterceptor returns a gRPC unary interceptor that scopes request workspaces to
+// their selected project.
+func projectUnaryInterceptor(p *projects) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if wsReq, ok := req.(workspaceRequest); ok {
+			if err := p.resolveWorkspace(wsReq.GetWorkspace()); err != nil {
+				return nil, status.Error(codes.NotFound, err.Error())
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// projectStreamInterceptor returns a gRPC stream interceptor that scopes request workspaces to
+// their selected project.
+func projectStreamInterceptor(p *projects) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &projectStream{ServerStream: ss, projects: p})
+	}
+}
+
+type projectStream struct {
+	grpc.ServerStream
+	projects *projects
+}
+
+func (s *projectStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if wsReq, ok := m.(workspaceRequest); ok {
+		if err := s.projects.resolveWorkspace(wsReq.GetWorkspace()); err != nil {
+			return status.Error(codes.NotFound, err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_projects_register tests that projects are validated before they're registered.
+func Test_projects_register(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "enos.hcl")
+	require.NoError(t, os.WriteFile(file, []byte(""), 0o600))
+
+	for desc, test := range map[string]struct {
+		proj     *pb.Project
+		expected *pb.Project
+		err      string
+	}{
+		"nil": {
+			err: "cannot register project that is nil",
+		},
+		"no name": {
+			proj: &pb.Project{Dir: dir},
+			err:  "without a name",
+		},
+		"no dir": {
+			proj: &pb.Project{Name: "vault"},
+			err:  "project vault without a directory",
+		},
+		"dir does not exist": {
+			proj: &pb.Project{Name: "vault", Dir: filepath.Join(dir, "missing")},
+			err:  "registering project vault",
+		},
+		"dir is a file": {
+			proj: &pb.Project{Name: "vault", Dir: file},
+			err:  "is not a directory",
+		},
+		"negative max decode workers": {
+			proj: &pb.Project{Name: "vault", Dir: dir, Limits: &pb.ResourceLimits{MaxDecodeWorkers: -1}},
+			err:  "max decode workers must not be negative",
+		},
+		"niceness out of range": {
+			proj: &pb.Project{Name: "vault", Dir: dir, Limits: &pb.ResourceLimits{Niceness: 20}},
+			err:  "niceness must be between 0 and 19",
+		},
+		"default out dir": {
+			proj:     &pb.Project{Name: "vault", Dir: dir},
+			expected: &pb.Project{Name: "vault", Dir: dir, OutDir: filepath.Join(dir, ".enos")},
+		},
+		"out dir and limits": {
+			proj: &pb.Project{
+				Name:   "vault",
+				Dir:    dir,
+				OutDir: filepath.Join(dir, "out"),
+				Limits: &pb.ResourceLimits{Niceness: 10},
+			},
+			expected: &pb.Project{
+				Name:   "vault",
+				Dir:    dir,
+				OutDir: filepath.Join(dir, "out"),
+				Limits: &pb.ResourceLimits{Niceness: 10},
+			},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			projs := newProjects()
+			proj, err := projs.register(test.proj)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				require.Empty(t, projs.list())
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected.String(), proj.String())
+			got, ok := projs.get(test.proj.GetName())
+			require.True(t, ok)
+			require.Same(t, proj, got)
+		})
+	}
+}
+
+// Test_projects_registry tests that projects can be registered, listed and unregistered.
+func Test_projects_registry(t *testing.T) {
+	t.Parallel()
+
+	projs := newProjects()
+	for _, name := range []string{"vault", "consul", "boundary"} {
+		_, err := projs.register(&pb.Project{Name: name, Dir: t.TempDir()})
+		require.NoError(t, err)
+	}
+
+	_, err := projs.register(&pb.Project{Name: "vault", Dir: t.TempDir()})
+	require.ErrorContains(t, err, "a project named vault has already been registered")
+
+	names := func() []string {
+		names := []string{}
+		for _, proj := range projs.list() {
+			names = append(names, proj.GetName())
+		}
+
+		return names
+	}
+	require.Equal(t, []string{"boundary", "consul", "vault"}, names())
+
+	require.NoError(t, projs.unregister("consul"))
+	require.ErrorContains(t, projs.unregister("consul"), "no project named consul has been registered")
+	_, ok := projs.get("consul")
+	require.False(t, ok)
+	require.Equal(t, []string{"boundary", "vault"}, names())
+}
+
+// Test_projects_resolveWorkspace tests that workspaces are scoped to their project.
+func Test_projects_resolveWorkspace(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"enos.hcl":         `scenario "smoke" {}`,
+		"enos-other.hcl":   `scenario "upgrade" {}`,
+		"enos.vars.hcl":    `foo = "bar"`,
+		"enos.lint.hcl":    `lint {}`,
+		"enos.project.hcl": `project {}`,
+		"enos.skip.hcl":    `skip {}`,
+		"README.md":        "# Vault",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	projs := newProjects()
+	proj, err := projs.register(&pb.Project{
+		Name:   "vault",
+		Dir:    dir,
+		Limits: &pb.ResourceLimits{MaxDecodeWorkers: 2, Niceness: 10},
+	})
+	require.NoError(t, err)
+
+	t.Run("no project", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, projs.resolveWorkspace(nil))
+		ws := &pb.Workspace{Dir: "/enos"}
+		require.NoError(t, projs.resolveWorkspace(ws))
+		require.Equal(t, (&pb.Workspace{Dir: "/enos"}).String(), ws.String())
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		t.Parallel()
+
+		err := projs.resolveWorkspace(&pb.Workspace{Project: "nomad"})
+		require.ErrorContains(t, err, "no project named nomad has been registered")
+	})
+
+	t.Run("project", func(t *testing.T) {
+		t.Parallel()
+
+		ws := &pb.Workspace{Project: "vault", Limits: &pb.ResourceLimits{Niceness: 5}}
+		require.NoError(t, projs.resolveWorkspace(ws))
+		require.Equal(t, proj.GetDir(), ws.GetDir())
+		require.Equal(t, proj.GetOutDir(), ws.GetOutDir())
+		require.EqualValues(t, 2, ws.GetLimits().GetMaxDecodeWorkers())
+		require.EqualValues(t, 5, ws.GetLimits().GetNiceness())
+
+		fp := ws.GetFlightplan()
+		require.Equal(t, dir, fp.GetBaseDir())
+		require.ElementsMatch(t, []string{
+			filepath.Join(dir, "enos.hcl"),
+			filepath.Join(dir, "enos-other.hcl"),
+		}, testFileNames(fp.GetEnosHcl()))
+		require.ElementsMatch(t, []string{filepath.Join(dir, "enos.vars.hcl")}, testFileNames(fp.GetEnosVarsHcl()))
+		require.ElementsMatch(t, []string{
+			filepath.Join(dir, "enos.lint.hcl"),
+			filepath.Join(dir, "enos.project.hcl"),
+		}, testFileNames(fp.GetEnosLintHcl()))
+		require.ElementsMatch(t, []string{filepath.Join(dir, "enos.skip.hcl")}, testFileNames(fp.GetEnosSkipHcl()))
+	})
+
+	t.Run("workspace values take precedence", func(t *testing.T) {
+		t.Parallel()
+
+		ws := &pb.Workspace{
+			Project: "vault",
+			Dir:     "/enos",
+			OutDir:  "/enos/out",
+			Flightplan: &pb.FlightPlan{
+				BaseDir: "/enos",
+				EnosHcl: map[string][]byte{"/enos/enos.hcl": []byte(`scenario "local" {}`)},
+			},
+		}
+		require.NoError(t, projs.resolveWorkspace(ws))
+		require.Equal(t, "/enos", ws.GetDir())
+		require.Equal(t, "/enos/out", ws.GetOutDir())
+		require.Equal(t, "/enos", ws.GetFlightplan().GetBaseDir())
+		require.Equal(t, []string{"/enos/enos.hcl"}, testFileNames(ws.GetFlightplan().GetEnosHcl()))
+		require.Empty(t, ws.GetFlightplan().GetEnosVarsHcl())
+		require.EqualValues(t, 10, ws.GetLimits().GetNiceness())
+	})
+}
+
+// Test_projectUnaryInterceptor tests that requests of unknown projects are rejected before they
+// reach the handler.
+func Test_projectUnaryInterceptor(t *testing.T) {
+	t.Parallel()
+
+	projs := newProjects()
+	_, err := projs.register(&pb.Project{Name: "vault", Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	interceptor := projectUnaryInterceptor(projs)
+	handled := false
+	handler := func(_ context.Context, req any) (any, error) {
+		handled = true
+
+		return req, nil
+	}
+
+	_, err = interceptor(context.Background(), &pb.ListScenariosRequest{
+		Workspace: &pb.Workspace{Project: "nomad"},
+	}, &grpc.UnaryServerInfo{}, handler)
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.False(t, handled)
+
+	req := &pb.ListScenariosRequest{Workspace: &pb.Workspace{Project: "vault"}}
+	_, err = interceptor(context.Background(), req, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.NotEmpty(t, req.GetWorkspace().GetDir())
+}
+
+// testFileNames returns the names of the files.
+func testFileNames(files map[string][]byte) []string {
+	keys := []string{}
+	for key := range files {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
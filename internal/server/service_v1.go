@@ -11,17 +11,22 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/metrics"
 	"github.com/hashicorp/enos/internal/operation"
+	"github.com/hashicorp/enos/internal/operation/terraform"
 	"github.com/hashicorp/enos/internal/proto"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	"github.com/hashicorp/go-hclog"
@@ -40,6 +45,20 @@ type ServiceV1 struct {
 	grpcServerOpts []grpc.ServerOption
 
 	operator operation.Operator
+	projects *projects
+
+	health            *health.Server
+	readinessChecks   []ReadinessCheck
+	readinessInterval time.Duration
+	readyMu           sync.RWMutex
+	ready             bool
+	tfExecCfg         *pb.Terraform_Runner_Config
+
+	decodeCacheEnabled bool
+	decodeCachesMu     sync.Mutex
+	decodeCaches       map[string]*flightplan.DecodeCache
+	decodeTimingLog    hclog.Logger
+	metrics            metrics.Sink
 }
 
 // ServiceConfig is the running service config.
@@ -102,16 +121,25 @@ func WithOperator(op operation.Operator) Opt {
 func New(opts ...Opt) (*ServiceV1, error) {
 	log := hclog.NewNullLogger()
 	grpcLogger := log.Named("grpc")
+	projs := newProjects()
 
 	svc := &ServiceV1{
-		log:      log,
-		operator: operation.NewLocalOperator(),
+		log:                log,
+		operator:           operation.NewLocalOperator(),
+		projects:           projs,
+		health:             health.NewServer(),
+		readinessInterval:  DefaultReadinessInterval,
+		decodeCacheEnabled: true,
+		decodeCaches:       map[string]*flightplan.DecodeCache{},
+		metrics:            metrics.Discard,
 		grpcServerOpts: []grpc.ServerOption{
 			grpc.ChainUnaryInterceptor(
 				logUnaryInterceptor(grpcLogger, false),
+				projectUnaryInterceptor(projs),
 			),
 			grpc.ChainStreamInterceptor(
 				logStreamInterceptor(grpcLogger),
+				projectStreamInterceptor(projs),
 			),
 			grpc.KeepaliveEnforcementPolicy(
 				keepalive.EnforcementPolicy{
@@ -160,6 +188,8 @@ func (s *ServiceV1) Start(ctx context.Context) (*ServiceConfig, error) {
 		return nil, err
 	}
 
+	go s.startReadiness(ctx)
+
 	serve := func() {
 		wg := sync.WaitGroup{}
 
@@ -204,6 +234,9 @@ func (s *ServiceV1) startListener(ctx context.Context) error {
 	// Register ourselves with the instance of the gRPC server
 	pb.RegisterEnosServiceServer(s.grpcServer, s)
 
+	// Register the standard health service so that orchestrators can determine our readiness.
+	s.registerHealth()
+
 	s.log.Debug("starting gRPC server listener",
 		"listen_grpc", s.configuredURL.String(),
 	)
@@ -289,6 +322,8 @@ func (s *ServiceV1) Stop() error {
 	var err error
 	stopC := make(chan struct{})
 
+	s.stopHealth()
+
 	go func() {
 		defer close(stopC)
 		s.log.Info("Attemping graceful gRPC server stop")
@@ -339,6 +374,8 @@ func (s *ServiceV1) dispatch(
 	refs := []*pb.Ref_Operation{}
 
 	ws := baseReq.GetWorkspace()
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("dispatch", timer, time.Now())
 	if ws == nil {
 		diags = append(diags, diagnostics.FromErr(errors.New("unable to dispatch operations for requests without the required workspace"))...)
 	}
@@ -348,19 +385,26 @@ func (s *ServiceV1) dispatch(
 		ws.GetFlightplan(),
 		flightplan.DecodeTargetAll,
 		f,
+		flightplan.WithDecoderCache(s.decodeCache(ws)),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(ws.GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
 	)
 
 	hclDiags := scenarioDecoder.DecodeAll(ctx, fp)
 	if len(hclDiags) > 0 {
 		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(nil, hclDiags)...)
 	}
+	decRes.Skipped = fp.SkippedProto()
 
 	if baseReq.GetValue() == nil {
 		diags = append(diags, diagnostics.FromErr(errors.New("failed to dispatch operation because operation request value has not been set"))...)
 	}
 
+	// Skipped scenarios are reported with the decode response so that a run where every scenario
+	// has been skipped doesn't fail.
 	scenarios := fp.Scenarios()
-	if len(scenarios) == 0 {
+	if len(scenarios) == 0 && len(decRes.GetSkipped()) == 0 {
 		filter, err := flightplan.NewScenarioFilter(
 			flightplan.WithScenarioFilterDecode(f),
 		)
@@ -380,6 +424,37 @@ func (s *ServiceV1) dispatch(
 		return diags, decRes, refs
 	}
 
+	// Every operation that we dispatch shares the same run ID.
+	runID, err := uuid.NewRandom()
+	if err != nil {
+		return append(diags, diagnostics.FromErr(err)...), decRes, refs
+	}
+
+	// Count the consumers of each fixture so that fixtures can be launched once per run and
+	// destroyed after their last consumer.
+	consumers := map[string]int32{}
+	for _, scenario := range scenarios {
+		for _, name := range scenario.Fixtures {
+			consumers[name]++
+		}
+	}
+
+	// Dispatch the scenarios whose outputs other scenarios consume before their consumers so that
+	// consumers that are launched by the run can wait for their producers to be launched.
+	producers, scenarios, moreDiags := s.resolveProducers(ctx, baseReq, scenarios)
+	diags = append(diags, moreDiags...)
+	if diagnostics.HasFailed(ws.GetTfExecCfg().GetFailOnWarnings(), diags) {
+		return diags, decRes, refs
+	}
+	producerConsumers := map[string]int32{}
+	for _, scenario := range scenarios {
+		for _, producer := range producers[scenario] {
+			if producer.inRun {
+				producerConsumers[producer.scenario.UID()]++
+			}
+		}
+	}
+
 	for _, scenario := range scenarios {
 		req := &pb.Operation_Request{}
 
@@ -402,6 +477,19 @@ func (s *ServiceV1) dispatch(
 			for k, v := range cli.Env {
 				baseReq.Workspace.TfExecCfg.Env[k] = v
 			}
+
+			for _, name := range cli.SensitiveEnv {
+				if !slices.Contains(baseReq.GetWorkspace().GetTfExecCfg().GetSensitiveEnv(), name) {
+					baseReq.Workspace.TfExecCfg.SensitiveEnv = append(baseReq.Workspace.TfExecCfg.SensitiveEnv, name)
+				}
+			}
+
+			s.log.Debug("resolved terraform_cli configuration",
+				"scenario", scenario.String(),
+				"path", cli.Path,
+				"env", terraform.EnvNames(cli.Env),
+				"sensitive_env", cli.SensitiveEnv,
+			)
 		}
 
 		err := proto.Copy(baseReq, req)
@@ -411,6 +499,28 @@ func (s *ServiceV1) dispatch(
 		}
 
 		req.Scenario = scenario.Ref()
+		// The scenario references of outputs include the metadata of the scenario so that consumers
+		// of outputs have its full context. Other operations only identify their scenario.
+		if _, ok := baseReq.GetValue().(*pb.Operation_Request_Output_); ok {
+			req.Scenario = scenario.RefWithMetadata()
+		}
+		req.RunId = runID.String()
+		req.Xfail = scenario.XFail
+		for _, name := range scenario.Fixtures {
+			req.Fixtures = append(req.Fixtures, &pb.Fixture{
+				Name:      name,
+				RunId:     runID.String(),
+				Consumers: consumers[name],
+			})
+		}
+		for _, producer := range producers[scenario] {
+			def := &pb.Producer{Scenario: producer.scenario.Ref()}
+			if producer.inRun {
+				def.RunId = runID.String()
+			}
+			req.Producers = append(req.Producers, def)
+		}
+		req.Consumers = producerConsumers[scenario.UID()]
 		ref, moreDiags := s.operator.Dispatch(req)
 		diags = append(diags, moreDiags...)
 		if ref != nil {
@@ -420,3 +530,109 @@ func (s *ServiceV1) dispatch(
 
 	return diags, decRes, refs
 }
+
+// dispatchProducer is a scenario whose outputs a dispatched scenario consumes.
+type dispatchProducer struct {
+	scenario *flightplan.Scenario
+	// inRun is whether the producer is launched by the same run as its consumer
+	inRun bool
+}
+
+// resolveProducers returns the variants of the scenarios whose outputs each of the scenarios
+// consumes and the scenarios sorted so that producers that are dispatched with their consumers are
+// dispatched first. The variant of a producer is the one that matches the variants of its consumer.
+// A scenario can't be run with its producer as the producer would be destroyed before its consumer
+// was launched.
+func (s *ServiceV1) resolveProducers(
+	ctx context.Context,
+	baseReq *pb.Operation_Request,
+	scenarios []*flightplan.Scenario,
+) (
+	map[*flightplan.Scenario][]*dispatchProducer,
+	[]*flightplan.Scenario,
+	[]*pb.Diagnostic,
+) {
+	diags := []*pb.Diagnostic{}
+	producers := map[*flightplan.Scenario][]*dispatchProducer{}
+	ws := baseReq.GetWorkspace()
+
+	dispatched := map[string]int{}
+	for i, scenario := range scenarios {
+		dispatched[scenario.UID()] = i
+	}
+
+	// Decode all of the variants of each producer once
+	variants := map[string][]*flightplan.Scenario{}
+	producerVariants := func(name string) ([]*flightplan.Scenario, []*pb.Diagnostic) {
+		if v, ok := variants[name]; ok {
+			return v, nil
+		}
+
+		filter, err := flightplan.NewScenarioFilter(flightplan.WithScenarioFilterName(name))
+		if err != nil {
+			return nil, diagnostics.FromErr(err)
+		}
+
+		fp, scenarioDecoder, decRes := flightplan.DecodeProto(
+			ctx,
+			ws.GetFlightplan(),
+			flightplan.DecodeTargetAll,
+			filter.Proto(),
+			flightplan.WithDecoderCache(s.decodeCache(ws)),
+			flightplan.WithDecoderResourceLimits(ws.GetLimits()),
+			flightplan.WithDecoderLogger(s.log.Named("decoder")),
+		)
+		diags := decRes.GetDiagnostics()
+		if scenarioDecoder != nil {
+			if hclDiags := scenarioDecoder.DecodeAll(ctx, fp); len(hclDiags) > 0 {
+				diags = append(diags, diagnostics.FromHCL(nil, hclDiags)...)
+			}
+		}
+		if diagnostics.HasErrors(diags) {
+			return nil, diags
+		}
+		variants[name] = fp.Scenarios()
+
+		return variants[name], diags
+	}
+
+	_, run := baseReq.GetValue().(*pb.Operation_Request_Run_)
+	_, launch := baseReq.GetValue().(*pb.Operation_Request_Launch_)
+	edges := map[int][]int{}
+	for i, scenario := range scenarios {
+		for _, name := range scenario.Producers {
+			v, moreDiags := producerVariants(name)
+			diags = append(diags, moreDiags...)
+			if diagnostics.HasErrors(moreDiags) {
+				continue
+			}
+
+			match, err := flightplan.MatchProducer(scenario, v)
+			if err != nil {
+				diags = append(diags, diagnostics.FromErr(err)...)
+
+				continue
+			}
+
+			producer := &dispatchProducer{scenario: match}
+			if j, ok := dispatched[match.UID()]; ok {
+				edges[i] = append(edges[i], j)
+				producer.inRun = launch
+				if run {
+					diags = append(diags, diagnostics.FromErr(fmt.Errorf(
+						"%s consumes the outputs of %s which would be destroyed by the same run, launch %s first",
+						scenario.String(), match.String(), match.String(),
+					))...)
+				}
+			}
+			producers[scenario] = append(producers[scenario], producer)
+		}
+	}
+
+	sorted, err := flightplan.SortScenariosByProducers(scenarios, edges)
+	if err != nil {
+		return producers, scenarios, append(diags, diagnostics.FromErr(err)...)
+	}
+
+	return producers, sorted, diags
+}
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"io"
+	"sync"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/operation"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// attachResizeQueue is how many resizes of the terminal can be queued before we drop them.
+const attachResizeQueue = 8
+
+// attachOutput writes the output of the terminal of an operation to the stream of the client.
+type attachOutput struct {
+	mu     sync.Mutex
+	stream pb.EnosService_AttachOperationServer
+	err    error
+}
+
+// Write sends the output to the client. If the client has gone away the output is discarded so
+// that the sub-command never blocks on a full terminal.
+func (o *attachOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.err == nil {
+		o.err = o.stream.Send(&pb.AttachOperationResponse{Output: p})
+	}
+
+	return len(p), nil
+}
+
+// AttachOperation attaches the client to the terminal of an operation that executes its
+// sub-command interactively. The first message of the client must have the operation. Its stdin is
+// proxied to the sub-command, which isn't executed until the client has attached, and the output of
+// the sub-command is streamed back until it exits.
+func (s *ServiceV1) AttachOperation(stream pb.EnosService_AttachOperationServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	log := s.log.With(operation.ReferenceDebugArgs(req.GetOp())...)
+
+	if req.GetOp().GetProject() != "" {
+		res, err := s.operator.Response(req.GetOp())
+		if err == nil {
+			err = verifyOperationProject(req.GetOp(), res.GetOp())
+		}
+		if err != nil {
+			log.Error("failed to attach to operation", "error", err)
+
+			return stream.Send(&pb.AttachOperationResponse{
+				Diagnostics: diagnostics.FromErr(err),
+			})
+		}
+	}
+
+	stdinR, stdinW := io.Pipe()
+	resize := make(chan *pb.TerminalSize, attachResizeQueue)
+	go func() {
+		defer close(resize)
+		defer stdinW.Close()
+
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return
+			}
+
+			if len(req.GetStdin()) > 0 {
+				if _, err := stdinW.Write(req.GetStdin()); err != nil {
+					return
+				}
+			}
+
+			if req.GetSize() != nil {
+				select {
+				case resize <- req.GetSize():
+				default:
+				}
+			}
+		}
+	}()
+
+	log.Debug("attaching to operation")
+
+	output := &attachOutput{stream: stream}
+	err = s.operator.Attach(stream.Context(), req.GetOp(), &operation.TerminalSession{
+		Stdin:  stdinR,
+		Output: output,
+		Size:   req.GetSize(),
+		Resize: resize,
+	})
+	stdinR.Close()
+	if err != nil {
+		log.Error("failed to attach to operation", "error", err)
+
+		output.mu.Lock()
+		defer output.mu.Unlock()
+
+		return stream.Send(&pb.AttachOperationResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		})
+	}
+
+	log.Debug("detached from operation")
+
+	return nil
+}
@@ -0,0 +1,244 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/generate"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// BenchmarkScenarios repeatedly decodes, and optionally generates, the flight plan and returns
+// timing and allocation statistics. Allocations are measured for the entire server process, so
+// concurrent requests will influence them.
+func (s *ServiceV1) BenchmarkScenarios(
+	ctx context.Context,
+	req *pb.BenchmarkScenariosRequest,
+) (
+	*pb.BenchmarkScenariosResponse,
+	error,
+) {
+	res := &pb.BenchmarkScenariosResponse{}
+
+	target := flightplan.DecodeTarget(flightplan.DecodeTargetAll)
+	if name := req.GetDecodeTarget(); name != "" {
+		var err error
+		target, err = flightplan.ParseDecodeTarget(name)
+		if err != nil {
+			res.Diagnostics = diagnostics.FromErr(err)
+
+			return res, nil
+		}
+	}
+
+	if req.GetIterations() < 1 {
+		res.Diagnostics = diagnostics.FromErr(errors.New("benchmark iterations must be greater than zero"))
+
+		return res, nil
+	}
+
+	if req.GetGenerate() && target < flightplan.DecodeTargetScenariosComplete {
+		res.Diagnostics = diagnostics.FromErr(fmt.Errorf(
+			"generating modules requires the %s or %s decode target, got %s",
+			flightplan.DecodeTarget(flightplan.DecodeTargetScenariosComplete),
+			flightplan.DecodeTarget(flightplan.DecodeTargetAll),
+			target,
+		))
+
+		return res, nil
+	}
+
+	// Benchmark decoding
+	samples := []time.Duration{}
+	var scenarios []*flightplan.Scenario
+	var count int
+	before := &runtime.MemStats{}
+	runtime.ReadMemStats(before)
+	for range req.GetIterations() {
+		start := time.Now()
+		var decRes *pb.DecodeResponse
+		scenarios, count, decRes = benchmarkDecode(ctx, req, target)
+		samples = append(samples, time.Since(start))
+		if diagnostics.HasFailed(
+			req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+			decRes.GetDiagnostics(),
+		) {
+			res.Decode = decRes
+
+			return res, nil
+		}
+	}
+	after := &runtime.MemStats{}
+	runtime.ReadMemStats(after)
+	res.DecodeBenchmark = newBenchmark(samples, count, before, after)
+
+	if !req.GetGenerate() {
+		return res, nil
+	}
+
+	// Benchmark generating modules for the scenarios of the last decode. We always generate into
+	// a temporary directory so that we never modify existing modules.
+	samples = []time.Duration{}
+	runtime.ReadMemStats(before)
+	for range req.GetIterations() {
+		start := time.Now()
+		if err := benchmarkGenerate(req, scenarios); err != nil {
+			res.Diagnostics = diagnostics.FromErr(err)
+
+			return res, nil
+		}
+		samples = append(samples, time.Since(start))
+	}
+	runtime.ReadMemStats(after)
+	res.GenerateBenchmark = newBenchmark(samples, len(scenarios), before, after)
+
+	return res, nil
+}
+
+// benchmarkDecode decodes the flight plan to the target. If the target includes scenarios they
+// are decoded as well. It returns the decoded scenarios if we've decoded them completely and
+// the number of scenarios that were decoded.
+func benchmarkDecode(
+	ctx context.Context,
+	req *pb.BenchmarkScenariosRequest,
+	target flightplan.DecodeTarget,
+) (
+	[]*flightplan.Scenario,
+	int,
+	*pb.DecodeResponse,
+) {
+	fp, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		req.GetWorkspace().GetFlightplan(),
+		target,
+		req.GetFilter(),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+	)
+	if diagnostics.HasErrors(decRes.GetDiagnostics()) || scenarioDecoder == nil {
+		return nil, 0, decRes
+	}
+
+	switch target {
+	case flightplan.DecodeTargetScenariosComplete, flightplan.DecodeTargetAll:
+		if hclDiags := scenarioDecoder.DecodeAll(ctx, fp); len(hclDiags) > 0 {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(nil, hclDiags)...)
+		}
+		scenarios := fp.Scenarios()
+
+		return scenarios, len(scenarios), decRes
+	case flightplan.DecodeTargetScenariosMatrixOnly,
+		flightplan.DecodeTargetScenariosNamesExpandVariants,
+		flightplan.DecodeTargetScenariosOutlines:
+	default:
+		// We've decoded everything there is for our target, either because it doesn't include
+		// scenarios or because the scenario decoder doesn't support it.
+		return nil, 0, decRes
+	}
+
+	iter := scenarioDecoder.Iterator()
+	if hclDiags := iter.Start(ctx); hclDiags.HasErrors() {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(nil, hclDiags)...)
+
+		return nil, 0, decRes
+	}
+	defer iter.Stop()
+
+	for iter.Next(ctx) {
+		if res := iter.Scenario(); res != nil && res.Diagnostics.HasErrors() {
+			break
+		}
+	}
+	if hclDiags := iter.Diagnostics(); len(hclDiags) > 0 {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(nil, hclDiags)...)
+	}
+
+	return nil, iter.Count(), decRes
+}
+
+// benchmarkGenerate generates Terraform modules for all scenarios into a temporary directory.
+func benchmarkGenerate(req *pb.BenchmarkScenariosRequest, scenarios []*flightplan.Scenario) error {
+	outDir, err := os.MkdirTemp("", "enos-benchmark-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(outDir)
+
+	baseDir, err := filepath.Abs(req.GetWorkspace().GetFlightplan().GetBaseDir())
+	if err != nil {
+		return err
+	}
+
+	for _, scenario := range scenarios {
+		gen, err := generate.NewGenerator(
+			generate.WithScenario(scenario),
+			generate.WithScenarioBaseDirectory(baseDir),
+			generate.WithOutBaseDirectory(outDir),
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := gen.Generate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newBenchmark takes the duration of each iteration, the number of scenarios handled per
+// iteration, and the memory stats before and after, and returns a new benchmark.
+func newBenchmark(
+	samples []time.Duration,
+	scenarios int,
+	before *runtime.MemStats,
+	after *runtime.MemStats,
+) *pb.Benchmark {
+	b := &pb.Benchmark{
+		Iterations: int32(len(samples)),
+		Scenarios:  int64(scenarios),
+	}
+	if len(samples) == 0 {
+		return b
+	}
+
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+
+	var total time.Duration
+	for _, sample := range sorted {
+		total += sample
+	}
+
+	b.Mean = durationpb.New(total / time.Duration(len(sorted)))
+	b.Min = durationpb.New(sorted[0])
+	b.Max = durationpb.New(sorted[len(sorted)-1])
+	b.P50 = durationpb.New(percentile(sorted, 50))
+	b.P90 = durationpb.New(percentile(sorted, 90))
+	b.P99 = durationpb.New(percentile(sorted, 99))
+	b.AllocsPerOp = (after.Mallocs - before.Mallocs) / uint64(len(sorted))
+	b.BytesPerOp = (after.TotalAlloc - before.TotalAlloc) / uint64(len(sorted))
+
+	return b
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+
+	return sorted[max(rank, 1)-1]
+}
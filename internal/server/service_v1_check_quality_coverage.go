@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// CheckQualityCoverage decodes the scenarios of the filter, or of the frame of the sample filter,
+// and returns which scenario variants verify each declared quality and which qualities no
+// scenario variant verifies.
+func (s *ServiceV1) CheckQualityCoverage(
+	ctx context.Context,
+	req *pb.CheckQualityCoverageRequest,
+) (
+	*pb.CheckQualityCoverageResponse,
+	error,
+) {
+	res := &pb.CheckQualityCoverageResponse{Decode: &pb.DecodeResponse{}}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("CheckQualityCoverage", timer, time.Now())
+
+	var frame *flightplan.SampleFrame
+	if req.GetSampleFilter().GetSample().GetId().GetName() != "" {
+		sampleReq, err := flightplan.NewSampleObservationReq(
+			flightplan.WithSampleObservationReqWorkSpace(req.GetWorkspace()),
+			flightplan.WithSampleObservationReqFilter(req.GetSampleFilter()),
+		)
+		if err != nil {
+			res.Diagnostics = diagnostics.FromErr(err)
+
+			return res, nil
+		}
+
+		var decRes *pb.DecodeResponse
+		frame, decRes = sampleReq.Frame(ctx)
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), decRes.GetDiagnostics()...)
+		if diagnostics.HasFailed(
+			req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+			res.GetDecode().GetDiagnostics(),
+		) {
+			return res, nil
+		}
+	}
+
+	fp, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		req.GetWorkspace().GetFlightplan(),
+		flightplan.DecodeTargetAll,
+		req.GetFilter(),
+		flightplan.WithDecoderCache(s.decodeCache(req.GetWorkspace())),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+	res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), decRes.GetDiagnostics()...)
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		res.GetDecode().GetDiagnostics(),
+	) {
+		return res, nil
+	}
+
+	if scenarioDecoder == nil {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return res, nil
+	}
+
+	iter := scenarioDecoder.Iterator()
+	if iter == nil {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return res, nil
+	}
+
+	if moreDiags := iter.Start(ctx); moreDiags.HasErrors() {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromHCL(nil, moreDiags)...)
+
+		return res, nil
+	}
+	defer iter.Stop()
+
+	coverage := flightplan.NewQualityCoverage(fp, flightplan.WithQualityCoverageSampleFrame(frame))
+	for iter.Next(ctx) {
+		scenarioResponse := iter.Scenario()
+		if scenarioResponse == nil {
+			res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromErr(errors.New(
+				"unable to retrieve scenario from decoder",
+			))...)
+
+			return res, nil
+		}
+
+		if moreDiags := scenarioResponse.Diagnostics; moreDiags.HasErrors() {
+			res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromHCL(nil, moreDiags)...)
+
+			return res, nil
+		}
+
+		coverage.Track(scenarioResponse.Scenario)
+	}
+
+	if moreDiags := iter.Diagnostics(); moreDiags.HasErrors() {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromHCL(nil, moreDiags)...)
+
+		return res, nil
+	}
+
+	res.Coverage, res.Unverified = coverage.Coverage()
+
+	return res, nil
+}
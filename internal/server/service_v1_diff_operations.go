@@ -0,0 +1,23 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+
+	"github.com/hashicorp/enos/internal/operation"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// DiffOperations compares two operations that have finished in the out directory of the
+// workspace.
+func (s *ServiceV1) DiffOperations(
+	ctx context.Context,
+	req *pb.DiffOperationsRequest,
+) (
+	*pb.DiffOperationsResponse,
+	error,
+) {
+	return operation.DiffHistory(historyOutDir(req.GetWorkspace()), req), nil
+}
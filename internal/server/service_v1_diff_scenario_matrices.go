@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// DiffScenarioMatrices decodes the scenario matrices of the flight plan of the workspace and of
+// the base flight plan and returns which scenario variants were added and removed.
+func (s *ServiceV1) DiffScenarioMatrices(
+	ctx context.Context,
+	req *pb.DiffScenarioMatricesRequest,
+) (
+	*pb.DiffScenarioMatricesResponse,
+	error,
+) {
+	res := &pb.DiffScenarioMatricesResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("DiffScenarioMatrices", timer, time.Now())
+
+	var base flightplan.ScenarioBlocks
+	base, res.BaseDecode = s.decodeScenarioMatrices(ctx, req.GetBase(), req, timer)
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		res.GetBaseDecode().GetDiagnostics(),
+	) {
+		return res, nil
+	}
+
+	var blocks flightplan.ScenarioBlocks
+	blocks, res.Decode = s.decodeScenarioMatrices(ctx, req.GetWorkspace().GetFlightplan(), req, timer)
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		res.GetDecode().GetDiagnostics(),
+	) {
+		return res, nil
+	}
+
+	added, removed, unchanged := flightplan.DiffScenarioBlocks(base, blocks)
+	for _, scenario := range added {
+		res.Added = append(res.Added, scenario.Ref())
+	}
+	for _, scenario := range removed {
+		res.Removed = append(res.Removed, scenario.Ref())
+	}
+	res.Unchanged = int32(unchanged)
+
+	return res, nil
+}
+
+// decodeScenarioMatrices decodes the scenario blocks and matrices of the flight plan that match
+// the filter of the request. A flight plan without any matching scenarios, e.g. a base flight plan
+// that doesn't have a new scenario yet, has no scenario blocks rather than failing to decode.
+func (s *ServiceV1) decodeScenarioMatrices(
+	ctx context.Context,
+	pfp *pb.FlightPlan,
+	req *pb.DiffScenarioMatricesRequest,
+	timer *flightplan.DecodeTimer,
+) (flightplan.ScenarioBlocks, *pb.DecodeResponse) {
+	_, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		pfp,
+		flightplan.DecodeTargetScenariosMatrixOnly,
+		req.GetFilter(),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+	if diagnostics.HasErrors(decRes.GetDiagnostics()) {
+		return nil, decRes
+	}
+
+	var iter *flightplan.ScenarioDecoderIterator
+	if scenarioDecoder != nil {
+		iter = scenarioDecoder.Iterator()
+	}
+	if iter == nil {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return nil, decRes
+	}
+
+	if hclDiags := iter.Start(ctx); hclDiags.HasErrors() {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(nil, hclDiags)...)
+
+		return nil, decRes
+	}
+	defer iter.Stop()
+
+	return iter.Blocks().Sort(), decRes
+}
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// evalExpressionFilename is the name of the expression in the ranges of its diagnostics.
+const evalExpressionFilename = "<expression>"
+
+// EvalScenarioExpression decodes the scenarios of the filter and evaluates the expression in the
+// eval context of every scenario variant, i.e. with its matrix, variables, globals, locals, and
+// steps.
+func (s *ServiceV1) EvalScenarioExpression(
+	ctx context.Context,
+	req *pb.EvalScenarioExpressionRequest,
+) (
+	*pb.EvalScenarioExpressionResponse,
+	error,
+) {
+	res := &pb.EvalScenarioExpressionResponse{Decode: &pb.DecodeResponse{}}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("EvalScenarioExpression", timer, time.Now())
+
+	src := []byte(req.GetExpression())
+	files := map[string]*hcl.File{evalExpressionFilename: {Bytes: src}}
+	expr, hclDiags := hclsyntax.ParseExpression(src, evalExpressionFilename, hcl.InitialPos)
+	if hclDiags.HasErrors() {
+		res.Diagnostics = diagnostics.FromHCL(files, hclDiags)
+
+		return res, nil
+	}
+
+	_, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		req.GetWorkspace().GetFlightplan(),
+		flightplan.DecodeTargetAll,
+		req.GetFilter(),
+		flightplan.WithDecoderCache(s.decodeCache(req.GetWorkspace())),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+	res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), decRes.GetDiagnostics()...)
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		res.GetDecode().GetDiagnostics(),
+	) {
+		return res, nil
+	}
+
+	if scenarioDecoder == nil {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return res, nil
+	}
+
+	iter := scenarioDecoder.Iterator()
+	if iter == nil {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return res, nil
+	}
+
+	if moreDiags := iter.Start(ctx); moreDiags.HasErrors() {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromHCL(nil, moreDiags)...)
+
+		return res, nil
+	}
+	defer iter.Stop()
+
+	for iter.Next(ctx) {
+		scenarioResponse := iter.Scenario()
+		if scenarioResponse == nil {
+			res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromErr(errors.New(
+				"unable to retrieve scenario from decoder",
+			))...)
+
+			return res, nil
+		}
+
+		// Evaluate the expression even if the scenario failed to decode, as the expression might
+		// be what explains why, but keep the diagnostics of the scenario with its result.
+		result, moreDiags := flightplan.EvalScenarioExpression(expr, scenarioResponse.ScenarioEvalContext)
+		result.Scenario = scenarioResponse.Scenario.Ref()
+		result.Diagnostics = append(
+			diagnostics.FromHCL(nil, scenarioResponse.Diagnostics),
+			diagnostics.FromHCL(files, moreDiags)...,
+		)
+		res.Results = append(res.GetResults(), result)
+	}
+
+	if moreDiags := iter.Diagnostics(); moreDiags.HasErrors() {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromHCL(nil, moreDiags)...)
+
+		return res, nil
+	}
+
+	return res, nil
+}
@@ -23,7 +23,9 @@ func (s *ServiceV1) ExecScenarios(
 		req.GetFilter(),
 		&pb.Operation_Request{
 			Workspace: req.GetWorkspace(),
-			Value:     &pb.Operation_Request_Exec_{},
+			Value: &pb.Operation_Request_Exec_{
+				Exec: &pb.Operation_Request_Exec{Tty: req.GetTty()},
+			},
 		},
 	)
 
@@ -0,0 +1,73 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ExportFlightPlan decodes the entire flight plan and returns the decoded model of its modules,
+// providers, scenarios that match the filter, samples and qualities.
+func (s *ServiceV1) ExportFlightPlan(
+	ctx context.Context,
+	req *pb.ExportFlightPlanRequest,
+) (
+	*pb.ExportFlightPlanResponse,
+	error,
+) {
+	res := &pb.ExportFlightPlanResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("ExportFlightPlan", timer, time.Now())
+
+	res.Export, res.Decode, res.Diagnostics = s.exportFlightPlan(ctx, req.GetWorkspace(), req.GetFilter(), timer)
+
+	return res, nil
+}
+
+// exportFlightPlan decodes the entire flight plan of the workspace and exports it. The export is
+// nil if the flight plan failed to decode.
+func (s *ServiceV1) exportFlightPlan(
+	ctx context.Context,
+	ws *pb.Workspace,
+	filter *pb.Scenario_Filter,
+	timer *flightplan.DecodeTimer,
+) (*pb.FlightPlanExport, *pb.DecodeResponse, []*pb.Diagnostic) {
+	fp, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		ws.GetFlightplan(),
+		flightplan.DecodeTargetAll,
+		filter,
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(ws.GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+
+	if scenarioDecoder != nil {
+		hclDiags := scenarioDecoder.DecodeAll(ctx, fp)
+		if hclDiags.HasErrors() {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(nil, hclDiags)...)
+		}
+	} else {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"unable to decode scenarios",
+		))...)
+	}
+
+	if diagnostics.HasFailed(ws.GetTfExecCfg().GetFailOnWarnings(), decRes.GetDiagnostics()) {
+		return nil, decRes, nil
+	}
+
+	export, err := fp.Export()
+	if err != nil {
+		return nil, decRes, diagnostics.FromErr(err)
+	}
+
+	return export, decRes, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// FetchScenarioModules resolves the remote module sources of the steps of all scenarios that match
+// the filter and fetches them into the workspace module cache.
+func (s *ServiceV1) FetchScenarioModules(
+	ctx context.Context,
+	req *pb.FetchScenarioModulesRequest,
+) (
+	*pb.FetchScenarioModulesResponse,
+	error,
+) {
+	res := &pb.FetchScenarioModulesResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("FetchScenarioModules", timer, time.Now())
+
+	fp, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		req.GetWorkspace().GetFlightplan(),
+		flightplan.DecodeTargetAll,
+		req.GetFilter(),
+		flightplan.WithDecoderCache(s.decodeCache(req.GetWorkspace())),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+	res.Decode = decRes
+
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		decRes.GetDiagnostics(),
+	) {
+		return res, nil
+	}
+
+	if scenarioDecoder == nil {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return res, nil
+	}
+
+	iter := scenarioDecoder.Iterator()
+	if iter == nil {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return res, nil
+	}
+
+	if hclDiags := iter.Start(ctx); hclDiags.HasErrors() {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, hclDiags)...)
+
+		return res, nil
+	}
+	defer iter.Stop()
+
+	modules := flightplan.NewRemoteModuleTracker(fp)
+	for iter.Next(ctx) {
+		scenarioResponse := iter.Scenario()
+		if scenarioResponse == nil {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(errors.New(
+				"unable to retrieve scenario from decoder",
+			))...)
+
+			return res, nil
+		}
+
+		if hclDiags := scenarioResponse.Diagnostics; len(hclDiags) > 0 {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, hclDiags)...)
+			if hclDiags.HasErrors() {
+				return res, nil
+			}
+		}
+
+		modules.Track(scenarioResponse.Scenario)
+	}
+
+	if hclDiags := iter.Diagnostics(); len(hclDiags) > 0 {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, hclDiags)...)
+
+		return res, nil
+	}
+
+	fetched, hclDiags := modules.Fetch(ctx, flightplan.NewModuleFetcher(
+		flightplan.WithModuleFetcherDir(moduleFetchDir(req.GetWorkspace())),
+	))
+	res.Modules = fetched
+	res.Diagnostics = diagnostics.FromHCL(fp.Files, hclDiags)
+
+	return res, nil
+}
+
+// moduleFetchDir returns the directory that remote modules of the workspace are fetched into.
+func moduleFetchDir(ws *pb.Workspace) string {
+	dir := ws.GetOutDir()
+	if dir == "" {
+		dir = filepath.Join(ws.GetFlightplan().GetBaseDir(), ".enos")
+	}
+
+	return filepath.Join(dir, "cache", "modules")
+}
@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// FixScenarios decodes the scenarios that match the filter and applies the fixes of any fixable
+// diagnostics to the flight plan files. The diff of every fixed file is always returned but the
+// files are only written if requested. Diagnostics that could not be fixed are returned as
+// decode diagnostics.
+func (s *ServiceV1) FixScenarios(
+	ctx context.Context,
+	req *pb.FixScenariosRequest,
+) (
+	*pb.FixScenariosResponse,
+	error,
+) {
+	res := &pb.FixScenariosResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("FixScenarios", timer, time.Now())
+
+	fp, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		req.GetWorkspace().GetFlightplan(),
+		flightplan.DecodeTargetAll,
+		req.GetFilter(),
+		flightplan.WithDecoderCache(s.decodeCache(req.GetWorkspace())),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+	res.Decode = decRes
+
+	// Fixable diagnostics can be created by decoding the flight plan or the scenarios. We can
+	// only decode the scenarios if the flight plan has been decoded.
+	if scenarioDecoder != nil && !diagnostics.HasErrors(decRes.GetDiagnostics()) {
+		iter := scenarioDecoder.Iterator()
+		if iter == nil {
+			res.Diagnostics = diagnostics.FromErr(errors.New("failed to decode scenarios"))
+
+			return res, nil
+		}
+
+		if hclDiags := iter.Start(ctx); len(hclDiags) > 0 {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, hclDiags)...)
+		}
+		defer iter.Stop()
+
+		for iter.Next(ctx) {
+			scenarioResponse := iter.Scenario()
+			if scenarioResponse == nil {
+				res.Diagnostics = diagnostics.FromErr(errors.New("unable to retrieve scenario from decoder"))
+
+				return res, nil
+			}
+
+			if hclDiags := scenarioResponse.Diagnostics; len(hclDiags) > 0 {
+				decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, hclDiags)...)
+			}
+		}
+
+		if hclDiags := iter.Diagnostics(); len(hclDiags) > 0 {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, hclDiags)...)
+		}
+	}
+
+	fixed, hclDiags := flightplan.ApplyFixes(req.GetWorkspace().GetFlightplan().GetEnosHcl(), decRes.GetDiagnostics())
+	res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromHCL(nil, hclDiags)...)
+
+	for _, file := range fixed {
+		edits := myers.ComputeEdits(span.URIFromPath(file.Path), string(file.Src), string(file.Fixed))
+		res.Files = append(res.GetFiles(), &pb.FixScenariosResponse_File{
+			Path: file.Path,
+			Diff: strings.TrimSuffix(
+				fmt.Sprint(gotextdiff.ToUnified(file.Path, file.Path, string(file.Src), edits)),
+				"\n",
+			),
+			Fixed: file.Diagnostics,
+		})
+
+		// Any diagnostic that we've fixed is no longer a decode diagnostic. Diagnostics of other
+		// scenario variants that have the same fix have been fixed too.
+		decRes.Diagnostics = slices.DeleteFunc(decRes.GetDiagnostics(), func(diag *pb.Diagnostic) bool {
+			return slices.ContainsFunc(file.Diagnostics, func(fixedDiag *pb.Diagnostic) bool {
+				return len(diag.GetFixes()) > 0 && proto.Equal(diag.GetFixes()[0], fixedDiag.GetFixes()[0])
+			})
+		})
+
+		if !req.GetWrite() {
+			continue
+		}
+
+		info, err := os.Stat(file.Path)
+		if err != nil {
+			res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+
+			continue
+		}
+
+		if err := os.WriteFile(file.Path, file.Fixed, info.Mode().Perm()); err != nil {
+			res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+		}
+	}
+
+	return res, nil
+}
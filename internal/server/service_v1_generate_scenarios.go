@@ -23,7 +23,12 @@ func (s *ServiceV1) GenerateScenarios(
 		req.GetFilter(),
 		&pb.Operation_Request{
 			Workspace: req.GetWorkspace(),
-			Value:     &pb.Operation_Request_Generate_{},
+			Value: &pb.Operation_Request_Generate_{
+				Generate: &pb.Operation_Request_Generate{
+					DryRun: req.GetDryRun(),
+					Diff:   req.GetDiff(),
+				},
+			},
 		},
 	)
 
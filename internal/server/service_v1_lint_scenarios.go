@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// LintScenarios lints the scenarios in the flight plan with the lint rules that have been
+// configured in the flight plan lint configuration.
+func (s *ServiceV1) LintScenarios(
+	ctx context.Context,
+	req *pb.LintScenariosRequest,
+) (
+	*pb.LintScenariosResponse,
+	error,
+) {
+	res := &pb.LintScenariosResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("LintScenarios", timer, time.Now())
+
+	linter := flightplan.NewLinter(
+		flightplan.WithLinterConfigFiles(req.GetWorkspace().GetFlightplan().GetEnosLintHcl()),
+	)
+	if hclDiags := linter.Configure(); len(hclDiags) > 0 {
+		res.Diagnostics = diagnostics.FromHCL(linter.Files(), hclDiags)
+		if diagnostics.HasErrors(res.GetDiagnostics()) {
+			return res, nil
+		}
+	}
+
+	// We only need to decode the scenario blocks and their matrices to lint them.
+	fp, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		req.GetWorkspace().GetFlightplan(),
+		flightplan.DecodeTargetScenariosMatrixOnly,
+		req.GetFilter(),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+	res.Decode = decRes
+
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		decRes.GetDiagnostics(),
+	) {
+		return res, nil
+	}
+
+	iter := scenarioDecoder.Iterator()
+	if iter == nil {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return res, nil
+	}
+
+	if hclDiags := iter.Start(ctx); hclDiags.HasErrors() {
+		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, hclDiags)...)
+
+		return res, nil
+	}
+	defer iter.Stop()
+
+	fp.ScenarioBlocks = iter.Blocks().Sort()
+	res.Lints = diagnostics.FromHCL(fp.Files, linter.Lint(fp))
+
+	return res, nil
+}
@@ -0,0 +1,40 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/operation"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ListOperations lists the operations of the project of the workspace that have finished in the
+// out directory of the workspace, most recent first.
+func (s *ServiceV1) ListOperations(
+	ctx context.Context,
+	req *pb.ListOperationsRequest,
+) (
+	*pb.ListOperationsResponse,
+	error,
+) {
+	res := &pb.ListOperationsResponse{}
+
+	ops, err := operation.ListHistory(historyOutDir(req.GetWorkspace()), req)
+	res.Operations = ops
+	res.Diagnostics = diagnostics.FromErr(err)
+
+	return res, nil
+}
+
+// historyOutDir returns the out directory of the workspace that has the history of operations.
+func historyOutDir(ws *pb.Workspace) string {
+	if outDir := ws.GetOutDir(); outDir != "" {
+		return outDir
+	}
+
+	return filepath.Join(ws.GetFlightplan().GetBaseDir(), ".enos")
+}
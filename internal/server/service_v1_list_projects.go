@@ -0,0 +1,23 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ListProjects returns all projects that have been registered with the server.
+func (s *ServiceV1) ListProjects(
+	ctx context.Context,
+	req *pb.ListProjectsRequest,
+) (
+	*pb.ListProjectsResponse,
+	error,
+) {
+	return &pb.ListProjectsResponse{
+		Projects: s.projects.list(),
+	}, nil
+}
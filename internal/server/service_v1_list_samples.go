@@ -7,6 +7,7 @@ import (
 	"cmp"
 	"context"
 	"slices"
+	"time"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan"
@@ -22,12 +23,18 @@ func (s *ServiceV1) ListSamples(
 	error,
 ) {
 	res := &pb.ListSamplesResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("ListSamples", timer, time.Now())
 
 	fp, _, decRes := flightplan.DecodeProto(
 		ctx,
 		req.GetWorkspace().GetFlightplan(),
 		flightplan.DecodeTargetSamples,
 		nil,
+		flightplan.WithDecoderCache(s.decodeCache(req.GetWorkspace())),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
 	)
 	res.Decode = decRes
 	if diagnostics.HasFailed(
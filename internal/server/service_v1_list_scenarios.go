@@ -4,85 +4,362 @@
 package server
 
 import (
+	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/operation"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 	"github.com/hashicorp/hcl/v2"
 )
 
-// ListScenarios returns a list of scenarios and their variants.
+// maxExplainedExclusions is the maximum number of exclusions that a scenario list explains.
+const maxExplainedExclusions = 1000
+
+// ListScenarios returns a list of scenarios and their variants. If the request has a page size or
+// page token the scenarios are sorted, by name and variants unless the request has another sort
+// order, and only a single page is returned. If the request asks for an explanation it's sent
+// after the scenarios.
 func (s *ServiceV1) ListScenarios(req *pb.ListScenariosRequest, stream pb.EnosService_ListScenariosServer) error {
+	if err := s.listScenarios(req, stream); err != nil {
+		return err
+	}
+
+	if !req.GetExplain() {
+		return nil
+	}
+
+	return s.sendListScenariosExplanation(req, stream)
+}
+
+// listScenarios sends the scenarios of a scenario list.
+func (s *ServiceV1) listScenarios(req *pb.ListScenariosRequest, stream pb.EnosService_ListScenariosServer) error {
+	diags := hcl.Diagnostics{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("ListScenarios", timer, time.Now())
+
+	paginated := req.GetPageSize() > 0 || req.GetPageToken() != ""
+	sorted := req.GetSort() != pb.Scenario_SORT_UNSPECIFIED
+	if req.GetPageSize() < 0 {
+		return sendListScenarioDecodeResponse(stream, &pb.DecodeResponse{}, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "invalid page size",
+			Detail:   fmt.Sprintf("the page size must not be negative, got %d", req.GetPageSize()),
+		}))
+	}
+
+	// Check our decode cache for scenarios from a previous decode of the same flight plan.
+	cache := s.decodeCache(req.GetWorkspace())
+	s.checkDecodeCache(cache, req.GetWorkspace().GetFlightplan())
+	cacheKey, err := cache.Key(
+		req.GetWorkspace().GetFlightplan(),
+		listScenariosDecodeTarget(req),
+		req.GetFilter(),
+	)
+	if err != nil {
+		s.log.Debug("unable to determine decode cache key", "error", err)
+		if paginated {
+			return sendListScenarioDecodeResponse(stream, &pb.DecodeResponse{}, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "unable to paginate scenarios",
+				Detail:   err.Error(),
+			}))
+		}
+	}
+
+	offset := 0
+	if paginated {
+		offset, err = decodeListScenariosPageToken(req.GetPageToken(), listScenariosPageKey(req, cacheKey))
+		if err != nil {
+			return sendListScenarioDecodeResponse(stream, &pb.DecodeResponse{}, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "invalid page token",
+				Detail:   err.Error(),
+			}))
+		}
+	}
+
+	// When we're neither paginating nor sorting we'll send every scenario as soon as it has been
+	// decoded.
+	send := func(ref *pb.Ref_Scenario) error {
+		return stream.Send(&pb.EnosServiceListScenariosResponse{
+			Response: &pb.EnosServiceListScenariosResponse_Scenario{
+				Scenario: ref,
+			},
+		})
+	}
+	sendAll := func(refs []*pb.Ref_Scenario) error {
+		if sorted {
+			refs = s.sortListScenarios(req, refs)
+		}
+
+		for _, ref := range refs {
+			if err := send(ref); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+	decodeSend := send
+	if paginated || sorted {
+		decodeSend = nil
+	}
+
+	refs, ok := cache.Scenarios(cacheKey)
+	if ok {
+		s.log.Debug("decode cache hit", "dir", cache.Dir(), "key", cacheKey, "scenarios", len(refs))
+		if !paginated {
+			return sendAll(refs)
+		}
+	} else {
+		var decRes *pb.DecodeResponse
+		var moreDiags hcl.Diagnostics
+		refs, decRes, moreDiags, err = s.decodeScenarioRefs(stream.Context(), req, cache, timer, decodeSend)
+		if err != nil {
+			return err
+		}
+
+		if diagnostics.HasFailed(
+			req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+			append(decRes.GetDiagnostics(), diagnostics.FromHCL(nil, moreDiags)...),
+		) {
+			return sendListScenarioDecodeResponse(stream, decRes, moreDiags)
+		}
+
+		// Only cache clean decodes so that we never hide diagnostics or skipped scenarios.
+		if cacheKey != "" && len(refs) > 0 && len(decRes.GetDiagnostics()) == 0 && len(moreDiags) == 0 &&
+			len(decRes.GetSkipped()) == 0 {
+			if err := cache.SetScenarios(cacheKey, refs); err != nil {
+				s.log.Debug("unable to update decode cache", "dir", cache.Dir(), "error", err)
+			}
+		}
+
+		if !paginated {
+			if sorted {
+				if err := sendAll(refs); err != nil {
+					return err
+				}
+			}
+
+			return sendListScenarioDecodeResponse(stream, decRes, moreDiags)
+		}
+
+		// Send the diagnostics and skipped scenarios of the decode before the first page so that
+		// they don't silently go missing when paginating.
+		if offset == 0 {
+			if err := sendListScenarioDecodeResponse(stream, decRes, moreDiags); err != nil {
+				return err
+			}
+		}
+	}
+
+	return sendListScenariosPage(
+		stream, s.sortListScenarios(req, refs), offset, int(req.GetPageSize()), listScenariosPageKey(req, cacheKey),
+	)
+}
+
+// listScenariosDecodeTarget returns the decode target of the scenario list request. Only the
+// names and variants of scenarios are decoded unless the request asks for their metadata or
+// sorts them by it.
+func listScenariosDecodeTarget(req *pb.ListScenariosRequest) flightplan.DecodeTarget {
+	if listScenariosMetadata(req) {
+		return flightplan.DecodeTargetAll
+	}
+
+	return flightplan.DecodeTargetScenariosNamesExpandVariants
+}
+
+// listScenariosMetadata returns whether the scenario references of the request need metadata.
+func listScenariosMetadata(req *pb.ListScenariosRequest) bool {
+	return req.GetMetadata() || flightplan.ScenarioSortNeedsMetadata(req.GetSort())
+}
+
+// listScenariosPageKey returns the key of the page tokens of the request. It includes the sort
+// order so that a page token can't be used with another order.
+func listScenariosPageKey(req *pb.ListScenariosRequest, cacheKey string) string {
+	if req.GetSort() == pb.Scenario_SORT_UNSPECIFIED {
+		return cacheKey
+	}
+
+	return cacheKey + ":" + strings.ToLower(strings.TrimPrefix(req.GetSort().String(), "SORT_"))
+}
+
+// sortListScenarios returns a sorted copy of the scenario references, which might be shared with
+// the decode cache. If the references only have metadata because of the sort order it's removed
+// after sorting.
+func (s *ServiceV1) sortListScenarios(req *pb.ListScenariosRequest, refs []*pb.Ref_Scenario) []*pb.Ref_Scenario {
+	sorted := slices.Clone(refs)
+
+	var durations *flightplan.ScenarioDurations
+	if req.GetSort() == pb.Scenario_SORT_DURATION {
+		var err error
+		durations, err = operation.HistoryDurations(historyOutDir(req.GetWorkspace()), req.GetWorkspace().GetProject())
+		if err != nil {
+			s.log.Warn("unable to read the complete operation history", "error", err)
+		}
+	}
+	flightplan.SortScenarioRefs(sorted, req.GetSort(), durations)
+
+	if req.GetMetadata() {
+		return sorted
+	}
+
+	for i, ref := range sorted {
+		if ref.GetMetadata() != nil {
+			sorted[i] = &pb.Ref_Scenario{Id: ref.GetId()}
+		}
+	}
+
+	return sorted
+}
+
+// decodeScenarioRefs decodes the scenarios in the flight plan and returns their references. If
+// send is set every reference will be sent as soon as it has been decoded. Any decode failures are
+// returned as diagnostics, errors are only returned if we fail to send a reference.
+func (s *ServiceV1) decodeScenarioRefs(
+	ctx context.Context,
+	req *pb.ListScenariosRequest,
+	cache *flightplan.DecodeCache,
+	timer *flightplan.DecodeTimer,
+	send func(*pb.Ref_Scenario) error,
+) (
+	[]*pb.Ref_Scenario,
+	*pb.DecodeResponse,
+	hcl.Diagnostics,
+	error,
+) {
 	diags := hcl.Diagnostics{}
 
 	_, scenarioDecoder, decRes := flightplan.DecodeProto(
-		stream.Context(),
+		ctx,
 		req.GetWorkspace().GetFlightplan(),
-		flightplan.DecodeTargetScenariosNamesExpandVariants,
+		listScenariosDecodeTarget(req),
 		req.GetFilter(),
+		flightplan.WithDecoderCache(cache),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
 	)
 
 	if diagnostics.HasFailed(
 		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
 		decRes.GetDiagnostics(),
 	) {
-		return sendListScenarioDecodeResponse(req, stream, decRes, nil)
+		return nil, decRes, nil, nil
 	}
 
 	if scenarioDecoder == nil {
-		return sendListScenarioDecodeResponse(req, stream, decRes, diags.Append(&hcl.Diagnostic{
+		return nil, decRes, diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "failed to decode scenarios",
-		}))
+		}), nil
 	}
 
 	iter := scenarioDecoder.Iterator()
 	if iter == nil {
-		return sendListScenarioDecodeResponse(req, stream, decRes, diags.Append(&hcl.Diagnostic{
+		return nil, decRes, diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "failed to decode scenarios",
-		}))
+		}), nil
 	}
 
-	if moreDiags := iter.Start(stream.Context()); moreDiags != nil && moreDiags.HasErrors() {
-		return sendListScenarioDecodeResponse(req, stream, decRes, moreDiags)
+	if moreDiags := iter.Start(ctx); moreDiags != nil && moreDiags.HasErrors() {
+		return nil, decRes, moreDiags, nil
 	}
 	defer iter.Stop()
 
-	for iter.Next(stream.Context()) {
+	refs := []*pb.Ref_Scenario{}
+	for iter.Next(ctx) {
 		if moreDiags := iter.Diagnostics(); moreDiags != nil && moreDiags.HasErrors() {
-			return sendListScenarioDecodeResponse(req, stream, decRes, moreDiags)
+			return nil, decRes, moreDiags, nil
 		}
 
 		scenarioResponse := iter.Scenario()
 		if scenarioResponse == nil {
-			return sendListScenarioDecodeResponse(req, stream, decRes, diags.Append(&hcl.Diagnostic{
+			return nil, decRes, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "unable to retrieve scenario from decoder",
-			}))
+			}), nil
 		}
 
 		if moreDiags := scenarioResponse.Diagnostics; moreDiags != nil && moreDiags.HasErrors() {
-			return sendListScenarioDecodeResponse(req, stream, decRes, moreDiags)
+			return nil, decRes, moreDiags, nil
 		}
 
-		err := stream.Send(&pb.EnosServiceListScenariosResponse{
-			Response: &pb.EnosServiceListScenariosResponse_Scenario{
-				Scenario: scenarioResponse.Scenario.Ref(),
-			},
-		})
-		if err != nil {
-			return err
+		ref := scenarioResponse.Scenario.Ref()
+		if listScenariosMetadata(req) {
+			ref = scenarioResponse.Scenario.RefWithMetadata()
+		}
+		refs = append(refs, ref)
+		if send != nil {
+			if err := send(ref); err != nil {
+				return nil, decRes, nil, err
+			}
 		}
 	}
 
-	return sendListScenarioDecodeResponse(req, stream, decRes, iter.Diagnostics())
+	for _, skipped := range iter.Skipped() {
+		decRes.Skipped = append(decRes.GetSkipped(), skipped.Proto())
+	}
+
+	return refs, decRes, iter.Diagnostics(), nil
 }
 
-func sendListScenarioDecodeResponse(
+// sendListScenariosExplanation decodes the scenario matrices without the filter and sends which
+// scenario blocks and variants the filter excluded and why. Decoding failures have already been
+// sent with the scenarios so we don't send an explanation if we fail to decode.
+func (s *ServiceV1) sendListScenariosExplanation(
 	req *pb.ListScenariosRequest,
 	stream pb.EnosService_ListScenariosServer,
+) error {
+	ctx := stream.Context()
+
+	sf, err := flightplan.NewScenarioFilter(flightplan.WithScenarioFilterDecode(req.GetFilter()))
+	if err != nil {
+		return nil
+	}
+
+	_, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		req.GetWorkspace().GetFlightplan(),
+		flightplan.DecodeTargetScenariosMatrixOnly,
+		&pb.Scenario_Filter{SelectAll: &pb.Scenario_Filter_SelectAll{}},
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+	if diagnostics.HasErrors(decRes.GetDiagnostics()) || scenarioDecoder == nil {
+		return nil
+	}
+
+	iter := scenarioDecoder.Iterator()
+	if iter == nil {
+		return nil
+	}
+
+	if hclDiags := iter.Start(ctx); hclDiags.HasErrors() {
+		return nil
+	}
+	defer iter.Stop()
+
+	return stream.Send(&pb.EnosServiceListScenariosResponse{
+		Response: &pb.EnosServiceListScenariosResponse_Explanation{
+			Explanation: flightplan.ExplainScenarioFilter(
+				iter.Blocks().Sort(), iter.Skipped(), sf, maxExplainedExclusions,
+			),
+		},
+	})
+}
+
+func sendListScenarioDecodeResponse(
+	stream pb.EnosService_ListScenariosServer,
 	decRes *pb.DecodeResponse,
 	diags hcl.Diagnostics,
 ) error {
@@ -94,10 +371,9 @@ func sendListScenarioDecodeResponse(
 		decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(nil, diags)...)
 	}
 
-	if diagnostics.HasFailed(
-		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
-		decRes.GetDiagnostics(),
-	) {
+	// Diagnostics and skipped scenarios are sent so that they don't silently go missing from the
+	// list
+	if len(decRes.GetSkipped()) > 0 || len(decRes.GetDiagnostics()) > 0 {
 		err := stream.Send(&pb.EnosServiceListScenariosResponse{
 			Response: &pb.EnosServiceListScenariosResponse_Decode{
 				Decode: decRes,
@@ -109,3 +385,78 @@ func sendListScenarioDecodeResponse(
 
 	return nil
 }
+
+// sendListScenariosPage sends the page of sorted scenario references that starts at the offset,
+// followed by the page information.
+func sendListScenariosPage(
+	stream pb.EnosService_ListScenariosServer,
+	sorted []*pb.Ref_Scenario,
+	offset int,
+	pageSize int,
+	key string,
+) error {
+	end := len(sorted)
+	if pageSize > 0 {
+		end = min(offset+pageSize, len(sorted))
+	}
+	offset = min(offset, end)
+
+	for _, ref := range sorted[offset:end] {
+		err := stream.Send(&pb.EnosServiceListScenariosResponse{
+			Response: &pb.EnosServiceListScenariosResponse_Scenario{
+				Scenario: ref,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	page := &pb.EnosServiceListScenariosResponse_Page{
+		TotalScenarios: int64(len(sorted)),
+	}
+	if end < len(sorted) {
+		page.NextPageToken = encodeListScenariosPageToken(end, key)
+	}
+
+	return stream.Send(&pb.EnosServiceListScenariosResponse{
+		Response: &pb.EnosServiceListScenariosResponse_Page_{
+			Page: page,
+		},
+	})
+}
+
+// encodeListScenariosPageToken returns an opaque page token for the offset. The token includes the
+// decode cache key of the request so that it can't be used with another flight plan or filter.
+func encodeListScenariosPageToken(offset int, key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", offset, key)))
+}
+
+// decodeListScenariosPageToken returns the offset of the page token. An empty token is the first
+// page.
+func decodeListScenariosPageToken(token string, key string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, errors.New("the page token is malformed")
+	}
+
+	offsetStr, tokenKey, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return 0, errors.New("the page token is malformed")
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		return 0, errors.New("the page token is malformed")
+	}
+
+	if tokenKey != key {
+		return 0, errors.New("the page token does not belong to this flight plan, filter, and sort, it might have changed since the previous page")
+	}
+
+	return offset, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// testListScenariosStream is a server stream of a scenario list that collects the responses.
+type testListScenariosStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*pb.EnosServiceListScenariosResponse
+}
+
+func (s *testListScenariosStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testListScenariosStream) Send(res *pb.EnosServiceListScenariosResponse) error {
+	s.responses = append(s.responses, res)
+
+	return nil
+}
+
+// Test_ServiceV1_ListScenarios_PaginatedSkipped tests that paginated scenario lists send the
+// skipped scenarios of the decode with the first page.
+func Test_ServiceV1_ListScenarios_PaginatedSkipped(t *testing.T) {
+	t.Parallel()
+
+	svc, err := New()
+	require.NoError(t, err)
+	svc.decodeCacheEnabled = false
+
+	req := &pb.ListScenariosRequest{
+		Workspace: &pb.Workspace{
+			Flightplan: &pb.FlightPlan{
+				BaseDir: t.TempDir(),
+				EnosHcl: map[string][]byte{
+					"enos.hcl": []byte(`
+module "foo" {
+  source = "./foo"
+}
+
+scenario "test" {
+  matrix {
+    arch = ["amd64", "arm64", "s390x"]
+  }
+
+  step "foo" {
+    module = module.foo
+  }
+}
+`),
+				},
+				EnosSkipHcl: map[string][]byte{
+					"enos.skip.hcl": []byte(`
+skip {
+  scenario_filter = "test arch:s390x"
+  reason          = "the s390x AMI is broken"
+}
+`),
+				},
+			},
+		},
+		PageSize: 1,
+	}
+
+	stream := &testListScenariosStream{ctx: context.Background()}
+	require.NoError(t, svc.ListScenarios(req, stream))
+
+	require.Len(t, stream.responses, 3)
+	decode := stream.responses[0].GetDecode()
+	require.NotNil(t, decode)
+	require.Empty(t, decode.GetDiagnostics())
+	require.Len(t, decode.GetSkipped(), 1)
+	require.Equal(t, "the s390x AMI is broken", decode.GetSkipped()[0].GetReason())
+	require.Equal(t, "test arch:amd64", stream.responses[1].GetScenario().GetId().GetFilter())
+	page := stream.responses[2].GetPage()
+	require.NotNil(t, page)
+	require.EqualValues(t, 2, page.GetTotalScenarios())
+	require.NotEmpty(t, page.GetNextPageToken())
+
+	// Only the first page has the decode response.
+	req.PageToken = page.GetNextPageToken()
+	stream = &testListScenariosStream{ctx: context.Background()}
+	require.NoError(t, svc.ListScenarios(req, stream))
+
+	require.Len(t, stream.responses, 2)
+	require.Equal(t, "test arch:arm64", stream.responses[0].GetScenario().GetId().GetFilter())
+	require.Empty(t, stream.responses[1].GetPage().GetNextPageToken())
+}
@@ -0,0 +1,32 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// LockScenarios generates and initializes the Terraform modules of each scenario that has been
+// filtered for the workspace and locks their providers for the platforms of the workspace.
+func (s *ServiceV1) LockScenarios(
+	ctx context.Context,
+	req *pb.LockScenariosRequest,
+) (
+	*pb.LockScenariosResponse,
+	error,
+) {
+	res := &pb.LockScenariosResponse{}
+	res.Diagnostics, res.Decode, res.Operations = s.dispatch(
+		ctx,
+		req.GetFilter(),
+		&pb.Operation_Request{
+			Workspace: req.GetWorkspace(),
+			Value:     &pb.Operation_Request_Lock_{},
+		},
+	)
+
+	return res, nil
+}
@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/generate"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// MigrateOutDir migrates the generated modules of every scenario in the flight plan from the out
+// directory format and layout of the version of enos that generated them to the current format and
+// layout. Modules are migrated with their Terraform state so that scenarios that were launched with
+// a prior version of enos can still be destroyed. Directories of generated modules that no longer
+// belong to any scenario in the flight plan are returned as orphans and are left as they are.
+func (s *ServiceV1) MigrateOutDir(
+	ctx context.Context,
+	req *pb.MigrateOutDirRequest,
+) (
+	*pb.MigrateOutDirResponse,
+	error,
+) {
+	res := &pb.MigrateOutDirResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("MigrateOutDir", timer, time.Now())
+
+	refs, decRes, hclDiags, err := s.decodeScenarioRefs(ctx, &pb.ListScenariosRequest{
+		Workspace: req.GetWorkspace(),
+	}, s.decodeCache(req.GetWorkspace()), timer, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Decode = decRes
+	res.Diagnostics = diagnostics.FromHCL(nil, hclDiags)
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		append(decRes.GetDiagnostics(), res.GetDiagnostics()...),
+	) {
+		return res, nil
+	}
+
+	outDir := req.GetWorkspace().GetOutDir()
+	if outDir == "" {
+		outDir = filepath.Join(req.GetWorkspace().GetFlightplan().GetBaseDir(), ".enos")
+	}
+	layout := req.GetWorkspace().GetLayout()
+	fpHash := flightplan.Hash(req.GetWorkspace().GetFlightplan())
+
+	// The directories of the out directory that belong to scenarios in the flight plan, whether
+	// or not they've been generated.
+	known := map[string]bool{}
+	for _, ref := range refs {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(ref)
+
+		for _, nested := range []bool{false, true} {
+			for _, naming := range []pb.Layout_Naming{pb.Layout_NAMING_SLUG, pb.Layout_NAMING_UID} {
+				dir := generate.ModuleDir(outDir, scenario, &pb.Layout{Naming: naming, Nested: nested})
+				known[dir] = true
+				known[filepath.Dir(dir)] = true
+			}
+		}
+
+		migration, err := generate.MigrateOutDir(outDir, scenario, layout, fpHash, req.GetDryRun())
+		if err != nil {
+			res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+
+			continue
+		}
+
+		if migration != nil {
+			res.Migrations = append(res.GetMigrations(), &pb.MigrateOutDirResponse_Migration{
+				Scenario:    ref,
+				From:        migration.From,
+				To:          migration.To,
+				FromVersion: uint32(migration.FromVersion),
+				ToVersion:   uint32(migration.ToVersion),
+			})
+		}
+	}
+
+	slices.SortFunc(res.GetMigrations(), func(a, b *pb.MigrateOutDirResponse_Migration) int {
+		return strings.Compare(a.GetFrom(), b.GetFrom())
+	})
+
+	res.Orphans = orphanedModuleDirs(outDir, known)
+
+	return res, nil
+}
+
+// orphanedModuleDirs returns the directories of generated modules in the out directory that don't
+// belong to any of the known directories. Modules are either in the out directory or nested in the
+// directory of their scenario.
+func orphanedModuleDirs(outDir string, known map[string]bool) []string {
+	orphans := []string{}
+
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		return orphans
+	}
+
+	isModuleDir := func(dir string) bool {
+		_, err := os.Stat(filepath.Join(dir, "scenario.tf"))
+
+		return err == nil
+	}
+
+	for _, entry := range entries {
+		// Fixture modules and reused .terraform directories aren't modules of scenarios
+		dir := filepath.Join(outDir, entry.Name())
+		if !entry.IsDir() || entry.Name() == generate.ReuseDirName || entry.Name() == "fixtures" {
+			continue
+		}
+
+		if isModuleDir(dir) {
+			if !known[dir] {
+				orphans = append(orphans, dir)
+			}
+
+			continue
+		}
+
+		nested, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, n := range nested {
+			if sub := filepath.Join(dir, n.Name()); n.IsDir() && !known[sub] && isModuleDir(sub) {
+				orphans = append(orphans, sub)
+			}
+		}
+	}
+
+	slices.Sort(orphans)
+
+	return orphans
+}
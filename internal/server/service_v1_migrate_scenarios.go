@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/generate"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// MigrateScenarios moves the generated modules of the scenarios that match the filter into the
+// directories of the layout of the workspace. Modules are moved with their Terraform state so that
+// scenarios that have been launched can still be destroyed after the layout has changed.
+func (s *ServiceV1) MigrateScenarios(
+	ctx context.Context,
+	req *pb.MigrateScenariosRequest,
+) (
+	*pb.MigrateScenariosResponse,
+	error,
+) {
+	res := &pb.MigrateScenariosResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("MigrateScenarios", timer, time.Now())
+
+	refs, decRes, hclDiags, err := s.decodeScenarioRefs(ctx, &pb.ListScenariosRequest{
+		Workspace: req.GetWorkspace(),
+		Filter:    req.GetFilter(),
+	}, s.decodeCache(req.GetWorkspace()), timer, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Decode = decRes
+	res.Diagnostics = diagnostics.FromHCL(nil, hclDiags)
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		append(decRes.GetDiagnostics(), res.GetDiagnostics()...),
+	) {
+		return res, nil
+	}
+
+	outDir := req.GetWorkspace().GetOutDir()
+	if outDir == "" {
+		outDir = filepath.Join(req.GetWorkspace().GetFlightplan().GetBaseDir(), ".enos")
+	}
+
+	for _, ref := range refs {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(ref)
+
+		from, to, err := generate.MigrateModuleDir(outDir, scenario, req.GetWorkspace().GetLayout(), req.GetDryRun())
+		if err != nil {
+			res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+
+			continue
+		}
+
+		if from != "" {
+			res.Moves = append(res.GetMoves(), &pb.MigrateScenariosResponse_Move{
+				Scenario: ref,
+				From:     from,
+				To:       to,
+			})
+		}
+	}
+
+	slices.SortFunc(res.GetMoves(), func(a, b *pb.MigrateScenariosResponse_Move) int {
+		return strings.Compare(a.GetFrom(), b.GetFrom())
+	})
+
+	return res, nil
+}
@@ -5,6 +5,7 @@ package server
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
@@ -17,9 +18,29 @@ func (s *ServiceV1) Operation(
 	req *pb.OperationRequest,
 ) (*pb.OperationResponse, error) {
 	res, err := s.operator.Response(req.GetOp())
+	if err == nil {
+		err = verifyOperationProject(req.GetOp(), res.GetOp())
+	}
+	if err != nil {
+		res = nil
+	}
 
 	return &pb.OperationResponse{
 		Diagnostics: diagnostics.FromErr(err),
 		Response:    res,
 	}, nil
 }
+
+// verifyOperationProject ensures that operations can only be retrieved by requests for their
+// project. Requests that aren't scoped to a project can only retrieve operations that aren't either.
+func verifyOperationProject(req *pb.Ref_Operation, op *pb.Ref_Operation) error {
+	if req.GetProject() == op.GetProject() {
+		return nil
+	}
+
+	if req.GetProject() == "" {
+		return fmt.Errorf("operation %s was not found", req.GetId())
+	}
+
+	return fmt.Errorf("operation %s was not found in project %s", req.GetId(), req.GetProject())
+}
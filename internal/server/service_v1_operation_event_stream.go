@@ -20,6 +20,20 @@ func (s *ServiceV1) OperationEventStream(
 ) error {
 	log := s.log.With(operation.ReferenceDebugArgs(req.GetOp())...)
 
+	if req.GetOp().GetProject() != "" {
+		res, err := s.operator.Response(req.GetOp())
+		if err == nil {
+			err = verifyOperationProject(req.GetOp(), res.GetOp())
+		}
+		if err != nil {
+			log.Error("failed to initialize stream", "error", err)
+
+			return stream.Send(&pb.OperationEventStreamResponse{
+				Diagnostics: diagnostics.FromErr(err),
+			})
+		}
+	}
+
 	sub, unsub, err := s.operator.Stream(req.GetOp())
 	log = log.With("subscriber_id", sub.ID)
 
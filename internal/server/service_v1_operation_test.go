@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/hashicorp/enos/internal/client"
+	"github.com/hashicorp/enos/internal/operation"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_verifyOperationProject tests that operations can only be retrieved by requests for their
+// project.
+func Test_verifyOperationProject(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		req *pb.Ref_Operation
+		op  *pb.Ref_Operation
+		err string
+	}{
+		"no project": {
+			req: &pb.Ref_Operation{Id: "op"},
+			op:  &pb.Ref_Operation{Id: "op"},
+		},
+		"same project": {
+			req: &pb.Ref_Operation{Id: "op", Project: "vault"},
+			op:  &pb.Ref_Operation{Id: "op", Project: "vault"},
+		},
+		"other project": {
+			req: &pb.Ref_Operation{Id: "op", Project: "consul"},
+			op:  &pb.Ref_Operation{Id: "op", Project: "vault"},
+			err: "operation op was not found in project consul",
+		},
+		"request without project": {
+			req: &pb.Ref_Operation{Id: "op"},
+			op:  &pb.Ref_Operation{Id: "op", Project: "vault"},
+			err: "operation op was not found",
+		},
+		"operation without project": {
+			req: &pb.Ref_Operation{Id: "op", Project: "vault"},
+			op:  &pb.Ref_Operation{Id: "op"},
+			err: "operation op was not found in project vault",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyOperationProject(test.req, test.op)
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+// Test_ServiceV1_Operations_Projects tests that clients that select a project only see the
+// operations of the project, even when projects share an out directory.
+func Test_ServiceV1_Operations_Projects(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	for id, project := range map[string]string{
+		"aaaa1111": "vault",
+		"aaaa2222": "consul",
+		"bbbb3333": "",
+	} {
+		testWriteHistory(t, outDir, &pb.Operation_Response{
+			Op:     &pb.Ref_Operation{Id: id, Project: project},
+			Status: pb.Operation_STATUS_COMPLETED,
+			Value:  &pb.Operation_Response_Launch_{Launch: &pb.Operation_Response_Launch{}},
+		})
+	}
+
+	listenURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	svc, err := New(WithGRPCListenURL(listenURL))
+	require.NoError(t, err)
+	for _, name := range []string{"vault", "consul"} {
+		_, err := svc.projects.register(&pb.Project{Name: name, Dir: t.TempDir(), OutDir: outDir})
+		require.NoError(t, err)
+	}
+	cfg, err := svc.Start(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = svc.Stop() })
+
+	for desc, test := range map[string]struct {
+		project string
+		id      string
+		other   string
+	}{
+		"vault": {
+			project: "vault",
+			id:      "aaaa1111",
+			other:   "aaaa2222",
+		},
+		"consul": {
+			project: "consul",
+			id:      "aaaa2222",
+			other:   "aaaa1111",
+		},
+		"no project": {
+			id:    "bbbb3333",
+			other: "aaaa1111",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			conn, err := client.Connect(context.Background(),
+				client.WithGRPCListenAddr(cfg.ListenAddr),
+				client.WithProject(test.project),
+			)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+
+			// Requests that don't select a project use the out directory, projects use their own.
+			ws := func() *pb.Workspace {
+				if test.project == "" {
+					return &pb.Workspace{OutDir: outDir}
+				}
+
+				return &pb.Workspace{}
+			}
+
+			list, err := conn.Client.ListOperations(context.Background(), &pb.ListOperationsRequest{
+				Workspace: ws(),
+			})
+			require.NoError(t, err)
+			require.Empty(t, list.GetDiagnostics())
+			require.Len(t, list.GetOperations(), 1)
+			require.Equal(t, test.id, list.GetOperations()[0].GetOp().GetId())
+			require.Equal(t, test.project, list.GetOperations()[0].GetOp().GetProject())
+
+			// The prefix of the ID only matches the operation of the project.
+			show, err := conn.Client.ShowOperation(context.Background(), &pb.ShowOperationRequest{
+				Workspace: ws(),
+				Id:        test.id[:4],
+			})
+			require.NoError(t, err)
+			require.Empty(t, show.GetDiagnostics())
+			require.Equal(t, test.id, show.GetOperation().GetOp().GetId())
+
+			show, err = conn.Client.ShowOperation(context.Background(), &pb.ShowOperationRequest{
+				Workspace: ws(),
+				Id:        test.other,
+			})
+			require.NoError(t, err)
+			require.Nil(t, show.GetOperation())
+			require.NotEmpty(t, show.GetDiagnostics())
+			require.Contains(t, show.GetDiagnostics()[0].GetSummary(), "operation "+test.other+" ")
+
+			diff, err := conn.Client.DiffOperations(context.Background(), &pb.DiffOperationsRequest{
+				Workspace: ws(),
+				From:      test.id,
+				To:        test.other,
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, diff.GetDiagnostics())
+		})
+	}
+}
+
+// testWriteHistory writes the response of an operation to the history of the out directory.
+func testWriteHistory(t *testing.T, outDir string, res *pb.Operation_Response) {
+	t.Helper()
+
+	dir := operation.HistoryDir(outDir)
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	b, err := protojson.Marshal(res)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, res.GetOp().GetId()+".json"), b, 0o600))
+}
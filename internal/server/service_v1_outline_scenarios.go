@@ -7,13 +7,16 @@ import (
 	"context"
 	"errors"
 	"slices"
+	"time"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/operation"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
 
-// OutlineScenarios returns an outline of scenarios.
+// OutlineScenarios returns an outline of scenarios. The outlines are sorted by the sort order of
+// the request.
 func (s *ServiceV1) OutlineScenarios(
 	ctx context.Context,
 	req *pb.OutlineScenariosRequest,
@@ -22,12 +25,18 @@ func (s *ServiceV1) OutlineScenarios(
 	error,
 ) {
 	res := &pb.OutlineScenariosResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("OutlineScenarios", timer, time.Now())
 
 	fp, scenarioDecoder, decRes := flightplan.DecodeProto(
 		ctx,
 		req.GetWorkspace().GetFlightplan(),
 		flightplan.DecodeTargetScenariosOutlines,
 		req.GetFilter(),
+		flightplan.WithDecoderCache(s.decodeCache(req.GetWorkspace())),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
 	)
 	res.Decode = decRes
 
@@ -68,6 +77,10 @@ func (s *ServiceV1) OutlineScenarios(
 		}
 	}
 
+	if req.GetSort() != pb.Scenario_SORT_UNSPECIFIED {
+		s.sortOutlineScenarios(req, res.GetOutlines())
+	}
+
 	verifies := []*pb.Quality{}
 	for _, qual := range qualities {
 		verifies = append(verifies, qual)
@@ -78,3 +91,17 @@ func (s *ServiceV1) OutlineScenarios(
 
 	return res, nil
 }
+
+// sortOutlineScenarios sorts the scenario outlines by the sort order of the request.
+func (s *ServiceV1) sortOutlineScenarios(req *pb.OutlineScenariosRequest, outlines []*pb.Scenario_Outline) {
+	var durations *flightplan.ScenarioDurations
+	if req.GetSort() == pb.Scenario_SORT_DURATION {
+		var err error
+		durations, err = operation.HistoryDurations(historyOutDir(req.GetWorkspace()), req.GetWorkspace().GetProject())
+		if err != nil {
+			s.log.Warn("unable to read the complete operation history", "error", err)
+		}
+	}
+
+	flightplan.SortScenarioOutlines(outlines, req.GetSort(), durations)
+}
@@ -23,7 +23,9 @@ func (s *ServiceV1) OutputScenarios(
 		req.GetFilter(),
 		&pb.Operation_Request{
 			Workspace: req.GetWorkspace(),
-			Value:     &pb.Operation_Request_Output_{},
+			Value: &pb.Operation_Request_Output_{
+				Output: &pb.Operation_Request_Output{ShowSensitive: req.GetShowSensitive()},
+			},
 		},
 	)
 
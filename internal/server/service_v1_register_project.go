@@ -0,0 +1,39 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// RegisterProject registers a new project with the server.
+func (s *ServiceV1) RegisterProject(
+	ctx context.Context,
+	req *pb.RegisterProjectRequest,
+) (
+	*pb.RegisterProjectResponse,
+	error,
+) {
+	proj, err := s.projects.register(req.GetProject())
+	if err != nil {
+		s.log.Error("failed to register project", "error", err)
+
+		return &pb.RegisterProjectResponse{
+			Diagnostics: diagnostics.FromErr(err),
+		}, nil
+	}
+
+	s.log.Info("registered project",
+		"name", proj.GetName(),
+		"dir", proj.GetDir(),
+		"out_dir", proj.GetOutDir(),
+	)
+
+	return &pb.RegisterProjectResponse{
+		Project: proj,
+	}, nil
+}
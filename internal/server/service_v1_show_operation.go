@@ -0,0 +1,39 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/operation"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowOperation shows an operation of the project of the workspace that has finished in the out
+// directory of the workspace.
+func (s *ServiceV1) ShowOperation(
+	ctx context.Context,
+	req *pb.ShowOperationRequest,
+) (
+	*pb.ShowOperationResponse,
+	error,
+) {
+	res := &pb.ShowOperationResponse{}
+
+	op, path, err := operation.ReadHistory(
+		historyOutDir(req.GetWorkspace()), req.GetWorkspace().GetProject(), req.GetId(),
+	)
+	if err != nil {
+		res.Diagnostics = diagnostics.FromErr(err)
+
+		return res, nil
+	}
+
+	res.Operation = op
+	res.Type = operation.ResponseTypeString(op)
+	res.Path = path
+
+	return res, nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioAt returns the scenario, step, output, block, and attribute that enclose a position
+// of a flight plan file and the resolved variants of the enclosing scenario.
+func (s *ServiceV1) ShowScenarioAt(
+	ctx context.Context,
+	req *pb.ShowScenarioAtRequest,
+) (
+	*pb.ShowScenarioAtResponse,
+	error,
+) {
+	res := &pb.ShowScenarioAtResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("ShowScenarioAt", timer, time.Now())
+
+	fp := req.GetWorkspace().GetFlightplan()
+	path := req.GetFile()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(fp.GetBaseDir(), path)
+	}
+	path = filepath.Clean(path)
+
+	var src []byte
+	for name, b := range fp.GetEnosHcl() {
+		if filepath.Clean(name) == path {
+			src = b
+			break
+		}
+	}
+	if src == nil {
+		res.Diagnostics = diagnostics.FromErr(fmt.Errorf(
+			"%s is not a flight plan file in %s", req.GetFile(), fp.GetBaseDir(),
+		))
+
+		return res, nil
+	}
+
+	enclosing, hclDiags := flightplan.EnclosingAt(src, path, int(req.GetLine()), int(req.GetColumn()))
+	res.Diagnostics = diagnostics.FromHCL(nil, hclDiags)
+	if hclDiags.HasErrors() {
+		return res, nil
+	}
+	res.Enclosing = enclosing
+
+	if enclosing.GetScenario() == "" {
+		return res, nil
+	}
+
+	filter, err := flightplan.NewScenarioFilter(flightplan.WithScenarioFilterName(enclosing.GetScenario()))
+	if err != nil {
+		res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
+
+		return res, nil
+	}
+
+	export, decRes, diags := s.exportFlightPlan(ctx, req.GetWorkspace(), filter.Proto(), timer)
+	res.Decode = decRes
+	res.Diagnostics = append(res.GetDiagnostics(), diags...)
+	if export == nil {
+		return res, nil
+	}
+
+	for _, scenario := range export.GetScenarios() {
+		switch {
+		case enclosing.GetStep() != "":
+			scenario.Outputs = nil
+			steps := scenario.GetSteps()
+			scenario.Steps = nil
+			for _, step := range steps {
+				if step.GetName() == enclosing.GetStep() {
+					scenario.Steps = append(scenario.Steps, step)
+				}
+			}
+		case enclosing.GetOutput() != "":
+			scenario.Steps = nil
+			outputs := scenario.GetOutputs()
+			scenario.Outputs = nil
+			for _, output := range outputs {
+				if output.GetName() == enclosing.GetOutput() {
+					scenario.Outputs = append(scenario.Outputs, output)
+				}
+			}
+		}
+		res.Scenarios = append(res.GetScenarios(), scenario)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioMatrix decodes the matrix of the scenario of the filter without the variants of
+// the filter and returns its dimensions, how many vectors remain after its excludes and after the
+// filter, and a preview of the filtered vectors.
+func (s *ServiceV1) ShowScenarioMatrix(
+	ctx context.Context,
+	req *pb.ShowScenarioMatrixRequest,
+) (
+	*pb.ShowScenarioMatrixResponse,
+	error,
+) {
+	res := &pb.ShowScenarioMatrixResponse{Decode: &pb.DecodeResponse{}}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("ShowScenarioMatrix", timer, time.Now())
+
+	name := req.GetFilter().GetName()
+	if name == "" {
+		res.Diagnostics = diagnostics.FromErr(errors.New("a scenario name is required"))
+
+		return res, nil
+	}
+
+	sf, err := flightplan.NewScenarioFilter(flightplan.WithScenarioFilterDecode(req.GetFilter()))
+	if err != nil {
+		res.Diagnostics = diagnostics.FromErr(err)
+
+		return res, nil
+	}
+
+	// Decode the matrix without the variants of the filter so that we can count the vectors
+	// that the filter removes.
+	_, scenarioDecoder, decRes := flightplan.DecodeProto(
+		ctx,
+		req.GetWorkspace().GetFlightplan(),
+		flightplan.DecodeTargetScenariosMatrixOnly,
+		&pb.Scenario_Filter{Name: name},
+		flightplan.WithDecoderCache(s.decodeCache(req.GetWorkspace())),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+	res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), decRes.GetDiagnostics()...)
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		res.GetDecode().GetDiagnostics(),
+	) {
+		return res, nil
+	}
+
+	if scenarioDecoder == nil {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return res, nil
+	}
+
+	iter := scenarioDecoder.Iterator()
+	if iter == nil {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromErr(errors.New(
+			"failed to decode scenarios",
+		))...)
+
+		return res, nil
+	}
+
+	if moreDiags := iter.Start(ctx); moreDiags.HasErrors() {
+		res.Decode.Diagnostics = append(res.GetDecode().GetDiagnostics(), diagnostics.FromHCL(nil, moreDiags)...)
+
+		return res, nil
+	}
+	defer iter.Stop()
+
+	block, ok := iter.Blocks().FindByName(name)
+	if !ok {
+		res.Diagnostics = diagnostics.FromErr(fmt.Errorf("no scenario named %s was found", name))
+
+		return res, nil
+	}
+
+	res.Summary = flightplan.SummarizeMatrix(block, sf, int(req.GetPreview()))
+
+	return res, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// BackupScenarioStates backs up the state and variables of the Terraform modules of each scenario
+// that has been filtered for the workspace into timestamped snapshots.
+func (s *ServiceV1) BackupScenarioStates(
+	ctx context.Context,
+	req *pb.BackupScenarioStatesRequest,
+) (
+	*pb.BackupScenarioStatesResponse,
+	error,
+) {
+	res := &pb.BackupScenarioStatesResponse{}
+	res.Diagnostics, res.Decode, res.Operations = s.dispatch(
+		ctx,
+		req.GetFilter(),
+		&pb.Operation_Request{
+			Workspace: req.GetWorkspace(),
+			Value:     &pb.Operation_Request_StateBackup_{},
+		},
+	)
+
+	return res, nil
+}
+
+// RestoreScenarioStates restores a snapshot of the state and variables of the Terraform modules of
+// each scenario that has been filtered for the workspace.
+func (s *ServiceV1) RestoreScenarioStates(
+	ctx context.Context,
+	req *pb.RestoreScenarioStatesRequest,
+) (
+	*pb.RestoreScenarioStatesResponse,
+	error,
+) {
+	res := &pb.RestoreScenarioStatesResponse{}
+	res.Diagnostics, res.Decode, res.Operations = s.dispatch(
+		ctx,
+		req.GetFilter(),
+		&pb.Operation_Request{
+			Workspace: req.GetWorkspace(),
+			Value: &pb.Operation_Request_StateRestore_{
+				StateRestore: &pb.Operation_Request_StateRestore{
+					Snapshot: req.GetSnapshot(),
+				},
+			},
+		},
+	)
+
+	return res, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// StreamObserveSample streams an observation of a sample. The elements of each subset are sent as
+// soon as they have been expanded.
+func (s *ServiceV1) StreamObserveSample(
+	req *pb.ObserveSampleRequest,
+	stream pb.EnosService_StreamObserveSampleServer,
+) error {
+	sendDecode := func(decRes *pb.DecodeResponse) error {
+		if len(decRes.GetDiagnostics()) == 0 {
+			return nil
+		}
+
+		return stream.Send(&pb.EnosServiceObserveSampleResponse{
+			Response: &pb.EnosServiceObserveSampleResponse_Decode{
+				Decode: decRes,
+			},
+		})
+	}
+
+	sampleReq, err := flightplan.NewSampleObservationReq(
+		flightplan.WithSampleObservationReqWorkSpace(req.GetWorkspace()),
+		flightplan.WithSampleObservationReqFilter(req.GetFilter()),
+		flightplan.WithSampleObservationReqFunc(flightplan.SampleFuncPurposiveStratified),
+	)
+	if err != nil {
+		return sendDecode(&pb.DecodeResponse{Diagnostics: diagnostics.FromErr(err)})
+	}
+
+	err = stream.Send(&pb.EnosServiceObserveSampleResponse{
+		Response: &pb.EnosServiceObserveSampleResponse_Filter{
+			Filter: req.GetFilter(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, decRes, err := sampleReq.ObserveEach(stream.Context(), func(elements []*pb.Sample_Element) error {
+		for _, elm := range elements {
+			err := stream.Send(&pb.EnosServiceObserveSampleResponse{
+				Response: &pb.EnosServiceObserveSampleResponse_Element{
+					Element: elm,
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return sendDecode(decRes)
+}
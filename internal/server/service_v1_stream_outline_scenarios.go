@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// StreamOutlineScenarios streams an outline of each scenario as soon as it has been decoded. Unlike
+// OutlineScenarios we never hold all of the scenarios in memory, unless the request has a sort
+// order and we have to decode every scenario before we can send the first of them.
+func (s *ServiceV1) StreamOutlineScenarios(
+	req *pb.OutlineScenariosRequest,
+	stream pb.EnosService_StreamOutlineScenariosServer,
+) error {
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("StreamOutlineScenarios", timer, time.Now())
+
+	_, scenarioDecoder, decRes := flightplan.DecodeProto(
+		stream.Context(),
+		req.GetWorkspace().GetFlightplan(),
+		flightplan.DecodeTargetScenariosOutlines,
+		req.GetFilter(),
+		flightplan.WithDecoderCache(s.decodeCache(req.GetWorkspace())),
+		flightplan.WithDecoderTimer(timer),
+		flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+		flightplan.WithDecoderLogger(s.log.Named("decoder")),
+	)
+
+	sendDecode := func(diags hcl.Diagnostics) error {
+		if len(diags) > 0 {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(nil, diags)...)
+		}
+
+		if len(decRes.GetDiagnostics()) == 0 {
+			return nil
+		}
+
+		return stream.Send(&pb.EnosServiceOutlineScenariosResponse{
+			Response: &pb.EnosServiceOutlineScenariosResponse_Decode{
+				Decode: decRes,
+			},
+		})
+	}
+
+	if diagnostics.HasFailed(
+		req.GetWorkspace().GetTfExecCfg().GetFailOnWarnings(),
+		decRes.GetDiagnostics(),
+	) {
+		return sendDecode(nil)
+	}
+
+	diags := hcl.Diagnostics{}
+	if scenarioDecoder == nil {
+		return sendDecode(diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "unable to decode scenarios",
+		}))
+	}
+
+	iter := scenarioDecoder.Iterator()
+	if iter == nil {
+		return sendDecode(diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "unable to decode scenarios",
+		}))
+	}
+
+	if moreDiags := iter.Start(stream.Context()); moreDiags.HasErrors() {
+		return sendDecode(moreDiags)
+	}
+	defer iter.Stop()
+
+	send := func(out *pb.Scenario_Outline) error {
+		return stream.Send(&pb.EnosServiceOutlineScenariosResponse{
+			Response: &pb.EnosServiceOutlineScenariosResponse_Outline{
+				Outline: out,
+			},
+		})
+	}
+
+	sorted := req.GetSort() != pb.Scenario_SORT_UNSPECIFIED
+	outlines := []*pb.Scenario_Outline{}
+	for iter.Next(stream.Context()) {
+		res := iter.Scenario()
+		if res == nil {
+			return sendDecode(diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "unable to retrieve scenario from decoder",
+			}))
+		}
+
+		if res.Diagnostics.HasErrors() {
+			return sendDecode(res.Diagnostics)
+		}
+
+		out := res.Scenario.Outline()
+		if out == nil {
+			continue
+		}
+		if res.ScenarioDecodeRequest != nil && res.ScenarioBlock != nil {
+			out.Matrix = res.ScenarioBlock.MatrixBlock.GetOriginal().Proto()
+		}
+
+		if sorted {
+			outlines = append(outlines, out)
+
+			continue
+		}
+
+		if err := send(out); err != nil {
+			return err
+		}
+	}
+
+	if sorted {
+		if moreDiags := iter.Diagnostics(); moreDiags.HasErrors() {
+			return sendDecode(moreDiags)
+		}
+
+		s.sortOutlineScenarios(req, outlines)
+		for _, out := range outlines {
+			if err := send(out); err != nil {
+				return err
+			}
+		}
+	}
+
+	return sendDecode(iter.Diagnostics())
+}
@@ -0,0 +1,25 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// UnregisterProject removes a project from the server. Operations that have already been
+// dispatched for the project are not affected.
+func (s *ServiceV1) UnregisterProject(
+	ctx context.Context,
+	req *pb.UnregisterProjectRequest,
+) (
+	*pb.UnregisterProjectResponse,
+	error,
+) {
+	return &pb.UnregisterProjectResponse{
+		Diagnostics: diagnostics.FromErr(s.projects.unregister(req.GetName())),
+	}, nil
+}
@@ -8,12 +8,13 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"runtime"
+	"time"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan"
 	"github.com/hashicorp/enos/internal/memory"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+	"github.com/hashicorp/hcl/v2"
 )
 
 // ValidateScenariosConfiguration validates a flight plan config.
@@ -25,6 +26,8 @@ func (s *ServiceV1) ValidateScenariosConfiguration(
 	error,
 ) {
 	res := &pb.ValidateScenariosConfigurationResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("ValidateScenariosConfiguration", timer, time.Now())
 
 	if req.GetNoValidateSamples() && req.GetNoValidateScenarios() {
 		res.Diagnostics = diagnostics.FromErr(errors.New("cannot validate when given both no_validate_scenarios and no_validate_samples"))
@@ -34,11 +37,16 @@ func (s *ServiceV1) ValidateScenariosConfiguration(
 	var decRes *pb.DecodeResponse
 	var scenarioDecoder *flightplan.ScenarioDecoder
 	if !req.GetNoValidateScenarios() {
-		_, scenarioDecoder, decRes = flightplan.DecodeProto(
+		var fp *flightplan.FlightPlan
+		fp, scenarioDecoder, decRes = flightplan.DecodeProto(
 			ctx,
 			req.GetWorkspace().GetFlightplan(),
 			flightplan.DecodeTargetAll,
 			req.GetFilter(),
+			flightplan.WithDecoderCache(s.decodeCache(req.GetWorkspace())),
+			flightplan.WithDecoderTimer(timer),
+			flightplan.WithDecoderResourceLimits(req.GetWorkspace().GetLimits()),
+			flightplan.WithDecoderLogger(s.log.Named("decoder")),
 		)
 		res.Decode = decRes
 
@@ -57,6 +65,19 @@ func (s *ServiceV1) ValidateScenariosConfiguration(
 			return res, nil
 		}
 
+		// We can only find unused blocks if we've decoded every scenario.
+		var usage *flightplan.UsageTracker
+		filter, err := flightplan.NewScenarioFilter(flightplan.WithScenarioFilterDecode(req.GetFilter()))
+		if err == nil && filter.SelectsAll() {
+			usage = flightplan.NewUsageTracker(fp)
+		}
+		requirements := flightplan.NewProviderRequirementsValidator(fp)
+		requirementsDiags := hcl.Diagnostics{}
+		var modules *flightplan.RemoteModuleTracker
+		if req.GetVerifyModuleSources() {
+			modules = flightplan.NewRemoteModuleTracker(fp)
+		}
+
 		iter := scenarioDecoder.Iterator()
 		if iter == nil {
 			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromErr(errors.New(
@@ -93,12 +114,13 @@ func (s *ServiceV1) ValidateScenariosConfiguration(
 
 				return res, nil
 			}
+
+			usage.Track(scenarioResponse.Scenario)
+			requirementsDiags = requirementsDiags.Extend(requirements.Validate(scenarioResponse.Scenario))
+			modules.Track(scenarioResponse.Scenario)
 		}
 
 		if iter.Count() == 0 {
-			filter, err := flightplan.NewScenarioFilter(
-				flightplan.WithScenarioFilterDecode(req.GetFilter()),
-			)
 			if err != nil {
 				res.Diagnostics = append(res.GetDiagnostics(), diagnostics.FromErr(err)...)
 			} else {
@@ -115,6 +137,22 @@ func (s *ServiceV1) ValidateScenariosConfiguration(
 
 			return res, nil
 		}
+
+		if moreDiags := modules.Verify(ctx, flightplan.NewModuleFetcher()); len(moreDiags) > 0 {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, moreDiags)...)
+		}
+
+		if len(requirementsDiags) > 0 {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, requirementsDiags)...)
+		}
+
+		severity := hcl.DiagWarning
+		if req.GetUnusedAsErrors() {
+			severity = hcl.DiagError
+		}
+		if moreDiags := usage.Unused(severity); len(moreDiags) > 0 {
+			decRes.Diagnostics = append(decRes.GetDiagnostics(), diagnostics.FromHCL(fp.Files, moreDiags)...)
+		}
 	}
 
 	if !req.GetNoValidateSamples() {
@@ -129,13 +167,13 @@ func (s *ServiceV1) ValidateScenariosConfiguration(
 			// Validating samples can be very memory intensive since we're creating lots of matrix
 			// products for each sample and subset frame. Set our worker count to either the number of
 			// CPUs, or a worker for 1 GiB of available memory, whichever is lower. Make sure we always
-			// provision at least one worker.
+			// provision at least one worker and no more than our decode worker limit.
 			flightplan.WithSampleValidationWorkerCount(
 				int(
 					math.Max(
 						float64(1),
 						math.Min(
-							float64(runtime.NumCPU()),
+							float64(flightplan.NewResourceLimits(req.GetWorkspace().GetLimits()).Workers()),
 							float64(stat.Available())/math.Pow(2, 30)),
 					),
 				),
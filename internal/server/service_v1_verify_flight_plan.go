@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// VerifyFlightPlan decodes and exports the current flight plan and returns how it semantically
+// differs from the previous export in the request.
+func (s *ServiceV1) VerifyFlightPlan(
+	ctx context.Context,
+	req *pb.VerifyFlightPlanRequest,
+) (
+	*pb.VerifyFlightPlanResponse,
+	error,
+) {
+	res := &pb.VerifyFlightPlanResponse{}
+	timer := s.decodeTimer()
+	defer s.recordDecodeTiming("VerifyFlightPlan", timer, time.Now())
+
+	if req.GetExport() == nil {
+		res.Diagnostics = diagnostics.FromErr(errors.New("an export of the flight plan is required"))
+
+		return res, nil
+	}
+
+	export, decRes, diags := s.exportFlightPlan(ctx, req.GetWorkspace(), req.GetFilter(), timer)
+	res.Decode = decRes
+	res.Diagnostics = diags
+	if export == nil {
+		return res, nil
+	}
+
+	res.Differences = flightplan.DiffExports(req.GetExport(), export)
+
+	return res, nil
+}
@@ -0,0 +1,324 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package stepresult
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// DefaultProgressInterval is the default of how often the progress of the steps is sent when their
+// status hasn't changed.
+const DefaultProgressInterval = time.Second
+
+var (
+	// resourceProgressRe matches the lines of the human readable output of Terraform for the
+	// progress of a resource, e.g. "module.vpc.aws_vpc.vpc: Creating...".
+	resourceProgressRe = regexp.MustCompile(`^(module\.\S.*?): (Creating|Destroying|Modifying|Reading|Creation complete|Destruction complete|Modifications complete|Read complete|Refreshing state|(?:Creation|Destruction|Modifications|Read) errored)`)
+	// moduleInstalledRe matches the line of the output of init for a module that is installed.
+	moduleInstalledRe = regexp.MustCompile(`^- (\S+) in `)
+	// moduleDownloadingRe matches the line of the output of init for a module that is downloaded.
+	moduleDownloadingRe = regexp.MustCompile(`^Downloading .* for (\S+?)\.\.\.$`)
+)
+
+// Progress parses the human readable output of a Terraform command into the progress of the scenario
+// steps. Progress is the writer of stdout and Stderr returns the writer of stderr. The update
+// function is called with the progress whenever the status of a step changes and otherwise at most
+// once per interval. It's called while the output is written so it must not block for long.
+type Progress struct {
+	mu         sync.Mutex
+	phase      string
+	update     func(*pb.Terraform_StepProgress)
+	interval   time.Duration
+	lastUpdate time.Time
+	steps      map[string]*progressStep
+	stdout     []byte
+	stderr     []byte
+	// inError is whether we're in an error diagnostic of stderr and haven't seen its resource yet.
+	inError bool
+	done    bool
+}
+
+// progressStep is the progress of a step. Running has the addresses of resources that are in
+// progress.
+type progressStep struct {
+	status  pb.Operation_Status
+	done    int32
+	running map[string]struct{}
+}
+
+// ProgressOpt is a functional option for NewProgress.
+type ProgressOpt func(*Progress)
+
+// NewProgress takes the phase, i.e. the Terraform command, the update function and options and
+// returns a new Progress.
+func NewProgress(phase string, update func(*pb.Terraform_StepProgress), opts ...ProgressOpt) *Progress {
+	p := &Progress{
+		phase:    phase,
+		update:   update,
+		interval: DefaultProgressInterval,
+		steps:    map[string]*progressStep{},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// WithProgressInterval sets how often the progress is sent when the status of the steps hasn't
+// changed.
+func WithProgressInterval(interval time.Duration) ProgressOpt {
+	return func(p *Progress) {
+		p.interval = interval
+	}
+}
+
+// Write parses the stdout of Terraform.
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stdout = p.writeLines(p.stdout, b, p.parseStdout)
+
+	return len(b), nil
+}
+
+// Stderr returns the writer of the stderr of Terraform.
+func (p *Progress) Stderr() *ProgressStderr {
+	return &ProgressStderr{progress: p}
+}
+
+// ProgressStderr parses the stderr of Terraform for the steps that failed.
+type ProgressStderr struct {
+	progress *Progress
+}
+
+// Write parses the stderr of Terraform.
+func (s *ProgressStderr) Write(b []byte) (int, error) {
+	p := s.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stderr = p.writeLines(p.stderr, b, p.parseStderr)
+
+	return len(b), nil
+}
+
+// Finish finishes the progress after the Terraform command has exited. Steps that are still
+// running are completed unless they failed and the final progress is sent.
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		return
+	}
+
+	p.parseStdout(string(p.stdout))
+	p.parseStderr(string(p.stderr))
+	p.stdout, p.stderr = nil, nil
+
+	for _, step := range p.steps {
+		step.running = map[string]struct{}{}
+		if step.status == pb.Operation_STATUS_RUNNING {
+			step.status = pb.Operation_STATUS_COMPLETED
+		}
+	}
+	p.done = true
+	p.send()
+}
+
+// writeLines appends the bytes to the buffer, parses every complete line and returns the
+// remainder.
+func (p *Progress) writeLines(buf []byte, b []byte, parse func(string) bool) []byte {
+	if p.done {
+		return nil
+	}
+
+	buf = append(buf, b...)
+	changed := false
+	for {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		if parse(strings.TrimRight(string(buf[:i]), "\r")) {
+			changed = true
+		}
+		buf = buf[i+1:]
+	}
+
+	if changed || time.Since(p.lastUpdate) >= p.interval {
+		p.send()
+	}
+
+	return buf
+}
+
+// parseStdout parses a line of stdout and returns whether the status of a step has changed.
+func (p *Progress) parseStdout(line string) bool {
+	if m := resourceProgressRe.FindStringSubmatch(line); m != nil {
+		step := p.step(Step(m[1]))
+		if step == nil {
+			return false
+		}
+
+		changed := step.setStatus(pb.Operation_STATUS_RUNNING)
+		switch action := m[2]; {
+		case strings.HasSuffix(action, "errored"):
+			delete(step.running, m[1])
+			changed = step.setStatus(pb.Operation_STATUS_FAILED) || changed
+		case strings.HasSuffix(action, "complete"):
+			delete(step.running, m[1])
+			step.done++
+		case action == "Refreshing state":
+			step.done++
+		default:
+			step.running[m[1]] = struct{}{}
+		}
+
+		return changed
+	}
+
+	if m := moduleDownloadingRe.FindStringSubmatch(line); m != nil {
+		if step := p.step(moduleStep(m[1])); step != nil {
+			return step.setStatus(pb.Operation_STATUS_RUNNING)
+		}
+
+		return false
+	}
+
+	if m := moduleInstalledRe.FindStringSubmatch(line); m != nil {
+		if step := p.step(moduleStep(m[1])); step != nil {
+			return step.setStatus(pb.Operation_STATUS_COMPLETED)
+		}
+	}
+
+	return false
+}
+
+// parseStderr parses a line of stderr and returns whether the status of a step has changed. Steps
+// whose resources have an error diagnostic have failed.
+func (p *Progress) parseStderr(line string) bool {
+	line = stripBox(line)
+
+	if errorRe.MatchString(line) {
+		p.inError = true
+
+		return false
+	}
+
+	if !p.inError {
+		return false
+	}
+
+	if m := withRe.FindStringSubmatch(line); m != nil {
+		p.inError = false
+		if step := p.step(Step(m[1])); step != nil {
+			return step.setStatus(pb.Operation_STATUS_FAILED)
+		}
+	}
+
+	return false
+}
+
+// step returns the progress of the step, creating it if necessary. Resources of the root module
+// don't belong to a step so it returns nil for them.
+func (p *Progress) step(name string) *progressStep {
+	if name == "" {
+		return nil
+	}
+
+	step, ok := p.steps[name]
+	if !ok {
+		step = &progressStep{running: map[string]struct{}{}}
+		p.steps[name] = step
+	}
+
+	return step
+}
+
+// send sends the progress of the steps, sorted by name.
+func (p *Progress) send() {
+	p.lastUpdate = time.Now()
+	if p.update == nil {
+		return
+	}
+
+	p.update(&pb.Terraform_StepProgress{
+		Phase: p.phase,
+		Steps: p.progressSteps(),
+		Done:  p.done,
+	})
+}
+
+// Steps returns the current progress of the steps, sorted by name.
+func (p *Progress) Steps() []*pb.Terraform_StepProgress_Step {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.progressSteps()
+}
+
+// progressSteps returns the progress of the steps, sorted by name.
+func (p *Progress) progressSteps() []*pb.Terraform_StepProgress_Step {
+	steps := []*pb.Terraform_StepProgress_Step{}
+	for name, step := range p.steps {
+		steps = append(steps, &pb.Terraform_StepProgress_Step{
+			Step:             name,
+			Status:           step.status,
+			ResourcesDone:    step.done,
+			ResourcesRunning: int32(len(step.running)),
+		})
+	}
+	slices.SortFunc(steps, func(a, b *pb.Terraform_StepProgress_Step) int {
+		return strings.Compare(a.GetStep(), b.GetStep())
+	})
+
+	return steps
+}
+
+// setStatus sets the status of the step and returns whether it has changed. Steps that have failed
+// stay failed.
+func (s *progressStep) setStatus(status pb.Operation_Status) bool {
+	if s.status == status || s.status == pb.Operation_STATUS_FAILED {
+		return false
+	}
+	s.status = status
+
+	return true
+}
+
+// moduleStep returns the step of the module of the output of init, which is the first part of the
+// module's name, e.g. "target" for "target.ec2".
+func moduleStep(name string) string {
+	step, _, _ := strings.Cut(name, ".")
+
+	return step
+}
+
+// ProgressStatus returns the status of the progress, which is running until it's done and failed if
+// any of its steps have failed.
+func ProgressStatus(p *pb.Terraform_StepProgress) pb.Operation_Status {
+	for _, step := range p.GetSteps() {
+		if step.GetStatus() == pb.Operation_STATUS_FAILED {
+			return pb.Operation_STATUS_FAILED
+		}
+	}
+
+	if !p.GetDone() {
+		return pb.Operation_STATUS_RUNNING
+	}
+
+	return pb.Operation_STATUS_COMPLETED
+}
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package stepresult
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_Progress_Apply tests parsing the output of apply into the progress of the steps.
+func Test_Progress_Apply(t *testing.T) {
+	t.Parallel()
+
+	updates := []*pb.Terraform_StepProgress{}
+	p := NewProgress("apply", func(sp *pb.Terraform_StepProgress) {
+		updates = append(updates, sp)
+	}, WithProgressInterval(time.Hour))
+
+	// Lines can be split across writes.
+	for _, out := range []string{
+		"module.vpc.data.aws_region.current: Reading...\n",
+		"module.vpc.data.aws_region.current: Read complete after 0s [id=us-east-1]\n",
+		"module.vpc.aws_vpc.vpc: Creating...\nmodule.target.aws_instance.target[\"0\"]: Crea",
+		"ting...\n",
+		"module.vpc.aws_vpc.vpc: Still creating... [10s elapsed]\n",
+		"module.vpc.aws_vpc.vpc: Creation complete after 12s [id=vpc-1234]\n",
+		"module.verify.enos_remote_exec.check: Creating...\n",
+		"module.verify.enos_remote_exec.check: Creation errored after 1s\n",
+		"aws_s3_bucket.root: Creating...\n",
+	} {
+		_, err := p.Write([]byte(out))
+		require.NoError(t, err)
+	}
+
+	_, err := p.Stderr().Write([]byte(`╷
+│ Error: creating EC2 Instance: Unsupported
+│
+│   with module.target.aws_instance.target["0"],
+│   on ../../modules/target/main.tf line 1, in resource "aws_instance" "target":
+╵
+`))
+	require.NoError(t, err)
+
+	// Updates are sent whenever the status of a step changes.
+	require.Len(t, updates, 5)
+	require.True(t, proto.Equal(&pb.Terraform_StepProgress{
+		Phase: "apply",
+		Steps: []*pb.Terraform_StepProgress_Step{
+			{Step: "target", Status: pb.Operation_STATUS_FAILED, ResourcesRunning: 1},
+			{Step: "verify", Status: pb.Operation_STATUS_FAILED},
+			{Step: "vpc", Status: pb.Operation_STATUS_RUNNING, ResourcesDone: 2},
+		},
+	}, updates[4]), updates[4].String())
+	require.Equal(t, pb.Operation_STATUS_FAILED, ProgressStatus(updates[4]))
+
+	p.Finish()
+	p.Finish()
+	require.Len(t, updates, 6)
+	require.True(t, proto.Equal(&pb.Terraform_StepProgress{
+		Phase: "apply",
+		Steps: []*pb.Terraform_StepProgress_Step{
+			{Step: "target", Status: pb.Operation_STATUS_FAILED},
+			{Step: "verify", Status: pb.Operation_STATUS_FAILED},
+			{Step: "vpc", Status: pb.Operation_STATUS_COMPLETED, ResourcesDone: 2},
+		},
+		Done: true,
+	}, updates[5]), updates[5].String())
+	require.Len(t, p.Steps(), 3)
+	require.True(t, proto.Equal(updates[5].GetSteps()[2], p.Steps()[2]))
+
+	// Nothing is parsed after the progress has finished.
+	_, err = p.Write([]byte("module.late.aws_vpc.vpc: Creating...\n"))
+	require.NoError(t, err)
+	require.Len(t, updates, 6)
+}
+
+// Test_Progress_Init tests parsing the output of init into the progress of the steps.
+func Test_Progress_Init(t *testing.T) {
+	t.Parallel()
+
+	var last *pb.Terraform_StepProgress
+	p := NewProgress("init", func(sp *pb.Terraform_StepProgress) {
+		last = sp
+	}, WithProgressInterval(0))
+
+	_, err := p.Write([]byte(`Initializing modules...
+- vpc in ../../modules/vpc
+Downloading registry.terraform.io/hashicorp-forge/target/aws 0.1.0 for target...
+- target.ec2 in .terraform/modules/target/ec2
+
+Initializing provider plugins...
+- Finding hashicorp/aws versions matching ">= 4.0.0"...
+`))
+	require.NoError(t, err)
+	require.Equal(t, pb.Operation_STATUS_RUNNING, ProgressStatus(last))
+
+	p.Finish()
+	require.True(t, proto.Equal(&pb.Terraform_StepProgress{
+		Phase: "init",
+		Steps: []*pb.Terraform_StepProgress_Step{
+			{Step: "target", Status: pb.Operation_STATUS_COMPLETED},
+			{Step: "vpc", Status: pb.Operation_STATUS_COMPLETED},
+		},
+		Done: true,
+	}, last), last.String())
+	require.Equal(t, pb.Operation_STATUS_COMPLETED, ProgressStatus(last))
+}
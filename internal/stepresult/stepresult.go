@@ -0,0 +1,319 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package stepresult turns the exec resources of the enos provider, i.e. enos_remote_exec and
+// enos_local_exec, into structured results of the scenario steps that they belong to. It also
+// parses the output of Terraform commands into the progress of the steps while they're running.
+package stepresult
+
+import (
+	"cmp"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Types of the exec resources of the enos provider.
+const (
+	TypeRemoteExec = "enos_remote_exec"
+	TypeLocalExec  = "enos_local_exec"
+)
+
+// Sensitive is the value of attributes that are sensitive.
+const Sensitive = "(sensitive)"
+
+// IsExecType returns whether the resource type is an exec resource of the enos provider.
+func IsExecType(typ string) bool {
+	return typ == TypeRemoteExec || typ == TypeLocalExec
+}
+
+// FromState returns the results of the exec resources in the state, grouped by step.
+func FromState(state *tfjson.State) []*pb.Terraform_StepResult {
+	if state == nil || state.Values == nil {
+		return nil
+	}
+
+	resources := []*pb.Terraform_StepResult_Resource{}
+	var walk func(mod *tfjson.StateModule)
+	walk = func(mod *tfjson.StateModule) {
+		if mod == nil {
+			return
+		}
+
+		for _, r := range mod.Resources {
+			if r.Mode != tfjson.ManagedResourceMode || !IsExecType(r.Type) {
+				continue
+			}
+
+			sensitive := sensitiveAttrs(r.SensitiveValues)
+			resources = append(resources, &pb.Terraform_StepResult_Resource{
+				Address: r.Address,
+				Type:    r.Type,
+				Host:    transportHost(r.AttributeValues["transport"]),
+				Stdout:  stringAttr(r.AttributeValues, sensitive, "stdout"),
+				Stderr:  stringAttr(r.AttributeValues, sensitive, "stderr"),
+			})
+		}
+
+		for _, child := range mod.ChildModules {
+			walk(child)
+		}
+	}
+	walk(state.Values.RootModule)
+
+	return group(resources)
+}
+
+var (
+	// errorRe matches the first line of an error diagnostic in the human readable output of
+	// Terraform.
+	errorRe = regexp.MustCompile(`^Error: (.*)$`)
+	// withRe matches the line of a diagnostic with the address of the resource.
+	withRe = regexp.MustCompile(`^\s+with (\S+?),?$`)
+	// sourceContextRe matches the lines of a diagnostic with its source location and snippet.
+	sourceContextRe = regexp.MustCompile(`^\s+(on .* line \d+|\d+:|\(and \d+ more similar\))`)
+	// instanceKeyRe matches the keys of resource and module instances in addresses.
+	instanceKeyRe = regexp.MustCompile(`\["(?:[^"\\]|\\.)*"\]|\[\d+\]`)
+)
+
+// FromApplyStderr returns the results of the exec resources that failed to apply, grouped by step,
+// from the errors in the stderr of Terraform. The error of a resource is the summary and the detail
+// of its diagnostic, which has the output of the commands that failed.
+func FromApplyStderr(stderr string) []*pb.Terraform_StepResult {
+	resources := []*pb.Terraform_StepResult_Resource{}
+
+	var cur *pb.Terraform_StepResult_Resource
+	var summary string
+	detail := []string{}
+	inContext := false
+
+	finish := func() {
+		if cur != nil {
+			cur.Error = strings.TrimSpace(summary + "\n\n" + strings.TrimSpace(strings.Join(detail, "\n")))
+			resources = append(resources, cur)
+		}
+		cur = nil
+		summary = ""
+		detail = []string{}
+	}
+
+	for _, line := range strings.Split(stderr, "\n") {
+		line = stripBox(line)
+
+		if m := errorRe.FindStringSubmatch(line); m != nil {
+			finish()
+			summary = m[1]
+			inContext = true
+
+			continue
+		}
+
+		if summary == "" {
+			continue
+		}
+
+		if inContext {
+			if m := withRe.FindStringSubmatch(line); m != nil {
+				if typ := resourceType(m[1]); IsExecType(typ) {
+					cur = &pb.Terraform_StepResult_Resource{
+						Address: m[1],
+						Type:    typ,
+						Failed:  true,
+					}
+				}
+
+				continue
+			}
+
+			if strings.TrimSpace(line) == "" || sourceContextRe.MatchString(line) {
+				continue
+			}
+			inContext = false
+		}
+
+		if cur == nil {
+			continue
+		}
+		detail = append(detail, line)
+	}
+	finish()
+
+	return group(resources)
+}
+
+// Failed returns the resources of the results that failed.
+func Failed(results []*pb.Terraform_StepResult) []*pb.Terraform_StepResult {
+	failed := []*pb.Terraform_StepResult{}
+	for _, result := range results {
+		resources := []*pb.Terraform_StepResult_Resource{}
+		for _, r := range result.GetResources() {
+			if r.GetFailed() {
+				resources = append(resources, r)
+			}
+		}
+
+		if len(resources) > 0 {
+			failed = append(failed, &pb.Terraform_StepResult{Step: result.GetStep(), Resources: resources})
+		}
+	}
+
+	return failed
+}
+
+// ErrorSummary returns a single line summary of the error of the resource of the step. It ends in
+// the last line of the error as that's usually the output of the command that failed.
+func ErrorSummary(step string, r *pb.Terraform_StepResult_Resource) string {
+	lines := strings.Split(strings.TrimSpace(r.GetError()), "\n")
+	last := strings.TrimLeft(strings.TrimSpace(lines[len(lines)-1]), "* ")
+
+	summary := strings.TrimPrefix(r.GetAddress(), "module."+step+".")
+	if step != "" {
+		summary = "step " + step + ": " + summary
+	}
+
+	if last == "" {
+		return summary + " failed"
+	}
+
+	return summary + ": " + last
+}
+
+// Step returns the name of the step of the resource address, which is the name of the top-level
+// module that the resource belongs to. Resources of the root module have no step.
+func Step(address string) string {
+	rest, ok := strings.CutPrefix(address, "module.")
+	if !ok {
+		return ""
+	}
+
+	if i := strings.IndexAny(rest, ".["); i >= 0 {
+		return rest[:i]
+	}
+
+	return rest
+}
+
+// group groups the resources by step. Steps and their resources are sorted by name.
+func group(resources []*pb.Terraform_StepResult_Resource) []*pb.Terraform_StepResult {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	byStep := map[string]*pb.Terraform_StepResult{}
+	results := []*pb.Terraform_StepResult{}
+	for _, r := range resources {
+		step := Step(r.GetAddress())
+		result, ok := byStep[step]
+		if !ok {
+			result = &pb.Terraform_StepResult{Step: step}
+			byStep[step] = result
+			results = append(results, result)
+		}
+		result.Resources = append(result.Resources, r)
+	}
+
+	slices.SortFunc(results, func(a, b *pb.Terraform_StepResult) int {
+		return cmp.Compare(a.GetStep(), b.GetStep())
+	})
+	for _, result := range results {
+		slices.SortStableFunc(result.Resources, func(a, b *pb.Terraform_StepResult_Resource) int {
+			return cmp.Compare(a.GetAddress(), b.GetAddress())
+		})
+	}
+
+	return results
+}
+
+// resourceType returns the type of the resource of the address, e.g. enos_remote_exec for
+// module.step.enos_remote_exec.name["key"].
+func resourceType(address string) string {
+	// Keys of instances might contain dots so we remove them first.
+	parts := strings.Split(instanceKeyRe.ReplaceAllString(address, ""), ".")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "module" {
+			i++
+
+			continue
+		}
+
+		if parts[i] == "data" {
+			return ""
+		}
+
+		return parts[i]
+	}
+
+	return ""
+}
+
+// stripBox removes the box drawing characters that Terraform draws around diagnostics when it
+// writes in color.
+func stripBox(line string) string {
+	for _, prefix := range []string{"│ ", "│", "╷", "╵"} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			return rest
+		}
+	}
+
+	return line
+}
+
+// sensitiveAttrs returns the names of the top-level attributes that are sensitive.
+func sensitiveAttrs(raw json.RawMessage) map[string]bool {
+	res := map[string]bool{}
+	if len(raw) == 0 {
+		return res
+	}
+
+	vals := map[string]any{}
+	if err := json.Unmarshal(raw, &vals); err != nil {
+		return res
+	}
+
+	for name, val := range vals {
+		if b, ok := val.(bool); ok && b {
+			res[name] = true
+		}
+	}
+
+	return res
+}
+
+// stringAttr returns the string value of the attribute.
+func stringAttr(attrs map[string]any, sensitive map[string]bool, name string) string {
+	if sensitive[name] {
+		return Sensitive
+	}
+
+	s, _ := attrs[name].(string)
+
+	return s
+}
+
+// transportHost returns the host of the transport of a remote exec resource.
+func transportHost(val any) string {
+	transports, ok := val.(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	for _, name := range []string{"ssh", "k8s", "nomad"} {
+		transport, ok := transports[name].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range []string{"host", "pod", "allocation_id"} {
+			if s, ok := transport[attr].(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+
+	return ""
+}
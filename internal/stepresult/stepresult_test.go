@@ -0,0 +1,214 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package stepresult
+
+import (
+	"encoding/json"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// Test_FromState tests that the exec resources of the state are grouped by step.
+func Test_FromState(t *testing.T) {
+	t.Parallel()
+
+	state := &tfjson.State{
+		Values: &tfjson.StateValues{
+			RootModule: &tfjson.StateModule{
+				Resources: []*tfjson.StateResource{
+					{
+						Address:         "enos_local_exec.root",
+						Mode:            tfjson.ManagedResourceMode,
+						Type:            TypeLocalExec,
+						AttributeValues: map[string]any{"stdout": "root"},
+					},
+				},
+				ChildModules: []*tfjson.StateModule{
+					{
+						Address: "module.verify",
+						Resources: []*tfjson.StateResource{
+							{
+								Address: `module.verify.enos_remote_exec.check["b"]`,
+								Mode:    tfjson.ManagedResourceMode,
+								Type:    TypeRemoteExec,
+								AttributeValues: map[string]any{
+									"stdout":    "ok\n",
+									"stderr":    "",
+									"transport": map[string]any{"ssh": map[string]any{"host": "10.0.0.2"}},
+								},
+							},
+							{
+								Address: `module.verify.enos_remote_exec.check["a"]`,
+								Mode:    tfjson.ManagedResourceMode,
+								Type:    TypeRemoteExec,
+								AttributeValues: map[string]any{
+									"stdout":    "secret",
+									"stderr":    "warning\n",
+									"transport": map[string]any{"ssh": map[string]any{"host": "10.0.0.1"}},
+								},
+								SensitiveValues: json.RawMessage(`{"stdout":true,"transport":{}}`),
+							},
+							{
+								Address: "module.verify.aws_instance.target",
+								Mode:    tfjson.ManagedResourceMode,
+								Type:    "aws_instance",
+							},
+						},
+					},
+					{
+						Address: "module.create",
+						ChildModules: []*tfjson.StateModule{
+							{
+								Address: "module.create.module.nested",
+								Resources: []*tfjson.StateResource{
+									{
+										Address:         "module.create.module.nested.enos_local_exec.build",
+										Mode:            tfjson.ManagedResourceMode,
+										Type:            TypeLocalExec,
+										AttributeValues: map[string]any{"stdout": "built"},
+									},
+									{
+										Address: "module.create.module.nested.data.enos_local_exec.read",
+										Mode:    tfjson.DataResourceMode,
+										Type:    TypeLocalExec,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := []*pb.Terraform_StepResult{
+		{
+			Resources: []*pb.Terraform_StepResult_Resource{
+				{Address: "enos_local_exec.root", Type: TypeLocalExec, Stdout: "root"},
+			},
+		},
+		{
+			Step: "create",
+			Resources: []*pb.Terraform_StepResult_Resource{
+				{Address: "module.create.module.nested.enos_local_exec.build", Type: TypeLocalExec, Stdout: "built"},
+			},
+		},
+		{
+			Step: "verify",
+			Resources: []*pb.Terraform_StepResult_Resource{
+				{Address: `module.verify.enos_remote_exec.check["a"]`, Type: TypeRemoteExec, Host: "10.0.0.1", Stdout: Sensitive, Stderr: "warning\n"},
+				{Address: `module.verify.enos_remote_exec.check["b"]`, Type: TypeRemoteExec, Host: "10.0.0.2", Stdout: "ok\n"},
+			},
+		},
+	}
+
+	got := FromState(state)
+	require.Len(t, got, len(expected))
+	for i := range expected {
+		require.True(t, proto.Equal(expected[i], got[i]), "expected %v, got %v", expected[i], got[i])
+	}
+
+	require.Nil(t, FromState(&tfjson.State{}))
+}
+
+// Test_FromApplyStderr tests that the exec resources that failed to apply are parsed from the
+// errors of Terraform.
+func Test_FromApplyStderr(t *testing.T) {
+	t.Parallel()
+
+	stderr := `
+Error: Execution Error
+
+  with module.verify.enos_remote_exec.check["10.0.0.1"],
+  on ../../modules/verify/main.tf line 10, in resource "enos_remote_exec" "check":
+  10: resource "enos_remote_exec" "check" {
+
+failed to execute commands due to: running script: [/tmp/check.sh] failed, due
+to: 1 error occurred:
+	* executing script: Process exited with status 1: vault is sealed
+
+
+Error: creating EC2 Instance: UnauthorizedOperation
+
+  with module.create.aws_instance.target,
+  on ../../modules/create/main.tf line 3, in resource "aws_instance" "target":
+   3: resource "aws_instance" "target" {
+
+not authorized
+╷
+│ Error: Command failed
+│
+│   with module.build.enos_local_exec.make,
+│   on ../../modules/build/main.tf line 1, in resource "enos_local_exec" "make":
+│    1: resource "enos_local_exec" "make" {
+│
+│ make: *** [all] Error 2
+╵
+`
+
+	got := FromApplyStderr(stderr)
+	expected := []*pb.Terraform_StepResult{
+		{
+			Step: "build",
+			Resources: []*pb.Terraform_StepResult_Resource{
+				{
+					Address: "module.build.enos_local_exec.make",
+					Type:    TypeLocalExec,
+					Failed:  true,
+					Error:   "Command failed\n\nmake: *** [all] Error 2",
+				},
+			},
+		},
+		{
+			Step: "verify",
+			Resources: []*pb.Terraform_StepResult_Resource{
+				{
+					Address: `module.verify.enos_remote_exec.check["10.0.0.1"]`,
+					Type:    TypeRemoteExec,
+					Failed:  true,
+					Error: "Execution Error\n\nfailed to execute commands due to: running script: [/tmp/check.sh] failed, due\n" +
+						"to: 1 error occurred:\n\t* executing script: Process exited with status 1: vault is sealed",
+				},
+			},
+		},
+	}
+
+	require.Len(t, got, len(expected))
+	for i := range expected {
+		require.True(t, proto.Equal(expected[i], got[i]), "expected %v, got %v", expected[i], got[i])
+	}
+
+	require.Len(t, Failed(got), 2)
+	require.Equal(t, `step verify: enos_remote_exec.check["10.0.0.1"]: executing script: Process exited with status 1: vault is sealed`,
+		ErrorSummary(got[1].GetStep(), got[1].GetResources()[0]),
+	)
+	require.Equal(t, "enos_local_exec.root failed",
+		ErrorSummary("", &pb.Terraform_StepResult_Resource{Address: "enos_local_exec.root"}),
+	)
+	require.Nil(t, FromApplyStderr("Error: no exec resources\n"))
+}
+
+// Test_Step tests getting the step of resource addresses.
+func Test_Step(t *testing.T) {
+	t.Parallel()
+
+	for address, expected := range map[string]string{
+		"enos_local_exec.root":                     "",
+		"module.verify.enos_remote_exec.check":     "verify",
+		`module.verify["a.b"].enos_remote_exec.c`:  "verify",
+		"module.a.module.b.enos_local_exec.c[0]":   "a",
+		`module.a.enos_remote_exec.c["module.b"]`:  "a",
+		"module.create.data.enos_environment.this": "create",
+	} {
+		require.Equal(t, expected, Step(address), address)
+	}
+
+	require.Equal(t, TypeRemoteExec, resourceType(`module.a["x.y"].module.b[0].enos_remote_exec.c["d.e"]`))
+	require.Equal(t, "", resourceType("module.a.data.enos_local_exec.c"))
+}
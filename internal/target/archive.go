@@ -0,0 +1,159 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package target
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// terraformDataDir is the directory that Terraform installs modules and providers to. It is never
+// synced as the providers are built for the platform that installed them.
+const terraformDataDir = ".terraform"
+
+// writeArchive writes a tar archive of the module directory and the sync directories to the writer.
+// The names of members are the absolute paths without the leading slash so that they can be
+// extracted below the root directory of the target. The out directory, i.e. the parent of the
+// module directory, is skipped in the sync directories so that the modules of other scenarios
+// aren't overwritten while they execute.
+func writeArchive(w io.Writer, dir string, syncDirs []string) error {
+	tw := tar.NewWriter(w)
+	outDir := filepath.Dir(dir)
+
+	for _, syncDir := range syncDirs {
+		syncDir, err := filepath.Abs(syncDir)
+		if err != nil {
+			return err
+		}
+
+		skip := func(path string) bool {
+			return path != syncDir && (path == outDir || path == dir)
+		}
+		if err := addDir(tw, syncDir, skip); err != nil {
+			return err
+		}
+	}
+
+	if err := addDir(tw, dir, func(string) bool { return false }); err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+// addDir adds the directory and its contents to the archive, except for Terraform data directories
+// and the directories that should be skipped.
+func addDir(tw *tar.Writer, root string, skip func(string) bool) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() && (d.Name() == terraformDataDir || skip(path)) {
+			return filepath.SkipDir
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		} else if !info.Mode().IsRegular() && !info.IsDir() {
+			// Sockets, devices, and the like can't be synced.
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = strings.TrimPrefix(filepath.ToSlash(path), "/")
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+
+		return err
+	})
+}
+
+// extractArchive extracts the members of the tar archive that are in the module directory. The
+// names of members are the absolute paths without the leading slash.
+func extractArchive(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(string(filepath.Separator), filepath.FromSlash(hdr.Name))
+		if path != dir && !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return fmt.Errorf("refusing to extract %s outside of %s", hdr.Name, dir)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, path, hdr.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			_ = os.Remove(path)
+			if err := os.Symlink(hdr.Linkname, path); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// extractFile writes the contents of the reader to the file.
+func extractFile(r io.Reader, path string, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+
+	return errors.Join(err, f.Close())
+}
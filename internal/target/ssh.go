@@ -0,0 +1,239 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package target executes Terraform commands on remote targets, e.g. a bastion host that is the
+// only machine with network access to the environment under test.
+package target
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SchemeSSH is the scheme of SSH target addresses.
+const SchemeSSH = "ssh"
+
+// DefaultRoot is the directory on the target, relative to the home directory of the user, that
+// local directories are synced to if the address has no path.
+const DefaultRoot = ".enos"
+
+// SSH is a remote target that is reachable with ssh. Local directories are synced to the same
+// path below the root directory on the target.
+type SSH struct {
+	User   string
+	Host   string
+	Port   string
+	Root   string
+	Binary string // the ssh binary
+}
+
+// SSHOpt is a functional option.
+type SSHOpt func(*SSH)
+
+// ParseSSH parses an SSH target address, e.g. ssh://user@host:22/path, and returns a new target.
+func ParseSSH(address string, opts ...SSHOpt) (*SSH, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing target address: %w", err)
+	}
+
+	if u.Scheme != SchemeSSH {
+		return nil, fmt.Errorf("unsupported target address %s, only ssh:// targets are supported", address)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("target address %s has no host", address)
+	}
+
+	s := &SSH{
+		User:   u.User.Username(),
+		Host:   u.Hostname(),
+		Port:   u.Port(),
+		Root:   DefaultRoot,
+		Binary: "ssh",
+	}
+
+	if p := strings.TrimSuffix(u.Path, "/"); p != "" {
+		s.Root = p
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// WithSSHBinary sets the ssh binary.
+func WithSSHBinary(bin string) SSHOpt {
+	return func(s *SSH) {
+		s.Binary = bin
+	}
+}
+
+// RemotePath returns the path on the target that the local path is synced to.
+func (s *SSH) RemotePath(local string) string {
+	return path.Join(s.Root, filepath.ToSlash(local))
+}
+
+// ExecRequest is a request to execute a Terraform command in a module directory on the target.
+type ExecRequest struct {
+	// Dir is the local module directory. It is synced to the target before and back from it after
+	// the command.
+	Dir string
+	// SyncDirs are more local directories that are synced to the target before the command.
+	SyncDirs []string
+	// Env are the environment variables of the command.
+	Env map[string]string
+	// Terraform is the Terraform binary on the target.
+	Terraform string
+	// Args are the arguments of Terraform.
+	Args   []string
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Exec syncs the directories of the request to the target, executes the Terraform command in the
+// module directory on the target, and syncs the module directory back. The module directory is
+// synced back even if the command fails because it might have changed the state. It returns the
+// exit code of the command.
+func (s *SSH) Exec(ctx context.Context, req *ExecRequest) (int, error) {
+	dir, err := filepath.Abs(req.Dir)
+	if err != nil {
+		return 1, err
+	}
+
+	// Terraform version commands don't need the module so we don't sync for them.
+	sync := len(req.Args) == 0 || req.Args[0] != "version"
+
+	if sync {
+		if err := s.upload(ctx, dir, req.SyncDirs, req.Stderr); err != nil {
+			return 1, fmt.Errorf("syncing %s to %s: %w", dir, s.Host, err)
+		}
+	}
+
+	code, err := s.run(ctx, dir, sync, req)
+
+	if sync {
+		if dErr := s.download(ctx, dir, req.Stderr); dErr != nil {
+			err = errors.Join(err, fmt.Errorf("syncing %s from %s: %w", dir, s.Host, dErr))
+		}
+	}
+
+	return code, err
+}
+
+// run executes the Terraform command on the target. Commands that are synced are executed in the
+// module directory, others in the home directory as it might not have been synced yet.
+func (s *SSH) run(ctx context.Context, dir string, sync bool, req *ExecRequest) (int, error) {
+	tf := req.Terraform
+	if tf == "" {
+		tf = "terraform"
+	}
+
+	names := make([]string, 0, len(req.Env))
+	for name := range req.Env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	cmdLine := []string{}
+	if sync {
+		cmdLine = append(cmdLine, "cd", shellQuote(s.RemotePath(dir)), "&&")
+	}
+	cmdLine = append(cmdLine, "exec", "env")
+	for _, name := range names {
+		cmdLine = append(cmdLine, shellQuote(name+"="+req.Env[name]))
+	}
+	cmdLine = append(cmdLine, shellQuote(tf))
+	for _, arg := range req.Args {
+		cmdLine = append(cmdLine, shellQuote(arg))
+	}
+
+	cmd := s.command(ctx, strings.Join(cmdLine, " "))
+	cmd.Stdout = req.Stdout
+	cmd.Stderr = req.Stderr
+
+	err := cmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+
+		return 1, err
+	}
+
+	return 0, nil
+}
+
+// upload syncs the module directory and the sync directories to the target.
+func (s *SSH) upload(ctx context.Context, dir string, syncDirs []string, stderr io.Writer) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeArchive(pw, dir, syncDirs))
+	}()
+
+	cmd := s.command(ctx, fmt.Sprintf("mkdir -p %[1]s && tar -x -f - -C %[1]s", shellQuote(s.Root)))
+	cmd.Stdin = pr
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	// Make sure that the archive writer returns if the command stopped reading.
+	pr.Close()
+
+	return err
+}
+
+// download syncs the module directory back from the target.
+func (s *SSH) download(ctx context.Context, dir string, stderr io.Writer) error {
+	member := strings.TrimPrefix(filepath.ToSlash(dir), "/")
+	cmd := s.command(ctx, fmt.Sprintf("tar -c -f - -C %s --exclude=%s %s",
+		shellQuote(s.Root), shellQuote(terraformDataDir), shellQuote(member),
+	))
+	cmd.Stderr = stderr
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	extractErr := extractArchive(out, dir)
+	// Drain the output so that the command doesn't block if we stopped reading early.
+	_, _ = io.Copy(io.Discard, out)
+
+	return errors.Join(extractErr, cmd.Wait())
+}
+
+// command returns the ssh command that executes the command line on the target.
+func (s *SSH) command(ctx context.Context, cmdLine string) *exec.Cmd {
+	args := []string{"-o", "BatchMode=yes"}
+	if s.Port != "" {
+		args = append(args, "-p", s.Port)
+	}
+
+	dest := s.Host
+	if s.User != "" {
+		dest = s.User + "@" + s.Host
+	}
+	args = append(args, dest, cmdLine)
+
+	return exec.CommandContext(ctx, s.Binary, args...)
+}
+
+// shellQuote quotes the value for sh.
+func shellQuote(val string) string {
+	return "'" + strings.ReplaceAll(val, "'", `'\''`) + "'"
+}
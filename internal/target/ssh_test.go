@@ -0,0 +1,215 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package target
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSH is an ssh binary that executes the command line locally in the home directory next to
+// it, like ssh does on the target, and records the destination.
+const fakeSSH = `#!/bin/sh
+while [ $# -gt 2 ]; do shift; done
+echo "$1" >> "$(dirname "$0")/destinations"
+cd "$(dirname "$0")/home" || exit 1
+exec sh -c "$2"
+`
+
+// fakeTerraform is a Terraform binary that writes its arguments and environment to the state.
+const fakeTerraform = `#!/bin/sh
+cat main.tf > terraform.tfstate
+echo "$@" >> terraform.tfstate
+echo "$TF_VAR_name" >> terraform.tfstate
+mkdir -p .terraform && touch .terraform/provider
+if [ "$1" = "fail" ]; then exit 3; fi
+`
+
+func testSSH(t *testing.T) (*SSH, string) {
+	t.Helper()
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ssh"), []byte(fakeSSH), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "terraform"), []byte(fakeTerraform), 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(bin, "home"), 0o755))
+
+	root := t.TempDir()
+	s, err := ParseSSH("ssh://enos@bastion:2222"+root, WithSSHBinary(filepath.Join(bin, "ssh")))
+	require.NoError(t, err)
+
+	return s, bin
+}
+
+// testProject returns the path of a flight plan directory with a module directory in its out
+// directory and the module directory of another scenario.
+func testProject(t *testing.T) (string, string) {
+	t.Helper()
+
+	base := t.TempDir()
+	dir := filepath.Join(base, ".enos", "one")
+	other := filepath.Join(base, ".enos", "two")
+	for path, content := range map[string]string{
+		filepath.Join(base, "enos.hcl"):                       "scenario",
+		filepath.Join(base, "modules", "foo", "main.tf"):      "foo",
+		filepath.Join(base, "modules", ".terraform", "x"):     "x",
+		filepath.Join(dir, "main.tf"):                         "one",
+		filepath.Join(dir, ".terraform", "providers", "a"):    "a",
+		filepath.Join(other, "terraform.tfstate"):             "two",
+		filepath.Join(base, "modules", "foo", "nested", "nf"): "nested",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	return base, dir
+}
+
+// Test_ParseSSH tests parsing target addresses.
+func Test_ParseSSH(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		address  string
+		expected *SSH
+		err      bool
+	}{
+		"full": {
+			address:  "ssh://enos@bastion:2222/srv/enos/",
+			expected: &SSH{User: "enos", Host: "bastion", Port: "2222", Root: "/srv/enos", Binary: "ssh"},
+		},
+		"host only": {
+			address:  "ssh://bastion",
+			expected: &SSH{Host: "bastion", Root: DefaultRoot, Binary: "ssh"},
+		},
+		"no host": {
+			address: "ssh:///srv",
+			err:     true,
+		},
+		"unsupported scheme": {
+			address: "winrm://bastion",
+			err:     true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := ParseSSH(test.address)
+			if test.err {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, s)
+		})
+	}
+}
+
+// Test_SSH_Exec tests that the directories are synced to the target, that Terraform is executed
+// in the module directory on the target, and that the module directory is synced back.
+func Test_SSH_Exec(t *testing.T) {
+	t.Parallel()
+
+	s, bin := testSSH(t)
+	base, dir := testProject(t)
+
+	stdout := &bytes.Buffer{}
+	code, err := s.Exec(context.Background(), &ExecRequest{
+		Dir:       dir,
+		SyncDirs:  []string{base},
+		Env:       map[string]string{"TF_VAR_name": "it's"},
+		Terraform: filepath.Join(bin, "terraform"),
+		Args:      []string{"apply", "-auto-approve"},
+		Stdout:    stdout,
+		Stderr:    stdout,
+	})
+	require.NoError(t, err, stdout.String())
+	require.Equal(t, 0, code)
+
+	// The state that Terraform wrote on the target is synced back.
+	state, err := os.ReadFile(filepath.Join(dir, "terraform.tfstate"))
+	require.NoError(t, err)
+	require.Equal(t, "oneapply -auto-approve\nit's\n", string(state))
+
+	// Terraform data directories are never synced back.
+	require.NoFileExists(t, filepath.Join(dir, ".terraform", "provider"))
+
+	remote := func(path string) string {
+		return s.RemotePath(path)
+	}
+	require.FileExists(t, remote(filepath.Join(base, "enos.hcl")))
+	require.FileExists(t, remote(filepath.Join(base, "modules", "foo", "nested", "nf")))
+	require.FileExists(t, remote(filepath.Join(dir, ".terraform", "provider")))
+	require.NoFileExists(t, remote(filepath.Join(dir, ".terraform", "providers", "a")))
+	require.NoFileExists(t, remote(filepath.Join(base, "modules", ".terraform", "x")))
+	// The modules of other scenarios aren't synced.
+	require.NoDirExists(t, remote(filepath.Join(base, ".enos", "two")))
+
+	destinations, err := os.ReadFile(filepath.Join(bin, "destinations"))
+	require.NoError(t, err)
+	require.Equal(t, "enos@bastion\nenos@bastion\nenos@bastion\n", string(destinations))
+}
+
+// Test_SSH_Exec_failed tests that the exit code of Terraform is returned and that the module
+// directory is synced back when Terraform fails.
+func Test_SSH_Exec_failed(t *testing.T) {
+	t.Parallel()
+
+	s, bin := testSSH(t)
+	_, dir := testProject(t)
+
+	code, err := s.Exec(context.Background(), &ExecRequest{
+		Dir:       dir,
+		Terraform: filepath.Join(bin, "terraform"),
+		Args:      []string{"fail"},
+		Stdout:    &bytes.Buffer{},
+		Stderr:    &bytes.Buffer{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, code)
+	require.FileExists(t, filepath.Join(dir, "terraform.tfstate"))
+}
+
+// Test_SSH_Exec_version tests that version commands don't sync.
+func Test_SSH_Exec_version(t *testing.T) {
+	t.Parallel()
+
+	s, bin := testSSH(t)
+	_, dir := testProject(t)
+	require.NoError(t, os.MkdirAll(s.RemotePath(dir), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(s.RemotePath(dir), "main.tf"), []byte("remote"), 0o644))
+
+	code, err := s.Exec(context.Background(), &ExecRequest{
+		Dir:       dir,
+		Terraform: filepath.Join(bin, "terraform"),
+		Args:      []string{"version", "-json"},
+		Stdout:    &bytes.Buffer{},
+		Stderr:    &bytes.Buffer{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, code)
+	require.NoFileExists(t, filepath.Join(dir, "terraform.tfstate"))
+	// Commands that aren't synced are executed in the home directory on the target.
+	require.FileExists(t, filepath.Join(bin, "home", "terraform.tfstate"))
+
+	destinations, err := os.ReadFile(filepath.Join(bin, "destinations"))
+	require.NoError(t, err)
+	require.Equal(t, "enos@bastion\n", string(destinations))
+}
+
+// Test_extractArchive_outside tests that members outside of the module directory are rejected.
+func Test_extractArchive_outside(t *testing.T) {
+	t.Parallel()
+
+	base, dir := testProject(t)
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeArchive(buf, filepath.Join(base, ".enos", "two"), nil))
+
+	require.ErrorContains(t, extractArchive(buf, dir), "outside of")
+}
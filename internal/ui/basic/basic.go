@@ -6,7 +6,10 @@ package basic
 import (
 	"io"
 	"os"
+	"sync"
 
+	"github.com/hashicorp/enos/internal/ci"
+	"github.com/hashicorp/enos/internal/i18n"
 	"github.com/hashicorp/enos/internal/ui/terminal"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
@@ -15,8 +18,16 @@ import (
 type View struct {
 	settings *pb.UI_Settings
 	ui       *terminal.UI
+	catalog  *i18n.Catalog
 	stdout   io.ReadWriteCloser
 	stderr   io.ReadWriteCloser
+
+	// ciWorkspace is the checkout of the repository in CI environments, which annotations are
+	// relative to.
+	ciWorkspace string
+	annotatedMu sync.Mutex
+	annotated   map[string]struct{}
+	steps       *stepTree
 }
 
 // Opt is a functional option.
@@ -24,7 +35,10 @@ type Opt func(*View)
 
 // New takes options and returns a new basic.View.
 func New(opts ...Opt) (*View, error) {
-	v := &View{}
+	v := &View{
+		annotated: map[string]struct{}{},
+		steps:     newStepTree(),
+	}
 
 	for _, opt := range opts {
 		opt(v)
@@ -36,12 +50,25 @@ func New(opts ...Opt) (*View, error) {
 		terminal.WithStderr(os.Stderr),
 	}
 	if v.settings != nil {
+		catalog, err := i18n.New(v.settings.GetLocale(), i18n.WithCatalogFile(v.settings.GetMessageCatalog()))
+		if err != nil {
+			return nil, err
+		}
+		v.catalog = catalog
+
 		uiOpts = append(uiOpts, terminal.WithLevel(v.settings.GetLevel()))
+		v.ciWorkspace = ci.Workspace(v.settings.GetCi(), os.Getenv)
 
-		if v.settings.GetIsTty() {
+		// The logs of CI environments render colors even though they're not terminals.
+		if v.settings.GetIsTty() || v.settings.GetCi() != pb.UI_Settings_CI_UNSPECIFIED {
 			uiOpts = append(uiOpts, terminal.WithColor(true))
 		}
 
+		// Only truncate when writing to a terminal so that redirected output is always complete.
+		if v.settings.GetIsTty() && !v.settings.GetNoTruncate() && v.settings.GetStdoutPath() == "" {
+			uiOpts = append(uiOpts, terminal.WithTruncate(true))
+		}
+
 		if v.settings.GetWidth() > 0 {
 			uiOpts = append(uiOpts, terminal.WithWidth(uint(v.settings.GetWidth())))
 		}
@@ -69,6 +96,17 @@ func New(opts ...Opt) (*View, error) {
 
 	v.ui = terminal.NewUI(uiOpts...)
 
+	// The status tree of the steps is only redrawn in place when we're writing to a terminal. We
+	// keep track of everything else that's written to it so that we know when we can.
+	if v.settings.GetIsTty() && v.settings.GetStdoutPath() == "" {
+		v.steps.live = true
+		v.steps.out = v.ui.Stdout
+		v.ui = terminal.NewUI(append(uiOpts,
+			terminal.WithStdout(v.steps.track(v.ui.Stdout)),
+			terminal.WithStderr(v.steps.track(v.ui.Stderr)),
+		)...)
+	}
+
 	return v, nil
 }
 
@@ -84,6 +122,11 @@ func (v *View) Settings() *pb.UI_Settings {
 	return v.settings
 }
 
+// t translates the message with the message catalog of the view and formats it with the arguments.
+func (v *View) t(msg string, args ...any) string {
+	return v.catalog.T(msg, args...)
+}
+
 // Close closes any open file handles.
 func (v *View) Close() error {
 	if v.stderr != nil {
@@ -108,52 +151,52 @@ func (v *View) opStatusString(status pb.Operation_Status) string {
 	case pb.Operation_STATUS_CANCELLED:
 		res = "❌"
 		if !tty {
-			res = "cancelled!"
+			res = v.t("cancelled!")
 		}
 	case pb.Operation_STATUS_COMPLETED:
 		res = "✅"
 		if !tty {
-			res = "success!"
+			res = v.t("success!")
 		}
 	case pb.Operation_STATUS_COMPLETED_WARNING:
 		res = "⚠️"
 		if !tty {
-			res = "success! (warnings present)"
+			res = v.t("success! (warnings present)")
 		}
 	case pb.Operation_STATUS_FAILED:
 		res = "❌"
 		if !tty {
-			res = "failed!"
+			res = v.t("failed!")
 		}
 	case pb.Operation_STATUS_RUNNING_WARNING:
 		res = "⚠️"
 		if !tty {
-			res = "running (warnings present)"
+			res = v.t("running (warnings present)")
 		}
 	case pb.Operation_STATUS_RUNNING:
 		res = "🚀"
 		if !tty {
-			res = "running"
+			res = v.t("running")
 		}
 	case pb.Operation_STATUS_WAITING:
 		res = "⏳"
 		if !tty {
-			res = "waiting"
+			res = v.t("waiting")
 		}
 	case pb.Operation_STATUS_QUEUED:
 		res = "⏳"
 		if !tty {
-			res = "queued"
+			res = v.t("queued")
 		}
 	case pb.Operation_STATUS_UNSPECIFIED, pb.Operation_STATUS_UNKNOWN:
 		res = "⁉️"
 		if !tty {
-			res = "unknown"
+			res = v.t("unknown")
 		}
 	default:
 		res = "⁉️"
 		if !tty {
-			res = "unknown"
+			res = v.t("unknown")
 		}
 	}
 
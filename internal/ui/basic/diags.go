@@ -4,8 +4,10 @@
 package basic
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/hashicorp/enos/internal/ci"
 	"github.com/hashicorp/enos/internal/diagnostics"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
@@ -23,6 +25,32 @@ func (v *View) WriteDiagnostics(diags []*pb.Diagnostic) {
 		return
 	}
 	v.ui.Error(v.diagsToString(diags))
+	v.writeAnnotations(diags)
+}
+
+// writeAnnotations writes the workflow commands that annotate the files of the diagnostics in CI
+// environments that support them. Each diagnostic is only annotated once as the same diagnostics
+// can be shown more than once, e.g. in the response of an operation and its summary.
+func (v *View) writeAnnotations(diags []*pb.Diagnostic) {
+	if v.settings.GetCi() == pb.UI_Settings_CI_UNSPECIFIED {
+		return
+	}
+
+	v.annotatedMu.Lock()
+	defer v.annotatedMu.Unlock()
+
+	for _, diag := range diags {
+		annotation := ci.Annotation(v.settings.GetCi(), diag, v.ciWorkspace)
+		if annotation == "" {
+			continue
+		}
+		if _, ok := v.annotated[annotation]; ok {
+			continue
+		}
+
+		v.annotated[annotation] = struct{}{}
+		v.ui.Output(annotation)
+	}
 }
 
 // diagsToString returns the diagsnostics as a string.
@@ -31,18 +59,65 @@ func (v *View) diagsToString(diags []*pb.Diagnostic) string {
 		return ""
 	}
 
+	if v.settings.GetGroupDiagnostics() {
+		return v.groupedDiagsToString(diags)
+	}
+
 	b := new(strings.Builder)
 	for _, diag := range diags {
-		b.WriteString(diagnostics.String(
-			diag,
-			diagnostics.WithStringUISettings(v.settings),
-			diagnostics.WithStringSnippetEnabled(true),
-		))
+		b.WriteString(v.diagToString(diag))
 	}
 
 	return strings.TrimSpace(b.String())
 }
 
+// groupedDiagsToString returns the diagnostics grouped by file. Each file has a header with the
+// counts of its diagnostics and a summary of all files follows the last file.
+func (v *View) groupedDiagsToString(diags []*pb.Diagnostic) string {
+	b := new(strings.Builder)
+	files := 0
+	for _, group := range diagnostics.GroupByFile(diags) {
+		name := group.Filename
+		if name == "" {
+			name = v.t("Other")
+		} else {
+			files++
+		}
+
+		b.WriteString(fmt.Sprintf("%s: %s\n", name, v.diagsSummary(group.Diagnostics)))
+		for _, diag := range group.Diagnostics {
+			b.WriteString(v.diagToString(diag))
+		}
+		b.WriteString("\n")
+	}
+
+	if files == 1 {
+		b.WriteString(v.t("%s in %d file", v.diagsSummary(diags), files))
+	} else {
+		b.WriteString(v.t("%s in %d files", v.diagsSummary(diags), files))
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// diagToString returns the diagnostic as a string.
+func (v *View) diagToString(diag *pb.Diagnostic) string {
+	return diagnostics.String(
+		diag,
+		diagnostics.WithStringUISettings(v.settings),
+		diagnostics.WithStringSnippetEnabled(true),
+		diagnostics.WithStringCatalog(v.catalog),
+	)
+}
+
+// diagsSummary returns the translated number of errors and warnings of the diagnostics.
+func (v *View) diagsSummary(diags []*pb.Diagnostic) string {
+	errs, warnings := diagnostics.Counts(diags)
+
+	return v.catalog.Plural(errs, "%d error", "%d errors") + ", " +
+		v.catalog.Plural(warnings, "%d warning", "%d warnings")
+}
+
 func (v *View) writeDiags(d []*pb.Diagnostic, w *strings.Builder) {
 	if len(d) < 1 {
 		return
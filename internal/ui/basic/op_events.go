@@ -97,6 +97,54 @@ func (v *View) writeEventInit(e *pb.Operation_Event, w *strings.Builder) {
 	v.writeDiags(i.GetDiagnostics(), w)
 }
 
+func (v *View) writeEventProvidersLock(e *pb.Operation_Event, w *strings.Builder) {
+	l := e.GetProvidersLock()
+	if l == nil {
+		return
+	}
+
+	v.writeEventHeader("providers lock", e, pb.UI_Settings_LEVEL_INFO, w)
+
+	if stderr := l.GetStderr(); stderr != "" &&
+		v.settings.GetLevel() > pb.UI_Settings_LEVEL_INFO {
+		fmt.Fprintf(w, "\n  Stderr: %s\n", stderr)
+	}
+
+	v.writeDiags(l.GetDiagnostics(), w)
+}
+
+func (v *View) writeEventStatePull(e *pb.Operation_Event, w *strings.Builder) {
+	p := e.GetStatePull()
+	if p == nil {
+		return
+	}
+
+	v.writeEventHeader("state pull", e, pb.UI_Settings_LEVEL_INFO, w)
+
+	if stderr := p.GetStderr(); stderr != "" &&
+		v.settings.GetLevel() > pb.UI_Settings_LEVEL_INFO {
+		fmt.Fprintf(w, "\n  Stderr: %s\n", stderr)
+	}
+
+	v.writeDiags(p.GetDiagnostics(), w)
+}
+
+func (v *View) writeEventStatePush(e *pb.Operation_Event, w *strings.Builder) {
+	p := e.GetStatePush()
+	if p == nil {
+		return
+	}
+
+	v.writeEventHeader("state push", e, pb.UI_Settings_LEVEL_INFO, w)
+
+	if stderr := p.GetStderr(); stderr != "" &&
+		v.settings.GetLevel() > pb.UI_Settings_LEVEL_INFO {
+		fmt.Fprintf(w, "\n  Stderr: %s\n", stderr)
+	}
+
+	v.writeDiags(p.GetDiagnostics(), w)
+}
+
 func (v *View) writeEventValidate(e *pb.Operation_Event, w *strings.Builder) {
 	v.writeEventHeader("validate", e, pb.UI_Settings_LEVEL_INFO, w)
 
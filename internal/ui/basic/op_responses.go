@@ -4,6 +4,7 @@
 package basic
 
 import (
+	"github.com/hashicorp/enos/internal/flightplan"
 	"github.com/hashicorp/enos/internal/ui/status"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
 )
@@ -16,7 +17,7 @@ func (v *View) writeDecodeResponse(out *pb.DecodeResponse) {
 	}
 
 	if status.HasFailed(v.settings.GetFailOnWarnings(), out) {
-		msg := "Decode: failed!"
+		msg := "Decode: " + v.t("failed!")
 		if v.settings.GetIsTty() {
 			msg = "Decode: ❌"
 		}
@@ -28,13 +29,13 @@ func (v *View) writeDecodeResponse(out *pb.DecodeResponse) {
 
 	var msg string
 	if status.HasWarningDiags(out) {
-		msg = "Decode: success! (warnings present)"
+		msg = "Decode: " + v.t("success! (warnings present)")
 		if v.settings.GetIsTty() {
 			msg = "Decode: ⚠️"
 		}
 		v.ui.Warn(msg)
 	} else {
-		msg = "Decode: success!"
+		msg = "Decode: " + v.t("success!")
 		if v.settings.GetIsTty() {
 			msg = "Decode: ✅"
 		}
@@ -42,6 +43,21 @@ func (v *View) writeDecodeResponse(out *pb.DecodeResponse) {
 	}
 
 	v.WriteDiagnostics(out.GetDiagnostics())
+	v.writeSkippedScenarios(out.GetSkipped())
+}
+
+// writeSkippedScenarios writes the scenarios that were skipped by skip files and why.
+func (v *View) writeSkippedScenarios(skipped []*pb.Scenario_Skipped) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	v.ui.Info(v.t("Skipped:"))
+	for _, s := range skipped {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(s.GetScenario())
+		v.ui.Info("  " + scenario.String() + ": " + s.GetReason())
+	}
 }
 
 // writeGenerateResponse takes a scenario generate response and writes human
@@ -52,7 +68,7 @@ func (v *View) writeGenerateResponse(out *pb.Operation_Response_Generate) {
 	}
 
 	if status.HasFailed(v.settings.GetFailOnWarnings(), out) {
-		msg := "  Generate: failed!"
+		msg := "  Generate: " + v.t("failed!")
 		if v.settings.GetIsTty() {
 			msg = "  Generate: ❌"
 		}
@@ -66,12 +82,12 @@ func (v *View) writeGenerateResponse(out *pb.Operation_Response_Generate) {
 
 	var msg string
 	if status.HasWarningDiags(out) {
-		msg = "  Generate: success! (warnings present)"
+		msg = "  Generate: " + v.t("success! (warnings present)")
 		if v.settings.GetIsTty() {
 			msg = "  Generate: ⚠️"
 		}
 	} else {
-		msg = "  Generate: success!"
+		msg = "  Generate: " + v.t("success!")
 		if v.settings.GetIsTty() {
 			msg = "  Generate: ✅"
 		}
@@ -80,5 +96,22 @@ func (v *View) writeGenerateResponse(out *pb.Operation_Response_Generate) {
 	v.ui.Info(msg)
 	v.ui.Debug("  Module path: " + out.GetTerraformModule().GetModulePath())
 	v.ui.Debug("  Module rc path: " + out.GetTerraformModule().GetRcPath())
+	v.writeGenerateFiles(out.GetFiles())
 	v.WriteDiagnostics(out.GetDiagnostics())
 }
+
+// writeGenerateFiles writes the files of a generate dry run and their diffs.
+func (v *View) writeGenerateFiles(files []*pb.Operation_Response_Generate_File) {
+	for _, file := range files {
+		if !file.GetChanged() {
+			v.ui.Info("  " + v.t("Unchanged: %s", file.GetPath()))
+
+			continue
+		}
+
+		v.ui.Info("  " + v.t("Would change: %s", file.GetPath()))
+		if diff := file.GetDiff(); diff != "" {
+			v.ui.Output(diff)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// writeArtifacts writes the table of the artifacts that have been published and their URIs.
+func (v *View) writeArtifacts(artifacts []*pb.Artifact) {
+	if len(artifacts) < 1 {
+		return
+	}
+
+	rows := [][]string{{""}} // add a padding row
+	for _, a := range artifacts {
+		rows = append(rows, []string{a.GetPath(), a.GetUri()})
+	}
+
+	v.ui.Info("\n" + v.t("Published artifacts:"))
+	v.ui.RenderTable([]string{"path", "uri"}, rows)
+}
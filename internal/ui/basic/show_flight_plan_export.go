@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowFlightPlanExport shows a summary of the exported flight plan. The entire model is only
+// written by the machine readable formats.
+func (v *View) ShowFlightPlanExport(res *pb.ExportFlightPlanResponse) error {
+	if export := res.GetExport(); export != nil {
+		v.ui.Info(v.t("Exported %d modules, %d providers, %d scenarios, %d samples and %d qualities",
+			len(export.GetModules()),
+			len(export.GetProviders()),
+			len(export.GetScenarios()),
+			len(export.GetSamples()),
+			len(export.GetQualities()),
+		))
+		v.ui.Info(v.t("Use --format json to write the entire decoded flight plan"))
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.ExportFlightPlan(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowFlightPlanVerify shows how the flight plan differs from an export.
+func (v *View) ShowFlightPlanVerify(res *pb.VerifyFlightPlanResponse) error {
+	if len(res.GetDifferences()) > 0 {
+		v.ui.Info(v.t("The flight plan differs from the export:"))
+		for _, diff := range res.GetDifferences() {
+			v.ui.Info("  " + flightPlanDifferenceString(diff))
+		}
+	} else if !status.HasErrorDiags(res, res.GetDecode()) {
+		v.ui.Info(v.t("The flight plan matches the export"))
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.VerifyFlightPlan(v.settings.GetFailOnWarnings(), res)
+}
+
+// flightPlanDifferenceString returns how the value at the path of the difference has changed.
+func flightPlanDifferenceString(diff *pb.FlightPlanExport_Difference) string {
+	switch diff.GetChange() {
+	case pb.DiffOperationsResponse_CHANGE_ADDED:
+		if diff.GetTo() == "" {
+			return "+ " + diff.GetPath()
+		}
+
+		return fmt.Sprintf("+ %s: %s", diff.GetPath(), diff.GetTo())
+	case pb.DiffOperationsResponse_CHANGE_REMOVED:
+		if diff.GetFrom() == "" {
+			return "- " + diff.GetPath()
+		}
+
+		return fmt.Sprintf("- %s: %s", diff.GetPath(), diff.GetFrom())
+	default:
+		return fmt.Sprintf("~ %s: %s => %s", diff.GetPath(), diff.GetFrom(), diff.GetTo())
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"github.com/hashicorp/enos/internal/cost"
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// writeOperationCosts writes the summary table of the estimated costs of the plans of each
+// scenario and their total.
+func (v *View) writeOperationCosts(responses []*pb.Operation_Response) {
+	header := []string{"scenario", "hourly", "monthly"}
+	rows := [][]string{{""}} // add a padding row
+	estimates := []*pb.Terraform_CostEstimate{}
+	for _, res := range responses {
+		est := planCost(res)
+		if est == nil {
+			continue
+		}
+		estimates = append(estimates, est)
+
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(res.GetOp().GetScenario())
+		rows = append(rows, []string{
+			scenario.String(),
+			cost.Format(est.GetHourly(), est.GetCurrency()),
+			cost.Format(est.GetMonthly(), est.GetCurrency()),
+		})
+	}
+
+	total := cost.Total(estimates...)
+	if total == nil {
+		return
+	}
+
+	if len(estimates) > 1 {
+		rows = append(rows, []string{
+			v.t("total"),
+			cost.Format(total.GetHourly(), total.GetCurrency()),
+			cost.Format(total.GetMonthly(), total.GetCurrency()),
+		})
+	}
+
+	v.ui.Info("\n" + v.t("Estimated cost: %s per month", cost.Format(total.GetMonthly(), total.GetCurrency())))
+	v.ui.RenderTable(header, rows)
+}
+
+// planCost returns the estimated cost of the plan of the response, if it has one.
+func planCost(res *pb.Operation_Response) *pb.Terraform_CostEstimate {
+	switch res.GetValue().(type) {
+	case *pb.Operation_Response_Check_:
+		return res.GetCheck().GetPlan().GetCost()
+	case *pb.Operation_Response_Launch_:
+		return res.GetLaunch().GetPlan().GetCost()
+	case *pb.Operation_Response_Run_:
+		return res.GetRun().GetPlan().GetCost()
+	default:
+		return nil
+	}
+}
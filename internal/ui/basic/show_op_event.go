@@ -44,6 +44,16 @@ func (v *View) ShowOperationEvent(event *pb.Operation_Event) {
 		v.writeEventExec(event, msg)
 	case *pb.Operation_Event_Show:
 		v.writeEventShow(event, msg)
+	case *pb.Operation_Event_ProvidersLock:
+		v.writeEventProvidersLock(event, msg)
+	case *pb.Operation_Event_StatePull:
+		v.writeEventStatePull(event, msg)
+	case *pb.Operation_Event_StatePush:
+		v.writeEventStatePush(event, msg)
+	case *pb.Operation_Event_StepProgress:
+		v.writeEventStepProgress(event)
+
+		return
 	case *pb.Operation_Event_Output:
 		// Don't display output events by default since outputs have their own
 		// view.
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/hashicorp/enos/internal/ci"
 	"github.com/hashicorp/enos/internal/diagnostics"
 	"github.com/hashicorp/enos/internal/flightplan"
 	"github.com/hashicorp/enos/internal/ui/status"
@@ -31,24 +32,26 @@ func (v *View) ShowOperationResponses(res *pb.OperationResponses) error {
 		}
 	}
 
-	header := "\nEnos operations"
+	var header string
 	if failed {
+		header = "\n" + v.t("Enos operations failed!")
 		if v.settings.GetIsTty() {
 			//nolint:gosec // G404 it's okay to use weak random numbers for random failed icons
 			r := rand.New(rand.NewSource(time.Now().UnixNano()))
-			header = fmt.Sprintf("%s failed! %s\n", header, failedIcons[r.Intn(len(failedIcons))])
+			header = fmt.Sprintf("%s %s\n", header, failedIcons[r.Intn(len(failedIcons))])
 		} else {
-			header += " failed!\n"
+			header += "\n"
 		}
 	} else {
+		header = "\n" + v.t("Enos operations finished!")
 		if v.settings.GetIsTty() {
-			header += " finished! 🐵\n"
+			header += " 🐵\n"
 		} else {
-			header += " finished!\n"
+			header += "\n"
 		}
 	}
 
-	if diagnostics.HasFailed(v.Settings().GetFailOnWarnings(), diags) {
+	if len(res.GetResponses()) == 0 && diagnostics.HasFailed(v.Settings().GetFailOnWarnings(), diags) {
 		// Our request failed so show our header and request diagnostics
 		v.ui.Error(header)
 
@@ -84,9 +87,40 @@ func (v *View) ShowOperationResponses(res *pb.OperationResponses) error {
 		}
 	}
 
+	v.writeXFailSummary(res.GetResponses())
+	v.writeOperationTiming(res.GetTiming())
+	v.writeOperationCosts(res.GetResponses())
+	v.writeArtifacts(res.GetArtifacts())
+
 	return status.OperationResponses(v.Settings().GetFailOnWarnings(), res)
 }
 
+// writeXFailSummary writes the number of operations of scenarios that are expected to fail that
+// failed as expected and that passed unexpectedly, if there are any.
+func (v *View) writeXFailSummary(responses []*pb.Operation_Response) {
+	xfailed, xpassed := 0, 0
+	for _, r := range responses {
+		switch {
+		case r.GetXfail() == nil:
+		case r.GetXfail().GetFailed():
+			xfailed++
+		default:
+			xpassed++
+		}
+	}
+
+	if xfailed == 0 && xpassed == 0 {
+		return
+	}
+
+	v.ui.Info("\n" + v.t("Expected failures: %d", xfailed))
+	if xpassed > 0 {
+		v.ui.Warn(v.t("Unexpected passes: %d", xpassed))
+	} else {
+		v.ui.Info(v.t("Unexpected passes: %d", xpassed))
+	}
+}
+
 // ShowOperationResponse shows an operation response.
 func (v *View) ShowOperationResponse(res *pb.Operation_Response) error {
 	if res == nil {
@@ -110,12 +144,35 @@ func (v *View) showOperationResponse(res *pb.Operation_Response, fullOnComplete
 
 	scenario := flightplan.NewScenario()
 	scenario.FromRef(res.GetOp().GetScenario())
-	v.ui.Info(fmt.Sprintf("Scenario: %s %s", scenario.String(), v.opStatusString(res.GetStatus())))
+	header := v.t("Scenario: %s %s", scenario.String(), v.opStatusString(res.GetStatus()))
+	if xfail := res.GetXfail(); xfail != nil {
+		if xfail.GetFailed() {
+			header += " " + v.t("(expected failure: %s)", xfail.GetReason())
+		} else {
+			header += " " + v.t("(unexpected pass, expected failure: %s)", xfail.GetReason())
+		}
+	}
 
 	if !shouldShowFullResponse(res, fullOnComplete) {
+		v.ui.Info(header)
+
 		return nil
 	}
 
+	// In CI environments the full response of each scenario is shown in a collapsible group.
+	// Groups of scenarios that didn't complete are expanded where possible.
+	expanded := res.GetStatus() != pb.Operation_STATUS_COMPLETED
+	if start := ci.StartGroup(v.settings.GetCi(), scenario.UID(), header, expanded, time.Now()); start != "" {
+		v.ui.Info(start)
+		defer func() {
+			if end := ci.EndGroup(v.settings.GetCi(), scenario.UID(), time.Now()); end != "" {
+				v.ui.Info(end)
+			}
+		}()
+	} else {
+		v.ui.Info(header)
+	}
+
 	switch t := res.GetValue().(type) {
 	case *pb.Operation_Response_Generate_:
 		v.writeGenerateResponse(res.GetGenerate())
@@ -135,6 +192,7 @@ func (v *View) showOperationResponse(res *pb.Operation_Response, fullOnComplete
 		}
 		if apply := res.GetLaunch().GetApply(); apply != nil {
 			v.writePlainTextResponse("apply", apply.GetStderr(), apply)
+			v.writeStepResults(apply.GetStepResults(), true)
 		}
 	case *pb.Operation_Response_Destroy_:
 		v.writeGenerateResponse(res.GetGenerate())
@@ -154,6 +212,7 @@ func (v *View) showOperationResponse(res *pb.Operation_Response, fullOnComplete
 		}
 		if apply := res.GetRun().GetApply(); apply != nil {
 			v.writePlainTextResponse("apply", apply.GetStderr(), apply)
+			v.writeStepResults(apply.GetStepResults(), true)
 		}
 		if show := res.GetRun().GetPriorStateShow(); show != nil {
 			v.writeShowResponse(show)
@@ -165,10 +224,36 @@ func (v *View) showOperationResponse(res *pb.Operation_Response, fullOnComplete
 		v.writeExecResponse(res.GetExec().GetExec())
 	case *pb.Operation_Response_Output_:
 		v.writeOutputResponse(res)
+	case *pb.Operation_Response_Lock_:
+		v.writeGenerateResponse(res.GetLock().GetGenerate())
+		v.writeInitResponse(res.GetLock().GetInit())
+		v.writeProvidersLockResponse(res.GetLock().GetProvidersLock())
+	case *pb.Operation_Response_StateBackup_:
+		if pull := res.GetStateBackup().GetStatePull(); pull != nil {
+			v.writePlainTextResponse("state pull", pull.GetStderr(), pull)
+		}
+		v.writeStateSnapshot(res.GetStateBackup().GetSnapshot())
+		v.WriteDiagnostics(res.GetStateBackup().GetDiagnostics())
+	case *pb.Operation_Response_StateRestore_:
+		if pull := res.GetStateRestore().GetStatePull(); pull != nil {
+			v.writePlainTextResponse("state pull", pull.GetStderr(), pull)
+		}
+		if backup := res.GetStateRestore().GetBackup(); backup != nil {
+			v.ui.Info("  " + v.t("Backup: %s", backup.GetId()))
+		}
+		v.writeStateSnapshot(res.GetStateRestore().GetSnapshot())
+		if push := res.GetStateRestore().GetStatePush(); push != nil {
+			v.writePlainTextResponse("state push", push.GetStderr(), push)
+		}
+		v.WriteDiagnostics(res.GetStateRestore().GetDiagnostics())
 	default:
 		return fmt.Errorf("unable to display operation response value '%t:%[1]v'", t)
 	}
 
+	// Diagnostics of the operation itself, e.g. the outputs of a scenario that it consumes not
+	// being available
+	v.WriteDiagnostics(res.GetDiagnostics())
+
 	return nil
 }
 
@@ -193,5 +278,15 @@ func shouldShowFullResponse(res *pb.Operation_Response, fullOnComplete bool) boo
 		return true
 	}
 
+	// The snapshots are only useful if we show their IDs
+	if res.GetStateBackup() != nil || res.GetStateRestore() != nil {
+		return true
+	}
+
+	// Dry runs are only useful if we show the files
+	if len(res.GetGenerate().GetFiles()) > 0 {
+		return true
+	}
+
 	return false
 }
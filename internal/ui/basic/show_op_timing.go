@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// writeOperationTiming writes the summary table of how long the phases of each scenario took
+// and the slowest phases.
+func (v *View) writeOperationTiming(timing *pb.Operation_Timing) {
+	if timing == nil || len(timing.GetScenarios()) < 1 {
+		return
+	}
+
+	scenarioString := func(ref *pb.Ref_Scenario) string {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(ref)
+
+		return scenario.String()
+	}
+
+	header := []string{"scenario", "generate", "init", "validate", "plan", "apply", "destroy", "total"}
+	rows := [][]string{{""}} // add a padding row
+	for _, s := range timing.GetScenarios() {
+		rows = append(rows, []string{
+			scenarioString(s.GetScenario()),
+			timingString(s.GetGenerate()),
+			timingString(s.GetInit()),
+			timingString(s.GetValidate()),
+			timingString(s.GetPlan()),
+			timingString(s.GetApply()),
+			timingString(s.GetDestroy()),
+			timingString(s.GetTotal()),
+		})
+	}
+
+	v.ui.Info("\n" + v.t("Timing: %s", timingString(timing.GetTotal())))
+	v.ui.RenderTable(header, rows)
+
+	if len(timing.GetSlowest()) < 1 {
+		return
+	}
+
+	rows = [][]string{{""}}
+	for _, step := range timing.GetSlowest() {
+		rows = append(rows, []string{
+			scenarioString(step.GetScenario()),
+			step.GetPhase(),
+			timingString(step.GetElapsed()),
+		})
+	}
+
+	v.ui.Info("\n" + v.t("Slowest steps:"))
+	v.ui.RenderTable([]string{"scenario", "phase", "elapsed"}, rows)
+}
+
+// timingString returns the duration rounded to milliseconds, or a dash if the phase didn't run.
+func timingString(d *durationpb.Duration) string {
+	if d == nil {
+		return "-"
+	}
+
+	return d.AsDuration().Round(time.Millisecond).String()
+}
@@ -0,0 +1,214 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// operationTimeFormat is the format of the times that operations started and completed.
+const operationTimeFormat = "2006-01-02 15:04:05"
+
+// ShowOperationList shows the operations that have finished, most recent first.
+func (v *View) ShowOperationList(res *pb.ListOperationsResponse) error {
+	header := []string{"id", "type", "scenario", "status", "started", "elapsed"}
+	rows := [][]string{{""}} // add a padding row
+	for _, op := range res.GetOperations() {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(op.GetOp().GetScenario())
+
+		rows = append(rows, []string{
+			op.GetOp().GetId(),
+			op.GetType(),
+			scenario.String(),
+			v.opStatusString(op.GetStatus()),
+			operationTimeString(op.GetStartedAt()),
+			timingString(operationElapsed(op.GetStartedAt(), op.GetCompletedAt())),
+		})
+	}
+
+	if len(rows) > 1 {
+		v.ui.RenderTable(header, rows)
+	} else if !status.HasErrorDiags(res) {
+		v.ui.Info(v.t("No operations found"))
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.ListOperations(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowOperation shows the details of an operation that has finished and its full response.
+func (v *View) ShowOperation(res *pb.ShowOperationResponse) error {
+	if op := res.GetOperation(); op != nil {
+		v.ui.Info(v.t("Operation: %s", op.GetOp().GetId()))
+		v.ui.Info("  Type: " + res.GetType())
+		v.ui.Info("  Started: " + operationTimeString(op.GetStartedAt()))
+		v.ui.Info("  Completed: " + operationTimeString(op.GetCompletedAt()))
+		v.ui.Info("  Elapsed: " + timingString(operationElapsed(op.GetStartedAt(), op.GetCompletedAt())))
+		if mod := operationModule(op); mod.GetModulePath() != "" {
+			v.ui.Info("  Module path: " + mod.GetModulePath())
+		}
+		if res.GetPath() != "" {
+			v.ui.Info("  File: " + res.GetPath())
+		}
+		v.ui.Info("")
+
+		if err := v.showOperationResponse(op, true); err != nil {
+			return err
+		}
+		v.WriteDiagnostics(op.GetDiagnostics())
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.ShowOperation(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowOperationDiff shows how two operations that have finished differ.
+func (v *View) ShowOperationDiff(res *pb.DiffOperationsResponse) error {
+	if res.GetFrom() != nil && res.GetTo() != nil {
+		v.ui.Info(v.t("From: %s", v.operationSummaryString(res.GetFrom())))
+		v.ui.Info(v.t("To: %s", v.operationSummaryString(res.GetTo())))
+
+		if len(res.GetOutputs()) > 0 {
+			v.ui.Info(v.t("Outputs:"))
+			for _, out := range res.GetOutputs() {
+				v.ui.Info("  " + operationDiffOutputString(out))
+			}
+		}
+
+		if len(res.GetResources()) > 0 {
+			v.ui.Info(v.t("Resources:"))
+			for _, r := range res.GetResources() {
+				v.ui.Info(fmt.Sprintf("  %s: %d => %d (%+d)", r.GetStep(), r.GetFrom(), r.GetTo(), r.GetTo()-r.GetFrom()))
+			}
+		}
+
+		if len(res.GetDurations()) > 0 {
+			v.ui.Info(v.t("Durations:"))
+			for _, d := range res.GetDurations() {
+				v.ui.Info("  " + operationDiffDurationString(d))
+			}
+		}
+
+		if len(res.GetNewDiagnostics()) > 0 {
+			v.ui.Info(v.t("New diagnostics:"))
+			v.WriteDiagnostics(res.GetNewDiagnostics())
+		}
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.DiffOperations(v.settings.GetFailOnWarnings(), res)
+}
+
+// operationSummaryString returns the ID, type, scenario, status and start time of the operation.
+func (v *View) operationSummaryString(op *pb.ListOperationsResponse_Operation) string {
+	scenario := flightplan.NewScenario()
+	scenario.FromRef(op.GetOp().GetScenario())
+
+	return fmt.Sprintf("%s %s %s %s %s",
+		op.GetOp().GetId(),
+		op.GetType(),
+		scenario.String(),
+		v.opStatusString(op.GetStatus()),
+		operationTimeString(op.GetStartedAt()),
+	)
+}
+
+// operationDiffOutputString returns how the output value has changed. Sensitive values are never
+// shown.
+func operationDiffOutputString(out *pb.DiffOperationsResponse_Output) string {
+	from, to := out.GetFrom(), out.GetTo()
+	if out.GetSensitive() {
+		from, to = "(sensitive)", "(sensitive)"
+	}
+
+	switch out.GetChange() {
+	case pb.DiffOperationsResponse_CHANGE_ADDED:
+		return fmt.Sprintf("+ %s: %s", out.GetName(), to)
+	case pb.DiffOperationsResponse_CHANGE_REMOVED:
+		return fmt.Sprintf("- %s: %s", out.GetName(), from)
+	default:
+		return fmt.Sprintf("~ %s: %s => %s", out.GetName(), from, to)
+	}
+}
+
+// operationDiffDurationString returns how long the phase took in each operation and the change.
+func operationDiffDurationString(d *pb.DiffOperationsResponse_Duration) string {
+	if d.GetFrom() == nil || d.GetTo() == nil {
+		return fmt.Sprintf("%s: %s => %s", d.GetPhase(), operationDurationString(d.GetFrom()), operationDurationString(d.GetTo()))
+	}
+
+	from := d.GetFrom().AsDuration().Round(10 * time.Millisecond)
+	to := d.GetTo().AsDuration().Round(10 * time.Millisecond)
+
+	change := (to - from).String()
+	if to >= from {
+		change = "+" + change
+	}
+
+	return fmt.Sprintf("%s: %s => %s (%s)", d.GetPhase(), from, to, change)
+}
+
+// operationDurationString returns the duration rounded to ten milliseconds, or a dash if it isn't set.
+func operationDurationString(d *durationpb.Duration) string {
+	if d == nil {
+		return "-"
+	}
+
+	return d.AsDuration().Round(10 * time.Millisecond).String()
+}
+
+// operationTimeString returns the time in the local time zone, or a dash if it isn't set.
+func operationTimeString(t *timestamppb.Timestamp) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.AsTime().Local().Format(operationTimeFormat)
+}
+
+// operationElapsed returns how long the operation took, or nil if we don't know.
+func operationElapsed(started, completed *timestamppb.Timestamp) *durationpb.Duration {
+	if started == nil || completed == nil {
+		return nil
+	}
+
+	return durationpb.New(completed.AsTime().Sub(started.AsTime()).Round(time.Second))
+}
+
+// operationModule returns the Terraform module of the operation response.
+func operationModule(res *pb.Operation_Response) *pb.Terraform_Module {
+	switch {
+	case res.GetGenerate() != nil:
+		return res.GetGenerate().GetTerraformModule()
+	case res.GetCheck() != nil:
+		return res.GetCheck().GetGenerate().GetTerraformModule()
+	case res.GetLaunch() != nil:
+		return res.GetLaunch().GetGenerate().GetTerraformModule()
+	case res.GetDestroy() != nil:
+		return res.GetDestroy().GetGenerate().GetTerraformModule()
+	case res.GetRun() != nil:
+		return res.GetRun().GetGenerate().GetTerraformModule()
+	case res.GetLock() != nil:
+		return res.GetLock().GetGenerate().GetTerraformModule()
+	case res.GetExec() != nil:
+		return res.GetExec().GetTerraformModule()
+	case res.GetOutput() != nil:
+		return res.GetOutput().GetTerraformModule()
+	case res.GetStateBackup() != nil:
+		return res.GetStateBackup().GetTerraformModule()
+	case res.GetStateRestore() != nil:
+		return res.GetStateRestore().GetTerraformModule()
+	default:
+		return nil
+	}
+}
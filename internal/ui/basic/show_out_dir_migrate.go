@@ -0,0 +1,42 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowOutDirMigrate shows the generated modules that have been migrated to the out directory format
+// of this version of enos and any modules of scenarios that are no longer in the flight plan.
+func (v *View) ShowOutDirMigrate(res *pb.MigrateOutDirResponse) error {
+	for _, migration := range res.GetMigrations() {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(migration.GetScenario())
+		v.ui.Output(v.t("Scenario: %s", scenario.String()))
+		line := fmt.Sprintf("  v%d -> v%d", migration.GetFromVersion(), migration.GetToVersion())
+		if migration.GetFrom() != migration.GetTo() {
+			line += " " + migration.GetFrom() + " -> " + migration.GetTo()
+		} else {
+			line += " " + migration.GetTo()
+		}
+		v.ui.Output(line)
+	}
+
+	if len(res.GetMigrations()) == 0 {
+		v.ui.Info(v.t("No modules to migrate"))
+	}
+
+	for _, orphan := range res.GetOrphans() {
+		v.ui.Warn(v.t("Module does not belong to any scenario in the flight plan: %s", orphan))
+	}
+
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.MigrateOutDir(v.settings.GetFailOnWarnings(), res)
+}
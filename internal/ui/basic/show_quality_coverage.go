@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowQualityCoverage shows how many scenarios verify each quality and the qualities that no
+// scenario verifies.
+func (v *View) ShowQualityCoverage(res *pb.CheckQualityCoverageResponse) error {
+	if len(res.GetCoverage()) > 0 {
+		header := []string{"quality", "scenarios"}
+		rows := [][]string{{""}} // add a padding row
+		for _, coverage := range res.GetCoverage() {
+			rows = append(rows, []string{
+				coverage.GetQuality().GetName(),
+				strconv.Itoa(len(coverage.GetScenarios())),
+			})
+		}
+		v.ui.RenderTable(header, rows)
+	}
+
+	if unverified := res.GetUnverified(); len(unverified) > 0 {
+		names := []string{}
+		for _, quality := range unverified {
+			names = append(names, quality.GetName())
+		}
+		v.ui.Info(v.t("%d of %d qualities are not verified by any scenario: %s",
+			len(unverified), len(res.GetCoverage()), strings.Join(names, ", "),
+		))
+	} else if len(res.GetCoverage()) > 0 {
+		v.ui.Info(v.t("Every quality is verified by at least one scenario"))
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.CheckQualityCoverage(v.settings.GetFailOnWarnings(), res)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"strings"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowReportMerge shows the merged report.
+func (v *View) ShowReportMerge(res *pb.MergeReportsResponse) error {
+	scenarioString := func(ref *pb.Ref_Scenario) string {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(ref)
+
+		return scenario.String()
+	}
+
+	if len(res.GetScenarios()) > 0 {
+		header := []string{"scenario", "result", "duration", "reports"}
+		rows := [][]string{{""}} // add a padding row
+		for _, s := range res.GetScenarios() {
+			result := v.t("success!")
+			switch {
+			case s.GetFailed():
+				result = v.t("failed!")
+			case s.GetSkipped():
+				result = v.t("skipped: %s", s.GetSkipReason())
+			}
+			reports := strings.Join(s.GetSources(), ", ")
+			if s.GetDuplicate() {
+				reports += " " + v.t("(duplicate)")
+			}
+
+			rows = append(rows, []string{
+				scenarioString(s.GetScenario()),
+				result,
+				timingString(s.GetDuration()),
+				reports,
+			})
+		}
+		v.ui.RenderTable(header, rows)
+	}
+
+	summary := res.GetSummary()
+	v.ui.Info("\n" + v.t("Merged %d reports: %d passed, %d failed, %d duplicated",
+		summary.GetReports(), summary.GetPassed(), summary.GetFailed(), summary.GetDuplicates(),
+	))
+	if summary.GetSkipped() > 0 {
+		v.ui.Info(v.t("Skipped %d scenarios", summary.GetSkipped()))
+	}
+
+	if coverage := res.GetCoverage(); coverage != nil {
+		pct := 100.0
+		if coverage.GetExpected() > 0 {
+			pct = float64(coverage.GetCovered()) / float64(coverage.GetExpected()) * 100
+		}
+		v.ui.Info(v.t("Coverage: %d of %d scenarios (%.1f%%)", coverage.GetCovered(), coverage.GetExpected(), pct))
+	}
+
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.MergeReports(v.settings.GetFailOnWarnings(), res)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowReportTrends shows the trends of runs.
+func (v *View) ShowReportTrends(res *pb.ReportTrendsResponse) error {
+	scenarioString := func(trend *pb.Report_Trend) string {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(trend.GetScenario())
+
+		return scenario.String()
+	}
+
+	v.ui.Info(v.t("Compared %d runs: %d newly failing, %d newly fixed, %d duration regressions, %d flaky",
+		res.GetRuns(), len(res.GetNewlyFailing()), len(res.GetNewlyFixed()),
+		len(res.GetDurationRegressions()), len(res.GetFlaky()),
+	))
+
+	for _, section := range []struct {
+		title  string
+		trends []*pb.Report_Trend
+		header []string
+		row    func(*pb.Report_Trend) []string
+	}{
+		{
+			"Newly failing:", res.GetNewlyFailing(), []string{"scenario", "error"},
+			func(t *pb.Report_Trend) []string { return []string{scenarioString(t), t.GetError()} },
+		},
+		{
+			"Newly fixed:", res.GetNewlyFixed(), []string{"scenario"},
+			func(t *pb.Report_Trend) []string { return []string{scenarioString(t)} },
+		},
+		{
+			"Duration regressions:", res.GetDurationRegressions(), []string{"scenario", "duration", "baseline", "change"},
+			func(t *pb.Report_Trend) []string {
+				return []string{
+					scenarioString(t),
+					timingString(t.GetDuration()),
+					timingString(t.GetBaseline()),
+					fmt.Sprintf("+%.0f%%", t.GetDurationChange()*100),
+				}
+			},
+		},
+		{
+			"Flaky:", res.GetFlaky(), []string{"scenario", "runs", "failures", "flakiness"},
+			func(t *pb.Report_Trend) []string {
+				return []string{
+					scenarioString(t),
+					strconv.Itoa(int(t.GetRuns())),
+					strconv.Itoa(int(t.GetFailures())),
+					fmt.Sprintf("%.0f%%", t.GetFlakiness()*100),
+				}
+			},
+		},
+	} {
+		if len(section.trends) < 1 {
+			continue
+		}
+
+		rows := [][]string{{""}} // add a padding row
+		for _, trend := range section.trends {
+			rows = append(rows, section.row(trend))
+		}
+
+		v.ui.Info("\n" + v.t(section.title))
+		v.ui.RenderTable(section.header, rows)
+	}
+
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.ReportTrends(v.settings.GetFailOnWarnings(), res)
+}
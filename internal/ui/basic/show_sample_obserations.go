@@ -5,7 +5,9 @@ package basic
 
 import (
 	"fmt"
+	"io"
 	"slices"
+	"strings"
 
 	"github.com/hashicorp/enos/internal/ui/status"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
@@ -17,30 +19,9 @@ func (v *View) ShowSampleObservation(res *pb.ObserveSampleResponse) error {
 	rows := [][]string{{""}} // add a padding row
 	maxAttrs := 0
 	for _, elm := range res.GetObservation().GetElements() {
-		row := []string{
-			elm.GetSample().GetId().GetName(),
-			elm.GetSubset().GetId().GetName(),
-			elm.GetScenario().GetId().GetFilter(),
-		}
-
-		attrs := elm.GetAttributes().AsMap()
-		if len(attrs) < 1 {
-			rows = append(rows, row)
-
-			continue
-		}
-
-		if len(attrs) > maxAttrs {
-			maxAttrs = len(attrs)
-		}
-		keys := []string{}
-		for key := range attrs {
-			keys = append(keys, key)
-		}
-		slices.Sort(keys)
-
-		for i := range keys {
-			row = append(row, fmt.Sprintf("%s=%v", keys[i], attrs[keys[i]]))
+		row := sampleElementRow(elm)
+		if attrs := len(row) - 3; attrs > maxAttrs {
+			maxAttrs = attrs
 		}
 
 		rows = append(rows, row)
@@ -61,3 +42,60 @@ func (v *View) ShowSampleObservation(res *pb.ObserveSampleResponse) error {
 
 	return status.ShowSampleObservation(v.settings.GetFailOnWarnings(), res)
 }
+
+// ShowSampleObservationStream shows each element of the sample observation as soon as it has been
+// received. As we can't know the width of every column ahead of time the columns are not aligned.
+func (v *View) ShowSampleObservationStream(stream pb.EnosService_StreamObserveSampleClient) error {
+	res := &pb.ObserveSampleResponse{}
+	count := 0
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch val := msg.GetResponse().(type) {
+		case *pb.EnosServiceObserveSampleResponse_Decode:
+			res.Decode = val.Decode
+		case *pb.EnosServiceObserveSampleResponse_Element:
+			if count == 0 {
+				v.ui.Output("SAMPLE  SUBSET  SCENARIO FILTER  ATTRIBUTES")
+				v.ui.Output("")
+			}
+			count++
+			v.ui.Output(v.ui.TruncateLine(strings.Join(sampleElementRow(val.Element), "  ")))
+		default:
+		}
+	}
+
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.ShowSampleObservation(v.settings.GetFailOnWarnings(), res)
+}
+
+// sampleElementRow returns the sample observation row for the element. Attributes are sorted by
+// key and follow the sample, subset, and scenario filter.
+func sampleElementRow(elm *pb.Sample_Element) []string {
+	row := []string{
+		elm.GetSample().GetId().GetName(),
+		elm.GetSubset().GetId().GetName(),
+		elm.GetScenario().GetId().GetFilter(),
+	}
+
+	attrs := elm.GetAttributes().AsMap()
+	keys := []string{}
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for i := range keys {
+		row = append(row, fmt.Sprintf("%s=%v", keys[i], attrs[keys[i]]))
+	}
+
+	return row
+}
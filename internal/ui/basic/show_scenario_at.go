@@ -0,0 +1,122 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioAt shows what encloses a position of a flight plan file and the resolved values of
+// the enclosing scenario variants.
+func (v *View) ShowScenarioAt(res *pb.ShowScenarioAtResponse) error {
+	if enclosing := res.GetEnclosing(); enclosing != nil {
+		b := new(strings.Builder)
+		for _, line := range []struct {
+			format string
+			value  string
+		}{
+			{"Scenario: %s", enclosing.GetScenario()},
+			{"Step: %s", enclosing.GetStep()},
+			{"Output: %s", enclosing.GetOutput()},
+			{"Block: %s", enclosing.GetBlock()},
+			{"Attribute: %s", enclosing.GetAttribute()},
+		} {
+			if line.value != "" {
+				printLine(b, 0, v.t(line.format, line.value))
+			}
+		}
+		if rng := enclosing.GetRange(); rng.GetFilename() != "" {
+			source := fmt.Sprintf("%s:%d,%d-%d,%d", rng.GetFilename(),
+				rng.GetStart().GetLine(), rng.GetStart().GetColumn(),
+				rng.GetEnd().GetLine(), rng.GetEnd().GetColumn(),
+			)
+			if v.settings.GetHyperlinks() {
+				source = diagnostics.Hyperlink(rng, source)
+			}
+			printLine(b, 0, v.t("Source: %s", source))
+		}
+		if b.Len() == 0 {
+			printLine(b, 0, v.t("The position is not in a block"))
+		}
+
+		for _, scenario := range res.GetScenarios() {
+			b.WriteString("\n")
+			writeExportedScenario(b, scenario)
+		}
+
+		v.ui.Output(strings.TrimSuffix(b.String(), "\n"))
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.ShowScenarioAt(v.settings.GetFailOnWarnings(), res)
+}
+
+// writeExportedScenario writes the resolved steps and outputs of an exported scenario variant.
+func writeExportedScenario(b *strings.Builder, scenario *pb.FlightPlanExport_Scenario) {
+	printLine(b, 0, scenario.GetScenario().GetId().GetFilter())
+	for _, step := range scenario.GetSteps() {
+		printLine(b, 2, "step "+step.GetName())
+		if step.GetModule() != "" {
+			printLine(b, 4, "module = "+step.GetModule())
+		}
+		if step.GetProvisioner() != "" {
+			printLine(b, 4, "provisioner = "+step.GetProvisioner())
+		}
+		if step.GetSkip() {
+			printLine(b, 4, "skip = true")
+		}
+		if len(step.GetDependsOn()) > 0 {
+			printLine(b, 4, "depends_on = "+strings.Join(step.GetDependsOn(), ", "))
+		}
+		names := []string{}
+		for name := range step.GetVariables() {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			printLine(b, 4, fmt.Sprintf("variables.%s = %s", name, exportedValueString(step.GetVariables()[name])))
+		}
+	}
+	for _, output := range scenario.GetOutputs() {
+		value := exportedValueString(output.GetValue())
+		if output.GetSensitive() {
+			value = "(sensitive)"
+		}
+		printLine(b, 2, fmt.Sprintf("output %s = %s", output.GetName(), value))
+	}
+}
+
+// exportedValueString returns the reference or the JSON of a known exported value.
+func exportedValueString(val *pb.FlightPlanExport_Value) string {
+	if val.GetReference() != "" {
+		return val.GetReference()
+	}
+	if val.GetKnown() == nil {
+		return "null"
+	}
+
+	out, err := protojson.Marshal(val.GetKnown())
+	if err != nil {
+		return ""
+	}
+
+	// protojson randomizes its whitespace
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, out); err != nil {
+		return string(out)
+	}
+
+	return buf.String()
+}
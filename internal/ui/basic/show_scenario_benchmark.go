@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioBenchmark shows the scenario benchmark.
+func (v *View) ShowScenarioBenchmark(res *pb.BenchmarkScenariosResponse) error {
+	header := []string{
+		"operation", "iterations", "scenarios", "mean", "min", "p50", "p90", "p99", "max",
+		"allocs/op", "bytes/op",
+	}
+	rows := [][]string{{""}} // add a padding row
+	for _, b := range []struct {
+		op        string
+		benchmark *pb.Benchmark
+	}{
+		{"decode", res.GetDecodeBenchmark()},
+		{"generate", res.GetGenerateBenchmark()},
+	} {
+		if b.benchmark == nil {
+			continue
+		}
+
+		rows = append(rows, []string{
+			b.op,
+			strconv.FormatInt(int64(b.benchmark.GetIterations()), 10),
+			strconv.FormatInt(b.benchmark.GetScenarios(), 10),
+			b.benchmark.GetMean().AsDuration().String(),
+			b.benchmark.GetMin().AsDuration().String(),
+			b.benchmark.GetP50().AsDuration().String(),
+			b.benchmark.GetP90().AsDuration().String(),
+			b.benchmark.GetP99().AsDuration().String(),
+			b.benchmark.GetMax().AsDuration().String(),
+			strconv.FormatUint(b.benchmark.GetAllocsPerOp(), 10),
+			strconv.FormatUint(b.benchmark.GetBytesPerOp(), 10),
+		})
+	}
+
+	if len(rows) > 1 {
+		v.ui.RenderTable(header, rows)
+	}
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.BenchmarkScenarios(v.settings.GetFailOnWarnings(), res)
+}
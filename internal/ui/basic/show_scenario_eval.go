@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"strings"
+
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioEval shows the value and type of an expression in every scenario variant that it was
+// evaluated in.
+func (v *View) ShowScenarioEval(res *pb.EvalScenarioExpressionResponse) error {
+	for _, result := range res.GetResults() {
+		b := new(strings.Builder)
+		printLine(b, 0, result.GetScenario().GetId().GetFilter())
+		if value := result.GetValue(); value != "" {
+			lines := strings.Split(value, "\n")
+			printLine(b, 2, v.t("Value: %s", lines[0]))
+			for _, line := range lines[1:] {
+				printLine(b, 2, line)
+			}
+
+			switch {
+			case result.GetType() != "":
+				printLine(b, 2, v.t("Type: %s", result.GetType()))
+			case result.GetDeferred():
+				printLine(b, 2, v.t("Type: known after apply"))
+			}
+
+			if result.GetDeferred() {
+				printLine(b, 2, v.t("Deferred to Terraform: %s", strings.Join(result.GetReferences(), ", ")))
+			}
+		}
+
+		v.ui.Output(strings.TrimSuffix(b.String(), "\n"))
+		v.WriteDiagnostics(result.GetDiagnostics())
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.ShowScenarioEval(v.settings.GetFailOnWarnings(), res)
+}
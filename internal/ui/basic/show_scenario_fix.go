@@ -0,0 +1,32 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioFix shows the fixes that have been applied and the diff of every fixed file.
+func (v *View) ShowScenarioFix(res *pb.FixScenariosResponse) error {
+	for _, file := range res.GetFiles() {
+		v.ui.Output(file.GetPath())
+		for _, diag := range file.GetFixed() {
+			v.ui.Output("  - " + diag.GetFixes()[0].GetDescription())
+		}
+
+		if diff := file.GetDiff(); diff != "" {
+			v.ui.Output(diff)
+		}
+	}
+
+	if len(res.GetFiles()) == 0 {
+		v.ui.Info(v.t("No fixes to apply"))
+	}
+
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.FixScenarios(v.settings.GetFailOnWarnings(), res)
+}
@@ -0,0 +1,18 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioLint shows the scenario lint response.
+func (v *View) ShowScenarioLint(res *pb.LintScenariosResponse) error {
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetLints())
+
+	return status.LintScenarios(v.settings.GetFailOnWarnings(), res)
+}
@@ -4,6 +4,10 @@
 package basic
 
 import (
+	"fmt"
+	"io"
+	"strings"
+
 	"github.com/hashicorp/enos/internal/flightplan"
 	"github.com/hashicorp/enos/internal/ui/status"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
@@ -11,33 +15,236 @@ import (
 
 // ShowScenarioList shows the a list of scenarios.
 func (v *View) ShowScenarioList(res *pb.ListScenariosResponse) error {
-	header := []string{"name"}
+	header := []string{}
 	rows := [][]string{{""}} // add a padding row
 	for i, ref := range res.GetScenarios() {
-		scenario := flightplan.NewScenario()
-		scenario.FromRef(ref)
-
-		if i == 0 && scenario.Variants != nil {
-			for vi := range scenario.Variants.Elements() {
-				if vi == 0 {
-					header = append(header, "variants")
-
-					continue
-				}
-				// create a blank "header" for every variant
-				header = append(header, "")
+		if i == 0 {
+			header = scenarioListHeader(ref)
+		}
+
+		if row := scenarioListRow(ref); row != nil {
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) > 1 {
+		v.ui.RenderTable(header, rows)
+	}
+	v.writeScenarioListPage(len(res.GetScenarios()), res)
+	v.writeScenarioListSkipped(res)
+	v.writeScenarioListExplanation(res.GetExplanation())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.ListScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioListStream shows each scenario of the list as soon as it has been received. As we
+// can't know the width of every column ahead of time the columns are not aligned.
+func (v *View) ShowScenarioListStream(stream pb.EnosService_ListScenariosClient) error {
+	res := &pb.ListScenariosResponse{}
+	count := 0
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch val := msg.GetResponse().(type) {
+		case *pb.EnosServiceListScenariosResponse_Decode:
+			res.Decode = val.Decode
+		case *pb.EnosServiceListScenariosResponse_Scenario:
+			row := scenarioListRow(val.Scenario)
+			if row == nil {
+				continue
 			}
+
+			if count == 0 {
+				v.ui.Output(v.ui.TruncateLine(strings.ToUpper(strings.Join(scenarioListHeader(val.Scenario), "  "))))
+				v.ui.Output("")
+			}
+			count++
+			v.ui.Output(v.ui.TruncateLine(strings.Join(row, "  ")))
+		case *pb.EnosServiceListScenariosResponse_Page_:
+			res.NextPageToken = val.Page.GetNextPageToken()
+			res.TotalScenarios = val.Page.GetTotalScenarios()
+		case *pb.EnosServiceListScenariosResponse_Explanation:
+			res.Explanation = val.Explanation
+		default:
+		}
+	}
+
+	v.writeScenarioListPage(count, res)
+	v.writeScenarioListSkipped(res)
+	v.writeScenarioListExplanation(res.GetExplanation())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.ListScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
+// writeScenarioListPage writes how to get the next page of a paginated list.
+func (v *View) writeScenarioListPage(count int, res *pb.ListScenariosResponse) {
+	if token := res.GetNextPageToken(); token != "" {
+		v.ui.Output("\n" + v.t("Showing %d of %d scenarios. Use --page-token %s to show the next page.",
+			count, res.GetTotalScenarios(), token,
+		))
+	}
+}
+
+// writeScenarioListSkipped writes the scenarios that were skipped by skip files. Explanations
+// already include them.
+func (v *View) writeScenarioListSkipped(res *pb.ListScenariosResponse) {
+	if res.GetExplanation() != nil || len(res.GetDecode().GetSkipped()) == 0 {
+		return
+	}
+
+	v.ui.Output("")
+	v.writeSkippedScenarios(res.GetDecode().GetSkipped())
+}
+
+// writeScenarioListExplanation writes which scenarios and variants were excluded and why.
+func (v *View) writeScenarioListExplanation(explanation *pb.Scenario_Filter_Explanation) {
+	if explanation == nil {
+		return
+	}
+
+	v.ui.Output("")
+	if len(explanation.GetExclusions()) == 0 {
+		v.ui.Output(v.t("No scenarios or variants were excluded"))
+
+		return
+	}
+
+	v.ui.Output(v.t("Excluded:"))
+	rows := [][]string{{""}} // add a padding row
+	for _, ex := range explanation.GetExclusions() {
+		variants := "-"
+		if ex.GetVariants() != nil {
+			variants = flightplan.NewVectorFromProto(ex.GetVariants()).String()
 		}
 
-		row := []string{scenario.Name}
-		if scenario.Variants != nil {
-			for _, elm := range scenario.Variants.Elements() {
-				row = append(row, elm.String())
+		rows = append(rows, []string{
+			ex.GetScenario(),
+			variants,
+			scenarioExclusionReasonString(ex.GetReason()),
+			ex.GetDetail(),
+		})
+	}
+	v.ui.RenderTable([]string{"scenario", "variants", "reason", "detail"}, rows)
+
+	if n := explanation.GetOmitted(); n > 0 {
+		v.ui.Output(v.t("%d more exclusions were omitted", n))
+	}
+}
+
+// scenarioExclusionReasonString returns the reason that a scenario or variant was excluded.
+func scenarioExclusionReasonString(reason pb.Scenario_Filter_Explanation_Reason) string {
+	switch reason {
+	case pb.Scenario_Filter_Explanation_REASON_NAME:
+		return "name mismatch"
+	case pb.Scenario_Filter_Explanation_REASON_MATRIX_EXCLUDE:
+		return "matrix exclude"
+	case pb.Scenario_Filter_Explanation_REASON_VARIANT_FILTER:
+		return "variant filter"
+	case pb.Scenario_Filter_Explanation_REASON_SAMPLE:
+		return "sample selection"
+	case pb.Scenario_Filter_Explanation_REASON_SKIP:
+		return "skip file"
+	default:
+		return "unknown"
+	}
+}
+
+// scenarioListHeader returns the scenario list header for the scenario.
+func scenarioListHeader(ref *pb.Ref_Scenario) []string {
+	header := []string{"name", "slug"}
+	if ref.GetMetadata() != nil {
+		header = append(header, "description", "tags", "verifies", "source")
+	}
+	scenario := flightplan.NewScenario()
+	scenario.FromRef(ref)
+
+	if scenario.Variants != nil {
+		for vi := range scenario.Variants.Elements() {
+			if vi == 0 {
+				header = append(header, "variants")
+
+				continue
 			}
-			rows = append(rows, row)
+			// create a blank "header" for every variant
+			header = append(header, "")
 		}
 	}
 
+	return header
+}
+
+// scenarioListRow returns the scenario list row for the scenario. Scenarios without variants
+// have no row.
+func scenarioListRow(ref *pb.Ref_Scenario) []string {
+	scenario := flightplan.NewScenario()
+	scenario.FromRef(ref)
+
+	if scenario.Variants == nil {
+		return nil
+	}
+
+	row := []string{scenario.Name, ref.GetId().GetSlug()}
+	if ref.GetMetadata() != nil {
+		row = append(row, scenarioMetadataColumns(ref)...)
+	}
+	for _, elm := range scenario.Variants.Elements() {
+		row = append(row, elm.String())
+	}
+
+	return row
+}
+
+// scenarioMetadataColumns returns the first line of the description, the tags, the verified
+// qualities, and the source location of the scenario. Missing values are a dash.
+func scenarioMetadataColumns(ref *pb.Ref_Scenario) []string {
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+
+		return s
+	}
+
+	description, _, _ := strings.Cut(strings.TrimSpace(ref.GetId().GetDescription()), "\n")
+	verifies := []string{}
+	for _, q := range ref.GetMetadata().GetVerifies() {
+		verifies = append(verifies, q.GetName())
+	}
+	source := ""
+	if rng := ref.GetMetadata().GetRange(); rng.GetFilename() != "" {
+		source = fmt.Sprintf("%s:%d", rng.GetFilename(), rng.GetStart().GetLine())
+	}
+
+	return []string{
+		orDash(description),
+		orDash(strings.Join(ref.GetMetadata().GetTags(), ",")),
+		orDash(strings.Join(verifies, ",")),
+		orDash(source),
+	}
+}
+
+// ShowScenarioIDs shows the identifiers of scenarios.
+func (v *View) ShowScenarioIDs(res *pb.ListScenariosResponse) error {
+	header := []string{"slug", "uid", "scenario"}
+	rows := [][]string{{""}} // add a padding row
+	for _, ref := range res.GetScenarios() {
+		rows = append(rows, []string{
+			ref.GetId().GetSlug(),
+			ref.GetId().GetUid(),
+			ref.GetId().GetFilter(),
+		})
+	}
+
 	if len(rows) > 1 {
 		v.ui.RenderTable(header, rows)
 	}
@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioMatrix shows the dimensions of a scenario matrix, how many vectors remain after its
+// excludes and the filter, and a preview of the filtered vectors.
+func (v *View) ShowScenarioMatrix(res *pb.ShowScenarioMatrixResponse) error {
+	if summary := res.GetSummary(); summary != nil {
+		header := []string{"dimension", "count", "values"}
+		rows := [][]string{{""}} // add a padding row
+		for _, dim := range summary.GetDimensions() {
+			rows = append(rows, []string{
+				dim.GetKey(),
+				strconv.Itoa(len(dim.GetValues())),
+				strings.Join(dim.GetValues(), ", "),
+			})
+		}
+		v.ui.RenderTable(header, rows)
+
+		v.ui.Info(v.t("Scenario %s has %d vectors, %d after excludes and %d after the filter",
+			summary.GetScenario(), summary.GetVectors(), summary.GetExcluded(), summary.GetFiltered(),
+		))
+
+		if preview := summary.GetPreview(); len(preview) > 0 {
+			v.ui.Info(v.t("Preview of %d of %d filtered vectors:", len(preview), summary.GetFiltered()))
+			for _, vec := range preview {
+				v.ui.Info("  " + flightplan.NewVectorFromProto(vec).String())
+			}
+		}
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.ShowScenarioMatrix(v.settings.GetFailOnWarnings(), res)
+}
@@ -0,0 +1,36 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioMatrixDiff shows the scenario variants that were added and removed compared to the
+// base flight plan.
+func (v *View) ShowScenarioMatrixDiff(res *pb.DiffScenarioMatricesResponse) error {
+	if len(res.GetAdded()) > 0 || len(res.GetRemoved()) > 0 {
+		header := []string{"", "scenario"}
+		rows := [][]string{{""}} // add a padding row
+		for _, ref := range res.GetAdded() {
+			rows = append(rows, []string{"+", ref.GetId().GetFilter()})
+		}
+		for _, ref := range res.GetRemoved() {
+			rows = append(rows, []string{"-", ref.GetId().GetFilter()})
+		}
+		v.ui.RenderTable(header, rows)
+	}
+
+	if !status.HasFailed(v.settings.GetFailOnWarnings(), res, res.GetBaseDecode(), res.GetDecode()) {
+		v.ui.Info(v.t("%d scenario variants added, %d removed and %d unchanged",
+			len(res.GetAdded()), len(res.GetRemoved()), res.GetUnchanged(),
+		))
+	}
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetBaseDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.ShowScenarioMatrixDiff(v.settings.GetFailOnWarnings(), res)
+}
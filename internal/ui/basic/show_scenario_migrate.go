@@ -0,0 +1,29 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioMigrate shows the generated modules that have been moved into the layout.
+func (v *View) ShowScenarioMigrate(res *pb.MigrateScenariosResponse) error {
+	for _, move := range res.GetMoves() {
+		scenario := flightplan.NewScenario()
+		scenario.FromRef(move.GetScenario())
+		v.ui.Output(v.t("Scenario: %s", scenario.String()))
+		v.ui.Output("  " + move.GetFrom() + " -> " + move.GetTo())
+	}
+
+	if len(res.GetMoves()) == 0 {
+		v.ui.Info(v.t("No modules to migrate"))
+	}
+
+	v.WriteDiagnostics(res.GetDiagnostics())
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+
+	return status.MigrateScenarios(v.settings.GetFailOnWarnings(), res)
+}
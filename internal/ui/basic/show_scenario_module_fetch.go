@@ -0,0 +1,26 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"github.com/hashicorp/enos/internal/ui/status"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// ShowScenarioModuleFetch shows the fetched scenario modules.
+func (v *View) ShowScenarioModuleFetch(res *pb.FetchScenarioModulesResponse) error {
+	header := []string{"module", "source", "version", "dir"}
+	rows := [][]string{{""}} // add a padding row
+	for _, mod := range res.GetModules() {
+		rows = append(rows, []string{mod.GetName(), mod.GetSource(), mod.GetVersion(), mod.GetDir()})
+	}
+
+	if len(rows) > 1 {
+		v.ui.RenderTable(header, rows)
+	}
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.FetchScenarioModules(v.settings.GetFailOnWarnings(), res)
+}
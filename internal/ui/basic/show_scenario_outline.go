@@ -49,79 +49,163 @@ func (v *View) ShowScenarioOutline(res *pb.OutlineScenariosResponse) error {
 			b.WriteString("\n")
 		}
 
-		fmt.Fprintf(b, "%s\n", out.GetScenario().GetId().GetName())
-		description := out.GetScenario().GetId().GetDescription()
-		if description != "" {
-			printLine(b, 2, "Description:")
-			if err := errToDiags(printMultiLine(b, 4, description)); err != nil {
+		if err := errToDiags(v.writeScenarioOutline(b, out)); err != nil {
+			return err
+		}
+	}
+
+	output := b.String()
+	if output != "" {
+		v.ui.Output(output)
+	}
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.OutlineScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioOutlineStream shows the outline of each scenario as soon as it has been received.
+func (v *View) ShowScenarioOutlineStream(stream pb.EnosService_StreamOutlineScenariosClient) error {
+	res := &pb.OutlineScenariosResponse{}
+	count := 0
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch val := msg.GetResponse().(type) {
+		case *pb.EnosServiceOutlineScenariosResponse_Decode:
+			res.Decode = val.Decode
+		case *pb.EnosServiceOutlineScenariosResponse_Outline:
+			b := new(strings.Builder)
+			if count != 0 {
+				b.WriteString("\n")
+			}
+			count++
+
+			if err := v.writeScenarioOutline(b, val.Outline); err != nil {
 				return err
 			}
+			v.ui.Output(strings.TrimSuffix(b.String(), "\n"))
+		default:
 		}
+	}
 
-		for i, variant := range out.GetMatrix().GetVectors() {
-			if i == 0 {
-				printLine(b, 2, "Variants:")
+	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
+	v.WriteDiagnostics(res.GetDiagnostics())
+
+	return status.OutlineScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
+// writeScenarioOutline writes the outline of a single scenario. The source location of the
+// scenario is written as a hyperlink if hyperlinks have been enabled.
+func (v *View) writeScenarioOutline(b *strings.Builder, out *pb.Scenario_Outline) error {
+	fmt.Fprintf(b, "%s\n", out.GetScenario().GetId().GetName())
+	if rng := out.GetRange(); rng.GetFilename() != "" {
+		source := fmt.Sprintf("%s:%d", rng.GetFilename(), rng.GetStart().GetLine())
+		if v.settings.GetHyperlinks() {
+			source = diagnostics.Hyperlink(rng, source)
+		}
+		printLine(b, 2, v.t("Source: %s", source))
+	}
+	description := out.GetScenario().GetId().GetDescription()
+	if description != "" {
+		printLine(b, 2, v.t("Description:"))
+		if err := printMultiLine(b, 4, description); err != nil {
+			return err
+		}
+	}
+	if tags := out.GetScenario().GetMetadata().GetTags(); len(tags) > 0 {
+		printLine(b, 2, v.t("Tags: %s", strings.Join(tags, ", ")))
+	}
+
+	for i, variant := range out.GetMatrix().GetVectors() {
+		if i == 0 {
+			printLine(b, 2, v.t("Variants:"))
+		}
+		// We assume a well formatted matrix from the service
+		key := variant.GetElements()[0].GetKey()
+		fmt.Fprint(b, indent(4, fmt.Sprintf("- %s: [", key)))
+		for i := range variant.GetElements() {
+			if i != 0 {
+				fmt.Fprint(b, ", ")
 			}
-			// We assume a well formatted matrix from the service
-			key := variant.GetElements()[0].GetKey()
-			fmt.Fprint(b, indent(4, fmt.Sprintf("- %s: [", key)))
-			for i := range variant.GetElements() {
-				if i != 0 {
-					fmt.Fprint(b, ", ")
-				}
-				fmt.Fprintf(b, "%s", variant.GetElements()[i].GetValue())
+			fmt.Fprintf(b, "%s", variant.GetElements()[i].GetValue())
+		}
+		fmt.Fprint(b, "]\n")
+	}
+
+	for i, quality := range out.GetVerifies() {
+		if i == 0 {
+			printLine(b, 2, v.t("Verifies:"))
+		}
+		printLine(b, 4, fmt.Sprintf("- %s:", quality.GetName()))
+		description := quality.GetDescription()
+		if description != "" {
+			if err := printMultiLine(b, 6, description); err != nil {
+				return err
 			}
-			fmt.Fprint(b, "]\n")
 		}
+	}
 
-		for i, quality := range out.GetVerifies() {
+	for i, step := range out.GetSteps() {
+		if i == 0 {
+			printLine(b, 2, v.t("Steps:"))
+		}
+		// We assume a well formatted matrix from the service
+		printLine(b, 4, "- "+step.GetName())
+		description := step.GetDescription()
+		if description != "" {
+			printLine(b, 6, v.t("Description:"))
+			if err := printMultiLine(b, 8, description); err != nil {
+				return err
+			}
+		}
+
+		for i, quality := range step.GetVerifies() {
 			if i == 0 {
-				printLine(b, 2, "Verifies:")
+				printLine(b, 6, v.t("Verifies:"))
 			}
-			printLine(b, 4, fmt.Sprintf("- %s:", quality.GetName()))
+			printLine(b, 8, fmt.Sprintf("- %s:", quality.GetName()))
 			description := quality.GetDescription()
 			if description != "" {
-				if err := errToDiags(printMultiLine(b, 6, description)); err != nil {
+				if err := printMultiLine(b, 10, description); err != nil {
 					return err
 				}
 			}
 		}
+	}
+
+	for i, output := range out.GetOutputs() {
+		if i == 0 {
+			printLine(b, 2, v.t("Outputs:"))
+		}
+		printLine(b, 4, "- "+output.GetName())
+		description := output.GetDescription()
+		if description != "" {
+			printLine(b, 6, v.t("Description:"))
+			if err := printMultiLine(b, 8, description); err != nil {
+				return err
+			}
+		}
 
-		for i, step := range out.GetSteps() {
+		for i, quality := range output.GetVerifies() {
 			if i == 0 {
-				printLine(b, 2, "Steps:")
+				printLine(b, 6, v.t("Verifies:"))
 			}
-			// We assume a well formatted matrix from the service
-			printLine(b, 4, "- "+step.GetName())
-			description := step.GetDescription()
+			printLine(b, 8, fmt.Sprintf("- %s:", quality.GetName()))
+			description := quality.GetDescription()
 			if description != "" {
-				printLine(b, 6, "Description:")
-				if err := errToDiags(printMultiLine(b, 8, description)); err != nil {
+				if err := printMultiLine(b, 10, description); err != nil {
 					return err
 				}
 			}
-
-			for i, quality := range step.GetVerifies() {
-				if i == 0 {
-					printLine(b, 6, "Verifies:")
-				}
-				printLine(b, 8, fmt.Sprintf("- %s:", quality.GetName()))
-				description := quality.GetDescription()
-				if description != "" {
-					if err := errToDiags(printMultiLine(b, 10, description)); err != nil {
-						return err
-					}
-				}
-			}
 		}
 	}
 
-	output := b.String()
-	if output != "" {
-		v.ui.Output(output)
-	}
-	v.WriteDiagnostics(res.GetDecode().GetDiagnostics())
-	v.WriteDiagnostics(res.GetDiagnostics())
-
-	return status.OutlineScenarios(v.settings.GetFailOnWarnings(), res)
+	return nil
 }
@@ -0,0 +1,11 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+// ShowSchema shows the JSON Schema of a command.
+func (v *View) ShowSchema(schema []byte) error {
+	v.ui.Output(string(schema))
+
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package basic
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/enos/internal/flightplan"
+	"github.com/hashicorp/enos/internal/ui/terminal"
+	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
+)
+
+// stepTree is the status tree of the scenario steps of the operations that are running. When we're
+// writing to a terminal the tree is live, i.e. it's redrawn in place whenever the progress of an
+// operation is updated for as long as nothing else has been written since it was last drawn.
+// Otherwise only the steps whose status has changed are written.
+type stepTree struct {
+	mu   sync.Mutex
+	live bool
+	out  io.Writer
+	ops  []string
+	byOp map[string]*stepTreeOp
+	// finished are the phases of the operations whose progress is done.
+	finished map[string]string
+	// lines is how many lines of the tree are on the terminal, which is zero if anything else has
+	// been written since it was last drawn.
+	lines int
+	// midLine is whether the last output didn't end with a newline.
+	midLine bool
+}
+
+// stepTreeOp is the progress of the steps of an operation.
+type stepTreeOp struct {
+	header   string
+	progress *pb.Terraform_StepProgress
+	statuses map[string]pb.Operation_Status
+}
+
+// stepTreeWriter is a writer to the terminal that keeps track of whether the tree is still the last
+// output.
+type stepTreeWriter struct {
+	tree *stepTree
+	w    io.Writer
+}
+
+// newStepTree returns a new stepTree.
+func newStepTree() *stepTree {
+	return &stepTree{
+		byOp:     map[string]*stepTreeOp{},
+		finished: map[string]string{},
+	}
+}
+
+// track returns a writer that writes to w, which must write to the same terminal as the tree.
+func (t *stepTree) track(w io.Writer) io.Writer {
+	return &stepTreeWriter{tree: t, w: w}
+}
+
+// Write writes to the terminal.
+func (w *stepTreeWriter) Write(p []byte) (int, error) {
+	w.tree.mu.Lock()
+	defer w.tree.mu.Unlock()
+
+	if len(p) > 0 {
+		w.tree.lines = 0
+		w.tree.midLine = p[len(p)-1] != '\n'
+	}
+
+	return w.w.Write(p)
+}
+
+// update updates the progress of the operation and returns the steps whose status has changed.
+// Operations are removed from the tree once their progress is done. The last event of an operation
+// is shown again while we wait for the next one so progress that is already done is ignored.
+func (t *stepTree) update(id string, header string, progress *pb.Terraform_StepProgress) []*pb.Terraform_StepProgress_Step {
+	if progress.GetDone() && t.finished[id] == progress.GetPhase() {
+		return nil
+	}
+
+	op, ok := t.byOp[id]
+	if !ok || op.progress.GetPhase() != progress.GetPhase() {
+		op = &stepTreeOp{statuses: map[string]pb.Operation_Status{}}
+		t.byOp[id] = op
+		if !ok {
+			t.ops = append(t.ops, id)
+		}
+	}
+	op.header = header
+	op.progress = progress
+
+	changed := []*pb.Terraform_StepProgress_Step{}
+	for _, step := range progress.GetSteps() {
+		if op.statuses[step.GetStep()] != step.GetStatus() {
+			changed = append(changed, step)
+			op.statuses[step.GetStep()] = step.GetStatus()
+		}
+	}
+
+	if progress.GetDone() {
+		t.finished[id] = progress.GetPhase()
+		delete(t.byOp, id)
+		t.ops = slices.DeleteFunc(t.ops, func(o string) bool { return o == id })
+	}
+
+	return changed
+}
+
+// draw draws the lines of the tree, replacing the previous tree if it's still the last output.
+func (t *stepTree) draw(lines []string) {
+	b := strings.Builder{}
+	if t.lines > 0 {
+		// Move the cursor to the first line of the tree and clear everything below it.
+		fmt.Fprintf(&b, "\x1b[%dA\x1b[J", t.lines)
+	} else if t.midLine && len(lines) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+
+	t.lines = len(lines)
+	if len(lines) > 0 {
+		t.midLine = false
+	}
+
+	_, _ = io.WriteString(t.out, b.String())
+}
+
+// writeEventStepProgress writes the progress of the scenario steps of the operation.
+func (v *View) writeEventStepProgress(e *pb.Operation_Event) {
+	progress := e.GetStepProgress()
+	if progress == nil || v.settings.GetLevel() < pb.UI_Settings_LEVEL_INFO {
+		return
+	}
+
+	scenario := flightplan.NewScenario()
+	scenario.FromRef(e.GetOp().GetScenario())
+	header := fmt.Sprintf("%s %s", scenario.String(), progress.GetPhase())
+
+	v.steps.mu.Lock()
+	changed := v.steps.update(e.GetOp().GetId(), header, progress)
+	if v.steps.live {
+		v.steps.draw(v.stepTreeLines())
+		v.steps.mu.Unlock()
+
+		return
+	}
+	v.steps.mu.Unlock()
+
+	for _, step := range changed {
+		v.writeMsg(step.GetStatus(), fmt.Sprintf("%s step %s: %s",
+			header, step.GetStep(), v.stepStatusString(step),
+		))
+	}
+}
+
+// stepTreeLines returns the lines of the tree, truncated to the width of the terminal so that the
+// tree can be redrawn in place.
+func (v *View) stepTreeLines() []string {
+	lines := []string{}
+	for _, id := range v.steps.ops {
+		op := v.steps.byOp[id]
+		if len(op.progress.GetSteps()) == 0 {
+			continue
+		}
+
+		lines = append(lines, terminal.Truncate(op.header+":", int(v.ui.Width)))
+		for _, step := range op.progress.GetSteps() {
+			lines = append(lines, terminal.Truncate(
+				fmt.Sprintf("  %s: %s", step.GetStep(), v.stepStatusString(step)),
+				int(v.ui.Width),
+			))
+		}
+	}
+
+	return lines
+}
+
+// stepStatusString returns the status of the step along with how many of its resources are done
+// and in progress.
+func (v *View) stepStatusString(step *pb.Terraform_StepProgress_Step) string {
+	status := v.opStatusString(step.GetStatus())
+
+	counts := []string{}
+	if n := step.GetResourcesDone(); n > 0 {
+		counts = append(counts, v.t("%d done", n))
+	}
+	if n := step.GetResourcesRunning(); n > 0 {
+		counts = append(counts, v.t("%d in progress", n))
+	}
+
+	if len(counts) == 0 {
+		return status
+	}
+
+	return fmt.Sprintf("%s (%s)", status, strings.Join(counts, ", "))
+}
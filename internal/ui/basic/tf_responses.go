@@ -5,6 +5,7 @@ package basic
 
 import (
 	"fmt"
+	"strings"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -24,13 +25,36 @@ func (v *View) writeInitResponse(init *pb.Terraform_Command_Init_Response) {
 	v.writePlainTextResponse("init", init.GetStderr(), init)
 }
 
+func (v *View) writeProvidersLockResponse(lock *pb.Terraform_Command_ProvidersLock_Response) {
+	if lock == nil {
+		return
+	}
+
+	v.writePlainTextResponse("providers lock", lock.GetStderr(), lock)
+	if platforms := lock.GetPlatforms(); len(platforms) > 0 {
+		v.ui.Info("  " + v.t("Platforms: %s", strings.Join(platforms, ", ")))
+	}
+}
+
+func (v *View) writeStateSnapshot(snapshot *pb.Terraform_StateSnapshot) {
+	if snapshot == nil {
+		return
+	}
+
+	v.ui.Info("  " + v.t("Snapshot: %s", snapshot.GetId()))
+	v.ui.Debug("  " + v.t("Path: %s", snapshot.GetPath()))
+	if files := snapshot.GetFiles(); len(files) > 0 {
+		v.ui.Debug("  " + v.t("Files: %s", strings.Join(files, ", ")))
+	}
+}
+
 func (v *View) writeValidateResponse(validate *pb.Terraform_Command_Validate_Response) {
 	if validate == nil {
 		return
 	}
 
 	if status.HasFailed(v.settings.GetFailOnWarnings(), validate) {
-		msg := "  Validate: failed!"
+		msg := "  Validate: " + v.t("failed!")
 		if v.settings.GetIsTty() {
 			msg = "  Validate: ❌"
 		}
@@ -45,12 +69,12 @@ func (v *View) writeValidateResponse(validate *pb.Terraform_Command_Validate_Res
 
 	var msg string
 	if status.HasWarningDiags(validate) {
-		msg = "  Validate: success! (warnings present)"
+		msg = "  Validate: " + v.t("success! (warnings present)")
 		if v.settings.GetIsTty() {
 			msg = "  Validate: ⚠️"
 		}
 	} else {
-		msg = "  Validate: success!"
+		msg = "  Validate: " + v.t("success!")
 		if v.settings.GetIsTty() {
 			msg = "  Validate: ✅"
 		}
@@ -68,7 +92,7 @@ func (v *View) writeExecResponse(exec *pb.Terraform_Command_Exec_Response) {
 	}
 
 	if status.HasFailed(v.settings.GetFailOnWarnings(), exec) {
-		msg := "  Exec: failed!"
+		msg := "  Exec: " + v.t("failed!")
 		if v.settings.GetIsTty() {
 			msg = "  Exec: ❌"
 		}
@@ -80,6 +104,7 @@ func (v *View) writeExecResponse(exec *pb.Terraform_Command_Exec_Response) {
 		if stderr := exec.GetStderr(); stderr != "" {
 			v.ui.Error(stderr)
 		}
+		v.writeExecCapturePaths(exec)
 		v.WriteDiagnostics(exec.GetDiagnostics())
 
 		return
@@ -88,9 +113,21 @@ func (v *View) writeExecResponse(exec *pb.Terraform_Command_Exec_Response) {
 	v.ui.Info(exec.GetStdout())
 	v.ui.Error(exec.GetStderr())
 	v.ui.Debug("  Sub-command: " + exec.GetSubCommand())
+	v.writeExecCapturePaths(exec)
 	v.WriteDiagnostics(exec.GetDiagnostics())
 }
 
+// writeExecCapturePaths writes the paths of the files that the output of the sub-command has been
+// captured into.
+func (v *View) writeExecCapturePaths(exec *pb.Terraform_Command_Exec_Response) {
+	if path := exec.GetStdoutPath(); path != "" {
+		v.ui.Debug("  " + v.t("Stdout: %s", path))
+	}
+	if path := exec.GetStderrPath(); path != "" {
+		v.ui.Debug("  " + v.t("Stderr: %s", path))
+	}
+}
+
 func (v *View) writeOutputResponse(res *pb.Operation_Response) {
 	if res == nil {
 		return
@@ -103,10 +140,11 @@ func (v *View) writeOutputResponse(res *pb.Operation_Response) {
 
 	scenario := flightplan.NewScenario()
 	scenario.FromRef(res.GetOp().GetScenario())
-	v.ui.Info(fmt.Sprintf("Scenario: %s %s", scenario.String(), v.opStatusString(res.GetStatus())))
+	v.ui.Info(v.t("Scenario: %s %s", scenario.String(), v.opStatusString(res.GetStatus())))
+	v.writeScenarioMetadata(res.GetOp().GetScenario())
 
 	if status.HasFailed(v.settings.GetFailOnWarnings(), out) {
-		msg := "  Output: failed!"
+		msg := "  Output: " + v.t("failed!")
 		if v.settings.GetIsTty() {
 			msg = "  Output: ❌"
 		}
@@ -123,16 +161,104 @@ func (v *View) writeOutputResponse(res *pb.Operation_Response) {
 			v.ui.Info(fmt.Sprintf("  %s = %s", meta.GetName(), s))
 		}
 	}
+	v.writeStepResults(res.GetOutput().GetStepResults(), false)
 	v.WriteDiagnostics(diags)
 }
 
+// writeScenarioMetadata writes the description, tags, source location, verified qualities, and
+// expected failure of the scenario reference, if it has them.
+func (v *View) writeScenarioMetadata(ref *pb.Ref_Scenario) {
+	if description := strings.TrimSpace(ref.GetId().GetDescription()); description != "" {
+		v.ui.Info("  " + v.t("Description:"))
+		for _, line := range strings.Split(description, "\n") {
+			v.ui.Info("    " + line)
+		}
+	}
+
+	md := ref.GetMetadata()
+	if len(md.GetTags()) > 0 {
+		v.ui.Info("  " + v.t("Tags: %s", strings.Join(md.GetTags(), ", ")))
+	}
+	if rng := md.GetRange(); rng.GetFilename() != "" {
+		source := fmt.Sprintf("%s:%d", rng.GetFilename(), rng.GetStart().GetLine())
+		if v.settings.GetHyperlinks() {
+			source = diagnostics.Hyperlink(rng, source)
+		}
+		v.ui.Info("  " + v.t("Source: %s", source))
+	}
+	if len(md.GetVerifies()) > 0 {
+		names := []string{}
+		for _, q := range md.GetVerifies() {
+			names = append(names, q.GetName())
+		}
+		v.ui.Info("  " + v.t("Verifies: %s", strings.Join(names, ", ")))
+	}
+	if xfail := md.GetXfail(); xfail != "" {
+		v.ui.Info("  " + v.t("Expected to fail: %s", xfail))
+	}
+}
+
+// writeStepResults writes the results of the exec resources of the steps, e.g. the output of
+// enos_remote_exec resources, indented below the step that they belong to.
+func (v *View) writeStepResults(results []*pb.Terraform_StepResult, failed bool) {
+	if len(results) == 0 {
+		return
+	}
+
+	write := v.ui.Info
+	if failed {
+		write = v.ui.Error
+	}
+
+	write("  " + v.t("Step results:"))
+	for _, result := range results {
+		step := result.GetStep()
+		if step == "" {
+			write("    " + v.t("Root module:"))
+		} else {
+			write("    " + v.t("Step: %s", step))
+		}
+
+		for _, r := range result.GetResources() {
+			line := "      " + strings.TrimPrefix(r.GetAddress(), "module."+step+".")
+			if host := r.GetHost(); host != "" {
+				line += " (" + host + ")"
+			}
+
+			if r.GetFailed() {
+				write(line + ": " + v.t("failed!"))
+				write(indentLines(8, r.GetError()))
+			} else {
+				write(line)
+			}
+
+			for _, out := range []struct{ name, text string }{
+				{"stdout", r.GetStdout()},
+				{"stderr", r.GetStderr()},
+			} {
+				if text := strings.TrimRight(out.text, "\n"); text != "" {
+					write(fmt.Sprintf("        %s:", out.name))
+					write(indentLines(10, text))
+				}
+			}
+		}
+	}
+}
+
+// indentLines indents every line of the text.
+func indentLines(width int, text string) string {
+	prefix := strings.Repeat(" ", width)
+
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
 func (v *View) writeShowResponse(show *pb.Terraform_Command_Show_Response) {
 	if show == nil {
 		return
 	}
 
 	if status.HasFailed(v.settings.GetFailOnWarnings(), show) {
-		msg := "  Read state: failed!"
+		msg := "  Read state: " + v.t("failed!")
 		if v.settings.GetIsTty() {
 			msg = "  Read state: ❌"
 		}
@@ -149,12 +275,12 @@ func (v *View) writeShowResponse(show *pb.Terraform_Command_Show_Response) {
 
 	var msg string
 	if status.HasWarningDiags(show) {
-		msg = "  Read state: success! (warnings present)"
+		msg = "  Read state: " + v.t("success! (warnings present)")
 		if v.settings.GetIsTty() {
 			msg = "  Read state: ⚠️"
 		}
 	} else {
-		msg = "  Read state: success!"
+		msg = "  Read state: " + v.t("success!")
 		if v.settings.GetIsTty() {
 			msg = "  Read state: ✅"
 		}
@@ -174,7 +300,7 @@ func (v *View) writePlainTextResponse(cmd string, stderr string, res status.ResW
 
 	cmd = cases.Title(language.English).String(cmd)
 	if status.HasFailed(v.settings.GetFailOnWarnings(), res) {
-		msg := fmt.Sprintf("  %s: failed!", cmd)
+		msg := fmt.Sprintf("  %s: %s", cmd, v.t("failed!"))
 		if v.settings.GetIsTty() {
 			msg = fmt.Sprintf("  %s: ❌", cmd)
 		}
@@ -189,12 +315,12 @@ func (v *View) writePlainTextResponse(cmd string, stderr string, res status.ResW
 
 	var msg string
 	if status.HasWarningDiags(res) {
-		msg = fmt.Sprintf("  %s: success! (warnings present)", cmd)
+		msg = fmt.Sprintf("  %s: %s", cmd, v.t("success! (warnings present)"))
 		if v.settings.GetIsTty() {
 			msg = fmt.Sprintf("  %s: ⚠️", cmd)
 		}
 	} else {
-		msg = fmt.Sprintf("  %s: success!", cmd)
+		msg = fmt.Sprintf("  %s: %s", cmd, v.t("success!"))
 		if v.settings.GetIsTty() {
 			msg = fmt.Sprintf("  %s: ✅", cmd)
 		}
@@ -8,6 +8,7 @@ import (
 	"embed"
 	"fmt"
 	"html/template"
+	"io"
 	"strings"
 
 	"github.com/hashicorp/enos/internal/ui/basic"
@@ -96,6 +97,16 @@ func (v *View) ShowScenarioList(res *pb.ListScenariosResponse) error {
 	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioList"))
 }
 
+// ShowScenarioListStream shows the a list of scenarios as they're received.
+func (v *View) ShowScenarioListStream(stream pb.EnosService_ListScenariosClient) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioListStream"))
+}
+
+// ShowScenarioIDs shows the identifiers of scenarios.
+func (v *View) ShowScenarioIDs(res *pb.ListScenariosResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioIDs"))
+}
+
 // ShowScenarioOutline shows the scenario outlines.
 func (v *View) ShowScenarioOutline(res *pb.OutlineScenariosResponse) error {
 	if res == nil || res.GetOutlines() == nil || len(res.GetOutlines()) < 1 {
@@ -117,6 +128,13 @@ func (v *View) ShowScenarioOutline(res *pb.OutlineScenariosResponse) error {
 
 			return g.GetScenario().GetId().GetDescription()
 		},
+		"scenarioTags": func(g *pb.Scenario_Outline) []string {
+			if g == nil {
+				return nil
+			}
+
+			return g.GetScenario().GetMetadata().GetTags()
+		},
 		"scenarioVariants": func(g *pb.Scenario_Outline) []*pb.Matrix_Vector {
 			if g == nil {
 				return nil
@@ -145,6 +163,20 @@ func (v *View) ShowScenarioOutline(res *pb.OutlineScenariosResponse) error {
 
 			return g.GetVerifies()
 		},
+		"scenarioOutputs": func(g *pb.Scenario_Outline) []*pb.Scenario_Outline_Output {
+			if g == nil {
+				return nil
+			}
+
+			return g.GetOutputs()
+		},
+		"scenarioOutputVerifies": func(g *pb.Scenario_Outline_Output) []*pb.Quality {
+			if g == nil {
+				return nil
+			}
+
+			return g.GetVerifies()
+		},
 		"printVariantKey": func(vec *pb.Matrix_Vector) string {
 			if vec == nil || vec.GetElements() == nil || len(vec.GetElements()) < 1 {
 				return ""
@@ -182,6 +214,31 @@ func (v *View) ShowScenarioOutline(res *pb.OutlineScenariosResponse) error {
 	return status.OutlineScenarios(v.settings.GetFailOnWarnings(), res)
 }
 
+// ShowScenarioOutlineStream collects the streamed scenario outlines and shows them. As we render
+// a single document we can't show the outlines before we've received all of them.
+func (v *View) ShowScenarioOutlineStream(stream pb.EnosService_StreamOutlineScenariosClient) error {
+	res := &pb.OutlineScenariosResponse{}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch val := msg.GetResponse().(type) {
+		case *pb.EnosServiceOutlineScenariosResponse_Decode:
+			res.Decode = val.Decode
+		case *pb.EnosServiceOutlineScenariosResponse_Outline:
+			res.Outlines = append(res.GetOutlines(), val.Outline)
+		default:
+		}
+	}
+
+	return v.ShowScenarioOutline(res)
+}
+
 // ShowSampleList shows the a list of samples.
 func (v *View) ShowSampleList(res *pb.ListSamplesResponse) error {
 	return v.ShowError(status.Unimplemented("html/ui: ShowSampleList"))
@@ -192,6 +249,81 @@ func (v *View) ShowSampleObservation(res *pb.ObserveSampleResponse) error {
 	return v.ShowError(status.Unimplemented("html/ui: ShowSampleObservation"))
 }
 
+// ShowSampleObservationStream shows the sample observation as it's received.
+func (v *View) ShowSampleObservationStream(stream pb.EnosService_StreamObserveSampleClient) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowSampleObservationStream"))
+}
+
+// ShowScenarioBenchmark shows the scenario benchmark.
+func (v *View) ShowScenarioBenchmark(res *pb.BenchmarkScenariosResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioBenchmark"))
+}
+
+// ShowScenarioAt shows what encloses a position of a flight plan file.
+func (v *View) ShowScenarioAt(res *pb.ShowScenarioAtResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioAt"))
+}
+
+// ShowScenarioEval shows the values of an expression in scenarios.
+func (v *View) ShowScenarioEval(res *pb.EvalScenarioExpressionResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioEval"))
+}
+
+// ShowQualityCoverage shows which scenarios verify the qualities.
+func (v *View) ShowQualityCoverage(res *pb.CheckQualityCoverageResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowQualityCoverage"))
+}
+
+// ShowScenarioMatrix shows the dimensions and size of a scenario matrix.
+func (v *View) ShowScenarioMatrix(res *pb.ShowScenarioMatrixResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioMatrix"))
+}
+
+// ShowScenarioMatrixDiff shows the scenario variants that were added and removed.
+func (v *View) ShowScenarioMatrixDiff(res *pb.DiffScenarioMatricesResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioMatrixDiff"))
+}
+
+// ShowScenarioLint shows the scenario lint response.
+func (v *View) ShowScenarioLint(res *pb.LintScenariosResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioLint"))
+}
+
+// ShowScenarioModuleFetch shows the fetched scenario modules.
+func (v *View) ShowScenarioModuleFetch(res *pb.FetchScenarioModulesResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioModuleFetch"))
+}
+
+// ShowScenarioFix shows the scenario fix response.
+func (v *View) ShowScenarioFix(res *pb.FixScenariosResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioFix"))
+}
+
+// ShowScenarioMigrate shows the scenario migrate response.
+func (v *View) ShowScenarioMigrate(res *pb.MigrateScenariosResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowScenarioMigrate"))
+}
+
+// ShowOutDirMigrate shows the migrate out-dir response.
+func (v *View) ShowOutDirMigrate(res *pb.MigrateOutDirResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowOutDirMigrate"))
+}
+
+// ShowSchema shows the JSON Schema of a command.
+func (v *View) ShowSchema(schema []byte) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowSchema"))
+}
+
+// ShowReportMerge shows the merged report.
+func (v *View) ShowReportMerge(res *pb.MergeReportsResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowReportMerge"))
+}
+
+// ShowReportTrends shows the trends of runs.
+func (v *View) ShowReportTrends(res *pb.ReportTrendsResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowReportTrends"))
+}
+
 // ShowDecode shows the decode response unless it's a incremental update.
 func (v *View) ShowDecode(res *pb.DecodeResponse, incremental bool) error {
 	return v.basic.ShowDecode(res, incremental)
@@ -215,3 +347,28 @@ func (v *View) ShowOperationResponse(res *pb.Operation_Response) error {
 func (v *View) ShowOperationResponses(res *pb.OperationResponses) error {
 	return v.ShowError(status.Unimplemented("html/ui: ShowOperationResponses"))
 }
+
+// ShowOperationList shows the operations that have finished.
+func (v *View) ShowOperationList(res *pb.ListOperationsResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowOperationList"))
+}
+
+// ShowOperation shows an operation that has finished.
+func (v *View) ShowOperation(res *pb.ShowOperationResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowOperation"))
+}
+
+// ShowOperationDiff shows how two operations that have finished differ.
+func (v *View) ShowOperationDiff(res *pb.DiffOperationsResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowOperationDiff"))
+}
+
+// ShowFlightPlanExport shows the exported flight plan.
+func (v *View) ShowFlightPlanExport(res *pb.ExportFlightPlanResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowFlightPlanExport"))
+}
+
+// ShowFlightPlanVerify shows how the flight plan differs from an export.
+func (v *View) ShowFlightPlanVerify(res *pb.VerifyFlightPlanResponse) error {
+	return v.ShowError(status.Unimplemented("html/ui: ShowFlightPlanVerify"))
+}
@@ -14,6 +14,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/hashicorp/enos/internal/diagnostics"
+	"github.com/hashicorp/enos/internal/schema"
 	"github.com/hashicorp/enos/internal/ui/status"
 	"github.com/hashicorp/enos/internal/ui/terminal"
 	pb "github.com/hashicorp/enos/pb/hashicorp/enos/v1"
@@ -28,11 +29,13 @@ type View struct {
 }
 
 type ErrJSON struct {
-	Errors []string `json:"errors"`
+	Errors        []string `json:"errors"`
+	SchemaVersion string   `json:"schema_version"`
 }
 
 type diagsJSON struct {
-	Diags []json.RawMessage `json:"diagnostics"`
+	Diags         []json.RawMessage `json:"diagnostics"`
+	SchemaVersion string            `json:"schema_version"`
 }
 
 // Opt is a functional option.
@@ -58,7 +61,7 @@ func New(opts ...Opt) (*View, error) {
 		opt(v)
 	}
 
-	if v.settings.GetFormat() != pb.UI_Settings_FORMAT_JSON {
+	if v.settings.GetFormat() != pb.UI_Settings_FORMAT_JSON && v.settings.GetFormat() != pb.UI_Settings_FORMAT_YAML {
 		return nil, NewErrUnsupportedEncodingFormat(v.settings.GetFormat())
 	}
 
@@ -171,6 +174,40 @@ func (v *View) ShowScenarioList(res *pb.ListScenariosResponse) error {
 	return status.ListScenarios(v.settings.GetFailOnWarnings(), res)
 }
 
+// ShowScenarioListStream writes each message of the scenario list stream as soon as it has been
+// received. Each message is written on its own line.
+func (v *View) ShowScenarioListStream(stream pb.EnosService_ListScenariosClient) error {
+	res := &pb.ListScenariosResponse{}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if val, ok := msg.GetResponse().(*pb.EnosServiceListScenariosResponse_Decode); ok {
+			res.Decode = val.Decode
+		}
+
+		if err := v.writeLine(msg); err != nil {
+			return err
+		}
+	}
+
+	return status.ListScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioIDs shows the identifiers of scenarios.
+func (v *View) ShowScenarioIDs(res *pb.ListScenariosResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.ListScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
 // ShowScenarioOutline shows the scenario outlines.
 func (v *View) ShowScenarioOutline(res *pb.OutlineScenariosResponse) error {
 	if err := v.write(res); err != nil {
@@ -180,6 +217,31 @@ func (v *View) ShowScenarioOutline(res *pb.OutlineScenariosResponse) error {
 	return status.OutlineScenarios(v.settings.GetFailOnWarnings(), res)
 }
 
+// ShowScenarioOutlineStream writes each message of the scenario outline stream as soon as it has
+// been received. Each message is written on its own line.
+func (v *View) ShowScenarioOutlineStream(stream pb.EnosService_StreamOutlineScenariosClient) error {
+	res := &pb.OutlineScenariosResponse{}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if val, ok := msg.GetResponse().(*pb.EnosServiceOutlineScenariosResponse_Decode); ok {
+			res.Decode = val.Decode
+		}
+
+		if err := v.writeLine(msg); err != nil {
+			return err
+		}
+	}
+
+	return status.OutlineScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
 // ShowSampleList shows the a list of samples.
 func (v *View) ShowSampleList(res *pb.ListSamplesResponse) error {
 	if err := v.write(res); err != nil {
@@ -198,6 +260,155 @@ func (v *View) ShowSampleObservation(res *pb.ObserveSampleResponse) error {
 	return status.ShowSampleObservation(v.settings.GetFailOnWarnings(), res)
 }
 
+// ShowSampleObservationStream writes each message of the sample observation stream as soon as it
+// has been received. Each message is written on its own line.
+func (v *View) ShowSampleObservationStream(stream pb.EnosService_StreamObserveSampleClient) error {
+	res := &pb.ObserveSampleResponse{}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if val, ok := msg.GetResponse().(*pb.EnosServiceObserveSampleResponse_Decode); ok {
+			res.Decode = val.Decode
+		}
+
+		if err := v.writeLine(msg); err != nil {
+			return err
+		}
+	}
+
+	return status.ShowSampleObservation(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioBenchmark shows the scenario benchmark.
+func (v *View) ShowScenarioBenchmark(res *pb.BenchmarkScenariosResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.BenchmarkScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioAt shows what encloses a position of a flight plan file.
+func (v *View) ShowScenarioAt(res *pb.ShowScenarioAtResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.ShowScenarioAt(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioEval shows the values of an expression in scenarios.
+func (v *View) ShowScenarioEval(res *pb.EvalScenarioExpressionResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.ShowScenarioEval(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowQualityCoverage shows which scenarios verify the qualities.
+func (v *View) ShowQualityCoverage(res *pb.CheckQualityCoverageResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.CheckQualityCoverage(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioMatrix shows the dimensions and size of a scenario matrix.
+func (v *View) ShowScenarioMatrix(res *pb.ShowScenarioMatrixResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.ShowScenarioMatrix(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioMatrixDiff shows the scenario variants that were added and removed.
+func (v *View) ShowScenarioMatrixDiff(res *pb.DiffScenarioMatricesResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.ShowScenarioMatrixDiff(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioLint shows the scenario lint response.
+func (v *View) ShowScenarioLint(res *pb.LintScenariosResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.LintScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioModuleFetch shows the fetched scenario modules.
+func (v *View) ShowScenarioModuleFetch(res *pb.FetchScenarioModulesResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.FetchScenarioModules(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioFix shows the scenario fix response.
+func (v *View) ShowScenarioFix(res *pb.FixScenariosResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.FixScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowScenarioMigrate shows the scenario migrate response.
+func (v *View) ShowScenarioMigrate(res *pb.MigrateScenariosResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.MigrateScenarios(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowOutDirMigrate shows the migrate out-dir response.
+func (v *View) ShowOutDirMigrate(res *pb.MigrateOutDirResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.MigrateOutDir(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowSchema shows the JSON Schema of a command.
+func (v *View) ShowSchema(schema []byte) error {
+	_, err := v.ui.Stdout.Write(schema)
+
+	return err
+}
+
+// ShowReportMerge shows the merged report.
+func (v *View) ShowReportMerge(res *pb.MergeReportsResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.MergeReports(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowReportTrends shows the trends of runs.
+func (v *View) ShowReportTrends(res *pb.ReportTrendsResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.ReportTrends(v.settings.GetFailOnWarnings(), res)
+}
+
 // ShowDecode shows the decode response unless it's a incremental update.
 func (v *View) ShowDecode(res *pb.DecodeResponse, incremental bool) error {
 	if incremental {
@@ -250,14 +461,63 @@ func (v *View) ShowOperationResponses(res *pb.OperationResponses) error {
 	return status.OperationResponses(v.settings.GetFailOnWarnings(), res)
 }
 
+// ShowOperationList shows the operations that have finished.
+func (v *View) ShowOperationList(res *pb.ListOperationsResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.ListOperations(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowOperation shows an operation that has finished.
+func (v *View) ShowOperation(res *pb.ShowOperationResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.ShowOperation(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowOperationDiff shows how two operations that have finished differ.
+func (v *View) ShowOperationDiff(res *pb.DiffOperationsResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.DiffOperations(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowFlightPlanExport shows the exported flight plan.
+func (v *View) ShowFlightPlanExport(res *pb.ExportFlightPlanResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.ExportFlightPlan(v.settings.GetFailOnWarnings(), res)
+}
+
+// ShowFlightPlanVerify shows how the flight plan differs from an export.
+func (v *View) ShowFlightPlanVerify(res *pb.VerifyFlightPlanResponse) error {
+	if err := v.write(res); err != nil {
+		return err
+	}
+
+	return status.VerifyFlightPlan(v.settings.GetFailOnWarnings(), res)
+}
+
 // writeError does our best to write the given error to our stderr.
 func (v *View) writeError(err error) error {
 	tryJSON := func(err error) error {
 		msg := &ErrJSON{
-			Errors: []string{err.Error()},
+			Errors:        []string{err.Error()},
+			SchemaVersion: schema.Version,
 		}
 
 		bytes, err2 := json.Marshal(msg)
+		if err2 == nil {
+			bytes, err2 = v.encode(bytes)
+		}
 		if err2 != nil {
 			return fmt.Errorf("%w: %s", err, err2.Error())
 		}
@@ -280,7 +540,7 @@ func (v *View) writeError(err error) error {
 	}
 
 	switch v.settings.GetFormat() {
-	case pb.UI_Settings_FORMAT_JSON:
+	case pb.UI_Settings_FORMAT_JSON, pb.UI_Settings_FORMAT_YAML:
 		err := tryJSON(err)
 		if err != nil {
 			return tryPlainText(err)
@@ -308,7 +568,8 @@ func (v *View) writeError(err error) error {
 func (v *View) writeDiagnostics(diags []*pb.Diagnostic) error {
 	tryJSON := func(diags []*pb.Diagnostic) error {
 		msg := &diagsJSON{
-			Diags: []json.RawMessage{},
+			Diags:         []json.RawMessage{},
+			SchemaVersion: schema.Version,
 		}
 
 		for _, diag := range diags {
@@ -322,6 +583,9 @@ func (v *View) writeDiagnostics(diags []*pb.Diagnostic) error {
 		}
 
 		bytes, err := json.Marshal(msg)
+		if err == nil {
+			bytes, err = v.encode(bytes)
+		}
 		if err != nil {
 			_, _ = v.ui.Stderr.Write([]byte(err.Error()))
 
@@ -343,7 +607,7 @@ func (v *View) writeDiagnostics(diags []*pb.Diagnostic) error {
 	}
 
 	switch v.settings.GetFormat() {
-	case pb.UI_Settings_FORMAT_JSON:
+	case pb.UI_Settings_FORMAT_JSON, pb.UI_Settings_FORMAT_YAML:
 		err := tryJSON(diags)
 		if err != nil {
 			return tryPlainText(err)
@@ -379,11 +643,17 @@ func (v *View) write(msg proto.Message) error {
 	var bytes []byte
 
 	switch v.settings.GetFormat() {
-	case pb.UI_Settings_FORMAT_JSON:
+	case pb.UI_Settings_FORMAT_JSON, pb.UI_Settings_FORMAT_YAML:
+		schema.Stamp(msg)
 		bytes, err = protojson.Marshal(msg)
 		if err != nil {
 			return err
 		}
+
+		bytes, err = v.encode(bytes)
+		if err != nil {
+			return err
+		}
 	case pb.UI_Settings_FORMAT_UNSPECIFIED, pb.UI_Settings_FORMAT_BASIC_TEXT, pb.UI_Settings_FORMAT_HTML:
 		return NewErrUnsupportedEncodingFormat(v.settings.GetFormat())
 	default:
@@ -394,3 +664,32 @@ func (v *View) write(msg proto.Message) error {
 
 	return err
 }
+
+// writeLine writes the message followed by a newline so that streamed messages can be read one
+// line at a time. YAML messages are written as separate documents instead.
+func (v *View) writeLine(msg proto.Message) error {
+	if v.settings.GetFormat() == pb.UI_Settings_FORMAT_YAML {
+		if _, err := v.ui.Stdout.Write([]byte("---\n")); err != nil {
+			return err
+		}
+
+		return v.write(msg)
+	}
+
+	if err := v.write(msg); err != nil {
+		return err
+	}
+
+	_, err := v.ui.Stdout.Write([]byte("\n"))
+
+	return err
+}
+
+// encode takes JSON and encodes it in the output format.
+func (v *View) encode(in []byte) ([]byte, error) {
+	if v.settings.GetFormat() != pb.UI_Settings_FORMAT_YAML {
+		return in, nil
+	}
+
+	return jsonToYAML(in)
+}
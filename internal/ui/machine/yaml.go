@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package machine
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonToYAML converts JSON to block style YAML. We always marshal responses with protojson first
+// so that the YAML output has exactly the same fields, names, and values as the JSON output. As
+// JSON is valid YAML we can decode it into YAML nodes, which preserves the order of fields, and
+// encode them again without the flow style of JSON.
+func jsonToYAML(in []byte) ([]byte, error) {
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(in, node); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(node)
+
+	out := &bytes.Buffer{}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// resetYAMLStyle resets the style of the node and its children so that the encoder chooses the
+// style. Values that would otherwise be decoded as a different type, like the string "true", are
+// still quoted by the encoder.
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		resetYAMLStyle(n)
+	}
+}
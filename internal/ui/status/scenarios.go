@@ -64,3 +64,188 @@ func OutlineScenarios(failOnWarn bool, res *pb.OutlineScenariosResponse) error {
 
 	return nil
 }
+
+// LintScenarios returns the status response for a scenario lint. Lint errors always fail, lint
+// warnings only fail if we fail on warnings.
+func LintScenarios(failOnWarn bool, res *pb.LintScenariosResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to lint scenarios")
+	}
+
+	if diagnostics.HasFailed(failOnWarn, res.GetLints()) {
+		return &ErrExit{ExitCode: 1}
+	}
+
+	return nil
+}
+
+// FetchScenarioModules returns the status response for a scenario module fetch.
+func FetchScenarioModules(failOnWarn bool, res *pb.FetchScenarioModulesResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to fetch scenario modules")
+	}
+
+	return nil
+}
+
+// FixScenarios returns the status response for a scenario fix. Diagnostics that could not be
+// fixed are returned as decode diagnostics and fail as usual.
+func FixScenarios(failOnWarn bool, res *pb.FixScenariosResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to fix scenarios")
+	}
+
+	return nil
+}
+
+// MigrateScenarios returns the status response for a scenario migrate.
+func MigrateScenarios(failOnWarn bool, res *pb.MigrateScenariosResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to migrate scenarios")
+	}
+
+	return nil
+}
+
+// MigrateOutDir returns the status response for a migrate out-dir.
+func MigrateOutDir(failOnWarn bool, res *pb.MigrateOutDirResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to migrate out directory")
+	}
+
+	return nil
+}
+
+// MergeReports returns the status response for a report merge. It fails if any merged scenario
+// failed.
+func MergeReports(failOnWarn bool, res *pb.MergeReportsResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) || res.GetSummary().GetFailed() > 0 {
+		return &ErrExit{ExitCode: 1}
+	}
+
+	return nil
+}
+
+// ReportTrends returns the status response for report trends.
+func ReportTrends(failOnWarn bool, res *pb.ReportTrendsResponse) error {
+	if HasFailed(failOnWarn, res) {
+		return Error("failed to compare reports")
+	}
+
+	return nil
+}
+
+// BenchmarkScenarios returns the status response for a scenario benchmark.
+func BenchmarkScenarios(failOnWarn bool, res *pb.BenchmarkScenariosResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to benchmark scenarios")
+	}
+
+	return nil
+}
+
+// ListOperations returns the status response for an operation list.
+func ListOperations(failOnWarn bool, res *pb.ListOperationsResponse) error {
+	if HasFailed(failOnWarn, res) {
+		return Error("failed to list operations")
+	}
+
+	return nil
+}
+
+// ShowOperation returns the status response for an operation show.
+func ShowOperation(failOnWarn bool, res *pb.ShowOperationResponse) error {
+	if HasFailed(failOnWarn, res) {
+		return Error("failed to show operation")
+	}
+
+	return nil
+}
+
+// DiffOperations returns the status response for an operation diff.
+func DiffOperations(failOnWarn bool, res *pb.DiffOperationsResponse) error {
+	if HasFailed(failOnWarn, res) {
+		return Error("failed to diff operations")
+	}
+
+	return nil
+}
+
+// ExportFlightPlan returns the status response for a flight plan export.
+func ExportFlightPlan(failOnWarn bool, res *pb.ExportFlightPlanResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to export flight plan")
+	}
+
+	return nil
+}
+
+// VerifyFlightPlan returns the status response for a flight plan verify. Any differences from the
+// export fail.
+func VerifyFlightPlan(failOnWarn bool, res *pb.VerifyFlightPlanResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to verify flight plan")
+	}
+
+	if len(res.GetDifferences()) > 0 {
+		return &ErrExit{ExitCode: 1}
+	}
+
+	return nil
+}
+
+// ShowScenarioAt returns the status response for a scenario show-at.
+func ShowScenarioAt(failOnWarn bool, res *pb.ShowScenarioAtResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to show the scenario at the position")
+	}
+
+	return nil
+}
+
+// ShowScenarioEval returns the status response for a scenario eval. It fails if the expression
+// failed to evaluate in any scenario.
+func ShowScenarioEval(failOnWarn bool, res *pb.EvalScenarioExpressionResponse) error {
+	results := []ResWithDiags{res, res.GetDecode()}
+	for _, result := range res.GetResults() {
+		results = append(results, result)
+	}
+
+	if HasFailed(failOnWarn, results...) {
+		return Error("failed to evaluate the expression")
+	}
+
+	return nil
+}
+
+// CheckQualityCoverage returns the status response for a quality coverage check. Any qualities
+// that no scenario verifies fail.
+func CheckQualityCoverage(failOnWarn bool, res *pb.CheckQualityCoverageResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to check quality coverage")
+	}
+
+	if len(res.GetUnverified()) > 0 {
+		return &ErrExit{ExitCode: 1}
+	}
+
+	return nil
+}
+
+// ShowScenarioMatrix returns the status response for showing a scenario matrix.
+func ShowScenarioMatrix(failOnWarn bool, res *pb.ShowScenarioMatrixResponse) error {
+	if HasFailed(failOnWarn, res, res.GetDecode()) {
+		return Error("failed to show scenario matrix")
+	}
+
+	return nil
+}
+
+// ShowScenarioMatrixDiff returns the status response for a scenario matrix diff.
+func ShowScenarioMatrixDiff(failOnWarn bool, res *pb.DiffScenarioMatricesResponse) error {
+	if HasFailed(failOnWarn, res, res.GetBaseDecode(), res.GetDecode()) {
+		return Error("failed to diff scenario matrices")
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package terminal
+
+import (
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	// ellipsis is appended to truncated text.
+	ellipsis = "…"
+	// minColumnWidth is the width that table columns are never truncated below.
+	minColumnWidth = 8
+)
+
+// TruncateLine truncates the line to the width of the UI if truncation is enabled.
+func (u *UI) TruncateLine(line string) string {
+	if !u.Truncate {
+		return line
+	}
+
+	return Truncate(line, int(u.Width))
+}
+
+// Truncate truncates the string to the width in terminal cells and appends an ellipsis if the
+// string has been truncated. A width of zero or less never truncates.
+func Truncate(s string, width int) string {
+	if width <= 0 || runewidth.StringWidth(s) <= width {
+		return s
+	}
+
+	return runewidth.Truncate(s, width, ellipsis)
+}
+
+// fitTable truncates the cells of the table so that it fits the width. The widest columns are
+// truncated first, e.g. long variant values are truncated before short scenario names. Columns are
+// never truncated below the minimum column width, so tables with many columns might still be wider.
+func fitTable(header []string, rows [][]string, width int, padding int) ([]string, [][]string) {
+	widths := []int{}
+	measure := func(row []string) {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], runewidth.StringWidth(cell))
+		}
+	}
+	measure(header)
+	for _, row := range rows {
+		measure(row)
+	}
+
+	total := padding * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+
+	if total <= width {
+		return header, rows
+	}
+
+	for total > width {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+
+		widths[widest]--
+		total--
+	}
+
+	truncate := func(row []string) []string {
+		out := make([]string, len(row))
+		for i, cell := range row {
+			out[i] = Truncate(cell, widths[i])
+		}
+
+		return out
+	}
+
+	fitRows := make([][]string, len(rows))
+	for i, row := range rows {
+		fitRows[i] = truncate(row)
+	}
+
+	return truncate(header), fitRows
+}
@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package terminal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Truncate tests truncating strings to a width.
+func Test_Truncate(t *testing.T) {
+	t.Parallel()
+
+	for desc, test := range map[string]struct {
+		in       string
+		width    int
+		expected string
+	}{
+		"fits":      {"consul", 6, "consul"},
+		"truncated": {"consul_enterprise", 8, "consul_…"},
+		"wide":      {"日本語のテキスト", 7, "日本語…"},
+		"no width":  {"consul_enterprise", 0, "consul_enterprise"},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.expected, Truncate(test.in, test.width))
+		})
+	}
+}
+
+// Test_fitTable tests that the widest columns of tables are truncated first and that columns are
+// never truncated below the minimum width.
+func Test_fitTable(t *testing.T) {
+	t.Parallel()
+
+	header := []string{"name", "variants"}
+	rows := [][]string{
+		{""},
+		{"upgrade", "backend:raft_storage_with_autopilot"},
+		{"smoke", "backend:consul"},
+	}
+
+	t.Run("fits", func(t *testing.T) {
+		t.Parallel()
+
+		h, r := fitTable(header, rows, 80, 2)
+		require.Equal(t, header, h)
+		require.Equal(t, rows, r)
+	})
+
+	t.Run("truncates widest column", func(t *testing.T) {
+		t.Parallel()
+
+		h, r := fitTable(header, rows, 24, 2)
+		require.Equal(t, header, h)
+		require.Equal(t, [][]string{
+			{""},
+			{"upgrade", "backend:raft_s…"},
+			{"smoke", "backend:consul"},
+		}, r)
+	})
+
+	t.Run("minimum column width", func(t *testing.T) {
+		t.Parallel()
+
+		h, r := fitTable(header, rows, 10, 2)
+		require.Equal(t, header, h)
+		require.Equal(t, [][]string{
+			{""},
+			{"upgrade", "backend…"},
+			{"smoke", "backend…"},
+		}, r)
+	})
+}
@@ -14,8 +14,16 @@ import (
 	"github.com/hashicorp/hcl/v2"
 )
 
-// RenderTable does a basic render of table data to the desired writer.
+// tablePadding is the padding between table columns.
+const tablePadding = "  "
+
+// RenderTable does a basic render of table data to the desired writer. If truncation is enabled
+// the cells are truncated so that the table fits the width.
 func (u *UI) RenderTable(header []string, rows [][]string) {
+	if u.Truncate && u.Width > 0 {
+		header, rows = fitTable(header, rows, int(u.Width), len(tablePadding))
+	}
+
 	table := tablewriter.NewWriter(u.Stdout)
 
 	table.SetHeader(header)
@@ -30,7 +38,7 @@ func (u *UI) RenderTable(header []string, rows [][]string) {
 	table.SetHeaderLine(false)
 	table.SetBorder(false)
 	table.SetNoWhiteSpace(true)
-	table.SetTablePadding("  ")
+	table.SetTablePadding(tablePadding)
 	table.AppendBulk(rows)
 
 	table.Render()
@@ -46,6 +54,7 @@ type UI struct {
 
 	UseColor bool
 	Width    uint
+	Truncate bool
 
 	AskPrefix       string
 	AskSecretPrefix string
@@ -181,6 +190,13 @@ func WithWidth(wrap uint) Opt {
 	}
 }
 
+// WithTruncate sets whether or not to truncate tables and lists to the width.
+func WithTruncate(truncate bool) Opt {
+	return func(ui *UI) {
+		ui.Truncate = truncate
+	}
+}
+
 // Ask prompts the user for some data.
 func (u *UI) Ask(q string) (string, error) {
 	return u.ui.Ask(q)
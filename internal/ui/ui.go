@@ -31,21 +31,44 @@ type View interface {
 	ShowVersion(all bool, res *pb.GetVersionResponse) error
 	ShowFormat(cfg *pb.FormatRequest_Config, res *pb.FormatResponse) error
 	ShowScenarioList(res *pb.ListScenariosResponse) error
+	ShowScenarioListStream(stream pb.EnosService_ListScenariosClient) error
+	ShowScenarioIDs(res *pb.ListScenariosResponse) error
 	ShowScenarioOutline(res *pb.OutlineScenariosResponse) error
+	ShowScenarioOutlineStream(stream pb.EnosService_StreamOutlineScenariosClient) error
 	ShowDecode(res *pb.DecodeResponse, incremental bool) error
 	ShowOutput(res *pb.OperationResponses) error
 	ShowScenariosValidateConfig(res *pb.ValidateScenariosConfigurationResponse) error
 	ShowOperationEvent(res *pb.Operation_Event)
 	ShowOperationResponse(res *pb.Operation_Response) error
 	ShowOperationResponses(res *pb.OperationResponses) error
+	ShowOperationList(res *pb.ListOperationsResponse) error
+	ShowOperation(res *pb.ShowOperationResponse) error
+	ShowOperationDiff(res *pb.DiffOperationsResponse) error
+	ShowFlightPlanExport(res *pb.ExportFlightPlanResponse) error
+	ShowFlightPlanVerify(res *pb.VerifyFlightPlanResponse) error
 	ShowSampleList(res *pb.ListSamplesResponse) error
 	ShowSampleObservation(res *pb.ObserveSampleResponse) error
+	ShowSampleObservationStream(stream pb.EnosService_StreamObserveSampleClient) error
+	ShowScenarioBenchmark(res *pb.BenchmarkScenariosResponse) error
+	ShowScenarioAt(res *pb.ShowScenarioAtResponse) error
+	ShowScenarioEval(res *pb.EvalScenarioExpressionResponse) error
+	ShowQualityCoverage(res *pb.CheckQualityCoverageResponse) error
+	ShowScenarioMatrix(res *pb.ShowScenarioMatrixResponse) error
+	ShowScenarioMatrixDiff(res *pb.DiffScenarioMatricesResponse) error
+	ShowScenarioLint(res *pb.LintScenariosResponse) error
+	ShowScenarioModuleFetch(res *pb.FetchScenarioModulesResponse) error
+	ShowScenarioFix(res *pb.FixScenariosResponse) error
+	ShowScenarioMigrate(res *pb.MigrateScenariosResponse) error
+	ShowOutDirMigrate(res *pb.MigrateOutDirResponse) error
+	ShowSchema(schema []byte) error
+	ShowReportMerge(res *pb.MergeReportsResponse) error
+	ShowReportTrends(res *pb.ReportTrendsResponse) error
 }
 
 // New takes a UI configuration settings and returns a new view.
 func New(s *pb.UI_Settings) (View, error) {
 	switch s.GetFormat() {
-	case pb.UI_Settings_FORMAT_JSON:
+	case pb.UI_Settings_FORMAT_JSON, pb.UI_Settings_FORMAT_YAML:
 		return machine.New(machine.WithUISettings(s))
 	case pb.UI_Settings_FORMAT_BASIC_TEXT:
 		return basic.New(basic.WithUISettings(s))
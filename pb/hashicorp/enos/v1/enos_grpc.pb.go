@@ -38,6 +38,31 @@ const (
 	EnosService_ListSamples_FullMethodName                    = "/hashicorp.enos.v1.EnosService/ListSamples"
 	EnosService_ObserveSample_FullMethodName                  = "/hashicorp.enos.v1.EnosService/ObserveSample"
 	EnosService_OutlineScenarios_FullMethodName               = "/hashicorp.enos.v1.EnosService/OutlineScenarios"
+	EnosService_RegisterProject_FullMethodName                = "/hashicorp.enos.v1.EnosService/RegisterProject"
+	EnosService_UnregisterProject_FullMethodName              = "/hashicorp.enos.v1.EnosService/UnregisterProject"
+	EnosService_ListProjects_FullMethodName                   = "/hashicorp.enos.v1.EnosService/ListProjects"
+	EnosService_BenchmarkScenarios_FullMethodName             = "/hashicorp.enos.v1.EnosService/BenchmarkScenarios"
+	EnosService_StreamOutlineScenarios_FullMethodName         = "/hashicorp.enos.v1.EnosService/StreamOutlineScenarios"
+	EnosService_StreamObserveSample_FullMethodName            = "/hashicorp.enos.v1.EnosService/StreamObserveSample"
+	EnosService_LintScenarios_FullMethodName                  = "/hashicorp.enos.v1.EnosService/LintScenarios"
+	EnosService_FetchScenarioModules_FullMethodName           = "/hashicorp.enos.v1.EnosService/FetchScenarioModules"
+	EnosService_FixScenarios_FullMethodName                   = "/hashicorp.enos.v1.EnosService/FixScenarios"
+	EnosService_MigrateScenarios_FullMethodName               = "/hashicorp.enos.v1.EnosService/MigrateScenarios"
+	EnosService_LockScenarios_FullMethodName                  = "/hashicorp.enos.v1.EnosService/LockScenarios"
+	EnosService_MigrateOutDir_FullMethodName                  = "/hashicorp.enos.v1.EnosService/MigrateOutDir"
+	EnosService_BackupScenarioStates_FullMethodName           = "/hashicorp.enos.v1.EnosService/BackupScenarioStates"
+	EnosService_RestoreScenarioStates_FullMethodName          = "/hashicorp.enos.v1.EnosService/RestoreScenarioStates"
+	EnosService_AttachOperation_FullMethodName                = "/hashicorp.enos.v1.EnosService/AttachOperation"
+	EnosService_ListOperations_FullMethodName                 = "/hashicorp.enos.v1.EnosService/ListOperations"
+	EnosService_ShowOperation_FullMethodName                  = "/hashicorp.enos.v1.EnosService/ShowOperation"
+	EnosService_DiffOperations_FullMethodName                 = "/hashicorp.enos.v1.EnosService/DiffOperations"
+	EnosService_ExportFlightPlan_FullMethodName               = "/hashicorp.enos.v1.EnosService/ExportFlightPlan"
+	EnosService_VerifyFlightPlan_FullMethodName               = "/hashicorp.enos.v1.EnosService/VerifyFlightPlan"
+	EnosService_ShowScenarioAt_FullMethodName                 = "/hashicorp.enos.v1.EnosService/ShowScenarioAt"
+	EnosService_CheckQualityCoverage_FullMethodName           = "/hashicorp.enos.v1.EnosService/CheckQualityCoverage"
+	EnosService_ShowScenarioMatrix_FullMethodName             = "/hashicorp.enos.v1.EnosService/ShowScenarioMatrix"
+	EnosService_DiffScenarioMatrices_FullMethodName           = "/hashicorp.enos.v1.EnosService/DiffScenarioMatrices"
+	EnosService_EvalScenarioExpression_FullMethodName         = "/hashicorp.enos.v1.EnosService/EvalScenarioExpression"
 )
 
 // EnosServiceClient is the client API for EnosService service.
@@ -60,6 +85,31 @@ type EnosServiceClient interface {
 	ListSamples(ctx context.Context, in *ListSamplesRequest, opts ...grpc.CallOption) (*ListSamplesResponse, error)
 	ObserveSample(ctx context.Context, in *ObserveSampleRequest, opts ...grpc.CallOption) (*ObserveSampleResponse, error)
 	OutlineScenarios(ctx context.Context, in *OutlineScenariosRequest, opts ...grpc.CallOption) (*OutlineScenariosResponse, error)
+	RegisterProject(ctx context.Context, in *RegisterProjectRequest, opts ...grpc.CallOption) (*RegisterProjectResponse, error)
+	UnregisterProject(ctx context.Context, in *UnregisterProjectRequest, opts ...grpc.CallOption) (*UnregisterProjectResponse, error)
+	ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponse, error)
+	BenchmarkScenarios(ctx context.Context, in *BenchmarkScenariosRequest, opts ...grpc.CallOption) (*BenchmarkScenariosResponse, error)
+	StreamOutlineScenarios(ctx context.Context, in *OutlineScenariosRequest, opts ...grpc.CallOption) (EnosService_StreamOutlineScenariosClient, error)
+	StreamObserveSample(ctx context.Context, in *ObserveSampleRequest, opts ...grpc.CallOption) (EnosService_StreamObserveSampleClient, error)
+	LintScenarios(ctx context.Context, in *LintScenariosRequest, opts ...grpc.CallOption) (*LintScenariosResponse, error)
+	FetchScenarioModules(ctx context.Context, in *FetchScenarioModulesRequest, opts ...grpc.CallOption) (*FetchScenarioModulesResponse, error)
+	FixScenarios(ctx context.Context, in *FixScenariosRequest, opts ...grpc.CallOption) (*FixScenariosResponse, error)
+	MigrateScenarios(ctx context.Context, in *MigrateScenariosRequest, opts ...grpc.CallOption) (*MigrateScenariosResponse, error)
+	LockScenarios(ctx context.Context, in *LockScenariosRequest, opts ...grpc.CallOption) (*LockScenariosResponse, error)
+	MigrateOutDir(ctx context.Context, in *MigrateOutDirRequest, opts ...grpc.CallOption) (*MigrateOutDirResponse, error)
+	BackupScenarioStates(ctx context.Context, in *BackupScenarioStatesRequest, opts ...grpc.CallOption) (*BackupScenarioStatesResponse, error)
+	RestoreScenarioStates(ctx context.Context, in *RestoreScenarioStatesRequest, opts ...grpc.CallOption) (*RestoreScenarioStatesResponse, error)
+	AttachOperation(ctx context.Context, opts ...grpc.CallOption) (EnosService_AttachOperationClient, error)
+	ListOperations(ctx context.Context, in *ListOperationsRequest, opts ...grpc.CallOption) (*ListOperationsResponse, error)
+	ShowOperation(ctx context.Context, in *ShowOperationRequest, opts ...grpc.CallOption) (*ShowOperationResponse, error)
+	DiffOperations(ctx context.Context, in *DiffOperationsRequest, opts ...grpc.CallOption) (*DiffOperationsResponse, error)
+	ExportFlightPlan(ctx context.Context, in *ExportFlightPlanRequest, opts ...grpc.CallOption) (*ExportFlightPlanResponse, error)
+	VerifyFlightPlan(ctx context.Context, in *VerifyFlightPlanRequest, opts ...grpc.CallOption) (*VerifyFlightPlanResponse, error)
+	ShowScenarioAt(ctx context.Context, in *ShowScenarioAtRequest, opts ...grpc.CallOption) (*ShowScenarioAtResponse, error)
+	CheckQualityCoverage(ctx context.Context, in *CheckQualityCoverageRequest, opts ...grpc.CallOption) (*CheckQualityCoverageResponse, error)
+	ShowScenarioMatrix(ctx context.Context, in *ShowScenarioMatrixRequest, opts ...grpc.CallOption) (*ShowScenarioMatrixResponse, error)
+	DiffScenarioMatrices(ctx context.Context, in *DiffScenarioMatricesRequest, opts ...grpc.CallOption) (*DiffScenarioMatricesResponse, error)
+	EvalScenarioExpression(ctx context.Context, in *EvalScenarioExpressionRequest, opts ...grpc.CallOption) (*EvalScenarioExpressionResponse, error)
 }
 
 type enosServiceClient struct {
@@ -276,6 +326,324 @@ func (c *enosServiceClient) OutlineScenarios(ctx context.Context, in *OutlineSce
 	return out, nil
 }
 
+func (c *enosServiceClient) RegisterProject(ctx context.Context, in *RegisterProjectRequest, opts ...grpc.CallOption) (*RegisterProjectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterProjectResponse)
+	err := c.cc.Invoke(ctx, EnosService_RegisterProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) UnregisterProject(ctx context.Context, in *UnregisterProjectRequest, opts ...grpc.CallOption) (*UnregisterProjectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnregisterProjectResponse)
+	err := c.cc.Invoke(ctx, EnosService_UnregisterProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProjectsResponse)
+	err := c.cc.Invoke(ctx, EnosService_ListProjects_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) BenchmarkScenarios(ctx context.Context, in *BenchmarkScenariosRequest, opts ...grpc.CallOption) (*BenchmarkScenariosResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BenchmarkScenariosResponse)
+	err := c.cc.Invoke(ctx, EnosService_BenchmarkScenarios_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) StreamOutlineScenarios(ctx context.Context, in *OutlineScenariosRequest, opts ...grpc.CallOption) (EnosService_StreamOutlineScenariosClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EnosService_ServiceDesc.Streams[2], EnosService_StreamOutlineScenarios_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &enosServiceStreamOutlineScenariosClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EnosService_StreamOutlineScenariosClient interface {
+	Recv() (*EnosServiceOutlineScenariosResponse, error)
+	grpc.ClientStream
+}
+
+type enosServiceStreamOutlineScenariosClient struct {
+	grpc.ClientStream
+}
+
+func (x *enosServiceStreamOutlineScenariosClient) Recv() (*EnosServiceOutlineScenariosResponse, error) {
+	m := new(EnosServiceOutlineScenariosResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *enosServiceClient) StreamObserveSample(ctx context.Context, in *ObserveSampleRequest, opts ...grpc.CallOption) (EnosService_StreamObserveSampleClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EnosService_ServiceDesc.Streams[3], EnosService_StreamObserveSample_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &enosServiceStreamObserveSampleClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EnosService_StreamObserveSampleClient interface {
+	Recv() (*EnosServiceObserveSampleResponse, error)
+	grpc.ClientStream
+}
+
+type enosServiceStreamObserveSampleClient struct {
+	grpc.ClientStream
+}
+
+func (x *enosServiceStreamObserveSampleClient) Recv() (*EnosServiceObserveSampleResponse, error) {
+	m := new(EnosServiceObserveSampleResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *enosServiceClient) LintScenarios(ctx context.Context, in *LintScenariosRequest, opts ...grpc.CallOption) (*LintScenariosResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LintScenariosResponse)
+	err := c.cc.Invoke(ctx, EnosService_LintScenarios_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) FetchScenarioModules(ctx context.Context, in *FetchScenarioModulesRequest, opts ...grpc.CallOption) (*FetchScenarioModulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchScenarioModulesResponse)
+	err := c.cc.Invoke(ctx, EnosService_FetchScenarioModules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) FixScenarios(ctx context.Context, in *FixScenariosRequest, opts ...grpc.CallOption) (*FixScenariosResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FixScenariosResponse)
+	err := c.cc.Invoke(ctx, EnosService_FixScenarios_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) MigrateScenarios(ctx context.Context, in *MigrateScenariosRequest, opts ...grpc.CallOption) (*MigrateScenariosResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MigrateScenariosResponse)
+	err := c.cc.Invoke(ctx, EnosService_MigrateScenarios_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) LockScenarios(ctx context.Context, in *LockScenariosRequest, opts ...grpc.CallOption) (*LockScenariosResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockScenariosResponse)
+	err := c.cc.Invoke(ctx, EnosService_LockScenarios_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) MigrateOutDir(ctx context.Context, in *MigrateOutDirRequest, opts ...grpc.CallOption) (*MigrateOutDirResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MigrateOutDirResponse)
+	err := c.cc.Invoke(ctx, EnosService_MigrateOutDir_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) BackupScenarioStates(ctx context.Context, in *BackupScenarioStatesRequest, opts ...grpc.CallOption) (*BackupScenarioStatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BackupScenarioStatesResponse)
+	err := c.cc.Invoke(ctx, EnosService_BackupScenarioStates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) RestoreScenarioStates(ctx context.Context, in *RestoreScenarioStatesRequest, opts ...grpc.CallOption) (*RestoreScenarioStatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreScenarioStatesResponse)
+	err := c.cc.Invoke(ctx, EnosService_RestoreScenarioStates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) AttachOperation(ctx context.Context, opts ...grpc.CallOption) (EnosService_AttachOperationClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EnosService_ServiceDesc.Streams[4], EnosService_AttachOperation_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &enosServiceAttachOperationClient{ClientStream: stream}
+	return x, nil
+}
+
+type EnosService_AttachOperationClient interface {
+	Send(*AttachOperationRequest) error
+	Recv() (*AttachOperationResponse, error)
+	grpc.ClientStream
+}
+
+type enosServiceAttachOperationClient struct {
+	grpc.ClientStream
+}
+
+func (x *enosServiceAttachOperationClient) Send(m *AttachOperationRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *enosServiceAttachOperationClient) Recv() (*AttachOperationResponse, error) {
+	m := new(AttachOperationResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *enosServiceClient) ListOperations(ctx context.Context, in *ListOperationsRequest, opts ...grpc.CallOption) (*ListOperationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOperationsResponse)
+	err := c.cc.Invoke(ctx, EnosService_ListOperations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) ShowOperation(ctx context.Context, in *ShowOperationRequest, opts ...grpc.CallOption) (*ShowOperationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShowOperationResponse)
+	err := c.cc.Invoke(ctx, EnosService_ShowOperation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) DiffOperations(ctx context.Context, in *DiffOperationsRequest, opts ...grpc.CallOption) (*DiffOperationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffOperationsResponse)
+	err := c.cc.Invoke(ctx, EnosService_DiffOperations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) ExportFlightPlan(ctx context.Context, in *ExportFlightPlanRequest, opts ...grpc.CallOption) (*ExportFlightPlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportFlightPlanResponse)
+	err := c.cc.Invoke(ctx, EnosService_ExportFlightPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) VerifyFlightPlan(ctx context.Context, in *VerifyFlightPlanRequest, opts ...grpc.CallOption) (*VerifyFlightPlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyFlightPlanResponse)
+	err := c.cc.Invoke(ctx, EnosService_VerifyFlightPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) ShowScenarioAt(ctx context.Context, in *ShowScenarioAtRequest, opts ...grpc.CallOption) (*ShowScenarioAtResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShowScenarioAtResponse)
+	err := c.cc.Invoke(ctx, EnosService_ShowScenarioAt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) CheckQualityCoverage(ctx context.Context, in *CheckQualityCoverageRequest, opts ...grpc.CallOption) (*CheckQualityCoverageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckQualityCoverageResponse)
+	err := c.cc.Invoke(ctx, EnosService_CheckQualityCoverage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) ShowScenarioMatrix(ctx context.Context, in *ShowScenarioMatrixRequest, opts ...grpc.CallOption) (*ShowScenarioMatrixResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShowScenarioMatrixResponse)
+	err := c.cc.Invoke(ctx, EnosService_ShowScenarioMatrix_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) DiffScenarioMatrices(ctx context.Context, in *DiffScenarioMatricesRequest, opts ...grpc.CallOption) (*DiffScenarioMatricesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffScenarioMatricesResponse)
+	err := c.cc.Invoke(ctx, EnosService_DiffScenarioMatrices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *enosServiceClient) EvalScenarioExpression(ctx context.Context, in *EvalScenarioExpressionRequest, opts ...grpc.CallOption) (*EvalScenarioExpressionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EvalScenarioExpressionResponse)
+	err := c.cc.Invoke(ctx, EnosService_EvalScenarioExpression_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EnosServiceServer is the server API for EnosService service.
 // All implementations should embed UnimplementedEnosServiceServer
 // for forward compatibility
@@ -296,6 +664,31 @@ type EnosServiceServer interface {
 	ListSamples(context.Context, *ListSamplesRequest) (*ListSamplesResponse, error)
 	ObserveSample(context.Context, *ObserveSampleRequest) (*ObserveSampleResponse, error)
 	OutlineScenarios(context.Context, *OutlineScenariosRequest) (*OutlineScenariosResponse, error)
+	RegisterProject(context.Context, *RegisterProjectRequest) (*RegisterProjectResponse, error)
+	UnregisterProject(context.Context, *UnregisterProjectRequest) (*UnregisterProjectResponse, error)
+	ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponse, error)
+	BenchmarkScenarios(context.Context, *BenchmarkScenariosRequest) (*BenchmarkScenariosResponse, error)
+	StreamOutlineScenarios(*OutlineScenariosRequest, EnosService_StreamOutlineScenariosServer) error
+	StreamObserveSample(*ObserveSampleRequest, EnosService_StreamObserveSampleServer) error
+	LintScenarios(context.Context, *LintScenariosRequest) (*LintScenariosResponse, error)
+	FetchScenarioModules(context.Context, *FetchScenarioModulesRequest) (*FetchScenarioModulesResponse, error)
+	FixScenarios(context.Context, *FixScenariosRequest) (*FixScenariosResponse, error)
+	MigrateScenarios(context.Context, *MigrateScenariosRequest) (*MigrateScenariosResponse, error)
+	LockScenarios(context.Context, *LockScenariosRequest) (*LockScenariosResponse, error)
+	MigrateOutDir(context.Context, *MigrateOutDirRequest) (*MigrateOutDirResponse, error)
+	BackupScenarioStates(context.Context, *BackupScenarioStatesRequest) (*BackupScenarioStatesResponse, error)
+	RestoreScenarioStates(context.Context, *RestoreScenarioStatesRequest) (*RestoreScenarioStatesResponse, error)
+	AttachOperation(EnosService_AttachOperationServer) error
+	ListOperations(context.Context, *ListOperationsRequest) (*ListOperationsResponse, error)
+	ShowOperation(context.Context, *ShowOperationRequest) (*ShowOperationResponse, error)
+	DiffOperations(context.Context, *DiffOperationsRequest) (*DiffOperationsResponse, error)
+	ExportFlightPlan(context.Context, *ExportFlightPlanRequest) (*ExportFlightPlanResponse, error)
+	VerifyFlightPlan(context.Context, *VerifyFlightPlanRequest) (*VerifyFlightPlanResponse, error)
+	ShowScenarioAt(context.Context, *ShowScenarioAtRequest) (*ShowScenarioAtResponse, error)
+	CheckQualityCoverage(context.Context, *CheckQualityCoverageRequest) (*CheckQualityCoverageResponse, error)
+	ShowScenarioMatrix(context.Context, *ShowScenarioMatrixRequest) (*ShowScenarioMatrixResponse, error)
+	DiffScenarioMatrices(context.Context, *DiffScenarioMatricesRequest) (*DiffScenarioMatricesResponse, error)
+	EvalScenarioExpression(context.Context, *EvalScenarioExpressionRequest) (*EvalScenarioExpressionResponse, error)
 }
 
 // UnimplementedEnosServiceServer should be embedded to have forward compatible implementations.
@@ -350,6 +743,81 @@ func (UnimplementedEnosServiceServer) ObserveSample(context.Context, *ObserveSam
 func (UnimplementedEnosServiceServer) OutlineScenarios(context.Context, *OutlineScenariosRequest) (*OutlineScenariosResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method OutlineScenarios not implemented")
 }
+func (UnimplementedEnosServiceServer) RegisterProject(context.Context, *RegisterProjectRequest) (*RegisterProjectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterProject not implemented")
+}
+func (UnimplementedEnosServiceServer) UnregisterProject(context.Context, *UnregisterProjectRequest) (*UnregisterProjectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnregisterProject not implemented")
+}
+func (UnimplementedEnosServiceServer) ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProjects not implemented")
+}
+func (UnimplementedEnosServiceServer) BenchmarkScenarios(context.Context, *BenchmarkScenariosRequest) (*BenchmarkScenariosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BenchmarkScenarios not implemented")
+}
+func (UnimplementedEnosServiceServer) StreamOutlineScenarios(*OutlineScenariosRequest, EnosService_StreamOutlineScenariosServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOutlineScenarios not implemented")
+}
+func (UnimplementedEnosServiceServer) StreamObserveSample(*ObserveSampleRequest, EnosService_StreamObserveSampleServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamObserveSample not implemented")
+}
+func (UnimplementedEnosServiceServer) LintScenarios(context.Context, *LintScenariosRequest) (*LintScenariosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LintScenarios not implemented")
+}
+func (UnimplementedEnosServiceServer) FetchScenarioModules(context.Context, *FetchScenarioModulesRequest) (*FetchScenarioModulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchScenarioModules not implemented")
+}
+func (UnimplementedEnosServiceServer) FixScenarios(context.Context, *FixScenariosRequest) (*FixScenariosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FixScenarios not implemented")
+}
+func (UnimplementedEnosServiceServer) MigrateScenarios(context.Context, *MigrateScenariosRequest) (*MigrateScenariosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrateScenarios not implemented")
+}
+func (UnimplementedEnosServiceServer) LockScenarios(context.Context, *LockScenariosRequest) (*LockScenariosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LockScenarios not implemented")
+}
+func (UnimplementedEnosServiceServer) MigrateOutDir(context.Context, *MigrateOutDirRequest) (*MigrateOutDirResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrateOutDir not implemented")
+}
+func (UnimplementedEnosServiceServer) BackupScenarioStates(context.Context, *BackupScenarioStatesRequest) (*BackupScenarioStatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BackupScenarioStates not implemented")
+}
+func (UnimplementedEnosServiceServer) RestoreScenarioStates(context.Context, *RestoreScenarioStatesRequest) (*RestoreScenarioStatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreScenarioStates not implemented")
+}
+func (UnimplementedEnosServiceServer) AttachOperation(EnosService_AttachOperationServer) error {
+	return status.Errorf(codes.Unimplemented, "method AttachOperation not implemented")
+}
+func (UnimplementedEnosServiceServer) ListOperations(context.Context, *ListOperationsRequest) (*ListOperationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOperations not implemented")
+}
+func (UnimplementedEnosServiceServer) ShowOperation(context.Context, *ShowOperationRequest) (*ShowOperationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShowOperation not implemented")
+}
+func (UnimplementedEnosServiceServer) DiffOperations(context.Context, *DiffOperationsRequest) (*DiffOperationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffOperations not implemented")
+}
+func (UnimplementedEnosServiceServer) ExportFlightPlan(context.Context, *ExportFlightPlanRequest) (*ExportFlightPlanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportFlightPlan not implemented")
+}
+func (UnimplementedEnosServiceServer) VerifyFlightPlan(context.Context, *VerifyFlightPlanRequest) (*VerifyFlightPlanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyFlightPlan not implemented")
+}
+func (UnimplementedEnosServiceServer) ShowScenarioAt(context.Context, *ShowScenarioAtRequest) (*ShowScenarioAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShowScenarioAt not implemented")
+}
+func (UnimplementedEnosServiceServer) CheckQualityCoverage(context.Context, *CheckQualityCoverageRequest) (*CheckQualityCoverageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckQualityCoverage not implemented")
+}
+func (UnimplementedEnosServiceServer) ShowScenarioMatrix(context.Context, *ShowScenarioMatrixRequest) (*ShowScenarioMatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShowScenarioMatrix not implemented")
+}
+func (UnimplementedEnosServiceServer) DiffScenarioMatrices(context.Context, *DiffScenarioMatricesRequest) (*DiffScenarioMatricesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffScenarioMatrices not implemented")
+}
+func (UnimplementedEnosServiceServer) EvalScenarioExpression(context.Context, *EvalScenarioExpressionRequest) (*EvalScenarioExpressionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvalScenarioExpression not implemented")
+}
 
 // UnsafeEnosServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to EnosServiceServer will
@@ -656,12 +1124,476 @@ func _EnosService_OutlineScenarios_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
-// EnosService_ServiceDesc is the grpc.ServiceDesc for EnosService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var EnosService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "hashicorp.enos.v1.EnosService",
-	HandlerType: (*EnosServiceServer)(nil),
+func _EnosService_RegisterProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).RegisterProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_RegisterProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).RegisterProject(ctx, req.(*RegisterProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_UnregisterProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnregisterProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).UnregisterProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_UnregisterProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).UnregisterProject(ctx, req.(*UnregisterProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_ListProjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).ListProjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_ListProjects_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).ListProjects(ctx, req.(*ListProjectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_BenchmarkScenarios_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BenchmarkScenariosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).BenchmarkScenarios(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_BenchmarkScenarios_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).BenchmarkScenarios(ctx, req.(*BenchmarkScenariosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_StreamOutlineScenarios_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(OutlineScenariosRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EnosServiceServer).StreamOutlineScenarios(m, &enosServiceStreamOutlineScenariosServer{ServerStream: stream})
+}
+
+type EnosService_StreamOutlineScenariosServer interface {
+	Send(*EnosServiceOutlineScenariosResponse) error
+	grpc.ServerStream
+}
+
+type enosServiceStreamOutlineScenariosServer struct {
+	grpc.ServerStream
+}
+
+func (x *enosServiceStreamOutlineScenariosServer) Send(m *EnosServiceOutlineScenariosResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _EnosService_StreamObserveSample_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ObserveSampleRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EnosServiceServer).StreamObserveSample(m, &enosServiceStreamObserveSampleServer{ServerStream: stream})
+}
+
+type EnosService_StreamObserveSampleServer interface {
+	Send(*EnosServiceObserveSampleResponse) error
+	grpc.ServerStream
+}
+
+type enosServiceStreamObserveSampleServer struct {
+	grpc.ServerStream
+}
+
+func (x *enosServiceStreamObserveSampleServer) Send(m *EnosServiceObserveSampleResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _EnosService_LintScenarios_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LintScenariosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).LintScenarios(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_LintScenarios_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).LintScenarios(ctx, req.(*LintScenariosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_FetchScenarioModules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchScenarioModulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).FetchScenarioModules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_FetchScenarioModules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).FetchScenarioModules(ctx, req.(*FetchScenarioModulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_FixScenarios_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FixScenariosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).FixScenarios(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_FixScenarios_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).FixScenarios(ctx, req.(*FixScenariosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_MigrateScenarios_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateScenariosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).MigrateScenarios(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_MigrateScenarios_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).MigrateScenarios(ctx, req.(*MigrateScenariosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_LockScenarios_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockScenariosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).LockScenarios(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_LockScenarios_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).LockScenarios(ctx, req.(*LockScenariosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_MigrateOutDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateOutDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).MigrateOutDir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_MigrateOutDir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).MigrateOutDir(ctx, req.(*MigrateOutDirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_BackupScenarioStates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupScenarioStatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).BackupScenarioStates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_BackupScenarioStates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).BackupScenarioStates(ctx, req.(*BackupScenarioStatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_RestoreScenarioStates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreScenarioStatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).RestoreScenarioStates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_RestoreScenarioStates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).RestoreScenarioStates(ctx, req.(*RestoreScenarioStatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_AttachOperation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EnosServiceServer).AttachOperation(&enosServiceAttachOperationServer{ServerStream: stream})
+}
+
+type EnosService_AttachOperationServer interface {
+	Send(*AttachOperationResponse) error
+	Recv() (*AttachOperationRequest, error)
+	grpc.ServerStream
+}
+
+type enosServiceAttachOperationServer struct {
+	grpc.ServerStream
+}
+
+func (x *enosServiceAttachOperationServer) Send(m *AttachOperationResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *enosServiceAttachOperationServer) Recv() (*AttachOperationRequest, error) {
+	m := new(AttachOperationRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _EnosService_ListOperations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOperationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).ListOperations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_ListOperations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).ListOperations(ctx, req.(*ListOperationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_ShowOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShowOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).ShowOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_ShowOperation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).ShowOperation(ctx, req.(*ShowOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_DiffOperations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffOperationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).DiffOperations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_DiffOperations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).DiffOperations(ctx, req.(*DiffOperationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_ExportFlightPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportFlightPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).ExportFlightPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_ExportFlightPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).ExportFlightPlan(ctx, req.(*ExportFlightPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_VerifyFlightPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyFlightPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).VerifyFlightPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_VerifyFlightPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).VerifyFlightPlan(ctx, req.(*VerifyFlightPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_ShowScenarioAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShowScenarioAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).ShowScenarioAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_ShowScenarioAt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).ShowScenarioAt(ctx, req.(*ShowScenarioAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_CheckQualityCoverage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckQualityCoverageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).CheckQualityCoverage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_CheckQualityCoverage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).CheckQualityCoverage(ctx, req.(*CheckQualityCoverageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_ShowScenarioMatrix_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShowScenarioMatrixRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).ShowScenarioMatrix(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_ShowScenarioMatrix_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).ShowScenarioMatrix(ctx, req.(*ShowScenarioMatrixRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_DiffScenarioMatrices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffScenarioMatricesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).DiffScenarioMatrices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_DiffScenarioMatrices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).DiffScenarioMatrices(ctx, req.(*DiffScenarioMatricesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EnosService_EvalScenarioExpression_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvalScenarioExpressionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnosServiceServer).EvalScenarioExpression(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EnosService_EvalScenarioExpression_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnosServiceServer).EvalScenarioExpression(ctx, req.(*EvalScenarioExpressionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EnosService_ServiceDesc is the grpc.ServiceDesc for EnosService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EnosService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hashicorp.enos.v1.EnosService",
+	HandlerType: (*EnosServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
 			MethodName: "GetVersion",
@@ -719,6 +1651,94 @@ var EnosService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "OutlineScenarios",
 			Handler:    _EnosService_OutlineScenarios_Handler,
 		},
+		{
+			MethodName: "RegisterProject",
+			Handler:    _EnosService_RegisterProject_Handler,
+		},
+		{
+			MethodName: "UnregisterProject",
+			Handler:    _EnosService_UnregisterProject_Handler,
+		},
+		{
+			MethodName: "ListProjects",
+			Handler:    _EnosService_ListProjects_Handler,
+		},
+		{
+			MethodName: "BenchmarkScenarios",
+			Handler:    _EnosService_BenchmarkScenarios_Handler,
+		},
+		{
+			MethodName: "LintScenarios",
+			Handler:    _EnosService_LintScenarios_Handler,
+		},
+		{
+			MethodName: "FetchScenarioModules",
+			Handler:    _EnosService_FetchScenarioModules_Handler,
+		},
+		{
+			MethodName: "FixScenarios",
+			Handler:    _EnosService_FixScenarios_Handler,
+		},
+		{
+			MethodName: "MigrateScenarios",
+			Handler:    _EnosService_MigrateScenarios_Handler,
+		},
+		{
+			MethodName: "LockScenarios",
+			Handler:    _EnosService_LockScenarios_Handler,
+		},
+		{
+			MethodName: "MigrateOutDir",
+			Handler:    _EnosService_MigrateOutDir_Handler,
+		},
+		{
+			MethodName: "BackupScenarioStates",
+			Handler:    _EnosService_BackupScenarioStates_Handler,
+		},
+		{
+			MethodName: "RestoreScenarioStates",
+			Handler:    _EnosService_RestoreScenarioStates_Handler,
+		},
+		{
+			MethodName: "ListOperations",
+			Handler:    _EnosService_ListOperations_Handler,
+		},
+		{
+			MethodName: "ShowOperation",
+			Handler:    _EnosService_ShowOperation_Handler,
+		},
+		{
+			MethodName: "DiffOperations",
+			Handler:    _EnosService_DiffOperations_Handler,
+		},
+		{
+			MethodName: "ExportFlightPlan",
+			Handler:    _EnosService_ExportFlightPlan_Handler,
+		},
+		{
+			MethodName: "VerifyFlightPlan",
+			Handler:    _EnosService_VerifyFlightPlan_Handler,
+		},
+		{
+			MethodName: "ShowScenarioAt",
+			Handler:    _EnosService_ShowScenarioAt_Handler,
+		},
+		{
+			MethodName: "CheckQualityCoverage",
+			Handler:    _EnosService_CheckQualityCoverage_Handler,
+		},
+		{
+			MethodName: "ShowScenarioMatrix",
+			Handler:    _EnosService_ShowScenarioMatrix_Handler,
+		},
+		{
+			MethodName: "DiffScenarioMatrices",
+			Handler:    _EnosService_DiffScenarioMatrices_Handler,
+		},
+		{
+			MethodName: "EvalScenarioExpression",
+			Handler:    _EnosService_EvalScenarioExpression_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -731,6 +1751,22 @@ var EnosService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _EnosService_OperationEventStream_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamOutlineScenarios",
+			Handler:       _EnosService_StreamOutlineScenarios_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamObserveSample",
+			Handler:       _EnosService_StreamObserveSample_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "AttachOperation",
+			Handler:       _EnosService_AttachOperation_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "hashicorp/enos/v1/enos.proto",
 }